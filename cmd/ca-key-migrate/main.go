@@ -0,0 +1,50 @@
+package main
+
+import (
+	"context"
+	"flag"
+	"net/http"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/clients"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	log "github.com/sirupsen/logrus"
+)
+
+// Migrates the private key of a CA from its current crypto engine to another one configured in the CA service.
+// Usage: ca-key-migrate -url https://lamassu.local/api/ca -ca-id my-ca -engine vault-1
+func main() {
+	log.SetFormatter(helpers.LogFormatter)
+
+	caURL := flag.String("url", "http://localhost:8085", "CA service base URL")
+	caID := flag.String("ca-id", "", "ID of the CA whose key will be migrated")
+	engineID := flag.String("engine", "", "ID of the target crypto engine")
+	caCertFile := flag.String("ca-cert-file", "", "CA certificate used to validate the CA service TLS certificate")
+	insecure := flag.Bool("insecure", false, "skip CA service TLS certificate validation")
+	flag.Parse()
+
+	if *caID == "" || *engineID == "" {
+		flag.Usage()
+		log.Fatalf("both -ca-id and -engine are required")
+	}
+
+	httpCli, err := helpers.BuildHTTPClientWithTLSOptions(&http.Client{}, config.TLSConfig{
+		InsecureSkipVerify: *insecure,
+		CACertificateFile:  *caCertFile,
+	})
+	if err != nil {
+		log.Fatalf("could not build HTTP client: %s", err)
+	}
+
+	caCli := clients.NewHttpCAClient(httpCli, *caURL)
+	ca, err := caCli.MigrateCAKey(context.Background(), services.MigrateCAKeyInput{
+		CAID:           *caID,
+		TargetEngineID: *engineID,
+	})
+	if err != nil {
+		log.Fatalf("could not migrate CA %s key to engine %s: %s", *caID, *engineID, err)
+	}
+
+	log.Infof("CA %s key migrated. CA is now using engine %s", ca.ID, ca.Certificate.EngineID)
+}
@@ -1,10 +1,13 @@
 package main
 
 import (
+	"flag"
+
 	lamassu "github.com/lamassuiot/lamassuiot/v2/pkg/assemblers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/builder"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
@@ -16,6 +19,9 @@ var (
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "upgrade the storage schema and exit without starting the service")
+	flag.Parse()
+
 	log.SetFormatter(helpers.LogFormatter)
 	log.Infof("starting api: version=%s buildTime=%s sha1ver=%s", version, buildTime, sha1ver)
 
@@ -42,6 +48,17 @@ func main() {
 	log.Debugf("%s", confBytes)
 	log.Debugf("===================================================")
 
+	if *migrateOnly {
+		lStorage := helpers.SetupLogger(conf.Storage.LogLevel, "CA", "Storage")
+		_, err = builder.BuildAndMigrateStorageEngine(lStorage, conf.Storage)
+		if err != nil {
+			log.Fatalf("could not migrate storage schema. Exiting: %s", err)
+		}
+
+		log.Infof("storage schema migrated. Exiting")
+		return
+	}
+
 	_, _, _, err = lamassu.AssembleCAServiceWithHTTPServer(*conf, models.APIServiceInfo{
 		Version:   version,
 		BuildSHA:  sha1ver,
@@ -1,6 +1,7 @@
 package main
 
 import (
+	"flag"
 	"fmt"
 
 	lamassu "github.com/lamassuiot/lamassuiot/v2/pkg/assemblers"
@@ -8,6 +9,7 @@ import (
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/builder"
 	log "github.com/sirupsen/logrus"
 	"gopkg.in/yaml.v2"
 )
@@ -19,6 +21,9 @@ var (
 )
 
 func main() {
+	migrateOnly := flag.Bool("migrate-only", false, "upgrade the storage schema and exit without starting the service")
+	flag.Parse()
+
 	log.SetFormatter(helpers.LogFormatter)
 	log.Infof("starting api: version=%s buildTime=%s sha1ver=%s", version, buildTime, sha1ver)
 
@@ -45,6 +50,17 @@ func main() {
 	log.Debugf("%s", confBytes)
 	log.Debugf("===================================================")
 
+	if *migrateOnly {
+		lStorage := helpers.SetupLogger(conf.Storage.LogLevel, "DMS Manager", "Storage")
+		_, err = builder.BuildAndMigrateStorageEngine(lStorage, conf.Storage)
+		if err != nil {
+			log.Fatalf("could not migrate storage schema. Exiting: %s", err)
+		}
+
+		log.Infof("storage schema migrated. Exiting")
+		return
+	}
+
 	lCAClient := helpers.SetupLogger(conf.CAClient.LogLevel, "DMS Manager", "LMS SDK - CA Client")
 	caHttpCli, err := clients.BuildHTTPClient(conf.CAClient.HTTPClient, lCAClient)
 	if err != nil {
@@ -132,7 +132,7 @@ func main() {
 	if *sqliteOptions == "" {
 		fmt.Println(">> launching docker: Postgres ...")
 		var err error
-		pCleanup, postgresStorageConfig, err = postgres_test.RunPostgresDocker([]string{"ca", "alerts", "dmsmanager", "devicemanager", "cloudproxy"})
+		pCleanup, postgresStorageConfig, err = postgres_test.RunPostgresDocker([]string{"ca", "alerts", "dmsmanager", "devicemanager", "cloudproxy", "eventbus"})
 		if err != nil {
 			log.Fatalf("could not launch Postgres: %s", err)
 		}
@@ -273,11 +273,13 @@ func main() {
 
 	if _, ok := cryptoengineOptionsMap[AwsKms]; ok {
 		cryptoEnginesConfig.DefaultEngine = "dockertest-localstack-kms"
-		cryptoEnginesConfig.AWSKMSProvider = []config.AWSCryptoEngine{
+		cryptoEnginesConfig.AWSKMSProvider = []config.AWSKMSCryptoEngine{
 			{
-				AWSSDKConfig: *awsCfg,
-				ID:           "dockertest-localstack-kms",
-				Metadata:     make(map[string]interface{}),
+				AWSCryptoEngine: config.AWSCryptoEngine{
+					AWSSDKConfig: *awsCfg,
+					ID:           "dockertest-localstack-kms",
+					Metadata:     make(map[string]interface{}),
+				},
 			},
 		}
 	}
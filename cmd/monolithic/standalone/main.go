@@ -0,0 +1,64 @@
+package main
+
+import (
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/test/monolithic"
+	log "github.com/sirupsen/logrus"
+	"gopkg.in/yaml.v2"
+)
+
+var (
+	version   string = "v0"    // api version
+	sha1ver   string = "-"     // sha1 revision used to build the program
+	buildTime string = "devTS" // when the executable was built
+)
+
+// Runs the CA, VA, Device Manager and DMS Manager in a single process, configured with a single config file. Meant
+// for labs, demos and small edge sites. Use the 'memory' or 'sqlite' storage providers to avoid external databases.
+func main() {
+	log.SetFormatter(helpers.LogFormatter)
+	log.Infof("starting monolithic api: version=%s buildTime=%s sha1ver=%s", version, buildTime, sha1ver)
+
+	conf, err := config.LoadConfig[config.MonolithicConfig](&config.MonolithicDefaults)
+	if err != nil {
+		log.Fatalf("something went wrong while loading config. Exiting: %s", err)
+	}
+
+	globalLogLevel, err := log.ParseLevel(string(conf.Logs.Level))
+	if err != nil {
+		log.Warn("unknown log level. defaulting to 'info' log level")
+		globalLogLevel = log.InfoLevel
+	}
+	log.SetLevel(globalLogLevel)
+
+	log.Infof("global log level set to '%s'", globalLogLevel)
+
+	confBytes, err := yaml.Marshal(conf)
+	if err != nil {
+		log.Fatalf("could not dump yaml config: %s", err)
+	}
+
+	log.Debugf("===================================================")
+	log.Debugf("%s", confBytes)
+	log.Debugf("===================================================")
+
+	if conf.Storage.Provider == config.Memory {
+		log.Warn("using in-memory storage engine. Data will be lost once the process stops")
+	}
+
+	port, err := monolithic.RunMonolithicLamassuPKIWithInfo(*conf, models.APIServiceInfo{
+		Version:   version,
+		BuildSHA:  sha1ver,
+		BuildTime: buildTime,
+	})
+	if err != nil {
+		log.Fatalf("could not run monolithic Lamassu PKI. Exiting: %s", err)
+	}
+
+	log.Infof("monolithic Lamassu PKI listening on port %d", port)
+
+	forever := make(chan struct{})
+	<-forever
+}
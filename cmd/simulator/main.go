@@ -0,0 +1,125 @@
+package main
+
+import (
+	"context"
+	"crypto"
+	"crypto/x509"
+	"flag"
+	"net/http"
+	"os"
+	"os/signal"
+	"strings"
+	"time"
+
+	"github.com/globalsign/est"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/clients"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/simulator"
+	log "github.com/sirupsen/logrus"
+)
+
+// Spawns virtual devices that generate their key, enroll through the EST endpoint of a DMS, periodically re-enroll
+// and finally revoke their certificate. Once all the devices finish, the latency percentiles and error rates of each
+// operation are reported. The bootstrap certificate must be accepted by the DMS enrollment validation CAs.
+// Usage: simulator -est-host lamassu.local/api/dmsmanager -dms my-dms -bootstrap-cert boot.crt -bootstrap-key boot.key -devices 100
+func main() {
+	log.SetFormatter(helpers.LogFormatter)
+
+	estHost := flag.String("est-host", "localhost:8085", "EST server host, including the DMS Manager path prefix (i.e. lamassu.local/api/dmsmanager)")
+	dmsID := flag.String("dms", "", "ID of the DMS used to enroll the devices")
+	caURL := flag.String("ca-url", "https://localhost:8085/api/ca", "CA service base URL, used to revoke the device certificates")
+	bootstrapCertFile := flag.String("bootstrap-cert", "", "bootstrap certificate used to enroll the devices")
+	bootstrapKeyFile := flag.String("bootstrap-key", "", "bootstrap private key used to enroll the devices")
+	caCertFile := flag.String("ca-cert-file", "", "CA certificate used to validate the server TLS certificates")
+	insecure := flag.Bool("insecure", false, "skip server TLS certificate validation")
+	devices := flag.Int("devices", 10, "number of virtual devices")
+	prefix := flag.String("device-prefix", "sim-", "prefix of the device IDs. The device index is appended to it")
+	keyType := flag.String("key-type", "ECDSA", "device key type: RSA or ECDSA")
+	reenrollments := flag.Int("reenrollments", 1, "re-enrollments performed by each device")
+	reenrollInterval := flag.Duration("reenroll-interval", 10*time.Second, "time between re-enrollments")
+	revoke := flag.Bool("revoke", true, "revoke the device certificates once the re-enrollments finish")
+	rampUp := flag.Duration("ramp-up", 0, "period along which the start of the devices is spread")
+	flag.Parse()
+
+	if *dmsID == "" || *bootstrapCertFile == "" || *bootstrapKeyFile == "" {
+		flag.Usage()
+		log.Fatalf("-dms, -bootstrap-cert and -bootstrap-key are required")
+	}
+
+	var keyAlg x509.PublicKeyAlgorithm
+	switch strings.ToUpper(*keyType) {
+	case "RSA":
+		keyAlg = x509.RSA
+	case "ECDSA":
+		keyAlg = x509.ECDSA
+	default:
+		log.Fatalf("unsupported key type %s", *keyType)
+	}
+
+	bootstrapCert, err := helpers.ReadCertificateFromFile(*bootstrapCertFile)
+	if err != nil {
+		log.Fatalf("could not read bootstrap certificate: %s", err)
+	}
+
+	key, err := helpers.ReadPrivateKeyFromFile(*bootstrapKeyFile)
+	if err != nil {
+		log.Fatalf("could not read bootstrap key: %s", err)
+	}
+
+	bootstrapKey, ok := key.(crypto.Signer)
+	if !ok {
+		log.Fatalf("unsupported bootstrap key")
+	}
+
+	var anchor *x509.CertPool
+	if *caCertFile != "" {
+		anchor = helpers.LoadSystemCACertPoolWithExtraCAsFromFiles([]string{*caCertFile})
+	}
+
+	estClient := func(cert *x509.Certificate, key crypto.Signer) simulator.ESTClient {
+		return &est.Client{
+			Host:                  *estHost,
+			AdditionalPathSegment: *dmsID,
+			Certificates:          []*x509.Certificate{cert},
+			PrivateKey:            key,
+			ExplicitAnchor:        anchor,
+			InsecureSkipVerify:    *insecure,
+		}
+	}
+
+	httpCli, err := helpers.BuildHTTPClientWithTLSOptions(&http.Client{}, config.TLSConfig{
+		InsecureSkipVerify: *insecure,
+		CACertificateFile:  *caCertFile,
+	})
+	if err != nil {
+		log.Fatalf("could not build HTTP client: %s", err)
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer cancel()
+
+	lSim := helpers.SetupLogger(config.Info, "Simulator", "Devices")
+	sim := simulator.NewSimulator(simulator.Config{
+		Devices:          *devices,
+		DeviceIDPrefix:   *prefix,
+		KeyType:          keyAlg,
+		Reenrollments:    *reenrollments,
+		ReenrollInterval: *reenrollInterval,
+		Revoke:           *revoke,
+		RampUp:           *rampUp,
+	}, bootstrapCert, bootstrapKey, estClient, clients.NewHttpCAClient(httpCli, *caURL), lSim)
+
+	start := time.Now()
+	reports := sim.Run(ctx)
+	log.Infof("simulated %d devices in %s", *devices, time.Since(start).Round(time.Millisecond))
+
+	for _, r := range reports {
+		if r.Total == 0 {
+			continue
+		}
+
+		log.Infof("%-8s total=%d errors=%d (%.2f%%) p50=%s p90=%s p99=%s max=%s",
+			r.Operation, r.Total, r.Errors, r.ErrorRate*100, r.P50, r.P90, r.P99, r.Max)
+	}
+}
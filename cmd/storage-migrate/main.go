@@ -0,0 +1,122 @@
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"os"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/builder"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/migration"
+	log "github.com/sirupsen/logrus"
+)
+
+// Migrates the CAs, DMSs, devices (with their slots) and the certificate history of an existing deployment into the
+// storage engine used by the upgraded services. The legacy Postgres databases are read through the source storage
+// engine and written into the target one (i.e. CouchDB or Postgres), both defined in the file pointed by LAMASSU_CONFIG_FILE.
+//
+// To switch backends without an outage window, configure the services with the dual_write storage provider (same
+// source and target engines), run this tool to backfill the records written before, and switch the services to
+// the target engine once the -verify report is consistent.
+// Usage: storage-migrate [-dry-run] [-checkpoint migration-checkpoint.json] [-verify [-resync] [-report migration-report.json]] [-skip-backfill]
+func main() {
+	log.SetFormatter(helpers.LogFormatter)
+
+	dryRun := flag.Bool("dry-run", false, "list the records to be migrated without writing them")
+	checkpointFile := flag.String("checkpoint", "migration-checkpoint.json", "file used to record the migrated records and resume interrupted migrations")
+	skipBackfill := flag.Bool("skip-backfill", false, "do not copy the records, only verify them")
+	verify := flag.Bool("verify", false, "compare the counts and hashes of the records stored in the source and target engines")
+	resync := flag.Bool("resync", false, "copy again the missing and mismatched records found by the verification")
+	reportFile := flag.String("report", "migration-report.json", "file the verification report is written to")
+	flag.Parse()
+
+	conf, err := config.LoadConfig[config.StorageMigrationConfig](nil)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	lMigration := helpers.SetupLogger(conf.Logs.Level, "Storage Migration", "Migration")
+	lSource := helpers.SetupLogger(conf.Source.LogLevel, "Storage Migration", "Source Storage")
+	lTarget := helpers.SetupLogger(conf.Target.LogLevel, "Storage Migration", "Target Storage")
+
+	source, err := builder.BuildStorageEngine(lSource, conf.Source)
+	if err != nil {
+		log.Fatalf("could not create source storage engine: %s", err)
+	}
+
+	target, err := builder.BuildStorageEngine(lTarget, conf.Target)
+	if err != nil {
+		log.Fatalf("could not create target storage engine: %s", err)
+	}
+
+	checkpoint, err := migration.LoadCheckpoint(*checkpointFile)
+	if err != nil {
+		log.Fatalf("could not load checkpoint %s: %s", *checkpointFile, err)
+	}
+
+	migrator := migration.NewMigrator(source, target, checkpoint, *dryRun, lMigration)
+	if !*skipBackfill {
+		backfill(migrator)
+	}
+
+	if !*verify || *dryRun {
+		return
+	}
+
+	report := verifyStorage(source, target, *reportFile)
+	if !report.Consistent && *resync {
+		for asset, verification := range report.Assets {
+			err = checkpoint.Forget(asset, append(verification.Missing, verification.Mismatched...))
+			if err != nil {
+				log.Fatalf("could not update checkpoint %s: %s", *checkpointFile, err)
+			}
+		}
+
+		log.Infof("copying again the missing and mismatched records")
+		backfill(migrator)
+		report = verifyStorage(source, target, *reportFile)
+	}
+
+	if !report.Consistent {
+		log.Fatalf("source and target storage engines are not consistent. See %s", *reportFile)
+	}
+
+	log.Infof("source and target storage engines are consistent")
+}
+
+func backfill(migrator *migration.Migrator) {
+	summary, err := migrator.Run(context.Background())
+	for asset, assetSummary := range summary {
+		log.Infof("%s: migrated=%d skipped=%d failed=%d", asset, assetSummary.Migrated, assetSummary.Skipped, assetSummary.Failed)
+	}
+	if err != nil {
+		log.Fatalf("migration aborted: %s", err)
+	}
+}
+
+func verifyStorage(source, target storage.StorageEngine, reportFile string) *migration.VerificationReport {
+	report, err := migration.Verify(context.Background(), source, target)
+	if err != nil {
+		log.Fatalf("could not verify storage engines: %s", err)
+	}
+
+	for asset, verification := range report.Assets {
+		log.Infof("%s: source=%d target=%d missing=%d unexpected=%d mismatched=%d", asset, verification.SourceCount, verification.TargetCount,
+			len(verification.Missing), len(verification.Unexpected), len(verification.Mismatched))
+	}
+
+	content, err := json.MarshalIndent(report, "", "  ")
+	if err != nil {
+		log.Fatalf("could not encode verification report: %s", err)
+	}
+
+	err = os.WriteFile(reportFile, content, 0600)
+	if err != nil {
+		log.Fatalf("could not write verification report %s: %s", reportFile, err)
+	}
+
+	return report
+}
@@ -0,0 +1,201 @@
+package assemblers
+
+import (
+	"context"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"golang.org/x/crypto/acme"
+)
+
+func TestACMEOrder(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	caDur := models.TimeDuration(time.Hour * 24 * 365)
+	issuanceDur := models.TimeDuration(time.Hour * 24)
+	enrollCA, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+		KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+		Subject:            models.Subject{CommonName: "acme-enroll"},
+		CAExpiration:       models.Expiration{Type: models.Duration, Duration: &caDur},
+		IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuanceDur},
+		Metadata:           map[string]any{},
+	})
+	if err != nil {
+		t.Fatalf("could not create Enrollment CA: %s", err)
+	}
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:       uuid.NewString(),
+		Name:     "acme-dms",
+		Metadata: map[string]any{},
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.ACME,
+				EnrollmentCA:       enrollCA.ID,
+				RegistrationMode:   models.JITP,
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Metadata: map[string]any{},
+					Tags:     []string{},
+				},
+			},
+			CADistributionSettings: models.CADistributionSettings{
+				ManagedCAs: []string{},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	accountKey, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	acmeCli := &acme.Client{
+		Key:          accountKey,
+		DirectoryURL: fmt.Sprintf("https://localhost:%d/v1/acme/%s/directory", dmsMgr.Port, dms.ID),
+		HTTPClient: &http.Client{
+			Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}},
+		},
+	}
+
+	_, err = acmeCli.Register(ctx, &acme.Account{}, acme.AcceptTOS)
+	if err != nil {
+		t.Fatalf("could not register ACME account: %s", err)
+	}
+
+	// Serve the http-01 challenge responses of the tests
+	challenges := sync.Map{}
+	listener, err := net.Listen("tcp", fmt.Sprintf("127.0.0.1:%d", dmsMgr.ACMEHTTP01Port))
+	if err != nil {
+		t.Fatalf("could not listen for http-01 challenges: %s", err)
+	}
+	challengeSrv := &http.Server{Handler: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		keyAuth, ok := challenges.Load(r.URL.Path)
+		if !ok {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		w.Write([]byte(keyAuth.(string)))
+	})}
+	go challengeSrv.Serve(listener)
+	t.Cleanup(func() { challengeSrv.Close() })
+
+	authorizeOrder := func(serveChallenge bool) (*acme.Order, error) {
+		order, err := acmeCli.AuthorizeOrder(ctx, acme.DomainIDs("localhost"))
+		if err != nil {
+			return nil, err
+		}
+
+		authz, err := acmeCli.GetAuthorization(ctx, order.AuthzURLs[0])
+		if err != nil {
+			return nil, err
+		}
+
+		for _, chal := range authz.Challenges {
+			if chal.Type != "http-01" {
+				continue
+			}
+
+			if serveChallenge {
+				keyAuth, _ := acmeCli.HTTP01ChallengeResponse(chal.Token)
+				challenges.Store(acmeCli.HTTP01ChallengePath(chal.Token), keyAuth)
+			}
+
+			_, err = acmeCli.Accept(ctx, chal)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		return acmeCli.WaitOrder(ctx, order.URI)
+	}
+
+	t.Run("OK", func(t *testing.T) {
+		order, err := authorizeOrder(true)
+		if err != nil {
+			t.Fatalf("unexpected error while authorizing order: %s", err)
+		}
+
+		if order.Status != acme.StatusReady {
+			t.Fatalf("unexpected order status: %s", order.Status)
+		}
+
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"localhost"}}, key)
+
+		ders, _, err := acmeCli.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+		if err != nil {
+			t.Fatalf("unexpected error while finalizing order: %s", err)
+		}
+
+		leaf, err := x509.ParseCertificate(ders[0])
+		if err != nil {
+			t.Fatalf("could not parse issued certificate: %s", err)
+		}
+
+		if len(leaf.DNSNames) != 1 || leaf.DNSNames[0] != "localhost" {
+			t.Fatalf("unexpected certificate DNS names: %v", leaf.DNSNames)
+		}
+
+		err = leaf.CheckSignatureFrom((*x509.Certificate)(enrollCA.Certificate.Certificate))
+		if err != nil {
+			t.Fatalf("certificate not signed by the enrollment CA: %s", err)
+		}
+
+		err = acmeCli.RevokeCert(ctx, nil, ders[0], acme.CRLReasonKeyCompromise)
+		if err != nil {
+			t.Fatalf("unexpected error while revoking certificate: %s", err)
+		}
+
+		crt, err := testServers.CA.Service.GetCertificateBySerialNumber(ctx, services.GetCertificatesBySerialNumberInput{
+			SerialNumber: helpers.SerialNumberToString(leaf.SerialNumber),
+		})
+		if err != nil {
+			t.Fatalf("could not get certificate: %s", err)
+		}
+
+		if crt.Status != models.StatusRevoked {
+			t.Fatalf("certificate should be revoked, got %s", crt.Status)
+		}
+	})
+
+	t.Run("Err/ChallengeNotServed", func(t *testing.T) {
+		_, err := authorizeOrder(false)
+		var orderErr *acme.OrderError
+		if !errors.As(err, &orderErr) || orderErr.Status != acme.StatusInvalid {
+			t.Fatalf("expected invalid order error, got: %v", err)
+		}
+	})
+
+	t.Run("Err/FinalizeWithOtherIdentifiers", func(t *testing.T) {
+		order, err := authorizeOrder(true)
+		if err != nil {
+			t.Fatalf("unexpected error while authorizing order: %s", err)
+		}
+
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{DNSNames: []string{"localhost", "lamassu.io"}}, key)
+
+		_, _, err = acmeCli.CreateOrderCert(ctx, order.FinalizeURL, csr, true)
+		var acmeErr *acme.Error
+		if !errors.As(err, &acmeErr) || acmeErr.ProblemType != "urn:ietf:params:acme:error:badCSR" {
+			t.Fatalf("expected badCSR error, got: %v", err)
+		}
+	})
+}
@@ -3,9 +3,11 @@ package assemblers
 import (
 	"fmt"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/clients"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/routes"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
@@ -23,10 +25,15 @@ func AssembleAlertsServiceWithHTTPServer(conf config.AlertsConfig, serviceInfo m
 
 	lHttp := helpers.SetupLogger(conf.Server.LogLevel, "Alerts", "HTTP Server")
 
-	httpEngine := routes.NewGinEngine(lHttp)
-	httpGrp := httpEngine.Group("/")
+	routers, err := routes.NewHttpRouters(lHttp, conf.Server)
+	if err != nil {
+		return nil, -1, fmt.Errorf("could not create Alerts http routers: %s", err)
+	}
+
+	httpGrp := routers.Management
 	routes.NewAlertsHTTPLayer(httpGrp, *service)
-	port, err := routes.RunHttpRouter(lHttp, httpEngine, conf.Server, serviceInfo)
+	routes.NewLogLevelsHTTPLayer(httpGrp, "Alerts")
+	port, err := routes.RunHttpRouters(lHttp, routers, conf.Server, serviceInfo)
 	if err != nil {
 		return nil, -1, fmt.Errorf("could not run Alerts http server: %s", err)
 	}
@@ -45,9 +52,10 @@ func AssembleAlertsService(conf config.AlertsConfig) (*services.AlertsService, e
 	}
 
 	svc := services.NewAlertsService(services.AlertsServiceBuilder{
-		Logger:       lSvc,
-		SubsStorage:  subStorage,
-		EventStorage: eventStore,
+		Logger:           lSvc,
+		SubsStorage:      subStorage,
+		EventStorage:     eventStore,
+		SmtpServerConfig: conf.SMTPConfig,
 	})
 
 	if conf.SubscriberEventBus.Enabled {
@@ -61,11 +69,34 @@ func AssembleAlertsService(conf config.AlertsConfig) (*services.AlertsService, e
 		subHandler.RunAsync()
 	}
 
+	if conf.ExpirationDigest.Enabled {
+		lDigest := helpers.SetupLogger(conf.Logs.Level, "Alerts", "Expiration Digest")
+		lDigest.Infof("Expiration digest is enabled")
+
+		caHttpCli, err := clients.BuildHTTPClient(conf.ExpirationDigest.CAClient.HTTPClient, lDigest)
+		if err != nil {
+			return nil, fmt.Errorf("could not build HTTP CA Client: %s", err)
+		}
+
+		caSDK := clients.NewHttpCAClient(
+			clients.HttpClientWithSourceHeaderInjector(caHttpCli, models.AlertsSource),
+			clients.BuildURL(conf.ExpirationDigest.CAClient.HTTPClient),
+		)
+
+		digesterJob, err := jobs.NewExpirationDigester(caSDK, svc, conf.ExpirationDigest.Windows, lDigest)
+		if err != nil {
+			return nil, fmt.Errorf("could not create expiration digester: %s", err)
+		}
+
+		scheduler := jobs.NewJobScheduler(conf.ExpirationDigest.CryptoMonitoring, lDigest, digesterJob)
+		scheduler.Start()
+	}
+
 	return &svc, nil
 }
 
 func createAlertsStorageInstance(logger *log.Entry, conf config.PluggableStorageEngine) (storage.SubscriptionsRepository, storage.EventRepository, error) {
-	engine, err := builder.BuildStorageEngine(logger, conf)
+	engine, err := builder.BuildAndMigrateStorageEngine(logger, conf)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not create storage engine: %s", err)
 	}
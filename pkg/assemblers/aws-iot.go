@@ -7,8 +7,10 @@ import (
 	"github.com/aws/aws-sdk-go-v2/aws"
 	"github.com/aws/aws-sdk-go-v2/service/sqs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services/handlers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services/iot"
@@ -43,6 +45,18 @@ func AssembleAWSIoTManagerService(conf config.IotAWS, caService services.CAServi
 	}
 	subHandler.RunAsync()
 
+	if conf.ExpiryTwinNotification.Enabled {
+		notifier := jobs.NewExpiryTwinNotifier(deviceService, caService, awsConnectorSvc, conf.ExpiryTwinNotification.NotificationDays, lSvc)
+		scheduler := jobs.NewJobScheduler(conf.ExpiryTwinNotification.CryptoMonitoring, lSvc, notifier)
+		scheduler.Start()
+	}
+
+	if conf.CertificateReconciliation.Enabled {
+		reconciler := connectors.NewCertificateStatusReconciler(conf.ConnectorID, awsConnectorSvc, caService, nil, lSvc)
+		scheduler := jobs.NewJobScheduler(conf.CertificateReconciliation, lSvc, reconciler)
+		scheduler.Start()
+	}
+
 	go func() {
 		lSvc.Infof("starting SQS thread")
 		sqsQueueName := fmt.Sprintf("https://sqs.%s.amazonaws.com/%s/%s", awsConnectorSvc.Region, awsConnectorSvc.AccountID, conf.AWSBidirectionalQueueName)
@@ -1,15 +1,24 @@
 package assemblers
 
 import (
+	"context"
 	"fmt"
+	"net/http"
+	"time"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/chaos"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/clients"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/debugtrace"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/featureflags"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/monitoring"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/routes"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
@@ -19,31 +28,94 @@ import (
 )
 
 func AssembleCAServiceWithHTTPServer(conf config.CAConfig, serviceInfo models.APIServiceInfo) (*services.CAService, *jobs.JobScheduler, int, error) {
-	caService, scheduler, err := AssembleCAService(conf)
+	flags := featureflags.NewFeatureFlags(featureflags.CAFlags, conf.FeatureFlags, helpers.SetupLogger(conf.Logs.Level, "CA", "Feature Flags"))
+	lHealth := helpers.SetupLogger(conf.Logs.Level, "CA", "Dependency Monitoring")
+	monitor, err := newDependencyMonitor("ca", conf.DependencyMonitoring, lHealth)
+	if err != nil {
+		return nil, nil, -1, err
+	}
+
+	caService, scheduler, err := assembleCAService(conf, flags, monitor)
 	if err != nil {
 		return nil, nil, -1, fmt.Errorf("could not assemble CA Service. Exiting: %s", err)
 	}
 
 	lHttp := helpers.SetupLogger(conf.Server.LogLevel, "CA", "HTTP Server")
 
-	httpEngine := routes.NewGinEngine(lHttp)
-	httpGrp := httpEngine.Group("/")
-	routes.NewCAHTTPLayer(httpGrp, *caService)
-	port, err := routes.RunHttpRouter(lHttp, httpEngine, conf.Server, serviceInfo)
+	routers, err := routes.NewHttpRouters(lHttp, conf.Server)
+	if err != nil {
+		return nil, nil, -1, fmt.Errorf("could not create CA Service http routers: %s", err)
+	}
+
+	httpGrp := routers.Management
+	if conf.DebugTrace.Enabled {
+		routers.Use(debugtrace.HTTPMiddleware(debugtrace.Default(), "ca"))
+		routes.NewDebugTraceHTTPLayer(httpGrp, debugtrace.Default())
+	}
+	routes.NewCAHTTPLayer(httpGrp, *caService, conf.CSRLimits)
+	routes.NewCAMonitoringHTTPLayer(httpGrp, *caService, conf)
+
+	crlValidity := time.Duration(0)
+	if conf.CRL.Validity != "" {
+		crlValidity, err = models.ParseDuration(conf.CRL.Validity)
+		if err != nil {
+			return nil, nil, -1, fmt.Errorf("could not parse CRL validity '%s': %s", conf.CRL.Validity, err)
+		}
+	}
+
+	routes.NewCRLHTTPLayer(routers.DataPlane, services.NewCRLService(services.CRLServiceBuilder{
+		Logger:   helpers.SetupLogger(conf.Logs.Level, "CA", "CRL"),
+		CAClient: *caService,
+		Validity: crlValidity,
+	}))
+	if conf.InternalCertificates.Enabled {
+		issuer, err := helpers.LoadInternalCertificateIssuer(conf.InternalCertificates)
+		if err != nil {
+			return nil, nil, -1, fmt.Errorf("could not create internal certificate issuer: %s", err)
+		}
+
+		// served by the data-plane router, as the services request their first certificate before they can authenticate
+		routes.NewInternalCertificateHTTPLayer(routers.DataPlane, services.NewInternalCertificateService(services.InternalCertificateServiceBuilder{
+			Logger:   helpers.SetupLogger(conf.Logs.Level, "CA", "Internal Certificates"),
+			Issuer:   issuer,
+			Services: conf.InternalCertificates.Services,
+		}))
+	}
+
+	routes.NewFeatureFlagsHTTPLayer(httpGrp, flags)
+	routes.NewStatusHTTPLayer(httpGrp, monitor)
+	routes.NewLogLevelsHTTPLayer(httpGrp, "CA")
+	if conf.CryptoEngines.HealthCheck.Enabled {
+		routers.AddHealthDetails("crypto_engines", cryptoEnginesHealth(*caService))
+	}
+
+	port, err := routes.RunHttpRouters(lHttp, routers, conf.Server, serviceInfo)
 	if err != nil {
 		return nil, nil, -1, fmt.Errorf("could not run CA Service http server: %s", err)
 	}
 
+	startDependencyMonitoring(monitor, conf.DependencyMonitoring, lHealth)
+
 	return caService, scheduler, port, nil
 }
 
 func AssembleCAService(conf config.CAConfig) (*services.CAService, *jobs.JobScheduler, error) {
+	flags := featureflags.NewFeatureFlags(featureflags.CAFlags, conf.FeatureFlags, helpers.SetupLogger(conf.Logs.Level, "CA", "Feature Flags"))
+	return assembleCAService(conf, flags, health.NewMonitor("ca", 0, helpers.SetupLogger(conf.Logs.Level, "CA", "Dependency Monitoring")))
+}
+
+// assembleCAService registers the storage, crypto engines and event bus dependencies of the service in the monitor.
+func assembleCAService(conf config.CAConfig, flags *featureflags.FeatureFlags, monitor *health.Monitor) (*services.CAService, *jobs.JobScheduler, error) {
 	lSvc := helpers.SetupLogger(conf.Logs.Level, "CA", "Service")
 	lMessage := helpers.SetupLogger(conf.PublisherEventBus.LogLevel, "CA", "Event Bus")
 	lStorage := helpers.SetupLogger(conf.Storage.LogLevel, "CA", "Storage")
 	lCryptoEng := helpers.SetupLogger(conf.CryptoEngines.LogLevel, "CA", "CryptoEngine")
 	lMonitor := helpers.SetupLogger(conf.Logs.Level, "CA", "Crypto Monitoring")
 
+	if conf.DestructiveOperationsApproval.Enabled && !conf.Server.Authentication.OIDC.Enabled {
+		return nil, nil, fmt.Errorf("destructive operations approval requires the OIDC authentication of the server to verify the identity of the administrators")
+	}
+
 	engines, err := createCryptoEngines(lCryptoEng, conf)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not create crypto engines: %s", err)
@@ -58,18 +130,80 @@ func AssembleCAService(conf config.CAConfig) (*services.CAService, *jobs.JobSche
 		logEntry.Infof("loaded %s engine with id %s", engine.Service.GetEngineConfig().Type, engineID)
 	}
 
-	caStorage, certStorage, err := createCAStorageInstance(lStorage, conf.Storage)
+	supervisedEngines := map[string]*cryptoengines.SupervisedEngine{}
+	for engineID, engine := range engines {
+		if supervised, ok := engine.Service.(*cryptoengines.SupervisedEngine); ok {
+			supervisedEngines[engineID] = supervised
+		}
+	}
+
+	if conf.FaultInjection.Enabled {
+		for engineID, engine := range engines {
+			injector, err := chaos.NewInjector(fmt.Sprintf("crypto engine %s", engineID), conf.FaultInjection.CryptoEngines, lCryptoEng)
+			if err != nil {
+				return nil, nil, err
+			}
+			engine.Service = chaos.NewCryptoEngine(engine.Service, injector)
+		}
+	}
+
+	caStorage, certStorage, certProfileStorage, issuanceLogStorage, caEventsStorage, signingRequestStorage, pendingActionStorage, err := createCAStorageInstance(lStorage, conf.Storage, conf.FaultInjection, conf.IssuanceLog)
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not create CA storage instance: %s", err)
 	}
 
+	if conf.DebugTrace.Enabled {
+		caStorage = debugtrace.NewCACertificatesRepo(caStorage, debugtrace.Default(), "ca")
+		certStorage = debugtrace.NewCertificatesRepo(certStorage, debugtrace.Default(), "ca")
+	}
+
+	monitor.Register("storage", models.DependencyKindStorage, true, health.StorageCheck(caStorage))
+	for engineID, engine := range engines {
+		monitor.Register(fmt.Sprintf("crypto-engine/%s", engineID), models.DependencyKindCryptoEngine, true, health.CryptoEngineCheck(engine.Service, caEngineKeyID(caStorage, engineID)))
+	}
+
+	issuanceWebhookTimeout := 10 * time.Second
+	if conf.IssuanceWebhooks.Timeout != "" {
+		issuanceWebhookTimeout, err = models.ParseDuration(conf.IssuanceWebhooks.Timeout)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not parse issuance webhooks timeout '%s': %s", conf.IssuanceWebhooks.Timeout, err)
+		}
+	}
+
+	issuanceWebhookClient, err := helpers.BuildHTTPClientWithTLSOptions(&http.Client{Timeout: issuanceWebhookTimeout}, conf.IssuanceWebhooks.TLSConfig)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not build issuance webhooks HTTP client: %s", err)
+	}
+
+	hybridSigners := map[string]x509engines.HybridSigner{}
+	for _, signerConf := range conf.HybridSigners {
+		signerCli, err := clients.BuildHTTPClient(signerConf.HTTPClient, lCryptoEng)
+		if err != nil {
+			return nil, nil, fmt.Errorf("could not build HTTP client for hybrid signer %s: %s", signerConf.ID, err)
+		}
+
+		hybridSigners[signerConf.ID] = clients.NewHttpHybridSigner(signerCli, clients.BuildURL(signerConf.HTTPClient))
+		lCryptoEng.Infof("loaded hybrid signer with id %s", signerConf.ID)
+	}
+
 	svc, err := services.NewCAService(services.CAServiceBuilder{
-		Logger:               lSvc,
-		CryptoEngines:        engines,
-		CAStorage:            caStorage,
-		CertificateStorage:   certStorage,
-		CryptoMonitoringConf: conf.CryptoMonitoring,
-		VAServerDomain:       conf.VAServerDomain,
+		Logger:                    lSvc,
+		CryptoEngines:             engines,
+		CAStorage:                 caStorage,
+		CertificateStorage:        certStorage,
+		CertificateProfileStorage: certProfileStorage,
+		IssuanceLogStorage:        issuanceLogStorage,
+		CAEventsStorage:           caEventsStorage,
+		SigningRequestStorage:     signingRequestStorage,
+		PendingActionStorage:      pendingActionStorage,
+		CryptoMonitoringConf:      conf.CryptoMonitoring,
+		VAServerDomain:            conf.VAServerDomain,
+		CRLDistributionPoints:     conf.CRL.DistributionPoints,
+		ApprovalConf:              conf.DestructiveOperationsApproval,
+		CSRLimits:                 conf.CSRLimits,
+		IssuanceWebhookClient:     issuanceWebhookClient,
+		HybridSigners:             hybridSigners,
+		BatchSigningWorkers:       conf.BatchSigningWorkers,
 	})
 	if err != nil {
 		return nil, nil, fmt.Errorf("could not create CA service: %v", err)
@@ -84,13 +218,43 @@ func AssembleCAService(conf config.CAConfig) (*services.CAService, *jobs.JobSche
 			return nil, nil, fmt.Errorf("could not create Event Bus publisher: %s", err)
 		}
 
-		eventpublisher := &eventpub.CloudEventMiddlewarePublisher{
+		if conf.FaultInjection.Enabled {
+			injector, err := chaos.NewInjector("event bus", conf.FaultInjection.EventBus, lMessage)
+			if err != nil {
+				return nil, nil, err
+			}
+			pub = chaos.NewPublisher(pub, injector)
+		}
+
+		var eventpublisher eventpub.ICloudEventMiddlewarePublisher = &eventpub.CloudEventMiddlewarePublisher{
 			Publisher: pub,
 			ServiceID: "ca",
 			Logger:    lMessage,
 		}
 
+		if conf.DebugTrace.Enabled {
+			eventpublisher = debugtrace.NewEventPublisher(eventpublisher, debugtrace.Default(), "ca")
+		}
+
 		svc = eventpub.NewCAEventBusPublisher(eventpublisher)(svc)
+
+		registerEventBusDependency(monitor, "publisher-event-bus", conf.PublisherEventBus)
+		monitor.SetPublisher(eventpublisher)
+	}
+
+	svc = monitoring.NewCAMetricsMiddleware()(svc)
+	svc = featureflags.NewCAFeatureFlagsMiddleware(flags)(svc)
+
+	if conf.DebugTrace.Enabled {
+		svc = debugtrace.NewCATraceMiddleware(debugtrace.Default())(svc)
+	}
+
+	if conf.CryptoEngines.HealthCheck.Enabled {
+		lEngineHealth := helpers.SetupLogger(conf.Logs.Level, "CA", "Crypto Engines Health Check")
+		log.Infof("Crypto engines health check is enabled")
+		healthJob := jobs.NewCryptoEngineHealthChecker(supervisedEngines, caEngineProbeKey(caStorage), lEngineHealth)
+		healthScheduler := jobs.NewJobScheduler(conf.CryptoEngines.HealthCheck, lEngineHealth, healthJob)
+		healthScheduler.Start()
 	}
 
 	var scheduler *jobs.JobScheduler
@@ -104,100 +268,146 @@ func AssembleCAService(conf config.CAConfig) (*services.CAService, *jobs.JobSche
 	//this utilizes the middlewares from within the CA service (if svc.Service.func is uses instead of regular svc.func)
 	caSvc.SetService(svc)
 
+	err = caSvc.ResumeCADeletions(context.Background())
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not resume CA deletions: %s", err)
+	}
+
 	return &svc, scheduler, nil
 }
 
-func createCAStorageInstance(logger *log.Entry, conf config.PluggableStorageEngine) (storage.CACertificatesRepo, storage.CertificatesRepo, error) {
-	engine, err := builder.BuildStorageEngine(logger, conf)
+func createCAStorageInstance(logger *log.Entry, conf config.PluggableStorageEngine, faults config.FaultInjection, issuanceLog config.IssuanceLog) (storage.CACertificatesRepo, storage.CertificatesRepo, storage.CertificateProfilesRepo, storage.IssuanceLogRepo, storage.CAEventsRepo, storage.CASigningRequestsRepo, storage.CAPendingActionsRepo, error) {
+	engine, err := builder.BuildAndMigrateStorageEngine(logger, conf)
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not create storage engine: %s", err)
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not create storage engine: %s", err)
+	}
+
+	if faults.Enabled {
+		injector, err := chaos.NewInjector("storage", faults.Storage, logger)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, err
+		}
+		engine = chaos.NewStorageEngine(engine, injector)
 	}
 
 	caStorage, err := engine.GetCAStorage()
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not get CA storage: %s", err)
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get CA storage: %s", err)
 	}
 
 	certStorage, err := engine.GetCertstorage()
 	if err != nil {
-		return nil, nil, fmt.Errorf("could not get Cert storage: %s", err)
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get Cert storage: %s", err)
 	}
 
-	return caStorage, certStorage, nil
+	certProfileStorage, err := engine.GetCertificateProfileStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get Certificate Profile storage: %s", err)
+	}
+
+	var issuanceLogStorage storage.IssuanceLogRepo
+	if issuanceLog.Enabled {
+		issuanceLogStorage, err = engine.GetIssuanceLogStorage()
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get Issuance Log storage: %s", err)
+		}
+	}
+
+	caEventsStorage, err := engine.GetCAEventsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get CA Events storage: %s", err)
+	}
+
+	signingRequestStorage, err := engine.GetCASigningRequestsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get CA Signing Requests storage: %s", err)
+	}
+
+	pendingActionStorage, err := engine.GetCAPendingActionsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get CA Pending Actions storage: %s", err)
+	}
+
+	return caStorage, certStorage, certProfileStorage, issuanceLogStorage, caEventsStorage, signingRequestStorage, pendingActionStorage, nil
 }
 
 func createCryptoEngines(logger *log.Entry, conf config.CAConfig) (map[string]*services.Engine, error) {
 	x509engines.SetCryptoEngineLogger(logger) //Important!
 
 	engines := map[string]*services.Engine{}
-	for _, cfg := range conf.CryptoEngines.HashicorpVaultKV2Provider {
-		vaultEngine, err := cryptoengines.NewVaultKV2Engine(logger, cfg)
-		if err != nil {
-			log.Warnf("skipping Hashicorp Vault KV2 engine with id %s. could not create Vault engine: %s", cfg.ID, err)
+	addEngine := func(id string, engineType models.CryptoEngineType, name string, connect func() (cryptoengines.CryptoEngine, error)) {
+		var engine cryptoengines.CryptoEngine
+		if conf.CryptoEngines.HealthCheck.Enabled {
+			supervised := cryptoengines.NewSupervisedEngine(logger, id, models.CryptoEngineInfo{Type: engineType, Provider: name}, connect)
+			if !supervised.Available() {
+				log.Warnf("%s engine with id %s is unavailable. It will be reconnected by the crypto engines health check", name, id)
+			}
+			engine = supervised
 		} else {
-			engines[cfg.ID] = &services.Engine{
-				Default: cfg.ID == conf.CryptoEngines.DefaultEngine,
-				Service: vaultEngine,
+			var err error
+			engine, err = connect()
+			if err != nil {
+				log.Warnf("skipping %s engine with id %s. could not create engine: %s", name, id, err)
+				return
 			}
 		}
-	}
 
-	for _, cfg := range conf.CryptoEngines.AWSKMSProvider {
-		awsCfg, err := config.GetAwsSdkConfig(cfg.AWSSDKConfig)
-		if err != nil {
-			log.Warnf("skipping AWS KMS engine with id %s: %s", cfg.ID, err)
-			continue
+		engines[id] = &services.Engine{
+			Default: id == conf.CryptoEngines.DefaultEngine,
+			Service: engine,
 		}
+	}
 
-		awsEngine, err := cryptoengines.NewAWSKMSEngine(logger, *awsCfg, cfg.Metadata)
-		if err != nil {
-			log.Warnf("skipping AWS KMS engine with id %s. could not create KMS engine: %s", cfg.ID, err)
-			continue
-		} else {
-			engines[cfg.ID] = &services.Engine{
-				Default: cfg.ID == conf.CryptoEngines.DefaultEngine,
-				Service: awsEngine,
+	for _, cfg := range conf.CryptoEngines.HashicorpVaultKV2Provider {
+		cfg := cfg
+		addEngine(cfg.ID, models.VaultKV2, "Hashicorp Vault KV2", func() (cryptoengines.CryptoEngine, error) {
+			return cryptoengines.NewVaultKV2Engine(logger, cfg)
+		})
+	}
+
+	for _, cfg := range conf.CryptoEngines.AWSKMSProvider {
+		cfg := cfg
+		addEngine(cfg.ID, models.AWSKMS, "AWS KMS", func() (cryptoengines.CryptoEngine, error) {
+			awsCfg, err := config.GetAwsSdkConfig(cfg.AWSSDKConfig)
+			if err != nil {
+				return nil, err
 			}
-		}
+
+			return cryptoengines.NewAWSKMSMultiRegionEngine(logger, *awsCfg, cfg.Metadata, cfg.MultiRegion, cfg.ReplicaRegions)
+		})
 	}
 
 	for _, cfg := range conf.CryptoEngines.AWSSecretsManagerProvider {
-		awsCfg, err := config.GetAwsSdkConfig(cfg.AWSSDKConfig)
-		if err != nil {
-			log.Warnf("skipping AWS Secrets Manager engine with id %s: %s", cfg.ID, err)
-			continue
-		}
-
-		awsEngine, err := cryptoengines.NewAWSSecretManagerEngine(logger, *awsCfg, cfg.Metadata)
-		if err != nil {
-			log.Warnf("skipping AWS Secrets Manager with id %s. could not create Secrets Manager engine: %s", cfg.ID, err)
-			continue
-		} else {
-			engines[cfg.ID] = &services.Engine{
-				Default: cfg.ID == conf.CryptoEngines.DefaultEngine,
-				Service: awsEngine,
+		cfg := cfg
+		addEngine(cfg.ID, models.AWSSecretsManager, "AWS Secrets Manager", func() (cryptoengines.CryptoEngine, error) {
+			awsCfg, err := config.GetAwsSdkConfig(cfg.AWSSDKConfig)
+			if err != nil {
+				return nil, err
 			}
-		}
+
+			return cryptoengines.NewAWSSecretManagerEngine(logger, *awsCfg, cfg.Metadata)
+		})
+	}
+
+	for _, cfg := range conf.CryptoEngines.GCPKMSProvider {
+		cfg := cfg
+		addEngine(cfg.ID, models.GCPKMS, "GCP KMS", func() (cryptoengines.CryptoEngine, error) {
+			return cryptoengines.NewGCPKMSEngine(logger, cfg)
+		})
 	}
 
 	for _, cfg := range conf.CryptoEngines.GolangProvider {
-		engine := cryptoengines.NewGolangPEMEngine(logger, cfg)
-		engines[cfg.ID] = &services.Engine{
-			Default: cfg.ID == conf.CryptoEngines.DefaultEngine,
-			Service: engine,
-		}
+		cfg := cfg
+		addEngine(cfg.ID, models.Golang, "Golang", func() (cryptoengines.CryptoEngine, error) {
+			return cryptoengines.NewGolangPEMEngine(logger, cfg)
+		})
 	}
 
 	for _, cfg := range conf.CryptoEngines.PKCS11Provider {
-		engine, err := cryptoengines.NewPKCS11Engine(logger, cfg)
-		if err != nil {
-			log.Warnf("skipping PKCS11 provider with id %s. could not create PKCS11 engine: %s", cfg.ID, err)
-		} else {
-			engines[cfg.ID] = &services.Engine{
-				Default: cfg.ID == conf.CryptoEngines.DefaultEngine,
-				Service: engine,
-			}
-		}
+		cfg := cfg
+		addEngine(cfg.ID, models.PKCS11, "PKCS11", func() (cryptoengines.CryptoEngine, error) {
+			return cryptoengines.NewPKCS11Engine(logger, cfg)
+		})
 	}
 
 	return engines, nil
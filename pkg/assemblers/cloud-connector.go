@@ -0,0 +1,223 @@
+package assemblers
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/routes"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/builder"
+	"github.com/sirupsen/logrus"
+)
+
+// AssembleCloudConnectorWithHTTPServer assembles the connector with AssembleCloudConnector. If the connector
+// implements connectors.CertificateStatusRegistry, the certificate status reconciliation is scheduled and
+// exposed through the HTTP server. Certificates are revoked in Lamassu with caService. If the retry queue is
+// enabled, its pending events are exposed through the HTTP server too.
+func AssembleCloudConnectorWithHTTPServer(conf config.CloudConnector, connector connectors.Connector, caService services.CAService, serviceInfo models.APIServiceInfo) (int, error) {
+	pub, queue, err := assembleCloudConnector(conf, connector, serviceInfo.Version)
+	if err != nil {
+		return -1, err
+	}
+
+	lSvc := helpers.SetupLogger(conf.Logs.Level, "Cloud Connector", connector.ID())
+	lHttp := helpers.SetupLogger(conf.Server.LogLevel, "Cloud Connector", "HTTP Server")
+
+	routers, err := routes.NewHttpRouters(lHttp, conf.Server)
+	if err != nil {
+		return -1, fmt.Errorf("could not create Cloud Connector http routers: %s", err)
+	}
+
+	httpGrp := routers.Management
+
+	lHealth := helpers.SetupLogger(conf.Logs.Level, "Cloud Connector", "Dependency Monitoring")
+	monitor, err := newDependencyMonitor(fmt.Sprintf("%s-connector-%s", connector.Provider(), connector.ID()), conf.DependencyMonitoring, lHealth)
+	if err != nil {
+		return -1, err
+	}
+
+	monitor.Register(connector.ID(), models.DependencyKindConnector, true, health.ConnectorHealthCheck(connector.Health))
+	registerEventBusDependency(monitor, "subscriber-event-bus", conf.SubscriberEventBus)
+	registerEventBusDependency(monitor, "publisher-event-bus", conf.PublisherEventBus)
+	if pub != nil {
+		monitor.SetPublisher(pub)
+	}
+	routes.NewStatusHTTPLayer(httpGrp, monitor)
+
+	if registry, ok := connector.(connectors.CertificateStatusRegistry); ok {
+		reconciler := connectors.NewCertificateStatusReconciler(connector.ID(), registry, caService, pub, lSvc)
+		if conf.CertificateReconciliation.Enabled {
+			scheduler := jobs.NewJobScheduler(conf.CertificateReconciliation, lSvc, reconciler)
+			scheduler.Start()
+		}
+
+		routes.NewReconciliationHTTPLayer(httpGrp, reconciler)
+	}
+
+	if queue != nil {
+		routes.NewConnectorPendingEventsHTTPLayer(httpGrp, connector.ID(), queue)
+	}
+
+	routes.NewLogLevelsHTTPLayer(httpGrp, "Cloud Connector")
+	port, err := routes.RunHttpRouters(lHttp, routers, conf.Server, serviceInfo)
+	if err != nil {
+		return -1, fmt.Errorf("could not run Cloud Connector http server: %s", err)
+	}
+
+	startDependencyMonitoring(monitor, conf.DependencyMonitoring, lHealth)
+
+	return port, nil
+}
+
+// AssembleCloudConnector subscribes a connector built with the connectors SDK to the event bus. Only the event
+// types returned by connectors.SubscribedEventTypes are bound to the connector. If the publisher event bus is
+// enabled, the connector is registered and its health is reported periodically.
+func AssembleCloudConnector(conf config.CloudConnector, connector connectors.Connector, version string) error {
+	_, _, err := assembleCloudConnector(conf, connector, version)
+	return err
+}
+
+// assembleCloudConnector returns the publisher used by the connector, nil if the publisher event bus is disabled, and
+// the retry queue, nil if disabled.
+func assembleCloudConnector(conf config.CloudConnector, connector connectors.Connector, version string) (eventpub.ICloudEventMiddlewarePublisher, *connectors.RetryQueue, error) {
+	serviceID := fmt.Sprintf("%s-connector-%s", connector.Provider(), connector.ID())
+	lSvc := helpers.SetupLogger(conf.Logs.Level, "Cloud Connector", connector.ID())
+	lMessaging := helpers.SetupLogger(conf.SubscriberEventBus.LogLevel, "Cloud Connector", "Event Bus")
+
+	if conf.Credentials.Reference != "" {
+		err := assembleConnectorCredentials(conf.Credentials, connector, lSvc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	var queue *connectors.RetryQueue
+	if conf.RetryQueue.Enabled {
+		var err error
+		queue, err = assembleConnectorRetryQueue(conf.RetryQueue, connector, lSvc)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	topics := []string{}
+	for _, eventType := range connectors.SubscribedEventTypes(connector) {
+		topics = append(topics, string(eventType))
+	}
+
+	handler := connectors.NewEventHandler(lMessaging, connector)
+	if queue != nil {
+		handler = connectors.NewRetryingEventHandler(lMessaging, connector, queue)
+	}
+
+	subHandler, err := eventbus.NewEventBusTopicsSubscriptionHandler(conf.SubscriberEventBus, serviceID, lMessaging, *handler, serviceID, topics)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not generate Event Bus Subscription Handler: %s", err)
+	}
+	subHandler.RunAsync()
+
+	if !conf.PublisherEventBus.Enabled {
+		return nil, queue, nil
+	}
+
+	lPub := helpers.SetupLogger(conf.PublisherEventBus.LogLevel, "Cloud Connector", "Event Bus Publisher")
+	pub, err := eventbus.NewEventBusPublisher(conf.PublisherEventBus, serviceID, lPub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create Event Bus publisher: %s", err)
+	}
+
+	cloudEventPub := &eventpub.CloudEventMiddlewarePublisher{
+		Publisher: pub,
+		ServiceID: serviceID,
+		Logger:    lPub,
+	}
+
+	reporter := connectors.NewHealthReporter(connector, cloudEventPub, lSvc)
+	reporter.Register(helpers.InitContext(), version)
+
+	scheduler := jobs.NewJobScheduler(conf.HealthReport, lSvc, reporter)
+	scheduler.Start()
+
+	return cloudEventPub, queue, nil
+}
+
+// assembleConnectorRetryQueue creates the retry queue of the connector and schedules the retries.
+func assembleConnectorRetryQueue(conf config.ConnectorRetryQueue, connector connectors.Connector, logger *logrus.Entry) (*connectors.RetryQueue, error) {
+	policy := connectors.RetryPolicy{
+		MaxAttempts:     10,
+		InitialInterval: 30 * time.Second,
+		MaxInterval:     time.Hour,
+	}
+
+	if conf.MaxAttempts > 0 {
+		policy.MaxAttempts = conf.MaxAttempts
+	}
+
+	var err error
+	if conf.InitialInterval != "" {
+		policy.InitialInterval, err = models.ParseDuration(conf.InitialInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse retry queue initial interval '%s': %s", conf.InitialInterval, err)
+		}
+	}
+
+	if conf.MaxInterval != "" {
+		policy.MaxInterval, err = models.ParseDuration(conf.MaxInterval)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse retry queue max interval '%s': %s", conf.MaxInterval, err)
+		}
+	}
+
+	engine, err := builder.BuildAndMigrateStorageEngine(logger, conf.Storage)
+	if err != nil {
+		return nil, fmt.Errorf("could not create storage engine: %s", err)
+	}
+
+	store, err := engine.GetConnectorPendingEventsStorage()
+	if err != nil {
+		return nil, fmt.Errorf("could not get connector pending events storage: %s", err)
+	}
+
+	queue := connectors.NewRetryQueue(connector, store, policy, logger)
+	scheduler := jobs.NewJobScheduler(conf.CryptoMonitoring, logger, queue)
+	scheduler.Start()
+
+	return queue, nil
+}
+
+// assembleConnectorCredentials injects the referenced credentials into the connector before it is subscribed to the
+// event bus, and schedules their rotation.
+func assembleConnectorCredentials(conf config.ConnectorCredentials, connector connectors.Connector, logger *logrus.Entry) error {
+	credConnector, ok := connector.(connectors.CredentialedConnector)
+	if !ok {
+		return fmt.Errorf("connector %s does not support credential references", connector.ID())
+	}
+
+	if conf.HashicorpVault == nil {
+		return fmt.Errorf("no credential store configured for connector %s", connector.ID())
+	}
+
+	store, err := connectors.NewVaultCredentialStore(logger, *conf.HashicorpVault)
+	if err != nil {
+		return fmt.Errorf("could not create credential store: %s", err)
+	}
+
+	injector := connectors.NewCredentialsInjector(credConnector, store, conf.Reference, logger)
+	err = injector.Inject(helpers.InitContext())
+	if err != nil {
+		return fmt.Errorf("could not inject connector credentials: %s", err)
+	}
+
+	scheduler := jobs.NewJobScheduler(conf.Rotation, logger, injector)
+	scheduler.Start()
+
+	return nil
+}
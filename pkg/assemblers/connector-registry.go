@@ -0,0 +1,42 @@
+package assemblers
+
+import (
+	"fmt"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+)
+
+// AssembleConnectorRegistry creates the registry the service (identified by serviceID) discovers the cloud
+// connectors with. The "events" registry is subscribed to the connector registration and health events.
+func AssembleConnectorRegistry(conf config.ConnectorRegistry, serviceID string) (connectors.ConnectorRegistry, error) {
+	switch conf.Type {
+	case config.StaticConnectorRegistry:
+		return connectors.NewStaticConnectorRegistry(conf.Static), nil
+	case config.KubernetesConnectorRegistry:
+		return connectors.NewKubernetesConnectorRegistry(conf.Kubernetes)
+	case config.ConsulConnectorRegistry:
+		return connectors.NewConsulConnectorRegistry(conf.Consul)
+	case config.EventsConnectorRegistry, "":
+		lMessaging := helpers.SetupLogger(conf.SubscriberEventBus.LogLevel, "Connector Registry", "Event Bus")
+		registry := connectors.NewEventConnectorRegistry()
+
+		topics := []string{}
+		for _, eventType := range registry.EventTypes() {
+			topics = append(topics, string(eventType))
+		}
+
+		handlerName := fmt.Sprintf("%s-connector-registry", serviceID)
+		subHandler, err := eventbus.NewEventBusTopicsSubscriptionHandler(conf.SubscriberEventBus, serviceID, lMessaging, *registry.NewEventHandler(lMessaging), handlerName, topics)
+		if err != nil {
+			return nil, fmt.Errorf("could not generate Event Bus Subscription Handler: %s", err)
+		}
+		subHandler.RunAsync()
+
+		return registry, nil
+	default:
+		return nil, fmt.Errorf("unsupported connector registry type %s", conf.Type)
+	}
+}
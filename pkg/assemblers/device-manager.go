@@ -2,10 +2,15 @@ package assemblers
 
 import (
 	"fmt"
+	"time"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/chaos"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/debugtrace"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/routes"
@@ -17,39 +22,92 @@ import (
 )
 
 func AssembleDeviceManagerServiceWithHTTPServer(conf config.DeviceManagerConfig, caService services.CAService, serviceInfo models.APIServiceInfo) (*services.DeviceManagerService, int, error) {
-	service, err := AssembleDeviceManagerService(conf, caService)
+	lHealth := helpers.SetupLogger(conf.Logs.Level, "Device Manager", "Dependency Monitoring")
+	monitor, err := newDependencyMonitor("device-manager", conf.DependencyMonitoring, lHealth)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	service, err := assembleDeviceManagerService(conf, caService, monitor)
 	if err != nil {
 		return nil, -1, fmt.Errorf("could not assemble Device Manager Service. Exiting: %s", err)
 	}
 
+	err = registerServiceClientDependency(monitor, "ca", conf.CAClient.HTTPClient, lHealth)
+	if err != nil {
+		return nil, -1, err
+	}
+
 	lHttp := helpers.SetupLogger(conf.Server.LogLevel, "Device Manager", "HTTP Server")
 
-	httpEngine := routes.NewGinEngine(lHttp)
-	httpGrp := httpEngine.Group("/")
+	routers, err := routes.NewHttpRouters(lHttp, conf.Server)
+	if err != nil {
+		return nil, -1, fmt.Errorf("could not create Device Manager http routers: %s", err)
+	}
+
+	httpGrp := routers.Management
+	if conf.DebugTrace.Enabled {
+		routers.Use(debugtrace.HTTPMiddleware(debugtrace.Default(), "device-manager"))
+		routes.NewDebugTraceHTTPLayer(httpGrp, debugtrace.Default())
+	}
 	routes.NewDeviceManagerHTTPLayer(httpGrp, *service)
-	port, err := routes.RunHttpRouter(lHttp, httpEngine, conf.Server, serviceInfo)
+	routes.NewStatusHTTPLayer(httpGrp, monitor)
+	routes.NewLogLevelsHTTPLayer(httpGrp, "Device Manager")
+	port, err := routes.RunHttpRouters(lHttp, routers, conf.Server, serviceInfo)
 	if err != nil {
 		return nil, -1, fmt.Errorf("could not run Device Manager http server: %s", err)
 	}
 
+	startDependencyMonitoring(monitor, conf.DependencyMonitoring, lHealth)
+
 	return service, port, nil
 }
 
 func AssembleDeviceManagerService(conf config.DeviceManagerConfig, caService services.CAService) (*services.DeviceManagerService, error) {
+	return assembleDeviceManagerService(conf, caService, health.NewMonitor("device-manager", 0, helpers.SetupLogger(conf.Logs.Level, "Device Manager", "Dependency Monitoring")))
+}
+
+// assembleDeviceManagerService registers the storage and event bus dependencies of the service in the monitor.
+func assembleDeviceManagerService(conf config.DeviceManagerConfig, caService services.CAService, monitor *health.Monitor) (*services.DeviceManagerService, error) {
 	serviceID := "device-manager"
 
 	lSvc := helpers.SetupLogger(conf.Logs.Level, "Device Manager", "Service")
 	lStorage := helpers.SetupLogger(conf.Storage.LogLevel, "Device Manager", "Storage")
 
-	devStorage, err := createDevicesStorageInstance(lStorage, conf.Storage)
+	devStorage, groupStorage, bulkActionStorage, logStorage, err := createDevicesStorageInstance(lStorage, conf.Storage, conf.FaultInjection)
 	if err != nil {
 		return nil, fmt.Errorf("could not create device storage: %s", err)
 	}
 
+	if conf.DebugTrace.Enabled {
+		devStorage = debugtrace.NewDeviceManagerRepo(devStorage, debugtrace.Default(), serviceID)
+	}
+
+	monitor.Register("storage", models.DependencyKindStorage, true, health.StorageCheck(devStorage))
+
+	bulkRevocationApprovalWindow := time.Duration(0)
+	if conf.DestructiveOperationsApproval.Enabled {
+		if !conf.Server.Authentication.OIDC.Enabled {
+			return nil, fmt.Errorf("destructive operations approval requires the OIDC authentication of the server to verify the identity of the administrators")
+		}
+
+		bulkRevocationApprovalWindow = time.Hour
+		if conf.DestructiveOperationsApproval.ApprovalWindow != "" {
+			bulkRevocationApprovalWindow, err = models.ParseDuration(conf.DestructiveOperationsApproval.ApprovalWindow)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse destructive operations approval window '%s': %s", conf.DestructiveOperationsApproval.ApprovalWindow, err)
+			}
+		}
+	}
+
 	svc := services.NewDeviceManagerService(services.DeviceManagerBuilder{
-		Logger:         lSvc,
-		DevicesStorage: devStorage,
-		CAClient:       caService,
+		Logger:                        lSvc,
+		DevicesStorage:                devStorage,
+		DeviceGroupsStorage:           groupStorage,
+		DeviceGroupBulkActionsStorage: bulkActionStorage,
+		DeviceLogsStorage:             logStorage,
+		CAClient:                      caService,
+		BulkRevocationApprovalWindow:  bulkRevocationApprovalWindow,
 	})
 
 	deviceSvc := svc.(*services.DeviceManagerServiceBackend)
@@ -62,16 +120,76 @@ func AssembleDeviceManagerService(conf config.DeviceManagerConfig, caService ser
 			return nil, fmt.Errorf("could not create Event Bus publisher: %s", err)
 		}
 
-		svc = eventpub.NewDeviceEventPublisher(&eventpub.CloudEventMiddlewarePublisher{
+		if conf.FaultInjection.Enabled {
+			injector, err := chaos.NewInjector("event bus", conf.FaultInjection.EventBus, lMessaging)
+			if err != nil {
+				return nil, err
+			}
+			pub = chaos.NewPublisher(pub, injector)
+		}
+
+		var eventMWPub eventpub.ICloudEventMiddlewarePublisher = &eventpub.CloudEventMiddlewarePublisher{
 			Publisher: pub,
 			ServiceID: serviceID,
 			Logger:    lMessaging,
-		})(svc)
+		}
+
+		if conf.DebugTrace.Enabled {
+			eventMWPub = debugtrace.NewEventPublisher(eventMWPub, debugtrace.Default(), serviceID)
+		}
+
+		svc = eventpub.NewDeviceEventPublisher(eventMWPub)(svc)
+
+		registerEventBusDependency(monitor, "publisher-event-bus", conf.PublisherEventBus)
+		monitor.SetPublisher(eventMWPub)
 
 		deviceSvc.SetService(svc)
+
+		if conf.IssuanceReports.Enabled {
+			period := 24 * time.Hour
+			if conf.IssuanceReports.Period != "" {
+				period, err = models.ParseDuration(conf.IssuanceReports.Period)
+				if err != nil {
+					return nil, fmt.Errorf("could not parse issuance reports period '%s': %s", conf.IssuanceReports.Period, err)
+				}
+			}
+
+			lReports := helpers.SetupLogger(conf.Logs.Level, "Device Manager", "Issuance Reports")
+			lReports.Infof("Issuance reports are enabled")
+			reporterJob := jobs.NewIssuanceReporter(svc, eventMWPub, period, lReports)
+			scheduler := jobs.NewJobScheduler(conf.IssuanceReports.CryptoMonitoring, lReports, reporterJob)
+			scheduler.Start()
+		}
+	} else if conf.IssuanceReports.Enabled {
+		lSvc.Warnf("issuance reports require the publisher event bus to be enabled. Reports will not be generated")
+	}
+
+	if conf.SecretSlotRotation.Enabled {
+		lRotation := helpers.SetupLogger(conf.Logs.Level, "Device Manager", "Secret Slot Rotation")
+		lRotation.Infof("Secret slot rotation is enabled")
+		rotatorJob := jobs.NewSecretSlotRotator(svc, lRotation)
+		scheduler := jobs.NewJobScheduler(conf.SecretSlotRotation, lRotation, rotatorJob)
+		scheduler.Start()
+	}
+
+	if conf.DeviceLogRetention.Enabled {
+		retention := 30 * 24 * time.Hour
+		if conf.DeviceLogRetention.Retention != "" {
+			retention, err = models.ParseDuration(conf.DeviceLogRetention.Retention)
+			if err != nil {
+				return nil, fmt.Errorf("could not parse device log retention '%s': %s", conf.DeviceLogRetention.Retention, err)
+			}
+		}
+
+		lRetention := helpers.SetupLogger(conf.Logs.Level, "Device Manager", "Device Log Retention")
+		lRetention.Infof("Device log retention is enabled")
+		prunerJob := jobs.NewDeviceLogPruner(svc, retention, lRetention)
+		scheduler := jobs.NewJobScheduler(conf.DeviceLogRetention.CryptoMonitoring, lRetention, prunerJob)
+		scheduler.Start()
 	}
 
 	if conf.SubscriberEventBus.Enabled {
+		registerEventBusDependency(monitor, "subscriber-event-bus", conf.SubscriberEventBus)
 
 		lMessaging := helpers.SetupLogger(conf.SubscriberEventBus.LogLevel, "Device Manager", "Event Bus")
 		lMessaging.Infof("Subscriber Event Bus is enabled")
@@ -83,19 +201,50 @@ func AssembleDeviceManagerService(conf config.DeviceManagerConfig, caService ser
 		}
 		subHandler.RunAsync()
 
+		connSubHandler, err := eventbus.NewEventBusSubscriptionHandler(conf.SubscriberEventBus, serviceID, lMessaging, *handler, fmt.Sprintf("%s-%s", models.EventReportDeviceConnectionKey, serviceID), string(models.EventReportDeviceConnectionKey))
+		if err != nil {
+			return nil, fmt.Errorf("could not create Event Bus Subscription Handler: %s", err)
+		}
+		connSubHandler.RunAsync()
+
 	}
 
 	return &svc, nil
 }
 
-func createDevicesStorageInstance(logger *logrus.Entry, conf config.PluggableStorageEngine) (storage.DeviceManagerRepo, error) {
-	storage, err := builder.BuildStorageEngine(logger, conf)
+func createDevicesStorageInstance(logger *logrus.Entry, conf config.PluggableStorageEngine, faults config.FaultInjection) (storage.DeviceManagerRepo, storage.DeviceGroupsRepo, storage.DeviceGroupBulkActionsRepo, storage.DeviceLogsRepo, error) {
+	storage, err := builder.BuildAndMigrateStorageEngine(logger, conf)
 	if err != nil {
-		return nil, fmt.Errorf("could not create storage engine: %s", err)
+		return nil, nil, nil, nil, fmt.Errorf("could not create storage engine: %s", err)
 	}
+
+	if faults.Enabled {
+		injector, err := chaos.NewInjector("storage", faults.Storage, logger)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+		storage = chaos.NewStorageEngine(storage, injector)
+	}
+
 	deviceStorage, err := storage.GetDeviceStorage()
 	if err != nil {
-		return nil, fmt.Errorf("could not get device storage: %s", err)
+		return nil, nil, nil, nil, fmt.Errorf("could not get device storage: %s", err)
 	}
-	return deviceStorage, nil
+
+	groupStorage, err := storage.GetDeviceGroupsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("could not get device groups storage: %s", err)
+	}
+
+	bulkActionStorage, err := storage.GetDeviceGroupBulkActionsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("could not get device group bulk actions storage: %s", err)
+	}
+
+	logStorage, err := storage.GetDeviceLogsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, fmt.Errorf("could not get device logs storage: %s", err)
+	}
+
+	return deviceStorage, groupStorage, bulkActionStorage, logStorage, nil
 }
@@ -2,12 +2,23 @@ package assemblers
 
 import (
 	"context"
+	"crypto/elliptic"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"slices"
 	"testing"
+	"time"
 
+	"github.com/go-jose/go-jose/v3"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/stretchr/testify/assert"
 )
 
 func StartDeviceManagerServiceTestServer(t *testing.T, withEventBus bool) (*DeviceManagerTestServer, error) {
@@ -473,3 +484,961 @@ func checkSelectAll(t *testing.T, dmgr *DeviceManagerTestServer) {
 		t.Fatalf("could not retrieve device: %s", err)
 	}
 }
+
+func TestGetDevicesByCertificateStatus(t *testing.T) {
+	ctx := context.Background()
+	dmgr, err := StartDeviceManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create Device Manager test server: %s", err)
+	}
+
+	slotStatuses := map[string]models.SlotStatus{
+		"expiring": models.SlotAboutToExpire,
+		"expired":  models.SlotExpired,
+		"active":   models.SlotActive,
+	}
+	for id, status := range slotStatuses {
+		_, err := dmgr.Service.CreateDevice(ctx, services.CreateDeviceInput{
+			ID:        id,
+			Alias:     id,
+			DMSID:     "test",
+			Icon:      "test",
+			IconColor: "#000000",
+		})
+		if err != nil {
+			t.Fatalf("could not create device %s: %s", id, err)
+		}
+
+		_, err = dmgr.Service.UpdateDeviceIdentitySlot(ctx, services.UpdateDeviceIdentitySlotInput{
+			ID: id,
+			Slot: models.Slot[string]{
+				Status:        status,
+				ActiveVersion: 0,
+				SecretType:    models.X509SlotProfileType,
+				Secrets:       map[int]string{0: id},
+				Events:        map[time.Time]models.DeviceEvent{},
+			},
+		})
+		if err != nil {
+			t.Fatalf("could not update identity slot of device %s: %s", id, err)
+		}
+	}
+
+	// A device without identity slot never matches.
+	_, err = dmgr.Service.CreateDevice(ctx, services.CreateDeviceInput{ID: "no-identity", Alias: "no-identity", DMSID: "test", Icon: "test", IconColor: "#000000"})
+	if err != nil {
+		t.Fatalf("could not create device: %s", err)
+	}
+
+	getDevices := func(status models.DeviceCertificateStatus) ([]string, error) {
+		ids := []string{}
+		_, err := dmgr.HttpDeviceManagerSDK.GetDevices(ctx, services.GetDevicesInput{
+			CertificateStatus: status,
+			ListInput: resources.ListInput[models.Device]{
+				ExhaustiveRun: true,
+				ApplyFunc: func(dev models.Device) {
+					ids = append(ids, dev.ID)
+				},
+			},
+		})
+		return ids, err
+	}
+
+	testcases := []struct {
+		status   models.DeviceCertificateStatus
+		expected []string
+	}{
+		{status: models.DeviceCertificateAboutToExpire, expected: []string{"expiring"}},
+		{status: models.DeviceCertificateExpired, expected: []string{"expired"}},
+		{status: models.DeviceCertificateRevoked, expected: []string{}},
+	}
+
+	for _, tc := range testcases {
+		t.Run(string(tc.status), func(t *testing.T) {
+			ids, err := getDevices(tc.status)
+			if err != nil {
+				t.Fatalf("could not get devices: %s", err)
+			}
+
+			if !slices.Equal(ids, tc.expected) {
+				t.Fatalf("unexpected devices: expected %v, got %v", tc.expected, ids)
+			}
+		})
+	}
+
+	t.Run("AllDevices", func(t *testing.T) {
+		ids, err := getDevices("")
+		if err != nil {
+			t.Fatalf("could not get devices: %s", err)
+		}
+
+		if len(ids) != 4 {
+			t.Fatalf("expected 4 devices, got %d", len(ids))
+		}
+	})
+
+	t.Run("InvalidStatus", func(t *testing.T) {
+		_, err := getDevices("ACTIVE")
+		if !errors.Is(err, errs.ErrValidateBadRequest) {
+			t.Fatalf("expected error %s, got %v", errs.ErrValidateBadRequest, err)
+		}
+	})
+}
+
+func TestGetDevicesOutOfCloudSync(t *testing.T) {
+	ctx := context.Background()
+	storageConfig, err := PreparePostgresForTest([]string{"ca", "devicemanager"})
+	if err != nil {
+		t.Fatalf("could not prepare Postgres test server: %s", err)
+	}
+
+	cryptoConfig := PrepareCryptoEnginesForTest([]CryptoEngine{GOLANG})
+	testServer, err := AssembleServices(storageConfig, &TestEventBusConfig{config: config.EventBusEngine{Enabled: false}}, cryptoConfig, []Service{CA, DEVICE_MANAGER})
+	if err != nil {
+		t.Fatalf("could not assemble Server with HTTP server")
+	}
+	err = testServer.BeforeEach()
+	if err != nil {
+		t.Fatalf("could not run 'BeforeEach' cleanup func in test case: %s", err)
+	}
+	t.Cleanup(testServer.AfterSuite)
+
+	_, err = initCA(testServer.CA.Service)
+	if err != nil {
+		t.Fatalf("could not create CA: %s", err)
+	}
+
+	connectorID := "aws.12345"
+	metaKey := models.CloudConnectorMetadataKey(connectorID)
+	devices := map[string]struct {
+		registered   bool
+		bound        bool
+		certInCloud  bool
+		expectReason models.DeviceCloudSyncReason
+	}{
+		"in-sync":        {bound: true, registered: true, certInCloud: true},
+		"reenrolled":     {bound: true, registered: true, expectReason: models.DeviceCloudSyncCertificateNotSynced},
+		"not-registered": {bound: true, expectReason: models.DeviceCloudSyncNotRegistered},
+		"not-bound":      {},
+	}
+
+	for id, dev := range devices {
+		crt, err := generateCertificate(testServer.CA.Service)
+		if err != nil {
+			t.Fatalf("could not generate certificate: %s", err)
+		}
+
+		if dev.certInCloud {
+			_, err = testServer.CA.Service.UpdateCertificateMetadata(ctx, services.UpdateCertificateMetadataInput{
+				SerialNumber: crt.SerialNumber,
+				Metadata:     map[string]any{metaKey: models.IoTAWSCertificateMetadata{ARN: "arn"}},
+			})
+			if err != nil {
+				t.Fatalf("could not update certificate metadata: %s", err)
+			}
+		}
+
+		metadata := map[string]any{}
+		if dev.bound {
+			metadata[metaKey] = models.DeviceAWSMetadata{Registered: dev.registered}
+		}
+
+		_, err = testServer.DeviceManager.Service.CreateDevice(ctx, services.CreateDeviceInput{
+			ID:        id,
+			Alias:     id,
+			DMSID:     "test",
+			Metadata:  metadata,
+			Icon:      "test",
+			IconColor: "#000000",
+		})
+		if err != nil {
+			t.Fatalf("could not create device %s: %s", id, err)
+		}
+
+		_, err = testServer.DeviceManager.Service.UpdateDeviceIdentitySlot(ctx, services.UpdateDeviceIdentitySlotInput{
+			ID: id,
+			Slot: models.Slot[string]{
+				Status:        models.SlotActive,
+				ActiveVersion: 0,
+				SecretType:    models.X509SlotProfileType,
+				Secrets:       map[int]string{0: crt.SerialNumber},
+				Events:        map[time.Time]models.DeviceEvent{},
+			},
+		})
+		if err != nil {
+			t.Fatalf("could not update identity slot of device %s: %s", id, err)
+		}
+	}
+
+	got := map[string]models.DeviceCloudSyncReason{}
+	_, err = testServer.DeviceManager.HttpDeviceManagerSDK.GetDevicesOutOfCloudSync(ctx, services.GetDevicesOutOfCloudSyncInput{
+		ConnectorID: connectorID,
+		ListInput: resources.ListInput[models.DeviceCloudSyncStatus]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(status models.DeviceCloudSyncStatus) {
+				got[status.DeviceID] = status.Reason
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not get devices out of sync: %s", err)
+	}
+
+	for id, dev := range devices {
+		if reason := got[id]; reason != dev.expectReason {
+			t.Errorf("device %s: expected reason '%s', got '%s'", id, dev.expectReason, reason)
+		}
+	}
+}
+
+func TestDeviceSecretSlots(t *testing.T) {
+	ctx := context.Background()
+	storageConfig, err := PreparePostgresForTest([]string{"ca", "devicemanager"})
+	if err != nil {
+		t.Fatalf("could not prepare Postgres test server: %s", err)
+	}
+
+	cryptoConfig := PrepareCryptoEnginesForTest([]CryptoEngine{GOLANG})
+	testServer, err := AssembleServices(storageConfig, &TestEventBusConfig{config: config.EventBusEngine{Enabled: false}}, cryptoConfig, []Service{CA, DEVICE_MANAGER})
+	if err != nil {
+		t.Fatalf("could not assemble Server with HTTP server")
+	}
+	err = testServer.BeforeEach()
+	if err != nil {
+		t.Fatalf("could not run 'BeforeEach' cleanup func in test case: %s", err)
+	}
+	t.Cleanup(testServer.AfterSuite)
+
+	_, err = initCA(testServer.CA.Service)
+	if err != nil {
+		t.Fatalf("could not create CA: %s", err)
+	}
+
+	deviceSDK := testServer.DeviceManager.HttpDeviceManagerSDK
+	_, err = deviceSDK.CreateDevice(ctx, services.CreateDeviceInput{
+		ID:        "azure-device",
+		Alias:     "azure-device",
+		DMSID:     "test",
+		Icon:      "test",
+		IconColor: "#000000",
+	})
+	if err != nil {
+		t.Fatalf("could not create device: %s", err)
+	}
+
+	profile := models.SymmetricSlotProfile{
+		ResourceURI:  "myhub.azure-devices.net/devices/azure-device",
+		Validity:     models.TimeDuration(24 * time.Hour),
+		RotateBefore: models.TimeDuration(time.Hour),
+	}
+
+	_, err = deviceSDK.ProvisionDeviceSecretSlot(ctx, services.ProvisionDeviceSecretSlotInput{
+		DeviceID:   "azure-device",
+		SlotID:     models.DeviceIdentitySlotID,
+		SecretType: models.SASTokenSlotProfileType,
+		Profile:    profile,
+	})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected the identity slot to be rejected, got %v", err)
+	}
+
+	_, err = deviceSDK.ProvisionDeviceSecretSlot(ctx, services.ProvisionDeviceSecretSlotInput{
+		DeviceID:   "azure-device",
+		SlotID:     "azure",
+		SecretType: models.SASTokenSlotProfileType,
+		Profile:    models.SymmetricSlotProfile{Validity: profile.Validity},
+	})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected a SAS token slot without resource URI to be rejected, got %v", err)
+	}
+
+	device, err := deviceSDK.ProvisionDeviceSecretSlot(ctx, services.ProvisionDeviceSecretSlotInput{
+		DeviceID:   "azure-device",
+		SlotID:     "azure",
+		SecretType: models.SASTokenSlotProfileType,
+		Profile:    profile,
+	})
+	if err != nil {
+		t.Fatalf("could not provision slot: %s", err)
+	}
+
+	slot := device.ExtraSlots["azure"]
+	if slot.SecretType != models.SASTokenSlotProfileType || slot.ActiveVersion != 0 || !slot.Redacted {
+		t.Fatalf("unexpected slot %v", slot)
+	}
+
+	_, err = deviceSDK.GetDeviceSecretSlot(ctx, services.GetDeviceSecretSlotInput{DeviceID: "azure-device", SlotID: "azure"})
+	if !errors.Is(err, errs.ErrDeviceNoIdentity) {
+		t.Fatalf("expected the secret to require a device identity, got %v", err)
+	}
+
+	key, err := helpers.GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		t.Fatalf("could not generate device key: %s", err)
+	}
+
+	csr, err := helpers.GenerateCertificateRequest(models.Subject{CommonName: "azure-device"}, key)
+	if err != nil {
+		t.Fatalf("could not generate csr: %s", err)
+	}
+
+	crt, err := testServer.CA.Service.SignCertificate(ctx, services.SignCertificateInput{
+		CAID:         DefaultCAID,
+		CertRequest:  (*models.X509CertificateRequest)(csr),
+		SignVerbatim: true,
+	})
+	if err != nil {
+		t.Fatalf("could not sign csr: %s", err)
+	}
+
+	device, err = deviceSDK.UpdateDeviceIdentitySlot(ctx, services.UpdateDeviceIdentitySlotInput{
+		ID: "azure-device",
+		Slot: models.Slot[string]{
+			Status:        models.SlotActive,
+			ActiveVersion: 0,
+			SecretType:    models.X509SlotProfileType,
+			Secrets:       map[int]string{0: crt.SerialNumber},
+			Events:        map[time.Time]models.DeviceEvent{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not update identity slot: %s", err)
+	}
+
+	checkRedacted := func(operation string, device *models.Device) {
+		slot := device.ExtraSlots["azure"]
+		if !slot.Redacted {
+			t.Fatalf("expected slot secrets to be redacted in the %s response", operation)
+		}
+
+		secret, err := helpers.DecodeSymmetricSecret(slot.Secrets[0])
+		if err != nil || secret.Key != "" || secret.Token != "" {
+			t.Fatalf("slot secret leaked in the %s response: %v", operation, slot.Secrets[0])
+		}
+	}
+	checkRedacted("identity slot update", device)
+
+	device, err = deviceSDK.UpdateDeviceMetadata(ctx, services.UpdateDeviceMetadataInput{
+		ID:       "azure-device",
+		Metadata: map[string]any{"hub": "myhub"},
+	})
+	if err != nil {
+		t.Fatalf("could not update device metadata: %s", err)
+	}
+	checkRedacted("metadata update", device)
+
+	device, err = deviceSDK.RotateDeviceSecretSlot(ctx, services.RotateDeviceSecretSlotInput{DeviceID: "azure-device", SlotID: "azure"})
+	if err != nil {
+		t.Fatalf("could not rotate slot: %s", err)
+	}
+
+	if device.ExtraSlots["azure"].ActiveVersion != 1 {
+		t.Fatalf("expected version 1 to be active, got %d", device.ExtraSlots["azure"].ActiveVersion)
+	}
+
+	encrypted, err := deviceSDK.GetDeviceSecretSlot(ctx, services.GetDeviceSecretSlotInput{DeviceID: "azure-device", SlotID: "azure"})
+	if err != nil {
+		t.Fatalf("could not get slot secret: %s", err)
+	}
+
+	if encrypted.Version != 1 || encrypted.SerialNumber != crt.SerialNumber {
+		t.Fatalf("unexpected encrypted secret %v", encrypted)
+	}
+
+	jwe, err := jose.ParseEncrypted(encrypted.JWE)
+	if err != nil {
+		t.Fatalf("could not parse JWE: %s", err)
+	}
+
+	payload, err := jwe.Decrypt(key)
+	if err != nil {
+		t.Fatalf("could not decrypt secret with the device key: %s", err)
+	}
+
+	var secret models.SymmetricSecret
+	if err := json.Unmarshal(payload, &secret); err != nil {
+		t.Fatalf("could not decode secret: %s", err)
+	}
+
+	rawKey, err := base64.StdEncoding.DecodeString(secret.Key)
+	if err != nil {
+		t.Fatalf("could not decode slot key: %s", err)
+	}
+
+	if secret.Token != helpers.GenerateSASToken(profile.ResourceURI, rawKey, secret.ExpiresAt) {
+		t.Fatalf("SAS token is not signed with the slot key")
+	}
+
+	_, err = deviceSDK.RotateDeviceSecretSlot(ctx, services.RotateDeviceSecretSlotInput{DeviceID: "azure-device", SlotID: "unknown"})
+	if !errors.Is(err, errs.ErrDeviceSlotNotFound) {
+		t.Fatalf("expected slot not found, got %v", err)
+	}
+}
+
+func TestDeviceHeartbeat(t *testing.T) {
+	ctx := context.Background()
+	dmgr, err := StartDeviceManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create Device Manager test server: %s", err)
+	}
+
+	sdk := dmgr.HttpDeviceManagerSDK
+	for _, id := range []string{"alive", "silent"} {
+		_, err := sdk.CreateDevice(ctx, services.CreateDeviceInput{ID: id, Alias: id, DMSID: "test", Icon: "test", IconColor: "#000000"})
+		if err != nil {
+			t.Fatalf("could not create device %s: %s", id, err)
+		}
+	}
+
+	_, err = sdk.ReportDeviceHeartbeat(ctx, services.ReportDeviceHeartbeatInput{ID: "missing", Source: "mqtt"})
+	if !errors.Is(err, errs.ErrDeviceNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceNotFound, err)
+	}
+
+	device, err := sdk.ReportDeviceHeartbeat(ctx, services.ReportDeviceHeartbeatInput{ID: "alive", Source: "mqtt", Broker: "broker-1"})
+	if err != nil {
+		t.Fatalf("could not report heartbeat: %s", err)
+	}
+
+	assert.Equal(t, models.DeviceConnectionOnline, device.ConnectionMetadata.Status)
+	assert.Equal(t, "mqtt", device.ConnectionMetadata.Source)
+	assert.Equal(t, "broker-1", device.ConnectionMetadata.Broker)
+	assert.NotEmpty(t, device.ConnectionMetadata.IPAddress)
+	assert.WithinDuration(t, time.Now(), device.ConnectionMetadata.LastSeen, 5*time.Second)
+
+	getNotSeen := func(notSeenFor time.Duration) ([]string, error) {
+		ids := []string{}
+		_, err := sdk.GetDevices(ctx, services.GetDevicesInput{
+			NotSeenFor: notSeenFor,
+			ListInput: resources.ListInput[models.Device]{
+				ExhaustiveRun: true,
+				ApplyFunc: func(dev models.Device) {
+					ids = append(ids, dev.ID)
+				},
+			},
+		})
+		slices.Sort(ids)
+		return ids, err
+	}
+
+	ids, err := getNotSeen(24 * time.Hour)
+	if err != nil {
+		t.Fatalf("could not get devices not seen: %s", err)
+	}
+	assert.Equal(t, []string{"silent"}, ids)
+
+	ids, err = getNotSeen(0)
+	if err != nil {
+		t.Fatalf("could not get devices: %s", err)
+	}
+	assert.Equal(t, []string{"alive", "silent"}, ids)
+}
+
+func TestDeviceLogs(t *testing.T) {
+	ctx := context.Background()
+	dmgr, err := StartDeviceManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create Device Manager test server: %s", err)
+	}
+
+	sdk := dmgr.HttpDeviceManagerSDK
+	_, err = sdk.CreateDevice(ctx, services.CreateDeviceInput{ID: "logger", Alias: "logger", DMSID: "test", Icon: "test", IconColor: "#000000"})
+	if err != nil {
+		t.Fatalf("could not create device: %s", err)
+	}
+
+	_, err = sdk.CreateDeviceLog(ctx, services.CreateDeviceLogInput{DeviceID: "missing", Severity: models.DeviceLogSeverityInfo, Message: "boot"})
+	if !errors.Is(err, errs.ErrDeviceNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceNotFound, err)
+	}
+
+	_, err = sdk.CreateDeviceLog(ctx, services.CreateDeviceLogInput{DeviceID: "logger", Severity: "FATAL", Message: "boot"})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected error %s, got %v", errs.ErrValidateBadRequest, err)
+	}
+
+	old := time.Now().Add(-48 * time.Hour)
+	entries := []services.CreateDeviceLogInput{
+		{DeviceID: "logger", Severity: models.DeviceLogSeverityInfo, Message: "boot", Source: "device", Timestamp: old},
+		{DeviceID: "logger", Severity: models.DeviceLogSeverityError, Message: "sensor unreachable", Source: "device"},
+		{DeviceID: "logger", Severity: models.DeviceLogSeverityInfo, Message: "connected", Source: "aws-iot"},
+	}
+	for _, entry := range entries {
+		_, err := sdk.CreateDeviceLog(ctx, entry)
+		if err != nil {
+			t.Fatalf("could not create device log: %s", err)
+		}
+	}
+
+	getLogs := func(severity models.DeviceLogSeverity) ([]string, error) {
+		messages := []string{}
+		_, err := sdk.GetDeviceLogs(ctx, services.GetDeviceLogsInput{
+			DeviceID: "logger",
+			Severity: severity,
+			ListInput: resources.ListInput[models.DeviceLog]{
+				ExhaustiveRun: true,
+				ApplyFunc: func(log models.DeviceLog) {
+					messages = append(messages, log.Message)
+				},
+			},
+		})
+		slices.Sort(messages)
+		return messages, err
+	}
+
+	messages, err := getLogs("")
+	if err != nil {
+		t.Fatalf("could not get device logs: %s", err)
+	}
+	assert.Equal(t, []string{"boot", "connected", "sensor unreachable"}, messages)
+
+	messages, err = getLogs(models.DeviceLogSeverityError)
+	if err != nil {
+		t.Fatalf("could not get device logs: %s", err)
+	}
+	assert.Equal(t, []string{"sensor unreachable"}, messages)
+
+	pruned, err := dmgr.Service.PruneDeviceLogs(ctx, services.PruneDeviceLogsInput{Before: time.Now().Add(-24 * time.Hour)})
+	if err != nil {
+		t.Fatalf("could not prune device logs: %s", err)
+	}
+	assert.Equal(t, 1, pruned)
+
+	messages, err = getLogs("")
+	if err != nil {
+		t.Fatalf("could not get device logs: %s", err)
+	}
+	assert.Equal(t, []string{"connected", "sensor unreachable"}, messages)
+}
+
+func TestForceDeviceReenroll(t *testing.T) {
+	ctx := context.Background()
+	dmgr, err := StartDeviceManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create Device Manager test server: %s", err)
+	}
+
+	sdk := dmgr.HttpDeviceManagerSDK
+	_, err = sdk.CreateDevice(ctx, services.CreateDeviceInput{ID: "device", Alias: "device", DMSID: "test", Icon: "test", IconColor: "#000000"})
+	if err != nil {
+		t.Fatalf("could not create device: %s", err)
+	}
+
+	_, err = sdk.ForceDeviceReenroll(ctx, services.ForceDeviceReenrollInput{ID: "missing"})
+	if !errors.Is(err, errs.ErrDeviceNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceNotFound, err)
+	}
+
+	_, err = sdk.ForceDeviceReenroll(ctx, services.ForceDeviceReenrollInput{ID: "device"})
+	if !errors.Is(err, errs.ErrDeviceNoIdentity) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceNoIdentity, err)
+	}
+
+	_, err = sdk.UpdateDeviceIdentitySlot(ctx, services.UpdateDeviceIdentitySlotInput{
+		ID: "device",
+		Slot: models.Slot[string]{
+			Status:        models.SlotActive,
+			ActiveVersion: 0,
+			SecretType:    models.X509SlotProfileType,
+			Secrets:       map[int]string{0: "01-02-03"},
+			Events:        map[time.Time]models.DeviceEvent{},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not update identity slot: %s", err)
+	}
+
+	device, err := sdk.ForceDeviceReenroll(ctx, services.ForceDeviceReenrollInput{ID: "device", Reason: "key compromise suspected"})
+	if err != nil {
+		t.Fatalf("could not force reenrollment: %s", err)
+	}
+
+	assert.Equal(t, models.DeviceRenewalWindow, device.Status)
+	assert.Equal(t, models.SlotRenewalWindow, device.IdentitySlot.Status)
+	assert.Equal(t, true, device.Metadata[models.DeviceMetadataForceReenrollKey])
+
+	found := false
+	for _, event := range device.Events {
+		if event.EvenType == models.DeviceEventTypeForceReenroll {
+			found = true
+			assert.Contains(t, event.EventDescriptions, "key compromise suspected")
+		}
+	}
+	assert.True(t, found, "expected a force reenroll device event")
+}
+
+func TestDeviceGroups(t *testing.T) {
+	ctx := context.Background()
+	dmgr, err := StartDeviceManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create Device Manager test server: %s", err)
+	}
+
+	devices := map[string][]string{
+		"static":     {},
+		"tagged":     {"fleet-a"},
+		"other-tags": {"fleet-b"},
+	}
+	for id, tags := range devices {
+		_, err := dmgr.Service.CreateDevice(ctx, services.CreateDeviceInput{ID: id, Alias: id, Tags: tags, DMSID: "test", Icon: "test", IconColor: "#000000"})
+		if err != nil {
+			t.Fatalf("could not create device %s: %s", id, err)
+		}
+	}
+
+	sdk := dmgr.HttpDeviceManagerSDK
+	getGroupDevices := func(groupID string) ([]string, error) {
+		ids := []string{}
+		_, err := sdk.GetDeviceGroupDevices(ctx, services.GetDeviceGroupDevicesInput{
+			ID: groupID,
+			ListInput: resources.ListInput[models.Device]{
+				ExhaustiveRun: true,
+				ApplyFunc: func(dev models.Device) {
+					ids = append(ids, dev.ID)
+				},
+			},
+		})
+		slices.Sort(ids)
+		return ids, err
+	}
+
+	_, err = sdk.CreateDeviceGroup(ctx, services.CreateDeviceGroupInput{Name: "unknown", DeviceIDs: []string{"missing"}})
+	if !errors.Is(err, errs.ErrDeviceNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceNotFound, err)
+	}
+
+	group, err := sdk.CreateDeviceGroup(ctx, services.CreateDeviceGroupInput{
+		Name:      "group",
+		DeviceIDs: []string{"static"},
+		Tags:      []string{"fleet-a"},
+	})
+	if err != nil {
+		t.Fatalf("could not create device group: %s", err)
+	}
+
+	ids, err := getGroupDevices(group.ID)
+	if err != nil {
+		t.Fatalf("could not get device group devices: %s", err)
+	}
+	assert.Equal(t, []string{"static", "tagged"}, ids)
+
+	t.Run("UpdateMembers", func(t *testing.T) {
+		_, err := sdk.UpdateDeviceGroupTags(ctx, services.UpdateDeviceGroupTagsInput{ID: group.ID, Tags: []string{"fleet-b"}})
+		if err != nil {
+			t.Fatalf("could not update device group tags: %s", err)
+		}
+
+		_, err = sdk.UpdateDeviceGroupDevices(ctx, services.UpdateDeviceGroupDevicesInput{ID: group.ID, AddDeviceIDs: []string{"tagged"}, RemoveDeviceIDs: []string{"static"}})
+		if err != nil {
+			t.Fatalf("could not update device group devices: %s", err)
+		}
+
+		ids, err := getGroupDevices(group.ID)
+		if err != nil {
+			t.Fatalf("could not get device group devices: %s", err)
+		}
+		assert.Equal(t, []string{"other-tags", "tagged"}, ids)
+	})
+
+	waitBulkAction := func(t *testing.T, action *models.DeviceGroupBulkAction) *models.DeviceGroupBulkAction {
+		var progress *models.DeviceGroupBulkAction
+		assert.Eventually(t, func() bool {
+			current, err := sdk.GetDeviceGroupBulkActionByID(ctx, services.GetDeviceGroupBulkActionByIDInput{GroupID: group.ID, ActionID: action.ID})
+			if err != nil {
+				return false
+			}
+			progress = current
+			return current.Status == models.DeviceGroupBulkActionCompleted
+		}, 5*time.Second, 50*time.Millisecond)
+		return progress
+	}
+
+	t.Run("UpdateMetadata", func(t *testing.T) {
+		action, err := sdk.StartDeviceGroupBulkAction(ctx, services.StartDeviceGroupBulkActionInput{
+			GroupID:  group.ID,
+			Type:     models.DeviceGroupBulkActionUpdateMetadata,
+			Metadata: map[string]any{"firmware": "v2"},
+		})
+		if err != nil {
+			t.Fatalf("could not start bulk action: %s", err)
+		}
+
+		action = waitBulkAction(t, action)
+		assert.Equal(t, 2, action.Total)
+		assert.Equal(t, 2, action.Succeeded)
+
+		for _, id := range []string{"tagged", "other-tags"} {
+			device, err := sdk.GetDeviceByID(ctx, services.GetDeviceByIDInput{ID: id})
+			if err != nil {
+				t.Fatalf("could not get device %s: %s", id, err)
+			}
+			assert.Equal(t, "v2", device.Metadata["firmware"])
+		}
+
+		device, err := sdk.GetDeviceByID(ctx, services.GetDeviceByIDInput{ID: "static"})
+		if err != nil {
+			t.Fatalf("could not get device: %s", err)
+		}
+		assert.NotContains(t, device.Metadata, "firmware")
+	})
+
+	t.Run("RevokeWithoutIdentity", func(t *testing.T) {
+		action, err := sdk.StartDeviceGroupBulkAction(ctx, services.StartDeviceGroupBulkActionInput{
+			GroupID: group.ID,
+			Type:    models.DeviceGroupBulkActionRevoke,
+		})
+		if err != nil {
+			t.Fatalf("could not start bulk action: %s", err)
+		}
+
+		action = waitBulkAction(t, action)
+		assert.Equal(t, 2, action.Failed)
+		assert.Len(t, action.Errors, 2)
+	})
+
+	t.Run("ListActions", func(t *testing.T) {
+		actions := []models.DeviceGroupBulkAction{}
+		_, err := sdk.GetDeviceGroupBulkActions(ctx, services.GetDeviceGroupBulkActionsInput{
+			GroupID: group.ID,
+			ListInput: resources.ListInput[models.DeviceGroupBulkAction]{
+				ExhaustiveRun: true,
+				ApplyFunc: func(action models.DeviceGroupBulkAction) {
+					actions = append(actions, action)
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("could not get bulk actions: %s", err)
+		}
+		assert.Len(t, actions, 2)
+	})
+
+	t.Run("InvalidAction", func(t *testing.T) {
+		_, err := sdk.StartDeviceGroupBulkAction(ctx, services.StartDeviceGroupBulkActionInput{GroupID: group.ID, Type: "REBOOT"})
+		if !errors.Is(err, errs.ErrValidateBadRequest) {
+			t.Fatalf("expected error %s, got %v", errs.ErrValidateBadRequest, err)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		err := sdk.DeleteDeviceGroup(ctx, services.DeleteDeviceGroupInput{ID: group.ID})
+		if err != nil {
+			t.Fatalf("could not delete device group: %s", err)
+		}
+
+		_, err = sdk.GetDeviceGroupByID(ctx, services.GetDeviceGroupByIDInput{ID: group.ID})
+		if !errors.Is(err, errs.ErrDeviceGroupNotFound) {
+			t.Fatalf("expected error %s, got %v", errs.ErrDeviceGroupNotFound, err)
+		}
+	})
+}
+
+func TestDynamicDeviceGroups(t *testing.T) {
+	ctx := context.Background()
+	dmgr, err := StartDeviceManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create Device Manager test server: %s", err)
+	}
+
+	createDevice := func(id, dmsID string, tags []string) {
+		_, err := dmgr.Service.CreateDevice(ctx, services.CreateDeviceInput{ID: id, Alias: id, Tags: tags, DMSID: dmsID, Icon: "test", IconColor: "#000000"})
+		if err != nil {
+			t.Fatalf("could not create device %s: %s", id, err)
+		}
+	}
+	createDevice("plant-1-sensor", "plant-1", []string{"sensor"})
+	createDevice("plant-1-gateway", "plant-1", []string{"gateway"})
+	createDevice("plant-2-sensor", "plant-2", []string{"sensor"})
+
+	sdk := dmgr.HttpDeviceManagerSDK
+	getGroupDevices := func(groupID string) ([]string, error) {
+		ids := []string{}
+		_, err := sdk.GetDeviceGroupDevices(ctx, services.GetDeviceGroupDevicesInput{
+			ID: groupID,
+			ListInput: resources.ListInput[models.Device]{
+				ExhaustiveRun: true,
+				ApplyFunc: func(dev models.Device) {
+					ids = append(ids, dev.ID)
+				},
+			},
+		})
+		slices.Sort(ids)
+		return ids, err
+	}
+
+	for _, input := range []services.CreateDeviceGroupInput{
+		{Name: "unknown-field", Filters: []string{"unknown[equal]x"}},
+		{Name: "unknown-status", CertificateStatus: "UNKNOWN"},
+		{Name: "mixed", Filters: []string{"dms_owner[equal]plant-1"}, Tags: []string{"sensor"}},
+	} {
+		_, err = sdk.CreateDeviceGroup(ctx, input)
+		if !errors.Is(err, errs.ErrValidateBadRequest) {
+			t.Fatalf("expected error %s creating group %s, got %v", errs.ErrValidateBadRequest, input.Name, err)
+		}
+	}
+
+	group, err := sdk.CreateDeviceGroup(ctx, services.CreateDeviceGroupInput{
+		Name:    "plant-1",
+		Filters: []string{"dms_owner[equal]plant-1"},
+	})
+	if err != nil {
+		t.Fatalf("could not create device group: %s", err)
+	}
+
+	ids, err := getGroupDevices(group.ID)
+	if err != nil {
+		t.Fatalf("could not get device group devices: %s", err)
+	}
+	assert.Equal(t, []string{"plant-1-gateway", "plant-1-sensor"}, ids)
+
+	_, err = sdk.UpdateDeviceGroupTags(ctx, services.UpdateDeviceGroupTagsInput{ID: group.ID, Tags: []string{"sensor"}})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected error %s, got %v", errs.ErrValidateBadRequest, err)
+	}
+
+	_, err = sdk.UpdateDeviceGroupFilters(ctx, services.UpdateDeviceGroupFiltersInput{
+		ID:      group.ID,
+		Filters: []string{"dms_owner[equal]plant-1", "tags[contains]sensor"},
+	})
+	if err != nil {
+		t.Fatalf("could not update device group filters: %s", err)
+	}
+
+	ids, err = getGroupDevices(group.ID)
+	if err != nil {
+		t.Fatalf("could not get device group devices: %s", err)
+	}
+	assert.Equal(t, []string{"plant-1-sensor"}, ids)
+
+	// members are resolved on every read, so new devices join the group right away
+	createDevice("plant-1-sensor-2", "plant-1", []string{"sensor"})
+
+	action, err := sdk.StartDeviceGroupBulkAction(ctx, services.StartDeviceGroupBulkActionInput{
+		GroupID:  group.ID,
+		Type:     models.DeviceGroupBulkActionUpdateMetadata,
+		Metadata: map[string]any{"firmware": "v2"},
+	})
+	if err != nil {
+		t.Fatalf("could not start bulk action: %s", err)
+	}
+
+	assert.Eventually(t, func() bool {
+		current, err := sdk.GetDeviceGroupBulkActionByID(ctx, services.GetDeviceGroupBulkActionByIDInput{GroupID: group.ID, ActionID: action.ID})
+		return err == nil && current.Status == models.DeviceGroupBulkActionCompleted && current.Succeeded == 2
+	}, 5*time.Second, 50*time.Millisecond)
+
+	for id, updated := range map[string]bool{"plant-1-sensor": true, "plant-1-sensor-2": true, "plant-1-gateway": false, "plant-2-sensor": false} {
+		device, err := sdk.GetDeviceByID(ctx, services.GetDeviceByIDInput{ID: id})
+		if err != nil {
+			t.Fatalf("could not get device %s: %s", id, err)
+		}
+		if updated {
+			assert.Equal(t, "v2", device.Metadata["firmware"])
+		} else {
+			assert.NotContains(t, device.Metadata, "firmware")
+		}
+	}
+
+	revoked, err := sdk.CreateDeviceGroup(ctx, services.CreateDeviceGroupInput{
+		Name:              "revoked",
+		CertificateStatus: models.DeviceCertificateRevoked,
+	})
+	if err != nil {
+		t.Fatalf("could not create device group: %s", err)
+	}
+
+	ids, err = getGroupDevices(revoked.ID)
+	if err != nil {
+		t.Fatalf("could not get device group devices: %s", err)
+	}
+	assert.Empty(t, ids)
+}
+
+func TestDeviceGroupBulkRevocationApproval(t *testing.T) {
+	storageConfig, err := PreparePostgresForTest([]string{"devicemanager"})
+	if err != nil {
+		t.Fatalf("could not prepare Postgres test server: %s", err)
+	}
+	t.Cleanup(storageConfig.AfterSuite)
+
+	dmConf := config.DeviceManagerConfig{
+		Logs:                          config.BaseConfigLogging{Level: config.Info},
+		Server:                        config.HttpServer{LogLevel: config.Info, Protocol: config.HTTP},
+		Storage:                       storageConfig.config,
+		DestructiveOperationsApproval: config.DestructiveOperationsApproval{Enabled: true},
+	}
+
+	_, err = AssembleDeviceManagerService(dmConf, nil)
+	if err == nil {
+		t.Fatalf("expected error enabling destructive operations approval without OIDC authentication")
+	}
+
+	// The provider is only contacted when serving requests. The test calls the service directly.
+	dmConf.Server.Authentication.OIDC = config.HttpServerOIDCAuthentication{
+		Enabled:          true,
+		OIDCWellKnownURL: "http://127.0.0.1:1/.well-known/openid-configuration",
+	}
+	dmSvc, err := AssembleDeviceManagerService(dmConf, nil)
+	if err != nil {
+		t.Fatalf("could not assemble Device Manager: %s", err)
+	}
+
+	svc := *dmSvc
+	as := func(id string) context.Context {
+		ctx := context.WithValue(context.Background(), string(identityextractors.CtxAuthID), id)
+		return context.WithValue(ctx, string(identityextractors.CtxAuthVerified), true)
+	}
+
+	_, err = svc.CreateDevice(as("admin-1"), services.CreateDeviceInput{ID: "device", Alias: "device", Tags: []string{}, DMSID: "test", Icon: "test", IconColor: "#000000"})
+	if err != nil {
+		t.Fatalf("could not create device: %s", err)
+	}
+
+	group, err := svc.CreateDeviceGroup(as("admin-1"), services.CreateDeviceGroupInput{Name: "group", DeviceIDs: []string{"device"}})
+	if err != nil {
+		t.Fatalf("could not create device group: %s", err)
+	}
+
+	action, err := svc.StartDeviceGroupBulkAction(as("admin-1"), services.StartDeviceGroupBulkActionInput{
+		GroupID: group.ID,
+		Type:    models.DeviceGroupBulkActionRevoke,
+	})
+	if err != nil {
+		t.Fatalf("could not start bulk action: %s", err)
+	}
+	assert.Equal(t, models.DeviceGroupBulkActionPendingApproval, action.Status)
+	assert.Equal(t, "admin-1", action.CreatedBy)
+	assert.NotNil(t, action.ApprovalExpiresAt)
+
+	approve := func(ctx context.Context) (*models.DeviceGroupBulkAction, error) {
+		return svc.ApproveDeviceGroupBulkAction(ctx, services.ApproveDeviceGroupBulkActionInput{GroupID: group.ID, ActionID: action.ID})
+	}
+
+	_, err = approve(as("admin-1"))
+	if !errors.Is(err, errs.ErrDeviceGroupBulkActionSelfApproval) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceGroupBulkActionSelfApproval, err)
+	}
+
+	// The caller ID is set, but was not verified by the authentication of the API.
+	_, err = approve(context.WithValue(context.Background(), string(identityextractors.CtxAuthID), "admin-2"))
+	if !errors.Is(err, errs.ErrDeviceGroupBulkActionUnverifiedApprover) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceGroupBulkActionUnverifiedApprover, err)
+	}
+
+	approved, err := approve(as("admin-2"))
+	if err != nil {
+		t.Fatalf("could not approve bulk action: %s", err)
+	}
+	assert.Equal(t, "admin-2", approved.ApprovedBy)
+	assert.Equal(t, 1, approved.Total)
+
+	_, err = approve(as("admin-3"))
+	if !errors.Is(err, errs.ErrDeviceGroupBulkActionNotPending) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDeviceGroupBulkActionNotPending, err)
+	}
+
+	assert.Eventually(t, func() bool {
+		current, err := svc.GetDeviceGroupBulkActionByID(context.Background(), services.GetDeviceGroupBulkActionByIDInput{GroupID: group.ID, ActionID: action.ID})
+		return err == nil && current.Status == models.DeviceGroupBulkActionCompleted && current.Failed == 1
+	}, 5*time.Second, 50*time.Millisecond)
+}
@@ -3,9 +3,15 @@ package assemblers
 import (
 	"fmt"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/chaos"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/debugtrace"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/eventbus"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/featureflags"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/routes"
@@ -16,25 +22,72 @@ import (
 )
 
 func AssembleDMSManagerServiceWithHTTPServer(conf config.DMSconfig, caService services.CAService, deviceService services.DeviceManagerService, serviceInfo models.APIServiceInfo) (*services.DMSManagerService, int, error) {
-	service, err := AssembleDMSManagerService(conf, caService, deviceService)
+	flags := featureflags.NewFeatureFlags(featureflags.DMSManagerFlags, conf.FeatureFlags, helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Feature Flags"))
+	lHealth := helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Dependency Monitoring")
+	monitor, err := newDependencyMonitor("dms-manager", conf.DependencyMonitoring, lHealth)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	service, err := assembleDMSManagerService(conf, caService, deviceService, flags, monitor)
 	if err != nil {
 		return nil, -1, fmt.Errorf("could not assemble DMS Manager Service. Exiting: %s", err)
 	}
 
+	err = registerServiceClientDependency(monitor, "ca", conf.CAClient.HTTPClient, lHealth)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	err = registerServiceClientDependency(monitor, "device-manager", conf.DevManagerClient.HTTPClient, lHealth)
+	if err != nil {
+		return nil, -1, err
+	}
+
 	lHttp := helpers.SetupLogger(conf.Server.LogLevel, "DMS Manager", "HTTP Server")
 
-	httpEngine := routes.NewGinEngine(lHttp)
-	httpGrp := httpEngine.Group("/")
-	routes.NewDMSManagerHTTPLayer(lHttp, httpGrp, *service)
-	port, err := routes.RunHttpRouter(lHttp, httpEngine, conf.Server, serviceInfo)
+	routers, err := routes.NewHttpRouters(lHttp, conf.Server)
+	if err != nil {
+		return nil, -1, fmt.Errorf("could not create DMS Manager http routers: %s", err)
+	}
+
+	httpGrp := routers.Management
+	if conf.DebugTrace.Enabled {
+		routers.Use(debugtrace.HTTPMiddleware(debugtrace.Default(), "dms-manager"))
+		routes.NewDebugTraceHTTPLayer(httpGrp, debugtrace.Default())
+	}
+	routes.NewDMSManagerHTTPLayer(httpGrp, *service)
+	routes.NewDMSManagerMonitoringHTTPLayer(httpGrp, *service)
+	routes.NewESTHttpRoutes(lHttp, routers.DataPlane, *service, conf.CSRLimits)
+	routes.NewDeviceClaimHTTPLayer(routers.DataPlane, *service)
+	if conf.ACMEServer.Enabled {
+		acmeSvc, err := assembleACMEService(conf, caService, *service)
+		if err != nil {
+			return nil, -1, fmt.Errorf("could not assemble ACME Service. Exiting: %s", err)
+		}
+
+		routes.NewACMEHttpRoutes(lHttp, routers.DataPlane, acmeSvc, conf.ACMEServer.ExternalURL)
+	}
+	routes.NewFeatureFlagsHTTPLayer(httpGrp, flags)
+	routes.NewStatusHTTPLayer(httpGrp, monitor)
+	routes.NewLogLevelsHTTPLayer(httpGrp, "DMS Manager")
+	port, err := routes.RunHttpRouters(lHttp, routers, conf.Server, serviceInfo)
 	if err != nil {
 		return nil, -1, fmt.Errorf("could not run DMS Manager http server: %s", err)
 	}
 
+	startDependencyMonitoring(monitor, conf.DependencyMonitoring, lHealth)
+
 	return service, port, nil
 }
 
 func AssembleDMSManagerService(conf config.DMSconfig, caService services.CAService, deviceService services.DeviceManagerService) (*services.DMSManagerService, error) {
+	flags := featureflags.NewFeatureFlags(featureflags.DMSManagerFlags, conf.FeatureFlags, helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Feature Flags"))
+	return assembleDMSManagerService(conf, caService, deviceService, flags, health.NewMonitor("dms-manager", 0, helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Dependency Monitoring")))
+}
+
+// assembleDMSManagerService registers the storage and event bus dependencies of the service in the monitor.
+func assembleDMSManagerService(conf config.DMSconfig, caService services.CAService, deviceService services.DeviceManagerService, flags *featureflags.FeatureFlags, monitor *health.Monitor) (*services.DMSManagerService, error) {
 	lSvc := helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Service")
 	lMessaging := helpers.SetupLogger(conf.PublisherEventBus.LogLevel, "DMS Manager", "Event Bus")
 	lStorage := helpers.SetupLogger(conf.Storage.LogLevel, "DMS Manager", "Storage")
@@ -44,17 +97,39 @@ func AssembleDMSManagerService(conf config.DMSconfig, caService services.CAServi
 		return nil, fmt.Errorf("could not read downstream certificate: %s", err)
 	}
 
-	devStorage, err := createDMSStorageInstance(lStorage, conf.Storage)
+	devStorage, statsStorage, policyStorage, tokenStorage, claimCodeStorage, auditStorage, err := createDMSStorageInstance(lStorage, conf.Storage, conf.FaultInjection)
 	if err != nil {
 		return nil, fmt.Errorf("could not create dms storage instance: %s", err)
 	}
 
+	if conf.DebugTrace.Enabled {
+		devStorage = debugtrace.NewDMSRepo(devStorage, debugtrace.Default(), "dms-manager")
+	}
+
+	monitor.Register("storage", models.DependencyKindStorage, true, health.StorageCheck(devStorage))
+
+	keyGenEngine, err := createServerKeyGenEngine(helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Server KeyGen Engine"), conf.ServerKeyGen)
+	if err != nil {
+		return nil, fmt.Errorf("could not create server key generation crypto engine: %s", err)
+	}
+
 	svc := services.NewDMSManagerService(services.DMSManagerBuilder{
 		Logger:                lSvc,
 		DMSStorage:            devStorage,
 		CAClient:              caService,
 		DevManagerCli:         deviceService,
 		DownstreamCertificate: downCert,
+		ACMEEABSecret:         []byte(conf.ACMEExternalAccountBinding.HMACSecret),
+		KeyGenEngine:          keyGenEngine,
+
+		EnrollmentStatsStorage: statsStorage,
+
+		EnrollmentPolicyStorage: policyStorage,
+		RegistrationApproval:    conf.RegistrationApproval.Enabled,
+
+		BootstrapTokenStorage:  tokenStorage,
+		ClaimCodeStorage:       claimCodeStorage,
+		EnrollmentAuditStorage: auditStorage,
 	})
 
 	dmsSvc := svc.(*services.DMSManagerServiceBackend)
@@ -66,25 +141,174 @@ func AssembleDMSManagerService(conf config.DMSconfig, caService services.CAServi
 			return nil, fmt.Errorf("could not create Event Bus publisher: %s", err)
 		}
 
-		svc = eventpub.NewDMSEventPublisher(&eventpub.CloudEventMiddlewarePublisher{
+		if conf.FaultInjection.Enabled {
+			injector, err := chaos.NewInjector("event bus", conf.FaultInjection.EventBus, lMessaging)
+			if err != nil {
+				return nil, err
+			}
+			pub = chaos.NewPublisher(pub, injector)
+		}
+
+		var eventpublisher eventpub.ICloudEventMiddlewarePublisher = &eventpub.CloudEventMiddlewarePublisher{
 			Publisher: pub,
 			ServiceID: "dms-manager",
 			Logger:    lMessaging,
-		})(svc)
-	} //this utilizes the middlewares from within the CA service (if svc.Service.func is uses instead of regular svc.func)
+		}
+
+		if conf.DebugTrace.Enabled {
+			eventpublisher = debugtrace.NewEventPublisher(eventpublisher, debugtrace.Default(), "dms-manager")
+		}
+
+		svc = eventpub.NewDMSEventPublisher(eventpublisher)(svc)
+
+		registerEventBusDependency(monitor, "publisher-event-bus", conf.PublisherEventBus)
+		monitor.SetPublisher(eventpublisher)
+	}
+
+	svc = featureflags.NewDMSManagerFeatureFlagsMiddleware(flags)(svc)
+
+	if conf.DebugTrace.Enabled {
+		svc = debugtrace.NewDMSManagerTraceMiddleware(debugtrace.Default())(svc)
+	}
+
+	//this utilizes the middlewares from within the CA service (if svc.Service.func is uses instead of regular svc.func)
 	dmsSvc.SetService(svc)
 
+	if conf.SupersededRevocationMonitoring.Enabled {
+		lMonitor := helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "Superseded Revocation")
+		log.Infof("Superseded certificates revocation monitoring is enabled")
+		revocatorJob := jobs.NewSupersededRevocator(caService, svc, lMonitor)
+		scheduler := jobs.NewJobScheduler(conf.SupersededRevocationMonitoring, lMonitor, revocatorJob)
+		scheduler.Start()
+	}
+
+	if conf.CASuccession.Enabled {
+		lMonitor := helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "CA Succession")
+		log.Infof("CA succession monitoring is enabled")
+
+		renewBeforeDays := conf.CASuccession.RenewBeforeDays
+		if renewBeforeDays <= 0 {
+			renewBeforeDays = 30
+		}
+
+		successorJob := jobs.NewCASuccessor(caService, svc, renewBeforeDays, lMonitor)
+		scheduler := jobs.NewJobScheduler(conf.CASuccession.CryptoMonitoring, lMonitor, successorJob)
+		scheduler.Start()
+	}
+
+	if conf.CARotation.Enabled {
+		lMonitor := helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "CA Rotation")
+		log.Infof("CA rotation campaigns processing is enabled")
+		rotatorJob := jobs.NewCARotator(caService, svc, lMonitor)
+		scheduler := jobs.NewJobScheduler(conf.CARotation, lMonitor, rotatorJob)
+		scheduler.Start()
+	}
+
 	return &svc, nil
 }
 
-func createDMSStorageInstance(logger *log.Entry, conf config.PluggableStorageEngine) (storage.DMSRepo, error) {
-	storage, err := builder.BuildStorageEngine(logger, conf)
+func assembleACMEService(conf config.DMSconfig, caService services.CAService, dmsService services.DMSManagerService) (services.ACMEService, error) {
+	lSvc := helpers.SetupLogger(conf.Logs.Level, "DMS Manager", "ACME")
+	lStorage := helpers.SetupLogger(conf.Storage.LogLevel, "DMS Manager", "ACME Storage")
+
+	accountStorage, orderStorage, err := createACMEStorageInstance(lStorage, conf.Storage, conf.FaultInjection)
+	if err != nil {
+		return nil, fmt.Errorf("could not create ACME storage instance: %s", err)
+	}
+
+	log.Infof("ACME server is enabled")
+	return services.NewACMEService(services.ACMEServiceBuilder{
+		Logger:         lSvc,
+		AccountStorage: accountStorage,
+		OrderStorage:   orderStorage,
+		DMSClient:      dmsService,
+		CAClient:       caService,
+		EABSecret:      []byte(conf.ACMEExternalAccountBinding.HMACSecret),
+		HTTP01Port:     conf.ACMEServer.HTTP01Port,
+	}), nil
+}
+
+func createDMSStorageInstance(logger *log.Entry, conf config.PluggableStorageEngine, faults config.FaultInjection) (storage.DMSRepo, storage.DMSEnrollmentStatsRepo, storage.DMSEnrollmentPoliciesRepo, storage.DMSBootstrapTokensRepo, storage.DMSClaimCodesRepo, storage.DMSEnrollmentAuditsRepo, error) {
+	storage, err := builder.BuildAndMigrateStorageEngine(logger, conf)
 	if err != nil {
-		return nil, fmt.Errorf("could not create storage engine: %s", err)
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not create storage engine: %s", err)
+	}
+
+	if faults.Enabled {
+		injector, err := chaos.NewInjector("storage", faults.Storage, logger)
+		if err != nil {
+			return nil, nil, nil, nil, nil, nil, err
+		}
+		storage = chaos.NewStorageEngine(storage, injector)
 	}
+
 	dmsStorage, err := storage.GetDMSStorage()
 	if err != nil {
-		return nil, fmt.Errorf("could not get device storage: %s", err)
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get device storage: %s", err)
+	}
+
+	statsStorage, err := storage.GetDMSEnrollmentStatsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get DMS enrollment stats storage: %s", err)
+	}
+
+	policyStorage, err := storage.GetDMSEnrollmentPoliciesStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get DMS enrollment policies storage: %s", err)
+	}
+
+	tokenStorage, err := storage.GetDMSBootstrapTokensStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get DMS bootstrap tokens storage: %s", err)
+	}
+
+	claimCodeStorage, err := storage.GetDMSClaimCodesStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get DMS claim codes storage: %s", err)
+	}
+
+	auditStorage, err := storage.GetDMSEnrollmentAuditsStorage()
+	if err != nil {
+		return nil, nil, nil, nil, nil, nil, fmt.Errorf("could not get DMS enrollment audits storage: %s", err)
+	}
+
+	return dmsStorage, statsStorage, policyStorage, tokenStorage, claimCodeStorage, auditStorage, nil
+}
+
+func createACMEStorageInstance(logger *log.Entry, conf config.PluggableStorageEngine, faults config.FaultInjection) (storage.ACMEAccountsRepo, storage.ACMEOrdersRepo, error) {
+	engine, err := builder.BuildAndMigrateStorageEngine(logger, conf)
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not create storage engine: %s", err)
+	}
+
+	if faults.Enabled {
+		injector, err := chaos.NewInjector("storage", faults.Storage, logger)
+		if err != nil {
+			return nil, nil, err
+		}
+		engine = chaos.NewStorageEngine(engine, injector)
+	}
+
+	accountStorage, err := engine.GetACMEAccountStorage()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get ACME account storage: %s", err)
+	}
+
+	orderStorage, err := engine.GetACMEOrderStorage()
+	if err != nil {
+		return nil, nil, fmt.Errorf("could not get ACME order storage: %s", err)
+	}
+
+	return accountStorage, orderStorage, nil
+}
+
+func createServerKeyGenEngine(logger *log.Entry, conf config.ServerKeyGen) (cryptoengines.CryptoEngine, error) {
+	switch {
+	case conf.CryptoEngine.Golang != nil:
+		return cryptoengines.NewGolangPEMEngine(logger, *conf.CryptoEngine.Golang)
+	case conf.CryptoEngine.HashicorpVault != nil:
+		return cryptoengines.NewVaultKV2Engine(logger, *conf.CryptoEngine.HashicorpVault)
+	default:
+		return nil, nil
 	}
-	return dmsStorage, nil
 }
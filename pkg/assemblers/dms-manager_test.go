@@ -9,20 +9,27 @@ import (
 	"crypto/tls"
 	"crypto/x509"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 
 	"github.com/globalsign/est"
 	"github.com/google/uuid"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/memory"
 	"golang.org/x/crypto/ocsp"
 )
 
@@ -81,6 +88,241 @@ func TestCreateDMS(t *testing.T) {
 	}
 }
 
+func TestDMSSigningProfileURLs(t *testing.T) {
+	dmsMgr, _, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	dmsSample := services.CreateDMSInput{
+		ID:   "1234-5678",
+		Name: "MyIotFleet",
+	}
+	dmsSample.Settings.EnrollmentSettings.SigningProfile.OCSPServers = []string{"ocsp.lamassu.io"}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.CreateDMS(context.Background(), dmsSample)
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected error %s creating a DMS with a relative OCSP URL, got %v", errs.ErrValidateBadRequest, err)
+	}
+
+	dmsSample.Settings.EnrollmentSettings.SigningProfile.OCSPServers = []string{"https://ocsp.lamassu.io"}
+	dms, err := dmsMgr.HttpDeviceManagerSDK.CreateDMS(context.Background(), dmsSample)
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	dms.Settings.EnrollmentSettings.SigningProfile.CRLDistributionPoints = []string{"ftp://crl.lamassu.io/root.crl"}
+	_, err = dmsMgr.HttpDeviceManagerSDK.UpdateDMS(context.Background(), services.UpdateDMSInput{DMS: *dms})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected error %s updating a DMS with a non http CRL URL, got %v", errs.ErrValidateBadRequest, err)
+	}
+}
+
+func TestCreateDMSDuplicatePublicKey(t *testing.T) {
+	dmsMgr, _, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "dms-a"}, key)
+
+	dms, err := dmsMgr.Service.CreateDMS(context.Background(), services.CreateDMSInput{
+		ID:   "dms-a",
+		Name: "Fleet A",
+		CSR:  (*models.X509CertificateRequest)(csr),
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	if dms.PublicKeyFingerprint != helpers.PublicKeyFingerprint(csr.RawSubjectPublicKeyInfo) {
+		t.Fatalf("unexpected DMS public key fingerprint: %s", dms.PublicKeyFingerprint)
+	}
+
+	shadowCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "dms-b"}, key)
+	_, err = dmsMgr.Service.CreateDMS(context.Background(), services.CreateDMSInput{
+		ID:   "dms-b",
+		Name: "Fleet B",
+		CSR:  (*models.X509CertificateRequest)(shadowCsr),
+	})
+
+	var conflict *errs.DMSPublicKeyConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a public key conflict, got: %v", err)
+	}
+
+	if conflict.DMSID != "dms-a" {
+		t.Fatalf("conflict should point to DMS 'dms-a', got '%s'", conflict.DMSID)
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.CreateDMS(context.Background(), services.CreateDMSInput{
+		ID:   "dms-b",
+		Name: "Fleet B",
+		CSR:  (*models.X509CertificateRequest)(shadowCsr),
+	})
+	if !errors.Is(err, errs.ErrDMSPublicKeyConflict) {
+		t.Fatalf("expected %s through the HTTP API, got: %v", errs.ErrDMSPublicKeyConflict, err)
+	}
+}
+
+func TestEnrollmentPolicyCRUD(t *testing.T) {
+	ctx := context.Background()
+	dmsMgr, _, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	sdk := dmsMgr.HttpDeviceManagerSDK
+	_, err = sdk.CreateEnrollmentPolicy(ctx, services.CreateEnrollmentPolicyInput{Name: "broken", SubjectRegex: "("})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected %s for an invalid subject regex, got: %v", errs.ErrValidateBadRequest, err)
+	}
+
+	policy, err := sdk.CreateEnrollmentPolicy(ctx, services.CreateEnrollmentPolicyInput{
+		ID:           "factory-gateways",
+		Name:         "Factory Gateways",
+		SubjectRegex: "^gw-[0-9]+$",
+		KeyTypes:     []models.KeyType{models.KeyType(x509.ECDSA)},
+		MinKeyBits:   256,
+	})
+	if err != nil {
+		t.Fatalf("could not create enrollment policy: %s", err)
+	}
+
+	_, err = sdk.CreateEnrollmentPolicy(ctx, services.CreateEnrollmentPolicyInput{ID: policy.ID, Name: "Duplicate"})
+	if !errors.Is(err, errs.ErrDMSEnrollmentPolicyAlreadyExists) {
+		t.Fatalf("expected %s, got: %v", errs.ErrDMSEnrollmentPolicyAlreadyExists, err)
+	}
+
+	policy.MinKeyBits = 384
+	_, err = sdk.UpdateEnrollmentPolicy(ctx, services.UpdateEnrollmentPolicyInput{Policy: *policy})
+	if err != nil {
+		t.Fatalf("could not update enrollment policy: %s", err)
+	}
+
+	policy, err = sdk.GetEnrollmentPolicyByID(ctx, services.GetEnrollmentPolicyByIDInput{ID: policy.ID})
+	if err != nil {
+		t.Fatalf("could not get enrollment policy: %s", err)
+	}
+
+	if policy.MinKeyBits != 384 || policy.SubjectRegex != "^gw-[0-9]+$" {
+		t.Fatalf("unexpected enrollment policy: %+v", policy)
+	}
+
+	policies := []models.DMSEnrollmentPolicy{}
+	_, err = sdk.GetEnrollmentPolicies(ctx, services.GetEnrollmentPoliciesInput{
+		ListInput: resources.ListInput[models.DMSEnrollmentPolicy]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(policy models.DMSEnrollmentPolicy) {
+				policies = append(policies, policy)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not list enrollment policies: %s", err)
+	}
+
+	if len(policies) != 1 {
+		t.Fatalf("expected 1 enrollment policy, got %d", len(policies))
+	}
+
+	err = sdk.DeleteEnrollmentPolicy(ctx, services.DeleteEnrollmentPolicyInput{ID: policy.ID})
+	if err != nil {
+		t.Fatalf("could not delete enrollment policy: %s", err)
+	}
+
+	_, err = sdk.GetEnrollmentPolicyByID(ctx, services.GetEnrollmentPolicyByIDInput{ID: policy.ID})
+	if !errors.Is(err, errs.ErrDMSEnrollmentPolicyNotFound) {
+		t.Fatalf("expected %s, got: %v", errs.ErrDMSEnrollmentPolicyNotFound, err)
+	}
+}
+
+func TestEnrollmentPolicyRegistrationApproval(t *testing.T) {
+	ctx := context.Background()
+	_, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	// The shared test server keeps the registration approval disabled
+	svc := services.NewDMSManagerService(services.DMSManagerBuilder{
+		Logger:                  helpers.SetupLogger(config.Info, "Test Case", "DMS Manager"),
+		DMSStorage:              memory.NewDMSManagerRepository(),
+		CAClient:                testServers.CA.Service,
+		DevManagerCli:           testServers.DeviceManager.Service,
+		EnrollmentPolicyStorage: memory.NewDMSEnrollmentPoliciesRepository(),
+		RegistrationApproval:    true,
+	})
+
+	settings := models.DMSSettings{
+		EnrollmentSettings: models.EnrollmentSettings{
+			EnrollmentProtocol: models.EST,
+			EnrollmentCA:       "enroll-ca",
+			EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+				AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate),
+				AuthOptionsMTLS: models.AuthOptionsClientCertificate{
+					ChainLevelValidation: -1,
+					ValidationCAs:        []string{"boot-ca"},
+				},
+			},
+		},
+	}
+
+	_, err = svc.CreateEnrollmentPolicy(ctx, services.CreateEnrollmentPolicyInput{
+		ID:           "factory-gateways",
+		Name:         "Factory Gateways",
+		SubjectRegex: "^gw-[0-9]+$",
+		KeyTypes:     []models.KeyType{models.KeyType(x509.ECDSA)},
+		MinKeyBits:   256,
+		AllowedCAIDs: []string{"enroll-ca", "boot-ca"},
+	})
+	if err != nil {
+		t.Fatalf("could not create enrollment policy: %s", err)
+	}
+
+	register := func(id, cn string) *models.DMS {
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: cn}, key)
+		dms, err := svc.CreateDMS(ctx, services.CreateDMSInput{
+			ID:       id,
+			Name:     id,
+			Metadata: map[string]any{},
+			Settings: settings,
+			CSR:      (*models.X509CertificateRequest)(csr),
+		})
+		if err != nil {
+			t.Fatalf("could not create DMS %s: %s", id, err)
+		}
+
+		return dms
+	}
+
+	dms := register("gw-approved", "gw-01")
+	if dms.Status != models.ActiveDMSStatus || dms.EnrollmentPolicyID != "factory-gateways" {
+		t.Fatalf("DMS should be auto-approved by the enrollment policy, got status '%s' and policy '%s'", dms.Status, dms.EnrollmentPolicyID)
+	}
+
+	dms = register("gw-pending", "laptop-01")
+	if dms.Status != models.PendingApprovalDMSStatus || dms.EnrollmentPolicyID != "" {
+		t.Fatalf("DMS should be pending approval, got status '%s' and policy '%s'", dms.Status, dms.EnrollmentPolicyID)
+	}
+
+	_, err = svc.UpdateDMSStatus(ctx, services.UpdateDMSStatusInput{ID: dms.ID, Status: models.DMSStatus("REVOKED")})
+	if !errors.Is(err, errs.ErrDMSInvalidStatus) {
+		t.Fatalf("expected %s, got: %v", errs.ErrDMSInvalidStatus, err)
+	}
+
+	dms, err = svc.UpdateDMSStatus(ctx, services.UpdateDMSStatusInput{ID: dms.ID, Status: models.ActiveDMSStatus})
+	if err != nil {
+		t.Fatalf("could not approve DMS: %s", err)
+	}
+
+	if dms.Status != models.ActiveDMSStatus {
+		t.Fatalf("DMS should be approved, got status '%s'", dms.Status)
+	}
+}
+
 func TestESTEnroll(t *testing.T) {
 	// t.Parallel()
 	ctx := context.Background()
@@ -306,7 +548,7 @@ func TestESTEnroll(t *testing.T) {
 			},
 		},
 		{
-			name: "OK/PreRegistration",
+			name: "OK/ServerKeyGen",
 			run: func() (caCert, cert *x509.Certificate, key any, err error) {
 				bootstrapCA, err := createCA("boot", "1y", "1m")
 				if err != nil {
@@ -319,7 +561,6 @@ func TestESTEnroll(t *testing.T) {
 				}
 
 				dms, err := createDMS(func(in *services.CreateDMSInput) {
-					in.Settings.EnrollmentSettings.RegistrationMode = models.PreRegistration
 					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
 					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
 						bootstrapCA.ID,
@@ -348,26 +589,23 @@ func TestESTEnroll(t *testing.T) {
 					InsecureSkipVerify:    true,
 				}
 
+				// the CSR is only used for its subject, the key is generated by the server
 				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
-				enrollKey, _ := helpers.GenerateRSAKey(2048)
-				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+				csrKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, csrKey)
 
-				_, err = testServers.DeviceManager.Service.CreateDevice(ctx, services.CreateDeviceInput{
-					ID:        deviceID,
-					Alias:     deviceID,
-					Tags:      []string{},
-					Metadata:  map[string]any{},
-					DMSID:     dms.ID,
-					Icon:      "test2",
-					IconColor: "#000001",
-				})
+				enrollCRT, keyDer, err := estCli.ServerKeyGen(context.Background(), enrollCSR)
 				if err != nil {
-					t.Fatalf("could not register device: %s", err)
+					t.Fatalf("unexpected error while enrolling with server key generation: %s", err)
 				}
 
-				enrollCRT, err := estCli.Enroll(context.Background(), enrollCSR)
+				enrollKey, err := x509.ParsePKCS8PrivateKey(keyDer)
 				if err != nil {
-					t.Fatalf("unexpected error while enrolling: %s", err)
+					t.Fatalf("could not parse server generated key: %s", err)
+				}
+
+				if enrollCRT.Subject.CommonName != deviceID {
+					t.Fatalf("unexpected certificate common name: %s", enrollCRT.Subject.CommonName)
 				}
 
 				return (*x509.Certificate)(enrollCA.Certificate.Certificate), enrollCRT, enrollKey, nil
@@ -379,7 +617,7 @@ func TestESTEnroll(t *testing.T) {
 
 				priv, ok := key.(*rsa.PrivateKey)
 				if !ok {
-					t.Fatalf("could not cast priv key into RSA")
+					t.Fatal("server generated keys must default to RSA")
 				}
 
 				valid, err := helpers.ValidateCertAndPrivKey(cert, priv, nil)
@@ -397,7 +635,7 @@ func TestESTEnroll(t *testing.T) {
 			},
 		},
 		{
-			name: "Err/PreRegistrationWithUnregisteredDevice",
+			name: "OK/PreRegistration",
 			run: func() (caCert, cert *x509.Certificate, key any, err error) {
 				bootstrapCA, err := createCA("boot", "1y", "1m")
 				if err != nil {
@@ -443,75 +681,143 @@ func TestESTEnroll(t *testing.T) {
 				enrollKey, _ := helpers.GenerateRSAKey(2048)
 				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
 
-				_, err = estCli.Enroll(context.Background(), enrollCSR)
-				return nil, nil, nil, err
+				_, err = testServers.DeviceManager.Service.CreateDevice(ctx, services.CreateDeviceInput{
+					ID:        deviceID,
+					Alias:     deviceID,
+					Tags:      []string{},
+					Metadata:  map[string]any{},
+					DMSID:     dms.ID,
+					Icon:      "test2",
+					IconColor: "#000001",
+				})
+				if err != nil {
+					t.Fatalf("could not register device: %s", err)
+				}
+
+				enrollCRT, err := estCli.Enroll(context.Background(), enrollCSR)
+				if err != nil {
+					t.Fatalf("unexpected error while enrolling: %s", err)
+				}
+
+				return (*x509.Certificate)(enrollCA.Certificate.Certificate), enrollCRT, enrollKey, nil
 			},
-			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
-				if err == nil {
-					t.Fatalf("expected error. Got none")
+			resultCheck: func(caCert *x509.Certificate, cert *x509.Certificate, key any, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
 				}
 
-				expectedErr := "device not preregistered"
-				if !strings.Contains(err.Error(), expectedErr) {
-					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+				priv, ok := key.(*rsa.PrivateKey)
+				if !ok {
+					t.Fatalf("could not cast priv key into RSA")
+				}
+
+				valid, err := helpers.ValidateCertAndPrivKey(cert, priv, nil)
+				if err != nil {
+					t.Fatalf("could not validate cert and key. Got error: %s", err)
+				}
+
+				if !valid {
+					t.Fatalf("private key does not match public key")
+				}
+
+				if err = helpers.ValidateCertificate(caCert, cert, true); err != nil {
+					t.Fatalf("could not validate certificate with CA: %s", err)
 				}
 			},
 		},
 		{
-			name: "Err/UnauthorizedValidationCA",
+			name: "OK/PreRegisteredDeviceClass",
 			run: func() (caCert, cert *x509.Certificate, key any, err error) {
 				bootstrapCA, err := createCA("boot", "1y", "1m")
 				if err != nil {
 					t.Fatalf("could not create bootstrap CA: %s", err)
 				}
 
-				enrollCA, err := createCA("enroll", "1y", "1m")
+				enrollCA, err := createCA("enroll", "1y", "1d")
 				if err != nil {
 					t.Fatalf("could not create Enrollment CA: %s", err)
 				}
 
+				gatewayValidity := models.TimeDuration(2 * time.Hour)
+				sensorValidity := models.TimeDuration(5 * time.Hour)
 				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.RegistrationMode = models.PreRegistration
 					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
 					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
 						bootstrapCA.ID,
 					}
+					in.Settings.EnrollmentSettings.DeviceClassProfiles = []models.DeviceClassProfile{
+						{Name: "gateway", Tags: []string{"gateway"}, Validity: &gatewayValidity},
+						{Name: "sensor", Tags: []string{"sensor"}, Validity: &sensorValidity},
+					}
 				})
 				if err != nil {
 					t.Fatalf("could not create DMS: %s", err)
 				}
 
-				fakeKey, _ := helpers.GenerateRSAKey(2048)
-				fakeCert, _ := helpers.GenerateSelfSignedCertificate(fakeKey, "my-fake-cert")
+				bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+				bootCrt, err := testServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
+					CAID:         bootstrapCA.ID,
+					CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+					SignVerbatim: true,
+				})
+				if err != nil {
+					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+				}
+
 				estCli := est.Client{
 					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
 					AdditionalPathSegment: dms.ID,
-					Certificates:          []*x509.Certificate{fakeCert},
-					PrivateKey:            fakeKey,
+					Certificates:          []*x509.Certificate{(*x509.Certificate)(bootCrt.Certificate)},
+					PrivateKey:            bootKey,
 					InsecureSkipVerify:    true,
 				}
 
 				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
-				enrollKey, _ := helpers.GenerateRSAKey(2048)
+				enrollKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
 				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
 
-				_, err = estCli.Enroll(context.Background(), enrollCSR)
-				return nil, nil, nil, err
+				_, err = testServers.DeviceManager.Service.CreateDevice(ctx, services.CreateDeviceInput{
+					ID:        deviceID,
+					Alias:     deviceID,
+					Tags:      []string{"sensor"},
+					Metadata:  map[string]any{},
+					DMSID:     dms.ID,
+					Icon:      "test2",
+					IconColor: "#000001",
+				})
+				if err != nil {
+					t.Fatalf("could not register device: %s", err)
+				}
+
+				enrollCRT, err := estCli.Enroll(context.Background(), enrollCSR)
+				if err != nil {
+					t.Fatalf("unexpected error while enrolling: %s", err)
+				}
+
+				return (*x509.Certificate)(enrollCA.Certificate.Certificate), enrollCRT, enrollKey, nil
 			},
-			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
-				if err == nil {
-					t.Fatalf("expected error. Got none")
+			resultCheck: func(caCert *x509.Certificate, cert *x509.Certificate, key any, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
 				}
 
-				expectedErr := "invalid certificate"
-				if !strings.Contains(err.Error(), expectedErr) {
-					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+				if err = helpers.ValidateCertificate(caCert, cert, true); err != nil {
+					t.Fatalf("could not validate certificate with CA: %s", err)
+				}
+
+				// The sensor class validity shortens the 1 day issuance expiration of the enrollment CA.
+				expectedNotAfter := time.Now().Add(5 * time.Hour)
+				if cert.NotAfter.Before(expectedNotAfter.Add(-time.Minute)) || cert.NotAfter.After(expectedNotAfter) {
+					t.Fatalf("expected certificate to expire at %s, got %s", expectedNotAfter, cert.NotAfter)
 				}
 			},
 		},
 		{
-			name: "Err/ExpiredCertificate",
+			name: "Err/PreRegistrationWithUnregisteredDevice",
 			run: func() (caCert, cert *x509.Certificate, key any, err error) {
-				bootstrapCA, err := createCA("boot", "1y", "3s")
+				bootstrapCA, err := createCA("boot", "1y", "1m")
 				if err != nil {
 					t.Fatalf("could not create bootstrap CA: %s", err)
 				}
@@ -522,6 +828,7 @@ func TestESTEnroll(t *testing.T) {
 				}
 
 				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.RegistrationMode = models.PreRegistration
 					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
 					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
 						bootstrapCA.ID,
@@ -554,8 +861,6 @@ func TestESTEnroll(t *testing.T) {
 				enrollKey, _ := helpers.GenerateRSAKey(2048)
 				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
 
-				time.Sleep(3 * time.Second)
-
 				_, err = estCli.Enroll(context.Background(), enrollCSR)
 				return nil, nil, nil, err
 			},
@@ -564,14 +869,14 @@ func TestESTEnroll(t *testing.T) {
 					t.Fatalf("expected error. Got none")
 				}
 
-				expectedErr := "invalid certificate"
+				expectedErr := "device not preregistered"
 				if !strings.Contains(err.Error(), expectedErr) {
 					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
 				}
 			},
 		},
 		{
-			name: "Err/RevokedCertificate",
+			name: "OK/PreRegistrationClaim",
 			run: func() (caCert, cert *x509.Certificate, key any, err error) {
 				bootstrapCA, err := createCA("boot", "1y", "1m")
 				if err != nil {
@@ -584,6 +889,7 @@ func TestESTEnroll(t *testing.T) {
 				}
 
 				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.RegistrationMode = models.PreRegistration
 					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
 					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
 						bootstrapCA.ID,
@@ -604,15 +910,6 @@ func TestESTEnroll(t *testing.T) {
 					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
 				}
 
-				_, err = testServers.CA.Service.UpdateCertificateStatus(context.Background(), services.UpdateCertificateStatusInput{
-					SerialNumber:     bootCrt.SerialNumber,
-					NewStatus:        models.StatusRevoked,
-					RevocationReason: ocsp.KeyCompromise,
-				})
-				if err != nil {
-					t.Fatalf("could not revoke Bootstrap Certificate: %s", err)
-				}
-
 				estCli := est.Client{
 					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
 					AdditionalPathSegment: dms.ID,
@@ -625,198 +922,1637 @@ func TestESTEnroll(t *testing.T) {
 				enrollKey, _ := helpers.GenerateRSAKey(2048)
 				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
 
-				_, err = estCli.Enroll(context.Background(), enrollCSR)
-				return nil, nil, nil, err
-			},
-			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
-				if err == nil {
-					t.Fatalf("expected error. Got none")
-				}
+				claim, err := dmsMgr.HttpDeviceManagerSDK.PreregisterDevices(ctx, services.PreregisterDevicesInput{
+					DMSID:     dms.ID,
+					DeviceIDs: []string{deviceID, deviceID, " "},
+				})
+				if err != nil {
+					t.Fatalf("could not preregister device: %s", err)
+				}
 
-				expectedErr := "certificate is revoked"
+				if len(claim.Registered) != 1 || claim.Registered[0] != deviceID {
+					t.Fatalf("expected device '%s' to be preregistered, got %+v", deviceID, claim)
+				}
+
+				claim, err = dmsMgr.HttpDeviceManagerSDK.PreregisterDevices(ctx, services.PreregisterDevicesInput{
+					DMSID:     dms.ID,
+					DeviceIDs: []string{deviceID},
+				})
+				if err != nil {
+					t.Fatalf("could not preregister device: %s", err)
+				}
+
+				if len(claim.AlreadyRegistered) != 1 {
+					t.Fatalf("expected device '%s' to be already registered, got %+v", deviceID, claim)
+				}
+
+				enrollCRT, err := estCli.Enroll(context.Background(), enrollCSR)
+				if err != nil {
+					t.Fatalf("unexpected error while enrolling: %s", err)
+				}
+
+				return (*x509.Certificate)(enrollCA.Certificate.Certificate), enrollCRT, enrollKey, nil
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if err = helpers.ValidateCertificate(caCert, cert, true); err != nil {
+					t.Fatalf("could not validate certificate with CA: %s", err)
+				}
+			},
+		},
+		{
+			name: "Err/PreRegistrationClaimedByOtherDMS",
+			run: func() (caCert, cert *x509.Certificate, key any, err error) {
+				bootstrapCA, err := createCA("boot", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create bootstrap CA: %s", err)
+				}
+
+				enrollCA, err := createCA("enroll", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create Enrollment CA: %s", err)
+				}
+
+				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.RegistrationMode = models.PreRegistration
+					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+						bootstrapCA.ID,
+					}
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+				bootCrt, err := testServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
+					CAID:         bootstrapCA.ID,
+					CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+					SignVerbatim: true,
+				})
+				if err != nil {
+					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+				}
+
+				estCli := est.Client{
+					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+					AdditionalPathSegment: dms.ID,
+					Certificates:          []*x509.Certificate{(*x509.Certificate)(bootCrt.Certificate)},
+					PrivateKey:            bootKey,
+					InsecureSkipVerify:    true,
+				}
+
+				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+				enrollKey, _ := helpers.GenerateRSAKey(2048)
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+
+				otherDMS, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.RegistrationMode = models.PreRegistration
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				_, err = dmsMgr.HttpDeviceManagerSDK.PreregisterDevices(ctx, services.PreregisterDevicesInput{
+					DMSID:     otherDMS.ID,
+					DeviceIDs: []string{deviceID},
+				})
+				if err != nil {
+					t.Fatalf("could not preregister device: %s", err)
+				}
+
+				claim, err := dmsMgr.HttpDeviceManagerSDK.PreregisterDevices(ctx, services.PreregisterDevicesInput{
+					DMSID:     dms.ID,
+					DeviceIDs: []string{deviceID},
+				})
+				if err != nil {
+					t.Fatalf("could not preregister device: %s", err)
+				}
+
+				if _, failed := claim.Failed[deviceID]; !failed {
+					t.Fatalf("device claimed by another DMS should fail, got %+v", claim)
+				}
+
+				_, err = estCli.Enroll(context.Background(), enrollCSR)
+				return nil, nil, nil, err
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err == nil {
+					t.Fatalf("expected error. Got none")
+				}
+
+				expectedErr := "device not preregistered"
 				if !strings.Contains(err.Error(), expectedErr) {
 					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
 				}
 			},
 		},
-		// TODO: Find a way of testing this. As of now, this causes a problem since the Testing Instance is launched under
-		// dev.lamassu.test domain. Then the DMS requests an OCSP/CRL request to https://dev.lamassu.test/api/va/crl/xxxx which
-		// is unreachable. We need a way of proxying dev.lamassu.test => localhost:xxxx (each test has a random port).
-		// {
-		// 	name: "Err/ExternalRevokedCertificate",
-		// 	run: func() (caCert, cert *x509.Certificate, key any, err error) {
-		// 		_, externalTestServers, err := StartDMSManagerServiceTestServer(t, false)
-		// 		if err != nil {
-		// 			t.Fatalf("could not create Second DMS Manager test server: %s", err)
-		// 		}
+		{
+			name: "Err/UnauthorizedValidationCA",
+			run: func() (caCert, cert *x509.Certificate, key any, err error) {
+				bootstrapCA, err := createCA("boot", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create bootstrap CA: %s", err)
+				}
 
-		// 		lifespanCABootDur, _ := models.ParseDuration("1y")
-		// 		issuanceCABootDur, _ := models.ParseDuration("1m")
-		// 		bootstrapCA, err := externalTestServers.CA.Service.CreateCA(context.Background(), services.CreateCAInput{
-		// 			KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 224},
-		// 			Subject:            models.Subject{CommonName: "ExternalCA"},
-		// 			CAExpiration:       models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&lifespanCABootDur)},
-		// 			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&issuanceCABootDur)},
-		// 			Metadata:           map[string]any{},
-		// 		})
-		// 		if err != nil {
-		// 			t.Fatalf("could not create external Bootstrap CA: %s", err)
-		// 		}
+				enrollCA, err := createCA("enroll", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create Enrollment CA: %s", err)
+				}
+
+				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+						bootstrapCA.ID,
+					}
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				fakeKey, _ := helpers.GenerateRSAKey(2048)
+				fakeCert, _ := helpers.GenerateSelfSignedCertificate(fakeKey, "my-fake-cert")
+				estCli := est.Client{
+					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+					AdditionalPathSegment: dms.ID,
+					Certificates:          []*x509.Certificate{fakeCert},
+					PrivateKey:            fakeKey,
+					InsecureSkipVerify:    true,
+				}
+
+				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+				enrollKey, _ := helpers.GenerateRSAKey(2048)
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+
+				_, err = estCli.Enroll(context.Background(), enrollCSR)
+				return nil, nil, nil, err
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err == nil {
+					t.Fatalf("expected error. Got none")
+				}
+
+				expectedErr := "invalid certificate"
+				if !strings.Contains(err.Error(), expectedErr) {
+					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+				}
+			},
+		},
+		{
+			name: "Err/ServerKeyGenUnauthorizedValidationCA",
+			run: func() (caCert, cert *x509.Certificate, key any, err error) {
+				bootstrapCA, err := createCA("boot", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create bootstrap CA: %s", err)
+				}
+
+				enrollCA, err := createCA("enroll", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create Enrollment CA: %s", err)
+				}
+
+				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+						bootstrapCA.ID,
+					}
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				fakeKey, _ := helpers.GenerateRSAKey(2048)
+				fakeCert, _ := helpers.GenerateSelfSignedCertificate(fakeKey, "my-fake-cert")
+				estCli := est.Client{
+					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+					AdditionalPathSegment: dms.ID,
+					Certificates:          []*x509.Certificate{fakeCert},
+					PrivateKey:            fakeKey,
+					InsecureSkipVerify:    true,
+				}
+
+				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+				csrKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, csrKey)
+
+				_, _, err = estCli.ServerKeyGen(context.Background(), enrollCSR)
+				return nil, nil, nil, err
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err == nil {
+					t.Fatalf("expected error. Got none")
+				}
+
+				expectedErr := "invalid certificate"
+				if !strings.Contains(err.Error(), expectedErr) {
+					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+				}
+
+				var estErr interface{ StatusCode() int }
+				if !errors.As(err, &estErr) || estErr.StatusCode() != http.StatusUnauthorized {
+					t.Fatalf("expected status %d. Got error %v", http.StatusUnauthorized, err)
+				}
+			},
+		},
+		{
+			name: "Err/ExpiredCertificate",
+			run: func() (caCert, cert *x509.Certificate, key any, err error) {
+				bootstrapCA, err := createCA("boot", "1y", "3s")
+				if err != nil {
+					t.Fatalf("could not create bootstrap CA: %s", err)
+				}
+
+				enrollCA, err := createCA("enroll", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create Enrollment CA: %s", err)
+				}
+
+				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+						bootstrapCA.ID,
+					}
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+				bootCrt, err := testServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
+					CAID:         bootstrapCA.ID,
+					CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+					SignVerbatim: true,
+				})
+				if err != nil {
+					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+				}
+
+				estCli := est.Client{
+					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+					AdditionalPathSegment: dms.ID,
+					Certificates:          []*x509.Certificate{(*x509.Certificate)(bootCrt.Certificate)},
+					PrivateKey:            bootKey,
+					InsecureSkipVerify:    true,
+				}
+
+				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+				enrollKey, _ := helpers.GenerateRSAKey(2048)
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+
+				time.Sleep(3 * time.Second)
+
+				_, err = estCli.Enroll(context.Background(), enrollCSR)
+				return nil, nil, nil, err
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err == nil {
+					t.Fatalf("expected error. Got none")
+				}
+
+				expectedErr := "invalid certificate"
+				if !strings.Contains(err.Error(), expectedErr) {
+					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+				}
+			},
+		},
+		{
+			name: "Err/RevokedCertificate",
+			run: func() (caCert, cert *x509.Certificate, key any, err error) {
+				bootstrapCA, err := createCA("boot", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create bootstrap CA: %s", err)
+				}
+
+				enrollCA, err := createCA("enroll", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create Enrollment CA: %s", err)
+				}
+
+				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+						bootstrapCA.ID,
+					}
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+				bootCrt, err := testServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
+					CAID:         bootstrapCA.ID,
+					CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+					SignVerbatim: true,
+				})
+				if err != nil {
+					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+				}
+
+				_, err = testServers.CA.Service.UpdateCertificateStatus(context.Background(), services.UpdateCertificateStatusInput{
+					SerialNumber:     bootCrt.SerialNumber,
+					NewStatus:        models.StatusRevoked,
+					RevocationReason: ocsp.KeyCompromise,
+				})
+				if err != nil {
+					t.Fatalf("could not revoke Bootstrap Certificate: %s", err)
+				}
+
+				estCli := est.Client{
+					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+					AdditionalPathSegment: dms.ID,
+					Certificates:          []*x509.Certificate{(*x509.Certificate)(bootCrt.Certificate)},
+					PrivateKey:            bootKey,
+					InsecureSkipVerify:    true,
+				}
+
+				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+				enrollKey, _ := helpers.GenerateRSAKey(2048)
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+
+				_, err = estCli.Enroll(context.Background(), enrollCSR)
+				return nil, nil, nil, err
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err == nil {
+					t.Fatalf("expected error. Got none")
+				}
+
+				expectedErr := "certificate is revoked"
+				if !strings.Contains(err.Error(), expectedErr) {
+					t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+				}
+			},
+		},
+		// TODO: Find a way of testing this. As of now, this causes a problem since the Testing Instance is launched under
+		// dev.lamassu.test domain. Then the DMS requests an OCSP/CRL request to https://dev.lamassu.test/api/va/crl/xxxx which
+		// is unreachable. We need a way of proxying dev.lamassu.test => localhost:xxxx (each test has a random port).
+		// {
+		// 	name: "Err/ExternalRevokedCertificate",
+		// 	run: func() (caCert, cert *x509.Certificate, key any, err error) {
+		// 		_, externalTestServers, err := StartDMSManagerServiceTestServer(t, false)
+		// 		if err != nil {
+		// 			t.Fatalf("could not create Second DMS Manager test server: %s", err)
+		// 		}
+
+		// 		lifespanCABootDur, _ := models.ParseDuration("1y")
+		// 		issuanceCABootDur, _ := models.ParseDuration("1m")
+		// 		bootstrapCA, err := externalTestServers.CA.Service.CreateCA(context.Background(), services.CreateCAInput{
+		// 			KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 224},
+		// 			Subject:            models.Subject{CommonName: "ExternalCA"},
+		// 			CAExpiration:       models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&lifespanCABootDur)},
+		// 			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&issuanceCABootDur)},
+		// 			Metadata:           map[string]any{},
+		// 		})
+		// 		if err != nil {
+		// 			t.Fatalf("could not create external Bootstrap CA: %s", err)
+		// 		}
+
+		// 		importedBootstrapCA, err := testServers.CA.Service.ImportCA(context.Background(), services.ImportCAInput{
+		// 			ID:                 fmt.Sprintf("my-external-CA-%s", uuid.NewString()),
+		// 			CAType:             models.CertificateTypeExternal,
+		// 			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&issuanceCABootDur)},
+		// 			CACertificate:      bootstrapCA.Certificate.Certificate,
+		// 		})
+		// 		if err != nil {
+		// 			t.Fatalf("could not import external Bootstrap CA: %s", err)
+		// 		}
+
+		// 		enrollCA, err := createCA("enroll", "1y", "1m")
+		// 		if err != nil {
+		// 			t.Fatalf("could not create Enrollment CA: %s", err)
+		// 		}
+
+		// 		dms, err := createDMS(func(in *services.CreateDMSInput) {
+		// 			in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+		// 			in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+		// 				importedBootstrapCA.ID,
+		// 			}
+		// 		})
+		// 		if err != nil {
+		// 			t.Fatalf("could not create DMS: %s", err)
+		// 		}
+
+		// 		bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+		// 		bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+		// 		bootCrt, err := externalTestServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
+		// 			CAID:         bootstrapCA.ID,
+		// 			CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+		// 			SignVerbatim: true,
+		// 		})
+		// 		if err != nil {
+		// 			t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+		// 		}
+
+		// 		_, err = externalTestServers.CA.Service.UpdateCertificateStatus(context.Background(), services.UpdateCertificateStatusInput{
+		// 			SerialNumber:     bootCrt.SerialNumber,
+		// 			NewStatus:        models.StatusRevoked,
+		// 			RevocationReason: ocsp.KeyCompromise,
+		// 		})
+		// 		if err != nil {
+		// 			t.Fatalf("could not revoke Bootstrap Certificate: %s", err)
+		// 		}
+
+		// 		estCli := est.Client{
+		// 			Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+		// 			AdditionalPathSegment: dms.ID,
+		// 			Certificates:          []*x509.Certificate{(*x509.Certificate)(bootCrt.Certificate)},
+		// 			PrivateKey:            bootKey,
+		// 			InsecureSkipVerify:    true,
+		// 		}
+
+		// 		deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+		// 		enrollKey, _ := helpers.GenerateRSAKey(2048)
+		// 		enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+
+		// 		_, err = estCli.Enroll(context.Background(), enrollCSR)
+		// 		return nil, nil, nil, err
+		// 	},
+		// 	resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+		// 		if err == nil {
+		// 			t.Fatalf("expected error. Got none")
+		// 		}
+
+		// 		expectedErr := "certificate is revoked"
+		// 		if !strings.Contains(err.Error(), expectedErr) {
+		// 			t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
+		// 		}
+		// 	},
+		// },
+		{
+			name: "OK/PEMOutput",
+			run: func() (caCert, cert *x509.Certificate, key any, err error) {
+				bootstrapCA, err := createCA("boot", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create bootstrap CA: %s", err)
+				}
+
+				enrollCA, err := createCA("enroll", "1y", "1m")
+				if err != nil {
+					t.Fatalf("could not create Enrollment CA: %s", err)
+				}
+
+				dms, err := createDMS(func(in *services.CreateDMSInput) {
+					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
+					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
+						bootstrapCA.ID,
+					}
+				})
+				if err != nil {
+					t.Fatalf("could not create DMS: %s", err)
+				}
+
+				bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
+				bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+				bootCrt, err := testServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
+					CAID:         bootstrapCA.ID,
+					CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+					SignVerbatim: true,
+				})
+				if err != nil {
+					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+				}
+
+				estCli := pemESTClient{
+					baseEndpoint: fmt.Sprintf("https://localhost:%d/.well-known/est/%s", dmsMgr.Port, dms.ID),
+					cert:         (*x509.Certificate)(bootCrt.Certificate),
+					key:          bootKey,
+				}
+
+				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
+				enrollKey, _ := helpers.GenerateRSAKey(2048)
+				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+
+				enrollCRT, err := estCli.Enroll(enrollCSR)
+				if err != nil {
+					t.Fatalf("unexpected error while enrolling: %s", err)
+				}
+
+				return (*x509.Certificate)(enrollCA.Certificate.Certificate), enrollCRT, enrollKey, nil
+			},
+			resultCheck: func(caCert *x509.Certificate, cert *x509.Certificate, key any, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				priv, ok := key.(*rsa.PrivateKey)
+				if !ok {
+					t.Fatal("could not cast priv key into RSA")
+				}
+
+				valid, err := helpers.ValidateCertAndPrivKey(cert, priv, nil)
+				if err != nil {
+					t.Fatalf("could not validate cert and key. Got error: %s", err)
+				}
+
+				if !valid {
+					t.Fatalf("private key does not match public key")
+				}
+
+				if err = helpers.ValidateCertificate(caCert, cert, true); err != nil {
+					t.Fatalf("could not validate certificate with CA: %s", err)
+				}
+			},
+		},
+	}
+
+	for _, tc := range testcases {
+		tc := tc
+
+		t.Run(tc.name, func(t *testing.T) {
+			tc.resultCheck(tc.run())
+		})
+	}
+}
+
+func TestESTEnrollBootstrapToken(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	caDur := models.TimeDuration(time.Hour * 24)
+	issuanceDur := models.TimeDuration(time.Hour)
+	enrollCA, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+		KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+		Subject:            models.Subject{CommonName: "enroll"},
+		CAExpiration:       models.Expiration{Type: models.Duration, Duration: &caDur},
+		IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuanceDur},
+	})
+	if err != nil {
+		t.Fatalf("could not create Enrollment CA: %s", err)
+	}
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:   uuid.NewString(),
+		Name: "FirstBootFleet",
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorBootstrapToken),
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{},
+				},
+				RegistrationMode: models.JITP,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	enroll := func(token, deviceID string) (*x509.Certificate, error) {
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, key)
+		estCli := pemESTClient{
+			baseEndpoint: fmt.Sprintf("https://localhost:%d/.well-known/est/%s", dmsMgr.Port, dms.ID),
+			bearerToken:  token,
+		}
+		return estCli.Enroll(csr)
+	}
+
+	deviceID := fmt.Sprintf("first-boot-%s", uuid.NewString())
+	scoped, err := dmsMgr.HttpDeviceManagerSDK.CreateBootstrapToken(ctx, services.CreateBootstrapTokenInput{
+		DMSID:    dms.ID,
+		DeviceID: deviceID,
+	})
+	if err != nil {
+		t.Fatalf("could not create bootstrap token: %s", err)
+	}
+
+	if scoped.Token == "" || scoped.ID != helpers.BootstrapTokenID(scoped.Token) {
+		t.Fatalf("unexpected bootstrap token credentials: %v", scoped)
+	}
+
+	if _, err := enroll("", deviceID); err == nil {
+		t.Fatalf("enrollment without bootstrap token should fail")
+	}
+
+	if _, err := enroll(scoped.Token, "other-device"); err == nil {
+		t.Fatalf("enrollment of a device out of the token scope should fail")
+	}
+
+	crt, err := enroll(scoped.Token, deviceID)
+	if err != nil {
+		t.Fatalf("unexpected error while enrolling with bootstrap token: %s", err)
+	}
+
+	if crt.Subject.CommonName != deviceID || crt.Issuer.CommonName != enrollCA.Subject.CommonName {
+		t.Fatalf("unexpected certificate: subject %s issued by %s", crt.Subject.CommonName, crt.Issuer.CommonName)
+	}
+
+	if _, err := enroll(scoped.Token, deviceID); err == nil {
+		t.Fatalf("bootstrap tokens must be single-use")
+	}
+
+	tokens, err := dmsMgr.HttpDeviceManagerSDK.GetBootstrapTokens(ctx, services.GetBootstrapTokensInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not get bootstrap tokens: %s", err)
+	}
+
+	if len(tokens) != 1 || tokens[0].UsedAt == nil || tokens[0].UsedBy != deviceID {
+		t.Fatalf("bootstrap token should be used by device %s: %v", deviceID, tokens)
+	}
+
+	// Reenrollments require the certificate issued by the DMS
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, key)
+	estCli := pemESTClient{
+		baseEndpoint: fmt.Sprintf("https://localhost:%d/.well-known/est/%s", dmsMgr.Port, dms.ID),
+		bearerToken:  scoped.Token,
+	}
+	if _, err := estCli.ReEnroll(csr); err == nil {
+		t.Fatalf("reenrollment with a bootstrap token should fail")
+	}
+
+	unscoped, err := dmsMgr.HttpDeviceManagerSDK.CreateBootstrapToken(ctx, services.CreateBootstrapTokenInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not create bootstrap token: %s", err)
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.RevokeBootstrapToken(ctx, services.RevokeBootstrapTokenInput{DMSID: dms.ID, TokenID: unscoped.ID})
+	if err != nil {
+		t.Fatalf("could not revoke bootstrap token: %s", err)
+	}
+
+	if _, err := enroll(unscoped.Token, fmt.Sprintf("first-boot-%s", uuid.NewString())); err == nil {
+		t.Fatalf("enrollment with a revoked bootstrap token should fail")
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.RevokeBootstrapToken(ctx, services.RevokeBootstrapTokenInput{DMSID: dms.ID, TokenID: unscoped.ID})
+	if !errors.Is(err, errs.ErrDMSBootstrapTokenNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSBootstrapTokenNotFound, err)
+	}
+
+	// Concurrent enrollments presenting the same token only enroll one device
+	raced, err := dmsMgr.HttpDeviceManagerSDK.CreateBootstrapToken(ctx, services.CreateBootstrapTokenInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not create bootstrap token: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	var enrolled atomic.Int32
+	for i := 0; i < 10; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := enroll(raced.Token, fmt.Sprintf("first-boot-%s", uuid.NewString())); err == nil {
+				enrolled.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if enrolled.Load() != 1 {
+		t.Fatalf("bootstrap token should enroll a single device, enrolled %d", enrolled.Load())
+	}
+}
+
+func TestClaimDevice(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	caDur := models.TimeDuration(time.Hour * 24)
+	issuanceDur := models.TimeDuration(time.Hour)
+	enrollCA, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+		KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+		Subject:            models.Subject{CommonName: "enroll"},
+		CAExpiration:       models.Expiration{Type: models.Duration, Duration: &caDur},
+		IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuanceDur},
+	})
+	if err != nil {
+		t.Fatalf("could not create Enrollment CA: %s", err)
+	}
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:   uuid.NewString(),
+		Name: "ConsumerFleet",
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate),
+					AuthOptionsMTLS: models.AuthOptionsClientCertificate{
+						ValidationCAs: []string{enrollCA.ID},
+					},
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{},
+				},
+				RegistrationMode: models.JITP,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	claim := func(code, deviceID string) (*models.DeviceClaim, error) {
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, key)
+		return dmsMgr.HttpDeviceManagerSDK.ClaimDevice(ctx, services.ClaimDeviceInput{
+			Code:              code,
+			CSR:               csr,
+			InstallerMetadata: map[string]any{"installer": "jane", "site": "home"},
+		})
+	}
+
+	credentials, err := dmsMgr.HttpDeviceManagerSDK.CreateClaimCode(ctx, services.CreateClaimCodeInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not create claim code: %s", err)
+	}
+
+	if credentials.Code == "" || credentials.ID != helpers.ClaimCodeID(credentials.Code) {
+		t.Fatalf("unexpected claim code credentials: %v", credentials)
+	}
+
+	deviceID := fmt.Sprintf("consumer-%s", uuid.NewString())
+	_, err = claim("AAAAA-AAAAA-AAAAA", deviceID)
+	if !errors.Is(err, errs.ErrDMSInvalidClaimCode) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSInvalidClaimCode, err)
+	}
+
+	// codes are typed by hand, so they are case and separator insensitive
+	claimed, err := claim(strings.ToLower(strings.ReplaceAll(credentials.Code, "-", "")), deviceID)
+	if err != nil {
+		t.Fatalf("unexpected error while claiming device: %s", err)
+	}
+
+	if claimed.DeviceID != deviceID || claimed.Certificate.Subject.CommonName != deviceID || claimed.Certificate.Issuer.CommonName != enrollCA.Subject.CommonName {
+		t.Fatalf("unexpected claim: %v", claimed)
+	}
+
+	device, err := testServers.DeviceManager.Service.GetDeviceByID(ctx, services.GetDeviceByIDInput{ID: deviceID})
+	if err != nil {
+		t.Fatalf("could not get claimed device: %s", err)
+	}
+
+	var claimMeta models.DeviceClaimMetadata
+	hasKey, err := helpers.GetMetadataToStruct(device.Metadata, models.DeviceMetadataClaimKey, &claimMeta)
+	if err != nil || !hasKey {
+		t.Fatalf("claimed device should have the %s metadata key: %v", models.DeviceMetadataClaimKey, err)
+	}
+	if claimMeta.ClaimCodeID != credentials.ID || claimMeta.Installer["installer"] != "jane" {
+		t.Fatalf("unexpected claim metadata: %v", claimMeta)
+	}
+
+	_, err = claim(credentials.Code, fmt.Sprintf("consumer-%s", uuid.NewString()))
+	if !errors.Is(err, errs.ErrDMSInvalidClaimCode) {
+		t.Fatalf("claim codes must be single-use, got %v", err)
+	}
+
+	codes, err := dmsMgr.HttpDeviceManagerSDK.GetClaimCodes(ctx, services.GetClaimCodesInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not get claim codes: %s", err)
+	}
+
+	if len(codes) != 1 || codes[0].ClaimedAt == nil || codes[0].ClaimedBy != deviceID || codes[0].InstallerMetadata["site"] != "home" {
+		t.Fatalf("claim code should be claimed by device %s: %v", deviceID, codes)
+	}
+
+	// failed enrollments release the code, so the claim can be retried
+	retried, err := dmsMgr.HttpDeviceManagerSDK.CreateClaimCode(ctx, services.CreateClaimCodeInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not create claim code: %s", err)
+	}
+
+	_, err = claim(retried.Code, deviceID)
+	if !errors.Is(err, errs.ErrDMSEnrollForbidden) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSEnrollForbidden, err)
+	}
+
+	_, err = claim(retried.Code, fmt.Sprintf("consumer-%s", uuid.NewString()))
+	if err != nil {
+		t.Fatalf("claim code should have been released after the failed claim: %s", err)
+	}
+
+	// concurrent claims with the same code enroll a single device
+	raced, err := dmsMgr.HttpDeviceManagerSDK.CreateClaimCode(ctx, services.CreateClaimCodeInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not create claim code: %s", err)
+	}
+
+	var wg sync.WaitGroup
+	var succeeded atomic.Int32
+	for i := 0; i < 5; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if _, err := claim(raced.Code, fmt.Sprintf("consumer-%s", uuid.NewString())); err == nil {
+				succeeded.Add(1)
+			}
+		}()
+	}
+	wg.Wait()
+
+	if succeeded.Load() != 1 {
+		t.Fatalf("expected a single claim to succeed, got %d", succeeded.Load())
+	}
+
+	scoped, err := dmsMgr.HttpDeviceManagerSDK.CreateClaimCode(ctx, services.CreateClaimCodeInput{DMSID: dms.ID, DeviceID: "scoped-device"})
+	if err != nil {
+		t.Fatalf("could not create claim code: %s", err)
+	}
+
+	_, err = claim(scoped.Code, "other-device")
+	if !errors.Is(err, errs.ErrDMSInvalidClaimCode) {
+		t.Fatalf("claim of a device out of the code scope should fail, got %v", err)
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.RevokeClaimCode(ctx, services.RevokeClaimCodeInput{DMSID: dms.ID, CodeID: scoped.ID})
+	if err != nil {
+		t.Fatalf("could not revoke claim code: %s", err)
+	}
+
+	_, err = claim(scoped.Code, "scoped-device")
+	if !errors.Is(err, errs.ErrDMSInvalidClaimCode) {
+		t.Fatalf("claim with a revoked code should fail, got %v", err)
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.RevokeClaimCode(ctx, services.RevokeClaimCodeInput{DMSID: dms.ID, CodeID: scoped.ID})
+	if !errors.Is(err, errs.ErrDMSClaimCodeNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSClaimCodeNotFound, err)
+	}
+}
+
+func TestRevokeDeviceCertificate(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	caDur := models.TimeDuration(time.Hour * 24)
+	issuanceDur := models.TimeDuration(time.Hour)
+	enrollCA, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+		KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+		Subject:            models.Subject{CommonName: "enroll"},
+		CAExpiration:       models.Expiration{Type: models.Duration, Duration: &caDur},
+		IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuanceDur},
+	})
+	if err != nil {
+		t.Fatalf("could not create Enrollment CA: %s", err)
+	}
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:   uuid.NewString(),
+		Name: "CustomerFleet",
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate),
+					AuthOptionsMTLS: models.AuthOptionsClientCertificate{
+						ValidationCAs: []string{enrollCA.ID},
+					},
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{},
+				},
+				RegistrationMode: models.JITP,
+			},
+			SelfServiceSettings: models.SelfServiceSettings{
+				Owners: []string{"customer"},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	credentials, err := dmsMgr.Service.CreateClaimCode(ctx, services.CreateClaimCodeInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not create claim code: %s", err)
+	}
+
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: fmt.Sprintf("device-%s", uuid.NewString())}, key)
+	claimed, err := dmsMgr.Service.ClaimDevice(ctx, services.ClaimDeviceInput{Code: credentials.Code, CSR: csr})
+	if err != nil {
+		t.Fatalf("could not claim device: %s", err)
+	}
+	deviceSN := helpers.SerialNumberToString(claimed.Certificate.SerialNumber)
+
+	// certificates signed out of the DMS enrollments are not revocable by its owners
+	otherCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "other"}, key)
+	other, err := testServers.CA.Service.SignCertificate(ctx, services.SignCertificateInput{
+		CAID:         enrollCA.ID,
+		CertRequest:  (*models.X509CertificateRequest)(otherCSR),
+		SignVerbatim: true,
+	})
+	if err != nil {
+		t.Fatalf("could not sign certificate: %s", err)
+	}
+
+	// certificates requested on behalf of the DMS for devices it does not own are not revocable either
+	ghostCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "ghost"}, key)
+	ghost, err := testServers.CA.Service.SignCertificate(ctx, services.SignCertificateInput{
+		CAID:            enrollCA.ID,
+		CertRequest:     (*models.X509CertificateRequest)(ghostCSR),
+		SignVerbatim:    true,
+		IssuanceContext: &models.CertificateIssuanceContext{DMSID: dms.ID, DeviceID: "ghost"},
+	})
+	if err != nil {
+		t.Fatalf("could not sign certificate: %s", err)
+	}
+
+	unverified := func(id string) context.Context {
+		return context.WithValue(context.Background(), string(identityextractors.CtxAuthID), id)
+	}
+	as := func(id string) context.Context {
+		return context.WithValue(unverified(id), string(identityextractors.CtxAuthVerified), true)
+	}
+
+	_, err = dmsMgr.Service.RevokeDeviceCertificate(ctx, services.RevokeDeviceCertificateInput{DMSID: dms.ID, SerialNumber: deviceSN})
+	if !errors.Is(err, errs.ErrDMSSelfServiceForbidden) {
+		t.Fatalf("anonymous callers must not revoke certificates, got %v", err)
+	}
+
+	_, err = dmsMgr.Service.RevokeDeviceCertificate(unverified("customer"), services.RevokeDeviceCertificateInput{DMSID: dms.ID, SerialNumber: deviceSN})
+	if !errors.Is(err, errs.ErrDMSSelfServiceForbidden) {
+		t.Fatalf("unverified identities must not revoke certificates, got %v", err)
+	}
+
+	_, err = dmsMgr.Service.RevokeDeviceCertificate(as("stranger"), services.RevokeDeviceCertificateInput{DMSID: dms.ID, SerialNumber: deviceSN})
+	if !errors.Is(err, errs.ErrDMSSelfServiceForbidden) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSSelfServiceForbidden, err)
+	}
+
+	_, err = dmsMgr.Service.RevokeDeviceCertificate(as("customer"), services.RevokeDeviceCertificateInput{DMSID: dms.ID, SerialNumber: other.SerialNumber})
+	if !errors.Is(err, errs.ErrDMSCertificateNotOwned) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSCertificateNotOwned, err)
+	}
+
+	_, err = dmsMgr.Service.RevokeDeviceCertificate(as("customer"), services.RevokeDeviceCertificateInput{DMSID: dms.ID, SerialNumber: ghost.SerialNumber})
+	if !errors.Is(err, errs.ErrDMSCertificateNotOwned) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSCertificateNotOwned, err)
+	}
+
+	revoked, err := dmsMgr.Service.RevokeDeviceCertificate(as("customer"), services.RevokeDeviceCertificateInput{
+		DMSID:            dms.ID,
+		SerialNumber:     deviceSN,
+		RevocationReason: ocsp.KeyCompromise,
+	})
+	if err != nil {
+		t.Fatalf("unexpected error while revoking device certificate: %s", err)
+	}
+
+	if revoked.Status != models.StatusRevoked || revoked.RevocationReason != ocsp.KeyCompromise {
+		t.Fatalf("certificate should be revoked with reason key compromise: %v", revoked)
+	}
+}
+
+func TestCARotation(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	caDur := models.TimeDuration(time.Hour * 24)
+	issuanceDur := models.TimeDuration(time.Hour)
+	enrollCA, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+		KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+		Subject:            models.Subject{CommonName: "enroll"},
+		CAExpiration:       models.Expiration{Type: models.Duration, Duration: &caDur},
+		IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuanceDur},
+	})
+	if err != nil {
+		t.Fatalf("could not create Enrollment CA: %s", err)
+	}
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:   uuid.NewString(),
+		Name: "Fleet",
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate),
+					AuthOptionsMTLS: models.AuthOptionsClientCertificate{
+						ValidationCAs: []string{enrollCA.ID},
+					},
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{},
+				},
+				RegistrationMode: models.JITP,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	devices := []string{}
+	for i := 0; i < 3; i++ {
+		credentials, err := dmsMgr.Service.CreateClaimCode(ctx, services.CreateClaimCodeInput{DMSID: dms.ID})
+		if err != nil {
+			t.Fatalf("could not create claim code: %s", err)
+		}
+
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: fmt.Sprintf("device-%s", uuid.NewString())}, key)
+		claimed, err := dmsMgr.Service.ClaimDevice(ctx, services.ClaimDeviceInput{Code: credentials.Code, CSR: csr})
+		if err != nil {
+			t.Fatalf("could not claim device: %s", err)
+		}
+		devices = append(devices, claimed.DeviceID)
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.ProcessCARotationBatch(ctx, services.ProcessCARotationBatchInput{CAID: enrollCA.ID})
+	if !errors.Is(err, errs.ErrCARotationNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrCARotationNotFound, err)
+	}
+
+	rotation, err := dmsMgr.HttpDeviceManagerSDK.StartCARotation(ctx, services.StartCARotationInput{CAID: enrollCA.ID, BatchSize: 2})
+	if err != nil {
+		t.Fatalf("could not start CA rotation: %s", err)
+	}
+
+	if rotation.SuccessorCAID == "" || !slices.Contains(rotation.UpdatedDMSs, dms.ID) {
+		t.Fatalf("unexpected rotation: %v", rotation)
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.StartCARotation(ctx, services.StartCARotationInput{CAID: enrollCA.ID})
+	if !errors.Is(err, errs.ErrCARotationAlreadyStarted) {
+		t.Fatalf("expected error %s, got %v", errs.ErrCARotationAlreadyStarted, err)
+	}
+
+	dms, err = dmsMgr.Service.GetDMSByID(ctx, services.GetDMSByIDInput{ID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not get DMS: %s", err)
+	}
+
+	if dms.Settings.EnrollmentSettings.EnrollmentCA != rotation.SuccessorCAID {
+		t.Fatalf("expected DMS to enroll with the successor CA %s, got %s", rotation.SuccessorCAID, dms.Settings.EnrollmentSettings.EnrollmentCA)
+	}
+
+	if !slices.Contains(dms.Settings.ReEnrollmentSettings.AdditionalValidationCAs, enrollCA.ID) {
+		t.Fatalf("expected rotated CA to validate reenrollments")
+	}
+
+	for i := 0; i < 10 && rotation.Status == models.CARotationRunning; i++ {
+		rotation, err = dmsMgr.HttpDeviceManagerSDK.ProcessCARotationBatch(ctx, services.ProcessCARotationBatchInput{CAID: enrollCA.ID})
+		if err != nil {
+			t.Fatalf("could not process CA rotation batch: %s", err)
+		}
+	}
+
+	if rotation.Status != models.CARotationCompleted {
+		t.Fatalf("expected rotation to be completed, got %s", rotation.Status)
+	}
+
+	// the cross certificate of the successor is issued by the rotated CA too
+	if rotation.ProcessedCertificates != 4 || len(rotation.ReenrolledDevices) != 3 {
+		t.Fatalf("expected 4 certificates processed and 3 devices reenrolled, got %d and %d", rotation.ProcessedCertificates, len(rotation.ReenrolledDevices))
+	}
+
+	for _, deviceID := range devices {
+		device, err := testServers.DeviceManager.Service.GetDeviceByID(ctx, services.GetDeviceByIDInput{ID: deviceID})
+		if err != nil {
+			t.Fatalf("could not get device %s: %s", deviceID, err)
+		}
+
+		if forced, _ := device.Metadata[models.DeviceMetadataForceReenrollKey].(bool); !forced {
+			t.Fatalf("expected device %s to be forced to reenroll", deviceID)
+		}
+	}
+
+	ca, err := testServers.CA.Service.GetCAByID(ctx, services.GetCAByIDInput{CAID: enrollCA.ID})
+	if err != nil {
+		t.Fatalf("could not get CA: %s", err)
+	}
+
+	if paused, _ := ca.Metadata[models.CAMetadataIssuancePausedKey].(bool); !paused {
+		t.Fatalf("expected issuance of the rotated CA to be paused")
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.ProcessCARotationBatch(ctx, services.ProcessCARotationBatchInput{CAID: enrollCA.ID})
+	if !errors.Is(err, errs.ErrCARotationCompleted) {
+		t.Fatalf("expected error %s, got %v", errs.ErrCARotationCompleted, err)
+	}
+}
+
+func TestESTEnrollContextPolicy(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	caDur := models.TimeDuration(time.Hour * 24)
+	issuanceDur := models.TimeDuration(time.Hour)
+	enrollCA, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+		KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+		Subject:            models.Subject{CommonName: "enroll"},
+		CAExpiration:       models.Expiration{Type: models.Duration, Duration: &caDur},
+		IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuanceDur},
+	})
+	if err != nil {
+		t.Fatalf("could not create Enrollment CA: %s", err)
+	}
+
+	dmsInput := services.CreateDMSInput{
+		ID:   uuid.NewString(),
+		Name: "Plant1",
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorNoAuth),
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{},
+				},
+				RegistrationMode:            models.JITP,
+				EnableReplaceableEnrollment: true,
+				ContextPolicy: models.EnrollmentContextPolicy{
+					Enabled:       true,
+					DefaultAction: models.EnrollmentContextDeny,
+					Rules: []models.EnrollmentContextRule{
+						{Name: "plant-1-gateway", Action: models.EnrollmentContextAllow, GatewayIDs: []string{"gw-1"}},
+					},
+				},
+			},
+		},
+	}
+
+	invalidInput := dmsInput
+	invalidInput.ID = uuid.NewString()
+	invalidInput.Settings.EnrollmentSettings.ContextPolicy = models.EnrollmentContextPolicy{
+		Enabled: true,
+		Rules:   []models.EnrollmentContextRule{{Name: "invalid", Action: models.EnrollmentContextDeny, SourceCIDRs: []string{"10.0.0.0"}}},
+	}
+	_, err = dmsMgr.Service.CreateDMS(ctx, invalidInput)
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Fatalf("expected error %s, got %v", errs.ErrValidateBadRequest, err)
+	}
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, dmsInput)
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	enroll := func(gatewayID string) error {
+		key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: fmt.Sprintf("plant-1-%s", uuid.NewString())}, key)
+		estCli := pemESTClient{
+			baseEndpoint: fmt.Sprintf("https://localhost:%d/.well-known/est/%s", dmsMgr.Port, dms.ID),
+			gatewayID:    gatewayID,
+		}
+		_, err := estCli.Enroll(csr)
+		return err
+	}
+
+	if err := enroll(""); err == nil {
+		t.Fatalf("enrollment out of the gateway should be denied")
+	}
+
+	if err := enroll("gw-1"); err != nil {
+		t.Fatalf("unexpected error while enrolling through the gateway: %s", err)
+	}
+
+	dms.Settings.EnrollmentSettings.ContextPolicy.Rules = append(dms.Settings.EnrollmentSettings.ContextPolicy.Rules, models.EnrollmentContextRule{
+		Name:        "loopback",
+		Action:      models.EnrollmentContextFlag,
+		SourceCIDRs: []string{"127.0.0.0/8", "::1/128"},
+	})
+	dms, err = dmsMgr.Service.UpdateDMS(ctx, services.UpdateDMSInput{DMS: *dms})
+	if err != nil {
+		t.Fatalf("could not update DMS: %s", err)
+	}
+
+	if err := enroll(""); err != nil {
+		t.Fatalf("flagged enrollments should be allowed: %s", err)
+	}
+
+	audits := []models.DMSEnrollmentAudit{}
+	_, err = dmsMgr.HttpDeviceManagerSDK.GetEnrollmentAudits(ctx, services.GetEnrollmentAuditsInput{
+		DMSID: dms.ID,
+		ListInput: resources.ListInput[models.DMSEnrollmentAudit]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(audit models.DMSEnrollmentAudit) {
+				audits = append(audits, audit)
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not get enrollment audits: %s", err)
+	}
+
+	slices.SortFunc(audits, func(a, b models.DMSEnrollmentAudit) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	expected := []struct {
+		action    models.EnrollmentContextAction
+		rule      string
+		gatewayID string
+	}{
+		{action: models.EnrollmentContextDeny},
+		{action: models.EnrollmentContextAllow, rule: "plant-1-gateway", gatewayID: "gw-1"},
+		{action: models.EnrollmentContextFlag, rule: "loopback"},
+	}
+
+	if len(audits) != len(expected) {
+		t.Fatalf("expected %d enrollment audits, got %d", len(expected), len(audits))
+	}
+
+	for i, audit := range audits {
+		if audit.DMSID != dms.ID || audit.DeviceID == "" || audit.SourceIP == "" {
+			t.Errorf("incomplete enrollment audit: %v", audit)
+		}
+
+		if audit.Action != expected[i].action || audit.Rule != expected[i].rule || audit.GatewayID != expected[i].gatewayID {
+			t.Errorf("unexpected enrollment audit %d: %v", i, audit)
+		}
+	}
+
+	_, err = dmsMgr.HttpDeviceManagerSDK.GetEnrollmentAudits(ctx, services.GetEnrollmentAuditsInput{
+		DMSID: "unknown",
+		ListInput: resources.ListInput[models.DMSEnrollmentAudit]{
+			ApplyFunc: func(audit models.DMSEnrollmentAudit) {},
+		},
+	})
+	if !errors.Is(err, errs.ErrDMSNotFound) {
+		t.Fatalf("expected error %s, got %v", errs.ErrDMSNotFound, err)
+	}
+}
+
+func TestDMSEnrollmentStats(t *testing.T) {
+	ctx := context.Background()
+
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
+
+	createCA := func(name string) *models.CACertificate {
+		lifespan := models.TimeDuration(365 * 24 * time.Hour)
+		issuance := models.TimeDuration(30 * 24 * time.Hour)
+		ca, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+			KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+			Subject:            models.Subject{CommonName: name},
+			CAExpiration:       models.Expiration{Type: models.Duration, Duration: &lifespan},
+			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuance},
+			Metadata:           map[string]any{},
+		})
+		if err != nil {
+			t.Fatalf("could not create CA %s: %s", name, err)
+		}
+		return ca
+	}
+
+	bootstrapCA := createCA("boot")
+	enrollCA := createCA("enroll")
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:       uuid.NewString(),
+		Name:     "MyIotFleet",
+		Metadata: map[string]any{},
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate),
+					AuthOptionsMTLS: models.AuthOptionsClientCertificate{
+						ChainLevelValidation: -1,
+						ValidationCAs:        []string{bootstrapCA.ID},
+					},
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{"iot"},
+				},
+				RegistrationMode:            models.JITP,
+				EnableReplaceableEnrollment: true,
+			},
+			ReEnrollmentSettings: models.ReEnrollmentSettings{
+				AdditionalValidationCAs: []string{},
+				ReEnrollmentDelta:       models.TimeDuration(time.Hour),
+			},
+			CADistributionSettings: models.CADistributionSettings{
+				ManagedCAs: []string{},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
+
+	enroll := func(crt *x509.Certificate, key any) error {
+		estCli := est.Client{
+			Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+			AdditionalPathSegment: dms.ID,
+			Certificates:          []*x509.Certificate{crt},
+			PrivateKey:            key,
+			InsecureSkipVerify:    true,
+		}
+
+		enrollKey, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: uuid.NewString()}, enrollKey)
+		_, err := estCli.Enroll(ctx, enrollCSR)
+		return err
+	}
+
+	bootKey, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
+	bootCrt, err := testServers.CA.Service.SignCertificate(ctx, services.SignCertificateInput{
+		CAID:         bootstrapCA.ID,
+		CertRequest:  (*models.X509CertificateRequest)(bootCsr),
+		SignVerbatim: true,
+	})
+	if err != nil {
+		t.Fatalf("could not sign Bootstrap Certificate: %s", err)
+	}
+
+	err = enroll((*x509.Certificate)(bootCrt.Certificate), bootKey)
+	if err != nil {
+		t.Fatalf("unexpected error while enrolling: %s", err)
+	}
+
+	unknownKey, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	unknownCrt, _ := helpers.GenerateSelfSignedCertificate(unknownKey, "unknown-cert")
+	err = enroll(unknownCrt, unknownKey)
+	if err == nil {
+		t.Fatalf("should've failed enrolling with a certificate not issued by the validation CAs")
+	}
 
-		// 		importedBootstrapCA, err := testServers.CA.Service.ImportCA(context.Background(), services.ImportCAInput{
-		// 			ID:                 fmt.Sprintf("my-external-CA-%s", uuid.NewString()),
-		// 			CAType:             models.CertificateTypeExternal,
-		// 			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&issuanceCABootDur)},
-		// 			CACertificate:      bootstrapCA.Certificate.Certificate,
-		// 		})
-		// 		if err != nil {
-		// 			t.Fatalf("could not import external Bootstrap CA: %s", err)
-		// 		}
+	stats, err := dmsMgr.HttpDeviceManagerSDK.GetDMSEnrollmentStats(ctx, services.GetDMSEnrollmentStatsInput{DMSID: dms.ID})
+	if err != nil {
+		t.Fatalf("could not get DMS enrollment stats: %s", err)
+	}
 
-		// 		enrollCA, err := createCA("enroll", "1y", "1m")
-		// 		if err != nil {
-		// 			t.Fatalf("could not create Enrollment CA: %s", err)
-		// 		}
+	if stats.Enroll.Succeeded != 1 || stats.Enroll.Failed[models.EnrollmentFailureAuth] != 1 {
+		t.Errorf("unexpected enrollment counters: %+v", stats.Enroll)
+	}
 
-		// 		dms, err := createDMS(func(in *services.CreateDMSInput) {
-		// 			in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
-		// 			in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
-		// 				importedBootstrapCA.ID,
-		// 			}
-		// 		})
-		// 		if err != nil {
-		// 			t.Fatalf("could not create DMS: %s", err)
-		// 		}
+	if stats.LastSuccessAt == nil || stats.LastFailureAt == nil {
+		t.Errorf("last success and failure dates should be set")
+	}
 
-		// 		bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
-		// 		bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
-		// 		bootCrt, err := externalTestServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
-		// 			CAID:         bootstrapCA.ID,
-		// 			CertRequest:  (*models.X509CertificateRequest)(bootCsr),
-		// 			SignVerbatim: true,
-		// 		})
-		// 		if err != nil {
-		// 			t.Fatalf("could not sign Bootstrap Certificate: %s", err)
-		// 		}
+	_, err = dmsMgr.HttpDeviceManagerSDK.GetDMSEnrollmentStats(ctx, services.GetDMSEnrollmentStatsInput{DMSID: "unknown"})
+	if !errors.Is(err, errs.ErrDMSNotFound) {
+		t.Errorf("should've got DMS not found error, got: %v", err)
+	}
 
-		// 		_, err = externalTestServers.CA.Service.UpdateCertificateStatus(context.Background(), services.UpdateCertificateStatusInput{
-		// 			SerialNumber:     bootCrt.SerialNumber,
-		// 			NewStatus:        models.StatusRevoked,
-		// 			RevocationReason: ocsp.KeyCompromise,
-		// 		})
-		// 		if err != nil {
-		// 			t.Fatalf("could not revoke Bootstrap Certificate: %s", err)
-		// 		}
+	httpCli := http.Client{Transport: &http.Transport{TLSClientConfig: &tls.Config{InsecureSkipVerify: true}}}
+	res, err := httpCli.Get(fmt.Sprintf("https://127.0.0.1:%d/v1/monitoring/metrics", dmsMgr.Port))
+	if err != nil {
+		t.Fatalf("could not get metrics: %s", err)
+	}
+	defer res.Body.Close()
 
-		// 		estCli := est.Client{
-		// 			Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
-		// 			AdditionalPathSegment: dms.ID,
-		// 			Certificates:          []*x509.Certificate{(*x509.Certificate)(bootCrt.Certificate)},
-		// 			PrivateKey:            bootKey,
-		// 			InsecureSkipVerify:    true,
-		// 		}
+	body, _ := io.ReadAll(res.Body)
+	expected := fmt.Sprintf(`lamassu_dms_enrollment_failures_total{dms_id="%s",operation="enroll",reason="auth"} 1`, dms.ID)
+	if !strings.Contains(string(body), expected) {
+		t.Errorf("metrics do not include %s:\n%s", expected, string(body))
+	}
+}
 
-		// 		deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
-		// 		enrollKey, _ := helpers.GenerateRSAKey(2048)
-		// 		enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+func TestIssuanceReport(t *testing.T) {
+	ctx := context.Background()
 
-		// 		_, err = estCli.Enroll(context.Background(), enrollCSR)
-		// 		return nil, nil, nil, err
-		// 	},
-		// 	resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
-		// 		if err == nil {
-		// 			t.Fatalf("expected error. Got none")
-		// 		}
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
 
-		// 		expectedErr := "certificate is revoked"
-		// 		if !strings.Contains(err.Error(), expectedErr) {
-		// 			t.Fatalf("error should contain '%s'. Got error %s", expectedErr, err.Error())
-		// 		}
-		// 	},
-		// },
-		{
-			name: "OK/PEMOutput",
-			run: func() (caCert, cert *x509.Certificate, key any, err error) {
-				bootstrapCA, err := createCA("boot", "1y", "1m")
-				if err != nil {
-					t.Fatalf("could not create bootstrap CA: %s", err)
-				}
+	createCA := func(name string) *models.CACertificate {
+		lifespan := models.TimeDuration(365 * 24 * time.Hour)
+		issuance := models.TimeDuration(30 * 24 * time.Hour)
+		ca, err := testServers.CA.Service.CreateCA(ctx, services.CreateCAInput{
+			KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+			Subject:            models.Subject{CommonName: name},
+			CAExpiration:       models.Expiration{Type: models.Duration, Duration: &lifespan},
+			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: &issuance},
+			Metadata:           map[string]any{},
+		})
+		if err != nil {
+			t.Fatalf("could not create CA %s: %s", name, err)
+		}
+		return ca
+	}
 
-				enrollCA, err := createCA("enroll", "1y", "1m")
-				if err != nil {
-					t.Fatalf("could not create Enrollment CA: %s", err)
-				}
+	enrollCA := createCA("enroll")
+
+	dms, err := dmsMgr.Service.CreateDMS(ctx, services.CreateDMSInput{
+		ID:       uuid.NewString(),
+		Name:     "MyIotFleet",
+		Metadata: map[string]any{},
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentCA:       enrollCA.ID,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorNoAuth),
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Icon:      "BiSolidCreditCardFront",
+					IconColor: "#25ee32-#222222",
+					Metadata:  map[string]any{},
+					Tags:      []string{"iot"},
+				},
+				RegistrationMode:            models.JITP,
+				EnableReplaceableEnrollment: true,
+			},
+			ReEnrollmentSettings: models.ReEnrollmentSettings{
+				AdditionalValidationCAs: []string{},
+				ReEnrollmentDelta:       models.TimeDuration(time.Hour),
+			},
+			CADistributionSettings: models.CADistributionSettings{
+				ManagedCAs: []string{},
+			},
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
+	}
 
-				dms, err := createDMS(func(in *services.CreateDMSInput) {
-					in.Settings.EnrollmentSettings.EnrollmentCA = enrollCA.ID
-					in.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs = []string{
-						bootstrapCA.ID,
-					}
-				})
-				if err != nil {
-					t.Fatalf("could not create DMS: %s", err)
-				}
+	estCli := est.Client{
+		Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+		AdditionalPathSegment: dms.ID,
+		InsecureSkipVerify:    true,
+	}
 
-				bootKey, _ := helpers.GenerateECDSAKey(elliptic.P224())
-				bootCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "boot-cert"}, bootKey)
-				bootCrt, err := testServers.CA.Service.SignCertificate(context.Background(), services.SignCertificateInput{
-					CAID:         bootstrapCA.ID,
-					CertRequest:  (*models.X509CertificateRequest)(bootCsr),
-					SignVerbatim: true,
-				})
-				if err != nil {
-					t.Fatalf("could not sign Bootstrap Certificate: %s", err)
-				}
+	from := time.Now().Add(-time.Hour)
+	for i := 0; i < 2; i++ {
+		enrollKey, _ := helpers.GenerateECDSAKey(elliptic.P256())
+		enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: uuid.NewString()}, enrollKey)
+		_, err = estCli.Enroll(ctx, enrollCSR)
+		if err != nil {
+			t.Fatalf("unexpected error while enrolling: %s", err)
+		}
+	}
 
-				estCli := pemESTClient{
-					baseEndpoint: fmt.Sprintf("https://localhost:%d/.well-known/est/%s", dmsMgr.Port, dms.ID),
-					cert:         (*x509.Certificate)(bootCrt.Certificate),
-					key:          bootKey,
-				}
+	report, err := testServers.DeviceManager.HttpDeviceManagerSDK.GetIssuanceReport(ctx, services.GetIssuanceReportInput{
+		From: from,
+		To:   time.Now().Add(time.Hour),
+	})
+	if err != nil {
+		t.Fatalf("could not get issuance report: %s", err)
+	}
 
-				deviceID := fmt.Sprintf("enrolled-device-%s", uuid.NewString())
-				enrollKey, _ := helpers.GenerateRSAKey(2048)
-				enrollCSR, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, enrollKey)
+	activityOf := func(activities []models.IssuanceActivity, id string) models.IssuanceActivity {
+		for _, activity := range activities {
+			if activity.ID == id {
+				return activity
+			}
+		}
+		return models.IssuanceActivity{}
+	}
 
-				enrollCRT, err := estCli.Enroll(enrollCSR)
-				if err != nil {
-					t.Fatalf("unexpected error while enrolling: %s", err)
-				}
+	if activity := activityOf(report.DMSs, dms.ID); activity.Issued != 2 {
+		t.Errorf("unexpected DMS activity: %+v", activity)
+	}
 
-				return (*x509.Certificate)(enrollCA.Certificate.Certificate), enrollCRT, enrollKey, nil
-			},
-			resultCheck: func(caCert *x509.Certificate, cert *x509.Certificate, key any, err error) {
-				if err != nil {
-					t.Fatalf("unexpected error: %s", err)
-				}
+	if activity := activityOf(report.CAs, enrollCA.ID); activity.Issued != 2 {
+		t.Errorf("unexpected CA activity: %+v", activity)
+	}
 
-				priv, ok := key.(*rsa.PrivateKey)
-				if !ok {
-					t.Fatal("could not cast priv key into RSA")
-				}
+	// Reports are generated synchronously, hence their period is bounded.
+	_, err = testServers.DeviceManager.HttpDeviceManagerSDK.GetIssuanceReport(ctx, services.GetIssuanceReportInput{
+		From: from.Add(-2 * 365 * 24 * time.Hour),
+		To:   from,
+	})
+	if !errors.Is(err, errs.ErrValidateBadRequest) {
+		t.Errorf("should've got error %s, got: %v", errs.ErrValidateBadRequest, err)
+	}
+}
 
-				valid, err := helpers.ValidateCertAndPrivKey(cert, priv, nil)
-				if err != nil {
-					t.Fatalf("could not validate cert and key. Got error: %s", err)
-				}
+func TestESTGetCACerts(t *testing.T) {
+	dmsMgr, testServers, err := StartDMSManagerServiceTestServer(t, false)
+	if err != nil {
+		t.Fatalf("could not create DMS Manager test server: %s", err)
+	}
 
-				if !valid {
-					t.Fatalf("private key does not match public key")
-				}
+	lifespan, _ := models.ParseDuration("1y")
+	issuance, _ := models.ParseDuration("1m")
+	createCA := func(name string, parentID string) *models.CACertificate {
+		ca, err := testServers.CA.Service.CreateCA(context.Background(), services.CreateCAInput{
+			ParentID:           parentID,
+			KeyMetadata:        models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256},
+			Subject:            models.Subject{CommonName: name},
+			CAExpiration:       models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&lifespan)},
+			IssuanceExpiration: models.Expiration{Type: models.Duration, Duration: (*models.TimeDuration)(&issuance)},
+			Metadata:           map[string]any{},
+		})
+		if err != nil {
+			t.Fatalf("could not create CA %s: %s", name, err)
+		}
 
-				if err = helpers.ValidateCertificate(caCert, cert, true); err != nil {
-					t.Fatalf("could not validate certificate with CA: %s", err)
-				}
+		return ca
+	}
+
+	rootCA := createCA("root", "")
+	enrollCA := createCA("enroll", rootCA.ID)
+	managedCA := createCA("managed", "")
+
+	dms, err := dmsMgr.Service.CreateDMS(context.Background(), services.CreateDMSInput{
+		ID:       uuid.NewString(),
+		Name:     "MyIotFleet",
+		Metadata: map[string]any{},
+		Settings: models.DMSSettings{
+			EnrollmentSettings: models.EnrollmentSettings{
+				EnrollmentProtocol: models.EST,
+				EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+					AuthMode: models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate),
+					AuthOptionsMTLS: models.AuthOptionsClientCertificate{
+						ChainLevelValidation: -1,
+						ValidationCAs:        []string{},
+					},
+				},
+				DeviceProvisionProfile: models.DeviceProvisionProfile{
+					Metadata: map[string]any{},
+					Tags:     []string{},
+				},
+				EnrollmentCA:     enrollCA.ID,
+				RegistrationMode: models.JITP,
+			},
+			ReEnrollmentSettings: models.ReEnrollmentSettings{
+				AdditionalValidationCAs: []string{},
+				ReEnrollmentDelta:       models.TimeDuration(time.Hour),
+			},
+			CADistributionSettings: models.CADistributionSettings{
+				IncludeLamassuSystemCA: false,
+				IncludeEnrollmentCA:    true,
+				ManagedCAs:             []string{managedCA.ID, rootCA.ID},
 			},
 		},
+	})
+	if err != nil {
+		t.Fatalf("could not create DMS: %s", err)
 	}
 
-	for _, tc := range testcases {
-		tc := tc
+	estCli := est.Client{
+		Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+		AdditionalPathSegment: dms.ID,
+		InsecureSkipVerify:    true,
+	}
 
-		t.Run(tc.name, func(t *testing.T) {
-			tc.resultCheck(tc.run())
-		})
+	cas, err := estCli.CACerts(context.Background())
+	if err != nil {
+		t.Fatalf("unexpected error while getting CA certificates: %s", err)
+	}
+
+	expected := map[string]*models.CACertificate{
+		"root":    rootCA,
+		"enroll":  enrollCA,
+		"managed": managedCA,
+	}
+	if len(cas) != len(expected) {
+		t.Fatalf("unexpected number of CA certificates. Expected %d, got %d", len(expected), len(cas))
+	}
+
+	for _, ca := range cas {
+		expectedCA, ok := expected[ca.Subject.CommonName]
+		if !ok {
+			t.Fatalf("unexpected CA certificate %s", ca.Subject.CommonName)
+		}
+
+		if !bytes.Equal(ca.Raw, expectedCA.Certificate.Certificate.Raw) {
+			t.Fatalf("CA certificate %s does not match", ca.Subject.CommonName)
+		}
 	}
 }
 
@@ -1071,6 +2807,62 @@ func TestESTReEnroll(t *testing.T) {
 				}
 			},
 		},
+		{
+			name: "OK/ForcedWindowNotOpened",
+			run: func() (caCert *x509.Certificate, cert *x509.Certificate, key any, err error) {
+				dms, enrollmentCA, deviceCrt, deviceKey := prepReenrollScenario(
+					func(in *services.CreateDMSInput) {
+						dur, _ := models.ParseDuration("3s")
+						in.Settings.ReEnrollmentSettings.ReEnrollmentDelta = models.TimeDuration(dur)
+					},
+					"1m",
+				)
+
+				deviceID := deviceCrt.Subject.CommonName
+				_, err = testServers.DeviceManager.Service.UpdateDeviceMetadata(context.Background(), services.UpdateDeviceMetadataInput{
+					ID:       deviceID,
+					Metadata: map[string]any{models.DeviceMetadataForceReenrollKey: true},
+				})
+				if err != nil {
+					t.Fatalf("could not flag device %s: %s", deviceID, err)
+				}
+
+				newCsr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, deviceKey)
+
+				estCli := est.Client{
+					Host:                  fmt.Sprintf("localhost:%d", dmsMgr.Port),
+					AdditionalPathSegment: dms.ID,
+					Certificates:          []*x509.Certificate{deviceCrt},
+					PrivateKey:            deviceKey,
+					InsecureSkipVerify:    true,
+				}
+
+				reEnrollCRT, err := estCli.Reenroll(context.Background(), newCsr)
+				if err != nil {
+					return nil, nil, nil, err
+				}
+
+				device, err := testServers.DeviceManager.Service.GetDeviceByID(context.Background(), services.GetDeviceByIDInput{ID: deviceID})
+				if err != nil {
+					t.Fatalf("could not get device %s: %s", deviceID, err)
+				}
+
+				if _, flagged := device.Metadata[models.DeviceMetadataForceReenrollKey]; flagged {
+					t.Fatalf("force reenrollment flag should be cleared after reenrolling")
+				}
+
+				return enrollmentCA, reEnrollCRT, nil, nil
+			},
+			resultCheck: func(caCert, cert *x509.Certificate, key any, err error) {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if err = helpers.ValidateCertificate(caCert, cert, true); err != nil {
+					t.Fatalf("invalid certificate: %s", err)
+				}
+			},
+		},
 		{
 			name: "OK/AllowExpired",
 			run: func() (caCert *x509.Certificate, cert *x509.Certificate, key any, err error) {
@@ -1324,6 +3116,8 @@ func checkDMS(t *testing.T, dms *models.DMS, dmsSample services.CreateDMSInput)
 type pemESTClient struct {
 	cert         *x509.Certificate
 	key          any
+	bearerToken  string
+	gatewayID    string
 	baseEndpoint string
 }
 
@@ -1335,21 +3129,25 @@ func (c *pemESTClient) ReEnroll(r *x509.CertificateRequest) (*x509.Certificate,
 }
 
 func (c *pemESTClient) commonEnrollPEM(r *x509.CertificateRequest, renew bool) (*x509.Certificate, error) {
-	keyPem, err := helpers.PrivateKeyToPEM(c.key)
-	if err != nil {
-		return nil, err
-	}
+	certificates := []tls.Certificate{}
+	if c.cert != nil {
+		keyPem, err := helpers.PrivateKeyToPEM(c.key)
+		if err != nil {
+			return nil, err
+		}
 
-	cer, err := tls.X509KeyPair([]byte(helpers.CertificateToPEM(c.cert)), []byte(keyPem))
-	if err != nil {
-		return nil, err
+		cer, err := tls.X509KeyPair([]byte(helpers.CertificateToPEM(c.cert)), []byte(keyPem))
+		if err != nil {
+			return nil, err
+		}
+		certificates = append(certificates, cer)
 	}
 
 	client := http.Client{}
 	client.Transport = &http.Transport{
 		TLSClientConfig: &tls.Config{
 			InsecureSkipVerify: true,
-			Certificates:       []tls.Certificate{cer},
+			Certificates:       certificates,
 		},
 	}
 
@@ -1371,6 +3169,12 @@ func (c *pemESTClient) commonEnrollPEM(r *x509.CertificateRequest, renew bool) (
 	req.Header.Set("Accept", "application/x-pem-file")
 	req.Header.Set("Content-Type", "application/pkcs10")
 	req.Header.Set("Content-Transfer-Encoding", "base64")
+	if c.bearerToken != "" {
+		req.Header.Set("Authorization", "Bearer "+c.bearerToken)
+	}
+	if c.gatewayID != "" {
+		req.Header.Set(models.HttpGatewayIDHeader, c.gatewayID)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
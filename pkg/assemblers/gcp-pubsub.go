@@ -0,0 +1,29 @@
+package assemblers
+
+import (
+	"fmt"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors/gcp"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+// AssembleGCPPubSubConnectorWithHTTPServer runs the GCP Pub/Sub connector as a cloud connector, so it is announced
+// and discovered through the connector registration events like the rest of the SDK connectors.
+func AssembleGCPPubSubConnectorWithHTTPServer(conf config.IotGCP, caService services.CAService, serviceInfo models.APIServiceInfo) (*gcp.PubSubConnector, int, error) {
+	lSvc := helpers.SetupLogger(conf.Logs.Level, "GCP Pub/Sub Connector", "Service")
+
+	connector, err := gcp.NewPubSubConnector(lSvc, conf.ConnectorID, conf.GCPPubSub, caService)
+	if err != nil {
+		return nil, -1, fmt.Errorf("could not create GCP Pub/Sub connector: %s", err)
+	}
+
+	port, err := AssembleCloudConnectorWithHTTPServer(conf.CloudConnector, connector, caService, serviceInfo)
+	if err != nil {
+		return nil, -1, err
+	}
+
+	return connector, port, nil
+}
@@ -0,0 +1,118 @@
+package assemblers
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/clients"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/x509engines"
+	log "github.com/sirupsen/logrus"
+)
+
+func newDependencyMonitor(serviceID string, conf config.DependencyMonitoring, logger *log.Entry) (*health.Monitor, error) {
+	timeout := time.Duration(0)
+	if conf.CheckTimeout != "" {
+		var err error
+		timeout, err = models.ParseDuration(conf.CheckTimeout)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse dependency check timeout '%s': %s", conf.CheckTimeout, err)
+		}
+	}
+
+	return health.NewMonitor(serviceID, timeout, logger), nil
+}
+
+func startDependencyMonitoring(monitor *health.Monitor, conf config.DependencyMonitoring, logger *log.Entry) {
+	if !conf.Enabled {
+		return
+	}
+
+	logger.Infof("Dependency monitoring is enabled")
+	scheduler := jobs.NewJobScheduler(conf.CryptoMonitoring, logger, monitor)
+	scheduler.Start()
+}
+
+func registerEventBusDependency(monitor *health.Monitor, name string, conf config.EventBusEngine) {
+	if !conf.Enabled {
+		return
+	}
+
+	if check, ok := health.EventBusCheck(conf); ok {
+		monitor.Register(name, models.DependencyKindEventBus, false, check)
+	}
+}
+
+// registerServiceClientDependency checks the service the client configuration points to. Services assembled in
+// the same process (no hostname configured) are not checked.
+func registerServiceClientDependency(monitor *health.Monitor, name string, conf config.HTTPClient, logger *log.Entry) error {
+	if conf.Hostname == "" {
+		return nil
+	}
+
+	httpCli, err := clients.BuildHTTPClient(conf, logger)
+	if err != nil {
+		return fmt.Errorf("could not build %s client: %s", name, err)
+	}
+
+	monitor.Register(name, models.DependencyKindService, false, health.HTTPServiceCheck(httpCli, clients.BuildURL(conf)))
+	return nil
+}
+
+// cryptoEnginesHealth reports the health of the engines tracking their own availability, indexed by engine ID.
+func cryptoEnginesHealth(caService services.CAService) func() interface{} {
+	return func() interface{} {
+		report := map[string]*models.CryptoEngineHealth{}
+		engines, err := caService.GetCryptoEngineProvider(context.Background())
+		if err != nil {
+			return report
+		}
+
+		for _, engine := range engines {
+			if engine.Health != nil {
+				report[engine.ID] = engine.Health
+			}
+		}
+
+		return report
+	}
+}
+
+// caEngineKeyID returns the key of a CA stored in the engine, so the crypto engine can be probed.
+func caEngineProbeKey(caStorage storage.CACertificatesRepo) func(ctx context.Context, engineID string) (string, error) {
+	return func(ctx context.Context, engineID string) (string, error) {
+		return caEngineKeyID(caStorage, engineID)(ctx)
+	}
+}
+
+func caEngineKeyID(caStorage storage.CACertificatesRepo, engineID string) func(ctx context.Context) (string, error) {
+	return func(ctx context.Context) (string, error) {
+		keyID := ""
+		_, err := caStorage.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+			ExhaustiveRun: false,
+			QueryParams: &resources.QueryParameters{
+				PageSize: 10,
+				Filters: []resources.FilterOption{
+					{Field: "engine_id", FilterOperation: resources.StringEqual, Value: engineID},
+				},
+			},
+			ExtraOpts: map[string]interface{}{},
+			ApplyFunc: func(ca models.CACertificate) {
+				if keyID != "" || ca.Type == models.CertificateTypeExternal || ca.Certificate.Certificate == nil {
+					return
+				}
+				keyID = x509engines.CryptoAssetLRI(x509engines.CertificateAuthority, helpers.SerialNumberToString(ca.Certificate.Certificate.SerialNumber))
+			},
+		})
+
+		return keyID, err
+	}
+}
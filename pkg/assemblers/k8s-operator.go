@@ -0,0 +1,26 @@
+package assemblers
+
+import (
+	"fmt"
+
+	external_clients "github.com/lamassuiot/lamassuiot/v2/pkg/clients/external"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/jobs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+func AssembleKubernetesOperator(conf config.KubernetesOperator, caService services.CAService, dmsService services.DMSManagerService) (*jobs.JobScheduler, error) {
+	lReconciler := helpers.SetupLogger(conf.Logs.Level, "Kubernetes Operator", "Reconciler")
+
+	k8sClient, err := external_clients.NewKubernetesClient(conf.Kubernetes)
+	if err != nil {
+		return nil, fmt.Errorf("could not build Kubernetes client: %s", err)
+	}
+
+	reconciler := jobs.NewKubernetesReconciler(k8sClient, caService, dmsService, lReconciler)
+	scheduler := jobs.NewJobScheduler(conf.Reconciliation, lReconciler, reconciler)
+	scheduler.Start()
+
+	return scheduler, nil
+}
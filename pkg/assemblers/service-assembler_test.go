@@ -4,6 +4,7 @@ import (
 	"crypto/elliptic"
 	"crypto/tls"
 	"fmt"
+	"net"
 	"net/http"
 	"os"
 	"slices"
@@ -66,6 +67,7 @@ type CATestServer struct {
 
 type DMSManagerTestServer struct {
 	Port                 int
+	ACMEHTTP01Port       int
 	Service              services.DMSManagerService
 	HttpDeviceManagerSDK services.DMSManagerService
 	BeforeEach           func() error
@@ -311,6 +313,14 @@ func BuildDMSManagerServiceTestServer(storageEngine *TestStorageEngineConfig, ev
 		return nil, fmt.Errorf("could not save downstream cert. Exiting: %s", err)
 	}
 
+	// Reserve a port for the ACME http-01 challenges served by the tests
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return nil, fmt.Errorf("could not reserve ACME http-01 port. Exiting: %s", err)
+	}
+	acmeHTTP01Port := listener.Addr().(*net.TCPAddr).Port
+	listener.Close()
+
 	svc, port, err := AssembleDMSManagerServiceWithHTTPServer(config.DMSconfig{
 		Logs: config.BaseConfigLogging{
 			Level: config.Info,
@@ -331,6 +341,10 @@ func BuildDMSManagerServiceTestServer(storageEngine *TestStorageEngineConfig, ev
 		PublisherEventBus:         eventBus.config,
 		Storage:                   storageEngine.config,
 		DownstreamCertificateFile: downstreamCertPath,
+		ACMEServer: config.ACMEServer{
+			Enabled:    true,
+			HTTP01Port: acmeHTTP01Port,
+		},
 	},
 		caTestServer.Service,
 		deviceManagerTestServer.Service,
@@ -351,6 +365,7 @@ func BuildDMSManagerServiceTestServer(storageEngine *TestStorageEngineConfig, ev
 	}
 	return &DMSManagerTestServer{
 		Port:                 port,
+		ACMEHTTP01Port:       acmeHTTP01Port,
 		Service:              *svc,
 		HttpDeviceManagerSDK: clients.NewHttpDMSManagerClient(&httpCli, fmt.Sprintf("https://127.0.0.1:%d", port)),
 		BeforeEach: func() error {
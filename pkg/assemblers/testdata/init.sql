@@ -0,0 +1,3 @@
+CREATE DATABASE ca;
+CREATE DATABASE devicemanager;
+CREATE DATABASE dmsmanager;
@@ -18,10 +18,14 @@ func AssembleVAServiceWithHTTPServer(conf config.VAconfig, caService services.CA
 
 	lHttp := helpers.SetupLogger(conf.Server.LogLevel, "VA", "HTTP Server")
 
-	httpEngine := routes.NewGinEngine(lHttp)
-	httpGrp := httpEngine.Group("/")
-	routes.NewValidationRoutes(lHttp, httpGrp, *ocsp, *crl)
-	port, err := routes.RunHttpRouter(lHttp, httpEngine, conf.Server, serviceInfo)
+	routers, err := routes.NewHttpRouters(lHttp, conf.Server)
+	if err != nil {
+		return nil, nil, -1, fmt.Errorf("could not create VA http routers: %s", err)
+	}
+
+	routes.NewValidationRoutes(lHttp, routers.DataPlane, *ocsp, *crl)
+	routes.NewLogLevelsHTTPLayer(routers.Management, "VA")
+	port, err := routes.RunHttpRouters(lHttp, routers, conf.Server, serviceInfo)
 	if err != nil {
 		return nil, nil, -1, fmt.Errorf("could not run VA http server: %s", err)
 	}
@@ -6,6 +6,7 @@ import (
 	"crypto"
 	"crypto/x509"
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
@@ -15,6 +16,7 @@ import (
 
 	external_clients "github.com/lamassuiot/lamassuiot/v2/pkg/clients/external"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
@@ -167,6 +169,52 @@ func TestCRLNumber(t *testing.T) {
 	}
 }
 
+func TestSignedCRL(t *testing.T) {
+	serverTest, err := StartVAServiceTestServer(t)
+	if err != nil {
+		t.Fatalf("could not create VA test server")
+	}
+
+	serverTest.BeforeEach()
+	ca, err := initCAForVA(serverTest)
+	if err != nil {
+		t.Fatalf("could not init CA for VA: %s", err)
+	}
+
+	res, err := http.Get(fmt.Sprintf("%s/crl/%s?signature=jws", serverTest.VA.HttpServerURL, DefaultCAID))
+	if err != nil {
+		t.Fatalf("could not get CRL: %s", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		t.Fatalf("unexpected status code %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(res.Body)
+	if err != nil {
+		t.Fatalf("could not read CRL: %s", err)
+	}
+
+	if _, err = x509.ParseRevocationList(body); err != nil {
+		t.Fatalf("could not parse CRL: %s", err)
+	}
+
+	jws := res.Header.Get(models.JWSSignatureHeader)
+	if jws == "" {
+		t.Fatalf("response should include the %s header", models.JWSSignatureHeader)
+	}
+
+	caCert := (*x509.Certificate)(ca.Certificate.Certificate)
+	if err = helpers.VerifyDetachedJWS(jws, body, caCert.PublicKey); err != nil {
+		t.Fatalf("invalid CRL detached JWS: %s", err)
+	}
+
+	if err = helpers.VerifyDetachedJWS(jws, append(body, 0), caCert.PublicKey); err == nil {
+		t.Fatalf("detached JWS should not verify tampered CRLs")
+	}
+}
+
 func TestPostOCSP(t *testing.T) {
 	t.Parallel()
 
@@ -276,6 +324,71 @@ func TestPostOCSP(t *testing.T) {
 		})
 	}
 }
+
+func TestPostOCSPUnknownCertificate(t *testing.T) {
+	serverTest, err := StartVAServiceTestServer(t)
+	if err != nil {
+		t.Fatalf("could not create VA test server")
+	}
+
+	serverTest.BeforeEach()
+	_, err = initCAForVA(serverTest)
+	if err != nil {
+		t.Fatalf("could not init CA for VA: %s", err)
+	}
+
+	issuerCA, err := serverTest.CA.Service.GetCAByID(context.Background(), services.GetCAByIDInput{CAID: DefaultCAID})
+	if err != nil {
+		t.Fatalf("could not get issuer CA: %s", err)
+	}
+
+	// Certificate never issued (nor imported) by Lamassu
+	unknownCrt, _, err := helpers.GenerateSelfSignedCA(x509.ECDSA, time.Hour, "unknown")
+	if err != nil {
+		t.Fatalf("could not generate certificate: %s", err)
+	}
+
+	_, err = getOCSPResponsePost(serverTest.VA.HttpServerURL, &models.Certificate{Certificate: (*models.X509Certificate)(unknownCrt)}, issuerCA)
+
+	var respErr ocsp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Status != ocsp.Unauthorized {
+		t.Fatalf("should've got an OCSP Unauthorized error response, got: %v", err)
+	}
+}
+
+func TestPostOCSPIssuerMismatch(t *testing.T) {
+	serverTest, err := StartVAServiceTestServer(t)
+	if err != nil {
+		t.Fatalf("could not create VA test server")
+	}
+
+	serverTest.BeforeEach()
+	_, err = initCAForVA(serverTest)
+	if err != nil {
+		t.Fatalf("could not init CA for VA: %s", err)
+	}
+
+	crt, err := generateCertificate(serverTest.CA.Service)
+	if err != nil {
+		t.Fatalf("failed generating crt: %s", err)
+	}
+
+	// The CertID hashes identify a CA that did not issue the certificate
+	otherIssuer, _, err := helpers.GenerateSelfSignedCA(x509.ECDSA, time.Hour, "other-issuer")
+	if err != nil {
+		t.Fatalf("could not generate CA certificate: %s", err)
+	}
+
+	_, err = getOCSPResponsePost(serverTest.VA.HttpServerURL, crt, &models.CACertificate{
+		Certificate: models.Certificate{Certificate: (*models.X509Certificate)(otherIssuer)},
+	})
+
+	var respErr ocsp.ResponseError
+	if !errors.As(err, &respErr) || respErr.Status != ocsp.Unauthorized {
+		t.Fatalf("should've got an OCSP Unauthorized error response, got: %v", err)
+	}
+}
+
 func TestGetOCSP(t *testing.T) {
 	t.Skip("Skipping test for now")
 	serverTest, err := StartVAServiceTestServer(t)
@@ -426,7 +539,7 @@ func getOCSPResponsePost(ocspServerURL string, crt *models.Certificate, issuer *
 
 	response, err := ocsp.ParseResponse(output, (*x509.Certificate)(issuer.Certificate.Certificate))
 	if err != nil {
-		return nil, fmt.Errorf("could not parse OCSP response: %s", err)
+		return nil, fmt.Errorf("could not parse OCSP response: %w", err)
 	}
 
 	return response, nil
@@ -513,3 +626,72 @@ func initCAForVA(testServer *TestServer) (*models.CACertificate, error) {
 }
 
 //Hacer la función de test de getCRL
+
+func TestCAEvents(t *testing.T) {
+	serverTest, err := StartVAServiceTestServer(t)
+	if err != nil {
+		t.Fatalf("could not create VA test server")
+	}
+
+	caSDK := serverTest.CA.HttpCASDK
+
+	serverTest.BeforeEach()
+	ca, err := initCAForVA(serverTest)
+	if err != nil {
+		t.Fatalf("could not init CA for VA: %s", err)
+	}
+
+	for i := 0; i < 2; i++ {
+		_, err := generateCertificate(caSDK)
+		if err != nil {
+			t.Fatalf("could not generate certificate: %s", err)
+		}
+	}
+
+	_, err = external_clients.GetCRLResponse(fmt.Sprintf("%s/crl/%s", serverTest.VA.HttpServerURL, DefaultCAID), (*x509.Certificate)(ca.Certificate.Certificate), nil, true)
+	if err != nil {
+		t.Fatalf("could not get CRL: %s", err)
+	}
+
+	_, err = caSDK.UpdateCAStatus(context.Background(), services.UpdateCAStatusInput{
+		CAID:             DefaultCAID,
+		Status:           models.StatusRevoked,
+		RevocationReason: ocsp.KeyCompromise,
+	})
+	if err != nil {
+		t.Fatalf("could not revoke CA: %s", err)
+	}
+
+	events, err := caSDK.GetCAEvents(context.Background(), services.GetCAEventsInput{CAID: DefaultCAID})
+	if err != nil {
+		t.Fatalf("could not get CA events: %s", err)
+	}
+
+	expected := []models.CAEventType{models.CAEventCreated, models.CAEventCRLGenerated, models.CAEventStatusUpdated, models.CAEventBulkRevocation}
+	if len(events) != len(expected) {
+		t.Fatalf("expected %d events, got %d: %v", len(expected), len(events), events)
+	}
+
+	for i, event := range events {
+		if event.Type != expected[i] {
+			t.Fatalf("event %d: expected %s, got %s", i, expected[i], event.Type)
+		}
+	}
+
+	if events[1].Details["revoked_certificates"] != float64(0) {
+		t.Fatalf("expected an empty CRL, got %v revoked certificates", events[1].Details["revoked_certificates"])
+	}
+
+	if events[2].Details["status"] != string(models.StatusRevoked) {
+		t.Fatalf("expected the CA to be revoked, got status %v", events[2].Details["status"])
+	}
+
+	if events[3].Details["revoked_certificates"] != float64(2) {
+		t.Fatalf("expected 2 revoked certificates, got %v", events[3].Details["revoked_certificates"])
+	}
+
+	_, err = caSDK.GetCAEvents(context.Background(), services.GetCAEventsInput{CAID: "unknown"})
+	if !errors.Is(err, errs.ErrCANotFound) {
+		t.Fatalf("expected error %s, got %s", errs.ErrCANotFound, err)
+	}
+}
@@ -0,0 +1,71 @@
+package chaos
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/sirupsen/logrus"
+)
+
+type countingPublisher struct {
+	published int
+}
+
+func (p *countingPublisher) Publish(topic string, messages ...*message.Message) error {
+	p.published++
+	return nil
+}
+
+func (p *countingPublisher) Close() error {
+	return nil
+}
+
+func TestNewInjectorInvalidConfig(t *testing.T) {
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	if _, err := NewInjector("storage", config.FaultInjectionTarget{Latency: "soon"}, logger); err == nil {
+		t.Fatalf("expected error for invalid latency")
+	}
+
+	if _, err := NewInjector("storage", config.FaultInjectionTarget{ErrorRate: 1.5}, logger); err == nil {
+		t.Fatalf("expected error for invalid error rate")
+	}
+}
+
+func TestInjectorErrorRate(t *testing.T) {
+	injector, err := NewInjector("storage", config.FaultInjectionTarget{ErrorRate: 0.5}, logrus.NewEntry(logrus.StandardLogger()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	injector.random = func() float64 { return 0.2 }
+	if err := injector.Inject("Insert"); !errors.Is(err, errs.ErrInjectedFault) {
+		t.Fatalf("expected injected fault, got %v", err)
+	}
+
+	injector.random = func() float64 { return 0.7 }
+	if err := injector.Inject("Insert"); err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+}
+
+func TestPublisherFaultSkipsPublish(t *testing.T) {
+	injector, err := NewInjector("event bus", config.FaultInjectionTarget{ErrorRate: 1}, logrus.NewEntry(logrus.StandardLogger()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	next := &countingPublisher{}
+	pub := NewPublisher(next, injector)
+
+	if err := pub.Publish("ca.create", message.NewMessage("1", nil)); !errors.Is(err, errs.ErrInjectedFault) {
+		t.Fatalf("expected injected fault, got %v", err)
+	}
+
+	if next.published != 0 {
+		t.Fatalf("message should not reach the event bus")
+	}
+}
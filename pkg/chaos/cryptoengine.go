@@ -0,0 +1,49 @@
+package chaos
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+type cryptoEngine struct {
+	next     cryptoengines.CryptoEngine
+	injector *Injector
+}
+
+// NewCryptoEngine injects faults into the key operations of a crypto engine. The returned signers are not wrapped,
+// so software keys can still be exported (i.e. when migrating a CA key to another engine).
+func NewCryptoEngine(engine cryptoengines.CryptoEngine, injector *Injector) cryptoengines.CryptoEngine {
+	return &cryptoEngine{
+		next:     engine,
+		injector: injector,
+	}
+}
+
+func (e *cryptoEngine) GetEngineConfig() models.CryptoEngineInfo {
+	return e.next.GetEngineConfig()
+}
+
+func (e *cryptoEngine) GetPrivateKeyByID(keyID string) (crypto.Signer, error) {
+	return call(e.injector, "GetPrivateKeyByID", func() (crypto.Signer, error) { return e.next.GetPrivateKeyByID(keyID) })
+}
+
+func (e *cryptoEngine) CreateRSAPrivateKey(keySize int, keyID string) (crypto.Signer, error) {
+	return call(e.injector, "CreateRSAPrivateKey", func() (crypto.Signer, error) { return e.next.CreateRSAPrivateKey(keySize, keyID) })
+}
+
+func (e *cryptoEngine) CreateECDSAPrivateKey(curve elliptic.Curve, keyID string) (crypto.Signer, error) {
+	return call(e.injector, "CreateECDSAPrivateKey", func() (crypto.Signer, error) { return e.next.CreateECDSAPrivateKey(curve, keyID) })
+}
+
+func (e *cryptoEngine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	return call(e.injector, "ImportRSAPrivateKey", func() (crypto.Signer, error) { return e.next.ImportRSAPrivateKey(key, keyID) })
+}
+
+func (e *cryptoEngine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	return call(e.injector, "ImportECDSAPrivateKey", func() (crypto.Signer, error) { return e.next.ImportECDSAPrivateKey(key, keyID) })
+}
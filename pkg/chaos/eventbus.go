@@ -0,0 +1,30 @@
+package chaos
+
+import (
+	"github.com/ThreeDotsLabs/watermill/message"
+)
+
+type publisher struct {
+	next     message.Publisher
+	injector *Injector
+}
+
+// NewPublisher injects faults into the messages published to the event bus (i.e. AMQP).
+func NewPublisher(pub message.Publisher, injector *Injector) message.Publisher {
+	return &publisher{
+		next:     pub,
+		injector: injector,
+	}
+}
+
+func (p *publisher) Publish(topic string, messages ...*message.Message) error {
+	if err := p.injector.Inject("Publish " + topic); err != nil {
+		return err
+	}
+
+	return p.next.Publish(topic, messages...)
+}
+
+func (p *publisher) Close() error {
+	return p.next.Close()
+}
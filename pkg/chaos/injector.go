@@ -0,0 +1,77 @@
+package chaos
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/sirupsen/logrus"
+)
+
+// Injector delays the calls made to a target subsystem and fails a ratio of them.
+type Injector struct {
+	target    string
+	latency   time.Duration
+	errorRate float64
+	random    func() float64
+	logger    *logrus.Entry
+}
+
+func NewInjector(target string, conf config.FaultInjectionTarget, logger *logrus.Entry) (*Injector, error) {
+	var latency time.Duration
+	if conf.Latency != "" {
+		var err error
+		latency, err = time.ParseDuration(conf.Latency)
+		if err != nil {
+			return nil, fmt.Errorf("invalid %s fault injection latency '%s': %w", target, conf.Latency, err)
+		}
+	}
+
+	if conf.ErrorRate < 0 || conf.ErrorRate > 1 {
+		return nil, fmt.Errorf("invalid %s fault injection error rate %f. Must be between 0 and 1", target, conf.ErrorRate)
+	}
+
+	logger.Warnf("fault injection enabled for %s: latency=%s error_rate=%.2f", target, latency, conf.ErrorRate)
+	return &Injector{
+		target:    target,
+		latency:   latency,
+		errorRate: conf.ErrorRate,
+		random:    rand.Float64,
+		logger:    logger,
+	}, nil
+}
+
+// Inject is called before each operation. The operation must not be executed if an error is returned.
+func (i *Injector) Inject(operation string) error {
+	if i.latency > 0 {
+		time.Sleep(i.latency)
+	}
+
+	if i.errorRate > 0 && i.random() < i.errorRate {
+		i.logger.Debugf("injecting fault into %s %s", i.target, operation)
+		return fmt.Errorf("%w: %s %s", errs.ErrInjectedFault, i.target, operation)
+	}
+
+	return nil
+}
+
+func call[T any](i *Injector, operation string, fn func() (T, error)) (T, error) {
+	if err := i.Inject(operation); err != nil {
+		var zero T
+		return zero, err
+	}
+
+	return fn()
+}
+
+func call2[T1 any, T2 any](i *Injector, operation string, fn func() (T1, T2, error)) (T1, T2, error) {
+	if err := i.Inject(operation); err != nil {
+		var zero1 T1
+		var zero2 T2
+		return zero1, zero2, err
+	}
+
+	return fn()
+}
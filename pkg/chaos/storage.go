@@ -0,0 +1,253 @@
+package chaos
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type storageEngine struct {
+	storage.StorageEngine
+	injector *Injector
+}
+
+// NewStorageEngine injects faults into the CA, certificate, device and DMS repositories of the storage engine.
+func NewStorageEngine(engine storage.StorageEngine, injector *Injector) storage.StorageEngine {
+	return &storageEngine{
+		StorageEngine: engine,
+		injector:      injector,
+	}
+}
+
+func (e *storageEngine) GetCAStorage() (storage.CACertificatesRepo, error) {
+	repo, err := e.StorageEngine.GetCAStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &caRepo{next: repo, injector: e.injector}, nil
+}
+
+func (e *storageEngine) GetCertstorage() (storage.CertificatesRepo, error) {
+	repo, err := e.StorageEngine.GetCertstorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &certRepo{next: repo, injector: e.injector}, nil
+}
+
+func (e *storageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, error) {
+	repo, err := e.StorageEngine.GetDeviceStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &deviceRepo{next: repo, injector: e.injector}, nil
+}
+
+func (e *storageEngine) GetDMSStorage() (storage.DMSRepo, error) {
+	repo, err := e.StorageEngine.GetDMSStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dmsRepo{next: repo, injector: e.injector}, nil
+}
+
+type caRepo struct {
+	next     storage.CACertificatesRepo
+	injector *Injector
+}
+
+func (r *caRepo) SelectByType(ctx context.Context, CAType models.CertificateType, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return call(r.injector, "SelectByType", func() (string, error) { return r.next.SelectByType(ctx, CAType, req) })
+}
+
+func (r *caRepo) Count(ctx context.Context) (int, error) {
+	return call(r.injector, "Count", func() (int, error) { return r.next.Count(ctx) })
+}
+
+func (r *caRepo) CountByEngine(ctx context.Context, engineID string) (int, error) {
+	return call(r.injector, "CountByEngine", func() (int, error) { return r.next.CountByEngine(ctx, engineID) })
+}
+
+func (r *caRepo) CountByStatus(ctx context.Context, status models.CertificateStatus) (int, error) {
+	return call(r.injector, "CountByStatus", func() (int, error) { return r.next.CountByStatus(ctx, status) })
+}
+
+func (r *caRepo) SelectAll(ctx context.Context, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return call(r.injector, "SelectAll", func() (string, error) { return r.next.SelectAll(ctx, req) })
+}
+
+func (r *caRepo) SelectExistsByID(ctx context.Context, id string) (bool, *models.CACertificate, error) {
+	return call2(r.injector, "SelectExistsByID", func() (bool, *models.CACertificate, error) { return r.next.SelectExistsByID(ctx, id) })
+}
+
+func (r *caRepo) SelectExistsBySerialNumber(ctx context.Context, serialNumber string) (bool, *models.CACertificate, error) {
+	return call2(r.injector, "SelectExistsBySerialNumber", func() (bool, *models.CACertificate, error) {
+		return r.next.SelectExistsBySerialNumber(ctx, serialNumber)
+	})
+}
+
+func (r *caRepo) SelectByCommonName(ctx context.Context, commonName string, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return call(r.injector, "SelectByCommonName", func() (string, error) { return r.next.SelectByCommonName(ctx, commonName, req) })
+}
+
+func (r *caRepo) SelectByParentCA(ctx context.Context, parentCAID string, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return call(r.injector, "SelectByParentCA", func() (string, error) { return r.next.SelectByParentCA(ctx, parentCAID, req) })
+}
+
+func (r *caRepo) Insert(ctx context.Context, caCertificate *models.CACertificate) (*models.CACertificate, error) {
+	return call(r.injector, "Insert", func() (*models.CACertificate, error) { return r.next.Insert(ctx, caCertificate) })
+}
+
+func (r *caRepo) Update(ctx context.Context, caCertificate *models.CACertificate) (*models.CACertificate, error) {
+	return call(r.injector, "Update", func() (*models.CACertificate, error) { return r.next.Update(ctx, caCertificate) })
+}
+
+func (r *caRepo) UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error) {
+	return call(r.injector, "UpdateIf", func() (*models.CACertificate, error) { return r.next.UpdateIf(ctx, caCertificate, precondition) })
+}
+
+func (r *caRepo) Delete(ctx context.Context, caID string) error {
+	if err := r.injector.Inject("Delete"); err != nil {
+		return err
+	}
+
+	return r.next.Delete(ctx, caID)
+}
+
+type certRepo struct {
+	next     storage.CertificatesRepo
+	injector *Injector
+}
+
+func (r *certRepo) CountByCA(ctx context.Context, caID string) (int, error) {
+	return call(r.injector, "CountByCA", func() (int, error) { return r.next.CountByCA(ctx, caID) })
+}
+
+func (r *certRepo) CountByCAIDAndStatus(ctx context.Context, caID string, status models.CertificateStatus) (int, error) {
+	return call(r.injector, "CountByCAIDAndStatus", func() (int, error) { return r.next.CountByCAIDAndStatus(ctx, caID, status) })
+}
+
+func (r *certRepo) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return call(r.injector, "SelectByCA", func() (string, error) { return r.next.SelectByCA(ctx, caID, req) })
+}
+
+func (r *certRepo) SelectByExpirationDate(ctx context.Context, beforeExpirationDate time.Time, afterExpirationDate time.Time, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return call(r.injector, "SelectByExpirationDate", func() (string, error) {
+		return r.next.SelectByExpirationDate(ctx, beforeExpirationDate, afterExpirationDate, req)
+	})
+}
+
+func (r *certRepo) SelectByCAIDAndStatus(ctx context.Context, CAID string, status models.CertificateStatus, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return call(r.injector, "SelectByCAIDAndStatus", func() (string, error) { return r.next.SelectByCAIDAndStatus(ctx, CAID, status, req) })
+}
+
+func (r *certRepo) SelectByStatus(ctx context.Context, status models.CertificateStatus, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return call(r.injector, "SelectByStatus", func() (string, error) { return r.next.SelectByStatus(ctx, status, req) })
+}
+
+func (r *certRepo) Count(ctx context.Context) (int, error) {
+	return call(r.injector, "Count", func() (int, error) { return r.next.Count(ctx) })
+}
+
+func (r *certRepo) SelectAll(ctx context.Context, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return call(r.injector, "SelectAll", func() (string, error) { return r.next.SelectAll(ctx, req) })
+}
+
+func (r *certRepo) SelectExistsBySerialNumber(ctx context.Context, serialNumber string) (bool, *models.Certificate, error) {
+	return call2(r.injector, "SelectExistsBySerialNumber", func() (bool, *models.Certificate, error) {
+		return r.next.SelectExistsBySerialNumber(ctx, serialNumber)
+	})
+}
+
+func (r *certRepo) Update(ctx context.Context, certificate *models.Certificate) (*models.Certificate, error) {
+	return call(r.injector, "Update", func() (*models.Certificate, error) { return r.next.Update(ctx, certificate) })
+}
+
+func (r *certRepo) Insert(ctx context.Context, certificate *models.Certificate) (*models.Certificate, error) {
+	return call(r.injector, "Insert", func() (*models.Certificate, error) { return r.next.Insert(ctx, certificate) })
+}
+
+type deviceRepo struct {
+	next     storage.DeviceManagerRepo
+	injector *Injector
+}
+
+func (r *deviceRepo) Count(ctx context.Context) (int, error) {
+	return call(r.injector, "Count", func() (int, error) { return r.next.Count(ctx) })
+}
+
+func (r *deviceRepo) CountByStatus(ctx context.Context, status models.DeviceStatus) (int, error) {
+	return call(r.injector, "CountByStatus", func() (int, error) { return r.next.CountByStatus(ctx, status) })
+}
+
+func (r *deviceRepo) SelectAll(ctx context.Context, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return call(r.injector, "SelectAll", func() (string, error) {
+		return r.next.SelectAll(ctx, exhaustiveRun, applyFunc, queryParams, extraOpts)
+	})
+}
+
+func (r *deviceRepo) SelectByDMS(ctx context.Context, dmsID string, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return call(r.injector, "SelectByDMS", func() (string, error) {
+		return r.next.SelectByDMS(ctx, dmsID, exhaustiveRun, applyFunc, queryParams, extraOpts)
+	})
+}
+
+func (r *deviceRepo) SelectByIdentitySlotStatus(ctx context.Context, status models.SlotStatus, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return call(r.injector, "SelectByIdentitySlotStatus", func() (string, error) {
+		return r.next.SelectByIdentitySlotStatus(ctx, status, exhaustiveRun, applyFunc, queryParams, extraOpts)
+	})
+}
+
+func (r *deviceRepo) SelectExists(ctx context.Context, ID string) (bool, *models.Device, error) {
+	return call2(r.injector, "SelectExists", func() (bool, *models.Device, error) { return r.next.SelectExists(ctx, ID) })
+}
+
+func (r *deviceRepo) Update(ctx context.Context, device *models.Device) (*models.Device, error) {
+	return call(r.injector, "Update", func() (*models.Device, error) { return r.next.Update(ctx, device) })
+}
+
+func (r *deviceRepo) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error) {
+	return call(r.injector, "UpdateIf", func() (*models.Device, error) { return r.next.UpdateIf(ctx, device, precondition) })
+}
+
+func (r *deviceRepo) Insert(ctx context.Context, device *models.Device) (*models.Device, error) {
+	return call(r.injector, "Insert", func() (*models.Device, error) { return r.next.Insert(ctx, device) })
+}
+
+type dmsRepo struct {
+	next     storage.DMSRepo
+	injector *Injector
+}
+
+func (r *dmsRepo) Count(ctx context.Context) (int, error) {
+	return call(r.injector, "Count", func() (int, error) { return r.next.Count(ctx) })
+}
+
+func (r *dmsRepo) SelectAll(ctx context.Context, exhaustiveRun bool, applyFunc func(models.DMS), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return call(r.injector, "SelectAll", func() (string, error) {
+		return r.next.SelectAll(ctx, exhaustiveRun, applyFunc, queryParams, extraOpts)
+	})
+}
+
+func (r *dmsRepo) SelectExists(ctx context.Context, ID string) (bool, *models.DMS, error) {
+	return call2(r.injector, "SelectExists", func() (bool, *models.DMS, error) { return r.next.SelectExists(ctx, ID) })
+}
+
+func (r *dmsRepo) Update(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
+	return call(r.injector, "Update", func() (*models.DMS, error) { return r.next.Update(ctx, dms) })
+}
+
+func (r *dmsRepo) UpdateIf(ctx context.Context, dms *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error) {
+	return call(r.injector, "UpdateIf", func() (*models.DMS, error) { return r.next.UpdateIf(ctx, dms, precondition) })
+}
+
+func (r *dmsRepo) Insert(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
+	return call(r.injector, "Insert", func() (*models.DMS, error) { return r.next.Insert(ctx, dms) })
+}
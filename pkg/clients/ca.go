@@ -7,6 +7,7 @@ import (
 	"encoding/pem"
 	"fmt"
 	"net/http"
+	"time"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
@@ -55,11 +56,38 @@ func (cli *httpCAClient) GetStatsByCAID(ctx context.Context, input services.GetS
 	return stats, nil
 }
 
+func (cli *httpCAClient) GetKeyStrengthReport(ctx context.Context) (*models.KeyStrengthReport, error) {
+	report, err := Get[*models.KeyStrengthReport](ctx, cli.httpClient, cli.baseUrl+"/v1/reports/key-strength", nil, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (cli *httpCAClient) GetSoftwareKeyCustodyReport(ctx context.Context) (*models.SoftwareKeyCustodyReport, error) {
+	report, err := Get[*models.SoftwareKeyCustodyReport](ctx, cli.httpClient, cli.baseUrl+"/v1/reports/key-custody", nil, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return report, nil
+}
+
+func (cli *httpCAClient) ExportCAInventory(ctx context.Context) (*models.CAInventory, error) {
+	inventory, err := Get[*models.CAInventory](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/export", nil, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return inventory, nil
+}
+
 func (cli *httpCAClient) GetCAs(ctx context.Context, input services.GetCAsInput) (string, error) {
 	url := cli.baseUrl + "/v1/cas"
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.CACertificate, *resources.GetCAsResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.CACertificate, *resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
 		resp, err := Get[resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
@@ -72,7 +100,11 @@ func (cli *httpCAClient) GetCAs(ctx context.Context, input services.GetCAsInput)
 }
 
 func (cli *httpCAClient) GetCAByID(ctx context.Context, input services.GetCAByIDInput) (*models.CACertificate, error) {
-	response, err := Get[models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID, nil, map[int][]error{})
+	response, err := Get[models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID, nil, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -84,7 +116,7 @@ func (cli *httpCAClient) GetCAsByCommonName(ctx context.Context, input services.
 	url := cli.baseUrl + "/v1/cas/cn/" + input.CommonName
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.CACertificate, *resources.GetCAsResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.CACertificate, *resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
 		resp, err := Get[resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
@@ -117,10 +149,16 @@ func (cli *httpCAClient) CreateCA(ctx context.Context, input services.CreateCAIn
 		EngineID:           input.EngineID,
 		ParentID:           input.ParentID,
 		Metadata:           input.Metadata,
+		HybridKeyMetadata:  input.HybridKeyMetadata,
 	}, map[int][]error{
 		400: {
 			errs.ErrCAIncompatibleExpirationTimeRef,
 			errs.ErrCAIssuanceExpiration,
+			errs.ErrCAType,
+			errs.ErrCAStatus,
+		},
+		404: {
+			errs.ErrCANotFound,
 		},
 		409: {
 			errs.ErrCAAlreadyExists,
@@ -138,13 +176,13 @@ func (cli *httpCAClient) CreateCA(ctx context.Context, input services.CreateCAIn
 
 func (cli *httpCAClient) ImportCA(ctx context.Context, input services.ImportCAInput) (*models.CACertificate, error) {
 	var privKey string
-	if input.KeyType == models.KeyType(x509.RSA) {
+	if input.CARSAKey != nil {
 		rsaBytes := x509.MarshalPKCS1PrivateKey(input.CARSAKey)
 		privKey = base64.StdEncoding.EncodeToString(pem.EncodeToMemory(&pem.Block{
 			Type:  "RSA PRIVATE KEY",
 			Bytes: rsaBytes,
 		}))
-	} else if input.KeyType == models.KeyType(x509.ECDSA) {
+	} else if input.CAECKey != nil {
 		ecBytes, err := x509.MarshalECPrivateKey(input.CAECKey)
 		if err != nil {
 			return nil, err
@@ -164,7 +202,22 @@ func (cli *httpCAClient) ImportCA(ctx context.Context, input services.ImportCAIn
 		CAPrivateKey:       privKey,
 		EngineID:           input.EngineID,
 		ParentID:           input.ParentID,
-	}, map[int][]error{})
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAType,
+			errs.ErrCAIssuanceExpiration,
+			errs.ErrCAIncompatibleExpirationTimeRef,
+			errs.ErrCAValidCertAndPrivKey,
+		},
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCryptoEngineNotFound,
+		},
+		409: {
+			errs.ErrCAAlreadyExists,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -172,12 +225,67 @@ func (cli *httpCAClient) ImportCA(ctx context.Context, input services.ImportCAIn
 	return response, nil
 }
 
+func (cli *httpCAClient) SignCertificatesBatch(ctx context.Context, input services.SignCertificatesBatchInput) ([]models.CertificateSigningResult, error) {
+	body := resources.SignCertificatesBatchBody{
+		Requests: make([]resources.SignCertificateBody, 0, len(input.Requests)),
+	}
+	for _, req := range input.Requests {
+		body.Requests = append(body.Requests, resources.SignCertificateBody{
+			SignVerbatim:         req.SignVerbatim,
+			CertRequest:          req.CertRequest,
+			Subject:              req.Subject,
+			SigningProfile:       req.SigningProfile,
+			IssuanceContext:      req.IssuanceContext,
+			CertificateProfileID: req.CertificateProfileID,
+		})
+	}
+
+	response, err := Post[resources.SignCertificatesBatchResponse](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/certificates/sign-batch", body, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAStatus,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Results, nil
+}
+
 func (cli *httpCAClient) SignCertificate(ctx context.Context, input services.SignCertificateInput) (*models.Certificate, error) {
 	response, err := Post[*models.Certificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/certificates/sign", resources.SignCertificateBody{
-		SignVerbatim: input.SignVerbatim,
-		CertRequest:  input.CertRequest,
-		Subject:      input.Subject,
-	}, map[int][]error{})
+		SignVerbatim:         input.SignVerbatim,
+		CertRequest:          input.CertRequest,
+		Subject:              input.Subject,
+		SigningProfile:       input.SigningProfile,
+		IssuanceContext:      input.IssuanceContext,
+		CertificateProfileID: input.CertificateProfileID,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAStatus,
+			errs.ErrCertificateProfileViolation,
+			errs.ErrCertificateRequestLimits,
+		},
+		403: {
+			errs.ErrCertificateIssuanceVetoed,
+			errs.ErrCASigningApprovalRequired,
+		},
+		409: {
+			errs.ErrCAIssuancePaused,
+		},
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCertificateProfileNotFound,
+		},
+		502: {
+			errs.ErrCertificateIssuanceWebhook,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -202,10 +310,17 @@ func (cli *httpCAClient) ImportCertificate(ctx context.Context, input services.I
 }
 
 func (cli *httpCAClient) UpdateCAStatus(ctx context.Context, input services.UpdateCAStatusInput) (*models.CACertificate, error) {
-	response, err := Post[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/status", resources.UpdateCertificateStatusBody{
+	response, err := PostIfMatch[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/status", resources.UpdateCertificateStatusBody{
 		NewStatus:        input.Status,
 		RevocationReason: input.RevocationReason,
-	}, map[int][]error{})
+	}, input.IfMatch, map[int][]error{
+		202: {
+			errs.ErrCAActionPendingApproval,
+		},
+		412: {
+			errs.ErrPreconditionFailed,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -213,11 +328,162 @@ func (cli *httpCAClient) UpdateCAStatus(ctx context.Context, input services.Upda
 }
 
 func (cli *httpCAClient) UpdateCAMetadata(ctx context.Context, input services.UpdateCAMetadataInput) (*models.CACertificate, error) {
-	response, err := Put[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/metadata", resources.UpdateCAMetadataBody{
+	response, err := PutIfMatch[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/metadata", resources.UpdateCAMetadataBody{
 		Metadata: input.Metadata,
+	}, input.IfMatch, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+		412: {
+			errs.ErrPreconditionFailed,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) UpdateCASettings(ctx context.Context, input services.UpdateCASettingsInput) (*models.CACertificate, error) {
+	response, err := PutIfMatch[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/settings", resources.UpdateCASettingsBody{
+		IssuanceExpiration: input.IssuanceExpiration,
+		ExpirationDeltas:   input.ExpirationDeltas,
+		Metadata:           input.Metadata,
+	}, input.IfMatch, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAStatus,
+			errs.ErrCAIssuanceExpiration,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+		412: {
+			errs.ErrPreconditionFailed,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) MigrateCAKey(ctx context.Context, input services.MigrateCAKeyInput) (*models.CACertificate, error) {
+	response, err := Post[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/key/migrate", resources.MigrateCAKeyBody{
+		TargetEngineID:  input.TargetEngineID,
+		RemoveSourceKey: input.RemoveSourceKey,
 	}, map[int][]error{
 		404: {
 			errs.ErrCANotFound,
+			errs.ErrCryptoEngineNotFound,
+		},
+		400: {
+			errs.ErrCAType,
+			errs.ErrCAKeyNotExportable,
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrCASigningApprovalRequired,
+		},
+		409: {
+			errs.ErrCAKeyMigrationConflict,
+		},
+		500: {
+			errs.ErrCAKeyMigrationVerification,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) SetCAFallbackEngine(ctx context.Context, input services.SetCAFallbackEngineInput) (*models.CACertificate, error) {
+	response, err := Put[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/key/fallback", resources.SetCAFallbackEngineBody{
+		EngineID: input.EngineID,
+	}, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCryptoEngineNotFound,
+		},
+		400: {
+			errs.ErrCAType,
+			errs.ErrCAKeyNotExportable,
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrCASigningApprovalRequired,
+		},
+		500: {
+			errs.ErrCAKeyMigrationVerification,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) SetCAIssuanceWebhook(ctx context.Context, input services.SetCAIssuanceWebhookInput) (*models.CACertificate, error) {
+	response, err := Put[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/issuance-webhook", resources.SetCAIssuanceWebhookBody{
+		Webhook: input.Webhook,
+	}, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+		},
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) ExportCAKey(ctx context.Context, input services.ExportCAKeyInput) (*models.CAKeyBackup, error) {
+	response, err := Get[*models.CAKeyBackup](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/key/backup", nil, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCryptoEngineNotFound,
+		},
+		400: {
+			errs.ErrCAType,
+			errs.ErrCAKeyNotExportable,
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrCASigningApprovalRequired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) RestoreCAKey(ctx context.Context, input services.RestoreCAKeyInput) (*models.CACertificate, error) {
+	response, err := Post[*models.CACertificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/key/restore", resources.RestoreCAKeyBody{
+		Backup: input.Backup,
+	}, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCryptoEngineNotFound,
+		},
+		400: {
+			errs.ErrCAType,
+			errs.ErrCAKeyNotExportable,
+			errs.ErrCAKeyBackupInvalid,
+			errs.ErrValidateBadRequest,
 		},
 	})
 	if err != nil {
@@ -228,13 +494,25 @@ func (cli *httpCAClient) UpdateCAMetadata(ctx context.Context, input services.Up
 }
 
 func (cli *httpCAClient) DeleteCA(ctx context.Context, input services.DeleteCAInput) error {
-	err := Delete(ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID, map[int][]error{
+	url := cli.baseUrl + "/v1/cas/" + input.CAID
+	if input.Force {
+		url += "?force=true"
+	}
+
+	err := Delete(ctx, cli.httpClient, url, map[int][]error{
 		404: {
 			errs.ErrCANotFound,
 		},
 		400: {
 			errs.ErrCAStatus,
 		},
+		409: {
+			errs.ErrCAHasActiveCertificates,
+			errs.ErrCADeletionInProgress,
+		},
+		202: {
+			errs.ErrCAActionPendingApproval,
+		},
 	})
 	if err != nil {
 		return err
@@ -243,12 +521,58 @@ func (cli *httpCAClient) DeleteCA(ctx context.Context, input services.DeleteCAIn
 	return nil
 }
 
+func (cli *httpCAClient) GetPendingCAAction(ctx context.Context, input services.GetPendingCAActionInput) (*models.CAPendingAction, error) {
+	response, err := Get[*models.CAPendingAction](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/pending-action", nil, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCAPendingActionNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) ApprovePendingCAAction(ctx context.Context, input services.ApprovePendingCAActionInput) (*models.CAPendingAction, error) {
+	response, err := Post[*models.CAPendingAction](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/pending-action/approve", nil, map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCAPendingActionNotFound,
+		},
+		403: {
+			errs.ErrCAPendingActionSelfApproval,
+			errs.ErrCAApprovalUnverifiedIdentity,
+		},
+		409: {
+			errs.ErrCAPendingActionExpired,
+			errs.ErrCAKeyInUse,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
 func (cli *httpCAClient) SignatureSign(ctx context.Context, input services.SignatureSignInput) ([]byte, error) {
 	response, err := Post[*resources.SignResponse](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/signature/sign", resources.SignatureSignBody{
 		Message:          base64.StdEncoding.EncodeToString(input.Message),
 		MessageType:      input.MessageType,
 		SigningAlgorithm: input.SigningAlgorithm,
-	}, map[int][]error{})
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrCASigningApprovalRequired,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -256,6 +580,300 @@ func (cli *httpCAClient) SignatureSign(ctx context.Context, input services.Signa
 	return base64.StdEncoding.DecodeString(response.SignedData)
 }
 
+func (cli *httpCAClient) CreateCASigningRequest(ctx context.Context, input services.CreateCASigningRequestInput) (*models.CASigningRequest, error) {
+	response, err := Post[*models.CASigningRequest](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/signing-requests", resources.CreateCASigningRequestBody{
+		Operation:            input.Operation,
+		Message:              base64.StdEncoding.EncodeToString(input.Message),
+		MessageType:          input.MessageType,
+		SigningAlgorithm:     input.SigningAlgorithm,
+		CertRequest:          input.CertRequest,
+		Subject:              input.Subject,
+		SignVerbatim:         input.SignVerbatim,
+		SigningProfile:       input.SigningProfile,
+		CertificateProfileID: input.CertificateProfileID,
+		DualControl:          input.DualControl,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCADualControlNotEnabled,
+		},
+		403: {
+			errs.ErrCAApprovalUnverifiedIdentity,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) GetCASigningRequests(ctx context.Context, input services.GetCASigningRequestsInput) (string, error) {
+	url := cli.baseUrl + "/v1/cas/" + input.CAID + "/signing-requests"
+	knownErrors := map[int][]error{
+		404: {
+			errs.ErrCANotFound,
+		},
+	}
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.CASigningRequest, *resources.GetCASigningRequestsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, knownErrors)
+		return "", err
+	} else {
+		resp, err := Get[resources.GetCASigningRequestsResponse](ctx, cli.httpClient, url, input.QueryParameters, knownErrors)
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *httpCAClient) GetCASigningRequestByID(ctx context.Context, input services.GetCASigningRequestByIDInput) (*models.CASigningRequest, error) {
+	response, err := Get[*models.CASigningRequest](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/signing-requests/"+input.RequestID, nil, map[int][]error{
+		404: {
+			errs.ErrCASigningRequestNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) ApproveCASigningRequest(ctx context.Context, input services.ApproveCASigningRequestInput) (*models.CASigningRequest, error) {
+	response, err := Post[*models.CASigningRequest](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/signing-requests/"+input.RequestID+"/approve", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAStatus,
+			errs.ErrCertificateProfileViolation,
+			errs.ErrCertificateRequestLimits,
+		},
+		403: {
+			errs.ErrCASigningRequestSelfApproval,
+			errs.ErrCASigningRequestApproverDenied,
+			errs.ErrCAApprovalUnverifiedIdentity,
+			errs.ErrCertificateIssuanceVetoed,
+		},
+		404: {
+			errs.ErrCASigningRequestNotFound,
+		},
+		409: {
+			errs.ErrCASigningRequestNotPending,
+			errs.ErrCASigningRequestExpired,
+			errs.ErrCASigningRequestAlreadyApproved,
+			errs.ErrCAIssuancePaused,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) RejectCASigningRequest(ctx context.Context, input services.RejectCASigningRequestInput) (*models.CASigningRequest, error) {
+	response, err := Post[*models.CASigningRequest](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/signing-requests/"+input.RequestID+"/reject", resources.RejectCASigningRequestBody{
+		Reason: input.Reason,
+	}, map[int][]error{
+		403: {
+			errs.ErrCAApprovalUnverifiedIdentity,
+		},
+		404: {
+			errs.ErrCASigningRequestNotFound,
+		},
+		409: {
+			errs.ErrCASigningRequestNotPending,
+			errs.ErrCASigningRequestExpired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) SignSVID(ctx context.Context, input services.SignSVIDInput) (*models.Certificate, error) {
+	response, err := Post[*models.Certificate](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/svids/sign", resources.SignSVIDBody{
+		CertRequest: input.CertRequest,
+		SPIFFEID:    input.SPIFFEID,
+		Validity:    input.Validity,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAStatus,
+			errs.ErrSVIDInvalidSPIFFEID,
+			errs.ErrCertificateProfileViolation,
+			errs.ErrCertificateRequestLimits,
+		},
+		403: {
+			errs.ErrCASPIFFENotEnabled,
+			errs.ErrCertificateIssuanceVetoed,
+			errs.ErrCASigningApprovalRequired,
+		},
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCertificateProfileNotFound,
+		},
+		409: {
+			errs.ErrCAIssuancePaused,
+		},
+		502: {
+			errs.ErrCertificateIssuanceWebhook,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) SignToken(ctx context.Context, input services.SignTokenInput) (*models.SignedToken, error) {
+	response, err := Post[*models.SignedToken](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/tokens/sign", resources.SignTokenBody{
+		Claims:    input.Claims,
+		Algorithm: input.Algorithm,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAStatus,
+		},
+		403: {
+			errs.ErrCATokenSigningNotEnabled,
+			errs.ErrFeatureDisabled,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) GetTokenSigningKeys(ctx context.Context) (*models.JWKS, error) {
+	response, err := Get[models.JWKS](ctx, cli.httpClient, cli.baseUrl+"/v1/tokens/jwks", nil, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (cli *httpCAClient) GetIssuanceLogTreeHead(ctx context.Context, input services.GetIssuanceLogTreeHeadInput) (*models.IssuanceLogTreeHead, error) {
+	response, err := Get[models.IssuanceLogTreeHead](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/issuance-log/tree-head", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrCAIssuanceLogNotEnabled,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (cli *httpCAClient) GetIssuanceLogInclusionProof(ctx context.Context, input services.GetIssuanceLogInclusionProofInput) (*models.IssuanceLogInclusionProof, error) {
+	url := cli.baseUrl + "/v1/cas/" + input.CAID + "/issuance-log/proofs/" + input.SerialNumber
+	if input.TreeSize > 0 {
+		url += fmt.Sprintf("?tree_size=%d", input.TreeSize)
+	}
+
+	response, err := Get[models.IssuanceLogInclusionProof](ctx, cli.httpClient, url, nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrCAIssuanceLogNotEnabled,
+		},
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCAIssuanceLogEntryNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (cli *httpCAClient) GetCAEvents(ctx context.Context, input services.GetCAEventsInput) ([]models.CAEvent, error) {
+	response, err := Get[[]models.CAEvent](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/events", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) CreateSuccessorCA(ctx context.Context, input services.CreateSuccessorCAInput) (*models.CASuccession, error) {
+	response, err := Post[*models.CASuccession](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/successor", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAType,
+			errs.ErrCAStatus,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+		409: {
+			errs.ErrCASuccessorAlreadyExists,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) ReportCRLGeneration(ctx context.Context, input services.ReportCRLGenerationInput) error {
+	_, err := Post[any](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/crls", resources.ReportCRLGenerationBody{
+		Number:              input.Number,
+		ThisUpdate:          input.ThisUpdate,
+		NextUpdate:          input.NextUpdate,
+		RevokedCertificates: input.RevokedCertificates,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+	})
+
+	return err
+}
+
+func (cli *httpCAClient) GetJWKS(ctx context.Context) (*models.JWKS, error) {
+	response, err := Get[models.JWKS](ctx, cli.httpClient, cli.baseUrl+"/.well-known/jwks.json", nil, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 func (cli *httpCAClient) SignatureVerify(ctx context.Context, input services.SignatureVerifyInput) (bool, error) {
 	response, err := Post[*resources.VerifyResponse](ctx, cli.httpClient, cli.baseUrl+"/v1/cas/"+input.CAID+"/signature/verify", resources.SignatureVerifyBody{
 		Signature:        base64.StdEncoding.EncodeToString(input.Signature),
@@ -287,7 +905,7 @@ func (cli *httpCAClient) GetCertificates(ctx context.Context, input services.Get
 	url := cli.baseUrl + "/v1/certificates"
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
 		resp, err := Get[resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
@@ -298,24 +916,46 @@ func (cli *httpCAClient) GetCertificates(ctx context.Context, input services.Get
 	}
 }
 
+// ExportCertificates streams the certificate export. The returned cursor is computed from the received certificates.
+func (cli *httpCAClient) ExportCertificates(ctx context.Context, input services.ExportCertificatesInput) (time.Time, error) {
+	url := cli.baseUrl + "/v1/certificates/export"
+
+	var cursor time.Time
+	err := GetStream(ctx, cli.httpClient, url, input.QueryParameters, func(cert models.Certificate) {
+		if cert.ValidFrom.After(cursor) {
+			cursor = cert.ValidFrom
+		}
+		input.ApplyFunc(cert)
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+	})
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return cursor, nil
+}
+
 func (cli *httpCAClient) GetCertificatesByCA(ctx context.Context, input services.GetCertificatesByCAInput) (string, error) {
 	url := cli.baseUrl + "/v1/cas/" + input.CAID + "/certificates"
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{
+		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{
 			404: {
 				errs.ErrCANotFound,
 			},
 		})
 		return "", err
 	} else {
-		resp, err := Get[resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{
+		resp, err := Get[resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{
 			404: {
 				errs.ErrCANotFound,
 			},
 		})
 		for _, elem := range resp.IterableList.List {
-			input.ApplyFunc(elem.Certificate)
+			input.ApplyFunc(elem)
 		}
 		return resp.NextBookmark, err
 	}
@@ -325,12 +965,12 @@ func (cli *httpCAClient) GetCertificatesByExpirationDate(ctx context.Context, in
 	url := fmt.Sprintf("%s/v1/certificates/expiration?expires_after=%s&expires_before=%s", cli.baseUrl, input.ExpiresAfter.UTC().Format("2006-01-02T15:04:05Z07:00"), input.ExpiresBefore.UTC().Format("2006-01-02T15:04:05Z07:00"))
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
-		resp, err := Get[resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		resp, err := Get[resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
 		for _, elem := range resp.IterableList.List {
-			input.ApplyFunc(elem.Certificate)
+			input.ApplyFunc(elem)
 		}
 		return resp.NextBookmark, err
 	}
@@ -342,9 +982,9 @@ func (cli *httpCAClient) GetCertificatesByCaAndStatus(ctx context.Context, input
 		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
-		resp, err := Get[resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		resp, err := Get[resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
 		for _, elem := range resp.IterableList.List {
-			input.ApplyFunc(elem.Certificate)
+			input.ApplyFunc(elem)
 		}
 		return resp.NextBookmark, err
 	}
@@ -357,9 +997,9 @@ func (cli *httpCAClient) GetCertificatesByStatus(ctx context.Context, input serv
 		err := IterGet[models.Certificate, *resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
-		resp, err := Get[resources.GetCAsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		resp, err := Get[resources.GetCertsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
 		for _, elem := range resp.IterableList.List {
-			input.ApplyFunc(elem.Certificate)
+			input.ApplyFunc(elem)
 		}
 		return resp.NextBookmark, err
 	}
@@ -393,3 +1033,90 @@ func (cli *httpCAClient) UpdateCertificateMetadata(ctx context.Context, input se
 	}
 	return response, nil
 }
+
+func (cli *httpCAClient) CreateCertificateProfile(ctx context.Context, input services.CreateCertificateProfileInput) (*models.CertificateProfile, error) {
+	response, err := Post[*models.CertificateProfile](ctx, cli.httpClient, cli.baseUrl+"/v1/profiles", resources.CreateCertificateProfileBody{
+		ID:                 input.ID,
+		Name:               input.Name,
+		Description:        input.Description,
+		CAIDs:              input.CAIDs,
+		KeyUsages:          input.KeyUsages,
+		ExtendedKeyUsages:  input.ExtendedKeyUsages,
+		SANPolicy:          input.SANPolicy,
+		SubjectConstraints: input.SubjectConstraints,
+		MaxValidity:        input.MaxValidity,
+		NotBeforeBackdate:  input.NotBeforeBackdate,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+		409: {
+			errs.ErrCertificateProfileAlreadyExists,
+			errs.ErrCertificateProfileCABound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) GetCertificateProfileByID(ctx context.Context, input services.GetCertificateProfileByIDInput) (*models.CertificateProfile, error) {
+	response, err := Get[models.CertificateProfile](ctx, cli.httpClient, cli.baseUrl+"/v1/profiles/"+input.ID, nil, map[int][]error{
+		404: {
+			errs.ErrCertificateProfileNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (cli *httpCAClient) GetCertificateProfiles(ctx context.Context, input services.GetCertificateProfilesInput) (string, error) {
+	url := cli.baseUrl + "/v1/profiles"
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.CertificateProfile, *resources.GetCertificateProfilesResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
+		return "", err
+	} else {
+		resp, err := Get[resources.GetCertificateProfilesResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *httpCAClient) UpdateCertificateProfile(ctx context.Context, input services.UpdateCertificateProfileInput) (*models.CertificateProfile, error) {
+	response, err := Put[*models.CertificateProfile](ctx, cli.httpClient, cli.baseUrl+"/v1/profiles/"+input.Profile.ID, input.Profile, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCertificateProfileNotFound,
+			errs.ErrCANotFound,
+		},
+		409: {
+			errs.ErrCertificateProfileCABound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *httpCAClient) DeleteCertificateProfile(ctx context.Context, input services.DeleteCertificateProfileInput) error {
+	return Delete(ctx, cli.httpClient, cli.baseUrl+"/v1/profiles/"+input.ID, map[int][]error{
+		404: {
+			errs.ErrCertificateProfileNotFound,
+		},
+	})
+}
@@ -2,7 +2,10 @@ package clients
 
 import (
 	"context"
+	"fmt"
 	"net/http"
+	"net/url"
+	"time"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
@@ -32,6 +35,23 @@ func (cli *deviceManagerClient) GetDevicesStats(ctx context.Context, input servi
 	return &response, nil
 }
 
+func (cli *deviceManagerClient) GetIssuanceReport(ctx context.Context, input services.GetIssuanceReportInput) (*models.IssuanceReport, error) {
+	query := url.Values{}
+	query.Add("from", input.From.Format(time.RFC3339))
+	query.Add("to", input.To.Format(time.RFC3339))
+
+	response, err := Get[models.IssuanceReport](ctx, cli.httpClient, cli.baseUrl+"/v1/reports/issuance?"+query.Encode(), nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 func (cli *deviceManagerClient) CreateDevice(ctx context.Context, input services.CreateDeviceInput) (*models.Device, error) {
 	response, err := Post[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices", resources.CreateDeviceBody{
 		ID:        input.ID,
@@ -41,7 +61,14 @@ func (cli *deviceManagerClient) CreateDevice(ctx context.Context, input services
 		DMSID:     input.DMSID,
 		Icon:      input.Icon,
 		IconColor: input.IconColor,
-	}, map[int][]error{})
+
+		DeviceIDRules: input.DeviceIDRules,
+	}, map[int][]error{
+		400: {
+			errs.ErrDeviceInvalidID,
+			errs.ErrValidateBadRequest,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -50,7 +77,12 @@ func (cli *deviceManagerClient) CreateDevice(ctx context.Context, input services
 }
 
 func (cli *deviceManagerClient) GetDeviceByID(ctx context.Context, input services.GetDeviceByIDInput) (*models.Device, error) {
-	response, err := Get[models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID, nil, map[int][]error{
+	url := cli.baseUrl + "/v1/devices/" + input.ID
+	if input.IncludeSecrets {
+		url += "?include_secrets=true"
+	}
+
+	response, err := Get[models.Device](ctx, cli.httpClient, url, nil, map[int][]error{
 		400: {errs.ErrDeviceNotFound},
 	})
 	if err != nil {
@@ -60,14 +92,51 @@ func (cli *deviceManagerClient) GetDeviceByID(ctx context.Context, input service
 	return &response, nil
 }
 
+func (cli *deviceManagerClient) GetDeviceByCertificate(ctx context.Context, input services.GetDeviceByCertificateInput) (*models.CertificateDeviceBinding, error) {
+	response, err := Get[models.CertificateDeviceBinding](ctx, cli.httpClient, cli.baseUrl+"/v1/certificates/"+input.SerialNumber+"/device", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCertificateNotFound,
+			errs.ErrDeviceCertificateNotBound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
 func (cli *deviceManagerClient) GetDevices(ctx context.Context, input services.GetDevicesInput) (string, error) {
+	query := url.Values{}
+	if input.CertificateStatus != "" {
+		query.Set("cert_status", string(input.CertificateStatus))
+	}
+	if input.NotSeenFor != 0 {
+		query.Set("not_seen_in", models.TimeDuration(input.NotSeenFor).String())
+	}
+
 	url := cli.baseUrl + "/v1/devices"
+	if len(query) > 0 {
+		url += "?" + query.Encode()
+	}
+
+	knownErrors := map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+	}
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.Device, resources.GetDevicesResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.Device, resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, knownErrors)
 		return "", err
 	} else {
-		resp, err := Get[resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		resp, err := Get[resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, knownErrors)
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
 		return resp.NextBookmark, err
 	}
 }
@@ -75,10 +144,33 @@ func (cli *deviceManagerClient) GetDeviceByDMS(ctx context.Context, input servic
 	url := cli.baseUrl + "/v1/devices/dms/" + input.DMSID
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.Device, *resources.GetDevicesResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.Device, *resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
 		resp, err := Get[resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *deviceManagerClient) GetDevicesOutOfCloudSync(ctx context.Context, input services.GetDevicesOutOfCloudSyncInput) (string, error) {
+	url := cli.baseUrl + "/v1/connectors/" + input.ConnectorID + "/devices/out-of-sync"
+	knownErrors := map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+	}
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.DeviceCloudSyncStatus, resources.GetDevicesOutOfCloudSyncResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, knownErrors)
+		return "", err
+	} else {
+		resp, err := Get[resources.GetDevicesOutOfCloudSyncResponse](ctx, cli.httpClient, url, input.QueryParameters, knownErrors)
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
 		return resp.NextBookmark, err
 	}
 }
@@ -93,9 +185,31 @@ func (cli *deviceManagerClient) UpdateDeviceStatus(ctx context.Context, input se
 }
 
 func (cli *deviceManagerClient) UpdateDeviceIdentitySlot(ctx context.Context, input services.UpdateDeviceIdentitySlotInput) (*models.Device, error) {
-	response, err := Put[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/idslot", resources.UpdateDeviceIdentitySlotBody{
+	response, err := PutIfMatch[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/idslot", resources.UpdateDeviceIdentitySlotBody{
 		Slot: input.Slot,
-	}, map[int][]error{})
+	}, input.IfMatch, map[int][]error{
+		412: {
+			errs.ErrPreconditionFailed,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) UpdateDeviceConnectionMetadata(ctx context.Context, input services.UpdateDeviceConnectionMetadataInput) (*models.Device, error) {
+	response, err := Put[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/connection", resources.UpdateDeviceConnectionMetadataBody{
+		DeviceConnectionMetadata: input.ConnectionMetadata,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -104,9 +218,369 @@ func (cli *deviceManagerClient) UpdateDeviceIdentitySlot(ctx context.Context, in
 }
 
 func (cli *deviceManagerClient) UpdateDeviceMetadata(ctx context.Context, input services.UpdateDeviceMetadataInput) (*models.Device, error) {
-	response, err := Put[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/metadata", resources.UpdateDeviceMetadataBody{
+	response, err := PutIfMatch[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/metadata", resources.UpdateDeviceMetadataBody{
 		Metadata: input.Metadata,
-	}, map[int][]error{})
+	}, input.IfMatch, map[int][]error{
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+		412: {
+			errs.ErrPreconditionFailed,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) ProvisionDeviceSecretSlot(ctx context.Context, input services.ProvisionDeviceSecretSlotInput) (*models.Device, error) {
+	response, err := Put[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.DeviceID+"/slots/"+input.SlotID, resources.ProvisionDeviceSecretSlotBody{
+		Type:    input.SecretType,
+		Profile: input.Profile,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) ReportDeviceHeartbeat(ctx context.Context, input services.ReportDeviceHeartbeatInput) (*models.Device, error) {
+	response, err := Post[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/heartbeat", resources.ReportDeviceHeartbeatBody{
+		Source: input.Source,
+		Broker: input.Broker,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) CreateDeviceLog(ctx context.Context, input services.CreateDeviceLogInput) (*models.DeviceLog, error) {
+	response, err := Post[*models.DeviceLog](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.DeviceID+"/logs", resources.CreateDeviceLogBody{
+		Severity:  input.Severity,
+		Message:   input.Message,
+		Source:    input.Source,
+		Timestamp: input.Timestamp,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) GetDeviceLogs(ctx context.Context, input services.GetDeviceLogsInput) (string, error) {
+	url := cli.baseUrl + "/v1/devices/" + input.DeviceID + "/logs"
+	if input.Severity != "" {
+		url += "?severity=" + string(input.Severity)
+	}
+
+	knownErrors := map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+	}
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.DeviceLog, *resources.GetDeviceLogsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, knownErrors)
+		return "", err
+	} else {
+		resp, err := Get[resources.GetDeviceLogsResponse](ctx, cli.httpClient, url, input.QueryParameters, knownErrors)
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *deviceManagerClient) PruneDeviceLogs(ctx context.Context, input services.PruneDeviceLogsInput) (int, error) {
+	return 0, fmt.Errorf("not supported, device logs are pruned by the device manager retention job")
+}
+
+func (cli *deviceManagerClient) ForceDeviceReenroll(ctx context.Context, input services.ForceDeviceReenrollInput) (*models.Device, error) {
+	response, err := Post[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.ID+"/force-reenroll", resources.ForceDeviceReenrollBody{
+		Reason: input.Reason,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+		409: {
+			errs.ErrDeviceNoIdentity,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) RotateDeviceSecretSlot(ctx context.Context, input services.RotateDeviceSecretSlotInput) (*models.Device, error) {
+	response, err := Post[*models.Device](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.DeviceID+"/slots/"+input.SlotID+"/rotate", "", map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+			errs.ErrDeviceSlotNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) GetDeviceSecretSlot(ctx context.Context, input services.GetDeviceSecretSlotInput) (*models.EncryptedSlotSecret, error) {
+	response, err := Get[models.EncryptedSlotSecret](ctx, cli.httpClient, cli.baseUrl+"/v1/devices/"+input.DeviceID+"/slots/"+input.SlotID+"/secret", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+			errs.ErrDeviceSlotNotFound,
+		},
+		409: {
+			errs.ErrDeviceNoIdentity,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &response, nil
+}
+
+func (cli *deviceManagerClient) CreateDeviceGroup(ctx context.Context, input services.CreateDeviceGroupInput) (*models.DeviceGroup, error) {
+	response, err := Post[*models.DeviceGroup](ctx, cli.httpClient, cli.baseUrl+"/v1/groups", resources.CreateDeviceGroupBody{
+		Name:              input.Name,
+		Description:       input.Description,
+		DeviceIDs:         input.DeviceIDs,
+		Tags:              input.Tags,
+		Filters:           input.Filters,
+		CertificateStatus: input.CertificateStatus,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) GetDeviceGroups(ctx context.Context, input services.GetDeviceGroupsInput) (string, error) {
+	url := cli.baseUrl + "/v1/groups"
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.DeviceGroup, *resources.GetDeviceGroupsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
+		return "", err
+	} else {
+		resp, err := Get[resources.GetDeviceGroupsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *deviceManagerClient) GetDeviceGroupByID(ctx context.Context, input services.GetDeviceGroupByIDInput) (*models.DeviceGroup, error) {
+	response, err := Get[*models.DeviceGroup](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.ID, nil, map[int][]error{
+		404: {
+			errs.ErrDeviceGroupNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) UpdateDeviceGroupDevices(ctx context.Context, input services.UpdateDeviceGroupDevicesInput) (*models.DeviceGroup, error) {
+	response, err := Put[*models.DeviceGroup](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.ID+"/devices", resources.UpdateDeviceGroupDevicesBody{
+		Add:    input.AddDeviceIDs,
+		Remove: input.RemoveDeviceIDs,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceGroupNotFound,
+			errs.ErrDeviceNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) UpdateDeviceGroupTags(ctx context.Context, input services.UpdateDeviceGroupTagsInput) (*models.DeviceGroup, error) {
+	response, err := Put[*models.DeviceGroup](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.ID+"/tags", resources.UpdateDeviceGroupTagsBody{
+		Tags: input.Tags,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceGroupNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) UpdateDeviceGroupFilters(ctx context.Context, input services.UpdateDeviceGroupFiltersInput) (*models.DeviceGroup, error) {
+	response, err := Put[*models.DeviceGroup](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.ID+"/filters", resources.UpdateDeviceGroupFiltersBody{
+		Filters:           input.Filters,
+		CertificateStatus: input.CertificateStatus,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceGroupNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) DeleteDeviceGroup(ctx context.Context, input services.DeleteDeviceGroupInput) error {
+	return Delete(ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.ID, map[int][]error{
+		404: {
+			errs.ErrDeviceGroupNotFound,
+		},
+	})
+}
+
+func (cli *deviceManagerClient) GetDeviceGroupDevices(ctx context.Context, input services.GetDeviceGroupDevicesInput) (string, error) {
+	url := cli.baseUrl + "/v1/groups/" + input.ID + "/devices"
+	knownErrors := map[int][]error{
+		404: {
+			errs.ErrDeviceGroupNotFound,
+		},
+	}
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.Device, *resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, knownErrors)
+		return "", err
+	} else {
+		resp, err := Get[resources.GetDevicesResponse](ctx, cli.httpClient, url, input.QueryParameters, knownErrors)
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *deviceManagerClient) StartDeviceGroupBulkAction(ctx context.Context, input services.StartDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error) {
+	response, err := Post[*models.DeviceGroupBulkAction](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.GroupID+"/actions", resources.StartDeviceGroupBulkActionBody{
+		Type:     input.Type,
+		Metadata: input.Metadata,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDeviceGroupNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) ApproveDeviceGroupBulkAction(ctx context.Context, input services.ApproveDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error) {
+	response, err := Post[*models.DeviceGroupBulkAction](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.GroupID+"/actions/"+input.ActionID+"/approve", nil, map[int][]error{
+		403: {
+			errs.ErrDeviceGroupBulkActionSelfApproval,
+			errs.ErrDeviceGroupBulkActionUnverifiedApprover,
+		},
+		404: {
+			errs.ErrDeviceGroupBulkActionNotFound,
+			errs.ErrDeviceGroupNotFound,
+		},
+		409: {
+			errs.ErrDeviceGroupBulkActionNotPending,
+			errs.ErrDeviceGroupBulkActionApprovalExpired,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *deviceManagerClient) GetDeviceGroupBulkActions(ctx context.Context, input services.GetDeviceGroupBulkActionsInput) (string, error) {
+	url := cli.baseUrl + "/v1/groups/" + input.GroupID + "/actions"
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.DeviceGroupBulkAction, *resources.GetDeviceGroupBulkActionsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
+		return "", err
+	} else {
+		resp, err := Get[resources.GetDeviceGroupBulkActionsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *deviceManagerClient) GetDeviceGroupBulkActionByID(ctx context.Context, input services.GetDeviceGroupBulkActionByIDInput) (*models.DeviceGroupBulkAction, error) {
+	response, err := Get[*models.DeviceGroupBulkAction](ctx, cli.httpClient, cli.baseUrl+"/v1/groups/"+input.GroupID+"/actions/"+input.ActionID, nil, map[int][]error{
+		404: {
+			errs.ErrDeviceGroupBulkActionNotFound,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -6,6 +6,7 @@ import (
 	"fmt"
 	"net/http"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
@@ -30,13 +31,39 @@ func (cli *dmsManagerClient) GetDMSStats(ctx context.Context, input services.Get
 	return &resp, err
 }
 
+func (cli *dmsManagerClient) GetDMSEnrollmentStats(ctx context.Context, input services.GetDMSEnrollmentStatsInput) (*models.DMSEnrollmentStats, error) {
+	url := cli.baseUrl + "/v1/dms/" + input.DMSID + "/stats/enrollments"
+	resp, err := Get[models.DMSEnrollmentStats](ctx, cli.httpClient, url, nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return &resp, nil
+}
+
 func (cli *dmsManagerClient) CreateDMS(ctx context.Context, input services.CreateDMSInput) (*models.DMS, error) {
 	response, err := Post[*models.DMS](ctx, cli.httpClient, cli.baseUrl+"/v1/dms", resources.CreateDMSBody{
 		ID:       input.ID,
 		Name:     input.Name,
 		Metadata: input.Metadata,
 		Settings: input.Settings,
-	}, map[int][]error{})
+		CSR:      input.CSR,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		409: {
+			errs.ErrDMSAlreadyExists,
+			errs.ErrDMSPublicKeyConflict,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -45,7 +72,14 @@ func (cli *dmsManagerClient) CreateDMS(ctx context.Context, input services.Creat
 }
 
 func (cli *dmsManagerClient) UpdateDMS(ctx context.Context, input services.UpdateDMSInput) (*models.DMS, error) {
-	response, err := Put[*models.DMS](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMS.ID, input.DMS, map[int][]error{})
+	response, err := PutIfMatch[*models.DMS](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMS.ID, input.DMS, input.IfMatch, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		412: {
+			errs.ErrPreconditionFailed,
+		},
+	})
 	if err != nil {
 		return nil, err
 	}
@@ -63,10 +97,13 @@ func (cli *dmsManagerClient) GetAll(ctx context.Context, input services.GetAllIn
 	url := cli.baseUrl + "/v1/dms"
 
 	if input.ExhaustiveRun {
-		err := IterGet[models.DMS, resources.GetDMSsResponse](ctx, cli.httpClient, url, nil, input.ApplyFunc, map[int][]error{})
+		err := IterGet[models.DMS, resources.GetDMSsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
 		return "", err
 	} else {
 		resp, err := Get[resources.GetDMSsResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
 		return resp.NextBookmark, err
 	}
 }
@@ -75,6 +112,10 @@ func (cli *dmsManagerClient) CACerts(ctx context.Context, aps string) ([]*x509.C
 	return nil, fmt.Errorf("not supported, use the estCli instead")
 }
 
+func (cli *dmsManagerClient) SignedCACerts(ctx context.Context, aps string, pemEncoded bool) (*models.SignedContent, error) {
+	return nil, fmt.Errorf("not supported, use the estCli instead")
+}
+
 func (cli *dmsManagerClient) Enroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, error) {
 	return nil, fmt.Errorf("not supported, use the estCli instead")
 }
@@ -99,3 +140,406 @@ func (cli *dmsManagerClient) BindIdentityToDevice(ctx context.Context, input ser
 
 	return response, nil
 }
+
+func (cli *dmsManagerClient) RevokeSupersededCertificate(ctx context.Context, input services.RevokeSupersededCertificateInput) (*models.Certificate, error) {
+	response, err := Post[*models.Certificate](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/superseded/"+input.SerialNumber+"/revoke", nil, map[int][]error{
+		404: {
+			errs.ErrCertificateNotFound,
+		},
+		409: {
+			errs.ErrDMSNoPendingSupersededRevocation,
+			errs.ErrDMSSupersededGracePeriod,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) StartCARotation(ctx context.Context, input services.StartCARotationInput) (*models.CARotation, error) {
+	response, err := Post[*models.CARotation](ctx, cli.httpClient, cli.baseUrl+"/v1/ca-rotations", resources.StartCARotationBody{
+		CAID:      input.CAID,
+		BatchSize: input.BatchSize,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrCAType,
+			errs.ErrCAStatus,
+		},
+		404: {
+			errs.ErrCANotFound,
+		},
+		409: {
+			errs.ErrCARotationAlreadyStarted,
+			errs.ErrCARotationCompleted,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) ProcessCARotationBatch(ctx context.Context, input services.ProcessCARotationBatchInput) (*models.CARotation, error) {
+	response, err := Post[*models.CARotation](ctx, cli.httpClient, cli.baseUrl+"/v1/ca-rotations/"+input.CAID+"/batch", nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCARotationNotFound,
+		},
+		409: {
+			errs.ErrCARotationCompleted,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) GetCARotation(ctx context.Context, input services.GetCARotationInput) (*models.CARotation, error) {
+	response, err := Get[*models.CARotation](ctx, cli.httpClient, cli.baseUrl+"/v1/ca-rotations/"+input.CAID, nil, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrCANotFound,
+			errs.ErrCARotationNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) RevokeDeviceCertificate(ctx context.Context, input services.RevokeDeviceCertificateInput) (*models.Certificate, error) {
+	response, err := Post[*models.Certificate](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/certificates/"+input.SerialNumber+"/revoke", resources.RevokeDeviceCertificateBody{
+		RevocationReason: input.RevocationReason,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		403: {
+			errs.ErrDMSSelfServiceForbidden,
+			errs.ErrDMSCertificateNotOwned,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+			errs.ErrCertificateNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) CreateACMEEABKey(ctx context.Context, input services.CreateACMEEABKeyInput) (*models.DMSACMEEABCredentials, error) {
+	response, err := Post[*models.DMSACMEEABCredentials](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/acme/eab-keys", nil, map[int][]error{
+		403: {
+			errs.ErrFeatureDisabled,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+		409: {
+			errs.ErrDMSACMEEABNotConfigured,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) GetACMEEABKeys(ctx context.Context, input services.GetACMEEABKeysInput) ([]models.DMSACMEEABKey, error) {
+	response, err := Get[[]models.DMSACMEEABKey](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/acme/eab-keys", nil, map[int][]error{
+		403: {
+			errs.ErrFeatureDisabled,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) RevokeACMEEABKey(ctx context.Context, input services.RevokeACMEEABKeyInput) (*models.DMSACMEEABKey, error) {
+	response, err := Post[*models.DMSACMEEABKey](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/acme/eab-keys/"+input.KeyID+"/revoke", nil, map[int][]error{
+		403: {
+			errs.ErrFeatureDisabled,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+			errs.ErrDMSACMEEABKeyNotFound,
+		},
+		409: {
+			errs.ErrDMSACMEEABKeyRevoked,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) UpdateDMSStatus(ctx context.Context, input services.UpdateDMSStatusInput) (*models.DMS, error) {
+	response, err := Put[*models.DMS](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.ID+"/status", resources.UpdateDMSStatusBody{
+		Status: input.Status,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrDMSInvalidStatus,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) CreateEnrollmentPolicy(ctx context.Context, input services.CreateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error) {
+	response, err := Post[*models.DMSEnrollmentPolicy](ctx, cli.httpClient, cli.baseUrl+"/v1/enrollment-policies", resources.CreateEnrollmentPolicyBody{
+		ID:           input.ID,
+		Name:         input.Name,
+		Description:  input.Description,
+		SubjectRegex: input.SubjectRegex,
+		KeyTypes:     input.KeyTypes,
+		MinKeyBits:   input.MinKeyBits,
+		AllowedCAIDs: input.AllowedCAIDs,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		409: {
+			errs.ErrDMSEnrollmentPolicyAlreadyExists,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) GetEnrollmentPolicies(ctx context.Context, input services.GetEnrollmentPoliciesInput) (string, error) {
+	url := cli.baseUrl + "/v1/enrollment-policies"
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.DMSEnrollmentPolicy, resources.GetEnrollmentPoliciesResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, map[int][]error{})
+		return "", err
+	} else {
+		resp, err := Get[resources.GetEnrollmentPoliciesResponse](ctx, cli.httpClient, url, input.QueryParameters, map[int][]error{})
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *dmsManagerClient) GetEnrollmentPolicyByID(ctx context.Context, input services.GetEnrollmentPolicyByIDInput) (*models.DMSEnrollmentPolicy, error) {
+	response, err := Get[*models.DMSEnrollmentPolicy](ctx, cli.httpClient, cli.baseUrl+"/v1/enrollment-policies/"+input.ID, nil, map[int][]error{
+		404: {
+			errs.ErrDMSEnrollmentPolicyNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) UpdateEnrollmentPolicy(ctx context.Context, input services.UpdateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error) {
+	response, err := Put[*models.DMSEnrollmentPolicy](ctx, cli.httpClient, cli.baseUrl+"/v1/enrollment-policies/"+input.Policy.ID, input.Policy, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDMSEnrollmentPolicyNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) DeleteEnrollmentPolicy(ctx context.Context, input services.DeleteEnrollmentPolicyInput) error {
+	return Delete(ctx, cli.httpClient, cli.baseUrl+"/v1/enrollment-policies/"+input.ID, map[int][]error{
+		404: {
+			errs.ErrDMSEnrollmentPolicyNotFound,
+		},
+	})
+}
+
+func (cli *dmsManagerClient) CreateBootstrapToken(ctx context.Context, input services.CreateBootstrapTokenInput) (*models.DMSBootstrapTokenCredentials, error) {
+	response, err := Post[*models.DMSBootstrapTokenCredentials](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/bootstrap-tokens", resources.CreateBootstrapTokenBody{
+		DeviceID: input.DeviceID,
+		TTL:      input.TTL,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) GetBootstrapTokens(ctx context.Context, input services.GetBootstrapTokensInput) ([]models.DMSBootstrapToken, error) {
+	response, err := Get[[]models.DMSBootstrapToken](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/bootstrap-tokens", nil, map[int][]error{
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) GetEnrollmentAudits(ctx context.Context, input services.GetEnrollmentAuditsInput) (string, error) {
+	url := cli.baseUrl + "/v1/dms/" + input.DMSID + "/enrollment-audits"
+	knownErrors := map[int][]error{
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	}
+
+	if input.ExhaustiveRun {
+		err := IterGet[models.DMSEnrollmentAudit, *resources.GetEnrollmentAuditsResponse](ctx, cli.httpClient, url, input.QueryParameters, input.ApplyFunc, knownErrors)
+		return "", err
+	} else {
+		resp, err := Get[resources.GetEnrollmentAuditsResponse](ctx, cli.httpClient, url, input.QueryParameters, knownErrors)
+		for _, elem := range resp.IterableList.List {
+			input.ApplyFunc(elem)
+		}
+		return resp.NextBookmark, err
+	}
+}
+
+func (cli *dmsManagerClient) RevokeBootstrapToken(ctx context.Context, input services.RevokeBootstrapTokenInput) (*models.DMSBootstrapToken, error) {
+	response, err := Post[*models.DMSBootstrapToken](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/bootstrap-tokens/"+input.TokenID+"/revoke", nil, map[int][]error{
+		404: {
+			errs.ErrDMSBootstrapTokenNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) CreateClaimCode(ctx context.Context, input services.CreateClaimCodeInput) (*models.DMSClaimCodeCredentials, error) {
+	response, err := Post[*models.DMSClaimCodeCredentials](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/claim-codes", resources.CreateClaimCodeBody{
+		DeviceID: input.DeviceID,
+		TTL:      input.TTL,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) GetClaimCodes(ctx context.Context, input services.GetClaimCodesInput) ([]models.DMSClaimCode, error) {
+	response, err := Get[[]models.DMSClaimCode](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/claim-codes", nil, map[int][]error{
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) RevokeClaimCode(ctx context.Context, input services.RevokeClaimCodeInput) (*models.DMSClaimCode, error) {
+	response, err := Post[*models.DMSClaimCode](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/claim-codes/"+input.CodeID+"/revoke", nil, map[int][]error{
+		404: {
+			errs.ErrDMSClaimCodeNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) ClaimDevice(ctx context.Context, input services.ClaimDeviceInput) (*models.DeviceClaim, error) {
+	response, err := Post[*models.DeviceClaim](ctx, cli.httpClient, cli.baseUrl+"/v1/claim", resources.ClaimDeviceBody{
+		Code:              input.Code,
+		CSR:               (*models.X509CertificateRequest)(input.CSR),
+		InstallerMetadata: input.InstallerMetadata,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+			errs.ErrDeviceInvalidID,
+		},
+		403: {
+			errs.ErrDMSInvalidClaimCode,
+			errs.ErrDMSEnrollForbidden,
+			errs.ErrDMSEnrollDeviceNotRegistered,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
+
+func (cli *dmsManagerClient) PreregisterDevices(ctx context.Context, input services.PreregisterDevicesInput) (*models.DevicePreregistration, error) {
+	response, err := Post[*models.DevicePreregistration](ctx, cli.httpClient, cli.baseUrl+"/v1/dms/"+input.DMSID+"/preregister", resources.PreregisterDevicesBody{
+		DeviceIDs: input.DeviceIDs,
+	}, map[int][]error{
+		400: {
+			errs.ErrValidateBadRequest,
+		},
+		404: {
+			errs.ErrDMSNotFound,
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return response, nil
+}
@@ -0,0 +1,157 @@
+package external_clients
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+const (
+	inClusterTokenFile  = "/var/run/secrets/kubernetes.io/serviceaccount/token"
+	inClusterCACertFile = "/var/run/secrets/kubernetes.io/serviceaccount/ca.crt"
+)
+
+// KubernetesClient reads and updates the status of the Lamassu custom resources through the Kubernetes REST API.
+type KubernetesClient struct {
+	httpClient *http.Client
+	apiServer  string
+	tokenFile  string
+	namespace  string
+}
+
+// NewKubernetesClient builds the client from the config, falling back to the in-cluster service account.
+func NewKubernetesClient(conf config.KubernetesAPI) (*KubernetesClient, error) {
+	apiServer := conf.APIServer
+	tokenFile := conf.TokenFile
+	caCertFile := conf.CACertFile
+	if apiServer == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("no API server configured and not running in a Kubernetes cluster")
+		}
+
+		apiServer = "https://" + net.JoinHostPort(host, port)
+		if tokenFile == "" {
+			tokenFile = inClusterTokenFile
+		}
+		if caCertFile == "" {
+			caCertFile = inClusterCACertFile
+		}
+	}
+
+	tlsConfig := &tls.Config{}
+	if caCertFile != "" {
+		caCert, err := helpers.ReadCertificateFromFile(caCertFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read Kubernetes API server CA certificate: %s", err)
+		}
+
+		pool := helpers.LoadSytemCACertPool()
+		pool.AddCert(caCert)
+		tlsConfig.RootCAs = pool
+	}
+
+	return &KubernetesClient{
+		httpClient: &http.Client{Transport: &http.Transport{TLSClientConfig: tlsConfig}},
+		apiServer:  strings.TrimSuffix(apiServer, "/"),
+		tokenFile:  tokenFile,
+		namespace:  conf.Namespace,
+	}, nil
+}
+
+func (c *KubernetesClient) ListCertificateAuthorities(ctx context.Context) ([]models.CertificateAuthorityResource, error) {
+	return listKubernetesResources[models.CertificateAuthorityResource](ctx, c, models.KubernetesCertificateAuthorityPlural)
+}
+
+func (c *KubernetesClient) ListDMSs(ctx context.Context) ([]models.DMSResource, error) {
+	return listKubernetesResources[models.DMSResource](ctx, c, models.KubernetesDMSPlural)
+}
+
+func (c *KubernetesClient) UpdateCertificateAuthorityStatus(ctx context.Context, resource models.CertificateAuthorityResource) error {
+	return c.patchStatus(ctx, models.KubernetesCertificateAuthorityPlural, resource.Metadata, resource.Status)
+}
+
+func (c *KubernetesClient) UpdateDMSStatus(ctx context.Context, resource models.DMSResource) error {
+	return c.patchStatus(ctx, models.KubernetesDMSPlural, resource.Metadata, resource.Status)
+}
+
+func listKubernetesResources[E any](ctx context.Context, c *KubernetesClient, plural string) ([]E, error) {
+	path := fmt.Sprintf("/apis/%s/%s/%s", models.KubernetesAPIGroup, models.KubernetesAPIVersion, plural)
+	if c.namespace != "" {
+		path = fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s", models.KubernetesAPIGroup, models.KubernetesAPIVersion, c.namespace, plural)
+	}
+
+	body, err := c.do(ctx, http.MethodGet, path, "", nil)
+	if err != nil {
+		return nil, err
+	}
+
+	var list struct {
+		Items []E `json:"items"`
+	}
+	err = json.Unmarshal(body, &list)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode %s list: %s", plural, err)
+	}
+
+	return list.Items, nil
+}
+
+func (c *KubernetesClient) patchStatus(ctx context.Context, plural string, meta models.KubernetesObjectMeta, status models.KubernetesResourceStatus) error {
+	patch, err := json.Marshal(map[string]any{"status": status})
+	if err != nil {
+		return err
+	}
+
+	path := fmt.Sprintf("/apis/%s/%s/namespaces/%s/%s/%s/status", models.KubernetesAPIGroup, models.KubernetesAPIVersion, meta.Namespace, plural, meta.Name)
+	_, err = c.do(ctx, http.MethodPatch, path, "application/merge-patch+json", patch)
+	return err
+}
+
+func (c *KubernetesClient) do(ctx context.Context, method, path, contentType string, body []byte) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.apiServer+path, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not generate Kubernetes API request: %s", err)
+	}
+
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+
+	// Service account tokens are rotated. The token is read on every request
+	if c.tokenFile != "" {
+		token, err := os.ReadFile(c.tokenFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read Kubernetes token: %s", err)
+		}
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	res, err := c.httpClient.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not do Kubernetes API request: %s", err)
+	}
+	defer res.Body.Close()
+
+	resBody, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, fmt.Errorf("could not read Kubernetes API response: %s", err)
+	}
+
+	if res.StatusCode < 200 || res.StatusCode > 299 {
+		return nil, fmt.Errorf("unexpected Kubernetes API status code %d for %s %s: %s", res.StatusCode, method, path, string(resBody))
+	}
+
+	return resBody, nil
+}
@@ -0,0 +1,47 @@
+package external_clients
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// QuerySecureElementAllowList asks the manufacturer allow-list service whether the secure element can be enrolled.
+func QuerySecureElementAllowList(ctx context.Context, allowListURL string, identity models.SecureElementIdentity) (*models.SecureElementAllowListResponse, error) {
+	body, err := json.Marshal(identity)
+	if err != nil {
+		return nil, fmt.Errorf("could not encode secure element identity: %s", err)
+	}
+
+	httpRequest, err := http.NewRequestWithContext(ctx, http.MethodPost, allowListURL, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("could not generate HTTP allow-list request: %s", err)
+	}
+
+	httpRequest.Header.Add("Content-Type", "application/json")
+	httpRequest.Header.Add("Accept", "application/json")
+
+	httpClient := &http.Client{Timeout: 10 * time.Second}
+	httpResponse, err := httpClient.Do(httpRequest)
+	if err != nil {
+		return nil, fmt.Errorf("could not DO allow-list request: %s", err)
+	}
+
+	defer httpResponse.Body.Close()
+	if httpResponse.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected allow-list response status %d", httpResponse.StatusCode)
+	}
+
+	var response models.SecureElementAllowListResponse
+	err = json.NewDecoder(httpResponse.Body).Decode(&response)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode allow-list response: %s", err)
+	}
+
+	return &response, nil
+}
@@ -0,0 +1,48 @@
+package clients
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+)
+
+// httpHybridSigner delegates the post-quantum keys of hybrid CAs to an external signer exposing:
+//   - POST /v1/keys: creates a key (resources.HybridSignerCreateKeyBody) and returns its public key.
+//   - POST /v1/keys/:keyID/sign: signs the message (resources.HybridSignerSignBody) with the key.
+type httpHybridSigner struct {
+	httpClient *http.Client
+	baseUrl    string
+}
+
+func NewHttpHybridSigner(client *http.Client, url string) *httpHybridSigner {
+	return &httpHybridSigner{
+		httpClient: client,
+		baseUrl:    url,
+	}
+}
+
+func (cli *httpHybridSigner) CreateKey(keyType models.KeyType, keyID string) ([]byte, error) {
+	response, err := Post[resources.HybridSignerCreateKeyResponse](context.Background(), cli.httpClient, cli.baseUrl+"/v1/keys", resources.HybridSignerCreateKeyBody{
+		KeyID: keyID,
+		Type:  keyType,
+	}, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.PublicKey, nil
+}
+
+func (cli *httpHybridSigner) Sign(keyID string, message []byte) ([]byte, error) {
+	response, err := Post[resources.HybridSignerSignResponse](context.Background(), cli.httpClient, cli.baseUrl+"/v1/keys/"+url.PathEscape(keyID)+"/sign", resources.HybridSignerSignBody{
+		Message: message,
+	}, map[int][]error{})
+	if err != nil {
+		return nil, err
+	}
+
+	return response.Signature, nil
+}
@@ -8,6 +8,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"time"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
@@ -43,6 +44,12 @@ type sourceRoundTripper struct {
 
 func (lrt sourceRoundTripper) RoundTrip(req *http.Request) (res *http.Response, err error) {
 	req.Header.Add(models.HttpSourceHeader, lrt.source)
+
+	// forward the ID of the request being served so the downstream service logs and traces can be correlated
+	if reqID := helpers.GetRequestID(req.Context()); reqID != "" && req.Header.Get(models.HttpRequestIDHeader) == "" {
+		req.Header.Set(models.HttpRequestIDHeader, reqID)
+	}
+
 	return lrt.transport.RoundTrip(req)
 }
 
@@ -71,14 +78,24 @@ func BuildHTTPClient(cfg config.HTTPClient, logger *logrus.Entry) (*http.Client,
 
 	switch cfg.AuthMode {
 	case config.MTLS:
-		cert, err := tls.LoadX509KeyPair(cfg.AuthMTLSOptions.CertFile, cfg.AuthMTLSOptions.KeyFile)
+		// the issuer is called without client certificate, authenticated with the token of the service
+		issuerCli := &http.Client{
+			Timeout: 10 * time.Second,
+			Transport: &http.Transport{
+				TLSClientConfig: tlsConfig.Clone(),
+			},
+		}
+
+		clientCert, err := helpers.NewInternalClientCertificate(cfg.AuthMTLSOptions, issuerCli)
 		if err != nil {
 			return nil, err
 		}
 
-		tlsConfig.Certificates = []tls.Certificate{cert}
+		// certificates are renewed on each TLS handshake once they are close to expire
+		tlsConfig.GetClientCertificate = clientCert.GetClientCertificate
 		client.Transport = &http.Transport{
 			TLSClientConfig: tlsConfig,
+			IdleConnTimeout: 90 * time.Second,
 		}
 
 	case config.JWT:
@@ -131,14 +148,32 @@ func BuildHTTPClient(cfg config.HTTPClient, logger *logrus.Entry) (*http.Client,
 }
 
 func Post[T any](ctx context.Context, client *http.Client, url string, data any, knownErrors map[int][]error) (T, error) {
-	return requestWithBody[T](ctx, client, "POST", url, data, knownErrors)
+	return requestWithBody[T](ctx, client, "POST", url, data, nil, knownErrors)
 }
 
 func Put[T any](ctx context.Context, client *http.Client, url string, data any, knownErrors map[int][]error) (T, error) {
-	return requestWithBody[T](ctx, client, "PUT", url, data, knownErrors)
+	return requestWithBody[T](ctx, client, "PUT", url, data, nil, knownErrors)
+}
+
+// PostIfMatch is Post with an If-Match precondition. No precondition is sent if ifMatch is empty.
+func PostIfMatch[T any](ctx context.Context, client *http.Client, url string, data any, ifMatch string, knownErrors map[int][]error) (T, error) {
+	return requestWithBody[T](ctx, client, "POST", url, data, ifMatchHeader(ifMatch), knownErrors)
+}
+
+// PutIfMatch is Put with an If-Match precondition. No precondition is sent if ifMatch is empty.
+func PutIfMatch[T any](ctx context.Context, client *http.Client, url string, data any, ifMatch string, knownErrors map[int][]error) (T, error) {
+	return requestWithBody[T](ctx, client, "PUT", url, data, ifMatchHeader(ifMatch), knownErrors)
 }
 
-func requestWithBody[T any](ctx context.Context, client *http.Client, method string, url string, data any, knownErrors map[int][]error) (T, error) {
+func ifMatchHeader(ifMatch string) map[string]string {
+	if ifMatch == "" {
+		return nil
+	}
+
+	return map[string]string{"If-Match": ifMatch}
+}
+
+func requestWithBody[T any](ctx context.Context, client *http.Client, method string, url string, data any, headers map[string]string, knownErrors map[int][]error) (T, error) {
 	var m T
 	b, err := toJSON(data)
 	if err != nil {
@@ -152,6 +187,10 @@ func requestWithBody[T any](ctx context.Context, client *http.Client, method str
 	}
 	// Important to set
 	r.Header.Add("Content-Type", "application/json")
+	for key, value := range headers {
+		r.Header.Set(key, value)
+	}
+
 	res, err := client.Do(r)
 	if err != nil {
 		return m, err
@@ -176,18 +215,7 @@ func Get[T any](ctx context.Context, client *http.Client, url string, queryParam
 		return m, err
 	}
 
-	if queryParams != nil {
-		query := r.URL.Query()
-		if queryParams.NextBookmark != "" {
-			query.Add("bookmark", queryParams.NextBookmark)
-		}
-
-		if queryParams.PageSize > 0 {
-			query.Add("page_size", fmt.Sprintf("%d", queryParams.PageSize))
-		}
-
-		r.URL.RawQuery = query.Encode()
-	}
+	setQueryParameters(r, queryParams)
 	// Important to set
 	r.Header.Add("Content-Type", "application/json")
 	res, err := client.Do(r)
@@ -207,6 +235,74 @@ func Get[T any](ctx context.Context, client *http.Client, url string, queryParam
 	return parseJSON[T](body)
 }
 
+// GetStream requests a newline delimited JSON stream and calls applyFunc for every element as it is received.
+func GetStream[E any](ctx context.Context, client *http.Client, url string, queryParams *resources.QueryParameters, applyFunc func(E), knownErrors map[int][]error) error {
+	r, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+
+	setQueryParameters(r, queryParams)
+	r.Header.Add("Accept", "application/x-ndjson")
+	res, err := client.Do(r)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != 200 {
+		body, err := io.ReadAll(res.Body)
+		if err != nil {
+			return err
+		}
+		return nonOKResponseToError(res.StatusCode, body, knownErrors)
+	}
+
+	decoder := json.NewDecoder(res.Body)
+	for {
+		var elem E
+		err := decoder.Decode(&elem)
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("could not decode stream element: %s", err)
+		}
+
+		applyFunc(elem)
+	}
+}
+
+func setQueryParameters(r *http.Request, queryParams *resources.QueryParameters) {
+	if queryParams != nil {
+		query := r.URL.Query()
+		if queryParams.NextBookmark != "" {
+			query.Add("next_bookmark", queryParams.NextBookmark)
+			// also sent with its legacy name, so servers not supporting next_bookmark keep paginating
+			query.Add("bookmark", queryParams.NextBookmark)
+		}
+
+		if queryParams.PageSize > 0 {
+			query.Add("page_size", fmt.Sprintf("%d", queryParams.PageSize))
+		}
+
+		if queryParams.Sort.SortField != "" {
+			query.Add("sort_by", queryParams.Sort.SortField)
+			query.Add("sort_mode", string(queryParams.Sort.SortMode))
+		}
+
+		for _, filter := range queryParams.Filters {
+			expr, ok := resources.FormatFilterExpression(filter)
+			if !ok {
+				continue
+			}
+			query.Add("filter", expr)
+		}
+
+		r.URL.RawQuery = query.Encode()
+	}
+}
+
 func Delete(ctx context.Context, client *http.Client, url string, knownErrors map[int][]error) error {
 	r, err := http.NewRequestWithContext(ctx, "DELETE", url, nil)
 	if err != nil {
@@ -233,13 +329,15 @@ func Delete(ctx context.Context, client *http.Client, url string, knownErrors ma
 	return nil
 }
 
+// IterGet follows the bookmarks of the paginated list until its last page. The filters, sorting and page size of the
+// query parameters apply to every page. If a bookmark is set, the iteration resumes from it.
 func IterGet[E any, T resources.Iterator[E]](ctx context.Context, client *http.Client, url string, queryParams *resources.QueryParameters, applyFunc func(E), knownErrors map[int][]error) error {
 	continueIter := true
-	if queryParams == nil {
-		queryParams = &resources.QueryParameters{}
+	pageParams := resources.QueryParameters{}
+	if queryParams != nil {
+		pageParams = *queryParams
 	}
-
-	queryParams.NextBookmark = ""
+	queryParams = &pageParams
 
 	for continueIter {
 		response, err := Get[T](ctx, client, url, queryParams, knownErrors)
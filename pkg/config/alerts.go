@@ -6,6 +6,18 @@ type AlertsConfig struct {
 	SubscriberEventBus EventBusEngine         `mapstructure:"subscriber_event_bus"`
 	Storage            PluggableStorageEngine `mapstructure:"storage"`
 	SMTPConfig         SMTPServer             `mapstructure:"smtp_server"`
+	ExpirationDigest   ExpirationDigest       `mapstructure:"expiration_digest"`
+}
+
+// ExpirationDigest schedules the digest of the CAs, DMS certificates and devices expiring soon. The digest is
+// delivered to the users subscribed to the "alerts.expiration.digest" event type.
+type ExpirationDigest struct {
+	CryptoMonitoring `mapstructure:",squash"`
+	// Windows are the expiration horizons covered by the digest (i.e. "30d", "60d"). Defaults to 30, 60 and 90 days.
+	Windows  []string `mapstructure:"windows"`
+	CAClient struct {
+		HTTPClient `mapstructure:",squash"`
+	} `mapstructure:"ca_client"`
 }
 
 type SMTPServer struct {
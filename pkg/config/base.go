@@ -34,10 +34,47 @@ type HttpServer struct {
 	CertFile           string                   `mapstructure:"cert_file"`
 	KeyFile            string                   `mapstructure:"key_file"`
 	Authentication     HttpServerAuthentication `mapstructure:"authentication"`
+	// APIs served by the main listener. All the APIs are served if empty.
+	APIs []ServerAPI `mapstructure:"apis"`
+	// Listeners bind additional addresses, so device traffic (i.e. EST) and the operator APIs can be exposed on
+	// different interfaces and ports. Use "::" as listen address to accept both IPv4 and IPv6 connections.
+	Listeners []HttpListener `mapstructure:"listeners"`
+	// DataPlane moves the device facing endpoints (EST, ACME, CRL and OCSP) to a dedicated router served by this
+	// listener, so they can use their own TLS and authentication policies. The management authentication (i.e.
+	// OIDC) is never applied to the data-plane endpoints. The endpoints are served by the main listener if nil.
+	DataPlane *HttpListener `mapstructure:"data_plane"`
+}
+
+// HttpListener is an additional listener of the HTTP server. Listeners without protocol inherit the protocol,
+// certificates and authentication of the server.
+type HttpListener struct {
+	ListenAddress  string                   `mapstructure:"listen_address"`
+	Port           int                      `mapstructure:"port"`
+	Protocol       HTTPProtocol             `mapstructure:"protocol"`
+	CertFile       string                   `mapstructure:"cert_file"`
+	KeyFile        string                   `mapstructure:"key_file"`
+	Authentication HttpServerAuthentication `mapstructure:"authentication"`
+	// APIs served by the listener. All the APIs are served if empty.
+	APIs []ServerAPI `mapstructure:"apis"`
 }
 
 type HttpServerAuthentication struct {
 	MutualTLS HttpServerMutualTLSAuthentication `mapstructure:"mutual_tls"`
+	OIDC      HttpServerOIDCAuthentication      `mapstructure:"oidc"`
+}
+
+// HttpServerOIDCAuthentication requires the management endpoints to be called with a bearer token issued by the
+// OIDC provider. Requests presenting an internal certificate of another Lamassu service, issued by the internal CA
+// for one of the InternalServices identities, are accepted without token.
+type HttpServerOIDCAuthentication struct {
+	Enabled          bool   `mapstructure:"enabled"`
+	OIDCWellKnownURL string `mapstructure:"oidc_well_known"`
+	// Audience, if set, must be included in the aud claim of the tokens.
+	Audience string `mapstructure:"audience"`
+	// InternalCACertificateFile is the certificate of the CA issuing the internal certificates of the services.
+	InternalCACertificateFile string   `mapstructure:"internal_ca_cert_file"`
+	InternalServices          []string `mapstructure:"internal_services"`
+	TLSConfig                 `mapstructure:",squash"`
 }
 type HttpServerMutualTLSAuthentication struct {
 	Enabled           bool          `mapstructure:"enabled"`
@@ -53,14 +90,61 @@ const (
 	Any     MutualTLSMode = "any"
 )
 
+// FaultInjection configures the opt-in chaos middleware, which delays and fails the calls made to the storage engine,
+// the crypto engines and the event bus, to test how devices and RA integrations behave during partial outages.
+// Never enable it in production.
+type FaultInjection struct {
+	Enabled       bool                 `mapstructure:"enabled"`
+	Storage       FaultInjectionTarget `mapstructure:"storage"`
+	CryptoEngines FaultInjectionTarget `mapstructure:"crypto_engines"`
+	EventBus      FaultInjectionTarget `mapstructure:"event_bus"`
+}
+
+type FaultInjectionTarget struct {
+	// Latency added to each call (i.e. "250ms")
+	Latency string `mapstructure:"latency"`
+	// ErrorRate is the ratio (0 to 1) of calls failing with an injected error
+	ErrorRate float64 `mapstructure:"error_rate"`
+}
+
+// DebugTrace records in memory the HTTP hops, CA signatures, storage writes and events published while serving each
+// request, so the full trace of a request ID can be exported through the debug API without a tracing backend.
+type DebugTrace struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// FeatureFlags enables the experimental subsystems of a service. Flags not listed are disabled.
+// If RuntimeToggles is set, flags can also be switched through the service admin API (not persisted across restarts).
+type FeatureFlags struct {
+	RuntimeToggles bool            `mapstructure:"runtime_toggles"`
+	Flags          map[string]bool `mapstructure:"flags"`
+}
+
+// DependencyMonitoring periodically checks the service dependencies (storage, event bus, crypto engines, other
+// services...) and publishes the aggregated status into the publisher event bus. The status is always served by the
+// service /v1/status endpoint, regardless of this configuration.
+type DependencyMonitoring struct {
+	CryptoMonitoring `mapstructure:",squash"`
+	// CheckTimeout is the maximum duration of each dependency check (i.e. "5s"). Defaults to "5s".
+	CheckTimeout string `mapstructure:"check_timeout"`
+}
+
 type PluggableStorageEngine struct {
 	LogLevel LogLevel `mapstructure:"log_level"`
 
 	Provider StorageProvider `mapstructure:"provider"`
 
-	CouchDB  CouchDBPSEConfig  `mapstructure:"couch_db"`
-	Postgres PostgresPSEConfig `mapstructure:"postgres"`
-	SQLite   SQLitePSEConfig   `mapstructure:"sqlite"`
+	CouchDB   CouchDBPSEConfig    `mapstructure:"couch_db"`
+	Postgres  PostgresPSEConfig   `mapstructure:"postgres"`
+	SQLite    SQLitePSEConfig     `mapstructure:"sqlite"`
+	DualWrite *DualWritePSEConfig `mapstructure:"dual_write"`
+}
+
+// DualWritePSEConfig defines the storage engines used by the dual_write provider. Source remains the engine the
+// services read from until the backend switch, Target is the engine being migrated to.
+type DualWritePSEConfig struct {
+	Source PluggableStorageEngine `mapstructure:"source"`
+	Target PluggableStorageEngine `mapstructure:"target"`
 }
 
 type CouchDBPSEConfig struct {
@@ -89,6 +173,32 @@ type EventBusEngine struct {
 
 	Amqp      AMQPConnection `mapstructure:"amqp"`
 	AWSSqsSns AWSSDKConfig   `mapstructure:"aws_sqs_sns"`
+
+	// Consumers configures how the subscribed topics are consumed. It is ignored by publishers.
+	Consumers EventBusConsumers `mapstructure:"consumers"`
+}
+
+// EventBusConsumers sets the number of workers consuming the queue of each subscribed topic. Workers of the same
+// queue compete for its messages, so events are processed concurrently and may be processed out of order.
+type EventBusConsumers struct {
+	// Concurrency is the number of workers of each queue. Defaults to 1.
+	Concurrency int                     `mapstructure:"concurrency"`
+	Topics      []EventBusTopicConsumer `mapstructure:"topics"`
+	// ProcessedEventsTTL enables the deduplication of redelivered events. The handled events are remembered for
+	// this duration (i.e. "1h") and acknowledged without handling them again if redelivered within it.
+	ProcessedEventsTTL string `mapstructure:"processed_events_ttl"`
+	// ProcessedEventsStorage stores the handled events, so the redeliveries to any instance of the service are
+	// detected. If unset, each instance only remembers the events it handled.
+	ProcessedEventsStorage *PluggableStorageEngine `mapstructure:"processed_events_storage"`
+}
+
+// EventBusTopicConsumer overrides the consumption of a subscribed topic (i.e. "certificate.#").
+type EventBusTopicConsumer struct {
+	Topic       string `mapstructure:"topic"`
+	Concurrency int    `mapstructure:"concurrency"`
+	// Ordered topics are consumed by a single worker, so the events of every routing key of the topic are processed
+	// in the order they were published. Concurrency is ignored.
+	Ordered bool `mapstructure:"ordered"`
 }
 
 type TLSConfig struct {
@@ -113,7 +223,10 @@ type AMQPConnection struct {
 	Exchange        string                  `mapstructure:"exchange"`
 	Protocol        AMQPProtocol            `mapstructure:"protocol"`
 	BasicAuth       AMQPConnectionBasicAuth `mapstructure:"basic_auth"`
-	ClientTLSAuth   struct {
+	// PrefetchCount limits the unacknowledged messages delivered to each worker. The broker default (unlimited)
+	// applies when 0.
+	PrefetchCount int `mapstructure:"prefetch_count"`
+	ClientTLSAuth struct {
 		Enabled  bool   `mapstructure:"enabled"`
 		CertFile string `mapstructure:"cert_file"`
 		KeyFile  string `mapstructure:"key_file"`
@@ -139,9 +252,16 @@ type AuthJWTOptions struct {
 	OIDCWellKnownURL string   `mapstructure:"oidc_well_known"`
 }
 
+// AuthMTLSOptions configures the short-lived client certificates authenticating the service-to-service calls. The
+// certificates are requested to the internal certificate issuer of the CA service (see InternalCertificates) for a
+// key generated by the service, which never leaves it. They are renewed once two thirds of their lifetime have elapsed.
 type AuthMTLSOptions struct {
-	CertFile string `mapstructure:"cert_file"`
-	KeyFile  string `mapstructure:"key_file"`
+	// IssuerURL is the base URL of the CA service issuing the internal certificates (i.e. "https://ca:8085").
+	IssuerURL string `mapstructure:"issuer_url"`
+	// Identity is the service identity set as the common name of the certificates. It must be bound to the token
+	// in the issuer configuration.
+	Identity string   `mapstructure:"identity"`
+	Token    Password `mapstructure:"token"`
 }
 
 func readConfig[E any](configFilePath string, defaults *E) (*E, error) {
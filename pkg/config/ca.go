@@ -8,6 +8,87 @@ type CAConfig struct {
 	CryptoEngines     CryptoEngines          `mapstructure:"crypto_engines"`
 	CryptoMonitoring  CryptoMonitoring       `mapstructure:"crypto_monitoring"`
 	VAServerDomain    string                 `mapstructure:"va_server_domain"`
+	CRL               CRLConfig              `mapstructure:"crl"`
+	CSRLimits         CSRLimits              `mapstructure:"csr_limits"`
+	IssuanceWebhooks  IssuanceWebhooks       `mapstructure:"issuance_webhooks"`
+	HybridSigners     []HybridSigner         `mapstructure:"hybrid_signers"`
+	IssuanceLog       IssuanceLog            `mapstructure:"issuance_log"`
+	// InternalCertificates issues the client certificates of the service-to-service calls.
+	InternalCertificates InternalCertificates `mapstructure:"internal_certificates"`
+	// BatchSigningWorkers bounds the certificate requests of a batch signed concurrently. Defaults to the number of CPUs.
+	BatchSigningWorkers int `mapstructure:"batch_signing_workers"`
+
+	DestructiveOperationsApproval DestructiveOperationsApproval `mapstructure:"destructive_operations_approval"`
+
+	DependencyMonitoring DependencyMonitoring `mapstructure:"dependency_monitoring"`
+
+	FeatureFlags   FeatureFlags   `mapstructure:"feature_flags"`
+	FaultInjection FaultInjection `mapstructure:"fault_injection"`
+	DebugTrace     DebugTrace     `mapstructure:"debug_trace"`
+}
+
+// HybridSigner configures an external signer holding the post-quantum keys of hybrid CAs. Hybrid CAs reference the
+// signer by its ID.
+type HybridSigner struct {
+	ID         string `mapstructure:"id"`
+	HTTPClient `mapstructure:",squash"`
+}
+
+// IssuanceWebhooks configures the client calling the issuance webhooks of the CAs. The webhook of each CA is set
+// through the issuance webhook endpoint of the CA.
+type IssuanceWebhooks struct {
+	// Timeout of each webhook call (i.e. "5s"). Defaults to "10s".
+	Timeout   string `mapstructure:"timeout"`
+	TLSConfig `mapstructure:",squash"`
+}
+
+// IssuanceLog configures the append-only issuance log of the CAs. Each signed certificate is appended to the Merkle
+// tree of its issuing CA, whose signed tree head and inclusion proofs are served under /v1/cas/:id/issuance-log.
+type IssuanceLog struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// InternalCertificates configures the issuer of the short-lived client certificates used by the Lamassu services to
+// authenticate against each other (HTTP clients with the "mtls" auth mode). The internal CA key is only read by the
+// CA service: the services request their certificates with a CSR under POST /v1/internal/certificates, authenticated
+// with the token bound to their identity. The endpoint is served by the data-plane router. The servers validate the
+// client certificates against the internal CA certificate (server.authentication.mutual_tls.ca_cert_file).
+type InternalCertificates struct {
+	Enabled    bool   `mapstructure:"enabled"`
+	CACertFile string `mapstructure:"ca_cert_file"`
+	CAKeyFile  string `mapstructure:"ca_key_file"`
+	// CertificateValidity is the lifetime of the issued certificates (i.e. "1h"). Defaults to "1h".
+	CertificateValidity string `mapstructure:"certificate_validity"`
+	// Services binds each service identity to the token it requests its certificates with.
+	Services []InternalService `mapstructure:"services"`
+}
+
+type InternalService struct {
+	Identity string   `mapstructure:"identity"`
+	Token    Password `mapstructure:"token"`
+}
+
+// CRLConfig configures the CRLs served by the CA service under /v1/crl/:caID.
+type CRLConfig struct {
+	// Validity is the window between the ThisUpdate and NextUpdate fields of the generated CRLs (i.e. "48h", "7d"). Defaults to "48h".
+	Validity string `mapstructure:"validity"`
+	// DistributionPoints are the base URLs embedded as CRL Distribution Points into the signed certificates. The ID
+	// of the issuing CA is appended to each URL (i.e. "https://lamassu.io/api/ca/v1/crl" results in
+	// "https://lamassu.io/api/ca/v1/crl/<CA ID>"). Signing profiles with their own distribution points take precedence.
+	DistributionPoints []string `mapstructure:"distribution_points"`
+}
+
+// CSRLimits bounds the certificate requests accepted by the enroll and sign endpoints, protecting the services from
+// pathological requests. Zero values fall back to the defaults.
+type CSRLimits struct {
+	// MaxSize is the maximum size in bytes of the DER encoded request. Defaults to 16384.
+	MaxSize int `mapstructure:"max_size"`
+	// MaxSANs is the maximum number of Subject Alternative Names (DNS, IP, email and URI). Defaults to 100.
+	MaxSANs int `mapstructure:"max_sans"`
+	// MaxExtensions is the maximum number of requested extensions. Defaults to 32.
+	MaxExtensions int `mapstructure:"max_extensions"`
+	// MaxBatchSize is the maximum number of requests of a batch signing request. Defaults to 1000.
+	MaxBatchSize int `mapstructure:"max_batch_size"`
 }
 
 type CryptoEngines struct {
@@ -15,15 +96,34 @@ type CryptoEngines struct {
 	DefaultEngine             string                             `mapstructure:"default_id"`
 	PKCS11Provider            []PKCS11EngineConfig               `mapstructure:"pkcs11"`
 	HashicorpVaultKV2Provider []HashicorpVaultCryptoEngineConfig `mapstructure:"hashicorp_vault"`
-	AWSKMSProvider            []AWSCryptoEngine                  `mapstructure:"aws_kms"`
+	AWSKMSProvider            []AWSKMSCryptoEngine               `mapstructure:"aws_kms"`
 	AWSSecretsManagerProvider []AWSCryptoEngine                  `mapstructure:"aws_secrets_manager"`
+	GCPKMSProvider            []GCPKMSCryptoEngine               `mapstructure:"gcp_kms"`
 	GolangProvider            []GolangEngineConfig               `mapstructure:"golang"`
+	// HealthCheck probes the engines periodically, reconnecting the unavailable ones. While enabled, engines that
+	// can not be created at startup are registered as unavailable instead of being skipped.
+	HealthCheck CryptoMonitoring `mapstructure:"health_check"`
 }
 
 type HashicorpVaultCryptoEngineConfig struct {
 	HashicorpVaultSDK `mapstructure:",squash"`
 	ID                string                 `mapstructure:"id"`
 	Metadata          map[string]interface{} `mapstructure:"metadata"`
+	// ManagedKeys, when set, keeps the keys of the engine in a Vault managed key backend (HSM or external KMS)
+	// instead of the KV-V2 mount. Configure a dedicated engine for the CAs whose keys must not leave the HSM.
+	ManagedKeys *HashicorpVaultManagedKeys `mapstructure:"managed_keys"`
+}
+
+// HashicorpVaultManagedKeys registers a Vault managed key for each key of the engine and signs through the Transit
+// secrets engine. Requires Vault Enterprise.
+type HashicorpVaultManagedKeys struct {
+	// Type of the managed key backend: pkcs11, awskms, azurekeyvault or gcpckms.
+	Type string `mapstructure:"type"`
+	// Parameters of the managed key backend (i.e. library, slot, pin and mechanism for pkcs11). The key type, size
+	// and name are set by the engine.
+	Parameters map[string]interface{} `mapstructure:"parameters"`
+	// TransitMountPath is the Transit mount used to sign with the managed keys. Defaults to "transit".
+	TransitMountPath string `mapstructure:"transit_mount_path"`
 }
 type HashicorpVaultSDK struct {
 	RoleID            string     `mapstructure:"role_id"`
@@ -31,17 +131,66 @@ type HashicorpVaultSDK struct {
 	AutoUnsealEnabled bool       `mapstructure:"auto_unseal_enabled"`
 	AutoUnsealKeys    []Password `mapstructure:"auto_unseal_keys"`
 	MountPath         string     `mapstructure:"mount_path"`
-	HTTPConnection    `mapstructure:",squash"`
+	// MountPathTemplate, when set, takes precedence over MountPath. It is a Go template
+	// rendered with the fields Tenant and EngineID (i.e. "lamassu/{{.Tenant}}/kv").
+	MountPathTemplate string `mapstructure:"mount_path_template"`
+	// KeyPathTemplate is the Go template used to build the secret path of each key inside the mount.
+	// Available fields: Tenant, EngineID, AssetType (certauth, cert), AssetID and KeyID. Defaults to "{{.KeyID}}".
+	// It must use KeyID or AssetID, so each key is stored in its own secret. The engine is not built otherwise.
+	KeyPathTemplate string `mapstructure:"key_path_template"`
+	// UseExistingMount makes the engine adopt a pre-provisioned KV-V2 mount instead of creating it.
+	UseExistingMount bool   `mapstructure:"use_existing_mount"`
+	Tenant           string `mapstructure:"tenant"`
+	HTTPConnection   `mapstructure:",squash"`
 }
 
 type GolangEngineConfig struct {
 	ID               string                 `mapstructure:"id"`
 	Metadata         map[string]interface{} `mapstructure:"metadata"`
 	StorageDirectory string                 `mapstructure:"storage_directory"`
+	// Encryption, when set, stores each key encrypted with its own AES-256-GCM data key, which is in turn wrapped
+	// by a master key encryption key (KEK). Plain PEM keys written before enabling it can still be read.
+	Encryption *GolangEngineEncryption `mapstructure:"encryption"`
+}
+
+type GolangKEKProvider string
+
+const (
+	GolangKEKStatic         GolangKEKProvider = "static"
+	GolangKEKHashicorpVault GolangKEKProvider = "hashicorp_vault"
+	GolangKEKAWSKMS         GolangKEKProvider = "aws_kms"
+)
+
+// GolangEngineEncryption selects where the master KEK of the Golang engine lives. Only the section of the
+// selected provider is used.
+type GolangEngineEncryption struct {
+	Provider GolangKEKProvider `mapstructure:"provider"`
+	// StaticKey is the base64 encoded 256 bit KEK used by the static provider.
+	StaticKey Password `mapstructure:"static_key"`
+	// HashicorpVault wraps the data keys with a Vault Transit key.
+	HashicorpVault *GolangVaultTransitKEK `mapstructure:"hashicorp_vault"`
+	// AWSKMS wraps the data keys with a symmetric KMS key.
+	AWSKMS *GolangAWSKMSKEK `mapstructure:"aws_kms"`
 }
 
+type GolangVaultTransitKEK struct {
+	HashicorpVaultSDK `mapstructure:",squash"`
+	// TransitMountPath is the Transit mount holding the KEK. Defaults to "transit".
+	TransitMountPath string `mapstructure:"transit_mount_path"`
+	KeyName          string `mapstructure:"key_name"`
+}
+
+type GolangAWSKMSKEK struct {
+	AWSSDKConfig `mapstructure:",squash"`
+	// KeyID is the ID, ARN or alias of the symmetric KMS key.
+	KeyID string `mapstructure:"key_id"`
+}
+
+// PKCS11Config selects the token of the PKCS#11 module (HSM) either by label or by slot number.
 type PKCS11Config struct {
-	TokenLabel         string                   `mapstructure:"token"`
+	TokenLabel string `mapstructure:"token"`
+	// TokenSlot selects the token by the ID of the slot containing it. It can not be combined with TokenLabel.
+	TokenSlot          *int                     `mapstructure:"slot"`
 	TokenPin           Password                 `mapstructure:"pin"`
 	ModulePath         string                   `mapstructure:"module_path"`
 	ModuleExtraOptions PKCS11ModuleExtraOptions `mapstructure:"module_extra_options"`
@@ -63,6 +212,29 @@ type AWSCryptoEngine struct {
 	Metadata     map[string]interface{} `mapstructure:"metadata"`
 }
 
+type AWSKMSCryptoEngine struct {
+	AWSCryptoEngine `mapstructure:",squash"`
+	// MultiRegion makes the engine create the CA keys as KMS multi-region keys.
+	MultiRegion bool `mapstructure:"multi_region"`
+	// ReplicaRegions lists the regions where newly created keys are replicated to.
+	ReplicaRegions []string `mapstructure:"replica_regions"`
+}
+
+// GCPKMSCryptoEngine stores the keys in a Google Cloud KMS key ring.
+type GCPKMSCryptoEngine struct {
+	ID        string                 `mapstructure:"id"`
+	Metadata  map[string]interface{} `mapstructure:"metadata"`
+	ProjectID string                 `mapstructure:"project_id"`
+	Location  string                 `mapstructure:"location"`
+	KeyRing   string                 `mapstructure:"key_ring"`
+	// ProtectionLevel of the created keys, either "hsm" or "software". Defaults to "hsm".
+	ProtectionLevel string `mapstructure:"protection_level"`
+	// CredentialsFile is the service account key file. Application Default Credentials are used if empty.
+	CredentialsFile string `mapstructure:"credentials_file"`
+	// EndpointURL overrides the KMS API endpoint.
+	EndpointURL string `mapstructure:"endpoint_url"`
+}
+
 type AWSSDKConfig struct {
 	AWSAuthenticationMethod AWSAuthenticationMethod `mapstructure:"auth_method"`
 	EndpointURL             string                  `mapstructure:"endpoint_url"`
@@ -76,3 +248,13 @@ type CryptoMonitoring struct {
 	Enabled   bool   `mapstructure:"enabled"`
 	Frequency string `mapstructure:"frequency"`
 }
+
+// DestructiveOperationsApproval requires a second administrator to approve CA deletions (which revoke the remaining
+// certificates of the CA and remove its key), CA revocations (which cascade to every certificate issued by the CA) and
+// the removal of the CA keys left in the source engine of a key migration before they are executed. The administrators are told apart by their verified OIDC identity, so it can only be
+// enabled along with the OIDC authentication of the server.
+type DestructiveOperationsApproval struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ApprovalWindow is the time a pending operation can be approved since it was requested (i.e. "1h", "30m"). Defaults to "1h".
+	ApprovalWindow string `mapstructure:"approval_window"`
+}
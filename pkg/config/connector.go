@@ -0,0 +1,96 @@
+package config
+
+// CloudConnector is the base configuration of the connectors built with the connectors SDK. Custom connectors
+// embed it (with `mapstructure:",squash"`) next to their provider specific settings.
+type CloudConnector struct {
+	Logs               BaseConfigLogging `mapstructure:"logs"`
+	SubscriberEventBus EventBusEngine    `mapstructure:"subscriber_event_bus"`
+	PublisherEventBus  EventBusEngine    `mapstructure:"publisher_event_bus"`
+
+	ConnectorID string `mapstructure:"connector_id"`
+
+	// HealthReport periodically publishes the connector health into the publisher event bus.
+	HealthReport CryptoMonitoring `mapstructure:"health_report"`
+
+	// DependencyMonitoring periodically publishes the status of the connector and its event buses. Requires the HTTP server.
+	DependencyMonitoring DependencyMonitoring `mapstructure:"dependency_monitoring"`
+
+	// Server and CertificateReconciliation are only used with assemblers.AssembleCloudConnectorWithHTTPServer.
+	Server HttpServer `mapstructure:"server"`
+	// CertificateReconciliation periodically fixes the certificate status drift between Lamassu and the provider.
+	CertificateReconciliation CryptoMonitoring `mapstructure:"certificate_reconciliation"`
+
+	// Credentials references the provider credentials in a credential store instead of embedding them into the
+	// connector configuration. Only supported by the connectors implementing connectors.CredentialedConnector.
+	Credentials ConnectorCredentials `mapstructure:"credentials"`
+
+	// RetryQueue keeps the events the connector fails to handle instead of dropping them.
+	RetryQueue ConnectorRetryQueue `mapstructure:"retry_queue"`
+}
+
+// ConnectorRetryQueue stores the failed events in Storage and retries them with exponential backoff every Frequency.
+// Events are dead lettered after MaxAttempts (defaults to 10) and can be replayed through the pending events API.
+type ConnectorRetryQueue struct {
+	CryptoMonitoring `mapstructure:",squash"`
+	Storage          PluggableStorageEngine `mapstructure:"storage"`
+	MaxAttempts      int                    `mapstructure:"max_attempts"`
+	// InitialInterval is the delay before the second attempt (defaults to 30s). It doubles on every attempt up to
+	// MaxInterval (defaults to 1h).
+	InitialInterval string `mapstructure:"initial_interval"`
+	MaxInterval     string `mapstructure:"max_interval"`
+}
+
+type ConnectorCredentials struct {
+	// Reference is the path of the credentials in the store. Credentials are not injected if empty.
+	Reference      string             `mapstructure:"reference"`
+	HashicorpVault *HashicorpVaultSDK `mapstructure:"hashicorp_vault"`
+	// Rotation periodically checks the store for a new version of the credentials and injects it into the connector.
+	Rotation CryptoMonitoring `mapstructure:"rotation"`
+}
+
+type ConnectorRegistryType string
+
+const (
+	// EventsConnectorRegistry discovers the connectors from the registration and health events they publish.
+	EventsConnectorRegistry     ConnectorRegistryType = "events"
+	StaticConnectorRegistry     ConnectorRegistryType = "static"
+	KubernetesConnectorRegistry ConnectorRegistryType = "kubernetes"
+	ConsulConnectorRegistry     ConnectorRegistryType = "consul"
+)
+
+// ConnectorRegistry configures how the services discover the deployed cloud connectors. Defaults to "events".
+type ConnectorRegistry struct {
+	Type ConnectorRegistryType `mapstructure:"type"`
+	// SubscriberEventBus receives the connector registration and health events. Only used by the "events" registry.
+	SubscriberEventBus EventBusEngine `mapstructure:"subscriber_event_bus"`
+
+	Static     []StaticConnector            `mapstructure:"static"`
+	Kubernetes KubernetesConnectorDiscovery `mapstructure:"kubernetes"`
+	Consul     ConsulConnectorDiscovery     `mapstructure:"consul"`
+}
+
+type StaticConnector struct {
+	ConnectorID string `mapstructure:"connector_id"`
+	Provider    string `mapstructure:"provider"`
+	// Address is the base URL of the connector HTTP server.
+	Address string `mapstructure:"address"`
+}
+
+// KubernetesConnectorDiscovery lists the services labeled with lamassu.io/connector-id. The connector provider is
+// read from the lamassu.io/connector-provider label.
+type KubernetesConnectorDiscovery struct {
+	// Namespace of the connector services. Defaults to the namespace of the pod.
+	Namespace string `mapstructure:"namespace"`
+	// LabelSelector narrows the listed services (i.e. "app.kubernetes.io/part-of=lamassu").
+	LabelSelector string `mapstructure:"label_selector"`
+	// APIServerURL defaults to the in-cluster API server, authenticated with the service account of the pod.
+	APIServerURL string `mapstructure:"api_server_url"`
+}
+
+// ConsulConnectorDiscovery lists the instances of the Consul catalog service the connectors are registered with.
+// The connector ID and provider are read from the connector-id and provider service metadata.
+type ConsulConnectorDiscovery struct {
+	Address     string   `mapstructure:"address"`
+	Token       Password `mapstructure:"token"`
+	ServiceName string   `mapstructure:"service_name"`
+}
@@ -9,4 +9,29 @@ type DeviceManagerConfig struct {
 	CAClient           struct {
 		HTTPClient `mapstructure:",squash"`
 	} `mapstructure:"ca_client"`
+	IssuanceReports IssuanceReports `mapstructure:"issuance_reports"`
+	// DestructiveOperationsApproval requires a second administrator to approve the REVOKE bulk actions of the device
+	// groups. It can only be enabled along with the OIDC authentication of the server.
+	DestructiveOperationsApproval DestructiveOperationsApproval `mapstructure:"destructive_operations_approval"`
+	// SecretSlotRotation schedules the rotation of the PSK and SAS token slots about to expire.
+	SecretSlotRotation CryptoMonitoring `mapstructure:"secret_slot_rotation"`
+	// DeviceLogRetention schedules the pruning of the device log entries older than the retention period.
+	DeviceLogRetention   DeviceLogRetention   `mapstructure:"device_log_retention"`
+	DependencyMonitoring DependencyMonitoring `mapstructure:"dependency_monitoring"`
+	FaultInjection       FaultInjection       `mapstructure:"fault_injection"`
+	DebugTrace           DebugTrace           `mapstructure:"debug_trace"`
+}
+
+type DeviceLogRetention struct {
+	CryptoMonitoring `mapstructure:",squash"`
+	// Retention is the age of the oldest log entry kept (i.e. "30d", "1w"). Defaults to "30d"
+	Retention string `mapstructure:"retention"`
+}
+
+// IssuanceReports schedules the generation of the certificate issuance report. Reports are published
+// to the event bus, so the alerts service can deliver them to the subscribed users.
+type IssuanceReports struct {
+	CryptoMonitoring `mapstructure:",squash"`
+	// Period covered by each report (i.e. "1d", "1w"), at most a year. Defaults to "1d"
+	Period string `mapstructure:"period"`
 }
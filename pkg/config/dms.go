@@ -15,4 +15,63 @@ type DMSconfig struct {
 	} `mapstructure:"device_manager_client"`
 
 	DownstreamCertificateFile string `mapstructure:"downstream_cert_file"`
+
+	SupersededRevocationMonitoring CryptoMonitoring `mapstructure:"superseded_revocation_monitoring"`
+	CASuccession                   CASuccession     `mapstructure:"ca_succession"`
+	// CARotation processes a batch of every running CA rotation campaign on each run.
+	CARotation CryptoMonitoring `mapstructure:"ca_rotation"`
+
+	ACMEExternalAccountBinding ACMEExternalAccountBinding `mapstructure:"acme_external_account_binding"`
+	ACMEServer                 ACMEServer                 `mapstructure:"acme_server"`
+
+	ServerKeyGen ServerKeyGen `mapstructure:"server_keygen"`
+	CSRLimits    CSRLimits    `mapstructure:"csr_limits"`
+
+	// RegistrationApproval holds the DMSs registered with a CSR in PENDING_APPROVAL until an administrator activates
+	// them, unless an enrollment policy auto-approves the registration.
+	RegistrationApproval DMSRegistrationApproval `mapstructure:"registration_approval"`
+
+	DependencyMonitoring DependencyMonitoring `mapstructure:"dependency_monitoring"`
+
+	FeatureFlags   FeatureFlags   `mapstructure:"feature_flags"`
+	FaultInjection FaultInjection `mapstructure:"fault_injection"`
+	DebugTrace     DebugTrace     `mapstructure:"debug_trace"`
+}
+
+// CASuccession periodically replaces the CAs about to expire with a cross signed successor, notifies the DMSs
+// enrolling with them and pauses their issuance once they expire.
+type CASuccession struct {
+	CryptoMonitoring `mapstructure:",squash"`
+	// RenewBeforeDays is the number of days before the CA expiration the successor is created. Defaults to 30.
+	RenewBeforeDays int `mapstructure:"renew_before_days"`
+}
+
+type DMSRegistrationApproval struct {
+	Enabled bool `mapstructure:"enabled"`
+}
+
+// ServerKeyGen configures the EST server-side key generation (RFC 7030 4.4).
+type ServerKeyGen struct {
+	// CryptoEngine stores the generated keys. Keys are returned to the devices, so only engines exporting the keys
+	// are supported. Keys are only kept in memory if no engine is configured.
+	CryptoEngine ServerKeyGenCryptoEngine `mapstructure:"crypto_engine"`
+}
+
+type ServerKeyGenCryptoEngine struct {
+	Golang         *GolangEngineConfig               `mapstructure:"golang"`
+	HashicorpVault *HashicorpVaultCryptoEngineConfig `mapstructure:"hashicorp_vault"`
+}
+
+type ACMEExternalAccountBinding struct {
+	// HMACSecret is used to derive the HMAC key of each EAB key. Rotating it invalidates all the issued EAB keys.
+	HMACSecret Password `mapstructure:"hmac_secret"`
+}
+
+type ACMEServer struct {
+	Enabled bool `mapstructure:"enabled"`
+	// ExternalURL is the URL the DMS Manager is reachable at by the ACME clients, used to build the ACME resource
+	// URLs. If empty, the URLs are built from the request host.
+	ExternalURL string `mapstructure:"external_url"`
+	// HTTP01Port is the port the http-01 challenges are fetched from. Defaults to 80.
+	HTTP01Port int `mapstructure:"http01_port"`
 }
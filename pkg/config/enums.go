@@ -16,6 +16,16 @@ const (
 	HTTP  HTTPProtocol = "http"
 )
 
+// ServerAPI groups the endpoints of a service, so each listener can serve a subset of them.
+type ServerAPI string
+
+const (
+	ESTAPI  ServerAPI = "est"
+	ACMEAPI ServerAPI = "acme"
+	// ManagementAPI includes all the endpoints not included in the other APIs.
+	ManagementAPI ServerAPI = "management"
+)
+
 type HTTPClientAuthMethod string
 
 const (
@@ -45,6 +55,11 @@ const (
 	CouchDB  StorageProvider = "couch_db"
 	DynamoDB StorageProvider = "dynamo_db"
 	SQLite   StorageProvider = "sqlite"
+	// Memory keeps all the data in memory. It is meant for development and tests only.
+	Memory StorageProvider = "memory"
+	// DualWrite reads from a source storage engine and writes into both the source and a target storage engine,
+	// so services keep running while their records are moved to another backend.
+	DualWrite StorageProvider = "dual_write"
 )
 
 type AWSAuthenticationMethod string
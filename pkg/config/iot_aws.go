@@ -19,6 +19,17 @@ type IotAWS struct {
 	ConnectorID               string       `mapstructure:"connector_id"`
 	AWSSDKConfig              AWSSDKConfig `mapstructure:"aws_config"`
 	AWSBidirectionalQueueName string       `mapstructure:"aws_bidirectional_queue_name"`
+
+	// ExpiryTwinNotification periodically flags in the device shadow the identity certificates
+	// expiring within NotificationDays.
+	ExpiryTwinNotification struct {
+		CryptoMonitoring `mapstructure:",squash"`
+		NotificationDays int `mapstructure:"notification_days"`
+	} `mapstructure:"expiry_twin_notification"`
+
+	// CertificateReconciliation periodically compares the AWS IoT certificate statuses with the Lamassu ones
+	// and fixes the drift.
+	CertificateReconciliation CryptoMonitoring `mapstructure:"certificate_reconciliation"`
 }
 
 var IotAWSDefaults = IotAWS{
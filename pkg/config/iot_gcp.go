@@ -0,0 +1,25 @@
+package config
+
+// IotGCP is the configuration of the GCP Pub/Sub connector. The CA certificates and the device certificate
+// status changes are published into Pub/Sub topics, as Cloud IoT Core device registries are no longer available.
+type IotGCP struct {
+	CloudConnector `mapstructure:",squash"`
+
+	CAClient struct {
+		HTTPClient `mapstructure:",squash"`
+	} `mapstructure:"ca_client"`
+
+	GCPPubSub GCPPubSubConfig `mapstructure:"gcp_pubsub"`
+}
+
+type GCPPubSubConfig struct {
+	ProjectID string `mapstructure:"project_id"`
+	// CATopic receives the registered CA certificates and their status changes.
+	CATopic string `mapstructure:"ca_topic"`
+	// DeviceTopic receives the device identity certificates and their status changes.
+	DeviceTopic string `mapstructure:"device_topic"`
+	// CredentialsFile is the service account key file. Application Default Credentials are used if empty.
+	CredentialsFile string `mapstructure:"credentials_file"`
+	// EndpointURL overrides the Pub/Sub API endpoint.
+	EndpointURL string `mapstructure:"endpoint_url"`
+}
@@ -0,0 +1,34 @@
+package config
+
+type KubernetesOperator struct {
+	Logs BaseConfigLogging `mapstructure:"logs"`
+
+	CAClient struct {
+		HTTPClient `mapstructure:",squash"`
+	} `mapstructure:"ca_client"`
+
+	DMSManagerClient struct {
+		HTTPClient `mapstructure:",squash"`
+	} `mapstructure:"dms_manager_client"`
+
+	Kubernetes KubernetesAPI `mapstructure:"kubernetes"`
+
+	// Reconciliation periodically reconciles the CertificateAuthority and DMS custom resources against the Lamassu APIs.
+	Reconciliation CryptoMonitoring `mapstructure:"reconciliation"`
+}
+
+// KubernetesAPI connects to the Kubernetes API server. The in-cluster service account is used if APIServer is empty.
+type KubernetesAPI struct {
+	APIServer  string `mapstructure:"api_server"`
+	TokenFile  string `mapstructure:"token_file"`
+	CACertFile string `mapstructure:"ca_cert_file"`
+	// Namespace watched by the operator. All namespaces are watched if empty.
+	Namespace string `mapstructure:"namespace"`
+}
+
+var KubernetesOperatorDefaults = KubernetesOperator{
+	Reconciliation: CryptoMonitoring{
+		Enabled:   true,
+		Frequency: "* * * * *",
+	},
+}
@@ -0,0 +1,9 @@
+package config
+
+// StorageMigrationConfig configures the migration of the CA, DMS Manager and Device Manager records (CAs, DMSs,
+// devices with their slots and the certificate history) from a source storage engine into the target storage engine.
+type StorageMigrationConfig struct {
+	Logs   BaseConfigLogging      `mapstructure:"logs"`
+	Source PluggableStorageEngine `mapstructure:"source"`
+	Target PluggableStorageEngine `mapstructure:"target"`
+}
@@ -23,6 +23,13 @@ type MonolithicConfig struct {
 	AWSIoTManager      MonolithicAWSIoTManagerConfig `mapstructure:"aws_iot_manager"`
 }
 
+// MonolithicDefaults runs the services in memory behind a gateway listening on port 8443.
+var MonolithicDefaults = MonolithicConfig{
+	Domain:       "localhost",
+	AssemblyMode: InMemory,
+	GatewayPort:  8443,
+}
+
 type MonolithicAWSIoTManagerConfig struct {
 	Enabled      bool         `mapstructure:"enabled"`
 	ConnectorID  string       `mapstructure:"connector_id"`
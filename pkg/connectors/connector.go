@@ -0,0 +1,119 @@
+// Package connectors is the SDK to build cloud provider connectors. A connector implements the Connector
+// operations and is plugged into the Lamassu event bus with assemblers.AssembleCloudConnector, which
+// dispatches the CA, DMS, device and certificate events to the operations, announces the connector and
+// reports its health.
+package connectors
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// Connector is implemented by the cloud provider connectors. CAs, DMSs and devices opt in to a connector by
+// setting its configuration under the models.CloudConnectorMetadataKey(ID()) metadata key, which can be
+// decoded with helpers.GetMetadataToStruct. Events of entities without the key are not dispatched.
+type Connector interface {
+	ID() string
+	Provider() string
+	Health(ctx context.Context) models.ConnectorHealth
+
+	// RegisterCA is called when a CA is created, imported or its metadata is updated.
+	RegisterCA(ctx context.Context, input RegisterCAInput) error
+	UpdateCAStatus(ctx context.Context, input UpdateCAStatusInput) error
+	// RegisterDMS is called when a DMS is created or updated. Previous is only set on updates.
+	RegisterDMS(ctx context.Context, input RegisterDMSInput) error
+	// BindDeviceIdentity is called when a certificate is bound to a device enrolled with a DMS using the connector.
+	BindDeviceIdentity(ctx context.Context, input BindDeviceIdentityInput) error
+	UpdateDeviceMetadata(ctx context.Context, input UpdateDeviceMetadataInput) error
+	// UpdateCertificateStatus is called for the status changes of the certificates attached to a device.
+	UpdateCertificateStatus(ctx context.Context, input UpdateCertificateStatusInput) error
+}
+
+type RegisterCAInput struct {
+	CA models.CACertificate
+}
+
+type UpdateCAStatusInput struct {
+	CA             models.CACertificate
+	PreviousStatus models.CertificateStatus
+}
+
+type RegisterDMSInput struct {
+	DMS      models.DMS
+	Previous *models.DMS
+}
+
+type BindDeviceIdentityInput struct {
+	BindedIdentity models.BindIdentityToDeviceOutput
+}
+
+type UpdateDeviceMetadataInput struct {
+	Device   models.Device
+	Previous models.Device
+}
+
+type UpdateCertificateStatusInput struct {
+	Certificate    models.Certificate
+	PreviousStatus models.CertificateStatus
+}
+
+// SubscribedConnector is implemented by the connectors that only need a subset of the events. Connectors not
+// implementing it receive every event type dispatched by NewEventHandler.
+type SubscribedConnector interface {
+	Connector
+	Subscription() EventSubscription
+}
+
+// EventSubscription declares the events a connector receives. The event types are bound to the connector queue, so
+// the event bus does not deliver the rest of the events.
+type EventSubscription struct {
+	// EventTypes to receive. Empty receives every event type dispatched by NewEventHandler.
+	EventTypes []models.EventType
+	// CAIDs restricts the CA, DMS (by enrollment CA), device identity and certificate events to the given CAs.
+	// Empty accepts every CA.
+	CAIDs []string
+}
+
+// BaseConnector implements every operation as a no-op and always reports a healthy status.
+// Connectors embed it and only override the operations they support.
+type BaseConnector struct {
+	ConnectorID  string
+	ProviderName string
+}
+
+func (c BaseConnector) ID() string {
+	return c.ConnectorID
+}
+
+func (c BaseConnector) Provider() string {
+	return c.ProviderName
+}
+
+func (c BaseConnector) Health(ctx context.Context) models.ConnectorHealth {
+	return models.ConnectorHealth{Status: models.ConnectorHealthy}
+}
+
+func (c BaseConnector) RegisterCA(ctx context.Context, input RegisterCAInput) error {
+	return nil
+}
+
+func (c BaseConnector) UpdateCAStatus(ctx context.Context, input UpdateCAStatusInput) error {
+	return nil
+}
+
+func (c BaseConnector) RegisterDMS(ctx context.Context, input RegisterDMSInput) error {
+	return nil
+}
+
+func (c BaseConnector) BindDeviceIdentity(ctx context.Context, input BindDeviceIdentityInput) error {
+	return nil
+}
+
+func (c BaseConnector) UpdateDeviceMetadata(ctx context.Context, input UpdateDeviceMetadataInput) error {
+	return nil
+}
+
+func (c BaseConnector) UpdateCertificateStatus(ctx context.Context, input UpdateCertificateStatusInput) error {
+	return nil
+}
@@ -0,0 +1,173 @@
+package connectors
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/ThreeDotsLabs/watermill/message"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testConnector struct {
+	BaseConnector
+	mock.Mock
+}
+
+func (c *testConnector) RegisterCA(ctx context.Context, input RegisterCAInput) error {
+	args := c.Called(input)
+	return args.Error(0)
+}
+
+func (c *testConnector) RegisterDMS(ctx context.Context, input RegisterDMSInput) error {
+	args := c.Called(input)
+	return args.Error(0)
+}
+
+type mockPublisher struct {
+	mock.Mock
+}
+
+func (m *mockPublisher) PublishCloudEvent(ctx context.Context, eventType models.EventType, payload interface{}) {
+	m.Called(ctx, eventType, payload)
+}
+
+func eventMessage(t *testing.T, eventType models.EventType, source string, payload any) *message.Message {
+	event := helpers.BuildCloudEvent(string(eventType), source, payload)
+	eventBytes, err := json.Marshal(event)
+	if err != nil {
+		t.Fatalf("could not marshal event: %s", err)
+	}
+
+	return message.NewMessage(event.ID(), eventBytes)
+}
+
+func TestEventHandlerDispatchesConfiguredEntities(t *testing.T) {
+	connector := &testConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	handler := NewEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector)
+
+	ca := models.CACertificate{
+		ID:       "ca-1",
+		Metadata: map[string]any{models.CloudConnectorMetadataKey("my-cloud"): map[string]any{"register": true}},
+	}
+	ca.KeyMetadata = models.KeyStrengthMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256}
+	skipped := models.CACertificate{ID: "ca-2"}
+	skipped.KeyMetadata = ca.KeyMetadata
+
+	connector.On("RegisterCA", mock.MatchedBy(func(input RegisterCAInput) bool { return input.CA.ID == "ca-1" })).Return(nil)
+
+	err := handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, ca))
+	assert.NoError(t, err)
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+
+	// CAs without the connector key are skipped
+	err = handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, skipped))
+	assert.NoError(t, err)
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+
+	// Events produced by the connector itself are dropped
+	err = handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CloudConnectorSource("my-cloud"), ca))
+	assert.NoError(t, err)
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+}
+
+func TestEventHandlerDMSUpdate(t *testing.T) {
+	connector := &testConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	handler := NewEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector)
+
+	metadata := map[string]any{models.CloudConnectorMetadataKey("my-cloud"): map[string]any{}}
+	update := models.UpdateModel[models.DMS]{
+		Previous: models.DMS{ID: "dms-1", Name: "old", Metadata: metadata},
+		Updated:  models.DMS{ID: "dms-1", Name: "new", Metadata: metadata},
+	}
+
+	connector.On("RegisterDMS", mock.MatchedBy(func(input RegisterDMSInput) bool {
+		return input.DMS.Name == "new" && input.Previous != nil && input.Previous.Name == "old"
+	})).Return(errors.New("provider unavailable"))
+
+	err := handler.HandleEvent(eventMessage(t, models.EventUpdateDMSKey, models.DMSManagerSource, update))
+	assert.Error(t, err)
+	connector.AssertExpectations(t)
+}
+
+type subscribedTestConnector struct {
+	testConnector
+	subscription EventSubscription
+}
+
+func (c *subscribedTestConnector) Subscription() EventSubscription {
+	return c.subscription
+}
+
+func TestSubscribedEventTypes(t *testing.T) {
+	connector := &testConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	assert.Len(t, SubscribedEventTypes(connector), len(eventHandlers))
+
+	subscribed := &subscribedTestConnector{
+		testConnector: testConnector{BaseConnector: connector.BaseConnector},
+		subscription: EventSubscription{EventTypes: []models.EventType{
+			models.EventUpdateDMSKey,
+			models.EventCreateCAKey,
+			models.EventCreateCAKey,
+			models.EventType("unsupported.event"),
+		}},
+	}
+	assert.Equal(t, []models.EventType{models.EventCreateCAKey, models.EventUpdateDMSKey}, SubscribedEventTypes(subscribed))
+}
+
+func TestEventHandlerSubscription(t *testing.T) {
+	connector := &subscribedTestConnector{
+		testConnector: testConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}},
+		subscription: EventSubscription{
+			EventTypes: []models.EventType{models.EventCreateCAKey},
+			CAIDs:      []string{"ca-1"},
+		},
+	}
+	handler := NewEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector)
+
+	metadata := map[string]any{models.CloudConnectorMetadataKey("my-cloud"): map[string]any{}}
+	ca := models.CACertificate{ID: "ca-1", Metadata: metadata}
+	ca.KeyMetadata = models.KeyStrengthMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256}
+	otherCA := models.CACertificate{ID: "ca-2", Metadata: metadata}
+	otherCA.KeyMetadata = ca.KeyMetadata
+
+	connector.On("RegisterCA", mock.MatchedBy(func(input RegisterCAInput) bool { return input.CA.ID == "ca-1" })).Return(nil)
+
+	err := handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, ca))
+	assert.NoError(t, err)
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+
+	// CAs not included in the subscription are skipped
+	err = handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, otherCA))
+	assert.NoError(t, err)
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+
+	// Event types not included in the subscription are not dispatched
+	err = handler.HandleEvent(eventMessage(t, models.EventImportCAKey, models.CASource, ca))
+	assert.NoError(t, err)
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+}
+
+func TestHealthReporter(t *testing.T) {
+	connector := &testConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	publisher := new(mockPublisher)
+	reporter := NewHealthReporter(connector, publisher, logrus.NewEntry(logrus.StandardLogger()))
+
+	publisher.On("PublishCloudEvent", mock.Anything, models.EventConnectorRegisterKey, mock.MatchedBy(func(reg models.ConnectorRegistration) bool {
+		return reg.ConnectorID == "my-cloud" && reg.Provider == "test" && reg.Version == "v1"
+	})).Return()
+	publisher.On("PublishCloudEvent", mock.Anything, models.EventConnectorHealthKey, mock.MatchedBy(func(report models.ConnectorHealthReport) bool {
+		return report.ConnectorID == "my-cloud" && report.Health.Status == models.ConnectorHealthy
+	})).Return()
+
+	reporter.Register(context.Background(), "v1")
+	reporter.Run()
+
+	publisher.AssertExpectations(t)
+}
@@ -0,0 +1,83 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// CredentialStore holds the provider credentials of the connectors, referenced by their path in the store.
+type CredentialStore interface {
+	GetCredentials(ctx context.Context, reference string) (*models.ConnectorCredentials, error)
+}
+
+// CredentialedConnector is implemented by the connectors reading their provider credentials from a CredentialStore
+// instead of their own configuration. SetCredentials is called before the connector receives any event and
+// again every time the credentials are rotated.
+type CredentialedConnector interface {
+	Connector
+	SetCredentials(ctx context.Context, credentials models.ConnectorCredentials) error
+}
+
+// CredentialsInjector injects the referenced credentials into the connector. It implements cron.Job so it can be
+// scheduled with jobs.NewJobScheduler to inject the rotated credentials.
+type CredentialsInjector struct {
+	connector CredentialedConnector
+	store     CredentialStore
+	reference string
+	logger    *logrus.Entry
+
+	mu      sync.Mutex
+	version int
+}
+
+func NewCredentialsInjector(connector CredentialedConnector, store CredentialStore, reference string, logger *logrus.Entry) *CredentialsInjector {
+	return &CredentialsInjector{
+		connector: connector,
+		store:     store,
+		reference: reference,
+		logger:    logger,
+		version:   -1,
+	}
+}
+
+// Inject reads the credentials from the store and injects them into the connector if their version changed
+// since the last injection.
+func (i *CredentialsInjector) Inject(ctx context.Context) error {
+	lFunc := helpers.ConfigureLogger(ctx, i.logger)
+
+	i.mu.Lock()
+	defer i.mu.Unlock()
+
+	credentials, err := i.store.GetCredentials(ctx, i.reference)
+	if err != nil {
+		return fmt.Errorf("could not read credentials %s: %w", i.reference, err)
+	}
+
+	if credentials.Version == i.version {
+		lFunc.Debugf("credentials %s of connector %s did not change", i.reference, i.connector.ID())
+		return nil
+	}
+
+	err = i.connector.SetCredentials(ctx, *credentials)
+	if err != nil {
+		return fmt.Errorf("could not inject credentials %s version %d: %w", i.reference, credentials.Version, err)
+	}
+
+	lFunc.Infof("injected credentials %s version %d into connector %s", i.reference, credentials.Version, i.connector.ID())
+	i.version = credentials.Version
+	return nil
+}
+
+// Run injects the rotated credentials. The connector keeps the previous credentials if the injection fails.
+func (i *CredentialsInjector) Run() {
+	ctx := helpers.InitContext()
+	err := i.Inject(ctx)
+	if err != nil {
+		helpers.ConfigureLogger(ctx, i.logger).Errorf("could not rotate credentials of connector %s: %s", i.connector.ID(), err)
+	}
+}
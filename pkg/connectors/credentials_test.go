@@ -0,0 +1,84 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+type credentialedTestConnector struct {
+	BaseConnector
+	injected []models.ConnectorCredentials
+	err      error
+}
+
+func (c *credentialedTestConnector) SetCredentials(ctx context.Context, credentials models.ConnectorCredentials) error {
+	if c.err != nil {
+		return c.err
+	}
+
+	c.injected = append(c.injected, credentials)
+	return nil
+}
+
+type memoryCredentialStore struct {
+	credentials map[string]models.ConnectorCredentials
+}
+
+func (s *memoryCredentialStore) GetCredentials(ctx context.Context, reference string) (*models.ConnectorCredentials, error) {
+	credentials, ok := s.credentials[reference]
+	if !ok {
+		return nil, errors.New("credentials not found")
+	}
+
+	return &credentials, nil
+}
+
+func TestCredentialsInjector(t *testing.T) {
+	connector := &credentialedTestConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	store := &memoryCredentialStore{credentials: map[string]models.ConnectorCredentials{
+		"connectors/my-cloud": {Version: 1, Values: map[string]string{"secret": "v1"}},
+	}}
+
+	injector := NewCredentialsInjector(connector, store, "connectors/my-cloud", logrus.NewEntry(logrus.StandardLogger()))
+
+	err := injector.Inject(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, connector.injected, 1)
+	assert.Equal(t, "v1", connector.injected[0].Values["secret"])
+
+	// the same version is not injected again
+	injector.Run()
+	assert.Len(t, connector.injected, 1)
+
+	// rotated credentials are injected
+	store.credentials["connectors/my-cloud"] = models.ConnectorCredentials{Version: 2, Values: map[string]string{"secret": "v2"}}
+	injector.Run()
+	assert.Len(t, connector.injected, 2)
+	assert.Equal(t, "v2", connector.injected[1].Values["secret"])
+
+	// a failed injection is retried on the next run
+	store.credentials["connectors/my-cloud"] = models.ConnectorCredentials{Version: 3, Values: map[string]string{"secret": "v3"}}
+	connector.err = errors.New("invalid credentials")
+	injector.Run()
+	assert.Len(t, connector.injected, 2)
+
+	connector.err = nil
+	injector.Run()
+	assert.Len(t, connector.injected, 3)
+	assert.Equal(t, 3, connector.injected[2].Version)
+}
+
+func TestCredentialsInjectorUnknownReference(t *testing.T) {
+	connector := &credentialedTestConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	store := &memoryCredentialStore{credentials: map[string]models.ConnectorCredentials{}}
+
+	injector := NewCredentialsInjector(connector, store, "connectors/unknown", logrus.NewEntry(logrus.StandardLogger()))
+	err := injector.Inject(context.Background())
+	assert.Error(t, err)
+	assert.Empty(t, connector.injected)
+}
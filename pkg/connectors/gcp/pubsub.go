@@ -0,0 +1,257 @@
+// Package gcp is the Google Cloud connector built with the connectors SDK. Cloud IoT Core device registries are
+// no longer available, so the CA certificates and the device certificate status changes are published into
+// Pub/Sub topics, where the GCP workloads consume them.
+package gcp
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+	"google.golang.org/api/pubsub/v1"
+)
+
+// Message attributes set in every published message, so subscriptions can filter on them.
+const (
+	AttributeType      = "lamassu_type"
+	AttributeConnector = "lamassu_connector"
+	AttributeRegistry  = "lamassu_registry"
+)
+
+// pubSubClient is the subset of the Pub/Sub API used by the connector.
+type pubSubClient interface {
+	Publish(ctx context.Context, topic string, message *pubsub.PubsubMessage) error
+	GetTopic(ctx context.Context, topic string) error
+}
+
+type restPubSubClient struct {
+	svc *pubsub.Service
+}
+
+func (c *restPubSubClient) Publish(ctx context.Context, topic string, message *pubsub.PubsubMessage) error {
+	_, err := c.svc.Projects.Topics.Publish(topic, &pubsub.PublishRequest{
+		Messages: []*pubsub.PubsubMessage{message},
+	}).Context(ctx).Do()
+	return err
+}
+
+func (c *restPubSubClient) GetTopic(ctx context.Context, topic string) error {
+	_, err := c.svc.Projects.Topics.Get(topic).Context(ctx).Do()
+	return err
+}
+
+// PubSubConnector publishes the CAs configured with the connector metadata key into the CA topic and the
+// identity certificates issued by them into the device topic.
+type PubSubConnector struct {
+	connectors.BaseConnector
+	client      pubSubClient
+	caSDK       services.CAService
+	caTopic     string
+	deviceTopic string
+	logger      *logrus.Entry
+}
+
+// NewPubSubConnector creates the connector. caSDK resolves the registry of the issuer CA of the device certificates.
+func NewPubSubConnector(logger *logrus.Entry, connectorID string, conf config.GCPPubSubConfig, caSDK services.CAService) (*PubSubConnector, error) {
+	opts := []option.ClientOption{}
+	if conf.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(conf.CredentialsFile))
+	}
+
+	if conf.EndpointURL != "" {
+		opts = append(opts, option.WithEndpoint(conf.EndpointURL))
+	}
+
+	svc, err := pubsub.NewService(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create Pub/Sub client: %s", err)
+	}
+
+	return newPubSubConnector(logger, connectorID, conf, &restPubSubClient{svc: svc}, caSDK)
+}
+
+func newPubSubConnector(logger *logrus.Entry, connectorID string, conf config.GCPPubSubConfig, client pubSubClient, caSDK services.CAService) (*PubSubConnector, error) {
+	if conf.ProjectID == "" || conf.CATopic == "" || conf.DeviceTopic == "" {
+		return nil, fmt.Errorf("project ID, CA topic and device topic are required")
+	}
+
+	return &PubSubConnector{
+		BaseConnector: connectors.BaseConnector{
+			ConnectorID:  connectorID,
+			ProviderName: models.GCPPubSubProvider,
+		},
+		client:      client,
+		caSDK:       caSDK,
+		caTopic:     fmt.Sprintf("projects/%s/topics/%s", conf.ProjectID, conf.CATopic),
+		deviceTopic: fmt.Sprintf("projects/%s/topics/%s", conf.ProjectID, conf.DeviceTopic),
+		logger:      logger,
+	}, nil
+}
+
+func (c *PubSubConnector) Subscription() connectors.EventSubscription {
+	return connectors.EventSubscription{
+		EventTypes: []models.EventType{
+			models.EventCreateCAKey,
+			models.EventImportCAKey,
+			models.EventUpdateCAMetadataKey,
+			models.EventUpdateCAStatusKey,
+			models.EventBindDeviceIdentityKey,
+			models.EventUpdateCertificateStatusKey,
+		},
+	}
+}
+
+// Health checks that both topics exist and are reachable with the connector credentials.
+func (c *PubSubConnector) Health(ctx context.Context) models.ConnectorHealth {
+	for _, topic := range []string{c.caTopic, c.deviceTopic} {
+		err := c.client.GetTopic(ctx, topic)
+		if err != nil {
+			return models.ConnectorHealth{
+				Status:  models.ConnectorUnhealthy,
+				Message: fmt.Sprintf("could not get topic %s: %s", topic, err),
+			}
+		}
+	}
+
+	return models.ConnectorHealth{Status: models.ConnectorHealthy}
+}
+
+func (c *PubSubConnector) RegisterCA(ctx context.Context, input connectors.RegisterCAInput) error {
+	return c.publishCA(ctx, models.GCPPubSubCARegistered, input.CA, "")
+}
+
+func (c *PubSubConnector) UpdateCAStatus(ctx context.Context, input connectors.UpdateCAStatusInput) error {
+	return c.publishCA(ctx, models.GCPPubSubCAStatusUpdated, input.CA, input.PreviousStatus)
+}
+
+func (c *PubSubConnector) BindDeviceIdentity(ctx context.Context, input connectors.BindDeviceIdentityInput) error {
+	bind := input.BindedIdentity
+	if bind.Certificate == nil {
+		return fmt.Errorf("bind event of device %s does not include the certificate", bind.Device.ID)
+	}
+
+	registry, _, err := c.issuerRegistry(ctx, bind.Certificate.IssuerCAMetadata.ID)
+	if err != nil {
+		return err
+	}
+
+	msg := models.GCPPubSubDeviceCertificateMessage{
+		Registry:     registry,
+		DeviceID:     bind.Device.ID,
+		DMSID:        bind.DMS.ID,
+		IssuerCAID:   bind.Certificate.IssuerCAMetadata.ID,
+		SerialNumber: bind.Certificate.SerialNumber,
+		Status:       bind.Certificate.Status,
+	}
+	if bind.Certificate.Certificate != nil {
+		msg.Certificate = helpers.CertificateToPEM((*x509.Certificate)(bind.Certificate.Certificate))
+	}
+
+	return c.publish(ctx, c.deviceTopic, models.GCPPubSubDeviceCertificateBound, registry, msg)
+}
+
+// UpdateCertificateStatus forwards the status changes of the device certificates issued by CAs synchronized
+// with the connector. Certificates issued by other CAs are skipped.
+func (c *PubSubConnector) UpdateCertificateStatus(ctx context.Context, input connectors.UpdateCertificateStatusInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, c.logger)
+
+	caID := input.Certificate.IssuerCAMetadata.ID
+	registry, synced, err := c.issuerRegistry(ctx, caID)
+	if err != nil {
+		return err
+	}
+
+	if !synced {
+		lFunc.Debugf("skipping certificate %s, issuer CA %s is not synchronized with connector %s", input.Certificate.SerialNumber, caID, c.ID())
+		return nil
+	}
+
+	deviceID, _ := input.Certificate.Metadata[models.CAAttachedToDeviceKey].(string)
+	return c.publish(ctx, c.deviceTopic, models.GCPPubSubDeviceCertificateStatusUpdated, registry, models.GCPPubSubDeviceCertificateMessage{
+		Registry:       registry,
+		DeviceID:       deviceID,
+		IssuerCAID:     caID,
+		SerialNumber:   input.Certificate.SerialNumber,
+		Status:         input.Certificate.Status,
+		PreviousStatus: input.PreviousStatus,
+	})
+}
+
+func (c *PubSubConnector) publishCA(ctx context.Context, msgType models.GCPPubSubMessageType, ca models.CACertificate, previous models.CertificateStatus) error {
+	registry, _, err := c.registry(ca)
+	if err != nil {
+		return err
+	}
+
+	msg := models.GCPPubSubCAMessage{
+		Registry:       registry,
+		CAID:           ca.ID,
+		SerialNumber:   ca.SerialNumber,
+		Status:         ca.Status,
+		PreviousStatus: previous,
+	}
+	if ca.Certificate.Certificate != nil {
+		msg.Certificate = helpers.CertificateToPEM((*x509.Certificate)(ca.Certificate.Certificate))
+	}
+
+	return c.publish(ctx, c.caTopic, msgType, registry, msg)
+}
+
+// issuerRegistry fetches the issuer CA and returns its registry as registry does.
+func (c *PubSubConnector) issuerRegistry(ctx context.Context, caID string) (string, bool, error) {
+	ca, err := c.caSDK.GetCAByID(ctx, services.GetCAByIDInput{CAID: caID})
+	if err != nil {
+		return "", false, fmt.Errorf("could not get issuer CA %s: %s", caID, err)
+	}
+
+	return c.registry(*ca)
+}
+
+// registry returns the registry configured in the CA metadata, defaulting to the CA ID. The returned bool
+// reports whether the CA is synchronized with the connector.
+func (c *PubSubConnector) registry(ca models.CACertificate) (string, bool, error) {
+	var caMeta models.IoTGCPCAMetadata
+	hasKey, err := helpers.GetMetadataToStruct(ca.Metadata, models.CloudConnectorMetadataKey(c.ID()), &caMeta)
+	if err != nil {
+		return "", hasKey, fmt.Errorf("could not decode CA %s connector metadata: %s", ca.ID, err)
+	}
+
+	if caMeta.Registry == "" {
+		return ca.ID, hasKey, nil
+	}
+
+	return caMeta.Registry, hasKey, nil
+}
+
+func (c *PubSubConnector) publish(ctx context.Context, topic string, msgType models.GCPPubSubMessageType, registry string, payload any) error {
+	lFunc := helpers.ConfigureLogger(ctx, c.logger)
+
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("could not encode %s message: %s", msgType, err)
+	}
+
+	err = c.client.Publish(ctx, topic, &pubsub.PubsubMessage{
+		Data: base64.StdEncoding.EncodeToString(data),
+		Attributes: map[string]string{
+			AttributeType:      string(msgType),
+			AttributeConnector: c.ID(),
+			AttributeRegistry:  registry,
+		},
+	})
+	if err != nil {
+		return fmt.Errorf("could not publish %s message into %s: %s", msgType, topic, err)
+	}
+
+	lFunc.Debugf("published %s message into %s", msgType, topic)
+	return nil
+}
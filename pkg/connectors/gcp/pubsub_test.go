@@ -0,0 +1,116 @@
+package gcp
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"google.golang.org/api/pubsub/v1"
+)
+
+type publishedMessage struct {
+	topic   string
+	message *pubsub.PubsubMessage
+}
+
+type fakePubSubClient struct {
+	published []publishedMessage
+}
+
+func (c *fakePubSubClient) Publish(ctx context.Context, topic string, message *pubsub.PubsubMessage) error {
+	c.published = append(c.published, publishedMessage{topic: topic, message: message})
+	return nil
+}
+
+func (c *fakePubSubClient) GetTopic(ctx context.Context, topic string) error {
+	return nil
+}
+
+func decodeMessage[E any](t *testing.T, msg *pubsub.PubsubMessage) E {
+	var payload E
+	data, err := base64.StdEncoding.DecodeString(msg.Data)
+	assert.NoError(t, err)
+	assert.NoError(t, json.Unmarshal(data, &payload))
+	return payload
+}
+
+func newTestConnector(t *testing.T) (*PubSubConnector, *fakePubSubClient, *svcmock.MockCAService) {
+	client := &fakePubSubClient{}
+	caSDK := &svcmock.MockCAService{}
+	connector, err := newPubSubConnector(logrus.NewEntry(logrus.StandardLogger()), "gcp", config.GCPPubSubConfig{
+		ProjectID:   "lamassu",
+		CATopic:     "cas",
+		DeviceTopic: "devices",
+	}, client, caSDK)
+	assert.NoError(t, err)
+
+	return connector, client, caSDK
+}
+
+func TestPubSubConnectorRegisterCA(t *testing.T) {
+	connector, client, _ := newTestConnector(t)
+
+	ca := models.CACertificate{
+		ID:       "ca-1",
+		Metadata: map[string]any{models.CloudConnectorMetadataKey("gcp"): map[string]any{"registry": "factory"}},
+	}
+	ca.SerialNumber = "01"
+	ca.Status = models.StatusActive
+
+	err := connector.RegisterCA(context.Background(), connectors.RegisterCAInput{CA: ca})
+	assert.NoError(t, err)
+
+	assert.Len(t, client.published, 1)
+	assert.Equal(t, "projects/lamassu/topics/cas", client.published[0].topic)
+	assert.Equal(t, string(models.GCPPubSubCARegistered), client.published[0].message.Attributes[AttributeType])
+	assert.Equal(t, "factory", client.published[0].message.Attributes[AttributeRegistry])
+
+	msg := decodeMessage[models.GCPPubSubCAMessage](t, client.published[0].message)
+	assert.Equal(t, "ca-1", msg.CAID)
+	assert.Equal(t, models.StatusActive, msg.Status)
+}
+
+func TestPubSubConnectorUpdateCertificateStatus(t *testing.T) {
+	connector, client, caSDK := newTestConnector(t)
+
+	synced := models.CACertificate{ID: "ca-1", Metadata: map[string]any{models.CloudConnectorMetadataKey("gcp"): map[string]any{}}}
+	caSDK.On("GetCAByID", mock.Anything, services.GetCAByIDInput{CAID: "ca-1"}).Return(&synced, nil)
+	caSDK.On("GetCAByID", mock.Anything, services.GetCAByIDInput{CAID: "ca-2"}).Return(&models.CACertificate{ID: "ca-2"}, nil)
+
+	cert := models.Certificate{
+		SerialNumber:     "0a",
+		Status:           models.StatusRevoked,
+		IssuerCAMetadata: models.IssuerCAMetadata{ID: "ca-1"},
+		Metadata:         map[string]any{models.CAAttachedToDeviceKey: "device-1"},
+	}
+
+	err := connector.UpdateCertificateStatus(context.Background(), connectors.UpdateCertificateStatusInput{
+		Certificate:    cert,
+		PreviousStatus: models.StatusActive,
+	})
+	assert.NoError(t, err)
+
+	assert.Len(t, client.published, 1)
+	assert.Equal(t, "projects/lamassu/topics/devices", client.published[0].topic)
+
+	msg := decodeMessage[models.GCPPubSubDeviceCertificateMessage](t, client.published[0].message)
+	assert.Equal(t, "ca-1", msg.Registry)
+	assert.Equal(t, "device-1", msg.DeviceID)
+	assert.Equal(t, models.StatusRevoked, msg.Status)
+	assert.Equal(t, models.StatusActive, msg.PreviousStatus)
+
+	// Certificates issued by CAs not synchronized with the connector are skipped
+	cert.IssuerCAMetadata.ID = "ca-2"
+	err = connector.UpdateCertificateStatus(context.Background(), connectors.UpdateCertificateStatusInput{Certificate: cert})
+	assert.NoError(t, err)
+	assert.Len(t, client.published, 1)
+}
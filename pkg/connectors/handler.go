@@ -0,0 +1,317 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"slices"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services/handlers"
+	"github.com/sirupsen/logrus"
+)
+
+// NewEventHandler dispatches the Lamassu events to the connector operations. Events originated by the
+// connector itself (source models.CloudConnectorSource) are dropped to prevent update loops.
+func NewEventHandler(l *logrus.Entry, connector Connector) *handlers.EventHandler {
+	return newEventHandler(l, connector, nil)
+}
+
+// NewRetryingEventHandler dispatches the events as NewEventHandler does, but the events the connector fails to
+// handle are enqueued into the retry queue and acknowledged. Events are only redelivered by the event bus if they
+// can't be enqueued.
+func NewRetryingEventHandler(l *logrus.Entry, connector Connector, queue *RetryQueue) *handlers.EventHandler {
+	return newEventHandler(l, connector, queue)
+}
+
+func newEventHandler(l *logrus.Entry, connector Connector, queue *RetryQueue) *handlers.EventHandler {
+	dispatch := func(handler func(ctx context.Context, e *event.Event, c Connector, l *logrus.Entry) error) func(*event.Event) error {
+		return func(e *event.Event) error {
+			l.Tracef("incoming cloud event: type=%s source=%s id=%s", e.Type(), e.Source(), e.ID())
+			if e.Source() == models.CloudConnectorSource(connector.ID()) {
+				l.Tracef("dropping cloud event originated by this connector: type=%s source=%s id=%s", e.Type(), e.Source(), e.ID())
+				return nil
+			}
+
+			ctx := helpers.InitContext()
+			err := handler(ctx, e, connector, l)
+			if err == nil || queue == nil {
+				return err
+			}
+
+			qErr := queue.Enqueue(ctx, e, err)
+			if qErr != nil {
+				l.Errorf("%s", qErr)
+				return err
+			}
+
+			return nil
+		}
+	}
+
+	dispatchMap := map[string]func(*event.Event) error{}
+	for _, eventType := range SubscribedEventTypes(connector) {
+		dispatchMap[string(eventType)] = dispatch(eventHandlers[eventType])
+	}
+
+	return handlers.NewEventHandler(l, dispatchMap)
+}
+
+var eventHandlers = map[models.EventType]func(ctx context.Context, e *event.Event, c Connector, l *logrus.Entry) error{
+	models.EventCreateCAKey:                registerCAHandler,
+	models.EventImportCAKey:                registerCAHandler,
+	models.EventUpdateCAMetadataKey:        registerCAHandler,
+	models.EventUpdateCAStatusKey:          updateCAStatusHandler,
+	models.EventCreateDMSKey:               registerDMSHandler,
+	models.EventUpdateDMSKey:               registerDMSHandler,
+	models.EventBindDeviceIdentityKey:      bindDeviceIdentityHandler,
+	models.EventUpdateDeviceMetadataKey:    updateDeviceMetadataHandler,
+	models.EventUpdateCertificateStatusKey: updateCertificateStatusHandler,
+}
+
+// handleEvent dispatches a single event to the connector operation of its type.
+func handleEvent(ctx context.Context, e *event.Event, connector Connector, l *logrus.Entry) error {
+	handler, ok := eventHandlers[models.EventType(e.Type())]
+	if !ok {
+		return fmt.Errorf("unsupported event type %s", e.Type())
+	}
+
+	return handler(ctx, e, connector, l)
+}
+
+// SubscribedEventTypes returns the event types dispatched to the connector: the ones declared in its
+// EventSubscription or every supported event type if it doesn't declare any. Unsupported types are ignored.
+func SubscribedEventTypes(connector Connector) []models.EventType {
+	eventTypes := []models.EventType{}
+	if subscribed, ok := connector.(SubscribedConnector); ok {
+		for _, eventType := range subscribed.Subscription().EventTypes {
+			if _, supported := eventHandlers[eventType]; supported && !slices.Contains(eventTypes, eventType) {
+				eventTypes = append(eventTypes, eventType)
+			}
+		}
+	}
+
+	if len(eventTypes) == 0 {
+		for eventType := range eventHandlers {
+			eventTypes = append(eventTypes, eventType)
+		}
+	}
+
+	slices.Sort(eventTypes)
+	return eventTypes
+}
+
+func logDecodeError(logger *logrus.Entry, e *event.Event, modelObject string, err error) {
+	logger.Errorf("could not decode event '%s' into model '%s' object. Skipping event with ID %s: %s", e.Type(), modelObject, e.ID(), err)
+}
+
+func usesConnector(metadata map[string]any, connector Connector) bool {
+	_, ok := metadata[models.CloudConnectorMetadataKey(connector.ID())]
+	return ok
+}
+
+// acceptsCA checks the CA against the CAIDs of the connector EventSubscription.
+func acceptsCA(connector Connector, caID string) bool {
+	subscribed, ok := connector.(SubscribedConnector)
+	if !ok {
+		return true
+	}
+
+	caIDs := subscribed.Subscription().CAIDs
+	return len(caIDs) == 0 || slices.Contains(caIDs, caID)
+}
+
+func registerCAHandler(ctx context.Context, e *event.Event, connector Connector, logger *logrus.Entry) error {
+	var ca *models.CACertificate
+	if e.Type() == string(models.EventUpdateCAMetadataKey) {
+		update, err := helpers.GetEventBody[models.UpdateModel[models.CACertificate]](e)
+		if err != nil {
+			logDecodeError(logger, e, "UpdateModel CACertificate", err)
+			return nil
+		}
+		ca = &update.Updated
+	} else {
+		var err error
+		ca, err = helpers.GetEventBody[models.CACertificate](e)
+		if err != nil {
+			logDecodeError(logger, e, "CACertificate", err)
+			return nil
+		}
+	}
+
+	if !usesConnector(ca.Metadata, connector) {
+		logger.Debugf("skipping event %s, CA %s doesn't have %s key", e.Type(), ca.ID, models.CloudConnectorMetadataKey(connector.ID()))
+		return nil
+	}
+
+	if !acceptsCA(connector, ca.ID) {
+		logger.Debugf("skipping event %s, CA %s is not subscribed", e.Type(), ca.ID)
+		return nil
+	}
+
+	err := connector.RegisterCA(ctx, RegisterCAInput{CA: *ca})
+	if err != nil {
+		err = fmt.Errorf("could not register CA %s: %s", ca.ID, err)
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func updateCAStatusHandler(ctx context.Context, e *event.Event, connector Connector, logger *logrus.Entry) error {
+	update, err := helpers.GetEventBody[models.UpdateModel[models.CACertificate]](e)
+	if err != nil {
+		logDecodeError(logger, e, "UpdateModel CACertificate", err)
+		return nil
+	}
+
+	if !usesConnector(update.Updated.Metadata, connector) {
+		logger.Debugf("skipping event %s, CA %s doesn't have %s key", e.Type(), update.Updated.ID, models.CloudConnectorMetadataKey(connector.ID()))
+		return nil
+	}
+
+	if !acceptsCA(connector, update.Updated.ID) {
+		logger.Debugf("skipping event %s, CA %s is not subscribed", e.Type(), update.Updated.ID)
+		return nil
+	}
+
+	err = connector.UpdateCAStatus(ctx, UpdateCAStatusInput{
+		CA:             update.Updated,
+		PreviousStatus: update.Previous.Status,
+	})
+	if err != nil {
+		err = fmt.Errorf("could not update CA %s status: %s", update.Updated.ID, err)
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func registerDMSHandler(ctx context.Context, e *event.Event, connector Connector, logger *logrus.Entry) error {
+	input := RegisterDMSInput{}
+	if e.Type() == string(models.EventUpdateDMSKey) {
+		update, err := helpers.GetEventBody[models.UpdateModel[models.DMS]](e)
+		if err != nil {
+			logDecodeError(logger, e, "UpdateModel DMS", err)
+			return nil
+		}
+		input.DMS = update.Updated
+		input.Previous = &update.Previous
+	} else {
+		dms, err := helpers.GetEventBody[models.DMS](e)
+		if err != nil {
+			logDecodeError(logger, e, "DMS", err)
+			return nil
+		}
+		input.DMS = *dms
+	}
+
+	if !usesConnector(input.DMS.Metadata, connector) {
+		logger.Debugf("skipping event %s, DMS %s doesn't have %s key", e.Type(), input.DMS.ID, models.CloudConnectorMetadataKey(connector.ID()))
+		return nil
+	}
+
+	if enrollmentCA := input.DMS.Settings.EnrollmentSettings.EnrollmentCA; !acceptsCA(connector, enrollmentCA) {
+		logger.Debugf("skipping event %s, DMS %s enrollment CA %s is not subscribed", e.Type(), input.DMS.ID, enrollmentCA)
+		return nil
+	}
+
+	err := connector.RegisterDMS(ctx, input)
+	if err != nil {
+		err = fmt.Errorf("could not register DMS %s: %s", input.DMS.ID, err)
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func bindDeviceIdentityHandler(ctx context.Context, e *event.Event, connector Connector, logger *logrus.Entry) error {
+	bind, err := helpers.GetEventBody[models.BindIdentityToDeviceOutput](e)
+	if err != nil {
+		logDecodeError(logger, e, "BindIdentityToDeviceOutput", err)
+		return nil
+	}
+
+	if bind.DMS == nil || bind.Device == nil {
+		logger.Warnf("skipping event %s with ID %s, it doesn't include the DMS and the device", e.Type(), e.ID())
+		return nil
+	}
+
+	if !usesConnector(bind.DMS.Metadata, connector) {
+		logger.Debugf("skipping event %s, DMS %s doesn't have %s key", e.Type(), bind.DMS.ID, models.CloudConnectorMetadataKey(connector.ID()))
+		return nil
+	}
+
+	if bind.Certificate != nil && !acceptsCA(connector, bind.Certificate.IssuerCAMetadata.ID) {
+		logger.Debugf("skipping event %s, certificate issuer CA %s is not subscribed", e.Type(), bind.Certificate.IssuerCAMetadata.ID)
+		return nil
+	}
+
+	err = connector.BindDeviceIdentity(ctx, BindDeviceIdentityInput{BindedIdentity: *bind})
+	if err != nil {
+		err = fmt.Errorf("could not bind identity to device %s: %s", bind.Device.ID, err)
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func updateDeviceMetadataHandler(ctx context.Context, e *event.Event, connector Connector, logger *logrus.Entry) error {
+	update, err := helpers.GetEventBody[models.UpdateModel[models.Device]](e)
+	if err != nil {
+		logDecodeError(logger, e, "UpdateModel Device", err)
+		return nil
+	}
+
+	if !usesConnector(update.Updated.Metadata, connector) {
+		logger.Debugf("skipping event %s, device %s doesn't have %s key", e.Type(), update.Updated.ID, models.CloudConnectorMetadataKey(connector.ID()))
+		return nil
+	}
+
+	err = connector.UpdateDeviceMetadata(ctx, UpdateDeviceMetadataInput{
+		Device:   update.Updated,
+		Previous: update.Previous,
+	})
+	if err != nil {
+		err = fmt.Errorf("could not update device %s metadata: %s", update.Updated.ID, err)
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
+
+func updateCertificateStatusHandler(ctx context.Context, e *event.Event, connector Connector, logger *logrus.Entry) error {
+	update, err := helpers.GetEventBody[models.UpdateModel[models.Certificate]](e)
+	if err != nil {
+		logDecodeError(logger, e, "UpdateModel Certificate", err)
+		return nil
+	}
+
+	if _, attached := update.Updated.Metadata[models.CAAttachedToDeviceKey]; !attached {
+		logger.Debugf("skipping event %s, certificate %s is not attached to a device", e.Type(), update.Updated.SerialNumber)
+		return nil
+	}
+
+	if !acceptsCA(connector, update.Updated.IssuerCAMetadata.ID) {
+		logger.Debugf("skipping event %s, certificate issuer CA %s is not subscribed", e.Type(), update.Updated.IssuerCAMetadata.ID)
+		return nil
+	}
+
+	err = connector.UpdateCertificateStatus(ctx, UpdateCertificateStatusInput{
+		Certificate:    update.Updated,
+		PreviousStatus: update.Previous.Status,
+	})
+	if err != nil {
+		err = fmt.Errorf("could not update certificate %s status: %s", update.Updated.SerialNumber, err)
+		logger.Error(err)
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,63 @@
+package connectors
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
+	"github.com/sirupsen/logrus"
+)
+
+// HealthReporter announces the connector and publishes its health into the event bus. It implements cron.Job
+// so it can be scheduled with jobs.NewJobScheduler.
+type HealthReporter struct {
+	connector Connector
+	publisher eventpub.ICloudEventMiddlewarePublisher
+	logger    *logrus.Entry
+}
+
+func NewHealthReporter(connector Connector, publisher eventpub.ICloudEventMiddlewarePublisher, logger *logrus.Entry) *HealthReporter {
+	return &HealthReporter{
+		connector: connector,
+		publisher: publisher,
+		logger:    logger,
+	}
+}
+
+// Register publishes the connector registration event.
+func (r *HealthReporter) Register(ctx context.Context, version string) {
+	ctx = r.withSource(ctx)
+	lFunc := helpers.ConfigureLogger(ctx, r.logger)
+	lFunc.Infof("registering %s connector %s", r.connector.Provider(), r.connector.ID())
+
+	r.publisher.PublishCloudEvent(ctx, models.EventConnectorRegisterKey, models.ConnectorRegistration{
+		ConnectorID: r.connector.ID(),
+		Provider:    r.connector.Provider(),
+		Version:     version,
+		Timestamp:   time.Now(),
+	})
+}
+
+func (r *HealthReporter) Run() {
+	ctx := r.withSource(helpers.InitContext())
+	lFunc := helpers.ConfigureLogger(ctx, r.logger)
+
+	health := r.connector.Health(ctx)
+	if health.Status != models.ConnectorHealthy {
+		lFunc.Warnf("connector %s is %s: %s", r.connector.ID(), health.Status, health.Message)
+	}
+
+	r.publisher.PublishCloudEvent(ctx, models.EventConnectorHealthKey, models.ConnectorHealthReport{
+		ConnectorID: r.connector.ID(),
+		Health:      health,
+		Timestamp:   time.Now(),
+	})
+}
+
+// withSource sets the connector as the source of the published events.
+func (r *HealthReporter) withSource(ctx context.Context) context.Context {
+	return context.WithValue(ctx, headerextractors.CtxSource, models.CloudConnectorSource(r.connector.ID()))
+}
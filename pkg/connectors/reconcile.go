@@ -0,0 +1,168 @@
+package connectors
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// CertificateStatusRegistry is implemented by the connectors whose provider keeps its own registry of device
+// certificates (i.e. the AWS IoT certificates). It enables the certificate status reconciliation.
+type CertificateStatusRegistry interface {
+	// ListCertificateStatuses calls apply for every certificate registered in the provider.
+	ListCertificateStatuses(ctx context.Context, apply func(models.ProviderCertificateStatus)) error
+	// SetCertificateStatus updates the status of the certificate in the provider registry.
+	SetCertificateStatus(ctx context.Context, cert models.ProviderCertificateStatus, status models.CertificateStatus) error
+}
+
+// CertificateStatusReconciler compares the status of the certificates registered in the provider with the
+// Lamassu status and fixes the drift:
+//   - Certificates revoked or expired in Lamassu but active in the provider are deactivated in the provider.
+//   - Certificates active in Lamassu but revoked in the provider are revoked in Lamassu.
+//   - Certificates unknown to Lamassu are only reported.
+//
+// Every discrepancy is published as a models.EventCertificateStatusDriftKey event. It implements cron.Job
+// so it can be scheduled with jobs.NewJobScheduler.
+type CertificateStatusReconciler struct {
+	connectorID string
+	registry    CertificateStatusRegistry
+	caSDK       services.CAService
+	publisher   eventpub.ICloudEventMiddlewarePublisher
+	logger      *logrus.Entry
+
+	mutex      sync.Mutex
+	lastReport *models.CertificateReconciliationReport
+}
+
+// NewCertificateStatusReconciler creates a reconciler. publisher is optional, no events are published if nil.
+func NewCertificateStatusReconciler(connectorID string, registry CertificateStatusRegistry, caSDK services.CAService, publisher eventpub.ICloudEventMiddlewarePublisher, logger *logrus.Entry) *CertificateStatusReconciler {
+	return &CertificateStatusReconciler{
+		connectorID: connectorID,
+		registry:    registry,
+		caSDK:       caSDK,
+		publisher:   publisher,
+		logger:      logger,
+	}
+}
+
+func (r *CertificateStatusReconciler) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, r.logger)
+
+	_, err := r.Reconcile(ctx)
+	if err != nil {
+		lFunc.Errorf("certificate status reconciliation failed: %s", err)
+	}
+}
+
+// LastReport returns the report of the last completed reconciliation or nil if none has completed yet.
+func (r *CertificateStatusReconciler) LastReport() *models.CertificateReconciliationReport {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	return r.lastReport
+}
+
+// Reconcile runs a full reconciliation. Concurrent calls are serialized.
+func (r *CertificateStatusReconciler) Reconcile(ctx context.Context) (*models.CertificateReconciliationReport, error) {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	ctx = context.WithValue(ctx, headerextractors.CtxSource, models.CloudConnectorSource(r.connectorID))
+	lFunc := helpers.ConfigureLogger(ctx, r.logger)
+	lFunc.Infof("reconciling certificate statuses of connector %s", r.connectorID)
+
+	report := &models.CertificateReconciliationReport{
+		ConnectorID:   r.connectorID,
+		StartedAt:     time.Now(),
+		Discrepancies: []models.CertificateStatusDiscrepancy{},
+	}
+
+	err := r.registry.ListCertificateStatuses(ctx, func(provCert models.ProviderCertificateStatus) {
+		report.Checked++
+		discrepancy := r.reconcileCertificate(ctx, lFunc, provCert)
+		if discrepancy == nil {
+			return
+		}
+
+		report.Discrepancies = append(report.Discrepancies, *discrepancy)
+		if r.publisher != nil {
+			r.publisher.PublishCloudEvent(ctx, models.EventCertificateStatusDriftKey, *discrepancy)
+		}
+	})
+	if err != nil {
+		lFunc.Errorf("could not list certificates of connector %s: %s", r.connectorID, err)
+		return nil, err
+	}
+
+	report.FinishedAt = time.Now()
+	r.lastReport = report
+	lFunc.Infof("reconciled %d certificates of connector %s. %d discrepancies found", report.Checked, r.connectorID, len(report.Discrepancies))
+
+	if r.publisher != nil {
+		r.publisher.PublishCloudEvent(ctx, models.EventCertificateReconciliationKey, *report)
+	}
+
+	return report, nil
+}
+
+func (r *CertificateStatusReconciler) reconcileCertificate(ctx context.Context, lFunc *logrus.Entry, provCert models.ProviderCertificateStatus) *models.CertificateStatusDiscrepancy {
+	discrepancy := &models.CertificateStatusDiscrepancy{
+		ConnectorID:    r.connectorID,
+		SerialNumber:   provCert.SerialNumber,
+		ProviderID:     provCert.ProviderID,
+		ProviderStatus: provCert.Status,
+		Action:         models.ReconciliationActionNone,
+	}
+
+	cert, err := r.caSDK.GetCertificateBySerialNumber(ctx, services.GetCertificatesBySerialNumberInput{
+		SerialNumber: provCert.SerialNumber,
+	})
+	if err != nil {
+		if !errors.Is(err, errs.ErrCertificateNotFound) {
+			lFunc.Errorf("could not get certificate %s: %s", provCert.SerialNumber, err)
+			discrepancy.Error = err.Error()
+			return discrepancy
+		}
+
+		lFunc.Warnf("certificate %s registered in connector %s is unknown to Lamassu", provCert.SerialNumber, r.connectorID)
+		return discrepancy
+	}
+
+	discrepancy.LamassuStatus = cert.Status
+	lamassuActive := cert.Status == models.StatusActive
+	providerActive := provCert.Status == models.StatusActive
+	if lamassuActive == providerActive {
+		return nil
+	}
+
+	if providerActive {
+		lFunc.Infof("certificate %s is %s in Lamassu but active in connector %s. Deactivating it in the provider", cert.SerialNumber, cert.Status, r.connectorID)
+		discrepancy.Action = models.ReconciliationActionProviderUpdated
+		err = r.registry.SetCertificateStatus(ctx, provCert, models.StatusRevoked)
+	} else {
+		lFunc.Infof("certificate %s is active in Lamassu but %s in connector %s. Revoking it in Lamassu", cert.SerialNumber, provCert.Status, r.connectorID)
+		discrepancy.Action = models.ReconciliationActionLamassuUpdated
+		_, err = r.caSDK.UpdateCertificateStatus(ctx, services.UpdateCertificateStatusInput{
+			SerialNumber:     cert.SerialNumber,
+			NewStatus:        models.StatusRevoked,
+			RevocationReason: models.RevocationReason(0),
+		})
+	}
+
+	if err != nil {
+		lFunc.Errorf("could not fix certificate %s status drift: %s", cert.SerialNumber, err)
+		discrepancy.Error = err.Error()
+	}
+
+	return discrepancy
+}
@@ -0,0 +1,81 @@
+package connectors
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type testRegistry struct {
+	mock.Mock
+	certificates []models.ProviderCertificateStatus
+}
+
+func (r *testRegistry) ListCertificateStatuses(ctx context.Context, apply func(models.ProviderCertificateStatus)) error {
+	for _, cert := range r.certificates {
+		apply(cert)
+	}
+	return nil
+}
+
+func (r *testRegistry) SetCertificateStatus(ctx context.Context, cert models.ProviderCertificateStatus, status models.CertificateStatus) error {
+	args := r.Called(cert.ProviderID, status)
+	return args.Error(0)
+}
+
+func TestCertificateStatusReconciler(t *testing.T) {
+	registry := &testRegistry{certificates: []models.ProviderCertificateStatus{
+		{SerialNumber: "01", ProviderID: "in-sync", Status: models.StatusActive},
+		{SerialNumber: "02", ProviderID: "revoked-in-lamassu", Status: models.StatusActive},
+		{SerialNumber: "03", ProviderID: "revoked-in-provider", Status: models.StatusRevoked},
+		{SerialNumber: "04", ProviderID: "unknown", Status: models.StatusActive},
+	}}
+	registry.On("SetCertificateStatus", "revoked-in-lamassu", models.StatusRevoked).Return(nil)
+
+	caSDK := &svcmock.MockCAService{}
+	bySN := func(sn string) services.GetCertificatesBySerialNumberInput {
+		return services.GetCertificatesBySerialNumberInput{SerialNumber: sn}
+	}
+	caSDK.On("GetCertificateBySerialNumber", mock.Anything, bySN("01")).Return(&models.Certificate{SerialNumber: "01", Status: models.StatusActive}, nil)
+	caSDK.On("GetCertificateBySerialNumber", mock.Anything, bySN("02")).Return(&models.Certificate{SerialNumber: "02", Status: models.StatusRevoked}, nil)
+	caSDK.On("GetCertificateBySerialNumber", mock.Anything, bySN("03")).Return(&models.Certificate{SerialNumber: "03", Status: models.StatusActive}, nil)
+	caSDK.On("GetCertificateBySerialNumber", mock.Anything, bySN("04")).Return((*models.Certificate)(nil), errs.ErrCertificateNotFound)
+	caSDK.On("UpdateCertificateStatus", mock.Anything, services.UpdateCertificateStatusInput{
+		SerialNumber: "03",
+		NewStatus:    models.StatusRevoked,
+	}).Return(&models.Certificate{SerialNumber: "03", Status: models.StatusRevoked}, nil)
+
+	pub := &mockPublisher{}
+	pub.On("PublishCloudEvent", mock.Anything, mock.Anything, mock.Anything).Return()
+
+	reconciler := NewCertificateStatusReconciler("my-cloud", registry, caSDK, pub, logrus.NewEntry(logrus.StandardLogger()))
+	assert.Nil(t, reconciler.LastReport())
+
+	report, err := reconciler.Reconcile(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, 4, report.Checked)
+	assert.Len(t, report.Discrepancies, 3)
+
+	actions := map[string]models.CertificateReconciliationAction{}
+	for _, discrepancy := range report.Discrepancies {
+		assert.Empty(t, discrepancy.Error)
+		actions[discrepancy.SerialNumber] = discrepancy.Action
+	}
+	assert.Equal(t, map[string]models.CertificateReconciliationAction{
+		"02": models.ReconciliationActionProviderUpdated,
+		"03": models.ReconciliationActionLamassuUpdated,
+		"04": models.ReconciliationActionNone,
+	}, actions)
+
+	registry.AssertExpectations(t)
+	caSDK.AssertExpectations(t)
+	pub.AssertNumberOfCalls(t, "PublishCloudEvent", 4)
+	assert.Equal(t, report, reconciler.LastReport())
+}
@@ -0,0 +1,170 @@
+package connectors
+
+import (
+	"context"
+	"slices"
+	"strings"
+	"sync"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services/handlers"
+	"github.com/sirupsen/logrus"
+)
+
+// ConnectorRegistry discovers the deployed cloud connectors, so the services talking to them do not depend on a
+// specific service discovery system.
+type ConnectorRegistry interface {
+	// GetConnectors returns the known connectors sorted by ID.
+	GetConnectors(ctx context.Context) ([]models.ConnectorInstance, error)
+	// GetConnector returns errs.ErrConnectorNotFound if the connector is unknown.
+	GetConnector(ctx context.Context, connectorID string) (*models.ConnectorInstance, error)
+}
+
+// getConnector looks up the connector in the list returned by the registry.
+func getConnector(ctx context.Context, registry ConnectorRegistry, connectorID string) (*models.ConnectorInstance, error) {
+	instances, err := registry.GetConnectors(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, instance := range instances {
+		if instance.ConnectorID == connectorID {
+			return &instance, nil
+		}
+	}
+
+	return nil, errs.ErrConnectorNotFound
+}
+
+func sortConnectors(instances []models.ConnectorInstance) {
+	slices.SortFunc(instances, func(a, b models.ConnectorInstance) int {
+		return strings.Compare(a.ConnectorID, b.ConnectorID)
+	})
+}
+
+// StaticConnectorRegistry returns the connectors listed in the configuration.
+type StaticConnectorRegistry struct {
+	instances []models.ConnectorInstance
+}
+
+func NewStaticConnectorRegistry(conf []config.StaticConnector) *StaticConnectorRegistry {
+	instances := []models.ConnectorInstance{}
+	for _, connector := range conf {
+		instances = append(instances, models.ConnectorInstance{
+			ConnectorID: connector.ConnectorID,
+			Provider:    connector.Provider,
+			Address:     connector.Address,
+		})
+	}
+	sortConnectors(instances)
+
+	return &StaticConnectorRegistry{instances: instances}
+}
+
+func (r *StaticConnectorRegistry) GetConnectors(ctx context.Context) ([]models.ConnectorInstance, error) {
+	return slices.Clone(r.instances), nil
+}
+
+func (r *StaticConnectorRegistry) GetConnector(ctx context.Context, connectorID string) (*models.ConnectorInstance, error) {
+	return getConnector(ctx, r, connectorID)
+}
+
+// EventConnectorRegistry keeps the connectors announced through the registration events published by
+// HealthReporter, along with their last reported health. Connectors are never removed: a connector that stopped
+// reporting keeps its last health and LastSeen.
+type EventConnectorRegistry struct {
+	lock      sync.RWMutex
+	instances map[string]models.ConnectorInstance
+}
+
+func NewEventConnectorRegistry() *EventConnectorRegistry {
+	return &EventConnectorRegistry{
+		instances: map[string]models.ConnectorInstance{},
+	}
+}
+
+// EventTypes returns the event types to bind to the registry event handler.
+func (r *EventConnectorRegistry) EventTypes() []models.EventType {
+	return []models.EventType{models.EventConnectorRegisterKey, models.EventConnectorHealthKey}
+}
+
+// NewEventHandler feeds the registry with the connector registration and health events.
+func (r *EventConnectorRegistry) NewEventHandler(l *logrus.Entry) *handlers.EventHandler {
+	return handlers.NewEventHandler(l, map[string]func(*event.Event) error{
+		string(models.EventConnectorRegisterKey): func(e *event.Event) error {
+			registration, err := helpers.GetEventBody[models.ConnectorRegistration](e)
+			if err != nil {
+				logDecodeError(l, e, "ConnectorRegistration", err)
+				return nil
+			}
+
+			r.Register(*registration)
+			return nil
+		},
+		string(models.EventConnectorHealthKey): func(e *event.Event) error {
+			report, err := helpers.GetEventBody[models.ConnectorHealthReport](e)
+			if err != nil {
+				logDecodeError(l, e, "ConnectorHealthReport", err)
+				return nil
+			}
+
+			r.ReportHealth(*report)
+			return nil
+		},
+	})
+}
+
+func (r *EventConnectorRegistry) Register(registration models.ConnectorRegistration) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	instance := r.instances[registration.ConnectorID]
+	instance.ConnectorID = registration.ConnectorID
+	instance.Provider = registration.Provider
+	instance.Version = registration.Version
+	instance.LastSeen = registration.Timestamp
+	r.instances[registration.ConnectorID] = instance
+}
+
+// ReportHealth updates the health of the connector. Connectors reporting their health before being registered
+// (i.e. the registry started after the connector) are added without provider nor version.
+func (r *EventConnectorRegistry) ReportHealth(report models.ConnectorHealthReport) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	instance := r.instances[report.ConnectorID]
+	instance.ConnectorID = report.ConnectorID
+	health := report.Health
+	instance.Health = &health
+	instance.LastSeen = report.Timestamp
+	r.instances[report.ConnectorID] = instance
+}
+
+func (r *EventConnectorRegistry) GetConnectors(ctx context.Context) ([]models.ConnectorInstance, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	instances := []models.ConnectorInstance{}
+	for _, instance := range r.instances {
+		instances = append(instances, instance)
+	}
+	sortConnectors(instances)
+
+	return instances, nil
+}
+
+func (r *EventConnectorRegistry) GetConnector(ctx context.Context, connectorID string) (*models.ConnectorInstance, error) {
+	r.lock.RLock()
+	defer r.lock.RUnlock()
+
+	instance, ok := r.instances[connectorID]
+	if !ok {
+		return nil, errs.ErrConnectorNotFound
+	}
+
+	return &instance, nil
+}
@@ -0,0 +1,107 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// Metadata of the Consul catalog service instances registered by the connectors.
+const (
+	ConsulConnectorIDMeta       = "connector-id"
+	ConsulConnectorProviderMeta = "provider"
+)
+
+// ConsulConnectorRegistry lists the instances of a Consul catalog service. Instances without the
+// ConsulConnectorIDMeta metadata use their service ID as connector ID.
+type ConsulConnectorRegistry struct {
+	client      *http.Client
+	address     string
+	token       string
+	serviceName string
+}
+
+func NewConsulConnectorRegistry(conf config.ConsulConnectorDiscovery) (*ConsulConnectorRegistry, error) {
+	if conf.Address == "" || conf.ServiceName == "" {
+		return nil, fmt.Errorf("consul address and service name are required")
+	}
+
+	return &ConsulConnectorRegistry{
+		client:      http.DefaultClient,
+		address:     strings.TrimSuffix(conf.Address, "/"),
+		token:       string(conf.Token),
+		serviceName: conf.ServiceName,
+	}, nil
+}
+
+type consulCatalogService struct {
+	ServiceID      string            `json:"ServiceID"`
+	Address        string            `json:"Address"`
+	ServiceAddress string            `json:"ServiceAddress"`
+	ServicePort    int               `json:"ServicePort"`
+	ServiceMeta    map[string]string `json:"ServiceMeta"`
+}
+
+func (r *ConsulConnectorRegistry) GetConnectors(ctx context.Context) ([]models.ConnectorInstance, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("%s/v1/catalog/service/%s", r.address, url.PathEscape(r.serviceName)), nil)
+	if err != nil {
+		return nil, err
+	}
+
+	if r.token != "" {
+		req.Header.Set("X-Consul-Token", r.token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not list connector services: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not list connector services: unexpected status code %d", resp.StatusCode)
+	}
+
+	var services []consulCatalogService
+	err = json.NewDecoder(resp.Body).Decode(&services)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode connector services: %s", err)
+	}
+
+	instances := []models.ConnectorInstance{}
+	for _, svc := range services {
+		instance := models.ConnectorInstance{
+			ConnectorID: svc.ServiceMeta[ConsulConnectorIDMeta],
+			Provider:    svc.ServiceMeta[ConsulConnectorProviderMeta],
+		}
+		if instance.ConnectorID == "" {
+			instance.ConnectorID = svc.ServiceID
+		}
+
+		// The node address is used if the service was registered without address.
+		host := svc.ServiceAddress
+		if host == "" {
+			host = svc.Address
+		}
+		if host != "" && svc.ServicePort != 0 {
+			instance.Address = "http://" + net.JoinHostPort(host, strconv.Itoa(svc.ServicePort))
+		}
+
+		instances = append(instances, instance)
+	}
+	sortConnectors(instances)
+
+	return instances, nil
+}
+
+func (r *ConsulConnectorRegistry) GetConnector(ctx context.Context, connectorID string) (*models.ConnectorInstance, error) {
+	return getConnector(ctx, r, connectorID)
+}
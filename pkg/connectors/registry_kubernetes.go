@@ -0,0 +1,157 @@
+package connectors
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// Labels of the Kubernetes services exposing the connectors.
+const (
+	KubernetesConnectorIDLabel       = "lamassu.io/connector-id"
+	KubernetesConnectorProviderLabel = "lamassu.io/connector-provider"
+)
+
+const kubernetesServiceAccountDir = "/var/run/secrets/kubernetes.io/serviceaccount"
+
+// KubernetesConnectorRegistry lists the services labeled with KubernetesConnectorIDLabel through the Kubernetes API.
+// The pod service account requires the permission to list the services of the namespace.
+type KubernetesConnectorRegistry struct {
+	client        *http.Client
+	apiServerURL  string
+	namespace     string
+	labelSelector string
+	tokenFile     string
+}
+
+func NewKubernetesConnectorRegistry(conf config.KubernetesConnectorDiscovery) (*KubernetesConnectorRegistry, error) {
+	apiServerURL := conf.APIServerURL
+	if apiServerURL == "" {
+		host, port := os.Getenv("KUBERNETES_SERVICE_HOST"), os.Getenv("KUBERNETES_SERVICE_PORT")
+		if host == "" || port == "" {
+			return nil, fmt.Errorf("not running in a Kubernetes cluster and no API server URL configured")
+		}
+		apiServerURL = "https://" + net.JoinHostPort(host, port)
+	}
+
+	namespace := conf.Namespace
+	if namespace == "" {
+		ns, err := os.ReadFile(kubernetesServiceAccountDir + "/namespace")
+		if err != nil {
+			return nil, fmt.Errorf("could not read pod namespace: %s", err)
+		}
+		namespace = strings.TrimSpace(string(ns))
+	}
+
+	client := http.DefaultClient
+	caPEM, err := os.ReadFile(kubernetesServiceAccountDir + "/ca.crt")
+	if err == nil {
+		pool := x509.NewCertPool()
+		pool.AppendCertsFromPEM(caPEM)
+		client = &http.Client{
+			Transport: &http.Transport{
+				TLSClientConfig: &tls.Config{RootCAs: pool},
+			},
+		}
+	}
+
+	return newKubernetesConnectorRegistry(conf, client, apiServerURL, namespace, kubernetesServiceAccountDir+"/token"), nil
+}
+
+func newKubernetesConnectorRegistry(conf config.KubernetesConnectorDiscovery, client *http.Client, apiServerURL, namespace, tokenFile string) *KubernetesConnectorRegistry {
+	selector := KubernetesConnectorIDLabel
+	if conf.LabelSelector != "" {
+		selector = selector + "," + conf.LabelSelector
+	}
+
+	return &KubernetesConnectorRegistry{
+		client:        client,
+		apiServerURL:  strings.TrimSuffix(apiServerURL, "/"),
+		namespace:     namespace,
+		labelSelector: selector,
+		tokenFile:     tokenFile,
+	}
+}
+
+type kubernetesServiceList struct {
+	Items []struct {
+		Metadata struct {
+			Name      string            `json:"name"`
+			Namespace string            `json:"namespace"`
+			Labels    map[string]string `json:"labels"`
+		} `json:"metadata"`
+		Spec struct {
+			Ports []struct {
+				Name string `json:"name"`
+				Port int    `json:"port"`
+			} `json:"ports"`
+		} `json:"spec"`
+	} `json:"items"`
+}
+
+func (r *KubernetesConnectorRegistry) GetConnectors(ctx context.Context) ([]models.ConnectorInstance, error) {
+	reqURL := fmt.Sprintf("%s/api/v1/namespaces/%s/services?labelSelector=%s", r.apiServerURL, url.PathEscape(r.namespace), url.QueryEscape(r.labelSelector))
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, reqURL, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	// The service account token is read on each request, as projected tokens are rotated by the kubelet.
+	if token, err := os.ReadFile(r.tokenFile); err == nil {
+		req.Header.Set("Authorization", "Bearer "+strings.TrimSpace(string(token)))
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not list connector services: %s", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("could not list connector services: unexpected status code %d", resp.StatusCode)
+	}
+
+	var services kubernetesServiceList
+	err = json.NewDecoder(resp.Body).Decode(&services)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode connector services: %s", err)
+	}
+
+	instances := []models.ConnectorInstance{}
+	for _, svc := range services.Items {
+		instance := models.ConnectorInstance{
+			ConnectorID: svc.Metadata.Labels[KubernetesConnectorIDLabel],
+			Provider:    svc.Metadata.Labels[KubernetesConnectorProviderLabel],
+		}
+
+		// The port named "http" is preferred over the first port of the service.
+		port := 0
+		for _, p := range svc.Spec.Ports {
+			if port == 0 || p.Name == "http" {
+				port = p.Port
+			}
+		}
+		if port != 0 {
+			instance.Address = fmt.Sprintf("http://%s.%s.svc:%d", svc.Metadata.Name, svc.Metadata.Namespace, port)
+		}
+
+		instances = append(instances, instance)
+	}
+	sortConnectors(instances)
+
+	return instances, nil
+}
+
+func (r *KubernetesConnectorRegistry) GetConnector(ctx context.Context, connectorID string) (*models.ConnectorInstance, error) {
+	return getConnector(ctx, r, connectorID)
+}
@@ -0,0 +1,133 @@
+package connectors
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestStaticConnectorRegistry(t *testing.T) {
+	registry := NewStaticConnectorRegistry([]config.StaticConnector{
+		{ConnectorID: "gcp", Provider: models.GCPPubSubProvider, Address: "http://gcp-connector:8080"},
+		{ConnectorID: "aws", Provider: "aws", Address: "http://aws-connector:8080"},
+	})
+
+	instances, err := registry.GetConnectors(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, instances, 2)
+	assert.Equal(t, "aws", instances[0].ConnectorID)
+
+	instance, err := registry.GetConnector(context.Background(), "gcp")
+	assert.NoError(t, err)
+	assert.Equal(t, "http://gcp-connector:8080", instance.Address)
+
+	_, err = registry.GetConnector(context.Background(), "azure")
+	assert.ErrorIs(t, err, errs.ErrConnectorNotFound)
+}
+
+func TestEventConnectorRegistry(t *testing.T) {
+	registry := NewEventConnectorRegistry()
+
+	now := time.Now()
+	registry.ReportHealth(models.ConnectorHealthReport{
+		ConnectorID: "aws",
+		Health:      models.ConnectorHealth{Status: models.ConnectorDegraded},
+		Timestamp:   now,
+	})
+	registry.Register(models.ConnectorRegistration{
+		ConnectorID: "aws",
+		Provider:    "aws",
+		Version:     "1.0.0",
+		Timestamp:   now.Add(time.Second),
+	})
+
+	instance, err := registry.GetConnector(context.Background(), "aws")
+	assert.NoError(t, err)
+	assert.Equal(t, "aws", instance.Provider)
+	assert.Equal(t, "1.0.0", instance.Version)
+	assert.Equal(t, models.ConnectorDegraded, instance.Health.Status)
+	assert.Equal(t, now.Add(time.Second), instance.LastSeen)
+
+	_, err = registry.GetConnector(context.Background(), "gcp")
+	assert.ErrorIs(t, err, errs.ErrConnectorNotFound)
+}
+
+func TestKubernetesConnectorRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/api/v1/namespaces/lamassu/services", r.URL.Path)
+		assert.Equal(t, KubernetesConnectorIDLabel+",app=lamassu", r.URL.Query().Get("labelSelector"))
+
+		json.NewEncoder(w).Encode(map[string]any{
+			"items": []map[string]any{
+				{
+					"metadata": map[string]any{
+						"name":      "aws-connector",
+						"namespace": "lamassu",
+						"labels": map[string]string{
+							KubernetesConnectorIDLabel:       "aws",
+							KubernetesConnectorProviderLabel: "aws",
+						},
+					},
+					"spec": map[string]any{
+						"ports": []map[string]any{
+							{"name": "metrics", "port": 9090},
+							{"name": "http", "port": 8080},
+						},
+					},
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	registry := newKubernetesConnectorRegistry(config.KubernetesConnectorDiscovery{LabelSelector: "app=lamassu"}, server.Client(), server.URL, "lamassu", "")
+
+	instance, err := registry.GetConnector(context.Background(), "aws")
+	assert.NoError(t, err)
+	assert.Equal(t, "aws", instance.Provider)
+	assert.Equal(t, "http://aws-connector.lamassu.svc:8080", instance.Address)
+}
+
+func TestConsulConnectorRegistry(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/catalog/service/lamassu-connectors", r.URL.Path)
+		assert.Equal(t, "secret", r.Header.Get("X-Consul-Token"))
+
+		json.NewEncoder(w).Encode([]map[string]any{
+			{
+				"ServiceID":      "gcp-connector-1",
+				"Address":        "10.0.0.1",
+				"ServiceAddress": "",
+				"ServicePort":    8080,
+				"ServiceMeta": map[string]string{
+					ConsulConnectorIDMeta:       "gcp",
+					ConsulConnectorProviderMeta: models.GCPPubSubProvider,
+				},
+			},
+		})
+	}))
+	defer server.Close()
+
+	registry, err := NewConsulConnectorRegistry(config.ConsulConnectorDiscovery{
+		Address:     server.URL,
+		Token:       "secret",
+		ServiceName: "lamassu-connectors",
+	})
+	assert.NoError(t, err)
+
+	instances, err := registry.GetConnectors(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []models.ConnectorInstance{{
+		ConnectorID: "gcp",
+		Provider:    models.GCPPubSubProvider,
+		Address:     "http://10.0.0.1:8080",
+	}}, instances)
+}
@@ -0,0 +1,169 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/cloudevents/sdk-go/v2/event"
+	"github.com/google/uuid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+// RetryPolicy is the exponential backoff applied to the events of a RetryQueue. The delay before the attempt n+1 is
+// InitialInterval * 2^(n-1), capped to MaxInterval.
+type RetryPolicy struct {
+	// MaxAttempts counts the first failed attempt. Events are dead lettered once they reach it.
+	MaxAttempts     int
+	InitialInterval time.Duration
+	MaxInterval     time.Duration
+}
+
+// RetryQueue keeps the events the connector failed to handle in a persistent queue instead of dropping them, and
+// retries them with exponential backoff. Events running out of attempts are dead lettered and kept until they are
+// replayed. It implements cron.Job so it can be scheduled with jobs.NewJobScheduler.
+type RetryQueue struct {
+	connector Connector
+	storage   storage.ConnectorPendingEventsRepo
+	policy    RetryPolicy
+	logger    *logrus.Entry
+}
+
+func NewRetryQueue(connector Connector, storage storage.ConnectorPendingEventsRepo, policy RetryPolicy, logger *logrus.Entry) *RetryQueue {
+	return &RetryQueue{
+		connector: connector,
+		storage:   storage,
+		policy:    policy,
+		logger:    logger,
+	}
+}
+
+// Enqueue stores an event whose first attempt failed with handleErr.
+func (q *RetryQueue) Enqueue(ctx context.Context, e *event.Event, handleErr error) error {
+	lFunc := helpers.ConfigureLogger(ctx, q.logger)
+
+	now := time.Now()
+	pending := &models.ConnectorPendingEvent{
+		ID:          uuid.NewString(),
+		ConnectorID: q.connector.ID(),
+		Event:       *e,
+		CreatedAt:   now,
+	}
+	q.failed(pending, handleErr, now)
+
+	_, err := q.storage.Insert(ctx, pending)
+	if err != nil {
+		return fmt.Errorf("could not enqueue event %s: %s", e.ID(), err)
+	}
+
+	lFunc.Warnf("event %s of type %s enqueued with status %s: %s", e.ID(), e.Type(), pending.Status, handleErr)
+	return nil
+}
+
+// Run retries the events whose next attempt is due.
+func (q *RetryQueue) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, q.logger)
+
+	now := time.Now()
+	due := []models.ConnectorPendingEvent{}
+	_, err := q.storage.SelectByConnector(ctx, q.connector.ID(), storage.StorageListRequest[models.ConnectorPendingEvent]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(pending models.ConnectorPendingEvent) {
+			if pending.Status == models.ConnectorPendingEventRetrying && !pending.NextAttemptAt.After(now) {
+				due = append(due, pending)
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list pending events of connector %s: %s", q.connector.ID(), err)
+		return
+	}
+
+	for _, pending := range due {
+		_, err := q.retry(ctx, &pending)
+		if err != nil {
+			lFunc.Errorf("could not retry event %s: %s", pending.Event.ID(), err)
+		}
+	}
+}
+
+// GetPendingEvents returns the retrying and dead lettered events of the connector.
+func (q *RetryQueue) GetPendingEvents(ctx context.Context) ([]models.ConnectorPendingEvent, error) {
+	events := []models.ConnectorPendingEvent{}
+	_, err := q.storage.SelectByConnector(ctx, q.connector.ID(), storage.StorageListRequest[models.ConnectorPendingEvent]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(pending models.ConnectorPendingEvent) {
+			events = append(events, pending)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Replay retries a pending event right away, regardless of its status and next attempt. It returns nil if the
+// event was handled and removed from the queue, or the updated pending event otherwise.
+//
+// Returned Error Codes:
+//   - ErrConnectorPendingEventNotFound
+//     The event is not in the connector queue.
+func (q *RetryQueue) Replay(ctx context.Context, id string) (*models.ConnectorPendingEvent, error) {
+	exists, pending, err := q.storage.SelectExistsByID(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists || pending.ConnectorID != q.connector.ID() {
+		return nil, errs.ErrConnectorPendingEventNotFound
+	}
+
+	return q.retry(ctx, pending)
+}
+
+// retry handles the event again. Handled events are removed from the queue.
+func (q *RetryQueue) retry(ctx context.Context, pending *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	lFunc := helpers.ConfigureLogger(ctx, q.logger)
+
+	handleErr := handleEvent(ctx, &pending.Event, q.connector, lFunc)
+	if handleErr == nil {
+		lFunc.Infof("event %s handled after %d failed attempts", pending.Event.ID(), pending.Attempts)
+		return nil, q.storage.Delete(ctx, pending.ID)
+	}
+
+	q.failed(pending, handleErr, time.Now())
+	if pending.Status == models.ConnectorPendingEventDeadLetter {
+		lFunc.Errorf("event %s dead lettered after %d attempts: %s", pending.Event.ID(), pending.Attempts, handleErr)
+	}
+
+	return q.storage.Update(ctx, pending)
+}
+
+// failed records a failed attempt and schedules the next one, or dead letters the event.
+func (q *RetryQueue) failed(pending *models.ConnectorPendingEvent, handleErr error, now time.Time) {
+	pending.Attempts++
+	pending.LastError = handleErr.Error()
+	if pending.Attempts >= q.policy.MaxAttempts {
+		pending.Status = models.ConnectorPendingEventDeadLetter
+		return
+	}
+
+	pending.Status = models.ConnectorPendingEventRetrying
+	pending.NextAttemptAt = now.Add(q.backoff(pending.Attempts))
+}
+
+func (q *RetryQueue) backoff(attempts int) time.Duration {
+	delay := float64(q.policy.InitialInterval) * math.Pow(2, float64(attempts-1))
+	if q.policy.MaxInterval > 0 && delay > float64(q.policy.MaxInterval) {
+		return q.policy.MaxInterval
+	}
+
+	return time.Duration(delay)
+}
@@ -0,0 +1,164 @@
+package connectors
+
+import (
+	"context"
+	"crypto/x509"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/memory"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func retryQueueTest(t *testing.T, maxAttempts int) (*testConnector, storage.ConnectorPendingEventsRepo, *RetryQueue) {
+	connector := &testConnector{BaseConnector: BaseConnector{ConnectorID: "my-cloud", ProviderName: "test"}}
+	store := memory.NewConnectorPendingEventsRepository()
+	queue := NewRetryQueue(connector, store, RetryPolicy{
+		MaxAttempts:     maxAttempts,
+		InitialInterval: time.Minute,
+		MaxInterval:     3 * time.Minute,
+	}, logrus.NewEntry(logrus.StandardLogger()))
+
+	return connector, store, queue
+}
+
+func connectorCA() models.CACertificate {
+	ca := models.CACertificate{
+		ID:       "ca-1",
+		Metadata: map[string]any{models.CloudConnectorMetadataKey("my-cloud"): map[string]any{}},
+	}
+	ca.KeyMetadata = models.KeyStrengthMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256}
+
+	return ca
+}
+
+func pendingEvents(t *testing.T, queue *RetryQueue) []models.ConnectorPendingEvent {
+	events, err := queue.GetPendingEvents(context.Background())
+	if err != nil {
+		t.Fatalf("could not get pending events: %s", err)
+	}
+
+	return events
+}
+
+// makeDue moves the next attempt of the pending event to the past.
+func makeDue(t *testing.T, store storage.ConnectorPendingEventsRepo, pending models.ConnectorPendingEvent) {
+	pending.NextAttemptAt = time.Now().Add(-time.Second)
+	_, err := store.Update(context.Background(), &pending)
+	if err != nil {
+		t.Fatalf("could not update pending event: %s", err)
+	}
+}
+
+func TestRetryingEventHandlerEnqueuesFailedEvents(t *testing.T) {
+	connector, _, queue := retryQueueTest(t, 3)
+	handler := NewRetryingEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector, queue)
+
+	connector.On("RegisterCA", mock.Anything).Return(errors.New("provider unavailable"))
+
+	before := time.Now()
+	err := handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, connectorCA()))
+	assert.NoError(t, err)
+
+	events := pendingEvents(t, queue)
+	assert.Len(t, events, 1)
+	assert.Equal(t, "my-cloud", events[0].ConnectorID)
+	assert.Equal(t, models.ConnectorPendingEventRetrying, events[0].Status)
+	assert.Equal(t, 1, events[0].Attempts)
+	assert.Contains(t, events[0].LastError, "provider unavailable")
+	assert.Equal(t, string(models.EventCreateCAKey), events[0].Event.Type())
+	assert.False(t, events[0].NextAttemptAt.Before(before.Add(time.Minute)))
+}
+
+func TestRetryQueueBackoffAndDeadLetter(t *testing.T) {
+	connector, store, queue := retryQueueTest(t, 3)
+	connector.On("RegisterCA", mock.Anything).Return(errors.New("provider unavailable"))
+
+	handler := NewRetryingEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector, queue)
+	err := handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, connectorCA()))
+	assert.NoError(t, err)
+
+	// Events not due yet are not retried
+	queue.Run()
+	connector.AssertNumberOfCalls(t, "RegisterCA", 1)
+
+	makeDue(t, store, pendingEvents(t, queue)[0])
+	before := time.Now()
+	queue.Run()
+	connector.AssertNumberOfCalls(t, "RegisterCA", 2)
+
+	events := pendingEvents(t, queue)
+	assert.Equal(t, 2, events[0].Attempts)
+	assert.Equal(t, models.ConnectorPendingEventRetrying, events[0].Status)
+	assert.False(t, events[0].NextAttemptAt.Before(before.Add(2*time.Minute)))
+
+	makeDue(t, store, events[0])
+	queue.Run()
+	connector.AssertNumberOfCalls(t, "RegisterCA", 3)
+
+	events = pendingEvents(t, queue)
+	assert.Equal(t, 3, events[0].Attempts)
+	assert.Equal(t, models.ConnectorPendingEventDeadLetter, events[0].Status)
+
+	// Dead lettered events are not retried
+	makeDue(t, store, events[0])
+	queue.Run()
+	connector.AssertNumberOfCalls(t, "RegisterCA", 3)
+}
+
+func TestRetryQueueBackoffIsCapped(t *testing.T) {
+	_, _, queue := retryQueueTest(t, 10)
+
+	assert.Equal(t, time.Minute, queue.backoff(1))
+	assert.Equal(t, 2*time.Minute, queue.backoff(2))
+	assert.Equal(t, 3*time.Minute, queue.backoff(3))
+	assert.Equal(t, 3*time.Minute, queue.backoff(8))
+}
+
+func TestRetryQueueRemovesHandledEvents(t *testing.T) {
+	connector, store, queue := retryQueueTest(t, 3)
+	connector.On("RegisterCA", mock.Anything).Return(errors.New("provider unavailable")).Once()
+	connector.On("RegisterCA", mock.Anything).Return(nil)
+
+	handler := NewRetryingEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector, queue)
+	err := handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, connectorCA()))
+	assert.NoError(t, err)
+
+	makeDue(t, store, pendingEvents(t, queue)[0])
+	queue.Run()
+
+	connector.AssertNumberOfCalls(t, "RegisterCA", 2)
+	assert.Empty(t, pendingEvents(t, queue))
+}
+
+func TestRetryQueueReplay(t *testing.T) {
+	connector, _, queue := retryQueueTest(t, 1)
+	connector.On("RegisterCA", mock.Anything).Return(errors.New("provider unavailable")).Twice()
+	connector.On("RegisterCA", mock.Anything).Return(nil)
+
+	handler := NewRetryingEventHandler(logrus.NewEntry(logrus.StandardLogger()), connector, queue)
+	err := handler.HandleEvent(eventMessage(t, models.EventCreateCAKey, models.CASource, connectorCA()))
+	assert.NoError(t, err)
+
+	events := pendingEvents(t, queue)
+	assert.Equal(t, models.ConnectorPendingEventDeadLetter, events[0].Status)
+
+	pending, err := queue.Replay(context.Background(), events[0].ID)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, pending.Attempts)
+	assert.Equal(t, models.ConnectorPendingEventDeadLetter, pending.Status)
+
+	pending, err = queue.Replay(context.Background(), events[0].ID)
+	assert.NoError(t, err)
+	assert.Nil(t, pending)
+	assert.Empty(t, pendingEvents(t, queue))
+
+	_, err = queue.Replay(context.Background(), events[0].ID)
+	assert.ErrorIs(t, err, errs.ErrConnectorPendingEventNotFound)
+}
@@ -0,0 +1,55 @@
+package connectors
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// vaultCredentialStore reads the connector credentials from a Hashicorp Vault KV-V2 mount. The KV-V2 version of
+// the secret is the version of the credentials, so writing a new version rotates them.
+type vaultCredentialStore struct {
+	kvv2Client *api.KVv2
+}
+
+func NewVaultCredentialStore(logger *logrus.Entry, conf config.HashicorpVaultSDK) (CredentialStore, error) {
+	client, err := cryptoengines.NewVaultAPIClient(logger, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	return &vaultCredentialStore{
+		kvv2Client: client.KVv2(conf.MountPath),
+	}, nil
+}
+
+func (s *vaultCredentialStore) GetCredentials(ctx context.Context, reference string) (*models.ConnectorCredentials, error) {
+	secret, err := s.kvv2Client.Get(ctx, reference)
+	if err != nil {
+		return nil, err
+	}
+
+	values := map[string]string{}
+	for key, value := range secret.Data {
+		str, ok := value.(string)
+		if !ok {
+			return nil, fmt.Errorf("credential %s is not a string", key)
+		}
+		values[key] = str
+	}
+
+	version := 0
+	if secret.VersionMetadata != nil {
+		version = secret.VersionMetadata.Version
+	}
+
+	return &models.ConnectorCredentials{
+		Version: version,
+		Values:  values,
+	}, nil
+}
@@ -0,0 +1,611 @@
+package controllers
+
+import (
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	acmeProblemContentType = "application/problem+json"
+	acmeMaxRequestSize     = 64 * 1024
+)
+
+type acmeHttpRoutes struct {
+	svc         services.ACMEService
+	externalURL string
+	logger      *logrus.Entry
+}
+
+// NewACMEHttpRoutes serves the ACME directories of the DMSs. The resource URLs are built from externalURL, the URL
+// the DMS Manager is reachable at by the ACME clients, or from the request host if it is empty.
+func NewACMEHttpRoutes(logger *logrus.Entry, svc services.ACMEService, externalURL string) *acmeHttpRoutes {
+	return &acmeHttpRoutes{
+		svc:         svc,
+		externalURL: strings.TrimSuffix(externalURL, "/"),
+		logger:      logger,
+	}
+}
+
+type acmeDMSParams struct {
+	DMS string `uri:"dms" binding:"required"`
+}
+
+// acmeProblems maps the ACME errors to the RFC 8555 6.7 problem types.
+var acmeProblems = []struct {
+	err        error
+	problem    string
+	statusCode int
+}{
+	{errs.ErrACMEMalformed, "malformed", 400},
+	{errs.ErrValidateBadRequest, "malformed", 400},
+	{errs.ErrACMEBadNonce, "badNonce", 400},
+	{errs.ErrACMEBadSignatureAlgorithm, "badSignatureAlgorithm", 400},
+	{errs.ErrACMEUnauthorized, "unauthorized", 403},
+	{errs.ErrACMEAccountDoesNotExist, "accountDoesNotExist", 400},
+	{errs.ErrACMEExternalAccountRequired, "externalAccountRequired", 400},
+	{errs.ErrACMEOrderNotReady, "orderNotReady", 403},
+	{errs.ErrACMEOrderNotFound, "malformed", 404},
+	{errs.ErrACMEBadCSR, "badCSR", 400},
+	{errs.ErrACMERejectedIdentifier, "rejectedIdentifier", 400},
+	{errs.ErrACMEUnsupportedIdentifier, "unsupportedIdentifier", 400},
+	{errs.ErrACMEIncorrectResponse, "incorrectResponse", 403},
+	{errs.ErrACMEAlreadyRevoked, "alreadyRevoked", 400},
+	{errs.ErrDMSNotFound, "malformed", 404},
+	{errs.ErrDMSACMENotEnabled, "malformed", 404},
+	{errs.ErrDMSPendingApproval, "unauthorized", 403},
+}
+
+func acmeProblem(err error) (*resources.ACMEProblem, int) {
+	for _, p := range acmeProblems {
+		if errors.Is(err, p.err) {
+			return &resources.ACMEProblem{
+				Type:   "urn:ietf:params:acme:error:" + p.problem,
+				Detail: err.Error(),
+				Status: p.statusCode,
+			}, p.statusCode
+		}
+	}
+
+	return &resources.ACMEProblem{
+		Type:   "urn:ietf:params:acme:error:serverInternal",
+		Detail: err.Error(),
+		Status: 500,
+	}, 500
+}
+
+// acmeStoredProblem returns the problem of an error stored in an order or a challenge.
+func acmeStoredProblem(detail string) *resources.ACMEProblem {
+	if detail == "" {
+		return nil
+	}
+
+	for _, p := range acmeProblems {
+		if strings.HasPrefix(detail, p.err.Error()) {
+			return &resources.ACMEProblem{Type: "urn:ietf:params:acme:error:" + p.problem, Detail: detail, Status: p.statusCode}
+		}
+	}
+
+	return &resources.ACMEProblem{Type: "urn:ietf:params:acme:error:malformed", Detail: detail, Status: 400}
+}
+
+// directoryURL returns the URL of the ACME directory of the DMS.
+func (r *acmeHttpRoutes) directoryURL(ctx *gin.Context, dmsID string) string {
+	base := r.externalURL
+	if base == "" {
+		scheme := "http"
+		if ctx.Request.TLS != nil {
+			scheme = "https"
+		}
+		if proto := ctx.GetHeader("X-Forwarded-Proto"); proto != "" {
+			scheme = proto
+		}
+		base = fmt.Sprintf("%s://%s", scheme, ctx.Request.Host)
+	}
+
+	return fmt.Sprintf("%s/v1/acme/%s", base, dmsID)
+}
+
+// requestURL returns the URL the request was sent to, as the client sees it.
+func (r *acmeHttpRoutes) requestURL(ctx *gin.Context, dmsID string) string {
+	prefix := "/v1/acme/" + dmsID
+	path := ctx.Request.URL.Path
+	if idx := strings.Index(path, prefix); idx >= 0 {
+		path = path[idx+len(prefix):]
+	}
+
+	return r.directoryURL(ctx, dmsID) + path
+}
+
+// writeHeaders sets the headers included in every ACME response: a fresh nonce and the directory link.
+func (r *acmeHttpRoutes) writeHeaders(ctx *gin.Context, dmsID string) {
+	nonce, err := r.svc.NewNonce(ctx)
+	if err == nil {
+		ctx.Header("Replay-Nonce", nonce)
+	}
+
+	ctx.Header("Cache-Control", "no-store")
+	ctx.Header("Link", fmt.Sprintf("<%s/directory>;rel=\"index\"", r.directoryURL(ctx, dmsID)))
+}
+
+func (r *acmeHttpRoutes) writeError(ctx *gin.Context, dmsID string, err error) {
+	r.logger.Warnf("ACME request %s %s failed: %s", ctx.Request.Method, ctx.Request.URL.Path, err)
+
+	problem, statusCode := acmeProblem(err)
+	r.writeHeaders(ctx, dmsID)
+	ctx.Header("Content-Type", acmeProblemContentType)
+	ctx.JSON(statusCode, problem)
+}
+
+// authenticate reads and verifies the JWS body of the request.
+func (r *acmeHttpRoutes) authenticate(ctx *gin.Context, dmsID string, allowEmbeddedKey bool) (*services.ACMERequest, bool) {
+	body, err := io.ReadAll(io.LimitReader(ctx.Request.Body, acmeMaxRequestSize))
+	if err != nil {
+		r.writeError(ctx, dmsID, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return nil, false
+	}
+
+	request, err := r.svc.AuthenticateRequest(ctx, services.AuthenticateACMERequestInput{
+		DMSID:            dmsID,
+		URL:              r.requestURL(ctx, dmsID),
+		AccountURLPrefix: r.directoryURL(ctx, dmsID) + "/account/",
+		Body:             body,
+		AllowEmbeddedKey: allowEmbeddedKey,
+	})
+	if err != nil {
+		r.writeError(ctx, dmsID, err)
+		return nil, false
+	}
+
+	return request, true
+}
+
+func (r *acmeHttpRoutes) GetDirectory(ctx *gin.Context) {
+	var params acmeDMSParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	meta, err := r.svc.GetDirectoryMeta(ctx, services.GetACMEDirectoryMetaInput{
+		DMSID: params.DMS,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	dirURL := r.directoryURL(ctx, params.DMS)
+	ctx.JSON(200, resources.ACMEDirectory{
+		NewNonce:   dirURL + "/new-nonce",
+		NewAccount: dirURL + "/new-account",
+		NewOrder:   dirURL + "/new-order",
+		RevokeCert: dirURL + "/revoke-cert",
+		Meta: resources.ACMEDirectoryMeta{
+			ExternalAccountRequired: meta.ExternalAccountRequired,
+		},
+	})
+}
+
+func (r *acmeHttpRoutes) NewNonce(ctx *gin.Context) {
+	var params acmeDMSParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	if ctx.Request.Method == "HEAD" {
+		ctx.Status(200)
+		return
+	}
+
+	ctx.Status(204)
+}
+
+func (r *acmeHttpRoutes) NewAccount(ctx *gin.Context) {
+	var params acmeDMSParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, true)
+	if !ok {
+		return
+	}
+
+	account, created, err := r.svc.NewAccount(ctx, services.NewACMEAccountInput{
+		Request: request,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	statusCode := 200
+	if created {
+		statusCode = 201
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.Header("Location", r.accountURL(ctx, params.DMS, account.ID))
+	ctx.JSON(statusCode, r.accountResponse(ctx, params.DMS, account))
+}
+
+func (r *acmeHttpRoutes) UpdateAccount(ctx *gin.Context) {
+	type uriParams struct {
+		acmeDMSParams
+		Account string `uri:"account" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	account, err := r.svc.UpdateAccount(ctx, services.UpdateACMEAccountInput{
+		Request:   request,
+		AccountID: params.Account,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.JSON(200, r.accountResponse(ctx, params.DMS, account))
+}
+
+func (r *acmeHttpRoutes) GetAccountOrders(ctx *gin.Context) {
+	type uriParams struct {
+		acmeDMSParams
+		Account string `uri:"account" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	orders, err := r.svc.GetAccountOrders(ctx, services.GetACMEAccountOrdersInput{
+		Request:   request,
+		AccountID: params.Account,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	orderURLs := []string{}
+	for _, order := range orders {
+		orderURLs = append(orderURLs, r.orderURL(ctx, params.DMS, order))
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.JSON(200, resources.ACMEAccountOrdersResponse{Orders: orderURLs})
+}
+
+func (r *acmeHttpRoutes) NewOrder(ctx *gin.Context) {
+	var params acmeDMSParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	order, err := r.svc.NewOrder(ctx, services.NewACMEOrderInput{
+		Request: request,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.Header("Location", r.orderURL(ctx, params.DMS, order.ID))
+	ctx.JSON(201, r.orderResponse(ctx, params.DMS, order))
+}
+
+type acmeOrderParams struct {
+	acmeDMSParams
+	Order string `uri:"order" binding:"required"`
+}
+
+func (r *acmeHttpRoutes) GetOrder(ctx *gin.Context) {
+	var params acmeOrderParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	order, err := r.svc.GetOrder(ctx, services.GetACMEOrderInput{
+		Request: request,
+		OrderID: params.Order,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.JSON(200, r.orderResponse(ctx, params.DMS, order))
+}
+
+func (r *acmeHttpRoutes) FinalizeOrder(ctx *gin.Context) {
+	var params acmeOrderParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	order, err := r.svc.FinalizeOrder(ctx, services.FinalizeACMEOrderInput{
+		Request: request,
+		OrderID: params.Order,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.Header("Location", r.orderURL(ctx, params.DMS, order.ID))
+	ctx.JSON(200, r.orderResponse(ctx, params.DMS, order))
+}
+
+func (r *acmeHttpRoutes) GetCertificate(ctx *gin.Context) {
+	var params acmeOrderParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	chain, err := r.svc.GetCertificateChain(ctx, services.GetACMEOrderInput{
+		Request: request,
+		OrderID: params.Order,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	body := []byte{}
+	for _, crt := range chain {
+		body = append(body, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: crt.Raw})...)
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.Data(200, "application/pem-certificate-chain", body)
+}
+
+type acmeAuthorizationParams struct {
+	acmeOrderParams
+	Index int `uri:"index"`
+}
+
+func (r *acmeHttpRoutes) GetAuthorization(ctx *gin.Context) {
+	var params acmeAuthorizationParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	authz, err := r.svc.GetAuthorization(ctx, services.GetACMEAuthorizationInput{
+		Request: request,
+		OrderID: params.Order,
+		Index:   params.Index,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	order, err := r.svc.GetOrder(ctx, services.GetACMEOrderInput{
+		Request: request,
+		OrderID: params.Order,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.JSON(200, r.authorizationResponse(ctx, params.DMS, order, params.Index, authz))
+}
+
+// RespondChallenge triggers the validation of the challenge. POST-as-GET requests (empty payload) return the
+// challenge without triggering it.
+func (r *acmeHttpRoutes) RespondChallenge(ctx *gin.Context) {
+	type uriParams struct {
+		acmeAuthorizationParams
+		Type models.ACMEChallengeType `uri:"type" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, false)
+	if !ok {
+		return
+	}
+
+	var challenge *models.ACMEChallenge
+	var err error
+	if len(request.Payload) == 0 {
+		var authz *models.ACMEAuthorization
+		authz, err = r.svc.GetAuthorization(ctx, services.GetACMEAuthorizationInput{
+			Request: request,
+			OrderID: params.Order,
+			Index:   params.Index,
+		})
+		if err == nil {
+			err = errs.ErrACMEOrderNotFound
+			for i := range authz.Challenges {
+				if authz.Challenges[i].Type == params.Type {
+					challenge, err = &authz.Challenges[i], nil
+				}
+			}
+		}
+	} else {
+		challenge, err = r.svc.RespondChallenge(ctx, services.RespondACMEChallengeInput{
+			Request: request,
+			OrderID: params.Order,
+			Index:   params.Index,
+			Type:    params.Type,
+		})
+	}
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	authzURL := r.authorizationURL(ctx, params.DMS, params.Order, params.Index)
+	r.writeHeaders(ctx, params.DMS)
+	ctx.Writer.Header().Add("Link", fmt.Sprintf("<%s>;rel=\"up\"", authzURL))
+	ctx.JSON(200, r.challengeResponse(authzURL, challenge))
+}
+
+func (r *acmeHttpRoutes) RevokeCertificate(ctx *gin.Context) {
+	var params acmeDMSParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		r.writeError(ctx, "", fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err))
+		return
+	}
+
+	request, ok := r.authenticate(ctx, params.DMS, true)
+	if !ok {
+		return
+	}
+
+	err := r.svc.RevokeCertificate(ctx, services.RevokeACMECertificateInput{
+		Request: request,
+	})
+	if err != nil {
+		r.writeError(ctx, params.DMS, err)
+		return
+	}
+
+	r.writeHeaders(ctx, params.DMS)
+	ctx.Status(200)
+}
+
+func (r *acmeHttpRoutes) accountURL(ctx *gin.Context, dmsID, accountID string) string {
+	return fmt.Sprintf("%s/account/%s", r.directoryURL(ctx, dmsID), accountID)
+}
+
+func (r *acmeHttpRoutes) orderURL(ctx *gin.Context, dmsID, orderID string) string {
+	return fmt.Sprintf("%s/order/%s", r.directoryURL(ctx, dmsID), orderID)
+}
+
+func (r *acmeHttpRoutes) authorizationURL(ctx *gin.Context, dmsID, orderID string, index int) string {
+	return fmt.Sprintf("%s/authz/%s/%s", r.directoryURL(ctx, dmsID), orderID, strconv.Itoa(index))
+}
+
+func (r *acmeHttpRoutes) accountResponse(ctx *gin.Context, dmsID string, account *models.ACMEAccount) resources.ACMEAccountResponse {
+	return resources.ACMEAccountResponse{
+		Status:  account.Status,
+		Contact: account.Contact,
+		Orders:  r.accountURL(ctx, dmsID, account.ID) + "/orders",
+	}
+}
+
+func (r *acmeHttpRoutes) orderResponse(ctx *gin.Context, dmsID string, order *models.ACMEOrder) resources.ACMEOrderResponse {
+	orderURL := r.orderURL(ctx, dmsID, order.ID)
+
+	resp := resources.ACMEOrderResponse{
+		Status:         order.Status,
+		Expires:        order.Expires,
+		Identifiers:    order.Identifiers,
+		Authorizations: []string{},
+		Finalize:       orderURL + "/finalize",
+		Error:          acmeStoredProblem(order.Error),
+	}
+
+	for i := range order.Authorizations {
+		resp.Authorizations = append(resp.Authorizations, r.authorizationURL(ctx, dmsID, order.ID, i))
+	}
+
+	if order.Status == models.ACMEStatusValid {
+		resp.Certificate = orderURL + "/cert"
+	}
+
+	return resp
+}
+
+func (r *acmeHttpRoutes) authorizationResponse(ctx *gin.Context, dmsID string, order *models.ACMEOrder, index int, authz *models.ACMEAuthorization) resources.ACMEAuthorizationResponse {
+	authzURL := r.authorizationURL(ctx, dmsID, order.ID, index)
+
+	resp := resources.ACMEAuthorizationResponse{
+		Identifier: authz.Identifier,
+		Status:     authz.Status,
+		Expires:    order.Expires,
+		Wildcard:   authz.Wildcard,
+		Challenges: []resources.ACMEChallengeResponse{},
+	}
+
+	for i := range authz.Challenges {
+		resp.Challenges = append(resp.Challenges, r.challengeResponse(authzURL, &authz.Challenges[i]))
+	}
+
+	return resp
+}
+
+func (r *acmeHttpRoutes) challengeResponse(authzURL string, challenge *models.ACMEChallenge) resources.ACMEChallengeResponse {
+	return resources.ACMEChallengeResponse{
+		Type:      challenge.Type,
+		URL:       fmt.Sprintf("%s/%s", authzURL, challenge.Type),
+		Token:     challenge.Token,
+		Status:    challenge.Status,
+		Validated: challenge.Validated,
+		Error:     acmeStoredProblem(challenge.Error),
+	}
+}
@@ -1,25 +1,37 @@
 package controllers
 
 import (
+	"archive/tar"
+	"bytes"
+	"compress/gzip"
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"path"
+	"strings"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 )
 
 type caHttpRoutes struct {
-	svc services.CAService
+	svc       services.CAService
+	csrLimits config.CSRLimits
 }
 
-func NewCAHttpRoutes(svc services.CAService) *caHttpRoutes {
+func NewCAHttpRoutes(svc services.CAService, csrLimits config.CSRLimits) *caHttpRoutes {
 	return &caHttpRoutes{
-		svc: svc,
+		svc:       svc,
+		csrLimits: csrLimits,
 	}
 }
 
@@ -44,7 +56,9 @@ func (r *caHttpRoutes) GetCryptoEngineProvider(ctx *gin.Context) {
 // @Security OAuth2Password
 // @Param message body resources.CreateCABody true "CA Info"
 // @Success 201 {object} models.CACertificate
-// @Failure 400 {string} string "Struct Validation error || CA type inconsistent || Issuance expiration greater than CA expiration || Incompatible expiration time ref"
+// @Failure 400 {string} string "Struct Validation error || CA type inconsistent || Issuance expiration greater than CA expiration || Incompatible expiration time ref || Parent CA not active"
+// @Failure 404 {string} string "Parent CA not found"
+// @Failure 409 {string} string "CA already exists"
 // @Failure 500
 // @Router /cas [post]
 func (r *caHttpRoutes) CreateCA(ctx *gin.Context) {
@@ -63,6 +77,7 @@ func (r *caHttpRoutes) CreateCA(ctx *gin.Context) {
 		IssuanceExpiration: requestBody.IssuanceExpiration,
 		EngineID:           requestBody.EngineID,
 		Metadata:           requestBody.Metadata,
+		HybridKeyMetadata:  requestBody.HybridKeyMetadata,
 	})
 	if err != nil {
 		switch err {
@@ -74,6 +89,10 @@ func (r *caHttpRoutes) CreateCA(ctx *gin.Context) {
 			ctx.JSON(400, gin.H{"err": err.Error()})
 		case errs.ErrCAIncompatibleExpirationTimeRef:
 			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
 		case errs.ErrCAAlreadyExists:
 			ctx.JSON(409, gin.H{"err": err.Error()})
 		default:
@@ -98,6 +117,120 @@ func (r *caHttpRoutes) GetStats(ctx *gin.Context) {
 	ctx.JSON(200, stats)
 }
 
+func (r *caHttpRoutes) GetKeyStrengthReport(ctx *gin.Context) {
+	report, err := r.svc.GetKeyStrengthReport(ctx)
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, report)
+}
+
+// ExportCAInventory returns the inventory as a JSON document, or as a gzipped tarball with the format=tar query
+// parameter. The tarball holds the JSON document and the PEM certificate and chain of each CA.
+func (r *caHttpRoutes) ExportCAInventory(ctx *gin.Context) {
+	format := ctx.DefaultQuery("format", "json")
+	if format != "json" && format != "tar" {
+		ctx.JSON(400, gin.H{"err": fmt.Sprintf("unsupported export format '%s'", format)})
+		return
+	}
+
+	inventory, err := r.svc.ExportCAInventory(ctx)
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	if format == "json" {
+		ctx.JSON(200, inventory)
+		return
+	}
+
+	var buf bytes.Buffer
+	err = writeCAInventoryTarball(&buf, inventory)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	filename := fmt.Sprintf("ca-inventory-%s.tar.gz", inventory.GeneratedAt.UTC().Format("20060102T150405Z"))
+	ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=%q", filename))
+	ctx.Data(200, "application/gzip", buf.Bytes())
+}
+
+func writeCAInventoryTarball(w io.Writer, inventory *models.CAInventory) error {
+	gzw := gzip.NewWriter(w)
+	tw := tar.NewWriter(gzw)
+
+	addFile := func(name string, content []byte) error {
+		err := tw.WriteHeader(&tar.Header{
+			Name:    name,
+			Mode:    0644,
+			Size:    int64(len(content)),
+			ModTime: inventory.GeneratedAt,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = tw.Write(content)
+		return err
+	}
+
+	inventoryJSON, err := json.MarshalIndent(inventory, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	err = addFile("inventory.json", inventoryJSON)
+	if err != nil {
+		return err
+	}
+
+	for _, ca := range inventory.CAs {
+		dir := path.Join("cas", strings.ReplaceAll(ca.ID, "/", "_"))
+		err = addFile(path.Join(dir, "certificate.pem"), []byte(ca.CertificatePEM))
+		if err != nil {
+			return err
+		}
+
+		err = addFile(path.Join(dir, "chain.pem"), []byte(strings.Join(ca.ChainPEMs, "")))
+		if err != nil {
+			return err
+		}
+	}
+
+	err = tw.Close()
+	if err != nil {
+		return err
+	}
+
+	return gzw.Close()
+}
+
+func (r *caHttpRoutes) GetSoftwareKeyCustodyReport(ctx *gin.Context) {
+	report, err := r.svc.GetSoftwareKeyCustodyReport(ctx)
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, report)
+}
+
 func (r *caHttpRoutes) GetStatsByCAID(ctx *gin.Context) {
 	type uriParams struct {
 		ID string `uri:"id" binding:"required"`
@@ -133,6 +266,8 @@ func (r *caHttpRoutes) GetStatsByCAID(ctx *gin.Context) {
 // @Param message body resources.ImportCABody true "CA Info"
 // @Success 201 {object} models.CACertificate
 // @Failure 400 {string} string "Struct Validation error || CA type inconsistent || Issuance expiration greater than CA expiration || Incompatible expiration time ref || CA and the provided key dont match"
+// @Failure 404 {string} string "Parent CA not found || Crypto engine not found"
+// @Failure 409 {string} string "CA already exists"
 // @Failure 500
 // @Router /cas/import [post]
 func (r *caHttpRoutes) ImportCA(ctx *gin.Context) {
@@ -157,6 +292,32 @@ func (r *caHttpRoutes) ImportCA(ctx *gin.Context) {
 		}
 	}
 
+	caCertificate := requestBody.CACertificate
+	caChain := requestBody.CAChain
+	if requestBody.Bundle != "" {
+		decodedBundle, err := base64.StdEncoding.DecodeString(requestBody.Bundle)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+
+		certs, bundleKey, err := helpers.ParsePEMBundle(decodedBundle)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+
+		caCertificate = (*models.X509Certificate)(certs[0])
+		caChain = []*models.X509Certificate{}
+		for _, cert := range certs[1:] {
+			caChain = append(caChain, (*models.X509Certificate)(cert))
+		}
+
+		if bundleKey != nil {
+			key = bundleKey
+		}
+	}
+
 	var keyType models.KeyType
 	var rsaKey *rsa.PrivateKey
 	var ecKey *ecdsa.PrivateKey
@@ -172,7 +333,8 @@ func (r *caHttpRoutes) ImportCA(ctx *gin.Context) {
 		ID:                 requestBody.ID,
 		IssuanceExpiration: requestBody.IssuanceExpiration,
 		CAType:             requestBody.CAType,
-		CACertificate:      requestBody.CACertificate,
+		CACertificate:      caCertificate,
+		CAChain:            caChain,
 		KeyType:            keyType,
 		CARSAKey:           rsaKey,
 		CAECKey:            ecKey,
@@ -191,6 +353,10 @@ func (r *caHttpRoutes) ImportCA(ctx *gin.Context) {
 			ctx.JSON(400, gin.H{"err": err.Error()})
 		case errs.ErrCAValidCertAndPrivKey:
 			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound, errs.ErrCryptoEngineNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCAAlreadyExists:
+			ctx.JSON(409, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -206,9 +372,11 @@ func (r *caHttpRoutes) ImportCA(ctx *gin.Context) {
 // @Produce json
 // @Security OAuth2Password
 // @Param message body resources.UpdateCAMetadataBody true "Update CA Metadata Info"
+// @Param If-Match header string false "ETag of the CA the update is based on"
 // @Success 200 {object} models.CACertificate
 // @Failure 404 {string} string "CA not found"
 // @Failure 400 {string} string "Struct Validation error"
+// @Failure 412 {string} string "CA has been modified"
 // @Failure 500
 // @Router /cas/{id}/metadata [put]
 func (r *caHttpRoutes) UpdateCAMetadata(ctx *gin.Context) {
@@ -231,6 +399,7 @@ func (r *caHttpRoutes) UpdateCAMetadata(ctx *gin.Context) {
 	ca, err := r.svc.UpdateCAMetadata(ctx, services.UpdateCAMetadataInput{
 		CAID:     params.ID,
 		Metadata: requestBody.Metadata,
+		IfMatch:  ctx.GetHeader("If-Match"),
 	})
 	if err != nil {
 		switch err {
@@ -238,20 +407,41 @@ func (r *caHttpRoutes) UpdateCAMetadata(ctx *gin.Context) {
 			ctx.JSON(404, gin.H{"err": err.Error()})
 		case errs.ErrValidateBadRequest:
 			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrPreconditionFailed:
+			ctx.JSON(412, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
 
 		return
 	}
+
+	ctx.Header("ETag", helpers.ETag(ca))
 	ctx.JSON(200, ca)
 }
 
-func (r *caHttpRoutes) GetCAsByCommonName(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.CAFiltrableFields)
+// @Summary Update CA Settings
+// @Description Update the issuance expiration, expiration monitoring thresholds and metadata of a CA
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param message body resources.UpdateCASettingsBody true "Update CA Settings Info"
+// @Param If-Match header string false "ETag of the CA the update is based on"
+// @Success 200 {object} models.CACertificate
+// @Failure 404 {string} string "CA not found"
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 412 {string} string "CA has been modified"
+// @Failure 500
+// @Router /cas/{id}/settings [put]
+func (r *caHttpRoutes) UpdateCASettings(ctx *gin.Context) {
+	var requestBody resources.UpdateCASettingsBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
 	type uriParams struct {
-		CommonName string `uri:"cn" binding:"required"`
+		ID string `uri:"id" binding:"required"`
 	}
 
 	var params uriParams
@@ -260,19 +450,86 @@ func (r *caHttpRoutes) GetCAsByCommonName(ctx *gin.Context) {
 		return
 	}
 
-	cas := []models.CACertificate{}
+	ca, err := r.svc.UpdateCASettings(ctx, services.UpdateCASettingsInput{
+		CAID:               params.ID,
+		IssuanceExpiration: requestBody.IssuanceExpiration,
+		ExpirationDeltas:   requestBody.ExpirationDeltas,
+		Metadata:           requestBody.Metadata,
+		IfMatch:            ctx.GetHeader("If-Match"),
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCAStatus, errs.ErrCAIssuanceExpiration:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrPreconditionFailed:
+			ctx.JSON(412, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
 
-	nextBookmark, err := r.svc.GetCAsByCommonName(ctx, services.GetCAsByCommonNameInput{
-		CommonName:      params.CommonName,
-		QueryParameters: queryParams,
-		ExhaustiveRun:   false,
-		ApplyFunc: func(ca models.CACertificate) {
-			cas = append(cas, ca)
-		},
+		return
+	}
+
+	ctx.Header("ETag", helpers.ETag(ca))
+	ctx.JSON(200, ca)
+}
+
+func (r *caHttpRoutes) GetPendingCAAction(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	action, err := r.svc.GetPendingCAAction(ctx, services.GetPendingCAActionInput{
+		CAID: params.ID,
 	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound, errs.ErrCAPendingActionNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, action)
+}
+
+func (r *caHttpRoutes) ApprovePendingCAAction(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
+	action, err := r.svc.ApprovePendingCAAction(ctx, services.ApprovePendingCAActionInput{
+		CAID: params.ID,
+	})
 	if err != nil {
 		switch err {
+		case errs.ErrCANotFound, errs.ErrCAPendingActionNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAPendingActionSelfApproval, errs.ErrCAApprovalUnverifiedIdentity:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCAPendingActionExpired, errs.ErrCAKeyInUse:
+			ctx.JSON(409, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -280,37 +537,1060 @@ func (r *caHttpRoutes) GetCAsByCommonName(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, resources.GetCAsResponse{
-		IterableList: resources.IterableList[models.CACertificate]{
-			NextBookmark: nextBookmark,
-			List:         cas,
-		},
+	ctx.JSON(200, action)
+}
+
+func (r *caHttpRoutes) MigrateCAKey(ctx *gin.Context) {
+	var requestBody resources.MigrateCAKeyBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.MigrateCAKey(ctx, services.MigrateCAKeyInput{
+		CAID:            params.ID,
+		TargetEngineID:  requestBody.TargetEngineID,
+		RemoveSourceKey: requestBody.RemoveSourceKey,
 	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound, errs.ErrCryptoEngineNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCAType, errs.ErrCAKeyNotExportable:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASigningApprovalRequired:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCAKeyMigrationConflict:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+	ctx.JSON(200, ca)
 }
 
-// @Summary Get All CAs
-// @Description Get All CAs
-// @Accept json
-// @Produce json
-// @Security OAuth2Password
-// @Success 200 {array} models.CACertificate
-// @Failure 500
-// @Router /cas [get]
-func (r *caHttpRoutes) GetAllCAs(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.CAFiltrableFields)
+func (r *caHttpRoutes) SetCAFallbackEngine(ctx *gin.Context) {
+	var requestBody resources.SetCAFallbackEngineBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
-	cas := []models.CACertificate{}
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
 
-	nextBookmark, err := r.svc.GetCAs(ctx, services.GetCAsInput{
-		QueryParameters: queryParams,
-		ExhaustiveRun:   false,
-		ApplyFunc: func(ca models.CACertificate) {
-			cas = append(cas, ca)
-		},
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.SetCAFallbackEngine(ctx, services.SetCAFallbackEngineInput{
+		CAID:     params.ID,
+		EngineID: requestBody.EngineID,
 	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound, errs.ErrCryptoEngineNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCAType, errs.ErrCAKeyNotExportable:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASigningApprovalRequired:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+	ctx.JSON(200, ca)
+}
+
+func (r *caHttpRoutes) SetCAIssuanceWebhook(ctx *gin.Context) {
+	var requestBody resources.SetCAIssuanceWebhookBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.SetCAIssuanceWebhook(ctx, services.SetCAIssuanceWebhookInput{
+		CAID:    params.ID,
+		Webhook: requestBody.Webhook,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+	ctx.JSON(200, ca)
+}
+
+func (r *caHttpRoutes) ExportCAKey(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	backup, err := r.svc.ExportCAKey(ctx, services.ExportCAKeyInput{
+		CAID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound, errs.ErrCryptoEngineNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCAType, errs.ErrCAKeyNotExportable:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASigningApprovalRequired:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+	ctx.JSON(200, backup)
+}
+
+func (r *caHttpRoutes) RestoreCAKey(ctx *gin.Context) {
+	var requestBody resources.RestoreCAKeyBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.RestoreCAKey(ctx, services.RestoreCAKeyInput{
+		CAID:   params.ID,
+		Backup: requestBody.Backup,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound, errs.ErrCryptoEngineNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCAType, errs.ErrCAKeyNotExportable, errs.ErrCAKeyBackupInvalid:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+	ctx.JSON(200, ca)
+}
+
+func (r *caHttpRoutes) GetCAsByCommonName(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.CAFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	type uriParams struct {
+		CommonName string `uri:"cn" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	cas := []models.CACertificate{}
+
+	nextBookmark, err := r.svc.GetCAsByCommonName(ctx, services.GetCAsByCommonNameInput{
+		CommonName:      params.CommonName,
+		QueryParameters: queryParams,
+		ExhaustiveRun:   false,
+		ApplyFunc: func(ca models.CACertificate) {
+			cas = append(cas, ca)
+		},
+	})
+
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetCAsResponse{
+		IterableList: resources.IterableList[models.CACertificate]{
+			NextBookmark: nextBookmark,
+			List:         cas,
+		},
+	})
+}
+
+// @Summary Get All CAs
+// @Description Get All CAs
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {array} models.CACertificate
+// @Failure 500
+// @Router /cas [get]
+func (r *caHttpRoutes) GetAllCAs(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.CAFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	cas := []models.CACertificate{}
+
+	nextBookmark, err := r.svc.GetCAs(ctx, services.GetCAsInput{
+		QueryParameters: queryParams,
+		ExhaustiveRun:   false,
+		ApplyFunc: func(ca models.CACertificate) {
+			cas = append(cas, ca)
+		},
+	})
+
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetCAsResponse{
+		IterableList: resources.IterableList[models.CACertificate]{
+			NextBookmark: nextBookmark,
+			List:         cas,
+		},
+	})
+}
+
+// @Summary Get CA By ID
+// @Description Get CA By ID
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} models.CACertificate
+// @Failure 404 {string} string "CA not found"
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 500
+// @Router /cas/{id} [get]
+func (r *caHttpRoutes) GetCAByID(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.GetCAByID(ctx, services.GetCAByIDInput{
+		CAID: params.ID,
+	})
+
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.Header("ETag", helpers.ETag(ca))
+	ctx.JSON(200, ca)
+}
+
+// @Summary Delete CA
+// @Description Delete CA. The CA is moved to the DELETING status and removed in the background
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param force query bool false "Revoke the active certificates of the CA instead of refusing the deletion"
+// @Success 201
+// @Failure 404 {string} string "CA not found"
+// @Failure 400 {string} string "Struct Validation error || CA Status inconsistent"
+// @Failure 409 {string} string "CA has active certificates || CA is already being deleted"
+// @Failure 500
+// @Router /cas/{id} [delete]
+func (r *caHttpRoutes) DeleteCA(ctx *gin.Context) {
+
+	type uriParams struct {
+		CAId string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	type queryParams struct {
+		Force bool `form:"force"`
+	}
+
+	var query queryParams
+	if err := ctx.ShouldBindQuery(&query); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	err := r.svc.DeleteCA(ctx, services.DeleteCAInput{
+		CAID:  params.CAId,
+		Force: query.Force,
+	})
+
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAHasActiveCertificates, errs.ErrCADeletionInProgress:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		case errs.ErrCAActionPendingApproval:
+			ctx.JSON(202, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, gin.H{})
+}
+
+func (r *caHttpRoutes) UpdateCAStatus(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.UpdateCertificateStatusBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.UpdateCAStatus(ctx, services.UpdateCAStatusInput{
+		CAID:             params.ID,
+		Status:           requestBody.NewStatus,
+		RevocationReason: requestBody.RevocationReason,
+		IfMatch:          ctx.GetHeader("If-Match"),
+	})
+
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCAAlreadyRevoked:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrPreconditionFailed:
+			ctx.JSON(412, gin.H{"err": err.Error()})
+		case errs.ErrCAActionPendingApproval:
+			ctx.JSON(202, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.Header("ETag", helpers.ETag(ca))
+	ctx.JSON(201, ca)
+}
+
+// @Summary Get Certificate by Serial Number
+// @Description Get Certificate by Serial Number
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} models.Certificate
+// @Failure 404 {string} string "Certificate not found"
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 500
+// @Router /cas/{id}/certificates/{sn} [get]
+func (r *caHttpRoutes) GetCertificateBySerialNumber(ctx *gin.Context) {
+	type uriParams struct {
+		SerialNumber string `uri:"sn" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	cert, err := r.svc.GetCertificateBySerialNumber(ctx, services.GetCertificatesBySerialNumberInput{
+		SerialNumber: params.SerialNumber,
+	})
+
+	if err != nil {
+		switch err {
+		case errs.ErrCertificateNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+	ctx.JSON(200, cert)
+}
+
+// @Summary Get Certificates
+// @Description Update CA Metadata
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param message body resources.UpdateCAMetadataBody true "Update CA Metadata Info"
+// @Success 200 {array} models.Certificate
+// @Failure 500
+// @Router /certificates [get]
+func (r *caHttpRoutes) GetCertificates(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	certs := []models.Certificate{}
+
+	nextBookmark, err := r.svc.GetCertificates(ctx, services.GetCertificatesInput{
+		ListInput: resources.ListInput[models.Certificate]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(cert models.Certificate) {
+				certs = append(certs, cert)
+			},
+		},
+	})
+
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetCertsResponse{
+		IterableList: resources.IterableList[models.Certificate]{
+			NextBookmark: nextBookmark,
+			List:         certs,
+		},
+	})
+}
+
+// @Summary Export Certificates
+// @Description Streams every certificate matching the filters as newline delimited JSON, in ascending valid_from order. The valid_from of the last certificate is the "issued_after" cursor of the next export
+// @Produce application/x-ndjson
+// @Security OAuth2Password
+// @Param issued_after query string false "Export cursor"
+// @Success 200 {array} models.Certificate
+// @Failure 500
+// @Router /certificates/export [get]
+func (r *caHttpRoutes) ExportCertificates(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	// the response is only started with the first certificate, so errors raised before can still be reported
+	started := false
+	start := func() {
+		if !started {
+			started = true
+			ctx.Header("Content-Type", "application/x-ndjson")
+			ctx.Status(200)
+		}
+	}
+
+	encoder := json.NewEncoder(ctx.Writer)
+	_, err = r.svc.ExportCertificates(ctx, services.ExportCertificatesInput{
+		QueryParameters: queryParams,
+		ApplyFunc: func(cert models.Certificate) {
+			start()
+			if err := encoder.Encode(cert); err == nil {
+				ctx.Writer.Flush()
+			}
+		},
+	})
+	if err != nil && !started {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	start()
+}
+
+func (r *caHttpRoutes) GetCertificatesByExpirationDate(ctx *gin.Context) {
+	var expirationQueryParams resources.GetCertificatesByExpirationDateQueryParams
+	if err := ctx.BindQuery(&expirationQueryParams); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	queryParams, err := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	certs := []models.Certificate{}
+
+	nextBookmark, err := r.svc.GetCertificatesByExpirationDate(ctx, services.GetCertificatesByExpirationDateInput{
+		ExpiresAfter:  expirationQueryParams.ExpiresAfter,
+		ExpiresBefore: expirationQueryParams.ExpiresBefore,
+		ListInput: resources.ListInput[models.Certificate]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(cert models.Certificate) {
+				certs = append(certs, cert)
+			},
+		},
+	})
+
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetCertsResponse{
+		IterableList: resources.IterableList[models.Certificate]{
+			NextBookmark: nextBookmark,
+			List:         certs,
+		},
+	})
+}
+
+// @Summary Get Certificates by CA
+// @Description Get Certificates by CA
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {array} models.Certificate
+// @Failure 404 {string} string "CA not found"
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 500
+// @Router /cas/{id}/certificates [get]
+func (r *caHttpRoutes) GetCertificatesByCA(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	certs := []models.Certificate{}
+
+	nextBookmark, err := r.svc.GetCertificatesByCA(ctx, services.GetCertificatesByCAInput{
+		CAID: params.ID,
+		ListInput: resources.ListInput[models.Certificate]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(cert models.Certificate) {
+				certs = append(certs, cert)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetCertsResponse{
+		IterableList: resources.IterableList[models.Certificate]{
+			NextBookmark: nextBookmark,
+			List:         certs,
+		},
+	})
+}
+
+// @Summary Sign Certificate
+// @Description Sign Certificate
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param message body resources.SignCertificateBody true "Sign Certificate Info"
+// @Success 200 {object} models.Certificate
+// @Failure 404 {string} string "CA not found"
+// @Failure 400 {string} string "Struct Validation error || CA Status inconsistent"
+// @Failure 500
+// @Router /cas/{id}/certificates/sign [post]
+func (r *caHttpRoutes) SignCertificate(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	limitRequestBody(ctx, helpers.CSRRequestBodyLimit(r.csrLimits))
+
+	var requestBody resources.SignCertificateBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		if isRequestBodyTooLarge(err) {
+			ctx.JSON(413, gin.H{"err": err.Error()})
+			return
+		}
+
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	ca, err := r.svc.SignCertificate(ctx, services.SignCertificateInput{
+		CAID:                 params.ID,
+		Subject:              requestBody.Subject,
+		CertRequest:          requestBody.CertRequest,
+		SignVerbatim:         requestBody.SignVerbatim,
+		SigningProfile:       requestBody.SigningProfile,
+		IssuanceContext:      trustedIssuanceContext(ctx, requestBody.IssuanceContext),
+		CertificateProfileID: requestBody.CertificateProfileID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound, errs.ErrCertificateProfileNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAIssuancePaused:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		case errs.ErrCertificateProfileViolation, errs.ErrCertificateRequestLimits:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCertificateIssuanceVetoed, errs.ErrCASigningApprovalRequired:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCertificateIssuanceWebhook:
+			ctx.JSON(502, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, ca)
+}
+
+// trustedIssuanceContext only accepts the issuance context sent by the internal services (i.e. the DMS manager
+// enrolling a device). It authorizes the DMS owners to revoke the certificate, so it is dropped from the requests of
+// any other caller.
+func trustedIssuanceContext(ctx *gin.Context, issuanceCtx *models.CertificateIssuanceContext) *models.CertificateIssuanceContext {
+	if _, ok := identityextractors.VerifiedInternalService(ctx); !ok {
+		return nil
+	}
+
+	return issuanceCtx
+}
+
+// SignCertificatesBatch signs a batch of certificate requests. The response holds the result of each request, in
+// the order of the requests, even if some of them failed.
+func (r *caHttpRoutes) SignCertificatesBatch(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	limitRequestBody(ctx, helpers.CSRBatchRequestBodyLimit(r.csrLimits))
+
+	var requestBody resources.SignCertificatesBatchBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		if isRequestBodyTooLarge(err) {
+			ctx.JSON(413, gin.H{"err": err.Error()})
+			return
+		}
+
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	requests := make([]services.SignCertificateInput, 0, len(requestBody.Requests))
+	for _, req := range requestBody.Requests {
+		requests = append(requests, services.SignCertificateInput{
+			CAID:                 params.ID,
+			Subject:              req.Subject,
+			CertRequest:          req.CertRequest,
+			SignVerbatim:         req.SignVerbatim,
+			SigningProfile:       req.SigningProfile,
+			IssuanceContext:      trustedIssuanceContext(ctx, req.IssuanceContext),
+			CertificateProfileID: req.CertificateProfileID,
+		})
+	}
+
+	results, err := r.svc.SignCertificatesBatch(ctx, services.SignCertificatesBatchInput{
+		CAID:     params.ID,
+		Requests: requests,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.SignCertificatesBatchResponse{
+		Results: results,
+	})
+}
+
+func (r *caHttpRoutes) SignatureSign(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.SignatureSignBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	msgDecoded, err := base64.StdEncoding.DecodeString(requestBody.Message)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	signature, err := r.svc.SignatureSign(ctx, services.SignatureSignInput{
+		CAID:             params.ID,
+		Message:          msgDecoded,
+		MessageType:      requestBody.MessageType,
+		SigningAlgorithm: requestBody.SigningAlgorithm,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASigningApprovalRequired:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.SignResponse{
+		SignedData: base64.StdEncoding.EncodeToString(signature),
+	})
+}
+
+// @Summary Create CA Signing Request
+// @Description Request a sign operation against a dual control CA. The operation is executed once the request collects the required approvals
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param request body resources.CreateCASigningRequestBody true "Sign operation"
+// @Success 201 {object} models.CASigningRequest
+// @Failure 400 {string} string "Struct Validation error || CA does not enforce dual control over the sign operation"
+// @Failure 403 {string} string "Requester identity not verified"
+// @Failure 404 {string} string "CA not found"
+// @Failure 500
+// @Router /cas/{id}/signing-requests [post]
+func (r *caHttpRoutes) CreateCASigningRequest(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	limitRequestBody(ctx, helpers.CSRRequestBodyLimit(r.csrLimits))
+
+	var requestBody resources.CreateCASigningRequestBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		if isRequestBodyTooLarge(err) {
+			ctx.JSON(413, gin.H{"err": err.Error()})
+			return
+		}
+
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	msgDecoded, err := base64.StdEncoding.DecodeString(requestBody.Message)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	request, err := r.svc.CreateCASigningRequest(ctx, services.CreateCASigningRequestInput{
+		CAID:                 params.ID,
+		Operation:            requestBody.Operation,
+		Message:              msgDecoded,
+		MessageType:          requestBody.MessageType,
+		SigningAlgorithm:     requestBody.SigningAlgorithm,
+		CertRequest:          requestBody.CertRequest,
+		Subject:              requestBody.Subject,
+		SignVerbatim:         requestBody.SignVerbatim,
+		SigningProfile:       requestBody.SigningProfile,
+		CertificateProfileID: requestBody.CertificateProfileID,
+		DualControl:          requestBody.DualControl,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest, errs.ErrCADualControlNotEnabled:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAApprovalUnverifiedIdentity:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, request)
+}
+
+// @Summary Get CA Signing Requests
+// @Description Get the signing requests of a dual control CA, along with their approvals and audit log
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} resources.GetCASigningRequestsResponse
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 404 {string} string "CA not found"
+// @Failure 500
+// @Router /cas/{id}/signing-requests [get]
+func (r *caHttpRoutes) GetCASigningRequests(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.CASigningRequestFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	requests := []models.CASigningRequest{}
+	nextBookmark, err := r.svc.GetCASigningRequests(ctx, services.GetCASigningRequestsInput{
+		CAID: params.ID,
+		ListInput: resources.ListInput[models.CASigningRequest]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(request models.CASigningRequest) {
+				requests = append(requests, request)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetCASigningRequestsResponse{
+		IterableList: resources.IterableList[models.CASigningRequest]{
+			NextBookmark: nextBookmark,
+			List:         requests,
+		},
+	})
+}
+
+type caSigningRequestURIParams struct {
+	ID        string `uri:"id" binding:"required"`
+	RequestID string `uri:"reqid" binding:"required"`
+}
+
+// @Summary Get CA Signing Request
+// @Description Get a signing request of a dual control CA
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} models.CASigningRequest
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 404 {string} string "CA signing request not found"
+// @Failure 500
+// @Router /cas/{id}/signing-requests/{reqid} [get]
+func (r *caHttpRoutes) GetCASigningRequestByID(ctx *gin.Context) {
+	var params caSigningRequestURIParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	request, err := r.svc.GetCASigningRequestByID(ctx, services.GetCASigningRequestByIDInput{
+		CAID:      params.ID,
+		RequestID: params.RequestID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASigningRequestNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, request)
+}
+
+// @Summary Approve CA Signing Request
+// @Description Approve a signing request of a dual control CA. The sign operation is executed with the last required approval
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} models.CASigningRequest
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 403 {string} string "Self approval || Approver not allowed || Approver identity not verified"
+// @Failure 404 {string} string "CA signing request not found"
+// @Failure 409 {string} string "CA signing request not pending || expired || already approved"
+// @Failure 500
+// @Router /cas/{id}/signing-requests/{reqid}/approve [post]
+func (r *caHttpRoutes) ApproveCASigningRequest(ctx *gin.Context) {
+	var params caSigningRequestURIParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
+	request, err := r.svc.ApproveCASigningRequest(ctx, services.ApproveCASigningRequestInput{
+		CAID:      params.ID,
+		RequestID: params.RequestID,
+	})
 	if err != nil {
 		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrCAStatus, errs.ErrCertificateProfileViolation, errs.ErrCertificateRequestLimits:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASigningRequestNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCASigningRequestSelfApproval, errs.ErrCASigningRequestApproverDenied, errs.ErrCAApprovalUnverifiedIdentity, errs.ErrCertificateIssuanceVetoed:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCASigningRequestNotPending, errs.ErrCASigningRequestExpired, errs.ErrCASigningRequestAlreadyApproved, errs.ErrCAIssuancePaused:
+			ctx.JSON(409, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -318,45 +1598,52 @@ func (r *caHttpRoutes) GetAllCAs(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, resources.GetCAsResponse{
-		IterableList: resources.IterableList[models.CACertificate]{
-			NextBookmark: nextBookmark,
-			List:         cas,
-		},
-	})
+	ctx.JSON(200, request)
 }
 
-// @Summary Get CA By ID
-// @Description Get CA By ID
+// @Summary Reject CA Signing Request
+// @Description Reject a pending signing request of a dual control CA
 // @Accept json
 // @Produce json
 // @Security OAuth2Password
-// @Success 200 {object} models.CACertificate
-// @Failure 404 {string} string "CA not found"
+// @Param request body resources.RejectCASigningRequestBody false "Rejection reason"
+// @Success 200 {object} models.CASigningRequest
 // @Failure 400 {string} string "Struct Validation error"
+// @Failure 403 {string} string "Rejecter identity not verified"
+// @Failure 404 {string} string "CA signing request not found"
+// @Failure 409 {string} string "CA signing request not pending || expired"
 // @Failure 500
-// @Router /cas/{id} [get]
-func (r *caHttpRoutes) GetCAByID(ctx *gin.Context) {
-	type uriParams struct {
-		ID string `uri:"id" binding:"required"`
-	}
-
-	var params uriParams
+// @Router /cas/{id}/signing-requests/{reqid}/reject [post]
+func (r *caHttpRoutes) RejectCASigningRequest(ctx *gin.Context) {
+	var params caSigningRequestURIParams
 	if err := ctx.ShouldBindUri(&params); err != nil {
 		ctx.JSON(400, gin.H{"err": err.Error()})
 		return
 	}
 
-	ca, err := r.svc.GetCAByID(ctx, services.GetCAByIDInput{
-		CAID: params.ID,
-	})
+	var requestBody resources.RejectCASigningRequestBody
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+	}
 
+	request, err := r.svc.RejectCASigningRequest(ctx, services.RejectCASigningRequestInput{
+		CAID:      params.ID,
+		RequestID: params.RequestID,
+		Reason:    requestBody.Reason,
+	})
 	if err != nil {
 		switch err {
-		case errs.ErrCANotFound:
-			ctx.JSON(404, gin.H{"err": err.Error()})
 		case errs.ErrValidateBadRequest:
 			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAApprovalUnverifiedIdentity:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCASigningRequestNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCASigningRequestNotPending, errs.ErrCASigningRequestExpired:
+			ctx.JSON(409, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -364,23 +1651,24 @@ func (r *caHttpRoutes) GetCAByID(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, ca)
+	ctx.JSON(200, request)
 }
 
-// @Summary Delete CA
-// @Description Delete CA
+// @Summary Sign SVID
+// @Description Sign a SPIFFE X509-SVID with a CA enabled to issue SVIDs
 // @Accept json
 // @Produce json
 // @Security OAuth2Password
-// @Success 201
+// @Param message body resources.SignSVIDBody true "Sign SVID Info"
+// @Success 201 {object} models.Certificate
+// @Failure 400 {string} string "Struct Validation error || Invalid SPIFFE ID || CA Status inconsistent"
+// @Failure 403 {string} string "CA not enabled to issue SVIDs"
 // @Failure 404 {string} string "CA not found"
-// @Failure 400 {string} string "Struct Validation error || CA Status inconsistent"
 // @Failure 500
-// @Router /cas/{id} [delete]
-func (r *caHttpRoutes) DeleteCA(ctx *gin.Context) {
-
+// @Router /cas/{id}/svids/sign [post]
+func (r *caHttpRoutes) SignSVID(ctx *gin.Context) {
 	type uriParams struct {
-		CAId string `uri:"id" binding:"required"`
+		ID string `uri:"id" binding:"required"`
 	}
 
 	var params uriParams
@@ -389,18 +1677,39 @@ func (r *caHttpRoutes) DeleteCA(ctx *gin.Context) {
 		return
 	}
 
-	err := r.svc.DeleteCA(ctx, services.DeleteCAInput{
-		CAID: params.CAId,
-	})
+	limitRequestBody(ctx, helpers.CSRRequestBodyLimit(r.csrLimits))
+
+	var requestBody resources.SignSVIDBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		if isRequestBodyTooLarge(err) {
+			ctx.JSON(413, gin.H{"err": err.Error()})
+			return
+		}
+
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
+	crt, err := r.svc.SignSVID(ctx, services.SignSVIDInput{
+		CAID:        params.ID,
+		CertRequest: requestBody.CertRequest,
+		SPIFFEID:    requestBody.SPIFFEID,
+		Validity:    requestBody.Validity,
+	})
 	if err != nil {
 		switch err {
-		case errs.ErrCANotFound:
+		case errs.ErrCANotFound, errs.ErrCertificateProfileNotFound:
 			ctx.JSON(404, gin.H{"err": err.Error()})
-		case errs.ErrValidateBadRequest:
+		case errs.ErrValidateBadRequest, errs.ErrCAStatus, errs.ErrSVIDInvalidSPIFFEID:
 			ctx.JSON(400, gin.H{"err": err.Error()})
-		case errs.ErrCAStatus:
+		case errs.ErrCertificateProfileViolation, errs.ErrCertificateRequestLimits:
 			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCASPIFFENotEnabled, errs.ErrCertificateIssuanceVetoed, errs.ErrCASigningApprovalRequired:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCAIssuancePaused:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		case errs.ErrCertificateIssuanceWebhook:
+			ctx.JSON(502, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -408,10 +1717,10 @@ func (r *caHttpRoutes) DeleteCA(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, gin.H{})
+	ctx.JSON(201, crt)
 }
 
-func (r *caHttpRoutes) UpdateCAStatus(ctx *gin.Context) {
+func (r *caHttpRoutes) SignToken(ctx *gin.Context) {
 	type uriParams struct {
 		ID string `uri:"id" binding:"required"`
 	}
@@ -422,26 +1731,25 @@ func (r *caHttpRoutes) UpdateCAStatus(ctx *gin.Context) {
 		return
 	}
 
-	var requestBody resources.UpdateCertificateStatusBody
+	var requestBody resources.SignTokenBody
 	if err := ctx.BindJSON(&requestBody); err != nil {
 		ctx.JSON(400, gin.H{"err": err.Error()})
 		return
 	}
 
-	ca, err := r.svc.UpdateCAStatus(ctx, services.UpdateCAStatusInput{
-		CAID:             params.ID,
-		Status:           requestBody.NewStatus,
-		RevocationReason: requestBody.RevocationReason,
+	token, err := r.svc.SignToken(ctx, services.SignTokenInput{
+		CAID:      params.ID,
+		Claims:    requestBody.Claims,
+		Algorithm: requestBody.Algorithm,
 	})
-
 	if err != nil {
 		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCATokenSigningNotEnabled, errs.ErrFeatureDisabled:
+			ctx.JSON(403, gin.H{"err": err.Error()})
 		case errs.ErrCANotFound:
 			ctx.JSON(404, gin.H{"err": err.Error()})
-		case errs.ErrCAAlreadyRevoked:
-			ctx.JSON(400, gin.H{"err": err.Error()})
-		case errs.ErrValidateBadRequest:
-			ctx.JSON(400, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -449,22 +1757,21 @@ func (r *caHttpRoutes) UpdateCAStatus(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(201, ca)
+	ctx.JSON(200, token)
 }
 
-// @Summary Get Certificate by Serial Number
-// @Description Get Certificate by Serial Number
-// @Accept json
+// @Summary Get Issuance Log Tree Head
+// @Description Get the Merkle tree head of the issuance log of the CA, signed with the CA key
 // @Produce json
 // @Security OAuth2Password
-// @Success 200 {object} models.Certificate
-// @Failure 404 {string} string "Certificate not found"
-// @Failure 400 {string} string "Struct Validation error"
+// @Success 200 {object} models.IssuanceLogTreeHead
+// @Failure 403 {string} string "Issuance log not enabled"
+// @Failure 404 {string} string "CA not found"
 // @Failure 500
-// @Router /cas/{id}/certificates/{sn} [get]
-func (r *caHttpRoutes) GetCertificateBySerialNumber(ctx *gin.Context) {
+// @Router /cas/{id}/issuance-log/tree-head [get]
+func (r *caHttpRoutes) GetIssuanceLogTreeHead(ctx *gin.Context) {
 	type uriParams struct {
-		SerialNumber string `uri:"sn" binding:"required"`
+		ID string `uri:"id" binding:"required"`
 	}
 
 	var params uriParams
@@ -473,91 +1780,73 @@ func (r *caHttpRoutes) GetCertificateBySerialNumber(ctx *gin.Context) {
 		return
 	}
 
-	cert, err := r.svc.GetCertificateBySerialNumber(ctx, services.GetCertificatesBySerialNumberInput{
-		SerialNumber: params.SerialNumber,
+	head, err := r.svc.GetIssuanceLogTreeHead(ctx, services.GetIssuanceLogTreeHeadInput{
+		CAID: params.ID,
 	})
-
 	if err != nil {
 		switch err {
-		case errs.ErrCertificateNotFound:
-			ctx.JSON(404, gin.H{"err": err.Error()})
 		case errs.ErrValidateBadRequest:
 			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAIssuanceLogNotEnabled:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
 
 		return
 	}
-	ctx.JSON(200, cert)
+
+	ctx.JSON(200, head)
 }
 
-// @Summary Get Certificates
-// @Description Update CA Metadata
-// @Accept json
+// @Summary Get Issuance Log Inclusion Proof
+// @Description Get the Merkle audit path of the certificate in the issuance log of the CA, together with the signed tree head it leads to
 // @Produce json
 // @Security OAuth2Password
-// @Param message body resources.UpdateCAMetadataBody true "Update CA Metadata Info"
-// @Success 200 {array} models.Certificate
+// @Param tree_size query int false "Size of the tree the proof is computed for. Defaults to the current size"
+// @Success 200 {object} models.IssuanceLogInclusionProof
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 403 {string} string "Issuance log not enabled"
+// @Failure 404 {string} string "CA not found || Certificate not found in the CA issuance log"
 // @Failure 500
-// @Router /certificates [get]
-func (r *caHttpRoutes) GetCertificates(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
-
-	certs := []models.Certificate{}
-
-	nextBookmark, err := r.svc.GetCertificates(ctx, services.GetCertificatesInput{
-		ListInput: resources.ListInput[models.Certificate]{
-			QueryParameters: queryParams,
-			ExhaustiveRun:   false,
-			ApplyFunc: func(cert models.Certificate) {
-				certs = append(certs, cert)
-			},
-		},
-	})
-
-	if err != nil {
-		switch err {
-		default:
-			ctx.JSON(500, gin.H{"err": err.Error()})
-		}
+// @Router /cas/{id}/issuance-log/proofs/{sn} [get]
+func (r *caHttpRoutes) GetIssuanceLogInclusionProof(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+		SN string `uri:"sn" binding:"required"`
+	}
 
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
 		return
 	}
 
-	ctx.JSON(200, resources.GetCertsResponse{
-		IterableList: resources.IterableList[models.Certificate]{
-			NextBookmark: nextBookmark,
-			List:         certs,
-		},
-	})
-}
+	type queryParams struct {
+		TreeSize int `form:"tree_size"`
+	}
 
-func (r *caHttpRoutes) GetCertificatesByExpirationDate(ctx *gin.Context) {
-	var expirationQueryParams resources.GetCertificatesByExpirationDateQueryParams
-	if err := ctx.BindQuery(&expirationQueryParams); err != nil {
+	var query queryParams
+	if err := ctx.ShouldBindQuery(&query); err != nil {
 		ctx.JSON(400, gin.H{"err": err.Error()})
 		return
 	}
 
-	queryParams := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
-
-	certs := []models.Certificate{}
-
-	nextBookmark, err := r.svc.GetCertificatesByExpirationDate(ctx, services.GetCertificatesByExpirationDateInput{
-		ExpiresAfter:  expirationQueryParams.ExpiresAfter,
-		ExpiresBefore: expirationQueryParams.ExpiresBefore,
-		ListInput: resources.ListInput[models.Certificate]{
-			QueryParameters: queryParams,
-			ExhaustiveRun:   false,
-			ApplyFunc: func(cert models.Certificate) {
-				certs = append(certs, cert)
-			},
-		},
+	proof, err := r.svc.GetIssuanceLogInclusionProof(ctx, services.GetIssuanceLogInclusionProofInput{
+		CAID:         params.ID,
+		SerialNumber: params.SN,
+		TreeSize:     query.TreeSize,
 	})
-
 	if err != nil {
 		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCAIssuanceLogNotEnabled:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound, errs.ErrCAIssuanceLogEntryNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -565,27 +1854,19 @@ func (r *caHttpRoutes) GetCertificatesByExpirationDate(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, resources.GetCertsResponse{
-		IterableList: resources.IterableList[models.Certificate]{
-			NextBookmark: nextBookmark,
-			List:         certs,
-		},
-	})
+	ctx.JSON(200, proof)
 }
 
-// @Summary Get Certificates by CA
-// @Description Get Certificates by CA
-// @Accept json
+// @Summary Get CA Events
+// @Description Get the lifecycle timeline of the CA (creation, status changes, key migrations, generated CRLs and bulk revocations) sorted from the oldest to the newest event
 // @Produce json
 // @Security OAuth2Password
-// @Success 200 {array} models.Certificate
-// @Failure 404 {string} string "CA not found"
+// @Success 200 {array} models.CAEvent
 // @Failure 400 {string} string "Struct Validation error"
+// @Failure 404 {string} string "CA not found"
 // @Failure 500
-// @Router /cas/{id}/certificates [get]
-func (r *caHttpRoutes) GetCertificatesByCA(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
-
+// @Router /cas/{id}/events [get]
+func (r *caHttpRoutes) GetCAEvents(ctx *gin.Context) {
 	type uriParams struct {
 		ID string `uri:"id" binding:"required"`
 	}
@@ -596,17 +1877,8 @@ func (r *caHttpRoutes) GetCertificatesByCA(ctx *gin.Context) {
 		return
 	}
 
-	certs := []models.Certificate{}
-
-	nextBookmark, err := r.svc.GetCertificatesByCA(ctx, services.GetCertificatesByCAInput{
+	events, err := r.svc.GetCAEvents(ctx, services.GetCAEventsInput{
 		CAID: params.ID,
-		ListInput: resources.ListInput[models.Certificate]{
-			QueryParameters: queryParams,
-			ExhaustiveRun:   false,
-			ApplyFunc: func(cert models.Certificate) {
-				certs = append(certs, cert)
-			},
-		},
 	})
 	if err != nil {
 		switch err {
@@ -621,26 +1893,21 @@ func (r *caHttpRoutes) GetCertificatesByCA(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, resources.GetCertsResponse{
-		IterableList: resources.IterableList[models.Certificate]{
-			NextBookmark: nextBookmark,
-			List:         certs,
-		},
-	})
+	ctx.JSON(200, events)
 }
 
-// @Summary Sign Certificate
-// @Description Sign Certificate
+// @Summary Report CRL Generation
+// @Description Record a CRL generated by the VA in the CA timeline
 // @Accept json
 // @Produce json
 // @Security OAuth2Password
-// @Param message body resources.SignCertificateBody true "Sign Certificate Info"
-// @Success 200 {object} models.Certificate
+// @Param request body resources.ReportCRLGenerationBody true "Generated CRL"
+// @Success 200
+// @Failure 400 {string} string "Struct Validation error"
 // @Failure 404 {string} string "CA not found"
-// @Failure 400 {string} string "Struct Validation error || CA Status inconsistent"
 // @Failure 500
-// @Router /cas/{id}/certificates/sign [post]
-func (r *caHttpRoutes) SignCertificate(ctx *gin.Context) {
+// @Router /cas/{id}/crls [post]
+func (r *caHttpRoutes) ReportCRLGeneration(ctx *gin.Context) {
 	type uriParams struct {
 		ID string `uri:"id" binding:"required"`
 	}
@@ -651,26 +1918,25 @@ func (r *caHttpRoutes) SignCertificate(ctx *gin.Context) {
 		return
 	}
 
-	var requestBody resources.SignCertificateBody
+	var requestBody resources.ReportCRLGenerationBody
 	if err := ctx.BindJSON(&requestBody); err != nil {
 		ctx.JSON(400, gin.H{"err": err.Error()})
 		return
 	}
 
-	ca, err := r.svc.SignCertificate(ctx, services.SignCertificateInput{
-		CAID:         params.ID,
-		Subject:      requestBody.Subject,
-		CertRequest:  requestBody.CertRequest,
-		SignVerbatim: requestBody.SignVerbatim,
+	err := r.svc.ReportCRLGeneration(ctx, services.ReportCRLGenerationInput{
+		CAID:                params.ID,
+		Number:              requestBody.Number,
+		ThisUpdate:          requestBody.ThisUpdate,
+		NextUpdate:          requestBody.NextUpdate,
+		RevokedCertificates: requestBody.RevokedCertificates,
 	})
 	if err != nil {
 		switch err {
-		case errs.ErrCANotFound:
-			ctx.JSON(404, gin.H{"err": err.Error()})
 		case errs.ErrValidateBadRequest:
 			ctx.JSON(400, gin.H{"err": err.Error()})
-		case errs.ErrCAStatus:
-			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -678,10 +1944,20 @@ func (r *caHttpRoutes) SignCertificate(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(201, ca)
+	ctx.JSON(200, gin.H{})
 }
 
-func (r *caHttpRoutes) SignatureSign(ctx *gin.Context) {
+// @Summary Create Successor CA
+// @Description Create a CA replacing the CA before it expires, with the same key type, subject, parent, engine and metadata. The successor is cross signed by the replaced CA
+// @Produce json
+// @Security OAuth2Password
+// @Success 201 {object} models.CASuccession
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 404 {string} string "CA not found"
+// @Failure 409 {string} string "CA already has a successor"
+// @Failure 500
+// @Router /cas/{id}/successor [post]
+func (r *caHttpRoutes) CreateSuccessorCA(ctx *gin.Context) {
 	type uriParams struct {
 		ID string `uri:"id" binding:"required"`
 	}
@@ -692,28 +1968,17 @@ func (r *caHttpRoutes) SignatureSign(ctx *gin.Context) {
 		return
 	}
 
-	var requestBody resources.SignatureSignBody
-	if err := ctx.BindJSON(&requestBody); err != nil {
-		ctx.JSON(400, gin.H{"err": err.Error()})
-		return
-	}
-
-	msgDecoded, err := base64.StdEncoding.DecodeString(requestBody.Message)
-	if err != nil {
-		ctx.JSON(400, gin.H{"err": err.Error()})
-		return
-	}
-
-	signature, err := r.svc.SignatureSign(ctx, services.SignatureSignInput{
-		CAID:             params.ID,
-		Message:          msgDecoded,
-		MessageType:      requestBody.MessageType,
-		SigningAlgorithm: requestBody.SigningAlgorithm,
+	succession, err := r.svc.CreateSuccessorCA(ctx, services.CreateSuccessorCAInput{
+		CAID: params.ID,
 	})
 	if err != nil {
 		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrCAType, errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
 		case errs.ErrCANotFound:
 			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCASuccessorAlreadyExists:
+			ctx.JSON(409, gin.H{"err": err.Error()})
 		default:
 			ctx.JSON(500, gin.H{"err": err.Error()})
 		}
@@ -721,9 +1986,29 @@ func (r *caHttpRoutes) SignatureSign(ctx *gin.Context) {
 		return
 	}
 
-	ctx.JSON(200, resources.SignResponse{
-		SignedData: base64.StdEncoding.EncodeToString(signature),
-	})
+	ctx.JSON(201, succession)
+}
+
+func (r *caHttpRoutes) GetTokenSigningKeys(ctx *gin.Context) {
+	jwks, err := r.svc.GetTokenSigningKeys(ctx)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, jwks)
+}
+
+func (r *caHttpRoutes) GetJWKS(ctx *gin.Context) {
+	jwks, err := r.svc.GetJWKS(ctx)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	// short lived cache, so consumers pick up rotated keys
+	ctx.Header("Cache-Control", "public, max-age=300")
+	ctx.JSON(200, jwks)
 }
 
 func (r *caHttpRoutes) SignatureVerify(ctx *gin.Context) {
@@ -779,7 +2064,11 @@ func (r *caHttpRoutes) SignatureVerify(ctx *gin.Context) {
 }
 
 func (r *caHttpRoutes) GetCertificatesByCAAndStatus(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	queryParams, err := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
 	type uriParams struct {
 		CAID   string `uri:"id" binding:"required"`
@@ -830,7 +2119,11 @@ func (r *caHttpRoutes) GetCertificatesByCAAndStatus(ctx *gin.Context) {
 }
 
 func (r *caHttpRoutes) GetCertificatesByStatus(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	queryParams, err := FilterQuery(ctx.Request, resources.CertificateFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
 	type uriParams struct {
 		Status string `uri:"status" binding:"required"`
@@ -0,0 +1,232 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+// @Summary Create Certificate Profile
+// @Description Create a certificate profile enforced when signing certificate requests
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param message body resources.CreateCertificateProfileBody true "Certificate Profile"
+// @Success 201 {object} models.CertificateProfile
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 404 {string} string "CA not found"
+// @Failure 409 {string} string "Certificate profile already exists || CA already bound to another certificate profile"
+// @Failure 500
+// @Router /profiles [post]
+func (r *caHttpRoutes) CreateCertificateProfile(ctx *gin.Context) {
+	var requestBody resources.CreateCertificateProfileBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	profile, err := r.svc.CreateCertificateProfile(ctx, services.CreateCertificateProfileInput{
+		ID:                 requestBody.ID,
+		Name:               requestBody.Name,
+		Description:        requestBody.Description,
+		CAIDs:              requestBody.CAIDs,
+		KeyUsages:          requestBody.KeyUsages,
+		ExtendedKeyUsages:  requestBody.ExtendedKeyUsages,
+		SANPolicy:          requestBody.SANPolicy,
+		SubjectConstraints: requestBody.SubjectConstraints,
+		MaxValidity:        requestBody.MaxValidity,
+		NotBeforeBackdate:  requestBody.NotBeforeBackdate,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCertificateProfileAlreadyExists, errs.ErrCertificateProfileCABound:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, profile)
+}
+
+// @Summary Get All Certificate Profiles
+// @Description Get All Certificate Profiles
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Success 200 {object} resources.GetCertificateProfilesResponse
+// @Failure 500
+// @Router /profiles [get]
+func (r *caHttpRoutes) GetCertificateProfiles(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, map[string]resources.FilterFieldType{})
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	profiles := []models.CertificateProfile{}
+	nextBookmark, err := r.svc.GetCertificateProfiles(ctx, services.GetCertificateProfilesInput{
+		ListInput: resources.ListInput[models.CertificateProfile]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(profile models.CertificateProfile) {
+				profiles = append(profiles, profile)
+			},
+		},
+	})
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, resources.GetCertificateProfilesResponse{
+		IterableList: resources.IterableList[models.CertificateProfile]{
+			NextBookmark: nextBookmark,
+			List:         profiles,
+		},
+	})
+}
+
+// @Summary Get Certificate Profile By ID
+// @Description Get Certificate Profile By ID
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "Certificate Profile ID"
+// @Success 200 {object} models.CertificateProfile
+// @Failure 404 {string} string "Certificate profile not found"
+// @Failure 500
+// @Router /profiles/{id} [get]
+func (r *caHttpRoutes) GetCertificateProfileByID(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	profile, err := r.svc.GetCertificateProfileByID(ctx, services.GetCertificateProfileByIDInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCertificateProfileNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, profile)
+}
+
+// @Summary Update Certificate Profile
+// @Description Replace the definition of a certificate profile
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "Certificate Profile ID"
+// @Param message body models.CertificateProfile true "Certificate Profile"
+// @Success 200 {object} models.CertificateProfile
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 404 {string} string "Certificate profile not found || CA not found"
+// @Failure 409 {string} string "CA already bound to another certificate profile"
+// @Failure 500
+// @Router /profiles/{id} [put]
+func (r *caHttpRoutes) UpdateCertificateProfile(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody models.CertificateProfile
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	requestBody.ID = params.ID
+	requestBody.CreationTS = time.Time{}
+
+	profile, err := r.svc.UpdateCertificateProfile(ctx, services.UpdateCertificateProfileInput{
+		Profile: requestBody,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCertificateProfileNotFound, errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCertificateProfileCABound:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, profile)
+}
+
+// @Summary Delete Certificate Profile
+// @Description Delete Certificate Profile
+// @Accept json
+// @Produce json
+// @Security OAuth2Password
+// @Param id path string true "Certificate Profile ID"
+// @Success 200
+// @Failure 404 {string} string "Certificate profile not found"
+// @Failure 500
+// @Router /profiles/{id} [delete]
+func (r *caHttpRoutes) DeleteCertificateProfile(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	err := r.svc.DeleteCertificateProfile(ctx, services.DeleteCertificateProfileInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrCertificateProfileNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, gin.H{})
+}
@@ -0,0 +1,93 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+)
+
+type connectorPendingEventsHttpRoutes struct {
+	connectorID string
+	queue       *connectors.RetryQueue
+}
+
+func NewConnectorPendingEventsHttpRoutes(connectorID string, queue *connectors.RetryQueue) *connectorPendingEventsHttpRoutes {
+	return &connectorPendingEventsHttpRoutes{
+		connectorID: connectorID,
+		queue:       queue,
+	}
+}
+
+type connectorPendingEventsParams struct {
+	ConnectorID string `uri:"id" binding:"required"`
+	EventID     string `uri:"eventId"`
+}
+
+// @Summary List Pending Events
+// @Description Lists the events the connector failed to handle, either retrying or dead lettered
+// @Produce json
+// @Param id path string true "Connector ID"
+// @Success 200 {array} models.ConnectorPendingEvent
+// @Failure 404 {string} string "Connector not found"
+// @Router /v1/connectors/{id}/pending-events [get]
+func (r *connectorPendingEventsHttpRoutes) GetPendingEvents(ctx *gin.Context) {
+	var params connectorPendingEventsParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	if params.ConnectorID != r.connectorID {
+		ctx.JSON(404, gin.H{"err": errs.ErrConnectorNotFound.Error()})
+		return
+	}
+
+	events, err := r.queue.GetPendingEvents(ctx)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, events)
+}
+
+// @Summary Replay Pending Event
+// @Description Retries a pending event right away. Responds with 204 if the event was handled or with the updated pending event otherwise
+// @Produce json
+// @Param id path string true "Connector ID"
+// @Param eventId path string true "Pending Event ID"
+// @Success 200 {object} models.ConnectorPendingEvent
+// @Success 204
+// @Failure 404 {string} string "Connector or pending event not found"
+// @Router /v1/connectors/{id}/pending-events/{eventId}/replay [post]
+func (r *connectorPendingEventsHttpRoutes) ReplayPendingEvent(ctx *gin.Context) {
+	var params connectorPendingEventsParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	if params.ConnectorID != r.connectorID {
+		ctx.JSON(404, gin.H{"err": errs.ErrConnectorNotFound.Error()})
+		return
+	}
+
+	pending, err := r.queue.Replay(ctx, params.EventID)
+	if err != nil {
+		switch err {
+		case errs.ErrConnectorPendingEventNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	if pending == nil {
+		ctx.Status(204)
+		return
+	}
+
+	ctx.JSON(200, pending)
+}
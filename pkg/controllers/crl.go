@@ -0,0 +1,61 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type crlHttpRoutes struct {
+	crl services.CRLService
+}
+
+func NewCRLHttpRoutes(crl services.CRLService) *crlHttpRoutes {
+	return &crlHttpRoutes{
+		crl: crl,
+	}
+}
+
+func (r *crlHttpRoutes) GetCRL(ctx *gin.Context) {
+	type uriParams struct {
+		CAID string `uri:"caID" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	input := services.GetCRLInput{
+		CAID: params.CAID,
+	}
+
+	var crl []byte
+	var err error
+	if jwsSignatureRequested(ctx) {
+		var signed *models.SignedContent
+		signed, err = r.crl.GetSignedCRL(ctx, input)
+		if err == nil {
+			crl = signed.Content
+			ctx.Header(models.JWSSignatureHeader, signed.Signature)
+		}
+	} else {
+		crl, err = r.crl.GetCRL(ctx, input)
+	}
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.Data(200, "application/pkix-crl", crl)
+}
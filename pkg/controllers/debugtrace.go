@@ -0,0 +1,37 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/debugtrace"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+)
+
+type debugTraceHttpRoutes struct {
+	recorder *debugtrace.Recorder
+}
+
+func NewDebugTraceHttpRoutes(recorder *debugtrace.Recorder) *debugTraceHttpRoutes {
+	return &debugTraceHttpRoutes{
+		recorder: recorder,
+	}
+}
+
+func (r *debugTraceHttpRoutes) GetTrace(ctx *gin.Context) {
+	type uriParams struct {
+		RequestID string `uri:"reqid" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	trace, ok := r.recorder.Get(params.RequestID)
+	if !ok {
+		ctx.JSON(404, gin.H{"err": errs.ErrDebugTraceNotFound.Error()})
+		return
+	}
+
+	ctx.JSON(200, trace)
+}
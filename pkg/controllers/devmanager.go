@@ -1,8 +1,12 @@
 package controllers
 
 import (
+	"fmt"
+	"time"
+
 	"github.com/gin-gonic/gin"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
@@ -29,11 +33,94 @@ func (r *devManagerHttpRoutes) GetStats(ctx *gin.Context) {
 	ctx.JSON(200, stats)
 }
 
+// GetIssuanceReport returns the issuance activity between the "from" and "to" RFC3339 query parameters
+// (defaults to the last 30 days, at most a year). Use "format=csv" to download the report as CSV.
+func (r *devManagerHttpRoutes) GetIssuanceReport(ctx *gin.Context) {
+	type queryParams struct {
+		From   string `form:"from"`
+		To     string `form:"to"`
+		Format string `form:"format"`
+	}
+
+	var params queryParams
+	if err := ctx.ShouldBindQuery(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	to := time.Now()
+	if params.To != "" {
+		parsed, err := time.Parse(time.RFC3339, params.To)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+		to = parsed
+	}
+
+	from := to.Add(-30 * 24 * time.Hour)
+	if params.From != "" {
+		parsed, err := time.Parse(time.RFC3339, params.From)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+		from = parsed
+	}
+
+	report, err := r.svc.GetIssuanceReport(ctx, services.GetIssuanceReportInput{
+		From: from,
+		To:   to,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	switch params.Format {
+	case "", "json":
+		ctx.JSON(200, report)
+	case "csv":
+		csv, err := helpers.IssuanceReportToCSV(report)
+		if err != nil {
+			ctx.JSON(500, gin.H{"err": err.Error()})
+			return
+		}
+
+		ctx.Header("Content-Disposition", fmt.Sprintf("attachment; filename=issuance-report-%s.csv", report.GeneratedAt.Format("20060102150405")))
+		ctx.Data(200, "text/csv", csv)
+	default:
+		ctx.JSON(400, gin.H{"err": fmt.Sprintf("unsupported report format '%s'", params.Format)})
+	}
+}
+
 func (r *devManagerHttpRoutes) GetAllDevices(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.DeviceFiltrableFields)
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var notSeenFor time.Duration
+	if value := ctx.Query("not_seen_in"); value != "" {
+		var err error
+		notSeenFor, err = models.ParseDuration(value)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": fmt.Sprintf("invalid not_seen_in duration '%s': %s", value, err)})
+			return
+		}
+	}
 
 	devices := []models.Device{}
 	nextBookmark, err := r.svc.GetDevices(ctx, services.GetDevicesInput{
+		CertificateStatus: models.DeviceCertificateStatus(ctx.Query("cert_status")),
+		NotSeenFor:        notSeenFor,
 		ListInput: resources.ListInput[models.Device]{
 			QueryParameters: queryParams,
 			ExhaustiveRun:   false,
@@ -44,7 +131,12 @@ func (r *devManagerHttpRoutes) GetAllDevices(ctx *gin.Context) {
 	})
 
 	if err != nil {
-		ctx.JSON(500, err)
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
 		return
 	}
 
@@ -57,7 +149,11 @@ func (r *devManagerHttpRoutes) GetAllDevices(ctx *gin.Context) {
 }
 
 func (r *devManagerHttpRoutes) GetDevicesByDMS(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.DeviceFiltrableFields)
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 	type uriParams struct {
 		DMSID string `uri:"id" binding:"required"`
 	}
@@ -93,6 +189,53 @@ func (r *devManagerHttpRoutes) GetDevicesByDMS(ctx *gin.Context) {
 	})
 }
 
+// GetDevicesOutOfCloudSync lists the devices whose active identity certificate is not reflected in the cloud
+// provider of the connector, to drive targeted resyncs.
+func (r *devManagerHttpRoutes) GetDevicesOutOfCloudSync(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	type uriParams struct {
+		ConnectorID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	devices := []models.DeviceCloudSyncStatus{}
+	nextBookmark, err := r.svc.GetDevicesOutOfCloudSync(ctx, services.GetDevicesOutOfCloudSyncInput{
+		ConnectorID: params.ConnectorID,
+		ListInput: resources.ListInput[models.DeviceCloudSyncStatus]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(status models.DeviceCloudSyncStatus) {
+				devices = append(devices, status)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, resources.GetDevicesOutOfCloudSyncResponse{
+		IterableList: resources.IterableList[models.DeviceCloudSyncStatus]{
+			NextBookmark: nextBookmark,
+			List:         devices,
+		},
+	})
+}
+
 func (r *devManagerHttpRoutes) GetDeviceByID(ctx *gin.Context) {
 	type uriParams struct {
 		ID string `uri:"id" binding:"required"`
@@ -104,8 +247,10 @@ func (r *devManagerHttpRoutes) GetDeviceByID(ctx *gin.Context) {
 		return
 	}
 
+	// confidential slot payloads are only returned when explicitly requested
 	dms, err := r.svc.GetDeviceByID(ctx, services.GetDeviceByIDInput{
-		ID: params.ID,
+		ID:             params.ID,
+		IncludeSecrets: ctx.Query("include_secrets") == "true",
 	})
 	if err != nil {
 		switch err {
@@ -118,9 +263,39 @@ func (r *devManagerHttpRoutes) GetDeviceByID(ctx *gin.Context) {
 		}
 	}
 
+	ctx.Header("ETag", services.DeviceETag(dms))
 	ctx.JSON(200, dms)
 }
 
+func (r *devManagerHttpRoutes) GetDeviceByCertificate(ctx *gin.Context) {
+	type uriParams struct {
+		SerialNumber string `uri:"sn" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	binding, err := r.svc.GetDeviceByCertificate(ctx, services.GetDeviceByCertificateInput{
+		SerialNumber: params.SerialNumber,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCertificateNotFound, errs.ErrDeviceCertificateNotBound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, binding)
+}
+
 func (r *devManagerHttpRoutes) CreateDevice(ctx *gin.Context) {
 	var requestBody resources.CreateDeviceBody
 	if err := ctx.BindJSON(&requestBody); err != nil {
@@ -136,10 +311,17 @@ func (r *devManagerHttpRoutes) CreateDevice(ctx *gin.Context) {
 		Icon:      requestBody.Icon,
 		IconColor: requestBody.IconColor,
 		DMSID:     requestBody.DMSID,
+
+		DeviceIDRules: requestBody.DeviceIDRules,
 	})
 
 	if err != nil {
-		ctx.JSON(500, err)
+		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrDeviceInvalidID:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, err)
+		}
 		return
 	}
 
@@ -164,15 +346,22 @@ func (r *devManagerHttpRoutes) UpdateDeviceIdentitySlot(ctx *gin.Context) {
 	}
 
 	dev, err := r.svc.UpdateDeviceIdentitySlot(ctx, services.UpdateDeviceIdentitySlotInput{
-		ID:   params.ID,
-		Slot: requestBody.Slot,
+		ID:      params.ID,
+		Slot:    requestBody.Slot,
+		IfMatch: ctx.GetHeader("If-Match"),
 	})
 
 	if err != nil {
-		ctx.JSON(500, err)
+		switch err {
+		case errs.ErrPreconditionFailed:
+			ctx.JSON(412, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, err)
+		}
 		return
 	}
 
+	ctx.Header("ETag", services.DeviceETag(dev))
 	ctx.JSON(200, dev)
 }
 
@@ -196,10 +385,56 @@ func (r *devManagerHttpRoutes) UpdateDeviceMetadata(ctx *gin.Context) {
 	dev, err := r.svc.UpdateDeviceMetadata(ctx, services.UpdateDeviceMetadataInput{
 		ID:       params.ID,
 		Metadata: requestBody.Metadata,
+		IfMatch:  ctx.GetHeader("If-Match"),
 	})
 
 	if err != nil {
-		ctx.JSON(500, err)
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrPreconditionFailed:
+			ctx.JSON(412, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, err)
+		}
+		return
+	}
+
+	ctx.Header("ETag", services.DeviceETag(dev))
+	ctx.JSON(200, dev)
+}
+
+func (r *devManagerHttpRoutes) UpdateDeviceConnectionMetadata(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.UpdateDeviceConnectionMetadataBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	dev, err := r.svc.UpdateDeviceConnectionMetadata(ctx, services.UpdateDeviceConnectionMetadataInput{
+		ID:                 params.ID,
+		ConnectionMetadata: requestBody.DeviceConnectionMetadata,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
 		return
 	}
 
@@ -229,3 +464,708 @@ func (r *devManagerHttpRoutes) DecommissionDevice(ctx *gin.Context) {
 
 	ctx.JSON(200, dev)
 }
+
+// ReportDeviceHeartbeat records the device as online. The IP address of the device is the client IP of the request.
+func (r *devManagerHttpRoutes) ReportDeviceHeartbeat(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	requestBody := resources.ReportDeviceHeartbeatBody{}
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.BindJSON(&requestBody); err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+	}
+
+	if requestBody.Source == "" {
+		requestBody.Source = "http"
+	}
+
+	dev, err := r.svc.ReportDeviceHeartbeat(ctx, services.ReportDeviceHeartbeatInput{
+		ID:        params.ID,
+		Source:    requestBody.Source,
+		IPAddress: ctx.ClientIP(),
+		Broker:    requestBody.Broker,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, dev)
+}
+
+func (r *devManagerHttpRoutes) CreateDeviceLog(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.CreateDeviceLogBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	log, err := r.svc.CreateDeviceLog(ctx, services.CreateDeviceLogInput{
+		DeviceID:  params.ID,
+		Severity:  requestBody.Severity,
+		Message:   requestBody.Message,
+		Source:    requestBody.Source,
+		Timestamp: requestBody.Timestamp,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, log)
+}
+
+func (r *devManagerHttpRoutes) GetDeviceLogs(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceLogFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	logs := []models.DeviceLog{}
+	nextBookmark, err := r.svc.GetDeviceLogs(ctx, services.GetDeviceLogsInput{
+		DeviceID: params.ID,
+		Severity: models.DeviceLogSeverity(ctx.Query("severity")),
+		ListInput: resources.ListInput[models.DeviceLog]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(log models.DeviceLog) {
+				logs = append(logs, log)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetDeviceLogsResponse{
+		IterableList: resources.IterableList[models.DeviceLog]{
+			NextBookmark: nextBookmark,
+			List:         logs,
+		},
+	})
+}
+
+func (r *devManagerHttpRoutes) ForceDeviceReenroll(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.ForceDeviceReenrollBody
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.BindJSON(&requestBody); err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+	}
+
+	dev, err := r.svc.ForceDeviceReenroll(ctx, services.ForceDeviceReenrollInput{
+		ID:     params.ID,
+		Reason: requestBody.Reason,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrDeviceNoIdentity:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, dev)
+}
+
+func (r *devManagerHttpRoutes) ProvisionDeviceSecretSlot(ctx *gin.Context) {
+	type uriParams struct {
+		ID   string `uri:"id" binding:"required"`
+		Slot string `uri:"slot" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.ProvisionDeviceSecretSlotBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	dev, err := r.svc.ProvisionDeviceSecretSlot(ctx, services.ProvisionDeviceSecretSlotInput{
+		DeviceID:   params.ID,
+		SlotID:     params.Slot,
+		SecretType: requestBody.Type,
+		Profile:    requestBody.Profile,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, dev)
+}
+
+func (r *devManagerHttpRoutes) RotateDeviceSecretSlot(ctx *gin.Context) {
+	type uriParams struct {
+		ID   string `uri:"id" binding:"required"`
+		Slot string `uri:"slot" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	dev, err := r.svc.RotateDeviceSecretSlot(ctx, services.RotateDeviceSecretSlotInput{
+		DeviceID: params.ID,
+		SlotID:   params.Slot,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound, errs.ErrDeviceSlotNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, dev)
+}
+
+func (r *devManagerHttpRoutes) GetDeviceSecretSlot(ctx *gin.Context) {
+	type uriParams struct {
+		ID   string `uri:"id" binding:"required"`
+		Slot string `uri:"slot" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	secret, err := r.svc.GetDeviceSecretSlot(ctx, services.GetDeviceSecretSlotInput{
+		DeviceID: params.ID,
+		SlotID:   params.Slot,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDeviceNotFound, errs.ErrDeviceSlotNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrDeviceNoIdentity:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, secret)
+}
+
+func (r *devManagerHttpRoutes) CreateDeviceGroup(ctx *gin.Context) {
+	var requestBody resources.CreateDeviceGroupBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	group, err := r.svc.CreateDeviceGroup(ctx, services.CreateDeviceGroupInput{
+		Name:              requestBody.Name,
+		Description:       requestBody.Description,
+		DeviceIDs:         requestBody.DeviceIDs,
+		Tags:              requestBody.Tags,
+		Filters:           requestBody.Filters,
+		CertificateStatus: requestBody.CertificateStatus,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(201, group)
+}
+
+func (r *devManagerHttpRoutes) GetDeviceGroups(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceGroupFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	groups := []models.DeviceGroup{}
+	nextBookmark, err := r.svc.GetDeviceGroups(ctx, services.GetDeviceGroupsInput{
+		ListInput: resources.ListInput[models.DeviceGroup]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(group models.DeviceGroup) {
+				groups = append(groups, group)
+			},
+		},
+	})
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, resources.GetDeviceGroupsResponse{
+		IterableList: resources.IterableList[models.DeviceGroup]{
+			NextBookmark: nextBookmark,
+			List:         groups,
+		},
+	})
+}
+
+func (r *devManagerHttpRoutes) GetDeviceGroupByID(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	group, err := r.svc.GetDeviceGroupByID(ctx, services.GetDeviceGroupByIDInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, group)
+}
+
+func (r *devManagerHttpRoutes) UpdateDeviceGroupDevices(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.UpdateDeviceGroupDevicesBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	group, err := r.svc.UpdateDeviceGroupDevices(ctx, services.UpdateDeviceGroupDevicesInput{
+		ID:              params.ID,
+		AddDeviceIDs:    requestBody.Add,
+		RemoveDeviceIDs: requestBody.Remove,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound, errs.ErrDeviceNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, group)
+}
+
+func (r *devManagerHttpRoutes) UpdateDeviceGroupTags(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.UpdateDeviceGroupTagsBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	group, err := r.svc.UpdateDeviceGroupTags(ctx, services.UpdateDeviceGroupTagsInput{
+		ID:   params.ID,
+		Tags: requestBody.Tags,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, group)
+}
+
+func (r *devManagerHttpRoutes) UpdateDeviceGroupFilters(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.UpdateDeviceGroupFiltersBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	group, err := r.svc.UpdateDeviceGroupFilters(ctx, services.UpdateDeviceGroupFiltersInput{
+		ID:                params.ID,
+		Filters:           requestBody.Filters,
+		CertificateStatus: requestBody.CertificateStatus,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, group)
+}
+
+func (r *devManagerHttpRoutes) DeleteDeviceGroup(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	err := r.svc.DeleteDeviceGroup(ctx, services.DeleteDeviceGroupInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, gin.H{})
+}
+
+func (r *devManagerHttpRoutes) GetDeviceGroupDevices(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	devices := []models.Device{}
+	nextBookmark, err := r.svc.GetDeviceGroupDevices(ctx, services.GetDeviceGroupDevicesInput{
+		ID: params.ID,
+		ListInput: resources.ListInput[models.Device]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(dev models.Device) {
+				devices = append(devices, dev)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, resources.GetDevicesResponse{
+		IterableList: resources.IterableList[models.Device]{
+			NextBookmark: nextBookmark,
+			List:         devices,
+		},
+	})
+}
+
+func (r *devManagerHttpRoutes) StartDeviceGroupBulkAction(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.StartDeviceGroupBulkActionBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	action, err := r.svc.StartDeviceGroupBulkAction(ctx, services.StartDeviceGroupBulkActionInput{
+		GroupID:  params.ID,
+		Type:     requestBody.Type,
+		Metadata: requestBody.Metadata,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(201, action)
+}
+
+func (r *devManagerHttpRoutes) ApproveDeviceGroupBulkAction(ctx *gin.Context) {
+	type uriParams struct {
+		ID       string `uri:"id" binding:"required"`
+		ActionID string `uri:"aid" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	action, err := r.svc.ApproveDeviceGroupBulkAction(ctx, services.ApproveDeviceGroupBulkActionInput{
+		GroupID:  params.ID,
+		ActionID: params.ActionID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupBulkActionSelfApproval, errs.ErrDeviceGroupBulkActionUnverifiedApprover:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupBulkActionNotFound, errs.ErrDeviceGroupNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupBulkActionNotPending, errs.ErrDeviceGroupBulkActionApprovalExpired:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, action)
+}
+
+func (r *devManagerHttpRoutes) GetDeviceGroupBulkActions(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.DeviceGroupBulkActionFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	actions := []models.DeviceGroupBulkAction{}
+	nextBookmark, err := r.svc.GetDeviceGroupBulkActions(ctx, services.GetDeviceGroupBulkActionsInput{
+		GroupID: params.ID,
+		ListInput: resources.ListInput[models.DeviceGroupBulkAction]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(action models.DeviceGroupBulkAction) {
+				actions = append(actions, action)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, resources.GetDeviceGroupBulkActionsResponse{
+		IterableList: resources.IterableList[models.DeviceGroupBulkAction]{
+			NextBookmark: nextBookmark,
+			List:         actions,
+		},
+	})
+}
+
+func (r *devManagerHttpRoutes) GetDeviceGroupBulkActionByID(ctx *gin.Context) {
+	type uriParams struct {
+		ID       string `uri:"id" binding:"required"`
+		ActionID string `uri:"aid" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	action, err := r.svc.GetDeviceGroupBulkActionByID(ctx, services.GetDeviceGroupBulkActionByIDInput{
+		GroupID:  params.ID,
+		ActionID: params.ActionID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDeviceGroupBulkActionNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, action)
+}
@@ -1,7 +1,13 @@
 package controllers
 
 import (
+	"crypto/x509"
+	"errors"
+	"io"
+
 	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
@@ -28,8 +34,41 @@ func (r *dmsManagerHttpRoutes) GetStats(ctx *gin.Context) {
 	ctx.JSON(200, stats)
 }
 
+func (r *dmsManagerHttpRoutes) GetDMSEnrollmentStats(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	stats, err := r.svc.GetDMSEnrollmentStats(ctx, services.GetDMSEnrollmentStatsInput{
+		DMSID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	ctx.JSON(200, stats)
+}
+
 func (r *dmsManagerHttpRoutes) GetAllDMSs(ctx *gin.Context) {
-	queryParams := FilterQuery(ctx.Request, resources.DMSFiltrableFields)
+	queryParams, err := FilterQuery(ctx.Request, resources.DMSFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
 
 	dmss := []models.DMS{}
 	nextBookmark, err := r.svc.GetAll(ctx, services.GetAllInput{
@@ -74,6 +113,7 @@ func (r *dmsManagerHttpRoutes) GetDMSByID(ctx *gin.Context) {
 		return
 	}
 
+	ctx.Header("ETag", helpers.ETag(dms))
 	ctx.JSON(200, dms)
 }
 
@@ -89,12 +129,23 @@ func (r *dmsManagerHttpRoutes) CreateDMS(ctx *gin.Context) {
 		Metadata: requestBody.Metadata,
 		Name:     requestBody.Name,
 		Settings: requestBody.Settings,
+		CSR:      requestBody.CSR,
 	}
 
 	dms, err := r.svc.CreateDMS(ctx, input)
 
 	if err != nil {
-		ctx.AbortWithStatusJSON(500, gin.H{"err": err.Error()})
+		var keyConflict *errs.DMSPublicKeyConflictError
+		switch {
+		case errors.As(err, &keyConflict):
+			ctx.AbortWithStatusJSON(409, gin.H{"err": errs.ErrDMSPublicKeyConflict.Error(), "dms_id": keyConflict.DMSID})
+		case err == errs.ErrDMSAlreadyExists:
+			ctx.AbortWithStatusJSON(409, gin.H{"err": err.Error()})
+		case err == errs.ErrValidateBadRequest:
+			ctx.AbortWithStatusJSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.AbortWithStatusJSON(500, gin.H{"err": err.Error()})
+		}
 		return
 	}
 
@@ -118,15 +169,24 @@ func (r *dmsManagerHttpRoutes) UpdateDMS(ctx *gin.Context) {
 		return
 	}
 
-	ca, err := r.svc.UpdateDMS(ctx, services.UpdateDMSInput{
-		DMS: requestBody,
+	dms, err := r.svc.UpdateDMS(ctx, services.UpdateDMSInput{
+		DMS:     requestBody,
+		IfMatch: ctx.GetHeader("If-Match"),
 	})
 	if err != nil {
-		ctx.JSON(500, err)
+		switch err {
+		case errs.ErrPreconditionFailed:
+			ctx.JSON(412, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, err)
+		}
 		return
 	}
 
-	ctx.JSON(200, ca)
+	ctx.Header("ETag", helpers.ETag(dms))
+	ctx.JSON(200, dms)
 }
 
 func (r *dmsManagerHttpRoutes) BindIdentityToDevice(ctx *gin.Context) {
@@ -148,3 +208,821 @@ func (r *dmsManagerHttpRoutes) BindIdentityToDevice(ctx *gin.Context) {
 
 	ctx.JSON(200, bind)
 }
+
+func (r *dmsManagerHttpRoutes) RevokeSupersededCertificate(ctx *gin.Context) {
+	type uriParams struct {
+		SerialNumber string `uri:"sn" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	crt, err := r.svc.RevokeSupersededCertificate(ctx, services.RevokeSupersededCertificateInput{
+		SerialNumber: params.SerialNumber,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCertificateNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrDMSNoPendingSupersededRevocation, errs.ErrDMSSupersededGracePeriod:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, crt)
+}
+
+// RevokeDeviceCertificate is the self-service revocation endpoint of the DMS owners. The caller identity is checked
+// against the DMS owners by the service.
+func (r *dmsManagerHttpRoutes) RevokeDeviceCertificate(ctx *gin.Context) {
+	type uriParams struct {
+		ID           string `uri:"id" binding:"required"`
+		SerialNumber string `uri:"sn" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.RevokeDeviceCertificateBody
+	if ctx.Request.ContentLength != 0 {
+		if err := ctx.BindJSON(&requestBody); err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+	}
+
+	crt, err := r.svc.RevokeDeviceCertificate(ctx, services.RevokeDeviceCertificateInput{
+		DMSID:            params.ID,
+		SerialNumber:     params.SerialNumber,
+		RevocationReason: requestBody.RevocationReason,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSSelfServiceForbidden, errs.ErrDMSCertificateNotOwned:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound, errs.ErrCertificateNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, crt)
+}
+
+// StartCARotation starts the rotation campaign of a CA. The devices are forced to reenroll in batches, either by the
+// CA rotation job or by ProcessCARotationBatch.
+func (r *dmsManagerHttpRoutes) StartCARotation(ctx *gin.Context) {
+	var requestBody resources.StartCARotationBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	rotation, err := r.svc.StartCARotation(ctx, services.StartCARotationInput{
+		CAID:      requestBody.CAID,
+		BatchSize: requestBody.BatchSize,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrCAType, errs.ErrCAStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCARotationAlreadyStarted, errs.ErrCARotationCompleted:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, rotation)
+}
+
+func (r *dmsManagerHttpRoutes) ProcessCARotationBatch(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	rotation, err := r.svc.ProcessCARotationBatch(ctx, services.ProcessCARotationBatchInput{
+		CAID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound, errs.ErrCARotationNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrCARotationCompleted:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, rotation)
+}
+
+func (r *dmsManagerHttpRoutes) GetCARotation(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	rotation, err := r.svc.GetCARotation(ctx, services.GetCARotationInput{
+		CAID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrCANotFound, errs.ErrCARotationNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, rotation)
+}
+
+func (r *dmsManagerHttpRoutes) CreateACMEEABKey(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	credentials, err := r.svc.CreateACMEEABKey(ctx, services.CreateACMEEABKeyInput{
+		DMSID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrFeatureDisabled:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrDMSACMEEABNotConfigured:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, credentials)
+}
+
+func (r *dmsManagerHttpRoutes) GetACMEEABKeys(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	keys, err := r.svc.GetACMEEABKeys(ctx, services.GetACMEEABKeysInput{
+		DMSID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrFeatureDisabled:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, keys)
+}
+
+func (r *dmsManagerHttpRoutes) RevokeACMEEABKey(ctx *gin.Context) {
+	type uriParams struct {
+		ID    string `uri:"id" binding:"required"`
+		KeyID string `uri:"kid" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	key, err := r.svc.RevokeACMEEABKey(ctx, services.RevokeACMEEABKeyInput{
+		DMSID: params.ID,
+		KeyID: params.KeyID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrFeatureDisabled:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound, errs.ErrDMSACMEEABKeyNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		case errs.ErrDMSACMEEABKeyRevoked:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, key)
+}
+
+func (r *dmsManagerHttpRoutes) UpdateDMSStatus(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.UpdateDMSStatusBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	dms, err := r.svc.UpdateDMSStatus(ctx, services.UpdateDMSStatusInput{
+		ID:     params.ID,
+		Status: requestBody.Status,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrDMSInvalidStatus:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.Header("ETag", helpers.ETag(dms))
+	ctx.JSON(200, dms)
+}
+
+// PreregisterDevices accepts the device IDs as a JSON body, a CSV body (text/csv) or a CSV upload in the 'file'
+// field of a multipart form.
+func (r *dmsManagerHttpRoutes) CreateBootstrapToken(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	// the body is optional: tokens are not scoped to a device and use the default TTL if omitted
+	var requestBody resources.CreateBootstrapTokenBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	credentials, err := r.svc.CreateBootstrapToken(ctx, services.CreateBootstrapTokenInput{
+		DMSID:    params.ID,
+		DeviceID: requestBody.DeviceID,
+		TTL:      requestBody.TTL,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, credentials)
+}
+
+func (r *dmsManagerHttpRoutes) GetBootstrapTokens(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	tokens, err := r.svc.GetBootstrapTokens(ctx, services.GetBootstrapTokensInput{
+		DMSID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, tokens)
+}
+
+func (r *dmsManagerHttpRoutes) GetEnrollmentAudits(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.EnrollmentAuditFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	audits := []models.DMSEnrollmentAudit{}
+	nextBookmark, err := r.svc.GetEnrollmentAudits(ctx, services.GetEnrollmentAuditsInput{
+		DMSID: params.ID,
+		ListInput: resources.ListInput[models.DMSEnrollmentAudit]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(audit models.DMSEnrollmentAudit) {
+				audits = append(audits, audit)
+			},
+		},
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, resources.GetEnrollmentAuditsResponse{
+		IterableList: resources.IterableList[models.DMSEnrollmentAudit]{
+			NextBookmark: nextBookmark,
+			List:         audits,
+		},
+	})
+}
+
+func (r *dmsManagerHttpRoutes) RevokeBootstrapToken(ctx *gin.Context) {
+	type uriParams struct {
+		ID      string `uri:"id" binding:"required"`
+		TokenID string `uri:"tid" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	token, err := r.svc.RevokeBootstrapToken(ctx, services.RevokeBootstrapTokenInput{
+		DMSID:   params.ID,
+		TokenID: params.TokenID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSBootstrapTokenNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, token)
+}
+
+func (r *dmsManagerHttpRoutes) CreateClaimCode(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	// the body is optional: codes are not scoped to a device and use the default TTL if omitted
+	var requestBody resources.CreateClaimCodeBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil && !errors.Is(err, io.EOF) {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	credentials, err := r.svc.CreateClaimCode(ctx, services.CreateClaimCodeInput{
+		DMSID:    params.ID,
+		DeviceID: requestBody.DeviceID,
+		TTL:      requestBody.TTL,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, credentials)
+}
+
+func (r *dmsManagerHttpRoutes) GetClaimCodes(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	codes, err := r.svc.GetClaimCodes(ctx, services.GetClaimCodesInput{
+		DMSID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, codes)
+}
+
+func (r *dmsManagerHttpRoutes) RevokeClaimCode(ctx *gin.Context) {
+	type uriParams struct {
+		ID     string `uri:"id" binding:"required"`
+		CodeID string `uri:"cid" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	code, err := r.svc.RevokeClaimCode(ctx, services.RevokeClaimCodeInput{
+		DMSID:  params.ID,
+		CodeID: params.CodeID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSClaimCodeNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, code)
+}
+
+// ClaimDevice is the public endpoint enrolling a device with a claim code. The claim code authenticates the request.
+func (r *dmsManagerHttpRoutes) ClaimDevice(ctx *gin.Context) {
+	var requestBody resources.ClaimDeviceBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	claim, err := r.svc.ClaimDevice(ctx, services.ClaimDeviceInput{
+		Code:              requestBody.Code,
+		CSR:               (*x509.CertificateRequest)(requestBody.CSR),
+		InstallerMetadata: requestBody.InstallerMetadata,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest, errs.ErrDeviceInvalidID:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSInvalidClaimCode, errs.ErrDMSEnrollForbidden, errs.ErrDMSEnrollDeviceNotRegistered:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, claim)
+}
+
+func (r *dmsManagerHttpRoutes) PreregisterDevices(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var deviceIDs []string
+	switch ctx.ContentType() {
+	case "multipart/form-data":
+		file, err := ctx.FormFile("file")
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+
+		reader, err := file.Open()
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+		defer reader.Close()
+
+		deviceIDs, err = helpers.ParseDeviceIDsCSV(reader)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+	case "text/csv":
+		var err error
+		deviceIDs, err = helpers.ParseDeviceIDsCSV(ctx.Request.Body)
+		if err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+	default:
+		var requestBody resources.PreregisterDevicesBody
+		if err := ctx.BindJSON(&requestBody); err != nil {
+			ctx.JSON(400, gin.H{"err": err.Error()})
+			return
+		}
+		deviceIDs = requestBody.DeviceIDs
+	}
+
+	output, err := r.svc.PreregisterDevices(ctx, services.PreregisterDevicesInput{
+		DMSID:     params.ID,
+		DeviceIDs: deviceIDs,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, output)
+}
+
+func (r *dmsManagerHttpRoutes) CreateEnrollmentPolicy(ctx *gin.Context) {
+	var requestBody resources.CreateEnrollmentPolicyBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	policy, err := r.svc.CreateEnrollmentPolicy(ctx, services.CreateEnrollmentPolicyInput{
+		ID:           requestBody.ID,
+		Name:         requestBody.Name,
+		Description:  requestBody.Description,
+		SubjectRegex: requestBody.SubjectRegex,
+		KeyTypes:     requestBody.KeyTypes,
+		MinKeyBits:   requestBody.MinKeyBits,
+		AllowedCAIDs: requestBody.AllowedCAIDs,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSEnrollmentPolicyAlreadyExists:
+			ctx.JSON(409, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, policy)
+}
+
+func (r *dmsManagerHttpRoutes) GetEnrollmentPolicies(ctx *gin.Context) {
+	queryParams, err := FilterQuery(ctx.Request, resources.EnrollmentPolicyFiltrableFields)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	policies := []models.DMSEnrollmentPolicy{}
+	nextBookmark, err := r.svc.GetEnrollmentPolicies(ctx, services.GetEnrollmentPoliciesInput{
+		ListInput: resources.ListInput[models.DMSEnrollmentPolicy]{
+			QueryParameters: queryParams,
+			ExhaustiveRun:   false,
+			ApplyFunc: func(policy models.DMSEnrollmentPolicy) {
+				policies = append(policies, policy)
+			},
+		},
+	})
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.JSON(200, resources.GetEnrollmentPoliciesResponse{
+		IterableList: resources.IterableList[models.DMSEnrollmentPolicy]{
+			NextBookmark: nextBookmark,
+			List:         policies,
+		},
+	})
+}
+
+func (r *dmsManagerHttpRoutes) GetEnrollmentPolicyByID(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	policy, err := r.svc.GetEnrollmentPolicyByID(ctx, services.GetEnrollmentPolicyByIDInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSEnrollmentPolicyNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, policy)
+}
+
+func (r *dmsManagerHttpRoutes) UpdateEnrollmentPolicy(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody models.DMSEnrollmentPolicy
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+	requestBody.ID = params.ID
+
+	policy, err := r.svc.UpdateEnrollmentPolicy(ctx, services.UpdateEnrollmentPolicyInput{
+		Policy: requestBody,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSEnrollmentPolicyNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, policy)
+}
+
+func (r *dmsManagerHttpRoutes) DeleteEnrollmentPolicy(ctx *gin.Context) {
+	type uriParams struct {
+		ID string `uri:"id" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	err := r.svc.DeleteEnrollmentPolicy(ctx, services.DeleteEnrollmentPolicyInput{
+		ID: params.ID,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrDMSEnrollmentPolicyNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, gin.H{})
+}
@@ -4,6 +4,7 @@ import (
 	"bytes"
 	"context"
 	"crypto/x509"
+	"encoding/asn1"
 	"encoding/base64"
 	"errors"
 	"fmt"
@@ -13,10 +14,14 @@ import (
 	"net/textproto"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	"github.com/sirupsen/logrus"
 	"go.mozilla.org/pkcs7"
@@ -25,7 +30,8 @@ import (
 var lEst *logrus.Entry
 
 type estHttpRoutes struct {
-	svc services.ESTService
+	svc       services.ESTService
+	csrLimits config.CSRLimits
 }
 
 var (
@@ -36,10 +42,11 @@ var (
 	ErrorMissingClientCertificate error = errors.New("missing client certificate")
 )
 
-func NewESTHttpRoutes(logger *logrus.Entry, svc services.ESTService) *estHttpRoutes {
+func NewESTHttpRoutes(logger *logrus.Entry, svc services.ESTService, csrLimits config.CSRLimits) *estHttpRoutes {
 	lEst = logger
 	return &estHttpRoutes{
-		svc: svc,
+		svc:       svc,
+		csrLimits: csrLimits,
 	}
 }
 
@@ -51,31 +58,39 @@ func (r *estHttpRoutes) GetCACerts(ctx *gin.Context) {
 	var params aps
 	ctx.ShouldBindUri(&params)
 
-	cacerts, err := r.svc.CACerts(ctx, params.APS)
-	if err != nil {
-		ctx.JSON(500, err)
-	}
+	pemEncoded := ctx.Request.Header.Get("accept") == "application/x-pem-file"
 
-	if ctx.Request.Header.Get("accept") == "application/x-pem-file" {
-		casPEM := []string{}
-
-		for _, cert := range cacerts {
-			casPEM = append(casPEM, helpers.CertificateToPEM(cert))
+	var body []byte
+	if jwsSignatureRequested(ctx) {
+		signed, err := r.svc.SignedCACerts(ctx, params.APS, pemEncoded)
+		if err != nil {
+			ctx.JSON(500, err)
+			return
 		}
 
-		ctx.Writer.Header().Set("Content-Type", "application/x-pem-file")
-		ctx.Writer.Write([]byte(strings.Join(casPEM, "\n")))
-		return
-	}
+		body = signed.Content
+		ctx.Writer.Header().Set(models.JWSSignatureHeader, signed.Signature)
+	} else {
+		cacerts, err := r.svc.CACerts(ctx, params.APS)
+		if err != nil {
+			ctx.JSON(500, err)
+			return
+		}
 
-	cb := []byte{}
-	for _, cert := range cacerts {
-		cb = append(cb, cert.Raw...)
+		if pemEncoded {
+			body = helpers.CertificatesToPEMBundle(cacerts)
+		} else {
+			body, err = helpers.CertificatesToPKCS7(cacerts)
+			if err != nil {
+				ctx.JSON(500, err)
+				return
+			}
+		}
 	}
 
-	body, err := pkcs7.DegenerateCertificate(cb)
-	if err != nil {
-		ctx.JSON(500, err)
+	if pemEncoded {
+		ctx.Writer.Header().Set("Content-Type", "application/x-pem-file")
+		ctx.Writer.Write(body)
 		return
 	}
 
@@ -98,8 +113,15 @@ func (r *estHttpRoutes) EnrollReenroll(ctx *gin.Context) {
 		return
 	}
 
+	limitRequestBody(ctx, helpers.CSRRequestBodyLimit(r.csrLimits))
+
 	data, err := io.ReadAll(ctx.Request.Body)
 	if err != nil {
+		if isRequestBodyTooLarge(err) {
+			ctx.JSON(413, gin.H{"err": "body payload exceeds the certificate request size limit"})
+			return
+		}
+
 		ctx.JSON(400, gin.H{"err": fmt.Sprintf("could not read the body payload: %s", err)})
 		return
 	}
@@ -116,6 +138,12 @@ func (r *estHttpRoutes) EnrollReenroll(ctx *gin.Context) {
 		return
 	}
 
+	err = helpers.ValidateCertificateRequestLimits(csr, r.csrLimits)
+	if err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
 	if bitSize := ctx.GetHeader("Bit-Size"); bitSize != "" {
 		lEst.Debugf("Bit-Size header present with value %s", bitSize)
 		bitSizeInt, err := strconv.Atoi(bitSize)
@@ -131,21 +159,22 @@ func (r *estHttpRoutes) EnrollReenroll(ctx *gin.Context) {
 		switch keyType {
 		case x509.RSA.String():
 			lEst.Debugf("valid Key-Type header")
-			ctx.Set(models.ESTServerKeyGenBitSize, x509.RSA)
+			ctx.Set(models.ESTServerKeyGenKeyType, x509.RSA)
 		case x509.ECDSA.String():
 			lEst.Debugf("valid Key-Type header")
-			ctx.Set(models.ESTServerKeyGenBitSize, x509.ECDSA)
+			ctx.Set(models.ESTServerKeyGenKeyType, x509.ECDSA)
 		default:
 			lEst.Warnf("invalid Key-Type header")
 		}
 	}
 
-	var key any
-	var signedCrt *x509.Certificate
 	if strings.Contains(ctx.Request.URL.Path, "serverkeygen") {
-		signedCrt, key, err = r.svc.ServerKeyGen(ctx, csr, params.APS)
-		fmt.Println(key)
-	} else if strings.Contains(ctx.Request.URL.Path, "simplereenroll") {
+		r.serverKeyGen(ctx, csr, params.APS)
+		return
+	}
+
+	var signedCrt *x509.Certificate
+	if strings.Contains(ctx.Request.URL.Path, "simplereenroll") {
 		signedCrt, err = r.svc.Reenroll(ctx, csr, params.APS)
 	} else {
 		signedCrt, err = r.svc.Enroll(ctx, csr, params.APS)
@@ -175,6 +204,118 @@ func (r *estHttpRoutes) EnrollReenroll(ctx *gin.Context) {
 	ctx.Writer.Write(body)
 }
 
+// oidAsymmetricDecryptKeyIdentifier is the CSR attribute requesting the server generated key to be encrypted. See
+// RFC7030 4.4.1.2.
+var oidAsymmetricDecryptKeyIdentifier = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 16, 2, 54}
+
+// pkcs7EncryptLock serializes the encryptions with pkcs7.Encrypt, which reads its content encryption algorithm from
+// a package variable.
+var pkcs7EncryptLock sync.Mutex
+
+// encryptServerGeneratedKey wraps the generated key in a CMS EnvelopedData addressed to the recipient. The library
+// defaults to DES, which EST clients should not accept, hence AES-256-CBC is set for the call and the previous
+// algorithm is restored afterwards.
+func encryptServerGeneratedKey(keyDer []byte, recipient *x509.Certificate) ([]byte, error) {
+	pkcs7EncryptLock.Lock()
+	defer pkcs7EncryptLock.Unlock()
+
+	prevAlgorithm := pkcs7.ContentEncryptionAlgorithm
+	pkcs7.ContentEncryptionAlgorithm = pkcs7.EncryptionAlgorithmAES256CBC
+	defer func() {
+		pkcs7.ContentEncryptionAlgorithm = prevAlgorithm
+	}()
+
+	return pkcs7.Encrypt(keyDer, []*x509.Certificate{recipient})
+}
+
+// serverKeyGen returns the generated key and its certificate in a multipart response. See RFC7030 4.4.2. The key is
+// returned as a PKCS#8 structure, protected by the TLS session, unless the CSR requests the key to be encrypted, in
+// which case it is returned inside a CMS EnvelopedData addressed to the client certificate.
+func (r *estHttpRoutes) serverKeyGen(ctx *gin.Context, csr *x509.CertificateRequest, aps string) {
+	var keyRecipient *x509.Certificate
+	if csrHasAttribute(csr, oidAsymmetricDecryptKeyIdentifier) {
+		clientCert, hasValue := ctx.Value(string(identityextractors.IdentityExtractorClientCertificate)).(*x509.Certificate)
+		if !hasValue || clientCert.PublicKeyAlgorithm != x509.RSA {
+			ctx.JSON(400, gin.H{"err": "key encryption requires an RSA client certificate"})
+			return
+		}
+
+		keyRecipient = clientCert
+	}
+
+	signedCrt, key, err := r.svc.ServerKeyGen(ctx, csr, aps)
+	if err != nil {
+		switch {
+		case errors.Is(err, errs.ErrDMSServerKeyGenInvalidKeySize), errors.Is(err, errs.ErrDeviceInvalidID), errors.Is(err, errs.ErrDMSOnlyEST):
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errors.Is(err, errs.ErrDMSAuthModeNotSupported), errors.Is(err, errs.ErrDMSEnrollInvalidCert),
+			errors.Is(err, errs.ErrDMSEnrollExpiredCert), errors.Is(err, errs.ErrDMSEnrollRevokedCert),
+			errors.Is(err, errs.ErrDMSEnrollInvalidBootstrapToken):
+			ctx.JSON(401, gin.H{"err": err.Error()})
+		case errors.Is(err, errs.ErrDMSPendingApproval), errors.Is(err, errs.ErrDMSEnrollForbidden),
+			errors.Is(err, errs.ErrDMSEnrollDeviceNotRegistered):
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errors.Is(err, errs.ErrDMSNotFound):
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+		return
+	}
+
+	keyDer, err := x509.MarshalPKCS8PrivateKey(key)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	keyPart := MultipartPart{ContentType: "application/pkcs8", Data: keyDer}
+	if keyRecipient != nil {
+		envelope, err := encryptServerGeneratedKey(keyDer, keyRecipient)
+		if err != nil {
+			ctx.JSON(500, gin.H{"err": fmt.Sprintf("could not encrypt the generated key: %s", err)})
+			return
+		}
+
+		keyPart = MultipartPart{ContentType: "application/pkcs7-mime; smime-type=server-generated-key", Data: envelope}
+	}
+
+	body, contentType, err := EncodeMultiPart("estServerKeyGenBoundary", []MultipartPart{
+		keyPart,
+		{ContentType: "application/pkcs7-mime; smime-type=certs-only", Data: signedCrt},
+	})
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.Data(http.StatusOK, contentType, body.Bytes())
+}
+
+func csrHasAttribute(csr *x509.CertificateRequest, oid asn1.ObjectIdentifier) bool {
+	var tbs struct {
+		Version       int
+		Subject       asn1.RawValue
+		PublicKey     asn1.RawValue
+		RawAttributes []asn1.RawValue `asn1:"tag:0"`
+	}
+	if _, err := asn1.Unmarshal(csr.RawTBSCertificateRequest, &tbs); err != nil {
+		return false
+	}
+
+	for _, rawAttr := range tbs.RawAttributes {
+		var attr struct {
+			Type   asn1.ObjectIdentifier
+			Values asn1.RawValue
+		}
+		if _, err := asn1.Unmarshal(rawAttr.FullBytes, &attr); err == nil && attr.Type.Equal(oid) {
+			return true
+		}
+	}
+
+	return false
+}
+
 type MultipartPart struct {
 	ContentType string
 	Data        interface{}
@@ -0,0 +1,68 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/featureflags"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+)
+
+type featureFlagsHttpRoutes struct {
+	flags *featureflags.FeatureFlags
+}
+
+func NewFeatureFlagsHttpRoutes(flags *featureflags.FeatureFlags) *featureFlagsHttpRoutes {
+	return &featureFlagsHttpRoutes{
+		flags: flags,
+	}
+}
+
+func (r *featureFlagsHttpRoutes) response() resources.FeatureFlagsResponse {
+	flags := map[string]bool{}
+	for flag, enabled := range r.flags.List() {
+		flags[string(flag)] = enabled
+	}
+
+	return resources.FeatureFlagsResponse{
+		RuntimeToggles: r.flags.RuntimeToggles(),
+		Flags:          flags,
+	}
+}
+
+func (r *featureFlagsHttpRoutes) GetFeatureFlags(ctx *gin.Context) {
+	ctx.JSON(200, r.response())
+}
+
+func (r *featureFlagsHttpRoutes) ToggleFeatureFlag(ctx *gin.Context) {
+	type uriParams struct {
+		Flag string `uri:"flag" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	var requestBody resources.ToggleFeatureFlagBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	err := r.flags.Toggle(featureflags.Flag(params.Flag), requestBody.Enabled)
+	if err != nil {
+		switch err {
+		case errs.ErrFeatureFlagToggleNotAllowed:
+			ctx.JSON(403, gin.H{"err": err.Error()})
+		case errs.ErrFeatureFlagNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, r.response())
+}
@@ -5,21 +5,33 @@ import (
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 )
 
+// HealthDetails adds service specific fields to the health check response. The health check keeps returning 200,
+// the details are informative.
+type HealthDetails map[string]func() interface{}
+
 type hcheckRoute struct {
-	info models.APIServiceInfo
+	info    models.APIServiceInfo
+	details HealthDetails
 }
 
-func NewHealthCheckRoute(info models.APIServiceInfo) *hcheckRoute {
+func NewHealthCheckRoute(info models.APIServiceInfo, details HealthDetails) *hcheckRoute {
 	return &hcheckRoute{
-		info: info,
+		info:    info,
+		details: details,
 	}
 }
 
 func (r *hcheckRoute) HealthCheck(ctx *gin.Context) {
-	ctx.JSON(200, gin.H{
+	response := gin.H{
 		"health":     true,
 		"version":    r.info.Version,
 		"build":      r.info.BuildSHA,
 		"build_time": r.info.BuildTime,
-	})
+	}
+
+	for name, details := range r.details {
+		response[name] = details()
+	}
+
+	ctx.JSON(200, response)
 }
@@ -0,0 +1,59 @@
+package controllers
+
+import (
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type internalCertificateHttpRoutes struct {
+	svc services.InternalCertificateService
+}
+
+func NewInternalCertificateHttpRoutes(svc services.InternalCertificateService) *internalCertificateHttpRoutes {
+	return &internalCertificateHttpRoutes{
+		svc: svc,
+	}
+}
+
+// @Summary Issue Internal Certificate
+// @Description Issue a short-lived client certificate for the service identity bound to the bearer token
+// @Accept json
+// @Produce json
+// @Param request body resources.IssueInternalCertificateBody true "Certificate request of the service key"
+// @Success 201 {object} models.InternalCertificate
+// @Failure 400 {string} string "Struct Validation error"
+// @Failure 401 {string} string "Invalid token"
+// @Failure 500 {string} string "Internal Server error"
+// @Router /v1/internal/certificates [post]
+func (r *internalCertificateHttpRoutes) IssueInternalCertificate(ctx *gin.Context) {
+	token, _ := strings.CutPrefix(ctx.GetHeader("authorization"), "Bearer ")
+
+	var requestBody resources.IssueInternalCertificateBody
+	if err := ctx.ShouldBindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	crt, err := r.svc.IssueInternalCertificate(ctx, services.IssueInternalCertificateInput{
+		Token:       token,
+		CertRequest: requestBody.CertRequest,
+	})
+	if err != nil {
+		switch err {
+		case errs.ErrInternalCertificateInvalidToken:
+			ctx.JSON(401, gin.H{"err": err.Error()})
+		case errs.ErrValidateBadRequest:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(201, crt)
+}
@@ -0,0 +1,92 @@
+package controllers
+
+import (
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+)
+
+type logLevelsHttpRoutes struct {
+	serviceID string
+}
+
+func NewLogLevelsHttpRoutes(serviceID string) *logLevelsHttpRoutes {
+	return &logLevelsHttpRoutes{
+		serviceID: serviceID,
+	}
+}
+
+func (r *logLevelsHttpRoutes) response() resources.LogLevelsResponse {
+	global, levels := helpers.GetLogLevels(r.serviceID)
+
+	subsystems := map[string]string{}
+	for subsystem, level := range levels {
+		subsystems[subsystem] = string(level)
+	}
+
+	return resources.LogLevelsResponse{
+		Global:     string(global),
+		Subsystems: subsystems,
+	}
+}
+
+func (r *logLevelsHttpRoutes) GetLogLevels(ctx *gin.Context) {
+	ctx.JSON(200, r.response())
+}
+
+func (r *logLevelsHttpRoutes) SetGlobalLogLevel(ctx *gin.Context) {
+	r.setLogLevel(ctx, "")
+}
+
+func (r *logLevelsHttpRoutes) SetSubsystemLogLevel(ctx *gin.Context) {
+	type uriParams struct {
+		Subsystem string `uri:"subsystem" binding:"required"`
+	}
+
+	var params uriParams
+	if err := ctx.ShouldBindUri(&params); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	r.setLogLevel(ctx, params.Subsystem)
+}
+
+func (r *logLevelsHttpRoutes) setLogLevel(ctx *gin.Context, subsystem string) {
+	var requestBody resources.SetLogLevelBody
+	if err := ctx.BindJSON(&requestBody); err != nil {
+		ctx.JSON(400, gin.H{"err": err.Error()})
+		return
+	}
+
+	duration := time.Duration(0)
+	if requestBody.Duration != "" {
+		var err error
+		duration, err = models.ParseDuration(requestBody.Duration)
+		if err != nil || duration <= 0 {
+			ctx.JSON(400, gin.H{"err": errs.ErrLogLevelDurationInvalid.Error()})
+			return
+		}
+	}
+
+	err := helpers.SetLogLevel(r.serviceID, subsystem, config.LogLevel(requestBody.Level), duration)
+	if err != nil {
+		switch err {
+		case errs.ErrLogLevelInvalid:
+			ctx.JSON(400, gin.H{"err": err.Error()})
+		case errs.ErrLogSubsystemNotFound:
+			ctx.JSON(404, gin.H{"err": err.Error()})
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, r.response())
+}
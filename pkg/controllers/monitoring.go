@@ -0,0 +1,119 @@
+package controllers
+
+import (
+	"bytes"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/monitoring"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type caMonitoringHttpRoutes struct {
+	svc  services.CAService
+	conf config.CAConfig
+}
+
+func NewCAMonitoringHttpRoutes(svc services.CAService, conf config.CAConfig) *caMonitoringHttpRoutes {
+	return &caMonitoringHttpRoutes{
+		svc:  svc,
+		conf: conf,
+	}
+}
+
+func (r *caMonitoringHttpRoutes) getAllCAs(ctx *gin.Context) ([]models.CACertificate, error) {
+	cas := []models.CACertificate{}
+	_, err := r.svc.GetCAs(ctx, services.GetCAsInput{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			cas = append(cas, ca)
+		},
+		QueryParameters: &resources.QueryParameters{},
+	})
+
+	return cas, err
+}
+
+// GetAlertingRules returns the Prometheus alerting rules (YAML) matching the live CA service configuration.
+func (r *caMonitoringHttpRoutes) GetAlertingRules(ctx *gin.Context) {
+	cas, err := r.getAllCAs(ctx)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	rules := monitoring.BuildAlertingRules(monitoring.AlertingRulesInput{
+		ServiceID:         "ca",
+		CAs:               cas,
+		PublisherEventBus: r.conf.PublisherEventBus,
+	})
+
+	ctx.YAML(200, rules)
+}
+
+// GetMetrics exposes the CA service metrics in the Prometheus text format.
+func (r *caMonitoringHttpRoutes) GetMetrics(ctx *gin.Context) {
+	cas, err := r.getAllCAs(ctx)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	var buf bytes.Buffer
+	err = monitoring.WriteCAMetrics(&buf, cas)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.Data(200, "text/plain; version=0.0.4", buf.Bytes())
+}
+
+type dmsMonitoringHttpRoutes struct {
+	svc services.DMSManagerService
+}
+
+func NewDMSManagerMonitoringHttpRoutes(svc services.DMSManagerService) *dmsMonitoringHttpRoutes {
+	return &dmsMonitoringHttpRoutes{
+		svc: svc,
+	}
+}
+
+// GetMetrics exposes the enrollment counters of the DMSs in the Prometheus text format.
+func (r *dmsMonitoringHttpRoutes) GetMetrics(ctx *gin.Context) {
+	dmss := []models.DMS{}
+	_, err := r.svc.GetAll(ctx, services.GetAllInput{
+		ListInput: resources.ListInput[models.DMS]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(dms models.DMS) {
+				dmss = append(dmss, dms)
+			},
+			QueryParameters: &resources.QueryParameters{},
+		},
+	})
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	stats := []models.DMSEnrollmentStats{}
+	for _, dms := range dmss {
+		dmsStats, err := r.svc.GetDMSEnrollmentStats(ctx, services.GetDMSEnrollmentStatsInput{DMSID: dms.ID})
+		if err != nil {
+			ctx.JSON(500, gin.H{"err": err.Error()})
+			return
+		}
+		stats = append(stats, *dmsStats)
+	}
+
+	var buf bytes.Buffer
+	err = monitoring.WriteDMSEnrollmentMetrics(&buf, stats)
+	if err != nil {
+		ctx.JSON(500, gin.H{"err": err.Error()})
+		return
+	}
+
+	ctx.Data(200, "text/plain; version=0.0.4", buf.Bytes())
+}
@@ -0,0 +1,40 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+)
+
+type reconciliationHttpRoutes struct {
+	reconciler *connectors.CertificateStatusReconciler
+}
+
+func NewReconciliationHttpRoutes(reconciler *connectors.CertificateStatusReconciler) *reconciliationHttpRoutes {
+	return &reconciliationHttpRoutes{
+		reconciler: reconciler,
+	}
+}
+
+func (r *reconciliationHttpRoutes) GetLastCertificateReconciliation(ctx *gin.Context) {
+	report := r.reconciler.LastReport()
+	if report == nil {
+		ctx.JSON(404, gin.H{"err": "no certificate reconciliation has completed yet"})
+		return
+	}
+
+	ctx.JSON(200, report)
+}
+
+func (r *reconciliationHttpRoutes) ReconcileCertificates(ctx *gin.Context) {
+	report, err := r.reconciler.Reconcile(ctx)
+	if err != nil {
+		switch err {
+		default:
+			ctx.JSON(500, gin.H{"err": err.Error()})
+		}
+
+		return
+	}
+
+	ctx.JSON(200, report)
+}
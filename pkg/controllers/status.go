@@ -0,0 +1,29 @@
+package controllers
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+type statusHttpRoutes struct {
+	monitor *health.Monitor
+}
+
+func NewStatusHttpRoutes(monitor *health.Monitor) *statusHttpRoutes {
+	return &statusHttpRoutes{
+		monitor: monitor,
+	}
+}
+
+// GetStatus checks the dependencies of the service. The response is 503 while the service is unhealthy, so it can
+// be used as readiness probe.
+func (r *statusHttpRoutes) GetStatus(ctx *gin.Context) {
+	status := r.monitor.Status(ctx)
+	if status.Status == models.HealthStatusUnhealthy {
+		ctx.JSON(503, status)
+		return
+	}
+
+	ctx.JSON(200, status)
+}
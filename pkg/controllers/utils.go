@@ -1,132 +1,32 @@
 package controllers
 
 import (
+	"errors"
 	"net/http"
-	"strconv"
-	"strings"
 
+	"github.com/gin-gonic/gin"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 )
 
-func FilterQuery(r *http.Request, filterFieldMap map[string]resources.FilterFieldType) *resources.QueryParameters {
-	queryParams := resources.QueryParameters{
-		NextBookmark: "",
-		Filters:      []resources.FilterOption{},
-		PageSize:     25,
-	}
-
-	if len(r.URL.RawQuery) > 0 {
-		values := r.URL.Query()
-		for k, v := range values {
-			switch k {
-			case "sort_by":
-				value := v[len(v)-1] //only get last
-				sortQueryParam := value
-				sortField := strings.Trim(sortQueryParam, " ")
-
-				_, exists := filterFieldMap[sortField]
-				if exists {
-					queryParams.Sort.SortField = sortField
-				}
-
-			case "sort_mode":
-				value := v[len(v)-1] //only get last
-				sortQueryParam := value
-				sortMode := resources.SortModeAsc
-
-				if sortQueryParam == "desc" {
-					sortMode = resources.SortModeDesc
-				}
-
-				queryParams.Sort.SortMode = sortMode
-
-			case "page_size":
-				value := v[len(v)-1] //only get last
-				pageS, err := strconv.Atoi(value)
-				if err == nil {
-					queryParams.PageSize = pageS
-				}
-
-			case "bookmark":
-				value := v[len(v)-1] //only get last
-				queryParams.NextBookmark = value
-
-			case "filter":
-				for _, value := range v {
-					bs := strings.Index(value, "[")
-					es := strings.Index(value, "]")
-					if bs != -1 && es != -1 && bs < es {
-						field, rest, _ := strings.Cut(value, "[")
-						operand, arg, _ := strings.Cut(rest, "]")
-						operand = strings.ToLower(operand)
-
-						fieldOperandType, exists := filterFieldMap[field]
-						if !exists {
-							continue
-						}
-
-						var filterOperand resources.FilterOperation
-						switch fieldOperandType {
-						case resources.StringFilterFieldType:
-							switch operand {
-							case "eq", "equal":
-								filterOperand = resources.StringEqual
-							case "ne", "notequal":
-								filterOperand = resources.StringNotEqual
-							case "ct", "contains":
-								filterOperand = resources.StringContains
-							case "nc", "notcontains":
-								filterOperand = resources.StringNotContains
-							}
-
-						case resources.StringArrayFilterFieldType:
-							filterOperand = resources.StringArrayContains
+// FilterQuery parses the pagination, sorting and filtering query parameters of a list request. Invalid filters
+// are returned as an error, so list requests are never run without the filters the caller asked for.
+func FilterQuery(r *http.Request, filterFieldMap map[string]resources.FilterFieldType) (*resources.QueryParameters, error) {
+	return resources.ParseQueryParameters(r.URL.Query(), filterFieldMap)
+}
 
-						case resources.DateFilterFieldType:
-							switch operand {
-							case "bf", "before":
-								filterOperand = resources.DateBefore
-							case "eq", "equal":
-								filterOperand = resources.DateEqual
-							case "af", "after":
-								filterOperand = resources.DateAfter
-							}
-						case resources.NumberFilterFieldType:
-							switch operand {
-							case "eq", "equal":
-								filterOperand = resources.NumberEqual
-							case "ne", "notequal":
-								filterOperand = resources.NumberNotEqual
-							case "lt", "lessthan":
-								filterOperand = resources.NumberLessThan
-							case "le", "lessequal", "lessorequal":
-								filterOperand = resources.NumberLessOrEqualThan
-							case "gt", "greaterthan":
-								filterOperand = resources.NumberGreaterThan
-							case "ge", "greaterequal", "greaterorequal":
-								filterOperand = resources.NumberGreaterOrEqualThan
-							}
-						case resources.EnumFilterFieldType:
-							switch operand {
-							case "eq", "equal":
-								filterOperand = resources.EnumEqual
-							case "ne", "notequal":
-								filterOperand = resources.EnumNotEqual
-							}
-						}
-						if exists {
-							queryParams.Filters = append(queryParams.Filters, resources.FilterOption{
-								Field:           field,
-								Value:           arg,
-								FilterOperation: filterOperand,
-							})
-						}
-					}
+// jwsSignatureRequested reports whether the client asked, with the "signature=jws" query parameter, for the
+// detached JWS of the response body in the models.JWSSignatureHeader header.
+func jwsSignatureRequested(ctx *gin.Context) bool {
+	return ctx.Query("signature") == "jws"
+}
 
-				}
-			}
-		}
-	}
+// limitRequestBody bounds the bytes the handler can read from the request body. Reading beyond the limit fails with
+// an error detected by isRequestBodyTooLarge.
+func limitRequestBody(ctx *gin.Context, limit int64) {
+	ctx.Request.Body = http.MaxBytesReader(ctx.Writer, ctx.Request.Body, limit)
+}
 
-	return &queryParams
+func isRequestBodyTooLarge(err error) bool {
+	var maxBytesErr *http.MaxBytesError
+	return errors.As(err, &maxBytesErr)
 }
@@ -2,11 +2,14 @@ package controllers
 
 import (
 	"encoding/base64"
+	"errors"
 	"fmt"
 	"io"
 	"net/url"
 
 	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	"github.com/sirupsen/logrus"
 	"golang.org/x/crypto/ocsp"
@@ -91,17 +94,23 @@ func (r *vaHttpRoutes) Verify(ctx *gin.Context) {
 		return
 	}
 
-	ocsp, err := ocsp.ParseRequest([]byte(ocspReqString))
+	// RFC 6960 errors are reported with an unsigned OCSP response carrying the error status, not with the HTTP status code.
+	ocspReq, err := ocsp.ParseRequest([]byte(ocspReqString))
 	if err != nil {
 		r.logger.Errorf("could not parse ocsp request: %s", err)
-		ctx.AbortWithError(400, err)
+		ctx.Data(200, "application/ocsp-response", ocsp.MalformedRequestErrorResponse)
 		return
 	}
 
-	response, err := r.ocsp.Verify(ctx, ocsp)
+	response, err := r.ocsp.Verify(ctx, ocspReq)
 	if err != nil {
 		r.logger.Errorf("something went wrong while verifying ocsp request: %s", err)
-		ctx.AbortWithError(500, err)
+		switch {
+		case errors.Is(err, errs.ErrCertificateNotFound), errors.Is(err, errs.ErrCANotFound), errors.Is(err, errs.ErrOCSPIssuerMismatch):
+			ctx.Data(200, "application/ocsp-response", ocsp.UnauthorizedErrorResponse)
+		default:
+			ctx.Data(200, "application/ocsp-response", ocsp.InternalErrorErrorResponse)
+		}
 		return
 	}
 
@@ -119,9 +128,22 @@ func (r *vaHttpRoutes) CRL(ctx *gin.Context) {
 		return
 	}
 
-	crl, err := r.crl.GetCRL(ctx, services.GetCRLInput{
+	input := services.GetCRLInput{
 		CAID: params.ID,
-	})
+	}
+
+	var crl []byte
+	var err error
+	if jwsSignatureRequested(ctx) {
+		var signed *models.SignedContent
+		signed, err = r.crl.GetSignedCRL(ctx, input)
+		if err == nil {
+			crl = signed.Content
+			ctx.Header(models.JWSSignatureHeader, signed.Signature)
+		}
+	} else {
+		crl, err = r.crl.GetCRL(ctx, input)
+	}
 	if err != nil {
 		r.logger.Errorf("something went wrong while getting crl list: %s", err)
 		ctx.AbortWithError(500, err)
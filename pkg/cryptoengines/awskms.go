@@ -24,14 +24,28 @@ import (
 var lAWSKMS *logrus.Entry
 
 type AWSKMSCryptoEngine struct {
-	config    models.CryptoEngineInfo
-	kmscli    *kms.Client
-	kmsConfig aws.Config
+	config         models.CryptoEngineInfo
+	kmscli         *kms.Client
+	kmsConfig      aws.Config
+	multiRegion    bool
+	replicaRegions []string
 }
 
 func NewAWSKMSEngine(logger *logrus.Entry, awsConf aws.Config, metadata map[string]any) (CryptoEngine, error) {
+	return NewAWSKMSMultiRegionEngine(logger, awsConf, metadata, false, nil)
+}
+
+// NewAWSKMSMultiRegionEngine creates a KMS engine that, when multiRegion is enabled, creates the keys as
+// multi-region primary keys and replicates them (including their aliases) to each of the replicaRegions.
+// Signing always uses the key (primary or replica) available in the region of awsConf, so
+// instances deployed in a replica region sign against their nearest replica.
+func NewAWSKMSMultiRegionEngine(logger *logrus.Entry, awsConf aws.Config, metadata map[string]any, multiRegion bool, replicaRegions []string) (CryptoEngine, error) {
 	lAWSKMS = logger.WithField("subsystem-provider", "AWS-KMS")
 
+	if !multiRegion && len(replicaRegions) > 0 {
+		return nil, fmt.Errorf("replica regions can only be used with multi-region keys")
+	}
+
 	httpCli, err := helpers.BuildHTTPClientWithTracerLogger(&http.Client{}, lAWSKMS)
 	if err != nil {
 		return nil, err
@@ -41,8 +55,10 @@ func NewAWSKMSEngine(logger *logrus.Entry, awsConf aws.Config, metadata map[stri
 	kmscli := kms.NewFromConfig(awsConf)
 
 	return &AWSKMSCryptoEngine{
-		kmscli:    kmscli,
-		kmsConfig: awsConf,
+		kmscli:         kmscli,
+		kmsConfig:      awsConf,
+		multiRegion:    multiRegion,
+		replicaRegions: replicaRegions,
 		config: models.CryptoEngineInfo{
 			Type:          models.AWSKMS,
 			SecurityLevel: models.SL2,
@@ -138,27 +154,12 @@ func (p *AWSKMSCryptoEngine) CreateRSAPrivateKey(keySize int, keyID string) (cry
 		return nil, err
 	}
 
-	key, err := p.kmscli.CreateKey(context.Background(), &kms.CreateKeyInput{
-		KeyUsage: types.KeyUsageTypeSignVerify,
-		KeySpec:  keySpec,
-	})
-
+	err := p.createKey(keySpec, keyID)
 	if err != nil {
 		lAWSKMS.Errorf("could not create '%s' RSA Private Key: %s", keyID, err)
 		return nil, err
 	}
 
-	lAWSKMS.Debugf("RSA key created with ARN [%s]", *key.KeyMetadata.Arn)
-
-	_, err = p.kmscli.CreateAlias(context.Background(), &kms.CreateAliasInput{
-		AliasName:   aws.String(fmt.Sprintf("alias/%s", keyID)),
-		TargetKeyId: key.KeyMetadata.Arn,
-	})
-
-	if err != nil {
-		lAWSKMS.Warnf("Could not create alias for key ARN [%s]: %s", *key.KeyMetadata.Arn, err)
-	}
-
 	return p.GetPrivateKeyByID(keyID)
 }
 
@@ -180,28 +181,69 @@ func (p *AWSKMSCryptoEngine) CreateECDSAPrivateKey(curve elliptic.Curve, keyID s
 		return nil, err
 	}
 
-	key, err := p.kmscli.CreateKey(context.Background(), &kms.CreateKeyInput{
-		KeyUsage: types.KeyUsageTypeSignVerify,
-		KeySpec:  keySpec,
-	})
-
+	err := p.createKey(keySpec, keyID)
 	if err != nil {
 		lAWSKMS.Errorf("could not create '%s' ECDSA Private Key: %s", keyID, err)
 		return nil, err
 	}
 
-	lAWSKMS.Debugf("ECDSA key created with ARN [%s]", *key.KeyMetadata.Arn)
+	return p.GetPrivateKeyByID(keyID)
+}
+
+func (p *AWSKMSCryptoEngine) createKey(keySpec types.KeySpec, keyID string) error {
+	key, err := p.kmscli.CreateKey(context.Background(), &kms.CreateKeyInput{
+		KeyUsage:    types.KeyUsageTypeSignVerify,
+		KeySpec:     keySpec,
+		MultiRegion: aws.Bool(p.multiRegion),
+	})
+	if err != nil {
+		return err
+	}
+
+	lAWSKMS.Debugf("key created with ARN [%s]", *key.KeyMetadata.Arn)
 
 	_, err = p.kmscli.CreateAlias(context.Background(), &kms.CreateAliasInput{
 		AliasName:   aws.String(fmt.Sprintf("alias/%s", keyID)),
 		TargetKeyId: key.KeyMetadata.Arn,
 	})
-
 	if err != nil {
 		lAWSKMS.Warnf("Could not create alias for key ARN [%s]: %s", *key.KeyMetadata.Arn, err)
 	}
 
-	return p.GetPrivateKeyByID(keyID)
+	for _, region := range p.replicaRegions {
+		err = p.replicateKey(*key.KeyMetadata.Arn, keyID, region)
+		if err != nil {
+			lAWSKMS.Errorf("could not replicate key ARN [%s] to region %s: %s", *key.KeyMetadata.Arn, region, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+func (p *AWSKMSCryptoEngine) replicateKey(keyArn string, keyID string, region string) error {
+	replica, err := p.kmscli.ReplicateKey(context.Background(), &kms.ReplicateKeyInput{
+		KeyId:         aws.String(keyArn),
+		ReplicaRegion: aws.String(region),
+	})
+	if err != nil {
+		return err
+	}
+
+	lAWSKMS.Debugf("key replicated to region %s with ARN [%s]", region, *replica.ReplicaKeyMetadata.Arn)
+
+	// Aliases are not replicated by KMS and must be created in each region.
+	_, err = p.kmscli.CreateAlias(context.Background(), &kms.CreateAliasInput{
+		AliasName:   aws.String(fmt.Sprintf("alias/%s", keyID)),
+		TargetKeyId: replica.ReplicaKeyMetadata.Arn,
+	}, func(o *kms.Options) {
+		o.Region = region
+	})
+	if err != nil {
+		lAWSKMS.Warnf("Could not create alias for replica key ARN [%s]: %s", *replica.ReplicaKeyMetadata.Arn, err)
+	}
+
+	return nil
 }
 
 func (p *AWSKMSCryptoEngine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string) (crypto.Signer, error) {
@@ -55,6 +55,20 @@ func TestNewAWSKMSEngine(t *testing.T) {
 
 	assert.Equal(t, expectedConfig, engine.GetEngineConfig())
 }
+func TestNewAWSKMSMultiRegionEngine(t *testing.T) {
+	logger := logrus.New().WithField("test", "NewAWSKMSMultiRegionEngine")
+
+	engine, err := NewAWSKMSMultiRegionEngine(logger, aws.Config{}, map[string]interface{}{}, true, []string{"eu-west-1", "us-east-1"})
+	assert.NoError(t, err)
+
+	kmsEngine := engine.(*AWSKMSCryptoEngine)
+	assert.True(t, kmsEngine.multiRegion)
+	assert.Equal(t, []string{"eu-west-1", "us-east-1"}, kmsEngine.replicaRegions)
+
+	_, err = NewAWSKMSMultiRegionEngine(logger, aws.Config{}, map[string]interface{}{}, false, []string{"eu-west-1"})
+	assert.Error(t, err)
+}
+
 func testDeleteKeyOnKMS(t *testing.T, engine CryptoEngine) {
 	awsengine := engine.(*AWSKMSCryptoEngine)
 	err := awsengine.DeleteKey("test-key")
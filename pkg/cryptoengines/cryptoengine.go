@@ -5,6 +5,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rsa"
+	"errors"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 )
@@ -20,3 +21,13 @@ type CryptoEngine interface {
 	ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string) (crypto.Signer, error)
 	ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID string) (crypto.Signer, error)
 }
+
+// ErrKeyBackupNotSupported is returned by the engines that can not export their keys encrypted.
+var ErrKeyBackupNotSupported = errors.New("crypto engine does not support encrypted key backups")
+
+// KeyBackupEngine is implemented by the engines able to export their keys encrypted, so they can be backed up
+// outside the engine and restored later with the regular import functions.
+type KeyBackupEngine interface {
+	ExportKey(keyID string) ([]byte, error)
+	OpenKeyBackup(keyID string, backup []byte) (crypto.Signer, error)
+}
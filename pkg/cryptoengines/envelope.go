@@ -0,0 +1,295 @@
+package cryptoengines
+
+import (
+	"context"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/pem"
+	"fmt"
+	"net/http"
+	"strings"
+
+	"github.com/aws/aws-sdk-go-v2/aws"
+	"github.com/aws/aws-sdk-go-v2/service/kms"
+	"github.com/hashicorp/vault/api"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	encryptedKeyPEMType       = "LAMASSU ENCRYPTED PRIVATE KEY"
+	encryptedKeyKEKHeader     = "KEK"
+	encryptedKeyWrappedHeader = "Wrapped-Key"
+	dataKeySize               = 32
+)
+
+// KeyEncryptionKey wraps and unwraps the data keys used to encrypt the private keys stored by an engine.
+type KeyEncryptionKey interface {
+	// ID identifies the KEK. It is stored next to each wrapped data key so keys encrypted with another KEK
+	// are detected before trying to unwrap them.
+	ID() string
+	WrapKey(dataKey []byte) ([]byte, error)
+	UnwrapKey(wrappedKey []byte) ([]byte, error)
+}
+
+func NewKeyEncryptionKey(logger *logrus.Entry, conf config.GolangEngineEncryption) (KeyEncryptionKey, error) {
+	switch conf.Provider {
+	case config.GolangKEKStatic:
+		key, err := base64.StdEncoding.DecodeString(string(conf.StaticKey))
+		if err != nil {
+			return nil, fmt.Errorf("could not decode static KEK: %w", err)
+		}
+
+		return newStaticKEK(key)
+	case config.GolangKEKHashicorpVault:
+		if conf.HashicorpVault == nil || conf.HashicorpVault.KeyName == "" {
+			return nil, fmt.Errorf("hashicorp_vault KEK requires a transit key name")
+		}
+
+		client, err := NewVaultAPIClient(logger, conf.HashicorpVault.HashicorpVaultSDK)
+		if err != nil {
+			return nil, err
+		}
+
+		mount := strings.Trim(conf.HashicorpVault.TransitMountPath, "/")
+		if mount == "" {
+			mount = vaultDefaultTransitMountPath
+		}
+
+		return &vaultTransitKEK{client: client, mount: mount, name: conf.HashicorpVault.KeyName}, nil
+	case config.GolangKEKAWSKMS:
+		if conf.AWSKMS == nil || conf.AWSKMS.KeyID == "" {
+			return nil, fmt.Errorf("aws_kms KEK requires a KMS key id")
+		}
+
+		awsCfg, err := config.GetAwsSdkConfig(conf.AWSKMS.AWSSDKConfig)
+		if err != nil {
+			return nil, err
+		}
+
+		httpCli, err := helpers.BuildHTTPClientWithTracerLogger(&http.Client{}, logger)
+		if err != nil {
+			return nil, err
+		}
+
+		awsCfg.HTTPClient = httpCli
+		return &awsKMSKEK{client: kms.NewFromConfig(*awsCfg), keyID: conf.AWSKMS.KeyID}, nil
+	default:
+		return nil, fmt.Errorf("unsupported KEK provider '%s'", conf.Provider)
+	}
+}
+
+type staticKEK struct {
+	id   string
+	aead cipher.AEAD
+}
+
+func newStaticKEK(key []byte) (KeyEncryptionKey, error) {
+	if len(key) != dataKeySize {
+		return nil, fmt.Errorf("static KEK must be %d bytes long, got %d", dataKeySize, len(key))
+	}
+
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	fingerprint := sha256.Sum256(key)
+	return &staticKEK{
+		id:   "static:" + hex.EncodeToString(fingerprint[:8]),
+		aead: aead,
+	}, nil
+}
+
+func (k *staticKEK) ID() string {
+	return k.id
+}
+
+func (k *staticKEK) WrapKey(dataKey []byte) ([]byte, error) {
+	return sealAEAD(k.aead, dataKey, nil)
+}
+
+func (k *staticKEK) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	return openAEAD(k.aead, wrappedKey, nil)
+}
+
+type vaultTransitKEK struct {
+	client *api.Client
+	mount  string
+	name   string
+}
+
+func (k *vaultTransitKEK) ID() string {
+	return "hashicorp_vault:" + k.mount + "/" + k.name
+}
+
+func (k *vaultTransitKEK) WrapKey(dataKey []byte) ([]byte, error) {
+	secret, err := k.client.Logical().Write(fmt.Sprintf("%s/encrypt/%s", k.mount, k.name), map[string]interface{}{
+		"plaintext": base64.StdEncoding.EncodeToString(dataKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap data key with transit key %s: %w", k.name, err)
+	}
+
+	if secret == nil {
+		return nil, fmt.Errorf("empty encrypt response for transit key %s", k.name)
+	}
+
+	ciphertext, ok := secret.Data["ciphertext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid ciphertext returned by transit key %s", k.name)
+	}
+
+	return []byte(ciphertext), nil
+}
+
+func (k *vaultTransitKEK) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	secret, err := k.client.Logical().Write(fmt.Sprintf("%s/decrypt/%s", k.mount, k.name), map[string]interface{}{
+		"ciphertext": string(wrappedKey),
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not unwrap data key with transit key %s: %w", k.name, err)
+	}
+
+	if secret == nil {
+		return nil, fmt.Errorf("empty decrypt response for transit key %s", k.name)
+	}
+
+	plaintext, ok := secret.Data["plaintext"].(string)
+	if !ok {
+		return nil, fmt.Errorf("invalid plaintext returned by transit key %s", k.name)
+	}
+
+	return base64.StdEncoding.DecodeString(plaintext)
+}
+
+type awsKMSKEK struct {
+	client *kms.Client
+	keyID  string
+}
+
+func (k *awsKMSKEK) ID() string {
+	return "aws_kms:" + k.keyID
+}
+
+func (k *awsKMSKEK) WrapKey(dataKey []byte) ([]byte, error) {
+	out, err := k.client.Encrypt(context.Background(), &kms.EncryptInput{
+		KeyId:     aws.String(k.keyID),
+		Plaintext: dataKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not wrap data key with KMS key %s: %w", k.keyID, err)
+	}
+
+	return out.CiphertextBlob, nil
+}
+
+func (k *awsKMSKEK) UnwrapKey(wrappedKey []byte) ([]byte, error) {
+	out, err := k.client.Decrypt(context.Background(), &kms.DecryptInput{
+		KeyId:          aws.String(k.keyID),
+		CiphertextBlob: wrappedKey,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not unwrap data key with KMS key %s: %w", k.keyID, err)
+	}
+
+	return out.Plaintext, nil
+}
+
+// sealPrivateKey encrypts a PEM encoded private key with a fresh data key wrapped by kek. The key ID is bound to
+// the ciphertext, so an envelope can only be opened under the key ID it was sealed for.
+func sealPrivateKey(kek KeyEncryptionKey, keyID string, keyPEM []byte) ([]byte, error) {
+	dataKey := make([]byte, dataKeySize)
+	if _, err := rand.Read(dataKey); err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	ciphertext, err := sealAEAD(aead, keyPEM, []byte(keyID))
+	if err != nil {
+		return nil, err
+	}
+
+	wrappedKey, err := kek.WrapKey(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return pem.EncodeToMemory(&pem.Block{
+		Type: encryptedKeyPEMType,
+		Headers: map[string]string{
+			encryptedKeyKEKHeader:     kek.ID(),
+			encryptedKeyWrappedHeader: base64.StdEncoding.EncodeToString(wrappedKey),
+		},
+		Bytes: ciphertext,
+	}), nil
+}
+
+// openPrivateKey decrypts an envelope created by sealPrivateKey and returns the PEM encoded private key.
+func openPrivateKey(kek KeyEncryptionKey, keyID string, block *pem.Block) ([]byte, error) {
+	if block.Type != encryptedKeyPEMType {
+		return nil, fmt.Errorf("unexpected PEM block type '%s'", block.Type)
+	}
+
+	if kekID := block.Headers[encryptedKeyKEKHeader]; kekID != kek.ID() {
+		return nil, fmt.Errorf("key %s was encrypted with KEK '%s' but the engine uses '%s'", keyID, kekID, kek.ID())
+	}
+
+	wrappedKey, err := base64.StdEncoding.DecodeString(block.Headers[encryptedKeyWrappedHeader])
+	if err != nil {
+		return nil, fmt.Errorf("could not decode wrapped data key: %w", err)
+	}
+
+	dataKey, err := kek.UnwrapKey(wrappedKey)
+	if err != nil {
+		return nil, err
+	}
+
+	aead, err := newAEAD(dataKey)
+	if err != nil {
+		return nil, err
+	}
+
+	keyPEM, err := openAEAD(aead, block.Bytes, []byte(keyID))
+	if err != nil {
+		return nil, fmt.Errorf("could not decrypt key %s: %w", keyID, err)
+	}
+
+	return keyPEM, nil
+}
+
+func newAEAD(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, err
+	}
+
+	return cipher.NewGCM(block)
+}
+
+func sealAEAD(aead cipher.AEAD, plaintext, additionalData []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+
+	return aead.Seal(nonce, nonce, plaintext, additionalData), nil
+}
+
+func openAEAD(aead cipher.AEAD, ciphertext, additionalData []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+
+	nonce, ciphertext := ciphertext[:aead.NonceSize()], ciphertext[aead.NonceSize():]
+	return aead.Open(nil, nonce, ciphertext, additionalData)
+}
@@ -23,17 +23,31 @@ var lGo *logrus.Entry
 type GoCryptoEngine struct {
 	config           models.CryptoEngineInfo
 	storageDirectory string
+	kek              KeyEncryptionKey
 }
 
-func NewGolangPEMEngine(logger *logrus.Entry, conf config.GolangEngineConfig) CryptoEngine {
+func NewGolangPEMEngine(logger *logrus.Entry, conf config.GolangEngineConfig) (CryptoEngine, error) {
 	lGo = logger.WithField("subsystem-provider", "GoSoft")
 
 	defaultMeta := map[string]interface{}{
 		"lamassu.io/cryptoengine.golang.storage-path": conf.StorageDirectory,
 	}
+
+	var kek KeyEncryptionKey
+	if conf.Encryption != nil {
+		var err error
+		kek, err = NewKeyEncryptionKey(lGo, *conf.Encryption)
+		if err != nil {
+			return nil, fmt.Errorf("could not create key encryption key: %w", err)
+		}
+
+		defaultMeta["lamassu.io/cryptoengine.golang.kek"] = kek.ID()
+	}
+
 	meta := helpers.MergeMaps[interface{}](&defaultMeta, &conf.Metadata)
 	return &GoCryptoEngine{
 		storageDirectory: conf.StorageDirectory,
+		kek:              kek,
 		config: models.CryptoEngineInfo{
 			Type:          models.Golang,
 			SecurityLevel: models.SL0,
@@ -61,7 +75,7 @@ func NewGolangPEMEngine(logger *logrus.Entry, conf config.GolangEngineConfig) Cr
 				},
 			},
 		},
-	}
+	}, nil
 }
 
 func (p *GoCryptoEngine) GetEngineConfig() models.CryptoEngineInfo {
@@ -76,12 +90,37 @@ func (p *GoCryptoEngine) GetPrivateKeyByID(keyID string) (crypto.Signer, error)
 		return nil, err
 	}
 
+	return p.parsePrivateKey(keyID, privatePEM)
+}
+
+// parsePrivateKey decodes a stored key, decrypting it first if it was written with envelope encryption.
+func (p *GoCryptoEngine) parsePrivateKey(keyID string, privatePEM []byte) (crypto.Signer, error) {
 	block, _ := pem.Decode(privatePEM)
 	if block == nil {
 		lGo.Errorf("could not decode %s PEM Key. Block is nil", keyID)
 		return nil, fmt.Errorf("failed to parse PEM block containing the key")
 	}
 
+	if block.Type == encryptedKeyPEMType {
+		if p.kek == nil {
+			lGo.Errorf("key %s is encrypted but the engine has no key encryption key configured", keyID)
+			return nil, fmt.Errorf("key %s is encrypted but the engine has no key encryption key configured", keyID)
+		}
+
+		lGo.Debugf("decrypting key %s", keyID)
+		keyPEM, err := openPrivateKey(p.kek, keyID, block)
+		if err != nil {
+			lGo.Errorf("could not decrypt key %s: %s", keyID, err)
+			return nil, err
+		}
+
+		block, _ = pem.Decode(keyPEM)
+		if block == nil {
+			lGo.Errorf("could not decode decrypted %s PEM Key. Block is nil", keyID)
+			return nil, fmt.Errorf("failed to parse PEM block containing the key")
+		}
+	}
+
 	switch block.Type {
 	case "RSA PRIVATE KEY":
 		lGo.Debugf("key %s is RSA in PKCS1 encoding format", keyID)
@@ -137,10 +176,10 @@ func (p *GoCryptoEngine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string)
 	lGo.Debugf("importing RSA %d key for keyID: %s", key.Size(), keyID)
 	p.checkAndCreateStorageDir()
 
-	err := os.WriteFile(p.storageDirectory+"/"+keyID, pem.EncodeToMemory(&pem.Block{
+	err := p.writeKey(keyID, &pem.Block{
 		Bytes: x509.MarshalPKCS1PrivateKey(key),
 		Type:  "RSA PRIVATE KEY",
-	}), 0600)
+	})
 	if err != nil {
 		lGo.Errorf("could not save %s RSA key: %s", keyID, err)
 		return nil, err
@@ -158,10 +197,10 @@ func (p *GoCryptoEngine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID stri
 		return nil, err
 	}
 
-	err = os.WriteFile(p.storageDirectory+"/"+keyID, pem.EncodeToMemory(&pem.Block{
+	err = p.writeKey(keyID, &pem.Block{
 		Bytes: b,
 		Type:  "EC PRIVATE KEY",
-	}), 0600)
+	})
 	if err != nil {
 		lGo.Errorf("could not save %s ECDSA key: %s", keyID, err)
 		return nil, err
@@ -170,6 +209,71 @@ func (p *GoCryptoEngine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID stri
 	return p.GetPrivateKeyByID(keyID)
 }
 
+// ExportKey returns the key encrypted with a fresh data key wrapped by the engine KEK, so the backup can be
+// stored outside the engine. Keys written in plain PEM before enabling encryption are encrypted on export.
+func (p *GoCryptoEngine) ExportKey(keyID string) ([]byte, error) {
+	if p.kek == nil {
+		lGo.Errorf("could not export key %s: engine has no key encryption key configured", keyID)
+		return nil, ErrKeyBackupNotSupported
+	}
+
+	privatePEM, err := os.ReadFile(p.storageDirectory + "/" + keyID)
+	if err != nil {
+		lGo.Errorf("Could not read %s Key: %s", keyID, err)
+		return nil, err
+	}
+
+	block, _ := pem.Decode(privatePEM)
+	if block == nil {
+		lGo.Errorf("could not decode %s PEM Key. Block is nil", keyID)
+		return nil, fmt.Errorf("failed to parse PEM block containing the key")
+	}
+
+	if block.Type == encryptedKeyPEMType {
+		// make sure the backup can be restored with the current KEK before handing it out
+		_, err = p.parsePrivateKey(keyID, privatePEM)
+		if err != nil {
+			return nil, err
+		}
+
+		return privatePEM, nil
+	}
+
+	lGo.Debugf("encrypting plain PEM key %s for export", keyID)
+	return sealPrivateKey(p.kek, keyID, pem.EncodeToMemory(block))
+}
+
+// OpenKeyBackup decrypts a backup created by ExportKey for the same key ID without storing it.
+func (p *GoCryptoEngine) OpenKeyBackup(keyID string, backup []byte) (crypto.Signer, error) {
+	if p.kek == nil {
+		lGo.Errorf("could not open backup of key %s: engine has no key encryption key configured", keyID)
+		return nil, ErrKeyBackupNotSupported
+	}
+
+	block, _ := pem.Decode(backup)
+	if block == nil || block.Type != encryptedKeyPEMType {
+		lGo.Errorf("backup of key %s is not an encrypted key", keyID)
+		return nil, fmt.Errorf("backup is not an encrypted key")
+	}
+
+	return p.parsePrivateKey(keyID, backup)
+}
+
+// writeKey stores the PEM encoded key, encrypting it when the engine has a KEK configured.
+func (p *GoCryptoEngine) writeKey(keyID string, block *pem.Block) error {
+	keyPEM := pem.EncodeToMemory(block)
+	if p.kek != nil {
+		var err error
+		keyPEM, err = sealPrivateKey(p.kek, keyID, keyPEM)
+		if err != nil {
+			lGo.Errorf("could not encrypt key %s: %s", keyID, err)
+			return err
+		}
+	}
+
+	return os.WriteFile(p.storageDirectory+"/"+keyID, keyPEM, 0600)
+}
+
 func (p *GoCryptoEngine) checkAndCreateStorageDir() error {
 	var err error
 	if _, err = os.Stat(p.storageDirectory); os.IsNotExist(err) {
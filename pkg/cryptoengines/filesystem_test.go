@@ -1,11 +1,13 @@
 package cryptoengines
 
 import (
+	"bytes"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
 	"encoding/pem"
 	"errors"
 	"fmt"
@@ -22,7 +24,10 @@ func setup(t *testing.T) (string, *GoCryptoEngine) {
 
 	// Create a new instance of GoCryptoEngine
 	log := helpers.SetupLogger(config.Info, "CA TestCase", "Golang Engine")
-	engine := NewGolangPEMEngine(log, config.GolangEngineConfig{StorageDirectory: tempDir})
+	engine, err := NewGolangPEMEngine(log, config.GolangEngineConfig{StorageDirectory: tempDir})
+	if err != nil {
+		t.Fatalf("could not create Golang engine: %s", err)
+	}
 
 	return tempDir, engine.(*GoCryptoEngine)
 }
@@ -211,3 +216,147 @@ func TestImportECDSAPrivateKey(t *testing.T) {
 		}
 	})
 }
+
+func setupEncrypted(t *testing.T, tempDir string, kek []byte) *GoCryptoEngine {
+	log := helpers.SetupLogger(config.Info, "CA TestCase", "Golang Engine")
+	engine, err := NewGolangPEMEngine(log, config.GolangEngineConfig{
+		StorageDirectory: tempDir,
+		Encryption: &config.GolangEngineEncryption{
+			Provider:  config.GolangKEKStatic,
+			StaticKey: config.Password(base64.StdEncoding.EncodeToString(kek)),
+		},
+	})
+	if err != nil {
+		t.Fatalf("could not create encrypted Golang engine: %s", err)
+	}
+
+	return engine.(*GoCryptoEngine)
+}
+
+func TestEncryptedKeys(t *testing.T) {
+	tempDir := t.TempDir()
+	kek := bytes.Repeat([]byte{1}, 32)
+	engine := setupEncrypted(t, tempDir, kek)
+
+	keyID := "test-key"
+	signer, err := engine.CreateECDSAPrivateKey(elliptic.P256(), keyID)
+	if err != nil {
+		t.Fatalf("failed to create ECDSA private key: %s", err)
+	}
+
+	stored, err := os.ReadFile(fmt.Sprintf("%s/%s", tempDir, keyID))
+	if err != nil {
+		t.Fatalf("failed to read stored private key: %s", err)
+	}
+
+	block, _ := pem.Decode(stored)
+	if block == nil || block.Type != encryptedKeyPEMType {
+		t.Fatalf("expected stored key to be encrypted, got %s", stored)
+	}
+
+	t.Run("ReadWithSameKEK", func(t *testing.T) {
+		read, err := setupEncrypted(t, tempDir, kek).GetPrivateKeyByID(keyID)
+		if err != nil {
+			t.Fatalf("failed to read encrypted key: %s", err)
+		}
+
+		if !signer.Public().(*ecdsa.PublicKey).Equal(read.Public()) {
+			t.Errorf("read key does not match the created key")
+		}
+	})
+
+	t.Run("ReadWithOtherKEK", func(t *testing.T) {
+		_, err := setupEncrypted(t, tempDir, bytes.Repeat([]byte{2}, 32)).GetPrivateKeyByID(keyID)
+		if err == nil {
+			t.Errorf("expected an error reading the key with another KEK")
+		}
+	})
+
+	t.Run("ReadWithoutKEK", func(t *testing.T) {
+		_, plain := setup(t)
+		plain.storageDirectory = tempDir
+		_, err := plain.GetPrivateKeyByID(keyID)
+		if err == nil {
+			t.Errorf("expected an error reading an encrypted key without KEK")
+		}
+	})
+
+	t.Run("ReadPlainKey", func(t *testing.T) {
+		privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+		if err != nil {
+			t.Fatalf("failed to generate test private key: %s", err)
+		}
+
+		err = os.WriteFile(fmt.Sprintf("%s/%s", tempDir, "plain-key"), pem.EncodeToMemory(&pem.Block{
+			Type:  "RSA PRIVATE KEY",
+			Bytes: x509.MarshalPKCS1PrivateKey(privateKey),
+		}), 0600)
+		if err != nil {
+			t.Fatalf("failed to write test private key to file: %s", err)
+		}
+
+		_, err = engine.GetPrivateKeyByID("plain-key")
+		if err != nil {
+			t.Errorf("failed to read plain key with an encrypted engine: %s", err)
+		}
+	})
+}
+
+func TestKeyBackup(t *testing.T) {
+	kek := bytes.Repeat([]byte{1}, 32)
+	engine := setupEncrypted(t, t.TempDir(), kek)
+
+	keyID := "test-key"
+	signer, err := engine.CreateRSAPrivateKey(2048, keyID)
+	if err != nil {
+		t.Fatalf("failed to create RSA private key: %s", err)
+	}
+
+	backup, err := engine.ExportKey(keyID)
+	if err != nil {
+		t.Fatalf("failed to export key: %s", err)
+	}
+
+	t.Run("Restore", func(t *testing.T) {
+		restoreEngine := setupEncrypted(t, t.TempDir(), kek)
+		restored, err := restoreEngine.OpenKeyBackup(keyID, backup)
+		if err != nil {
+			t.Fatalf("failed to open key backup: %s", err)
+		}
+
+		if !signer.Public().(*rsa.PublicKey).Equal(restored.Public()) {
+			t.Errorf("restored key does not match the exported key")
+		}
+	})
+
+	t.Run("RestoreUnderOtherKeyID", func(t *testing.T) {
+		_, err := engine.OpenKeyBackup("other-key", backup)
+		if err == nil {
+			t.Errorf("expected an error opening a backup under another key ID")
+		}
+	})
+
+	t.Run("ExportPlainKey", func(t *testing.T) {
+		tempDir, plain := setup(t)
+		_, err := plain.CreateECDSAPrivateKey(elliptic.P256(), keyID)
+		if err != nil {
+			t.Fatalf("failed to create ECDSA private key: %s", err)
+		}
+
+		_, err = plain.ExportKey(keyID)
+		if !errors.Is(err, ErrKeyBackupNotSupported) {
+			t.Errorf("expected error %s, got: %v", ErrKeyBackupNotSupported, err)
+		}
+
+		// enabling encryption on an existing directory allows exporting the keys written in plain PEM
+		backup, err := setupEncrypted(t, tempDir, kek).ExportKey(keyID)
+		if err != nil {
+			t.Fatalf("failed to export plain key: %s", err)
+		}
+
+		_, err = engine.OpenKeyBackup(keyID, backup)
+		if err != nil {
+			t.Errorf("failed to open backup of plain key: %s", err)
+		}
+	})
+}
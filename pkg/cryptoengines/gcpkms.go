@@ -0,0 +1,323 @@
+package cryptoengines
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+	"time"
+
+	kms "cloud.google.com/go/kms/apiv1"
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+	"google.golang.org/api/option"
+)
+
+var lGCPKMS *logrus.Entry
+
+// gcpKeyGenerationPolling is the interval between checks of a key version still being generated by KMS.
+var gcpKeyGenerationPolling = time.Second
+
+const gcpKeyGenerationAttempts = 30
+
+// gcpKMSClient is the subset of the KMS client used by the engine.
+type gcpKMSClient interface {
+	GetPublicKey(ctx context.Context, req *kmspb.GetPublicKeyRequest, opts ...gax.CallOption) (*kmspb.PublicKey, error)
+	GetCryptoKeyVersion(ctx context.Context, req *kmspb.GetCryptoKeyVersionRequest, opts ...gax.CallOption) (*kmspb.CryptoKeyVersion, error)
+	CreateCryptoKey(ctx context.Context, req *kmspb.CreateCryptoKeyRequest, opts ...gax.CallOption) (*kmspb.CryptoKey, error)
+	DestroyCryptoKeyVersion(ctx context.Context, req *kmspb.DestroyCryptoKeyVersionRequest, opts ...gax.CallOption) (*kmspb.CryptoKeyVersion, error)
+	AsymmetricSign(ctx context.Context, req *kmspb.AsymmetricSignRequest, opts ...gax.CallOption) (*kmspb.AsymmetricSignResponse, error)
+}
+
+type GCPKMSCryptoEngine struct {
+	config          models.CryptoEngineInfo
+	kmscli          gcpKMSClient
+	keyRing         string
+	protectionLevel kmspb.ProtectionLevel
+}
+
+// NewGCPKMSEngine creates an engine storing the keys in the configured Cloud KMS key ring. Keys are created with a
+// single version, which is the one used to sign.
+func NewGCPKMSEngine(logger *logrus.Entry, conf config.GCPKMSCryptoEngine) (CryptoEngine, error) {
+	opts := []option.ClientOption{}
+	if conf.CredentialsFile != "" {
+		opts = append(opts, option.WithCredentialsFile(conf.CredentialsFile))
+	}
+
+	if conf.EndpointURL != "" {
+		opts = append(opts, option.WithEndpoint(conf.EndpointURL))
+	}
+
+	kmscli, err := kms.NewKeyManagementClient(context.Background(), opts...)
+	if err != nil {
+		return nil, fmt.Errorf("could not create KMS client: %s", err)
+	}
+
+	return newGCPKMSEngine(logger, kmscli, conf)
+}
+
+func newGCPKMSEngine(logger *logrus.Entry, kmscli gcpKMSClient, conf config.GCPKMSCryptoEngine) (CryptoEngine, error) {
+	lGCPKMS = logger.WithField("subsystem-provider", "GCP-KMS")
+
+	if conf.ProjectID == "" || conf.Location == "" || conf.KeyRing == "" {
+		return nil, fmt.Errorf("project ID, location and key ring are required")
+	}
+
+	protectionLevel := kmspb.ProtectionLevel_HSM
+	securityLevel := models.SL2
+	switch strings.ToLower(conf.ProtectionLevel) {
+	case "", "hsm":
+	case "software":
+		protectionLevel = kmspb.ProtectionLevel_SOFTWARE
+		securityLevel = models.SL1
+	default:
+		return nil, fmt.Errorf("unsupported protection level '%s'", conf.ProtectionLevel)
+	}
+
+	defaultMeta := map[string]interface{}{
+		"lamassu.io/cryptoengine/gcp-kms/project-id":       conf.ProjectID,
+		"lamassu.io/cryptoengine/gcp-kms/location":         conf.Location,
+		"lamassu.io/cryptoengine/gcp-kms/key-ring":         conf.KeyRing,
+		"lamassu.io/cryptoengine/gcp-kms/protection-level": strings.ToLower(protectionLevel.String()),
+	}
+
+	meta := helpers.MergeMaps[interface{}](&defaultMeta, &conf.Metadata)
+
+	return &GCPKMSCryptoEngine{
+		kmscli:          kmscli,
+		keyRing:         fmt.Sprintf("projects/%s/locations/%s/keyRings/%s", conf.ProjectID, conf.Location, conf.KeyRing),
+		protectionLevel: protectionLevel,
+		config: models.CryptoEngineInfo{
+			Type:          models.GCPKMS,
+			SecurityLevel: securityLevel,
+			Provider:      "Google Cloud",
+			Name:          "KMS",
+			Metadata:      *meta,
+			SupportedKeyTypes: []models.SupportedKeyTypeInfo{
+				{
+					Type: models.KeyType(x509.RSA),
+					Sizes: []int{
+						2048,
+						3072,
+						4096,
+					},
+				},
+				{
+					Type: models.KeyType(x509.ECDSA),
+					Sizes: []int{
+						256,
+						384,
+					},
+				},
+			},
+		},
+	}, nil
+}
+
+func (p *GCPKMSCryptoEngine) GetEngineConfig() models.CryptoEngineInfo {
+	return p.config
+}
+
+func (p *GCPKMSCryptoEngine) keyVersionName(keyID string) string {
+	return fmt.Sprintf("%s/cryptoKeys/%s/cryptoKeyVersions/1", p.keyRing, keyID)
+}
+
+func (p *GCPKMSCryptoEngine) GetPrivateKeyByID(keyID string) (crypto.Signer, error) {
+	lGCPKMS.Debugf("Getting the private key with ID: %s", keyID)
+
+	signer, err := newGCPKMSKeySigner(p.kmscli, p.keyVersionName(keyID))
+	if err != nil {
+		lGCPKMS.Errorf("could not get key '%s': %s", keyID, err)
+		return nil, err
+	}
+
+	return signer, nil
+}
+
+func (p *GCPKMSCryptoEngine) CreateRSAPrivateKey(keySize int, keyID string) (crypto.Signer, error) {
+	lGCPKMS.Debugf("Creating RSA key with ID: %s", keyID)
+
+	var algorithm kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm
+
+	switch keySize {
+	case 2048:
+		algorithm = kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_2048_SHA256
+	case 3072:
+		algorithm = kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_3072_SHA256
+	case 4096:
+		algorithm = kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_4096_SHA256
+	default:
+		err := fmt.Errorf("key size not supported")
+		lGCPKMS.Error(err)
+		return nil, err
+	}
+
+	err := p.createKey(algorithm, keyID)
+	if err != nil {
+		lGCPKMS.Errorf("could not create '%s' RSA Private Key: %s", keyID, err)
+		return nil, err
+	}
+
+	return p.GetPrivateKeyByID(keyID)
+}
+
+func (p *GCPKMSCryptoEngine) CreateECDSAPrivateKey(curve elliptic.Curve, keyID string) (crypto.Signer, error) {
+	lGCPKMS.Debugf("Creating ECDSA key with ID: %s and curve %s", keyID, curve.Params().Name)
+
+	var algorithm kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm
+
+	switch curve.Params().Name {
+	case "P-256":
+		algorithm = kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256
+	case "P-384":
+		algorithm = kmspb.CryptoKeyVersion_EC_SIGN_P384_SHA384
+	default:
+		err := fmt.Errorf("key curve not supported")
+		lGCPKMS.Error(err)
+		return nil, err
+	}
+
+	err := p.createKey(algorithm, keyID)
+	if err != nil {
+		lGCPKMS.Errorf("could not create '%s' ECDSA Private Key: %s", keyID, err)
+		return nil, err
+	}
+
+	return p.GetPrivateKeyByID(keyID)
+}
+
+func (p *GCPKMSCryptoEngine) createKey(algorithm kmspb.CryptoKeyVersion_CryptoKeyVersionAlgorithm, keyID string) error {
+	key, err := p.kmscli.CreateCryptoKey(context.Background(), &kmspb.CreateCryptoKeyRequest{
+		Parent:      p.keyRing,
+		CryptoKeyId: keyID,
+		CryptoKey: &kmspb.CryptoKey{
+			Purpose: kmspb.CryptoKey_ASYMMETRIC_SIGN,
+			VersionTemplate: &kmspb.CryptoKeyVersionTemplate{
+				Algorithm:       algorithm,
+				ProtectionLevel: p.protectionLevel,
+			},
+		},
+	})
+	if err != nil {
+		return err
+	}
+
+	lGCPKMS.Debugf("key created with name [%s]", key.Name)
+
+	// Asymmetric key versions are generated asynchronously and can not be used until enabled.
+	for i := 0; i < gcpKeyGenerationAttempts; i++ {
+		version, err := p.kmscli.GetCryptoKeyVersion(context.Background(), &kmspb.GetCryptoKeyVersionRequest{
+			Name: p.keyVersionName(keyID),
+		})
+		if err != nil {
+			return err
+		}
+
+		switch version.State {
+		case kmspb.CryptoKeyVersion_ENABLED:
+			return nil
+		case kmspb.CryptoKeyVersion_PENDING_GENERATION:
+			time.Sleep(gcpKeyGenerationPolling)
+		default:
+			return fmt.Errorf("key version is in %s state", version.State)
+		}
+	}
+
+	return fmt.Errorf("key version was not generated in time")
+}
+
+func (p *GCPKMSCryptoEngine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	lGCPKMS.Warnf("KMS engine does not support key import. See https://cloud.google.com/kms/docs/importing-a-key")
+	return nil, fmt.Errorf("KMS engine does not support key import")
+}
+
+func (p *GCPKMSCryptoEngine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	lGCPKMS.Warnf("KMS engine does not support key import. See https://cloud.google.com/kms/docs/importing-a-key")
+	return nil, fmt.Errorf("KMS engine does not support key import")
+}
+
+// DeleteKey schedules the destruction of the key version. Cloud KMS keeps the key until the scheduled destruction
+// date, so it can be restored from the console in the meantime.
+func (p *GCPKMSCryptoEngine) DeleteKey(keyID string) error {
+	_, err := p.kmscli.DestroyCryptoKeyVersion(context.Background(), &kmspb.DestroyCryptoKeyVersionRequest{
+		Name: p.keyVersionName(keyID),
+	})
+	return err
+}
+
+type gcpKMSKeySigner struct {
+	keyVersion string
+	sdk        gcpKMSClient
+
+	publicKey crypto.PublicKey
+}
+
+func newGCPKMSKeySigner(sdk gcpKMSClient, keyVersion string) (crypto.Signer, error) {
+	//preload PubKey from KMS
+	pubResp, err := sdk.GetPublicKey(context.Background(), &kmspb.GetPublicKeyRequest{
+		Name: keyVersion,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	block, _ := pem.Decode([]byte(pubResp.Pem))
+	if block == nil {
+		return nil, fmt.Errorf("could not decode public key PEM")
+	}
+
+	pubKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	return &gcpKMSKeySigner{
+		sdk:        sdk,
+		keyVersion: keyVersion,
+		publicKey:  pubKey,
+	}, nil
+}
+
+func (k *gcpKMSKeySigner) Public() crypto.PublicKey {
+	return k.publicKey
+}
+
+// Sign signs the digest with the key version. The keys are created for PKCS#1 v1.5 (RSA) signatures, so PSS
+// signatures are not supported.
+func (k *gcpKMSKeySigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) (signature []byte, err error) {
+	if _, isPSS := opts.(*rsa.PSSOptions); isPSS {
+		return nil, fmt.Errorf("RSA PSS signatures are not supported")
+	}
+
+	kmsDigest := &kmspb.Digest{}
+	switch h := opts.HashFunc(); h {
+	case crypto.SHA256:
+		kmsDigest.Digest = &kmspb.Digest_Sha256{Sha256: digest}
+	case crypto.SHA384:
+		kmsDigest.Digest = &kmspb.Digest_Sha384{Sha384: digest}
+	case crypto.SHA512:
+		kmsDigest.Digest = &kmspb.Digest_Sha512{Sha512: digest}
+	default:
+		return nil, fmt.Errorf("unsupported hash function %v", h)
+	}
+
+	resp, err := k.sdk.AsymmetricSign(context.Background(), &kmspb.AsymmetricSignRequest{
+		Name:   k.keyVersion,
+		Digest: kmsDigest,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return resp.Signature, nil
+}
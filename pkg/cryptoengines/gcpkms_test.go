@@ -0,0 +1,206 @@
+package cryptoengines
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/pem"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"cloud.google.com/go/kms/apiv1/kmspb"
+	"github.com/googleapis/gax-go/v2"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeGCPKMSClient keeps the key versions in memory. Created versions stay pending for the first state check.
+type fakeGCPKMSClient struct {
+	keys    map[string]crypto.Signer
+	pending map[string]bool
+}
+
+func (c *fakeGCPKMSClient) GetPublicKey(ctx context.Context, req *kmspb.GetPublicKeyRequest, opts ...gax.CallOption) (*kmspb.PublicKey, error) {
+	key, ok := c.keys[req.Name]
+	if !ok {
+		return nil, fmt.Errorf("NotFound: %s", req.Name)
+	}
+
+	der, err := x509.MarshalPKIXPublicKey(key.Public())
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmspb.PublicKey{
+		Pem: string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})),
+	}, nil
+}
+
+func (c *fakeGCPKMSClient) GetCryptoKeyVersion(ctx context.Context, req *kmspb.GetCryptoKeyVersionRequest, opts ...gax.CallOption) (*kmspb.CryptoKeyVersion, error) {
+	if _, ok := c.keys[req.Name]; !ok {
+		return nil, fmt.Errorf("NotFound: %s", req.Name)
+	}
+
+	if c.pending[req.Name] {
+		c.pending[req.Name] = false
+		return &kmspb.CryptoKeyVersion{Name: req.Name, State: kmspb.CryptoKeyVersion_PENDING_GENERATION}, nil
+	}
+
+	return &kmspb.CryptoKeyVersion{Name: req.Name, State: kmspb.CryptoKeyVersion_ENABLED}, nil
+}
+
+func (c *fakeGCPKMSClient) CreateCryptoKey(ctx context.Context, req *kmspb.CreateCryptoKeyRequest, opts ...gax.CallOption) (*kmspb.CryptoKey, error) {
+	var key crypto.Signer
+	var err error
+
+	algorithm := req.CryptoKey.VersionTemplate.Algorithm
+	switch algorithm {
+	case kmspb.CryptoKeyVersion_RSA_SIGN_PKCS1_2048_SHA256:
+		key, err = rsa.GenerateKey(rand.Reader, 2048)
+	case kmspb.CryptoKeyVersion_EC_SIGN_P256_SHA256:
+		key, err = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	default:
+		return nil, fmt.Errorf("unexpected algorithm %s", algorithm)
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	name := fmt.Sprintf("%s/cryptoKeys/%s", req.Parent, req.CryptoKeyId)
+	c.keys[name+"/cryptoKeyVersions/1"] = key
+	c.pending[name+"/cryptoKeyVersions/1"] = true
+
+	return &kmspb.CryptoKey{Name: name}, nil
+}
+
+func (c *fakeGCPKMSClient) DestroyCryptoKeyVersion(ctx context.Context, req *kmspb.DestroyCryptoKeyVersionRequest, opts ...gax.CallOption) (*kmspb.CryptoKeyVersion, error) {
+	delete(c.keys, req.Name)
+	return &kmspb.CryptoKeyVersion{Name: req.Name, State: kmspb.CryptoKeyVersion_DESTROY_SCHEDULED}, nil
+}
+
+func (c *fakeGCPKMSClient) AsymmetricSign(ctx context.Context, req *kmspb.AsymmetricSignRequest, opts ...gax.CallOption) (*kmspb.AsymmetricSignResponse, error) {
+	key, ok := c.keys[req.Name]
+	if !ok {
+		return nil, fmt.Errorf("NotFound: %s", req.Name)
+	}
+
+	signature, err := key.Sign(rand.Reader, req.Digest.GetSha256(), crypto.SHA256)
+	if err != nil {
+		return nil, err
+	}
+
+	return &kmspb.AsymmetricSignResponse{Signature: signature}, nil
+}
+
+func prepareGCPKMSCryptoEngine(t *testing.T) CryptoEngine {
+	gcpKeyGenerationPolling = time.Millisecond
+
+	engine, err := newGCPKMSEngine(logrus.New().WithField("test", "GCP-KMS"), &fakeGCPKMSClient{
+		keys:    map[string]crypto.Signer{},
+		pending: map[string]bool{},
+	}, config.GCPKMSCryptoEngine{
+		ID:        "gcp-kms",
+		ProjectID: "lamassu",
+		Location:  "europe-west1",
+		KeyRing:   "pki",
+		Metadata:  map[string]interface{}{"team": "pki"},
+	})
+	assert.NoError(t, err)
+
+	return engine
+}
+
+func testCreateRSAPKCS1PrivateKeyOnGCPKMS(t *testing.T, engine CryptoEngine) {
+	signer, err := engine.CreateRSAPrivateKey(2048, "test-rsa-key")
+	assert.NoError(t, err)
+
+	hashed := sha256.Sum256([]byte("aa"))
+	signature, err := signer.Sign(rand.Reader, hashed[:], crypto.SHA256)
+	assert.NoError(t, err)
+
+	err = rsa.VerifyPKCS1v15(signer.Public().(*rsa.PublicKey), crypto.SHA256, hashed[:], signature)
+	assert.NoError(t, err)
+
+	_, err = signer.Sign(rand.Reader, hashed[:], &rsa.PSSOptions{Hash: crypto.SHA256})
+	assert.Error(t, err)
+}
+
+func testUnsupportedKeysOnGCPKMS(t *testing.T, engine CryptoEngine) {
+	_, err := engine.CreateECDSAPrivateKey(elliptic.P521(), "test-p521-key")
+	assert.Error(t, err)
+
+	_, err = engine.CreateRSAPrivateKey(1024, "test-rsa-1024-key")
+	assert.Error(t, err)
+
+	_, err = engine.ImportECDSAPrivateKey(nil, "imported-ecdsa-key")
+	assert.EqualError(t, err, "KMS engine does not support key import")
+}
+
+func testDeleteKeyOnGCPKMS(t *testing.T, engine CryptoEngine) {
+	_, err := engine.CreateECDSAPrivateKey(elliptic.P256(), "test-deleted-key")
+	assert.NoError(t, err)
+
+	err = engine.(*GCPKMSCryptoEngine).DeleteKey("test-deleted-key")
+	assert.NoError(t, err)
+
+	_, err = engine.GetPrivateKeyByID("test-deleted-key")
+	assert.Error(t, err)
+}
+
+func testGetEngineConfigOnGCPKMS(t *testing.T, engine CryptoEngine) {
+	info := engine.GetEngineConfig()
+
+	assert.Equal(t, models.GCPKMS, info.Type)
+	assert.Equal(t, models.SL2, info.SecurityLevel)
+	assert.Equal(t, "lamassu", info.Metadata["lamassu.io/cryptoengine/gcp-kms/project-id"])
+	assert.Equal(t, "europe-west1", info.Metadata["lamassu.io/cryptoengine/gcp-kms/location"])
+	assert.Equal(t, "pki", info.Metadata["lamassu.io/cryptoengine/gcp-kms/key-ring"])
+	assert.Equal(t, "hsm", info.Metadata["lamassu.io/cryptoengine/gcp-kms/protection-level"])
+	assert.Equal(t, "pki", info.Metadata["team"])
+}
+
+func TestGCPKMSCryptoEngine(t *testing.T) {
+	engine := prepareGCPKMSCryptoEngine(t)
+
+	table := []struct {
+		name     string
+		function func(t *testing.T, engine CryptoEngine)
+	}{
+		{"CreateECDSAPrivateKey", testCreateECDSAPrivateKey},
+		{"CreateRSAPrivateKey", testCreateRSAPKCS1PrivateKeyOnGCPKMS},
+		{"UnsupportedKeys", testUnsupportedKeysOnGCPKMS},
+		{"DeleteKey", testDeleteKeyOnGCPKMS},
+		{"GetEngineConfig", testGetEngineConfigOnGCPKMS},
+	}
+
+	for _, tt := range table {
+		t.Run(tt.name, func(t *testing.T) {
+			tt.function(t, engine)
+		})
+	}
+}
+
+func TestNewGCPKMSEngineConfig(t *testing.T) {
+	logger := logrus.New().WithField("test", "GCP-KMS")
+	cli := &fakeGCPKMSClient{}
+
+	_, err := newGCPKMSEngine(logger, cli, config.GCPKMSCryptoEngine{ProjectID: "lamassu", Location: "global"})
+	assert.Error(t, err)
+
+	_, err = newGCPKMSEngine(logger, cli, config.GCPKMSCryptoEngine{ProjectID: "lamassu", Location: "global", KeyRing: "pki", ProtectionLevel: "external"})
+	assert.Error(t, err)
+
+	engine, err := newGCPKMSEngine(logger, cli, config.GCPKMSCryptoEngine{ProjectID: "lamassu", Location: "global", KeyRing: "pki", ProtectionLevel: "SOFTWARE"})
+	assert.NoError(t, err)
+	assert.Equal(t, models.SL1, engine.GetEngineConfig().SecurityLevel)
+	assert.True(t, strings.HasSuffix(engine.(*GCPKMSCryptoEngine).keyVersionName("ca"), "/keyRings/pki/cryptoKeys/ca/cryptoKeyVersions/1"))
+}
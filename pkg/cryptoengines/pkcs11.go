@@ -30,10 +30,15 @@ type pkcs11EngineContext struct {
 
 func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (CryptoEngine, error) {
 	lPkcs11 = logger.WithField("subsystem-provider", "PKCS11")
+	if (conf.TokenLabel == "") == (conf.TokenSlot == nil) {
+		return nil, fmt.Errorf("PKCS11 token must be selected either by label or by slot")
+	}
+
 	config := &crypto11.Config{
 		Path:       conf.ModulePath,
 		Pin:        string(conf.TokenPin),
 		TokenLabel: conf.TokenLabel,
+		SlotNumber: conf.TokenSlot,
 	}
 
 	for envKey, envVal := range conf.ModuleExtraOptions.Env {
@@ -41,7 +46,7 @@ func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (Cryp
 		os.Setenv(envKey, envVal)
 	}
 
-	lPkcs11.Debugf("configuring pkcs11 module: \n - ModulePath: %s\n - TokenLabel: %s\n - Pin: ******\n", config.Path, conf.TokenLabel)
+	lPkcs11.Debugf("configuring pkcs11 module: \n - ModulePath: %s\n - TokenLabel: %s\n - TokenSlot: %v\n - Pin: ******\n", config.Path, conf.TokenLabel, printableSlot(conf.TokenSlot))
 	instance, err := crypto11.Configure(config)
 	if err != nil {
 		lPkcs11.Errorf("could not configure pkcs11 module: %s", err)
@@ -57,6 +62,8 @@ func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (Cryp
 
 	lPkcs11.Debugf("pkcs11 provier has %d slots", len(pkcs11ProviderSlots))
 	var tokenInfo pkcs11.TokenInfo
+	var tokenSlot uint
+	tokenFound := false
 	for _, slot := range pkcs11ProviderSlots {
 		lPkcs11.Tracef("geting slot '%d' info", slot)
 		tokenInfoResp, err := pkcs11ProviderContext.GetTokenInfo(slot)
@@ -66,11 +73,19 @@ func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (Cryp
 		}
 
 		lPkcs11.Tracef("slot '%d' has label '%s'", slot, tokenInfoResp.Label)
-		if config.TokenLabel == tokenInfoResp.Label {
+		if (config.SlotNumber != nil && uint(*config.SlotNumber) == slot) || (config.TokenLabel != "" && config.TokenLabel == tokenInfoResp.Label) {
 			tokenInfo = tokenInfoResp
+			tokenSlot = slot
+			tokenFound = true
+			break
 		}
 	}
 
+	if !tokenFound {
+		lPkcs11.Errorf("could not find the configured token in the provider slots")
+		return nil, fmt.Errorf("could not find token")
+	}
+
 	pkcs11ProviderInfo, err := pkcs11ProviderContext.GetInfo()
 	if err != nil {
 		lPkcs11.Errorf("could not get provider info: %s", err)
@@ -79,7 +94,7 @@ func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (Cryp
 
 	pkcs11SupporedKeys := []models.SupportedKeyTypeInfo{}
 
-	rsaMechanismInfo, err := pkcs11ProviderContext.GetMechanismInfo(pkcs11ProviderSlots[0], []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)})
+	rsaMechanismInfo, err := pkcs11ProviderContext.GetMechanismInfo(tokenSlot, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_RSA_PKCS, nil)})
 	if err == nil {
 		pkcs11SupporedKeys = append(pkcs11SupporedKeys, models.SupportedKeyTypeInfo{
 			Type:  models.KeyType(x509.RSA),
@@ -90,7 +105,7 @@ func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (Cryp
 		lPkcs11.Errorf("could not get RSA PKCS mechanism. Provider might not support RSA or something went wrong: %s", err)
 	}
 
-	ecdsaMechanismInfo, err := pkcs11ProviderContext.GetMechanismInfo(pkcs11ProviderSlots[0], []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)})
+	ecdsaMechanismInfo, err := pkcs11ProviderContext.GetMechanismInfo(tokenSlot, []*pkcs11.Mechanism{pkcs11.NewMechanism(pkcs11.CKM_ECDSA, nil)})
 	if err == nil {
 		pkcs11SupporedKeys = append(pkcs11SupporedKeys, models.SupportedKeyTypeInfo{
 			Type:  models.KeyType(x509.ECDSA),
@@ -106,6 +121,7 @@ func NewPKCS11Engine(logger *logrus.Entry, conf config.PKCS11EngineConfig) (Cryp
 		"lamassu.io/cryptoengine/pkcs11/library":          pkcs11ProviderInfo.LibraryDescription,
 		"lamassu.io/cryptoengine/pkcs11/manufacturer":     pkcs11ProviderInfo.ManufacturerID,
 		"lamassu.io/cryptoengine/pkcs11/model":            tokenInfo.Model,
+		"lamassu.io/cryptoengine/pkcs11/slot":             tokenSlot,
 	}
 
 	meta := helpers.MergeMaps[interface{}](&defaultMeta, &conf.Metadata)
@@ -182,3 +198,11 @@ func (hsmContext *pkcs11EngineContext) ImportRSAPrivateKey(key *rsa.PrivateKey,
 func (hsmContext *pkcs11EngineContext) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID string) (crypto.Signer, error) {
 	return nil, fmt.Errorf("TODO")
 }
+
+func printableSlot(slot *int) string {
+	if slot == nil {
+		return "-"
+	}
+
+	return fmt.Sprintf("%d", *slot)
+}
@@ -0,0 +1,39 @@
+//go:build !windows
+// +build !windows
+
+package cryptoengines
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+)
+
+func TestNewPKCS11EngineTokenSelection(t *testing.T) {
+	log := helpers.SetupLogger(config.Info, "CA TestCase", "PKCS11 Engine")
+	slot := 0
+
+	var testcases = []struct {
+		name  string
+		token config.PKCS11Config
+	}{
+		{
+			name:  "Err/NoTokenSelector",
+			token: config.PKCS11Config{ModulePath: "/usr/lib/softhsm/libsofthsm2.so"},
+		},
+		{
+			name:  "Err/LabelAndSlot",
+			token: config.PKCS11Config{ModulePath: "/usr/lib/softhsm/libsofthsm2.so", TokenLabel: "lamassuHSM", TokenSlot: &slot},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			_, err := NewPKCS11Engine(log, config.PKCS11EngineConfig{PKCS11Config: tc.token, ID: "pkcs11-1"})
+			if err == nil {
+				t.Fatalf("expected error selecting the token")
+			}
+		})
+	}
+}
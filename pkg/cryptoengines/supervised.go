@@ -0,0 +1,248 @@
+package cryptoengines
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+var ErrCryptoEngineUnavailable = errors.New("crypto engine is unavailable")
+
+// HealthReporter is implemented by the engines tracking their own availability.
+type HealthReporter interface {
+	Available() bool
+	Health() models.CryptoEngineHealth
+}
+
+// SupervisedEngine wraps an engine that may be unreachable. If the engine can not be created, every operation
+// returns ErrCryptoEngineUnavailable until Probe manages to create it. Probe also recreates the engine (new
+// connection, new login) when reading a key fails, so engines recover after their backend restarts.
+type SupervisedEngine struct {
+	id      string
+	info    models.CryptoEngineInfo
+	connect func() (CryptoEngine, error)
+	logger  *logrus.Entry
+
+	mu     sync.RWMutex
+	engine CryptoEngine
+	health models.CryptoEngineHealth
+}
+
+// NewSupervisedEngine creates the engine with connect. info describes the engine while it can not be created.
+func NewSupervisedEngine(logger *logrus.Entry, id string, info models.CryptoEngineInfo, connect func() (CryptoEngine, error)) *SupervisedEngine {
+	e := &SupervisedEngine{
+		id:      id,
+		info:    info,
+		connect: connect,
+		logger:  logger,
+	}
+
+	if e.reconnect() != nil {
+		e.record(nil)
+	}
+
+	return e
+}
+
+// Probe checks the engine by reading keyID, or only by creating the engine if keyID is empty. Unavailable
+// engines are created again before being probed.
+func (e *SupervisedEngine) Probe(keyID string) error {
+	e.mu.RLock()
+	engine := e.engine
+	e.mu.RUnlock()
+
+	if engine == nil {
+		engine = e.reconnect()
+		if engine == nil {
+			return ErrCryptoEngineUnavailable
+		}
+	}
+
+	if keyID == "" {
+		e.record(nil)
+		return nil
+	}
+
+	_, err := engine.GetPrivateKeyByID(keyID)
+	if err != nil {
+		e.logger.Warnf("could not read key %s from engine %s: %s. Reconnecting", keyID, e.id, err)
+		engine = e.reconnect()
+		if engine == nil {
+			return ErrCryptoEngineUnavailable
+		}
+
+		_, err = engine.GetPrivateKeyByID(keyID)
+	}
+
+	e.record(err)
+	return err
+}
+
+// reconnect creates the engine again. Failures are recorded, successes are recorded by the callers once the
+// engine has been probed.
+func (e *SupervisedEngine) reconnect() CryptoEngine {
+	engine, err := e.connect()
+	if err != nil {
+		e.logger.Warnf("could not create engine %s: %s", e.id, err)
+		e.record(fmt.Errorf("%w: %s", ErrCryptoEngineUnavailable, err))
+		return nil
+	}
+
+	e.mu.Lock()
+	e.engine = engine
+	e.mu.Unlock()
+
+	return engine
+}
+
+func (e *SupervisedEngine) record(err error) {
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	now := time.Now()
+	e.health.LastCheck = &now
+	if err != nil {
+		e.health.Available = false
+		e.health.Message = err.Error()
+		e.health.ConsecutiveFailures++
+		return
+	}
+
+	if !e.health.Available && e.health.ConsecutiveFailures > 0 {
+		e.logger.Infof("engine %s is available again after %d failed checks", e.id, e.health.ConsecutiveFailures)
+	}
+
+	e.health.Available = true
+	e.health.Message = ""
+	e.health.ConsecutiveFailures = 0
+	e.health.LastAvailable = &now
+}
+
+func (e *SupervisedEngine) Available() bool {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.health.Available
+}
+
+func (e *SupervisedEngine) Health() models.CryptoEngineHealth {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	return e.health
+}
+
+func (e *SupervisedEngine) current() (CryptoEngine, error) {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.engine == nil {
+		return nil, fmt.Errorf("%w: %s", ErrCryptoEngineUnavailable, e.id)
+	}
+
+	return e.engine, nil
+}
+
+func (e *SupervisedEngine) GetEngineConfig() models.CryptoEngineInfo {
+	engine, err := e.current()
+	if err != nil {
+		return e.info
+	}
+
+	return engine.GetEngineConfig()
+}
+
+func (e *SupervisedEngine) GetPrivateKeyByID(keyID string) (crypto.Signer, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.GetPrivateKeyByID(keyID)
+}
+
+func (e *SupervisedEngine) CreateRSAPrivateKey(keySize int, keyID string) (crypto.Signer, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.CreateRSAPrivateKey(keySize, keyID)
+}
+
+func (e *SupervisedEngine) CreateECDSAPrivateKey(curve elliptic.Curve, keyID string) (crypto.Signer, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.CreateECDSAPrivateKey(curve, keyID)
+}
+
+func (e *SupervisedEngine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.ImportRSAPrivateKey(key, keyID)
+}
+
+func (e *SupervisedEngine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	return engine.ImportECDSAPrivateKey(key, keyID)
+}
+
+func (e *SupervisedEngine) DeleteKey(keyID string) error {
+	engine, err := e.current()
+	if err != nil {
+		return err
+	}
+
+	deleter, ok := engine.(interface{ DeleteKey(keyID string) error })
+	if !ok {
+		return fmt.Errorf("engine %s does not support key removal", e.id)
+	}
+
+	return deleter.DeleteKey(keyID)
+}
+
+func (e *SupervisedEngine) ExportKey(keyID string) ([]byte, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	backupEngine, ok := engine.(KeyBackupEngine)
+	if !ok {
+		return nil, ErrKeyBackupNotSupported
+	}
+
+	return backupEngine.ExportKey(keyID)
+}
+
+func (e *SupervisedEngine) OpenKeyBackup(keyID string, backup []byte) (crypto.Signer, error) {
+	engine, err := e.current()
+	if err != nil {
+		return nil, err
+	}
+
+	backupEngine, ok := engine.(KeyBackupEngine)
+	if !ok {
+		return nil, ErrKeyBackupNotSupported
+	}
+
+	return backupEngine.OpenKeyBackup(keyID, backup)
+}
@@ -0,0 +1,78 @@
+package cryptoengines
+
+import (
+	"crypto/elliptic"
+	"errors"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestSupervisedEngineReconnects(t *testing.T) {
+	log := helpers.SetupLogger(config.Info, "CA TestCase", "Supervised Engine")
+	storageDir := t.TempDir()
+
+	online := false
+	connect := func() (CryptoEngine, error) {
+		if !online {
+			return nil, errors.New("connection refused")
+		}
+
+		return NewGolangPEMEngine(log, config.GolangEngineConfig{StorageDirectory: storageDir})
+	}
+
+	engine := NewSupervisedEngine(log, "golang-1", models.CryptoEngineInfo{Type: models.Golang, Provider: "Golang"}, connect)
+	if engine.Available() {
+		t.Fatalf("engine should be unavailable while it can not be created")
+	}
+
+	if engine.GetEngineConfig().Type != models.Golang {
+		t.Fatalf("unavailable engine should report the configured engine info")
+	}
+
+	_, err := engine.CreateECDSAPrivateKey(elliptic.P256(), "key-1")
+	if !errors.Is(err, ErrCryptoEngineUnavailable) {
+		t.Fatalf("expected ErrCryptoEngineUnavailable, got %v", err)
+	}
+
+	err = engine.Probe("")
+	if !errors.Is(err, ErrCryptoEngineUnavailable) {
+		t.Fatalf("expected ErrCryptoEngineUnavailable, got %v", err)
+	}
+
+	if failures := engine.Health().ConsecutiveFailures; failures != 2 {
+		t.Fatalf("expected 2 consecutive failures, got %d", failures)
+	}
+
+	online = true
+	err = engine.Probe("")
+	if err != nil {
+		t.Fatalf("unexpected error while probing the engine: %s", err)
+	}
+
+	health := engine.Health()
+	if !health.Available || health.ConsecutiveFailures != 0 || health.LastAvailable == nil {
+		t.Fatalf("engine should be available after reconnecting, got %+v", health)
+	}
+
+	_, err = engine.CreateECDSAPrivateKey(elliptic.P256(), "key-1")
+	if err != nil {
+		t.Fatalf("unexpected error while creating a key: %s", err)
+	}
+
+	err = engine.Probe("key-1")
+	if err != nil {
+		t.Fatalf("unexpected error while probing the engine with a key: %s", err)
+	}
+
+	err = engine.Probe("missing-key")
+	if err == nil {
+		t.Fatalf("probing a missing key should fail")
+	}
+
+	if engine.Available() {
+		t.Fatalf("engine should be unavailable after a failed probe")
+	}
+}
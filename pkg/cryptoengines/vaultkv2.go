@@ -13,8 +13,10 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"path"
 	"strconv"
 	"strings"
+	"text/template"
 
 	"github.com/hashicorp/vault/api"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
@@ -26,49 +28,79 @@ import (
 var lVault *logrus.Entry
 
 type VaultKV2Engine struct {
-	kvv2Client *api.KVv2
+	kvv2Client      *api.KVv2
+	mountPath       string
+	keyPathTemplate *template.Template
+	tenant          string
+	engineID        string
+	// managedKeys replaces the KV-V2 mount when the engine is configured with managed keys.
+	managedKeys *vaultManagedKeys
 }
 
+type vaultPathTemplateData struct {
+	Tenant    string
+	EngineID  string
+	AssetType string
+	AssetID   string
+	KeyID     string
+}
+
+const vaultDefaultKeyPathTemplate = "{{.KeyID}}"
+
 func NewVaultKV2Engine(logger *logrus.Entry, conf config.HashicorpVaultCryptoEngineConfig) (CryptoEngine, error) {
 	var err error
 	lVault = logger.WithField("subsystem-provider", "Vault-KV2")
-	address := fmt.Sprintf("%s://%s:%d", conf.Protocol, conf.Hostname, conf.Port)
 
 	lVault.Debugf("configuring VaultKV2 Engine")
 
-	vaultClientConf := api.DefaultConfig()
-	httpClient, err := helpers.BuildHTTPClientWithTLSOptions(&http.Client{}, conf.TLSConfig)
-
+	vaultClient, err := NewVaultAPIClient(lVault, conf.HashicorpVaultSDK)
 	if err != nil {
 		return nil, err
 	}
 
-	httpClient, err = helpers.BuildHTTPClientWithTracerLogger(httpClient, lVault)
+	mountPath := conf.MountPath
+	if conf.MountPathTemplate != "" {
+		mountPath, err = renderVaultPath("mount-path", conf.MountPathTemplate, vaultPathTemplateData{
+			Tenant:   conf.Tenant,
+			EngineID: conf.ID,
+		})
+		if err != nil {
+			lVault.Errorf("could not render mount path template: %s", err)
+			return nil, err
+		}
+	}
+
+	keyPathTemplate := conf.KeyPathTemplate
+	if keyPathTemplate == "" {
+		keyPathTemplate = vaultDefaultKeyPathTemplate
+	}
+
+	keyTmpl, err := template.New("key-path").Option("missingkey=error").Parse(keyPathTemplate)
 	if err != nil {
+		lVault.Errorf("could not parse key path template: %s", err)
 		return nil, err
 	}
 
-	vaultClientConf.HttpClient = httpClient
-	vaultClientConf.Address = address
-	vaultClient, err := api.NewClient(vaultClientConf)
-
+	err = checkVaultKeyPathTemplate(keyTmpl, conf.Tenant, conf.ID)
 	if err != nil {
-		lVault.Errorf("could not create Vault API client: %s", err)
-		return nil, errors.New("could not create Vault API client: " + err.Error())
+		lVault.Errorf("invalid key path template %q: %s", keyPathTemplate, err)
+		return nil, err
 	}
 
-	if conf.AutoUnsealEnabled {
-		err = Unseal(vaultClient, conf.AutoUnsealKeys)
+	if conf.ManagedKeys != nil {
+		managedKeys, err := newVaultManagedKeys(vaultClient, *conf.ManagedKeys)
 		if err != nil {
-			lVault.Errorf("could not unseal Vault: %s", err)
-			return nil, errors.New("could not unseal Vault: " + err.Error())
+			lVault.Errorf("could not configure managed keys: %s", err)
+			return nil, err
 		}
-	}
 
-	err = Login(vaultClient, conf.RoleID, string(conf.SecretID))
-	if err != nil {
-		lVault.Errorf("could not login into Vault: %s", err)
-		return nil, errors.New("could not login into Vault: " + err.Error())
+		lVault.Infof("using %s managed keys through transit mount %s", conf.ManagedKeys.Type, managedKeys.transitPath)
+		return &VaultKV2Engine{
+			keyPathTemplate: keyTmpl,
+			tenant:          conf.Tenant,
+			engineID:        conf.ID,
+			managedKeys:     managedKeys,
+		}, nil
 	}
 
 	mounts, err := vaultClient.Sys().ListMounts()
@@ -76,32 +108,203 @@ func NewVaultKV2Engine(logger *logrus.Entry, conf config.HashicorpVaultCryptoEng
 		return nil, err
 	}
 
-	hasMount := false
-
-	for mountPath := range mounts {
-		if mountPath == conf.MountPath {
-			hasMount = true
+	var existingMount *api.MountOutput
+	for mount, mountOutput := range mounts {
+		if strings.Trim(mount, "/") == strings.Trim(mountPath, "/") {
+			existingMount = mountOutput
 		}
 	}
 
-	if !hasMount {
-		err = vaultClient.Sys().Mount(conf.MountPath, &api.MountInput{
+	if existingMount == nil {
+		if conf.UseExistingMount {
+			lVault.Errorf("mount %s does not exist and engine is configured to use an existing mount", mountPath)
+			return nil, fmt.Errorf("mount %s does not exist", mountPath)
+		}
+
+		lVault.Infof("creating KV-V2 mount %s", mountPath)
+		err = vaultClient.Sys().Mount(mountPath, &api.MountInput{
 			Type: "kv-v2",
 		})
 
 		if err != nil {
 			return nil, err
 		}
+	} else if conf.UseExistingMount {
+		if existingMount.Type != "kv" || existingMount.Options["version"] != "2" {
+			lVault.Errorf("mount %s is not a KV-V2 mount: type=%s options=%v", mountPath, existingMount.Type, existingMount.Options)
+			return nil, fmt.Errorf("mount %s is not a KV-V2 mount", mountPath)
+		}
+		lVault.Infof("adopting existing KV-V2 mount %s", mountPath)
 	}
 
-	kv2 := vaultClient.KVv2(conf.MountPath)
+	kv2 := vaultClient.KVv2(mountPath)
 
 	return &VaultKV2Engine{
-		kvv2Client: kv2,
+		kvv2Client:      kv2,
+		mountPath:       mountPath,
+		keyPathTemplate: keyTmpl,
+		tenant:          conf.Tenant,
+		engineID:        conf.ID,
 	}, nil
 }
 
+// keyPath builds the secret path (relative to the mount) where the key with the given ID is stored.
+func (vaultCli *VaultKV2Engine) keyPath(keyID string) (string, error) {
+	assetType, assetID := splitCryptoAssetLRI(keyID)
+
+	var buf strings.Builder
+	err := vaultCli.keyPathTemplate.Execute(&buf, vaultPathTemplateData{
+		Tenant:    vaultCli.tenant,
+		EngineID:  vaultCli.engineID,
+		AssetType: assetType,
+		AssetID:   assetID,
+		KeyID:     keyID,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Trim(path.Clean(buf.String()), "/"), nil
+}
+
+// vaultKeyPathProbes are rendered with the key path template when the engine is built. They only differ in the
+// ID of the key (or of its asset), so a template not using it would store every key in the same secret.
+var vaultKeyPathProbes = []string{
+	"lms-caservice-certauth-keyid-aa",
+	"lms-caservice-certauth-keyid-bb",
+	"key-aa",
+	"key-bb",
+}
+
+// checkVaultKeyPathTemplate rejects the key path templates that render the same (or an empty) path for different
+// keys, i.e. templates missing the KeyID or AssetID placeholders.
+func checkVaultKeyPathTemplate(tmpl *template.Template, tenant, engineID string) error {
+	probe := &VaultKV2Engine{
+		keyPathTemplate: tmpl,
+		tenant:          tenant,
+		engineID:        engineID,
+	}
+
+	rendered := map[string]string{}
+	for _, keyID := range vaultKeyPathProbes {
+		keyPath, err := probe.keyPath(keyID)
+		if err != nil {
+			return err
+		}
+
+		if keyPath == "" || keyPath == "." {
+			return fmt.Errorf("key path template renders an empty path for key %s", keyID)
+		}
+
+		if other, ok := rendered[keyPath]; ok {
+			return fmt.Errorf("key path template renders the same path %q for keys %s and %s. It must use the KeyID or AssetID fields", keyPath, other, keyID)
+		}
+		rendered[keyPath] = keyID
+	}
+
+	return nil
+}
+
+// managedKeyName builds the managed (and transit) key name of the key with the given ID.
+func (vaultCli *VaultKV2Engine) managedKeyName(keyID string) (string, error) {
+	keyPath, err := vaultCli.keyPath(keyID)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.ReplaceAll(keyPath, "/", "-"), nil
+}
+
+func (vaultCli *VaultKV2Engine) getSecret(keyID string) (*api.KVSecret, error) {
+	secretPath, err := vaultCli.keyPath(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return vaultCli.kvv2Client.Get(context.Background(), secretPath)
+}
+
+func (vaultCli *VaultKV2Engine) putSecret(keyID string, data map[string]interface{}) (*api.KVSecret, error) {
+	secretPath, err := vaultCli.keyPath(keyID)
+	if err != nil {
+		return nil, err
+	}
+
+	return vaultCli.kvv2Client.Put(context.Background(), secretPath, data)
+}
+
+func (vaultCli *VaultKV2Engine) deleteSecret(keyID string) error {
+	secretPath, err := vaultCli.keyPath(keyID)
+	if err != nil {
+		return err
+	}
+
+	return vaultCli.kvv2Client.Delete(context.Background(), secretPath)
+}
+
+func renderVaultPath(name, tmpl string, data vaultPathTemplateData) (string, error) {
+	t, err := template.New(name).Option("missingkey=error").Parse(tmpl)
+	if err != nil {
+		return "", err
+	}
+
+	var buf strings.Builder
+	err = t.Execute(&buf, data)
+	if err != nil {
+		return "", err
+	}
+
+	return strings.Trim(path.Clean(buf.String()), "/"), nil
+}
+
+// splitCryptoAssetLRI extracts the asset type and asset ID from key IDs following the
+// "lms-caservice-<type>-keyid-<id>" layout used by the CA service. Key IDs with any other
+// layout are returned as the asset ID with an empty asset type.
+func splitCryptoAssetLRI(keyID string) (string, string) {
+	rest, ok := strings.CutPrefix(keyID, "lms-caservice-")
+	if !ok {
+		return "", keyID
+	}
+
+	assetType, assetID, ok := strings.Cut(rest, "-keyid-")
+	if !ok {
+		return "", keyID
+	}
+
+	return assetType, assetID
+}
+
 func (vaultCli *VaultKV2Engine) GetEngineConfig() models.CryptoEngineInfo {
+	if vaultCli.managedKeys != nil {
+		return models.CryptoEngineInfo{
+			Type:          models.VaultKV2,
+			SecurityLevel: models.SL2,
+			Provider:      "Hashicorp",
+			Name:          "Managed Keys",
+			Metadata: map[string]any{
+				"managed_key_type": vaultCli.managedKeys.keyType,
+			},
+			SupportedKeyTypes: []models.SupportedKeyTypeInfo{
+				{
+					Type: models.KeyType(x509.RSA),
+					Sizes: []int{
+						2048,
+						3072,
+						4096,
+					},
+				},
+				{
+					Type: models.KeyType(x509.ECDSA),
+					Sizes: []int{
+						256,
+						384,
+						521,
+					},
+				},
+			},
+		}
+	}
+
 	return models.CryptoEngineInfo{
 		Type:          models.VaultKV2,
 		SecurityLevel: models.SL1,
@@ -131,7 +334,16 @@ func (vaultCli *VaultKV2Engine) GetEngineConfig() models.CryptoEngineInfo {
 
 func (vaultCli *VaultKV2Engine) GetPrivateKeyByID(keyID string) (crypto.Signer, error) {
 	lVault.Debugf("requesting private key with ID [%s]", keyID)
-	key, err := vaultCli.kvv2Client.Get(context.Background(), keyID)
+	if vaultCli.managedKeys != nil {
+		name, err := vaultCli.managedKeyName(keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		return vaultCli.managedKeys.getKey(name)
+	}
+
+	key, err := vaultCli.getSecret(keyID)
 	if err != nil {
 		lVault.Errorf("could not get private key: %s", err)
 		return nil, errors.New("could not get private key")
@@ -170,6 +382,15 @@ func (vaultCli *VaultKV2Engine) GetPrivateKeyByID(keyID string) (crypto.Signer,
 
 func (vaultCli *VaultKV2Engine) CreateRSAPrivateKey(keySize int, keyID string) (crypto.Signer, error) {
 	lVault.Debugf("creating RSA private key of size [%d] with ID [%s]", keySize, keyID)
+	if vaultCli.managedKeys != nil {
+		name, err := vaultCli.managedKeyName(keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		return vaultCli.managedKeys.createKey(name, keySize, nil)
+	}
+
 	key, err := vaultCli.GetPrivateKeyByID(keyID)
 	if key != nil {
 		lVault.Warnf("RSA private key already exists and will be overwritten: %s", err)
@@ -196,7 +417,7 @@ func (vaultCli *VaultKV2Engine) CreateRSAPrivateKey(keySize int, keyID string) (
 		"key": keyBase64,
 	}
 
-	_, err = vaultCli.kvv2Client.Put(context.Background(), keyID, keyMap)
+	_, err = vaultCli.putSecret(keyID, keyMap)
 	if err != nil {
 		lVault.Errorf("could not create RSA key: %s", err)
 		return nil, err
@@ -208,6 +429,15 @@ func (vaultCli *VaultKV2Engine) CreateRSAPrivateKey(keySize int, keyID string) (
 
 func (vaultCli *VaultKV2Engine) CreateECDSAPrivateKey(c elliptic.Curve, keyID string) (crypto.Signer, error) {
 	lVault.Debugf("creating ECDSA private key of size [%d] with ID [%s]", c.Params().BitSize, keyID)
+	if vaultCli.managedKeys != nil {
+		name, err := vaultCli.managedKeyName(keyID)
+		if err != nil {
+			return nil, err
+		}
+
+		return vaultCli.managedKeys.createKey(name, 0, c)
+	}
+
 	key, err := ecdsa.GenerateKey(c, rand.Reader)
 
 	if err != nil {
@@ -229,12 +459,17 @@ func (vaultCli *VaultKV2Engine) CreateECDSAPrivateKey(c elliptic.Curve, keyID st
 		"key": keyBase64,
 	}
 
-	_, err = vaultCli.kvv2Client.Put(context.Background(), keyID, keyMap)
+	_, err = vaultCli.putSecret(keyID, keyMap)
 
 	return key, err
 }
 
 func (vaultCli *VaultKV2Engine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	if vaultCli.managedKeys != nil {
+		lVault.Warnf("managed keys are generated by the managed key backend and can not be imported")
+		return nil, fmt.Errorf("managed keys do not support key import")
+	}
+
 	keyPem := pem.EncodeToMemory(&pem.Block{
 		Type:  "RSA PRIVATE KEY",
 		Bytes: x509.MarshalPKCS1PrivateKey(key),
@@ -245,7 +480,7 @@ func (vaultCli *VaultKV2Engine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID s
 		"key": keyBase64,
 	}
 
-	_, err := vaultCli.kvv2Client.Put(context.Background(), keyID, keyMap)
+	_, err := vaultCli.putSecret(keyID, keyMap)
 	if err != nil {
 		lVault.Errorf("could not save the private key in vault: %s", err)
 		return nil, err
@@ -257,6 +492,11 @@ func (vaultCli *VaultKV2Engine) ImportRSAPrivateKey(key *rsa.PrivateKey, keyID s
 }
 
 func (vaultCli *VaultKV2Engine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, keyID string) (crypto.Signer, error) {
+	if vaultCli.managedKeys != nil {
+		lVault.Warnf("managed keys are generated by the managed key backend and can not be imported")
+		return nil, fmt.Errorf("managed keys do not support key import")
+	}
+
 	keyBytes, err := x509.MarshalECPrivateKey(key)
 
 	if err != nil {
@@ -272,7 +512,7 @@ func (vaultCli *VaultKV2Engine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, key
 		"key": keyBase64,
 	}
 
-	_, err = vaultCli.kvv2Client.Put(context.Background(), keyID, keyMap)
+	_, err = vaultCli.putSecret(keyID, keyMap)
 
 	if err != nil {
 		lVault.Errorf("Could not save the private key in vault: %s", err)
@@ -285,11 +525,63 @@ func (vaultCli *VaultKV2Engine) ImportECDSAPrivateKey(key *ecdsa.PrivateKey, key
 }
 
 func (vaultCli *VaultKV2Engine) DeleteKey(keyID string) error {
-	err := vaultCli.kvv2Client.Delete(context.Background(), keyID)
+	if vaultCli.managedKeys != nil {
+		name, err := vaultCli.managedKeyName(keyID)
+		if err != nil {
+			return err
+		}
+
+		return vaultCli.managedKeys.deleteKey(name)
+	}
+
+	err := vaultCli.deleteSecret(keyID)
 	return err
 }
 
 // ---------------------
+// NewVaultAPIClient builds a Vault API client, unsealing Vault if auto unseal is enabled, and logs in with the
+// AppRole credentials.
+func NewVaultAPIClient(logger *logrus.Entry, conf config.HashicorpVaultSDK) (*api.Client, error) {
+	address := fmt.Sprintf("%s://%s:%d", conf.Protocol, conf.Hostname, conf.Port)
+
+	vaultClientConf := api.DefaultConfig()
+	httpClient, err := helpers.BuildHTTPClientWithTLSOptions(&http.Client{}, conf.TLSConfig)
+
+	if err != nil {
+		return nil, err
+	}
+
+	httpClient, err = helpers.BuildHTTPClientWithTracerLogger(httpClient, logger)
+	if err != nil {
+		return nil, err
+	}
+
+	vaultClientConf.HttpClient = httpClient
+	vaultClientConf.Address = address
+	vaultClient, err := api.NewClient(vaultClientConf)
+
+	if err != nil {
+		logger.Errorf("could not create Vault API client: %s", err)
+		return nil, errors.New("could not create Vault API client: " + err.Error())
+	}
+
+	if conf.AutoUnsealEnabled {
+		err = Unseal(logger, vaultClient, conf.AutoUnsealKeys)
+		if err != nil {
+			logger.Errorf("could not unseal Vault: %s", err)
+			return nil, errors.New("could not unseal Vault: " + err.Error())
+		}
+	}
+
+	err = Login(vaultClient, conf.RoleID, string(conf.SecretID))
+	if err != nil {
+		logger.Errorf("could not login into Vault: %s", err)
+		return nil, errors.New("could not login into Vault: " + err.Error())
+	}
+
+	return vaultClient, nil
+}
+
 func CreateVaultSdkClient(httpClient *http.Client, vaultAddress string) (*api.Client, error) {
 	conf := api.DefaultConfig()
 
@@ -298,7 +590,7 @@ func CreateVaultSdkClient(httpClient *http.Client, vaultAddress string) (*api.Cl
 	return api.NewClient(conf)
 }
 
-func Unseal(client *api.Client, unsealKeys []config.Password) error {
+func Unseal(logger *logrus.Entry, client *api.Client, unsealKeys []config.Password) error {
 
 	providedSharesCount := 0
 	sealed := true
@@ -306,14 +598,14 @@ func Unseal(client *api.Client, unsealKeys []config.Password) error {
 	for sealed {
 		unsealStatusProgress, err := client.Sys().Unseal(string(unsealKeys[providedSharesCount]))
 		if err != nil {
-			lVault.Error("Error while unsealing vault: ", err)
+			logger.Error("Error while unsealing vault: ", err)
 			return err
 		}
-		lVault.Info("Unseal progress shares=" + strconv.Itoa(unsealStatusProgress.N) + " threshold=" + strconv.Itoa(unsealStatusProgress.T) + " remaining_shares=" + strconv.Itoa(unsealStatusProgress.Progress))
+		logger.Info("Unseal progress shares=" + strconv.Itoa(unsealStatusProgress.N) + " threshold=" + strconv.Itoa(unsealStatusProgress.T) + " remaining_shares=" + strconv.Itoa(unsealStatusProgress.Progress))
 
 		providedSharesCount++
 		if !unsealStatusProgress.Sealed {
-			lVault.Info("Vault is unsealed")
+			logger.Info("Vault is unsealed")
 			sealed = false
 		}
 	}
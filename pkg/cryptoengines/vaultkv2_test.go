@@ -4,6 +4,7 @@ import (
 	"crypto/elliptic"
 	"crypto/x509"
 	"testing"
+	"text/template"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
@@ -103,3 +104,54 @@ func prepareVaultkv2CryptoEngine(t *testing.T) CryptoEngine {
 
 	return engine
 }
+
+func TestVaultKeyPathTemplate(t *testing.T) {
+	tmpl, err := template.New("key-path").Option("missingkey=error").Parse("{{.Tenant}}/{{.AssetType}}/{{.AssetID}}")
+	assert.NoError(t, err)
+
+	engine := &VaultKV2Engine{
+		keyPathTemplate: tmpl,
+		tenant:          "acme",
+		engineID:        "vault-1",
+	}
+
+	path, err := engine.keyPath("lms-caservice-certauth-keyid-1a-2b-3c")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/certauth/1a-2b-3c", path)
+
+	path, err = engine.keyPath("custom-key")
+	assert.NoError(t, err)
+	assert.Equal(t, "acme/custom-key", path)
+}
+
+func TestCheckVaultKeyPathTemplate(t *testing.T) {
+	for tmpl, valid := range map[string]bool{
+		"{{.KeyID}}": true,
+		"{{.Tenant}}/{{.AssetType}}/{{.AssetID}}": true,
+		"{{.Tenant}}/{{.AssetType}}":              false,
+		"{{.EngineID}}/key":                       false,
+		"{{if false}}{{.KeyID}}{{end}}":           false,
+	} {
+		parsed, err := template.New("key-path").Option("missingkey=error").Parse(tmpl)
+		assert.NoError(t, err)
+
+		err = checkVaultKeyPathTemplate(parsed, "acme", "vault-1")
+		if valid {
+			assert.NoError(t, err, tmpl)
+		} else {
+			assert.Error(t, err, tmpl)
+		}
+	}
+}
+
+func TestVaultMountPathTemplate(t *testing.T) {
+	path, err := renderVaultPath("mount-path", "/lamassu/{{.Tenant}}/{{.EngineID}}/", vaultPathTemplateData{
+		Tenant:   "acme",
+		EngineID: "vault-1",
+	})
+	assert.NoError(t, err)
+	assert.Equal(t, "lamassu/acme/vault-1", path)
+
+	_, err = renderVaultPath("mount-path", "{{.Unknown}}", vaultPathTemplateData{})
+	assert.Error(t, err)
+}
@@ -0,0 +1,212 @@
+package cryptoengines
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+)
+
+const vaultDefaultTransitMountPath = "transit"
+
+// vaultManagedKeys keeps the keys in a Vault managed key backend. Each key is registered as a managed key (usable
+// from any mount) and as a Transit key of type managed_key, so signatures are computed by the backend and the private
+// key never reaches Vault nor Lamassu.
+type vaultManagedKeys struct {
+	client      *api.Client
+	keyType     string
+	parameters  map[string]interface{}
+	transitPath string
+}
+
+func newVaultManagedKeys(client *api.Client, conf config.HashicorpVaultManagedKeys) (*vaultManagedKeys, error) {
+	if conf.Type != "pkcs11" && conf.Type != "awskms" {
+		return nil, fmt.Errorf("unsupported managed key type '%s'", conf.Type)
+	}
+
+	transitPath := strings.Trim(conf.TransitMountPath, "/")
+	if transitPath == "" {
+		transitPath = vaultDefaultTransitMountPath
+	}
+
+	return &vaultManagedKeys{
+		client:      client,
+		keyType:     conf.Type,
+		parameters:  conf.Parameters,
+		transitPath: transitPath,
+	}, nil
+}
+
+// keyParameters builds the managed key parameters of a new RSA (bits) or ECDSA (curve) key.
+func (m *vaultManagedKeys) keyParameters(name string, bits int, curve elliptic.Curve) map[string]interface{} {
+	params := map[string]interface{}{}
+	for k, v := range m.parameters {
+		params[k] = v
+	}
+
+	params["allow_generate_key"] = true
+	params["any_mount"] = true
+
+	curveName := ""
+	if curve != nil {
+		curveName = strings.ReplaceAll(curve.Params().Name, "-", "")
+	}
+
+	switch m.keyType {
+	case "pkcs11":
+		params["key_label"] = name
+		if curve != nil {
+			params["mechanism"] = "0x1041" // CKM_ECDSA
+			params["curve"] = curveName
+		} else {
+			params["mechanism"] = "0x0001" // CKM_RSA_PKCS
+			params["key_bits"] = bits
+		}
+	case "awskms":
+		params["kms_key"] = "alias/" + name
+		if curve != nil {
+			params["key_type"] = "ECDSA"
+			params["curve"] = curveName
+		} else {
+			params["key_type"] = "RSA"
+			params["key_bits"] = bits
+		}
+	}
+
+	return params
+}
+
+func (m *vaultManagedKeys) createKey(name string, bits int, curve elliptic.Curve) (crypto.Signer, error) {
+	_, err := m.client.Logical().Write(fmt.Sprintf("sys/managed-keys/%s/%s", m.keyType, name), m.keyParameters(name, bits, curve))
+	if err != nil {
+		return nil, fmt.Errorf("could not register managed key %s: %w", name, err)
+	}
+
+	_, err = m.client.Logical().Write(fmt.Sprintf("%s/keys/%s", m.transitPath, name), map[string]interface{}{
+		"type":             "managed_key",
+		"managed_key_name": name,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("could not create transit key %s: %w", name, err)
+	}
+
+	return m.getKey(name)
+}
+
+func (m *vaultManagedKeys) getKey(name string) (crypto.Signer, error) {
+	secret, err := m.client.Logical().Read(fmt.Sprintf("%s/keys/%s", m.transitPath, name))
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil {
+		return nil, fmt.Errorf("transit key %s not found", name)
+	}
+
+	keys, _ := secret.Data["keys"].(map[string]interface{})
+	version, _ := secret.Data["latest_version"].(json.Number)
+	keyVersion, _ := keys[version.String()].(map[string]interface{})
+	publicKeyPEM, _ := keyVersion["public_key"].(string)
+
+	block, _ := pem.Decode([]byte(publicKeyPEM))
+	if block == nil {
+		return nil, fmt.Errorf("transit key %s has no public key", name)
+	}
+
+	publicKey, err := x509.ParsePKIXPublicKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("invalid public key of transit key %s: %w", name, err)
+	}
+
+	return &vaultManagedKeySigner{keys: m, name: name, publicKey: publicKey}, nil
+}
+
+func (m *vaultManagedKeys) deleteKey(name string) error {
+	_, err := m.client.Logical().Write(fmt.Sprintf("%s/keys/%s/config", m.transitPath, name), map[string]interface{}{
+		"deletion_allowed": true,
+	})
+	if err != nil {
+		return err
+	}
+
+	_, err = m.client.Logical().Delete(fmt.Sprintf("%s/keys/%s", m.transitPath, name))
+	if err != nil {
+		return err
+	}
+
+	// removes the managed key configuration. The key material is kept by the backend
+	_, err = m.client.Logical().Delete(fmt.Sprintf("sys/managed-keys/%s/%s", m.keyType, name))
+	return err
+}
+
+type vaultManagedKeySigner struct {
+	keys      *vaultManagedKeys
+	name      string
+	publicKey crypto.PublicKey
+}
+
+func (s *vaultManagedKeySigner) Public() crypto.PublicKey {
+	return s.publicKey
+}
+
+func (s *vaultManagedKeySigner) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpts) ([]byte, error) {
+	var hashAlgorithm string
+	switch h := opts.HashFunc(); h {
+	case crypto.SHA256:
+		hashAlgorithm = "sha2-256"
+	case crypto.SHA384:
+		hashAlgorithm = "sha2-384"
+	case crypto.SHA512:
+		hashAlgorithm = "sha2-512"
+	default:
+		return nil, fmt.Errorf("unsupported hash function %v", h)
+	}
+
+	input := map[string]interface{}{
+		"input":                base64.StdEncoding.EncodeToString(digest),
+		"prehashed":            true,
+		"marshaling_algorithm": "asn1",
+	}
+
+	if _, isRSA := s.publicKey.(*rsa.PublicKey); isRSA {
+		input["signature_algorithm"] = "pkcs1v15"
+		if pss, isPSS := opts.(*rsa.PSSOptions); isPSS {
+			input["signature_algorithm"] = "pss"
+			switch pss.SaltLength {
+			case rsa.PSSSaltLengthAuto:
+				input["salt_length"] = "auto"
+			case rsa.PSSSaltLengthEqualsHash:
+				input["salt_length"] = "hash"
+			default:
+				input["salt_length"] = pss.SaltLength
+			}
+		}
+	}
+
+	secret, err := s.keys.client.Logical().Write(fmt.Sprintf("%s/sign/%s/%s", s.keys.transitPath, s.name, hashAlgorithm), input)
+	if err != nil {
+		return nil, err
+	}
+
+	if secret == nil {
+		return nil, fmt.Errorf("empty sign response for transit key %s", s.name)
+	}
+
+	// signatures are formatted as vault:v<version>:<base64 signature>
+	signature, _ := secret.Data["signature"].(string)
+	parts := strings.SplitN(signature, ":", 3)
+	if len(parts) != 3 || parts[0] != "vault" {
+		return nil, fmt.Errorf("invalid signature format returned by transit key %s", s.name)
+	}
+
+	return base64.StdEncoding.DecodeString(parts[2])
+}
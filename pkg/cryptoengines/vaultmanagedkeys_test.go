@@ -0,0 +1,197 @@
+package cryptoengines
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"text/template"
+
+	"github.com/hashicorp/vault/api"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+)
+
+// fakeVaultManagedKeys emulates the managed keys and transit APIs used by the engine, keeping the keys in memory.
+type fakeVaultManagedKeys struct {
+	mu          sync.Mutex
+	managedKeys map[string]map[string]interface{}
+	keys        map[string]crypto.Signer
+}
+
+func (f *fakeVaultManagedKeys) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	body := map[string]interface{}{}
+	_ = json.NewDecoder(r.Body).Decode(&body)
+
+	reply := func(data map[string]interface{}) {
+		w.Header().Set("Content-Type", "application/json")
+		_ = json.NewEncoder(w).Encode(map[string]interface{}{"data": data})
+	}
+
+	p := strings.TrimPrefix(r.URL.Path, "/v1/")
+	switch {
+	case strings.HasPrefix(p, "sys/managed-keys/pkcs11/"):
+		name := strings.TrimPrefix(p, "sys/managed-keys/pkcs11/")
+		if r.Method == http.MethodDelete {
+			delete(f.managedKeys, name)
+		} else {
+			f.managedKeys[name] = body
+		}
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(p, "transit/sign/"):
+		name, hashAlgorithm, _ := strings.Cut(strings.TrimPrefix(p, "transit/sign/"), "/")
+		if hashAlgorithm != "sha2-256" || body["prehashed"] != true {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		digest, _ := base64.StdEncoding.DecodeString(body["input"].(string))
+		var opts crypto.SignerOpts = crypto.SHA256
+		if body["signature_algorithm"] == "pss" {
+			opts = &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash}
+		}
+
+		signature, err := f.keys[name].Sign(rand.Reader, digest, opts)
+		if err != nil {
+			w.WriteHeader(http.StatusInternalServerError)
+			return
+		}
+		reply(map[string]interface{}{"signature": "vault:v1:" + base64.StdEncoding.EncodeToString(signature)})
+	case strings.HasSuffix(p, "/config"):
+		w.WriteHeader(http.StatusNoContent)
+	case strings.HasPrefix(p, "transit/keys/"):
+		name := strings.TrimPrefix(p, "transit/keys/")
+		switch r.Method {
+		case http.MethodGet:
+			key, ok := f.keys[name]
+			if !ok {
+				w.WriteHeader(http.StatusNotFound)
+				return
+			}
+
+			der, _ := x509.MarshalPKIXPublicKey(key.Public())
+			reply(map[string]interface{}{
+				"latest_version": 1,
+				"keys": map[string]interface{}{
+					"1": map[string]interface{}{"public_key": string(pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}))},
+				},
+			})
+		case http.MethodDelete:
+			delete(f.keys, name)
+			w.WriteHeader(http.StatusNoContent)
+		default:
+			params := f.managedKeys[body["managed_key_name"].(string)]
+			if params["curve"] == "P256" {
+				f.keys[name], _ = ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+			} else {
+				f.keys[name], _ = rsa.GenerateKey(rand.Reader, int(params["key_bits"].(float64)))
+			}
+			w.WriteHeader(http.StatusNoContent)
+		}
+	default:
+		w.WriteHeader(http.StatusNotFound)
+	}
+}
+
+func prepareVaultManagedKeysEngine(t *testing.T) (*VaultKV2Engine, *fakeVaultManagedKeys) {
+	lVault = logrus.NewEntry(logrus.StandardLogger())
+
+	fake := &fakeVaultManagedKeys{managedKeys: map[string]map[string]interface{}{}, keys: map[string]crypto.Signer{}}
+	server := httptest.NewServer(fake)
+	t.Cleanup(server.Close)
+
+	client, err := api.NewClient(&api.Config{Address: server.URL, HttpClient: server.Client()})
+	assert.NoError(t, err)
+	client.SetToken("test")
+
+	managedKeys, err := newVaultManagedKeys(client, config.HashicorpVaultManagedKeys{
+		Type:       "pkcs11",
+		Parameters: map[string]interface{}{"library": "hsm", "slot": "0", "pin": "1234"},
+	})
+	assert.NoError(t, err)
+
+	tmpl, err := template.New("key-path").Parse(vaultDefaultKeyPathTemplate)
+	assert.NoError(t, err)
+
+	return &VaultKV2Engine{keyPathTemplate: tmpl, managedKeys: managedKeys}, fake
+}
+
+func TestVaultManagedKeysECDSA(t *testing.T) {
+	engine, fake := prepareVaultManagedKeysEngine(t)
+
+	signer, err := engine.CreateECDSAPrivateKey(elliptic.P256(), "test-ecdsa")
+	assert.NoError(t, err)
+
+	params := fake.managedKeys["test-ecdsa"]
+	assert.Equal(t, "hsm", params["library"])
+	assert.Equal(t, "test-ecdsa", params["key_label"])
+	assert.Equal(t, "0x1041", params["mechanism"])
+	assert.Equal(t, "P256", params["curve"])
+	assert.Equal(t, true, params["any_mount"])
+
+	digest := sha256.Sum256([]byte("message"))
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.NoError(t, err)
+	assert.True(t, ecdsa.VerifyASN1(signer.Public().(*ecdsa.PublicKey), digest[:], signature))
+
+	stored, err := engine.GetPrivateKeyByID("test-ecdsa")
+	assert.NoError(t, err)
+	assert.True(t, stored.Public().(*ecdsa.PublicKey).Equal(signer.Public()))
+
+	assert.NoError(t, engine.DeleteKey("test-ecdsa"))
+	_, err = engine.GetPrivateKeyByID("test-ecdsa")
+	assert.Error(t, err)
+	assert.NotContains(t, fake.managedKeys, "test-ecdsa")
+}
+
+func TestVaultManagedKeysRSA(t *testing.T) {
+	engine, fake := prepareVaultManagedKeysEngine(t)
+
+	signer, err := engine.CreateRSAPrivateKey(2048, "test-rsa")
+	assert.NoError(t, err)
+	assert.Equal(t, "0x0001", fake.managedKeys["test-rsa"]["mechanism"])
+
+	pub := signer.Public().(*rsa.PublicKey)
+	digest := sha256.Sum256([]byte("message"))
+
+	signature, err := signer.Sign(rand.Reader, digest[:], crypto.SHA256)
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature))
+
+	signature, err = signer.Sign(rand.Reader, digest[:], &rsa.PSSOptions{Hash: crypto.SHA256, SaltLength: rsa.PSSSaltLengthEqualsHash})
+	assert.NoError(t, err)
+	assert.NoError(t, rsa.VerifyPSS(pub, crypto.SHA256, digest[:], signature, nil))
+}
+
+func TestVaultManagedKeysImportNotSupported(t *testing.T) {
+	engine, _ := prepareVaultManagedKeysEngine(t)
+
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	_, err := engine.ImportECDSAPrivateKey(key, "imported")
+	assert.Error(t, err)
+
+	info := engine.GetEngineConfig()
+	assert.Equal(t, models.SL2, info.SecurityLevel)
+	assert.False(t, info.IsSoftware())
+}
+
+func TestVaultManagedKeysUnsupportedType(t *testing.T) {
+	_, err := newVaultManagedKeys(nil, config.HashicorpVaultManagedKeys{Type: "unknown"})
+	assert.Error(t, err)
+}
@@ -0,0 +1,102 @@
+package debugtrace
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/stretchr/testify/mock"
+)
+
+func requestContext(reqID string) context.Context {
+	return context.WithValue(context.Background(), headerextractors.CtxRequestID, reqID)
+}
+
+func TestRecorderEvictsOldestTraces(t *testing.T) {
+	recorder := NewRecorder()
+	for i := 0; i < maxTraces+1; i++ {
+		recorder.Record(requestContext(fmt.Sprintf("req-%d", i)), Span{Name: "op"})
+	}
+
+	if _, ok := recorder.Get("req-0"); ok {
+		t.Errorf("oldest trace should have been evicted")
+	}
+
+	if _, ok := recorder.Get(fmt.Sprintf("req-%d", maxTraces)); !ok {
+		t.Errorf("newest trace should be kept")
+	}
+}
+
+func TestRecorderSortsSpansAndSkipsContextsWithoutRequestID(t *testing.T) {
+	recorder := NewRecorder()
+	now := time.Now()
+
+	recorder.Record(requestContext("req"), Span{Name: "second", Start: now.Add(time.Second)})
+	recorder.Record(requestContext("req"), Span{Name: "first", Start: now})
+	recorder.Record(context.Background(), Span{Name: "orphan", Start: now})
+
+	trace, ok := recorder.Get("req")
+	if !ok {
+		t.Fatalf("trace not found")
+	}
+
+	if len(trace.Spans) != 2 || trace.Spans[0].Name != "first" || trace.Spans[1].Name != "second" {
+		t.Errorf("unexpected spans: %+v", trace.Spans)
+	}
+}
+
+func TestHTTPMiddlewareAssignsRequestID(t *testing.T) {
+	gin.SetMode(gin.TestMode)
+	recorder := NewRecorder()
+
+	router := gin.New()
+	router.Use(HTTPMiddleware(recorder, "ca"))
+	router.GET("/v1/cas/:id", func(c *gin.Context) {
+		recorder.Record(c, Span{Service: "ca", Kind: SpanStorage, Name: "ca.Update", Start: time.Now()})
+		c.Status(http.StatusOK)
+	})
+
+	w := httptest.NewRecorder()
+	router.ServeHTTP(w, httptest.NewRequest(http.MethodGet, "/v1/cas/my-ca", nil))
+
+	reqID := w.Header().Get(models.HttpRequestIDHeader)
+	if reqID == "" {
+		t.Fatalf("request ID not returned")
+	}
+
+	trace, ok := recorder.Get(reqID)
+	if !ok || len(trace.Spans) != 2 {
+		t.Fatalf("expected storage and HTTP spans, got %+v", trace)
+	}
+
+	httpSpan := trace.Spans[0]
+	if httpSpan.Kind != SpanHTTP || httpSpan.Name != "GET /v1/cas/:id" || httpSpan.Attributes["status"] != "200" {
+		t.Errorf("unexpected HTTP span: %+v", httpSpan)
+	}
+}
+
+func TestCATraceMiddlewareRecordsErrors(t *testing.T) {
+	recorder := NewRecorder()
+	mockCAService := new(svcmock.MockCAService)
+	mockCAService.On("SignCertificate", mock.Anything, mock.Anything).Return((*models.Certificate)(nil), errors.New("sign failed"))
+
+	svc := NewCATraceMiddleware(recorder)(mockCAService)
+	_, err := svc.SignCertificate(requestContext("req"), services.SignCertificateInput{CAID: "my-ca"})
+	if err == nil {
+		t.Fatalf("expected error")
+	}
+
+	trace, _ := recorder.Get("req")
+	if len(trace.Spans) != 1 || trace.Spans[0].Error != "sign failed" || trace.Spans[0].Attributes["ca_id"] != "my-ca" {
+		t.Errorf("unexpected spans: %+v", trace.Spans)
+	}
+}
@@ -0,0 +1,37 @@
+package debugtrace
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/middlewares/eventpub"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+type eventPublisher struct {
+	next     eventpub.ICloudEventMiddlewarePublisher
+	recorder *Recorder
+	service  string
+}
+
+// NewEventPublisher records a span for each event published by the service.
+func NewEventPublisher(next eventpub.ICloudEventMiddlewarePublisher, recorder *Recorder, service string) eventpub.ICloudEventMiddlewarePublisher {
+	return &eventPublisher{
+		next:     next,
+		recorder: recorder,
+		service:  service,
+	}
+}
+
+func (p *eventPublisher) PublishCloudEvent(ctx context.Context, eventType models.EventType, payload interface{}) {
+	start := time.Now()
+	p.next.PublishCloudEvent(ctx, eventType, payload)
+
+	p.recorder.Record(ctx, Span{
+		Service:  p.service,
+		Kind:     SpanEvent,
+		Name:     string(eventType),
+		Start:    start,
+		Duration: time.Since(start).String(),
+	})
+}
@@ -0,0 +1,49 @@
+package debugtrace
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/google/uuid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
+)
+
+// HTTPMiddleware records a span for each request served. Requests without request ID get one assigned, which is
+// returned in the response headers so the trace can be retrieved afterwards.
+func HTTPMiddleware(recorder *Recorder, service string) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		reqID := helpers.GetRequestID(c)
+		if reqID == "" {
+			reqID = uuid.NewString()
+			c.Set(headerextractors.CtxRequestID, reqID)
+		}
+		c.Header(models.HttpRequestIDHeader, reqID)
+
+		start := time.Now()
+		c.Next()
+
+		span := Span{
+			Service:  service,
+			Kind:     SpanHTTP,
+			Name:     fmt.Sprintf("%s %s", c.Request.Method, c.FullPath()),
+			Start:    start,
+			Duration: time.Since(start).String(),
+			Attributes: map[string]string{
+				"path":   c.Request.URL.Path,
+				"status": strconv.Itoa(c.Writer.Status()),
+			},
+		}
+		if src := c.GetString(headerextractors.CtxSource); src != "" {
+			span.Attributes["source"] = src
+		}
+		if len(c.Errors) > 0 {
+			span.Error = c.Errors.String()
+		}
+
+		recorder.Record(c, span)
+	}
+}
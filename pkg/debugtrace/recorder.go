@@ -0,0 +1,123 @@
+package debugtrace
+
+import (
+	"context"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+)
+
+const (
+	maxTraces        = 500
+	maxSpansPerTrace = 1000
+)
+
+type SpanKind string
+
+const (
+	SpanHTTP    SpanKind = "http"
+	SpanService SpanKind = "service"
+	SpanStorage SpanKind = "storage"
+	SpanEvent   SpanKind = "event"
+)
+
+type Span struct {
+	Service    string            `json:"service"`
+	Kind       SpanKind          `json:"kind"`
+	Name       string            `json:"name"`
+	Start      time.Time         `json:"start"`
+	Duration   string            `json:"duration"`
+	Error      string            `json:"error,omitempty"`
+	Attributes map[string]string `json:"attributes,omitempty"`
+}
+
+type Trace struct {
+	RequestID string `json:"request_id"`
+	Spans     []Span `json:"spans"`
+}
+
+// Recorder keeps in memory the spans of the last requests, grouped by request ID. Oldest requests are discarded first.
+type Recorder struct {
+	lock   sync.Mutex
+	traces map[string]*Trace
+	order  []string
+}
+
+var defaultRecorder = NewRecorder()
+
+// Default returns the recorder shared by all the services running in the process, so the monolithic deployment
+// returns the spans of every service involved in a request.
+func Default() *Recorder {
+	return defaultRecorder
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		traces: map[string]*Trace{},
+	}
+}
+
+// Record adds a span to the trace of the request carried by the context. Spans without request ID are discarded.
+func (r *Recorder) Record(ctx context.Context, span Span) {
+	reqID := helpers.GetRequestID(ctx)
+	if reqID == "" {
+		return
+	}
+
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	trace, ok := r.traces[reqID]
+	if !ok {
+		if len(r.order) >= maxTraces {
+			delete(r.traces, r.order[0])
+			r.order = r.order[1:]
+		}
+
+		trace = &Trace{RequestID: reqID}
+		r.traces[reqID] = trace
+		r.order = append(r.order, reqID)
+	}
+
+	if len(trace.Spans) < maxSpansPerTrace {
+		trace.Spans = append(trace.Spans, span)
+	}
+}
+
+// Get returns a copy of the trace of the given request with its spans sorted by start time.
+func (r *Recorder) Get(reqID string) (*Trace, bool) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	trace, ok := r.traces[reqID]
+	if !ok {
+		return nil, false
+	}
+
+	spans := append([]Span{}, trace.Spans...)
+	sort.SliceStable(spans, func(i, j int) bool { return spans[i].Start.Before(spans[j].Start) })
+
+	return &Trace{RequestID: reqID, Spans: spans}, true
+}
+
+func (r *Recorder) measure(ctx context.Context, service string, kind SpanKind, name string, attributes map[string]string, fn func() error) error {
+	start := time.Now()
+	err := fn()
+
+	span := Span{
+		Service:    service,
+		Kind:       kind,
+		Name:       name,
+		Start:      start,
+		Duration:   time.Since(start).String(),
+		Attributes: attributes,
+	}
+	if err != nil {
+		span.Error = err.Error()
+	}
+
+	r.Record(ctx, span)
+	return err
+}
@@ -0,0 +1,86 @@
+package debugtrace
+
+import (
+	"context"
+	"crypto/x509"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type caTraceMiddleware struct {
+	services.CAService
+	recorder *Recorder
+}
+
+// NewCATraceMiddleware records a span for each certificate signed by the CA service.
+func NewCATraceMiddleware(recorder *Recorder) services.CAMiddleware {
+	return func(next services.CAService) services.CAService {
+		return &caTraceMiddleware{
+			CAService: next,
+			recorder:  recorder,
+		}
+	}
+}
+
+func (mw *caTraceMiddleware) SignCertificate(ctx context.Context, input services.SignCertificateInput) (output *models.Certificate, err error) {
+	attributes := map[string]string{"ca_id": input.CAID}
+	err = mw.recorder.measure(ctx, "ca", SpanService, "SignCertificate", attributes, func() error {
+		output, err = mw.CAService.SignCertificate(ctx, input)
+		if err == nil {
+			attributes["serial_number"] = output.SerialNumber
+		}
+		return err
+	})
+
+	return output, err
+}
+
+type dmsTraceMiddleware struct {
+	services.DMSManagerService
+	recorder *Recorder
+}
+
+// NewDMSManagerTraceMiddleware records a span for each enrollment, re-enrollment and server-side key generation.
+func NewDMSManagerTraceMiddleware(recorder *Recorder) services.DMSManagerMiddleware {
+	return func(next services.DMSManagerService) services.DMSManagerService {
+		return &dmsTraceMiddleware{
+			DMSManagerService: next,
+			recorder:          recorder,
+		}
+	}
+}
+
+func (mw *dmsTraceMiddleware) enrollmentAttributes(csr *x509.CertificateRequest, aps string) map[string]string {
+	return map[string]string{
+		"dms_id":    aps,
+		"device_id": csr.Subject.CommonName,
+	}
+}
+
+func (mw *dmsTraceMiddleware) Enroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (crt *x509.Certificate, err error) {
+	err = mw.recorder.measure(ctx, "dms-manager", SpanService, "Enroll", mw.enrollmentAttributes(csr, aps), func() error {
+		crt, err = mw.DMSManagerService.Enroll(ctx, csr, aps)
+		return err
+	})
+
+	return crt, err
+}
+
+func (mw *dmsTraceMiddleware) Reenroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (crt *x509.Certificate, err error) {
+	err = mw.recorder.measure(ctx, "dms-manager", SpanService, "Reenroll", mw.enrollmentAttributes(csr, aps), func() error {
+		crt, err = mw.DMSManagerService.Reenroll(ctx, csr, aps)
+		return err
+	})
+
+	return crt, err
+}
+
+func (mw *dmsTraceMiddleware) ServerKeyGen(ctx context.Context, csr *x509.CertificateRequest, aps string) (crt *x509.Certificate, key interface{}, err error) {
+	err = mw.recorder.measure(ctx, "dms-manager", SpanService, "ServerKeyGen", mw.enrollmentAttributes(csr, aps), func() error {
+		crt, key, err = mw.DMSManagerService.ServerKeyGen(ctx, csr, aps)
+		return err
+	})
+
+	return crt, key, err
+}
@@ -0,0 +1,157 @@
+package debugtrace
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type caRepo struct {
+	storage.CACertificatesRepo
+	recorder *Recorder
+	service  string
+}
+
+// NewCACertificatesRepo records a span for each write into the CA repository. Reads are not recorded.
+func NewCACertificatesRepo(repo storage.CACertificatesRepo, recorder *Recorder, service string) storage.CACertificatesRepo {
+	return &caRepo{CACertificatesRepo: repo, recorder: recorder, service: service}
+}
+
+func (r *caRepo) Insert(ctx context.Context, caCertificate *models.CACertificate) (ca *models.CACertificate, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "ca.Insert", map[string]string{"id": caCertificate.ID}, func() error {
+		ca, err = r.CACertificatesRepo.Insert(ctx, caCertificate)
+		return err
+	})
+
+	return ca, err
+}
+
+func (r *caRepo) Update(ctx context.Context, caCertificate *models.CACertificate) (ca *models.CACertificate, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "ca.Update", map[string]string{"id": caCertificate.ID}, func() error {
+		ca, err = r.CACertificatesRepo.Update(ctx, caCertificate)
+		return err
+	})
+
+	return ca, err
+}
+
+func (r *caRepo) UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (ca *models.CACertificate, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "ca.UpdateIf", map[string]string{"id": caCertificate.ID}, func() error {
+		ca, err = r.CACertificatesRepo.UpdateIf(ctx, caCertificate, precondition)
+		return err
+	})
+
+	return ca, err
+}
+
+func (r *caRepo) Delete(ctx context.Context, caID string) error {
+	return r.recorder.measure(ctx, r.service, SpanStorage, "ca.Delete", map[string]string{"id": caID}, func() error {
+		return r.CACertificatesRepo.Delete(ctx, caID)
+	})
+}
+
+type certRepo struct {
+	storage.CertificatesRepo
+	recorder *Recorder
+	service  string
+}
+
+// NewCertificatesRepo records a span for each write into the certificates repository. Reads are not recorded.
+func NewCertificatesRepo(repo storage.CertificatesRepo, recorder *Recorder, service string) storage.CertificatesRepo {
+	return &certRepo{CertificatesRepo: repo, recorder: recorder, service: service}
+}
+
+func (r *certRepo) Insert(ctx context.Context, certificate *models.Certificate) (crt *models.Certificate, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "certificate.Insert", map[string]string{"id": certificate.SerialNumber}, func() error {
+		crt, err = r.CertificatesRepo.Insert(ctx, certificate)
+		return err
+	})
+
+	return crt, err
+}
+
+func (r *certRepo) Update(ctx context.Context, certificate *models.Certificate) (crt *models.Certificate, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "certificate.Update", map[string]string{"id": certificate.SerialNumber}, func() error {
+		crt, err = r.CertificatesRepo.Update(ctx, certificate)
+		return err
+	})
+
+	return crt, err
+}
+
+type deviceRepo struct {
+	storage.DeviceManagerRepo
+	recorder *Recorder
+	service  string
+}
+
+// NewDeviceManagerRepo records a span for each write into the devices repository. Reads are not recorded.
+func NewDeviceManagerRepo(repo storage.DeviceManagerRepo, recorder *Recorder, service string) storage.DeviceManagerRepo {
+	return &deviceRepo{DeviceManagerRepo: repo, recorder: recorder, service: service}
+}
+
+func (r *deviceRepo) Insert(ctx context.Context, device *models.Device) (dev *models.Device, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "device.Insert", map[string]string{"id": device.ID}, func() error {
+		dev, err = r.DeviceManagerRepo.Insert(ctx, device)
+		return err
+	})
+
+	return dev, err
+}
+
+func (r *deviceRepo) Update(ctx context.Context, device *models.Device) (dev *models.Device, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "device.Update", map[string]string{"id": device.ID}, func() error {
+		dev, err = r.DeviceManagerRepo.Update(ctx, device)
+		return err
+	})
+
+	return dev, err
+}
+
+func (r *deviceRepo) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (dev *models.Device, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "device.UpdateIf", map[string]string{"id": device.ID}, func() error {
+		dev, err = r.DeviceManagerRepo.UpdateIf(ctx, device, precondition)
+		return err
+	})
+
+	return dev, err
+}
+
+type dmsRepo struct {
+	storage.DMSRepo
+	recorder *Recorder
+	service  string
+}
+
+// NewDMSRepo records a span for each write into the DMS repository. Reads are not recorded.
+func NewDMSRepo(repo storage.DMSRepo, recorder *Recorder, service string) storage.DMSRepo {
+	return &dmsRepo{DMSRepo: repo, recorder: recorder, service: service}
+}
+
+func (r *dmsRepo) Insert(ctx context.Context, dms *models.DMS) (out *models.DMS, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "dms.Insert", map[string]string{"id": dms.ID}, func() error {
+		out, err = r.DMSRepo.Insert(ctx, dms)
+		return err
+	})
+
+	return out, err
+}
+
+func (r *dmsRepo) Update(ctx context.Context, dms *models.DMS) (out *models.DMS, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "dms.Update", map[string]string{"id": dms.ID}, func() error {
+		out, err = r.DMSRepo.Update(ctx, dms)
+		return err
+	})
+
+	return out, err
+}
+
+func (r *dmsRepo) UpdateIf(ctx context.Context, dms *models.DMS, precondition func(current *models.DMS) bool) (out *models.DMS, err error) {
+	err = r.recorder.measure(ctx, r.service, SpanStorage, "dms.UpdateIf", map[string]string{"id": dms.ID}, func() error {
+		out, err = r.DMSRepo.UpdateIf(ctx, dms, precondition)
+		return err
+	})
+
+	return out, err
+}
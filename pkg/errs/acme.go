@@ -0,0 +1,21 @@
+package errs
+
+import "errors"
+
+// ACME errors are wrapped with the details of the failure and mapped to the RFC 8555 problem types by the
+// ACME HTTP layer.
+var (
+	ErrACMEMalformed               error = errors.New("malformed request")
+	ErrACMEBadNonce                error = errors.New("invalid anti-replay nonce")
+	ErrACMEBadSignatureAlgorithm   error = errors.New("unsupported JWS signature algorithm")
+	ErrACMEUnauthorized            error = errors.New("unauthorized")
+	ErrACMEAccountDoesNotExist     error = errors.New("account does not exist")
+	ErrACMEExternalAccountRequired error = errors.New("external account binding required")
+	ErrACMEOrderNotReady           error = errors.New("order not ready")
+	ErrACMEOrderNotFound           error = errors.New("order not found")
+	ErrACMEBadCSR                  error = errors.New("invalid CSR")
+	ErrACMERejectedIdentifier      error = errors.New("identifier rejected")
+	ErrACMEUnsupportedIdentifier   error = errors.New("unsupported identifier")
+	ErrACMEIncorrectResponse       error = errors.New("challenge response does not match")
+	ErrACMEAlreadyRevoked          error = errors.New("certificate already revoked")
+)
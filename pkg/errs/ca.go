@@ -9,16 +9,57 @@ var (
 	ErrCAAlreadyExists                 error = errors.New("CA already exists")
 	ErrCAStatusTransitionNotAllowed    error = errors.New("status transition not allowed for CA")
 	ErrCAStatus                        error = errors.New("CA Status inconsistent")
+	ErrCAHasActiveCertificates         error = errors.New("CA has active certificates")
+	ErrCADeletionInProgress            error = errors.New("CA is already being deleted")
 	ErrCAAlreadyRevoked                error = errors.New("CA already revoked")
 	ErrCAIncompatibleHashFunc          error = errors.New("incompatible hash function")
 	ErrCAIncompatibleExpirationTimeRef error = errors.New("incompatible expiration time ref")
 	ErrCAIssuanceExpiration            error = errors.New("issuance expiration greater than CA expiration")
 	ErrCAType                          error = errors.New("CA type inconsistent")
 	ErrCAValidCertAndPrivKey           error = errors.New("CA and the provided key don't match")
+	ErrCAKeyNotExportable              error = errors.New("CA key can not be exported from its crypto engine")
+	ErrCAKeyMigrationVerification      error = errors.New("migrated CA key could not be verified")
+	ErrCAKeyMigrationConflict          error = errors.New("CA key was concurrently migrated to another engine")
+	ErrCAKeyInUse                      error = errors.New("CA key is in use in the crypto engine")
+	ErrCAKeyBackupInvalid              error = errors.New("CA key backup can not be opened or does not match the CA certificate")
+	ErrCAActionPendingApproval         error = errors.New("operation requires the approval of a second administrator")
+	ErrCAPendingActionNotFound         error = errors.New("CA has no pending operation")
+	ErrCAPendingActionExpired          error = errors.New("pending operation approval window expired")
+	ErrCAPendingActionSelfApproval     error = errors.New("pending operation must be approved by a different administrator")
+	ErrCAApprovalUnverifiedIdentity    error = errors.New("approvals require an identity verified by the authentication of the API")
+	ErrCATokenSigningNotEnabled        error = errors.New("CA is not enabled to sign tokens")
+	ErrCASPIFFENotEnabled              error = errors.New("CA is not enabled to issue SPIFFE SVIDs")
+	ErrSVIDInvalidSPIFFEID             error = errors.New("invalid SPIFFE ID")
+	ErrCAIssuanceLogNotEnabled         error = errors.New("issuance log is not enabled")
+	ErrCAIssuanceLogEntryNotFound      error = errors.New("certificate not found in the CA issuance log")
+	ErrCASuccessorAlreadyExists        error = errors.New("CA already has a successor")
+	ErrCAIssuancePaused                error = errors.New("CA issuance is paused")
+	ErrCASigningApprovalRequired       error = errors.New("sign operation requires an approved CA signing request")
+	ErrCADualControlNotEnabled         error = errors.New("CA does not enforce dual control over the sign operation")
+	ErrCASigningRequestNotFound        error = errors.New("CA signing request not found")
+	ErrCASigningRequestNotPending      error = errors.New("CA signing request is not pending")
+	ErrCASigningRequestExpired         error = errors.New("CA signing request approval window expired")
+	ErrCASigningRequestSelfApproval    error = errors.New("CA signing request must be approved by a different administrator")
+	ErrCASigningRequestApproverDenied  error = errors.New("administrator is not an approver of the CA signing requests")
+	ErrCASigningRequestAlreadyApproved error = errors.New("CA signing request already approved by the administrator")
 
 	ErrValidateBadRequest error = errors.New("struct validation error")
 
+	ErrInternalCertificateInvalidToken error = errors.New("invalid internal certificate token")
+
 	ErrCertificateNotFound                   error = errors.New("certificate not found")
 	ErrCertificateAlreadyRevoked             error = errors.New("certificate already revoked")
 	ErrCertificateStatusTransitionNotAllowed error = errors.New("new status transition not allowed for certificate")
+
+	ErrCertificateProfileNotFound      error = errors.New("certificate profile not found")
+	ErrCertificateProfileAlreadyExists error = errors.New("certificate profile already exists")
+	ErrCertificateProfileCABound       error = errors.New("CA already bound to another certificate profile")
+	ErrCertificateProfileViolation     error = errors.New("certificate request does not satisfy the certificate profile")
+
+	ErrCertificateRequestLimits error = errors.New("certificate request exceeds the configured limits")
+
+	ErrCertificateIssuanceVetoed  error = errors.New("certificate issuance vetoed by the CA issuance webhook")
+	ErrCertificateIssuanceWebhook error = errors.New("CA issuance webhook could not review the certificate issuance")
+
+	ErrOCSPIssuerMismatch error = errors.New("OCSP request issuer does not match the certificate issuer")
 )
@@ -0,0 +1,7 @@
+package errs
+
+import "errors"
+
+var (
+	ErrInjectedFault error = errors.New("injected fault")
+)
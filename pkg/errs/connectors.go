@@ -0,0 +1,8 @@
+package errs
+
+import "errors"
+
+var (
+	ErrConnectorNotFound             error = errors.New("connector not found")
+	ErrConnectorPendingEventNotFound error = errors.New("connector pending event not found")
+)
@@ -0,0 +1,7 @@
+package errs
+
+import "errors"
+
+var (
+	ErrDebugTraceNotFound error = errors.New("no trace recorded for request")
+)
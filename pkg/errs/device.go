@@ -5,4 +5,18 @@ import "errors"
 var (
 	ErrDeviceNotFound      error = errors.New("device not found")
 	ErrDeviceAlreadyExists error = errors.New("device already exits")
+	ErrDeviceInvalidID     error = errors.New("device ID does not satisfy the DMS device ID rules")
+
+	ErrDeviceCertificateNotBound error = errors.New("certificate is not bound to any device")
+
+	ErrDeviceSlotNotFound error = errors.New("device slot not found")
+	ErrDeviceNoIdentity   error = errors.New("device has no identity certificate")
+
+	ErrDeviceGroupNotFound           error = errors.New("device group not found")
+	ErrDeviceGroupBulkActionNotFound error = errors.New("device group bulk action not found")
+
+	ErrDeviceGroupBulkActionNotPending         error = errors.New("device group bulk action is not pending approval")
+	ErrDeviceGroupBulkActionApprovalExpired    error = errors.New("device group bulk action approval window expired")
+	ErrDeviceGroupBulkActionSelfApproval       error = errors.New("device group bulk action must be approved by a different administrator")
+	ErrDeviceGroupBulkActionUnverifiedApprover error = errors.New("approvals require an identity verified by the authentication of the API")
 )
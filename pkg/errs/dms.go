@@ -1,13 +1,88 @@
 package errs
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+)
 
 var (
 	ErrDMSNotFound      error = errors.New("DMS not found")
 	ErrDMSAlreadyExists error = errors.New("DMS already exists")
 
+	ErrDMSPublicKeyConflict error = errors.New("DMS public key already registered")
+	ErrDMSPendingApproval   error = errors.New("DMS registration is pending approval")
+	ErrDMSInvalidStatus     error = errors.New("invalid DMS status")
+
+	ErrDMSEnrollmentPolicyNotFound      error = errors.New("DMS enrollment policy not found")
+	ErrDMSEnrollmentPolicyAlreadyExists error = errors.New("DMS enrollment policy already exists")
+
+	ErrDMSBootstrapTokenNotFound      error = errors.New("DMS bootstrap token not found")
+	ErrDMSEnrollInvalidBootstrapToken error = errors.New("invalid bootstrap token")
+
+	ErrDMSClaimCodeNotFound error = errors.New("DMS claim code not found")
+	ErrDMSInvalidClaimCode  error = errors.New("invalid claim code")
+
 	ErrDMSOnlyEST              error = errors.New("DMS uses EST protocol")
 	ErrDMSInvalidAuthMode      error = errors.New("DMS invalid auth mode")
 	ErrDMSAuthModeNotSupported error = errors.New("DMS auth mode not supported")
 	ErrDMSEnrollInvalidCert    error = errors.New("invalid certificate")
+	ErrDMSEnrollExpiredCert    error = errors.New("expired certificate")
+	ErrDMSEnrollRevokedCert    error = errors.New("certificate is revoked")
+
+	ErrDMSEnrollForbidden           error = errors.New("DMS forbids new enrollments of existing devices")
+	ErrDMSEnrollDeviceNotRegistered error = errors.New("device not preregistered")
+	ErrDMSReenrollSubjectMismatch   error = errors.New("invalid RawSubject bytes")
+	ErrDMSReenrollWindowNotOpen     error = errors.New("invalid reenroll window")
+
+	ErrDMSServerKeyGenInvalidKeySize error = errors.New("unsupported server generated key size")
+
+	ErrDMSNoPendingSupersededRevocation error = errors.New("certificate has no pending superseded revocation")
+	ErrDMSSupersededGracePeriod         error = errors.New("superseded certificate grace period has not elapsed")
+
+	ErrDMSACMEEABNotConfigured error = errors.New("ACME external account binding is not configured")
+	ErrDMSACMEEABKeyNotFound   error = errors.New("ACME EAB key not found")
+	ErrDMSACMEEABKeyRevoked    error = errors.New("ACME EAB key already revoked")
+	ErrDMSACMENotEnabled       error = errors.New("DMS does not use the ACME protocol")
+
+	ErrDMSSecureElementMissing    error = errors.New("CSR does not include the secure element extension")
+	ErrDMSSecureElementNotAllowed error = errors.New("secure element rejected by the manufacturer allow-list")
+
+	ErrDMSTPMAttestationMissing error = errors.New("CSR does not include the TPM attestation extension")
+	ErrDMSTPMAttestationInvalid error = errors.New("invalid TPM attestation")
+
+	ErrDMSEnrollContextDenied error = errors.New("enrollment denied by the DMS context policy")
+
+	ErrDMSSelfServiceForbidden error = errors.New("caller is not an owner of the DMS")
+	ErrDMSCertificateNotOwned  error = errors.New("certificate was not issued for a device of the DMS")
+
+	ErrCARotationAlreadyStarted error = errors.New("CA rotation already started")
+	ErrCARotationNotFound       error = errors.New("CA rotation not found")
+	ErrCARotationCompleted      error = errors.New("CA rotation already completed")
 )
+
+// DMSPublicKeyConflictError is returned when a DMS is registered with a CSR whose public key belongs to an existing DMS.
+// It matches ErrDMSPublicKeyConflict with errors.Is.
+type DMSPublicKeyConflictError struct {
+	DMSID string
+}
+
+func (e *DMSPublicKeyConflictError) Error() string {
+	return fmt.Sprintf("%s by DMS '%s'", ErrDMSPublicKeyConflict, e.DMSID)
+}
+
+func (e *DMSPublicKeyConflictError) Is(target error) bool {
+	return target == ErrDMSPublicKeyConflict
+}
+
+// DMSEnrollCAError is returned when the CA service fails while serving an enrollment. It wraps the CA service error.
+type DMSEnrollCAError struct {
+	Err error
+}
+
+func (e *DMSEnrollCAError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *DMSEnrollCAError) Unwrap() error {
+	return e.Err
+}
@@ -0,0 +1,7 @@
+package errs
+
+import "errors"
+
+var (
+	ErrPreconditionFailed error = errors.New("resource has been modified: If-Match precondition failed")
+)
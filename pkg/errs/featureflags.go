@@ -0,0 +1,9 @@
+package errs
+
+import "errors"
+
+var (
+	ErrFeatureDisabled             error = errors.New("feature is disabled")
+	ErrFeatureFlagNotFound         error = errors.New("feature flag not found")
+	ErrFeatureFlagToggleNotAllowed error = errors.New("feature flags can not be toggled at runtime")
+)
@@ -0,0 +1,9 @@
+package errs
+
+import "errors"
+
+var (
+	ErrLogLevelInvalid         error = errors.New("invalid log level")
+	ErrLogSubsystemNotFound    error = errors.New("log subsystem not found")
+	ErrLogLevelDurationInvalid error = errors.New("invalid log level duration")
+)
@@ -74,6 +74,11 @@ func amqpConfig(conf config.AMQPConnection, serviceID string, logger *logrus.Ent
 		},
 	}
 
+	if conf.PrefetchCount > 0 {
+		logger.Debugf("consumer prefetch count set to %d", conf.PrefetchCount)
+		amqpConfig.Consume.Qos.PrefetchCount = conf.PrefetchCount
+	}
+
 	amqpConfig.Publish = amqp.PublishConfig{
 		GenerateRoutingKey: func(topic string) string {
 			return topic
@@ -3,10 +3,13 @@ package eventbus
 import (
 	"context"
 	"fmt"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services/handlers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/builder"
 	"github.com/sirupsen/logrus"
 )
 
@@ -19,12 +22,17 @@ type EventSubscriptionHandler struct {
 	router      *message.Router
 	subscriber  *message.Subscriber
 	handlerName string
-	topic       string
-	handler     *message.Handler
+	topics      []string
+	handlers    []*message.Handler
 }
 
 func NewEventBusSubscriptionHandler(conf config.EventBusEngine, serviceId string, lMessaging *logrus.Entry, handler handlers.EventHandler, handlerName string, topic string) (*EventSubscriptionHandler, error) {
+	return NewEventBusTopicsSubscriptionHandler(conf, serviceId, lMessaging, handler, handlerName, []string{topic})
+}
 
+// NewEventBusTopicsSubscriptionHandler subscribes the handler to each topic. Every topic is bound separately to
+// the service, so only the events matching one of the topics are delivered.
+func NewEventBusTopicsSubscriptionHandler(conf config.EventBusEngine, serviceId string, lMessaging *logrus.Entry, handler handlers.EventHandler, handlerName string, topics []string) (*EventSubscriptionHandler, error) {
 	eventBusRouter, err := NewEventBusRouter(conf, serviceId, lMessaging)
 	if err != nil {
 		return nil, fmt.Errorf("could not setup event bus: %s", err)
@@ -36,14 +44,48 @@ func NewEventBusSubscriptionHandler(conf config.EventBusEngine, serviceId string
 		return nil, err
 	}
 
-	mHandler := eventBusRouter.AddNoPublisherHandler(handlerName, topic, sub, handler.HandleEvent)
+	if conf.Consumers.ProcessedEventsTTL != "" {
+		ttl, err := models.ParseDuration(conf.Consumers.ProcessedEventsTTL)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse processed events TTL '%s': %s", conf.Consumers.ProcessedEventsTTL, err)
+		}
+
+		lMessaging.Debugf("skipping events redelivered within %s", conf.Consumers.ProcessedEventsTTL)
+		// The store is shared by the workers of every topic, so a redelivery consumed by another worker is detected.
+		store, err := newProcessedEventStore(conf.Consumers, serviceId, ttl, lMessaging)
+		if err != nil {
+			return nil, err
+		}
+
+		handler = handler.WithProcessedEventStore(store)
+	}
+
+	mHandlers := []*message.Handler{}
+	for _, topic := range topics {
+		name := handlerName
+		if len(topics) > 1 {
+			name = fmt.Sprintf("%s-%s", handlerName, topic)
+		}
+
+		// Each worker subscribes separately, becoming a competing consumer of the topic queue.
+		workers := TopicConcurrency(conf.Consumers, topic)
+		lMessaging.Debugf("consuming topic %s with %d workers", topic, workers)
+		for worker := 0; worker < workers; worker++ {
+			workerName := name
+			if workers > 1 {
+				workerName = fmt.Sprintf("%s-%d", name, worker)
+			}
+
+			mHandlers = append(mHandlers, eventBusRouter.AddNoPublisherHandler(workerName, topic, sub, handler.HandleEvent))
+		}
+	}
 
 	return &EventSubscriptionHandler{
 		router:      eventBusRouter,
 		subscriber:  &sub,
 		handlerName: handlerName,
-		topic:       topic,
-		handler:     mHandler,
+		topics:      topics,
+		handlers:    mHandlers,
 	}, nil
 }
 
@@ -52,6 +94,54 @@ func (s *EventSubscriptionHandler) RunAsync() {
 }
 
 func (s *EventSubscriptionHandler) Stop() {
-	s.handler.Stop()
+	for _, handler := range s.handlers {
+		handler.Stop()
+	}
 	s.router.Close()
 }
+
+// TopicConcurrency returns the number of workers consuming the topic queue. Ordered topics are always consumed by
+// a single worker.
+func TopicConcurrency(conf config.EventBusConsumers, topic string) int {
+	concurrency := conf.Concurrency
+	for _, topicConf := range conf.Topics {
+		if topicConf.Topic != topic {
+			continue
+		}
+
+		if topicConf.Ordered {
+			return 1
+		}
+
+		if topicConf.Concurrency > 0 {
+			concurrency = topicConf.Concurrency
+		}
+	}
+
+	if concurrency < 1 {
+		return 1
+	}
+
+	return concurrency
+}
+
+// newProcessedEventStore builds the store of the handled events. The records are namespaced by the service, so
+// services sharing the storage do not skip each other's events.
+func newProcessedEventStore(conf config.EventBusConsumers, serviceId string, ttl time.Duration, lMessaging *logrus.Entry) (handlers.ProcessedEventStore, error) {
+	if conf.ProcessedEventsStorage == nil {
+		lMessaging.Warnf("no processed events storage configured: only the redeliveries to this instance are skipped")
+		return handlers.NewMemoryProcessedEventStore(ttl), nil
+	}
+
+	engine, err := builder.BuildAndMigrateStorageEngine(lMessaging, *conf.ProcessedEventsStorage)
+	if err != nil {
+		return nil, fmt.Errorf("could not create processed events storage engine: %s", err)
+	}
+
+	repo, err := engine.GetProcessedEventsStorage()
+	if err != nil {
+		return nil, fmt.Errorf("could not get processed events storage: %s", err)
+	}
+
+	return handlers.NewStorageProcessedEventStore(repo, ttl, serviceId, lMessaging), nil
+}
@@ -0,0 +1,38 @@
+package eventbus
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+)
+
+func TestTopicConcurrency(t *testing.T) {
+	conf := config.EventBusConsumers{
+		Concurrency: 4,
+		Topics: []config.EventBusTopicConsumer{
+			{Topic: "certificate.#", Concurrency: 8},
+			{Topic: "device.#", Concurrency: 8, Ordered: true},
+			{Topic: "dms.#"},
+		},
+	}
+
+	testcases := []struct {
+		conf     config.EventBusConsumers
+		topic    string
+		expected int
+	}{
+		{conf: config.EventBusConsumers{}, topic: "#", expected: 1},
+		{conf: config.EventBusConsumers{Concurrency: -1}, topic: "#", expected: 1},
+		{conf: conf, topic: "#", expected: 4},
+		{conf: conf, topic: "certificate.#", expected: 8},
+		{conf: conf, topic: "device.#", expected: 1},
+		{conf: conf, topic: "dms.#", expected: 4},
+	}
+
+	for _, tc := range testcases {
+		concurrency := TopicConcurrency(tc.conf, tc.topic)
+		if concurrency != tc.expected {
+			t.Errorf("topic %s: expected %d workers, got %d", tc.topic, tc.expected, concurrency)
+		}
+	}
+}
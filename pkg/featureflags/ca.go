@@ -0,0 +1,32 @@
+package featureflags
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type caFeatureFlagsMiddleware struct {
+	services.CAService
+	flags *FeatureFlags
+}
+
+// NewCAFeatureFlagsMiddleware rejects the operations of the CA experimental subsystems that are disabled.
+func NewCAFeatureFlagsMiddleware(flags *FeatureFlags) services.CAMiddleware {
+	return func(next services.CAService) services.CAService {
+		return &caFeatureFlagsMiddleware{
+			CAService: next,
+			flags:     flags,
+		}
+	}
+}
+
+func (mw *caFeatureFlagsMiddleware) SignToken(ctx context.Context, input services.SignTokenInput) (*models.SignedToken, error) {
+	if !mw.flags.Enabled(TokenSigning) {
+		return nil, errs.ErrFeatureDisabled
+	}
+
+	return mw.CAService.SignToken(ctx, input)
+}
@@ -0,0 +1,48 @@
+package featureflags
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type dmsFeatureFlagsMiddleware struct {
+	services.DMSManagerService
+	flags *FeatureFlags
+}
+
+// NewDMSManagerFeatureFlagsMiddleware rejects the operations of the DMS Manager experimental subsystems that are disabled.
+func NewDMSManagerFeatureFlagsMiddleware(flags *FeatureFlags) services.DMSManagerMiddleware {
+	return func(next services.DMSManagerService) services.DMSManagerService {
+		return &dmsFeatureFlagsMiddleware{
+			DMSManagerService: next,
+			flags:             flags,
+		}
+	}
+}
+
+func (mw *dmsFeatureFlagsMiddleware) CreateACMEEABKey(ctx context.Context, input services.CreateACMEEABKeyInput) (*models.DMSACMEEABCredentials, error) {
+	if !mw.flags.Enabled(ACME) {
+		return nil, errs.ErrFeatureDisabled
+	}
+
+	return mw.DMSManagerService.CreateACMEEABKey(ctx, input)
+}
+
+func (mw *dmsFeatureFlagsMiddleware) GetACMEEABKeys(ctx context.Context, input services.GetACMEEABKeysInput) ([]models.DMSACMEEABKey, error) {
+	if !mw.flags.Enabled(ACME) {
+		return nil, errs.ErrFeatureDisabled
+	}
+
+	return mw.DMSManagerService.GetACMEEABKeys(ctx, input)
+}
+
+func (mw *dmsFeatureFlagsMiddleware) RevokeACMEEABKey(ctx context.Context, input services.RevokeACMEEABKeyInput) (*models.DMSACMEEABKey, error) {
+	if !mw.flags.Enabled(ACME) {
+		return nil, errs.ErrFeatureDisabled
+	}
+
+	return mw.DMSManagerService.RevokeACMEEABKey(ctx, input)
+}
@@ -0,0 +1,98 @@
+package featureflags
+
+import (
+	"maps"
+	"sync"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/sirupsen/logrus"
+)
+
+type Flag string
+
+// Experimental subsystems of the CA service.
+const (
+	TokenSigning Flag = "token-signing"
+)
+
+// Experimental subsystems of the DMS Manager service.
+const (
+	ACME Flag = "acme"
+)
+
+var (
+	CAFlags         = []Flag{TokenSigning}
+	DMSManagerFlags = []Flag{ACME}
+)
+
+// FeatureFlags holds the state of the experimental subsystems of a service.
+type FeatureFlags struct {
+	mu             sync.RWMutex
+	flags          map[Flag]bool
+	runtimeToggles bool
+	logger         *logrus.Entry
+}
+
+// NewFeatureFlags returns the flags known by the service, initialized with the configured values.
+// Configured flags unknown by the service are ignored.
+func NewFeatureFlags(known []Flag, conf config.FeatureFlags, logger *logrus.Entry) *FeatureFlags {
+	flags := map[Flag]bool{}
+	for _, flag := range known {
+		flags[flag] = conf.Flags[string(flag)]
+	}
+
+	for name := range conf.Flags {
+		if _, ok := flags[Flag(name)]; !ok {
+			logger.Warnf("ignoring unknown feature flag '%s'", name)
+		}
+	}
+
+	return &FeatureFlags{
+		flags:          flags,
+		runtimeToggles: conf.RuntimeToggles,
+		logger:         logger,
+	}
+}
+
+func (f *FeatureFlags) Enabled(flag Flag) bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return f.flags[flag]
+}
+
+func (f *FeatureFlags) List() map[Flag]bool {
+	f.mu.RLock()
+	defer f.mu.RUnlock()
+
+	return maps.Clone(f.flags)
+}
+
+func (f *FeatureFlags) RuntimeToggles() bool {
+	return f.runtimeToggles
+}
+
+// Toggle enables or disables a flag at runtime.
+//
+// Returned Error Codes:
+//   - ErrFeatureFlagToggleNotAllowed
+//     Runtime toggles are not enabled in the service configuration.
+//   - ErrFeatureFlagNotFound
+//     The flag is not known by the service.
+func (f *FeatureFlags) Toggle(flag Flag, enabled bool) error {
+	if !f.runtimeToggles {
+		return errs.ErrFeatureFlagToggleNotAllowed
+	}
+
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if _, ok := f.flags[flag]; !ok {
+		return errs.ErrFeatureFlagNotFound
+	}
+
+	f.logger.Infof("feature flag '%s' set to %t", flag, enabled)
+	f.flags[flag] = enabled
+	return nil
+}
@@ -0,0 +1,73 @@
+package featureflags
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestFeatureFlagsFromConfig(t *testing.T) {
+	flags := NewFeatureFlags(CAFlags, config.FeatureFlags{
+		Flags: map[string]bool{
+			string(TokenSigning): true,
+			"unknown":            true,
+		},
+	}, logrus.NewEntry(logrus.StandardLogger()))
+
+	assert.True(t, flags.Enabled(TokenSigning))
+	assert.False(t, flags.Enabled("unknown"))
+	assert.Equal(t, map[Flag]bool{TokenSigning: true}, flags.List())
+}
+
+func TestFeatureFlagsToggle(t *testing.T) {
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	static := NewFeatureFlags(CAFlags, config.FeatureFlags{}, logger)
+	assert.Equal(t, errs.ErrFeatureFlagToggleNotAllowed, static.Toggle(TokenSigning, true))
+	assert.False(t, static.Enabled(TokenSigning))
+
+	runtime := NewFeatureFlags(CAFlags, config.FeatureFlags{RuntimeToggles: true}, logger)
+	assert.NoError(t, runtime.Toggle(TokenSigning, true))
+	assert.True(t, runtime.Enabled(TokenSigning))
+	assert.Equal(t, errs.ErrFeatureFlagNotFound, runtime.Toggle(ACME, true))
+}
+
+func TestCAFeatureFlagsMiddleware(t *testing.T) {
+	flags := NewFeatureFlags(CAFlags, config.FeatureFlags{RuntimeToggles: true}, logrus.NewEntry(logrus.StandardLogger()))
+	mockCAService := new(svcmock.MockCAService)
+	svc := NewCAFeatureFlagsMiddleware(flags)(mockCAService)
+
+	_, err := svc.SignToken(context.Background(), services.SignTokenInput{CAID: "ca-1"})
+	assert.Equal(t, errs.ErrFeatureDisabled, err)
+	mockCAService.AssertNotCalled(t, "SignToken", mock.Anything, mock.Anything)
+
+	mockCAService.On("SignToken", mock.Anything, mock.Anything).Return(&models.SignedToken{KeyID: "ca-1"}, nil)
+	assert.NoError(t, flags.Toggle(TokenSigning, true))
+
+	token, err := svc.SignToken(context.Background(), services.SignTokenInput{CAID: "ca-1"})
+	assert.NoError(t, err)
+	assert.Equal(t, "ca-1", token.KeyID)
+}
+
+func TestDMSManagerFeatureFlagsMiddleware(t *testing.T) {
+	flags := NewFeatureFlags(DMSManagerFlags, config.FeatureFlags{}, logrus.NewEntry(logrus.StandardLogger()))
+	mockDMSService := new(svcmock.MockDMSManagerService)
+	svc := NewDMSManagerFeatureFlagsMiddleware(flags)(mockDMSService)
+
+	_, err := svc.CreateACMEEABKey(context.Background(), services.CreateACMEEABKeyInput{DMSID: "dms-1"})
+	assert.Equal(t, errs.ErrFeatureDisabled, err)
+
+	_, err = svc.GetACMEEABKeys(context.Background(), services.GetACMEEABKeysInput{DMSID: "dms-1"})
+	assert.Equal(t, errs.ErrFeatureDisabled, err)
+
+	_, err = svc.RevokeACMEEABKey(context.Background(), services.RevokeACMEEABKeyInput{DMSID: "dms-1", KeyID: "kid-1"})
+	assert.Equal(t, errs.ErrFeatureDisabled, err)
+}
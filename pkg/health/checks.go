@@ -0,0 +1,108 @@
+package health
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// Counter is implemented by all the storage repositories.
+type Counter interface {
+	Count(ctx context.Context) (int, error)
+}
+
+// StorageCheck runs a count query against a repository of the storage engine.
+func StorageCheck(repo Counter) Check {
+	return func(ctx context.Context) error {
+		_, err := repo.Count(ctx)
+		return err
+	}
+}
+
+// HTTPServiceCheck calls the health endpoint of a Lamassu service. baseURL is the URL the service client is
+// configured with.
+func HTTPServiceCheck(client *http.Client, baseURL string) Check {
+	return func(ctx context.Context) error {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(baseURL, "/")+"/health", nil)
+		if err != nil {
+			return err
+		}
+
+		res, err := client.Do(req)
+		if err != nil {
+			return err
+		}
+		defer res.Body.Close()
+
+		if res.StatusCode != http.StatusOK {
+			return fmt.Errorf("health endpoint returned status %d", res.StatusCode)
+		}
+
+		return nil
+	}
+}
+
+// TCPCheck opens a TCP connection to the address.
+func TCPCheck(address string) Check {
+	return func(ctx context.Context) error {
+		conn, err := (&net.Dialer{}).DialContext(ctx, "tcp", address)
+		if err != nil {
+			return err
+		}
+
+		return conn.Close()
+	}
+}
+
+// EventBusCheck returns the check of the event bus broker. Only the AMQP provider can be checked, so ok is false
+// for any other provider.
+func EventBusCheck(conf config.EventBusEngine) (check Check, ok bool) {
+	if conf.Provider != config.Amqp {
+		return nil, false
+	}
+
+	return TCPCheck(net.JoinHostPort(conf.Amqp.Hostname, fmt.Sprint(conf.Amqp.Port))), true
+}
+
+// CryptoEngineCheck reads a key from the engine. keyID returns the ID of a key stored in the engine, or an empty
+// string if the engine stores no keys yet, in which case the engine can not be probed and it is reported healthy,
+// unless the engine tracks its own availability and it is unavailable.
+func CryptoEngineCheck(engine cryptoengines.CryptoEngine, keyID func(ctx context.Context) (string, error)) Check {
+	return func(ctx context.Context) error {
+		id, err := keyID(ctx)
+		if err != nil {
+			return fmt.Errorf("could not select key to probe: %w", err)
+		}
+
+		if id == "" {
+			if reporter, ok := engine.(cryptoengines.HealthReporter); ok && !reporter.Available() {
+				return cryptoengines.ErrCryptoEngineUnavailable
+			}
+			return nil
+		}
+
+		_, err = engine.GetPrivateKeyByID(id)
+		return err
+	}
+}
+
+// ConnectorHealthCheck adapts the health reported by a cloud connector.
+func ConnectorHealthCheck(health func(ctx context.Context) models.ConnectorHealth) Check {
+	return func(ctx context.Context) error {
+		h := health(ctx)
+		switch h.Status {
+		case models.ConnectorHealthy:
+			return nil
+		case models.ConnectorDegraded:
+			return fmt.Errorf("%w: %s", ErrDegraded, h.Message)
+		default:
+			return fmt.Errorf("connector is unhealthy: %s", h.Message)
+		}
+	}
+}
@@ -0,0 +1,177 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+const defaultCheckTimeout = 5 * time.Second
+
+// Check probes a dependency. Errors wrapping ErrDegraded report the dependency as degraded instead of unhealthy.
+type Check func(ctx context.Context) error
+
+var ErrDegraded = errors.New("degraded")
+
+// StatusPublisher publishes the status documents to the event bus.
+type StatusPublisher interface {
+	PublishCloudEvent(ctx context.Context, eventType models.EventType, payload interface{})
+}
+
+type dependency struct {
+	name     string
+	kind     models.DependencyKind
+	critical bool
+	check    Check
+}
+
+// Monitor checks the dependencies of a service and aggregates their health into a status document. It implements
+// cron.Job so it can be scheduled with jobs.NewJobScheduler to publish the document periodically.
+type Monitor struct {
+	serviceID    string
+	checkTimeout time.Duration
+	logger       *logrus.Entry
+
+	mu           sync.RWMutex
+	dependencies []dependency
+	publisher    StatusPublisher
+}
+
+// NewMonitor returns a monitor without dependencies. Checks taking longer than checkTimeout (5s if zero) report
+// the dependency as unhealthy.
+func NewMonitor(serviceID string, checkTimeout time.Duration, logger *logrus.Entry) *Monitor {
+	if checkTimeout == 0 {
+		checkTimeout = defaultCheckTimeout
+	}
+
+	return &Monitor{
+		serviceID:    serviceID,
+		checkTimeout: checkTimeout,
+		logger:       logger,
+		dependencies: []dependency{},
+	}
+}
+
+// Register adds a dependency to the monitor. The service is unhealthy while a critical dependency is not healthy,
+// and degraded while any other dependency is not healthy.
+func (m *Monitor) Register(name string, kind models.DependencyKind, critical bool, check Check) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.dependencies = append(m.dependencies, dependency{
+		name:     name,
+		kind:     kind,
+		critical: critical,
+		check:    check,
+	})
+}
+
+// SetPublisher sets the publisher used by Run. Without publisher, Run only logs the unhealthy dependencies.
+func (m *Monitor) SetPublisher(publisher StatusPublisher) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.publisher = publisher
+}
+
+// Status checks all the dependencies concurrently and returns the aggregated status document.
+func (m *Monitor) Status(ctx context.Context) models.ServiceStatus {
+	m.mu.RLock()
+	dependencies := m.dependencies
+	m.mu.RUnlock()
+
+	results := make([]models.DependencyHealth, len(dependencies))
+
+	wg := sync.WaitGroup{}
+	for i, dep := range dependencies {
+		wg.Add(1)
+		go func(i int, dep dependency) {
+			defer wg.Done()
+			results[i] = m.check(ctx, dep)
+		}(i, dep)
+	}
+	wg.Wait()
+
+	status := models.HealthStatusHealthy
+	for _, result := range results {
+		if result.Status == models.HealthStatusHealthy {
+			continue
+		}
+
+		if result.Critical && result.Status == models.HealthStatusUnhealthy {
+			status = models.HealthStatusUnhealthy
+		} else if status == models.HealthStatusHealthy {
+			status = models.HealthStatusDegraded
+		}
+	}
+
+	return models.ServiceStatus{
+		Service:      m.serviceID,
+		Status:       status,
+		Dependencies: results,
+		Timestamp:    time.Now(),
+	}
+}
+
+func (m *Monitor) check(ctx context.Context, dep dependency) models.DependencyHealth {
+	ctx, cancel := context.WithTimeout(ctx, m.checkTimeout)
+	defer cancel()
+
+	start := time.Now()
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- dep.check(ctx)
+	}()
+
+	var err error
+	select {
+	case err = <-errCh:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+
+	result := models.DependencyHealth{
+		Name:      dep.name,
+		Kind:      dep.kind,
+		Critical:  dep.critical,
+		Status:    models.HealthStatusHealthy,
+		LatencyMs: time.Since(start).Milliseconds(),
+		CheckedAt: start,
+	}
+
+	if err != nil {
+		result.Message = err.Error()
+		result.Status = models.HealthStatusUnhealthy
+		if errors.Is(err, ErrDegraded) {
+			result.Status = models.HealthStatusDegraded
+		}
+	}
+
+	return result
+}
+
+// Run checks the dependencies and publishes the status document.
+func (m *Monitor) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, m.logger)
+
+	status := m.Status(ctx)
+	for _, dep := range status.Dependencies {
+		if dep.Status != models.HealthStatusHealthy {
+			lFunc.Warnf("%s dependency %s is %s: %s", dep.Kind, dep.Name, dep.Status, dep.Message)
+		}
+	}
+
+	m.mu.RLock()
+	publisher := m.publisher
+	m.mu.RUnlock()
+
+	if publisher != nil {
+		publisher.PublishCloudEvent(ctx, models.EventServiceStatusKey, status)
+	}
+}
@@ -0,0 +1,147 @@
+package health
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockPublisher struct {
+	mock.Mock
+}
+
+func (m *mockPublisher) PublishCloudEvent(ctx context.Context, eventType models.EventType, payload interface{}) {
+	m.Called(ctx, eventType, payload)
+}
+
+func healthy(ctx context.Context) error {
+	return nil
+}
+
+func failing(ctx context.Context) error {
+	return errors.New("connection refused")
+}
+
+func degraded(ctx context.Context) error {
+	return fmt.Errorf("%w: throttled", ErrDegraded)
+}
+
+func newTestMonitor(timeout time.Duration) *Monitor {
+	return NewMonitor("test", timeout, logrus.NewEntry(logrus.StandardLogger()))
+}
+
+func TestMonitorStatus(t *testing.T) {
+	var testcases = []struct {
+		name         string
+		register     func(m *Monitor)
+		expectStatus models.HealthStatus
+	}{
+		{
+			name:         "OK/NoDependencies",
+			register:     func(m *Monitor) {},
+			expectStatus: models.HealthStatusHealthy,
+		},
+		{
+			name: "OK/AllHealthy",
+			register: func(m *Monitor) {
+				m.Register("storage", models.DependencyKindStorage, true, healthy)
+				m.Register("event-bus", models.DependencyKindEventBus, false, healthy)
+			},
+			expectStatus: models.HealthStatusHealthy,
+		},
+		{
+			name: "Degraded/NonCriticalUnhealthy",
+			register: func(m *Monitor) {
+				m.Register("storage", models.DependencyKindStorage, true, healthy)
+				m.Register("event-bus", models.DependencyKindEventBus, false, failing)
+			},
+			expectStatus: models.HealthStatusDegraded,
+		},
+		{
+			name: "Degraded/CriticalDegraded",
+			register: func(m *Monitor) {
+				m.Register("connector", models.DependencyKindConnector, true, degraded)
+			},
+			expectStatus: models.HealthStatusDegraded,
+		},
+		{
+			name: "Unhealthy/CriticalUnhealthy",
+			register: func(m *Monitor) {
+				m.Register("storage", models.DependencyKindStorage, true, failing)
+				m.Register("event-bus", models.DependencyKindEventBus, false, degraded)
+			},
+			expectStatus: models.HealthStatusUnhealthy,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			m := newTestMonitor(0)
+			tc.register(m)
+
+			status := m.Status(context.Background())
+			assert.Equal(t, "test", status.Service)
+			assert.Equal(t, tc.expectStatus, status.Status)
+		})
+	}
+}
+
+func TestMonitorStatusDependencies(t *testing.T) {
+	m := newTestMonitor(0)
+	m.Register("storage", models.DependencyKindStorage, true, healthy)
+	m.Register("event-bus", models.DependencyKindEventBus, false, failing)
+	m.Register("connector", models.DependencyKindConnector, true, degraded)
+
+	status := m.Status(context.Background())
+	assert.Len(t, status.Dependencies, 3)
+
+	assert.Equal(t, "storage", status.Dependencies[0].Name)
+	assert.Equal(t, models.HealthStatusHealthy, status.Dependencies[0].Status)
+	assert.Empty(t, status.Dependencies[0].Message)
+
+	assert.Equal(t, models.DependencyKindEventBus, status.Dependencies[1].Kind)
+	assert.Equal(t, models.HealthStatusUnhealthy, status.Dependencies[1].Status)
+	assert.Equal(t, "connection refused", status.Dependencies[1].Message)
+
+	assert.True(t, status.Dependencies[2].Critical)
+	assert.Equal(t, models.HealthStatusDegraded, status.Dependencies[2].Status)
+}
+
+func TestMonitorStatusCheckTimeout(t *testing.T) {
+	m := newTestMonitor(50 * time.Millisecond)
+	m.Register("storage", models.DependencyKindStorage, true, func(ctx context.Context) error {
+		time.Sleep(time.Second)
+		return nil
+	})
+
+	start := time.Now()
+	status := m.Status(context.Background())
+	assert.Less(t, time.Since(start), time.Second)
+	assert.Equal(t, models.HealthStatusUnhealthy, status.Status)
+	assert.Equal(t, context.DeadlineExceeded.Error(), status.Dependencies[0].Message)
+}
+
+func TestMonitorRunPublishesStatus(t *testing.T) {
+	m := newTestMonitor(0)
+	m.Register("event-bus", models.DependencyKindEventBus, false, failing)
+
+	// Without publisher the status is only logged
+	m.Run()
+
+	pub := &mockPublisher{}
+	pub.On("PublishCloudEvent", mock.Anything, models.EventServiceStatusKey, mock.MatchedBy(func(status models.ServiceStatus) bool {
+		return status.Service == "test" && status.Status == models.HealthStatusDegraded
+	})).Once()
+
+	m.SetPublisher(pub)
+	m.Run()
+
+	pub.AssertExpectations(t)
+}
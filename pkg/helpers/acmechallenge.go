@@ -0,0 +1,89 @@
+package helpers
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+var acmeDNSNameRegex = regexp.MustCompile(`^(\*\.)?([a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?\.)*[a-z0-9]([a-z0-9-]{0,61}[a-z0-9])?$`)
+
+// ValidateACMEDNSIdentifier checks that the value of a "dns" ACME identifier is a lowercase domain name. Only the
+// leftmost label can be a wildcard.
+func ValidateACMEDNSIdentifier(value string) error {
+	if len(value) > 253 || !acmeDNSNameRegex.MatchString(value) {
+		return fmt.Errorf("%s is not a valid domain name", value)
+	}
+
+	if net.ParseIP(value) != nil {
+		return fmt.Errorf("%s is an IP address", value)
+	}
+
+	return nil
+}
+
+// ACMEKeyAuthorization returns the key authorization of a challenge token (RFC 8555 8.1).
+func ACMEKeyAuthorization(token, accountKeyThumbprint string) string {
+	return token + "." + accountKeyThumbprint
+}
+
+// ValidateACMEHTTP01Challenge fetches the http-01 challenge resource of the domain (RFC 8555 8.3) and checks it
+// matches the key authorization.
+func ValidateACMEHTTP01Challenge(ctx context.Context, client *http.Client, domain string, port int, token, keyAuthorization string) error {
+	host := domain
+	if port != 0 && port != 80 {
+		host = net.JoinHostPort(domain, strconv.Itoa(port))
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, fmt.Sprintf("http://%s/.well-known/acme-challenge/%s", host, token), nil)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("could not fetch http-01 challenge: %w", err)
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("http-01 challenge resource returned status %d", res.StatusCode)
+	}
+
+	body, err := io.ReadAll(io.LimitReader(res.Body, 1024))
+	if err != nil {
+		return fmt.Errorf("could not read http-01 challenge: %w", err)
+	}
+
+	if strings.TrimRight(string(body), " \t\r\n") != keyAuthorization {
+		return fmt.Errorf("http-01 challenge resource does not match the key authorization")
+	}
+
+	return nil
+}
+
+// ValidateACMEDNS01Challenge looks up the _acme-challenge TXT records of the domain (RFC 8555 8.4) and checks one of
+// them holds the digest of the key authorization.
+func ValidateACMEDNS01Challenge(ctx context.Context, resolver *net.Resolver, domain, keyAuthorization string) error {
+	records, err := resolver.LookupTXT(ctx, "_acme-challenge."+domain)
+	if err != nil {
+		return fmt.Errorf("could not look up dns-01 challenge: %w", err)
+	}
+
+	digest := sha256.Sum256([]byte(keyAuthorization))
+	expected := base64.RawURLEncoding.EncodeToString(digest[:])
+	for _, record := range records {
+		if record == expected {
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no _acme-challenge TXT record matches the key authorization")
+}
@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"testing"
+)
+
+func TestValidateACMEDNSIdentifier(t *testing.T) {
+	var testcases = []struct {
+		value   string
+		wantErr bool
+	}{
+		{value: "device.lamassu.io"},
+		{value: "*.lamassu.io"},
+		{value: "localhost"},
+		{value: "Device.lamassu.io", wantErr: true},
+		{value: "device.*.lamassu.io", wantErr: true},
+		{value: "-device.lamassu.io", wantErr: true},
+		{value: "device.lamassu.io.", wantErr: true},
+		{value: "10.0.0.1", wantErr: true},
+		{value: "", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.value, func(t *testing.T) {
+			err := ValidateACMEDNSIdentifier(tc.value)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateACMEHTTP01Challenge(t *testing.T) {
+	keyAuth := ACMEKeyAuthorization("token-1", "thumbprint")
+
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/.well-known/acme-challenge/token-1" {
+			w.WriteHeader(http.StatusNotFound)
+			return
+		}
+		fmt.Fprintln(w, keyAuth)
+	}))
+	defer srv.Close()
+
+	host, portStr, _ := net.SplitHostPort(srv.Listener.Addr().String())
+	port, _ := strconv.Atoi(portStr)
+
+	err := ValidateACMEHTTP01Challenge(context.Background(), srv.Client(), host, port, "token-1", keyAuth)
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	err = ValidateACMEHTTP01Challenge(context.Background(), srv.Client(), host, port, "token-1", ACMEKeyAuthorization("token-1", "other"))
+	if err == nil {
+		t.Errorf("expected error for a key authorization mismatch")
+	}
+
+	err = ValidateACMEHTTP01Challenge(context.Background(), srv.Client(), host, port, "token-2", keyAuth)
+	if err == nil {
+		t.Errorf("expected error for a missing challenge resource")
+	}
+}
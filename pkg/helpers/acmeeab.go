@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// DeriveACMEEABHMACKey returns the HMAC key of the EAB key issued by the DMS. The ACME front-end
+// uses it to verify the external account binding JWS sent by the ACME clients.
+func DeriveACMEEABHMACKey(secret []byte, dmsID, keyID string) []byte {
+	mac := hmac.New(sha256.New, secret)
+	mac.Write([]byte(dmsID + "/" + keyID))
+	return mac.Sum(nil)
+}
+
+// GetACMEEABKeys returns the EAB keys issued by the DMS, including the revoked ones.
+func GetACMEEABKeys(dms models.DMS) ([]models.DMSACMEEABKey, error) {
+	keys := []models.DMSACMEEABKey{}
+	_, err := GetMetadataToStruct(dms.Metadata, models.DMSMetadataACMEEABKeysKey, &keys)
+	if err != nil {
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+// GetActiveACMEEABKey returns the EAB key of the DMS with the given ID, only if it has not been revoked.
+func GetActiveACMEEABKey(dms models.DMS, keyID string) (*models.DMSACMEEABKey, bool) {
+	keys, err := GetACMEEABKeys(dms)
+	if err != nil {
+		return nil, false
+	}
+
+	for _, key := range keys {
+		if key.KeyID == keyID && key.RevokedAt == nil {
+			return &key, true
+		}
+	}
+
+	return nil, false
+}
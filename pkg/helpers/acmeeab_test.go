@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"bytes"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestDeriveACMEEABHMACKey(t *testing.T) {
+	secret := []byte("eab-secret")
+
+	key := DeriveACMEEABHMACKey(secret, "dms-1", "kid-1")
+	if !bytes.Equal(key, DeriveACMEEABHMACKey(secret, "dms-1", "kid-1")) {
+		t.Errorf("HMAC key derivation must be deterministic")
+	}
+
+	if bytes.Equal(key, DeriveACMEEABHMACKey(secret, "dms-2", "kid-1")) {
+		t.Errorf("HMAC keys must differ between DMSs")
+	}
+
+	if bytes.Equal(key, DeriveACMEEABHMACKey([]byte("other-secret"), "dms-1", "kid-1")) {
+		t.Errorf("HMAC keys must differ between secrets")
+	}
+}
+
+func TestGetActiveACMEEABKey(t *testing.T) {
+	revokedAt := time.Now()
+	dms := models.DMS{
+		ID: "dms-1",
+		Metadata: map[string]any{
+			models.DMSMetadataACMEEABKeysKey: []models.DMSACMEEABKey{
+				{KeyID: "kid-1", DMSID: "dms-1"},
+				{KeyID: "kid-2", DMSID: "dms-1", RevokedAt: &revokedAt},
+			},
+		},
+	}
+
+	if _, ok := GetActiveACMEEABKey(dms, "kid-1"); !ok {
+		t.Errorf("expected kid-1 to be active")
+	}
+
+	if _, ok := GetActiveACMEEABKey(dms, "kid-2"); ok {
+		t.Errorf("expected kid-2 to be revoked")
+	}
+
+	if _, ok := GetActiveACMEEABKey(dms, "kid-3"); ok {
+		t.Errorf("expected kid-3 not to be found")
+	}
+}
@@ -0,0 +1,147 @@
+package helpers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/xeipuuv/gojsonschema"
+)
+
+// jsonPathOperators are the comparisons supported by the JSON-PATH conditions. Longer operators go first so
+// "!=" is not parsed as "=".
+var jsonPathOperators = []string{"==", "!="}
+
+// ValidateSubscriptionCondition checks the condition can be evaluated against the events.
+func ValidateSubscriptionCondition(condition models.SubscriptionCondition) error {
+	switch condition.Type {
+	case models.JSONSchema:
+		_, err := gojsonschema.NewSchema(gojsonschema.NewStringLoader(condition.Condition))
+		if err != nil {
+			return fmt.Errorf("invalid JSON schema: %w", err)
+		}
+		return nil
+	case models.JSONPath:
+		_, _, _, err := parseJSONPathCondition(condition.Condition)
+		return err
+	case models.DMSIDs:
+		return nil
+	default:
+		return fmt.Errorf("unsupported condition type %s", condition.Type)
+	}
+}
+
+// EvaluateSubscriptionCondition reports whether the event data satisfies the condition:
+//   - JSON-SCHEMA: the data is valid against the schema.
+//   - JSON-PATH: "$.a.b[0]" matches if the value exists and is not null nor false. The value can also be compared
+//     with a JSON literal, i.e. "$.status == \"REVOKED\"" or "$.count != 0".
+//
+// DMS-IDS conditions are not evaluated against the event data, so they always match.
+func EvaluateSubscriptionCondition(condition models.SubscriptionCondition, data []byte) (bool, error) {
+	switch condition.Type {
+	case models.JSONSchema:
+		result, err := gojsonschema.Validate(gojsonschema.NewStringLoader(condition.Condition), gojsonschema.NewBytesLoader(data))
+		if err != nil {
+			return false, err
+		}
+		return result.Valid(), nil
+	case models.JSONPath:
+		path, operator, expected, err := parseJSONPathCondition(condition.Condition)
+		if err != nil {
+			return false, err
+		}
+
+		var doc any
+		if err := json.Unmarshal(data, &doc); err != nil {
+			return false, fmt.Errorf("event data is not JSON: %w", err)
+		}
+
+		value, found := lookupJSONPath(doc, path)
+		switch operator {
+		case "==":
+			return found && reflect.DeepEqual(value, expected), nil
+		case "!=":
+			return !found || !reflect.DeepEqual(value, expected), nil
+		default:
+			return found && value != nil && value != false, nil
+		}
+	case models.DMSIDs:
+		return true, nil
+	default:
+		return false, fmt.Errorf("unsupported condition type %s", condition.Type)
+	}
+}
+
+// parseJSONPathCondition splits a "$.a.b[0] <operator> <JSON literal>" condition into the path segments, the
+// operator and the decoded literal. The operator is empty for existence checks.
+func parseJSONPathCondition(condition string) ([]string, string, any, error) {
+	expr := strings.TrimSpace(condition)
+	operator := ""
+	var expected any
+
+	for _, op := range jsonPathOperators {
+		if path, literal, found := strings.Cut(expr, op); found {
+			if err := json.Unmarshal([]byte(strings.TrimSpace(literal)), &expected); err != nil {
+				return nil, "", nil, fmt.Errorf("value of JSON path condition '%s' is not a JSON literal: %w", condition, err)
+			}
+			expr, operator = strings.TrimSpace(path), op
+			break
+		}
+	}
+
+	if expr != "$" && !strings.HasPrefix(expr, "$.") && !strings.HasPrefix(expr, "$[") {
+		return nil, "", nil, fmt.Errorf("JSON path condition '%s' must start with '$'", condition)
+	}
+
+	segments := []string{}
+	for _, field := range strings.Split(strings.TrimPrefix(expr, "$"), ".") {
+		for field != "" {
+			name, rest, hasIndex := strings.Cut(field, "[")
+			if name != "" {
+				segments = append(segments, name)
+			}
+			if !hasIndex {
+				break
+			}
+
+			index, remaining, found := strings.Cut(rest, "]")
+			if _, err := strconv.Atoi(index); !found || err != nil {
+				return nil, "", nil, fmt.Errorf("JSON path condition '%s' has an invalid index", condition)
+			}
+			segments = append(segments, "["+index)
+			field = remaining
+		}
+	}
+
+	return segments, operator, expected, nil
+}
+
+func lookupJSONPath(doc any, segments []string) (any, bool) {
+	current := doc
+	for _, segment := range segments {
+		if index, isIndex := strings.CutPrefix(segment, "["); isIndex {
+			list, ok := current.([]any)
+			i, _ := strconv.Atoi(index)
+			if !ok || i < 0 || i >= len(list) {
+				return nil, false
+			}
+			current = list[i]
+			continue
+		}
+
+		object, ok := current.(map[string]any)
+		if !ok {
+			return nil, false
+		}
+
+		current, ok = object[segment]
+		if !ok {
+			return nil, false
+		}
+	}
+
+	return current, true
+}
@@ -0,0 +1,63 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestEvaluateSubscriptionCondition(t *testing.T) {
+	data := []byte(`{"id":"ca-1","status":"REVOKED","metadata":{"tier":"gold"},"serials":["aa","bb"],"count":0,"enabled":false}`)
+
+	testcases := []struct {
+		name      string
+		condition models.SubscriptionCondition
+		want      bool
+		wantErr   bool
+	}{
+		{name: "Schema match", condition: models.SubscriptionCondition{Type: models.JSONSchema, Condition: `{"type":"object","properties":{"status":{"const":"REVOKED"}},"required":["status"]}`}, want: true},
+		{name: "Schema mismatch", condition: models.SubscriptionCondition{Type: models.JSONSchema, Condition: `{"type":"object","properties":{"status":{"const":"ACTIVE"}}}`}, want: false},
+		{name: "Path exists", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "$.metadata.tier"}, want: true},
+		{name: "Path missing", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "$.metadata.region"}, want: false},
+		{name: "Path false", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "$.enabled"}, want: false},
+		{name: "Path equal", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: `$.status == "REVOKED"`}, want: true},
+		{name: "Path index", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: `$.serials[1] == "bb"`}, want: true},
+		{name: "Path index out of range", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "$.serials[2]"}, want: false},
+		{name: "Path not equal number", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "$.count != 0"}, want: false},
+		{name: "Path not equal missing", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: `$.region != "eu"`}, want: true},
+		{name: "DMS IDs", condition: models.SubscriptionCondition{Type: models.DMSIDs, Condition: "dms-1"}, want: true},
+		{name: "Invalid path", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "status"}, wantErr: true},
+		{name: "Invalid literal", condition: models.SubscriptionCondition{Type: models.JSONPath, Condition: "$.status == REVOKED"}, wantErr: true},
+		{name: "Unknown type", condition: models.SubscriptionCondition{Type: "XPATH", Condition: "/status"}, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := EvaluateSubscriptionCondition(tc.condition, data)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", got)
+				}
+				if ValidateSubscriptionCondition(tc.condition) == nil {
+					t.Fatalf("expected the condition to be rejected")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %v, got %v", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestValidateSubscriptionConditionSchema(t *testing.T) {
+	err := ValidateSubscriptionCondition(models.SubscriptionCondition{Type: models.JSONSchema, Condition: `{"type":`})
+	if err == nil {
+		t.Fatalf("expected malformed schema to be rejected")
+	}
+}
@@ -0,0 +1,51 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// GenerateBootstrapToken returns a random base64url encoded bootstrap token and its ID.
+func GenerateBootstrapToken() (token string, id string, err error) {
+	raw := make([]byte, 32)
+	_, err = rand.Read(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	token = base64.RawURLEncoding.EncodeToString(raw)
+	return token, BootstrapTokenID(token), nil
+}
+
+// BootstrapTokenID returns the hex encoded SHA-256 of the bootstrap token, which is the only form in which it is stored.
+func BootstrapTokenID(token string) string {
+	sum := sha256.Sum256([]byte(token))
+	return hex.EncodeToString(sum[:])
+}
+
+// CheckBootstrapToken returns an error describing why the bootstrap token can not enroll the device with the DMS.
+func CheckBootstrapToken(token models.DMSBootstrapToken, dmsID, deviceID string, now time.Time) error {
+	if token.DMSID != dmsID {
+		return fmt.Errorf("token was issued by DMS '%s'", token.DMSID)
+	}
+
+	if token.UsedAt != nil {
+		return fmt.Errorf("token was already used by device '%s'", token.UsedBy)
+	}
+
+	if !now.Before(token.ExpiresAt) {
+		return fmt.Errorf("token expired at %s", token.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if token.DeviceID != "" && token.DeviceID != deviceID {
+		return fmt.Errorf("token is scoped to device '%s'", token.DeviceID)
+	}
+
+	return nil
+}
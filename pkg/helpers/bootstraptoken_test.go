@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestGenerateBootstrapToken(t *testing.T) {
+	token, id, err := GenerateBootstrapToken()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if id != BootstrapTokenID(token) {
+		t.Errorf("token ID must be derived from the token")
+	}
+
+	other, _, _ := GenerateBootstrapToken()
+	if token == other {
+		t.Errorf("tokens must be random")
+	}
+}
+
+func TestCheckBootstrapToken(t *testing.T) {
+	now := time.Now()
+	usedAt := now.Add(-time.Minute)
+
+	var testcases = []struct {
+		name     string
+		token    models.DMSBootstrapToken
+		deviceID string
+		valid    bool
+	}{
+		{name: "OK", token: models.DMSBootstrapToken{DMSID: "dms-1", ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1", valid: true},
+		{name: "OK/DeviceScope", token: models.DMSBootstrapToken{DMSID: "dms-1", DeviceID: "dev-1", ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1", valid: true},
+		{name: "Err/OtherDMS", token: models.DMSBootstrapToken{DMSID: "dms-2", ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1"},
+		{name: "Err/Used", token: models.DMSBootstrapToken{DMSID: "dms-1", ExpiresAt: now.Add(time.Hour), UsedAt: &usedAt, UsedBy: "dev-0"}, deviceID: "dev-1"},
+		{name: "Err/Expired", token: models.DMSBootstrapToken{DMSID: "dms-1", ExpiresAt: now}, deviceID: "dev-1"},
+		{name: "Err/OtherDevice", token: models.DMSBootstrapToken{DMSID: "dms-1", DeviceID: "dev-2", ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckBootstrapToken(tc.token, "dms-1", tc.deviceID, now)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
@@ -0,0 +1,234 @@
+package helpers
+
+import (
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+var (
+	oidExtensionKeyUsage         = asn1.ObjectIdentifier{2, 5, 29, 15}
+	oidExtensionExtendedKeyUsage = asn1.ObjectIdentifier{2, 5, 29, 37}
+)
+
+var keyUsages = map[models.KeyUsage]x509.KeyUsage{
+	models.KeyUsageDigitalSignature:  x509.KeyUsageDigitalSignature,
+	models.KeyUsageContentCommitment: x509.KeyUsageContentCommitment,
+	models.KeyUsageKeyEncipherment:   x509.KeyUsageKeyEncipherment,
+	models.KeyUsageDataEncipherment:  x509.KeyUsageDataEncipherment,
+	models.KeyUsageKeyAgreement:      x509.KeyUsageKeyAgreement,
+	models.KeyUsageCertSign:          x509.KeyUsageCertSign,
+	models.KeyUsageCRLSign:           x509.KeyUsageCRLSign,
+	models.KeyUsageEncipherOnly:      x509.KeyUsageEncipherOnly,
+	models.KeyUsageDecipherOnly:      x509.KeyUsageDecipherOnly,
+}
+
+var extendedKeyUsages = map[models.ExtendedKeyUsage]struct {
+	usage x509.ExtKeyUsage
+	oid   asn1.ObjectIdentifier
+}{
+	models.ExtendedKeyUsageServerAuth:      {x509.ExtKeyUsageServerAuth, asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 1}},
+	models.ExtendedKeyUsageClientAuth:      {x509.ExtKeyUsageClientAuth, asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 2}},
+	models.ExtendedKeyUsageCodeSigning:     {x509.ExtKeyUsageCodeSigning, asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 3}},
+	models.ExtendedKeyUsageEmailProtection: {x509.ExtKeyUsageEmailProtection, asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 4}},
+	models.ExtendedKeyUsageTimeStamping:    {x509.ExtKeyUsageTimeStamping, asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 8}},
+	models.ExtendedKeyUsageOCSPSigning:     {x509.ExtKeyUsageOCSPSigning, asn1.ObjectIdentifier{1, 3, 6, 1, 5, 5, 7, 3, 9}},
+}
+
+// KeyUsagesToX509 combines the key usages into the x509 key usage bit mask.
+func KeyUsagesToX509(usages []models.KeyUsage) (x509.KeyUsage, error) {
+	var keyUsage x509.KeyUsage
+	for _, usage := range usages {
+		x509Usage, ok := keyUsages[usage]
+		if !ok {
+			return 0, fmt.Errorf("unknown key usage %s", usage)
+		}
+		keyUsage |= x509Usage
+	}
+
+	return keyUsage, nil
+}
+
+// ExtendedKeyUsagesToX509 maps the extended key usages to their x509 counterparts.
+func ExtendedKeyUsagesToX509(usages []models.ExtendedKeyUsage) ([]x509.ExtKeyUsage, error) {
+	extKeyUsages := []x509.ExtKeyUsage{}
+	for _, usage := range usages {
+		x509Usage, ok := extendedKeyUsages[usage]
+		if !ok {
+			return nil, fmt.Errorf("unknown extended key usage %s", usage)
+		}
+		extKeyUsages = append(extKeyUsages, x509Usage.usage)
+	}
+
+	return extKeyUsages, nil
+}
+
+// ValidateCertificateProfile checks that the profile only references known usages and subject attributes
+// and that its common name pattern compiles.
+func ValidateCertificateProfile(profile models.CertificateProfile) error {
+	if _, err := KeyUsagesToX509(profile.KeyUsages); err != nil {
+		return err
+	}
+
+	if _, err := ExtendedKeyUsagesToX509(profile.ExtendedKeyUsages); err != nil {
+		return err
+	}
+
+	if err := ValidateSubjectOrder(profile.SubjectConstraints.RequiredAttributes); err != nil {
+		return fmt.Errorf("invalid required subject attributes: %w", err)
+	}
+
+	if _, err := regexp.Compile(profile.SubjectConstraints.CommonNamePattern); err != nil {
+		return fmt.Errorf("invalid common name pattern: %w", err)
+	}
+
+	if profile.MaxValidity != nil && *profile.MaxValidity <= 0 {
+		return fmt.Errorf("max validity must be positive")
+	}
+
+	if profile.NotBeforeBackdate != nil && *profile.NotBeforeBackdate < 0 {
+		return fmt.Errorf("not before backdate can not be negative")
+	}
+
+	return nil
+}
+
+// ValidateCertificateRequestWithProfile checks that the CSR and the subject of the certificate to be issued
+// satisfy the profile: the requested SANs must be allowed by the SAN policy, the subject must satisfy the
+// subject constraints and the key usages requested in the CSR (if any) must be part of the profile usages.
+func ValidateCertificateRequestWithProfile(csr *x509.CertificateRequest, subject models.Subject, profile models.CertificateProfile) error {
+	err := validateSANs(csr, profile.SANPolicy)
+	if err != nil {
+		return err
+	}
+
+	err = validateSubject(subject, profile.SubjectConstraints)
+	if err != nil {
+		return err
+	}
+
+	return validateRequestedUsages(csr, profile)
+}
+
+func validateSANs(csr *x509.CertificateRequest, policy models.SANPolicy) error {
+	if len(csr.DNSNames) > 0 && !policy.AllowDNSNames {
+		return fmt.Errorf("DNS names are not allowed")
+	}
+
+	if len(csr.IPAddresses) > 0 && !policy.AllowIPAddresses {
+		return fmt.Errorf("IP addresses are not allowed")
+	}
+
+	if len(csr.EmailAddresses) > 0 && !policy.AllowEmailAddresses {
+		return fmt.Errorf("email addresses are not allowed")
+	}
+
+	if len(csr.URIs) > 0 && !policy.AllowURIs {
+		return fmt.Errorf("URIs are not allowed")
+	}
+
+	if len(policy.DNSDomains) == 0 {
+		return nil
+	}
+
+	for _, dnsName := range csr.DNSNames {
+		allowed := slices.ContainsFunc(policy.DNSDomains, func(domain string) bool {
+			domain = strings.ToLower(strings.TrimPrefix(domain, "."))
+			name := strings.ToLower(dnsName)
+			return name == domain || strings.HasSuffix(name, "."+domain)
+		})
+		if !allowed {
+			return fmt.Errorf("DNS name %s is not in the allowed domains", dnsName)
+		}
+	}
+
+	return nil
+}
+
+func validateSubject(subject models.Subject, constraints models.SubjectConstraints) error {
+	for _, attr := range constraints.RequiredAttributes {
+		if !subjectHasAttribute(subject, attr) {
+			return fmt.Errorf("subject attribute %s is required", attr)
+		}
+	}
+
+	if constraints.CommonNamePattern == "" {
+		return nil
+	}
+
+	cnPattern, err := regexp.Compile(constraints.CommonNamePattern)
+	if err != nil {
+		return fmt.Errorf("invalid common name pattern: %w", err)
+	}
+
+	if !cnPattern.MatchString(subject.CommonName) {
+		return fmt.Errorf("common name %s does not match %s", subject.CommonName, constraints.CommonNamePattern)
+	}
+
+	return nil
+}
+
+func subjectHasAttribute(subject models.Subject, attr models.SubjectAttribute) bool {
+	switch attr {
+	case models.SubjectAttributeCommonName:
+		return subject.CommonName != ""
+	case models.SubjectAttributeOrganization:
+		return subject.Organization != ""
+	case models.SubjectAttributeOrganizationUnit:
+		return subject.OrganizationUnit != ""
+	case models.SubjectAttributeCountry:
+		return subject.Country != ""
+	case models.SubjectAttributeState:
+		return subject.State != ""
+	case models.SubjectAttributeLocality:
+		return subject.Locality != ""
+	case models.SubjectAttributeSerialNumber:
+		return subject.SerialNumber != ""
+	case models.SubjectAttributeEmailAddress:
+		return subject.EmailAddress != ""
+	case models.SubjectAttributeDomainComponent:
+		return len(subject.DomainComponents) > 0
+	}
+
+	return false
+}
+
+func validateRequestedUsages(csr *x509.CertificateRequest, profile models.CertificateProfile) error {
+	for _, ext := range csr.Extensions {
+		switch {
+		case ext.Id.Equal(oidExtensionKeyUsage) && len(profile.KeyUsages) > 0:
+			var usageBits asn1.BitString
+			if _, err := asn1.Unmarshal(ext.Value, &usageBits); err != nil {
+				return fmt.Errorf("could not decode requested key usage: %w", err)
+			}
+
+			allowed, _ := KeyUsagesToX509(profile.KeyUsages)
+			for i := 0; i < usageBits.BitLength; i++ {
+				if usageBits.At(i) != 0 && allowed&(1<<uint(i)) == 0 {
+					return fmt.Errorf("requested key usage %d is not allowed", 1<<uint(i))
+				}
+			}
+		case ext.Id.Equal(oidExtensionExtendedKeyUsage) && len(profile.ExtendedKeyUsages) > 0:
+			var usageOIDs []asn1.ObjectIdentifier
+			if _, err := asn1.Unmarshal(ext.Value, &usageOIDs); err != nil {
+				return fmt.Errorf("could not decode requested extended key usage: %w", err)
+			}
+
+			for _, oid := range usageOIDs {
+				allowed := slices.ContainsFunc(profile.ExtendedKeyUsages, func(usage models.ExtendedKeyUsage) bool {
+					return extendedKeyUsages[usage].oid.Equal(oid)
+				})
+				if !allowed {
+					return fmt.Errorf("requested extended key usage %s is not allowed", oid)
+				}
+			}
+		}
+	}
+
+	return nil
+}
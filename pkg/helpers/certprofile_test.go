@@ -0,0 +1,152 @@
+package helpers
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"net"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func profileCSR(t *testing.T, template x509.CertificateRequest) *x509.CertificateRequest {
+	key, err := GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	if err != nil {
+		t.Fatalf("could not generate CSR: %s", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("could not parse CSR: %s", err)
+	}
+
+	return csr
+}
+
+func TestValidateCertificateRequestWithProfile(t *testing.T) {
+	extKeyUsageValue, _ := asn1.Marshal([]asn1.ObjectIdentifier{{1, 3, 6, 1, 5, 5, 7, 3, 3}})
+	keyUsageValue, _ := asn1.Marshal(asn1.BitString{Bytes: []byte{0x84}, BitLength: 7}) // digitalSignature + certSign
+
+	profile := models.CertificateProfile{
+		KeyUsages:         []models.KeyUsage{models.KeyUsageDigitalSignature},
+		ExtendedKeyUsages: []models.ExtendedKeyUsage{models.ExtendedKeyUsageClientAuth},
+		SANPolicy: models.SANPolicy{
+			AllowDNSNames: true,
+			DNSDomains:    []string{"devices.lamassu.io"},
+		},
+		SubjectConstraints: models.SubjectConstraints{
+			RequiredAttributes: []models.SubjectAttribute{models.SubjectAttributeOrganization},
+			CommonNamePattern:  "^device-[0-9]+$",
+		},
+	}
+
+	var testcases = []struct {
+		name     string
+		template x509.CertificateRequest
+		subject  models.Subject
+		wantErr  bool
+	}{
+		{
+			name:     "OK",
+			template: x509.CertificateRequest{DNSNames: []string{"dev1.devices.lamassu.io", "devices.lamassu.io"}},
+			subject:  models.Subject{CommonName: "device-1", Organization: "Lamassu"},
+		},
+		{
+			name:     "Err/DNSNameOutsideDomains",
+			template: x509.CertificateRequest{DNSNames: []string{"dev1.lamassu.io"}},
+			subject:  models.Subject{CommonName: "device-1", Organization: "Lamassu"},
+			wantErr:  true,
+		},
+		{
+			name:     "Err/IPAddressNotAllowed",
+			template: x509.CertificateRequest{IPAddresses: []net.IP{net.ParseIP("10.0.0.1")}},
+			subject:  models.Subject{CommonName: "device-1", Organization: "Lamassu"},
+			wantErr:  true,
+		},
+		{
+			name:    "Err/MissingRequiredAttribute",
+			subject: models.Subject{CommonName: "device-1"},
+			wantErr: true,
+		},
+		{
+			name:    "Err/CommonNamePattern",
+			subject: models.Subject{CommonName: "gateway-1", Organization: "Lamassu"},
+			wantErr: true,
+		},
+		{
+			name: "Err/ExtendedKeyUsageNotAllowed",
+			template: x509.CertificateRequest{ExtraExtensions: []pkix.Extension{
+				{Id: oidExtensionExtendedKeyUsage, Value: extKeyUsageValue},
+			}},
+			subject: models.Subject{CommonName: "device-1", Organization: "Lamassu"},
+			wantErr: true,
+		},
+		{
+			name: "Err/KeyUsageNotAllowed",
+			template: x509.CertificateRequest{ExtraExtensions: []pkix.Extension{
+				{Id: oidExtensionKeyUsage, Value: keyUsageValue},
+			}},
+			subject: models.Subject{CommonName: "device-1", Organization: "Lamassu"},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCertificateRequestWithProfile(profileCSR(t, tc.template), tc.subject, profile)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestValidateCertificateProfile(t *testing.T) {
+	negative := models.TimeDuration(-1)
+
+	var testcases = []struct {
+		name    string
+		profile models.CertificateProfile
+		wantErr bool
+	}{
+		{name: "OK", profile: models.CertificateProfile{KeyUsages: []models.KeyUsage{models.KeyUsageKeyAgreement}}},
+		{name: "Err/UnknownKeyUsage", profile: models.CertificateProfile{KeyUsages: []models.KeyUsage{"SIGN_EVERYTHING"}}, wantErr: true},
+		{name: "Err/UnknownExtendedKeyUsage", profile: models.CertificateProfile{ExtendedKeyUsages: []models.ExtendedKeyUsage{"ANY"}}, wantErr: true},
+		{name: "Err/InvalidPattern", profile: models.CertificateProfile{SubjectConstraints: models.SubjectConstraints{CommonNamePattern: "("}}, wantErr: true},
+		{name: "Err/NegativeMaxValidity", profile: models.CertificateProfile{MaxValidity: &negative}, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCertificateProfile(tc.profile)
+			if tc.wantErr && err == nil {
+				t.Errorf("expected error, got nil")
+			}
+			if !tc.wantErr && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestKeyUsagesToX509(t *testing.T) {
+	keyUsage, err := KeyUsagesToX509([]models.KeyUsage{models.KeyUsageDigitalSignature, models.KeyUsageKeyEncipherment})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if keyUsage != x509.KeyUsageDigitalSignature|x509.KeyUsageKeyEncipherment {
+		t.Errorf("unexpected key usage: %d", keyUsage)
+	}
+}
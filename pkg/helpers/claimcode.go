@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"crypto/rand"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// claimCodeAlphabet is the Crockford base32 alphabet, which leaves out the characters easily mistaken when a code
+// is typed by hand.
+const claimCodeAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+const (
+	claimCodeGroups     = 3
+	claimCodeGroupChars = 5
+)
+
+// GenerateClaimCode returns a random claim code formatted as "XXXXX-XXXXX-XXXXX" and its ID.
+func GenerateClaimCode() (code string, id string, err error) {
+	raw := make([]byte, claimCodeGroups*claimCodeGroupChars)
+	_, err = rand.Read(raw)
+	if err != nil {
+		return "", "", err
+	}
+
+	groups := make([]string, 0, claimCodeGroups)
+	for i := 0; i < len(raw); i += claimCodeGroupChars {
+		group := make([]byte, claimCodeGroupChars)
+		for j := range group {
+			group[j] = claimCodeAlphabet[int(raw[i+j])%len(claimCodeAlphabet)]
+		}
+		groups = append(groups, string(group))
+	}
+
+	code = strings.Join(groups, "-")
+	return code, ClaimCodeID(code), nil
+}
+
+// NormalizeClaimCode removes the separators of the claim code and maps the characters a user may type in place of
+// the Crockford base32 ones, so "abcde-fghij-klmno" and "ABCDEFGH1JK1MN0" are the same code.
+func NormalizeClaimCode(code string) string {
+	replacer := strings.NewReplacer("-", "", " ", "", "I", "1", "L", "1", "O", "0")
+	return replacer.Replace(strings.ToUpper(code))
+}
+
+// ClaimCodeID returns the hex encoded SHA-256 of the normalized claim code, which is the only form in which it is
+// stored.
+func ClaimCodeID(code string) string {
+	return BootstrapTokenID(NormalizeClaimCode(code))
+}
+
+// CheckClaimCode returns an error describing why the claim code can not enroll the device.
+func CheckClaimCode(code models.DMSClaimCode, deviceID string, now time.Time) error {
+	if code.ClaimedAt != nil {
+		return fmt.Errorf("code was already claimed by device '%s'", code.ClaimedBy)
+	}
+
+	if !now.Before(code.ExpiresAt) {
+		return fmt.Errorf("code expired at %s", code.ExpiresAt.Format(time.RFC3339))
+	}
+
+	if code.DeviceID != "" && code.DeviceID != deviceID {
+		return fmt.Errorf("code is scoped to device '%s'", code.DeviceID)
+	}
+
+	return nil
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"regexp"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestGenerateClaimCode(t *testing.T) {
+	code, id, err := GenerateClaimCode()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !regexp.MustCompile(`^[0-9A-HJKMNP-TV-Z]{5}-[0-9A-HJKMNP-TV-Z]{5}-[0-9A-HJKMNP-TV-Z]{5}$`).MatchString(code) {
+		t.Errorf("unexpected claim code format '%s'", code)
+	}
+
+	if id != ClaimCodeID(code) {
+		t.Errorf("code ID must be derived from the code")
+	}
+
+	other, _, _ := GenerateClaimCode()
+	if code == other {
+		t.Errorf("codes must be random")
+	}
+}
+
+func TestClaimCodeID(t *testing.T) {
+	if ClaimCodeID("ab1de-0ghjk-mnpqr") != ClaimCodeID("ABIDEOGHJKMNPQR") {
+		t.Errorf("claim code ID must not depend on case, separators or mistyped characters")
+	}
+
+	if ClaimCodeID("ABCDE-FGHJK-MNPQR") == ClaimCodeID("ABCDE-FGHJK-MNPQS") {
+		t.Errorf("different codes must have different IDs")
+	}
+}
+
+func TestCheckClaimCode(t *testing.T) {
+	now := time.Now()
+	claimedAt := now.Add(-time.Minute)
+
+	var testcases = []struct {
+		name     string
+		code     models.DMSClaimCode
+		deviceID string
+		valid    bool
+	}{
+		{name: "OK", code: models.DMSClaimCode{ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1", valid: true},
+		{name: "OK/DeviceScope", code: models.DMSClaimCode{DeviceID: "dev-1", ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1", valid: true},
+		{name: "Err/Claimed", code: models.DMSClaimCode{ExpiresAt: now.Add(time.Hour), ClaimedAt: &claimedAt, ClaimedBy: "dev-0"}, deviceID: "dev-1"},
+		{name: "Err/Expired", code: models.DMSClaimCode{ExpiresAt: now}, deviceID: "dev-1"},
+		{name: "Err/OtherDevice", code: models.DMSClaimCode{DeviceID: "dev-2", ExpiresAt: now.Add(time.Hour)}, deviceID: "dev-1"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := CheckClaimCode(tc.code, tc.deviceID, now)
+			if tc.valid && err != nil {
+				t.Errorf("unexpected error: %s", err)
+			}
+
+			if !tc.valid && err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
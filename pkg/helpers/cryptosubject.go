@@ -2,7 +2,9 @@ package helpers
 
 import (
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"fmt"
+	"slices"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 )
@@ -44,9 +46,120 @@ func SubjectToPkixName(subj models.Subject) pkix.Name {
 		}
 	}
 
+	if subj.SerialNumber != "" {
+		subjPkix.SerialNumber = subj.SerialNumber
+	}
+
+	rdns := subjectRDNs(subj)
+	if len(subj.Order) == 0 {
+		// Standard attributes are encoded by pkix.Name itself. Only the extra ones are needed.
+		for _, attr := range []models.SubjectAttribute{models.SubjectAttributeEmailAddress, models.SubjectAttributeDomainComponent} {
+			subjPkix.ExtraNames = append(subjPkix.ExtraNames, rdns[attr]...)
+		}
+
+		return subjPkix
+	}
+
+	// ExtraNames take precedence over the standard fields sharing their OID, so listing every attribute
+	// there sets the exact RDN order while keeping the standard fields readable.
+	order := append([]models.SubjectAttribute{}, subj.Order...)
+	for _, attr := range defaultSubjectOrder {
+		if !slices.Contains(order, attr) {
+			order = append(order, attr)
+		}
+	}
+
+	for _, attr := range order {
+		subjPkix.ExtraNames = append(subjPkix.ExtraNames, rdns[attr]...)
+	}
+
 	return subjPkix
 }
 
+var (
+	oidEmailAddress    = asn1.ObjectIdentifier{1, 2, 840, 113549, 1, 9, 1}
+	oidDomainComponent = asn1.ObjectIdentifier{0, 9, 2342, 19200300, 100, 1, 25}
+)
+
+var subjectAttributeOIDs = map[models.SubjectAttribute]asn1.ObjectIdentifier{
+	models.SubjectAttributeCountry:          {2, 5, 4, 6},
+	models.SubjectAttributeState:            {2, 5, 4, 8},
+	models.SubjectAttributeLocality:         {2, 5, 4, 7},
+	models.SubjectAttributeOrganization:     {2, 5, 4, 10},
+	models.SubjectAttributeOrganizationUnit: {2, 5, 4, 11},
+	models.SubjectAttributeCommonName:       {2, 5, 4, 3},
+	models.SubjectAttributeSerialNumber:     {2, 5, 4, 5},
+	models.SubjectAttributeEmailAddress:     oidEmailAddress,
+	models.SubjectAttributeDomainComponent:  oidDomainComponent,
+}
+
+// defaultSubjectOrder matches the order in which pkix.Name encodes its fields, followed by the extra attributes.
+var defaultSubjectOrder = []models.SubjectAttribute{
+	models.SubjectAttributeCountry,
+	models.SubjectAttributeState,
+	models.SubjectAttributeLocality,
+	models.SubjectAttributeOrganization,
+	models.SubjectAttributeOrganizationUnit,
+	models.SubjectAttributeCommonName,
+	models.SubjectAttributeSerialNumber,
+	models.SubjectAttributeEmailAddress,
+	models.SubjectAttributeDomainComponent,
+}
+
+// subjectRDNs returns the non empty attributes of the subject. emailAddress and DC are encoded as IA5String (RFC 5280).
+func subjectRDNs(subj models.Subject) map[models.SubjectAttribute][]pkix.AttributeTypeAndValue {
+	rdns := map[models.SubjectAttribute][]pkix.AttributeTypeAndValue{}
+	add := func(attr models.SubjectAttribute, value any) {
+		rdns[attr] = append(rdns[attr], pkix.AttributeTypeAndValue{Type: subjectAttributeOIDs[attr], Value: value})
+	}
+	ia5 := func(value string) asn1.RawValue {
+		return asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagIA5String, Bytes: []byte(value)}
+	}
+
+	for attr, value := range map[models.SubjectAttribute]string{
+		models.SubjectAttributeCountry:          subj.Country,
+		models.SubjectAttributeState:            subj.State,
+		models.SubjectAttributeLocality:         subj.Locality,
+		models.SubjectAttributeOrganization:     subj.Organization,
+		models.SubjectAttributeOrganizationUnit: subj.OrganizationUnit,
+		models.SubjectAttributeCommonName:       subj.CommonName,
+		models.SubjectAttributeSerialNumber:     subj.SerialNumber,
+	} {
+		if value != "" {
+			add(attr, value)
+		}
+	}
+
+	if subj.EmailAddress != "" {
+		add(models.SubjectAttributeEmailAddress, ia5(subj.EmailAddress))
+	}
+
+	for _, dc := range subj.DomainComponents {
+		if dc != "" {
+			add(models.SubjectAttributeDomainComponent, ia5(dc))
+		}
+	}
+
+	return rdns
+}
+
+// ValidateSubjectOrder checks that the order only references known attributes, each of them once.
+func ValidateSubjectOrder(order []models.SubjectAttribute) error {
+	seen := map[models.SubjectAttribute]bool{}
+	for _, attr := range order {
+		if _, ok := subjectAttributeOIDs[attr]; !ok {
+			return fmt.Errorf("unknown subject attribute '%s'", attr)
+		}
+
+		if seen[attr] {
+			return fmt.Errorf("subject attribute '%s' is listed more than once", attr)
+		}
+		seen[attr] = true
+	}
+
+	return nil
+}
+
 func PkixNameToSubject(pkixName pkix.Name) models.Subject {
 	subject := models.Subject{
 		CommonName: pkixName.CommonName,
@@ -68,9 +181,44 @@ func PkixNameToSubject(pkixName pkix.Name) models.Subject {
 		subject.State = pkixName.Province[0]
 	}
 
+	subject.SerialNumber = pkixName.SerialNumber
+
+	// Parsed names carry every attribute in Names, while names built in memory only carry them in ExtraNames.
+	attrs := pkixName.Names
+	if len(attrs) == 0 {
+		attrs = pkixName.ExtraNames
+	}
+
+	for _, attr := range attrs {
+		value, ok := attributeValueToString(attr.Value)
+		if !ok {
+			continue
+		}
+
+		switch {
+		case attr.Type.Equal(oidEmailAddress):
+			if subject.EmailAddress == "" {
+				subject.EmailAddress = value
+			}
+		case attr.Type.Equal(oidDomainComponent):
+			subject.DomainComponents = append(subject.DomainComponents, value)
+		}
+	}
+
 	return subject
 }
 
+func attributeValueToString(value any) (string, bool) {
+	switch v := value.(type) {
+	case string:
+		return v, true
+	case asn1.RawValue:
+		return string(v.Bytes), true
+	default:
+		return "", false
+	}
+}
+
 func PkixNameToString(subject pkix.Name) string {
 	return fmt.Sprintf("C=%v/ST=%v/L=%v/O=%v/OU=%v/CN=%s", subject.Country, subject.Province, subject.Locality, subject.Organization, subject.OrganizationalUnit, subject.CommonName)
 }
@@ -2,6 +2,7 @@ package helpers
 
 import (
 	"crypto/x509/pkix"
+	"encoding/asn1"
 	"reflect"
 	"testing"
 
@@ -202,6 +203,100 @@ func TestPkixNameToSubject(t *testing.T) {
 	}
 }
 
+func TestSubjectToPkixNameExtraAttributesRoundTrip(t *testing.T) {
+	subj := models.Subject{
+		CommonName:       "device-1",
+		Organization:     "Acme Corp",
+		SerialNumber:     "SN-0001",
+		EmailAddress:     "factory@acme.com",
+		DomainComponents: []string{"acme", "com"},
+	}
+
+	parsed := encodeAndParseName(t, SubjectToPkixName(subj))
+
+	result := PkixNameToSubject(parsed)
+	if !reflect.DeepEqual(result, subj) {
+		t.Errorf("Expected %v, but got %v", subj, result)
+	}
+}
+
+func TestSubjectToPkixNameOrder(t *testing.T) {
+	subj := models.Subject{
+		CommonName:       "device-1",
+		Organization:     "Acme Corp",
+		Country:          "ES",
+		SerialNumber:     "SN-0001",
+		DomainComponents: []string{"acme", "com"},
+		Order: []models.SubjectAttribute{
+			models.SubjectAttributeDomainComponent,
+			models.SubjectAttributeCommonName,
+			models.SubjectAttributeSerialNumber,
+		},
+	}
+
+	name := SubjectToPkixName(subj)
+	if name.CommonName != "device-1" {
+		t.Errorf("Expected the standard fields to be kept, but got CommonName %q", name.CommonName)
+	}
+
+	parsed := encodeAndParseName(t, name)
+
+	expected := []asn1.ObjectIdentifier{
+		oidDomainComponent,
+		oidDomainComponent,
+		subjectAttributeOIDs[models.SubjectAttributeCommonName],
+		subjectAttributeOIDs[models.SubjectAttributeSerialNumber],
+		subjectAttributeOIDs[models.SubjectAttributeCountry],
+		subjectAttributeOIDs[models.SubjectAttributeOrganization],
+	}
+
+	if len(parsed.Names) != len(expected) {
+		t.Fatalf("Expected %d RDNs, but got %d: %v", len(expected), len(parsed.Names), parsed.Names)
+	}
+
+	for i, oid := range expected {
+		if !parsed.Names[i].Type.Equal(oid) {
+			t.Errorf("Expected RDN %d to be %s, but got %s", i, oid, parsed.Names[i].Type)
+		}
+	}
+}
+
+func TestValidateSubjectOrder(t *testing.T) {
+	err := ValidateSubjectOrder([]models.SubjectAttribute{models.SubjectAttributeCommonName, models.SubjectAttributeEmailAddress})
+	if err != nil {
+		t.Errorf("Expected no error, but got %s", err)
+	}
+
+	err = ValidateSubjectOrder([]models.SubjectAttribute{"STREET"})
+	if err == nil {
+		t.Errorf("Expected an error for an unknown attribute")
+	}
+
+	err = ValidateSubjectOrder([]models.SubjectAttribute{models.SubjectAttributeCommonName, models.SubjectAttributeCommonName})
+	if err == nil {
+		t.Errorf("Expected an error for a repeated attribute")
+	}
+}
+
+func encodeAndParseName(t *testing.T, name pkix.Name) pkix.Name {
+	t.Helper()
+
+	der, err := asn1.Marshal(name.ToRDNSequence())
+	if err != nil {
+		t.Fatalf("could not encode name: %s", err)
+	}
+
+	var rdns pkix.RDNSequence
+	_, err = asn1.Unmarshal(der, &rdns)
+	if err != nil {
+		t.Fatalf("could not decode name: %s", err)
+	}
+
+	var parsed pkix.Name
+	parsed.FillFromRDNSequence(&rdns)
+	return parsed
+}
+
 func TestPkixNameToString(t *testing.T) {
 	subject1 := pkix.Name{}
 	expected1 := "C=[]/ST=[]/L=[]/O=[]/OU=[]/CN="
@@ -5,10 +5,12 @@ import (
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/sha512"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
+	"encoding/hex"
 	"encoding/pem"
 	"fmt"
 	"math/big"
@@ -276,3 +278,9 @@ func CalculateECDSAKeySizes(keyMin int, KeyMax int) []int {
 	}
 	return keySizes
 }
+
+// PublicKeyFingerprint returns the hex encoded SHA-256 of a DER encoded SubjectPublicKeyInfo.
+func PublicKeyFingerprint(rawSubjectPublicKeyInfo []byte) string {
+	fingerprint := sha256.Sum256(rawSubjectPublicKeyInfo)
+	return hex.EncodeToString(fingerprint[:])
+}
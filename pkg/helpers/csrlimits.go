@@ -0,0 +1,72 @@
+package helpers
+
+import (
+	"crypto/x509"
+	"fmt"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+)
+
+const (
+	defaultCSRMaxSize       = 16 * 1024
+	defaultCSRMaxSANs       = 100
+	defaultCSRMaxExtensions = 32
+	defaultCSRMaxBatchSize  = 1000
+
+	// csrBodyOverhead is the room left for the rest of the request body (JSON fields, signing profiles...).
+	csrBodyOverhead = 16 * 1024
+)
+
+// CSRLimitsWithDefaults returns the limits with the unset values replaced by the defaults.
+func CSRLimitsWithDefaults(limits config.CSRLimits) config.CSRLimits {
+	if limits.MaxSize <= 0 {
+		limits.MaxSize = defaultCSRMaxSize
+	}
+
+	if limits.MaxSANs <= 0 {
+		limits.MaxSANs = defaultCSRMaxSANs
+	}
+
+	if limits.MaxExtensions <= 0 {
+		limits.MaxExtensions = defaultCSRMaxExtensions
+	}
+
+	if limits.MaxBatchSize <= 0 {
+		limits.MaxBatchSize = defaultCSRMaxBatchSize
+	}
+
+	return limits
+}
+
+// CSRRequestBodyLimit returns the maximum size of the request bodies transporting a certificate request. The CSR is
+// base64 or PEM encoded in the body, so twice the DER size is allowed.
+func CSRRequestBodyLimit(limits config.CSRLimits) int64 {
+	limits = CSRLimitsWithDefaults(limits)
+	return int64(limits.MaxSize)*2 + csrBodyOverhead
+}
+
+// CSRBatchRequestBodyLimit returns the maximum size of the request bodies transporting a batch of certificate requests.
+func CSRBatchRequestBodyLimit(limits config.CSRLimits) int64 {
+	limits = CSRLimitsWithDefaults(limits)
+	return CSRRequestBodyLimit(limits) * int64(limits.MaxBatchSize)
+}
+
+// ValidateCertificateRequestLimits checks the size, the number of SANs and the number of extensions of the request.
+func ValidateCertificateRequestLimits(csr *x509.CertificateRequest, limits config.CSRLimits) error {
+	limits = CSRLimitsWithDefaults(limits)
+
+	if len(csr.Raw) > limits.MaxSize {
+		return fmt.Errorf("certificate request size %d exceeds the limit of %d bytes", len(csr.Raw), limits.MaxSize)
+	}
+
+	sans := len(csr.DNSNames) + len(csr.EmailAddresses) + len(csr.IPAddresses) + len(csr.URIs)
+	if sans > limits.MaxSANs {
+		return fmt.Errorf("certificate request has %d subject alternative names, the limit is %d", sans, limits.MaxSANs)
+	}
+
+	if len(csr.Extensions) > limits.MaxExtensions {
+		return fmt.Errorf("certificate request has %d extensions, the limit is %d", len(csr.Extensions), limits.MaxExtensions)
+	}
+
+	return nil
+}
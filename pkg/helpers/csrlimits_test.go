@@ -0,0 +1,111 @@
+package helpers
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+)
+
+func newLimitsTestCSR(t *testing.T, dnsNames int, extensions int) *x509.CertificateRequest {
+	key, _ := GenerateECDSAKey(elliptic.P256())
+
+	template := x509.CertificateRequest{
+		Subject: pkix.Name{CommonName: "device"},
+	}
+	for i := 0; i < dnsNames; i++ {
+		template.DNSNames = append(template.DNSNames, fmt.Sprintf("device-%d.lamassu.io", i))
+	}
+	for i := 0; i < extensions; i++ {
+		template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+			Id:    asn1.ObjectIdentifier{1, 3, 6, 1, 4, 1, 99999, i + 1},
+			Value: []byte{0x05, 0x00},
+		})
+	}
+
+	der, err := x509.CreateCertificateRequest(rand.Reader, &template, key)
+	if err != nil {
+		t.Fatalf("could not create certificate request: %s", err)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		t.Fatalf("could not parse certificate request: %s", err)
+	}
+
+	return csr
+}
+
+func TestValidateCertificateRequestLimits(t *testing.T) {
+	var testcases = []struct {
+		name      string
+		csr       *x509.CertificateRequest
+		limits    config.CSRLimits
+		expectErr bool
+	}{
+		{
+			name:      "OK/Defaults",
+			csr:       newLimitsTestCSR(t, 10, 5),
+			limits:    config.CSRLimits{},
+			expectErr: false,
+		},
+		{
+			name:      "OK/AtLimits",
+			csr:       newLimitsTestCSR(t, 3, 1),
+			limits:    config.CSRLimits{MaxSANs: 3, MaxExtensions: 2},
+			expectErr: false,
+		},
+		{
+			name:      "Err/Size",
+			csr:       newLimitsTestCSR(t, 0, 0),
+			limits:    config.CSRLimits{MaxSize: 64},
+			expectErr: true,
+		},
+		{
+			name:      "Err/SANs",
+			csr:       newLimitsTestCSR(t, 4, 0),
+			limits:    config.CSRLimits{MaxSANs: 3},
+			expectErr: true,
+		},
+		{
+			name:      "Err/DefaultSANs",
+			csr:       newLimitsTestCSR(t, defaultCSRMaxSANs+1, 0),
+			limits:    config.CSRLimits{MaxSize: 1024 * 1024},
+			expectErr: true,
+		},
+		{
+			name:      "Err/Extensions",
+			csr:       newLimitsTestCSR(t, 0, 3),
+			limits:    config.CSRLimits{MaxExtensions: 2},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateCertificateRequestLimits(tc.csr, tc.limits)
+			if tc.expectErr && err == nil {
+				t.Fatalf("expected error, got nil")
+			}
+
+			if !tc.expectErr && err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+		})
+	}
+}
+
+func TestCSRRequestBodyLimit(t *testing.T) {
+	if limit := CSRRequestBodyLimit(config.CSRLimits{}); limit != defaultCSRMaxSize*2+csrBodyOverhead {
+		t.Errorf("unexpected default body limit %d", limit)
+	}
+
+	if limit := CSRRequestBodyLimit(config.CSRLimits{MaxSize: 1000}); limit != 2000+csrBodyOverhead {
+		t.Errorf("unexpected body limit %d", limit)
+	}
+}
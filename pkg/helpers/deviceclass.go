@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ValidateDeviceClassProfiles checks that the validity of the DMS signing profile is positive and that the device
+// classes have a unique name, at least one tag and a positive validity.
+func ValidateDeviceClassProfiles(settings models.EnrollmentSettings) error {
+	if settings.SigningProfile.Validity != nil && *settings.SigningProfile.Validity <= 0 {
+		return fmt.Errorf("signing profile validity must be positive")
+	}
+
+	names := map[string]bool{}
+	for _, class := range settings.DeviceClassProfiles {
+		if class.Name == "" {
+			return fmt.Errorf("device class name can not be empty")
+		}
+
+		if names[class.Name] {
+			return fmt.Errorf("device class '%s' is repeated", class.Name)
+		}
+		names[class.Name] = true
+
+		if len(class.Tags) == 0 {
+			return fmt.Errorf("device class '%s' has no tags", class.Name)
+		}
+
+		if class.Validity != nil && *class.Validity <= 0 {
+			return fmt.Errorf("device class '%s' validity must be positive", class.Name)
+		}
+	}
+
+	return nil
+}
+
+// ResolveDeviceClassProfile returns the first device class the device belongs to, or nil if it belongs to none.
+func ResolveDeviceClassProfile(classes []models.DeviceClassProfile, device models.Device) *models.DeviceClassProfile {
+	for _, class := range classes {
+		for _, tag := range class.Tags {
+			if slices.Contains(device.Tags, tag) {
+				return &class
+			}
+		}
+	}
+
+	return nil
+}
+
+// GetDeviceIssuanceProfile returns the signing profile and the certificate profile ID used to issue the certificates
+// of the device, applying the overrides of its device class (if any) to the DMS enrollment settings.
+func GetDeviceIssuanceProfile(settings models.EnrollmentSettings, device models.Device) (models.SigningProfile, string, *models.DeviceClassProfile) {
+	profile := settings.SigningProfile
+	certificateProfileID := settings.CertificateProfileID
+
+	class := ResolveDeviceClassProfile(settings.DeviceClassProfiles, device)
+	if class == nil {
+		return profile, certificateProfileID, nil
+	}
+
+	if class.Validity != nil {
+		validity := *class.Validity
+		profile.Validity = &validity
+	}
+
+	if class.CertificateProfileID != "" {
+		certificateProfileID = class.CertificateProfileID
+	}
+
+	return profile, certificateProfileID, class
+}
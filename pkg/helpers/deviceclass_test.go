@@ -0,0 +1,123 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestValidateDeviceClassProfiles(t *testing.T) {
+	validity := models.TimeDuration(time.Hour)
+	negative := models.TimeDuration(-time.Hour)
+
+	testcases := []struct {
+		name      string
+		settings  models.EnrollmentSettings
+		expectErr bool
+	}{
+		{
+			name: "OK",
+			settings: models.EnrollmentSettings{
+				SigningProfile: models.SigningProfile{Validity: &validity},
+				DeviceClassProfiles: []models.DeviceClassProfile{
+					{Name: "gateway", Tags: []string{"gateway"}, Validity: &validity},
+					{Name: "sensor", Tags: []string{"sensor"}, CertificateProfileID: "sensor-profile"},
+				},
+			},
+		},
+		{
+			name:      "Err/NegativeSigningProfileValidity",
+			settings:  models.EnrollmentSettings{SigningProfile: models.SigningProfile{Validity: &negative}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/EmptyName",
+			settings:  models.EnrollmentSettings{DeviceClassProfiles: []models.DeviceClassProfile{{Tags: []string{"gateway"}}}},
+			expectErr: true,
+		},
+		{
+			name: "Err/RepeatedName",
+			settings: models.EnrollmentSettings{DeviceClassProfiles: []models.DeviceClassProfile{
+				{Name: "gateway", Tags: []string{"gateway"}},
+				{Name: "gateway", Tags: []string{"router"}},
+			}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/NoTags",
+			settings:  models.EnrollmentSettings{DeviceClassProfiles: []models.DeviceClassProfile{{Name: "gateway"}}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/NegativeValidity",
+			settings:  models.EnrollmentSettings{DeviceClassProfiles: []models.DeviceClassProfile{{Name: "gateway", Tags: []string{"gateway"}, Validity: &negative}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateDeviceClassProfiles(tc.settings)
+			if tc.expectErr != (err != nil) {
+				t.Errorf("unexpected error result: %v", err)
+			}
+		})
+	}
+}
+
+func TestGetDeviceIssuanceProfile(t *testing.T) {
+	dmsValidity := models.TimeDuration(24 * time.Hour)
+	gatewayValidity := models.TimeDuration(365 * 24 * time.Hour)
+	sensorValidity := models.TimeDuration(5 * 365 * 24 * time.Hour)
+
+	settings := models.EnrollmentSettings{
+		SigningProfile:       models.SigningProfile{Validity: &dmsValidity, OCSPServers: []string{"http://ocsp"}},
+		CertificateProfileID: "dms-profile",
+		DeviceClassProfiles: []models.DeviceClassProfile{
+			{Name: "gateway", Tags: []string{"gateway", "router"}, Validity: &gatewayValidity},
+			{Name: "sensor", Tags: []string{"sensor"}, Validity: &sensorValidity, CertificateProfileID: "sensor-profile"},
+		},
+	}
+
+	testcases := []struct {
+		name                  string
+		tags                  []string
+		expectedClass         string
+		expectedValidity      models.TimeDuration
+		expectedCertProfileID string
+	}{
+		{name: "NoClass", tags: []string{"other"}, expectedValidity: dmsValidity, expectedCertProfileID: "dms-profile"},
+		{name: "Gateway", tags: []string{"router"}, expectedClass: "gateway", expectedValidity: gatewayValidity, expectedCertProfileID: "dms-profile"},
+		{name: "Sensor", tags: []string{"sensor"}, expectedClass: "sensor", expectedValidity: sensorValidity, expectedCertProfileID: "sensor-profile"},
+		{name: "FirstMatchingClass", tags: []string{"sensor", "gateway"}, expectedClass: "gateway", expectedValidity: gatewayValidity, expectedCertProfileID: "dms-profile"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			profile, certProfileID, class := GetDeviceIssuanceProfile(settings, models.Device{Tags: tc.tags})
+			if tc.expectedClass == "" && class != nil {
+				t.Errorf("expected no device class, got %s", class.Name)
+			}
+			if tc.expectedClass != "" && (class == nil || class.Name != tc.expectedClass) {
+				t.Errorf("expected device class %s, got %v", tc.expectedClass, class)
+			}
+
+			if profile.Validity == nil || *profile.Validity != tc.expectedValidity {
+				t.Errorf("expected validity %s, got %v", tc.expectedValidity, profile.Validity)
+			}
+
+			if certProfileID != tc.expectedCertProfileID {
+				t.Errorf("expected certificate profile %s, got %s", tc.expectedCertProfileID, certProfileID)
+			}
+
+			if len(profile.OCSPServers) != 1 {
+				t.Errorf("expected the DMS signing profile to be kept")
+			}
+		})
+	}
+
+	if *settings.SigningProfile.Validity != dmsValidity {
+		t.Errorf("DMS signing profile must not be modified")
+	}
+}
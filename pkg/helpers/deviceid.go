@@ -0,0 +1,95 @@
+package helpers
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// NormalizeDeviceID canonicalizes the device ID according to the DMS device ID rules. Normalizing an already
+// normalized ID returns the same ID.
+func NormalizeDeviceID(id string, rules models.DeviceIDRules) (string, error) {
+	id = strings.TrimSpace(id)
+
+	switch rules.Case {
+	case models.DeviceIDCaseLower:
+		id = strings.ToLower(id)
+	case models.DeviceIDCaseUpper:
+		id = strings.ToUpper(id)
+	}
+
+	if rules.ForbiddenCharacters != "" && strings.ContainsAny(id, rules.ForbiddenCharacters) {
+		if rules.ForbiddenReplacement == "" {
+			return "", fmt.Errorf("device ID '%s' contains forbidden characters '%s'", id, rules.ForbiddenCharacters)
+		}
+
+		id = strings.NewReplacer(forbiddenReplacerPairs(rules)...).Replace(id)
+	}
+
+	if id == "" {
+		return "", fmt.Errorf("device ID is empty")
+	}
+
+	if rules.MaxLength > 0 && utf8.RuneCountInString(id) > rules.MaxLength {
+		return "", fmt.Errorf("device ID '%s' exceeds the max length of %d characters", id, rules.MaxLength)
+	}
+
+	if rules.Pattern != "" {
+		pattern, err := compileDeviceIDPattern(rules.Pattern)
+		if err != nil {
+			return "", err
+		}
+
+		if !pattern.MatchString(id) {
+			return "", fmt.Errorf("device ID '%s' does not match pattern '%s'", id, rules.Pattern)
+		}
+	}
+
+	return id, nil
+}
+
+// ValidateDeviceIDRules checks that the pattern can be compiled and that the replacement does not contain forbidden characters.
+func ValidateDeviceIDRules(rules models.DeviceIDRules) error {
+	switch rules.Case {
+	case models.DeviceIDCaseUnchanged, models.DeviceIDCaseLower, models.DeviceIDCaseUpper:
+	default:
+		return fmt.Errorf("unknown device ID case '%s'", rules.Case)
+	}
+
+	if rules.MaxLength < 0 {
+		return fmt.Errorf("device ID max length must be positive")
+	}
+
+	if rules.ForbiddenCharacters != "" && strings.ContainsAny(rules.ForbiddenReplacement, rules.ForbiddenCharacters) {
+		return fmt.Errorf("forbidden characters replacement '%s' contains forbidden characters", rules.ForbiddenReplacement)
+	}
+
+	if rules.Pattern != "" {
+		_, err := compileDeviceIDPattern(rules.Pattern)
+		return err
+	}
+
+	return nil
+}
+
+func forbiddenReplacerPairs(rules models.DeviceIDRules) []string {
+	pairs := []string{}
+	for _, r := range rules.ForbiddenCharacters {
+		pairs = append(pairs, string(r), rules.ForbiddenReplacement)
+	}
+
+	return pairs
+}
+
+// compileDeviceIDPattern anchors the pattern so the whole ID must match it
+func compileDeviceIDPattern(pattern string) (*regexp.Regexp, error) {
+	re, err := regexp.Compile("^(?:" + pattern + ")$")
+	if err != nil {
+		return nil, fmt.Errorf("invalid device ID pattern '%s': %w", pattern, err)
+	}
+
+	return re, nil
+}
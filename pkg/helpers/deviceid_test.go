@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestNormalizeDeviceID(t *testing.T) {
+	var testcases = []struct {
+		name     string
+		id       string
+		rules    models.DeviceIDRules
+		expected string
+		fails    bool
+	}{
+		{name: "NoRules", id: "Device 1", rules: models.DeviceIDRules{}, expected: "Device 1"},
+		{name: "TrimAndLowerCase", id: "  ABC-123 ", rules: models.DeviceIDRules{Case: models.DeviceIDCaseLower}, expected: "abc-123"},
+		{name: "UpperCase", id: "abc-123", rules: models.DeviceIDRules{Case: models.DeviceIDCaseUpper}, expected: "ABC-123"},
+		{name: "ReplaceForbidden", id: "line 3/dev:1", rules: models.DeviceIDRules{ForbiddenCharacters: " /:", ForbiddenReplacement: "_"}, expected: "line_3_dev_1"},
+		{name: "RejectForbidden", id: "line 3/dev:1", rules: models.DeviceIDRules{ForbiddenCharacters: "/"}, fails: true},
+		{name: "MaxLength", id: "abcdef", rules: models.DeviceIDRules{MaxLength: 5}, fails: true},
+		{name: "PatternMatches", id: "SN-0001", rules: models.DeviceIDRules{Pattern: "SN-[0-9]{4}"}, expected: "SN-0001"},
+		{name: "PatternIsAnchored", id: "x-SN-0001", rules: models.DeviceIDRules{Pattern: "SN-[0-9]{4}"}, fails: true},
+		{name: "PatternAfterNormalization", id: "sn-0001", rules: models.DeviceIDRules{Case: models.DeviceIDCaseUpper, Pattern: "SN-[0-9]{4}"}, expected: "SN-0001"},
+		{name: "Empty", id: "  ", rules: models.DeviceIDRules{}, fails: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			id, err := NormalizeDeviceID(tc.id, tc.rules)
+			if tc.fails {
+				if err == nil {
+					t.Fatalf("expected error, got ID '%s'", id)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if id != tc.expected {
+				t.Errorf("expected '%s', got '%s'", tc.expected, id)
+			}
+
+			again, err := NormalizeDeviceID(id, tc.rules)
+			if err != nil || again != id {
+				t.Errorf("normalization is not idempotent: '%s' became '%s' (%v)", id, again, err)
+			}
+		})
+	}
+}
+
+func TestValidateDeviceIDRules(t *testing.T) {
+	if err := ValidateDeviceIDRules(models.DeviceIDRules{Pattern: "[a-z"}); err == nil {
+		t.Errorf("expected error for invalid pattern")
+	}
+
+	if err := ValidateDeviceIDRules(models.DeviceIDRules{ForbiddenCharacters: "_ ", ForbiddenReplacement: "_"}); err == nil {
+		t.Errorf("expected error for forbidden replacement")
+	}
+
+	if err := ValidateDeviceIDRules(models.DeviceIDRules{Case: "title"}); err == nil {
+		t.Errorf("expected error for unknown case")
+	}
+
+	if err := ValidateDeviceIDRules(models.DeviceIDRules{Case: models.DeviceIDCaseLower, ForbiddenCharacters: " ", ForbiddenReplacement: "-", MaxLength: 64, Pattern: "[a-z0-9-]+"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
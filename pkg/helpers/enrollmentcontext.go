@@ -0,0 +1,166 @@
+package helpers
+
+import (
+	"fmt"
+	"net"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+const enrollmentTimeWindowLayout = "15:04"
+
+var weekdays = map[string]time.Weekday{}
+
+func init() {
+	for day := time.Sunday; day <= time.Saturday; day++ {
+		weekdays[strings.ToLower(day.String())] = day
+	}
+}
+
+func validEnrollmentContextAction(action models.EnrollmentContextAction) bool {
+	switch action {
+	case models.EnrollmentContextAllow, models.EnrollmentContextDeny, models.EnrollmentContextFlag:
+		return true
+	}
+
+	return false
+}
+
+// ValidateEnrollmentContextPolicy checks the actions, IP ranges and time windows of the rules of the policy.
+func ValidateEnrollmentContextPolicy(policy models.EnrollmentContextPolicy) error {
+	if policy.DefaultAction != "" && !validEnrollmentContextAction(policy.DefaultAction) {
+		return fmt.Errorf("invalid default action '%s'", policy.DefaultAction)
+	}
+
+	for i, rule := range policy.Rules {
+		if !validEnrollmentContextAction(rule.Action) {
+			return fmt.Errorf("rule %d has an invalid action '%s'", i, rule.Action)
+		}
+
+		for _, cidr := range rule.SourceCIDRs {
+			if _, _, err := net.ParseCIDR(cidr); err != nil {
+				return fmt.Errorf("rule %d has an invalid source CIDR '%s': %w", i, cidr, err)
+			}
+		}
+
+		for _, window := range rule.TimeWindows {
+			if _, err := time.Parse(enrollmentTimeWindowLayout, window.Start); err != nil {
+				return fmt.Errorf("rule %d has an invalid time window start '%s'", i, window.Start)
+			}
+
+			if _, err := time.Parse(enrollmentTimeWindowLayout, window.End); err != nil {
+				return fmt.Errorf("rule %d has an invalid time window end '%s'", i, window.End)
+			}
+
+			if _, err := time.LoadLocation(window.Location); err != nil {
+				return fmt.Errorf("rule %d has an invalid time window location '%s': %w", i, window.Location, err)
+			}
+
+			for _, day := range window.Days {
+				if _, ok := weekdays[strings.ToLower(day)]; !ok {
+					return fmt.Errorf("rule %d has an invalid time window day '%s'", i, day)
+				}
+			}
+		}
+	}
+
+	return nil
+}
+
+// EvaluateEnrollmentContextPolicy returns the action of the first rule matching the enrollment context, or the default
+// action of the policy (with a nil rule) if none matches.
+func EvaluateEnrollmentContextPolicy(policy models.EnrollmentContextPolicy, enrollCtx models.EnrollmentContext) (models.EnrollmentContextAction, *models.EnrollmentContextRule) {
+	for _, rule := range policy.Rules {
+		if matchEnrollmentContextRule(rule, enrollCtx) {
+			return rule.Action, &rule
+		}
+	}
+
+	if policy.DefaultAction == "" {
+		return models.EnrollmentContextAllow, nil
+	}
+
+	return policy.DefaultAction, nil
+}
+
+func matchEnrollmentContextRule(rule models.EnrollmentContextRule, enrollCtx models.EnrollmentContext) bool {
+	if len(rule.SourceCIDRs) > 0 {
+		ip := net.ParseIP(enrollCtx.SourceIP)
+		if ip == nil {
+			return false
+		}
+
+		inRange := slices.ContainsFunc(rule.SourceCIDRs, func(cidr string) bool {
+			_, ipNet, err := net.ParseCIDR(cidr)
+			return err == nil && ipNet.Contains(ip)
+		})
+		if !inRange {
+			return false
+		}
+	}
+
+	if len(rule.TimeWindows) > 0 {
+		inWindow := slices.ContainsFunc(rule.TimeWindows, func(window models.EnrollmentTimeWindow) bool {
+			return inEnrollmentTimeWindow(window, enrollCtx.Time)
+		})
+		if !inWindow {
+			return false
+		}
+	}
+
+	if len(rule.GatewayIDs) > 0 && !slices.Contains(rule.GatewayIDs, enrollCtx.GatewayID) {
+		return false
+	}
+
+	return true
+}
+
+func inEnrollmentTimeWindow(window models.EnrollmentTimeWindow, t time.Time) bool {
+	loc, err := time.LoadLocation(window.Location)
+	if err != nil {
+		return false
+	}
+
+	start, err := time.Parse(enrollmentTimeWindowLayout, window.Start)
+	if err != nil {
+		return false
+	}
+
+	end, err := time.Parse(enrollmentTimeWindowLayout, window.End)
+	if err != nil {
+		return false
+	}
+
+	t = t.In(loc)
+	minute := t.Hour()*60 + t.Minute()
+	startMinute := start.Hour()*60 + start.Minute()
+	endMinute := end.Hour()*60 + end.Minute()
+
+	startDay := t.Weekday()
+	if startMinute <= endMinute {
+		if minute < startMinute || minute >= endMinute {
+			return false
+		}
+	} else {
+		// the window spans midnight. Times before the end belong to the window started the previous day
+		if minute < startMinute && minute >= endMinute {
+			return false
+		}
+
+		if minute < endMinute {
+			startDay = (startDay + 6) % 7
+		}
+	}
+
+	if len(window.Days) == 0 {
+		return true
+	}
+
+	return slices.ContainsFunc(window.Days, func(day string) bool {
+		weekday, ok := weekdays[strings.ToLower(day)]
+		return ok && weekday == startDay
+	})
+}
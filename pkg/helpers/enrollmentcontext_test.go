@@ -0,0 +1,152 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestValidateEnrollmentContextPolicy(t *testing.T) {
+	testcases := []struct {
+		name      string
+		policy    models.EnrollmentContextPolicy
+		expectErr bool
+	}{
+		{
+			name: "OK",
+			policy: models.EnrollmentContextPolicy{
+				DefaultAction: models.EnrollmentContextDeny,
+				Rules: []models.EnrollmentContextRule{
+					{
+						Name:        "plant-1",
+						Action:      models.EnrollmentContextAllow,
+						SourceCIDRs: []string{"10.1.0.0/16", "2001:db8::/32"},
+						TimeWindows: []models.EnrollmentTimeWindow{{Days: []string{"Monday", "friday"}, Start: "22:00", End: "06:00", Location: "Europe/Madrid"}},
+						GatewayIDs:  []string{"gw-1"},
+					},
+				},
+			},
+		},
+		{
+			name:      "Err/DefaultAction",
+			policy:    models.EnrollmentContextPolicy{DefaultAction: "BLOCK"},
+			expectErr: true,
+		},
+		{
+			name:      "Err/RuleAction",
+			policy:    models.EnrollmentContextPolicy{Rules: []models.EnrollmentContextRule{{Name: "rule"}}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/CIDR",
+			policy:    models.EnrollmentContextPolicy{Rules: []models.EnrollmentContextRule{{Action: models.EnrollmentContextDeny, SourceCIDRs: []string{"10.1.0.0"}}}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/WindowStart",
+			policy:    models.EnrollmentContextPolicy{Rules: []models.EnrollmentContextRule{{Action: models.EnrollmentContextDeny, TimeWindows: []models.EnrollmentTimeWindow{{Start: "25:00", End: "06:00"}}}}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/WindowLocation",
+			policy:    models.EnrollmentContextPolicy{Rules: []models.EnrollmentContextRule{{Action: models.EnrollmentContextDeny, TimeWindows: []models.EnrollmentTimeWindow{{Start: "08:00", End: "16:00", Location: "Mars/Olympus"}}}}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/WindowDay",
+			policy:    models.EnrollmentContextPolicy{Rules: []models.EnrollmentContextRule{{Action: models.EnrollmentContextDeny, TimeWindows: []models.EnrollmentTimeWindow{{Start: "08:00", End: "16:00", Days: []string{"Mon"}}}}}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateEnrollmentContextPolicy(tc.policy)
+			if tc.expectErr != (err != nil) {
+				t.Errorf("unexpected error result: %v", err)
+			}
+		})
+	}
+}
+
+func TestEvaluateEnrollmentContextPolicy(t *testing.T) {
+	policy := models.EnrollmentContextPolicy{
+		DefaultAction: models.EnrollmentContextDeny,
+		Rules: []models.EnrollmentContextRule{
+			{
+				Name:        "plant-1-day-shift",
+				Action:      models.EnrollmentContextAllow,
+				SourceCIDRs: []string{"10.1.0.0/16"},
+				TimeWindows: []models.EnrollmentTimeWindow{{Days: []string{"Monday", "Tuesday", "Wednesday", "Thursday", "Friday"}, Start: "08:00", End: "16:00"}},
+			},
+			{
+				Name:        "plant-1-night-shift",
+				Action:      models.EnrollmentContextFlag,
+				SourceCIDRs: []string{"10.1.0.0/16"},
+				TimeWindows: []models.EnrollmentTimeWindow{{Days: []string{"Friday"}, Start: "22:00", End: "06:00"}},
+			},
+			{
+				Name:       "gateway",
+				Action:     models.EnrollmentContextAllow,
+				GatewayIDs: []string{"gw-1"},
+			},
+		},
+	}
+
+	// 2024-01-05 is a Friday
+	friday := func(hour, minute int) time.Time {
+		return time.Date(2024, time.January, 5, hour, minute, 0, 0, time.UTC)
+	}
+
+	testcases := []struct {
+		name           string
+		enrollCtx      models.EnrollmentContext
+		expectedAction models.EnrollmentContextAction
+		expectedRule   string
+	}{
+		{name: "DayShift", enrollCtx: models.EnrollmentContext{SourceIP: "10.1.2.3", Time: friday(10, 0)}, expectedAction: models.EnrollmentContextAllow, expectedRule: "plant-1-day-shift"},
+		{name: "DayShiftEnd", enrollCtx: models.EnrollmentContext{SourceIP: "10.1.2.3", Time: friday(16, 0)}, expectedAction: models.EnrollmentContextDeny},
+		{name: "OtherPlant", enrollCtx: models.EnrollmentContext{SourceIP: "10.2.2.3", Time: friday(10, 0)}, expectedAction: models.EnrollmentContextDeny},
+		{name: "InvalidIP", enrollCtx: models.EnrollmentContext{SourceIP: "unknown", Time: friday(10, 0)}, expectedAction: models.EnrollmentContextDeny},
+		{name: "NightShiftStart", enrollCtx: models.EnrollmentContext{SourceIP: "10.1.2.3", Time: friday(23, 0)}, expectedAction: models.EnrollmentContextFlag, expectedRule: "plant-1-night-shift"},
+		{name: "NightShiftAfterMidnight", enrollCtx: models.EnrollmentContext{SourceIP: "10.1.2.3", Time: friday(23, 0).Add(4 * time.Hour)}, expectedAction: models.EnrollmentContextFlag, expectedRule: "plant-1-night-shift"},
+		{name: "NightShiftStartedThursday", enrollCtx: models.EnrollmentContext{SourceIP: "10.1.2.3", Time: friday(2, 0)}, expectedAction: models.EnrollmentContextDeny},
+		{name: "Gateway", enrollCtx: models.EnrollmentContext{SourceIP: "192.168.1.1", GatewayID: "gw-1", Time: friday(2, 0)}, expectedAction: models.EnrollmentContextAllow, expectedRule: "gateway"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			action, rule := EvaluateEnrollmentContextPolicy(policy, tc.enrollCtx)
+			if action != tc.expectedAction {
+				t.Errorf("expected action %s, got %s", tc.expectedAction, action)
+			}
+
+			ruleName := ""
+			if rule != nil {
+				ruleName = rule.Name
+			}
+			if ruleName != tc.expectedRule {
+				t.Errorf("expected rule '%s', got '%s'", tc.expectedRule, ruleName)
+			}
+		})
+	}
+
+	action, rule := EvaluateEnrollmentContextPolicy(models.EnrollmentContextPolicy{}, models.EnrollmentContext{})
+	if action != models.EnrollmentContextAllow || rule != nil {
+		t.Errorf("empty policies must allow the enrollment")
+	}
+}
+
+func TestEnrollmentTimeWindowLocation(t *testing.T) {
+	window := models.EnrollmentTimeWindow{Start: "08:00", End: "16:00", Location: "America/New_York"}
+
+	// 14:00 UTC is 09:00 in New York (EST)
+	if !inEnrollmentTimeWindow(window, time.Date(2024, time.January, 5, 14, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected time to be within the window")
+	}
+
+	if inEnrollmentTimeWindow(window, time.Date(2024, time.January, 5, 10, 0, 0, 0, time.UTC)) {
+		t.Errorf("expected time to be out of the window")
+	}
+}
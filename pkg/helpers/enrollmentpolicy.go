@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/x509"
+	"fmt"
+	"regexp"
+	"slices"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ValidateDMSEnrollmentPolicy checks that the subject regex compiles and the minimum key size is not negative.
+func ValidateDMSEnrollmentPolicy(policy models.DMSEnrollmentPolicy) error {
+	if policy.SubjectRegex != "" {
+		_, err := regexp.Compile(policy.SubjectRegex)
+		if err != nil {
+			return fmt.Errorf("invalid subject regex: %s", err)
+		}
+	}
+
+	if policy.MinKeyBits < 0 {
+		return fmt.Errorf("min key bits must not be negative")
+	}
+
+	return nil
+}
+
+// MatchDMSEnrollmentPolicy evaluates the registration of a DMS with the CSR and settings against the policy. If the
+// registration doesn't match, the unmet rule is returned.
+func MatchDMSEnrollmentPolicy(policy models.DMSEnrollmentPolicy, csr *x509.CertificateRequest, settings models.DMSSettings) (bool, string) {
+	if policy.SubjectRegex != "" {
+		matched, err := regexp.MatchString(policy.SubjectRegex, csr.Subject.CommonName)
+		if err != nil || !matched {
+			return false, fmt.Sprintf("subject '%s' does not match '%s'", csr.Subject.CommonName, policy.SubjectRegex)
+		}
+	}
+
+	keyType, keyBits := certificateRequestKey(csr)
+	if len(policy.KeyTypes) > 0 && !slices.Contains(policy.KeyTypes, keyType) {
+		return false, fmt.Sprintf("key type %s is not allowed", keyType)
+	}
+
+	if keyBits < policy.MinKeyBits {
+		return false, fmt.Sprintf("key size %d is below %d bits", keyBits, policy.MinKeyBits)
+	}
+
+	if len(policy.AllowedCAIDs) > 0 {
+		caIDs := append([]string{settings.EnrollmentSettings.EnrollmentCA}, settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ValidationCAs...)
+		caIDs = append(caIDs, settings.ReEnrollmentSettings.AdditionalValidationCAs...)
+		for _, caID := range caIDs {
+			if caID != "" && !slices.Contains(policy.AllowedCAIDs, caID) {
+				return false, fmt.Sprintf("CA %s is not allowed", caID)
+			}
+		}
+	}
+
+	return true, ""
+}
+
+func certificateRequestKey(csr *x509.CertificateRequest) (models.KeyType, int) {
+	switch key := csr.PublicKey.(type) {
+	case *rsa.PublicKey:
+		return models.KeyType(x509.RSA), key.N.BitLen()
+	case *ecdsa.PublicKey:
+		return models.KeyType(x509.ECDSA), key.Params().BitSize
+	}
+
+	return models.KeyType(csr.PublicKeyAlgorithm), 0
+}
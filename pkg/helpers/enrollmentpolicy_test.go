@@ -0,0 +1,71 @@
+package helpers
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestValidateDMSEnrollmentPolicy(t *testing.T) {
+	err := ValidateDMSEnrollmentPolicy(models.DMSEnrollmentPolicy{SubjectRegex: "^gw-[0-9]+$", MinKeyBits: 256})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	err = ValidateDMSEnrollmentPolicy(models.DMSEnrollmentPolicy{SubjectRegex: "gw-[0-9"})
+	if err == nil {
+		t.Fatalf("expected invalid regex error")
+	}
+
+	err = ValidateDMSEnrollmentPolicy(models.DMSEnrollmentPolicy{MinKeyBits: -1})
+	if err == nil {
+		t.Fatalf("expected negative key size error")
+	}
+}
+
+func TestMatchDMSEnrollmentPolicy(t *testing.T) {
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	csr, err := GenerateCertificateRequest(models.Subject{CommonName: "gw-001"}, key)
+	if err != nil {
+		t.Fatalf("could not generate csr: %s", err)
+	}
+
+	settings := models.DMSSettings{
+		EnrollmentSettings: models.EnrollmentSettings{
+			EnrollmentCA: "ca-1",
+			EnrollmentOptionsESTRFC7030: models.EnrollmentOptionsESTRFC7030{
+				AuthOptionsMTLS: models.AuthOptionsClientCertificate{ValidationCAs: []string{"bootstrap-ca"}},
+			},
+		},
+	}
+
+	testcases := []struct {
+		name    string
+		policy  models.DMSEnrollmentPolicy
+		matches bool
+	}{
+		{name: "EmptyPolicy", policy: models.DMSEnrollmentPolicy{}, matches: true},
+		{name: "SubjectMatches", policy: models.DMSEnrollmentPolicy{SubjectRegex: "^gw-[0-9]+$"}, matches: true},
+		{name: "SubjectMismatch", policy: models.DMSEnrollmentPolicy{SubjectRegex: "^sensor-"}, matches: false},
+		{name: "KeyTypeAllowed", policy: models.DMSEnrollmentPolicy{KeyTypes: []models.KeyType{models.KeyType(x509.ECDSA)}, MinKeyBits: 256}, matches: true},
+		{name: "KeyTypeNotAllowed", policy: models.DMSEnrollmentPolicy{KeyTypes: []models.KeyType{models.KeyType(x509.RSA)}}, matches: false},
+		{name: "KeyTooSmall", policy: models.DMSEnrollmentPolicy{MinKeyBits: 384}, matches: false},
+		{name: "CAsAllowed", policy: models.DMSEnrollmentPolicy{AllowedCAIDs: []string{"ca-1", "bootstrap-ca"}}, matches: true},
+		{name: "ValidationCANotAllowed", policy: models.DMSEnrollmentPolicy{AllowedCAIDs: []string{"ca-1"}}, matches: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			matches, reason := MatchDMSEnrollmentPolicy(tc.policy, csr, settings)
+			if matches != tc.matches {
+				t.Fatalf("expected match %v, got %v (%s)", tc.matches, matches, reason)
+			}
+
+			if !matches && reason == "" {
+				t.Fatalf("expected the unmet rule to be reported")
+			}
+		})
+	}
+}
@@ -0,0 +1,37 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ETag returns the strong entity tag of the resource, derived from its JSON encoding. Any change of the stored
+// resource results in a different tag.
+func ETag(resource any) string {
+	b, err := json.Marshal(resource)
+	if err != nil {
+		return ""
+	}
+
+	sum := sha256.Sum256(b)
+	return fmt.Sprintf("\"%x\"", sum[:16])
+}
+
+// ETagMatches evaluates an If-Match header value (RFC 9110 section 13.1.1) against the current entity tag of the
+// resource. An empty header always matches. Weak tags never match, as If-Match uses the strong comparison.
+func ETagMatches(ifMatch, etag string) bool {
+	ifMatch = strings.TrimSpace(ifMatch)
+	if ifMatch == "" || ifMatch == "*" {
+		return true
+	}
+
+	for _, candidate := range strings.Split(ifMatch, ",") {
+		if strings.TrimSpace(candidate) == etag {
+			return true
+		}
+	}
+
+	return false
+}
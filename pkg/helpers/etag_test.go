@@ -0,0 +1,46 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestETag(t *testing.T) {
+	dms := models.DMS{ID: "dms", Name: "DMS", Metadata: map[string]any{"a": 1, "b": 2}}
+	etag := ETag(dms)
+
+	if etag != ETag(models.DMS{ID: "dms", Name: "DMS", Metadata: map[string]any{"b": 2, "a": 1}}) {
+		t.Fatalf("expected the same tag for the same resource")
+	}
+
+	dms.Name = "Updated DMS"
+	if etag == ETag(dms) {
+		t.Fatalf("expected a different tag for a modified resource")
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	etag := ETag(models.DMS{ID: "dms"})
+
+	testcases := []struct {
+		name    string
+		ifMatch string
+		matches bool
+	}{
+		{name: "Empty", ifMatch: "", matches: true},
+		{name: "Any", ifMatch: "*", matches: true},
+		{name: "Equal", ifMatch: etag, matches: true},
+		{name: "List", ifMatch: "\"other\", " + etag, matches: true},
+		{name: "Weak", ifMatch: "W/" + etag, matches: false},
+		{name: "Different", ifMatch: "\"other\"", matches: false},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			if ETagMatches(tc.ifMatch, etag) != tc.matches {
+				t.Fatalf("expected match %t for If-Match %s", tc.matches, tc.ifMatch)
+			}
+		})
+	}
+}
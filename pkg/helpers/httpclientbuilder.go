@@ -28,6 +28,7 @@ func BuildHTTPClientWithTLSOptions(cli *http.Client, cfg config.TLSConfig) (*htt
 		caPool.AddCert(cert)
 	}
 
+	tlsConfig.RootCAs = caPool
 	cli.Transport = &http.Transport{
 		TLSClientConfig: tlsConfig,
 	}
@@ -0,0 +1,250 @@
+package helpers
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+)
+
+const defaultInternalCertificateValidity = time.Hour
+
+// InternalCertificateIssuer signs the short-lived client certificates used by the Lamassu services to authenticate
+// against each other. It holds the internal CA key, so it is only built by the CA service.
+type InternalCertificateIssuer struct {
+	caCert   *x509.Certificate
+	caKey    crypto.Signer
+	validity time.Duration
+}
+
+func NewInternalCertificateIssuer(caCert *x509.Certificate, caKey crypto.Signer, validity time.Duration) (*InternalCertificateIssuer, error) {
+	if !caCert.IsCA {
+		return nil, fmt.Errorf("internal CA certificate is not a CA")
+	}
+
+	if validity <= 0 {
+		return nil, fmt.Errorf("internal certificate validity must be positive")
+	}
+
+	return &InternalCertificateIssuer{
+		caCert:   caCert,
+		caKey:    caKey,
+		validity: validity,
+	}, nil
+}
+
+// LoadInternalCertificateIssuer builds the issuer from the internal CA files of the CA service configuration.
+func LoadInternalCertificateIssuer(cfg config.InternalCertificates) (*InternalCertificateIssuer, error) {
+	caCert, err := ReadCertificateFromFile(cfg.CACertFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read internal CA certificate: %w", err)
+	}
+
+	key, err := ReadPrivateKeyFromFile(cfg.CAKeyFile)
+	if err != nil {
+		return nil, fmt.Errorf("could not read internal CA key: %w", err)
+	}
+
+	caKey, ok := key.(crypto.Signer)
+	if !ok {
+		return nil, fmt.Errorf("internal CA key can not sign")
+	}
+
+	validity := defaultInternalCertificateValidity
+	if cfg.CertificateValidity != "" {
+		validity, err = models.ParseDuration(cfg.CertificateValidity)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse internal certificate validity '%s': %w", cfg.CertificateValidity, err)
+		}
+	}
+
+	return NewInternalCertificateIssuer(caCert, caKey, validity)
+}
+
+func (i *InternalCertificateIssuer) CACertificate() *x509.Certificate {
+	return i.caCert
+}
+
+// Issue signs a client certificate for the key of the request. The subject of the request is ignored: the
+// certificate is always issued for the identity the caller was authenticated as.
+func (i *InternalCertificateIssuer) Issue(csr *x509.CertificateRequest, identity string) (*x509.Certificate, error) {
+	err := csr.CheckSignature()
+	if err != nil {
+		return nil, fmt.Errorf("invalid certificate request signature: %w", err)
+	}
+
+	sn, err := rand.Int(rand.Reader, new(big.Int).Lsh(big.NewInt(1), 160))
+	if err != nil {
+		return nil, err
+	}
+
+	now := time.Now()
+	notAfter := now.Add(i.validity)
+	if notAfter.After(i.caCert.NotAfter) {
+		notAfter = i.caCert.NotAfter
+	}
+
+	if !notAfter.After(now) {
+		return nil, fmt.Errorf("internal CA certificate is expired")
+	}
+
+	template := x509.Certificate{
+		SerialNumber: sn,
+		Subject: pkix.Name{
+			CommonName: identity,
+		},
+		// tolerate small clock skews between services
+		NotBefore:   now.Add(-time.Minute),
+		NotAfter:    notAfter,
+		KeyUsage:    x509.KeyUsageDigitalSignature,
+		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth},
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, &template, i.caCert, csr.PublicKey, i.caKey)
+	if err != nil {
+		return nil, fmt.Errorf("could not issue internal certificate: %w", err)
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+// InternalClientCertificate is the client certificate of a service, requested to the internal certificate issuer
+// of the CA service. The key is generated when the service starts and never leaves it. Certificates are renewed
+// once two thirds of their lifetime have elapsed, so a leaked certificate is only valid for a short period of time.
+type InternalClientCertificate struct {
+	key       *ecdsa.PrivateKey
+	identity  string
+	token     string
+	issuerURL string
+	httpCli   *http.Client
+
+	mu      sync.Mutex
+	current *tls.Certificate
+	renewAt time.Time
+}
+
+// NewInternalClientCertificate builds the client certificate of the service. httpCli is used to call the issuer and
+// must not authenticate with the client certificate itself.
+func NewInternalClientCertificate(cfg config.AuthMTLSOptions, httpCli *http.Client) (*InternalClientCertificate, error) {
+	if cfg.IssuerURL == "" || cfg.Identity == "" || cfg.Token == "" {
+		return nil, fmt.Errorf("internal client certificates require the issuer URL, the identity and the token")
+	}
+
+	key, err := GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		return nil, err
+	}
+
+	return &InternalClientCertificate{
+		key:       key,
+		identity:  cfg.Identity,
+		token:     string(cfg.Token),
+		issuerURL: strings.TrimSuffix(cfg.IssuerURL, "/"),
+		httpCli:   httpCli,
+	}, nil
+}
+
+// Certificate returns the current client certificate, requesting a new one if it is due for renewal.
+func (c *InternalClientCertificate) Certificate() (*tls.Certificate, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	now := time.Now()
+	if c.current != nil && now.Before(c.renewAt) {
+		return c.current, nil
+	}
+
+	cert, err := c.request()
+	if err != nil {
+		return nil, err
+	}
+
+	lifetime := cert.Leaf.NotAfter.Sub(now)
+	c.current = cert
+	c.renewAt = now.Add(lifetime * 2 / 3)
+
+	return cert, nil
+}
+
+// GetClientCertificate can be used as tls.Config.GetClientCertificate so each TLS handshake uses a valid certificate.
+func (c *InternalClientCertificate) GetClientCertificate(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+	return c.Certificate()
+}
+
+func (c *InternalClientCertificate) request() (*tls.Certificate, error) {
+	csr, err := GenerateCertificateRequest(models.Subject{CommonName: c.identity}, c.key)
+	if err != nil {
+		return nil, err
+	}
+
+	body, err := json.Marshal(resources.IssueInternalCertificateBody{
+		CertRequest: (*models.X509CertificateRequest)(csr),
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.issuerURL+"/v1/internal/certificates", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Authorization", "Bearer "+c.token)
+
+	res, err := c.httpCli.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("could not request internal certificate: %w", err)
+	}
+	defer res.Body.Close()
+
+	content, err := io.ReadAll(res.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if res.StatusCode != http.StatusCreated {
+		return nil, fmt.Errorf("internal certificate issuer responded with status %d: %s", res.StatusCode, content)
+	}
+
+	var issued models.InternalCertificate
+	err = json.Unmarshal(content, &issued)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode internal certificate: %w", err)
+	}
+
+	if issued.Certificate == nil || issued.CACertificate == nil {
+		return nil, fmt.Errorf("internal certificate issuer did not return the certificate chain")
+	}
+
+	leaf := (*x509.Certificate)(issued.Certificate)
+	if pub, ok := leaf.PublicKey.(*ecdsa.PublicKey); !ok || !pub.Equal(&c.key.PublicKey) {
+		return nil, fmt.Errorf("internal certificate was not issued for the key of the service")
+	}
+
+	return &tls.Certificate{
+		Certificate: [][]byte{leaf.Raw, issued.CACertificate.Raw},
+		PrivateKey:  c.key,
+		Leaf:        leaf,
+	}, nil
+}
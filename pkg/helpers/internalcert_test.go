@@ -0,0 +1,249 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/elliptic"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+)
+
+func newInternalCA(t *testing.T, expiration time.Duration) (*x509.Certificate, crypto.Signer) {
+	caCert, caKey, err := GenerateSelfSignedCA(x509.ECDSA, expiration, "internal-ca")
+	if err != nil {
+		t.Fatalf("could not generate internal CA: %s", err)
+	}
+
+	return caCert, caKey.(crypto.Signer)
+}
+
+// newInternalIssuerServer serves the internal certificate issuer, binding the token to the identity.
+func newInternalIssuerServer(t *testing.T, issuer *InternalCertificateIssuer, token, identity string, requests *atomic.Int32) *httptest.Server {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requests.Add(1)
+		if r.URL.Path != "/v1/internal/certificates" || r.Header.Get("Authorization") != "Bearer "+token {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+
+		var body resources.IssueInternalCertificateBody
+		if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		crt, err := issuer.Issue((*x509.CertificateRequest)(body.CertRequest), identity)
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+
+		w.WriteHeader(http.StatusCreated)
+		json.NewEncoder(w).Encode(models.InternalCertificate{
+			Certificate:   (*models.X509Certificate)(crt),
+			CACertificate: (*models.X509Certificate)(issuer.CACertificate()),
+		})
+	}))
+	t.Cleanup(srv.Close)
+
+	return srv
+}
+
+func TestInternalCertificateIssuer(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Hour*24)
+
+	issuer, err := NewInternalCertificateIssuer(caCert, caKey, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	csr, _ := GenerateCertificateRequest(models.Subject{CommonName: "ca"}, key)
+
+	crt, err := issuer.Issue(csr, "dms-manager")
+	if err != nil {
+		t.Fatalf("unexpected error while issuing certificate: %s", err)
+	}
+
+	if crt.Subject.CommonName != "dms-manager" {
+		t.Errorf("certificate must be issued for the authenticated identity, got %s", crt.Subject.CommonName)
+	}
+
+	if crt.NotAfter.Sub(time.Now()) > time.Hour {
+		t.Errorf("certificate must not be valid for more than the configured validity")
+	}
+
+	roots := x509.NewCertPool()
+	roots.AddCert(caCert)
+	_, err = crt.Verify(x509.VerifyOptions{Roots: roots, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth}})
+	if err != nil {
+		t.Errorf("certificate must be a client certificate issued by the internal CA: %s", err)
+	}
+
+	csr.Signature[0] ^= 0xff
+	if _, err := issuer.Issue(csr, "dms-manager"); err == nil {
+		t.Errorf("expected error with an invalid CSR signature")
+	}
+}
+
+func TestInternalCertificateIssuerCappedByCA(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Minute)
+
+	issuer, err := NewInternalCertificateIssuer(caCert, caKey, time.Hour)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	csr, _ := GenerateCertificateRequest(models.Subject{CommonName: "va"}, key)
+
+	crt, err := issuer.Issue(csr, "va")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if crt.NotAfter.After(caCert.NotAfter) {
+		t.Errorf("certificate must not outlive the internal CA")
+	}
+}
+
+func TestNewInternalCertificateIssuerErrors(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Hour)
+
+	leafCert, err := GenerateSelfSignedCertificate(caKey, "not-a-ca")
+	if err != nil {
+		t.Fatalf("could not generate certificate: %s", err)
+	}
+
+	if _, err := NewInternalCertificateIssuer(leafCert, caKey, time.Hour); err == nil {
+		t.Errorf("expected error with a non CA certificate")
+	}
+
+	if _, err := NewInternalCertificateIssuer(caCert, caKey, 0); err == nil {
+		t.Errorf("expected error with a zero validity")
+	}
+}
+
+func TestLoadInternalCertificateIssuer(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Hour)
+	keyPem, err := PrivateKeyToPEM(caKey)
+	if err != nil {
+		t.Fatalf("could not encode key: %s", err)
+	}
+
+	dir := t.TempDir()
+	certFile := filepath.Join(dir, "ca.crt")
+	keyFile := filepath.Join(dir, "ca.key")
+	os.WriteFile(certFile, []byte(CertificateToPEM(caCert)), 0600)
+	os.WriteFile(keyFile, []byte(keyPem), 0600)
+
+	issuer, err := LoadInternalCertificateIssuer(config.InternalCertificates{
+		CACertFile:          certFile,
+		CAKeyFile:           keyFile,
+		CertificateValidity: "10m",
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if issuer.validity != 10*time.Minute {
+		t.Errorf("unexpected issuer validity: %s", issuer.validity)
+	}
+
+	_, err = LoadInternalCertificateIssuer(config.InternalCertificates{CACertFile: certFile, CAKeyFile: keyFile, CertificateValidity: "forever"})
+	if err == nil {
+		t.Errorf("expected error with an invalid validity")
+	}
+}
+
+func TestInternalClientCertificate(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Hour*24)
+	issuer, _ := NewInternalCertificateIssuer(caCert, caKey, time.Hour)
+
+	var requests atomic.Int32
+	srv := newInternalIssuerServer(t, issuer, "dms-token", "dms-manager", &requests)
+
+	clientCert, err := NewInternalClientCertificate(config.AuthMTLSOptions{
+		IssuerURL: srv.URL + "/",
+		Identity:  "dms-manager",
+		Token:     "dms-token",
+	}, srv.Client())
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cert, err := clientCert.Certificate()
+	if err != nil {
+		t.Fatalf("unexpected error while requesting certificate: %s", err)
+	}
+
+	if cert.Leaf.Subject.CommonName != "dms-manager" || len(cert.Certificate) != 2 {
+		t.Errorf("unexpected certificate: %s with %d certificates in chain", cert.Leaf.Subject.CommonName, len(cert.Certificate))
+	}
+
+	if cert.PrivateKey != clientCert.key {
+		t.Errorf("certificate must use the key of the service")
+	}
+
+	again, err := clientCert.GetClientCertificate(nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if again != cert || requests.Load() != 1 {
+		t.Errorf("certificate must be reused until it is due for renewal")
+	}
+}
+
+func TestInternalClientCertificateRenewal(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Hour*24)
+	issuer, _ := NewInternalCertificateIssuer(caCert, caKey, 30*time.Millisecond)
+
+	var requests atomic.Int32
+	srv := newInternalIssuerServer(t, issuer, "va-token", "va", &requests)
+
+	clientCert, _ := NewInternalClientCertificate(config.AuthMTLSOptions{IssuerURL: srv.URL, Identity: "va", Token: "va-token"}, srv.Client())
+
+	first, err := clientCert.Certificate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	time.Sleep(25 * time.Millisecond)
+
+	second, err := clientCert.Certificate()
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if first.Leaf.SerialNumber.Cmp(second.Leaf.SerialNumber) == 0 || requests.Load() != 2 {
+		t.Errorf("certificate must be renewed after two thirds of its lifetime")
+	}
+}
+
+func TestInternalClientCertificateErrors(t *testing.T) {
+	caCert, caKey := newInternalCA(t, time.Hour*24)
+	issuer, _ := NewInternalCertificateIssuer(caCert, caKey, time.Hour)
+
+	var requests atomic.Int32
+	srv := newInternalIssuerServer(t, issuer, "dms-token", "dms-manager", &requests)
+
+	if _, err := NewInternalClientCertificate(config.AuthMTLSOptions{IssuerURL: srv.URL, Identity: "dms-manager"}, srv.Client()); err == nil {
+		t.Errorf("expected error without token")
+	}
+
+	clientCert, _ := NewInternalClientCertificate(config.AuthMTLSOptions{IssuerURL: srv.URL, Identity: "dms-manager", Token: "wrong"}, srv.Client())
+	if _, err := clientCert.Certificate(); err == nil {
+		t.Errorf("expected error with a token rejected by the issuer")
+	}
+}
@@ -0,0 +1,50 @@
+package helpers
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// IssuanceReportToCSV encodes the report as CSV, one row per CA and DMS.
+func IssuanceReportToCSV(report *models.IssuanceReport) ([]byte, error) {
+	var buf bytes.Buffer
+	w := csv.NewWriter(&buf)
+
+	err := w.Write([]string{"scope", "id", "issued", "renewed", "revoked", "from", "to"})
+	if err != nil {
+		return nil, err
+	}
+
+	writeRows := func(scope string, activities []models.IssuanceActivity) error {
+		for _, activity := range activities {
+			err := w.Write([]string{
+				scope,
+				activity.ID,
+				strconv.Itoa(activity.Issued),
+				strconv.Itoa(activity.Renewed),
+				strconv.Itoa(activity.Revoked),
+				report.From.Format(time.RFC3339),
+				report.To.Format(time.RFC3339),
+			})
+			if err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	if err := writeRows("ca", report.CAs); err != nil {
+		return nil, err
+	}
+
+	if err := writeRows("dms", report.DMSs); err != nil {
+		return nil, err
+	}
+
+	w.Flush()
+	return buf.Bytes(), w.Error()
+}
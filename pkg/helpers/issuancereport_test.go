@@ -0,0 +1,36 @@
+package helpers
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestIssuanceReportToCSV(t *testing.T) {
+	from := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	to := time.Date(2024, 2, 1, 0, 0, 0, 0, time.UTC)
+
+	report := &models.IssuanceReport{
+		From: from,
+		To:   to,
+		CAs: []models.IssuanceActivity{
+			{ID: "ca-1", Issued: 10, Renewed: 3, Revoked: 1},
+		},
+		DMSs: []models.IssuanceActivity{
+			{ID: "dms-1", Issued: 7, Renewed: 3, Revoked: 0},
+		},
+	}
+
+	csv, err := IssuanceReportToCSV(report)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "scope,id,issued,renewed,revoked,from,to\n" +
+		"ca,ca-1,10,3,1,2024-01-01T00:00:00Z,2024-02-01T00:00:00Z\n" +
+		"dms,dms-1,7,3,0,2024-01-01T00:00:00Z,2024-02-01T00:00:00Z\n"
+	if string(csv) != expected {
+		t.Errorf("unexpected CSV.\nexpected:\n%s\ngot:\n%s", expected, string(csv))
+	}
+}
@@ -0,0 +1,234 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// jwsAlgorithms maps the JWS algorithms (RFC 7518) to the signing algorithms supported by the X509 engine.
+var jwsAlgorithms = map[string]string{
+	"ES256": "ECDSA_SHA_256",
+	"ES384": "ECDSA_SHA_384",
+	"ES512": "ECDSA_SHA_512",
+	"RS256": "RSASSA_PKCS1_V1_5_SHA_256",
+	"RS384": "RSASSA_PKCS1_V1_5_SHA_384",
+	"RS512": "RSASSA_PKCS1_V1_5_SHA_512",
+	"PS256": "RSASSA_PSS_SHA_256",
+	"PS384": "RSASSA_PSS_SHA_384",
+	"PS512": "RSASSA_PSS_SHA_512",
+}
+
+var jwsCurves = map[string]string{
+	"P-256": "ES256",
+	"P-384": "ES384",
+	"P-521": "ES512",
+}
+
+// JWSAlgorithmToSigningAlgorithm returns the X509 engine signing algorithm for the JWS algorithm,
+// checking it can be used with the given public key.
+func JWSAlgorithmToSigningAlgorithm(pub crypto.PublicKey, alg string) (string, error) {
+	signingAlg, ok := jwsAlgorithms[alg]
+	if !ok {
+		return "", fmt.Errorf("unsupported JWS algorithm %s", alg)
+	}
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		if jwsCurves[key.Curve.Params().Name] != alg {
+			return "", fmt.Errorf("JWS algorithm %s can not be used with %s keys", alg, key.Curve.Params().Name)
+		}
+	case *rsa.PublicKey:
+		if alg[0] != 'R' && alg[0] != 'P' {
+			return "", fmt.Errorf("JWS algorithm %s can not be used with RSA keys", alg)
+		}
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	return signingAlg, nil
+}
+
+// DefaultJWSAlgorithm returns the JWS algorithm used when the caller does not request one.
+func DefaultJWSAlgorithm(pub crypto.PublicKey) (string, error) {
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		alg, ok := jwsCurves[key.Curve.Params().Name]
+		if !ok {
+			return "", fmt.Errorf("unsupported curve %s", key.Curve.Params().Name)
+		}
+		return alg, nil
+	case *rsa.PublicKey:
+		return "RS256", nil
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// PublicKeyToJWK returns the JWK of a public signing key.
+func PublicKeyToJWK(pub crypto.PublicKey, kid string, alg string) (*models.JWK, error) {
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		return &models.JWK{
+			Kty: "EC",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			Crv: key.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(key.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(key.Y.FillBytes(make([]byte, size))),
+		}, nil
+	case *rsa.PublicKey:
+		return &models.JWK{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			N:   base64.RawURLEncoding.EncodeToString(key.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(key.E)).Bytes()),
+		}, nil
+	default:
+		return nil, fmt.Errorf("unsupported public key type %T", pub)
+	}
+}
+
+// CertificateToJWK returns the JWK of the certificate public key, including the certificate chain (x5c),
+// its thumbprint (x5t#S256) and its validity period.
+func CertificateToJWK(cert *x509.Certificate, kid string, alg string) (*models.JWK, error) {
+	jwk, err := PublicKeyToJWK(cert.PublicKey, kid, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	thumbprint := sha256.Sum256(cert.Raw)
+	jwk.X5C = []string{base64.StdEncoding.EncodeToString(cert.Raw)}
+	jwk.X5TS256 = base64.RawURLEncoding.EncodeToString(thumbprint[:])
+	jwk.NotBefore = cert.NotBefore.Unix()
+	jwk.ExpiresAt = cert.NotAfter.Unix()
+
+	return jwk, nil
+}
+
+// JWSSigningInput returns the JWS compact serialization signing input (base64url(header).base64url(claims)).
+func JWSSigningInput(alg, kid string, claims map[string]any) (string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": alg,
+		"kid": kid,
+		"typ": "JWT",
+	})
+	if err != nil {
+		return "", err
+	}
+
+	payload, err := json.Marshal(claims)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(payload), nil
+}
+
+// JWSSignature converts the signature returned by the crypto engines into its JWS encoding. ECDSA signatures
+// are ASN.1 encoded by the engines, while JWS expects the fixed size R || S concatenation.
+func JWSSignature(pub crypto.PublicKey, signature []byte) (string, error) {
+	if key, ok := pub.(*ecdsa.PublicKey); ok {
+		var sig struct {
+			R, S *big.Int
+		}
+		_, err := asn1.Unmarshal(signature, &sig)
+		if err != nil {
+			return "", fmt.Errorf("could not decode ECDSA signature: %w", err)
+		}
+
+		size := (key.Curve.Params().BitSize + 7) / 8
+		signature = append(sig.R.FillBytes(make([]byte, size)), sig.S.FillBytes(make([]byte, size))...)
+	}
+
+	return base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// JWSDetachedSigningInput returns the signing input of a JWS with detached content (RFC 7515 Appendix F) and its
+// encoded protected header. The payload is left out of the serialization, so the content is distributed as is.
+func JWSDetachedSigningInput(alg, kid string, payload []byte) (string, string, error) {
+	header, err := json.Marshal(map[string]string{
+		"alg": alg,
+		"kid": kid,
+	})
+	if err != nil {
+		return "", "", err
+	}
+
+	encodedHeader := base64.RawURLEncoding.EncodeToString(header)
+	return encodedHeader + "." + base64.RawURLEncoding.EncodeToString(payload), encodedHeader, nil
+}
+
+// VerifyDetachedJWS verifies the compact serialization of a JWS with detached content (header..signature) against
+// the payload it was computed over.
+func VerifyDetachedJWS(jws string, payload []byte, pub crypto.PublicKey) error {
+	parts := strings.Split(jws, ".")
+	if len(parts) != 3 || parts[1] != "" {
+		return fmt.Errorf("not a detached JWS compact serialization")
+	}
+
+	rawHeader, err := base64.RawURLEncoding.DecodeString(parts[0])
+	if err != nil {
+		return fmt.Errorf("could not decode JWS header: %w", err)
+	}
+
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if err := json.Unmarshal(rawHeader, &header); err != nil {
+		return fmt.Errorf("could not decode JWS header: %w", err)
+	}
+
+	if _, err := JWSAlgorithmToSigningAlgorithm(pub, header.Alg); err != nil {
+		return err
+	}
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return fmt.Errorf("could not decode JWS signature: %w", err)
+	}
+
+	hash := map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}[header.Alg[2:]]
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + base64.RawURLEncoding.EncodeToString(payload)))
+	digest := h.Sum(nil)
+
+	switch key := pub.(type) {
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if len(signature) != 2*size {
+			return fmt.Errorf("invalid ECDSA signature length")
+		}
+
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, digest, r, s) {
+			return fmt.Errorf("invalid JWS signature")
+		}
+	case *rsa.PublicKey:
+		if header.Alg[0] == 'P' {
+			err = rsa.VerifyPSS(key, hash, digest, signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		} else {
+			err = rsa.VerifyPKCS1v15(key, hash, digest, signature)
+		}
+		if err != nil {
+			return fmt.Errorf("invalid JWS signature: %w", err)
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,200 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/base64"
+	"math/big"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestJWSAlgorithmToSigningAlgorithm(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	testcases := []struct {
+		name    string
+		pub     crypto.PublicKey
+		alg     string
+		want    string
+		wantErr bool
+	}{
+		{name: "ES256 with P-256", pub: &ecKey.PublicKey, alg: "ES256", want: "ECDSA_SHA_256"},
+		{name: "ES384 with P-256", pub: &ecKey.PublicKey, alg: "ES384", wantErr: true},
+		{name: "RS256 with RSA", pub: &rsaKey.PublicKey, alg: "RS256", want: "RSASSA_PKCS1_V1_5_SHA_256"},
+		{name: "PS384 with RSA", pub: &rsaKey.PublicKey, alg: "PS384", want: "RSASSA_PSS_SHA_384"},
+		{name: "ES256 with RSA", pub: &rsaKey.PublicKey, alg: "ES256", wantErr: true},
+		{name: "HS256", pub: &rsaKey.PublicKey, alg: "HS256", wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			got, err := JWSAlgorithmToSigningAlgorithm(tc.pub, tc.alg)
+			if tc.wantErr {
+				if err == nil {
+					t.Errorf("expected error, got %s", got)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if got != tc.want {
+				t.Errorf("expected %s, got %s", tc.want, got)
+			}
+		})
+	}
+}
+
+func TestJWSSignatureECDSA(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+
+	signingInput, err := JWSSigningInput("ES256", "ca-1", map[string]any{"sub": "device-1"})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	digest := sha256.Sum256([]byte(signingInput))
+	der, err := key.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	encoded, err := JWSSignature(&key.PublicKey, der)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	raw, _ := base64.RawURLEncoding.DecodeString(encoded)
+	if len(raw) != 64 {
+		t.Fatalf("expected 64 bytes ES256 signature, got %d", len(raw))
+	}
+
+	r := new(big.Int).SetBytes(raw[:32])
+	s := new(big.Int).SetBytes(raw[32:])
+	if !ecdsa.Verify(&key.PublicKey, digest[:], r, s) {
+		t.Errorf("JWS signature could not be verified")
+	}
+
+	header, _ := base64.RawURLEncoding.DecodeString(strings.Split(signingInput, ".")[0])
+	if !strings.Contains(string(header), `"kid":"ca-1"`) {
+		t.Errorf("expected kid in JWS header, got %s", header)
+	}
+}
+
+func TestPublicKeyToJWK(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	jwk, err := PublicKeyToJWK(&ecKey.PublicKey, "ca-1", "ES384")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if jwk.Kty != "EC" || jwk.Crv != "P-384" || jwk.Kid != "ca-1" {
+		t.Errorf("unexpected EC JWK %+v", jwk)
+	}
+
+	x, _ := base64.RawURLEncoding.DecodeString(jwk.X)
+	if len(x) != 48 {
+		t.Errorf("expected 48 bytes X coordinate, got %d", len(x))
+	}
+
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	jwk, err = PublicKeyToJWK(&rsaKey.PublicKey, "ca-2", "RS256")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if jwk.Kty != "RSA" || jwk.E != "AQAB" {
+		t.Errorf("unexpected RSA JWK %+v", jwk)
+	}
+}
+
+func TestCertificateToJWK(t *testing.T) {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	notBefore := time.Now().Truncate(time.Second)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(1),
+		Subject:      pkix.Name{CommonName: "ca-1"},
+		NotBefore:    notBefore,
+		NotAfter:     notBefore.Add(24 * time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+	cert, _ := x509.ParseCertificate(der)
+
+	jwk, err := CertificateToJWK(cert, "ca-1", "ES256")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	thumbprint := sha256.Sum256(der)
+	if jwk.X5TS256 != base64.RawURLEncoding.EncodeToString(thumbprint[:]) {
+		t.Errorf("unexpected x5t#S256 %s", jwk.X5TS256)
+	}
+
+	if len(jwk.X5C) != 1 || jwk.X5C[0] != base64.StdEncoding.EncodeToString(der) {
+		t.Errorf("unexpected x5c %v", jwk.X5C)
+	}
+
+	if jwk.NotBefore != notBefore.Unix() || jwk.ExpiresAt != notBefore.Add(24*time.Hour).Unix() {
+		t.Errorf("unexpected validity nbf=%d exp=%d", jwk.NotBefore, jwk.ExpiresAt)
+	}
+}
+
+func TestVerifyDetachedJWS(t *testing.T) {
+	payload := []byte("-----BEGIN CERTIFICATE-----")
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P384(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+
+	sign := func(alg string, signer crypto.Signer, hash crypto.Hash, opts crypto.SignerOpts) string {
+		signingInput, header, err := JWSDetachedSigningInput(alg, "ca-1", payload)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		h := hash.New()
+		h.Write([]byte(signingInput))
+		der, err := signer.Sign(rand.Reader, h.Sum(nil), opts)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		signature, err := JWSSignature(signer.Public(), der)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		return header + ".." + signature
+	}
+
+	ecJWS := sign("ES384", ecKey, crypto.SHA384, crypto.SHA384)
+	if err := VerifyDetachedJWS(ecJWS, payload, &ecKey.PublicKey); err != nil {
+		t.Errorf("unexpected error verifying ES384 signature: %s", err)
+	}
+
+	rsaJWS := sign("PS256", rsaKey, crypto.SHA256, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthEqualsHash, Hash: crypto.SHA256})
+	if err := VerifyDetachedJWS(rsaJWS, payload, &rsaKey.PublicKey); err != nil {
+		t.Errorf("unexpected error verifying PS256 signature: %s", err)
+	}
+
+	if err := VerifyDetachedJWS(ecJWS, []byte("tampered"), &ecKey.PublicKey); err == nil {
+		t.Errorf("expected error verifying tampered content")
+	}
+
+	if err := VerifyDetachedJWS(ecJWS, payload, &rsaKey.PublicKey); err == nil {
+		t.Errorf("expected error verifying with another key")
+	}
+}
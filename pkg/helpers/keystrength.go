@@ -4,6 +4,7 @@ import (
 	"crypto/ecdsa"
 	"crypto/rsa"
 	"crypto/x509"
+	"slices"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 )
@@ -46,3 +47,37 @@ func KeyStrengthMetadataFromCertificate(cert *x509.Certificate) models.KeyStreng
 		Strength: keyStrength,
 	}
 }
+
+var deprecatedSignatureAlgorithms = []x509.SignatureAlgorithm{
+	x509.MD2WithRSA,
+	x509.MD5WithRSA,
+	x509.SHA1WithRSA,
+	x509.DSAWithSHA1,
+	x509.DSAWithSHA256,
+	x509.ECDSAWithSHA1,
+}
+
+// KeyStrengthFindingFromCertificate checks the key strength and the signature algorithm of the certificate.
+// The returned boolean is false when no issue has been found.
+func KeyStrengthFindingFromCertificate(cert models.Certificate) (models.KeyStrengthFinding, bool) {
+	finding := models.KeyStrengthFinding{
+		SerialNumber:     cert.SerialNumber,
+		Subject:          cert.Subject,
+		IssuerCAMetadata: cert.IssuerCAMetadata,
+		KeyMetadata:      cert.KeyMetadata,
+		Issues:           []models.KeyStrengthIssue{},
+	}
+
+	if cert.KeyMetadata.Strength == models.KeyStrengthLow {
+		finding.Issues = append(finding.Issues, models.KeyStrengthIssueLowStrength)
+	}
+
+	if cert.Certificate != nil {
+		finding.SignatureAlgorithm = cert.Certificate.SignatureAlgorithm.String()
+		if slices.Contains(deprecatedSignatureAlgorithms, cert.Certificate.SignatureAlgorithm) {
+			finding.Issues = append(finding.Issues, models.KeyStrengthIssueDeprecatedSignatureAlgorithm)
+		}
+	}
+
+	return finding, len(finding.Issues) > 0
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"slices"
 	"testing"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
@@ -91,3 +92,37 @@ func TestKeyStrengthMetadataFromCertificate(t *testing.T) {
 		t.Errorf("Expected %v, but got %v", expected6, result6)
 	}
 }
+
+func TestKeyStrengthFindingFromCertificate(t *testing.T) {
+	cert := models.Certificate{
+		SerialNumber: "01-02",
+		KeyMetadata: models.KeyStrengthMetadata{
+			Type:     models.KeyType(x509.RSA),
+			Bits:     2048,
+			Strength: models.KeyStrengthMedium,
+		},
+		Certificate: &models.X509Certificate{SignatureAlgorithm: x509.SHA256WithRSA},
+	}
+
+	_, found := KeyStrengthFindingFromCertificate(cert)
+	if found {
+		t.Errorf("Expected no issues for a medium strength key signed with SHA256WithRSA")
+	}
+
+	cert.KeyMetadata.Strength = models.KeyStrengthLow
+	cert.Certificate = &models.X509Certificate{SignatureAlgorithm: x509.SHA1WithRSA}
+
+	finding, found := KeyStrengthFindingFromCertificate(cert)
+	if !found {
+		t.Fatalf("Expected issues for a low strength key signed with SHA1WithRSA")
+	}
+
+	expectedIssues := []models.KeyStrengthIssue{models.KeyStrengthIssueLowStrength, models.KeyStrengthIssueDeprecatedSignatureAlgorithm}
+	if !slices.Equal(finding.Issues, expectedIssues) {
+		t.Errorf("Expected %v, but got %v", expectedIssues, finding.Issues)
+	}
+
+	if finding.SerialNumber != "01-02" || finding.SignatureAlgorithm != x509.SHA1WithRSA.String() {
+		t.Errorf("Unexpected finding %v", finding)
+	}
+}
@@ -53,6 +53,12 @@ func SetupLogger(currentLevel config.LogLevel, serviceID string, subsystem strin
 		lSubsystem.Logger.SetLevel(level)
 	}
 
+	registeredLevel := config.LogLevel(lSubsystem.Logger.GetLevel().String())
+	if currentLevel == config.None {
+		registeredLevel = config.None
+	}
+	registerLogger(serviceID, subsystem, registeredLevel, logger)
+
 	lSubsystem.Infof("log level set to '%s'", lSubsystem.Logger.GetLevel())
 	return lSubsystem
 }
@@ -108,3 +114,9 @@ func InitContext() context.Context {
 	ctx = context.WithValue(ctx, headerextractors.CtxRequestID, fmt.Sprintf("internal.%s", goid.NewV4UUID()))
 	return ctx
 }
+
+// GetRequestID returns the ID of the request being processed, or an empty string if the context does not carry one.
+func GetRequestID(ctx context.Context) string {
+	reqID, _ := ctx.Value(headerextractors.CtxRequestID).(string)
+	return reqID
+}
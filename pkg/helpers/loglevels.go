@@ -0,0 +1,167 @@
+package helpers
+
+import (
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/sirupsen/logrus"
+)
+
+// subsystemLoggers groups the loggers created by SetupLogger for the same service subsystem.
+type subsystemLoggers struct {
+	level   config.LogLevel
+	loggers []*logrus.Logger
+}
+
+// logLevels keeps the subsystem loggers of each service so their level can be changed at runtime.
+var logLevels = struct {
+	mu       sync.Mutex
+	services map[string]map[string]*subsystemLoggers
+	reverts  map[string]*time.Timer
+}{
+	services: map[string]map[string]*subsystemLoggers{},
+	reverts:  map[string]*time.Timer{},
+}
+
+func registerLogger(serviceID, subsystem string, level config.LogLevel, logger *logrus.Logger) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+
+	subsystems, ok := logLevels.services[serviceID]
+	if !ok {
+		subsystems = map[string]*subsystemLoggers{}
+		logLevels.services[serviceID] = subsystems
+	}
+
+	sub, ok := subsystems[subsystem]
+	if !ok {
+		sub = &subsystemLoggers{}
+		subsystems[subsystem] = sub
+	}
+
+	sub.level = level
+	sub.loggers = append(sub.loggers, logger)
+}
+
+func parseLogLevel(level config.LogLevel) (logrus.Level, error) {
+	if level == config.None {
+		return logrus.PanicLevel, nil
+	}
+
+	parsed, err := logrus.ParseLevel(string(level))
+	if err != nil {
+		return parsed, errs.ErrLogLevelInvalid
+	}
+
+	return parsed, nil
+}
+
+func (sub *subsystemLoggers) setLevel(level config.LogLevel) {
+	parsed, _ := parseLogLevel(level)
+	for _, logger := range sub.loggers {
+		if level == config.None {
+			logger.SetOutput(io.Discard)
+		} else {
+			logger.SetOutput(os.Stderr)
+			logger.SetLevel(parsed)
+		}
+	}
+
+	sub.level = level
+}
+
+// GetLogLevels returns the global log level and the level of each subsystem of the service.
+func GetLogLevels(serviceID string) (config.LogLevel, map[string]config.LogLevel) {
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+
+	levels := map[string]config.LogLevel{}
+	for subsystem, sub := range logLevels.services[serviceID] {
+		levels[subsystem] = sub.level
+	}
+
+	return config.LogLevel(logrus.GetLevel().String()), levels
+}
+
+// SetLogLevel changes at runtime the level of a subsystem of the service. If subsystem is empty, the global level and
+// the level of all the subsystems of the service are changed. If duration is positive, the previous levels are
+// restored once elapsed.
+//
+// Returned Error Codes:
+//   - ErrLogLevelInvalid
+//     The level is not a logrus level nor 'none'. The global level can not be 'none'.
+//   - ErrLogSubsystemNotFound
+//     The service has no subsystem with the given name.
+func SetLogLevel(serviceID, subsystem string, level config.LogLevel, duration time.Duration) error {
+	parsed, err := parseLogLevel(level)
+	if err != nil {
+		return err
+	}
+
+	logLevels.mu.Lock()
+	defer logLevels.mu.Unlock()
+
+	subsystems := logLevels.services[serviceID]
+	previous := map[string]config.LogLevel{}
+	if subsystem == "" {
+		if level == config.None {
+			return errs.ErrLogLevelInvalid
+		}
+
+		for name, sub := range subsystems {
+			previous[name] = sub.level
+		}
+	} else {
+		sub, ok := subsystems[subsystem]
+		if !ok {
+			return errs.ErrLogSubsystemNotFound
+		}
+
+		previous[subsystem] = sub.level
+	}
+
+	previousGlobal := logrus.GetLevel()
+	for name := range previous {
+		subsystems[name].setLevel(level)
+	}
+
+	if subsystem == "" {
+		logrus.SetLevel(parsed)
+	}
+
+	revertKey := serviceID + "/" + subsystem
+	if timer, ok := logLevels.reverts[revertKey]; ok {
+		timer.Stop()
+		delete(logLevels.reverts, revertKey)
+	}
+
+	if duration > 0 {
+		var timer *time.Timer
+		timer = time.AfterFunc(duration, func() {
+			logLevels.mu.Lock()
+			defer logLevels.mu.Unlock()
+
+			// A later change of the same level replaced this revert.
+			if logLevels.reverts[revertKey] != timer {
+				return
+			}
+
+			for name, level := range previous {
+				subsystems[name].setLevel(level)
+			}
+
+			if subsystem == "" {
+				logrus.SetLevel(previousGlobal)
+			}
+
+			delete(logLevels.reverts, revertKey)
+		})
+		logLevels.reverts[revertKey] = timer
+	}
+
+	return nil
+}
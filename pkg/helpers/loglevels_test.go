@@ -0,0 +1,91 @@
+package helpers
+
+import (
+	"io"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/sirupsen/logrus"
+)
+
+func TestSetLogLevel(t *testing.T) {
+	globalLevel := logrus.GetLevel()
+	defer logrus.SetLevel(globalLevel)
+
+	lService := SetupLogger(config.Info, "LogLevels TestCase", "Service")
+	lStorage := SetupLogger(config.None, "LogLevels TestCase", "Storage")
+
+	_, levels := GetLogLevels("LogLevels TestCase")
+	if levels["Service"] != config.Info || levels["Storage"] != config.None {
+		t.Fatalf("unexpected initial levels %v", levels)
+	}
+
+	err := SetLogLevel("LogLevels TestCase", "Service", config.Trace, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if lService.Logger.GetLevel() != logrus.TraceLevel {
+		t.Errorf("expected service subsystem at trace level, got %s", lService.Logger.GetLevel())
+	}
+
+	err = SetLogLevel("LogLevels TestCase", "", config.Debug, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if lStorage.Logger.GetLevel() != logrus.DebugLevel || lStorage.Logger.Out == io.Discard {
+		t.Errorf("expected storage subsystem enabled at debug level")
+	}
+
+	if logrus.GetLevel() != logrus.DebugLevel {
+		t.Errorf("expected global debug level, got %s", logrus.GetLevel())
+	}
+
+	err = SetLogLevel("LogLevels TestCase", "Storage", config.None, 0)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if lStorage.Logger.Out != io.Discard {
+		t.Errorf("expected storage subsystem disabled")
+	}
+}
+
+func TestSetLogLevelErrors(t *testing.T) {
+	SetupLogger(config.Info, "LogLevels Errors TestCase", "Service")
+
+	if err := SetLogLevel("LogLevels Errors TestCase", "Service", "verbose", 0); err != errs.ErrLogLevelInvalid {
+		t.Errorf("expected ErrLogLevelInvalid, got %v", err)
+	}
+
+	if err := SetLogLevel("LogLevels Errors TestCase", "", config.None, 0); err != errs.ErrLogLevelInvalid {
+		t.Errorf("expected ErrLogLevelInvalid, got %v", err)
+	}
+
+	if err := SetLogLevel("LogLevels Errors TestCase", "Storage", config.Debug, 0); err != errs.ErrLogSubsystemNotFound {
+		t.Errorf("expected ErrLogSubsystemNotFound, got %v", err)
+	}
+}
+
+func TestSetLogLevelRevert(t *testing.T) {
+	lService := SetupLogger(config.Info, "LogLevels Revert TestCase", "Service")
+
+	err := SetLogLevel("LogLevels Revert TestCase", "Service", config.Trace, 50*time.Millisecond)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if lService.Logger.GetLevel() != logrus.TraceLevel {
+		t.Fatalf("expected trace level, got %s", lService.Logger.GetLevel())
+	}
+
+	time.Sleep(200 * time.Millisecond)
+
+	_, levels := GetLogLevels("LogLevels Revert TestCase")
+	if levels["Service"] != config.Info {
+		t.Errorf("expected level to be restored to info, got %s", levels["Service"])
+	}
+}
@@ -0,0 +1,116 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/sha256"
+	"fmt"
+	"time"
+)
+
+// MerkleLeafHash returns the RFC 6962 hash of a Merkle tree leaf: SHA-256(0x00 || data).
+func MerkleLeafHash(data []byte) []byte {
+	h := sha256.Sum256(append([]byte{0x00}, data...))
+	return h[:]
+}
+
+func merkleNodeHash(left, right []byte) []byte {
+	h := sha256.New()
+	h.Write([]byte{0x01})
+	h.Write(left)
+	h.Write(right)
+	return h.Sum(nil)
+}
+
+// MerkleRootHash returns the RFC 6962 Merkle tree hash of the leaf hashes. The root of an empty tree is the hash of
+// the empty string.
+func MerkleRootHash(leaves [][]byte) []byte {
+	switch len(leaves) {
+	case 0:
+		h := sha256.Sum256(nil)
+		return h[:]
+	case 1:
+		return leaves[0]
+	}
+
+	k := largestPowerOfTwoBelow(len(leaves))
+	return merkleNodeHash(MerkleRootHash(leaves[:k]), MerkleRootHash(leaves[k:]))
+}
+
+// MerkleInclusionProof returns the audit path of the leaf at index in the tree formed by the leaf hashes
+// (RFC 6962 section 2.1.1).
+func MerkleInclusionProof(leaves [][]byte, index int) ([][]byte, error) {
+	if index < 0 || index >= len(leaves) {
+		return nil, fmt.Errorf("leaf index %d out of range for a tree of size %d", index, len(leaves))
+	}
+
+	return merkleAuditPath(leaves, index), nil
+}
+
+func merkleAuditPath(leaves [][]byte, index int) [][]byte {
+	if len(leaves) <= 1 {
+		return [][]byte{}
+	}
+
+	k := largestPowerOfTwoBelow(len(leaves))
+	if index < k {
+		return append(merkleAuditPath(leaves[:k], index), MerkleRootHash(leaves[k:]))
+	}
+
+	return append(merkleAuditPath(leaves[k:], index-k), MerkleRootHash(leaves[:k]))
+}
+
+// VerifyMerkleInclusion checks the audit path proves the leaf at index is included in the tree of the given size and
+// root hash (RFC 9162 section 2.1.3.2).
+func VerifyMerkleInclusion(leafHash []byte, index, treeSize int, auditPath [][]byte, rootHash []byte) bool {
+	if index < 0 || index >= treeSize {
+		return false
+	}
+
+	fn, sn := index, treeSize-1
+	r := leafHash
+	for _, p := range auditPath {
+		if sn == 0 {
+			return false
+		}
+
+		if fn%2 == 1 || fn == sn {
+			r = merkleNodeHash(p, r)
+			for fn%2 == 0 && fn != 0 {
+				fn >>= 1
+				sn >>= 1
+			}
+		} else {
+			r = merkleNodeHash(r, p)
+		}
+		fn >>= 1
+		sn >>= 1
+	}
+
+	return sn == 0 && bytes.Equal(r, rootHash)
+}
+
+// IssuanceLogChainHash links a log entry with the previous one: SHA-256(previous chain hash || leaf hash).
+func IssuanceLogChainHash(prevChainHash, leafHash []byte) []byte {
+	h := sha256.New()
+	h.Write(prevChainHash)
+	h.Write(leafHash)
+	return h.Sum(nil)
+}
+
+// IssuanceLogEntryID returns the storage ID of the entry at index of the issuance log of the CA.
+func IssuanceLogEntryID(caID string, index int) string {
+	return fmt.Sprintf("%s:%d", caID, index)
+}
+
+// IssuanceLogTreeHeadMessage returns the message signed by the CA for a tree head of its issuance log.
+func IssuanceLogTreeHeadMessage(caID string, treeSize int, rootHash, chainHash string, timestamp time.Time) []byte {
+	return []byte(fmt.Sprintf("lamassu-issuance-log-v1\n%s\n%d\n%s\n%s\n%d", caID, treeSize, rootHash, chainHash, timestamp.UnixMilli()))
+}
+
+func largestPowerOfTwoBelow(n int) int {
+	k := 1
+	for k<<1 < n {
+		k <<= 1
+	}
+	return k
+}
@@ -0,0 +1,70 @@
+package helpers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"testing"
+)
+
+func merkleTestLeaves(n int) [][]byte {
+	leaves := [][]byte{}
+	for i := 0; i < n; i++ {
+		leaves = append(leaves, MerkleLeafHash([]byte(fmt.Sprintf("certificate-%d", i))))
+	}
+	return leaves
+}
+
+func TestMerkleRootHash(t *testing.T) {
+	empty := sha256.Sum256(nil)
+	if hex.EncodeToString(MerkleRootHash(nil)) != hex.EncodeToString(empty[:]) {
+		t.Errorf("unexpected empty tree root")
+	}
+
+	leaves := merkleTestLeaves(3)
+	expected := merkleNodeHash(merkleNodeHash(leaves[0], leaves[1]), leaves[2])
+	if hex.EncodeToString(MerkleRootHash(leaves)) != hex.EncodeToString(expected) {
+		t.Errorf("unexpected root for a tree of size 3")
+	}
+
+	// RFC 6962 leaf hash of the empty string
+	if hex.EncodeToString(MerkleLeafHash([]byte{})) != "6e340b9cffb37a989ca544e6bb780a2c78901d3fb33738768511a30617afa01d" {
+		t.Errorf("unexpected leaf hash")
+	}
+}
+
+func TestMerkleInclusionProof(t *testing.T) {
+	for size := 1; size <= 17; size++ {
+		leaves := merkleTestLeaves(size)
+		root := MerkleRootHash(leaves)
+
+		for index := 0; index < size; index++ {
+			proof, err := MerkleInclusionProof(leaves, index)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !VerifyMerkleInclusion(leaves[index], index, size, proof, root) {
+				t.Fatalf("proof of leaf %d in tree of size %d does not verify", index, size)
+			}
+
+			if size > 1 && VerifyMerkleInclusion(leaves[(index+1)%size], index, size, proof, root) {
+				t.Fatalf("proof of leaf %d in tree of size %d verifies another leaf", index, size)
+			}
+		}
+	}
+
+	if _, err := MerkleInclusionProof(merkleTestLeaves(2), 2); err == nil {
+		t.Errorf("expected out of range index to fail")
+	}
+}
+
+func TestIssuanceLogChainHash(t *testing.T) {
+	leaves := merkleTestLeaves(2)
+	first := IssuanceLogChainHash(nil, leaves[0])
+	second := IssuanceLogChainHash(first, leaves[1])
+
+	if hex.EncodeToString(second) == hex.EncodeToString(IssuanceLogChainHash(nil, leaves[1])) {
+		t.Errorf("chain hash does not depend on the previous entry")
+	}
+}
@@ -0,0 +1,53 @@
+package helpers
+
+import (
+	"encoding/csv"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// deviceIDCSVHeaders are the column names skipped if found in the first row of a preregistration CSV.
+var deviceIDCSVHeaders = []string{"id", "device_id", "serial_number", "cn", "common_name"}
+
+// ParseDeviceIDsCSV reads the device IDs (serial numbers or CommonNames) from the first column of a CSV. Blank
+// values and a header row are skipped.
+func ParseDeviceIDsCSV(r io.Reader) ([]string, error) {
+	reader := csv.NewReader(r)
+	reader.FieldsPerRecord = -1
+	reader.TrimLeadingSpace = true
+
+	ids := []string{}
+	for row := 0; ; row++ {
+		record, err := reader.Read()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("could not read CSV: %w", err)
+		}
+
+		id := strings.TrimSpace(record[0])
+		if id == "" {
+			continue
+		}
+
+		if row == 0 && isDeviceIDCSVHeader(id) {
+			continue
+		}
+
+		ids = append(ids, id)
+	}
+
+	return ids, nil
+}
+
+func isDeviceIDCSVHeader(value string) bool {
+	for _, header := range deviceIDCSVHeaders {
+		if strings.EqualFold(value, header) {
+			return true
+		}
+	}
+
+	return false
+}
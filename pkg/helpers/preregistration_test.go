@@ -0,0 +1,62 @@
+package helpers
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseDeviceIDsCSV(t *testing.T) {
+	tcs := []struct {
+		name     string
+		csv      string
+		expected []string
+		err      bool
+	}{
+		{
+			name:     "OK/SingleColumn",
+			csv:      "sn-001\nsn-002\n",
+			expected: []string{"sn-001", "sn-002"},
+		},
+		{
+			name:     "OK/HeaderAndExtraColumns",
+			csv:      "serial_number,location\nsn-001,plant-a\n sn-002 ,plant-b\n",
+			expected: []string{"sn-001", "sn-002"},
+		},
+		{
+			name:     "OK/BlankValues",
+			csv:      "sn-001\n\n,plant-a\nsn-002",
+			expected: []string{"sn-001", "sn-002"},
+		},
+		{
+			name:     "OK/HeaderOnlyInFirstRow",
+			csv:      "sn-001\nid\n",
+			expected: []string{"sn-001", "id"},
+		},
+		{
+			name: "Err/InvalidCSV",
+			csv:  "\"sn-001\n",
+			err:  true,
+		},
+	}
+
+	for _, tc := range tcs {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, err := ParseDeviceIDsCSV(strings.NewReader(tc.csv))
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected error. Got none")
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if !reflect.DeepEqual(ids, tc.expected) {
+				t.Fatalf("expected %v, got %v", tc.expected, ids)
+			}
+		})
+	}
+}
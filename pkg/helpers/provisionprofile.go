@@ -0,0 +1,192 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+	"strings"
+	"text/template"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ProvisionTemplateSubject exposes the subject of a certificate (or CSR) to the provisioning templates
+// using the short attribute names (i.e. "{{.CSR.Subject.OU}}").
+type ProvisionTemplateSubject struct {
+	CN string
+	O  string
+	OU string
+	C  string
+	ST string
+	L  string
+}
+
+type ProvisionTemplateCSR struct {
+	Subject        ProvisionTemplateSubject
+	DNSNames       []string
+	EmailAddresses []string
+}
+
+type ProvisionTemplateCertificate struct {
+	Subject      ProvisionTemplateSubject
+	Issuer       ProvisionTemplateSubject
+	SerialNumber string
+}
+
+type ProvisionTemplateDMS struct {
+	ID   string
+	Name string
+}
+
+// DeviceProvisionTemplateContext is the enrollment context available to the templates of a DMS device provisioning profile.
+// ClientCertificate is only set when the device authenticated with a client certificate.
+type DeviceProvisionTemplateContext struct {
+	DeviceID          string
+	DMS               ProvisionTemplateDMS
+	CSR               ProvisionTemplateCSR
+	ClientCertificate *ProvisionTemplateCertificate
+}
+
+func provisionTemplateSubject(name pkix.Name) ProvisionTemplateSubject {
+	subject := PkixNameToSubject(name)
+	return ProvisionTemplateSubject{
+		CN: subject.CommonName,
+		O:  subject.Organization,
+		OU: subject.OrganizationUnit,
+		C:  subject.Country,
+		ST: subject.State,
+		L:  subject.Locality,
+	}
+}
+
+func NewDeviceProvisionTemplateContext(dms *models.DMS, csr *x509.CertificateRequest, clientCert *x509.Certificate) DeviceProvisionTemplateContext {
+	tmplCtx := DeviceProvisionTemplateContext{
+		DeviceID: csr.Subject.CommonName,
+		DMS: ProvisionTemplateDMS{
+			ID:   dms.ID,
+			Name: dms.Name,
+		},
+		CSR: ProvisionTemplateCSR{
+			Subject:        provisionTemplateSubject(csr.Subject),
+			DNSNames:       csr.DNSNames,
+			EmailAddresses: csr.EmailAddresses,
+		},
+	}
+
+	if clientCert != nil {
+		tmplCtx.ClientCertificate = &ProvisionTemplateCertificate{
+			Subject:      provisionTemplateSubject(clientCert.Subject),
+			Issuer:       provisionTemplateSubject(clientCert.Issuer),
+			SerialNumber: SerialNumberToString(clientCert.SerialNumber),
+		}
+	}
+
+	return tmplCtx
+}
+
+// RenderDeviceProvisionProfile renders the icon, icon color, tags and (string) metadata values of the profile as Go templates.
+// Values without template actions are copied as is. Tags rendered to an empty string are dropped.
+func RenderDeviceProvisionProfile(profile models.DeviceProvisionProfile, tmplCtx DeviceProvisionTemplateContext) (models.DeviceProvisionProfile, error) {
+	render := func(value string) (string, error) {
+		return renderProvisionTemplate(value, &tmplCtx)
+	}
+
+	return walkDeviceProvisionProfile(profile, render)
+}
+
+// ValidateDeviceProvisionProfile checks that every templated value of the profile can be parsed.
+func ValidateDeviceProvisionProfile(profile models.DeviceProvisionProfile) error {
+	_, err := walkDeviceProvisionProfile(profile, func(value string) (string, error) {
+		if !strings.Contains(value, "{{") {
+			return value, nil
+		}
+		_, err := template.New("").Option("missingkey=error").Parse(value)
+		return value, err
+	})
+
+	return err
+}
+
+func renderProvisionTemplate(value string, data any) (string, error) {
+	if !strings.Contains(value, "{{") {
+		return value, nil
+	}
+
+	tmpl, err := template.New("").Option("missingkey=error").Parse(value)
+	if err != nil {
+		return "", fmt.Errorf("could not parse template '%s': %w", value, err)
+	}
+
+	var buf bytes.Buffer
+	err = tmpl.Execute(&buf, data)
+	if err != nil {
+		return "", fmt.Errorf("could not render template '%s': %w", value, err)
+	}
+
+	return buf.String(), nil
+}
+
+func walkDeviceProvisionProfile(profile models.DeviceProvisionProfile, fn func(string) (string, error)) (models.DeviceProvisionProfile, error) {
+	var err error
+	out := models.DeviceProvisionProfile{}
+
+	out.Icon, err = fn(profile.Icon)
+	if err != nil {
+		return out, err
+	}
+
+	out.IconColor, err = fn(profile.IconColor)
+	if err != nil {
+		return out, err
+	}
+
+	out.Tags = []string{}
+	for _, tag := range profile.Tags {
+		rendered, err := fn(tag)
+		if err != nil {
+			return out, err
+		}
+
+		if rendered != "" {
+			out.Tags = append(out.Tags, rendered)
+		}
+	}
+
+	metadata, err := walkMetadataValue(profile.Metadata, fn)
+	if err != nil {
+		return out, err
+	}
+
+	out.Metadata, _ = metadata.(map[string]any)
+	return out, nil
+}
+
+func walkMetadataValue(value any, fn func(string) (string, error)) (any, error) {
+	switch v := value.(type) {
+	case string:
+		return fn(v)
+	case map[string]any:
+		out := make(map[string]any, len(v))
+		for key, elem := range v {
+			rendered, err := walkMetadataValue(elem, fn)
+			if err != nil {
+				return nil, err
+			}
+			out[key] = rendered
+		}
+		return out, nil
+	case []any:
+		out := make([]any, 0, len(v))
+		for _, elem := range v {
+			rendered, err := walkMetadataValue(elem, fn)
+			if err != nil {
+				return nil, err
+			}
+			out = append(out, rendered)
+		}
+		return out, nil
+	default:
+		return v, nil
+	}
+}
@@ -0,0 +1,74 @@
+package helpers
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"reflect"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestRenderDeviceProvisionProfile(t *testing.T) {
+	dms := &models.DMS{ID: "dms-1", Name: "Factory DMS"}
+	csr := &x509.CertificateRequest{
+		Subject: pkix.Name{
+			CommonName:         "device-1",
+			OrganizationalUnit: []string{"line-3"},
+		},
+	}
+
+	profile := models.DeviceProvisionProfile{
+		Icon:      "CgSmartphoneChip",
+		IconColor: "#25ee32-#222222",
+		Tags:      []string{"iot", "{{.CSR.Subject.OU}}", "{{if .ClientCertificate}}mtls{{end}}"},
+		Metadata: map[string]any{
+			"registered-by": "{{.DMS.Name}}",
+			"nested": map[string]any{
+				"device": "{{.DeviceID}}",
+			},
+			"count": 3,
+		},
+	}
+
+	rendered, err := RenderDeviceProvisionProfile(profile, NewDeviceProvisionTemplateContext(dms, csr, nil))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(rendered.Tags, []string{"iot", "line-3"}) {
+		t.Errorf("unexpected tags: %v", rendered.Tags)
+	}
+
+	expectedMeta := map[string]any{
+		"registered-by": "Factory DMS",
+		"nested": map[string]any{
+			"device": "device-1",
+		},
+		"count": 3,
+	}
+	if !reflect.DeepEqual(rendered.Metadata, expectedMeta) {
+		t.Errorf("unexpected metadata: %v", rendered.Metadata)
+	}
+
+	if rendered.Icon != profile.Icon || rendered.IconColor != profile.IconColor {
+		t.Errorf("non templated values must be copied as is")
+	}
+
+	_, err = RenderDeviceProvisionProfile(models.DeviceProvisionProfile{Tags: []string{"{{.CSR.Subject.Unknown}}"}}, NewDeviceProvisionTemplateContext(dms, csr, nil))
+	if err == nil {
+		t.Errorf("expected error while rendering unknown field")
+	}
+}
+
+func TestValidateDeviceProvisionProfile(t *testing.T) {
+	err := ValidateDeviceProvisionProfile(models.DeviceProvisionProfile{Icon: "{{.DeviceID}}"})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	err = ValidateDeviceProvisionProfile(models.DeviceProvisionProfile{Metadata: map[string]any{"k": "{{.DeviceID"}})
+	if err == nil {
+		t.Errorf("expected error with malformed template")
+	}
+}
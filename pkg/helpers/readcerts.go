@@ -13,6 +13,9 @@ import (
 	"fmt"
 	"math/big"
 	"os"
+	"strings"
+
+	"go.mozilla.org/pkcs7"
 )
 
 func ReadCertificateFromFile(filePath string) (*x509.Certificate, error) {
@@ -63,11 +66,74 @@ func ParsePrivateKey(privKeyBytes []byte) (interface{}, error) {
 	return nil, errors.New("tls: failed to parse private key")
 }
 
+// ParsePEMBundle parses a PEM bundle with certificates and, optionally, a private key. The certificates are returned
+// in the order they appear in the bundle. The key is nil if the bundle doesn't include one.
+func ParsePEMBundle(bundle []byte) ([]*x509.Certificate, any, error) {
+	certs := []*x509.Certificate{}
+	var key any
+
+	for {
+		var block *pem.Block
+		block, bundle = pem.Decode(bundle)
+		if block == nil {
+			break
+		}
+
+		switch block.Type {
+		case "CERTIFICATE":
+			cert, err := x509.ParseCertificate(block.Bytes)
+			if err != nil {
+				return nil, nil, fmt.Errorf("could not parse certificate: %w", err)
+			}
+			certs = append(certs, cert)
+		case "PRIVATE KEY", "RSA PRIVATE KEY", "EC PRIVATE KEY":
+			if key != nil {
+				return nil, nil, errors.New("bundle includes more than one private key")
+			}
+
+			var err error
+			key, err = ParsePrivateKey(pem.EncodeToMemory(block))
+			if err != nil {
+				return nil, nil, err
+			}
+		default:
+			return nil, nil, fmt.Errorf("unsupported PEM block type %s", block.Type)
+		}
+	}
+
+	if len(certs) == 0 {
+		return nil, nil, errors.New("bundle does not include any certificate")
+	}
+
+	return certs, key, nil
+}
+
 func CertificateToPEM(c *x509.Certificate) string {
 	pemCert := pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.Raw})
 	return string(pemCert)
 }
 
+// CertificatesToPEMBundle encodes the certificates as a PEM bundle, one certificate after the other.
+func CertificatesToPEMBundle(certs []*x509.Certificate) []byte {
+	certsPEM := []string{}
+	for _, cert := range certs {
+		certsPEM = append(certsPEM, CertificateToPEM(cert))
+	}
+
+	return []byte(strings.Join(certsPEM, "\n"))
+}
+
+// CertificatesToPKCS7 encodes the certificates as a degenerate (certs-only) PKCS#7 SignedData, as used by the EST
+// cacerts responses (RFC 7030 4.1.3).
+func CertificatesToPKCS7(certs []*x509.Certificate) ([]byte, error) {
+	cb := []byte{}
+	for _, cert := range certs {
+		cb = append(cb, cert.Raw...)
+	}
+
+	return pkcs7.DegenerateCertificate(cb)
+}
+
 func PrivateKeyToPEM(key any) (string, error) {
 	b, err := x509.MarshalPKCS8PrivateKey(key)
 	if err != nil {
@@ -216,3 +216,51 @@ func TestGenerateSelfSignedCertificateIntegration(t *testing.T) {
 		t.Error("Parsed certificate has incorrect public key")
 	}
 }
+
+func TestParsePEMBundle(t *testing.T) {
+	key, err := GenerateRSAKey(2048)
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	cert, err := GenerateSelfSignedCertificate(key, "bundle")
+	if err != nil {
+		t.Fatalf("could not generate certificate: %s", err)
+	}
+
+	keyPEM, err := PrivateKeyToPEM(key)
+	if err != nil {
+		t.Fatalf("could not encode key: %s", err)
+	}
+
+	certs, parsedKey, err := ParsePEMBundle([]byte(CertificateToPEM(cert) + CertificateToPEM(cert) + keyPEM))
+	if err != nil {
+		t.Fatalf("ParsePEMBundle failed for a valid bundle: %s", err)
+	}
+
+	if len(certs) != 2 {
+		t.Errorf("ParsePEMBundle should have returned 2 certificates, got %d", len(certs))
+	}
+
+	if rsaKey, ok := parsedKey.(*rsa.PrivateKey); !ok || !rsaKey.Equal(key) {
+		t.Error("ParsePEMBundle returned an unexpected private key")
+	}
+
+	// Bundles without private key
+	_, parsedKey, err = ParsePEMBundle([]byte(CertificateToPEM(cert)))
+	if err != nil || parsedKey != nil {
+		t.Errorf("ParsePEMBundle should have returned no key and no error, got key %v and error %v", parsedKey, err)
+	}
+
+	// Bundles without certificates
+	_, _, err = ParsePEMBundle([]byte(keyPEM))
+	if err == nil {
+		t.Error("ParsePEMBundle should have returned an error for a bundle without certificates")
+	}
+
+	// Bundles with two keys
+	_, _, err = ParsePEMBundle([]byte(CertificateToPEM(cert) + keyPEM + keyPEM))
+	if err == nil {
+		t.Error("ParsePEMBundle should have returned an error for a bundle with two private keys")
+	}
+}
@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto/x509"
+	"encoding/asn1"
+	"encoding/hex"
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ParseOID parses a dotted object identifier (i.e. "1.3.6.1.4.1.99999.1")
+func ParseOID(oid string) (asn1.ObjectIdentifier, error) {
+	parts := strings.Split(oid, ".")
+	if len(parts) < 2 {
+		return nil, fmt.Errorf("invalid OID '%s'", oid)
+	}
+
+	identifier := asn1.ObjectIdentifier{}
+	for _, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil || n < 0 {
+			return nil, fmt.Errorf("invalid OID '%s'", oid)
+		}
+		identifier = append(identifier, n)
+	}
+
+	return identifier, nil
+}
+
+// ValidateSecureElementVerification checks that the extension OID and the allow-list URL are valid when the verification is enabled.
+func ValidateSecureElementVerification(settings models.SecureElementVerification) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if _, err := ParseOID(settings.ExtensionOID); err != nil {
+		return err
+	}
+
+	u, err := url.Parse(settings.AllowListURL)
+	if err != nil || u.Host == "" || (u.Scheme != "https" && u.Scheme != "http") {
+		return fmt.Errorf("invalid secure element allow-list URL '%s'", settings.AllowListURL)
+	}
+
+	return nil
+}
+
+// GetSecureElementIdentity extracts the secure element serial number (or certificate) from the CSR extension.
+// Returns a nil identity if the CSR does not include the extension. If a secure element certificate is presented,
+// its public key must be the CSR public key.
+func GetSecureElementIdentity(csr *x509.CertificateRequest, extensionOID string) (*models.SecureElementIdentity, error) {
+	oid, err := ParseOID(extensionOID)
+	if err != nil {
+		return nil, err
+	}
+
+	var value []byte
+	found := false
+	for _, ext := range csr.Extensions {
+		if ext.Id.Equal(oid) {
+			value = ext.Value
+			found = true
+			break
+		}
+	}
+
+	if !found {
+		return nil, nil
+	}
+
+	identity := &models.SecureElementIdentity{
+		PublicKeySHA256: PublicKeyFingerprint(csr.RawSubjectPublicKeyInfo),
+	}
+
+	if seCert, err := x509.ParseCertificate(value); err == nil {
+		if !bytes.Equal(seCert.RawSubjectPublicKeyInfo, csr.RawSubjectPublicKeyInfo) {
+			return nil, fmt.Errorf("secure element certificate %s public key does not match the CSR public key", SerialNumberToString(seCert.SerialNumber))
+		}
+
+		identity.SerialNumber = SerialNumberToString(seCert.SerialNumber)
+		identity.Certificate = CertificateToPEM(seCert)
+		return identity, nil
+	}
+
+	var serial string
+	if rest, err := asn1.Unmarshal(value, &serial); err == nil && len(rest) == 0 {
+		identity.SerialNumber = serial
+	} else {
+		var octets []byte
+		if rest, err := asn1.Unmarshal(value, &octets); err == nil && len(rest) == 0 {
+			identity.SerialNumber = hex.EncodeToString(octets)
+		}
+	}
+
+	if identity.SerialNumber == "" {
+		return nil, fmt.Errorf("could not decode secure element extension %s", extensionOID)
+	}
+
+	return identity, nil
+}
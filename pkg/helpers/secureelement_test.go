@@ -0,0 +1,105 @@
+package helpers
+
+import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+const testSecureElementOID = "1.3.6.1.4.1.99999.1"
+
+func secureElementCSR(t *testing.T, value []byte) *x509.CertificateRequest {
+	key, err := GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	extensions := []pkix.Extension{}
+	if value != nil {
+		oid, _ := ParseOID(testSecureElementOID)
+		extensions = append(extensions, pkix.Extension{Id: oid, Value: value})
+	}
+
+	csr, err := GenerateCertificateRequestWithExtensions(models.Subject{CommonName: "device-1"}, extensions, key)
+	if err != nil {
+		t.Fatalf("could not generate CSR: %s", err)
+	}
+
+	return csr
+}
+
+func TestGetSecureElementIdentityFromString(t *testing.T) {
+	value, _ := asn1.Marshal("SE-0001")
+	identity, err := GetSecureElementIdentity(secureElementCSR(t, value), testSecureElementOID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if identity.SerialNumber != "SE-0001" || len(identity.PublicKeySHA256) != 64 {
+		t.Errorf("unexpected identity: %+v", identity)
+	}
+}
+
+func TestGetSecureElementIdentityFromOctetString(t *testing.T) {
+	value, _ := asn1.Marshal([]byte{0x01, 0xab})
+	identity, err := GetSecureElementIdentity(secureElementCSR(t, value), testSecureElementOID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if identity.SerialNumber != "01ab" {
+		t.Errorf("unexpected serial number: %s", identity.SerialNumber)
+	}
+}
+
+func TestGetSecureElementIdentityMissingExtension(t *testing.T) {
+	identity, err := GetSecureElementIdentity(secureElementCSR(t, nil), testSecureElementOID)
+	if err != nil || identity != nil {
+		t.Errorf("expected no identity, got %+v (%v)", identity, err)
+	}
+}
+
+func TestGetSecureElementIdentityCertificateKeyMismatch(t *testing.T) {
+	seKey, err := GenerateECDSAKey(elliptic.P256())
+	if err != nil {
+		t.Fatalf("could not generate key: %s", err)
+	}
+
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(42),
+		Subject:      pkix.Name{CommonName: "secure-element"},
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, template, &seKey.PublicKey, seKey)
+	if err != nil {
+		t.Fatalf("could not create certificate: %s", err)
+	}
+
+	_, err = GetSecureElementIdentity(secureElementCSR(t, der), testSecureElementOID)
+	if err == nil {
+		t.Errorf("expected error for secure element certificate not matching the CSR key")
+	}
+}
+
+func TestValidateSecureElementVerification(t *testing.T) {
+	if err := ValidateSecureElementVerification(models.SecureElementVerification{Enabled: true, ExtensionOID: "1.x", AllowListURL: "https://allow.list"}); err == nil {
+		t.Errorf("expected error for invalid OID")
+	}
+
+	if err := ValidateSecureElementVerification(models.SecureElementVerification{Enabled: true, ExtensionOID: testSecureElementOID, AllowListURL: "allow.list"}); err == nil {
+		t.Errorf("expected error for invalid URL")
+	}
+
+	if err := ValidateSecureElementVerification(models.SecureElementVerification{Enabled: true, ExtensionOID: testSecureElementOID, AllowListURL: "https://allow.list/v1/check"}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -0,0 +1,43 @@
+package helpers
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ValidateSigningProfileURLs checks that the OCSP servers and CRL distribution points of the signing profile are
+// absolute http or https URLs, as they are embedded in the signed certificates.
+func ValidateSigningProfileURLs(profile models.SigningProfile) error {
+	for _, ocspServer := range profile.OCSPServers {
+		if err := validateValidationURL(ocspServer); err != nil {
+			return fmt.Errorf("invalid OCSP server: %s", err)
+		}
+	}
+
+	for _, crlDP := range profile.CRLDistributionPoints {
+		if err := validateValidationURL(crlDP); err != nil {
+			return fmt.Errorf("invalid CRL distribution point: %s", err)
+		}
+	}
+
+	return nil
+}
+
+func validateValidationURL(rawURL string) error {
+	parsed, err := url.Parse(rawURL)
+	if err != nil {
+		return fmt.Errorf("could not parse '%s': %s", rawURL, err)
+	}
+
+	if parsed.Scheme != "http" && parsed.Scheme != "https" {
+		return fmt.Errorf("'%s' must use the http or https scheme", rawURL)
+	}
+
+	if parsed.Host == "" {
+		return fmt.Errorf("'%s' has no host", rawURL)
+	}
+
+	return nil
+}
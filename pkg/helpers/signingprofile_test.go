@@ -0,0 +1,56 @@
+package helpers
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestValidateSigningProfileURLs(t *testing.T) {
+	testcases := []struct {
+		name      string
+		profile   models.SigningProfile
+		expectErr bool
+	}{
+		{
+			name: "OK",
+			profile: models.SigningProfile{
+				OCSPServers:           []string{"http://ocsp.lamassu.io", "https://eu.ocsp.lamassu.io/ocsp"},
+				CRLDistributionPoints: []string{"https://eu.crl.lamassu.io/crl/root.crl"},
+			},
+		},
+		{
+			name:    "OK/Empty",
+			profile: models.SigningProfile{},
+		},
+		{
+			name:      "Err/OCSPScheme",
+			profile:   models.SigningProfile{OCSPServers: []string{"ldap://ocsp.lamassu.io"}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/OCSPRelative",
+			profile:   models.SigningProfile{OCSPServers: []string{"/ocsp"}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/CRLNoHost",
+			profile:   models.SigningProfile{CRLDistributionPoints: []string{"http:///crl/root.crl"}},
+			expectErr: true,
+		},
+		{
+			name:      "Err/CRLMalformed",
+			profile:   models.SigningProfile{CRLDistributionPoints: []string{"http://crl.lamassu.io/%zz"}},
+			expectErr: true,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			err := ValidateSigningProfileURLs(tc.profile)
+			if tc.expectErr != (err != nil) {
+				t.Errorf("unexpected error result: %v", err)
+			}
+		})
+	}
+}
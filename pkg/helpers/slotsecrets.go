@@ -0,0 +1,149 @@
+package helpers
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+const defaultSymmetricKeyLength = 32
+
+// ValidateSymmetricSlotProfile checks the profile can be used to generate the secrets of the slot type.
+func ValidateSymmetricSlotProfile(secretType models.CryptoSecretType, profile models.SymmetricSlotProfile) error {
+	if !models.IsSymmetricSlotType(secretType) {
+		return fmt.Errorf("slot type %s is not a symmetric slot type", secretType)
+	}
+
+	if profile.KeyLength < 0 || (profile.KeyLength > 0 && profile.KeyLength < 16) {
+		return fmt.Errorf("key length must be at least 16 bytes")
+	}
+
+	if profile.Validity < 0 || profile.RotateBefore < 0 {
+		return fmt.Errorf("validity and rotate before can not be negative")
+	}
+
+	if secretType == models.SASTokenSlotProfileType {
+		if profile.ResourceURI == "" {
+			return fmt.Errorf("SAS token slots require a resource URI")
+		}
+		if profile.Validity == 0 {
+			return fmt.Errorf("SAS token slots require a validity")
+		}
+	}
+
+	return nil
+}
+
+// GenerateSymmetricSlotSecret generates a new version of a PSK or SAS token slot. SAS tokens are signed with the
+// generated key, so devices holding the key can renew their own tokens.
+func GenerateSymmetricSlotSecret(secretType models.CryptoSecretType, profile models.SymmetricSlotProfile, now time.Time) (*models.SymmetricSecret, error) {
+	if err := ValidateSymmetricSlotProfile(secretType, profile); err != nil {
+		return nil, err
+	}
+
+	keyLength := profile.KeyLength
+	if keyLength == 0 {
+		keyLength = defaultSymmetricKeyLength
+	}
+
+	key := make([]byte, keyLength)
+	if _, err := rand.Read(key); err != nil {
+		return nil, fmt.Errorf("could not generate key: %w", err)
+	}
+
+	secret := &models.SymmetricSecret{
+		Key:       base64.StdEncoding.EncodeToString(key),
+		CreatedAt: now,
+	}
+
+	if profile.Validity > 0 {
+		secret.ExpiresAt = now.Add(time.Duration(profile.Validity))
+	}
+
+	if secretType == models.SASTokenSlotProfileType {
+		secret.Token = GenerateSASToken(profile.ResourceURI, key, secret.ExpiresAt)
+	}
+
+	return secret, nil
+}
+
+// GenerateSASToken returns a Shared Access Signature token, as used by Azure IoT Hub, granting access to the
+// resource URI until expiry.
+func GenerateSASToken(resourceURI string, key []byte, expiry time.Time) string {
+	encodedURI := url.QueryEscape(resourceURI)
+	se := strconv.FormatInt(expiry.Unix(), 10)
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(encodedURI + "\n" + se))
+	sig := base64.StdEncoding.EncodeToString(mac.Sum(nil))
+
+	return fmt.Sprintf("SharedAccessSignature sr=%s&sig=%s&se=%s", encodedURI, url.QueryEscape(sig), se)
+}
+
+// SymmetricSlotSecretRotationDue reports whether the secret expires within the rotate before window of the profile.
+// Secrets without expiration are never rotated.
+func SymmetricSlotSecretRotationDue(secret models.SymmetricSecret, profile models.SymmetricSlotProfile, now time.Time) bool {
+	if secret.ExpiresAt.IsZero() {
+		return false
+	}
+
+	return !now.Add(time.Duration(profile.RotateBefore)).Before(secret.ExpiresAt)
+}
+
+// DecodeSymmetricSecret decodes a version of a symmetric slot. The versions are stored as generic values, so they
+// are decoded as maps once read from the storage engine.
+func DecodeSymmetricSecret(secret any) (*models.SymmetricSecret, error) {
+	if s, ok := secret.(models.SymmetricSecret); ok {
+		return &s, nil
+	}
+
+	b, err := json.Marshal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	var s models.SymmetricSecret
+	if err := json.Unmarshal(b, &s); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// EncryptToPublicKey encrypts the payload into a compact JWE (A256GCM) for the holder of the private key. RSA keys
+// use RSA-OAEP-256 and EC keys use ECDH-ES+A256KW.
+func EncryptToPublicKey(payload []byte, pub crypto.PublicKey) (string, error) {
+	var alg jose.KeyAlgorithm
+	switch pub.(type) {
+	case *rsa.PublicKey:
+		alg = jose.RSA_OAEP_256
+	case *ecdsa.PublicKey:
+		alg = jose.ECDH_ES_A256KW
+	default:
+		return "", fmt.Errorf("unsupported public key type %T", pub)
+	}
+
+	encrypter, err := jose.NewEncrypter(jose.A256GCM, jose.Recipient{Algorithm: alg, Key: pub}, nil)
+	if err != nil {
+		return "", fmt.Errorf("could not create encrypter: %w", err)
+	}
+
+	jwe, err := encrypter.Encrypt(payload)
+	if err != nil {
+		return "", fmt.Errorf("could not encrypt payload: %w", err)
+	}
+
+	return jwe.CompactSerialize()
+}
@@ -0,0 +1,168 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/json"
+	"net/url"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestGenerateSymmetricSlotSecret(t *testing.T) {
+	now := time.Now()
+
+	testcases := []struct {
+		name       string
+		secretType models.CryptoSecretType
+		profile    models.SymmetricSlotProfile
+		keyLength  int
+		wantErr    bool
+	}{
+		{name: "PSK default length", secretType: models.PSKSlotProfileType, keyLength: 32},
+		{name: "PSK custom length", secretType: models.PSKSlotProfileType, profile: models.SymmetricSlotProfile{KeyLength: 64}, keyLength: 64},
+		{name: "PSK short key", secretType: models.PSKSlotProfileType, profile: models.SymmetricSlotProfile{KeyLength: 8}, wantErr: true},
+		{name: "SAS token", secretType: models.SASTokenSlotProfileType, profile: models.SymmetricSlotProfile{ResourceURI: "hub.azure-devices.net/devices/dev1", Validity: models.TimeDuration(time.Hour)}, keyLength: 32},
+		{name: "SAS token without URI", secretType: models.SASTokenSlotProfileType, profile: models.SymmetricSlotProfile{Validity: models.TimeDuration(time.Hour)}, wantErr: true},
+		{name: "SAS token without validity", secretType: models.SASTokenSlotProfileType, profile: models.SymmetricSlotProfile{ResourceURI: "hub.azure-devices.net/devices/dev1"}, wantErr: true},
+		{name: "x509", secretType: models.X509SlotProfileType, wantErr: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			secret, err := GenerateSymmetricSlotSecret(tc.secretType, tc.profile, now)
+			if tc.wantErr {
+				if err == nil {
+					t.Fatalf("expected error, got %v", secret)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			key, err := base64.StdEncoding.DecodeString(secret.Key)
+			if err != nil || len(key) != tc.keyLength {
+				t.Fatalf("expected a %d bytes key, got %d (%v)", tc.keyLength, len(key), err)
+			}
+
+			if tc.secretType == models.SASTokenSlotProfileType {
+				if secret.Token != GenerateSASToken(tc.profile.ResourceURI, key, secret.ExpiresAt) {
+					t.Fatalf("token is not signed with the slot key")
+				}
+				if !secret.ExpiresAt.Equal(now.Add(time.Hour)) {
+					t.Fatalf("unexpected expiration %s", secret.ExpiresAt)
+				}
+			} else if secret.Token != "" || !secret.ExpiresAt.IsZero() {
+				t.Fatalf("PSK without validity should not expire nor have a token")
+			}
+		})
+	}
+}
+
+func TestGenerateSASToken(t *testing.T) {
+	key := []byte("0123456789abcdef0123456789abcdef")
+	expiry := time.Unix(1700000000, 0)
+
+	token := GenerateSASToken("hub.azure-devices.net/devices/dev1", key, expiry)
+	if !strings.HasPrefix(token, "SharedAccessSignature sr=hub.azure-devices.net%2Fdevices%2Fdev1&sig=") || !strings.HasSuffix(token, "&se=1700000000") {
+		t.Fatalf("unexpected token format %s", token)
+	}
+
+	values, err := url.ParseQuery(strings.TrimPrefix(token, "SharedAccessSignature "))
+	if err != nil {
+		t.Fatalf("could not parse token: %s", err)
+	}
+
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte("hub.azure-devices.net%2Fdevices%2Fdev1\n1700000000"))
+	if values.Get("sig") != base64.StdEncoding.EncodeToString(mac.Sum(nil)) {
+		t.Fatalf("unexpected signature %s", values.Get("sig"))
+	}
+}
+
+func TestSymmetricSlotSecretRotationDue(t *testing.T) {
+	now := time.Now()
+	profile := models.SymmetricSlotProfile{RotateBefore: models.TimeDuration(time.Hour)}
+
+	if SymmetricSlotSecretRotationDue(models.SymmetricSecret{}, profile, now) {
+		t.Errorf("secrets without expiration should not be rotated")
+	}
+
+	if SymmetricSlotSecretRotationDue(models.SymmetricSecret{ExpiresAt: now.Add(2 * time.Hour)}, profile, now) {
+		t.Errorf("secret expiring after the rotation window should not be rotated")
+	}
+
+	if !SymmetricSlotSecretRotationDue(models.SymmetricSecret{ExpiresAt: now.Add(30 * time.Minute)}, profile, now) {
+		t.Errorf("secret expiring within the rotation window should be rotated")
+	}
+
+	if !SymmetricSlotSecretRotationDue(models.SymmetricSecret{ExpiresAt: now.Add(-time.Minute)}, models.SymmetricSlotProfile{}, now) {
+		t.Errorf("expired secret should be rotated")
+	}
+}
+
+func TestDecodeSymmetricSecret(t *testing.T) {
+	secret := models.SymmetricSecret{Key: "a2V5", Token: "token", CreatedAt: time.Unix(1700000000, 0).UTC()}
+
+	var stored any
+	b, _ := json.Marshal(secret)
+	json.Unmarshal(b, &stored)
+
+	for _, s := range []any{secret, stored} {
+		decoded, err := DecodeSymmetricSecret(s)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+		if *decoded != secret {
+			t.Fatalf("expected %v, got %v", secret, decoded)
+		}
+	}
+}
+
+func TestEncryptToPublicKey(t *testing.T) {
+	ecKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	rsaKey, _ := rsa.GenerateKey(rand.Reader, 2048)
+	payload := []byte(`{"key":"c2VjcmV0"}`)
+
+	for name, key := range map[string]any{"EC": ecKey, "RSA": rsaKey} {
+		t.Run(name, func(t *testing.T) {
+			var pub any
+			switch k := key.(type) {
+			case *ecdsa.PrivateKey:
+				pub = &k.PublicKey
+			case *rsa.PrivateKey:
+				pub = &k.PublicKey
+			}
+
+			compact, err := EncryptToPublicKey(payload, pub)
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			jwe, err := jose.ParseEncrypted(compact)
+			if err != nil {
+				t.Fatalf("could not parse JWE: %s", err)
+			}
+
+			decrypted, err := jwe.Decrypt(key)
+			if err != nil {
+				t.Fatalf("could not decrypt JWE: %s", err)
+			}
+
+			if string(decrypted) != string(payload) {
+				t.Fatalf("expected %s, got %s", payload, decrypted)
+			}
+		})
+	}
+}
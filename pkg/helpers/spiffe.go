@@ -0,0 +1,117 @@
+package helpers
+
+import (
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+const spiffeIDMaxLength = 2048
+
+var oidExtensionSubjectAltName = asn1.ObjectIdentifier{2, 5, 29, 17}
+
+// ParseSPIFFEID parses and validates a SPIFFE ID (spiffe://<trust domain>/<path>) as defined by the SPIFFE ID
+// specification: a lowercase trust domain, no port, user info, query or fragment, and a path without empty, "." or
+// ".." segments.
+func ParseSPIFFEID(id string) (*url.URL, error) {
+	if len(id) > spiffeIDMaxLength {
+		return nil, fmt.Errorf("SPIFFE ID exceeds %d bytes", spiffeIDMaxLength)
+	}
+
+	if !strings.HasPrefix(id, "spiffe://") {
+		return nil, fmt.Errorf("SPIFFE ID must use the spiffe scheme")
+	}
+
+	rest := strings.TrimPrefix(id, "spiffe://")
+	trustDomain, path, _ := strings.Cut(rest, "/")
+	if trustDomain == "" {
+		return nil, fmt.Errorf("SPIFFE ID has no trust domain")
+	}
+
+	for _, c := range trustDomain {
+		if !(c >= 'a' && c <= 'z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+			return nil, fmt.Errorf("trust domain %s has invalid character %q", trustDomain, c)
+		}
+	}
+
+	if path != "" || strings.HasSuffix(rest, "/") {
+		for _, segment := range strings.Split(path, "/") {
+			if segment == "" || segment == "." || segment == ".." {
+				return nil, fmt.Errorf("SPIFFE ID path has an invalid segment %q", segment)
+			}
+
+			for _, c := range segment {
+				if !(c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= '0' && c <= '9' || c == '.' || c == '-' || c == '_') {
+					return nil, fmt.Errorf("SPIFFE ID path has invalid character %q", c)
+				}
+			}
+		}
+	}
+
+	spiffeID := &url.URL{Scheme: "spiffe", Host: trustDomain}
+	if path != "" {
+		spiffeID.Path = "/" + path
+	}
+
+	return spiffeID, nil
+}
+
+// SVIDCertificateRequest returns a copy of the CSR whose only SAN is the SPIFFE ID. The CSR may already carry the
+// SPIFFE ID as URI SAN, but no other URI. Other SANs requested in the CSR are dropped.
+func SVIDCertificateRequest(csr *x509.CertificateRequest, spiffeID *url.URL) (*x509.CertificateRequest, error) {
+	for _, uri := range csr.URIs {
+		if uri.String() != spiffeID.String() {
+			return nil, fmt.Errorf("CSR requests URI %s but the SPIFFE ID is %s", uri, spiffeID)
+		}
+	}
+
+	san, err := asn1.Marshal([]asn1.RawValue{
+		{Tag: 6, Class: asn1.ClassContextSpecific, Bytes: []byte(spiffeID.String())},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	svidCSR := *csr
+	svidCSR.URIs = []*url.URL{spiffeID}
+	svidCSR.DNSNames = nil
+	svidCSR.EmailAddresses = nil
+	svidCSR.IPAddresses = nil
+	svidCSR.Extensions = []pkix.Extension{{Id: oidExtensionSubjectAltName, Value: san}}
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(oidExtensionSubjectAltName) {
+			svidCSR.Extensions = append(svidCSR.Extensions, ext)
+		}
+	}
+
+	return &svidCSR, nil
+}
+
+// ValidateX509SVID checks that the certificate is a leaf X509-SVID and returns its SPIFFE ID.
+func ValidateX509SVID(crt *x509.Certificate) (*url.URL, error) {
+	if len(crt.URIs) != 1 {
+		return nil, fmt.Errorf("X509-SVIDs must have exactly one URI SAN, found %d", len(crt.URIs))
+	}
+
+	spiffeID, err := ParseSPIFFEID(crt.URIs[0].String())
+	if err != nil {
+		return nil, err
+	}
+
+	if crt.IsCA {
+		return nil, fmt.Errorf("leaf X509-SVIDs can not be CAs")
+	}
+
+	if crt.KeyUsage&x509.KeyUsageDigitalSignature == 0 {
+		return nil, fmt.Errorf("leaf X509-SVIDs must have the digital signature key usage")
+	}
+
+	if crt.KeyUsage&(x509.KeyUsageCertSign|x509.KeyUsageCRLSign) != 0 {
+		return nil, fmt.Errorf("leaf X509-SVIDs can not have the cert sign or CRL sign key usages")
+	}
+
+	return spiffeID, nil
+}
@@ -0,0 +1,99 @@
+package helpers
+
+import (
+	"crypto/elliptic"
+	"crypto/x509"
+	"net/url"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestParseSPIFFEID(t *testing.T) {
+	var testcases = []struct {
+		id    string
+		valid bool
+	}{
+		{id: "spiffe://example.org/ns/prod/sa/payments", valid: true},
+		{id: "spiffe://example.org", valid: true},
+		{id: "spiffe://my_domain-1.example.org/workload.v2", valid: true},
+		{id: "https://example.org/workload"},
+		{id: "spiffe:///workload"},
+		{id: "spiffe://Example.org/workload"},
+		{id: "spiffe://example.org:8443/workload"},
+		{id: "spiffe://user@example.org/workload"},
+		{id: "spiffe://example.org/workload/"},
+		{id: "spiffe://example.org//workload"},
+		{id: "spiffe://example.org/ns/../workload"},
+		{id: "spiffe://example.org/workload?x=1"},
+		{id: "spiffe://example.org/workload#frag"},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.id, func(t *testing.T) {
+			spiffeID, err := ParseSPIFFEID(tc.id)
+			if tc.valid {
+				if err != nil {
+					t.Fatalf("unexpected error: %s", err)
+				}
+
+				if spiffeID.String() != tc.id {
+					t.Errorf("expected %s, got %s", tc.id, spiffeID)
+				}
+			} else if err == nil {
+				t.Errorf("expected an error")
+			}
+		})
+	}
+}
+
+func TestSVIDCertificateRequest(t *testing.T) {
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	spiffeID, _ := ParseSPIFFEID("spiffe://example.org/workload")
+
+	csr, _ := GenerateCertificateRequest(models.Subject{}, key)
+	svidCSR, err := SVIDCertificateRequest(csr, spiffeID)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(svidCSR.URIs) != 1 || svidCSR.URIs[0].String() != spiffeID.String() {
+		t.Errorf("CSR must request the SPIFFE ID: %v", svidCSR.URIs)
+	}
+
+	sans := 0
+	for _, ext := range svidCSR.Extensions {
+		if ext.Id.Equal(oidExtensionSubjectAltName) {
+			sans++
+		}
+	}
+	if sans != 1 {
+		t.Errorf("CSR must have a single SAN extension, found %d", sans)
+	}
+
+	other, _ := url.Parse("spiffe://example.org/other")
+	csr.URIs = []*url.URL{other}
+	_, err = SVIDCertificateRequest(csr, spiffeID)
+	if err == nil {
+		t.Errorf("CSRs requesting other URIs must be rejected")
+	}
+}
+
+func TestValidateX509SVID(t *testing.T) {
+	spiffeID, _ := url.Parse("spiffe://example.org/workload")
+
+	_, err := ValidateX509SVID(&x509.Certificate{URIs: []*url.URL{spiffeID}, KeyUsage: x509.KeyUsageDigitalSignature})
+	if err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	_, err = ValidateX509SVID(&x509.Certificate{KeyUsage: x509.KeyUsageDigitalSignature})
+	if err == nil {
+		t.Errorf("SVIDs without URI SAN must be rejected")
+	}
+
+	_, err = ValidateX509SVID(&x509.Certificate{URIs: []*url.URL{spiffeID}, KeyUsage: x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign})
+	if err == nil {
+		t.Errorf("SVIDs with cert sign usage must be rejected")
+	}
+}
@@ -0,0 +1,199 @@
+package helpers
+
+import (
+	"bytes"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/asn1"
+	"fmt"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ValidateTPMAttestation checks that the extension OID is valid and that manufacturer CAs are configured when the
+// attestation is enabled.
+func ValidateTPMAttestation(settings models.TPMAttestation) error {
+	if !settings.Enabled {
+		return nil
+	}
+
+	if _, err := ParseOID(settings.ExtensionOID); err != nil {
+		return err
+	}
+
+	if len(settings.ManufacturerCAs) == 0 {
+		return fmt.Errorf("TPM attestation requires at least one manufacturer CA")
+	}
+
+	return nil
+}
+
+// GetTPMAttestationEvidence decodes the TPM attestation evidence from the CSR extension. Returns a nil evidence if the
+// CSR does not include the extension.
+func GetTPMAttestationEvidence(csr *x509.CertificateRequest, extensionOID string) (*models.TPMAttestationEvidence, error) {
+	oid, err := ParseOID(extensionOID)
+	if err != nil {
+		return nil, err
+	}
+
+	for _, ext := range csr.Extensions {
+		if !ext.Id.Equal(oid) {
+			continue
+		}
+
+		var evidence models.TPMAttestationEvidence
+		rest, err := asn1.Unmarshal(ext.Value, &evidence)
+		if err != nil || len(rest) != 0 {
+			return nil, fmt.Errorf("could not decode TPM attestation extension %s", extensionOID)
+		}
+
+		return &evidence, nil
+	}
+
+	return nil, nil
+}
+
+// VerifyTPMAttestation verifies the TPM attestation evidence of the CSR and returns the EK certificate. The EK (and AK)
+// certificates must be issued by one of the manufacturer CAs. The quote must be signed by the AK, a restricted signing
+// key fixed to the TPM, and its extra data must be the SHA-256 of the CSR SubjectPublicKeyInfo.
+func VerifyTPMAttestation(csr *x509.CertificateRequest, evidence *models.TPMAttestationEvidence, manufacturerCAs []*x509.Certificate, requireAKCertificate bool) (*x509.Certificate, error) {
+	roots := x509.NewCertPool()
+	for _, ca := range manufacturerCAs {
+		roots.AddCert(ca)
+	}
+
+	ek, err := x509.ParseCertificate(evidence.EKCertificate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid EK certificate: %s", err)
+	}
+
+	err = verifyManufacturerCertificate(ek, roots)
+	if err != nil {
+		return nil, fmt.Errorf("EK certificate %s is not issued by a manufacturer CA: %s", SerialNumberToString(ek.SerialNumber), err)
+	}
+
+	akPublic, err := tpm2.DecodePublic(evidence.AKPublic)
+	if err != nil {
+		return nil, fmt.Errorf("invalid AK public area: %s", err)
+	}
+
+	akAttributes := tpm2.FlagFixedTPM | tpm2.FlagRestricted | tpm2.FlagSign
+	if akPublic.Attributes&akAttributes != akAttributes {
+		return nil, fmt.Errorf("AK must be a restricted signing key fixed to the TPM")
+	}
+
+	akKey, err := akPublic.Key()
+	if err != nil {
+		return nil, fmt.Errorf("invalid AK public key: %s", err)
+	}
+
+	if len(evidence.AKCertificate) > 0 {
+		ak, err := x509.ParseCertificate(evidence.AKCertificate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid AK certificate: %s", err)
+		}
+
+		err = verifyManufacturerCertificate(ak, roots)
+		if err != nil {
+			return nil, fmt.Errorf("AK certificate %s is not issued by a manufacturer CA: %s", SerialNumberToString(ak.SerialNumber), err)
+		}
+
+		akDER, err := x509.MarshalPKIXPublicKey(akKey)
+		if err != nil || !bytes.Equal(akDER, ak.RawSubjectPublicKeyInfo) {
+			return nil, fmt.Errorf("AK certificate %s does not certify the AK", SerialNumberToString(ak.SerialNumber))
+		}
+	} else if requireAKCertificate {
+		return nil, fmt.Errorf("AK certificate is required")
+	}
+
+	signature, err := tpm2.DecodeSignature(bytes.NewBuffer(evidence.Signature))
+	if err != nil {
+		return nil, fmt.Errorf("invalid quote signature: %s", err)
+	}
+
+	err = verifyTPMSignature(akKey, evidence.Quote, signature)
+	if err != nil {
+		return nil, fmt.Errorf("quote is not signed by the AK: %s", err)
+	}
+
+	quote, err := tpm2.DecodeAttestationData(evidence.Quote)
+	if err != nil {
+		return nil, fmt.Errorf("invalid quote: %s", err)
+	}
+
+	if quote.Type != tpm2.TagAttestQuote || quote.AttestedQuoteInfo == nil {
+		return nil, fmt.Errorf("attestation data is not a quote")
+	}
+
+	csrKeyDigest := sha256.Sum256(csr.RawSubjectPublicKeyInfo)
+	if !bytes.Equal(quote.ExtraData, csrKeyDigest[:]) {
+		return nil, fmt.Errorf("quote is not bound to the CSR public key")
+	}
+
+	return ek, nil
+}
+
+// verifyManufacturerCertificate verifies the certificate against the manufacturer CAs. EK certificates usually carry
+// a critical SAN with the TPM manufacturer, model and version directory names which is not handled by crypto/x509.
+func verifyManufacturerCertificate(crt *x509.Certificate, roots *x509.CertPool) error {
+	unhandled := crt.UnhandledCriticalExtensions[:0:0]
+	for _, ext := range crt.UnhandledCriticalExtensions {
+		if !ext.Equal(oidExtensionSubjectAltName) {
+			unhandled = append(unhandled, ext)
+		}
+	}
+
+	verifiable := *crt
+	verifiable.UnhandledCriticalExtensions = unhandled
+
+	// EK certificates use the tcg-kp-EKCertificate extended key usage, unknown to crypto/x509
+	_, err := verifiable.Verify(x509.VerifyOptions{
+		Roots:     roots,
+		KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+	})
+	return err
+}
+
+func verifyTPMSignature(key crypto.PublicKey, message []byte, signature *tpm2.Signature) error {
+	var hashAlg tpm2.Algorithm
+	switch {
+	case (signature.Alg == tpm2.AlgRSASSA || signature.Alg == tpm2.AlgRSAPSS) && signature.RSA != nil:
+		hashAlg = signature.RSA.HashAlg
+	case signature.Alg == tpm2.AlgECDSA && signature.ECC != nil:
+		hashAlg = signature.ECC.HashAlg
+	default:
+		return fmt.Errorf("unsupported signature algorithm 0x%x", signature.Alg)
+	}
+
+	hash, err := hashAlg.Hash()
+	if err != nil || !hash.Available() {
+		return fmt.Errorf("unsupported hash algorithm 0x%x", hashAlg)
+	}
+
+	h := hash.New()
+	h.Write(message)
+	digest := h.Sum(nil)
+
+	switch pub := key.(type) {
+	case *rsa.PublicKey:
+		if signature.Alg == tpm2.AlgRSAPSS {
+			return rsa.VerifyPSS(pub, hash, digest, signature.RSA.Signature, &rsa.PSSOptions{SaltLength: rsa.PSSSaltLengthAuto})
+		}
+		if signature.Alg == tpm2.AlgRSASSA {
+			return rsa.VerifyPKCS1v15(pub, hash, digest, signature.RSA.Signature)
+		}
+	case *ecdsa.PublicKey:
+		if signature.Alg == tpm2.AlgECDSA {
+			if !ecdsa.Verify(pub, digest, signature.ECC.R, signature.ECC.S) {
+				return fmt.Errorf("invalid ECDSA signature")
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("signature algorithm 0x%x does not match the AK type", signature.Alg)
+}
@@ -0,0 +1,289 @@
+package helpers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/google/go-tpm/tpm2"
+	"github.com/google/go-tpm/tpmutil"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+const testTPMAttestationOID = "1.3.6.1.4.1.99999.2"
+
+type testTPM struct {
+	manufacturerCA *x509.Certificate
+	caKey          *ecdsa.PrivateKey
+	ek             *x509.Certificate
+	akKey          *ecdsa.PrivateKey
+	akPublic       []byte
+}
+
+func newTestTPM(t *testing.T) *testTPM {
+	caKey, _ := GenerateECDSAKey(elliptic.P256())
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "TPM Manufacturer CA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDER, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, &caKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create manufacturer CA: %s", err)
+	}
+	ca, _ := x509.ParseCertificate(caDER)
+
+	// EK certificates carry a critical SAN with directory names only
+	san, _ := asn1.Marshal([]asn1.RawValue{{Class: asn1.ClassContextSpecific, Tag: 4, IsCompound: true, Bytes: mustMarshal(t, pkix.Name{CommonName: "tpm"}.ToRDNSequence())}})
+	ekKey, _ := GenerateECDSAKey(elliptic.P256())
+	ekDER, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+		SerialNumber:       big.NewInt(2),
+		NotBefore:          time.Now().Add(-time.Hour),
+		NotAfter:           time.Now().Add(time.Hour),
+		UnknownExtKeyUsage: []asn1.ObjectIdentifier{{2, 23, 133, 8, 1}},
+		ExtraExtensions:    []pkix.Extension{{Id: oidExtensionSubjectAltName, Critical: true, Value: san}},
+	}, ca, &ekKey.PublicKey, caKey)
+	if err != nil {
+		t.Fatalf("could not create EK certificate: %s", err)
+	}
+	ek, _ := x509.ParseCertificate(ekDER)
+
+	akKey, _ := GenerateECDSAKey(elliptic.P256())
+	return &testTPM{
+		manufacturerCA: ca,
+		caKey:          caKey,
+		ek:             ek,
+		akKey:          akKey,
+		akPublic:       tpmPublic(t, &akKey.PublicKey, tpm2.FlagSignerDefault),
+	}
+}
+
+func mustMarshal(t *testing.T, value any) []byte {
+	der, err := asn1.Marshal(value)
+	if err != nil {
+		t.Fatalf("could not marshal: %s", err)
+	}
+	return der
+}
+
+func tpmPublic(t *testing.T, key *ecdsa.PublicKey, attributes tpm2.KeyProp) []byte {
+	public, err := tpm2.Public{
+		Type:       tpm2.AlgECC,
+		NameAlg:    tpm2.AlgSHA256,
+		Attributes: attributes,
+		ECCParameters: &tpm2.ECCParams{
+			Sign:    &tpm2.SigScheme{Alg: tpm2.AlgECDSA, Hash: tpm2.AlgSHA256},
+			CurveID: tpm2.CurveNISTP256,
+			Point:   tpm2.ECPoint{XRaw: key.X.FillBytes(make([]byte, 32)), YRaw: key.Y.FillBytes(make([]byte, 32))},
+		},
+	}.Encode()
+	if err != nil {
+		t.Fatalf("could not encode TPM public area: %s", err)
+	}
+	return public
+}
+
+// quote returns a TPMS_ATTEST quote with the given extra data, and its TPMT_SIGNATURE.
+func (tpm *testTPM) quote(t *testing.T, extraData []byte) ([]byte, []byte) {
+	quote, err := tpmutil.Pack(
+		uint32(0xff544347), tpm2.TagAttestQuote,
+		tpmutil.U16Bytes{}, tpmutil.U16Bytes(extraData),
+		uint64(1), uint32(0), uint32(0), byte(1),
+		uint64(0),
+		uint32(1), tpm2.AlgSHA256, byte(3), tpmutil.RawBytes{0x01, 0x00, 0x00},
+		tpmutil.U16Bytes(make([]byte, 32)),
+	)
+	if err != nil {
+		t.Fatalf("could not encode quote: %s", err)
+	}
+
+	digest := sha256.Sum256(quote)
+	r, s, _ := ecdsa.Sign(rand.Reader, tpm.akKey, digest[:])
+	signature, _ := tpmutil.Pack(tpm2.AlgECDSA, tpm2.AlgSHA256, tpmutil.U16Bytes(r.Bytes()), tpmutil.U16Bytes(s.Bytes()))
+
+	return quote, signature
+}
+
+func (tpm *testTPM) csr(t *testing.T, evidence func(csrKey []byte) *models.TPMAttestationEvidence) *x509.CertificateRequest {
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	spki, _ := x509.MarshalPKIXPublicKey(&key.PublicKey)
+
+	extensions := []pkix.Extension{}
+	if evidence != nil {
+		oid, _ := ParseOID(testTPMAttestationOID)
+		extensions = append(extensions, pkix.Extension{Id: oid, Value: mustMarshal(t, *evidence(spki))})
+	}
+
+	csr, err := GenerateCertificateRequestWithExtensions(models.Subject{CommonName: "device-1"}, extensions, key)
+	if err != nil {
+		t.Fatalf("could not generate CSR: %s", err)
+	}
+
+	return csr
+}
+
+func (tpm *testTPM) evidence(t *testing.T) func(csrKey []byte) *models.TPMAttestationEvidence {
+	return func(csrKey []byte) *models.TPMAttestationEvidence {
+		digest := sha256.Sum256(csrKey)
+		quote, signature := tpm.quote(t, digest[:])
+		return &models.TPMAttestationEvidence{
+			EKCertificate: tpm.ek.Raw,
+			AKPublic:      tpm.akPublic,
+			Quote:         quote,
+			Signature:     signature,
+		}
+	}
+}
+
+func verifyTestTPMAttestation(t *testing.T, tpm *testTPM, csr *x509.CertificateRequest, requireAKCertificate bool) error {
+	evidence, err := GetTPMAttestationEvidence(csr, testTPMAttestationOID)
+	if err != nil {
+		t.Fatalf("could not get TPM attestation evidence: %s", err)
+	}
+
+	_, err = VerifyTPMAttestation(csr, evidence, []*x509.Certificate{tpm.manufacturerCA}, requireAKCertificate)
+	return err
+}
+
+func TestVerifyTPMAttestation(t *testing.T) {
+	tpm := newTestTPM(t)
+	csr := tpm.csr(t, tpm.evidence(t))
+
+	evidence, err := GetTPMAttestationEvidence(csr, testTPMAttestationOID)
+	if err != nil {
+		t.Fatalf("could not get TPM attestation evidence: %s", err)
+	}
+
+	ek, err := VerifyTPMAttestation(csr, evidence, []*x509.Certificate{tpm.manufacturerCA}, false)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if ek.SerialNumber.Cmp(tpm.ek.SerialNumber) != 0 {
+		t.Errorf("unexpected EK certificate %s", ek.SerialNumber)
+	}
+}
+
+func TestGetTPMAttestationEvidenceMissing(t *testing.T) {
+	tpm := newTestTPM(t)
+	evidence, err := GetTPMAttestationEvidence(tpm.csr(t, nil), testTPMAttestationOID)
+	if err != nil || evidence != nil {
+		t.Errorf("expected no evidence, got %v, %v", evidence, err)
+	}
+}
+
+func TestVerifyTPMAttestationUnknownManufacturer(t *testing.T) {
+	tpm := newTestTPM(t)
+	csr := tpm.csr(t, tpm.evidence(t))
+
+	other := newTestTPM(t)
+	evidence, _ := GetTPMAttestationEvidence(csr, testTPMAttestationOID)
+	_, err := VerifyTPMAttestation(csr, evidence, []*x509.Certificate{other.manufacturerCA}, false)
+	if err == nil {
+		t.Errorf("expected EK certificate of unknown manufacturer to be rejected")
+	}
+}
+
+func TestVerifyTPMAttestationQuoteNotBoundToCSR(t *testing.T) {
+	tpm := newTestTPM(t)
+	csr := tpm.csr(t, func(csrKey []byte) *models.TPMAttestationEvidence {
+		return tpm.evidence(t)([]byte("other key"))
+	})
+
+	if err := verifyTestTPMAttestation(t, tpm, csr, false); err == nil {
+		t.Errorf("expected quote of another key to be rejected")
+	}
+}
+
+func TestVerifyTPMAttestationInvalidSignature(t *testing.T) {
+	tpm := newTestTPM(t)
+	csr := tpm.csr(t, func(csrKey []byte) *models.TPMAttestationEvidence {
+		evidence := tpm.evidence(t)(csrKey)
+		_, evidence.Signature = tpm.quote(t, []byte("other quote"))
+		return evidence
+	})
+
+	if err := verifyTestTPMAttestation(t, tpm, csr, false); err == nil {
+		t.Errorf("expected quote with invalid signature to be rejected")
+	}
+}
+
+func TestVerifyTPMAttestationUnrestrictedAK(t *testing.T) {
+	tpm := newTestTPM(t)
+	csr := tpm.csr(t, func(csrKey []byte) *models.TPMAttestationEvidence {
+		evidence := tpm.evidence(t)(csrKey)
+		evidence.AKPublic = tpmPublic(t, &tpm.akKey.PublicKey, tpm2.FlagSign|tpm2.FlagFixedTPM)
+		return evidence
+	})
+
+	if err := verifyTestTPMAttestation(t, tpm, csr, false); err == nil {
+		t.Errorf("expected unrestricted AK to be rejected")
+	}
+}
+
+func TestVerifyTPMAttestationAKCertificate(t *testing.T) {
+	tpm := newTestTPM(t)
+	if err := verifyTestTPMAttestation(t, tpm, tpm.csr(t, tpm.evidence(t)), true); err == nil {
+		t.Errorf("expected evidence without AK certificate to be rejected")
+	}
+
+	akCertificate := func(key *ecdsa.PublicKey) []byte {
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(3),
+			NotBefore:    time.Now().Add(-time.Hour),
+			NotAfter:     time.Now().Add(time.Hour),
+		}, tpm.manufacturerCA, key, tpm.caKey)
+		if err != nil {
+			t.Fatalf("could not create AK certificate: %s", err)
+		}
+		return der
+	}
+
+	csr := tpm.csr(t, func(csrKey []byte) *models.TPMAttestationEvidence {
+		evidence := tpm.evidence(t)(csrKey)
+		evidence.AKCertificate = akCertificate(&tpm.akKey.PublicKey)
+		return evidence
+	})
+	if err := verifyTestTPMAttestation(t, tpm, csr, true); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+
+	otherKey, _ := GenerateECDSAKey(elliptic.P256())
+	csr = tpm.csr(t, func(csrKey []byte) *models.TPMAttestationEvidence {
+		evidence := tpm.evidence(t)(csrKey)
+		evidence.AKCertificate = akCertificate(&otherKey.PublicKey)
+		return evidence
+	})
+	if err := verifyTestTPMAttestation(t, tpm, csr, true); err == nil {
+		t.Errorf("expected AK certificate of another key to be rejected")
+	}
+}
+
+func TestValidateTPMAttestation(t *testing.T) {
+	if err := ValidateTPMAttestation(models.TPMAttestation{}); err != nil {
+		t.Errorf("unexpected error for disabled attestation: %s", err)
+	}
+
+	if err := ValidateTPMAttestation(models.TPMAttestation{Enabled: true, ExtensionOID: testTPMAttestationOID}); err == nil {
+		t.Errorf("expected error without manufacturer CAs")
+	}
+
+	if err := ValidateTPMAttestation(models.TPMAttestation{Enabled: true, ExtensionOID: "x", ManufacturerCAs: []string{"ca"}}); err == nil {
+		t.Errorf("expected error for invalid OID")
+	}
+
+	if err := ValidateTPMAttestation(models.TPMAttestation{Enabled: true, ExtensionOID: testTPMAttestationOID, ManufacturerCAs: []string{"ca"}}); err != nil {
+		t.Errorf("unexpected error: %s", err)
+	}
+}
@@ -2,6 +2,10 @@ package helpers
 
 import (
 	"crypto/x509"
+	"fmt"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 )
 
 func ValidateCertificate(ca, cert *x509.Certificate, considerExpiration bool) error {
@@ -23,3 +27,44 @@ func ValidateCertificate(ca, cert *x509.Certificate, considerExpiration bool) er
 
 	return nil
 }
+
+// ValidateCertificateWithClockSkew validates the certificate considering its expiration as ValidateCertificate does,
+// but the certificate is also accepted up to skew before its NotBefore or after its NotAfter.
+func ValidateCertificateWithClockSkew(ca, cert *x509.Certificate, skew time.Duration) error {
+	caPool := x509.NewCertPool()
+	caPool.AddCert(ca)
+
+	now := time.Now()
+	opts := x509.VerifyOptions{
+		Roots:       caPool,
+		KeyUsages:   []x509.ExtKeyUsage{x509.ExtKeyUsageAny},
+		CurrentTime: now,
+	}
+
+	if now.Before(cert.NotBefore) && cert.NotBefore.Sub(now) <= skew {
+		opts.CurrentTime = cert.NotBefore
+	} else if now.After(cert.NotAfter) && now.Sub(cert.NotAfter) <= skew {
+		opts.CurrentTime = cert.NotAfter
+	}
+
+	_, err := cert.Verify(opts)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+// ValidateClockSkewSettings checks that the clock skew tolerance and the NotBefore backdate of the DMS are not negative.
+func ValidateClockSkewSettings(settings models.DMSSettings) error {
+	if settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ClockSkewTolerance < 0 {
+		return fmt.Errorf("clock skew tolerance can not be negative")
+	}
+
+	backdate := settings.EnrollmentSettings.SigningProfile.NotBeforeBackdate
+	if backdate != nil && *backdate < 0 {
+		return fmt.Errorf("not before backdate can not be negative")
+	}
+
+	return nil
+}
@@ -1,7 +1,13 @@
 package helpers
 
 import (
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"math/big"
 	"testing"
+	"time"
 )
 
 func TestValidateCertificate(t *testing.T) {
@@ -43,3 +49,63 @@ func TestValidateCertificate(t *testing.T) {
 	}
 
 }
+
+func TestValidateCertificateWithClockSkew(t *testing.T) {
+	caKey, _ := GenerateECDSAKey(elliptic.P256())
+	caTemplate := &x509.Certificate{
+		SerialNumber:          big.NewInt(1),
+		Subject:               pkix.Name{CommonName: "MyCA"},
+		NotBefore:             time.Now().Add(-time.Hour),
+		NotAfter:              time.Now().Add(time.Hour),
+		IsCA:                  true,
+		BasicConstraintsValid: true,
+		KeyUsage:              x509.KeyUsageCertSign,
+	}
+	caDer, err := x509.CreateCertificate(rand.Reader, caTemplate, caTemplate, caKey.Public(), caKey)
+	if err != nil {
+		t.Fatalf("could not generate CA: %s", err)
+	}
+	caCert, _ := x509.ParseCertificate(caDer)
+
+	key, _ := GenerateECDSAKey(elliptic.P256())
+	signCert := func(notBefore, notAfter time.Time) *x509.Certificate {
+		der, err := x509.CreateCertificate(rand.Reader, &x509.Certificate{
+			SerialNumber: big.NewInt(time.Now().UnixNano()),
+			Subject:      pkix.Name{CommonName: "device"},
+			NotBefore:    notBefore,
+			NotAfter:     notAfter,
+		}, caCert, key.Public(), caKey)
+		if err != nil {
+			t.Fatalf("could not sign certificate: %s", err)
+		}
+
+		cert, _ := x509.ParseCertificate(der)
+		return cert
+	}
+
+	now := time.Now()
+	notYetValid := signCert(now.Add(2*time.Minute), now.Add(30*time.Minute))
+	justExpired := signCert(now.Add(-30*time.Minute), now.Add(-2*time.Minute))
+
+	tests := []struct {
+		name    string
+		cert    *x509.Certificate
+		skew    time.Duration
+		wantErr bool
+	}{
+		{"NotYetValidWithoutSkew", notYetValid, 0, true},
+		{"NotYetValidWithinSkew", notYetValid, 5 * time.Minute, false},
+		{"NotYetValidBeyondSkew", notYetValid, time.Minute, true},
+		{"ExpiredWithoutSkew", justExpired, 0, true},
+		{"ExpiredWithinSkew", justExpired, 5 * time.Minute, false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := ValidateCertificateWithClockSkew(caCert, tt.cert, tt.skew)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("ValidateCertificateWithClockSkew() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// CARotator processes the next batch of every running CA rotation campaign, so the devices holding certificates
+// issued by the rotated CAs are forced to reenroll with the successors one batch per run.
+type CARotator struct {
+	logger     *logrus.Entry
+	caService  services.CAService
+	dmsService services.DMSManagerService
+}
+
+func NewCARotator(caService services.CAService, dmsService services.DMSManagerService, logger *logrus.Entry) *CARotator {
+	return &CARotator{
+		caService:  caService,
+		dmsService: dmsService,
+		logger:     logger,
+	}
+}
+
+func (job *CARotator) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Info("starting periodic processing of CA rotations")
+
+	rotating := []string{}
+	_, err := job.caService.GetCAs(ctx, services.GetCAsInput{
+		QueryParameters: nil,
+		ExhaustiveRun:   true,
+		ApplyFunc: func(ca models.CACertificate) {
+			var rotation models.CARotation
+			hasKey, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataRotationKey, &rotation)
+			if err == nil && hasKey && rotation.Status == models.CARotationRunning {
+				rotating = append(rotating, ca.ID)
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate CAs: %s", err)
+		return
+	}
+
+	for _, caID := range rotating {
+		rotation, err := job.dmsService.ProcessCARotationBatch(ctx, services.ProcessCARotationBatchInput{
+			CAID: caID,
+		})
+		if err != nil {
+			lFunc.Errorf("could not process rotation batch of CA %s: %s", caID, err)
+			continue
+		}
+
+		lFunc.Infof("CA %s rotation is %s: %d certificates processed, %d devices reenrolled", caID, rotation.Status, rotation.ProcessedCertificates, len(rotation.ReenrolledDevices))
+	}
+
+	end := time.Now()
+	lFunc.Infof("ending processing. Took %v", end.Sub(now))
+}
@@ -0,0 +1,43 @@
+package jobs
+
+import (
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCARotatorProcessesRunningRotations(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	rotator := NewCARotator(mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger()))
+
+	rotatingCA := func(id string, status models.CARotationStatus) models.CACertificate {
+		return models.CACertificate{
+			ID: id,
+			Metadata: map[string]interface{}{
+				models.CAMetadataRotationKey: models.CARotation{CAID: id, SuccessorCAID: id + "-successor", Status: status},
+			},
+		}
+	}
+
+	mockCAService.On("GetCAs", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input := args.Get(1).(services.GetCAsInput)
+		input.ApplyFunc(rotatingCA("running", models.CARotationRunning))
+		input.ApplyFunc(rotatingCA("completed", models.CARotationCompleted))
+		input.ApplyFunc(models.CACertificate{ID: "not-rotated", Metadata: map[string]interface{}{}})
+	}).Return("", nil)
+	mockDMSService.On("ProcessCARotationBatch", mock.Anything, services.ProcessCARotationBatchInput{CAID: "running"}).Return(&models.CARotation{
+		CAID:   "running",
+		Status: models.CARotationRunning,
+	}, nil)
+
+	rotator.Run()
+
+	mockDMSService.AssertNumberOfCalls(t, "ProcessCARotationBatch", 1)
+	mockDMSService.AssertCalled(t, "ProcessCARotationBatch", mock.Anything, services.ProcessCARotationBatchInput{CAID: "running"})
+}
@@ -0,0 +1,158 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// CASuccessor replaces the managed CAs expiring within the configured number of days with a cross signed successor,
+// notifies the DMSs enrolling with the replaced CAs and pauses the issuance of the replaced CAs once they expire.
+type CASuccessor struct {
+	logger          *logrus.Entry
+	caService       services.CAService
+	dmsService      services.DMSManagerService
+	renewBeforeDays int
+}
+
+func NewCASuccessor(caService services.CAService, dmsService services.DMSManagerService, renewBeforeDays int, logger *logrus.Entry) *CASuccessor {
+	return &CASuccessor{
+		caService:       caService,
+		dmsService:      dmsService,
+		renewBeforeDays: renewBeforeDays,
+		logger:          logger,
+	}
+}
+
+func (job *CASuccessor) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Info("starting periodic check for CAs about to expire")
+
+	cas := []models.CACertificate{}
+	_, err := job.caService.GetCAs(ctx, services.GetCAsInput{
+		QueryParameters: nil,
+		ExhaustiveRun:   true,
+		ApplyFunc: func(ca models.CACertificate) {
+			cas = append(cas, ca)
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate CAs: %s", err)
+		return
+	}
+
+	dmss := map[string][]models.DMS{}
+	_, err = job.dmsService.GetAll(ctx, services.GetAllInput{
+		ListInput: resources.ListInput[models.DMS]{
+			QueryParameters: nil,
+			ExhaustiveRun:   true,
+			ApplyFunc: func(dms models.DMS) {
+				caID := dms.Settings.EnrollmentSettings.EnrollmentCA
+				dmss[caID] = append(dmss[caID], dms)
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate DMSs: %s", err)
+		return
+	}
+
+	for _, ca := range cas {
+		job.processCA(ctx, ca, dmss[ca.ID], now)
+	}
+
+	end := time.Now()
+	lFunc.Infof("ending check. Took %v", end.Sub(now))
+}
+
+func (job *CASuccessor) processCA(ctx context.Context, ca models.CACertificate, dmss []models.DMS, now time.Time) {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	if ca.Type != models.CertificateTypeManaged {
+		return
+	}
+
+	var succession models.CASuccession
+	hasSuccessor, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataSuccessorKey, &succession)
+	if err != nil {
+		lFunc.Errorf("could not decode successor of CA %s: %s", ca.ID, err)
+		return
+	}
+
+	if !hasSuccessor {
+		if ca.Status != models.StatusActive || now.AddDate(0, 0, job.renewBeforeDays).Before(ca.Certificate.ValidTo) {
+			return
+		}
+
+		created, err := job.caService.CreateSuccessorCA(ctx, services.CreateSuccessorCAInput{
+			CAID: ca.ID,
+		})
+		if err != nil {
+			lFunc.Errorf("could not create successor of CA %s: %s", ca.ID, err)
+			return
+		}
+
+		lFunc.Infof("CA %s expiring at %s replaced by successor CA %s", ca.ID, ca.Certificate.ValidTo, created.SuccessorCAID)
+		succession = *created
+	}
+
+	job.notifyDMSs(ctx, succession, dmss)
+
+	if paused, _ := ca.Metadata[models.CAMetadataIssuancePausedKey].(bool); paused || now.Before(ca.Certificate.ValidTo) {
+		return
+	}
+
+	metadata := map[string]any{}
+	for key, value := range ca.Metadata {
+		metadata[key] = value
+	}
+	metadata[models.CAMetadataIssuancePausedKey] = true
+
+	_, err = job.caService.UpdateCAMetadata(ctx, services.UpdateCAMetadataInput{
+		CAID:     ca.ID,
+		Metadata: metadata,
+	})
+	if err != nil {
+		lFunc.Errorf("could not pause issuance of expired CA %s: %s", ca.ID, err)
+		return
+	}
+
+	lFunc.Infof("issuance of expired CA %s paused. Successor CA is %s", ca.ID, succession.SuccessorCAID)
+}
+
+// notifyDMSs stores the succession in the metadata of the DMSs enrolling with the replaced CA. DMSs already notified
+// are skipped.
+func (job *CASuccessor) notifyDMSs(ctx context.Context, succession models.CASuccession, dmss []models.DMS) {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	for _, dms := range dmss {
+		var notified models.CASuccession
+		hasKey, err := helpers.GetMetadataToStruct(dms.Metadata, models.DMSMetadataCASuccessionKey, &notified)
+		if err == nil && hasKey && notified.SuccessorCAID == succession.SuccessorCAID {
+			continue
+		}
+
+		if dms.Metadata == nil {
+			dms.Metadata = map[string]any{}
+		}
+		dms.Metadata[models.DMSMetadataCASuccessionKey] = succession
+
+		_, err = job.dmsService.UpdateDMS(ctx, services.UpdateDMSInput{
+			DMS: dms,
+		})
+		if err != nil {
+			lFunc.Errorf("could not notify DMS %s of the successor of CA %s: %s", dms.ID, succession.CAID, err)
+			continue
+		}
+
+		lFunc.Infof("DMS %s notified of the successor CA %s of its enrollment CA %s", dms.ID, succession.SuccessorCAID, succession.CAID)
+	}
+}
@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestCASuccessorCreatesSuccessorBeforeExpiration(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	successor := NewCASuccessor(mockCAService, mockDMSService, 30, logrus.NewEntry(logrus.StandardLogger()))
+
+	ca := models.CACertificate{
+		ID:       "ca",
+		Type:     models.CertificateTypeManaged,
+		Metadata: map[string]interface{}{},
+		Certificate: models.Certificate{
+			Status:  models.StatusActive,
+			ValidTo: time.Now().AddDate(0, 0, 10),
+		},
+	}
+	dms := models.DMS{ID: "dms", Metadata: map[string]any{}}
+
+	mockCAService.On("CreateSuccessorCA", mock.Anything, services.CreateSuccessorCAInput{CAID: "ca"}).Return(&models.CASuccession{
+		CAID:          "ca",
+		SuccessorCAID: "successor",
+	}, nil)
+	mockDMSService.On("UpdateDMS", mock.Anything, mock.MatchedBy(func(input services.UpdateDMSInput) bool {
+		succession, ok := input.DMS.Metadata[models.DMSMetadataCASuccessionKey].(models.CASuccession)
+		return ok && succession.SuccessorCAID == "successor"
+	})).Return(&dms, nil)
+
+	successor.processCA(context.Background(), ca, []models.DMS{dms}, time.Now())
+
+	mockCAService.AssertCalled(t, "CreateSuccessorCA", mock.Anything, services.CreateSuccessorCAInput{CAID: "ca"})
+	mockDMSService.AssertNumberOfCalls(t, "UpdateDMS", 1)
+	mockCAService.AssertNotCalled(t, "UpdateCAMetadata", mock.Anything, mock.Anything)
+}
+
+func TestCASuccessorNotExpiringSoon(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	successor := NewCASuccessor(mockCAService, mockDMSService, 30, logrus.NewEntry(logrus.StandardLogger()))
+
+	ca := models.CACertificate{
+		ID:       "ca",
+		Type:     models.CertificateTypeManaged,
+		Metadata: map[string]interface{}{},
+		Certificate: models.Certificate{
+			Status:  models.StatusActive,
+			ValidTo: time.Now().AddDate(0, 0, 60),
+		},
+	}
+
+	successor.processCA(context.Background(), ca, []models.DMS{{ID: "dms"}}, time.Now())
+
+	mockCAService.AssertNotCalled(t, "CreateSuccessorCA", mock.Anything, mock.Anything)
+	mockDMSService.AssertNotCalled(t, "UpdateDMS", mock.Anything, mock.Anything)
+}
+
+func TestCASuccessorPausesExpiredCA(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	successor := NewCASuccessor(mockCAService, mockDMSService, 30, logrus.NewEntry(logrus.StandardLogger()))
+
+	succession := models.CASuccession{CAID: "ca", SuccessorCAID: "successor"}
+	ca := models.CACertificate{
+		ID:   "ca",
+		Type: models.CertificateTypeManaged,
+		Metadata: map[string]interface{}{
+			models.CAMetadataSuccessorKey: succession,
+		},
+		Certificate: models.Certificate{
+			Status:  models.StatusExpired,
+			ValidTo: time.Now().Add(-time.Hour),
+		},
+	}
+	dms := models.DMS{ID: "dms", Metadata: map[string]any{models.DMSMetadataCASuccessionKey: succession}}
+
+	mockCAService.On("UpdateCAMetadata", mock.Anything, mock.MatchedBy(func(input services.UpdateCAMetadataInput) bool {
+		return input.CAID == "ca" && input.Metadata[models.CAMetadataIssuancePausedKey] == true
+	})).Return(&ca, nil)
+
+	successor.processCA(context.Background(), ca, []models.DMS{dms}, time.Now())
+
+	mockCAService.AssertNotCalled(t, "CreateSuccessorCA", mock.Anything, mock.Anything)
+	mockDMSService.AssertNotCalled(t, "UpdateDMS", mock.Anything, mock.Anything)
+	mockCAService.AssertNumberOfCalls(t, "UpdateCAMetadata", 1)
+}
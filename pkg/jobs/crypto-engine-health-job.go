@@ -0,0 +1,53 @@
+package jobs
+
+import (
+	"context"
+	"sort"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/sirupsen/logrus"
+)
+
+// CryptoEngineHealthChecker probes the supervised crypto engines, reconnecting the ones that are unavailable.
+// probeKey returns the ID of a key stored in the engine, or an empty string if the engine holds no keys yet.
+type CryptoEngineHealthChecker struct {
+	logger   *logrus.Entry
+	engines  map[string]*cryptoengines.SupervisedEngine
+	probeKey func(ctx context.Context, engineID string) (string, error)
+}
+
+func NewCryptoEngineHealthChecker(engines map[string]*cryptoengines.SupervisedEngine, probeKey func(ctx context.Context, engineID string) (string, error), logger *logrus.Entry) *CryptoEngineHealthChecker {
+	return &CryptoEngineHealthChecker{
+		engines:  engines,
+		probeKey: probeKey,
+		logger:   logger,
+	}
+}
+
+func (job *CryptoEngineHealthChecker) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	engineIDs := make([]string, 0, len(job.engines))
+	for engineID := range job.engines {
+		engineIDs = append(engineIDs, engineID)
+	}
+	sort.Strings(engineIDs)
+
+	for _, engineID := range engineIDs {
+		keyID, err := job.probeKey(ctx, engineID)
+		if err != nil {
+			lFunc.Errorf("could not select key to probe engine %s: %s", engineID, err)
+			continue
+		}
+
+		err = job.engines[engineID].Probe(keyID)
+		if err != nil {
+			lFunc.Warnf("crypto engine %s is unavailable: %s", engineID, err)
+			continue
+		}
+
+		lFunc.Debugf("crypto engine %s is available", engineID)
+	}
+}
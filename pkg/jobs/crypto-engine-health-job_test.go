@@ -0,0 +1,62 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+func TestCryptoEngineHealthCheckerReconnectsEngines(t *testing.T) {
+	logger := logrus.NewEntry(logrus.StandardLogger())
+
+	connects := map[string]int{}
+	newEngine := func(id string, onlineAfter int) *cryptoengines.SupervisedEngine {
+		return cryptoengines.NewSupervisedEngine(logger, id, models.CryptoEngineInfo{}, func() (cryptoengines.CryptoEngine, error) {
+			connects[id]++
+			if connects[id] <= onlineAfter {
+				return nil, errors.New("connection refused")
+			}
+
+			return cryptoengines.NewGolangPEMEngine(logger, config.GolangEngineConfig{StorageDirectory: t.TempDir()})
+		})
+	}
+
+	engines := map[string]*cryptoengines.SupervisedEngine{
+		"recovering": newEngine("recovering", 1),
+		"down":       newEngine("down", 100),
+		"no-keys":    newEngine("no-keys", 0),
+	}
+
+	probed := []string{}
+	checker := NewCryptoEngineHealthChecker(engines, func(ctx context.Context, engineID string) (string, error) {
+		probed = append(probed, engineID)
+		if engineID == "no-keys" {
+			return "", errors.New("storage unavailable")
+		}
+
+		return "", nil
+	}, logger)
+
+	checker.Run()
+
+	if len(probed) != 3 || probed[0] != "down" || probed[1] != "no-keys" || probed[2] != "recovering" {
+		t.Fatalf("expected the engines to be probed in order, got %v", probed)
+	}
+
+	if !engines["recovering"].Available() {
+		t.Fatalf("recovering engine should be available after the health check")
+	}
+
+	if engines["down"].Available() {
+		t.Fatalf("down engine should remain unavailable")
+	}
+
+	if connects["no-keys"] != 1 {
+		t.Fatalf("engine should not be probed if the probe key can not be selected, got %d connections", connects["no-keys"])
+	}
+}
@@ -0,0 +1,42 @@
+package jobs
+
+import (
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// DeviceLogPruner periodically deletes the device log entries older than the retention period.
+type DeviceLogPruner struct {
+	logger        *logrus.Entry
+	deviceService services.DeviceManagerService
+	retention     time.Duration
+}
+
+func NewDeviceLogPruner(deviceService services.DeviceManagerService, retention time.Duration, logger *logrus.Entry) *DeviceLogPruner {
+	return &DeviceLogPruner{
+		deviceService: deviceService,
+		retention:     retention,
+		logger:        logger,
+	}
+}
+
+func (job *DeviceLogPruner) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Infof("pruning device log entries older than %s", job.retention)
+
+	pruned, err := job.deviceService.PruneDeviceLogs(ctx, services.PruneDeviceLogsInput{
+		Before: now.Add(-job.retention),
+	})
+	if err != nil {
+		lFunc.Errorf("could not prune device logs: %s", err)
+		return
+	}
+
+	lFunc.Infof("pruned %d device log entries. Took %v", pruned, time.Since(now))
+}
@@ -0,0 +1,26 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestDeviceLogPrunerPrunesRetentionPeriod(t *testing.T) {
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+
+	pruner := NewDeviceLogPruner(mockDeviceService, 30*24*time.Hour, logrus.NewEntry(logrus.StandardLogger()))
+
+	mockDeviceService.On("PruneDeviceLogs", mock.Anything, mock.MatchedBy(func(input services.PruneDeviceLogsInput) bool {
+		expected := time.Now().Add(-30 * 24 * time.Hour)
+		return input.Before.Sub(expected).Abs() < time.Minute
+	})).Return(3, nil)
+
+	pruner.Run()
+
+	mockDeviceService.AssertExpectations(t)
+}
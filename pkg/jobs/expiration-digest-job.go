@@ -0,0 +1,208 @@
+package jobs
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+var defaultExpirationDigestWindows = []string{"30d", "60d", "90d"}
+
+type expirationWindow struct {
+	name     string
+	duration time.Duration
+}
+
+// ExpirationDigester periodically summarizes the CAs, DMS certificates and devices expiring in the next windows and
+// delivers the digest through the alerts service.
+type ExpirationDigester struct {
+	logger        *logrus.Entry
+	caService     services.CAService
+	alertsService services.AlertsService
+	windows       []expirationWindow
+}
+
+func NewExpirationDigester(caService services.CAService, alertsService services.AlertsService, windows []string, logger *logrus.Entry) (*ExpirationDigester, error) {
+	if len(windows) == 0 {
+		windows = defaultExpirationDigestWindows
+	}
+
+	parsed := []expirationWindow{}
+	for _, window := range windows {
+		duration, err := models.ParseDuration(window)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse expiration digest window '%s': %s", window, err)
+		}
+
+		if duration <= 0 {
+			return nil, fmt.Errorf("expiration digest window '%s' must be positive", window)
+		}
+
+		parsed = append(parsed, expirationWindow{name: window, duration: duration})
+	}
+
+	sort.Slice(parsed, func(i, j int) bool {
+		return parsed[i].duration < parsed[j].duration
+	})
+
+	return &ExpirationDigester{
+		logger:        logger,
+		caService:     caService,
+		alertsService: alertsService,
+		windows:       parsed,
+	}, nil
+}
+
+func (job *ExpirationDigester) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Infof("generating expiration digest")
+
+	digest, err := job.Digest(ctx, now)
+	if err != nil {
+		lFunc.Errorf("could not generate expiration digest: %s", err)
+		return
+	}
+
+	err = job.alertsService.SendExpirationDigest(ctx, &services.SendExpirationDigestInput{
+		Digest: *digest,
+	})
+	if err != nil {
+		lFunc.Errorf("could not send expiration digest: %s", err)
+		return
+	}
+
+	end := time.Now()
+	lFunc.Infof("expiration digest sent. Took %v", end.Sub(now))
+}
+
+// Digest collects the active CAs and certificates expiring before the largest window. Certificates are attributed to
+// the DMS that authorized their enrollment, so certificates not enrolled through a DMS are not reported.
+func (job *ExpirationDigester) Digest(ctx context.Context, now time.Time) (*models.ExpirationDigest, error) {
+	horizon := now.Add(job.windows[len(job.windows)-1].duration)
+	filters := []resources.FilterOption{
+		{Field: "valid_to", FilterOperation: resources.DateAfter, Value: now.Format(time.RFC3339)},
+		{Field: "valid_to", FilterOperation: resources.DateBefore, Value: horizon.Format(time.RFC3339)},
+		{Field: "status", FilterOperation: resources.EnumEqual, Value: string(models.StatusActive)},
+	}
+
+	windows := make([]models.ExpirationDigestWindow, len(job.windows))
+	dmsCerts := make([]map[string]int, len(job.windows))
+	dmsDevices := make([]map[string]map[string]bool, len(job.windows))
+	from := now
+	for i, window := range job.windows {
+		windows[i] = models.ExpirationDigestWindow{
+			Window: window.name,
+			From:   from,
+			To:     now.Add(window.duration),
+			CAs:    []models.ExpiringCA{},
+			DMSs:   []models.ExpiringDMSSummary{},
+		}
+		dmsCerts[i] = map[string]int{}
+		dmsDevices[i] = map[string]map[string]bool{}
+		from = windows[i].To
+	}
+
+	windowOf := func(validTo time.Time) int {
+		for i, window := range windows {
+			if validTo.After(now) && !validTo.After(window.To) {
+				return i
+			}
+		}
+		return -1
+	}
+
+	bookmark := ""
+	for {
+		next, err := job.caService.GetCAs(ctx, services.GetCAsInput{
+			QueryParameters: &resources.QueryParameters{NextBookmark: bookmark, PageSize: 100, Filters: filters},
+			ApplyFunc: func(ca models.CACertificate) {
+				if i := windowOf(ca.ValidTo); i >= 0 && ca.Status == models.StatusActive {
+					windows[i].CAs = append(windows[i].CAs, models.ExpiringCA{
+						ID:         ca.ID,
+						CommonName: ca.Subject.CommonName,
+						ValidTo:    ca.ValidTo,
+					})
+				}
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get expiring CAs: %w", err)
+		}
+
+		if next == "" || next == bookmark {
+			break
+		}
+		bookmark = next
+	}
+
+	bookmark = ""
+	for {
+		next, err := job.caService.GetCertificates(ctx, services.GetCertificatesInput{
+			ListInput: resources.ListInput[models.Certificate]{
+				QueryParameters: &resources.QueryParameters{NextBookmark: bookmark, PageSize: 100, Filters: filters},
+				ApplyFunc: func(crt models.Certificate) {
+					i := windowOf(crt.ValidTo)
+					if i < 0 || crt.Status != models.StatusActive {
+						return
+					}
+
+					var attachedTo models.CAAttachedToDevice
+					hasKey, err := helpers.GetMetadataToStruct(crt.Metadata, models.CAAttachedToDeviceKey, &attachedTo)
+					if err != nil || !hasKey || attachedTo.AuthorizedBy.RAID == "" {
+						return
+					}
+
+					dmsID := attachedTo.AuthorizedBy.RAID
+					dmsCerts[i][dmsID]++
+					if dmsDevices[i][dmsID] == nil {
+						dmsDevices[i][dmsID] = map[string]bool{}
+					}
+					if attachedTo.DeviceID != "" {
+						dmsDevices[i][dmsID][attachedTo.DeviceID] = true
+					}
+				},
+			},
+		})
+		if err != nil {
+			return nil, fmt.Errorf("could not get expiring certificates: %w", err)
+		}
+
+		if next == "" || next == bookmark {
+			break
+		}
+		bookmark = next
+	}
+
+	for i := range windows {
+		sort.Slice(windows[i].CAs, func(a, b int) bool {
+			return windows[i].CAs[a].ValidTo.Before(windows[i].CAs[b].ValidTo)
+		})
+
+		for dmsID, certs := range dmsCerts[i] {
+			windows[i].DMSs = append(windows[i].DMSs, models.ExpiringDMSSummary{
+				DMSID:        dmsID,
+				Certificates: certs,
+				Devices:      len(dmsDevices[i][dmsID]),
+			})
+		}
+
+		sort.Slice(windows[i].DMSs, func(a, b int) bool {
+			return windows[i].DMSs[a].DMSID < windows[i].DMSs[b].DMSID
+		})
+	}
+
+	return &models.ExpirationDigest{
+		GeneratedAt: now,
+		Windows:     windows,
+	}, nil
+}
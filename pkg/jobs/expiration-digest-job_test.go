@@ -0,0 +1,142 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type stubAlertsService struct {
+	services.AlertsService
+	digests []models.ExpirationDigest
+}
+
+func (s *stubAlertsService) SendExpirationDigest(ctx context.Context, input *services.SendExpirationDigestInput) error {
+	s.digests = append(s.digests, input.Digest)
+	return nil
+}
+
+func expiringCA(id string, validTo time.Time) models.CACertificate {
+	return models.CACertificate{
+		ID: id,
+		Certificate: models.Certificate{
+			Status:  models.StatusActive,
+			Subject: models.Subject{CommonName: id},
+			ValidTo: validTo,
+		},
+	}
+}
+
+func expiringCertificate(sn string, dmsID string, deviceID string, validTo time.Time) models.Certificate {
+	crt := models.Certificate{
+		SerialNumber: sn,
+		Status:       models.StatusActive,
+		ValidTo:      validTo,
+		Metadata:     map[string]interface{}{},
+	}
+
+	if dmsID != "" {
+		crt.Metadata[models.CAAttachedToDeviceKey] = map[string]any{
+			"authorized_by": map[string]any{"ra_id": dmsID},
+			"device_id":     deviceID,
+		}
+	}
+
+	return crt
+}
+
+func newTestExpirationDigester(t *testing.T, now time.Time) (*ExpirationDigester, *svcmock.MockCAService, *stubAlertsService) {
+	caService := new(svcmock.MockCAService)
+	alerts := &stubAlertsService{}
+
+	digester, err := NewExpirationDigester(caService, alerts, nil, logrus.NewEntry(logrus.StandardLogger()))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	day := 24 * time.Hour
+	caService.On("GetCAs", mock.Anything, mock.MatchedBy(func(input services.GetCAsInput) bool {
+		return input.QueryParameters.NextBookmark == ""
+	})).Run(func(args mock.Arguments) {
+		input := args.Get(1).(services.GetCAsInput)
+		input.ApplyFunc(expiringCA("ca-45d", now.Add(45*day)))
+	}).Return("page-2", nil)
+	caService.On("GetCAs", mock.Anything, mock.MatchedBy(func(input services.GetCAsInput) bool {
+		return input.QueryParameters.NextBookmark == "page-2"
+	})).Run(func(args mock.Arguments) {
+		input := args.Get(1).(services.GetCAsInput)
+		input.ApplyFunc(expiringCA("ca-10d", now.Add(10*day)))
+		input.ApplyFunc(expiringCA("ca-5d", now.Add(5*day)))
+	}).Return("", nil)
+
+	caService.On("GetCertificates", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input := args.Get(1).(services.GetCertificatesInput)
+		input.ApplyFunc(expiringCertificate("1", "dms-1", "device-1", now.Add(20*day)))
+		input.ApplyFunc(expiringCertificate("2", "dms-1", "device-1", now.Add(25*day)))
+		input.ApplyFunc(expiringCertificate("3", "dms-1", "device-2", now.Add(29*day)))
+		input.ApplyFunc(expiringCertificate("4", "dms-2", "device-3", now.Add(80*day)))
+		input.ApplyFunc(expiringCertificate("5", "", "", now.Add(10*day)))
+	}).Return("", nil)
+
+	return digester, caService, alerts
+}
+
+func TestExpirationDigesterDigest(t *testing.T) {
+	now := time.Now()
+	digester, caService, _ := newTestExpirationDigester(t, now)
+
+	digest, err := digester.Digest(context.Background(), now)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	caService.AssertExpectations(t)
+	assert.Len(t, digest.Windows, 3)
+
+	within30d := digest.Windows[0]
+	assert.Equal(t, "30d", within30d.Window)
+	assert.Equal(t, []string{"ca-5d", "ca-10d"}, []string{within30d.CAs[0].ID, within30d.CAs[1].ID})
+	assert.Equal(t, []models.ExpiringDMSSummary{{DMSID: "dms-1", Certificates: 3, Devices: 2}}, within30d.DMSs)
+
+	within60d := digest.Windows[1]
+	assert.Equal(t, within30d.To, within60d.From)
+	assert.Len(t, within60d.CAs, 1)
+	assert.Empty(t, within60d.DMSs)
+
+	within90d := digest.Windows[2]
+	assert.Empty(t, within90d.CAs)
+	assert.Equal(t, []models.ExpiringDMSSummary{{DMSID: "dms-2", Certificates: 1, Devices: 1}}, within90d.DMSs)
+}
+
+func TestExpirationDigesterRunSendsDigest(t *testing.T) {
+	digester, _, alerts := newTestExpirationDigester(t, time.Now())
+
+	digester.Run()
+
+	assert.Len(t, alerts.digests, 1)
+}
+
+func TestExpirationDigesterDoesNotSendOnError(t *testing.T) {
+	caService := new(svcmock.MockCAService)
+	alerts := &stubAlertsService{}
+	digester, _ := NewExpirationDigester(caService, alerts, []string{"7d"}, logrus.NewEntry(logrus.StandardLogger()))
+
+	caService.On("GetCAs", mock.Anything, mock.Anything).Return("", errors.New("some error"))
+
+	digester.Run()
+
+	assert.Empty(t, alerts.digests)
+}
+
+func TestNewExpirationDigesterInvalidWindow(t *testing.T) {
+	_, err := NewExpirationDigester(nil, nil, []string{"30d", "soon"}, logrus.NewEntry(logrus.StandardLogger()))
+	assert.Error(t, err)
+}
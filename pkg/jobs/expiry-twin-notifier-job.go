@@ -0,0 +1,99 @@
+package jobs
+
+import (
+	"context"
+	"math"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// DeviceTwinExpiryWriter writes the expiration state of the device identity certificate
+// into the device digital twin (i.e. AWS IoT shadow) managed by a cloud connector.
+type DeviceTwinExpiryWriter interface {
+	NotifyCertificateExpiry(ctx context.Context, device models.Device, cert models.Certificate, daysLeft int) error
+}
+
+// ExpiryTwinNotifier flags the digital twin of the devices whose identity certificate expires
+// within the configured number of days, so device-side agents can re-enroll proactively.
+type ExpiryTwinNotifier struct {
+	logger           *logrus.Entry
+	deviceService    services.DeviceManagerService
+	caService        services.CAService
+	twinWriter       DeviceTwinExpiryWriter
+	notificationDays int
+}
+
+func NewExpiryTwinNotifier(deviceService services.DeviceManagerService, caService services.CAService, twinWriter DeviceTwinExpiryWriter, notificationDays int, logger *logrus.Entry) *ExpiryTwinNotifier {
+	return &ExpiryTwinNotifier{
+		deviceService:    deviceService,
+		caService:        caService,
+		twinWriter:       twinWriter,
+		notificationDays: notificationDays,
+		logger:           logger,
+	}
+}
+
+func (job *ExpiryTwinNotifier) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Info("starting periodic check for device certificates about to expire")
+
+	_, err := job.deviceService.GetDevices(ctx, services.GetDevicesInput{
+		ListInput: resources.ListInput[models.Device]{
+			QueryParameters: nil,
+			ExhaustiveRun:   true,
+			ApplyFunc: func(device models.Device) {
+				job.notifyIfNeeded(ctx, device, now)
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate devices: %s", err)
+	}
+
+	end := time.Now()
+	lFunc.Infof("ending check. Took %v", end.Sub(now))
+}
+
+func (job *ExpiryTwinNotifier) notifyIfNeeded(ctx context.Context, device models.Device, now time.Time) {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	if device.IdentitySlot == nil || device.Status == models.DeviceDecommissioned {
+		return
+	}
+
+	sn, ok := device.IdentitySlot.Secrets[device.IdentitySlot.ActiveVersion]
+	if !ok {
+		return
+	}
+
+	cert, err := job.caService.GetCertificateBySerialNumber(ctx, services.GetCertificatesBySerialNumberInput{
+		SerialNumber: sn,
+	})
+	if err != nil {
+		lFunc.Errorf("could not get certificate %s for device %s: %s", sn, device.ID, err)
+		return
+	}
+
+	if cert.Status != models.StatusActive {
+		return
+	}
+
+	daysLeft := int(math.Ceil(cert.ValidTo.Sub(now).Hours() / 24))
+	if daysLeft > job.notificationDays {
+		return
+	}
+
+	lFunc.Debugf("device %s certificate %s expires in %d days", device.ID, sn, daysLeft)
+	err = job.twinWriter.NotifyCertificateExpiry(ctx, device, *cert, daysLeft)
+	if err != nil {
+		lFunc.Errorf("could not update digital twin for device %s: %s", device.ID, err)
+	}
+}
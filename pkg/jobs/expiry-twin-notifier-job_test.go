@@ -0,0 +1,88 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockTwinWriter struct {
+	mock.Mock
+}
+
+func (m *mockTwinWriter) NotifyCertificateExpiry(ctx context.Context, device models.Device, cert models.Certificate, daysLeft int) error {
+	args := m.Called(ctx, device, cert, daysLeft)
+	return args.Error(0)
+}
+
+func expiryTestDevice() models.Device {
+	return models.Device{
+		ID:     "device-1",
+		Status: models.DeviceActive,
+		IdentitySlot: &models.Slot[string]{
+			ActiveVersion: 0,
+			Secrets:       map[int]string{0: "11-22"},
+		},
+	}
+}
+
+func TestExpiryTwinNotifierWithinNotificationWindow(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+	twinWriter := new(mockTwinWriter)
+
+	notifier := NewExpiryTwinNotifier(mockDeviceService, mockCAService, twinWriter, 30, logrus.NewEntry(logrus.StandardLogger()))
+
+	now := time.Now()
+	cert := &models.Certificate{
+		SerialNumber: "11-22",
+		Status:       models.StatusActive,
+		ValidTo:      now.Add(10 * 24 * time.Hour),
+	}
+
+	mockCAService.On("GetCertificateBySerialNumber", mock.Anything, mock.Anything).Return(cert, nil)
+	twinWriter.On("NotifyCertificateExpiry", mock.Anything, mock.Anything, *cert, 10).Return(nil)
+
+	notifier.notifyIfNeeded(context.Background(), expiryTestDevice(), now)
+
+	twinWriter.AssertExpectations(t)
+}
+
+func TestExpiryTwinNotifierOutsideNotificationWindow(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+	twinWriter := new(mockTwinWriter)
+
+	notifier := NewExpiryTwinNotifier(mockDeviceService, mockCAService, twinWriter, 30, logrus.NewEntry(logrus.StandardLogger()))
+
+	now := time.Now()
+	cert := &models.Certificate{
+		SerialNumber: "11-22",
+		Status:       models.StatusActive,
+		ValidTo:      now.Add(90 * 24 * time.Hour),
+	}
+
+	mockCAService.On("GetCertificateBySerialNumber", mock.Anything, mock.Anything).Return(cert, nil)
+
+	notifier.notifyIfNeeded(context.Background(), expiryTestDevice(), now)
+
+	twinWriter.AssertNotCalled(t, "NotifyCertificateExpiry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
+
+func TestExpiryTwinNotifierDeviceWithoutIdentity(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+	twinWriter := new(mockTwinWriter)
+
+	notifier := NewExpiryTwinNotifier(mockDeviceService, mockCAService, twinWriter, 30, logrus.NewEntry(logrus.StandardLogger()))
+
+	notifier.notifyIfNeeded(context.Background(), models.Device{ID: "device-1", Status: models.DeviceNoIdentity}, time.Now())
+
+	mockCAService.AssertNotCalled(t, "GetCertificateBySerialNumber", mock.Anything, mock.Anything)
+	twinWriter.AssertNotCalled(t, "NotifyCertificateExpiry", mock.Anything, mock.Anything, mock.Anything, mock.Anything)
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// IssuanceReportPublisher publishes the generated reports to the event bus. Users subscribed to
+// the report event type through the alerts service receive it by email (or any other channel).
+type IssuanceReportPublisher interface {
+	PublishCloudEvent(ctx context.Context, eventType models.EventType, payload interface{})
+}
+
+// IssuanceReporter periodically generates the issuance report covering the last period and publishes it.
+type IssuanceReporter struct {
+	logger        *logrus.Entry
+	deviceService services.DeviceManagerService
+	publisher     IssuanceReportPublisher
+	period        time.Duration
+}
+
+func NewIssuanceReporter(deviceService services.DeviceManagerService, publisher IssuanceReportPublisher, period time.Duration, logger *logrus.Entry) *IssuanceReporter {
+	return &IssuanceReporter{
+		deviceService: deviceService,
+		publisher:     publisher,
+		period:        period,
+		logger:        logger,
+	}
+}
+
+func (job *IssuanceReporter) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Infof("generating issuance report for the last %s", job.period)
+
+	report, err := job.deviceService.GetIssuanceReport(ctx, services.GetIssuanceReportInput{
+		From: now.Add(-job.period),
+		To:   now,
+	})
+	if err != nil {
+		lFunc.Errorf("could not generate issuance report: %s", err)
+		return
+	}
+
+	job.publisher.PublishCloudEvent(ctx, models.EventIssuanceReportKey, report)
+
+	end := time.Now()
+	lFunc.Infof("issuance report published. Took %v", end.Sub(now))
+}
@@ -0,0 +1,56 @@
+package jobs
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+type mockReportPublisher struct {
+	mock.Mock
+}
+
+func (m *mockReportPublisher) PublishCloudEvent(ctx context.Context, eventType models.EventType, payload interface{}) {
+	m.Called(ctx, eventType, payload)
+}
+
+func TestIssuanceReporterPublishesReport(t *testing.T) {
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+	publisher := new(mockReportPublisher)
+
+	reporter := NewIssuanceReporter(mockDeviceService, publisher, 24*time.Hour, logrus.NewEntry(logrus.StandardLogger()))
+
+	report := &models.IssuanceReport{
+		CAs: []models.IssuanceActivity{{ID: "ca-1", Issued: 2}},
+	}
+
+	mockDeviceService.On("GetIssuanceReport", mock.Anything, mock.MatchedBy(func(input services.GetIssuanceReportInput) bool {
+		return input.To.Sub(input.From) == 24*time.Hour
+	})).Return(report, nil)
+	publisher.On("PublishCloudEvent", mock.Anything, models.EventIssuanceReportKey, report)
+
+	reporter.Run()
+
+	mockDeviceService.AssertExpectations(t)
+	publisher.AssertExpectations(t)
+}
+
+func TestIssuanceReporterDoesNotPublishOnError(t *testing.T) {
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+	publisher := new(mockReportPublisher)
+
+	reporter := NewIssuanceReporter(mockDeviceService, publisher, 24*time.Hour, logrus.NewEntry(logrus.StandardLogger()))
+
+	mockDeviceService.On("GetIssuanceReport", mock.Anything, mock.Anything).Return((*models.IssuanceReport)(nil), errors.New("some error"))
+
+	reporter.Run()
+
+	publisher.AssertNotCalled(t, "PublishCloudEvent", mock.Anything, mock.Anything, mock.Anything)
+}
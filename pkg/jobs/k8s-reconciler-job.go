@@ -0,0 +1,248 @@
+package jobs
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// KubernetesResources lists the Lamassu custom resources and updates their status.
+type KubernetesResources interface {
+	ListCertificateAuthorities(ctx context.Context) ([]models.CertificateAuthorityResource, error)
+	ListDMSs(ctx context.Context) ([]models.DMSResource, error)
+	UpdateCertificateAuthorityStatus(ctx context.Context, resource models.CertificateAuthorityResource) error
+	UpdateDMSStatus(ctx context.Context, resource models.DMSResource) error
+}
+
+// KubernetesReconciler drives the CAs and DMSs to the state declared by the CertificateAuthority and DMS custom
+// resources: missing CAs and DMSs are created, CAs are revoked and DMS registrations approved. The outcome is
+// reported in the status of each resource. Deleting a resource does not delete the CA nor the DMS.
+type KubernetesReconciler struct {
+	logger     *logrus.Entry
+	resources  KubernetesResources
+	caService  services.CAService
+	dmsService services.DMSManagerService
+}
+
+func NewKubernetesReconciler(resources KubernetesResources, caService services.CAService, dmsService services.DMSManagerService, logger *logrus.Entry) *KubernetesReconciler {
+	return &KubernetesReconciler{
+		logger:     logger,
+		resources:  resources,
+		caService:  caService,
+		dmsService: dmsService,
+	}
+}
+
+func (job *KubernetesReconciler) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Info("starting reconciliation of Kubernetes resources")
+
+	// CAs go first so the DMSs can reference the CAs declared along with them
+	cas, err := job.resources.ListCertificateAuthorities(ctx)
+	if err != nil {
+		lFunc.Errorf("could not list CertificateAuthority resources: %s", err)
+	}
+
+	for _, resource := range cas {
+		status := job.reconcileCA(ctx, resource)
+		if status != resource.Status {
+			resource.Status = status
+			err = job.resources.UpdateCertificateAuthorityStatus(ctx, resource)
+			if err != nil {
+				lFunc.Errorf("could not update status of CertificateAuthority %s/%s: %s", resource.Metadata.Namespace, resource.Metadata.Name, err)
+			}
+		}
+	}
+
+	dmss, err := job.resources.ListDMSs(ctx)
+	if err != nil {
+		lFunc.Errorf("could not list DMS resources: %s", err)
+	}
+
+	for _, resource := range dmss {
+		status := job.reconcileDMS(ctx, resource)
+		if status != resource.Status {
+			resource.Status = status
+			err = job.resources.UpdateDMSStatus(ctx, resource)
+			if err != nil {
+				lFunc.Errorf("could not update status of DMS %s/%s: %s", resource.Metadata.Namespace, resource.Metadata.Name, err)
+			}
+		}
+	}
+
+	end := time.Now()
+	lFunc.Infof("ending reconciliation. Took %v", end.Sub(now))
+}
+
+func (job *KubernetesReconciler) reconcileCA(ctx context.Context, resource models.CertificateAuthorityResource) models.KubernetesResourceStatus {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	spec := resource.Spec
+	id := spec.ID
+	if id == "" {
+		id = resource.Metadata.Name
+	}
+
+	status := models.KubernetesResourceStatus{
+		ID:                 id,
+		ObservedGeneration: resource.Metadata.Generation,
+	}
+	failed := func(err error) models.KubernetesResourceStatus {
+		lFunc.Errorf("could not reconcile CertificateAuthority %s/%s: %s", resource.Metadata.Namespace, resource.Metadata.Name, err)
+		status.Phase = models.KubernetesResourceFailed
+		status.Message = err.Error()
+		return status
+	}
+
+	ca, err := job.caService.GetCAByID(ctx, services.GetCAByIDInput{CAID: id})
+	if err == errs.ErrCANotFound {
+		lFunc.Infof("creating CA %s declared by CertificateAuthority %s/%s", id, resource.Metadata.Namespace, resource.Metadata.Name)
+		ca, err = job.caService.CreateCA(ctx, services.CreateCAInput{
+			ID:                 id,
+			ParentID:           spec.ParentID,
+			KeyMetadata:        spec.KeyMetadata,
+			Subject:            spec.Subject,
+			IssuanceExpiration: spec.IssuanceExpiration,
+			CAExpiration:       spec.CAExpiration,
+			EngineID:           spec.EngineID,
+			Metadata:           spec.Metadata,
+		})
+	}
+	if err != nil {
+		return failed(err)
+	}
+
+	if spec.Revoked && ca.Certificate.Status != models.StatusRevoked {
+		lFunc.Infof("revoking CA %s as declared by CertificateAuthority %s/%s", id, resource.Metadata.Namespace, resource.Metadata.Name)
+		ca, err = job.caService.UpdateCAStatus(ctx, services.UpdateCAStatusInput{
+			CAID:   id,
+			Status: models.StatusRevoked,
+		})
+		if err != nil {
+			return failed(err)
+		}
+	}
+
+	switch {
+	case ca.Certificate.Status == models.StatusRevoked && !spec.Revoked:
+		status.Phase = models.KubernetesResourceFailed
+		status.Message = "CA is revoked and can not be reactivated"
+	case ca.Certificate.Status == models.StatusRevoked:
+		status.Phase = models.KubernetesResourceRevoked
+	default:
+		status.Phase = models.KubernetesResourceReady
+		status.Message = string(ca.Certificate.Status)
+	}
+
+	return status
+}
+
+func (job *KubernetesReconciler) reconcileDMS(ctx context.Context, resource models.DMSResource) models.KubernetesResourceStatus {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	spec := resource.Spec
+	id := spec.ID
+	if id == "" {
+		id = resource.Metadata.Name
+	}
+
+	status := models.KubernetesResourceStatus{
+		ID:                 id,
+		ObservedGeneration: resource.Metadata.Generation,
+	}
+	failed := func(err error) models.KubernetesResourceStatus {
+		lFunc.Errorf("could not reconcile DMS %s/%s: %s", resource.Metadata.Namespace, resource.Metadata.Name, err)
+		status.Phase = models.KubernetesResourceFailed
+		status.Message = err.Error()
+		return status
+	}
+
+	dms, err := job.dmsService.GetDMSByID(ctx, services.GetDMSByIDInput{ID: id})
+	if err == errs.ErrDMSNotFound {
+		lFunc.Infof("creating DMS %s declared by DMS %s/%s", id, resource.Metadata.Namespace, resource.Metadata.Name)
+		metadata := spec.Metadata
+		if metadata == nil {
+			metadata = map[string]any{}
+		}
+
+		dms, err = job.dmsService.CreateDMS(ctx, services.CreateDMSInput{
+			ID:       id,
+			Name:     spec.Name,
+			Metadata: metadata,
+			Settings: spec.Settings,
+		})
+	} else if err == nil && dmsDrifted(*dms, spec) {
+		lFunc.Infof("updating DMS %s to match DMS %s/%s", id, resource.Metadata.Namespace, resource.Metadata.Name)
+		updated := *dms
+		updated.Name = spec.Name
+		updated.Settings = spec.Settings
+		if spec.Metadata != nil {
+			updated.Metadata = spec.Metadata
+		}
+
+		dms, err = job.dmsService.UpdateDMS(ctx, services.UpdateDMSInput{DMS: updated})
+	}
+	if err != nil {
+		return failed(err)
+	}
+
+	if spec.Approved != nil {
+		desired := models.PendingApprovalDMSStatus
+		if *spec.Approved {
+			desired = models.ActiveDMSStatus
+		}
+
+		if dmsStatus(*dms) != desired {
+			lFunc.Infof("setting DMS %s status to %s as declared by DMS %s/%s", id, desired, resource.Metadata.Namespace, resource.Metadata.Name)
+			dms, err = job.dmsService.UpdateDMSStatus(ctx, services.UpdateDMSStatusInput{ID: id, Status: desired})
+			if err != nil {
+				return failed(err)
+			}
+		}
+	}
+
+	status.Phase = models.KubernetesResourceReady
+	if dmsStatus(*dms) == models.PendingApprovalDMSStatus {
+		status.Phase = models.KubernetesResourcePendingApproval
+	}
+
+	return status
+}
+
+// dmsStatus returns the status of the DMS. DMSs without status are ACTIVE.
+func dmsStatus(dms models.DMS) models.DMSStatus {
+	if dms.Status == "" {
+		return models.ActiveDMSStatus
+	}
+
+	return dms.Status
+}
+
+// dmsDrifted reports whether the DMS name, settings or (if declared) metadata differ from the spec.
+func dmsDrifted(dms models.DMS, spec models.DMSSpec) bool {
+	if dms.Name != spec.Name {
+		return true
+	}
+
+	if !jsonEqual(dms.Settings, spec.Settings) {
+		return true
+	}
+
+	return spec.Metadata != nil && !jsonEqual(dms.Metadata, spec.Metadata)
+}
+
+func jsonEqual(a, b any) bool {
+	aBytes, errA := json.Marshal(a)
+	bBytes, errB := json.Marshal(b)
+	return errA == nil && errB == nil && bytes.Equal(aBytes, bBytes)
+}
@@ -0,0 +1,176 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeKubernetesResources struct {
+	cas        []models.CertificateAuthorityResource
+	dmss       []models.DMSResource
+	caStatus   map[string]models.KubernetesResourceStatus
+	dmsStatus  map[string]models.KubernetesResourceStatus
+	statusPuts int
+}
+
+func (f *fakeKubernetesResources) ListCertificateAuthorities(ctx context.Context) ([]models.CertificateAuthorityResource, error) {
+	return f.cas, nil
+}
+
+func (f *fakeKubernetesResources) ListDMSs(ctx context.Context) ([]models.DMSResource, error) {
+	return f.dmss, nil
+}
+
+func (f *fakeKubernetesResources) UpdateCertificateAuthorityStatus(ctx context.Context, resource models.CertificateAuthorityResource) error {
+	f.statusPuts++
+	f.caStatus[resource.Metadata.Name] = resource.Status
+	return nil
+}
+
+func (f *fakeKubernetesResources) UpdateDMSStatus(ctx context.Context, resource models.DMSResource) error {
+	f.statusPuts++
+	f.dmsStatus[resource.Metadata.Name] = resource.Status
+	return nil
+}
+
+func newFakeKubernetesResources() *fakeKubernetesResources {
+	return &fakeKubernetesResources{
+		caStatus:  map[string]models.KubernetesResourceStatus{},
+		dmsStatus: map[string]models.KubernetesResourceStatus{},
+	}
+}
+
+func caWithStatus(id string, status models.CertificateStatus) *models.CACertificate {
+	ca := &models.CACertificate{ID: id}
+	ca.Certificate.Status = status
+	return ca
+}
+
+func TestKubernetesReconcilerCreatesMissingCA(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+	resources := newFakeKubernetesResources()
+	resources.cas = []models.CertificateAuthorityResource{
+		{
+			Metadata: models.KubernetesObjectMeta{Name: "root-ca", Namespace: "pki", Generation: 2},
+			Spec:     models.CertificateAuthoritySpec{Subject: models.Subject{CommonName: "Root CA"}},
+		},
+	}
+
+	mockCAService.On("GetCAByID", mock.Anything, services.GetCAByIDInput{CAID: "root-ca"}).Return((*models.CACertificate)(nil), errs.ErrCANotFound)
+	mockCAService.On("CreateCA", mock.Anything, mock.MatchedBy(func(input services.CreateCAInput) bool {
+		return input.ID == "root-ca" && input.Subject.CommonName == "Root CA"
+	})).Return(caWithStatus("root-ca", models.StatusActive), nil)
+
+	NewKubernetesReconciler(resources, mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger())).Run()
+
+	mockCAService.AssertCalled(t, "CreateCA", mock.Anything, mock.Anything)
+	assert.Equal(t, models.KubernetesResourceStatus{
+		ID:                 "root-ca",
+		Phase:              models.KubernetesResourceReady,
+		Message:            string(models.StatusActive),
+		ObservedGeneration: 2,
+	}, resources.caStatus["root-ca"])
+}
+
+func TestKubernetesReconcilerRevokesCA(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+	resources := newFakeKubernetesResources()
+	resources.cas = []models.CertificateAuthorityResource{
+		{
+			Metadata: models.KubernetesObjectMeta{Name: "issuing", Namespace: "pki"},
+			Spec:     models.CertificateAuthoritySpec{ID: "issuing-ca", Revoked: true},
+		},
+	}
+
+	mockCAService.On("GetCAByID", mock.Anything, services.GetCAByIDInput{CAID: "issuing-ca"}).Return(caWithStatus("issuing-ca", models.StatusActive), nil)
+	mockCAService.On("UpdateCAStatus", mock.Anything, mock.MatchedBy(func(input services.UpdateCAStatusInput) bool {
+		return input.CAID == "issuing-ca" && input.Status == models.StatusRevoked
+	})).Return(caWithStatus("issuing-ca", models.StatusRevoked), nil)
+
+	NewKubernetesReconciler(resources, mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger())).Run()
+
+	mockCAService.AssertNumberOfCalls(t, "UpdateCAStatus", 1)
+	assert.Equal(t, models.KubernetesResourceRevoked, resources.caStatus["issuing"].Phase)
+	assert.Equal(t, "issuing-ca", resources.caStatus["issuing"].ID)
+}
+
+func TestKubernetesReconcilerSkipsUnchangedStatus(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+	resources := newFakeKubernetesResources()
+	resources.cas = []models.CertificateAuthorityResource{
+		{
+			Metadata: models.KubernetesObjectMeta{Name: "root-ca", Namespace: "pki"},
+			Status: models.KubernetesResourceStatus{
+				ID:      "root-ca",
+				Phase:   models.KubernetesResourceReady,
+				Message: string(models.StatusActive),
+			},
+		},
+	}
+
+	mockCAService.On("GetCAByID", mock.Anything, mock.Anything).Return(caWithStatus("root-ca", models.StatusActive), nil)
+
+	NewKubernetesReconciler(resources, mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger())).Run()
+
+	mockCAService.AssertNotCalled(t, "CreateCA", mock.Anything, mock.Anything)
+	assert.Equal(t, 0, resources.statusPuts)
+}
+
+func TestKubernetesReconcilerCreatesAndApprovesDMS(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+	approved := true
+	resources := newFakeKubernetesResources()
+	resources.dmss = []models.DMSResource{
+		{
+			Metadata: models.KubernetesObjectMeta{Name: "factory", Namespace: "pki"},
+			Spec:     models.DMSSpec{Name: "Factory", Approved: &approved},
+		},
+	}
+
+	mockDMSService.On("GetDMSByID", mock.Anything, services.GetDMSByIDInput{ID: "factory"}).Return((*models.DMS)(nil), errs.ErrDMSNotFound)
+	mockDMSService.On("CreateDMS", mock.Anything, mock.MatchedBy(func(input services.CreateDMSInput) bool {
+		return input.ID == "factory" && input.Name == "Factory"
+	})).Return(&models.DMS{ID: "factory", Name: "Factory", Status: models.PendingApprovalDMSStatus}, nil)
+	mockDMSService.On("UpdateDMSStatus", mock.Anything, services.UpdateDMSStatusInput{ID: "factory", Status: models.ActiveDMSStatus}).Return(&models.DMS{ID: "factory", Name: "Factory", Status: models.ActiveDMSStatus}, nil)
+
+	NewKubernetesReconciler(resources, mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger())).Run()
+
+	mockDMSService.AssertNumberOfCalls(t, "UpdateDMSStatus", 1)
+	assert.Equal(t, models.KubernetesResourceReady, resources.dmsStatus["factory"].Phase)
+}
+
+func TestKubernetesReconcilerUpdatesDriftedDMS(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+	resources := newFakeKubernetesResources()
+	spec := models.DMSSpec{ID: "factory", Name: "Factory"}
+	spec.Settings.EnrollmentSettings.EnrollmentCA = "issuing-ca"
+	resources.dmss = []models.DMSResource{
+		{Metadata: models.KubernetesObjectMeta{Name: "factory", Namespace: "pki"}, Spec: spec},
+	}
+
+	current := &models.DMS{ID: "factory", Name: "Factory", Status: models.PendingApprovalDMSStatus, Metadata: map[string]any{"owner": "ops"}}
+	current.Settings.EnrollmentSettings.EnrollmentCA = "old-ca"
+	mockDMSService.On("GetDMSByID", mock.Anything, mock.Anything).Return(current, nil)
+	mockDMSService.On("UpdateDMS", mock.Anything, mock.MatchedBy(func(input services.UpdateDMSInput) bool {
+		return input.DMS.Settings.EnrollmentSettings.EnrollmentCA == "issuing-ca" && input.DMS.Metadata["owner"] == "ops"
+	})).Return(&models.DMS{ID: "factory", Name: "Factory", Status: models.PendingApprovalDMSStatus}, nil)
+
+	NewKubernetesReconciler(resources, mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger())).Run()
+
+	mockDMSService.AssertNumberOfCalls(t, "UpdateDMS", 1)
+	mockDMSService.AssertNotCalled(t, "UpdateDMSStatus", mock.Anything, mock.Anything)
+	assert.Equal(t, models.KubernetesResourcePendingApproval, resources.dmsStatus["factory"].Phase)
+}
@@ -0,0 +1,98 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// SecretSlotRotator rotates the PSK and SAS token slots whose active secret expires within the rotation window
+// configured in the slot profile.
+type SecretSlotRotator struct {
+	logger        *logrus.Entry
+	deviceService services.DeviceManagerService
+}
+
+func NewSecretSlotRotator(deviceService services.DeviceManagerService, logger *logrus.Entry) *SecretSlotRotator {
+	return &SecretSlotRotator{
+		deviceService: deviceService,
+		logger:        logger,
+	}
+}
+
+type dueSecretSlot struct {
+	deviceID string
+	slotID   string
+}
+
+func (job *SecretSlotRotator) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Info("starting periodic check for device secret slots to rotate")
+
+	// slots are rotated once the iteration ends, so devices are not updated while being listed
+	due := []dueSecretSlot{}
+	_, err := job.deviceService.GetDevices(ctx, services.GetDevicesInput{
+		ListInput: resources.ListInput[models.Device]{
+			QueryParameters: nil,
+			ExhaustiveRun:   true,
+			ApplyFunc: func(device models.Device) {
+				due = append(due, dueSecretSlots(device, now)...)
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate devices: %s", err)
+		return
+	}
+
+	for _, slot := range due {
+		job.rotate(ctx, slot)
+	}
+
+	end := time.Now()
+	lFunc.Infof("ending check. Rotated %d slots. Took %v", len(due), end.Sub(now))
+}
+
+func (job *SecretSlotRotator) rotate(ctx context.Context, slot dueSecretSlot) {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	_, err := job.deviceService.RotateDeviceSecretSlot(ctx, services.RotateDeviceSecretSlotInput{
+		DeviceID: slot.deviceID,
+		SlotID:   slot.slotID,
+	})
+	if err != nil {
+		lFunc.Errorf("could not rotate slot '%s' of device '%s': %s", slot.slotID, slot.deviceID, err)
+	}
+}
+
+func dueSecretSlots(device models.Device, now time.Time) []dueSecretSlot {
+	due := []dueSecretSlot{}
+	if device.Status == models.DeviceDecommissioned {
+		return due
+	}
+
+	for slotID, slot := range device.ExtraSlots {
+		if slot == nil || slot.Profile == nil || !models.IsSymmetricSlotType(slot.SecretType) {
+			continue
+		}
+
+		secret, err := helpers.DecodeSymmetricSecret(slot.Secrets[slot.ActiveVersion])
+		if err != nil {
+			continue
+		}
+
+		if helpers.SymmetricSlotSecretRotationDue(*secret, *slot.Profile, now) {
+			due = append(due, dueSecretSlot{deviceID: device.ID, slotID: slotID})
+		}
+	}
+
+	return due
+}
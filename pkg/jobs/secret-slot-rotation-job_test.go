@@ -0,0 +1,66 @@
+package jobs
+
+import (
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func secretSlot(secretType models.CryptoSecretType, expiresAt time.Time) *models.Slot[any] {
+	return &models.Slot[any]{
+		SecretType:    secretType,
+		ActiveVersion: 1,
+		Secrets: map[int]any{
+			0: models.SymmetricSecret{ExpiresAt: expiresAt.Add(-time.Hour)},
+			1: models.SymmetricSecret{ExpiresAt: expiresAt},
+		},
+		Profile: &models.SymmetricSlotProfile{RotateBefore: models.TimeDuration(time.Hour)},
+	}
+}
+
+func TestSecretSlotRotatorRotatesDueSlots(t *testing.T) {
+	mockDeviceService := new(svcmock.MockDeviceManagerService)
+	rotator := NewSecretSlotRotator(mockDeviceService, logrus.NewEntry(logrus.StandardLogger()))
+
+	now := time.Now()
+	devices := []models.Device{
+		{
+			ID:     "dev1",
+			Status: models.DeviceActive,
+			ExtraSlots: map[string]*models.Slot[any]{
+				"azure":  secretSlot(models.SASTokenSlotProfileType, now.Add(30*time.Minute)),
+				"psk":    secretSlot(models.PSKSlotProfileType, now.Add(48*time.Hour)),
+				"ssh":    {SecretType: models.SshKeySlotProfileType, Secrets: map[int]any{0: "ssh-rsa AAAA"}},
+				"static": secretSlot(models.PSKSlotProfileType, time.Time{}),
+			},
+		},
+		{
+			ID:     "dev2",
+			Status: models.DeviceDecommissioned,
+			ExtraSlots: map[string]*models.Slot[any]{
+				"azure": secretSlot(models.SASTokenSlotProfileType, now.Add(-time.Minute)),
+			},
+		},
+	}
+
+	mockDeviceService.On("GetDevices", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+		input := args.Get(1).(services.GetDevicesInput)
+		for _, device := range devices {
+			input.ApplyFunc(device)
+		}
+	}).Return("", nil)
+	mockDeviceService.On("RotateDeviceSecretSlot", mock.Anything, mock.Anything).Return(&models.Device{}, nil)
+
+	rotator.Run()
+
+	mockDeviceService.AssertNumberOfCalls(t, "RotateDeviceSecretSlot", 1)
+	mockDeviceService.AssertCalled(t, "RotateDeviceSecretSlot", mock.Anything, services.RotateDeviceSecretSlotInput{
+		DeviceID: "dev1",
+		SlotID:   "azure",
+	})
+}
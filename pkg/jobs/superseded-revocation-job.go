@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+// SupersededRevocator revokes the certificates replaced by a reenrollment once the grace period
+// configured in the DMS that authorized the reenrollment has elapsed.
+type SupersededRevocator struct {
+	logger     *logrus.Entry
+	caService  services.CAService
+	dmsService services.DMSManagerService
+}
+
+func NewSupersededRevocator(caService services.CAService, dmsService services.DMSManagerService, logger *logrus.Entry) *SupersededRevocator {
+	return &SupersededRevocator{
+		caService:  caService,
+		dmsService: dmsService,
+		logger:     logger,
+	}
+}
+
+func (job *SupersededRevocator) Run() {
+	ctx := helpers.InitContext()
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	now := time.Now()
+	lFunc.Info("starting periodic check for superseded certificates pending revocation")
+
+	_, err := job.caService.GetCertificatesByStatus(ctx, services.GetCertificatesByStatusInput{
+		Status: models.StatusActive,
+		ListInput: resources.ListInput[models.Certificate]{
+			QueryParameters: nil,
+			ExhaustiveRun:   true,
+			ApplyFunc: func(cert models.Certificate) {
+				job.revokeIfNeeded(ctx, cert, now)
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate active certificates: %s", err)
+	}
+
+	end := time.Now()
+	lFunc.Infof("ending check. Took %v", end.Sub(now))
+}
+
+func (job *SupersededRevocator) revokeIfNeeded(ctx context.Context, cert models.Certificate, now time.Time) {
+	lFunc := helpers.ConfigureLogger(ctx, job.logger)
+
+	var pendingRevocation models.DMSMetadataSupersededRevocation
+	hasKey, err := helpers.GetMetadataToStruct(cert.Metadata, models.DMSMetadataSupersededRevocationKey, &pendingRevocation)
+	if err != nil || !hasKey {
+		return
+	}
+
+	if now.Before(pendingRevocation.RevokeAfter) {
+		return
+	}
+
+	_, err = job.dmsService.RevokeSupersededCertificate(ctx, services.RevokeSupersededCertificateInput{
+		SerialNumber: cert.SerialNumber,
+	})
+	if err != nil {
+		lFunc.Errorf("could not revoke superseded certificate %s: %s", cert.SerialNumber, err)
+	}
+}
@@ -0,0 +1,74 @@
+package jobs
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+func TestSupersededRevocatorRevokesAfterGracePeriod(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	revocator := NewSupersededRevocator(mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger()))
+
+	cert := models.Certificate{
+		SerialNumber: "11-22",
+		Metadata: map[string]interface{}{
+			models.DMSMetadataSupersededRevocationKey: models.DMSMetadataSupersededRevocation{
+				DMSID:        "dms",
+				SupersededBy: "33-44",
+				RevokeAfter:  time.Now().Add(-time.Hour),
+			},
+		},
+	}
+
+	mockDMSService.On("RevokeSupersededCertificate", mock.Anything, mock.Anything).Return(&cert, nil)
+
+	revocator.revokeIfNeeded(context.Background(), cert, time.Now())
+
+	mockDMSService.AssertCalled(t, "RevokeSupersededCertificate", mock.Anything, mock.Anything)
+}
+
+func TestSupersededRevocatorWithinGracePeriod(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	revocator := NewSupersededRevocator(mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger()))
+
+	cert := models.Certificate{
+		SerialNumber: "11-22",
+		Metadata: map[string]interface{}{
+			models.DMSMetadataSupersededRevocationKey: models.DMSMetadataSupersededRevocation{
+				DMSID:        "dms",
+				SupersededBy: "33-44",
+				RevokeAfter:  time.Now().Add(time.Hour),
+			},
+		},
+	}
+
+	revocator.revokeIfNeeded(context.Background(), cert, time.Now())
+
+	mockDMSService.AssertNotCalled(t, "RevokeSupersededCertificate", mock.Anything, mock.Anything)
+}
+
+func TestSupersededRevocatorNoMetadata(t *testing.T) {
+	mockCAService := new(svcmock.MockCAService)
+	mockDMSService := new(svcmock.MockDMSManagerService)
+
+	revocator := NewSupersededRevocator(mockCAService, mockDMSService, logrus.NewEntry(logrus.StandardLogger()))
+
+	cert := models.Certificate{
+		SerialNumber: "11-22",
+		Metadata:     map[string]interface{}{},
+	}
+
+	revocator.revokeIfNeeded(context.Background(), cert, time.Now())
+
+	mockDMSService.AssertNotCalled(t, "RevokeSupersededCertificate", mock.Anything, mock.Anything)
+}
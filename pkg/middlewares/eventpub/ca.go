@@ -3,7 +3,10 @@ package eventpub
 import (
 	"context"
 	"fmt"
+	"time"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 )
@@ -33,10 +36,85 @@ func (mw CAEventPublisher) GetStatsByCAID(ctx context.Context, input services.Ge
 	return mw.Next.GetStatsByCAID(ctx, input)
 }
 
+func (mw CAEventPublisher) ExportCAInventory(ctx context.Context) (*models.CAInventory, error) {
+	return mw.Next.ExportCAInventory(ctx)
+}
+
+func (mw CAEventPublisher) GetKeyStrengthReport(ctx context.Context) (*models.KeyStrengthReport, error) {
+	return mw.Next.GetKeyStrengthReport(ctx)
+}
+
+// publishKeyStrengthWarning fires an additional event whenever a CA or certificate
+// uses a LOW strength key or a deprecated signature algorithm.
+func (mw CAEventPublisher) publishKeyStrengthWarning(ctx context.Context, cert models.Certificate, caID string) {
+	finding, weak := helpers.KeyStrengthFindingFromCertificate(cert)
+	if !weak {
+		return
+	}
+
+	finding.CAID = caID
+	mw.eventMWPub.PublishCloudEvent(ctx, models.EventKeyStrengthWarningKey, finding)
+}
+
+// signingContext completes the issuance context provided by the caller with the signing profile
+// and the ID of the request being served, if those were not already set.
+func signingContext(ctx context.Context, input services.SignCertificateInput) models.CertificateIssuanceContext {
+	issuanceCtx := models.CertificateIssuanceContext{}
+	if input.IssuanceContext != nil {
+		issuanceCtx = *input.IssuanceContext
+	}
+
+	if issuanceCtx.Profile == nil {
+		issuanceCtx.Profile = input.SigningProfile
+	}
+
+	if issuanceCtx.RequestID == "" {
+		issuanceCtx.RequestID = helpers.GetRequestID(ctx)
+	}
+
+	return issuanceCtx
+}
+
+func (mw CAEventPublisher) GetSoftwareKeyCustodyReport(ctx context.Context) (*models.SoftwareKeyCustodyReport, error) {
+	return mw.Next.GetSoftwareKeyCustodyReport(ctx)
+}
+
+// publishKeyCustodyWarning fires an additional event whenever a production CA keeps its key
+// in a software crypto engine.
+func (mw CAEventPublisher) publishKeyCustodyWarning(ctx context.Context, ca *models.CACertificate) {
+	production := false
+	_, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataProductionKey, &production)
+	if err != nil || !production {
+		return
+	}
+
+	engines, err := mw.Next.GetCryptoEngineProvider(ctx)
+	if err != nil {
+		return
+	}
+
+	for _, engine := range engines {
+		if engine.ID == ca.Certificate.EngineID && engine.IsSoftware() {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventKeyCustodyWarningKey, models.SoftwareKeyCustodyFinding{
+				CAID:          ca.ID,
+				Subject:       ca.Certificate.Subject,
+				Type:          ca.Type,
+				Production:    production,
+				EngineID:      engine.ID,
+				EngineType:    engine.Type,
+				SecurityLevel: engine.SecurityLevel,
+				KeyMetadata:   ca.Certificate.KeyMetadata,
+			})
+		}
+	}
+}
+
 func (mw CAEventPublisher) CreateCA(ctx context.Context, input services.CreateCAInput) (output *models.CACertificate, err error) {
 	defer func() {
 		if err == nil {
 			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateCAKey, output)
+			mw.publishKeyStrengthWarning(ctx, output.Certificate, output.ID)
+			mw.publishKeyCustodyWarning(ctx, output)
 		}
 	}()
 	return mw.Next.CreateCA(ctx, input)
@@ -46,6 +124,8 @@ func (mw CAEventPublisher) ImportCA(ctx context.Context, input services.ImportCA
 	defer func() {
 		if err == nil {
 			mw.eventMWPub.PublishCloudEvent(ctx, models.EventImportCAKey, output)
+			mw.publishKeyStrengthWarning(ctx, output.Certificate, output.ID)
+			mw.publishKeyCustodyWarning(ctx, output)
 		}
 	}()
 	return mw.Next.ImportCA(ctx, input)
@@ -63,6 +143,18 @@ func (mw CAEventPublisher) GetCAsByCommonName(ctx context.Context, input service
 	return mw.Next.GetCAsByCommonName(ctx, input)
 }
 
+// publishPendingCAAction publishes the audit record of a destructive operation waiting for approval.
+func (mw CAEventPublisher) publishPendingCAAction(ctx context.Context, caID string) {
+	action, err := mw.Next.GetPendingCAAction(ctx, services.GetPendingCAActionInput{
+		CAID: caID,
+	})
+	if err != nil {
+		return
+	}
+
+	mw.eventMWPub.PublishCloudEvent(ctx, models.EventRequestCAActionKey, *action)
+}
+
 func (mw CAEventPublisher) UpdateCAStatus(ctx context.Context, input services.UpdateCAStatusInput) (output *models.CACertificate, err error) {
 	prev, err := mw.GetCAByID(ctx, services.GetCAByIDInput{
 		CAID: input.CAID,
@@ -77,6 +169,8 @@ func (mw CAEventPublisher) UpdateCAStatus(ctx context.Context, input services.Up
 				Updated:  *output,
 				Previous: *prev,
 			})
+		} else if err == errs.ErrCAActionPendingApproval {
+			mw.publishPendingCAAction(ctx, input.CAID)
 		}
 	}()
 	return mw.Next.UpdateCAStatus(ctx, input)
@@ -100,28 +194,143 @@ func (mw CAEventPublisher) UpdateCAMetadata(ctx context.Context, input services.
 	return mw.Next.UpdateCAMetadata(ctx, input)
 }
 
+func (mw CAEventPublisher) UpdateCASettings(ctx context.Context, input services.UpdateCASettingsInput) (output *models.CACertificate, err error) {
+	prev, err := mw.GetCAByID(ctx, services.GetCAByIDInput{
+		CAID: input.CAID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get CA %s: %w", input.CAID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateCASettingsKey, models.UpdateModel[models.CACertificate]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.Next.UpdateCASettings(ctx, input)
+}
+
 func (mw CAEventPublisher) DeleteCA(ctx context.Context, input services.DeleteCAInput) (err error) {
 	defer func() {
 		if err == nil {
 			mw.eventMWPub.PublishCloudEvent(ctx, models.EventDeleteCAKey, input)
+		} else if err == errs.ErrCAActionPendingApproval {
+			mw.publishPendingCAAction(ctx, input.CAID)
 		}
 	}()
 	return mw.Next.DeleteCA(ctx, input)
 }
 
+func (mw CAEventPublisher) GetPendingCAAction(ctx context.Context, input services.GetPendingCAActionInput) (*models.CAPendingAction, error) {
+	return mw.Next.GetPendingCAAction(ctx, input)
+}
+
+func (mw CAEventPublisher) ApprovePendingCAAction(ctx context.Context, input services.ApprovePendingCAActionInput) (output *models.CAPendingAction, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventApproveCAActionKey, output)
+		}
+	}()
+	return mw.Next.ApprovePendingCAAction(ctx, input)
+}
+
+func (mw CAEventPublisher) MigrateCAKey(ctx context.Context, input services.MigrateCAKeyInput) (output *models.CACertificate, err error) {
+	prev, err := mw.GetCAByID(ctx, services.GetCAByIDInput{
+		CAID: input.CAID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get CA %s: %w", input.CAID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventMigrateCAKeyKey, models.UpdateModel[models.CACertificate]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.Next.MigrateCAKey(ctx, input)
+}
+
+func (mw CAEventPublisher) SetCAFallbackEngine(ctx context.Context, input services.SetCAFallbackEngineInput) (output *models.CACertificate, err error) {
+	prev, err := mw.GetCAByID(ctx, services.GetCAByIDInput{
+		CAID: input.CAID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get CA %s: %w", input.CAID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateCAFallbackEngineKey, models.UpdateModel[models.CACertificate]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.Next.SetCAFallbackEngine(ctx, input)
+}
+
+func (mw CAEventPublisher) SetCAIssuanceWebhook(ctx context.Context, input services.SetCAIssuanceWebhookInput) (output *models.CACertificate, err error) {
+	prev, err := mw.GetCAByID(ctx, services.GetCAByIDInput{
+		CAID: input.CAID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get CA %s: %w", input.CAID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateCAIssuanceWebhookKey, models.UpdateModel[models.CACertificate]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.Next.SetCAIssuanceWebhook(ctx, input)
+}
+
+func (mw CAEventPublisher) ExportCAKey(ctx context.Context, input services.ExportCAKeyInput) (*models.CAKeyBackup, error) {
+	return mw.Next.ExportCAKey(ctx, input)
+}
+
+func (mw CAEventPublisher) RestoreCAKey(ctx context.Context, input services.RestoreCAKeyInput) (output *models.CACertificate, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRestoreCAKeyKey, output)
+		}
+	}()
+	return mw.Next.RestoreCAKey(ctx, input)
+}
+
 func (mw CAEventPublisher) SignCertificate(ctx context.Context, input services.SignCertificateInput) (output *models.Certificate, err error) {
 	defer func() {
 		if err == nil {
-			mw.eventMWPub.PublishCloudEvent(ctx, models.EventSignCertificateKey, output)
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventSignCertificateKey, models.SignedCertificateEvent{
+				Certificate:     *output,
+				IssuanceContext: signingContext(ctx, input),
+			})
+			mw.publishKeyStrengthWarning(ctx, *output, "")
 		}
 	}()
 	return mw.Next.SignCertificate(ctx, input)
 }
 
+// SignCertificatesBatch does not publish any event itself: the backend signs each request through the service
+// chain, so a sign certificate event is published per signed certificate.
+func (mw CAEventPublisher) SignCertificatesBatch(ctx context.Context, input services.SignCertificatesBatchInput) ([]models.CertificateSigningResult, error) {
+	return mw.Next.SignCertificatesBatch(ctx, input)
+}
+
 func (mw CAEventPublisher) CreateCertificate(ctx context.Context, input services.CreateCertificateInput) (output *models.Certificate, err error) {
 	defer func() {
 		if err == nil {
 			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateCertificateKey, output)
+			mw.publishKeyStrengthWarning(ctx, *output, "")
 		}
 	}()
 	return mw.Next.CreateCertificate(ctx, input)
@@ -131,6 +340,7 @@ func (mw CAEventPublisher) ImportCertificate(ctx context.Context, input services
 	defer func() {
 		if err == nil {
 			mw.eventMWPub.PublishCloudEvent(ctx, models.EventImportCACertificateKey, output)
+			mw.publishKeyStrengthWarning(ctx, *output, "")
 		}
 	}()
 	return mw.Next.ImportCertificate(ctx, input)
@@ -145,6 +355,94 @@ func (mw CAEventPublisher) SignatureSign(ctx context.Context, input services.Sig
 	return mw.Next.SignatureSign(ctx, input)
 }
 
+// SignSVID does not publish any event itself: the backend signs the SVID through the service chain, so a sign
+// certificate event is published.
+func (mw CAEventPublisher) SignSVID(ctx context.Context, input services.SignSVIDInput) (*models.Certificate, error) {
+	return mw.Next.SignSVID(ctx, input)
+}
+
+func (mw CAEventPublisher) SignToken(ctx context.Context, input services.SignTokenInput) (output *models.SignedToken, err error) {
+	defer func() {
+		if err == nil {
+			// the token itself is a bearer credential and is not published
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventSignTokenKey, models.TokenSignEvent{
+				KeyID:     output.KeyID,
+				Algorithm: output.Algorithm,
+				Claims:    input.Claims,
+			})
+		}
+	}()
+	return mw.Next.SignToken(ctx, input)
+}
+
+func (mw CAEventPublisher) GetTokenSigningKeys(ctx context.Context) (*models.JWKS, error) {
+	return mw.Next.GetTokenSigningKeys(ctx)
+}
+
+func (mw CAEventPublisher) GetIssuanceLogTreeHead(ctx context.Context, input services.GetIssuanceLogTreeHeadInput) (*models.IssuanceLogTreeHead, error) {
+	return mw.Next.GetIssuanceLogTreeHead(ctx, input)
+}
+
+func (mw CAEventPublisher) GetIssuanceLogInclusionProof(ctx context.Context, input services.GetIssuanceLogInclusionProofInput) (*models.IssuanceLogInclusionProof, error) {
+	return mw.Next.GetIssuanceLogInclusionProof(ctx, input)
+}
+
+func (mw CAEventPublisher) GetCAEvents(ctx context.Context, input services.GetCAEventsInput) ([]models.CAEvent, error) {
+	return mw.Next.GetCAEvents(ctx, input)
+}
+
+func (mw CAEventPublisher) ReportCRLGeneration(ctx context.Context, input services.ReportCRLGenerationInput) error {
+	return mw.Next.ReportCRLGeneration(ctx, input)
+}
+
+func (mw CAEventPublisher) CreateSuccessorCA(ctx context.Context, input services.CreateSuccessorCAInput) (output *models.CASuccession, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateCASuccessorKey, *output)
+		}
+	}()
+	return mw.Next.CreateSuccessorCA(ctx, input)
+}
+
+func (mw CAEventPublisher) CreateCASigningRequest(ctx context.Context, input services.CreateCASigningRequestInput) (output *models.CASigningRequest, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateCASigningRequestKey, *output)
+		}
+	}()
+	return mw.Next.CreateCASigningRequest(ctx, input)
+}
+
+func (mw CAEventPublisher) GetCASigningRequests(ctx context.Context, input services.GetCASigningRequestsInput) (string, error) {
+	return mw.Next.GetCASigningRequests(ctx, input)
+}
+
+func (mw CAEventPublisher) GetCASigningRequestByID(ctx context.Context, input services.GetCASigningRequestByIDInput) (*models.CASigningRequest, error) {
+	return mw.Next.GetCASigningRequestByID(ctx, input)
+}
+
+func (mw CAEventPublisher) ApproveCASigningRequest(ctx context.Context, input services.ApproveCASigningRequestInput) (output *models.CASigningRequest, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventApproveCASigningRequestKey, *output)
+		}
+	}()
+	return mw.Next.ApproveCASigningRequest(ctx, input)
+}
+
+func (mw CAEventPublisher) RejectCASigningRequest(ctx context.Context, input services.RejectCASigningRequestInput) (output *models.CASigningRequest, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRejectCASigningRequestKey, *output)
+		}
+	}()
+	return mw.Next.RejectCASigningRequest(ctx, input)
+}
+
+func (mw CAEventPublisher) GetJWKS(ctx context.Context) (*models.JWKS, error) {
+	return mw.Next.GetJWKS(ctx)
+}
+
 func (mw CAEventPublisher) SignatureVerify(ctx context.Context, input services.SignatureVerifyInput) (output bool, err error) {
 	return mw.Next.SignatureVerify(ctx, input)
 }
@@ -157,6 +455,10 @@ func (mw CAEventPublisher) GetCertificates(ctx context.Context, input services.G
 	return mw.Next.GetCertificates(ctx, input)
 }
 
+func (mw CAEventPublisher) ExportCertificates(ctx context.Context, input services.ExportCertificatesInput) (time.Time, error) {
+	return mw.Next.ExportCertificates(ctx, input)
+}
+
 func (mw CAEventPublisher) GetCertificatesByCA(ctx context.Context, input services.GetCertificatesByCAInput) (string, error) {
 	return mw.Next.GetCertificatesByCA(ctx, input)
 }
@@ -210,3 +512,48 @@ func (mw CAEventPublisher) UpdateCertificateMetadata(ctx context.Context, input
 	}()
 	return mw.Next.UpdateCertificateMetadata(ctx, input)
 }
+
+func (mw CAEventPublisher) CreateCertificateProfile(ctx context.Context, input services.CreateCertificateProfileInput) (output *models.CertificateProfile, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateCertificateProfileKey, output)
+		}
+	}()
+	return mw.Next.CreateCertificateProfile(ctx, input)
+}
+
+func (mw CAEventPublisher) GetCertificateProfileByID(ctx context.Context, input services.GetCertificateProfileByIDInput) (*models.CertificateProfile, error) {
+	return mw.Next.GetCertificateProfileByID(ctx, input)
+}
+
+func (mw CAEventPublisher) GetCertificateProfiles(ctx context.Context, input services.GetCertificateProfilesInput) (string, error) {
+	return mw.Next.GetCertificateProfiles(ctx, input)
+}
+
+func (mw CAEventPublisher) UpdateCertificateProfile(ctx context.Context, input services.UpdateCertificateProfileInput) (output *models.CertificateProfile, err error) {
+	prev, err := mw.GetCertificateProfileByID(ctx, services.GetCertificateProfileByIDInput{
+		ID: input.Profile.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get certificate profile %s: %w", input.Profile.ID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateCertificateProfileKey, models.UpdateModel[models.CertificateProfile]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.Next.UpdateCertificateProfile(ctx, input)
+}
+
+func (mw CAEventPublisher) DeleteCertificateProfile(ctx context.Context, input services.DeleteCertificateProfileInput) (err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventDeleteCertificateProfileKey, input)
+		}
+	}()
+	return mw.Next.DeleteCertificateProfile(ctx, input)
+}
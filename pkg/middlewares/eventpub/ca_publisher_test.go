@@ -6,7 +6,9 @@ import (
 	"reflect"
 	"testing"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
 	"github.com/stretchr/testify/assert"
@@ -166,6 +168,122 @@ func TestCAEventPublisher(t *testing.T) {
 				withoutErrors(t, "SignCertificate", services.SignCertificateInput{}, models.EventSignCertificateKey, &models.Certificate{})
 			},
 		},
+		{
+			name: "SingCertificate with low strength key - fire key strength warning event",
+			test: func(t *testing.T) {
+				mockCAService := new(svcmock.MockCAService)
+				mockEventMWPub := new(CloudEventMiddlewarePublisherMock)
+				caEventPublisher := NewCAEventBusPublisher(mockEventMWPub)(mockCAService)
+
+				output := &models.Certificate{
+					SerialNumber: "01-02",
+					KeyMetadata:  models.KeyStrengthMetadata{Strength: models.KeyStrengthLow},
+				}
+				mockCAService.On("SignCertificate", context.Background(), mock.Anything).Return(output, nil)
+				mockEventMWPub.On("PublishCloudEvent", context.Background(), models.EventSignCertificateKey, mock.Anything)
+				mockEventMWPub.On("PublishCloudEvent", context.Background(), models.EventKeyStrengthWarningKey, mock.Anything)
+
+				_, err := caEventPublisher.SignCertificate(context.Background(), services.SignCertificateInput{})
+				assert.NoError(t, err)
+
+				mockCAService.AssertExpectations(t)
+				mockEventMWPub.AssertExpectations(t)
+			},
+		},
+		{
+			name: "SingCertificate with issuance context - fire event with context",
+			test: func(t *testing.T) {
+				mockCAService := new(svcmock.MockCAService)
+				mockEventMWPub := new(CloudEventMiddlewarePublisherMock)
+				caEventPublisher := NewCAEventBusPublisher(mockEventMWPub)(mockCAService)
+
+				ctx := context.WithValue(context.Background(), headerextractors.CtxRequestID, "req-1")
+				profile := &models.SigningProfile{OCSPServers: []string{"http://ocsp.lamassu.io"}}
+				output := &models.Certificate{SerialNumber: "01-02"}
+
+				mockCAService.On("SignCertificate", ctx, mock.Anything).Return(output, nil)
+				mockEventMWPub.On("PublishCloudEvent", ctx, models.EventSignCertificateKey, models.SignedCertificateEvent{
+					Certificate: *output,
+					IssuanceContext: models.CertificateIssuanceContext{
+						DMSID:     "dms-1",
+						DeviceID:  "device-1",
+						Profile:   profile,
+						RequestID: "req-1",
+					},
+				})
+
+				_, err := caEventPublisher.SignCertificate(ctx, services.SignCertificateInput{
+					SigningProfile: profile,
+					IssuanceContext: &models.CertificateIssuanceContext{
+						DMSID:    "dms-1",
+						DeviceID: "device-1",
+					},
+				})
+				assert.NoError(t, err)
+
+				mockCAService.AssertExpectations(t)
+				mockEventMWPub.AssertExpectations(t)
+			},
+		},
+		{
+			name: "SignToken with errors - Not fire event",
+			test: func(t *testing.T) {
+				withErrors(t, "SignToken", services.SignTokenInput{}, models.EventSignTokenKey, &models.SignedToken{})
+			},
+		},
+		{
+			name: "SignToken without errors - fire event without token",
+			test: func(t *testing.T) {
+				mockCAService := new(svcmock.MockCAService)
+				mockEventMWPub := new(CloudEventMiddlewarePublisherMock)
+				caEventPublisher := NewCAEventBusPublisher(mockEventMWPub)(mockCAService)
+
+				claims := map[string]any{"sub": "device-1"}
+				mockCAService.On("SignToken", context.Background(), mock.Anything).Return(&models.SignedToken{
+					Token:     "header.payload.signature",
+					KeyID:     "ca-1",
+					Algorithm: "ES256",
+				}, nil)
+				mockEventMWPub.On("PublishCloudEvent", context.Background(), models.EventSignTokenKey, models.TokenSignEvent{
+					KeyID:     "ca-1",
+					Algorithm: "ES256",
+					Claims:    claims,
+				})
+
+				_, err := caEventPublisher.SignToken(context.Background(), services.SignTokenInput{CAID: "ca-1", Claims: claims})
+				assert.NoError(t, err)
+
+				mockCAService.AssertExpectations(t)
+				mockEventMWPub.AssertExpectations(t)
+			},
+		},
+		{
+			name: "CreateCA for production on software engine - fire key custody warning event",
+			test: func(t *testing.T) {
+				mockCAService := new(svcmock.MockCAService)
+				mockEventMWPub := new(CloudEventMiddlewarePublisherMock)
+				caEventPublisher := NewCAEventBusPublisher(mockEventMWPub)(mockCAService)
+
+				output := &models.CACertificate{
+					ID:       "ca-1",
+					Metadata: map[string]any{models.CAMetadataProductionKey: true},
+				}
+				output.Certificate.EngineID = "golang-1"
+
+				mockCAService.On("CreateCA", context.Background(), mock.Anything).Return(output, nil)
+				mockCAService.On("GetCryptoEngineProvider", context.Background()).Return([]*models.CryptoEngineProvider{
+					{ID: "golang-1", CryptoEngineInfo: models.CryptoEngineInfo{Type: models.Golang, SecurityLevel: models.SL0}},
+				}, nil)
+				mockEventMWPub.On("PublishCloudEvent", context.Background(), models.EventCreateCAKey, mock.Anything)
+				mockEventMWPub.On("PublishCloudEvent", context.Background(), models.EventKeyCustodyWarningKey, mock.Anything)
+
+				_, err := caEventPublisher.CreateCA(context.Background(), services.CreateCAInput{})
+				assert.NoError(t, err)
+
+				mockCAService.AssertExpectations(t)
+				mockEventMWPub.AssertExpectations(t)
+			},
+		},
 		{
 			name: "CreateCertificate with errors - Not fire event",
 			test: func(t *testing.T) {
@@ -238,6 +356,72 @@ func TestCAEventPublisher(t *testing.T) {
 					})
 			},
 		},
+		{
+			name: "UpdateCASettings with errors - Not fire event",
+			test: func(t *testing.T) {
+				withErrors(t, "UpdateCASettings", services.UpdateCASettingsInput{}, models.EventUpdateCASettingsKey, &models.CACertificate{},
+					func(mockCAService *svcmock.MockCAService) {
+						mockCAService.On("GetCAByID", context.Background(), mock.Anything).Return(&models.CACertificate{}, nil)
+					})
+			},
+		},
+		{
+			name: "UpdateCASettings without errors - fire event",
+			test: func(t *testing.T) {
+				withoutErrors(t, "UpdateCASettings", services.UpdateCASettingsInput{}, models.EventUpdateCASettingsKey, &models.CACertificate{},
+					func(mockCAService *svcmock.MockCAService) {
+						mockCAService.On("GetCAByID", context.Background(), mock.Anything).Return(&models.CACertificate{}, nil)
+					})
+			},
+		},
+		{
+			name: "MigrateCAKey with errors - Not fire event",
+			test: func(t *testing.T) {
+				withErrors(t, "MigrateCAKey", services.MigrateCAKeyInput{}, models.EventMigrateCAKeyKey, &models.CACertificate{},
+					func(mockCAService *svcmock.MockCAService) {
+						mockCAService.On("GetCAByID", context.Background(), mock.Anything).Return(&models.CACertificate{}, nil)
+					})
+			},
+		},
+		{
+			name: "MigrateCAKey without errors - fire event",
+			test: func(t *testing.T) {
+				withoutErrors(t, "MigrateCAKey", services.MigrateCAKeyInput{}, models.EventMigrateCAKeyKey, &models.CACertificate{},
+					func(mockCAService *svcmock.MockCAService) {
+						mockCAService.On("GetCAByID", context.Background(), mock.Anything).Return(&models.CACertificate{}, nil)
+					})
+			},
+		},
+		{
+			name: "SetCAFallbackEngine with errors - Not fire event",
+			test: func(t *testing.T) {
+				withErrors(t, "SetCAFallbackEngine", services.SetCAFallbackEngineInput{}, models.EventUpdateCAFallbackEngineKey, &models.CACertificate{},
+					func(mockCAService *svcmock.MockCAService) {
+						mockCAService.On("GetCAByID", context.Background(), mock.Anything).Return(&models.CACertificate{}, nil)
+					})
+			},
+		},
+		{
+			name: "SetCAFallbackEngine without errors - fire event",
+			test: func(t *testing.T) {
+				withoutErrors(t, "SetCAFallbackEngine", services.SetCAFallbackEngineInput{}, models.EventUpdateCAFallbackEngineKey, &models.CACertificate{},
+					func(mockCAService *svcmock.MockCAService) {
+						mockCAService.On("GetCAByID", context.Background(), mock.Anything).Return(&models.CACertificate{}, nil)
+					})
+			},
+		},
+		{
+			name: "RestoreCAKey with errors - Not fire event",
+			test: func(t *testing.T) {
+				withErrors(t, "RestoreCAKey", services.RestoreCAKeyInput{}, models.EventRestoreCAKeyKey, &models.CACertificate{})
+			},
+		},
+		{
+			name: "RestoreCAKey without errors - fire event",
+			test: func(t *testing.T) {
+				withoutErrors(t, "RestoreCAKey", services.RestoreCAKeyInput{}, models.EventRestoreCAKeyKey, &models.CACertificate{})
+			},
+		},
 		{
 			name: "UpdateCertificateStatus with errors - Not fire event",
 			test: func(t *testing.T) {
@@ -286,6 +470,18 @@ func TestCAEventPublisher(t *testing.T) {
 				withoutErrorsSingleResult(t, "DeleteCA", services.DeleteCAInput{}, models.EventDeleteCAKey)
 			},
 		},
+		{
+			name: "ApprovePendingCAAction with errors - Not fire event",
+			test: func(t *testing.T) {
+				withErrors(t, "ApprovePendingCAAction", services.ApprovePendingCAActionInput{}, models.EventApproveCAActionKey, &models.CAPendingAction{})
+			},
+		},
+		{
+			name: "ApprovePendingCAAction without errors - fire event",
+			test: func(t *testing.T) {
+				withoutErrors(t, "ApprovePendingCAAction", services.ApprovePendingCAActionInput{}, models.EventApproveCAActionKey, &models.CAPendingAction{})
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -293,3 +489,34 @@ func TestCAEventPublisher(t *testing.T) {
 		t.Run(tc.name, tc.test)
 	}
 }
+
+func TestCAEventPublisherPendingApproval(t *testing.T) {
+	pendingAction := &models.CAPendingAction{
+		ID:          "action-1",
+		Type:        models.CAPendingActionDelete,
+		CAID:        "ca-1",
+		RequestedBy: "admin-1",
+	}
+
+	expectations := []func(*svcmock.MockCAService){
+		func(mockCAService *svcmock.MockCAService) {
+			mockCAService.On("DeleteCA", context.Background(), mock.Anything).Return(errs.ErrCAActionPendingApproval)
+			mockCAService.On("GetPendingCAAction", context.Background(), services.GetPendingCAActionInput{CAID: "ca-1"}).Return(pendingAction, nil)
+		},
+	}
+
+	operation := func(caMiddleware services.CAService) {
+		err := caMiddleware.DeleteCA(context.Background(), services.DeleteCAInput{CAID: "ca-1"})
+		assert.Equal(t, errs.ErrCAActionPendingApproval, err)
+	}
+
+	assertions := func(mockEventMWPub *CloudEventMiddlewarePublisherMock, mockCAService *svcmock.MockCAService) {
+		mockCAService.AssertExpectations(t)
+		mockEventMWPub.AssertCalled(t, "PublishCloudEvent", context.Background(), models.EventRequestCAActionKey, mock.MatchedBy(func(action models.CAPendingAction) bool {
+			return action.ID == "action-1" && action.RequestedBy == "admin-1"
+		}))
+		mockEventMWPub.AssertNotCalled(t, "PublishCloudEvent", context.Background(), models.EventDeleteCAKey, mock.Anything)
+	}
+
+	eventChecker(models.EventRequestCAActionKey, expectations, operation, assertions)
+}
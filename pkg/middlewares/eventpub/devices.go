@@ -3,8 +3,11 @@ package eventpub
 import (
 	"context"
 	"fmt"
+	"sort"
+	"time"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 )
 
@@ -26,6 +29,18 @@ func (mw *deviceEventPublisher) GetDevicesStats(ctx context.Context, input servi
 	return mw.next.GetDevicesStats(ctx, input)
 }
 
+func (mw *deviceEventPublisher) GetIssuanceReport(ctx context.Context, input services.GetIssuanceReportInput) (*models.IssuanceReport, error) {
+	return mw.next.GetIssuanceReport(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceByCertificate(ctx context.Context, input services.GetDeviceByCertificateInput) (*models.CertificateDeviceBinding, error) {
+	return mw.next.GetDeviceByCertificate(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDevicesOutOfCloudSync(ctx context.Context, input services.GetDevicesOutOfCloudSyncInput) (string, error) {
+	return mw.next.GetDevicesOutOfCloudSync(ctx, input)
+}
+
 func (mw *deviceEventPublisher) CreateDevice(ctx context.Context, input services.CreateDeviceInput) (output *models.Device, err error) {
 	defer func() {
 		if err == nil {
@@ -35,10 +50,34 @@ func (mw *deviceEventPublisher) CreateDevice(ctx context.Context, input services
 	return mw.next.CreateDevice(ctx, input)
 }
 
-func (mw *deviceEventPublisher) GetDeviceByID(ctx context.Context, input services.GetDeviceByIDInput) (*models.Device, error) {
+func (mw *deviceEventPublisher) GetDeviceByID(ctx context.Context, input services.GetDeviceByIDInput) (output *models.Device, err error) {
+	defer func() {
+		if err == nil && input.IncludeSecrets {
+			mw.publishSecretsAccess(ctx, output)
+		}
+	}()
 	return mw.next.GetDeviceByID(ctx, input)
 }
 
+// publishSecretsAccess publishes the audit record of a request retrieving the confidential slot payloads.
+func (mw *deviceEventPublisher) publishSecretsAccess(ctx context.Context, device *models.Device) {
+	slots := []string{}
+	for slotID, slot := range device.ExtraSlots {
+		if slot != nil && slot.SecretType != models.X509SlotProfileType {
+			slots = append(slots, slotID)
+		}
+	}
+	sort.Strings(slots)
+
+	accessedBy, _ := ctx.Value(string(identityextractors.CtxAuthID)).(string)
+	mw.eventMWPub.PublishCloudEvent(ctx, models.EventReadDeviceSecretsKey, models.DeviceSecretsAccess{
+		DeviceID:   device.ID,
+		Slots:      slots,
+		AccessedBy: accessedBy,
+		AccessedAt: time.Now(),
+	})
+}
+
 func (mw *deviceEventPublisher) GetDevices(ctx context.Context, input services.GetDevicesInput) (string, error) {
 	return mw.next.GetDevices(ctx, input)
 }
@@ -84,6 +123,26 @@ func (mw *deviceEventPublisher) UpdateDeviceIdentitySlot(ctx context.Context, in
 	return mw.next.UpdateDeviceIdentitySlot(ctx, input)
 }
 
+func (mw *deviceEventPublisher) UpdateDeviceConnectionMetadata(ctx context.Context, input services.UpdateDeviceConnectionMetadataInput) (output *models.Device, err error) {
+	prev, err := mw.GetDeviceByID(ctx, services.GetDeviceByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get Device %s: %w", input.ID, err)
+	}
+
+	// Only status transitions are published. Heartbeats refreshing the last seen timestamp are not.
+	defer func() {
+		if err == nil && prev.ConnectionMetadata.Status != output.ConnectionMetadata.Status {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDeviceConnectionKey, models.UpdateModel[models.Device]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.UpdateDeviceConnectionMetadata(ctx, input)
+}
+
 func (mw *deviceEventPublisher) UpdateDeviceMetadata(ctx context.Context, input services.UpdateDeviceMetadataInput) (output *models.Device, err error) {
 	prev, err := mw.GetDeviceByID(ctx, services.GetDeviceByIDInput{
 		ID: input.ID,
@@ -102,3 +161,217 @@ func (mw *deviceEventPublisher) UpdateDeviceMetadata(ctx context.Context, input
 	}()
 	return mw.next.UpdateDeviceMetadata(ctx, input)
 }
+
+// ReportDeviceHeartbeat publishes no event of its own: the connection update it results in is published by
+// UpdateDeviceConnectionMetadata when the connection status changes.
+func (mw *deviceEventPublisher) ReportDeviceHeartbeat(ctx context.Context, input services.ReportDeviceHeartbeatInput) (*models.Device, error) {
+	return mw.next.ReportDeviceHeartbeat(ctx, input)
+}
+
+// Device logs are not published to the event bus: entries are high volume and can be iterated with GetDeviceLogs.
+func (mw *deviceEventPublisher) CreateDeviceLog(ctx context.Context, input services.CreateDeviceLogInput) (*models.DeviceLog, error) {
+	return mw.next.CreateDeviceLog(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceLogs(ctx context.Context, input services.GetDeviceLogsInput) (string, error) {
+	return mw.next.GetDeviceLogs(ctx, input)
+}
+
+func (mw *deviceEventPublisher) PruneDeviceLogs(ctx context.Context, input services.PruneDeviceLogsInput) (int, error) {
+	return mw.next.PruneDeviceLogs(ctx, input)
+}
+
+func (mw *deviceEventPublisher) ForceDeviceReenroll(ctx context.Context, input services.ForceDeviceReenrollInput) (output *models.Device, err error) {
+	prev, err := mw.GetDeviceByID(ctx, services.GetDeviceByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get Device %s: %w", input.ID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventForceReenrollDeviceKey, models.UpdateModel[models.Device]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.ForceDeviceReenroll(ctx, input)
+}
+
+func (mw *deviceEventPublisher) ProvisionDeviceSecretSlot(ctx context.Context, input services.ProvisionDeviceSecretSlotInput) (output *models.Device, err error) {
+	prev, err := mw.GetDeviceByID(ctx, services.GetDeviceByIDInput{
+		ID: input.DeviceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get Device %s: %w", input.DeviceID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDeviceSlotKey, models.UpdateModel[models.Device]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.ProvisionDeviceSecretSlot(ctx, input)
+}
+
+func (mw *deviceEventPublisher) RotateDeviceSecretSlot(ctx context.Context, input services.RotateDeviceSecretSlotInput) (output *models.Device, err error) {
+	prev, err := mw.GetDeviceByID(ctx, services.GetDeviceByIDInput{
+		ID: input.DeviceID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get Device %s: %w", input.DeviceID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDeviceSlotKey, models.UpdateModel[models.Device]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.RotateDeviceSecretSlot(ctx, input)
+}
+
+// GetDeviceSecretSlot publishes the same audit record as GetDeviceByID with IncludeSecrets, even though the secret
+// is only returned encrypted to the device identity.
+func (mw *deviceEventPublisher) GetDeviceSecretSlot(ctx context.Context, input services.GetDeviceSecretSlotInput) (output *models.EncryptedSlotSecret, err error) {
+	defer func() {
+		if err == nil {
+			accessedBy, _ := ctx.Value(string(identityextractors.CtxAuthID)).(string)
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventReadDeviceSecretsKey, models.DeviceSecretsAccess{
+				DeviceID:   input.DeviceID,
+				Slots:      []string{input.SlotID},
+				AccessedBy: accessedBy,
+				AccessedAt: time.Now(),
+			})
+		}
+	}()
+	return mw.next.GetDeviceSecretSlot(ctx, input)
+}
+
+func (mw *deviceEventPublisher) CreateDeviceGroup(ctx context.Context, input services.CreateDeviceGroupInput) (output *models.DeviceGroup, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateDeviceGroupKey, output)
+		}
+	}()
+	return mw.next.CreateDeviceGroup(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceGroups(ctx context.Context, input services.GetDeviceGroupsInput) (string, error) {
+	return mw.next.GetDeviceGroups(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceGroupByID(ctx context.Context, input services.GetDeviceGroupByIDInput) (*models.DeviceGroup, error) {
+	return mw.next.GetDeviceGroupByID(ctx, input)
+}
+
+func (mw *deviceEventPublisher) UpdateDeviceGroupDevices(ctx context.Context, input services.UpdateDeviceGroupDevicesInput) (output *models.DeviceGroup, err error) {
+	prev, err := mw.GetDeviceGroupByID(ctx, services.GetDeviceGroupByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get device group %s: %w", input.ID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDeviceGroupKey, models.UpdateModel[models.DeviceGroup]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.UpdateDeviceGroupDevices(ctx, input)
+}
+
+func (mw *deviceEventPublisher) UpdateDeviceGroupTags(ctx context.Context, input services.UpdateDeviceGroupTagsInput) (output *models.DeviceGroup, err error) {
+	prev, err := mw.GetDeviceGroupByID(ctx, services.GetDeviceGroupByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get device group %s: %w", input.ID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDeviceGroupKey, models.UpdateModel[models.DeviceGroup]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.UpdateDeviceGroupTags(ctx, input)
+}
+
+func (mw *deviceEventPublisher) UpdateDeviceGroupFilters(ctx context.Context, input services.UpdateDeviceGroupFiltersInput) (output *models.DeviceGroup, err error) {
+	prev, err := mw.GetDeviceGroupByID(ctx, services.GetDeviceGroupByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get device group %s: %w", input.ID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDeviceGroupKey, models.UpdateModel[models.DeviceGroup]{
+				Updated:  *output,
+				Previous: *prev,
+			})
+		}
+	}()
+	return mw.next.UpdateDeviceGroupFilters(ctx, input)
+}
+
+func (mw *deviceEventPublisher) DeleteDeviceGroup(ctx context.Context, input services.DeleteDeviceGroupInput) (err error) {
+	prev, err := mw.GetDeviceGroupByID(ctx, services.GetDeviceGroupByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("mw error: could not get device group %s: %w", input.ID, err)
+	}
+
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventDeleteDeviceGroupKey, prev)
+		}
+	}()
+	return mw.next.DeleteDeviceGroup(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceGroupDevices(ctx context.Context, input services.GetDeviceGroupDevicesInput) (string, error) {
+	return mw.next.GetDeviceGroupDevices(ctx, input)
+}
+
+// StartDeviceGroupBulkAction only publishes the start of the action. Each device update publishes its own event.
+func (mw *deviceEventPublisher) StartDeviceGroupBulkAction(ctx context.Context, input services.StartDeviceGroupBulkActionInput) (output *models.DeviceGroupBulkAction, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventStartDeviceGroupBulkActionKey, output)
+		}
+	}()
+	return mw.next.StartDeviceGroupBulkAction(ctx, input)
+}
+
+func (mw *deviceEventPublisher) ApproveDeviceGroupBulkAction(ctx context.Context, input services.ApproveDeviceGroupBulkActionInput) (output *models.DeviceGroupBulkAction, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventApproveDeviceGroupBulkActionKey, output)
+		}
+	}()
+	return mw.next.ApproveDeviceGroupBulkAction(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceGroupBulkActions(ctx context.Context, input services.GetDeviceGroupBulkActionsInput) (string, error) {
+	return mw.next.GetDeviceGroupBulkActions(ctx, input)
+}
+
+func (mw *deviceEventPublisher) GetDeviceGroupBulkActionByID(ctx context.Context, input services.GetDeviceGroupBulkActionByIDInput) (*models.DeviceGroupBulkAction, error) {
+	return mw.next.GetDeviceGroupBulkActionByID(ctx, input)
+}
@@ -144,6 +144,52 @@ func TestDevicesEventPublisher(t *testing.T) {
 					})
 			},
 		},
+		{
+			name: "UpdateDeviceConnectionMetadata with errors - Not fire event",
+			test: func(t *testing.T) {
+				devicesWithErrors(t, "UpdateDeviceConnectionMetadata", services.UpdateDeviceConnectionMetadataInput{}, models.EventUpdateDeviceConnectionKey, &models.Device{},
+					func(mockCAService *svcmock.MockDeviceManagerService) {
+						mockCAService.On("GetDeviceByID", context.Background(), mock.Anything).Return(&models.Device{}, nil)
+					})
+			},
+		},
+		{
+			name: "UpdateDeviceConnectionMetadata with status change - fire event",
+			test: func(t *testing.T) {
+				devicesWithoutErrors(t, "UpdateDeviceConnectionMetadata", services.UpdateDeviceConnectionMetadataInput{}, models.EventUpdateDeviceConnectionKey,
+					&models.Device{ConnectionMetadata: models.DeviceConnectionMetadata{Status: models.DeviceConnectionOnline}},
+					func(mockCAService *svcmock.MockDeviceManagerService) {
+						mockCAService.On("GetDeviceByID", context.Background(), mock.Anything).Return(&models.Device{}, nil)
+					})
+			},
+		},
+		{
+			name: "GetDeviceByID with secrets and errors - Not fire event",
+			test: func(t *testing.T) {
+				devicesWithErrors(t, "GetDeviceByID", services.GetDeviceByIDInput{IncludeSecrets: true}, models.EventReadDeviceSecretsKey, &models.Device{})
+			},
+		},
+		{
+			name: "GetDeviceByID with secrets without errors - fire event",
+			test: func(t *testing.T) {
+				devicesWithoutErrors(t, "GetDeviceByID", services.GetDeviceByIDInput{IncludeSecrets: true}, models.EventReadDeviceSecretsKey, &models.Device{})
+			},
+		},
+		{
+			name: "GetDeviceByID without secrets - Not fire event",
+			test: func(t *testing.T) {
+				mockDeviceManagerService := new(svcmock.MockDeviceManagerService)
+				mockEventMWPub := new(CloudEventMiddlewarePublisherMock)
+				deviceEventPublisher := NewDeviceEventPublisher(mockEventMWPub)(mockDeviceManagerService)
+
+				mockDeviceManagerService.On("GetDeviceByID", context.Background(), mock.Anything).Return(&models.Device{}, nil)
+				_, err := deviceEventPublisher.GetDeviceByID(context.Background(), services.GetDeviceByIDInput{ID: "device"})
+				assert.Nil(t, err)
+
+				mockDeviceManagerService.AssertExpectations(t)
+				mockEventMWPub.AssertNotCalled(t, "PublishCloudEvent")
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -27,6 +27,10 @@ func (mw dmsEventPublisher) GetDMSStats(ctx context.Context, input services.GetD
 	return mw.next.GetDMSStats(ctx, input)
 }
 
+func (mw dmsEventPublisher) GetDMSEnrollmentStats(ctx context.Context, input services.GetDMSEnrollmentStatsInput) (*models.DMSEnrollmentStats, error) {
+	return mw.next.GetDMSEnrollmentStats(ctx, input)
+}
+
 func (mw dmsEventPublisher) CreateDMS(ctx context.Context, input services.CreateDMSInput) (output *models.DMS, err error) {
 	defer func() {
 		if err == nil {
@@ -66,6 +70,10 @@ func (mw dmsEventPublisher) CACerts(ctx context.Context, aps string) ([]*x509.Ce
 	return mw.next.CACerts(ctx, aps)
 }
 
+func (mw dmsEventPublisher) SignedCACerts(ctx context.Context, aps string, pemEncoded bool) (*models.SignedContent, error) {
+	return mw.next.SignedCACerts(ctx, aps, pemEncoded)
+}
+
 func (mw dmsEventPublisher) Enroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (out *x509.Certificate, err error) {
 	defer func() {
 		if err == nil {
@@ -94,6 +102,69 @@ func (mw dmsEventPublisher) ServerKeyGen(ctx context.Context, csr *x509.Certific
 	return mw.next.ServerKeyGen(ctx, csr, aps)
 }
 
+func (mw dmsEventPublisher) CreateBootstrapToken(ctx context.Context, input services.CreateBootstrapTokenInput) (output *models.DMSBootstrapTokenCredentials, err error) {
+	defer func() {
+		if err == nil {
+			// the token must not leave the DMS Manager
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateDMSBootstrapTokenKey, output.DMSBootstrapToken)
+		}
+	}()
+	return mw.next.CreateBootstrapToken(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetBootstrapTokens(ctx context.Context, input services.GetBootstrapTokensInput) ([]models.DMSBootstrapToken, error) {
+	return mw.next.GetBootstrapTokens(ctx, input)
+}
+
+func (mw dmsEventPublisher) CreateClaimCode(ctx context.Context, input services.CreateClaimCodeInput) (output *models.DMSClaimCodeCredentials, err error) {
+	defer func() {
+		if err == nil {
+			// the code must not leave the DMS Manager
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateDMSClaimCodeKey, output.DMSClaimCode)
+		}
+	}()
+	return mw.next.CreateClaimCode(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetClaimCodes(ctx context.Context, input services.GetClaimCodesInput) ([]models.DMSClaimCode, error) {
+	return mw.next.GetClaimCodes(ctx, input)
+}
+
+func (mw dmsEventPublisher) RevokeClaimCode(ctx context.Context, input services.RevokeClaimCodeInput) (output *models.DMSClaimCode, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRevokeDMSClaimCodeKey, output)
+		}
+	}()
+	return mw.next.RevokeClaimCode(ctx, input)
+}
+
+func (mw dmsEventPublisher) ClaimDevice(ctx context.Context, input services.ClaimDeviceInput) (output *models.DeviceClaim, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventClaimDeviceKey, output)
+		}
+	}()
+	return mw.next.ClaimDevice(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetEnrollmentAudits(ctx context.Context, input services.GetEnrollmentAuditsInput) (string, error) {
+	return mw.next.GetEnrollmentAudits(ctx, input)
+}
+
+func (mw dmsEventPublisher) RevokeBootstrapToken(ctx context.Context, input services.RevokeBootstrapTokenInput) (output *models.DMSBootstrapToken, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRevokeDMSBootstrapTokenKey, output)
+		}
+	}()
+	return mw.next.RevokeBootstrapToken(ctx, input)
+}
+
+func (mw dmsEventPublisher) PreregisterDevices(ctx context.Context, input services.PreregisterDevicesInput) (*models.DevicePreregistration, error) {
+	return mw.next.PreregisterDevices(ctx, input)
+}
+
 func (mw dmsEventPublisher) BindIdentityToDevice(ctx context.Context, input services.BindIdentityToDeviceInput) (output *models.BindIdentityToDeviceOutput, err error) {
 	defer func() {
 		if err == nil {
@@ -102,3 +173,134 @@ func (mw dmsEventPublisher) BindIdentityToDevice(ctx context.Context, input serv
 	}()
 	return mw.next.BindIdentityToDevice(ctx, input)
 }
+
+func (mw dmsEventPublisher) RevokeSupersededCertificate(ctx context.Context, input services.RevokeSupersededCertificateInput) (output *models.Certificate, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRevokeSupersededKey, output)
+		}
+	}()
+	return mw.next.RevokeSupersededCertificate(ctx, input)
+}
+
+func (mw dmsEventPublisher) RevokeDeviceCertificate(ctx context.Context, input services.RevokeDeviceCertificateInput) (output *models.Certificate, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRevokeDeviceCertKey, output)
+		}
+	}()
+	return mw.next.RevokeDeviceCertificate(ctx, input)
+}
+
+func (mw dmsEventPublisher) StartCARotation(ctx context.Context, input services.StartCARotationInput) (output *models.CARotation, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventStartCARotationKey, output)
+		}
+	}()
+	return mw.next.StartCARotation(ctx, input)
+}
+
+func (mw dmsEventPublisher) ProcessCARotationBatch(ctx context.Context, input services.ProcessCARotationBatchInput) (output *models.CARotation, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCARotationBatchKey, output)
+		}
+	}()
+	return mw.next.ProcessCARotationBatch(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetCARotation(ctx context.Context, input services.GetCARotationInput) (*models.CARotation, error) {
+	return mw.next.GetCARotation(ctx, input)
+}
+
+func (mw dmsEventPublisher) CreateACMEEABKey(ctx context.Context, input services.CreateACMEEABKeyInput) (output *models.DMSACMEEABCredentials, err error) {
+	defer func() {
+		if err == nil {
+			// the HMAC key must not leave the DMS Manager
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateACMEEABKey, output.DMSACMEEABKey)
+		}
+	}()
+	return mw.next.CreateACMEEABKey(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetACMEEABKeys(ctx context.Context, input services.GetACMEEABKeysInput) ([]models.DMSACMEEABKey, error) {
+	return mw.next.GetACMEEABKeys(ctx, input)
+}
+
+func (mw dmsEventPublisher) RevokeACMEEABKey(ctx context.Context, input services.RevokeACMEEABKeyInput) (output *models.DMSACMEEABKey, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventRevokeACMEEABKey, output)
+		}
+	}()
+	return mw.next.RevokeACMEEABKey(ctx, input)
+}
+
+func (mw dmsEventPublisher) UpdateDMSStatus(ctx context.Context, input services.UpdateDMSStatusInput) (output *models.DMS, err error) {
+	prev, err := mw.GetDMSByID(ctx, services.GetDMSByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get DMS %s: %w", input.ID, err)
+	}
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDMSKey, models.UpdateModel[models.DMS]{
+				Previous: *prev,
+				Updated:  *output,
+			})
+		}
+	}()
+	return mw.next.UpdateDMSStatus(ctx, input)
+}
+
+func (mw dmsEventPublisher) CreateEnrollmentPolicy(ctx context.Context, input services.CreateEnrollmentPolicyInput) (output *models.DMSEnrollmentPolicy, err error) {
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventCreateDMSEnrollmentPolicyKey, output)
+		}
+	}()
+	return mw.next.CreateEnrollmentPolicy(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetEnrollmentPolicies(ctx context.Context, input services.GetEnrollmentPoliciesInput) (string, error) {
+	return mw.next.GetEnrollmentPolicies(ctx, input)
+}
+
+func (mw dmsEventPublisher) GetEnrollmentPolicyByID(ctx context.Context, input services.GetEnrollmentPolicyByIDInput) (*models.DMSEnrollmentPolicy, error) {
+	return mw.next.GetEnrollmentPolicyByID(ctx, input)
+}
+
+func (mw dmsEventPublisher) UpdateEnrollmentPolicy(ctx context.Context, input services.UpdateEnrollmentPolicyInput) (output *models.DMSEnrollmentPolicy, err error) {
+	prev, err := mw.GetEnrollmentPolicyByID(ctx, services.GetEnrollmentPolicyByIDInput{
+		ID: input.Policy.ID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("mw error: could not get enrollment policy %s: %w", input.Policy.ID, err)
+	}
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventUpdateDMSEnrollmentPolicyKey, models.UpdateModel[models.DMSEnrollmentPolicy]{
+				Previous: *prev,
+				Updated:  *output,
+			})
+		}
+	}()
+	return mw.next.UpdateEnrollmentPolicy(ctx, input)
+}
+
+func (mw dmsEventPublisher) DeleteEnrollmentPolicy(ctx context.Context, input services.DeleteEnrollmentPolicyInput) (err error) {
+	prev, err := mw.GetEnrollmentPolicyByID(ctx, services.GetEnrollmentPolicyByIDInput{
+		ID: input.ID,
+	})
+	if err != nil {
+		return fmt.Errorf("mw error: could not get enrollment policy %s: %w", input.ID, err)
+	}
+	defer func() {
+		if err == nil {
+			mw.eventMWPub.PublishCloudEvent(ctx, models.EventDeleteDMSEnrollmentPolicyKey, prev)
+		}
+	}()
+	return mw.next.DeleteEnrollmentPolicy(ctx, input)
+}
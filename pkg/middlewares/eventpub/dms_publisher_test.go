@@ -189,6 +189,98 @@ func TestDMSEventPublisher(t *testing.T) {
 				dmsWithoutErrors(t, "BindIdentityToDevice", services.BindIdentityToDeviceInput{}, models.EventBindDeviceIdentityKey, &models.BindIdentityToDeviceOutput{})
 			},
 		},
+		{
+			name: "RevokeSupersededCertificate with errors - Not fire event",
+			test: func(t *testing.T) {
+				dmsWithErrors(t, "RevokeSupersededCertificate", services.RevokeSupersededCertificateInput{}, models.EventRevokeSupersededKey, &models.Certificate{})
+			},
+		},
+		{
+			name: "RevokeSupersededCertificate without errors - fire event",
+			test: func(t *testing.T) {
+				dmsWithoutErrors(t, "RevokeSupersededCertificate", services.RevokeSupersededCertificateInput{}, models.EventRevokeSupersededKey, &models.Certificate{})
+			},
+		},
+		{
+			name: "RevokeDeviceCertificate with errors - Not fire event",
+			test: func(t *testing.T) {
+				dmsWithErrors(t, "RevokeDeviceCertificate", services.RevokeDeviceCertificateInput{}, models.EventRevokeDeviceCertKey, &models.Certificate{})
+			},
+		},
+		{
+			name: "RevokeDeviceCertificate without errors - fire event",
+			test: func(t *testing.T) {
+				dmsWithoutErrors(t, "RevokeDeviceCertificate", services.RevokeDeviceCertificateInput{}, models.EventRevokeDeviceCertKey, &models.Certificate{})
+			},
+		},
+		{
+			name: "StartCARotation with errors - Not fire event",
+			test: func(t *testing.T) {
+				dmsWithErrors(t, "StartCARotation", services.StartCARotationInput{}, models.EventStartCARotationKey, &models.CARotation{})
+			},
+		},
+		{
+			name: "StartCARotation without errors - fire event",
+			test: func(t *testing.T) {
+				dmsWithoutErrors(t, "StartCARotation", services.StartCARotationInput{}, models.EventStartCARotationKey, &models.CARotation{})
+			},
+		},
+		{
+			name: "ProcessCARotationBatch with errors - Not fire event",
+			test: func(t *testing.T) {
+				dmsWithErrors(t, "ProcessCARotationBatch", services.ProcessCARotationBatchInput{}, models.EventCARotationBatchKey, &models.CARotation{})
+			},
+		},
+		{
+			name: "ProcessCARotationBatch without errors - fire event",
+			test: func(t *testing.T) {
+				dmsWithoutErrors(t, "ProcessCARotationBatch", services.ProcessCARotationBatchInput{}, models.EventCARotationBatchKey, &models.CARotation{})
+			},
+		},
+		{
+			name: "CreateACMEEABKey with errors - Not fire event",
+			test: func(t *testing.T) {
+				dmsWithErrors(t, "CreateACMEEABKey", services.CreateACMEEABKeyInput{}, models.EventCreateACMEEABKey, &models.DMSACMEEABCredentials{})
+			},
+		},
+		{
+			name: "CreateACMEEABKey without errors - fire event without HMAC key",
+			test: func(t *testing.T) {
+				key := models.DMSACMEEABKey{KeyID: "kid-1", DMSID: "dms-1"}
+				expectations := []func(*svcmock.MockDMSManagerService){
+					func(mockDMSService *svcmock.MockDMSManagerService) {
+						mockDMSService.On("CreateACMEEABKey", context.Background(), mock.Anything).Return(&models.DMSACMEEABCredentials{
+							DMSACMEEABKey: key,
+							HMACKey:       "secret",
+						}, nil)
+					},
+				}
+
+				operation := func(dmsMiddleware services.DMSManagerService) {
+					_, err := dmsMiddleware.CreateACMEEABKey(context.Background(), services.CreateACMEEABKeyInput{DMSID: "dms-1"})
+					assert.NoError(t, err)
+				}
+
+				assertions := func(mockEventMWPub *CloudEventMiddlewarePublisherMock, mockDMSService *svcmock.MockDMSManagerService) {
+					mockDMSService.AssertExpectations(t)
+					mockEventMWPub.AssertCalled(t, "PublishCloudEvent", context.Background(), models.EventCreateACMEEABKey, key)
+				}
+
+				dmsEventChecker(models.EventCreateACMEEABKey, expectations, operation, assertions)
+			},
+		},
+		{
+			name: "RevokeACMEEABKey with errors - Not fire event",
+			test: func(t *testing.T) {
+				dmsWithErrors(t, "RevokeACMEEABKey", services.RevokeACMEEABKeyInput{}, models.EventRevokeACMEEABKey, &models.DMSACMEEABKey{})
+			},
+		},
+		{
+			name: "RevokeACMEEABKey without errors - fire event",
+			test: func(t *testing.T) {
+				dmsWithoutErrors(t, "RevokeACMEEABKey", services.RevokeACMEEABKeyInput{}, models.EventRevokeACMEEABKey, &models.DMSACMEEABKey{})
+			},
+		},
 	}
 
 	for _, tc := range testcases {
@@ -7,6 +7,7 @@ import (
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/monitoring"
 	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
 	"github.com/sirupsen/logrus"
 )
@@ -38,5 +39,9 @@ func (cemp *CloudEventMiddlewarePublisher) PublishCloudEvent(ctx context.Context
 	}
 
 	cemp.Logger.Tracef("publishing event: Type=%s Source=%s \n%s", eventType, src, string(eventBytes))
-	cemp.Publisher.Publish(string(eventType), message.NewMessage(event.ID(), eventBytes))
+	err := cemp.Publisher.Publish(string(eventType), message.NewMessage(event.ID(), eventBytes))
+	if err != nil {
+		cemp.Logger.Errorf("error while publishing event %s: %s", eventType, err)
+		monitoring.EventBusPublishFailures.Inc(cemp.ServiceID, string(eventType))
+	}
 }
@@ -0,0 +1,77 @@
+package models
+
+import "time"
+
+type ACMEStatus string
+
+const (
+	ACMEStatusPending     ACMEStatus = "pending"
+	ACMEStatusReady       ACMEStatus = "ready"
+	ACMEStatusProcessing  ACMEStatus = "processing"
+	ACMEStatusValid       ACMEStatus = "valid"
+	ACMEStatusInvalid     ACMEStatus = "invalid"
+	ACMEStatusDeactivated ACMEStatus = "deactivated"
+	ACMEStatusRevoked     ACMEStatus = "revoked"
+)
+
+type ACMEChallengeType string
+
+const (
+	ACMEChallengeHTTP01 ACMEChallengeType = "http-01"
+	ACMEChallengeDNS01  ACMEChallengeType = "dns-01"
+)
+
+const ACMEIdentifierDNS = "dns"
+
+// ACMEMetadataAccountKey is the certificate metadata key holding the ID of the ACME account the certificate was
+// issued to, so the account can revoke it.
+const ACMEMetadataAccountKey = "lamassu.io/acme/account"
+
+type ACMEIdentifier struct {
+	Type  string `json:"type"`
+	Value string `json:"value"`
+}
+
+// ACMEAccount is an account registered by an ACME client (RFC 8555 7.1.2) in the ACME directory of a DMS.
+// The ID is the RFC 7638 thumbprint of the account key.
+type ACMEAccount struct {
+	ID       string     `json:"id" gorm:"primaryKey"`
+	DMSID    string     `json:"dms_id"`
+	Status   ACMEStatus `json:"status"`
+	Contact  []string   `json:"contact" gorm:"serializer:json"`
+	Key      string     `json:"key"`
+	EABKeyID string     `json:"eab_key_id,omitempty"`
+
+	CreationTS time.Time `json:"creation_ts"`
+}
+
+type ACMEChallenge struct {
+	Type      ACMEChallengeType `json:"type"`
+	Token     string            `json:"token"`
+	Status    ACMEStatus        `json:"status"`
+	Validated *time.Time        `json:"validated,omitempty"`
+	Error     string            `json:"error,omitempty"`
+}
+
+type ACMEAuthorization struct {
+	Identifier ACMEIdentifier  `json:"identifier"`
+	Status     ACMEStatus      `json:"status"`
+	Wildcard   bool            `json:"wildcard,omitempty"`
+	Challenges []ACMEChallenge `json:"challenges"`
+}
+
+// ACMEOrder is a certificate order (RFC 8555 7.1.3). Orders own their authorizations, which are identified by
+// their index in the order.
+type ACMEOrder struct {
+	ID                      string              `json:"id" gorm:"primaryKey"`
+	AccountID               string              `json:"account_id"`
+	DMSID                   string              `json:"dms_id"`
+	Status                  ACMEStatus          `json:"status"`
+	Expires                 time.Time           `json:"expires"`
+	Identifiers             []ACMEIdentifier    `json:"identifiers" gorm:"serializer:json"`
+	Authorizations          []ACMEAuthorization `json:"authorizations" gorm:"serializer:json"`
+	CertificateSerialNumber string              `json:"certificate_serial_number,omitempty"`
+	Error                   string              `json:"error,omitempty"`
+
+	CreationTS time.Time `json:"creation_ts"`
+}
@@ -25,6 +25,8 @@ type ConditionType string
 const (
 	JSONSchema ConditionType = "JSON-SCHEMA"
 	JSONPath   ConditionType = "JSON-PATH"
+	// DMSIDs restricts the expiration digests delivered to the subscription to the comma separated list of DMS IDs.
+	DMSIDs ConditionType = "DMS-IDS"
 )
 
 type ChannelType string
@@ -33,6 +35,7 @@ const (
 	ChannelTypeEmail   ChannelType = "EMAIL"
 	ChannelTypeMSTeams ChannelType = "MSTEAMS"
 	ChannelTypeWebhook ChannelType = "WEBHOOK"
+	ChannelTypeSlack   ChannelType = "SLACK"
 )
 
 type Channel struct {
@@ -49,6 +52,10 @@ type MSTeamsChannelConfig struct {
 	WebhookURL string `json:"webhook_url"`
 }
 
+type SlackChannelConfig struct {
+	WebhookURL string `json:"webhook_url"`
+}
+
 type WebhookChannelConfig struct {
 	WebhookURL    string `json:"webhook_url"`
 	WebhookMethod string `json:"webhook_method"`
@@ -60,3 +67,32 @@ type AlertLatestEvent struct {
 	LastSeen  time.Time         `json:"seen_at"`
 	TotalSeen int               `json:"counter"`
 }
+
+// ExpirationDigest summarizes the CAs, DMS certificates and devices expiring in the next windows.
+type ExpirationDigest struct {
+	GeneratedAt time.Time                `json:"generated_at"`
+	Windows     []ExpirationDigestWindow `json:"windows"`
+}
+
+// ExpirationDigestWindow holds the assets expiring between From and To. Windows do not overlap, so an asset is only
+// reported in the first window it expires in.
+type ExpirationDigestWindow struct {
+	Window string               `json:"window"`
+	From   time.Time            `json:"from"`
+	To     time.Time            `json:"to"`
+	CAs    []ExpiringCA         `json:"cas"`
+	DMSs   []ExpiringDMSSummary `json:"dmss"`
+}
+
+type ExpiringCA struct {
+	ID         string    `json:"id"`
+	CommonName string    `json:"common_name"`
+	ValidTo    time.Time `json:"valid_to"`
+}
+
+// ExpiringDMSSummary counts the certificates enrolled through the DMS, and the devices owning them, expiring in the window.
+type ExpiringDMSSummary struct {
+	DMSID        string `json:"dms_id"`
+	Certificates int    `json:"certificates"`
+	Devices      int    `json:"devices"`
+}
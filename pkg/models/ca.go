@@ -25,6 +25,8 @@ const (
 	StatusActive  CertificateStatus = "ACTIVE"
 	StatusExpired CertificateStatus = "EXPIRED"
 	StatusRevoked CertificateStatus = "REVOKED"
+	// StatusDeleting is the status of the CAs being deleted in the background. See CAService.DeleteCA.
+	StatusDeleting CertificateStatus = "DELETING"
 )
 
 type Certificate struct {
@@ -43,12 +45,53 @@ type Certificate struct {
 	EngineID            string                 `json:"engine_id"`
 }
 
+// CertificateSigningResult is the outcome of signing one of the certificate requests of a batch. Index is the
+// position of the request in the batch. Either Certificate or Error is set.
+type CertificateSigningResult struct {
+	Index       int          `json:"index"`
+	Certificate *Certificate `json:"certificate,omitempty"`
+	Error       string       `json:"error,omitempty"`
+}
+
 type Expiration struct {
 	Type     ExpirationTimeRef `json:"type"`
 	Duration *TimeDuration     `json:"duration,omitempty"`
 	Time     *time.Time        `json:"time,omitempty"`
 }
 
+// SigningProfile holds the per-request overrides applied by the CA when signing a certificate request.
+// Empty values keep the CA defaults.
+type SigningProfile struct {
+	OCSPServers           []string `json:"ocsp_servers"`
+	CRLDistributionPoints []string `json:"crl_distribution_points"`
+	// SubjectOrder re-encodes the subject of the signed certificate with its RDNs in the given order.
+	SubjectOrder []SubjectAttribute `json:"subject_order"`
+	// KeyUsages and ExtendedKeyUsages replace the default usages of the signed certificate.
+	KeyUsages         []KeyUsage         `json:"key_usages,omitempty"`
+	ExtendedKeyUsages []ExtendedKeyUsage `json:"extended_key_usages,omitempty"`
+	// NotBeforeBackdate sets the NotBefore of the signed certificate in the past, so devices with drifting clocks
+	// do not consider it not yet valid. It replaces the backdate of the certificate profile and the CA.
+	NotBeforeBackdate *TimeDuration `json:"not_before_backdate,omitempty"`
+	// Validity shortens the validity of the signed certificate. It is capped by the CA issuance expiration and the
+	// certificate profile max validity, so callers can never extend the validity granted by the CA.
+	Validity *TimeDuration `json:"validity,omitempty"`
+}
+
+// CertificateIssuanceContext identifies on behalf of which DMS and device a certificate was signed,
+// together with the signing profile applied and the request that triggered the issuance.
+type CertificateIssuanceContext struct {
+	DMSID     string          `json:"dms_id,omitempty"`
+	DeviceID  string          `json:"device_id,omitempty"`
+	Profile   *SigningProfile `json:"profile,omitempty"`
+	RequestID string          `json:"request_id,omitempty"`
+}
+
+// CertificateMetadataIssuanceContextKey records (with a CertificateIssuanceContext value, without the profile) the
+// DMS and device a certificate was signed for. It scopes the self-service revocations of the DMS owners.
+const (
+	CertificateMetadataIssuanceContextKey = "lamassu.io/ca/issuance-context"
+)
+
 type IssuerCAMetadata struct {
 	SerialNumber string `json:"serial_number"`
 	ID           string `json:"id"`
@@ -63,6 +106,20 @@ type CACertificate struct {
 	Type                  CertificateType        `json:"type"`
 	CreationTS            time.Time              `json:"creation_ts"`
 	Level                 int                    `json:"level"`
+	// ChainIDs holds the IDs of the parent CAs sorted from the Root CA to the direct issuer. Empty for Root CAs.
+	ChainIDs []string `json:"chain_ids" gorm:"serializer:json"`
+	// CAChain holds the certificates of the parent CAs, in the same order as ChainIDs. It is only resolved by GetCAByID.
+	CAChain []*X509Certificate `json:"ca_chain,omitempty" gorm:"-"`
+	// HybridKeyMetadata is only set for hybrid CAs. The certificates signed by hybrid CAs carry an alternative
+	// post-quantum signature besides the classical one.
+	HybridKeyMetadata *HybridKeyMetadata `json:"hybrid_key_metadata,omitempty" gorm:"serializer:json"`
+}
+
+// HybridKeyMetadata describes the alternative (post-quantum) key of a hybrid CA. The key is held by the external
+// hybrid signer with the given ID, not by the crypto engine of the CA.
+type HybridKeyMetadata struct {
+	SignerID string  `json:"signer_id"`
+	Type     KeyType `json:"type"`
 }
 
 type CAStats struct {
@@ -80,6 +137,25 @@ type CertificatesStats struct {
 	CertificateStatus            map[CertificateStatus]int `json:"status_distribution"`
 }
 
+// CAInventory is the machine-readable inventory of the CA service, collected as compliance evidence.
+type CAInventory struct {
+	GeneratedAt time.Time               `json:"generated_at"`
+	CAs         []CAInventoryEntry      `json:"cas"`
+	Engines     []*CryptoEngineProvider `json:"engines"`
+	Profiles    []CertificateProfile    `json:"profiles"`
+	Stats       CAStats                 `json:"stats"`
+}
+
+type CAInventoryEntry struct {
+	CACertificate
+	CertificatePEM string `json:"certificate_pem"`
+	// ChainPEMs holds the certificates of the parent CAs, sorted from the Root CA to the direct issuer.
+	ChainPEMs []string `json:"chain_pems"`
+	// ProfileIDs lists the certificate profiles applied by default to the certificates signed by the CA.
+	ProfileIDs       []string                  `json:"profile_ids"`
+	CertificateStats map[CertificateStatus]int `json:"certificate_stats"`
+}
+
 type MonitoringExpirationDelta struct {
 	Delta     TimeDuration `json:"delta"`
 	Name      string       `json:"name"`
@@ -96,6 +172,231 @@ const (
 	CAAttachedToDeviceKey = "lamassu.io/ca/attached-to"
 )
 
+// CAMetadataProductionKey flags (with a boolean value) the CAs used in production. Production CAs
+// are expected to keep their keys in hardware backed engines.
+const (
+	CAMetadataProductionKey = "lamassu.io/ca/production"
+)
+
+// CAMetadataFallbackEngineKey holds the ID of the crypto engine keeping a copy of the CA key. The CA signs with it
+// while its primary engine is unavailable. Set it with SetCAFallbackEngine, which copies the key.
+const (
+	CAMetadataFallbackEngineKey = "lamassu.io/ca/fallback-engine"
+)
+
+// CAMetadataTokenSigningKey enables (with a boolean value) the CA key to sign JWS tokens. The public keys
+// of the enabled CAs are published as a JWKS, using the CA ID as key ID.
+const (
+	CAMetadataTokenSigningKey = "lamassu.io/ca/token-signing"
+)
+
+// CAMetadataNotBeforeBackdateKey configures (with a duration string, i.e. "5m") the default backdate of the NotBefore
+// of the certificates signed by the CA. Signing profiles and certificate profiles can replace it.
+const (
+	CAMetadataNotBeforeBackdateKey = "lamassu.io/ca/not-before-backdate"
+)
+
+// CAMetadataIssuanceWebhookKey configures (with a CAIssuanceWebhook value) the webhook reviewing each certificate
+// before it is signed by the CA. It is reserved, set it with SetCAIssuanceWebhook.
+const (
+	CAMetadataIssuanceWebhookKey = "lamassu.io/ca/issuance-webhook"
+)
+
+type CAIssuanceWebhook struct {
+	URL string `json:"url"`
+	// FailOpen signs the certificates when the webhook can not be reached or replies with an invalid response.
+	// By default, the issuance is denied.
+	FailOpen bool `json:"fail_open"`
+}
+
+// CertificateIssuanceTemplate holds the values of the certificate to be issued that can be reviewed by the
+// issuance webhook.
+type CertificateIssuanceTemplate struct {
+	Subject           Subject            `json:"subject"`
+	NotAfter          time.Time          `json:"not_after"`
+	KeyUsages         []KeyUsage         `json:"key_usages"`
+	ExtendedKeyUsages []ExtendedKeyUsage `json:"extended_key_usages"`
+}
+
+// CertificateIssuanceReview is sent by the CA to the issuance webhook before signing a certificate.
+type CertificateIssuanceReview struct {
+	CAID               string                      `json:"ca_id"`
+	RequestID          string                      `json:"request_id,omitempty"`
+	CertificateRequest *X509CertificateRequest     `json:"csr"`
+	Template           CertificateIssuanceTemplate `json:"template"`
+}
+
+// CertificateIssuanceReviewResponse is the verdict of the issuance webhook. The webhook can replace the template
+// as long as the issuance is only narrowed: the certificate can not expire later, nor have usages other than the
+// ones in the reviewed template. Its subject can only drop attributes of the reviewed subject (never change or add
+// them), and must satisfy the certificate profile of the CA.
+type CertificateIssuanceReviewResponse struct {
+	Allowed  bool                         `json:"allowed"`
+	Reason   string                       `json:"reason,omitempty"`
+	Template *CertificateIssuanceTemplate `json:"template,omitempty"`
+}
+
+type CAPendingActionType string
+
+const (
+	CAPendingActionDelete CAPendingActionType = "DELETE_CA"
+	CAPendingActionRevoke CAPendingActionType = "REVOKE_CA"
+	// CAPendingActionDeleteKey removes the copy of the CA key left in the source engine of a key migration.
+	CAPendingActionDeleteKey CAPendingActionType = "DELETE_CA_KEY"
+)
+
+// CAPendingAction is a destructive operation over a CA waiting for the approval of a second administrator.
+type CAPendingAction struct {
+	ID               string              `json:"id" gorm:"primaryKey"`
+	Type             CAPendingActionType `json:"type"`
+	CAID             string              `json:"ca_id" gorm:"column:ca_id"`
+	RevocationReason RevocationReason    `json:"revocation_reason,omitempty"`
+	// Force is set for the delete actions requested with DeleteCAInput.Force.
+	Force bool `json:"force,omitempty"`
+	// EngineID is set for the DELETE_CA_KEY actions: the crypto engine the CA key is removed from.
+	EngineID    string     `json:"engine_id,omitempty"`
+	RequestedBy string     `json:"requested_by"`
+	RequestedAt time.Time  `json:"requested_at"`
+	ExpiresAt   time.Time  `json:"expires_at"`
+	ApprovedBy  string     `json:"approved_by,omitempty"`
+	ApprovedAt  *time.Time `json:"approved_at,omitempty"`
+}
+
+// CAMetadataDualControlKey flags (with a CADualControl value) the CAs whose sign operations require M-of-N approvals,
+// i.e. firmware signing CAs. Guarded operations are only executed through approved signing requests. The key can only
+// be set through the metadata while the CA has no dual control. Once set, the policy is only changed or removed
+// through approved UPDATE_DUAL_CONTROL signing requests.
+const (
+	CAMetadataDualControlKey = "lamassu.io/ca/dual-control"
+)
+
+type CASigningOperation string
+
+const (
+	CASigningOperationCertificate CASigningOperation = "SIGN_CERTIFICATE"
+	// CASigningOperationMessage also covers the CRLs and OCSP responses signed with the CA key, so CAs whose revocation
+	// status is served by the VA should only guard SIGN_CERTIFICATE.
+	CASigningOperationMessage CASigningOperation = "SIGN_MESSAGE"
+	// CASigningOperationDualControl replaces the dual control policy of the CA. It is always guarded, regardless of the
+	// operations of the policy.
+	CASigningOperationDualControl CASigningOperation = "UPDATE_DUAL_CONTROL"
+	// CASigningOperationKeyExport exports the (encrypted) CA key or copies it into another crypto engine (migrations
+	// and fallback engines). It is always guarded and can not be requested through signing requests, so the keys of
+	// dual control CAs can not leave their engine.
+	CASigningOperationKeyExport CASigningOperation = "EXPORT_KEY"
+)
+
+type CADualControl struct {
+	// RequiredApprovals (M) is the number of approvals a signing request needs to be executed.
+	RequiredApprovals int `json:"required_approvals"`
+	// Approvers (N) restricts who can approve the signing requests. Any administrator can approve them if empty.
+	// The requester can never approve its own request.
+	Approvers []string `json:"approvers,omitempty"`
+	// ApprovalWindow is the time a signing request can be approved since it was requested. Defaults to the
+	// destructive operations approval window.
+	ApprovalWindow *TimeDuration `json:"approval_window,omitempty"`
+	// Operations are the guarded sign operations. Both are guarded if empty.
+	Operations []CASigningOperation `json:"operations,omitempty"`
+}
+
+type CASigningRequestStatus string
+
+const (
+	CASigningRequestPending  CASigningRequestStatus = "PENDING"
+	CASigningRequestSigned   CASigningRequestStatus = "SIGNED"
+	CASigningRequestRejected CASigningRequestStatus = "REJECTED"
+	CASigningRequestExpired  CASigningRequestStatus = "EXPIRED"
+	// CASigningRequestApproved requests collected the required approvals and are being executed. The replica storing
+	// the transition from PENDING is the only one executing the request.
+	CASigningRequestApproved CASigningRequestStatus = "APPROVED"
+	// CASigningRequestFailed requests were approved but the sign operation failed.
+	CASigningRequestFailed CASigningRequestStatus = "FAILED"
+)
+
+// CASigningRequest is a sign operation against a dual control CA, waiting for M-of-N approvals. The crypto engine is
+// only invoked once the request collects the required approvals.
+type CASigningRequest struct {
+	ID        string             `json:"id" gorm:"primaryKey"`
+	CAID      string             `json:"ca_id" gorm:"column:ca_id"`
+	Operation CASigningOperation `json:"operation"`
+	// Message, MessageType and SigningAlgorithm are set for SIGN_MESSAGE requests.
+	Message          []byte          `json:"message,omitempty"`
+	MessageType      SignMessageType `json:"message_type,omitempty"`
+	SigningAlgorithm string          `json:"signing_algorithm,omitempty"`
+	// CertRequest, Subject, SigningProfile and CertificateProfileID are set for SIGN_CERTIFICATE requests.
+	CertRequest          *X509CertificateRequest `json:"csr,omitempty" gorm:"serializer:json"`
+	Subject              *Subject                `json:"subject,omitempty" gorm:"serializer:json"`
+	SignVerbatim         bool                    `json:"sign_verbatim,omitempty"`
+	SigningProfile       *SigningProfile         `json:"signing_profile,omitempty" gorm:"serializer:json"`
+	CertificateProfileID string                  `json:"certificate_profile_id,omitempty"`
+	// DualControl is the new policy of UPDATE_DUAL_CONTROL requests. The dual control of the CA is removed if nil.
+	// The request is marked as SIGNED once the policy is replaced.
+	DualControl *CADualControl `json:"dual_control,omitempty" gorm:"serializer:json"`
+
+	Status            CASigningRequestStatus `json:"status"`
+	RequiredApprovals int                    `json:"required_approvals"`
+	// Approvers is a snapshot of the approvers of the CA when the request was created.
+	Approvers   []string            `json:"approvers,omitempty" gorm:"serializer:json"`
+	Approvals   []CASigningApproval `json:"approvals" gorm:"serializer:json"`
+	RequestedBy string              `json:"requested_by"`
+	RequestedAt time.Time           `json:"requested_at"`
+	ExpiresAt   time.Time           `json:"expires_at"`
+	// Signature is set once a SIGN_MESSAGE request is signed. CertificateSerialNumber is set once a SIGN_CERTIFICATE
+	// request is signed.
+	Signature               []byte `json:"signature,omitempty"`
+	CertificateSerialNumber string `json:"certificate_serial_number,omitempty"`
+	// AuditLog records every state change of the request, in order.
+	AuditLog []CASigningAuditEntry `json:"audit_log" gorm:"serializer:json"`
+}
+
+type CASigningApproval struct {
+	Approver   string    `json:"approver"`
+	ApprovedAt time.Time `json:"approved_at"`
+}
+
+type CASigningAuditAction string
+
+const (
+	CASigningAuditRequested CASigningAuditAction = "REQUESTED"
+	CASigningAuditApproved  CASigningAuditAction = "APPROVED"
+	CASigningAuditRejected  CASigningAuditAction = "REJECTED"
+	CASigningAuditExpired   CASigningAuditAction = "EXPIRED"
+	CASigningAuditSigned    CASigningAuditAction = "SIGNED"
+	CASigningAuditFailed    CASigningAuditAction = "FAILED"
+)
+
+type CASigningAuditEntry struct {
+	Timestamp time.Time            `json:"timestamp"`
+	Actor     string               `json:"actor,omitempty"`
+	Action    CASigningAuditAction `json:"action"`
+	Detail    string               `json:"detail,omitempty"`
+}
+
+type SoftwareKeyCustodyFinding struct {
+	CAID          string              `json:"ca_id"`
+	Subject       Subject             `json:"subject"`
+	Type          CertificateType     `json:"type"`
+	Production    bool                `json:"production"`
+	EngineID      string              `json:"engine_id"`
+	EngineType    CryptoEngineType    `json:"engine_type"`
+	SecurityLevel CryptoEngineSL      `json:"security_level"`
+	KeyMetadata   KeyStrengthMetadata `json:"key_metadata"`
+}
+
+type SoftwareKeyCustodyReport struct {
+	CAs []SoftwareKeyCustodyFinding `json:"cas"`
+}
+
+// CAKeyBackup is the private key of a CA encrypted by its crypto engine. Only the engine holding the same key
+// encryption key can restore it.
+type CAKeyBackup struct {
+	CAID       string    `json:"ca_id"`
+	EngineID   string    `json:"engine_id"`
+	KeyID      string    `json:"key_id"`
+	Backup     []byte    `json:"backup"`
+	ExportedAt time.Time `json:"exported_at"`
+}
+
 type CAAttachedToDevice struct {
 	AuthorizedBy struct {
 		RAID string `json:"ra_id"`
@@ -0,0 +1,35 @@
+package models
+
+import "time"
+
+type CAEventType string
+
+const (
+	CAEventCreated                CAEventType = "CREATED"
+	CAEventImported               CAEventType = "IMPORTED"
+	CAEventKeyMigrated            CAEventType = "KEY_MIGRATED"
+	CAEventKeyRemoved             CAEventType = "KEY_REMOVED"
+	CAEventKeyExported            CAEventType = "KEY_EXPORTED"
+	CAEventKeyRestored            CAEventType = "KEY_RESTORED"
+	CAEventFallbackEngineUpdated  CAEventType = "FALLBACK_ENGINE_UPDATED"
+	CAEventIssuanceWebhookUpdated CAEventType = "ISSUANCE_WEBHOOK_UPDATED"
+	CAEventStatusUpdated          CAEventType = "STATUS_UPDATED"
+	CAEventSettingsUpdated        CAEventType = "SETTINGS_UPDATED"
+	CAEventCRLGenerated           CAEventType = "CRL_GENERATED"
+	CAEventBulkRevocation         CAEventType = "BULK_REVOCATION"
+	CAEventSuccessorCreated       CAEventType = "SUCCESSOR_CREATED"
+	// CAEventSigningRequest mirrors each entry of the audit log of the signing requests of dual control CAs.
+	CAEventSigningRequest CAEventType = "SIGNING_REQUEST"
+)
+
+// CAEvent is an entry of the lifecycle timeline of a CA. Details holds the event specific attributes, i.e. the
+// previous and new status of a status update. Actor is the authenticated caller that triggered the event, empty for
+// the operations cascaded by the CA service.
+type CAEvent struct {
+	ID        string         `json:"id" gorm:"primaryKey"`
+	CAID      string         `json:"ca_id" gorm:"column:ca_id"`
+	Type      CAEventType    `json:"type"`
+	Timestamp time.Time      `json:"timestamp"`
+	Actor     string         `json:"actor"`
+	Details   map[string]any `json:"details" gorm:"serializer:json"`
+}
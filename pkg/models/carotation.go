@@ -0,0 +1,38 @@
+package models
+
+import "time"
+
+// CAMetadataRotationKey holds (with a CARotation value) the rotation campaign of a CA. The CA is marked for
+// retirement while the campaign runs, and its issuance is paused once the devices holding its certificates have been
+// forced to reenroll with the successor.
+const (
+	CAMetadataRotationKey = "lamassu.io/ca/rotation"
+)
+
+type CARotationStatus string
+
+const (
+	CARotationRunning   CARotationStatus = "RUNNING"
+	CARotationCompleted CARotationStatus = "COMPLETED"
+)
+
+// CARotation tracks the progress of a CA rotation campaign. The certificates issued by the rotated CA are processed
+// in batches of BatchSize, resuming from Bookmark.
+type CARotation struct {
+	CAID          string           `json:"ca_id"`
+	SuccessorCAID string           `json:"successor_ca_id"`
+	Status        CARotationStatus `json:"status"`
+	BatchSize     int              `json:"batch_size"`
+	Bookmark      string           `json:"bookmark"`
+	// UpdatedDMSs are the DMSs switched to enroll with the successor CA when the campaign started.
+	UpdatedDMSs []string `json:"updated_dmss"`
+	// ProcessedCertificates counts the certificates of the rotated CA processed so far.
+	ProcessedCertificates int `json:"processed_certificates"`
+	// ReenrolledDevices are the devices forced to reenroll with the successor CA.
+	ReenrolledDevices []string `json:"reenrolled_devices"`
+	// FailedDevices are the devices that could not be forced to reenroll (i.e. decommissioned devices).
+	FailedDevices []string   `json:"failed_devices"`
+	StartedAt     time.Time  `json:"started_at"`
+	UpdatedAt     time.Time  `json:"updated_at"`
+	CompletedAt   *time.Time `json:"completed_at,omitempty"`
+}
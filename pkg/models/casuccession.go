@@ -0,0 +1,39 @@
+package models
+
+import "time"
+
+// CAMetadataSuccessorKey holds (with a CASuccession value) the successor of a CA created before it expires.
+// The successor holds the ID of the replaced CA under CAMetadataPredecessorKey.
+const (
+	CAMetadataSuccessorKey   = "lamassu.io/ca/successor"
+	CAMetadataPredecessorKey = "lamassu.io/ca/predecessor"
+)
+
+// CertificateMetadataCrossSignedCAKey identifies (with the ID of the successor CA) the cross certificates issued by a
+// CA to its successor.
+const (
+	CertificateMetadataCrossSignedCAKey = "lamassu.io/ca/cross-signed"
+)
+
+// CAMetadataIssuancePausedKey pauses (with a boolean value) the issuance of certificates by the CA. It is set on the
+// CAs replaced by a successor once they expire.
+const (
+	CAMetadataIssuancePausedKey = "lamassu.io/ca/issuance-paused"
+)
+
+// DMSMetadataCASuccessionKey notifies (with a CASuccession value) the DMSs enrolling with a CA that a successor has
+// been created for it.
+const (
+	DMSMetadataCASuccessionKey = "lamassu.io/ra/ca-succession"
+)
+
+// CASuccession links a CA with the successor created to replace it. CrossCertificateSerialNumber is the certificate
+// of the successor key signed by the predecessor CA, so the devices trusting the predecessor also trust the
+// certificates issued by the successor.
+type CASuccession struct {
+	CAID                         string    `json:"ca_id"`
+	SuccessorCAID                string    `json:"successor_ca_id"`
+	CrossCertificateSerialNumber string    `json:"cross_certificate_serial_number"`
+	ExpiresAt                    time.Time `json:"expires_at"`
+	CreatedAt                    time.Time `json:"created_at"`
+}
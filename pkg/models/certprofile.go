@@ -0,0 +1,68 @@
+package models
+
+import "time"
+
+type KeyUsage string
+
+const (
+	KeyUsageDigitalSignature  KeyUsage = "DIGITAL_SIGNATURE"
+	KeyUsageContentCommitment KeyUsage = "CONTENT_COMMITMENT"
+	KeyUsageKeyEncipherment   KeyUsage = "KEY_ENCIPHERMENT"
+	KeyUsageDataEncipherment  KeyUsage = "DATA_ENCIPHERMENT"
+	KeyUsageKeyAgreement      KeyUsage = "KEY_AGREEMENT"
+	KeyUsageCertSign          KeyUsage = "CERT_SIGN"
+	KeyUsageCRLSign           KeyUsage = "CRL_SIGN"
+	KeyUsageEncipherOnly      KeyUsage = "ENCIPHER_ONLY"
+	KeyUsageDecipherOnly      KeyUsage = "DECIPHER_ONLY"
+)
+
+type ExtendedKeyUsage string
+
+const (
+	ExtendedKeyUsageServerAuth      ExtendedKeyUsage = "SERVER_AUTH"
+	ExtendedKeyUsageClientAuth      ExtendedKeyUsage = "CLIENT_AUTH"
+	ExtendedKeyUsageCodeSigning     ExtendedKeyUsage = "CODE_SIGNING"
+	ExtendedKeyUsageEmailProtection ExtendedKeyUsage = "EMAIL_PROTECTION"
+	ExtendedKeyUsageTimeStamping    ExtendedKeyUsage = "TIME_STAMPING"
+	ExtendedKeyUsageOCSPSigning     ExtendedKeyUsage = "OCSP_SIGNING"
+)
+
+// SANPolicy defines which Subject Alternative Names can be requested in the CSR.
+type SANPolicy struct {
+	AllowDNSNames       bool `json:"allow_dns_names"`
+	AllowIPAddresses    bool `json:"allow_ip_addresses"`
+	AllowEmailAddresses bool `json:"allow_email_addresses"`
+	AllowURIs           bool `json:"allow_uris"`
+	// DNSDomains restricts the DNS names to the listed domains and their subdomains. Empty allows any domain.
+	DNSDomains []string `json:"dns_domains"`
+}
+
+// SubjectConstraints defines the subject of the certificates issued with a profile.
+type SubjectConstraints struct {
+	// RequiredAttributes lists the attributes that must be present (non empty) in the subject.
+	RequiredAttributes []SubjectAttribute `json:"required_attributes"`
+	// CommonNamePattern is a regular expression the common name must match. Empty allows any common name.
+	CommonNamePattern string `json:"common_name_pattern"`
+}
+
+// CertificateProfile defines the constraints enforced by the CA service when signing certificate requests.
+// The profile applies to every certificate signed by the CAs in CAIDs: sign requests (i.e. the DMS enrollment
+// settings) can only reference the profile bound to the CA. Profiles without CAIDs are not enforced.
+type CertificateProfile struct {
+	ID          string   `json:"id" gorm:"primaryKey"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	CAIDs       []string `json:"ca_ids" gorm:"serializer:json"`
+	// KeyUsages and ExtendedKeyUsages are set in the issued certificates. The CSR cannot request other usages.
+	// The engine defaults apply when empty.
+	KeyUsages          []KeyUsage         `json:"key_usages" gorm:"serializer:json"`
+	ExtendedKeyUsages  []ExtendedKeyUsage `json:"extended_key_usages" gorm:"serializer:json"`
+	SANPolicy          SANPolicy          `json:"san_policy" gorm:"serializer:json"`
+	SubjectConstraints SubjectConstraints `json:"subject_constraints" gorm:"serializer:json"`
+	// MaxValidity caps the validity of the issued certificates. The CA issuance expiration applies when empty.
+	MaxValidity *TimeDuration `json:"max_validity,omitempty" gorm:"serializer:json"`
+	// NotBeforeBackdate sets the NotBefore of the issued certificates in the past to tolerate device clock skew.
+	// It replaces the backdate configured in the CA metadata.
+	NotBeforeBackdate *TimeDuration `json:"not_before_backdate,omitempty" gorm:"serializer:json"`
+	CreationTS        time.Time     `json:"creation_ts"`
+}
@@ -0,0 +1,144 @@
+package models
+
+import (
+	"fmt"
+	"time"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+)
+
+// CloudConnectorSource is the event source of the cloud connectors built with the connectors SDK.
+func CloudConnectorSource(id string) string {
+	return fmt.Sprintf("lrn://service/lamassuiot-connector/%s", id)
+}
+
+// CloudConnectorMetadataKey is the metadata key holding the configuration of a cloud connector in
+// CAs, DMSs and devices. Entities without the key are ignored by the connector.
+func CloudConnectorMetadataKey(connectorID string) string {
+	return fmt.Sprintf("lamassu.io/iot/%s", connectorID)
+}
+
+// ConnectorCredentials are the provider credentials injected into a cloud connector. Version changes whenever the
+// credentials are rotated.
+type ConnectorCredentials struct {
+	Version int
+	Values  map[string]string
+}
+
+type ConnectorHealthStatus string
+
+const (
+	ConnectorHealthy   ConnectorHealthStatus = "HEALTHY"
+	ConnectorDegraded  ConnectorHealthStatus = "DEGRADED"
+	ConnectorUnhealthy ConnectorHealthStatus = "UNHEALTHY"
+)
+
+type ConnectorHealth struct {
+	Status  ConnectorHealthStatus `json:"status"`
+	Message string                `json:"message,omitempty"`
+}
+
+// ConnectorRegistration is published by a cloud connector when it starts.
+type ConnectorRegistration struct {
+	ConnectorID string    `json:"connector_id"`
+	Provider    string    `json:"provider"`
+	Version     string    `json:"version"`
+	Timestamp   time.Time `json:"timestamp"`
+}
+
+// ConnectorInstance is a deployed cloud connector found by a connectors.ConnectorRegistry. Health and LastSeen are
+// only known by the registries fed with the connector events.
+type ConnectorInstance struct {
+	ConnectorID string           `json:"connector_id"`
+	Provider    string           `json:"provider"`
+	Version     string           `json:"version,omitempty"`
+	Address     string           `json:"address,omitempty"`
+	Health      *ConnectorHealth `json:"health,omitempty"`
+	LastSeen    time.Time        `json:"last_seen,omitempty"`
+}
+
+// ConnectorHealthReport is published periodically by a cloud connector.
+type ConnectorHealthReport struct {
+	ConnectorID string          `json:"connector_id"`
+	Health      ConnectorHealth `json:"health"`
+	Timestamp   time.Time       `json:"timestamp"`
+}
+
+// ProviderCertificateStatus is the status of a certificate in a cloud provider registry. Status is either
+// StatusActive or StatusRevoked.
+type ProviderCertificateStatus struct {
+	SerialNumber string
+	ProviderID   string
+	Status       CertificateStatus
+}
+
+type CertificateReconciliationAction string
+
+const (
+	// ReconciliationActionNone is used when the drift can not be fixed, i.e. the certificate is unknown to Lamassu.
+	ReconciliationActionNone            CertificateReconciliationAction = "NONE"
+	ReconciliationActionProviderUpdated CertificateReconciliationAction = "PROVIDER_UPDATED"
+	ReconciliationActionLamassuUpdated  CertificateReconciliationAction = "LAMASSU_UPDATED"
+)
+
+// CertificateStatusDiscrepancy is a certificate whose status differs between Lamassu and a cloud provider.
+// LamassuStatus is empty for certificates unknown to Lamassu.
+type CertificateStatusDiscrepancy struct {
+	ConnectorID    string                          `json:"connector_id"`
+	SerialNumber   string                          `json:"serial_number"`
+	ProviderID     string                          `json:"provider_id"`
+	LamassuStatus  CertificateStatus               `json:"lamassu_status"`
+	ProviderStatus CertificateStatus               `json:"provider_status"`
+	Action         CertificateReconciliationAction `json:"action"`
+	Error          string                          `json:"error,omitempty"`
+}
+
+type CertificateReconciliationReport struct {
+	ConnectorID   string                         `json:"connector_id"`
+	StartedAt     time.Time                      `json:"started_at"`
+	FinishedAt    time.Time                      `json:"finished_at"`
+	Checked       int                            `json:"checked"`
+	Discrepancies []CertificateStatusDiscrepancy `json:"discrepancies"`
+}
+
+type DeviceCloudSyncReason string
+
+const (
+	// DeviceCloudSyncNotRegistered is used when the device is bound to the connector but has not been registered
+	// in the cloud provider yet.
+	DeviceCloudSyncNotRegistered DeviceCloudSyncReason = "NOT_REGISTERED"
+	// DeviceCloudSyncCertificateNotSynced is used when the active identity certificate of the device has not been
+	// registered in the cloud provider, i.e. the device was reenrolled after the cloud registration.
+	DeviceCloudSyncCertificateNotSynced DeviceCloudSyncReason = "CERTIFICATE_NOT_SYNCED"
+)
+
+// DeviceCloudSyncStatus is a device whose active identity certificate is not reflected in a cloud provider.
+type DeviceCloudSyncStatus struct {
+	DeviceID     string                `json:"device_id"`
+	DMSID        string                `json:"dms_id"`
+	ConnectorID  string                `json:"connector_id"`
+	SerialNumber string                `json:"serial_number"`
+	Reason       DeviceCloudSyncReason `json:"reason"`
+}
+
+type ConnectorPendingEventStatus string
+
+const (
+	// ConnectorPendingEventRetrying events are retried with exponential backoff.
+	ConnectorPendingEventRetrying ConnectorPendingEventStatus = "RETRYING"
+	// ConnectorPendingEventDeadLetter events ran out of attempts. They are only retried when replayed.
+	ConnectorPendingEventDeadLetter ConnectorPendingEventStatus = "DEAD_LETTER"
+)
+
+// ConnectorPendingEvent is an event a cloud connector failed to handle. It is kept in the connector retry queue until
+// it is handled.
+type ConnectorPendingEvent struct {
+	ID            string                      `json:"id" gorm:"primaryKey"`
+	ConnectorID   string                      `json:"connector_id"`
+	Event         cloudevents.Event           `json:"event" gorm:"serializer:json"`
+	Status        ConnectorPendingEventStatus `json:"status"`
+	Attempts      int                         `json:"attempts"`
+	LastError     string                      `json:"last_error"`
+	NextAttemptAt time.Time                   `json:"next_attempt_at"`
+	CreatedAt     time.Time                   `json:"created_at"`
+}
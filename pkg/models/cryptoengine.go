@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type CryptoEngineType string
 
 const (
@@ -9,6 +11,7 @@ const (
 	VaultKV2          CryptoEngineType = "HASHICORP_VAULT_KV_V2"
 	AWSKMS            CryptoEngineType = "AWS_KMS"
 	AWSSecretsManager CryptoEngineType = "AWS_SECRETS_MANAGER"
+	GCPKMS            CryptoEngineType = "GCP_KMS"
 )
 
 type CryptoEngineSL int
@@ -28,10 +31,26 @@ type CryptoEngineInfo struct {
 	SupportedKeyTypes []SupportedKeyTypeInfo `json:"supported_key_types"`
 }
 
+// IsSoftware reports whether the engine keeps the keys outside of a hardware security module.
+func (info CryptoEngineInfo) IsSoftware() bool {
+	return info.SecurityLevel < SL2
+}
+
 type CryptoEngineProvider struct {
 	CryptoEngineInfo
-	ID      string `json:"id"`
-	Default bool   `json:"default"`
+	ID      string              `json:"id"`
+	Default bool                `json:"default"`
+	Health  *CryptoEngineHealth `json:"health,omitempty"`
+}
+
+// CryptoEngineHealth is the result of the last health check of a crypto engine. Engines that could not be created
+// at startup are reported as not available until a health check reconnects them.
+type CryptoEngineHealth struct {
+	Available           bool       `json:"available"`
+	Message             string     `json:"message,omitempty"`
+	ConsecutiveFailures int        `json:"consecutive_failures"`
+	LastCheck           *time.Time `json:"last_check,omitempty"`
+	LastAvailable       *time.Time `json:"last_available,omitempty"`
 }
 
 type SupportedKeyTypeInfo struct {
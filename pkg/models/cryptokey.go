@@ -16,6 +16,14 @@ const (
 
 type KeyType x509.PublicKeyAlgorithm
 
+// Post-quantum key types. They are not supported by crypto/x509, so they can only be used as the alternative key of
+// hybrid CAs (see HybridKeyMetadata).
+const (
+	KeyTypeMLDSA44 KeyType = KeyType(100 + iota)
+	KeyTypeMLDSA65
+	KeyTypeMLDSA87
+)
+
 type KeyMetadata struct {
 	Type KeyType `json:"type"`
 	Bits int     `json:"bits"`
@@ -30,10 +38,24 @@ type KeyStrengthMetadata struct {
 //---------------------------------------
 
 func (kt KeyType) String() string {
+	switch kt {
+	case KeyTypeMLDSA44:
+		return "ML-DSA-44"
+	case KeyTypeMLDSA65:
+		return "ML-DSA-65"
+	case KeyTypeMLDSA87:
+		return "ML-DSA-87"
+	}
+
 	publicKeyAlg := x509.PublicKeyAlgorithm(kt)
 	return publicKeyAlg.String()
 }
 
+// IsPostQuantum reports whether the key type is one of the post-quantum key types.
+func (kt KeyType) IsPostQuantum() bool {
+	return kt == KeyTypeMLDSA44 || kt == KeyTypeMLDSA65 || kt == KeyTypeMLDSA87
+}
+
 func (kt KeyType) MarshalJSON() ([]byte, error) {
 	str := kt.String()
 	return json.Marshal(str)
@@ -59,6 +81,12 @@ func (kt *KeyType) UnmarshalJSON(data []byte) error {
 		nkt = KeyType(x509.ECDSA)
 	case "Ed25519":
 		nkt = KeyType(x509.Ed25519)
+	case "ML-DSA-44":
+		nkt = KeyTypeMLDSA44
+	case "ML-DSA-65":
+		nkt = KeyTypeMLDSA65
+	case "ML-DSA-87":
+		nkt = KeyTypeMLDSA87
 	default:
 		return fmt.Errorf("unknown key type")
 	}
@@ -66,3 +94,25 @@ func (kt *KeyType) UnmarshalJSON(data []byte) error {
 	*kt = nkt
 	return nil
 }
+
+type KeyStrengthIssue string
+
+const (
+	KeyStrengthIssueLowStrength                  KeyStrengthIssue = "LOW_KEY_STRENGTH"
+	KeyStrengthIssueDeprecatedSignatureAlgorithm KeyStrengthIssue = "DEPRECATED_SIGNATURE_ALGORITHM"
+)
+
+type KeyStrengthFinding struct {
+	CAID               string              `json:"ca_id,omitempty"`
+	SerialNumber       string              `json:"serial_number"`
+	Subject            Subject             `json:"subject"`
+	IssuerCAMetadata   IssuerCAMetadata    `json:"issuer_metadata"`
+	KeyMetadata        KeyStrengthMetadata `json:"key_metadata"`
+	SignatureAlgorithm string              `json:"signature_algorithm"`
+	Issues             []KeyStrengthIssue  `json:"issues"`
+}
+
+type KeyStrengthReport struct {
+	CAs          []KeyStrengthFinding `json:"cas"`
+	Certificates []KeyStrengthFinding `json:"certificates"`
+}
@@ -1,5 +1,7 @@
 package models
 
+import "time"
+
 type CryptoSecretType string
 
 const (
@@ -7,4 +9,48 @@ const (
 	X509SlotProfileType   CryptoSecretType = "x509"
 	SshKeySlotProfileType CryptoSecretType = "SSH_KEY"
 	OtherSlotProfileType  CryptoSecretType = "OTHER"
+	// PSKSlotProfileType slots hold symmetric pre-shared keys generated by the Device Manager.
+	PSKSlotProfileType CryptoSecretType = "PSK"
+	// SASTokenSlotProfileType slots hold Shared Access Signature tokens (i.e. Azure IoT Hub devices), signed with a
+	// symmetric key generated by the Device Manager.
+	SASTokenSlotProfileType CryptoSecretType = "SAS_TOKEN"
 )
+
+// IsSymmetricSlotType reports whether the secrets of the slot type are generated and rotated by the Device Manager.
+func IsSymmetricSlotType(secretType CryptoSecretType) bool {
+	return secretType == PSKSlotProfileType || secretType == SASTokenSlotProfileType
+}
+
+// SymmetricSlotProfile configures the generation and rotation of the PSK and SAS token slots.
+type SymmetricSlotProfile struct {
+	// KeyLength is the size in bytes of the generated keys. Defaults to 32.
+	KeyLength int `json:"key_length"`
+	// ResourceURI is the resource the SAS tokens grant access to (i.e. "myhub.azure-devices.net/devices/device-1").
+	// Required by SAS token slots.
+	ResourceURI string `json:"resource_uri,omitempty"`
+	// Validity of each secret version. Required by SAS token slots. PSKs never expire if empty.
+	Validity TimeDuration `json:"validity"`
+	// RotateBefore rotates the secret when it expires within the given duration. Secrets are rotated once expired if empty.
+	RotateBefore TimeDuration `json:"rotate_before"`
+}
+
+// SymmetricSecret is a version of a PSK or SAS token slot. Key is base64 encoded. Token is only set in SAS token
+// slots. Key and Token are removed when the device secrets are redacted.
+type SymmetricSecret struct {
+	Key       string    `json:"key,omitempty"`
+	Token     string    `json:"token,omitempty"`
+	CreatedAt time.Time `json:"created_at"`
+	ExpiresAt time.Time `json:"expires_at,omitempty"`
+}
+
+// EncryptedSlotSecret is the active secret of a device slot, encrypted to the public key of the device identity
+// certificate. JWE is a compact JWE whose payload is the JSON encoded SymmetricSecret.
+type EncryptedSlotSecret struct {
+	DeviceID     string           `json:"device_id"`
+	SlotID       string           `json:"slot_id"`
+	SecretType   CryptoSecretType `json:"type"`
+	Version      int              `json:"version"`
+	ExpiresAt    time.Time        `json:"expires_at,omitempty"`
+	SerialNumber string           `json:"serial_number"` // identity certificate the secret is encrypted to
+	JWE          string           `json:"jwe"`
+}
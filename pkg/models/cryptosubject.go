@@ -1,10 +1,31 @@
 package models
 
+// SubjectAttribute names a distinguished name attribute using its usual short name.
+type SubjectAttribute string
+
+const (
+	SubjectAttributeCommonName       SubjectAttribute = "CN"
+	SubjectAttributeOrganization     SubjectAttribute = "O"
+	SubjectAttributeOrganizationUnit SubjectAttribute = "OU"
+	SubjectAttributeCountry          SubjectAttribute = "C"
+	SubjectAttributeState            SubjectAttribute = "ST"
+	SubjectAttributeLocality         SubjectAttribute = "L"
+	SubjectAttributeSerialNumber     SubjectAttribute = "SERIALNUMBER"
+	SubjectAttributeEmailAddress     SubjectAttribute = "emailAddress"
+	SubjectAttributeDomainComponent  SubjectAttribute = "DC"
+)
+
 type Subject struct {
-	CommonName       string `json:"common_name"`
-	Organization     string `json:"organization"`
-	OrganizationUnit string `json:"organization_unit" `
-	Country          string `json:"country"`
-	State            string `json:"state"`
-	Locality         string `json:"locality"`
+	CommonName       string   `json:"common_name"`
+	Organization     string   `json:"organization"`
+	OrganizationUnit string   `json:"organization_unit" `
+	Country          string   `json:"country"`
+	State            string   `json:"state"`
+	Locality         string   `json:"locality"`
+	SerialNumber     string   `json:"serial_number,omitempty"`
+	EmailAddress     string   `json:"email_address,omitempty"`
+	DomainComponents []string `json:"domain_components,omitempty" gorm:"serializer:json"`
+	// Order sets the exact order of the RDNs in the encoded DN. Attributes not listed are appended afterwards
+	// in the default order. When empty, the Go default order (C, ST, L, O, OU, CN, SERIALNUMBER, ...) is used.
+	Order []SubjectAttribute `json:"order,omitempty" gorm:"serializer:json"`
 }
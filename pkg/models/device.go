@@ -26,18 +26,69 @@ const (
 	SlotRevoke        SlotStatus = "REVOKED"
 )
 
+// DeviceCertificateStatus selects the devices by the state of the certificate held in their identity slot.
+type DeviceCertificateStatus string
+
+const (
+	DeviceCertificateAboutToExpire DeviceCertificateStatus = "ABOUT_TO_EXPIRE"
+	DeviceCertificateRevoked       DeviceCertificateStatus = "REVOKED"
+	DeviceCertificateExpired       DeviceCertificateStatus = "EXPIRED"
+)
+
+// DeviceCertificateStatusSlots maps each DeviceCertificateStatus to the identity slot status it selects.
+var DeviceCertificateStatusSlots = map[DeviceCertificateStatus]SlotStatus{
+	DeviceCertificateAboutToExpire: SlotAboutToExpire,
+	DeviceCertificateRevoked:       SlotRevoke,
+	DeviceCertificateExpired:       SlotExpired,
+}
+
 type Device struct {
-	ID                string                    `json:"id" gorm:"primaryKey"`
-	Tags              []string                  `json:"tags" gorm:"serializer:json"`
-	Status            DeviceStatus              `json:"status"`
-	Icon              string                    `json:"icon"`
-	IconColor         string                    `json:"icon_color"`
-	CreationTimestamp time.Time                 `json:"creation_timestamp"`
-	Metadata          map[string]any            `json:"metadata" gorm:"serializer:json"`
-	DMSOwner          string                    `json:"dms_owner"`
-	IdentitySlot      *Slot[string]             `json:"identity,omitempty" gorm:"serializer:json"`
-	ExtraSlots        map[string]*Slot[any]     `json:"slots" gorm:"serializer:json"`
-	Events            map[time.Time]DeviceEvent `json:"events" gorm:"serializer:json"`
+	ID                 string                    `json:"id" gorm:"primaryKey"`
+	Tags               []string                  `json:"tags" gorm:"serializer:json"`
+	Status             DeviceStatus              `json:"status"`
+	Icon               string                    `json:"icon"`
+	IconColor          string                    `json:"icon_color"`
+	CreationTimestamp  time.Time                 `json:"creation_timestamp"`
+	Metadata           map[string]any            `json:"metadata" gorm:"serializer:json"`
+	DMSOwner           string                    `json:"dms_owner"`
+	IdentitySlot       *Slot[string]             `json:"identity,omitempty" gorm:"serializer:json"`
+	ExtraSlots         map[string]*Slot[any]     `json:"slots" gorm:"serializer:json"`
+	Events             map[time.Time]DeviceEvent `json:"events" gorm:"serializer:json"`
+	ConnectionMetadata DeviceConnectionMetadata  `json:"connection_metadata" gorm:"embedded;embeddedPrefix:connection_metadata_"`
+}
+
+// DeviceIdentitySlotID identifies the identity slot in a CertificateDeviceBinding. Other slots use their ExtraSlots key.
+const DeviceIdentitySlotID = "identity"
+
+// CertificateDeviceBinding locates a certificate within the slots of the device that owns it.
+type CertificateDeviceBinding struct {
+	SerialNumber string  `json:"serial_number"`
+	Device       *Device `json:"device"`
+	SlotID       string  `json:"slot_id"`
+	Version      int     `json:"version"`
+	Active       bool    `json:"active"` // the certificate is the active version of the slot
+}
+
+type DeviceConnectionStatus string
+
+const (
+	DeviceConnectionOnline  DeviceConnectionStatus = "ONLINE"
+	DeviceConnectionOffline DeviceConnectionStatus = "OFFLINE"
+)
+
+type DeviceConnectionMetadata struct {
+	Status    DeviceConnectionStatus `json:"status"`
+	LastSeen  time.Time              `json:"last_seen"`
+	IPAddress string                 `json:"ip_address"`
+	Broker    string                 `json:"broker"`
+	Source    string                 `json:"source"`
+}
+
+// DeviceConnectionReport is the payload sent by cloud connectors through the event bus
+// to report the connection state of a device.
+type DeviceConnectionReport struct {
+	DeviceID   string                   `json:"device_id"`
+	Connection DeviceConnectionMetadata `json:"connection"`
 }
 
 type Slot[E any] struct {
@@ -46,6 +97,18 @@ type Slot[E any] struct {
 	SecretType    CryptoSecretType          `json:"type"`
 	Secrets       map[int]E                 `json:"versions"` // version -> secret
 	Events        map[time.Time]DeviceEvent `json:"events" gorm:"serializer:json"`
+	// Redacted is set when the confidential payloads of Secrets have been removed from the response.
+	Redacted bool `json:"redacted,omitempty"`
+	// Profile configures the generation and rotation of the PSK and SAS token slots.
+	Profile *SymmetricSlotProfile `json:"profile,omitempty"`
+}
+
+// DeviceSecretsAccess is the audit record of a request retrieving the confidential slot payloads of a device.
+type DeviceSecretsAccess struct {
+	DeviceID   string    `json:"device_id"`
+	Slots      []string  `json:"slots"`
+	AccessedBy string    `json:"accessed_by"`
+	AccessedAt time.Time `json:"accessed_at"`
 }
 
 type DeviceEventType string
@@ -58,6 +121,7 @@ const (
 	DeviceEventTypeShadowUpdated        DeviceEventType = "SHADOW-UPDATED"
 	DeviceEventTypeStatusUpdated        DeviceEventType = "STATUS-UPDATED"
 	DeviceEventTypeStatusDecommissioned DeviceEventType = "DECOMMISSIONED"
+	DeviceEventTypeForceReenroll        DeviceEventType = "FORCE-REENROLL"
 )
 
 type DeviceEvent struct {
@@ -69,3 +133,19 @@ type DevicesStats struct {
 	TotalDevices  int                  `json:"total"`
 	DevicesStatus map[DeviceStatus]int `json:"status_distribution"`
 }
+
+// IssuanceActivity summarizes the certificates issued, renewed (reenrolled) and revoked by a CA or DMS.
+type IssuanceActivity struct {
+	ID      string `json:"id"`
+	Issued  int    `json:"issued"`
+	Renewed int    `json:"renewed"`
+	Revoked int    `json:"revoked"`
+}
+
+type IssuanceReport struct {
+	From        time.Time          `json:"from"`
+	To          time.Time          `json:"to"`
+	GeneratedAt time.Time          `json:"generated_at"`
+	CAs         []IssuanceActivity `json:"cas"`
+	DMSs        []IssuanceActivity `json:"dmss"`
+}
@@ -0,0 +1,70 @@
+package models
+
+import "time"
+
+// DeviceMetadataForceReenrollKey flags a device allowed to reenroll before the reenrollment window of its DMS opens.
+// The flag is removed once the device reenrolls.
+const DeviceMetadataForceReenrollKey = "lamassu.io/device/force-reenroll"
+
+// DeviceGroup groups devices to run bulk actions on them. A device belongs to the group if it is listed in DeviceIDs
+// or if it has any of the Tags, so tagged devices join (and leave) the group dynamically.
+//
+// Groups defined by Filters and/or CertificateStatus are dynamic: their members are the devices matching every filter
+// expression (i.e. "dms_owner[equal]plant-1" or "tags[contains]sensor") and holding a certificate in
+// CertificateStatus. Members are resolved by the storage engine each time the group is listed, and dynamic groups
+// have neither static members nor tags.
+type DeviceGroup struct {
+	ID                string                  `json:"id" gorm:"primaryKey"`
+	Name              string                  `json:"name"`
+	Description       string                  `json:"description"`
+	DeviceIDs         []string                `json:"device_ids" gorm:"serializer:json"`
+	Tags              []string                `json:"tags" gorm:"serializer:json"`
+	Filters           []string                `json:"filters" gorm:"serializer:json"`
+	CertificateStatus DeviceCertificateStatus `json:"certificate_status,omitempty"`
+	CreationTimestamp time.Time               `json:"creation_timestamp"`
+}
+
+type DeviceGroupBulkActionType string
+
+const (
+	DeviceGroupBulkActionForceReenroll  DeviceGroupBulkActionType = "FORCE_REENROLL"
+	DeviceGroupBulkActionRevoke         DeviceGroupBulkActionType = "REVOKE"
+	DeviceGroupBulkActionUpdateMetadata DeviceGroupBulkActionType = "UPDATE_METADATA"
+)
+
+type DeviceGroupBulkActionStatus string
+
+const (
+	// DeviceGroupBulkActionPendingApproval actions wait for the approval of a second administrator before running.
+	DeviceGroupBulkActionPendingApproval DeviceGroupBulkActionStatus = "PENDING_APPROVAL"
+	DeviceGroupBulkActionRunning         DeviceGroupBulkActionStatus = "RUNNING"
+	DeviceGroupBulkActionCompleted       DeviceGroupBulkActionStatus = "COMPLETED"
+)
+
+// DeviceGroupBulkAction is an action run asynchronously on the devices of a group. The devices are resolved when the
+// action starts running, and Succeeded and Failed are updated as each device is processed. REVOKE actions can require
+// the approval of a second administrator before ApprovalExpiresAt.
+type DeviceGroupBulkAction struct {
+	ID      string                    `json:"id" gorm:"primaryKey"`
+	GroupID string                    `json:"group_id"`
+	Type    DeviceGroupBulkActionType `json:"type"`
+	// Metadata is merged into the metadata of the devices by UPDATE_METADATA actions.
+	Metadata            map[string]any               `json:"metadata,omitempty" gorm:"serializer:json"`
+	Status              DeviceGroupBulkActionStatus  `json:"status"`
+	Total               int                          `json:"total"`
+	Succeeded           int                          `json:"succeeded"`
+	Failed              int                          `json:"failed"`
+	Errors              []DeviceGroupBulkActionError `json:"errors" gorm:"serializer:json"`
+	CreatedBy           string                       `json:"created_by"`
+	CreationTimestamp   time.Time                    `json:"creation_timestamp"`
+	ApprovalExpiresAt   *time.Time                   `json:"approval_expires_at,omitempty"`
+	ApprovedBy          string                       `json:"approved_by,omitempty"`
+	ApprovedAt          *time.Time                   `json:"approved_at,omitempty"`
+	CompletionTimestamp *time.Time                   `json:"completion_timestamp,omitempty"`
+}
+
+// DeviceGroupBulkActionError records why the action failed on a device.
+type DeviceGroupBulkActionError struct {
+	DeviceID string `json:"device_id"`
+	Error    string `json:"error"`
+}
@@ -0,0 +1,24 @@
+package models
+
+import "time"
+
+type DeviceLogSeverity string
+
+const (
+	DeviceLogSeverityDebug DeviceLogSeverity = "DEBUG"
+	DeviceLogSeverityInfo  DeviceLogSeverity = "INFO"
+	DeviceLogSeverityWarn  DeviceLogSeverity = "WARN"
+	DeviceLogSeverityError DeviceLogSeverity = "ERROR"
+)
+
+// DeviceLog is an entry of the log of a device. Logs are stored apart from the device, so they can be paginated
+// and pruned without rewriting the device.
+type DeviceLog struct {
+	ID        string            `json:"id" gorm:"primaryKey"`
+	DeviceID  string            `json:"device_id"`
+	Timestamp time.Time         `json:"timestamp"`
+	Severity  DeviceLogSeverity `json:"severity"`
+	Message   string            `json:"message"`
+	// Source identifies the component that reported the entry (i.e. the device itself or a cloud connector).
+	Source string `json:"source"`
+}
@@ -11,6 +11,8 @@ const (
 	ActiveDMSStatus     DMSStatus = "ACTIVE"
 	RevokedDMSStatus    DMSStatus = "REVOKED"
 	ExpiredDMSStatus    DMSStatus = "EXPIRED"
+	// PendingApprovalDMSStatus DMSs can't enroll devices until an administrator sets them ACTIVE.
+	PendingApprovalDMSStatus DMSStatus = "PENDING_APPROVAL"
 )
 
 type DMS struct {
@@ -19,20 +21,52 @@ type DMS struct {
 	Metadata     map[string]any `json:"metadata" gorm:"serializer:json"`
 	CreationDate time.Time      `json:"creation_ts"`
 	Settings     DMSSettings    `json:"settings" gorm:"serializer:json"`
+	// PublicKeyFingerprint is the hex encoded SHA-256 of the public key of the CSR the DMS was registered with (if any).
+	PublicKeyFingerprint string `json:"public_key_fingerprint,omitempty"`
+	// Status is empty for the DMSs registered without the approval workflow, which are treated as ACTIVE.
+	Status DMSStatus `json:"status,omitempty"`
+	// EnrollmentPolicyID is the enrollment policy that auto-approved the DMS registration (if any).
+	EnrollmentPolicyID string `json:"enrollment_policy_id,omitempty"`
+}
+
+// DMSEnrollmentPolicy auto-approves the DMS registrations matching all its rules. Empty rules match any registration.
+type DMSEnrollmentPolicy struct {
+	ID          string `json:"id" gorm:"primaryKey"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	// SubjectRegex is matched against the common name of the registration CSR subject.
+	SubjectRegex string `json:"subject_regex"`
+	// KeyTypes and MinKeyBits constrain the key of the registration CSR.
+	KeyTypes   []KeyType `json:"key_types" gorm:"serializer:json"`
+	MinKeyBits int       `json:"min_key_bits"`
+	// AllowedCAIDs constrains the enrollment CA and the validation CAs of the DMS.
+	AllowedCAIDs []string  `json:"allowed_ca_ids" gorm:"serializer:json"`
+	CreationDate time.Time `json:"creation_ts"`
 }
 
 type DMSSettings struct {
 	EnrollmentSettings     EnrollmentSettings     `json:"enrollment_settings"`
 	ReEnrollmentSettings   ReEnrollmentSettings   `json:"reenrollment_settings"`
 	CADistributionSettings CADistributionSettings `json:"ca_distribution_settings"`
+	SelfServiceSettings    SelfServiceSettings    `json:"self_service_settings"`
+}
+
+// SelfServiceSettings grants the DMS owners access to the self-service operations over the devices of the DMS,
+// without administrator rights over the PKI.
+type SelfServiceSettings struct {
+	// Owners are the caller identities (JWT subject or client certificate CommonName) of the DMS owners.
+	Owners []string `json:"owners"`
 }
 
 type EnrollmentProto string
 
 const (
-	EST EnrollmentProto = "EST_RFC7030"
+	EST  EnrollmentProto = "EST_RFC7030"
+	ACME EnrollmentProto = "ACME_RFC8555"
 )
 
+// DeviceProvisionProfile defines the defaults of the devices registered (JITP) while enrolling. The icon, icon color,
+// tags and metadata string values are Go templates rendered with the enrollment context (i.e. "{{.CSR.Subject.OU}}").
 type DeviceProvisionProfile struct {
 	Icon      string         `json:"icon"`
 	IconColor string         `json:"icon_color"`
@@ -54,6 +88,158 @@ type EnrollmentSettings struct {
 	EnrollmentCA                string                      `json:"enrollment_ca"`
 	EnableReplaceableEnrollment bool                        `json:"enable_replaceable_enrollment"` //switch-like option that enables enrolling, already enrolled devices
 	RegistrationMode            RegistrationMode            `json:"registration_mode"`
+	SigningProfile              SigningProfile              `json:"signing_profile"`        // overrides applied to the certificates issued to devices enrolled with this DMS
+	CertificateProfileID        string                      `json:"certificate_profile_id"` // certificate profile expected to be bound to the enrollment CA. Enrollments are rejected otherwise
+	DeviceIDRules               DeviceIDRules               `json:"device_id_rules"`
+	SecureElementVerification   SecureElementVerification   `json:"secure_element_verification"`
+	TPMAttestation              TPMAttestation              `json:"tpm_attestation"`
+	DeviceClassProfiles         []DeviceClassProfile        `json:"device_class_profiles"`
+	ContextPolicy               EnrollmentContextPolicy     `json:"context_policy"`
+}
+
+// DeviceClassProfile overrides the issuance of the certificates of the devices of a class (i.e. gateways or sensors).
+// Devices belong to the class if they are tagged with any of its tags. Classes are evaluated in order and only the
+// first matching class is applied, both while enrolling and reenrolling.
+type DeviceClassProfile struct {
+	Name string   `json:"name"`
+	Tags []string `json:"tags"`
+	// Validity replaces the validity of the DMS signing profile. It is capped by the issuance expiration of the
+	// enrollment CA.
+	Validity *TimeDuration `json:"validity,omitempty"`
+	// CertificateProfileID replaces the certificate profile of the DMS. It must be bound to the enrollment CA.
+	CertificateProfileID string `json:"certificate_profile_id,omitempty"`
+}
+
+// SecureElementVerification binds the device identity to its hardware. The CSR must carry an extension with the
+// serial number (as an ASN.1 string or octet string) or the certificate of the device secure element. The serial
+// number and the CSR public key are checked against the manufacturer allow-list service, so CSRs signed with
+// cloned keys or from unknown secure elements are rejected.
+type SecureElementVerification struct {
+	Enabled      bool   `json:"enabled"`
+	ExtensionOID string `json:"extension_oid"`
+	AllowListURL string `json:"allow_list_url"`
+}
+
+// SecureElementIdentity is sent (POST, JSON encoded) to the manufacturer allow-list service.
+type SecureElementIdentity struct {
+	DMSID           string `json:"dms_id"`
+	SerialNumber    string `json:"serial_number"`
+	PublicKeySHA256 string `json:"public_key_sha256"`     // hex encoded SHA-256 of the CSR SubjectPublicKeyInfo
+	Certificate     string `json:"certificate,omitempty"` // PEM encoded secure element certificate, if presented
+}
+
+// SecureElementAllowListResponse is returned by the manufacturer allow-list service. If the service knows the key
+// provisioned into the secure element at manufacturing time, it must match the CSR public key.
+type SecureElementAllowListResponse struct {
+	Allowed         bool   `json:"allowed"`
+	PublicKeySHA256 string `json:"public_key_sha256,omitempty"`
+}
+
+// TPMAttestation binds the enrolled key to a TPM 2.0. The CSR must carry a TPMAttestationEvidence extension with the
+// TPM endorsement key (EK) certificate, issued by one of the manufacturer CAs, and a quote signed by a restricted
+// attestation key (AK) of the TPM whose extra data is the SHA-256 of the CSR SubjectPublicKeyInfo.
+type TPMAttestation struct {
+	Enabled      bool   `json:"enabled"`
+	ExtensionOID string `json:"extension_oid"`
+	// ManufacturerCAs are the IDs of the (imported) CAs issuing the EK and AK certificates of the TPMs.
+	ManufacturerCAs []string `json:"manufacturer_cas"`
+	// RequireAKCertificate rejects evidences without an AK certificate. Otherwise the AK is trusted to belong to the
+	// TPM of the EK, as binding both keys requires a credential activation round trip.
+	RequireAKCertificate bool `json:"require_ak_certificate"`
+}
+
+// TPMAttestationEvidence is DER encoded in the CSR extension as a SEQUENCE of OCTET STRINGs. The TPM structures are
+// encoded in the TPM wire format, without the size prefix of their TPM2B wrappers.
+type TPMAttestationEvidence struct {
+	EKCertificate []byte // DER encoded EK certificate
+	AKPublic      []byte // TPMT_PUBLIC of the AK
+	Quote         []byte // TPMS_ATTEST returned by TPM2_Quote
+	Signature     []byte // TPMT_SIGNATURE of the quote
+	AKCertificate []byte `asn1:"optional,tag:0"` // DER encoded AK certificate
+}
+
+// HttpGatewayIDHeader identifies the gateway forwarding the enrollment requests of the devices. It must be set (and
+// overwritten if present) by a trusted gateway, as it is only used to evaluate the enrollment context policies.
+const HttpGatewayIDHeader = "x-lms-gateway-id"
+
+type EnrollmentContextAction string
+
+const (
+	EnrollmentContextAllow EnrollmentContextAction = "ALLOW"
+	EnrollmentContextDeny  EnrollmentContextAction = "DENY"
+	// EnrollmentContextFlag allows the enrollment but flags its audit record for review.
+	EnrollmentContextFlag EnrollmentContextAction = "FLAG"
+)
+
+// EnrollmentContextPolicy evaluates the context of the enrollment requests (source IP, time and gateway) of a DMS,
+// i.e. to restrict factory enrollment to specific plants and shifts. Rules are evaluated in order and the action of
+// the first matching rule applies. DefaultAction applies if no rule matches (ALLOW if empty). Each evaluation is
+// recorded as an enrollment audit record.
+type EnrollmentContextPolicy struct {
+	Enabled       bool                    `json:"enabled"`
+	Rules         []EnrollmentContextRule `json:"rules"`
+	DefaultAction EnrollmentContextAction `json:"default_action,omitempty"`
+}
+
+// EnrollmentContextRule matches the requests meeting all its conditions. Empty conditions match any request.
+type EnrollmentContextRule struct {
+	Name   string                  `json:"name"`
+	Action EnrollmentContextAction `json:"action"`
+	// SourceCIDRs match the requests sent from any of the IP ranges (i.e. "10.1.0.0/16").
+	SourceCIDRs []string `json:"source_cidrs,omitempty"`
+	// TimeWindows match the requests received within any of the windows.
+	TimeWindows []EnrollmentTimeWindow `json:"time_windows,omitempty"`
+	// GatewayIDs match the requests forwarded by any of the gateways (see HttpGatewayIDHeader).
+	GatewayIDs []string `json:"gateway_ids,omitempty"`
+}
+
+// EnrollmentTimeWindow spans from Start to End ("15:04" format) in the Location timezone (UTC if empty). Windows
+// ending before they start span midnight (i.e. a night shift from "22:00" to "06:00"). Days are the weekdays (i.e.
+// "Monday") the window starts on, any day if empty.
+type EnrollmentTimeWindow struct {
+	Days     []string `json:"days,omitempty"`
+	Start    string   `json:"start"`
+	End      string   `json:"end"`
+	Location string   `json:"location,omitempty"`
+}
+
+// EnrollmentContext is the context of an enrollment request evaluated by the enrollment context policy of the DMS.
+type EnrollmentContext struct {
+	SourceIP  string
+	GatewayID string
+	Time      time.Time
+}
+
+// DMSEnrollmentAudit records the evaluation of the enrollment context policy of a DMS for an enrollment request.
+type DMSEnrollmentAudit struct {
+	ID        string `json:"id" gorm:"primaryKey"`
+	DMSID     string `json:"dms_id" gorm:"column:dms_id"`
+	DeviceID  string `json:"device_id"`
+	SourceIP  string `json:"source_ip"`
+	GatewayID string `json:"gateway_id,omitempty"`
+	// Rule is the name of the matching rule, empty if the default action applied.
+	Rule      string                  `json:"rule,omitempty"`
+	Action    EnrollmentContextAction `json:"action"`
+	Timestamp time.Time               `json:"timestamp"`
+}
+
+type DeviceIDCase string
+
+const (
+	DeviceIDCaseUnchanged DeviceIDCase = ""
+	DeviceIDCaseLower     DeviceIDCase = "lower"
+	DeviceIDCaseUpper     DeviceIDCase = "upper"
+)
+
+// DeviceIDRules canonicalize the IDs of the devices registered with a DMS (taken from the CSR CommonName while
+// enrolling), before they are used as cloud thing names. The case is normalized and the forbidden characters are
+// replaced first (or rejected if no replacement is set), then the result is checked against the max length and pattern.
+type DeviceIDRules struct {
+	Case                 DeviceIDCase `json:"case,omitempty"`
+	ForbiddenCharacters  string       `json:"forbidden_characters,omitempty"`
+	ForbiddenReplacement string       `json:"forbidden_replacement,omitempty"`
+	MaxLength            int          `json:"max_length,omitempty"`
+	Pattern              string       `json:"pattern,omitempty"` // regular expression the whole ID must match
 }
 
 type EnrollmentOptionsESTRFC7030 struct {
@@ -64,14 +250,25 @@ type EnrollmentOptionsESTRFC7030 struct {
 type AuthOptionsClientCertificate struct {
 	ValidationCAs        []string `json:"validation_cas"`
 	ChainLevelValidation int      `json:"chain_level_validation"`
+	// ClockSkewTolerance accepts client certificates presented while enrolling or reenrolling up to this duration
+	// before their NotBefore or after their NotAfter.
+	ClockSkewTolerance TimeDuration `json:"clock_skew_tolerance"`
 }
 
 type ReEnrollmentSettings struct {
-	AdditionalValidationCAs     []string     `json:"additional_validation_cas"`
-	ReEnrollmentDelta           TimeDuration `json:"reenrollment_delta"`
-	EnableExpiredRenewal        bool         `json:"enable_expired_renewal"`
-	PreventiveReEnrollmentDelta TimeDuration `json:"preventive_delta"` // (expiration time - delta < time.now) at witch point an event is issued notify its time to reenroll
-	CriticalReEnrollmentDelta   TimeDuration `json:"critical_delta"`   // (expiration time - delta < time.now) at witch point an event is issued notify critical status
+	AdditionalValidationCAs     []string                     `json:"additional_validation_cas"`
+	ReEnrollmentDelta           TimeDuration                 `json:"reenrollment_delta"`
+	EnableExpiredRenewal        bool                         `json:"enable_expired_renewal"`
+	PreventiveReEnrollmentDelta TimeDuration                 `json:"preventive_delta"` // (expiration time - delta < time.now) at witch point an event is issued notify its time to reenroll
+	CriticalReEnrollmentDelta   TimeDuration                 `json:"critical_delta"`   // (expiration time - delta < time.now) at witch point an event is issued notify critical status
+	SupersededRevocation        SupersededRevocationSettings `json:"superseded_revocation"`
+}
+
+// SupersededRevocationSettings controls how the certificate replaced by a successful reenrollment is revoked.
+// If disabled, the superseded certificate is revoked as soon as the new certificate is issued.
+type SupersededRevocationSettings struct {
+	Enabled     bool         `json:"enabled"`
+	GracePeriod TimeDuration `json:"grace_period"` // time the superseded certificate remains valid after the reenrollment
 }
 
 type CADistributionSettings struct {
@@ -84,8 +281,134 @@ type DMSStats struct {
 	TotalDMSs int `json:"total"`
 }
 
+type EnrollmentOperation string
+
+const (
+	EnrollmentOperationEnroll   EnrollmentOperation = "enroll"
+	EnrollmentOperationReenroll EnrollmentOperation = "reenroll"
+)
+
+type EnrollmentFailureReason string
+
+const (
+	EnrollmentFailureAuth   EnrollmentFailureReason = "auth"   // the client is not authenticated or not authorized by the DMS
+	EnrollmentFailurePolicy EnrollmentFailureReason = "policy" // the request is rejected by the enrollment settings of the DMS
+	EnrollmentFailureCA     EnrollmentFailureReason = "ca"     // the CA service could not issue the certificate
+	EnrollmentFailureOther  EnrollmentFailureReason = "other"
+)
+
+type EnrollmentCounters struct {
+	Succeeded int                             `json:"succeeded"`
+	Failed    map[EnrollmentFailureReason]int `json:"failed"`
+}
+
+// DMSEnrollmentStats counts the EST enrollments and reenrollments served by a DMS, with the failures broken down by
+// reason.
+type DMSEnrollmentStats struct {
+	DMSID         string             `json:"dms_id" gorm:"primaryKey;column:dms_id"`
+	Enroll        EnrollmentCounters `json:"enroll" gorm:"serializer:json"`
+	Reenroll      EnrollmentCounters `json:"reenroll" gorm:"serializer:json"`
+	LastSuccessAt *time.Time         `json:"last_success_at,omitempty"`
+	LastFailureAt *time.Time         `json:"last_failure_at,omitempty"`
+}
+
+// DevicePreregistration reports the devices claimed for a DMS. Failed maps each rejected entry to the reason.
+type DevicePreregistration struct {
+	Registered        []string          `json:"registered"`
+	AlreadyRegistered []string          `json:"already_registered"`
+	Failed            map[string]string `json:"failed"`
+}
+
 type BindIdentityToDeviceOutput struct {
 	Certificate *Certificate `json:"certificate"`
 	DMS         *DMS         `json:"dms"`
 	Device      *Device      `json:"device"`
 }
+
+const (
+	DMSMetadataSupersededRevocationKey = "lamassu.io/ra/superseded-revocation"
+	DMSMetadataACMEEABKeysKey          = "lamassu.io/ra/acme-eab-keys"
+)
+
+type DMSMetadataSupersededRevocation struct {
+	DMSID        string    `json:"dms_id"`
+	SupersededBy string    `json:"superseded_by"`
+	RevokeAfter  time.Time `json:"revoke_after"`
+}
+
+// DMSACMEEABKey is an ACME External Account Binding key issued by a DMS. ACME accounts registered with it
+// are bound to the DMS, so the certificates issued through them are attributed to (and revocable per) DMS.
+// The HMAC key is never stored: it is derived from the DMS Manager EAB secret, the DMS ID and the key ID.
+type DMSACMEEABKey struct {
+	KeyID     string     `json:"key_id"`
+	DMSID     string     `json:"dms_id"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// DMSBootstrapToken is a single-use credential enrolling a device with a DMS configured with the BOOTSTRAP_TOKEN EST
+// auth mode, so devices with no factory certificate can be enrolled on first boot. The token is presented as
+// "Authorization: Bearer <token>" and is never stored: the ID of the bootstrap token is its hex encoded SHA-256.
+type DMSBootstrapToken struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	DMSID string `json:"dms_id"`
+	// DeviceID restricts the token to the enrollment of a single device (if set).
+	DeviceID  string     `json:"device_id,omitempty"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	UsedAt    *time.Time `json:"used_at,omitempty"`
+	UsedBy    string     `json:"used_by,omitempty"`
+}
+
+// DMSBootstrapTokenCredentials are only returned when the bootstrap token is created.
+type DMSBootstrapTokenCredentials struct {
+	DMSBootstrapToken
+	Token string `json:"token"`
+}
+
+// DMSClaimCode is a one-time code enrolling a device with a DMS through the public claim endpoint, so consumer
+// devices with no factory certificate can be onboarded by an installer. As with bootstrap tokens, the code is never
+// stored: the ID of the claim code is the hex encoded SHA-256 of its normalized form.
+type DMSClaimCode struct {
+	ID    string `json:"id" gorm:"primaryKey"`
+	DMSID string `json:"dms_id"`
+	// DeviceID restricts the code to the enrollment of a single device (if set).
+	DeviceID  string     `json:"device_id,omitempty"`
+	CreatedBy string     `json:"created_by"`
+	CreatedAt time.Time  `json:"created_at"`
+	ExpiresAt time.Time  `json:"expires_at"`
+	ClaimedAt *time.Time `json:"claimed_at,omitempty"`
+	ClaimedBy string     `json:"claimed_by,omitempty"`
+	// InstallerMetadata is the metadata presented by the installer claiming the device.
+	InstallerMetadata map[string]any `json:"installer_metadata,omitempty" gorm:"serializer:json"`
+}
+
+// DMSClaimCodeCredentials are only returned when the claim code is created.
+type DMSClaimCodeCredentials struct {
+	DMSClaimCode
+	Code string `json:"code"`
+}
+
+// DeviceClaim is the outcome of a device claimed with a claim code.
+type DeviceClaim struct {
+	DeviceID    string           `json:"device_id"`
+	DMSID       string           `json:"dms_id"`
+	Certificate *X509Certificate `json:"certificate"`
+}
+
+// DeviceMetadataClaimKey holds the DeviceClaimMetadata of the devices enrolled with a claim code.
+const DeviceMetadataClaimKey = "lamassu.io/device/claim"
+
+type DeviceClaimMetadata struct {
+	ClaimCodeID string         `json:"claim_code_id"`
+	ClaimedAt   time.Time      `json:"claimed_at"`
+	Installer   map[string]any `json:"installer,omitempty"`
+}
+
+// DMSACMEEABCredentials are only returned when the EAB key is created. HMACKey is base64url encoded, as expected by ACME clients.
+type DMSACMEEABCredentials struct {
+	DMSACMEEABKey
+	HMACKey string `json:"hmac_key"`
+}
@@ -1,6 +1,9 @@
 package models
 
-import "fmt"
+import (
+	"fmt"
+	"time"
+)
 
 const HttpSourceHeader = "x-lms-source"
 const HttpRequestIDHeader = "x-request-id"
@@ -10,6 +13,7 @@ const DMSManagerSource = "lrn://service/lamassuiot-ra"
 const DeviceManagerSource = "lrn://service/lamassuiot-devmanager"
 const VASource = "lrn://service/lamassuiot-va"
 const AlertsSource = "lrn://service/lamassuiot-alerts"
+const KubernetesOperatorSource = "lrn://service/lamassuiot-k8s-operator"
 
 func AWSIoTSource(id string) string { return fmt.Sprintf("lrn://service/lamassuiot-awsiot/%s", id) }
 
@@ -18,33 +22,106 @@ type UpdateModel[E any] struct {
 	Updated  E `json:"updated"`
 }
 
+// SignedCertificateEvent is the payload of the sign certificate event. The certificate fields
+// are kept at the top level so consumers decoding a plain Certificate keep working.
+type SignedCertificateEvent struct {
+	Certificate
+	IssuanceContext CertificateIssuanceContext `json:"issuance_context"`
+}
+
 type EventType string
 
 const (
-	EventCreateCAKey            EventType = "ca.create"
-	EventImportCAKey            EventType = "ca.import"
-	EventImportCACertificateKey EventType = "ca.certificate.import"
-	EventUpdateCAStatusKey      EventType = "ca.status.update"
-	EventUpdateCAMetadataKey    EventType = "ca.metadata.update"
-	EventSignCertificateKey     EventType = "ca.sign.certificate"
-	EventSignatureSignKey       EventType = "ca.sign.signature"
-	EventDeleteCAKey            EventType = "ca.delete"
+	EventCreateCAKey                EventType = "ca.create"
+	EventImportCAKey                EventType = "ca.import"
+	EventImportCACertificateKey     EventType = "ca.certificate.import"
+	EventUpdateCAStatusKey          EventType = "ca.status.update"
+	EventUpdateCAMetadataKey        EventType = "ca.metadata.update"
+	EventUpdateCASettingsKey        EventType = "ca.settings.update"
+	EventSignCertificateKey         EventType = "ca.sign.certificate"
+	EventSignatureSignKey           EventType = "ca.sign.signature"
+	EventSignTokenKey               EventType = "ca.sign.token"
+	EventDeleteCAKey                EventType = "ca.delete"
+	EventMigrateCAKeyKey            EventType = "ca.key.migrate"
+	EventRestoreCAKeyKey            EventType = "ca.key.restore"
+	EventUpdateCAFallbackEngineKey  EventType = "ca.key.fallback.update"
+	EventUpdateCAIssuanceWebhookKey EventType = "ca.issuance-webhook.update"
+	EventRequestCAActionKey         EventType = "ca.action.request"
+	EventApproveCAActionKey         EventType = "ca.action.approve"
+	EventKeyCustodyWarningKey       EventType = "ca.key-custody.warning"
+	EventCreateCASuccessorKey       EventType = "ca.successor.create"
+
+	EventCreateCASigningRequestKey  EventType = "ca.signing-request.create"
+	EventApproveCASigningRequestKey EventType = "ca.signing-request.approve"
+	EventRejectCASigningRequestKey  EventType = "ca.signing-request.reject"
 
 	EventCreateCertificateKey         EventType = "certificate.create"
 	EventImportCertificateKey         EventType = "certificate.import"
 	EventUpdateCertificateStatusKey   EventType = "certificate.status.update"
 	EventUpdateCertificateMetadataKey EventType = "certificate.metadata.update"
+	EventKeyStrengthWarningKey        EventType = "certificate.key-strength.warning"
+
+	EventCreateCertificateProfileKey EventType = "certificate-profile.create"
+	EventUpdateCertificateProfileKey EventType = "certificate-profile.update"
+	EventDeleteCertificateProfileKey EventType = "certificate-profile.delete"
 
 	EventCreateDMSKey          EventType = "dms.create"
 	EventUpdateDMSKey          EventType = "dms.update"
 	EventEnrollKey             EventType = "dms.enroll"
 	EventReEnrollKey           EventType = "dms.reenroll"
 	EventBindDeviceIdentityKey EventType = "dms.bind-device-id"
+	EventRevokeSupersededKey   EventType = "dms.superseded.revoke"
+	EventRevokeDeviceCertKey   EventType = "dms.device-certificate.revoke"
+	EventStartCARotationKey    EventType = "dms.ca-rotation.start"
+	EventCARotationBatchKey    EventType = "dms.ca-rotation.batch"
+	EventCreateACMEEABKey      EventType = "dms.acme-eab.create"
+	EventRevokeACMEEABKey      EventType = "dms.acme-eab.revoke"
+
+	EventCreateDMSEnrollmentPolicyKey EventType = "dms.enrollment-policy.create"
+	EventUpdateDMSEnrollmentPolicyKey EventType = "dms.enrollment-policy.update"
+	EventDeleteDMSEnrollmentPolicyKey EventType = "dms.enrollment-policy.delete"
+
+	EventCreateDMSBootstrapTokenKey EventType = "dms.bootstrap-token.create"
+	EventRevokeDMSBootstrapTokenKey EventType = "dms.bootstrap-token.revoke"
+	EventCreateDMSClaimCodeKey      EventType = "dms.claim-code.create"
+	EventRevokeDMSClaimCodeKey      EventType = "dms.claim-code.revoke"
+	EventClaimDeviceKey             EventType = "dms.claim-code.claim"
 
-	EventCreateDeviceKey         EventType = "device.create"
-	EventUpdateDeviceIDSlotKey   EventType = "device.identity.update"
-	EventUpdateDeviceStatusKey   EventType = "device.status.update"
-	EventUpdateDeviceMetadataKey EventType = "device.metadata.update"
+	EventCreateDeviceKey           EventType = "device.create"
+	EventUpdateDeviceIDSlotKey     EventType = "device.identity.update"
+	EventUpdateDeviceStatusKey     EventType = "device.status.update"
+	EventUpdateDeviceMetadataKey   EventType = "device.metadata.update"
+	EventUpdateDeviceConnectionKey EventType = "device.connection.update"
+	EventReportDeviceConnectionKey EventType = "device.connection.report"
+	EventIssuanceReportKey         EventType = "device.issuance.report"
+	EventReadDeviceSecretsKey      EventType = "device.secrets.read"
+	EventUpdateDeviceSlotKey       EventType = "device.slot.update"
+	EventForceReenrollDeviceKey    EventType = "device.force-reenroll"
+
+	EventCreateDeviceGroupKey            EventType = "device.group.create"
+	EventUpdateDeviceGroupKey            EventType = "device.group.update"
+	EventDeleteDeviceGroupKey            EventType = "device.group.delete"
+	EventStartDeviceGroupBulkActionKey   EventType = "device.group.bulk-action.start"
+	EventApproveDeviceGroupBulkActionKey EventType = "device.group.bulk-action.approve"
+
+	EventServiceStatusKey EventType = "service.status"
+
+	EventExpirationDigestKey EventType = "alerts.expiration.digest"
+
+	EventConnectorRegisterKey EventType = "connector.register"
+	EventConnectorHealthKey   EventType = "connector.health"
+
+	EventCertificateStatusDriftKey    EventType = "connector.certificate-status.drift"
+	EventCertificateReconciliationKey EventType = "connector.certificate-status.reconciliation"
 
 	EventAnyKey EventType = "any"
 )
+
+// ProcessedEvent records an event handled by an event bus consumer, shared by every replica of the consumer. Key
+// identifies the consumer and the event. The record is ignored once expired.
+type ProcessedEvent struct {
+	Key string `json:"key" gorm:"primaryKey"`
+	// Processed is false while the event is being handled.
+	Processed bool      `json:"processed"`
+	ExpiresAt time.Time `json:"expires_at"`
+}
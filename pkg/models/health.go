@@ -0,0 +1,42 @@
+package models
+
+import "time"
+
+type HealthStatus string
+
+const (
+	HealthStatusHealthy   HealthStatus = "HEALTHY"
+	HealthStatusDegraded  HealthStatus = "DEGRADED"
+	HealthStatusUnhealthy HealthStatus = "UNHEALTHY"
+)
+
+type DependencyKind string
+
+const (
+	DependencyKindStorage      DependencyKind = "STORAGE"
+	DependencyKindEventBus     DependencyKind = "EVENT_BUS"
+	DependencyKindCryptoEngine DependencyKind = "CRYPTO_ENGINE"
+	DependencyKindConnector    DependencyKind = "CONNECTOR"
+	DependencyKindService      DependencyKind = "SERVICE"
+)
+
+// DependencyHealth is the result of the last check of a service dependency. Critical dependencies are required
+// by the service to work, so the service is unhealthy while any of them is not healthy.
+type DependencyHealth struct {
+	Name      string         `json:"name"`
+	Kind      DependencyKind `json:"kind"`
+	Critical  bool           `json:"critical"`
+	Status    HealthStatus   `json:"status"`
+	Message   string         `json:"message,omitempty"`
+	LatencyMs int64          `json:"latency_ms"`
+	CheckedAt time.Time      `json:"checked_at"`
+}
+
+// ServiceStatus aggregates the health of the dependencies of a service. It is served by the service status API
+// and published periodically, so it can drive a status page.
+type ServiceStatus struct {
+	Service      string             `json:"service"`
+	Status       HealthStatus       `json:"status"`
+	Dependencies []DependencyHealth `json:"dependencies"`
+	Timestamp    time.Time          `json:"timestamp"`
+}
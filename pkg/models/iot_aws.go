@@ -1,7 +1,6 @@
 package models
 
 import (
-	"fmt"
 	"time"
 )
 
@@ -37,7 +36,7 @@ type LamassuConfiguration struct {
 }
 
 func AWSIoTMetadataKey(connectorID string) string {
-	return fmt.Sprintf("lamassu.io/iot/%s", connectorID)
+	return CloudConnectorMetadataKey(connectorID)
 }
 
 type IoTAWSCAMetadata struct {
@@ -83,5 +82,6 @@ type AWSIoTPolicy struct {
 
 type DeviceAWSMetadata struct {
 	Registered bool                    `json:"thing_registered"`
+	ThingARN   string                  `json:"thing_arn,omitempty"`
 	Actions    []RemediationActionType `json:"actions"`
 }
@@ -0,0 +1,43 @@
+package models
+
+const GCPPubSubProvider = "gcp-pubsub"
+
+// IoTGCPCAMetadata is the configuration of a CA synchronized by a GCP Pub/Sub connector, stored under the
+// CloudConnectorMetadataKey of the connector.
+type IoTGCPCAMetadata struct {
+	// Registry groups the CA and the device certificates it issues in the Pub/Sub subscribers, the same way
+	// device registries did in Cloud IoT Core. Defaults to the CA ID.
+	Registry string `json:"registry"`
+}
+
+type GCPPubSubMessageType string
+
+const (
+	GCPPubSubCARegistered                   GCPPubSubMessageType = "ca.registered"
+	GCPPubSubCAStatusUpdated                GCPPubSubMessageType = "ca.status.updated"
+	GCPPubSubDeviceCertificateBound         GCPPubSubMessageType = "device.certificate.bound"
+	GCPPubSubDeviceCertificateStatusUpdated GCPPubSubMessageType = "device.certificate.status.updated"
+)
+
+// GCPPubSubCAMessage is the payload of the CA messages published into the CA topic.
+type GCPPubSubCAMessage struct {
+	Registry       string            `json:"registry"`
+	CAID           string            `json:"ca_id"`
+	SerialNumber   string            `json:"serial_number"`
+	Certificate    string            `json:"certificate"`
+	Status         CertificateStatus `json:"status"`
+	PreviousStatus CertificateStatus `json:"previous_status,omitempty"`
+}
+
+// GCPPubSubDeviceCertificateMessage is the payload of the device certificate messages published into the
+// device topic. DeviceID and DMSID are only known when the certificate is bound to the device.
+type GCPPubSubDeviceCertificateMessage struct {
+	Registry       string            `json:"registry"`
+	DeviceID       string            `json:"device_id,omitempty"`
+	DMSID          string            `json:"dms_id,omitempty"`
+	IssuerCAID     string            `json:"issuer_ca_id"`
+	SerialNumber   string            `json:"serial_number"`
+	Certificate    string            `json:"certificate,omitempty"`
+	Status         CertificateStatus `json:"status"`
+	PreviousStatus CertificateStatus `json:"previous_status,omitempty"`
+}
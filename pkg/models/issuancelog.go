@@ -0,0 +1,40 @@
+package models
+
+import "time"
+
+// IssuanceLogEntry is a leaf of the append-only issuance log of a CA. Each certificate signed by the CA is appended
+// to the log, so auditors can detect certificates issued outside of it. Hashes are hex encoded.
+type IssuanceLogEntry struct {
+	ID           string `json:"id" gorm:"primaryKey"`
+	CAID         string `json:"ca_id" gorm:"column:ca_id"`
+	Index        int    `json:"index" gorm:"column:log_index"`
+	SerialNumber string `json:"serial_number"`
+	// LeafHash is the RFC 6962 Merkle leaf hash of the DER encoded certificate.
+	LeafHash string `json:"leaf_hash"`
+	// ChainHash links the entry with the previous one: SHA-256(previous ChainHash || LeafHash). The previous chain
+	// hash of the first entry is empty.
+	ChainHash string    `json:"chain_hash"`
+	LoggedAt  time.Time `json:"logged_at"`
+}
+
+// IssuanceLogTreeHead is the Merkle tree head of the issuance log of a CA, signed with the CA key. Signature is base64
+// encoded and computed over the message returned by helpers.IssuanceLogTreeHeadMessage.
+type IssuanceLogTreeHead struct {
+	CAID             string    `json:"ca_id"`
+	TreeSize         int       `json:"tree_size"`
+	RootHash         string    `json:"root_hash"`
+	ChainHash        string    `json:"chain_hash"`
+	Timestamp        time.Time `json:"timestamp"`
+	SigningAlgorithm string    `json:"signing_algorithm"`
+	Signature        string    `json:"signature"`
+}
+
+// IssuanceLogInclusionProof proves that the certificate is the leaf LeafIndex of the tree of size TreeSize. AuditPath
+// holds the hex encoded sibling hashes (RFC 6962 section 2.1.1), from the leaf up to the root of the signed TreeHead.
+type IssuanceLogInclusionProof struct {
+	SerialNumber string              `json:"serial_number"`
+	LeafIndex    int                 `json:"leaf_index"`
+	LeafHash     string              `json:"leaf_hash"`
+	AuditPath    []string            `json:"audit_path"`
+	TreeHead     IssuanceLogTreeHead `json:"tree_head"`
+}
@@ -0,0 +1,65 @@
+package models
+
+// JWK is the JSON Web Key (RFC 7517) representation of a public signing key.
+type JWK struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+	N   string `json:"n,omitempty"`
+	E   string `json:"e,omitempty"`
+
+	X5C     []string `json:"x5c,omitempty"`
+	X5TS256 string   `json:"x5t#S256,omitempty"`
+
+	// NotBefore and ExpiresAt (unix time) bound the validity of the key, so consumers can anticipate its rotation.
+	NotBefore int64      `json:"nbf,omitempty"`
+	ExpiresAt int64      `json:"exp,omitempty"`
+	Usages    []JWKUsage `json:"lamassu_usages,omitempty"`
+}
+
+type JWKUsage string
+
+const (
+	JWKUsageTokenSigning JWKUsage = "token-signing"
+	JWKUsageOCSP         JWKUsage = "ocsp"
+	// JWKUsageResponseSigning keys sign the CRL and CA certificate distribution responses with a detached JWS.
+	JWKUsageResponseSigning JWKUsage = "response-signing"
+)
+
+type JWKS struct {
+	Keys []JWK `json:"keys"`
+}
+
+type SignedToken struct {
+	Token     string `json:"token"`
+	KeyID     string `json:"kid"`
+	Algorithm string `json:"alg"`
+}
+
+// JWSSignatureHeader is the HTTP header carrying the detached JWS of signed responses.
+const JWSSignatureHeader = "X-JWS-Signature"
+
+// SignedContent is a response body together with its detached JWS (RFC 7515 Appendix F), so the content can be
+// verified even if it is fetched from an untrusted mirror. The signing CA ID is used as key ID.
+type SignedContent struct {
+	Content   []byte
+	Signature string
+	KeyID     string
+}
+
+// InternalCertificate is a short-lived client certificate authenticating the service-to-service calls, along with
+// the internal CA certificate it was issued by.
+type InternalCertificate struct {
+	Certificate   *X509Certificate `json:"certificate"`
+	CACertificate *X509Certificate `json:"ca_certificate"`
+}
+
+type TokenSignEvent struct {
+	KeyID     string         `json:"kid"`
+	Algorithm string         `json:"alg"`
+	Claims    map[string]any `json:"claims"`
+}
@@ -0,0 +1,71 @@
+package models
+
+// Kubernetes custom resources reconciled against the Lamassu APIs by the Kubernetes operator. Spec fields use the
+// same JSON as the Lamassu API bodies.
+const (
+	KubernetesAPIGroup   = "lamassu.io"
+	KubernetesAPIVersion = "v1alpha1"
+
+	KubernetesCertificateAuthorityPlural = "certificateauthorities"
+	KubernetesDMSPlural                  = "dmss"
+)
+
+type KubernetesObjectMeta struct {
+	Name            string `json:"name"`
+	Namespace       string `json:"namespace,omitempty"`
+	ResourceVersion string `json:"resourceVersion,omitempty"`
+	Generation      int64  `json:"generation,omitempty"`
+}
+
+type KubernetesResourcePhase string
+
+const (
+	KubernetesResourceReady           KubernetesResourcePhase = "Ready"
+	KubernetesResourcePendingApproval KubernetesResourcePhase = "PendingApproval"
+	KubernetesResourceRevoked         KubernetesResourcePhase = "Revoked"
+	KubernetesResourceFailed          KubernetesResourcePhase = "Failed"
+)
+
+type KubernetesResourceStatus struct {
+	// ID of the reconciled Lamassu CA or DMS.
+	ID                 string                  `json:"id,omitempty"`
+	Phase              KubernetesResourcePhase `json:"phase,omitempty"`
+	Message            string                  `json:"message,omitempty"`
+	ObservedGeneration int64                   `json:"observedGeneration,omitempty"`
+}
+
+type CertificateAuthorityResource struct {
+	Metadata KubernetesObjectMeta     `json:"metadata"`
+	Spec     CertificateAuthoritySpec `json:"spec"`
+	Status   KubernetesResourceStatus `json:"status"`
+}
+
+type CertificateAuthoritySpec struct {
+	// ID of the CA. Defaults to the resource name.
+	ID                 string         `json:"id,omitempty"`
+	ParentID           string         `json:"parent_id,omitempty"`
+	EngineID           string         `json:"engine_id,omitempty"`
+	Subject            Subject        `json:"subject"`
+	KeyMetadata        KeyMetadata    `json:"key_metadata"`
+	CAExpiration       Expiration     `json:"ca_expiration"`
+	IssuanceExpiration Expiration     `json:"issuance_expiration"`
+	Metadata           map[string]any `json:"metadata,omitempty"`
+	// Revoked revokes the CA. Revoked CAs can not be reactivated.
+	Revoked bool `json:"revoked,omitempty"`
+}
+
+type DMSResource struct {
+	Metadata KubernetesObjectMeta     `json:"metadata"`
+	Spec     DMSSpec                  `json:"spec"`
+	Status   KubernetesResourceStatus `json:"status"`
+}
+
+type DMSSpec struct {
+	// ID of the DMS. Defaults to the resource name.
+	ID       string         `json:"id,omitempty"`
+	Name     string         `json:"name"`
+	Metadata map[string]any `json:"metadata,omitempty"`
+	Settings DMSSettings    `json:"settings"`
+	// Approved sets the DMS status to ACTIVE (true) or PENDING_APPROVAL (false). The status is left untouched if nil.
+	Approved *bool `json:"approved,omitempty"`
+}
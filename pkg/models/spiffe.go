@@ -0,0 +1,15 @@
+package models
+
+// CAMetadataSPIFFEKey enables (with a CASPIFFEProfile value) the CA to issue SPIFFE X509-SVIDs to the workloads of a
+// trust domain, so the same CA hierarchy backs workload identity in addition to device identity.
+const (
+	CAMetadataSPIFFEKey = "lamassu.io/ca/spiffe"
+)
+
+// CASPIFFEProfile is the issuance profile of the X509-SVIDs signed by a CA. SVIDs carry the SPIFFE ID as their only
+// URI SAN, have no subject requirements and are meant to be short lived.
+type CASPIFFEProfile struct {
+	TrustDomain string `json:"trust_domain"`
+	// Validity of the SVIDs, one hour if empty. Sign requests can only ask for shorter validities.
+	Validity *TimeDuration `json:"validity,omitempty"`
+}
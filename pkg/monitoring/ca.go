@@ -0,0 +1,32 @@
+package monitoring
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+type caMetricsMiddleware struct {
+	services.CAService
+}
+
+// NewCAMetricsMiddleware counts the certificate issuance requests (and their failures) per CA.
+func NewCAMetricsMiddleware() services.CAMiddleware {
+	return func(next services.CAService) services.CAService {
+		return &caMetricsMiddleware{
+			CAService: next,
+		}
+	}
+}
+
+func (mw *caMetricsMiddleware) SignCertificate(ctx context.Context, input services.SignCertificateInput) (*models.Certificate, error) {
+	output, err := mw.CAService.SignCertificate(ctx, input)
+
+	CertificateIssuances.Inc(input.CAID)
+	if err != nil {
+		CertificateIssuanceErrors.Inc(input.CAID)
+	}
+
+	return output, err
+}
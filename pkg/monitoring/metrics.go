@@ -0,0 +1,193 @@
+package monitoring
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+var (
+	CertificateIssuances      = NewCounterVec("lamassu_ca_certificate_issuance_total", "Number of certificate issuance requests handled by the CA service.", "ca_id")
+	CertificateIssuanceErrors = NewCounterVec("lamassu_ca_certificate_issuance_errors_total", "Number of certificate issuance requests that failed.", "ca_id")
+	EventBusPublishFailures   = NewCounterVec("lamassu_eventbus_publish_failures_total", "Number of events that could not be published to the event bus.", "service", "event_type")
+)
+
+var labelValueEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+// Sample is a single value of a metric, identified by its label values.
+type Sample struct {
+	Labels map[string]string
+	Value  float64
+}
+
+// CounterVec is a minimal counter partitioned by label values, exposed using the Prometheus text format.
+type CounterVec struct {
+	name   string
+	help   string
+	labels []string
+
+	mu      sync.Mutex
+	samples map[string]*Sample
+}
+
+func NewCounterVec(name, help string, labels ...string) *CounterVec {
+	return &CounterVec{
+		name:    name,
+		help:    help,
+		labels:  labels,
+		samples: map[string]*Sample{},
+	}
+}
+
+// Inc increments the counter identified by the label values, given in the same order as the counter labels.
+func (c *CounterVec) Inc(labelValues ...string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	key := strings.Join(labelValues, "\xff")
+	sample, ok := c.samples[key]
+	if !ok {
+		labels := map[string]string{}
+		for idx, label := range c.labels {
+			if idx < len(labelValues) {
+				labels[label] = labelValues[idx]
+			}
+		}
+
+		sample = &Sample{Labels: labels}
+		c.samples[key] = sample
+	}
+
+	sample.Value++
+}
+
+// Value returns the current value of the counter identified by the label values.
+func (c *CounterVec) Value(labelValues ...string) float64 {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if sample, ok := c.samples[strings.Join(labelValues, "\xff")]; ok {
+		return sample.Value
+	}
+
+	return 0
+}
+
+func (c *CounterVec) Write(w io.Writer) error {
+	c.mu.Lock()
+	samples := []Sample{}
+	for _, sample := range c.samples {
+		samples = append(samples, *sample)
+	}
+	c.mu.Unlock()
+
+	return WriteMetric(w, c.name, c.help, "counter", samples)
+}
+
+// WriteMetric writes the samples of a metric using the Prometheus text exposition format.
+func WriteMetric(w io.Writer, name, help, metricType string, samples []Sample) error {
+	lines := []string{}
+	for _, sample := range samples {
+		labelNames := []string{}
+		for label := range sample.Labels {
+			labelNames = append(labelNames, label)
+		}
+		sort.Strings(labelNames)
+
+		labels := []string{}
+		for _, label := range labelNames {
+			labels = append(labels, fmt.Sprintf(`%s="%s"`, label, labelValueEscaper.Replace(sample.Labels[label])))
+		}
+
+		lines = append(lines, fmt.Sprintf("%s{%s} %v", name, strings.Join(labels, ","), sample.Value))
+	}
+	sort.Strings(lines)
+
+	_, err := fmt.Fprintf(w, "# HELP %s %s\n# TYPE %s %s\n", name, help, name, metricType)
+	if err != nil {
+		return err
+	}
+
+	for _, line := range lines {
+		_, err = fmt.Fprintln(w, line)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteCAMetrics writes the expiration timestamp of the active CAs along with the CA service counters.
+func WriteCAMetrics(w io.Writer, cas []models.CACertificate) error {
+	samples := []Sample{}
+	for _, ca := range cas {
+		if ca.Status != models.StatusActive || ca.Certificate.Certificate == nil {
+			continue
+		}
+
+		samples = append(samples, Sample{
+			Labels: map[string]string{
+				"ca_id":       ca.ID,
+				"common_name": ca.Certificate.Subject.CommonName,
+			},
+			Value: float64(ca.Certificate.ValidTo.Unix()),
+		})
+	}
+
+	err := WriteMetric(w, "lamassu_ca_expiration_timestamp_seconds", "Expiration date of the active CAs as a Unix timestamp.", "gauge", samples)
+	if err != nil {
+		return err
+	}
+
+	for _, counter := range []*CounterVec{CertificateIssuances, CertificateIssuanceErrors, EventBusPublishFailures} {
+		err = counter.Write(w)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// WriteDMSEnrollmentMetrics writes the enrollment counters of the DMSs along with the event bus publish failures.
+func WriteDMSEnrollmentMetrics(w io.Writer, stats []models.DMSEnrollmentStats) error {
+	succeeded := []Sample{}
+	failed := []Sample{}
+	for _, dmsStats := range stats {
+		operations := map[models.EnrollmentOperation]models.EnrollmentCounters{
+			models.EnrollmentOperationEnroll:   dmsStats.Enroll,
+			models.EnrollmentOperationReenroll: dmsStats.Reenroll,
+		}
+
+		for operation, counters := range operations {
+			succeeded = append(succeeded, Sample{
+				Labels: map[string]string{"dms_id": dmsStats.DMSID, "operation": string(operation)},
+				Value:  float64(counters.Succeeded),
+			})
+
+			for reason, count := range counters.Failed {
+				failed = append(failed, Sample{
+					Labels: map[string]string{"dms_id": dmsStats.DMSID, "operation": string(operation), "reason": string(reason)},
+					Value:  float64(count),
+				})
+			}
+		}
+	}
+
+	err := WriteMetric(w, "lamassu_dms_enrollments_total", "Number of successful EST enrollments served by the DMS.", "counter", succeeded)
+	if err != nil {
+		return err
+	}
+
+	err = WriteMetric(w, "lamassu_dms_enrollment_failures_total", "Number of failed EST enrollments served by the DMS, by failure reason.", "counter", failed)
+	if err != nil {
+		return err
+	}
+
+	return EventBusPublishFailures.Write(w)
+}
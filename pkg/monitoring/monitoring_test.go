@@ -0,0 +1,150 @@
+package monitoring
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestCounterVecWrite(t *testing.T) {
+	counter := NewCounterVec("test_total", "Test counter.", "service", "event_type")
+	counter.Inc("ca", "ca.create")
+	counter.Inc("ca", "ca.create")
+	counter.Inc("ca", `quoted "type"`)
+
+	if counter.Value("ca", "ca.create") != 2 {
+		t.Errorf("unexpected counter value: %v", counter.Value("ca", "ca.create"))
+	}
+
+	var buf bytes.Buffer
+	err := counter.Write(&buf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := "# HELP test_total Test counter.\n" +
+		"# TYPE test_total counter\n" +
+		"test_total{event_type=\"ca.create\",service=\"ca\"} 2\n" +
+		"test_total{event_type=\"quoted \\\"type\\\"\",service=\"ca\"} 1\n"
+	if buf.String() != expected {
+		t.Errorf("unexpected output.\nexpected:\n%s\ngot:\n%s", expected, buf.String())
+	}
+}
+
+func TestBuildAlertingRules(t *testing.T) {
+	cas := []models.CACertificate{
+		{
+			ID: "ca-1",
+			Certificate: models.Certificate{
+				Status: models.StatusActive,
+			},
+			Metadata: map[string]any{
+				models.CAMetadataMonitoringExpirationDeltasKey: models.CAMetadataMonitoringExpirationDeltas{
+					{Name: "Preventive", Delta: models.TimeDuration(90 * 24 * time.Hour)},
+				},
+			},
+		},
+		{
+			ID: "ca-2",
+			Certificate: models.Certificate{
+				Status: models.StatusRevoked,
+			},
+		},
+	}
+
+	rules := BuildAlertingRules(AlertingRulesInput{
+		ServiceID: "ca",
+		CAs:       cas,
+		PublisherEventBus: config.EventBusEngine{
+			Enabled:  true,
+			Provider: config.Amqp,
+		},
+	})
+
+	if len(rules.Groups) != 3 {
+		t.Fatalf("expected 3 rule groups, got %d", len(rules.Groups))
+	}
+
+	expirationRules := rules.Groups[0].Rules
+	if len(expirationRules) != 2 {
+		t.Fatalf("expected 2 expiration rules, got %d", len(expirationRules))
+	}
+
+	if expirationRules[1].Alert != "LamassuCAExpiringPreventive" || !strings.Contains(expirationRules[1].Expr, `ca_id="ca-1"`) || !strings.HasSuffix(expirationRules[1].Expr, "< 7776000") {
+		t.Errorf("unexpected CA expiration rule: %+v", expirationRules[1])
+	}
+
+	if rules.Groups[2].Rules[0].Alert != "LamassuAMQPPublishFailures" {
+		t.Errorf("unexpected event bus rule: %+v", rules.Groups[2].Rules[0])
+	}
+
+	if !strings.Contains(rules.Groups[2].Rules[0].Expr, `service="ca"`) {
+		t.Errorf("unexpected event bus rule expression: %s", rules.Groups[2].Rules[0].Expr)
+	}
+
+	noEventBusRules := BuildAlertingRules(AlertingRulesInput{ServiceID: "ca"})
+	if len(noEventBusRules.Groups) != 2 {
+		t.Errorf("expected no event bus rules when the publisher is disabled, got %d groups", len(noEventBusRules.Groups))
+	}
+}
+
+func TestBuildAlertingRulesEscapesLabelValues(t *testing.T) {
+	rules := BuildAlertingRules(AlertingRulesInput{
+		ServiceID: "ca",
+		CAs: []models.CACertificate{
+			{
+				ID: "ca\"} or vector(1) #\\\n",
+				Certificate: models.Certificate{
+					Status: models.StatusActive,
+				},
+				Metadata: map[string]any{
+					models.CAMetadataMonitoringExpirationDeltasKey: models.CAMetadataMonitoringExpirationDeltas{
+						{Name: "Critical", Delta: models.TimeDuration(24 * time.Hour)},
+					},
+				},
+			},
+		},
+	})
+
+	expr := rules.Groups[0].Rules[1].Expr
+	expected := `lamassu_ca_expiration_timestamp_seconds{ca_id="ca\"} or vector(1) #\\\n"} - time() < 86400`
+	if expr != expected {
+		t.Errorf("expected the CA ID to be escaped in the rule expression:\n got: %s\nwant: %s", expr, expected)
+	}
+}
+
+func TestWriteDMSEnrollmentMetrics(t *testing.T) {
+	stats := []models.DMSEnrollmentStats{
+		{
+			DMSID: "dms-1",
+			Enroll: models.EnrollmentCounters{
+				Succeeded: 3,
+				Failed:    map[models.EnrollmentFailureReason]int{models.EnrollmentFailureAuth: 2},
+			},
+			Reenroll: models.EnrollmentCounters{
+				Failed: map[models.EnrollmentFailureReason]int{models.EnrollmentFailureCA: 1},
+			},
+		},
+	}
+
+	var buf bytes.Buffer
+	err := WriteDMSEnrollmentMetrics(&buf, stats)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	for _, line := range []string{
+		`lamassu_dms_enrollments_total{dms_id="dms-1",operation="enroll"} 3`,
+		`lamassu_dms_enrollments_total{dms_id="dms-1",operation="reenroll"} 0`,
+		`lamassu_dms_enrollment_failures_total{dms_id="dms-1",operation="enroll",reason="auth"} 2`,
+		`lamassu_dms_enrollment_failures_total{dms_id="dms-1",operation="reenroll",reason="ca"} 1`,
+	} {
+		if !strings.Contains(buf.String(), line+"\n") {
+			t.Errorf("metrics do not include %s:\n%s", line, buf.String())
+		}
+	}
+}
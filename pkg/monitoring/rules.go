@@ -0,0 +1,164 @@
+package monitoring
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// DefaultCAExpirationWarning is the expiration threshold applied to every CA, on top of the
+// expiration deltas configured in each CA metadata.
+const DefaultCAExpirationWarning = 30 * 24 * time.Hour
+
+// RuleFile follows the Prometheus alerting rules file format.
+type RuleFile struct {
+	Groups []RuleGroup `json:"groups" yaml:"groups"`
+}
+
+type RuleGroup struct {
+	Name  string `json:"name" yaml:"name"`
+	Rules []Rule `json:"rules" yaml:"rules"`
+}
+
+type Rule struct {
+	Alert       string            `json:"alert" yaml:"alert"`
+	Expr        string            `json:"expr" yaml:"expr"`
+	For         string            `json:"for,omitempty" yaml:"for,omitempty"`
+	Labels      map[string]string `json:"labels,omitempty" yaml:"labels,omitempty"`
+	Annotations map[string]string `json:"annotations,omitempty" yaml:"annotations,omitempty"`
+}
+
+type AlertingRulesInput struct {
+	ServiceID         string
+	CAs               []models.CACertificate
+	PublisherEventBus config.EventBusEngine
+	// IssuanceErrorRatio is the ratio (0-1) of failed issuance requests that triggers the alert. Defaults to 0.05
+	IssuanceErrorRatio float64
+}
+
+// BuildAlertingRules generates the alerting rules matching the metrics exposed by the CA service and its live configuration:
+// the expiration deltas defined by each CA, the issuance error rate and, if enabled, the event bus publish failures.
+func BuildAlertingRules(input AlertingRulesInput) RuleFile {
+	errorRatio := input.IssuanceErrorRatio
+	if errorRatio <= 0 {
+		errorRatio = 0.05
+	}
+
+	expirationRules := []Rule{
+		{
+			Alert: "LamassuCAExpiring",
+			Expr:  fmt.Sprintf("lamassu_ca_expiration_timestamp_seconds - time() < %d", int64(DefaultCAExpirationWarning.Seconds())),
+			For:   "1h",
+			Labels: map[string]string{
+				"severity": "warning",
+			},
+			Annotations: map[string]string{
+				"summary":     "CA {{ $labels.ca_id }} expires in less than 30 days",
+				"description": "CA {{ $labels.ca_id }} ({{ $labels.common_name }}) must be renewed before it expires.",
+			},
+		},
+	}
+
+	for _, ca := range input.CAs {
+		if ca.Status != models.StatusActive {
+			continue
+		}
+
+		var deltas models.CAMetadataMonitoringExpirationDeltas
+		hasDeltas, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataMonitoringExpirationDeltasKey, &deltas)
+		if err != nil || !hasDeltas {
+			continue
+		}
+
+		for _, delta := range deltas {
+			expirationRules = append(expirationRules, Rule{
+				Alert: fmt.Sprintf("LamassuCAExpiring%s", alertNameSuffix(delta.Name)),
+				Expr:  fmt.Sprintf(`lamassu_ca_expiration_timestamp_seconds{ca_id=%s} - time() < %d`, promLabelValue(ca.ID), int64(time.Duration(delta.Delta).Seconds())),
+				For:   "1h",
+				Labels: map[string]string{
+					"severity": strings.ToLower(delta.Name),
+					"ca_id":    ca.ID,
+				},
+				Annotations: map[string]string{
+					"summary": fmt.Sprintf("CA %s reached its '%s' expiration threshold (%s)", ca.ID, delta.Name, delta.Delta.String()),
+				},
+			})
+		}
+	}
+
+	ruleFile := RuleFile{
+		Groups: []RuleGroup{
+			{
+				Name:  "lamassu-ca-expiration",
+				Rules: expirationRules,
+			},
+			{
+				Name: "lamassu-ca-issuance",
+				Rules: []Rule{
+					{
+						Alert: "LamassuCertificateIssuanceErrorRate",
+						Expr:  fmt.Sprintf("sum by (ca_id) (rate(lamassu_ca_certificate_issuance_errors_total[5m])) / sum by (ca_id) (rate(lamassu_ca_certificate_issuance_total[5m])) > %v", errorRatio),
+						For:   "10m",
+						Labels: map[string]string{
+							"severity": "critical",
+						},
+						Annotations: map[string]string{
+							"summary": fmt.Sprintf("More than %v%% of the issuance requests for CA {{ $labels.ca_id }} are failing", errorRatio*100),
+						},
+					},
+				},
+			},
+		},
+	}
+
+	if input.PublisherEventBus.Enabled {
+		alert := "LamassuEventBusPublishFailures"
+		if input.PublisherEventBus.Provider == config.Amqp {
+			alert = "LamassuAMQPPublishFailures"
+		}
+
+		ruleFile.Groups = append(ruleFile.Groups, RuleGroup{
+			Name: "lamassu-eventbus",
+			Rules: []Rule{
+				{
+					Alert: alert,
+					Expr:  fmt.Sprintf(`increase(lamassu_eventbus_publish_failures_total{service=%s}[5m]) > 0`, promLabelValue(input.ServiceID)),
+					Labels: map[string]string{
+						"severity": "critical",
+						"provider": string(input.PublisherEventBus.Provider),
+					},
+					Annotations: map[string]string{
+						"summary": fmt.Sprintf("%s service could not publish {{ $labels.event_type }} events to the %s event bus", input.ServiceID, input.PublisherEventBus.Provider),
+					},
+				},
+			},
+		})
+	}
+
+	return ruleFile
+}
+
+// promLabelValue renders a label value as a double quoted PromQL string literal. PromQL follows the Go
+// escaping rules, so quotes, backslashes and control characters in IDs can't break out of the matcher.
+func promLabelValue(value string) string {
+	return strconv.Quote(value)
+}
+
+// alertNameSuffix converts a delta name (i.e. "Preventive", "critical-threshold") into CamelCase.
+func alertNameSuffix(name string) string {
+	parts := strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	})
+
+	suffix := ""
+	for _, part := range parts {
+		suffix += strings.ToUpper(part[:1]) + part[1:]
+	}
+
+	return suffix
+}
@@ -0,0 +1,64 @@
+package resources
+
+import (
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ACME resources follow the JSON representations of RFC 8555 7.1, so they are consumed by standard ACME clients.
+
+type ACMEDirectory struct {
+	NewNonce   string            `json:"newNonce"`
+	NewAccount string            `json:"newAccount"`
+	NewOrder   string            `json:"newOrder"`
+	RevokeCert string            `json:"revokeCert"`
+	Meta       ACMEDirectoryMeta `json:"meta"`
+}
+
+type ACMEDirectoryMeta struct {
+	ExternalAccountRequired bool `json:"externalAccountRequired"`
+}
+
+type ACMEProblem struct {
+	Type   string `json:"type"`
+	Detail string `json:"detail"`
+	Status int    `json:"status"`
+}
+
+type ACMEAccountResponse struct {
+	Status  models.ACMEStatus `json:"status"`
+	Contact []string          `json:"contact,omitempty"`
+	Orders  string            `json:"orders"`
+}
+
+type ACMEAccountOrdersResponse struct {
+	Orders []string `json:"orders"`
+}
+
+type ACMEOrderResponse struct {
+	Status         models.ACMEStatus       `json:"status"`
+	Expires        time.Time               `json:"expires"`
+	Identifiers    []models.ACMEIdentifier `json:"identifiers"`
+	Authorizations []string                `json:"authorizations"`
+	Finalize       string                  `json:"finalize"`
+	Certificate    string                  `json:"certificate,omitempty"`
+	Error          *ACMEProblem            `json:"error,omitempty"`
+}
+
+type ACMEAuthorizationResponse struct {
+	Identifier models.ACMEIdentifier   `json:"identifier"`
+	Status     models.ACMEStatus       `json:"status"`
+	Expires    time.Time               `json:"expires"`
+	Wildcard   bool                    `json:"wildcard,omitempty"`
+	Challenges []ACMEChallengeResponse `json:"challenges"`
+}
+
+type ACMEChallengeResponse struct {
+	Type      models.ACMEChallengeType `json:"type"`
+	URL       string                   `json:"url"`
+	Token     string                   `json:"token"`
+	Status    models.ACMEStatus        `json:"status"`
+	Validated *time.Time               `json:"validated,omitempty"`
+	Error     *ACMEProblem             `json:"error,omitempty"`
+}
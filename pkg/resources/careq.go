@@ -32,6 +32,15 @@ var CertificateFiltrableFields = map[string]FilterFieldType{
 	"revocation_reason":    EnumFilterFieldType,
 }
 
+var CASigningRequestFiltrableFields = map[string]FilterFieldType{
+	"id":           StringFilterFieldType,
+	"operation":    EnumFilterFieldType,
+	"status":       EnumFilterFieldType,
+	"requested_by": StringFilterFieldType,
+	"requested_at": DateFilterFieldType,
+	"expires_at":   DateFilterFieldType,
+}
+
 type CreateCABody struct {
 	ID                 string             `json:"id"`
 	ParentID           string             `json:"parent_id"`
@@ -41,27 +50,93 @@ type CreateCABody struct {
 	IssuanceExpiration models.Expiration  `json:"issuance_expiration"`
 	EngineID           string             `json:"engine_id"`
 	Metadata           map[string]any     `json:"metadata"`
+	// HybridKeyMetadata creates a hybrid CA with an alternative post-quantum key. Optional.
+	HybridKeyMetadata *models.HybridKeyMetadata `json:"hybrid_key_metadata,omitempty"`
 }
 
 type ImportCABody struct {
-	ID                 string                    `json:"id"`
-	EngineID           string                    `json:"engine_id"`
-	ParentID           string                    `json:"parent_id"`
-	CAPrivateKey       string                    `json:"private_key"` //b64 from PEM
-	CACertificate      *models.X509Certificate   `json:"ca"`
-	CAChain            []*models.X509Certificate `json:"ca_chain"`
-	CAType             models.CertificateType    `json:"ca_type"`
-	IssuanceExpiration models.Expiration         `json:"issuance_expiration"`
+	ID            string                    `json:"id"`
+	EngineID      string                    `json:"engine_id"`
+	ParentID      string                    `json:"parent_id"`
+	CAPrivateKey  string                    `json:"private_key"` //b64 from PEM
+	CACertificate *models.X509Certificate   `json:"ca"`
+	CAChain       []*models.X509Certificate `json:"ca_chain"`
+	// Bundle is a b64 encoded PEM bundle with the CA certificate, followed by its chain and the private key.
+	// It replaces the ca, ca_chain and private_key fields.
+	Bundle             string                 `json:"bundle"`
+	CAType             models.CertificateType `json:"ca_type"`
+	IssuanceExpiration models.Expiration      `json:"issuance_expiration"`
 }
 
 type UpdateCAMetadataBody struct {
 	Metadata map[string]interface{} `json:"metadata"`
 }
 
+type UpdateCASettingsBody struct {
+	IssuanceExpiration *models.Expiration                          `json:"issuance_expiration,omitempty"`
+	ExpirationDeltas   models.CAMetadataMonitoringExpirationDeltas `json:"expiration_deltas,omitempty"`
+	Metadata           map[string]interface{}                      `json:"metadata,omitempty"`
+}
+
+type MigrateCAKeyBody struct {
+	TargetEngineID  string `json:"engine_id"`
+	RemoveSourceKey bool   `json:"remove_source_key"`
+}
+
+type SetCAFallbackEngineBody struct {
+	EngineID string `json:"engine_id"`
+}
+
+type SetCAIssuanceWebhookBody struct {
+	Webhook *models.CAIssuanceWebhook `json:"webhook"`
+}
+
+type RestoreCAKeyBody struct {
+	Backup []byte `json:"backup"`
+}
+
 type SignCertificateBody struct {
-	SignVerbatim bool                           `json:"sign_verbatim"`
-	CertRequest  *models.X509CertificateRequest `json:"csr"`
-	Subject      *models.Subject                `json:"subject"`
+	SignVerbatim   bool                           `json:"sign_verbatim"`
+	CertRequest    *models.X509CertificateRequest `json:"csr"`
+	Subject        *models.Subject                `json:"subject"`
+	SigningProfile *models.SigningProfile         `json:"signing_profile,omitempty"`
+	// IssuanceContext is only accepted from the internal services authenticated with their client certificate (i.e.
+	// the DMS manager). It is ignored for any other caller.
+	IssuanceContext *models.CertificateIssuanceContext `json:"issuance_context,omitempty"`
+	// CertificateProfileID pins the certificate profile bound to the CA. Requests referencing another profile are rejected.
+	CertificateProfileID string `json:"certificate_profile_id,omitempty"`
+}
+
+type SignCertificatesBatchBody struct {
+	Requests []SignCertificateBody `json:"requests"`
+}
+
+type CreateCertificateProfileBody struct {
+	ID                 string                    `json:"id"`
+	Name               string                    `json:"name"`
+	Description        string                    `json:"description"`
+	CAIDs              []string                  `json:"ca_ids"`
+	KeyUsages          []models.KeyUsage         `json:"key_usages"`
+	ExtendedKeyUsages  []models.ExtendedKeyUsage `json:"extended_key_usages"`
+	SANPolicy          models.SANPolicy          `json:"san_policy"`
+	SubjectConstraints models.SubjectConstraints `json:"subject_constraints"`
+	MaxValidity        *models.TimeDuration      `json:"max_validity,omitempty"`
+	NotBeforeBackdate  *models.TimeDuration      `json:"not_before_backdate,omitempty"`
+}
+
+type SignSVIDBody struct {
+	CertRequest *models.X509CertificateRequest `json:"csr"`
+	SPIFFEID    string                         `json:"spiffe_id"`
+	Validity    *models.TimeDuration           `json:"validity,omitempty"`
+}
+
+type IssueInternalCertificateBody struct {
+	CertRequest *models.X509CertificateRequest `json:"csr"`
+}
+
+type SignTokenBody struct {
+	Claims    map[string]any `json:"claims"`
+	Algorithm string         `json:"alg"`
 }
 
 type SignatureSignBody struct {
@@ -70,6 +145,25 @@ type SignatureSignBody struct {
 	SigningAlgorithm string                 `json:"signature_algorithm"`
 }
 
+type CreateCASigningRequestBody struct {
+	Operation models.CASigningOperation `json:"operation"`
+	// Message is the base64 encoded message of SIGN_MESSAGE requests.
+	Message              string                         `json:"message,omitempty"`
+	MessageType          models.SignMessageType         `json:"message_type,omitempty"`
+	SigningAlgorithm     string                         `json:"signature_algorithm,omitempty"`
+	CertRequest          *models.X509CertificateRequest `json:"csr,omitempty"`
+	Subject              *models.Subject                `json:"subject,omitempty"`
+	SignVerbatim         bool                           `json:"sign_verbatim,omitempty"`
+	SigningProfile       *models.SigningProfile         `json:"signing_profile,omitempty"`
+	CertificateProfileID string                         `json:"certificate_profile_id,omitempty"`
+	// DualControl is the new policy of UPDATE_DUAL_CONTROL requests. The dual control of the CA is removed if missing.
+	DualControl *models.CADualControl `json:"dual_control,omitempty"`
+}
+
+type RejectCASigningRequestBody struct {
+	Reason string `json:"reason"`
+}
+
 type SignatureVerifyBody struct {
 	Signature        string                 `json:"signature"`
 	Message          string                 `json:"message"`
@@ -103,3 +197,10 @@ type ImportCertificateBody struct {
 	Metadata    map[string]interface{}  `json:"metadata"`
 	Certificate *models.X509Certificate `json:"certificate"`
 }
+
+type ReportCRLGenerationBody struct {
+	Number              string    `json:"number"`
+	ThisUpdate          time.Time `json:"this_update"`
+	NextUpdate          time.Time `json:"next_update"`
+	RevokedCertificates int       `json:"revoked_certificates"`
+}
@@ -10,6 +10,14 @@ type GetCertsResponse struct {
 	IterableList[models.Certificate]
 }
 
+type GetCertificateProfilesResponse struct {
+	IterableList[models.CertificateProfile]
+}
+
+type GetCASigningRequestsResponse struct {
+	IterableList[models.CASigningRequest]
+}
+
 type SignResponse struct {
 	SignedData string `json:"signed_data"`
 }
@@ -17,3 +25,7 @@ type SignResponse struct {
 type VerifyResponse struct {
 	Valid bool `json:"valid"`
 }
+
+type SignCertificatesBatchResponse struct {
+	Results []models.CertificateSigningResult `json:"results"`
+}
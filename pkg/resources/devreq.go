@@ -1,13 +1,41 @@
 package resources
 
-import "github.com/lamassuiot/lamassuiot/v2/pkg/models"
+import (
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
 
 var DeviceFiltrableFields = map[string]FilterFieldType{
+	"id":                             StringFilterFieldType,
+	"dms_owner":                      StringFilterFieldType,
+	"creation_timestamp":             DateFilterFieldType,
+	"status":                         EnumFilterFieldType,
+	"tags":                           StringArrayFilterFieldType,
+	"connection_metadata.status":     EnumFilterFieldType,
+	"connection_metadata.last_seen":  DateFilterFieldType,
+	"connection_metadata.broker":     StringFilterFieldType,
+	"connection_metadata.ip_address": StringFilterFieldType,
+}
+
+var DeviceGroupFiltrableFields = map[string]FilterFieldType{
 	"id":                 StringFilterFieldType,
-	"dms_owner":          StringFilterFieldType,
+	"name":               StringFilterFieldType,
 	"creation_timestamp": DateFilterFieldType,
+}
+
+var DeviceGroupBulkActionFiltrableFields = map[string]FilterFieldType{
+	"id":                 StringFilterFieldType,
+	"type":               EnumFilterFieldType,
 	"status":             EnumFilterFieldType,
-	"tags":               StringArrayFilterFieldType,
+	"creation_timestamp": DateFilterFieldType,
+}
+
+var DeviceLogFiltrableFields = map[string]FilterFieldType{
+	"severity":  EnumFilterFieldType,
+	"source":    StringFilterFieldType,
+	"message":   StringFilterFieldType,
+	"timestamp": DateFilterFieldType,
 }
 
 type CreateDeviceBody struct {
@@ -18,12 +46,67 @@ type CreateDeviceBody struct {
 	DMSID     string         `json:"dms_id"`
 	Icon      string         `json:"icon"`
 	IconColor string         `json:"icon_color"`
+
+	DeviceIDRules *models.DeviceIDRules `json:"device_id_rules,omitempty"`
 }
 
 type UpdateDeviceIdentitySlotBody struct {
 	models.Slot[string]
 }
 
+type UpdateDeviceConnectionMetadataBody struct {
+	models.DeviceConnectionMetadata
+}
+
 type UpdateDeviceMetadataBody struct {
 	Metadata map[string]any `json:"metadata"`
 }
+
+type ReportDeviceHeartbeatBody struct {
+	Source string `json:"source"`
+	Broker string `json:"broker"`
+}
+
+type CreateDeviceLogBody struct {
+	Severity  models.DeviceLogSeverity `json:"severity"`
+	Message   string                   `json:"message"`
+	Source    string                   `json:"source"`
+	Timestamp time.Time                `json:"timestamp"`
+}
+
+type ForceDeviceReenrollBody struct {
+	Reason string `json:"reason"`
+}
+
+type ProvisionDeviceSecretSlotBody struct {
+	Type    models.CryptoSecretType     `json:"type"`
+	Profile models.SymmetricSlotProfile `json:"profile"`
+}
+
+type CreateDeviceGroupBody struct {
+	Name              string                         `json:"name"`
+	Description       string                         `json:"description"`
+	DeviceIDs         []string                       `json:"device_ids"`
+	Tags              []string                       `json:"tags"`
+	Filters           []string                       `json:"filters"`
+	CertificateStatus models.DeviceCertificateStatus `json:"certificate_status"`
+}
+
+type UpdateDeviceGroupDevicesBody struct {
+	Add    []string `json:"add"`
+	Remove []string `json:"remove"`
+}
+
+type UpdateDeviceGroupTagsBody struct {
+	Tags []string `json:"tags"`
+}
+
+type UpdateDeviceGroupFiltersBody struct {
+	Filters           []string                       `json:"filters"`
+	CertificateStatus models.DeviceCertificateStatus `json:"certificate_status"`
+}
+
+type StartDeviceGroupBulkActionBody struct {
+	Type     models.DeviceGroupBulkActionType `json:"type"`
+	Metadata map[string]any                   `json:"metadata"`
+}
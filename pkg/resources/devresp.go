@@ -5,3 +5,19 @@ import "github.com/lamassuiot/lamassuiot/v2/pkg/models"
 type GetDevicesResponse struct {
 	IterableList[models.Device]
 }
+
+type GetDevicesOutOfCloudSyncResponse struct {
+	IterableList[models.DeviceCloudSyncStatus]
+}
+
+type GetDeviceLogsResponse struct {
+	IterableList[models.DeviceLog]
+}
+
+type GetDeviceGroupsResponse struct {
+	IterableList[models.DeviceGroup]
+}
+
+type GetDeviceGroupBulkActionsResponse struct {
+	IterableList[models.DeviceGroupBulkAction]
+}
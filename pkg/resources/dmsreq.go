@@ -8,6 +8,9 @@ var DMSFiltrableFields = map[string]FilterFieldType{
 	"id":          StringFilterFieldType,
 	"name":        StringFilterFieldType,
 	"creation_ts": DateFilterFieldType,
+
+	"public_key_fingerprint": StringFilterFieldType,
+	"status":                 EnumFilterFieldType,
 }
 
 type CreateDMSBody struct {
@@ -15,6 +18,66 @@ type CreateDMSBody struct {
 	Name     string             `json:"name"`
 	Metadata map[string]any     `json:"metadata"`
 	Settings models.DMSSettings `json:"settings"`
+	// CSR is optional. When set, its public key must not be registered by another DMS.
+	CSR *models.X509CertificateRequest `json:"csr,omitempty"`
+}
+
+type UpdateDMSStatusBody struct {
+	Status models.DMSStatus `json:"status"`
+}
+
+var EnrollmentAuditFiltrableFields = map[string]FilterFieldType{
+	"device_id":  StringFilterFieldType,
+	"source_ip":  StringFilterFieldType,
+	"gateway_id": StringFilterFieldType,
+	"rule":       StringFilterFieldType,
+	"action":     EnumFilterFieldType,
+	"timestamp":  DateFilterFieldType,
+}
+
+var EnrollmentPolicyFiltrableFields = map[string]FilterFieldType{
+	"id":          StringFilterFieldType,
+	"name":        StringFilterFieldType,
+	"creation_ts": DateFilterFieldType,
+}
+
+type CreateEnrollmentPolicyBody struct {
+	ID           string           `json:"id"`
+	Name         string           `json:"name"`
+	Description  string           `json:"description"`
+	SubjectRegex string           `json:"subject_regex"`
+	KeyTypes     []models.KeyType `json:"key_types"`
+	MinKeyBits   int              `json:"min_key_bits"`
+	AllowedCAIDs []string         `json:"allowed_ca_ids"`
+}
+
+type CreateBootstrapTokenBody struct {
+	DeviceID string              `json:"device_id"`
+	TTL      models.TimeDuration `json:"ttl"`
+}
+
+type CreateClaimCodeBody struct {
+	DeviceID string              `json:"device_id"`
+	TTL      models.TimeDuration `json:"ttl"`
+}
+
+type ClaimDeviceBody struct {
+	Code              string                         `json:"code"`
+	CSR               *models.X509CertificateRequest `json:"csr"`
+	InstallerMetadata map[string]any                 `json:"installer_metadata"`
+}
+
+type PreregisterDevicesBody struct {
+	DeviceIDs []string `json:"device_ids"`
+}
+
+type RevokeDeviceCertificateBody struct {
+	RevocationReason models.RevocationReason `json:"revocation_reason"`
+}
+
+type StartCARotationBody struct {
+	CAID      string `json:"ca_id"`
+	BatchSize int    `json:"batch_size"`
 }
 
 type BindIdentityToDeviceBody struct {
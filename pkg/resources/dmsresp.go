@@ -5,3 +5,11 @@ import "github.com/lamassuiot/lamassuiot/v2/pkg/models"
 type GetDMSsResponse struct {
 	IterableList[models.DMS]
 }
+
+type GetEnrollmentPoliciesResponse struct {
+	IterableList[models.DMSEnrollmentPolicy]
+}
+
+type GetEnrollmentAuditsResponse struct {
+	IterableList[models.DMSEnrollmentAudit]
+}
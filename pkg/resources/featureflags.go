@@ -0,0 +1,10 @@
+package resources
+
+type FeatureFlagsResponse struct {
+	RuntimeToggles bool            `json:"runtime_toggles"`
+	Flags          map[string]bool `json:"flags"`
+}
+
+type ToggleFeatureFlagBody struct {
+	Enabled bool `json:"enabled"`
+}
@@ -0,0 +1,227 @@
+package resources
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// DefaultPageSize is the page size of the list endpoints when the "page_size" query parameter is not set.
+const DefaultPageSize = 25
+
+// filterOperands maps the operands of a filter expression (i.e. "status[eq]ACTIVE") to the filter operations
+// of each field type. The first operand of each operation is the canonical one.
+var filterOperands = map[FilterFieldType][]struct {
+	operands  []string
+	operation FilterOperation
+}{
+	StringFilterFieldType: {
+		{[]string{"eq", "equal"}, StringEqual},
+		{[]string{"ne", "notequal"}, StringNotEqual},
+		{[]string{"ct", "contains"}, StringContains},
+		{[]string{"nc", "notcontains"}, StringNotContains},
+	},
+	StringArrayFilterFieldType: {
+		{[]string{"ct", "contains"}, StringArrayContains},
+	},
+	DateFilterFieldType: {
+		{[]string{"eq", "equal"}, DateEqual},
+		{[]string{"bf", "before"}, DateBefore},
+		{[]string{"af", "after"}, DateAfter},
+	},
+	NumberFilterFieldType: {
+		{[]string{"eq", "equal"}, NumberEqual},
+		{[]string{"ne", "notequal"}, NumberNotEqual},
+		{[]string{"lt", "lessthan"}, NumberLessThan},
+		{[]string{"le", "lessequal", "lessorequal"}, NumberLessOrEqualThan},
+		{[]string{"gt", "greaterthan"}, NumberGreaterThan},
+		{[]string{"ge", "greaterequal", "greaterorequal"}, NumberGreaterOrEqualThan},
+	},
+	EnumFilterFieldType: {
+		{[]string{"eq", "equal"}, EnumEqual},
+		{[]string{"ne", "notequal"}, EnumNotEqual},
+	},
+}
+
+// filterShorthands are the query parameters accepted as a shorthand of a date filter, i.e.
+// "expires_before=2025-01-01" is the same as "filter=valid_to[bf]2025-01-01". Besides, every date field accepts
+// the "<field>_before" and "<field>_after" shorthands.
+var filterShorthands = map[string]struct {
+	field     string
+	operation FilterOperation
+}{
+	"expires_before": {"valid_to", DateBefore},
+	"expires_after":  {"valid_to", DateAfter},
+	"issued_before":  {"valid_from", DateBefore},
+	"issued_after":   {"valid_from", DateAfter},
+}
+
+// ParseFilterExpression parses a "<field>[<operand>]<value>" filter expression. The expression is rejected if the
+// field is not in the filtrable fields or the operand is not supported by the field type. Date values are
+// normalized to RFC3339 and can also be expressed as "2006-01-02".
+func ParseFilterExpression(expr string, filtrableFields map[string]FilterFieldType) (FilterOption, error) {
+	field, rest, found := strings.Cut(expr, "[")
+	if !found {
+		return FilterOption{}, fmt.Errorf("filter '%s' is not a <field>[<operand>]<value> expression", expr)
+	}
+
+	operand, value, found := strings.Cut(rest, "]")
+	if !found {
+		return FilterOption{}, fmt.Errorf("filter '%s' is not a <field>[<operand>]<value> expression", expr)
+	}
+
+	fieldType, ok := filtrableFields[field]
+	if !ok {
+		return FilterOption{}, fmt.Errorf("field '%s' is not filtrable", field)
+	}
+
+	operand = strings.ToLower(operand)
+	for _, op := range filterOperands[fieldType] {
+		for _, candidate := range op.operands {
+			if candidate == operand {
+				return newFilterOption(field, fieldType, op.operation, value)
+			}
+		}
+	}
+
+	return FilterOption{}, fmt.Errorf("operand '%s' is not supported by field '%s'", operand, field)
+}
+
+// FormatFilterExpression is the inverse of ParseFilterExpression. It returns false if the filter operation is
+// not supported.
+func FormatFilterExpression(filter FilterOption) (string, bool) {
+	for _, ops := range filterOperands {
+		for _, op := range ops {
+			if op.operation == filter.FilterOperation {
+				return fmt.Sprintf("%s[%s]%s", filter.Field, op.operands[0], filter.Value), true
+			}
+		}
+	}
+
+	return "", false
+}
+
+func newFilterOption(field string, fieldType FilterFieldType, operation FilterOperation, value string) (FilterOption, error) {
+	switch fieldType {
+	case DateFilterFieldType:
+		date, err := parseFilterDate(value)
+		if err != nil {
+			return FilterOption{}, fmt.Errorf("field '%s' value '%s' is not a date: %w", field, value, err)
+		}
+		value = date.Format(time.RFC3339)
+	case NumberFilterFieldType:
+		if _, err := strconv.ParseFloat(value, 64); err != nil {
+			return FilterOption{}, fmt.Errorf("field '%s' value '%s' is not a number: %w", field, value, err)
+		}
+	}
+
+	return FilterOption{
+		Field:           field,
+		FilterOperation: operation,
+		Value:           value,
+	}, nil
+}
+
+func parseFilterDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return date, nil
+	}
+
+	return time.Parse(time.DateOnly, value)
+}
+
+// parseFilterShorthand resolves the "<field>_before" and "<field>_after" query parameters of the date fields.
+func parseFilterShorthand(key, value string, filtrableFields map[string]FilterFieldType) (FilterOption, bool, error) {
+	shorthand, ok := filterShorthands[key]
+	if !ok {
+		if field, found := strings.CutSuffix(key, "_before"); found {
+			shorthand.field, shorthand.operation = field, DateBefore
+		} else if field, found := strings.CutSuffix(key, "_after"); found {
+			shorthand.field, shorthand.operation = field, DateAfter
+		} else {
+			return FilterOption{}, false, nil
+		}
+	}
+
+	if fieldType, ok := filtrableFields[shorthand.field]; !ok || fieldType != DateFilterFieldType {
+		return FilterOption{}, false, nil
+	}
+
+	filter, err := newFilterOption(shorthand.field, DateFilterFieldType, shorthand.operation, value)
+	return filter, true, err
+}
+
+// ParseQueryParameters builds the QueryParameters of a list request from its query:
+//   - "page_size", "next_bookmark" (or its legacy name "bookmark"), "sort_by" and "sort_mode"
+//   - "filter", repeated once per filter expression (see ParseFilterExpression)
+//   - the date filter shorthands, i.e. "expires_before=2025-01-01" or "creation_ts_after=2025-01-01"
+//
+// Sorting and filtering is only allowed on the filtrable fields. The returned error lists the filters that
+// were discarded, the remaining parameters are still returned.
+func ParseQueryParameters(values url.Values, filtrableFields map[string]FilterFieldType) (*QueryParameters, error) {
+	queryParams := QueryParameters{
+		NextBookmark: "",
+		Filters:      []FilterOption{},
+		PageSize:     DefaultPageSize,
+	}
+
+	invalid := []string{}
+	for k, v := range values {
+		value := v[len(v)-1] //only get last
+		switch k {
+		case "sort_by":
+			sortField := strings.Trim(value, " ")
+			if _, exists := filtrableFields[sortField]; exists {
+				queryParams.Sort.SortField = sortField
+			}
+
+		case "sort_mode":
+			queryParams.Sort.SortMode = SortModeAsc
+			if value == "desc" {
+				queryParams.Sort.SortMode = SortModeDesc
+			}
+
+		case "page_size":
+			pageS, err := strconv.Atoi(value)
+			if err == nil && pageS > 0 {
+				queryParams.PageSize = pageS
+			}
+
+		case "next_bookmark":
+			queryParams.NextBookmark = value
+
+		case "bookmark":
+			// legacy name of next_bookmark. next_bookmark takes precedence if both are present
+			if !values.Has("next_bookmark") {
+				queryParams.NextBookmark = value
+			}
+
+		case "filter":
+			for _, expr := range v {
+				filter, err := ParseFilterExpression(expr, filtrableFields)
+				if err != nil {
+					invalid = append(invalid, err.Error())
+					continue
+				}
+				queryParams.Filters = append(queryParams.Filters, filter)
+			}
+
+		default:
+			filter, ok, err := parseFilterShorthand(k, value, filtrableFields)
+			if err != nil {
+				invalid = append(invalid, err.Error())
+			} else if ok {
+				queryParams.Filters = append(queryParams.Filters, filter)
+			}
+		}
+	}
+
+	if len(invalid) > 0 {
+		return &queryParams, fmt.Errorf("invalid filters: %s", strings.Join(invalid, "; "))
+	}
+
+	return &queryParams, nil
+}
@@ -0,0 +1,111 @@
+package resources
+
+import (
+	"net/url"
+	"testing"
+)
+
+func TestParseFilterExpression(t *testing.T) {
+	testcases := []struct {
+		name     string
+		expr     string
+		expected FilterOption
+		err      bool
+	}{
+		{name: "Enum", expr: "status[eq]ACTIVE", expected: FilterOption{Field: "status", FilterOperation: EnumEqual, Value: "ACTIVE"}},
+		{name: "LongOperand", expr: "level[GreaterOrEqual]2", expected: FilterOption{Field: "level", FilterOperation: NumberGreaterOrEqualThan, Value: "2"}},
+		{name: "DateOnly", expr: "valid_to[bf]2025-01-01", expected: FilterOption{Field: "valid_to", FilterOperation: DateBefore, Value: "2025-01-01T00:00:00Z"}},
+		{name: "ValueWithBrackets", expr: "id[ct]a[b]", expected: FilterOption{Field: "id", FilterOperation: StringContains, Value: "a[b]"}},
+		{name: "UnknownField", expr: "foo[eq]bar", err: true},
+		{name: "UnsupportedOperand", expr: "status[ct]ACT", err: true},
+		{name: "InvalidDate", expr: "valid_to[bf]tomorrow", err: true},
+		{name: "InvalidNumber", expr: "level[gt]two", err: true},
+		{name: "Malformed", expr: "status=ACTIVE", err: true},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			filter, err := ParseFilterExpression(tc.expr, CAFiltrableFields)
+			if tc.err {
+				if err == nil {
+					t.Fatalf("expected an error, got filter %v", filter)
+				}
+				return
+			}
+
+			if err != nil {
+				t.Fatalf("unexpected error: %s", err)
+			}
+
+			if filter != tc.expected {
+				t.Fatalf("expected filter %v, got %v", tc.expected, filter)
+			}
+
+			expr, ok := FormatFilterExpression(filter)
+			if !ok {
+				t.Fatalf("could not format filter %v", filter)
+			}
+
+			reparsed, err := ParseFilterExpression(expr, CAFiltrableFields)
+			if err != nil || reparsed != filter {
+				t.Fatalf("formatted expression %s does not parse back: %v %s", expr, reparsed, err)
+			}
+		})
+	}
+}
+
+func TestParseQueryParameters(t *testing.T) {
+	values, err := url.ParseQuery("filter=status[eq]ACTIVE&expires_before=2025-01-01&valid_from_after=2024-01-01&sort_by=valid_to&sort_mode=desc&page_size=10&bookmark=old&next_bookmark=new&cert_status=EXPIRED")
+	if err != nil {
+		t.Fatalf("could not parse query: %s", err)
+	}
+
+	queryParams, err := ParseQueryParameters(values, CertificateFiltrableFields)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if queryParams.PageSize != 10 || queryParams.NextBookmark != "new" {
+		t.Fatalf("unexpected pagination: page size %d, bookmark %s", queryParams.PageSize, queryParams.NextBookmark)
+	}
+
+	if queryParams.Sort.SortField != "valid_to" || queryParams.Sort.SortMode != SortModeDesc {
+		t.Fatalf("unexpected sort options: %v", queryParams.Sort)
+	}
+
+	expected := map[FilterOption]bool{
+		{Field: "status", FilterOperation: EnumEqual, Value: "ACTIVE"}:                   true,
+		{Field: "valid_to", FilterOperation: DateBefore, Value: "2025-01-01T00:00:00Z"}:  true,
+		{Field: "valid_from", FilterOperation: DateAfter, Value: "2024-01-01T00:00:00Z"}: true,
+	}
+	if len(queryParams.Filters) != len(expected) {
+		t.Fatalf("expected %d filters, got %v", len(expected), queryParams.Filters)
+	}
+	for _, filter := range queryParams.Filters {
+		if !expected[filter] {
+			t.Fatalf("unexpected filter %v", filter)
+		}
+	}
+
+	t.Run("InvalidFilter", func(t *testing.T) {
+		queryParams, err := ParseQueryParameters(url.Values{"filter": {"status[eq]ACTIVE", "status[ct]ACT"}, "expires_after": {"soon"}}, CertificateFiltrableFields)
+		if err == nil {
+			t.Fatalf("expected an error")
+		}
+
+		if len(queryParams.Filters) != 1 {
+			t.Fatalf("expected the valid filter to be kept, got %v", queryParams.Filters)
+		}
+	})
+
+	t.Run("Defaults", func(t *testing.T) {
+		queryParams, err := ParseQueryParameters(url.Values{"page_size": {"-1"}, "sort_by": {"unknown"}}, DMSFiltrableFields)
+		if err != nil {
+			t.Fatalf("unexpected error: %s", err)
+		}
+
+		if queryParams.PageSize != DefaultPageSize || queryParams.Sort.SortField != "" {
+			t.Fatalf("unexpected defaults: %v", queryParams)
+		}
+	})
+}
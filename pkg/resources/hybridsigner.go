@@ -0,0 +1,23 @@
+package resources
+
+import "github.com/lamassuiot/lamassuiot/v2/pkg/models"
+
+// Bodies of the external hybrid signer API. The signer holds the post-quantum keys of the hybrid CAs.
+
+type HybridSignerCreateKeyBody struct {
+	KeyID string         `json:"key_id"`
+	Type  models.KeyType `json:"type"`
+}
+
+type HybridSignerCreateKeyResponse struct {
+	// PublicKey is the DER encoded SubjectPublicKeyInfo of the key.
+	PublicKey []byte `json:"public_key"`
+}
+
+type HybridSignerSignBody struct {
+	Message []byte `json:"message"`
+}
+
+type HybridSignerSignResponse struct {
+	Signature []byte `json:"signature"`
+}
@@ -0,0 +1,12 @@
+package resources
+
+type LogLevelsResponse struct {
+	Global     string            `json:"global"`
+	Subsystems map[string]string `json:"subsystems"`
+}
+
+type SetLogLevelBody struct {
+	Level string `json:"level" binding:"required"`
+	// Duration restores the previous level once elapsed (e.g. "15m"). The level is kept if empty.
+	Duration string `json:"duration"`
+}
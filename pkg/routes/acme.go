@@ -0,0 +1,30 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+)
+
+func NewACMEHttpRoutes(logger *logrus.Entry, router *gin.RouterGroup, svc services.ACMEService, externalURL string) *gin.RouterGroup {
+	routes := controllers.NewACMEHttpRoutes(logger, svc, externalURL)
+
+	acme := router.Group("/v1/acme/:dms")
+
+	acme.GET("/directory", routes.GetDirectory)
+	acme.HEAD("/new-nonce", routes.NewNonce)
+	acme.GET("/new-nonce", routes.NewNonce)
+	acme.POST("/new-account", routes.NewAccount)
+	acme.POST("/account/:account", routes.UpdateAccount)
+	acme.POST("/account/:account/orders", routes.GetAccountOrders)
+	acme.POST("/new-order", routes.NewOrder)
+	acme.POST("/order/:order", routes.GetOrder)
+	acme.POST("/order/:order/finalize", routes.FinalizeOrder)
+	acme.POST("/order/:order/cert", routes.GetCertificate)
+	acme.POST("/authz/:order/:index", routes.GetAuthorization)
+	acme.POST("/authz/:order/:index/:type", routes.RespondChallenge)
+	acme.POST("/revoke-cert", routes.RevokeCertificate)
+
+	return acme
+}
@@ -2,42 +2,77 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 )
 
-func NewCAHTTPLayer(parentRouterGroup *gin.RouterGroup, svc services.CAService) {
-	routes := controllers.NewCAHttpRoutes(svc)
+func NewCAHTTPLayer(parentRouterGroup *gin.RouterGroup, svc services.CAService, csrLimits config.CSRLimits) {
+	routes := controllers.NewCAHttpRoutes(svc, csrLimits)
 
 	router := parentRouterGroup
+	router.GET("/.well-known/jwks.json", routes.GetJWKS)
+
 	rv1 := router.Group("/v1")
 
 	rv1.GET("/cas", routes.GetAllCAs)
 	rv1.POST("/cas", routes.CreateCA)
 	rv1.POST("/cas/import", routes.ImportCA)
+	rv1.GET("/cas/export", routes.ExportCAInventory)
 
 	rv1.GET("/cas/:id", routes.GetCAByID)
 	rv1.GET("/cas/cn/:cn", routes.GetCAsByCommonName)
 
 	rv1.PUT("/cas/:id/metadata", routes.UpdateCAMetadata)
+	rv1.PUT("/cas/:id/settings", routes.UpdateCASettings)
 	rv1.POST("/cas/:id/status", routes.UpdateCAStatus)
+	rv1.POST("/cas/:id/key/migrate", routes.MigrateCAKey)
+	rv1.PUT("/cas/:id/key/fallback", routes.SetCAFallbackEngine)
+	rv1.PUT("/cas/:id/issuance-webhook", routes.SetCAIssuanceWebhook)
+	rv1.GET("/cas/:id/key/backup", routes.ExportCAKey)
+	rv1.POST("/cas/:id/key/restore", routes.RestoreCAKey)
+	rv1.GET("/cas/:id/pending-action", routes.GetPendingCAAction)
+	rv1.POST("/cas/:id/pending-action/approve", routes.ApprovePendingCAAction)
 	rv1.GET("/cas/:id/certificates", routes.GetCertificatesByCA)
 	rv1.GET("/cas/:id/certificates/status/:status", routes.GetCertificatesByCAAndStatus)
 	rv1.POST("/cas/:id/certificates/sign", routes.SignCertificate)
+	rv1.POST("/cas/:id/certificates/sign-batch", routes.SignCertificatesBatch)
 	rv1.POST("/cas/:id/signature/sign", routes.SignatureSign)
 	rv1.POST("/cas/:id/signature/verify", routes.SignatureVerify)
+	rv1.GET("/cas/:id/signing-requests", routes.GetCASigningRequests)
+	rv1.POST("/cas/:id/signing-requests", routes.CreateCASigningRequest)
+	rv1.GET("/cas/:id/signing-requests/:reqid", routes.GetCASigningRequestByID)
+	rv1.POST("/cas/:id/signing-requests/:reqid/approve", routes.ApproveCASigningRequest)
+	rv1.POST("/cas/:id/signing-requests/:reqid/reject", routes.RejectCASigningRequest)
+	rv1.POST("/cas/:id/tokens/sign", routes.SignToken)
+	rv1.POST("/cas/:id/svids/sign", routes.SignSVID)
+	rv1.GET("/tokens/jwks", routes.GetTokenSigningKeys)
+	rv1.GET("/cas/:id/issuance-log/tree-head", routes.GetIssuanceLogTreeHead)
+	rv1.GET("/cas/:id/issuance-log/proofs/:sn", routes.GetIssuanceLogInclusionProof)
+	rv1.GET("/cas/:id/events", routes.GetCAEvents)
+	rv1.POST("/cas/:id/crls", routes.ReportCRLGeneration)
+	rv1.POST("/cas/:id/successor", routes.CreateSuccessorCA)
 	rv1.GET("/cas/:id/certificates/:sn", routes.GetCertificateBySerialNumber)
 	rv1.DELETE("/cas/:id", routes.DeleteCA)
 
 	rv1.GET("/certificates", routes.GetCertificates)
 	rv1.GET("/certificates/status/:status", routes.GetCertificatesByStatus)
 	rv1.GET("/certificates/expiration", routes.GetCertificatesByExpirationDate)
+	rv1.GET("/certificates/export", routes.ExportCertificates)
 	rv1.GET("/certificates/:sn", routes.GetCertificateBySerialNumber)
 	rv1.PUT("/certificates/:sn/status", routes.UpdateCertificateStatus)
 	rv1.PUT("/certificates/:sn/metadata", routes.UpdateCertificateMetadata)
 	rv1.POST("/certificates/import", routes.ImportCertificate)
 
+	rv1.GET("/profiles", routes.GetCertificateProfiles)
+	rv1.POST("/profiles", routes.CreateCertificateProfile)
+	rv1.GET("/profiles/:id", routes.GetCertificateProfileByID)
+	rv1.PUT("/profiles/:id", routes.UpdateCertificateProfile)
+	rv1.DELETE("/profiles/:id", routes.DeleteCertificateProfile)
+
 	rv1.GET("/engines", routes.GetCryptoEngineProvider)
 	rv1.GET("/stats", routes.GetStats)
 	rv1.GET("/stats/:id", routes.GetStatsByCAID)
+	rv1.GET("/reports/key-strength", routes.GetKeyStrengthReport)
+	rv1.GET("/reports/key-custody", routes.GetSoftwareKeyCustodyReport)
 }
@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+)
+
+func NewConnectorPendingEventsHTTPLayer(router *gin.RouterGroup, connectorID string, queue *connectors.RetryQueue) {
+	routes := controllers.NewConnectorPendingEventsHttpRoutes(connectorID, queue)
+
+	rv1 := router.Group("/v1")
+
+	rv1.GET("/connectors/:id/pending-events", routes.GetPendingEvents)
+	rv1.POST("/connectors/:id/pending-events/:eventId/replay", routes.ReplayPendingEvent)
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+func NewCRLHTTPLayer(parentRouterGroup *gin.RouterGroup, crl services.CRLService) {
+	routes := controllers.NewCRLHttpRoutes(crl)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/crl/:caID", routes.GetCRL)
+}
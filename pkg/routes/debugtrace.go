@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/debugtrace"
+)
+
+func NewDebugTraceHTTPLayer(parentRouterGroup *gin.RouterGroup, recorder *debugtrace.Recorder) {
+	routes := controllers.NewDebugTraceHttpRoutes(recorder)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/debug/traces/:reqid", routes.GetTrace)
+}
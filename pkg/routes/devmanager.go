@@ -11,12 +11,35 @@ func NewDeviceManagerHTTPLayer(router *gin.RouterGroup, svc services.DeviceManag
 
 	rv1 := router.Group("/v1")
 	rv1.GET("/stats", routes.GetStats)
+	rv1.GET("/reports/issuance", routes.GetIssuanceReport)
 	rv1.GET("/devices", routes.GetAllDevices)
 	rv1.POST("/devices", routes.CreateDevice)
 	rv1.GET("/devices/:id", routes.GetDeviceByID)
 	rv1.PUT("/devices/:id/idslot", routes.UpdateDeviceIdentitySlot)
 	rv1.PUT("/devices/:id/metadata", routes.UpdateDeviceMetadata)
+	rv1.PUT("/devices/:id/connection", routes.UpdateDeviceConnectionMetadata)
+	rv1.POST("/devices/:id/heartbeat", routes.ReportDeviceHeartbeat)
+	rv1.POST("/devices/:id/force-reenroll", routes.ForceDeviceReenroll)
+	rv1.GET("/devices/:id/logs", routes.GetDeviceLogs)
+	rv1.POST("/devices/:id/logs", routes.CreateDeviceLog)
+	rv1.PUT("/devices/:id/slots/:slot", routes.ProvisionDeviceSecretSlot)
+	rv1.POST("/devices/:id/slots/:slot/rotate", routes.RotateDeviceSecretSlot)
+	rv1.GET("/devices/:id/slots/:slot/secret", routes.GetDeviceSecretSlot)
 	rv1.DELETE("/devices/:id/decommission", routes.DecommissionDevice)
 	rv1.GET("/devices/dms/:id", routes.GetDevicesByDMS)
+	rv1.GET("/certificates/:sn/device", routes.GetDeviceByCertificate)
+	rv1.GET("/connectors/:id/devices/out-of-sync", routes.GetDevicesOutOfCloudSync)
+	rv1.GET("/groups", routes.GetDeviceGroups)
+	rv1.POST("/groups", routes.CreateDeviceGroup)
+	rv1.GET("/groups/:id", routes.GetDeviceGroupByID)
+	rv1.DELETE("/groups/:id", routes.DeleteDeviceGroup)
+	rv1.GET("/groups/:id/devices", routes.GetDeviceGroupDevices)
+	rv1.PUT("/groups/:id/devices", routes.UpdateDeviceGroupDevices)
+	rv1.PUT("/groups/:id/tags", routes.UpdateDeviceGroupTags)
+	rv1.PUT("/groups/:id/filters", routes.UpdateDeviceGroupFilters)
+	rv1.GET("/groups/:id/actions", routes.GetDeviceGroupBulkActions)
+	rv1.POST("/groups/:id/actions", routes.StartDeviceGroupBulkAction)
+	rv1.GET("/groups/:id/actions/:aid", routes.GetDeviceGroupBulkActionByID)
+	rv1.POST("/groups/:id/actions/:aid/approve", routes.ApproveDeviceGroupBulkAction)
 
 }
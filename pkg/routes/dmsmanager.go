@@ -4,14 +4,13 @@ import (
 	"github.com/gin-gonic/gin"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
-	"github.com/sirupsen/logrus"
 )
 
-func NewDMSManagerHTTPLayer(logger *logrus.Entry, httpGrp *gin.RouterGroup, svc services.DMSManagerService) {
+// NewDMSManagerHTTPLayer registers the management endpoints of the DMS Manager. The EST endpoints are registered in
+// the data-plane router with NewESTHttpRoutes.
+func NewDMSManagerHTTPLayer(httpGrp *gin.RouterGroup, svc services.DMSManagerService) {
 	routes := controllers.NewDMSManagerHttpRoutes(svc)
 
-	NewESTHttpRoutes(logger, httpGrp, svc)
-
 	rv1 := httpGrp.Group("/v1")
 
 	rv1.GET("/stats", routes.GetStats)
@@ -19,6 +18,38 @@ func NewDMSManagerHTTPLayer(logger *logrus.Entry, httpGrp *gin.RouterGroup, svc
 	rv1.POST("/dms", routes.CreateDMS)
 	rv1.GET("/dms/:id", routes.GetDMSByID)
 	rv1.PUT("/dms/:id", routes.UpdateDMS)
+	rv1.PUT("/dms/:id/status", routes.UpdateDMSStatus)
+	rv1.POST("/dms/:id/preregister", routes.PreregisterDevices)
+	rv1.GET("/dms/:id/bootstrap-tokens", routes.GetBootstrapTokens)
+	rv1.POST("/dms/:id/bootstrap-tokens", routes.CreateBootstrapToken)
+	rv1.POST("/dms/:id/bootstrap-tokens/:tid/revoke", routes.RevokeBootstrapToken)
+	rv1.GET("/dms/:id/claim-codes", routes.GetClaimCodes)
+	rv1.POST("/dms/:id/claim-codes", routes.CreateClaimCode)
+	rv1.POST("/dms/:id/claim-codes/:cid/revoke", routes.RevokeClaimCode)
+	rv1.GET("/dms/:id/enrollment-audits", routes.GetEnrollmentAudits)
+	rv1.GET("/dms/:id/stats/enrollments", routes.GetDMSEnrollmentStats)
 	rv1.POST("/dms/bind-identity", routes.BindIdentityToDevice)
+	rv1.POST("/dms/superseded/:sn/revoke", routes.RevokeSupersededCertificate)
+	rv1.POST("/dms/:id/certificates/:sn/revoke", routes.RevokeDeviceCertificate)
+	rv1.POST("/ca-rotations", routes.StartCARotation)
+	rv1.GET("/ca-rotations/:id", routes.GetCARotation)
+	rv1.POST("/ca-rotations/:id/batch", routes.ProcessCARotationBatch)
+	rv1.GET("/dms/:id/acme/eab-keys", routes.GetACMEEABKeys)
+	rv1.POST("/dms/:id/acme/eab-keys", routes.CreateACMEEABKey)
+	rv1.POST("/dms/:id/acme/eab-keys/:kid/revoke", routes.RevokeACMEEABKey)
+	rv1.GET("/enrollment-policies", routes.GetEnrollmentPolicies)
+	rv1.POST("/enrollment-policies", routes.CreateEnrollmentPolicy)
+	rv1.GET("/enrollment-policies/:id", routes.GetEnrollmentPolicyByID)
+	rv1.PUT("/enrollment-policies/:id", routes.UpdateEnrollmentPolicy)
+	rv1.DELETE("/enrollment-policies/:id", routes.DeleteEnrollmentPolicy)
+
+}
+
+// NewDeviceClaimHTTPLayer registers the public device claim endpoint in the data-plane router. Requests are
+// authenticated by the claim code they present.
+func NewDeviceClaimHTTPLayer(router *gin.RouterGroup, svc services.DMSManagerService) {
+	routes := controllers.NewDMSManagerHttpRoutes(svc)
 
+	rv1 := router.Group("/v1")
+	rv1.POST("/claim", routes.ClaimDevice)
 }
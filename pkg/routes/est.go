@@ -2,13 +2,14 @@ package routes
 
 import (
 	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	"github.com/sirupsen/logrus"
 )
 
-func NewESTHttpRoutes(logger *logrus.Entry, router *gin.RouterGroup, svc services.ESTService) *gin.RouterGroup {
-	routes := controllers.NewESTHttpRoutes(logger, svc)
+func NewESTHttpRoutes(logger *logrus.Entry, router *gin.RouterGroup, svc services.ESTService, csrLimits config.CSRLimits) *gin.RouterGroup {
+	routes := controllers.NewESTHttpRoutes(logger, svc, csrLimits)
 
 	est := router.Group("/.well-known/est")
 
@@ -21,8 +22,8 @@ func NewESTHttpRoutes(logger *logrus.Entry, router *gin.RouterGroup, svc service
 	est.POST("/simplereenroll", routes.EnrollReenroll)
 	est.POST("/:aps/simplereenroll", routes.EnrollReenroll)
 
-	// est.POST("/serverkeygen", routes.ServerKeygen)
-	// est.POST("/:aps/serverkeygen", routes.ServerKeygenWithAPS)
+	est.POST("/serverkeygen", routes.EnrollReenroll)
+	est.POST("/:aps/serverkeygen", routes.EnrollReenroll)
 
 	return est
 }
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/featureflags"
+)
+
+func NewFeatureFlagsHTTPLayer(parentRouterGroup *gin.RouterGroup, flags *featureflags.FeatureFlags) {
+	routes := controllers.NewFeatureFlagsHttpRoutes(flags)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/admin/feature-flags", routes.GetFeatureFlags)
+	rv1.PUT("/admin/feature-flags/:flag", routes.ToggleFeatureFlag)
+}
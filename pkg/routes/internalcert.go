@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+func NewInternalCertificateHTTPLayer(parentRouterGroup *gin.RouterGroup, svc services.InternalCertificateService) {
+	routes := controllers.NewInternalCertificateHttpRoutes(svc)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.POST("/internal/certificates", routes.IssueInternalCertificate)
+}
@@ -0,0 +1,15 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+)
+
+func NewLogLevelsHTTPLayer(parentRouterGroup *gin.RouterGroup, serviceID string) {
+	routes := controllers.NewLogLevelsHttpRoutes(serviceID)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/admin/log-levels", routes.GetLogLevels)
+	rv1.PUT("/admin/log-levels", routes.SetGlobalLogLevel)
+	rv1.PUT("/admin/log-levels/:subsystem", routes.SetSubsystemLogLevel)
+}
@@ -10,6 +10,8 @@ import (
 
 const CtxSource = "REQ_SOURCE"
 const CtxRequestID = "REQ_ID"
+const CtxClientIP = "REQ_CLIENT_IP"
+const CtxGatewayID = "REQ_GATEWAY_ID"
 
 func updateContextWithRequestWithRequestID(ctx *gin.Context, headers http.Header) {
 	reqID := headers.Get("x-request-id")
@@ -25,11 +27,20 @@ func updateContextWithRequestWithSource(ctx *gin.Context, headers http.Header) {
 	}
 }
 
+func updateContextWithRequestWithGatewayID(ctx *gin.Context, headers http.Header) {
+	gatewayID := headers.Get(models.HttpGatewayIDHeader)
+	if gatewayID != "" {
+		ctx.Set(CtxGatewayID, gatewayID)
+	}
+}
+
 func RequestMetadataToContextMiddleware(logger *logrus.Entry) gin.HandlerFunc {
 	return func(c *gin.Context) {
 
 		updateContextWithRequestWithRequestID(c, c.Request.Header)
 		updateContextWithRequestWithSource(c, c.Request.Header)
+		updateContextWithRequestWithGatewayID(c, c.Request.Header)
+		c.Set(CtxClientIP, c.ClientIP())
 		c.Next()
 	}
 }
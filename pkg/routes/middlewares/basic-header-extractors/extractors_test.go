@@ -13,10 +13,12 @@ func TestUpdateContextWithRequest(t *testing.T) {
 	headers := http.Header{}
 	headers.Set("x-request-id", "12345")
 	headers.Set("x-lms-source", "test-source")
+	headers.Set("x-lms-gateway-id", "gw-1")
 	headers.Set("x-ignored", "ignored")
 
 	updateContextWithRequestWithRequestID(&ctx, headers)
 	updateContextWithRequestWithSource(&ctx, headers)
+	updateContextWithRequestWithGatewayID(&ctx, headers)
 
 	// Verify that the request ID is correctly set in the context
 	reqID := ctx.Value(string(CtxRequestID))
@@ -30,6 +32,12 @@ func TestUpdateContextWithRequest(t *testing.T) {
 		t.Errorf("UpdateContextWithRequest did not set the correct source in the context. Expected: %s, Got: %v", "test-source", source)
 	}
 
+	// Verify that the gateway ID is correctly set in the context
+	gatewayID := ctx.Value(string(CtxGatewayID))
+	if gatewayID != "gw-1" {
+		t.Errorf("UpdateContextWithRequest did not set the correct gateway ID in the context. Expected: %s, Got: %v", "gw-1", gatewayID)
+	}
+
 	// Verify that the source is correctly set in the context
 	ignored := ctx.Value("x-ignored")
 	if ignored != nil {
@@ -0,0 +1,32 @@
+package identityextractors
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/gin-gonic/gin"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	IdentityExtractorBootstrapToken IdentityExtractor = "BOOTSTRAP_TOKEN"
+)
+
+// BootstrapTokenExtractor keeps the opaque bearer token of the request. It is only validated by the DMSs configured
+// with the BOOTSTRAP_TOKEN EST auth mode.
+type BootstrapTokenExtractor struct {
+	logger *logrus.Entry
+}
+
+func (extractor BootstrapTokenExtractor) ExtractAuthentication(ctx *gin.Context, req http.Request) {
+	header := req.Header.Get("authorization")
+
+	authToken := strings.Split(header, " ")
+	if len(authToken) != 2 || authToken[0] != "Bearer" || authToken[1] == "" {
+		return
+	}
+
+	extractor.logger.Tracef("found bearer token in request headers")
+
+	ctx.Set(string(IdentityExtractorBootstrapToken), authToken[1])
+}
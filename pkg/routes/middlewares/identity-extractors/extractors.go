@@ -12,6 +12,10 @@ import (
 const CtxAuthMode = "REQ_AUTH_MODE"
 const CtxAuthID = "REQ_AUTH_ID"
 
+// CtxAuthVerified is only set once an authentication middleware verified the identity in CtxAuthID (i.e. the
+// signature of the OIDC token). The extractors don't verify the tokens, so their identity can be forged.
+const CtxAuthVerified = "REQ_AUTH_VERIFIED"
+
 type IdentityExtractor string
 
 const (
@@ -31,6 +35,10 @@ func RequestMetadataToContextMiddleware(logger *logrus.Entry) gin.HandlerFunc {
 		JWTExtractor{
 			logger: logger,
 		},
+
+		BootstrapTokenExtractor{
+			logger: logger,
+		},
 	}
 
 	return func(c *gin.Context) {
@@ -77,3 +85,20 @@ func UpdateContextWithRequest(ctx *gin.Context, headers http.Header) {
 		ctx.Set(CtxAuthID, callerID)
 	}
 }
+
+// SetVerifiedIdentity replaces the identity of the request with the one verified by an authentication middleware.
+func SetVerifiedIdentity(ctx *gin.Context, authMode string, callerID string) {
+	ctx.Set(CtxAuthMode, authMode)
+	ctx.Set(CtxAuthID, callerID)
+	ctx.Set(CtxAuthVerified, true)
+}
+
+// VerifiedInternalService returns the identity of the internal service that sent the request, if an authentication
+// middleware verified its client certificate.
+func VerifiedInternalService(ctx *gin.Context) (string, bool) {
+	if !ctx.GetBool(CtxAuthVerified) || ctx.GetString(CtxAuthMode) != "crt" {
+		return "", false
+	}
+
+	return ctx.GetString(CtxAuthID), true
+}
@@ -0,0 +1,244 @@
+package oidcauth
+
+import (
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
+	"github.com/sirupsen/logrus"
+)
+
+// minKeysRefreshInterval limits how often the provider keys are fetched when a token is signed with an unknown key.
+const minKeysRefreshInterval = time.Minute
+
+type authenticator struct {
+	logger   *logrus.Entry
+	conf     config.HttpServerOIDCAuthentication
+	httpCli  *http.Client
+	mu       sync.Mutex
+	issuer   string
+	keys     jose.JSONWebKeySet
+	lastSync time.Time
+
+	// internalCA and internalServices identify the internal certificates accepted without token.
+	internalCA       *x509.Certificate
+	internalServices map[string]bool
+}
+
+// NewMiddleware rejects the requests without a valid bearer token issued by the OIDC provider. Requests presenting a
+// client certificate issued by the internal CA for one of the configured service identities are accepted without
+// token. The provider is contacted on the first request, so the service can start while the provider is not reachable.
+func NewMiddleware(logger *logrus.Entry, conf config.HttpServerOIDCAuthentication) (gin.HandlerFunc, error) {
+	if conf.OIDCWellKnownURL == "" {
+		return nil, fmt.Errorf("OIDC authentication requires the well-known URL of the provider")
+	}
+
+	httpCli, err := helpers.BuildHTTPClientWithTLSOptions(&http.Client{Timeout: 10 * time.Second}, conf.TLSConfig)
+	if err != nil {
+		return nil, err
+	}
+
+	auth := &authenticator{
+		logger:           logger,
+		conf:             conf,
+		httpCli:          httpCli,
+		internalServices: map[string]bool{},
+	}
+
+	if len(conf.InternalServices) > 0 {
+		if conf.InternalCACertificateFile == "" {
+			return nil, fmt.Errorf("OIDC authentication requires the internal CA certificate to accept internal services")
+		}
+
+		auth.internalCA, err = helpers.ReadCertificateFromFile(conf.InternalCACertificateFile)
+		if err != nil {
+			return nil, fmt.Errorf("could not read internal CA certificate: %w", err)
+		}
+
+		for _, identity := range conf.InternalServices {
+			auth.internalServices[identity] = true
+		}
+	}
+
+	return auth.handle, nil
+}
+
+func (a *authenticator) handle(ctx *gin.Context) {
+	if identity, ok := a.internalService(ctx.Request); ok {
+		identityextractors.SetVerifiedIdentity(ctx, "crt", identity)
+		ctx.Next()
+		return
+	}
+
+	header := ctx.GetHeader("authorization")
+	tokenString, found := strings.CutPrefix(header, "Bearer ")
+	if !found || tokenString == "" {
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"err": "missing bearer token"})
+		return
+	}
+
+	claims, err := a.verify(tokenString)
+	if err != nil {
+		a.logger.Debugf("rejecting request with invalid token: %s", err)
+		ctx.AbortWithStatusJSON(http.StatusUnauthorized, gin.H{"err": "invalid bearer token"})
+		return
+	}
+
+	if sub, ok := claims["sub"].(string); ok && sub != "" {
+		identityextractors.SetVerifiedIdentity(ctx, "jwt", sub)
+	}
+
+	ctx.Next()
+}
+
+// internalService returns the internal service identity of the request if it presents a client certificate issued by
+// the internal CA for one of them. Certificates verified by the server but issued by any other CA (e.g. device
+// certificates) must still present a token.
+func (a *authenticator) internalService(req *http.Request) (string, bool) {
+	if a.internalCA == nil || req.TLS == nil {
+		return "", false
+	}
+
+	for _, chain := range req.TLS.VerifiedChains {
+		if len(chain) != 2 || !chain[1].Equal(a.internalCA) {
+			continue
+		}
+
+		leaf := chain[0]
+		if !slices.Contains(leaf.ExtKeyUsage, x509.ExtKeyUsageClientAuth) {
+			continue
+		}
+
+		if a.internalServices[leaf.Subject.CommonName] {
+			return leaf.Subject.CommonName, true
+		}
+	}
+
+	return "", false
+}
+
+func (a *authenticator) verify(tokenString string) (jwt.MapClaims, error) {
+	token, err := jwt.Parse(tokenString, func(t *jwt.Token) (interface{}, error) {
+		switch t.Method.(type) {
+		case *jwt.SigningMethodRSA, *jwt.SigningMethodRSAPSS, *jwt.SigningMethodECDSA:
+		default:
+			return nil, fmt.Errorf("unsupported signing method %s", t.Method.Alg())
+		}
+
+		kid, _ := t.Header["kid"].(string)
+		return a.key(kid)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	claims, ok := token.Claims.(jwt.MapClaims)
+	if !ok {
+		return nil, fmt.Errorf("unexpected claims")
+	}
+
+	a.mu.Lock()
+	issuer := a.issuer
+	a.mu.Unlock()
+
+	if !claims.VerifyIssuer(issuer, true) {
+		return nil, fmt.Errorf("token not issued by %s", issuer)
+	}
+
+	if a.conf.Audience != "" && !claims.VerifyAudience(a.conf.Audience, true) {
+		return nil, fmt.Errorf("token not issued for audience %s", a.conf.Audience)
+	}
+
+	return claims, nil
+}
+
+// key returns the provider key with the ID, fetching the provider keys again if it is unknown. Tokens without key ID
+// are accepted if the provider has a single key.
+func (a *authenticator) key(kid string) (interface{}, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if key, ok := a.lookup(kid); ok {
+		return key, nil
+	}
+
+	if !a.lastSync.IsZero() && time.Since(a.lastSync) < minKeysRefreshInterval {
+		return nil, fmt.Errorf("unknown key %s", kid)
+	}
+
+	err := a.sync()
+	if err != nil {
+		a.logger.Errorf("could not fetch OIDC provider keys: %s", err)
+		return nil, err
+	}
+
+	if key, ok := a.lookup(kid); ok {
+		return key, nil
+	}
+
+	return nil, fmt.Errorf("unknown key %s", kid)
+}
+
+func (a *authenticator) lookup(kid string) (interface{}, bool) {
+	if kid == "" {
+		if len(a.keys.Keys) == 1 {
+			return a.keys.Keys[0].Key, true
+		}
+		return nil, false
+	}
+
+	keys := a.keys.Key(kid)
+	if len(keys) == 0 {
+		return nil, false
+	}
+
+	return keys[0].Key, true
+}
+
+func (a *authenticator) sync() error {
+	a.lastSync = time.Now()
+
+	var wellKnown struct {
+		Issuer  string `json:"issuer"`
+		JWKSURL string `json:"jwks_uri"`
+	}
+	err := a.getJSON(a.conf.OIDCWellKnownURL, &wellKnown)
+	if err != nil {
+		return fmt.Errorf("could not get OIDC discovery document: %w", err)
+	}
+
+	var keys jose.JSONWebKeySet
+	err = a.getJSON(wellKnown.JWKSURL, &keys)
+	if err != nil {
+		return fmt.Errorf("could not get OIDC provider keys: %w", err)
+	}
+
+	a.issuer = wellKnown.Issuer
+	a.keys = keys
+	return nil
+}
+
+func (a *authenticator) getJSON(url string, v any) error {
+	res, err := a.httpCli.Get(url)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+
+	if res.StatusCode != http.StatusOK {
+		return fmt.Errorf("unexpected status code %d", res.StatusCode)
+	}
+
+	return json.NewDecoder(res.Body).Decode(v)
+}
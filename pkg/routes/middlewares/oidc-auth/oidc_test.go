@@ -0,0 +1,328 @@
+package oidcauth
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/gin-gonic/gin"
+	"github.com/go-jose/go-jose/v3"
+	"github.com/golang-jwt/jwt"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
+	"github.com/sirupsen/logrus"
+)
+
+type testProvider struct {
+	server *httptest.Server
+	key    *ecdsa.PrivateKey
+}
+
+func newTestProvider(t *testing.T) *testProvider {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	provider := &testProvider{key: key}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(map[string]string{
+			"issuer":   provider.server.URL,
+			"jwks_uri": provider.server.URL + "/keys",
+		})
+	})
+	mux.HandleFunc("/keys", func(w http.ResponseWriter, r *http.Request) {
+		json.NewEncoder(w).Encode(jose.JSONWebKeySet{Keys: []jose.JSONWebKey{
+			{Key: &key.PublicKey, KeyID: "key-1", Algorithm: "ES256", Use: "sig"},
+		}})
+	})
+
+	provider.server = httptest.NewServer(mux)
+	t.Cleanup(provider.server.Close)
+
+	return provider
+}
+
+func (p *testProvider) token(kid string, claims jwt.MapClaims) string {
+	token := jwt.NewWithClaims(jwt.SigningMethodES256, claims)
+	token.Header["kid"] = kid
+	signed, _ := token.SignedString(p.key)
+	return signed
+}
+
+func newTestRouter(t *testing.T, conf config.HttpServerOIDCAuthentication) *gin.Engine {
+	middleware, err := NewMiddleware(logrus.NewEntry(logrus.StandardLogger()), conf)
+	if err != nil {
+		t.Fatalf("could not create middleware: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	router.Use(middleware)
+	router.GET("/v1/cas", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "%s verified=%t", ctx.GetString(identityextractors.CtxAuthID), ctx.GetBool(identityextractors.CtxAuthVerified))
+	})
+
+	return router
+}
+
+func TestMiddleware(t *testing.T) {
+	provider := newTestProvider(t)
+	router := newTestRouter(t, config.HttpServerOIDCAuthentication{
+		Enabled:          true,
+		OIDCWellKnownURL: provider.server.URL + "/.well-known/openid-configuration",
+		Audience:         "lamassu",
+	})
+
+	validClaims := func() jwt.MapClaims {
+		return jwt.MapClaims{
+			"iss": provider.server.URL,
+			"aud": "lamassu",
+			"sub": "operator",
+			"exp": time.Now().Add(time.Hour).Unix(),
+		}
+	}
+
+	otherKey, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	forged := jwt.NewWithClaims(jwt.SigningMethodES256, validClaims())
+	forged.Header["kid"] = "key-1"
+	forgedToken, _ := forged.SignedString(otherKey)
+
+	var testcases = []struct {
+		name           string
+		authorization  string
+		expectedStatus int
+	}{
+		{
+			name:           "OK",
+			authorization:  "Bearer " + provider.token("key-1", validClaims()),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Err/MissingToken",
+			authorization:  "",
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Err/InvalidSignature",
+			authorization:  "Bearer " + forgedToken,
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Err/UnknownKey",
+			authorization:  "Bearer " + provider.token("key-2", validClaims()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Err/Expired",
+			authorization: "Bearer " + provider.token("key-1", func() jwt.MapClaims {
+				claims := validClaims()
+				claims["exp"] = time.Now().Add(-time.Minute).Unix()
+				return claims
+			}()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Err/OtherIssuer",
+			authorization: "Bearer " + provider.token("key-1", func() jwt.MapClaims {
+				claims := validClaims()
+				claims["iss"] = "https://other.idp"
+				return claims
+			}()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name: "Err/OtherAudience",
+			authorization: "Bearer " + provider.token("key-1", func() jwt.MapClaims {
+				claims := validClaims()
+				claims["aud"] = "other"
+				return claims
+			}()),
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cas", nil)
+			if tc.authorization != "" {
+				req.Header.Set("Authorization", tc.authorization)
+			}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			if res.Code != tc.expectedStatus {
+				t.Fatalf("unexpected status code. Expected %d, got %d: %s", tc.expectedStatus, res.Code, res.Body.String())
+			}
+		})
+	}
+}
+
+func newTestIssuer(t *testing.T, cn string) *helpers.InternalCertificateIssuer {
+	caCert, caKey, err := helpers.GenerateSelfSignedCA(x509.ECDSA, time.Hour, cn)
+	if err != nil {
+		t.Fatalf("could not generate CA: %s", err)
+	}
+
+	issuer, err := helpers.NewInternalCertificateIssuer(caCert, caKey.(*ecdsa.PrivateKey), time.Hour)
+	if err != nil {
+		t.Fatalf("could not create issuer: %s", err)
+	}
+
+	return issuer
+}
+
+func issueTestChain(t *testing.T, issuer *helpers.InternalCertificateIssuer, identity string) []*x509.Certificate {
+	key, _ := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: identity}, key)
+
+	crt, err := issuer.Issue(csr, identity)
+	if err != nil {
+		t.Fatalf("could not issue certificate: %s", err)
+	}
+
+	return []*x509.Certificate{crt, issuer.CACertificate()}
+}
+
+func TestMiddlewareInternalCertificates(t *testing.T) {
+	provider := newTestProvider(t)
+	internal := newTestIssuer(t, "internal-ca")
+	other := newTestIssuer(t, "device-ca")
+
+	caFile := filepath.Join(t.TempDir(), "internal-ca.crt")
+	os.WriteFile(caFile, []byte(helpers.CertificateToPEM(internal.CACertificate())), 0600)
+
+	router := newTestRouter(t, config.HttpServerOIDCAuthentication{
+		Enabled:                   true,
+		OIDCWellKnownURL:          provider.server.URL + "/.well-known/openid-configuration",
+		InternalCACertificateFile: caFile,
+		InternalServices:          []string{"dms-manager"},
+	})
+
+	var testcases = []struct {
+		name           string
+		chain          []*x509.Certificate
+		expectedStatus int
+	}{
+		{
+			name:           "OK/InternalService",
+			chain:          issueTestChain(t, internal, "dms-manager"),
+			expectedStatus: http.StatusOK,
+		},
+		{
+			name:           "Err/UnknownIdentity",
+			chain:          issueTestChain(t, internal, "operator"),
+			expectedStatus: http.StatusUnauthorized,
+		},
+		{
+			name:           "Err/OtherCA",
+			chain:          issueTestChain(t, other, "dms-manager"),
+			expectedStatus: http.StatusUnauthorized,
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodGet, "/v1/cas", nil)
+			req.TLS = &tls.ConnectionState{VerifiedChains: [][]*x509.Certificate{tc.chain}}
+
+			res := httptest.NewRecorder()
+			router.ServeHTTP(res, req)
+
+			if res.Code != tc.expectedStatus {
+				t.Fatalf("unexpected status code. Expected %d, got %d: %s", tc.expectedStatus, res.Code, res.Body.String())
+			}
+		})
+	}
+}
+
+func TestMiddlewareVerifiedIdentity(t *testing.T) {
+	provider := newTestProvider(t)
+	conf := config.HttpServerOIDCAuthentication{
+		Enabled:          true,
+		OIDCWellKnownURL: provider.server.URL + "/.well-known/openid-configuration",
+	}
+
+	middleware, err := NewMiddleware(logrus.NewEntry(logrus.StandardLogger()), conf)
+	if err != nil {
+		t.Fatalf("could not create middleware: %s", err)
+	}
+
+	gin.SetMode(gin.TestMode)
+	router := gin.New()
+	// The identity extractors run first and take the subject of the token without verifying it.
+	router.Use(identityextractors.RequestMetadataToContextMiddleware(logrus.NewEntry(logrus.StandardLogger())), middleware)
+	router.GET("/v1/cas", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "%s verified=%t", ctx.GetString(identityextractors.CtxAuthID), ctx.GetBool(identityextractors.CtxAuthVerified))
+	})
+
+	token := provider.token("key-1", jwt.MapClaims{"iss": provider.server.URL, "sub": "operator", "exp": time.Now().Add(time.Hour).Unix()})
+	req := httptest.NewRequest(http.MethodGet, "/v1/cas", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusOK || res.Body.String() != "operator verified=true" {
+		t.Fatalf("expected the verified identity of the token, got %d: %s", res.Code, res.Body.String())
+	}
+
+	unverified := gin.New()
+	unverified.Use(identityextractors.RequestMetadataToContextMiddleware(logrus.NewEntry(logrus.StandardLogger())))
+	unverified.GET("/v1/cas", func(ctx *gin.Context) {
+		ctx.String(http.StatusOK, "%s verified=%t", ctx.GetString(identityextractors.CtxAuthID), ctx.GetBool(identityextractors.CtxAuthVerified))
+	})
+
+	res = httptest.NewRecorder()
+	unverified.ServeHTTP(res, req)
+
+	if res.Body.String() != "operator verified=false" {
+		t.Fatalf("identities taken from unverified tokens must not be marked as verified, got %s", res.Body.String())
+	}
+}
+
+func TestMiddlewareProviderUnreachable(t *testing.T) {
+	provider := newTestProvider(t)
+	token := provider.token("key-1", jwt.MapClaims{"iss": provider.server.URL, "exp": time.Now().Add(time.Hour).Unix()})
+
+	router := newTestRouter(t, config.HttpServerOIDCAuthentication{
+		Enabled:          true,
+		OIDCWellKnownURL: "http://127.0.0.1:1/.well-known/openid-configuration",
+	})
+
+	req := httptest.NewRequest(http.MethodGet, "/v1/cas", nil)
+	req.Header.Set("Authorization", "Bearer "+token)
+
+	res := httptest.NewRecorder()
+	router.ServeHTTP(res, req)
+
+	if res.Code != http.StatusUnauthorized {
+		t.Fatalf("unexpected status code. Expected %d, got %d", http.StatusUnauthorized, res.Code)
+	}
+}
+
+func TestNewMiddlewareWithoutWellKnown(t *testing.T) {
+	_, err := NewMiddleware(logrus.NewEntry(logrus.StandardLogger()), config.HttpServerOIDCAuthentication{Enabled: true})
+	if err == nil {
+		t.Fatalf("expected error without well-known URL")
+	}
+
+	_, err = NewMiddleware(logrus.NewEntry(logrus.StandardLogger()), config.HttpServerOIDCAuthentication{
+		Enabled:          true,
+		OIDCWellKnownURL: "http://127.0.0.1:1/.well-known/openid-configuration",
+		InternalServices: []string{"dms-manager"},
+	})
+	if err == nil {
+		t.Fatalf("expected error with internal services without internal CA")
+	}
+}
@@ -0,0 +1,23 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+)
+
+func NewCAMonitoringHTTPLayer(parentRouterGroup *gin.RouterGroup, svc services.CAService, conf config.CAConfig) {
+	routes := controllers.NewCAMonitoringHttpRoutes(svc, conf)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/monitoring/rules", routes.GetAlertingRules)
+	rv1.GET("/monitoring/metrics", routes.GetMetrics)
+}
+
+func NewDMSManagerMonitoringHTTPLayer(parentRouterGroup *gin.RouterGroup, svc services.DMSManagerService) {
+	routes := controllers.NewDMSManagerMonitoringHttpRoutes(svc)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/monitoring/metrics", routes.GetMetrics)
+}
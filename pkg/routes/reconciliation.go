@@ -0,0 +1,16 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/connectors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+)
+
+func NewReconciliationHTTPLayer(router *gin.RouterGroup, reconciler *connectors.CertificateStatusReconciler) {
+	routes := controllers.NewReconciliationHttpRoutes(reconciler)
+
+	rv1 := router.Group("/v1")
+
+	rv1.GET("/reconciliation/certificates", routes.GetLastCertificateReconciliation)
+	rv1.POST("/reconciliation/certificates", routes.ReconcileCertificates)
+}
@@ -0,0 +1,87 @@
+package routes
+
+import (
+	"fmt"
+	"net"
+
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	oidcauth "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/oidc-auth"
+	"github.com/sirupsen/logrus"
+)
+
+// HttpRouters splits the endpoints of a service between the management router, used by the operators, and the
+// data-plane router, used by the devices. The management authentication is only applied to the management router.
+// Both routers share the same engine unless the server configures a data-plane listener.
+type HttpRouters struct {
+	Management *gin.RouterGroup
+	DataPlane  *gin.RouterGroup
+
+	managementEngine *gin.Engine
+	dataPlaneEngine  *gin.Engine
+	healthDetails    controllers.HealthDetails
+}
+
+func NewHttpRouters(logger *logrus.Entry, httpServerCfg config.HttpServer) (*HttpRouters, error) {
+	managementEngine := NewGinEngine(logger)
+
+	managementHandlers := []gin.HandlerFunc{}
+	if httpServerCfg.Authentication.OIDC.Enabled {
+		oidcMiddleware, err := oidcauth.NewMiddleware(logger, httpServerCfg.Authentication.OIDC)
+		if err != nil {
+			return nil, fmt.Errorf("could not create OIDC authentication: %w", err)
+		}
+
+		managementHandlers = append(managementHandlers, oidcMiddleware)
+	}
+
+	routers := &HttpRouters{
+		Management:       managementEngine.Group("/", managementHandlers...),
+		managementEngine: managementEngine,
+	}
+
+	if httpServerCfg.DataPlane != nil {
+		routers.dataPlaneEngine = NewGinEngine(logger)
+		routers.DataPlane = routers.dataPlaneEngine.Group("/")
+	} else {
+		routers.DataPlane = managementEngine.Group("/")
+	}
+
+	return routers, nil
+}
+
+// Use adds the middlewares to both routers.
+func (r *HttpRouters) Use(middlewares ...gin.HandlerFunc) {
+	r.Management.Use(middlewares...)
+	r.DataPlane.Use(middlewares...)
+}
+
+// AddHealthDetails adds the value returned by details to the health check response under name.
+func (r *HttpRouters) AddHealthDetails(name string, details func() interface{}) {
+	if r.healthDetails == nil {
+		r.healthDetails = controllers.HealthDetails{}
+	}
+
+	r.healthDetails[name] = details
+}
+
+// RunHttpRouters runs the management router with RunHttpRouter and, if configured, the data-plane listener. The
+// returned port is the one of the management router main listener.
+func RunHttpRouters(logger *logrus.Entry, routers *HttpRouters, httpServerCfg config.HttpServer, apiInfo models.APIServiceInfo) (int, error) {
+	port, err := runHttpRouter(logger, routers.managementEngine, httpServerCfg, apiInfo, routers.healthDetails)
+	if err != nil {
+		return -1, err
+	}
+
+	if routers.dataPlaneEngine != nil {
+		listenerCfg := inheritServerTLS(*httpServerCfg.DataPlane, httpServerCfg)
+		_, err := runHttpListener(logger, newServiceMux(logger, routers.dataPlaneEngine, httpServerCfg, apiInfo, routers.healthDetails), listenerCfg)
+		if err != nil {
+			return -1, fmt.Errorf("could not start data-plane listener on %s: %w", net.JoinHostPort(listenerCfg.ListenAddress, fmt.Sprint(listenerCfg.Port)), err)
+		}
+	}
+
+	return port, nil
+}
@@ -0,0 +1,14 @@
+package routes
+
+import (
+	"github.com/gin-gonic/gin"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/controllers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/health"
+)
+
+func NewStatusHTTPLayer(parentRouterGroup *gin.RouterGroup, monitor *health.Monitor) {
+	routes := controllers.NewStatusHttpRoutes(monitor)
+
+	rv1 := parentRouterGroup.Group("/v1")
+	rv1.GET("/status", routes.GetStatus)
+}
@@ -9,6 +9,7 @@ import (
 	"io"
 	"net"
 	"net/http"
+	"slices"
 	"strings"
 	"sync"
 	"time"
@@ -51,7 +52,39 @@ func NewGinEngine(logger *logrus.Entry) *gin.Engine {
 }
 
 func RunHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCfg config.HttpServer, apiInfo models.APIServiceInfo) (int, error) {
-	hCheckRoute := controllers.NewHealthCheckRoute(apiInfo)
+	return runHttpRouter(logger, routerEngine, httpServerCfg, apiInfo, nil)
+}
+
+func runHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCfg config.HttpServer, apiInfo models.APIServiceInfo, healthDetails controllers.HealthDetails) (int, error) {
+	mainEngine := newServiceMux(logger, routerEngine, httpServerCfg, apiInfo, healthDetails)
+
+	usedPort, err := runHttpListener(logger, mainEngine, config.HttpListener{
+		ListenAddress:  httpServerCfg.ListenAddress,
+		Port:           httpServerCfg.Port,
+		Protocol:       httpServerCfg.Protocol,
+		CertFile:       httpServerCfg.CertFile,
+		KeyFile:        httpServerCfg.KeyFile,
+		Authentication: httpServerCfg.Authentication,
+		APIs:           httpServerCfg.APIs,
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	for _, listenerCfg := range httpServerCfg.Listeners {
+		listenerCfg = inheritServerTLS(listenerCfg, httpServerCfg)
+		_, err := runHttpListener(logger, mainEngine, listenerCfg)
+		if err != nil {
+			return -1, fmt.Errorf("could not start listener on %s: %w", net.JoinHostPort(listenerCfg.ListenAddress, fmt.Sprint(listenerCfg.Port)), err)
+		}
+	}
+
+	return usedPort, nil
+}
+
+// newServiceMux serves the health endpoint next to the service router.
+func newServiceMux(logger *logrus.Entry, routerEngine http.Handler, httpServerCfg config.HttpServer, apiInfo models.APIServiceInfo, healthDetails controllers.HealthDetails) *http.ServeMux {
+	hCheckRoute := controllers.NewHealthCheckRoute(apiInfo, healthDetails)
 	mainLogger := logger
 	if !httpServerCfg.HealthCheckLogging {
 		nooutLogger := logrus.New()
@@ -63,26 +96,80 @@ func RunHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCf
 	healthEngine := NewGinEngine(mainLogger)
 	healthEngine.GET("/health", hCheckRoute.HealthCheck)
 
-	mainEngine := http.NewServeMux()
-	mainEngine.Handle("/", routerEngine)
-	mainEngine.Handle("/health", healthEngine)
+	mux := http.NewServeMux()
+	mux.Handle("/", routerEngine)
+	mux.Handle("/health", healthEngine)
+
+	return mux
+}
+
+// inheritServerTLS copies the protocol, certificates and authentication of the server into listeners without protocol.
+func inheritServerTLS(listenerCfg config.HttpListener, httpServerCfg config.HttpServer) config.HttpListener {
+	if listenerCfg.Protocol == "" {
+		listenerCfg.Protocol = httpServerCfg.Protocol
+		listenerCfg.CertFile = httpServerCfg.CertFile
+		listenerCfg.KeyFile = httpServerCfg.KeyFile
+		listenerCfg.Authentication = httpServerCfg.Authentication
+	}
+
+	return listenerCfg
+}
+
+// apiPathPrefixes maps the APIs that can be served on dedicated listeners to their paths. The management API
+// includes any other path.
+var apiPathPrefixes = map[config.ServerAPI]string{
+	config.ESTAPI:  "/.well-known/est",
+	config.ACMEAPI: "/v1/acme/",
+}
 
-	addr := fmt.Sprintf("%s:%d", httpServerCfg.ListenAddress, httpServerCfg.Port)
+func requestAPI(path string) config.ServerAPI {
+	for api, prefix := range apiPathPrefixes {
+		if strings.HasPrefix(path, prefix) {
+			return api
+		}
+	}
+
+	return config.ManagementAPI
+}
+
+// apiFilter only serves the requests of the listener APIs. The health endpoint is served by all the listeners.
+func apiFilter(handler http.Handler, apis []config.ServerAPI) http.Handler {
+	if len(apis) == 0 {
+		return handler
+	}
 
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/health" && !slices.Contains(apis, requestAPI(r.URL.Path)) {
+			http.NotFound(w, r)
+			return
+		}
+
+		handler.ServeHTTP(w, r)
+	})
+}
+
+func runHttpListener(logger *logrus.Entry, handler http.Handler, listenerCfg config.HttpListener) (int, error) {
 	t := time.Second * 10
 	server := http.Server{
-		Addr:         addr,
-		Handler:      mainEngine,
+		Handler:      apiFilter(handler, listenerCfg.APIs),
 		ReadTimeout:  t,
 		WriteTimeout: t,
 	}
 
-	listener, err := net.Listen("tcp", fmt.Sprintf("%s:%d", httpServerCfg.ListenAddress, httpServerCfg.Port))
+	// brackets are optional for IPv6 addresses
+	addr := net.JoinHostPort(strings.Trim(listenerCfg.ListenAddress, "[]"), fmt.Sprint(listenerCfg.Port))
+	listener, err := net.Listen("tcp", addr)
 	if err != nil {
 		return -1, err
 	}
 
 	usedPort := listener.Addr().(*net.TCPAddr).Port
+	addr = listener.Addr().String()
+
+	apisLog := ""
+	if len(listenerCfg.APIs) > 0 {
+		apisLog = fmt.Sprintf(" serving %v APIs", listenerCfg.APIs)
+	}
 
 	wg := new(sync.WaitGroup)
 	wg.Add(1) // add `1` goroutines to finish
@@ -92,19 +179,15 @@ func RunHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCf
 
 	httpErrChan := make(chan error, 1)
 
-	if strings.HasSuffix(addr, ":0") {
-		addr = strings.ReplaceAll(addr, ":0", "")
-	}
-
 	go func() {
-		if httpServerCfg.Protocol == config.HTTPS {
+		if listenerCfg.Protocol == config.HTTPS {
 			srvExtraLog := ""
-			if httpServerCfg.Authentication.MutualTLS.Enabled {
+			if listenerCfg.Authentication.MutualTLS.Enabled {
 				srvExtraLog = "with mTLS enabled"
 
 				valCAPool := x509.NewCertPool()
 
-				vaCert, err := helpers.ReadCertificateFromFile(httpServerCfg.Authentication.MutualTLS.CACertificateFile)
+				vaCert, err := helpers.ReadCertificateFromFile(listenerCfg.Authentication.MutualTLS.CACertificateFile)
 				if err != nil {
 					logger.Warnf("could not load CA cert used while validating mTLS requests: %s", err)
 				} else {
@@ -112,16 +195,16 @@ func RunHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCf
 				}
 
 				var clientAuth tls.ClientAuthType
-				if httpServerCfg.Authentication.MutualTLS.ValidationMode == config.Any {
+				if listenerCfg.Authentication.MutualTLS.ValidationMode == config.Any {
 					clientAuth = tls.RequireAnyClientCert
 					srvExtraLog = srvExtraLog + " using 'any' validation mode (at least one client certificate MUST be sent but wont be validated)"
-				} else if httpServerCfg.Authentication.MutualTLS.ValidationMode == config.Strict {
+				} else if listenerCfg.Authentication.MutualTLS.ValidationMode == config.Strict {
 					clientAuth = tls.RequireAndVerifyClientCert
 					srvExtraLog = srvExtraLog + " using 'strict' validation mode"
-				} else if httpServerCfg.Authentication.MutualTLS.ValidationMode == config.Request {
+				} else if listenerCfg.Authentication.MutualTLS.ValidationMode == config.Request {
 					clientAuth = tls.RequestClientCert
 					srvExtraLog = srvExtraLog + " using 'request' validation mode (client certificate will be request although not mandatory to be sent. Behaves like optional mTLS)"
-				} else if httpServerCfg.Authentication.MutualTLS.ValidationMode == "" {
+				} else if listenerCfg.Authentication.MutualTLS.ValidationMode == "" {
 					logger.Warnf("mutual TLS validation mode is empty. Defaulting to 'strict' validation")
 					srvExtraLog = srvExtraLog + " using 'strict' validation mode"
 					clientAuth = tls.RequireAndVerifyClientCert
@@ -131,7 +214,7 @@ func RunHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCf
 					srvExtraLog = srvExtraLog + " using 'strict' validation mode"
 				}
 
-				if clientAuth == tls.RequireAndVerifyClientCert {
+				if clientAuth == tls.RequireAndVerifyClientCert && vaCert != nil {
 					logger.Debugf("mTLS requests will be accepted when client presents a certificate issued by CA with subject '%s'", vaCert.Subject.String())
 				}
 
@@ -142,15 +225,15 @@ func RunHttpRouter(logger *logrus.Entry, routerEngine http.Handler, httpServerCf
 				}
 			}
 
-			logger.Infof("HTTPS server listening on %s:%d %s", addr, usedPort, srvExtraLog)
+			logger.Infof("HTTPS server listening on %s%s %s", addr, apisLog, srvExtraLog)
 			startLaunching()
-			err := server.ServeTLS(listener, httpServerCfg.CertFile, httpServerCfg.KeyFile)
+			err := server.ServeTLS(listener, listenerCfg.CertFile, listenerCfg.KeyFile)
 			if err != nil {
 				logger.Errorf("could not start http server: %s", err)
 				httpErrChan <- err
 			}
 		} else {
-			logger.Infof("HTTP server listening on %s:%d", addr, usedPort)
+			logger.Infof("HTTP server listening on %s%s", addr, apisLog)
 			startLaunching()
 			err := server.Serve(listener)
 			if err != nil {
@@ -0,0 +1,1103 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/go-jose/go-jose/v3"
+	"github.com/go-playground/validator/v10"
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+const acmeNonceValidity = time.Hour
+
+var acmeValidate = validator.New()
+
+// ACMEService implements the RFC 8555 server side of the ACME protocol for the DMSs using the ACME enrollment
+// protocol. Certificates are signed by the DMS enrollment CA through the CA service.
+type ACMEService interface {
+	NewNonce(ctx context.Context) (string, error)
+	GetDirectoryMeta(ctx context.Context, input GetACMEDirectoryMetaInput) (*ACMEDirectoryMeta, error)
+	AuthenticateRequest(ctx context.Context, input AuthenticateACMERequestInput) (*ACMERequest, error)
+
+	NewAccount(ctx context.Context, input NewACMEAccountInput) (*models.ACMEAccount, bool, error)
+	UpdateAccount(ctx context.Context, input UpdateACMEAccountInput) (*models.ACMEAccount, error)
+	GetAccountOrders(ctx context.Context, input GetACMEAccountOrdersInput) ([]string, error)
+
+	NewOrder(ctx context.Context, input NewACMEOrderInput) (*models.ACMEOrder, error)
+	GetOrder(ctx context.Context, input GetACMEOrderInput) (*models.ACMEOrder, error)
+	GetAuthorization(ctx context.Context, input GetACMEAuthorizationInput) (*models.ACMEAuthorization, error)
+	RespondChallenge(ctx context.Context, input RespondACMEChallengeInput) (*models.ACMEChallenge, error)
+	FinalizeOrder(ctx context.Context, input FinalizeACMEOrderInput) (*models.ACMEOrder, error)
+	GetCertificateChain(ctx context.Context, input GetACMEOrderInput) ([]*x509.Certificate, error)
+	RevokeCertificate(ctx context.Context, input RevokeACMECertificateInput) error
+}
+
+type ACMEServiceBackend struct {
+	accountStorage storage.ACMEAccountsRepo
+	orderStorage   storage.ACMEOrdersRepo
+	dmsClient      DMSManagerService
+	caClient       CAService
+	eabSecret      []byte
+	orderValidity  time.Duration
+	http01Client   *http.Client
+	http01Port     int
+	dnsResolver    *net.Resolver
+	nonces         *acmeNonces
+	logger         *logrus.Entry
+}
+
+type ACMEServiceBuilder struct {
+	Logger         *logrus.Entry
+	AccountStorage storage.ACMEAccountsRepo
+	OrderStorage   storage.ACMEOrdersRepo
+	DMSClient      DMSManagerService
+	CAClient       CAService
+	// EABSecret is the DMS Manager secret the EAB HMAC keys are derived from. If set, accounts can only be
+	// registered with an external account binding.
+	EABSecret []byte
+	// OrderValidity is the time clients have to complete the challenges of an order. Defaults to 7 days.
+	OrderValidity time.Duration
+	// HTTP01Port is the port the http-01 challenges are fetched from. Defaults to 80.
+	HTTP01Port int
+	// DNSResolver resolves the dns-01 challenges. Defaults to the system resolver.
+	DNSResolver *net.Resolver
+}
+
+func NewACMEService(builder ACMEServiceBuilder) ACMEService {
+	orderValidity := builder.OrderValidity
+	if orderValidity == 0 {
+		orderValidity = 7 * 24 * time.Hour
+	}
+
+	http01Port := builder.HTTP01Port
+	if http01Port == 0 {
+		http01Port = 80
+	}
+
+	resolver := builder.DNSResolver
+	if resolver == nil {
+		resolver = net.DefaultResolver
+	}
+
+	return &ACMEServiceBackend{
+		accountStorage: builder.AccountStorage,
+		orderStorage:   builder.OrderStorage,
+		dmsClient:      builder.DMSClient,
+		caClient:       builder.CAClient,
+		eabSecret:      builder.EABSecret,
+		orderValidity:  orderValidity,
+		http01Client: &http.Client{
+			Timeout: 10 * time.Second,
+			// Redirects are followed as allowed by RFC 8555 8.3, but only to plain HTTP or HTTPS ports.
+			CheckRedirect: func(req *http.Request, via []*http.Request) error {
+				if len(via) >= 10 {
+					return fmt.Errorf("too many redirects")
+				}
+				return nil
+			},
+		},
+		http01Port:  http01Port,
+		dnsResolver: resolver,
+		nonces:      &acmeNonces{nonces: map[string]time.Time{}},
+		logger:      builder.Logger,
+	}
+}
+
+// acmeNonces keeps the issued anti-replay nonces in memory. Clients retry the requests rejected with badNonce
+// errors using the nonce of the error response, so nonces issued by another replica only cost a retry.
+type acmeNonces struct {
+	mu        sync.Mutex
+	nonces    map[string]time.Time
+	lastSweep time.Time
+}
+
+func (n *acmeNonces) issue() (string, error) {
+	raw := make([]byte, 16)
+	if _, err := rand.Read(raw); err != nil {
+		return "", err
+	}
+	nonce := base64.RawURLEncoding.EncodeToString(raw)
+
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	now := time.Now()
+	if now.Sub(n.lastSweep) > acmeNonceValidity {
+		for issued, expiresAt := range n.nonces {
+			if now.After(expiresAt) {
+				delete(n.nonces, issued)
+			}
+		}
+		n.lastSweep = now
+	}
+
+	n.nonces[nonce] = now.Add(acmeNonceValidity)
+	return nonce, nil
+}
+
+func (n *acmeNonces) consume(nonce string) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	expiresAt, ok := n.nonces[nonce]
+	delete(n.nonces, nonce)
+	return ok && time.Now().Before(expiresAt)
+}
+
+func (svc *ACMEServiceBackend) NewNonce(ctx context.Context) (string, error) {
+	return svc.nonces.issue()
+}
+
+type ACMEDirectoryMeta struct {
+	ExternalAccountRequired bool
+}
+
+type GetACMEDirectoryMetaInput struct {
+	DMSID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found.
+//   - ErrDMSACMENotEnabled
+//     The DMS does not use the ACME enrollment protocol.
+func (svc *ACMEServiceBackend) GetDirectoryMeta(ctx context.Context, input GetACMEDirectoryMetaInput) (*ACMEDirectoryMeta, error) {
+	_, err := svc.getDMS(ctx, input.DMSID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ACMEDirectoryMeta{
+		ExternalAccountRequired: len(svc.eabSecret) > 0,
+	}, nil
+}
+
+// ACMERequest is an authenticated ACME request. Account is set for the requests signed with the key of an
+// existing account (kid), while Key is set for the requests signed with the embedded key (jwk).
+type ACMERequest struct {
+	DMSID   string
+	URL     string
+	Payload []byte
+	Account *models.ACMEAccount
+	Key     *jose.JSONWebKey
+}
+
+type AuthenticateACMERequestInput struct {
+	DMSID string `validate:"required"`
+	// URL is the URL the request was sent to. It must match the url header of the JWS.
+	URL string `validate:"required"`
+	// AccountURLPrefix is the URL of the accounts of the DMS directory, without the account ID.
+	AccountURLPrefix string `validate:"required"`
+	Body             []byte
+	// AllowEmbeddedKey accepts requests signed with the key embedded in the JWS (new account and revocation requests).
+	AllowEmbeddedKey bool
+}
+
+// AuthenticateRequest verifies the JWS of an ACME request (RFC 8555 6.2), consuming its nonce.
+//
+// Returned Error Codes:
+//   - ErrACMEMalformed
+//     The request is not a valid flattened JWS or its headers are not valid.
+//   - ErrACMEBadNonce
+//     The nonce was not issued by the server or has been used.
+//   - ErrACMEBadSignatureAlgorithm
+//     The JWS is MAC protected or unsigned.
+//   - ErrACMEAccountDoesNotExist
+//     The request references an account that does not exist.
+//   - ErrACMEUnauthorized
+//     The JWS signature is not valid or the account has been deactivated.
+func (svc *ACMEServiceBackend) AuthenticateRequest(ctx context.Context, input AuthenticateACMERequestInput) (*ACMERequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := acmeValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("AuthenticateACMERequestInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, err = svc.getDMS(ctx, input.DMSID)
+	if err != nil {
+		return nil, err
+	}
+
+	jws, err := jose.ParseSigned(string(input.Body))
+	if err != nil {
+		lFunc.Errorf("could not parse ACME request JWS: %s", err)
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	if len(jws.Signatures) != 1 {
+		return nil, fmt.Errorf("%w: JWS must have exactly one signature", errs.ErrACMEMalformed)
+	}
+
+	header := jws.Signatures[0].Protected
+	if header.Algorithm == "" || header.Algorithm == "none" || strings.HasPrefix(header.Algorithm, "HS") {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEBadSignatureAlgorithm, header.Algorithm)
+	}
+
+	url, _ := header.ExtraHeaders["url"].(string)
+	if url != input.URL {
+		lFunc.Errorf("ACME request url header %s does not match request URL %s", url, input.URL)
+		return nil, fmt.Errorf("%w: url header does not match the request URL", errs.ErrACMEUnauthorized)
+	}
+
+	if !svc.nonces.consume(header.Nonce) {
+		lFunc.Warnf("ACME request with invalid nonce")
+		return nil, errs.ErrACMEBadNonce
+	}
+
+	request := &ACMERequest{
+		DMSID: input.DMSID,
+		URL:   input.URL,
+	}
+
+	var verificationKey any
+	switch {
+	case header.JSONWebKey != nil && header.KeyID == "":
+		if !input.AllowEmbeddedKey {
+			return nil, fmt.Errorf("%w: requests must be signed with the account key (kid)", errs.ErrACMEMalformed)
+		}
+
+		if !header.JSONWebKey.Valid() || !header.JSONWebKey.IsPublic() {
+			return nil, fmt.Errorf("%w: jwk is not a valid public key", errs.ErrACMEMalformed)
+		}
+
+		request.Key = header.JSONWebKey
+		verificationKey = header.JSONWebKey.Key
+	case header.KeyID != "" && header.JSONWebKey == nil:
+		if !strings.HasPrefix(header.KeyID, input.AccountURLPrefix) {
+			return nil, fmt.Errorf("%w: kid is not an account of this directory", errs.ErrACMEAccountDoesNotExist)
+		}
+
+		account, err := svc.getAccount(ctx, input.DMSID, strings.TrimPrefix(header.KeyID, input.AccountURLPrefix))
+		if err != nil {
+			return nil, err
+		}
+
+		if account.Status != models.ACMEStatusValid {
+			return nil, fmt.Errorf("%w: account is %s", errs.ErrACMEUnauthorized, account.Status)
+		}
+
+		var key jose.JSONWebKey
+		err = key.UnmarshalJSON([]byte(account.Key))
+		if err != nil {
+			lFunc.Errorf("could not decode ACME account %s key: %s", account.ID, err)
+			return nil, err
+		}
+
+		request.Account = account
+		verificationKey = key.Key
+	default:
+		return nil, fmt.Errorf("%w: exactly one of jwk and kid must be set", errs.ErrACMEMalformed)
+	}
+
+	request.Payload, err = jws.Verify(verificationKey)
+	if err != nil {
+		lFunc.Errorf("invalid ACME request signature: %s", err)
+		return nil, fmt.Errorf("%w: invalid JWS signature", errs.ErrACMEUnauthorized)
+	}
+
+	return request, nil
+}
+
+type NewACMEAccountInput struct {
+	Request *ACMERequest `validate:"required"`
+}
+
+type acmeNewAccountPayload struct {
+	Contact                []string        `json:"contact"`
+	TermsOfServiceAgreed   bool            `json:"termsOfServiceAgreed"`
+	OnlyReturnExisting     bool            `json:"onlyReturnExisting"`
+	ExternalAccountBinding json.RawMessage `json:"externalAccountBinding"`
+}
+
+// NewAccount registers the account of the request key (RFC 8555 7.3). The returned flag reports whether the
+// account has been created, since the existing account is returned if the key is already registered.
+//
+// Returned Error Codes:
+//   - ErrACMEMalformed
+//     The request is not signed with an embedded key or the payload is not valid.
+//   - ErrACMEAccountDoesNotExist
+//     The client only requested an existing account and the key is not registered.
+//   - ErrACMEExternalAccountRequired
+//     The DMS Manager requires an external account binding and the request does not include it.
+//   - ErrACMEUnauthorized
+//     The external account binding is not valid.
+func (svc *ACMEServiceBackend) NewAccount(ctx context.Context, input NewACMEAccountInput) (*models.ACMEAccount, bool, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := acmeValidate.Struct(input)
+	if err != nil || input.Request.Key == nil {
+		lFunc.Errorf("NewACMEAccountInput struct validation error: %v", err)
+		return nil, false, fmt.Errorf("%w: new account requests must be signed with an embedded key (jwk)", errs.ErrACMEMalformed)
+	}
+
+	var payload acmeNewAccountPayload
+	err = json.Unmarshal(input.Request.Payload, &payload)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	thumbprint, err := acmeKeyThumbprint(input.Request.Key)
+	if err != nil {
+		return nil, false, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	exists, account, err := svc.accountStorage.SelectExists(ctx, thumbprint)
+	if err != nil {
+		lFunc.Errorf("could not check if ACME account %s exists: %s", thumbprint, err)
+		return nil, false, err
+	}
+
+	if exists {
+		if account.DMSID != input.Request.DMSID {
+			lFunc.Errorf("ACME account key %s is registered in DMS %s", thumbprint, account.DMSID)
+			return nil, false, fmt.Errorf("%w: key registered in another directory", errs.ErrACMEUnauthorized)
+		}
+
+		return account, false, nil
+	}
+
+	if payload.OnlyReturnExisting {
+		return nil, false, errs.ErrACMEAccountDoesNotExist
+	}
+
+	eabKeyID := ""
+	if len(svc.eabSecret) > 0 {
+		if len(payload.ExternalAccountBinding) == 0 {
+			return nil, false, errs.ErrACMEExternalAccountRequired
+		}
+
+		eabKeyID, err = svc.verifyExternalAccountBinding(ctx, input.Request, payload.ExternalAccountBinding, thumbprint)
+		if err != nil {
+			return nil, false, err
+		}
+	}
+
+	key, err := input.Request.Key.MarshalJSON()
+	if err != nil {
+		return nil, false, err
+	}
+
+	lFunc.Infof("registering ACME account %s in DMS %s", thumbprint, input.Request.DMSID)
+	account, err = svc.accountStorage.Insert(ctx, &models.ACMEAccount{
+		ID:         thumbprint,
+		DMSID:      input.Request.DMSID,
+		Status:     models.ACMEStatusValid,
+		Contact:    payload.Contact,
+		Key:        string(key),
+		EABKeyID:   eabKeyID,
+		CreationTS: time.Now(),
+	})
+	if err != nil {
+		lFunc.Errorf("could not insert ACME account %s: %s", thumbprint, err)
+		return nil, false, err
+	}
+
+	return account, true, nil
+}
+
+// verifyExternalAccountBinding checks the EAB JWS (RFC 8555 7.3.4) was MACed with the HMAC key of an active EAB
+// key of the DMS and binds the account key. It returns the EAB key ID.
+func (svc *ACMEServiceBackend) verifyExternalAccountBinding(ctx context.Context, request *ACMERequest, binding json.RawMessage, accountThumbprint string) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	eab, err := jose.ParseSigned(string(binding))
+	if err != nil || len(eab.Signatures) != 1 {
+		return "", fmt.Errorf("%w: invalid externalAccountBinding JWS", errs.ErrACMEMalformed)
+	}
+
+	header := eab.Signatures[0].Protected
+	if !strings.HasPrefix(header.Algorithm, "HS") || header.Nonce != "" {
+		return "", fmt.Errorf("%w: externalAccountBinding must be MACed and have no nonce", errs.ErrACMEMalformed)
+	}
+
+	if url, _ := header.ExtraHeaders["url"].(string); url != request.URL {
+		return "", fmt.Errorf("%w: externalAccountBinding url does not match the request URL", errs.ErrACMEUnauthorized)
+	}
+
+	dms, err := svc.getDMS(ctx, request.DMSID)
+	if err != nil {
+		return "", err
+	}
+
+	if _, active := helpers.GetActiveACMEEABKey(*dms, header.KeyID); !active {
+		lFunc.Errorf("ACME EAB key %s of DMS %s is not active", header.KeyID, dms.ID)
+		return "", fmt.Errorf("%w: unknown or revoked EAB key", errs.ErrACMEUnauthorized)
+	}
+
+	payload, err := eab.Verify(helpers.DeriveACMEEABHMACKey(svc.eabSecret, dms.ID, header.KeyID))
+	if err != nil {
+		lFunc.Errorf("invalid ACME EAB key %s MAC: %s", header.KeyID, err)
+		return "", fmt.Errorf("%w: invalid externalAccountBinding MAC", errs.ErrACMEUnauthorized)
+	}
+
+	var boundKey jose.JSONWebKey
+	err = boundKey.UnmarshalJSON(payload)
+	if err != nil {
+		return "", fmt.Errorf("%w: externalAccountBinding payload is not a JWK", errs.ErrACMEMalformed)
+	}
+
+	boundThumbprint, err := acmeKeyThumbprint(&boundKey)
+	if err != nil || boundThumbprint != accountThumbprint {
+		return "", fmt.Errorf("%w: externalAccountBinding does not bind the account key", errs.ErrACMEUnauthorized)
+	}
+
+	return header.KeyID, nil
+}
+
+type UpdateACMEAccountInput struct {
+	Request   *ACMERequest `validate:"required"`
+	AccountID string       `validate:"required"`
+}
+
+// UpdateAccount updates the contacts of the account or deactivates it (RFC 8555 7.3.2 and 7.3.6). Requests with
+// an empty payload return the account.
+//
+// Returned Error Codes:
+//   - ErrACMEUnauthorized
+//     The request is not signed by the account.
+//   - ErrACMEMalformed
+//     The payload is not valid.
+func (svc *ACMEServiceBackend) UpdateAccount(ctx context.Context, input UpdateACMEAccountInput) (*models.ACMEAccount, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	account, err := svc.requestAccount(input.Request, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(input.Request.Payload) == 0 {
+		return account, nil
+	}
+
+	var payload struct {
+		Contact []string          `json:"contact"`
+		Status  models.ACMEStatus `json:"status"`
+	}
+	err = json.Unmarshal(input.Request.Payload, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	switch payload.Status {
+	case "":
+	case models.ACMEStatusDeactivated:
+		lFunc.Infof("deactivating ACME account %s", account.ID)
+		account.Status = models.ACMEStatusDeactivated
+	default:
+		return nil, fmt.Errorf("%w: accounts can only be deactivated", errs.ErrACMEMalformed)
+	}
+
+	if payload.Contact != nil {
+		account.Contact = payload.Contact
+	}
+
+	return svc.accountStorage.Update(ctx, account)
+}
+
+type GetACMEAccountOrdersInput struct {
+	Request   *ACMERequest `validate:"required"`
+	AccountID string       `validate:"required"`
+}
+
+// GetAccountOrders returns the IDs of the orders of the account (RFC 8555 7.1.2.1).
+//
+// Returned Error Codes:
+//   - ErrACMEUnauthorized
+//     The request is not signed by the account.
+func (svc *ACMEServiceBackend) GetAccountOrders(ctx context.Context, input GetACMEAccountOrdersInput) ([]string, error) {
+	account, err := svc.requestAccount(input.Request, input.AccountID)
+	if err != nil {
+		return nil, err
+	}
+
+	orders := []string{}
+	_, err = svc.orderStorage.SelectByAccount(ctx, account.ID, storage.StorageListRequest[models.ACMEOrder]{
+		ExhaustiveRun: true,
+		QueryParams:   &resources.QueryParameters{},
+		ExtraOpts:     map[string]interface{}{},
+		ApplyFunc: func(order models.ACMEOrder) {
+			orders = append(orders, order.ID)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return orders, nil
+}
+
+type NewACMEOrderInput struct {
+	Request *ACMERequest `validate:"required"`
+}
+
+// NewOrder creates an order for the requested identifiers, with one authorization per identifier (RFC 8555 7.4).
+// Only "dns" identifiers are supported. Wildcard identifiers can only be authorized with dns-01 challenges.
+//
+// Returned Error Codes:
+//   - ErrACMEUnauthorized
+//     The request is not signed with an account key or the account EAB key has been revoked.
+//   - ErrACMEMalformed
+//     The payload is not valid.
+//   - ErrACMEUnsupportedIdentifier
+//     An identifier is not of "dns" type.
+//   - ErrACMERejectedIdentifier
+//     An identifier is not a valid domain name.
+func (svc *ACMEServiceBackend) NewOrder(ctx context.Context, input NewACMEOrderInput) (*models.ACMEOrder, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if input.Request == nil || input.Request.Account == nil {
+		return nil, fmt.Errorf("%w: orders must be requested by an account", errs.ErrACMEUnauthorized)
+	}
+
+	if keyID := input.Request.Account.EABKeyID; keyID != "" {
+		dms, err := svc.getDMS(ctx, input.Request.DMSID)
+		if err != nil {
+			return nil, err
+		}
+
+		if _, active := helpers.GetActiveACMEEABKey(*dms, keyID); !active {
+			lFunc.Errorf("ACME account %s EAB key %s has been revoked", input.Request.Account.ID, keyID)
+			return nil, fmt.Errorf("%w: the account external account binding has been revoked", errs.ErrACMEUnauthorized)
+		}
+	}
+
+	var payload struct {
+		Identifiers []models.ACMEIdentifier `json:"identifiers"`
+	}
+	err := json.Unmarshal(input.Request.Payload, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	if len(payload.Identifiers) == 0 {
+		return nil, fmt.Errorf("%w: orders must include at least one identifier", errs.ErrACMEMalformed)
+	}
+
+	now := time.Now()
+	order := models.ACMEOrder{
+		ID:             goid.NewV4UUID().String(),
+		AccountID:      input.Request.Account.ID,
+		DMSID:          input.Request.DMSID,
+		Status:         models.ACMEStatusPending,
+		Expires:        now.Add(svc.orderValidity),
+		Identifiers:    []models.ACMEIdentifier{},
+		Authorizations: []models.ACMEAuthorization{},
+		CreationTS:     now,
+	}
+
+	for _, identifier := range payload.Identifiers {
+		if identifier.Type != models.ACMEIdentifierDNS {
+			return nil, fmt.Errorf("%w: %s", errs.ErrACMEUnsupportedIdentifier, identifier.Type)
+		}
+
+		if err := helpers.ValidateACMEDNSIdentifier(identifier.Value); err != nil {
+			return nil, fmt.Errorf("%w: %s", errs.ErrACMERejectedIdentifier, err)
+		}
+
+		if slices.Contains(order.Identifiers, identifier) {
+			continue
+		}
+		order.Identifiers = append(order.Identifiers, identifier)
+
+		wildcard := strings.HasPrefix(identifier.Value, "*.")
+		challengeTypes := []models.ACMEChallengeType{models.ACMEChallengeHTTP01, models.ACMEChallengeDNS01}
+		if wildcard {
+			challengeTypes = []models.ACMEChallengeType{models.ACMEChallengeDNS01}
+		}
+
+		authz := models.ACMEAuthorization{
+			Identifier: models.ACMEIdentifier{Type: identifier.Type, Value: strings.TrimPrefix(identifier.Value, "*.")},
+			Status:     models.ACMEStatusPending,
+			Wildcard:   wildcard,
+			Challenges: []models.ACMEChallenge{},
+		}
+
+		for _, challengeType := range challengeTypes {
+			token := make([]byte, 32)
+			if _, err := rand.Read(token); err != nil {
+				return nil, err
+			}
+
+			authz.Challenges = append(authz.Challenges, models.ACMEChallenge{
+				Type:   challengeType,
+				Token:  base64.RawURLEncoding.EncodeToString(token),
+				Status: models.ACMEStatusPending,
+			})
+		}
+
+		order.Authorizations = append(order.Authorizations, authz)
+	}
+
+	lFunc.Infof("creating ACME order %s for account %s", order.ID, order.AccountID)
+	return svc.orderStorage.Insert(ctx, &order)
+}
+
+type GetACMEOrderInput struct {
+	Request *ACMERequest `validate:"required"`
+	OrderID string       `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrACMEOrderNotFound
+//     The order does not exist.
+//   - ErrACMEUnauthorized
+//     The order belongs to another account.
+func (svc *ACMEServiceBackend) GetOrder(ctx context.Context, input GetACMEOrderInput) (*models.ACMEOrder, error) {
+	return svc.getOrder(ctx, input.Request, input.OrderID)
+}
+
+type GetACMEAuthorizationInput struct {
+	Request *ACMERequest `validate:"required"`
+	OrderID string       `validate:"required"`
+	Index   int
+}
+
+// Returned Error Codes:
+//   - ErrACMEOrderNotFound
+//     The order or the authorization does not exist.
+//   - ErrACMEUnauthorized
+//     The order belongs to another account.
+func (svc *ACMEServiceBackend) GetAuthorization(ctx context.Context, input GetACMEAuthorizationInput) (*models.ACMEAuthorization, error) {
+	order, err := svc.getOrder(ctx, input.Request, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Index < 0 || input.Index >= len(order.Authorizations) {
+		return nil, errs.ErrACMEOrderNotFound
+	}
+
+	return &order.Authorizations[input.Index], nil
+}
+
+type RespondACMEChallengeInput struct {
+	Request *ACMERequest `validate:"required"`
+	OrderID string       `validate:"required"`
+	Index   int
+	Type    models.ACMEChallengeType `validate:"required"`
+}
+
+// RespondChallenge validates the challenge of the authorization (RFC 8555 7.5.1). Challenges are validated before
+// responding, so the returned challenge is either valid or invalid. A failed challenge invalidates the
+// authorization and its order.
+//
+// Returned Error Codes:
+//   - ErrACMEOrderNotFound
+//     The order, the authorization or the challenge does not exist.
+//   - ErrACMEUnauthorized
+//     The order belongs to another account.
+func (svc *ACMEServiceBackend) RespondChallenge(ctx context.Context, input RespondACMEChallengeInput) (*models.ACMEChallenge, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	order, err := svc.getOrder(ctx, input.Request, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Index < 0 || input.Index >= len(order.Authorizations) {
+		return nil, errs.ErrACMEOrderNotFound
+	}
+
+	authz := &order.Authorizations[input.Index]
+	challengeIdx := slices.IndexFunc(authz.Challenges, func(challenge models.ACMEChallenge) bool {
+		return challenge.Type == input.Type
+	})
+	if challengeIdx < 0 {
+		return nil, errs.ErrACMEOrderNotFound
+	}
+
+	challenge := &authz.Challenges[challengeIdx]
+	if challenge.Status != models.ACMEStatusPending || authz.Status != models.ACMEStatusPending || order.Status != models.ACMEStatusPending {
+		return challenge, nil
+	}
+
+	keyAuthorization := helpers.ACMEKeyAuthorization(challenge.Token, order.AccountID)
+	validationCtx, cancel := context.WithTimeout(ctx, 30*time.Second)
+	defer cancel()
+
+	switch challenge.Type {
+	case models.ACMEChallengeHTTP01:
+		err = helpers.ValidateACMEHTTP01Challenge(validationCtx, svc.http01Client, authz.Identifier.Value, svc.http01Port, challenge.Token, keyAuthorization)
+	case models.ACMEChallengeDNS01:
+		err = helpers.ValidateACMEDNS01Challenge(validationCtx, svc.dnsResolver, authz.Identifier.Value, keyAuthorization)
+	}
+
+	if err != nil {
+		lFunc.Warnf("ACME order %s %s challenge for %s failed: %s", order.ID, challenge.Type, authz.Identifier.Value, err)
+		challenge.Status = models.ACMEStatusInvalid
+		challenge.Error = fmt.Errorf("%w: %s", errs.ErrACMEIncorrectResponse, err).Error()
+		authz.Status = models.ACMEStatusInvalid
+		order.Status = models.ACMEStatusInvalid
+		order.Error = challenge.Error
+	} else {
+		lFunc.Infof("ACME order %s %s challenge for %s validated", order.ID, challenge.Type, authz.Identifier.Value)
+		now := time.Now()
+		challenge.Status = models.ACMEStatusValid
+		challenge.Validated = &now
+		authz.Status = models.ACMEStatusValid
+
+		if !slices.ContainsFunc(order.Authorizations, func(authz models.ACMEAuthorization) bool { return authz.Status != models.ACMEStatusValid }) {
+			order.Status = models.ACMEStatusReady
+		}
+	}
+
+	_, err = svc.orderStorage.Update(ctx, order)
+	if err != nil {
+		lFunc.Errorf("could not update ACME order %s: %s", order.ID, err)
+		return nil, err
+	}
+
+	return challenge, nil
+}
+
+type FinalizeACMEOrderInput struct {
+	Request *ACMERequest `validate:"required"`
+	OrderID string       `validate:"required"`
+}
+
+// FinalizeOrder signs the CSR of a ready order with the DMS enrollment CA (RFC 8555 7.4). The CSR must request
+// exactly the order identifiers, either as DNS SANs or as common name.
+//
+// Returned Error Codes:
+//   - ErrACMEOrderNotFound
+//     The order does not exist.
+//   - ErrACMEUnauthorized
+//     The order belongs to another account.
+//   - ErrACMEOrderNotReady
+//     The authorizations of the order have not been completed.
+//   - ErrACMEBadCSR
+//     The CSR is not valid or does not match the order identifiers.
+func (svc *ACMEServiceBackend) FinalizeOrder(ctx context.Context, input FinalizeACMEOrderInput) (*models.ACMEOrder, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	order, err := svc.getOrder(ctx, input.Request, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != models.ACMEStatusReady {
+		return nil, fmt.Errorf("%w: order is %s", errs.ErrACMEOrderNotReady, order.Status)
+	}
+
+	var payload struct {
+		CSR string `json:"csr"`
+	}
+	err = json.Unmarshal(input.Request.Payload, &payload)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(payload.CSR)
+	if err != nil {
+		return nil, fmt.Errorf("%w: CSR is not base64url encoded", errs.ErrACMEBadCSR)
+	}
+
+	csr, err := x509.ParseCertificateRequest(der)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEBadCSR, err)
+	}
+
+	if err = csr.CheckSignature(); err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEBadCSR, err)
+	}
+
+	err = checkACMECSRIdentifiers(csr, order.Identifiers)
+	if err != nil {
+		return nil, fmt.Errorf("%w: %s", errs.ErrACMEBadCSR, err)
+	}
+
+	dms, err := svc.getDMS(ctx, order.DMSID)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Infof("signing ACME order %s certificate with enrollment CA %s", order.ID, dms.Settings.EnrollmentSettings.EnrollmentCA)
+	crt, err := svc.caClient.SignCertificate(ctx, SignCertificateInput{
+		CAID:                 dms.Settings.EnrollmentSettings.EnrollmentCA,
+		CertRequest:          (*models.X509CertificateRequest)(csr),
+		SignVerbatim:         true,
+		SigningProfile:       &dms.Settings.EnrollmentSettings.SigningProfile,
+		CertificateProfileID: dms.Settings.EnrollmentSettings.CertificateProfileID,
+		IssuanceContext: &models.CertificateIssuanceContext{
+			DMSID:     dms.ID,
+			RequestID: helpers.GetRequestID(ctx),
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not sign ACME order %s certificate: %s", order.ID, err)
+		if errors.Is(err, errs.ErrCertificateProfileViolation) || errors.Is(err, errs.ErrValidateBadRequest) {
+			return nil, fmt.Errorf("%w: %s", errs.ErrACMEBadCSR, err)
+		}
+		return nil, err
+	}
+
+	metadata := crt.Metadata
+	if metadata == nil {
+		metadata = map[string]any{}
+	}
+	metadata[models.ACMEMetadataAccountKey] = order.AccountID
+
+	_, err = svc.caClient.UpdateCertificateMetadata(ctx, UpdateCertificateMetadataInput{
+		SerialNumber: crt.SerialNumber,
+		Metadata:     metadata,
+	})
+	if err != nil {
+		lFunc.Errorf("could not bind certificate %s to ACME account %s: %s", crt.SerialNumber, order.AccountID, err)
+		return nil, err
+	}
+
+	order.Status = models.ACMEStatusValid
+	order.CertificateSerialNumber = crt.SerialNumber
+	return svc.orderStorage.Update(ctx, order)
+}
+
+// checkACMECSRIdentifiers checks the CSR requests the order identifiers, and nothing else.
+func checkACMECSRIdentifiers(csr *x509.CertificateRequest, identifiers []models.ACMEIdentifier) error {
+	if len(csr.IPAddresses) > 0 || len(csr.EmailAddresses) > 0 || len(csr.URIs) > 0 {
+		return fmt.Errorf("CSR can only include DNS SANs")
+	}
+
+	names := slices.Clone(csr.DNSNames)
+	if csr.Subject.CommonName != "" {
+		names = append(names, csr.Subject.CommonName)
+	}
+
+	for _, name := range names {
+		if !slices.ContainsFunc(identifiers, func(identifier models.ACMEIdentifier) bool { return identifier.Value == strings.ToLower(name) }) {
+			return fmt.Errorf("%s is not an identifier of the order", name)
+		}
+	}
+
+	for _, identifier := range identifiers {
+		if !slices.ContainsFunc(csr.DNSNames, func(name string) bool { return strings.ToLower(name) == identifier.Value }) {
+			return fmt.Errorf("CSR does not include the %s DNS SAN", identifier.Value)
+		}
+	}
+
+	return nil
+}
+
+// GetCertificateChain returns the certificate issued for the order followed by its issuer CA chain, without the
+// root CA unless it is the issuer.
+//
+// Returned Error Codes:
+//   - ErrACMEOrderNotFound
+//     The order does not exist or its certificate has not been issued.
+//   - ErrACMEUnauthorized
+//     The order belongs to another account.
+func (svc *ACMEServiceBackend) GetCertificateChain(ctx context.Context, input GetACMEOrderInput) ([]*x509.Certificate, error) {
+	order, err := svc.getOrder(ctx, input.Request, input.OrderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if order.Status != models.ACMEStatusValid || order.CertificateSerialNumber == "" {
+		return nil, errs.ErrACMEOrderNotFound
+	}
+
+	crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+		SerialNumber: order.CertificateSerialNumber,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	issuer, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{
+		CAID: crt.IssuerCAMetadata.ID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	chain := []*x509.Certificate{(*x509.Certificate)(crt.Certificate), (*x509.Certificate)(issuer.Certificate.Certificate)}
+	for i := len(issuer.CAChain) - 1; i > 0; i-- {
+		chain = append(chain, (*x509.Certificate)(issuer.CAChain[i]))
+	}
+
+	return chain, nil
+}
+
+type RevokeACMECertificateInput struct {
+	Request *ACMERequest `validate:"required"`
+}
+
+// RevokeCertificate revokes a certificate issued by the ACME server (RFC 8555 7.6). The request must be signed by
+// the account the certificate was issued to or by the certificate key.
+//
+// Returned Error Codes:
+//   - ErrACMEMalformed
+//     The payload is not valid or the certificate was not issued by the server.
+//   - ErrACMEUnauthorized
+//     The request is not signed by the certificate account or key.
+//   - ErrACMEAlreadyRevoked
+//     The certificate is already revoked.
+func (svc *ACMEServiceBackend) RevokeCertificate(ctx context.Context, input RevokeACMECertificateInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if input.Request == nil {
+		return errs.ErrACMEMalformed
+	}
+
+	var payload struct {
+		Certificate string `json:"certificate"`
+		// ACME clients send the numeric RFC 5280 reason code
+		Reason int `json:"reason"`
+	}
+	err := json.Unmarshal(input.Request.Payload, &payload)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	if _, ok := models.RevocationReasonMap[payload.Reason]; !ok {
+		return fmt.Errorf("%w: unsupported revocation reason %d", errs.ErrACMEMalformed, payload.Reason)
+	}
+
+	der, err := base64.RawURLEncoding.DecodeString(payload.Certificate)
+	if err != nil {
+		return fmt.Errorf("%w: certificate is not base64url encoded", errs.ErrACMEMalformed)
+	}
+
+	cert, err := x509.ParseCertificate(der)
+	if err != nil {
+		return fmt.Errorf("%w: %s", errs.ErrACMEMalformed, err)
+	}
+
+	crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+		SerialNumber: helpers.SerialNumberToString(cert.SerialNumber),
+	})
+	if err != nil || !bytes.Equal(crt.Certificate.Raw, cert.Raw) {
+		return fmt.Errorf("%w: certificate was not issued by this server", errs.ErrACMEMalformed)
+	}
+
+	if input.Request.Account != nil {
+		accountID, _ := crt.Metadata[models.ACMEMetadataAccountKey].(string)
+		if accountID != input.Request.Account.ID {
+			return fmt.Errorf("%w: certificate was not issued to the account", errs.ErrACMEUnauthorized)
+		}
+	} else {
+		certThumbprint, err := acmeKeyThumbprint(&jose.JSONWebKey{Key: cert.PublicKey})
+		requestThumbprint, _ := acmeKeyThumbprint(input.Request.Key)
+		if err != nil || certThumbprint != requestThumbprint {
+			return fmt.Errorf("%w: request is not signed by the certificate key", errs.ErrACMEUnauthorized)
+		}
+	}
+
+	if crt.Status == models.StatusRevoked {
+		return errs.ErrACMEAlreadyRevoked
+	}
+
+	lFunc.Infof("revoking certificate %s by ACME request", crt.SerialNumber)
+	_, err = svc.caClient.UpdateCertificateStatus(ctx, UpdateCertificateStatusInput{
+		SerialNumber:     crt.SerialNumber,
+		NewStatus:        models.StatusRevoked,
+		RevocationReason: models.RevocationReason(payload.Reason),
+	})
+	return err
+}
+
+func (svc *ACMEServiceBackend) getDMS(ctx context.Context, dmsID string) (*models.DMS, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	dms, err := svc.dmsClient.GetDMSByID(ctx, GetDMSByIDInput{ID: dmsID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", dmsID, err)
+		return nil, errs.ErrDMSNotFound
+	}
+
+	if dms.Settings.EnrollmentSettings.EnrollmentProtocol != models.ACME {
+		lFunc.Errorf("DMS %s does not use the ACME protocol", dmsID)
+		return nil, errs.ErrDMSACMENotEnabled
+	}
+
+	if dms.Status == models.PendingApprovalDMSStatus {
+		lFunc.Errorf("DMS %s registration is pending approval", dmsID)
+		return nil, errs.ErrDMSPendingApproval
+	}
+
+	return dms, nil
+}
+
+func (svc *ACMEServiceBackend) getAccount(ctx context.Context, dmsID, accountID string) (*models.ACMEAccount, error) {
+	exists, account, err := svc.accountStorage.SelectExists(ctx, accountID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists || account.DMSID != dmsID {
+		return nil, errs.ErrACMEAccountDoesNotExist
+	}
+
+	return account, nil
+}
+
+// requestAccount returns the account of the request, checking it is the account with the given ID.
+func (svc *ACMEServiceBackend) requestAccount(request *ACMERequest, accountID string) (*models.ACMEAccount, error) {
+	if request == nil || request.Account == nil || request.Account.ID != accountID {
+		return nil, fmt.Errorf("%w: request is not signed by the account", errs.ErrACMEUnauthorized)
+	}
+
+	return request.Account, nil
+}
+
+// getOrder returns the order if it belongs to the request account, invalidating it if it has expired.
+func (svc *ACMEServiceBackend) getOrder(ctx context.Context, request *ACMERequest, orderID string) (*models.ACMEOrder, error) {
+	if request == nil || request.Account == nil {
+		return nil, fmt.Errorf("%w: orders can only be accessed by their account", errs.ErrACMEUnauthorized)
+	}
+
+	exists, order, err := svc.orderStorage.SelectExists(ctx, orderID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !exists || order.DMSID != request.DMSID {
+		return nil, errs.ErrACMEOrderNotFound
+	}
+
+	if order.AccountID != request.Account.ID {
+		return nil, fmt.Errorf("%w: order belongs to another account", errs.ErrACMEUnauthorized)
+	}
+
+	if order.Status != models.ACMEStatusValid && order.Status != models.ACMEStatusInvalid && time.Now().After(order.Expires) {
+		order.Status = models.ACMEStatusInvalid
+		order.Error = "order expired"
+		return svc.orderStorage.Update(ctx, order)
+	}
+
+	return order, nil
+}
+
+func acmeKeyThumbprint(key *jose.JSONWebKey) (string, error) {
+	if key == nil {
+		return "", fmt.Errorf("missing key")
+	}
+
+	thumbprint, err := key.Thumbprint(crypto.SHA256)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.RawURLEncoding.EncodeToString(thumbprint), nil
+}
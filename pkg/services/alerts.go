@@ -3,11 +3,13 @@ package services
 import (
 	"context"
 	"encoding/json"
+	"strings"
 	"time"
 
 	cloudevents "github.com/cloudevents/sdk-go/v2"
 	"github.com/google/uuid"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
@@ -23,6 +25,7 @@ type AlertsService interface {
 	Unsubscribe(ctx context.Context, input *UnsubscribeInput) ([]*models.Subscription, error)
 
 	GetLatestEventsPerEventType(ctx context.Context, input *GetLatestEventsPerEventTypeInput) ([]*models.AlertLatestEvent, error)
+	SendExpirationDigest(ctx context.Context, input *SendExpirationDigestInput) error
 }
 
 type AlertsServiceBackend struct {
@@ -78,55 +81,180 @@ func (svc *AlertsServiceBackend) HandleEvent(ctx context.Context, input *HandleE
 	}
 
 	_, err = svc.subsStorage.GetSubscriptionsByEventType(ctx, input.Event.Type(), true, func(sub models.Subscription) {
-		// Send alert
-		lFunc.Debugf("sending notification to user %s via %s", sub.UserID, sub.Channel.Type)
-		var outSvc outputChannels.NotificationSenderService
-		chanConfigBytes, err := json.Marshal(sub.Channel.Config)
+		if !subscriptionMatches(lFunc, sub, input.Event) {
+			lFunc.Debugf("event ID '%s' does not satisfy the conditions of subscription %s", input.Event.ID(), sub.ID)
+			return
+		}
+
+		svc.notify(ctx, lFunc, sub, input.Event)
+	}, nil, nil)
+
+	if err != nil {
+		lFunc.Errorf("could not get user subscriptions for event type %s: %s", input.Event.Type(), err)
+		return err
+	}
+	lFunc.Debugf("completed handling Event ID '%s'. Event Type '%s'", input.Event.ID(), input.Event.Type())
+	return nil
+}
+
+// subscriptionMatches reports whether the event data satisfies every JSON-SCHEMA and JSON-PATH condition of the
+// subscription. Conditions that can not be evaluated do not match.
+func subscriptionMatches(lFunc *logrus.Entry, sub models.Subscription, event cloudevents.Event) bool {
+	for _, condition := range sub.Conditions {
+		match, err := helpers.EvaluateSubscriptionCondition(condition, event.Data())
 		if err != nil {
-			lFunc.Errorf("cannot get channel config to bytes")
+			lFunc.Warnf("could not evaluate %s condition of subscription %s: %s", condition.Type, sub.ID, err)
+			return false
 		}
-		switch sub.Channel.Type {
-		case models.ChannelTypeWebhook:
-			var webhookCfg models.WebhookChannelConfig
-			err = json.Unmarshal(chanConfigBytes, &webhookCfg)
-			if err != nil {
-				lFunc.Errorf("cannot get channel config to WebhookChannelConfig")
-			}
-			outSvc = outputChannels.NewWebhookOutputService(webhookCfg)
-		case models.ChannelTypeMSTeams:
-			var webhookCfg models.MSTeamsChannelConfig
-			err = json.Unmarshal(chanConfigBytes, &webhookCfg)
-			if err != nil {
-				lFunc.Errorf("cannot get channel config to MSTeamsChannelConfig")
-			}
-			outSvc = outputChannels.NewMSTeamsOutputService(webhookCfg)
 
-		case models.ChannelTypeEmail:
-			var emailConf models.EmailConfig
-			err = json.Unmarshal(chanConfigBytes, &emailConf)
-			if err != nil {
-				lFunc.Errorf("cannot get channel config to EmailConfig")
-			}
-			outSvc = outputChannels.NewSMTPOutputService(emailConf, svc.smtpServerConfig)
+		if !match {
+			return false
+		}
+	}
+
+	return true
+}
 
-		default:
-			lFunc.Errorf("unsupported channel type. No implementation for %s", sub.Channel.Type)
+// notify sends the event to the user over the channel of the subscription.
+func (svc *AlertsServiceBackend) notify(ctx context.Context, lFunc *logrus.Entry, sub models.Subscription, event cloudevents.Event) {
+	lFunc.Debugf("sending notification to user %s via %s", sub.UserID, sub.Channel.Type)
+	var outSvc outputChannels.NotificationSenderService
+	chanConfigBytes, err := json.Marshal(sub.Channel.Config)
+	if err != nil {
+		lFunc.Errorf("cannot get channel config to bytes")
+	}
+	switch sub.Channel.Type {
+	case models.ChannelTypeWebhook:
+		var webhookCfg models.WebhookChannelConfig
+		err = json.Unmarshal(chanConfigBytes, &webhookCfg)
+		if err != nil {
+			lFunc.Errorf("cannot get channel config to WebhookChannelConfig")
 		}
+		outSvc = outputChannels.NewWebhookOutputService(webhookCfg)
+	case models.ChannelTypeMSTeams:
+		var webhookCfg models.MSTeamsChannelConfig
+		err = json.Unmarshal(chanConfigBytes, &webhookCfg)
+		if err != nil {
+			lFunc.Errorf("cannot get channel config to MSTeamsChannelConfig")
+		}
+		outSvc = outputChannels.NewMSTeamsOutputService(webhookCfg)
+	case models.ChannelTypeSlack:
+		var webhookCfg models.SlackChannelConfig
+		err = json.Unmarshal(chanConfigBytes, &webhookCfg)
+		if err != nil {
+			lFunc.Errorf("cannot get channel config to SlackChannelConfig")
+		}
+		outSvc = outputChannels.NewSlackOutputService(webhookCfg)
 
-		err = outSvc.SendNotification(ctx, input.Event)
+	case models.ChannelTypeEmail:
+		var emailConf models.EmailConfig
+		err = json.Unmarshal(chanConfigBytes, &emailConf)
 		if err != nil {
-			lFunc.Errorf("error while sending notification to user %s via %s. Event ID '%s'. Event Type '%s'. Got error: %s", sub.UserID, sub.Channel.Type, input.Event.ID(), input.Event.Type(), err)
+			lFunc.Errorf("cannot get channel config to EmailConfig")
 		}
-	}, nil, nil)
+		outSvc = outputChannels.NewSMTPOutputService(emailConf, svc.smtpServerConfig)
 
+	default:
+		lFunc.Errorf("unsupported channel type. No implementation for %s", sub.Channel.Type)
+		return
+	}
+
+	err = outSvc.SendNotification(ctx, event)
 	if err != nil {
-		lFunc.Errorf("could not get user subscriptions for event type %s: %s", input.Event.Type(), err)
+		lFunc.Errorf("error while sending notification to user %s via %s. Event ID '%s'. Event Type '%s'. Got error: %s", sub.UserID, sub.Channel.Type, event.ID(), event.Type(), err)
+	}
+}
+
+type SendExpirationDigestInput struct {
+	Digest models.ExpirationDigest
+}
+
+// SendExpirationDigest delivers the digest to the users subscribed to the expiration digest event type. Subscriptions
+// with a DMS-IDS condition only receive the DMSs listed in the condition. The CAs are reported to every subscriber.
+func (svc *AlertsServiceBackend) SendExpirationDigest(ctx context.Context, input *SendExpirationDigestInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	event := helpers.BuildCloudEvent(string(models.EventExpirationDigestKey), models.AlertsSource, input.Digest)
+	exists, storedEv, err := svc.eventStorage.GetLatestEventByEventType(ctx, models.EventExpirationDigestKey)
+	if err != nil {
+		lFunc.Errorf("could not obtain last event stored for type %s", models.EventExpirationDigestKey)
 		return err
 	}
-	lFunc.Debugf("completed handling Event ID '%s'. Event Type '%s'", input.Event.ID(), input.Event.Type())
+
+	if !exists {
+		storedEv = &models.AlertLatestEvent{}
+	}
+
+	storedEv.TotalSeen++
+	storedEv.EventType = models.EventExpirationDigestKey
+	storedEv.Event = event
+	storedEv.LastSeen = time.Now()
+
+	_, err = svc.eventStorage.InsertUpdateEvent(ctx, storedEv)
+	if err != nil {
+		lFunc.Errorf("could not insert/update latest event: %s", err)
+		return err
+	}
+
+	_, err = svc.subsStorage.GetSubscriptionsByEventType(ctx, string(models.EventExpirationDigestKey), true, func(sub models.Subscription) {
+		dmsIDs, filtered := subscriptionDMSFilter(sub)
+		if !filtered {
+			svc.notify(ctx, lFunc, sub, event)
+			return
+		}
+
+		digest := filterExpirationDigest(input.Digest, dmsIDs)
+		svc.notify(ctx, lFunc, sub, helpers.BuildCloudEvent(string(models.EventExpirationDigestKey), models.AlertsSource, digest))
+	}, nil, nil)
+	if err != nil {
+		lFunc.Errorf("could not get user subscriptions for event type %s: %s", models.EventExpirationDigestKey, err)
+		return err
+	}
+
 	return nil
 }
 
+// subscriptionDMSFilter returns the DMS IDs listed in the DMS-IDS conditions of the subscription.
+func subscriptionDMSFilter(sub models.Subscription) (map[string]bool, bool) {
+	dmsIDs := map[string]bool{}
+	filtered := false
+	for _, condition := range sub.Conditions {
+		if condition.Type != models.DMSIDs {
+			continue
+		}
+
+		filtered = true
+		for _, id := range strings.Split(condition.Condition, ",") {
+			if id = strings.TrimSpace(id); id != "" {
+				dmsIDs[id] = true
+			}
+		}
+	}
+
+	return dmsIDs, filtered
+}
+
+func filterExpirationDigest(digest models.ExpirationDigest, dmsIDs map[string]bool) models.ExpirationDigest {
+	filtered := models.ExpirationDigest{
+		GeneratedAt: digest.GeneratedAt,
+		Windows:     make([]models.ExpirationDigestWindow, 0, len(digest.Windows)),
+	}
+
+	for _, window := range digest.Windows {
+		dmss := []models.ExpiringDMSSummary{}
+		for _, dms := range window.DMSs {
+			if dmsIDs[dms.DMSID] {
+				dmss = append(dmss, dms)
+			}
+		}
+
+		window.DMSs = dmss
+		filtered.Windows = append(filtered.Windows, window)
+	}
+
+	return filtered
+}
+
 type GetLatestEventsPerEventTypeInput struct{}
 
 func (svc *AlertsServiceBackend) GetLatestEventsPerEventType(ctx context.Context, input *GetLatestEventsPerEventTypeInput) ([]*models.AlertLatestEvent, error) {
@@ -171,9 +299,28 @@ type SubscribeInput struct {
 	Channel    models.Channel
 }
 
+// Subscribe registers a new subscription of the user to the event type. Notifications are only delivered for the
+// events satisfying all the conditions of the subscription.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The channel type is not supported or a condition is not valid.
 func (svc *AlertsServiceBackend) Subscribe(ctx context.Context, input *SubscribeInput) ([]*models.Subscription, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
+	switch input.Channel.Type {
+	case models.ChannelTypeEmail, models.ChannelTypeMSTeams, models.ChannelTypeSlack, models.ChannelTypeWebhook:
+	default:
+		lFunc.Errorf("unsupported channel type %s", input.Channel.Type)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	for _, condition := range input.Conditions {
+		if err := helpers.ValidateSubscriptionCondition(condition); err != nil {
+			lFunc.Errorf("invalid subscription condition: %s", err)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
 	lFunc.Infof("subscribing user %s to event type %s with %d conditions over %s", input.UserID, input.EventType, len(input.Conditions), input.Channel.Type)
 	sub := &models.Subscription{
 		ID:               uuid.NewString(),
@@ -0,0 +1,111 @@
+package outputchannels
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+
+	cloudevents "github.com/cloudevents/sdk-go/v2"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+type SlackWebhookText struct {
+	Type string `json:"type"`
+	Text string `json:"text"`
+}
+
+type SlackWebhookBlock struct {
+	Type   string             `json:"type"`
+	Text   *SlackWebhookText  `json:"text,omitempty"`
+	Fields []SlackWebhookText `json:"fields,omitempty"`
+}
+
+type SlackWebhookMsg struct {
+	Text   string              `json:"text"`
+	Blocks []SlackWebhookBlock `json:"blocks"`
+}
+
+type SlackWebhookOutputService struct {
+	config models.SlackChannelConfig
+}
+
+func NewSlackOutputService(config models.SlackChannelConfig) NotificationSenderService {
+	return &SlackWebhookOutputService{
+		config: config,
+	}
+}
+
+func (s *SlackWebhookOutputService) SendNotification(ctx context.Context, event cloudevents.Event) error {
+	var eventDataMap map[string]any
+	json.Unmarshal(event.Data(), &eventDataMap)
+
+	keys := make([]string, 0, len(eventDataMap))
+	for k := range eventDataMap {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	// Slack limits section blocks to 10 fields
+	fields := []SlackWebhookText{}
+	for _, k := range keys {
+		if len(fields) == 10 {
+			break
+		}
+
+		valueB, err := json.Marshal(eventDataMap[k])
+		if err != nil {
+			valueB = []byte{}
+		}
+		fields = append(fields, SlackWebhookText{
+			Type: "mrkdwn",
+			Text: fmt.Sprintf("*%s*\n%s", k, valueB),
+		})
+	}
+
+	slackWebhookMsg := SlackWebhookMsg{
+		Text: event.Type(),
+		Blocks: []SlackWebhookBlock{
+			{
+				Type: "header",
+				Text: &SlackWebhookText{Type: "plain_text", Text: event.Type()},
+			},
+			{
+				Type: "section",
+				Text: &SlackWebhookText{Type: "mrkdwn", Text: fmt.Sprintf("Event `%s` from `%s`", event.ID(), event.Source())},
+			},
+		},
+	}
+
+	if len(fields) > 0 {
+		slackWebhookMsg.Blocks = append(slackWebhookMsg.Blocks, SlackWebhookBlock{
+			Type:   "section",
+			Fields: fields,
+		})
+	}
+
+	msgBytes, err := json.Marshal(slackWebhookMsg)
+	if err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, "POST", s.config.WebhookURL, bytes.NewBuffer(msgBytes))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("slack webhook returned status code %d", resp.StatusCode)
+	}
+
+	return nil
+}
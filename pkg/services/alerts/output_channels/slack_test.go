@@ -0,0 +1,51 @@
+package outputchannels
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestSlackOutputService(t *testing.T) {
+	var received SlackWebhookMsg
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Content-Type") != "application/json" {
+			t.Errorf("unexpected content type %s", r.Header.Get("Content-Type"))
+		}
+		json.NewDecoder(r.Body).Decode(&received)
+		w.WriteHeader(http.StatusOK)
+	}))
+	defer server.Close()
+
+	event := helpers.BuildCloudEvent(string(models.EventCreateCAKey), "test", map[string]any{"id": "ca-1"})
+	err := NewSlackOutputService(models.SlackChannelConfig{WebhookURL: server.URL}).SendNotification(context.Background(), event)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if received.Text != string(models.EventCreateCAKey) || len(received.Blocks) != 3 {
+		t.Fatalf("unexpected message %v", received)
+	}
+
+	if received.Blocks[2].Fields[0].Text != "*id*\n\"ca-1\"" {
+		t.Fatalf("unexpected field %s", received.Blocks[2].Fields[0].Text)
+	}
+}
+
+func TestSlackOutputServiceRejected(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusForbidden)
+	}))
+	defer server.Close()
+
+	event := helpers.BuildCloudEvent(string(models.EventCreateCAKey), "test", map[string]any{"id": "ca-1"})
+	err := NewSlackOutputService(models.SlackChannelConfig{WebhookURL: server.URL}).SendNotification(context.Background(), event)
+	if err == nil {
+		t.Fatalf("expected an error")
+	}
+}
@@ -2,11 +2,23 @@ package services
 
 import (
 	"context"
+	"crypto"
 	"crypto/ecdsa"
+	"crypto/rand"
 	"crypto/rsa"
+	"crypto/sha256"
 	"crypto/x509"
-	"crypto/x509/pkix"
+	"encoding/json"
+	"errors"
 	"fmt"
+	"net/http"
+	"net/url"
+	"reflect"
+	"runtime"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -17,6 +29,7 @@ import (
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/x509engines"
 	"github.com/sirupsen/logrus"
@@ -28,6 +41,9 @@ type CAMiddleware func(CAService) CAService
 type CAService interface {
 	GetStats(ctx context.Context) (*models.CAStats, error)
 	GetStatsByCAID(ctx context.Context, input GetStatsByCAIDInput) (map[models.CertificateStatus]int, error)
+	GetKeyStrengthReport(ctx context.Context) (*models.KeyStrengthReport, error)
+	GetSoftwareKeyCustodyReport(ctx context.Context) (*models.SoftwareKeyCustodyReport, error)
+	ExportCAInventory(ctx context.Context) (*models.CAInventory, error)
 
 	GetCryptoEngineProvider(ctx context.Context) ([]*models.CryptoEngineProvider, error)
 
@@ -38,17 +54,32 @@ type CAService interface {
 	GetCAsByCommonName(ctx context.Context, input GetCAsByCommonNameInput) (string, error)
 	UpdateCAStatus(ctx context.Context, input UpdateCAStatusInput) (*models.CACertificate, error)
 	UpdateCAMetadata(ctx context.Context, input UpdateCAMetadataInput) (*models.CACertificate, error)
+	UpdateCASettings(ctx context.Context, input UpdateCASettingsInput) (*models.CACertificate, error)
 	DeleteCA(ctx context.Context, input DeleteCAInput) error
+	GetPendingCAAction(ctx context.Context, input GetPendingCAActionInput) (*models.CAPendingAction, error)
+	ApprovePendingCAAction(ctx context.Context, input ApprovePendingCAActionInput) (*models.CAPendingAction, error)
+	MigrateCAKey(ctx context.Context, input MigrateCAKeyInput) (*models.CACertificate, error)
+	SetCAFallbackEngine(ctx context.Context, input SetCAFallbackEngineInput) (*models.CACertificate, error)
+	SetCAIssuanceWebhook(ctx context.Context, input SetCAIssuanceWebhookInput) (*models.CACertificate, error)
+	ExportCAKey(ctx context.Context, input ExportCAKeyInput) (*models.CAKeyBackup, error)
+	RestoreCAKey(ctx context.Context, input RestoreCAKeyInput) (*models.CACertificate, error)
 
 	SignatureSign(ctx context.Context, input SignatureSignInput) ([]byte, error)
 	SignatureVerify(ctx context.Context, input SignatureVerifyInput) (bool, error)
 
+	SignToken(ctx context.Context, input SignTokenInput) (*models.SignedToken, error)
+	SignSVID(ctx context.Context, input SignSVIDInput) (*models.Certificate, error)
+	GetTokenSigningKeys(ctx context.Context) (*models.JWKS, error)
+	GetJWKS(ctx context.Context) (*models.JWKS, error)
+
 	SignCertificate(ctx context.Context, input SignCertificateInput) (*models.Certificate, error)
+	SignCertificatesBatch(ctx context.Context, input SignCertificatesBatchInput) ([]models.CertificateSigningResult, error)
 	CreateCertificate(ctx context.Context, input CreateCertificateInput) (*models.Certificate, error)
 	ImportCertificate(ctx context.Context, input ImportCertificateInput) (*models.Certificate, error)
 
 	GetCertificateBySerialNumber(ctx context.Context, input GetCertificatesBySerialNumberInput) (*models.Certificate, error)
 	GetCertificates(ctx context.Context, input GetCertificatesInput) (string, error)
+	ExportCertificates(ctx context.Context, input ExportCertificatesInput) (time.Time, error)
 	GetCertificatesByCA(ctx context.Context, input GetCertificatesByCAInput) (string, error)
 	GetCertificatesByExpirationDate(ctx context.Context, input GetCertificatesByExpirationDateInput) (string, error)
 	GetCertificatesByCaAndStatus(ctx context.Context, input GetCertificatesByCaAndStatusInput) (string, error)
@@ -57,6 +88,26 @@ type CAService interface {
 	// GetCertificatesByStatusAndCA(input GetCertificatesByExpirationDateInput) (string, error)
 	UpdateCertificateStatus(ctx context.Context, input UpdateCertificateStatusInput) (*models.Certificate, error)
 	UpdateCertificateMetadata(ctx context.Context, input UpdateCertificateMetadataInput) (*models.Certificate, error)
+
+	CreateCertificateProfile(ctx context.Context, input CreateCertificateProfileInput) (*models.CertificateProfile, error)
+	GetCertificateProfileByID(ctx context.Context, input GetCertificateProfileByIDInput) (*models.CertificateProfile, error)
+	GetCertificateProfiles(ctx context.Context, input GetCertificateProfilesInput) (string, error)
+	UpdateCertificateProfile(ctx context.Context, input UpdateCertificateProfileInput) (*models.CertificateProfile, error)
+	DeleteCertificateProfile(ctx context.Context, input DeleteCertificateProfileInput) error
+
+	GetIssuanceLogTreeHead(ctx context.Context, input GetIssuanceLogTreeHeadInput) (*models.IssuanceLogTreeHead, error)
+	GetIssuanceLogInclusionProof(ctx context.Context, input GetIssuanceLogInclusionProofInput) (*models.IssuanceLogInclusionProof, error)
+
+	GetCAEvents(ctx context.Context, input GetCAEventsInput) ([]models.CAEvent, error)
+	ReportCRLGeneration(ctx context.Context, input ReportCRLGenerationInput) error
+
+	CreateSuccessorCA(ctx context.Context, input CreateSuccessorCAInput) (*models.CASuccession, error)
+
+	CreateCASigningRequest(ctx context.Context, input CreateCASigningRequestInput) (*models.CASigningRequest, error)
+	GetCASigningRequests(ctx context.Context, input GetCASigningRequestsInput) (string, error)
+	GetCASigningRequestByID(ctx context.Context, input GetCASigningRequestByIDInput) (*models.CASigningRequest, error)
+	ApproveCASigningRequest(ctx context.Context, input ApproveCASigningRequestInput) (*models.CASigningRequest, error)
+	RejectCASigningRequest(ctx context.Context, input RejectCASigningRequestInput) (*models.CASigningRequest, error)
 }
 
 var validate *validator.Validate
@@ -73,18 +124,54 @@ type CAServiceBackend struct {
 	defaultCryptoEngineID string
 	caStorage             storage.CACertificatesRepo
 	certStorage           storage.CertificatesRepo
+	certProfileStorage    storage.CertificateProfilesRepo
+	issuanceLogStorage    storage.IssuanceLogRepo
+	issuanceLogLock       *sync.Mutex
+	caEventsStorage       storage.CAEventsRepo
+	signingRequestStorage storage.CASigningRequestsRepo
+	pendingActionStorage  storage.CAPendingActionsRepo
 	cryptoMonitorConfig   config.CryptoMonitoring
 	vaServerDomain        string
+	crlDistributionPoints []string
+	approvalEnabled       bool
+	approvalWindow        time.Duration
+	csrLimits             config.CSRLimits
+	issuanceWebhookClient *http.Client
+	hybridSigners         map[string]x509engines.HybridSigner
+	batchSigningWorkers   int
 	logger                *logrus.Entry
 }
 
 type CAServiceBuilder struct {
-	Logger               *logrus.Entry
-	CryptoEngines        map[string]*Engine
-	CAStorage            storage.CACertificatesRepo
-	CertificateStorage   storage.CertificatesRepo
+	Logger             *logrus.Entry
+	CryptoEngines      map[string]*Engine
+	CAStorage          storage.CACertificatesRepo
+	CertificateStorage storage.CertificatesRepo
+	// CertificateProfileStorage is optional. No certificate profile is enforced if nil.
+	CertificateProfileStorage storage.CertificateProfilesRepo
+	// IssuanceLogStorage is optional. Signed certificates are not appended to the CA issuance logs if nil.
+	IssuanceLogStorage storage.IssuanceLogRepo
+	// CAEventsStorage is optional. The lifecycle events of the CAs are not recorded if nil.
+	CAEventsStorage storage.CAEventsRepo
+	// SigningRequestStorage is optional. Dual control CAs can not sign the operations they guard if nil.
+	SigningRequestStorage storage.CASigningRequestsRepo
+	// PendingActionStorage holds the destructive operations waiting for approval. Required if ApprovalConf is enabled.
+	PendingActionStorage storage.CAPendingActionsRepo
 	CryptoMonitoringConf config.CryptoMonitoring
 	VAServerDomain       string
+	// CRLDistributionPoints are the base URLs of the CRL Distribution Points embedded into the signed certificates.
+	// The ID of the issuing CA is appended to each URL.
+	CRLDistributionPoints []string
+	ApprovalConf          config.DestructiveOperationsApproval
+	// CSRLimits bounds the size and complexity of the certificate requests to sign.
+	CSRLimits config.CSRLimits
+	// IssuanceWebhookClient calls the issuance webhooks of the CAs. Defaults to a client with a 10 seconds timeout.
+	IssuanceWebhookClient *http.Client
+	// HybridSigners hold the post-quantum keys of hybrid CAs, indexed by signer ID. Hybrid CAs can not be created
+	// without signers.
+	HybridSigners map[string]x509engines.HybridSigner
+	// BatchSigningWorkers bounds the certificate requests of a batch signed concurrently. Defaults to the number of CPUs.
+	BatchSigningWorkers int
 }
 
 func NewCAService(builder CAServiceBuilder) (CAService, error) {
@@ -105,14 +192,50 @@ func NewCAService(builder CAServiceBuilder) (CAService, error) {
 		return nil, fmt.Errorf("could not find the default crypto engine")
 	}
 
+	if builder.ApprovalConf.Enabled && builder.PendingActionStorage == nil {
+		return nil, fmt.Errorf("destructive operations approval requires a pending actions storage")
+	}
+
+	approvalWindow := time.Hour
+	if builder.ApprovalConf.ApprovalWindow != "" {
+		window, err := models.ParseDuration(builder.ApprovalConf.ApprovalWindow)
+		if err != nil {
+			return nil, fmt.Errorf("could not parse destructive operations approval window '%s': %s", builder.ApprovalConf.ApprovalWindow, err)
+		}
+		approvalWindow = window
+	}
+
+	batchSigningWorkers := builder.BatchSigningWorkers
+	if batchSigningWorkers <= 0 {
+		batchSigningWorkers = runtime.NumCPU()
+	}
+
+	issuanceWebhookClient := builder.IssuanceWebhookClient
+	if issuanceWebhookClient == nil {
+		issuanceWebhookClient = &http.Client{Timeout: 10 * time.Second}
+	}
+
 	svc := CAServiceBackend{
 		cryptoEngines:         engines,
 		defaultCryptoEngine:   defaultCryptoEngine,
 		defaultCryptoEngineID: defaultCryptoEngineID,
 		caStorage:             builder.CAStorage,
 		certStorage:           builder.CertificateStorage,
+		certProfileStorage:    builder.CertificateProfileStorage,
+		issuanceLogStorage:    builder.IssuanceLogStorage,
+		issuanceLogLock:       &sync.Mutex{},
+		caEventsStorage:       builder.CAEventsStorage,
+		signingRequestStorage: builder.SigningRequestStorage,
+		pendingActionStorage:  builder.PendingActionStorage,
 		cryptoMonitorConfig:   builder.CryptoMonitoringConf,
 		vaServerDomain:        builder.VAServerDomain,
+		crlDistributionPoints: builder.CRLDistributionPoints,
+		approvalEnabled:       builder.ApprovalConf.Enabled,
+		approvalWindow:        approvalWindow,
+		csrLimits:             helpers.CSRLimitsWithDefaults(builder.CSRLimits),
+		issuanceWebhookClient: issuanceWebhookClient,
+		hybridSigners:         builder.HybridSigners,
+		batchSigningWorkers:   batchSigningWorkers,
 		logger:                builder.Logger,
 	}
 
@@ -216,16 +339,226 @@ func (svc *CAServiceBackend) GetStatsByCAID(ctx context.Context, input GetStatsB
 	return stats, nil
 }
 
+// GetKeyStrengthReport lists the active CAs and certificates using LOW strength keys
+// or deprecated signature algorithms.
+func (svc *CAServiceBackend) GetKeyStrengthReport(ctx context.Context) (*models.KeyStrengthReport, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	report := models.KeyStrengthReport{
+		CAs:          []models.KeyStrengthFinding{},
+		Certificates: []models.KeyStrengthFinding{},
+	}
+
+	lFunc.Debugf("checking key strength of active CAs")
+	_, err := svc.caStorage.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			if ca.Status != models.StatusActive {
+				return
+			}
+
+			finding, weak := helpers.KeyStrengthFindingFromCertificate(ca.Certificate)
+			if weak {
+				finding.CAID = ca.ID
+				report.CAs = append(report.CAs, finding)
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("something went wrong while reading all CAs from storage engine: %s", err)
+		return nil, err
+	}
+
+	lFunc.Debugf("checking key strength of active certificates")
+	_, err = svc.certStorage.SelectByStatus(ctx, models.StatusActive, storage.StorageListRequest[models.Certificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(cert models.Certificate) {
+			finding, weak := helpers.KeyStrengthFindingFromCertificate(cert)
+			if weak {
+				report.Certificates = append(report.Certificates, finding)
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("something went wrong while reading active certificates from storage engine: %s", err)
+		return nil, err
+	}
+
+	lFunc.Debugf("found %d CAs and %d certificates with key strength issues", len(report.CAs), len(report.Certificates))
+	return &report, nil
+}
+
+// GetSoftwareKeyCustodyReport lists the active CAs whose keys are held in software crypto engines,
+// so they can be migrated to HSM backed engines.
+func (svc *CAServiceBackend) GetSoftwareKeyCustodyReport(ctx context.Context) (*models.SoftwareKeyCustodyReport, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	report := models.SoftwareKeyCustodyReport{
+		CAs: []models.SoftwareKeyCustodyFinding{},
+	}
+
+	lFunc.Debugf("checking crypto engines of active CAs")
+	_, err := svc.caStorage.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			if ca.Status != models.StatusActive || ca.Type == models.CertificateTypeExternal {
+				return
+			}
+
+			engine, ok := svc.cryptoEngines[ca.Certificate.EngineID]
+			if !ok {
+				lFunc.Warnf("CA %s uses engine %s which is not configured", ca.ID, ca.Certificate.EngineID)
+				return
+			}
+
+			engineInfo := (*engine).GetEngineConfig()
+			if !engineInfo.IsSoftware() {
+				return
+			}
+
+			production := false
+			_, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataProductionKey, &production)
+			if err != nil {
+				lFunc.Warnf("could not decode %s metadata for CA %s: %s", models.CAMetadataProductionKey, ca.ID, err)
+			}
+
+			report.CAs = append(report.CAs, models.SoftwareKeyCustodyFinding{
+				CAID:          ca.ID,
+				Subject:       ca.Certificate.Subject,
+				Type:          ca.Type,
+				Production:    production,
+				EngineID:      ca.Certificate.EngineID,
+				EngineType:    engineInfo.Type,
+				SecurityLevel: engineInfo.SecurityLevel,
+				KeyMetadata:   ca.Certificate.KeyMetadata,
+			})
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("something went wrong while reading all CAs from storage engine: %s", err)
+		return nil, err
+	}
+
+	lFunc.Debugf("found %d CAs with keys in software engines", len(report.CAs))
+	return &report, nil
+}
+
+// ExportCAInventory collects all the CAs, with their chains and certificate statistics, the crypto engines, the
+// certificate profiles and the global statistics in a single document.
+func (svc *CAServiceBackend) ExportCAInventory(ctx context.Context) (*models.CAInventory, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	inventory := models.CAInventory{
+		GeneratedAt: time.Now(),
+		CAs:         []models.CAInventoryEntry{},
+		Profiles:    []models.CertificateProfile{},
+	}
+
+	lFunc.Debugf("reading all CAs")
+	cas := map[string]models.CACertificate{}
+	_, err := svc.caStorage.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			cas[ca.ID] = ca
+			inventory.CAs = append(inventory.CAs, models.CAInventoryEntry{
+				CACertificate: ca,
+				ChainPEMs:     []string{},
+				ProfileIDs:    []string{},
+			})
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("something went wrong while reading all CAs from storage engine: %s", err)
+		return nil, err
+	}
+
+	// Parent CAs first, so the inventory is stable between exports.
+	sort.Slice(inventory.CAs, func(i, j int) bool {
+		if inventory.CAs[i].Level != inventory.CAs[j].Level {
+			return inventory.CAs[i].Level < inventory.CAs[j].Level
+		}
+		return inventory.CAs[i].ID < inventory.CAs[j].ID
+	})
+
+	profilesByCA := map[string][]string{}
+	if svc.certProfileStorage != nil {
+		lFunc.Debugf("reading all certificate profiles")
+		_, err = svc.certProfileStorage.SelectAll(ctx, storage.StorageListRequest[models.CertificateProfile]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(profile models.CertificateProfile) {
+				inventory.Profiles = append(inventory.Profiles, profile)
+				for _, caID := range profile.CAIDs {
+					profilesByCA[caID] = append(profilesByCA[caID], profile.ID)
+				}
+			},
+		})
+		if err != nil {
+			lFunc.Errorf("something went wrong while reading all certificate profiles from storage engine: %s", err)
+			return nil, err
+		}
+	}
+
+	for i := range inventory.CAs {
+		entry := &inventory.CAs[i]
+		if entry.Certificate.Certificate != nil {
+			entry.CertificatePEM = helpers.CertificateToPEM((*x509.Certificate)(entry.Certificate.Certificate))
+		}
+
+		for _, parentID := range entry.ChainIDs {
+			parent, ok := cas[parentID]
+			if !ok || parent.Certificate.Certificate == nil {
+				lFunc.Warnf("parent CA '%s' of CA '%s' no longer exists. The exported chain is incomplete", parentID, entry.ID)
+				continue
+			}
+
+			entry.ChainPEMs = append(entry.ChainPEMs, helpers.CertificateToPEM((*x509.Certificate)(parent.Certificate.Certificate)))
+		}
+
+		if profileIDs, ok := profilesByCA[entry.ID]; ok {
+			entry.ProfileIDs = profileIDs
+		}
+
+		entry.CertificateStats, err = svc.GetStatsByCAID(ctx, GetStatsByCAIDInput{CAID: entry.ID})
+		if err != nil {
+			lFunc.Errorf("could not get certificate statistics of CA '%s': %s", entry.ID, err)
+			return nil, err
+		}
+	}
+
+	inventory.Engines, err = svc.GetCryptoEngineProvider(ctx)
+	if err != nil {
+		lFunc.Errorf("could not get engines: %s", err)
+		return nil, err
+	}
+
+	stats, err := svc.GetStats(ctx)
+	if err != nil {
+		lFunc.Errorf("could not get statistics: %s", err)
+		return nil, err
+	}
+	inventory.Stats = *stats
+
+	lFunc.Debugf("exported inventory with %d CAs and %d certificate profiles", len(inventory.CAs), len(inventory.Profiles))
+	return &inventory, nil
+}
+
 func (svc *CAServiceBackend) GetCryptoEngineProvider(ctx context.Context) ([]*models.CryptoEngineProvider, error) {
 	info := []*models.CryptoEngineProvider{}
 	for engineID, engine := range svc.cryptoEngines {
 		engineInstance := *engine
 		engineInfo := engineInstance.GetEngineConfig()
-		info = append(info, &models.CryptoEngineProvider{
+		provider := &models.CryptoEngineProvider{
 			CryptoEngineInfo: engineInfo,
 			ID:               engineID,
 			Default:          engineID == svc.defaultCryptoEngineID,
-		})
+		}
+
+		if reporter, ok := engineInstance.(cryptoengines.HealthReporter); ok {
+			health := reporter.Health()
+			provider.Health = &health
+		}
+
+		info = append(info, provider)
 	}
 
 	return info, nil
@@ -246,6 +579,7 @@ type issueCAInput struct {
 	CAExpiration models.Expiration
 	EngineID     string
 	CAID         string `validate:"required"`
+	HybridKey    *models.HybridKeyMetadata
 }
 
 type issueCAOutput struct {
@@ -258,11 +592,11 @@ func (svc *CAServiceBackend) issueCA(ctx context.Context, input issueCAInput) (*
 
 	var x509Engine x509engines.X509Engine
 	if input.EngineID == "" {
-		x509Engine = x509engines.NewX509Engine(svc.defaultCryptoEngine, svc.vaServerDomain)
+		x509Engine = x509engines.NewX509Engine(svc.defaultCryptoEngine, svc.vaServerDomain).WithHybridSigners(svc.hybridSigners)
 		lFunc.Infof("creating CA %s with default engine %s crypto engine", input.Subject.CommonName, x509Engine.GetEngineConfig().Provider)
 	} else {
 		if engine, ok := svc.cryptoEngines[input.EngineID]; ok {
-			x509Engine = x509engines.NewX509Engine(engine, svc.vaServerDomain)
+			x509Engine = x509engines.NewX509Engine(engine, svc.vaServerDomain).WithHybridSigners(svc.hybridSigners)
 			lFunc.Infof("creating CA %s with %s crypto engine", input.Subject.CommonName, x509Engine.GetEngineConfig().Provider)
 		} else {
 			errMsg := fmt.Sprintf("engine ID %s not configured", input.EngineID)
@@ -281,30 +615,30 @@ func (svc *CAServiceBackend) issueCA(ctx context.Context, input issueCAInput) (*
 
 	if input.ParentCA == nil {
 		lFunc.Debugf("creating ROOT CA certificate. common name: %s. key type: %s. key bits: %d", input.Subject.CommonName, input.KeyMetadata.Type, input.KeyMetadata.Bits)
-		caCert, err = x509Engine.CreateRootCA(input.CAID, input.KeyMetadata, input.Subject, expiration)
+		caCert, err = x509Engine.CreateHybridRootCA(input.CAID, input.KeyMetadata, input.HybridKey, input.Subject, expiration)
 		if err != nil {
 			lFunc.Errorf("something went wrong while creating CA '%s' Certificate: %s", input.Subject.CommonName, err)
 			return nil, err
 		}
 	} else {
 		if parentEngine, ok := svc.cryptoEngines[input.ParentCA.EngineID]; ok {
-			x509ParentEngine := x509engines.NewX509Engine(parentEngine, svc.vaServerDomain)
+			x509ParentEngine := x509engines.NewX509Engine(parentEngine, svc.vaServerDomain).WithHybridSigners(svc.hybridSigners)
 			if input.ParentCA.EngineID != input.EngineID {
 				if input.EngineID == "" {
-					x509Engine = x509engines.NewX509Engine(svc.defaultCryptoEngine, svc.vaServerDomain)
+					x509Engine = x509engines.NewX509Engine(svc.defaultCryptoEngine, svc.vaServerDomain).WithHybridSigners(svc.hybridSigners)
 				} else {
 					childEngine, ok := svc.cryptoEngines[input.EngineID]
 					if !ok {
-						lFunc.Errorf("something went wrong while doing the cast")
-						return nil, nil
+						lFunc.Errorf("engine ID %s not configured", input.EngineID)
+						return nil, errs.ErrCryptoEngineNotFound
 					}
-					x509Engine = x509engines.NewX509Engine(childEngine, svc.vaServerDomain)
+					x509Engine = x509engines.NewX509Engine(childEngine, svc.vaServerDomain).WithHybridSigners(svc.hybridSigners)
 				}
 			} else {
 				x509Engine = x509ParentEngine
 			}
 			lFunc.Debugf("creating SUBORDINATE CA certificate.common name: %s. key type: %s. key bits: %d", input.Subject.CommonName, input.KeyMetadata.Type, input.KeyMetadata.Bits)
-			caCert, err = x509Engine.CreateSubordinateCA(input.ParentCA.ID, input.CAID, (*x509.Certificate)(input.ParentCA.Certificate.Certificate), input.KeyMetadata, input.Subject, expiration, x509ParentEngine)
+			caCert, err = x509Engine.CreateHybridSubordinateCA(input.ParentCA.ID, input.CAID, (*x509.Certificate)(input.ParentCA.Certificate.Certificate), input.ParentCA.HybridKeyMetadata, input.KeyMetadata, input.HybridKey, input.Subject, expiration, x509ParentEngine)
 			if err != nil {
 				lFunc.Errorf("something went wrong while creating CA '%s' Certificate: %s", input.Subject.CommonName, err)
 				return nil, err
@@ -343,8 +677,14 @@ type ImportCAInput struct {
 //     The CA Type cannot have the value of MANAGED.
 //   - ErrCAValidCertAndPrivKey
 //     The CA certificate and the private key provided are not compatible.
+//   - ErrCAAlreadyExists
+//     A CA with the same ID already exists.
+//   - ErrCryptoEngineNotFound
+//     The EngineID does not match any of the configured crypto engines.
+//   - ErrCANotFound
+//     The parent CA does not exist.
 //   - ErrValidateBadRequest
-//     The required variables of the data structure are not valid.
+//     The required variables of the data structure are not valid or the CA certificate is not issued by the parent CA.
 func (svc *CAServiceBackend) ImportCA(ctx context.Context, input ImportCAInput) (*models.CACertificate, error) {
 	var err error
 
@@ -359,7 +699,46 @@ func (svc *CAServiceBackend) ImportCA(ctx context.Context, input ImportCAInput)
 		lFunc.Tracef("ImportCA struct validation success")
 	}
 
+	caID := input.ID
+	if caID == "" {
+		caID = goid.NewV4UUID().String()
+	} else {
+		exists, _, err := svc.caStorage.SelectExistsByID(ctx, caID)
+		if err != nil {
+			lFunc.Errorf("could not check if CA %s exists: %s", caID, err)
+			return nil, err
+		}
+
+		if exists {
+			lFunc.Errorf("CA %s already exists", caID)
+			return nil, errs.ErrCAAlreadyExists
+		}
+	}
+
 	caCert := input.CACertificate
+
+	var parentCA *models.CACertificate
+	if input.ParentID != "" {
+		exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.ParentID)
+		if err != nil {
+			lFunc.Errorf("could not check if parent CA %s exists: %s", input.ParentID, err)
+			return nil, err
+		}
+
+		if !exists {
+			lFunc.Errorf("parent CA %s does not exist", input.ParentID)
+			return nil, errs.ErrCANotFound
+		}
+
+		err = (*x509.Certificate)(caCert).CheckSignatureFrom((*x509.Certificate)(ca.Certificate.Certificate))
+		if err != nil {
+			lFunc.Errorf("CA certificate is not issued by parent CA %s: %s", input.ParentID, err)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		parentCA = ca
+	}
+
 	var engineID string
 	if input.CAType != models.CertificateTypeExternal {
 		lFunc.Debugf("importing CA %s - %s  private key. CA type: %s", helpers.SerialNumberToString(input.CACertificate.SerialNumber), input.CACertificate.Subject.CommonName, input.CAType)
@@ -369,7 +748,12 @@ func (svc *CAServiceBackend) ImportCA(ctx context.Context, input ImportCAInput)
 			engineID = svc.defaultCryptoEngineID
 			lFunc.Infof("importing CA %s - %s  with %s crypto engine", helpers.SerialNumberToString(input.CACertificate.SerialNumber), input.CACertificate.Subject.CommonName, engine.GetEngineConfig().Provider)
 		} else {
-			engine = *svc.cryptoEngines[input.EngineID]
+			selectedEngine, ok := svc.cryptoEngines[input.EngineID]
+			if !ok {
+				lFunc.Errorf("crypto engine %s not found", input.EngineID)
+				return nil, errs.ErrCryptoEngineNotFound
+			}
+			engine = *selectedEngine
 			engineID = input.EngineID
 			lFunc.Infof("importing CA %s - %s with %s crypto engine", helpers.SerialNumberToString(input.CACertificate.SerialNumber), input.CACertificate.Subject.CommonName, engine.GetEngineConfig().Provider)
 		}
@@ -393,31 +777,17 @@ func (svc *CAServiceBackend) ImportCA(ctx context.Context, input ImportCAInput)
 		}
 	}
 
-	caID := input.ID
-	if caID == "" {
-		caID = goid.NewV4UUID().String()
-	}
-
-	var parentCA *models.CACertificate
-	if input.ParentID != "" {
-		parentCA, err = svc.service.GetCAByID(ctx, GetCAByIDInput{
-			CAID: input.ParentID,
-		})
-		if err != nil {
-			return nil, fmt.Errorf("parent CA not found: %w", err)
-		}
-
-	}
-
 	issuerMeta := models.IssuerCAMetadata{
 		ID:           caID,
 		SerialNumber: helpers.SerialNumberToString(input.CACertificate.SerialNumber),
 		Level:        0,
 	}
 	level := 0
+	chainIDs := []string{}
 
 	if parentCA != nil {
 		level = parentCA.Level + 1
+		chainIDs = append(slices.Clone(parentCA.ChainIDs), parentCA.ID)
 		issuerMeta = models.IssuerCAMetadata{
 			ID:           input.ParentID,
 			SerialNumber: parentCA.SerialNumber,
@@ -432,6 +802,7 @@ func (svc *CAServiceBackend) ImportCA(ctx context.Context, input ImportCAInput)
 		IssuanceExpirationRef: input.IssuanceExpiration,
 		CreationTS:            time.Now(),
 		Level:                 level,
+		ChainIDs:              chainIDs,
 		Certificate: models.Certificate{
 			Certificate:         input.CACertificate,
 			Status:              models.StatusActive,
@@ -449,7 +820,17 @@ func (svc *CAServiceBackend) ImportCA(ctx context.Context, input ImportCAInput)
 	}
 
 	lFunc.Debugf("insert CA %s in storage engine", caID)
-	return svc.caStorage.Insert(ctx, ca)
+	ca, err = svc.caStorage.Insert(ctx, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventImported, map[string]any{
+		"type":          ca.Type,
+		"serial_number": ca.SerialNumber,
+	})
+
+	return ca, nil
 }
 
 type CreateCAInput struct {
@@ -461,6 +842,8 @@ type CreateCAInput struct {
 	CAExpiration       models.Expiration  `validate:"required"`
 	EngineID           string
 	Metadata           map[string]any
+	// HybridKeyMetadata, if set, creates a hybrid CA with an alternative post-quantum key held by the hybrid signer.
+	HybridKeyMetadata *models.HybridKeyMetadata
 }
 
 // Returned Error Codes:
@@ -469,9 +852,16 @@ type CreateCAInput struct {
 //   - ErrCAIssuanceExpiration
 //     When creating a CA, the Issuance Expiration is greater than the CA Expiration.
 //   - ErrCAType
-//     When creating the CA, the CA Type must have the value of MANAGED.
+//     When creating the CA, the CA Type must have the value of MANAGED. Also returned if the parent CA is an external CA.
+//   - ErrCANotFound
+//     The parent CA can not be found in the Database.
+//   - ErrCAStatus
+//     The parent CA is not active.
 //   - ErrValidateBadRequest
-//     The required variables of the data structure are not valid.
+//     The required variables of the data structure are not valid. Also returned if the hybrid key type is not a
+//     post-quantum key type or the hybrid signer is not configured.
+//   - ErrCASigningApprovalRequired
+//     The parent CA enforces dual control over certificate signing.
 func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput) (*models.CACertificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 	if input.Metadata == nil {
@@ -486,6 +876,24 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 		return nil, errs.ErrValidateBadRequest
 	}
 
+	err = helpers.ValidateSubjectOrder(input.Subject.Order)
+	if err != nil {
+		lFunc.Errorf("invalid CA subject order: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if input.HybridKeyMetadata != nil {
+		if !input.HybridKeyMetadata.Type.IsPostQuantum() {
+			lFunc.Errorf("hybrid key type %s is not a post-quantum key type", input.HybridKeyMetadata.Type)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		if _, ok := svc.hybridSigners[input.HybridKeyMetadata.SignerID]; !ok {
+			lFunc.Errorf("hybrid signer %s not configured", input.HybridKeyMetadata.SignerID)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
 	var parentCA *models.CACertificate
 	if input.ParentID != "" {
 		lFunc.Infof("request includes a parent CA id: %s", input.ParentID)
@@ -497,14 +905,30 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 
 		if !exists {
 			lFunc.Errorf("parent CA %s does not exist", input.ParentID)
+			return nil, errs.ErrCANotFound
 		}
 
 		lFunc.Debugf("parent CA %s exists", input.ParentID)
 
+		if ca.Status != models.StatusActive {
+			lFunc.Errorf("parent CA %s is in %s status. Only active CAs can issue subordinate CAs", input.ParentID, ca.Status)
+			return nil, errs.ErrCAStatus
+		}
+
+		if ca.Type == models.CertificateTypeExternal {
+			lFunc.Errorf("parent CA %s is an external CA without private key. It cannot issue subordinate CAs", input.ParentID)
+			return nil, errs.ErrCAType
+		}
+
+		err = svc.requireSigningApproval(ctx, ca, models.CASigningOperationCertificate)
+		if err != nil {
+			return nil, err
+		}
+
 		parentCA = ca
 		var caExpiration time.Time
 
-		if input.IssuanceExpiration.Type == models.Duration {
+		if input.CAExpiration.Type == models.Duration {
 			caExpiration = time.Now().Add((time.Duration)(*input.CAExpiration.Duration))
 		} else {
 			caExpiration = *input.CAExpiration.Time
@@ -545,6 +969,7 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 		CAExpiration: input.CAExpiration,
 		EngineID:     input.EngineID,
 		CAID:         caID,
+		HybridKey:    input.HybridKeyMetadata,
 	})
 	if err != nil {
 		lFunc.Errorf("could not create CA %s certificate: %s", input.Subject.CommonName, err)
@@ -560,6 +985,7 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 
 	caCert := issuedCA.Certificate
 	caLevel := 0
+	chainIDs := []string{}
 	issuerCAMeta := models.IssuerCAMetadata{
 		SerialNumber: helpers.SerialNumberToString(caCert.SerialNumber),
 		ID:           caID,
@@ -568,6 +994,7 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 
 	if parentCA != nil {
 		caLevel = parentCA.Level + 1
+		chainIDs = append(slices.Clone(parentCA.ChainIDs), parentCA.ID)
 		issuerCAMeta = models.IssuerCAMetadata{
 			SerialNumber: parentCA.SerialNumber,
 			ID:           parentCA.ID,
@@ -582,6 +1009,8 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 		IssuanceExpirationRef: input.IssuanceExpiration,
 		CreationTS:            time.Now(),
 		Level:                 caLevel,
+		ChainIDs:              chainIDs,
+		HybridKeyMetadata:     input.HybridKeyMetadata,
 		Certificate: models.Certificate{
 			Certificate:  (*models.X509Certificate)(caCert),
 			Status:       models.StatusActive,
@@ -603,7 +1032,18 @@ func (svc *CAServiceBackend) CreateCA(ctx context.Context, input CreateCAInput)
 	}
 
 	lFunc.Debugf("insert CA %s in storage engine", caID)
-	return svc.caStorage.Insert(ctx, &ca)
+	created, err := svc.caStorage.Insert(ctx, &ca)
+	if err != nil {
+		return nil, err
+	}
+
+	svc.recordCAEvent(ctx, created.ID, models.CAEventCreated, map[string]any{
+		"serial_number": created.SerialNumber,
+		"engine_id":     engineID,
+		"parent_id":     input.ParentID,
+	})
+
+	return created, nil
 }
 
 type GetCAByIDInput struct {
@@ -636,6 +1076,22 @@ func (svc *CAServiceBackend) GetCAByID(ctx context.Context, input GetCAByIDInput
 		return nil, errs.ErrCANotFound
 	}
 
+	ca.CAChain = []*models.X509Certificate{}
+	for _, parentID := range ca.ChainIDs {
+		exists, parentCA, err := svc.caStorage.SelectExistsByID(ctx, parentID)
+		if err != nil {
+			lFunc.Errorf("something went wrong while reading parent CA '%s' of CA '%s': %s", parentID, input.CAID, err)
+			return nil, err
+		}
+
+		if !exists {
+			lFunc.Warnf("parent CA '%s' of CA '%s' no longer exists. The returned chain is incomplete", parentID, input.CAID)
+			continue
+		}
+
+		ca.CAChain = append(ca.CAChain, parentCA.Certificate.Certificate)
+	}
+
 	return ca, err
 }
 
@@ -721,6 +1177,8 @@ type UpdateCAStatusInput struct {
 	CAID             string                   `validate:"required"`
 	Status           models.CertificateStatus `validate:"required"`
 	RevocationReason models.RevocationReason
+	// IfMatch is the If-Match precondition of the update, evaluated against the ETag of the stored CA.
+	IfMatch string
 }
 
 // Returned Error Codes:
@@ -728,8 +1186,12 @@ type UpdateCAStatusInput struct {
 //     The specified CA can not be found in the Database
 //   - ErrValidateBadRequest
 //     The required variables of the data structure are not valid.
+//   - ErrPreconditionFailed
+//     The CA has been modified since IfMatch was read.
 //   - ErrCAAlreadyRevoked
 //     CA already revoked
+//   - ErrCAActionPendingApproval
+//     Revoking the CA requires the approval of a second administrator. See ApprovePendingCAAction
 func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAStatusInput) (*models.CACertificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
@@ -751,16 +1213,37 @@ func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAS
 		return nil, errs.ErrCANotFound
 	}
 
+	if !helpers.ETagMatches(input.IfMatch, helpers.ETag(ca)) {
+		lFunc.Errorf("CA %s has been modified. If-Match precondition failed", input.CAID)
+		return nil, errs.ErrPreconditionFailed
+	}
+
 	if ca.Status == models.StatusExpired {
 		lFunc.Errorf("cannot update an expired CA certificate")
 		return nil, errs.ErrCertificateStatusTransitionNotAllowed
 	}
 
+	if ca.Status == models.StatusDeleting || input.Status == models.StatusDeleting {
+		lFunc.Errorf("the %s status is only managed by the CA deletion", models.StatusDeleting)
+		return nil, errs.ErrCertificateStatusTransitionNotAllowed
+	}
+
 	if ca.Status == models.StatusRevoked && ca.RevocationReason != ocsp.CertificateHold {
 		lFunc.Errorf("cannot update a revoke CA certificate in %s status. Only a revoked CA certificate with reason '6 - CertificateHold' can be unrevoked", ca.RevocationReason.String())
 		return nil, errs.ErrCertificateStatusTransitionNotAllowed
 	}
 
+	if input.Status == models.StatusRevoked {
+		err = svc.requireApproval(ctx, ca, models.CAPendingAction{
+			Type:             models.CAPendingActionRevoke,
+			RevocationReason: input.RevocationReason,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	prevStatus := ca.Status
 	ca.Status = input.Status
 	if ca.Status == models.StatusRevoked {
 		rrb, _ := input.RevocationReason.MarshalText()
@@ -770,12 +1253,21 @@ func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAS
 	}
 
 	lFunc.Debugf("updating the status of CA %s to %s", input.CAID, input.Status)
-	ca, err = svc.caStorage.Update(ctx, ca)
+	ca, err = updateIfMatch(ctx, svc.caStorage.UpdateIf, ca, input.IfMatch, caETag)
 	if err != nil {
 		lFunc.Errorf("could not update CA %s status: %s", input.CAID, err)
 		return nil, err
 	}
 
+	statusDetails := map[string]any{
+		"previous_status": prevStatus,
+		"status":          ca.Status,
+	}
+	if ca.Status == models.StatusRevoked {
+		statusDetails["revocation_reason"] = ca.RevocationReason
+	}
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventStatusUpdated, statusDetails)
+
 	if input.Status == models.StatusRevoked {
 		revokeCAFunc := func(ca models.CACertificate) {
 			_, err := svc.service.UpdateCAStatus(ctx, UpdateCAStatusInput{
@@ -799,6 +1291,7 @@ func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAS
 		}
 
 		ctr := 0
+		failed := 0
 		revokeCertFunc := func(c models.Certificate) {
 			lFunc.Infof("\n\n%d - %s\n\n", ctr, c.SerialNumber)
 			ctr++
@@ -808,6 +1301,7 @@ func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAS
 				RevocationReason: ocsp.CessationOfOperation,
 			})
 			if err != nil {
+				failed++
 				lFunc.Errorf("could not revoke certificate %s issued by CA %s", c.SerialNumber, c.IssuerCAMetadata.ID)
 			}
 		}
@@ -821,6 +1315,14 @@ func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAS
 		if err != nil {
 			return nil, err
 		}
+
+		if ctr > 0 {
+			svc.recordCAEvent(ctx, ca.ID, models.CAEventBulkRevocation, map[string]any{
+				"revoked_certificates": ctr - failed,
+				"failed_certificates":  failed,
+				"revocation_reason":    models.RevocationReason(ocsp.CessationOfOperation),
+			})
+		}
 	}
 
 	return ca, err
@@ -829,13 +1331,20 @@ func (svc *CAServiceBackend) UpdateCAStatus(ctx context.Context, input UpdateCAS
 type UpdateCAMetadataInput struct {
 	CAID     string                 `validate:"required"`
 	Metadata map[string]interface{} `validate:"required"`
+	// IfMatch is the If-Match precondition of the update, evaluated against the ETag of the stored CA.
+	IfMatch string
 }
 
+// UpdateCAMetadata replaces the metadata of the CA. The reserved keys managed by the CA service (i.e. the pending
+// action or the dual control policy of the CA) are kept, and can only be sent back unchanged. The dual control
+// policy can only be set while the CA has none, see CreateCASigningRequest to change it.
 // Returned Error Codes:
 //   - ErrCANotFound
 //     The specified CA can not be found in the Database
 //   - ErrValidateBadRequest
-//     The required variables of the data structure are not valid.
+//     The required variables of the data structure are not valid, or the metadata changes a reserved key.
+//   - ErrPreconditionFailed
+//     The CA has been modified since IfMatch was read.
 func (svc *CAServiceBackend) UpdateCAMetadata(ctx context.Context, input UpdateCAMetadataInput) (*models.CACertificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
@@ -857,28 +1366,286 @@ func (svc *CAServiceBackend) UpdateCAMetadata(ctx context.Context, input UpdateC
 		return nil, errs.ErrCANotFound
 	}
 
+	if !helpers.ETagMatches(input.IfMatch, helpers.ETag(ca)) {
+		lFunc.Errorf("CA %s has been modified. If-Match precondition failed", input.CAID)
+		return nil, errs.ErrPreconditionFailed
+	}
+
+	err = checkReservedCAMetadata(ca.Metadata, input.Metadata)
+	if err != nil {
+		lFunc.Errorf("invalid metadata for CA %s: %s", input.CAID, err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, dualControl := ca.Metadata[models.CAMetadataDualControlKey]
+	for _, key := range reservedCAMetadataKeys {
+		if key == models.CAMetadataTokenSigningKey && !dualControl {
+			continue
+		}
+
+		if value, ok := ca.Metadata[key]; ok {
+			input.Metadata[key] = value
+		}
+	}
+
 	ca.Metadata = input.Metadata
 
 	lFunc.Debugf("updating %s CA metadata", input.CAID)
-	return svc.caStorage.Update(ctx, ca)
+	return updateIfMatch(ctx, svc.caStorage.UpdateIf, ca, input.IfMatch, caETag)
 }
 
-type DeleteCAInput struct {
-	CAID string `validate:"required"`
+// reservedCAMetadataKeys are written by the CA service itself. Metadata updates can not change them, as they
+// would allow a single administrator to bypass the approvals of a second one.
+var reservedCAMetadataKeys = []string{
+	models.CAMetadataDualControlKey,
+	models.CAMetadataIssuancePausedKey,
+	models.CAMetadataTokenSigningKey,
+	models.CAMetadataIssuanceWebhookKey,
 }
 
-// Returned Error Codes:
-//   - ErrCANotFound
-//     The specified CA can not be found in the Database
-//   - ErrValidateBadRequest
-//     The required variables of the data structure are not valid.
-//   - ErrCAStatus
-//     Cannot delete a CA that is not expired or revoked.
-func (svc *CAServiceBackend) DeleteCA(ctx context.Context, input DeleteCAInput) error {
-	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+// checkReservedCAMetadata rejects the updated metadata if it changes the value of a reserved key. Reserved keys
+// missing in the updated metadata are not checked. Changes only restricting the CA are allowed: dual control can be
+// enabled on the CAs without it and the issuance of a CA can be paused. Token signing can only be toggled on the CAs
+// without dual control.
+func checkReservedCAMetadata(stored, updated map[string]any) error {
+	_, dualControl := stored[models.CAMetadataDualControlKey]
+	for _, key := range reservedCAMetadataKeys {
+		value, ok := updated[key]
+		if !ok {
+			continue
+		}
 
-	err := validate.Struct(input)
-	if err != nil {
+		switch key {
+		case models.CAMetadataDualControlKey:
+			if !dualControl {
+				continue
+			}
+		case models.CAMetadataIssuancePausedKey:
+			if paused, _ := value.(bool); paused {
+				continue
+			}
+		case models.CAMetadataTokenSigningKey:
+			if !dualControl {
+				continue
+			}
+		}
+
+		if !sameMetadataValue(stored[key], value) {
+			return fmt.Errorf("metadata key %s is reserved", key)
+		}
+	}
+
+	return nil
+}
+
+// sameMetadataValue compares the JSON encoding of both values, as the stored metadata may hold the structs
+// written by the service while the updated metadata holds the decoded JSON sent back by the client.
+func sameMetadataValue(a, b any) bool {
+	normalize := func(value any) (any, error) {
+		content, err := json.Marshal(value)
+		if err != nil {
+			return nil, err
+		}
+
+		var normalized any
+		err = json.Unmarshal(content, &normalized)
+		return normalized, err
+	}
+
+	normalizedA, err := normalize(a)
+	if err != nil {
+		return false
+	}
+
+	normalizedB, err := normalize(b)
+	if err != nil {
+		return false
+	}
+
+	return reflect.DeepEqual(normalizedA, normalizedB)
+}
+
+type UpdateCASettingsInput struct {
+	CAID string `validate:"required"`
+	// IssuanceExpiration replaces the expiration of the certificates signed by the CA. It is kept if nil.
+	IssuanceExpiration *models.Expiration
+	// ExpirationDeltas replaces the expiration monitoring thresholds of the CA. They are kept if nil.
+	ExpirationDeltas models.CAMetadataMonitoringExpirationDeltas
+	// Metadata is merged into the metadata of the CA. Keys with a null value are removed.
+	Metadata map[string]any
+	// IfMatch is the If-Match precondition of the update, evaluated against the ETag of the stored CA.
+	IfMatch string
+}
+
+// UpdateCASettings adjusts the settings fixed when the CA was created: the issuance expiration, the expiration
+// monitoring thresholds and the metadata. The settings are validated against the remaining lifetime of the CA.
+//
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, the expiration deltas are not positive,
+//     repeated or longer than the CA validity, or the metadata changes a reserved key.
+//   - ErrCAStatus
+//     CA is not active
+//   - ErrCAIssuanceExpiration
+//     The Issuance Expiration is greater than the CA Expiration.
+//   - ErrPreconditionFailed
+//     The CA has been modified since IfMatch was read.
+func (svc *CAServiceBackend) UpdateCASettings(ctx context.Context, input UpdateCASettingsInput) (*models.CACertificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("UpdateCASettingsInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if !helpers.ETagMatches(input.IfMatch, helpers.ETag(ca)) {
+		lFunc.Errorf("CA %s has been modified. If-Match precondition failed", input.CAID)
+		return nil, errs.ErrPreconditionFailed
+	}
+
+	if ca.Status != models.StatusActive {
+		lFunc.Errorf("%s CA is not active", ca.ID)
+		return nil, errs.ErrCAStatus
+	}
+
+	details := map[string]any{}
+	if input.IssuanceExpiration != nil {
+		if !helpers.ValidateExpirationTimeRef(*input.IssuanceExpiration) {
+			lFunc.Errorf("issuance expiration time ref is incompatible with the selected variable")
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		if !helpers.ValidateCAExpiration(*input.IssuanceExpiration, ca.ValidTo) {
+			lFunc.Errorf("issuance expiration is greater than the expiration of CA %s (%s)", ca.ID, ca.ValidTo)
+			return nil, errs.ErrCAIssuanceExpiration
+		}
+
+		details["previous_issuance_expiration"] = ca.IssuanceExpirationRef
+		details["issuance_expiration"] = *input.IssuanceExpiration
+		ca.IssuanceExpirationRef = *input.IssuanceExpiration
+	}
+
+	if ca.Metadata == nil {
+		ca.Metadata = map[string]any{}
+	}
+
+	err = checkReservedCAMetadata(ca.Metadata, input.Metadata)
+	if err != nil {
+		lFunc.Errorf("invalid metadata for CA %s: %s", input.CAID, err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	metadataKeys := []string{}
+	for key, value := range input.Metadata {
+		metadataKeys = append(metadataKeys, key)
+		if value == nil {
+			delete(ca.Metadata, key)
+		} else {
+			ca.Metadata[key] = value
+		}
+	}
+
+	if len(metadataKeys) > 0 {
+		sort.Strings(metadataKeys)
+		details["metadata_keys"] = metadataKeys
+	}
+
+	if input.ExpirationDeltas != nil {
+		var previous models.CAMetadataMonitoringExpirationDeltas
+		_, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataMonitoringExpirationDeltasKey, &previous)
+		if err != nil {
+			lFunc.Warnf("could not decode metadata with key %s: %s", models.CAMetadataMonitoringExpirationDeltasKey, err)
+		}
+
+		deltas, err := validateExpirationDeltas(input.ExpirationDeltas, previous, ca.ValidTo.Sub(ca.ValidFrom))
+		if err != nil {
+			lFunc.Errorf("invalid expiration deltas for CA %s: %s", ca.ID, err)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		details["expiration_deltas"] = deltas
+		ca.Metadata[models.CAMetadataMonitoringExpirationDeltasKey] = deltas
+	}
+
+	lFunc.Debugf("updating %s CA settings", input.CAID)
+	ca, err = updateIfMatch(ctx, svc.caStorage.UpdateIf, ca, input.IfMatch, caETag)
+	if err != nil {
+		lFunc.Errorf("could not update CA %s settings: %s", input.CAID, err)
+		return nil, err
+	}
+
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventSettingsUpdated, details)
+	return ca, nil
+}
+
+// validateExpirationDeltas checks the deltas are positive, uniquely named and do not exceed the CA validity. The
+// triggered flag of the unchanged deltas is kept, so the monitoring does not notify them again.
+func validateExpirationDeltas(deltas, previous models.CAMetadataMonitoringExpirationDeltas, validity time.Duration) (models.CAMetadataMonitoringExpirationDeltas, error) {
+	validated := models.CAMetadataMonitoringExpirationDeltas{}
+	names := map[string]bool{}
+	for _, delta := range deltas {
+		if delta.Name == "" || names[delta.Name] {
+			return nil, fmt.Errorf("delta name '%s' is empty or repeated", delta.Name)
+		}
+		names[delta.Name] = true
+
+		if delta.Delta <= 0 || time.Duration(delta.Delta) > validity {
+			return nil, fmt.Errorf("delta '%s' (%s) must be positive and not exceed the CA validity", delta.Name, delta.Delta.String())
+		}
+
+		delta.Triggered = slices.ContainsFunc(previous, func(prev models.MonitoringExpirationDelta) bool {
+			return prev.Name == delta.Name && prev.Delta == delta.Delta && prev.Triggered
+		})
+		validated = append(validated, delta)
+	}
+
+	return validated, nil
+}
+
+type DeleteCAInput struct {
+	CAID string `validate:"required"`
+	// Force deletes the CA even if it has active certificates. They are revoked before the CA is removed.
+	Force bool
+}
+
+// DeleteCA deletes the CA in two phases. The CA is moved to the DELETING status and, in the background, its
+// remaining active certificates are revoked, its key is removed from the crypto engine and the CA is removed
+// from the storage. The background phase of the CAs left in the DELETING status (i.e. by a restart) is resumed by
+// ResumeCADeletions.
+//
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCAStatus
+//     Cannot delete a CA that is not expired or revoked.
+//   - ErrCADeletionInProgress
+//     The CA is already being deleted.
+//   - ErrCAHasActiveCertificates
+//     The CA has active certificates and Force is not set.
+//   - ErrCAActionPendingApproval
+//     Deleting the CA requires the approval of a second administrator. See ApprovePendingCAAction
+func (svc *CAServiceBackend) DeleteCA(ctx context.Context, input DeleteCAInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
 		lFunc.Errorf("DeleteCA struct validation error: %s", err)
 		return errs.ErrValidateBadRequest
 	}
@@ -895,178 +1662,1395 @@ func (svc *CAServiceBackend) DeleteCA(ctx context.Context, input DeleteCAInput)
 		return errs.ErrCANotFound
 	}
 
+	if ca.Status == models.StatusDeleting {
+		lFunc.Errorf("CA %s is already being deleted", input.CAID)
+		return errs.ErrCADeletionInProgress
+	}
+
 	if ca.Status != models.StatusExpired && ca.Status != models.StatusRevoked {
 		lFunc.Errorf("CA %s can not be deleted while in status %s", input.CAID, ca.Status)
 		return errs.ErrCAStatus
 	}
 
-	err = svc.caStorage.Delete(ctx, input.CAID)
+	activeCerts, err := svc.certStorage.CountByCAIDAndStatus(ctx, input.CAID, models.StatusActive)
+	if err != nil {
+		lFunc.Errorf("could not count CA %s active certificates: %s", input.CAID, err)
+		return err
+	}
+
+	if activeCerts > 0 && !input.Force {
+		lFunc.Errorf("CA %s can not be deleted, it has %d active certificates", input.CAID, activeCerts)
+		return errs.ErrCAHasActiveCertificates
+	}
+
+	err = svc.requireApproval(ctx, ca, models.CAPendingAction{
+		Type:  models.CAPendingActionDelete,
+		Force: input.Force,
+	})
+	if err != nil {
+		return err
+	}
+
+	lFunc.Infof("moving CA %s to %s status", input.CAID, models.StatusDeleting)
+	prevStatus := ca.Status
+	ca.Status = models.StatusDeleting
+	// Only the request moving the CA to the DELETING status starts the background phase.
+	ca, err = svc.caStorage.UpdateIf(ctx, ca, func(current *models.CACertificate) bool {
+		return current.Status == prevStatus
+	})
+	if errors.Is(err, storage.ErrUpdateConflict) {
+		lFunc.Errorf("CA %s status changed while being deleted", input.CAID)
+		return errs.ErrCADeletionInProgress
+	} else if err != nil {
+		lFunc.Errorf("could not update CA %s status: %s", input.CAID, err)
+		return err
+	}
+
+	go svc.completeCADeletion(context.WithoutCancel(ctx), ca)
+	return nil
+}
+
+// ResumeCADeletions resumes the background phase of the deletion of the CAs left in the DELETING status, i.e. by
+// a restart of the service while deleting them. It is called once the service is set up.
+func (svc *CAServiceBackend) ResumeCADeletions(ctx context.Context) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	cas := []models.CACertificate{}
+	_, err := svc.caStorage.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			if ca.Status == models.StatusDeleting {
+				cas = append(cas, ca)
+			}
+		},
+		QueryParams: &resources.QueryParameters{},
+		ExtraOpts:   map[string]interface{}{},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list the CAs being deleted: %s", err)
+		return err
+	}
+
+	for _, ca := range cas {
+		lFunc.Infof("resuming the deletion of CA %s", ca.ID)
+		go svc.completeCADeletion(context.WithoutCancel(ctx), &ca)
+	}
+
+	return nil
+}
+
+// keyDeleter is implemented by the crypto engines able to remove keys.
+type keyDeleter interface {
+	DeleteKey(keyID string) error
+}
+
+// completeCADeletion revokes the remaining active certificates of a CA in the DELETING status, removes its key
+// from the crypto engine and deletes it from the storage. The CA is left in the DELETING status if any step fails.
+func (svc *CAServiceBackend) completeCADeletion(ctx context.Context, ca *models.CACertificate) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	certs := []models.Certificate{}
+	_, err := svc.certStorage.SelectByCAIDAndStatus(ctx, ca.ID, models.StatusActive, storage.StorageListRequest[models.Certificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(cert models.Certificate) {
+			certs = append(certs, cert)
+		},
+		QueryParams: &resources.QueryParameters{},
+		ExtraOpts:   map[string]interface{}{},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list CA %s active certificates: %s", ca.ID, err)
+		return
+	}
+
+	lFunc.Infof("revoking %d active certificates of CA %s", len(certs), ca.ID)
+	for _, cert := range certs {
+		_, err = svc.service.UpdateCertificateStatus(ctx, UpdateCertificateStatusInput{
+			SerialNumber:     cert.SerialNumber,
+			NewStatus:        models.StatusRevoked,
+			RevocationReason: ocsp.CessationOfOperation,
+		})
+		if err != nil {
+			lFunc.Errorf("could not revoke certificate %s of CA %s: %s", cert.SerialNumber, ca.ID, err)
+			return
+		}
+	}
+
+	if ca.Type != models.CertificateTypeExternal {
+		engine, ok := svc.cryptoEngines[ca.Certificate.EngineID]
+		if !ok {
+			lFunc.Errorf("crypto engine %s of CA %s is not configured", ca.Certificate.EngineID, ca.ID)
+			return
+		}
+
+		keyID := x509engines.CryptoAssetLRI(x509engines.CertificateAuthority, ca.Certificate.SerialNumber)
+		if deleter, ok := (*engine).(keyDeleter); ok {
+			lFunc.Infof("removing CA %s key from %s crypto engine", ca.ID, ca.Certificate.EngineID)
+			err = deleter.DeleteKey(keyID)
+			if err != nil {
+				lFunc.Errorf("could not remove CA %s key: %s", ca.ID, err)
+				return
+			}
+		} else {
+			lFunc.Warnf("crypto engine %s does not support key removal. CA %s key must be removed manually", ca.Certificate.EngineID, ca.ID)
+		}
+	}
+
+	err = svc.caStorage.Delete(ctx, ca.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while deleting the CA %s %s", ca.ID, err)
+		return
+	}
+
+	lFunc.Infof("CA %s deleted", ca.ID)
+}
+
+// approvedActionCtxKey marks the context of an operation already approved by a second administrator.
+// It is also propagated to the cascaded operations (i.e. the revocation of child CAs).
+type approvedActionCtxKey struct{}
+
+func callerID(ctx context.Context) string {
+	if id, ok := ctx.Value(string(identityextractors.CtxAuthID)).(string); ok {
+		return id
+	}
+	return ""
+}
+
+// verifiedCallerID returns the identity of the caller only if the authentication of the API verified it. The
+// identity of unverified tokens can be forged, so it must not be trusted to tell administrators apart.
+func verifiedCallerID(ctx context.Context) string {
+	if verified, ok := ctx.Value(string(identityextractors.CtxAuthVerified)).(bool); !ok || !verified {
+		return ""
+	}
+	return callerID(ctx)
+}
+
+// requireApproval registers the destructive operation as a pending action of the CA, waiting for
+// a second administrator to approve it. Returns nil if the operation can be executed right away.
+// The Type, RevocationReason and Force fields of the pending action are set by the caller.
+func (svc *CAServiceBackend) requireApproval(ctx context.Context, ca *models.CACertificate, action models.CAPendingAction) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if !svc.approvalEnabled {
+		return nil
+	}
+
+	if approved, ok := ctx.Value(approvedActionCtxKey{}).(bool); ok && approved {
+		return nil
+	}
+
+	now := time.Now()
+	pending, err := svc.latestPendingCAAction(ctx, ca.ID)
+	if err != nil {
+		lFunc.Errorf("could not get pending action of CA %s: %s", ca.ID, err)
+		return err
+	}
+
+	if pending != nil && pending.Type == action.Type && now.Before(pending.ExpiresAt) {
+		lFunc.Infof("CA %s already has a pending %s action requested by '%s'", ca.ID, pending.Type, pending.RequestedBy)
+		return errs.ErrCAActionPendingApproval
+	}
+
+	action.ID = goid.NewV4UUID().String()
+	action.CAID = ca.ID
+	action.RequestedBy = verifiedCallerID(ctx)
+	action.RequestedAt = now
+	action.ExpiresAt = now.Add(svc.approvalWindow)
+
+	lFunc.Infof("%s action over CA %s requires approval. Registering pending action", action.Type, ca.ID)
+	_, err = svc.pendingActionStorage.Insert(ctx, &action)
 	if err != nil {
-		lFunc.Errorf("something went wrong while deleting the CA %s %s", input.CAID, err)
+		lFunc.Errorf("could not register pending action for CA %s: %s", ca.ID, err)
 		return err
 	}
-	return err
+
+	return errs.ErrCAActionPendingApproval
+}
+
+// latestPendingCAAction returns the last requested action of the CA not approved yet, even if its approval window
+// expired. A new request supersedes the previous ones. Returns nil if the CA has no such action.
+func (svc *CAServiceBackend) latestPendingCAAction(ctx context.Context, caID string) (*models.CAPendingAction, error) {
+	var latest *models.CAPendingAction
+	_, err := svc.pendingActionStorage.SelectByCA(ctx, caID, storage.StorageListRequest[models.CAPendingAction]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(action models.CAPendingAction) {
+			if action.ApprovedBy != "" {
+				return
+			}
+
+			if latest == nil || action.RequestedAt.After(latest.RequestedAt) {
+				latest = &action
+			}
+		},
+		QueryParams: &resources.QueryParameters{},
+		ExtraOpts:   map[string]interface{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return latest, nil
+}
+
+type GetPendingCAActionInput struct {
+	CAID string `validate:"required"`
+}
+
+// GetPendingCAAction returns the destructive operation waiting for approval on the CA.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCAPendingActionNotFound
+//     The CA has no pending operation, or its approval window expired
+func (svc *CAServiceBackend) GetPendingCAAction(ctx context.Context, input GetPendingCAActionInput) (*models.CAPendingAction, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetPendingCAActionInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, _, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if svc.pendingActionStorage == nil {
+		return nil, errs.ErrCAPendingActionNotFound
+	}
+
+	action, err := svc.latestPendingCAAction(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("could not get pending action of CA %s: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if action == nil || time.Now().After(action.ExpiresAt) {
+		lFunc.Errorf("CA %s has no pending action", input.CAID)
+		return nil, errs.ErrCAPendingActionNotFound
+	}
+
+	return action, nil
+}
+
+type ApprovePendingCAActionInput struct {
+	CAID string `validate:"required"`
+}
+
+// ApprovePendingCAAction approves and executes the destructive operation pending on the CA: its revocation, which
+// revokes every certificate issued by the CA, its deletion, which revokes the remaining certificates and removes
+// the CA key from the crypto engine, or the removal of the copy of its key left in the source engine of a migration. The approver must be a different administrator than the one who requested the
+// operation. Each action is approved once, even by concurrent approvals handled by different replicas.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCAPendingActionNotFound
+//     The CA has no pending operation
+//   - ErrCAPendingActionExpired
+//     The approval window of the pending operation expired
+//   - ErrCAPendingActionSelfApproval
+//     The approver is the administrator who requested the operation
+//   - ErrCAApprovalUnverifiedIdentity
+//     The identity of the approver was not verified by the authentication of the API
+//   - ErrCAKeyInUse
+//     The CA key to remove is in use again, i.e. the CA was migrated back to the engine
+func (svc *CAServiceBackend) ApprovePendingCAAction(ctx context.Context, input ApprovePendingCAActionInput) (*models.CAPendingAction, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("ApprovePendingCAActionInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if svc.pendingActionStorage == nil {
+		lFunc.Errorf("CA %s has no pending action", input.CAID)
+		return nil, errs.ErrCAPendingActionNotFound
+	}
+
+	action, err := svc.latestPendingCAAction(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("could not get pending action of CA %s: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if action == nil {
+		lFunc.Errorf("CA %s has no pending action", input.CAID)
+		return nil, errs.ErrCAPendingActionNotFound
+	}
+
+	now := time.Now()
+	if now.After(action.ExpiresAt) {
+		lFunc.Errorf("pending %s action over CA %s expired at %s", action.Type, input.CAID, action.ExpiresAt)
+		return nil, errs.ErrCAPendingActionExpired
+	}
+
+	approver := verifiedCallerID(ctx)
+	if approver == "" {
+		lFunc.Errorf("pending %s action over CA %s can only be approved by an administrator verified by the API authentication", action.Type, input.CAID)
+		return nil, errs.ErrCAApprovalUnverifiedIdentity
+	}
+
+	if approver == action.RequestedBy {
+		lFunc.Errorf("pending %s action over CA %s must be approved by a different administrator than '%s'", action.Type, input.CAID, action.RequestedBy)
+		return nil, errs.ErrCAPendingActionSelfApproval
+	}
+
+	action.ApprovedBy = approver
+	action.ApprovedAt = &now
+
+	action, err = svc.pendingActionStorage.UpdateIf(ctx, action, func(current *models.CAPendingAction) bool {
+		return current.ApprovedBy == ""
+	})
+	if errors.Is(err, storage.ErrUpdateConflict) {
+		lFunc.Errorf("pending action %s of CA %s has already been approved", action.ID, input.CAID)
+		return nil, errs.ErrCAPendingActionNotFound
+	} else if err != nil {
+		lFunc.Errorf("could not approve pending action of CA %s: %s", input.CAID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("%s action over CA %s requested by '%s' approved by '%s'", action.Type, input.CAID, action.RequestedBy, approver)
+	approvedCtx := context.WithValue(ctx, approvedActionCtxKey{}, true)
+	switch action.Type {
+	case models.CAPendingActionDelete:
+		err = svc.service.DeleteCA(approvedCtx, DeleteCAInput{
+			CAID:  input.CAID,
+			Force: action.Force,
+		})
+	case models.CAPendingActionRevoke:
+		_, err = svc.service.UpdateCAStatus(approvedCtx, UpdateCAStatusInput{
+			CAID:             input.CAID,
+			Status:           models.StatusRevoked,
+			RevocationReason: action.RevocationReason,
+		})
+	case models.CAPendingActionDeleteKey:
+		err = svc.removeCAKey(approvedCtx, ca, action.EngineID)
+	default:
+		err = fmt.Errorf("unknown pending action type %s", action.Type)
+	}
+
+	if err != nil {
+		lFunc.Errorf("could not execute approved %s action over CA %s: %s", action.Type, input.CAID, err)
+		return nil, err
+	}
+
+	return action, nil
+}
+
+type SignCertificateInput struct {
+	CAID           string                         `validate:"required"`
+	CertRequest    *models.X509CertificateRequest `validate:"required"`
+	Subject        *models.Subject
+	SignVerbatim   bool
+	SigningProfile *models.SigningProfile
+	// CertificateProfileID pins the certificate profile the request must satisfy. The profile bound to the CA (if any)
+	// is always enforced: the request is rejected if CertificateProfileID references another profile.
+	CertificateProfileID string
+	// IssuanceContext is not used to sign the certificate. It is recorded in the certificate metadata and propagated
+	// to the sign certificate event. It authorizes the DMS owners to revoke the certificate, so the HTTP API only
+	// accepts it from the internal services (i.e. the DMS manager).
+	IssuanceContext *models.CertificateIssuanceContext
+}
+
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCAStatus
+//     CA is not active
+//   - ErrValidateBadRequest
+//     The subject or signing profile subject order references unknown or repeated attributes, or the signing
+//     profile validity is not positive.
+//   - ErrCertificateProfileNotFound
+//     The referenced certificate profile can not be found in the Database.
+//   - ErrCertificateProfileViolation
+//     The certificate request does not satisfy the certificate profile, or the referenced profile is bound to other CAs.
+//   - ErrCertificateRequestLimits
+//     The certificate request exceeds the configured size, SAN or extension limits.
+//   - ErrCertificateIssuanceVetoed
+//     The issuance webhook of the CA denied the certificate.
+//   - ErrCertificateIssuanceWebhook
+//     The issuance webhook of the CA could not review the certificate and does not fail open.
+//   - ErrCASigningApprovalRequired
+//     The CA enforces dual control over certificate signing. See CreateCASigningRequest
+func (svc *CAServiceBackend) SignCertificate(ctx context.Context, input SignCertificateInput) (*models.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("SignCertificateInput struct validation error: %s", err)
+		return nil, errs.ErrCANotFound
+	}
+
+	err = helpers.ValidateCertificateRequestLimits((*x509.CertificateRequest)(input.CertRequest), svc.csrLimits)
+	if err != nil {
+		lFunc.Errorf("rejecting certificate request: %s", err)
+		return nil, errs.ErrCertificateRequestLimits
+	}
+
+	if input.Subject != nil {
+		err = helpers.ValidateSubjectOrder(input.Subject.Order)
+		if err != nil {
+			lFunc.Errorf("invalid subject order: %s", err)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
+	if input.SigningProfile != nil {
+		err = helpers.ValidateSubjectOrder(input.SigningProfile.SubjectOrder)
+		if err != nil {
+			lFunc.Errorf("invalid signing profile subject order: %s", err)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		if input.SigningProfile.Validity != nil && *input.SigningProfile.Validity <= 0 {
+			lFunc.Errorf("invalid signing profile validity: %s", input.SigningProfile.Validity)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Status != models.StatusActive {
+		lFunc.Errorf("%s CA is not active", ca.ID)
+		return nil, errs.ErrCAStatus
+	}
+
+	if paused, _ := ca.Metadata[models.CAMetadataIssuancePausedKey].(bool); paused {
+		lFunc.Errorf("%s CA issuance is paused", ca.ID)
+		return nil, errs.ErrCAIssuancePaused
+	}
+
+	err = svc.requireSigningApproval(ctx, ca, models.CASigningOperationCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := svc.signingEngine(ctx, ca)
+
+	x509Engine := x509engines.NewX509Engine(engine, svc.vaServerDomain).WithHybridSigners(svc.hybridSigners)
+
+	caCert := (*x509.Certificate)(ca.Certificate.Certificate)
+	csr := (*x509.CertificateRequest)(input.CertRequest)
+
+	if !input.SignVerbatim {
+		csr.Subject = helpers.SubjectToPkixName(*input.Subject)
+	}
+
+	expiration := time.Now()
+	if ca.IssuanceExpirationRef.Type == models.Duration {
+		expiration = expiration.Add(time.Duration(*ca.IssuanceExpirationRef.Duration))
+	} else {
+		expiration = *ca.IssuanceExpirationRef.Time
+	}
+	profile := input.SigningProfile
+
+	if profile != nil && profile.Validity != nil {
+		profileExpiration := time.Now().Add(time.Duration(*profile.Validity))
+		if profileExpiration.After(expiration) {
+			lFunc.Warnf("signing profile validity %s exceeds the issuance expiration of CA %s. Capping to the issuance expiration", profile.Validity, ca.ID)
+		} else {
+			expiration = profileExpiration
+		}
+	}
+
+	certProfile, err := svc.resolveCertificateProfile(ctx, lFunc, ca, input.CertificateProfileID)
+	if err != nil {
+		return nil, err
+	}
+
+	if certProfile != nil {
+		lFunc.Debugf("validating certificate request against certificate profile %s", certProfile.ID)
+		err = helpers.ValidateCertificateRequestWithProfile(csr, helpers.PkixNameToSubject(csr.Subject), *certProfile)
+		if err != nil {
+			lFunc.Errorf("certificate request does not satisfy certificate profile %s: %s", certProfile.ID, err)
+			return nil, errs.ErrCertificateProfileViolation
+		}
+
+		if certProfile.MaxValidity != nil {
+			maxExpiration := time.Now().Add(time.Duration(*certProfile.MaxValidity))
+			if maxExpiration.Before(expiration) {
+				expiration = maxExpiration
+			}
+		}
+
+		usagesProfile := models.SigningProfile{}
+		if profile != nil {
+			usagesProfile = *profile
+		}
+		if len(certProfile.KeyUsages) > 0 {
+			usagesProfile.KeyUsages = certProfile.KeyUsages
+		}
+		if len(certProfile.ExtendedKeyUsages) > 0 {
+			usagesProfile.ExtendedKeyUsages = certProfile.ExtendedKeyUsages
+		}
+		profile = &usagesProfile
+	}
+
+	if profile == nil || profile.NotBeforeBackdate == nil {
+		var backdate *models.TimeDuration
+		if certProfile != nil && certProfile.NotBeforeBackdate != nil {
+			backdate = certProfile.NotBeforeBackdate
+		} else {
+			var caBackdate models.TimeDuration
+			hasBackdate, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataNotBeforeBackdateKey, &caBackdate)
+			if err != nil {
+				lFunc.Warnf("ignoring invalid not before backdate of CA %s: %s", ca.ID, err)
+			} else if hasBackdate {
+				backdate = &caBackdate
+			}
+		}
+
+		if backdate != nil {
+			backdateProfile := models.SigningProfile{}
+			if profile != nil {
+				backdateProfile = *profile
+			}
+			backdateProfile.NotBeforeBackdate = backdate
+			profile = &backdateProfile
+		}
+	}
+
+	var webhook models.CAIssuanceWebhook
+	hasWebhook, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataIssuanceWebhookKey, &webhook)
+	if err != nil {
+		lFunc.Errorf("could not decode issuance webhook of CA %s: %s", ca.ID, err)
+		return nil, errs.ErrCertificateIssuanceWebhook
+	}
+
+	if hasWebhook && webhook.URL != "" {
+		expiration, profile, err = svc.reviewCertificateIssuance(ctx, lFunc, webhook, ca.ID, csr, expiration, profile, certProfile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(svc.crlDistributionPoints) > 0 && (profile == nil || len(profile.CRLDistributionPoints) == 0) {
+		crlProfile := models.SigningProfile{}
+		if profile != nil {
+			crlProfile = *profile
+		}
+
+		for _, crlDP := range svc.crlDistributionPoints {
+			crlProfile.CRLDistributionPoints = append(crlProfile.CRLDistributionPoints, fmt.Sprintf("%s/%s", strings.TrimSuffix(crlDP, "/"), ca.ID))
+		}
+		profile = &crlProfile
+	}
+
+	lFunc.Debugf("sign certificate request with %s CA and %s crypto engine", input.CAID, x509Engine.GetEngineConfig().Provider)
+	x509Cert, err := x509Engine.SignHybridCertificateRequest(caCert, ca.HybridKeyMetadata, csr, expiration, profile)
+	if err != nil {
+		lFunc.Errorf("could not sign certificate request with %s CA", caCert.Subject.CommonName)
+		return nil, err
+	}
+
+	cert := models.Certificate{
+		Metadata:    map[string]interface{}{},
+		Type:        models.CertificateTypeExternal,
+		Certificate: (*models.X509Certificate)(x509Cert),
+		IssuerCAMetadata: models.IssuerCAMetadata{
+			SerialNumber: helpers.SerialNumberToString(caCert.SerialNumber),
+			ID:           ca.ID,
+		},
+		Status:              models.StatusActive,
+		KeyMetadata:         helpers.KeyStrengthMetadataFromCertificate(x509Cert),
+		Subject:             helpers.PkixNameToSubject(x509Cert.Subject),
+		SerialNumber:        helpers.SerialNumberToString(x509Cert.SerialNumber),
+		ValidFrom:           x509Cert.NotBefore,
+		ValidTo:             x509Cert.NotAfter,
+		RevocationTimestamp: time.Time{},
+	}
+	if issuanceCtx := input.IssuanceContext; issuanceCtx != nil && (issuanceCtx.DMSID != "" || issuanceCtx.DeviceID != "") {
+		cert.Metadata[models.CertificateMetadataIssuanceContextKey] = models.CertificateIssuanceContext{
+			DMSID:     issuanceCtx.DMSID,
+			DeviceID:  issuanceCtx.DeviceID,
+			RequestID: issuanceCtx.RequestID,
+		}
+	}
+
+	if svc.issuanceLogStorage != nil {
+		err = svc.appendIssuanceLogEntry(ctx, ca.ID, x509Cert)
+		if err != nil {
+			lFunc.Errorf("could not append certificate %s to the issuance log of CA %s: %s", cert.SerialNumber, ca.ID, err)
+			return nil, err
+		}
+	}
+
+	lFunc.Debugf("insert Certificate %s in storage engine", cert.SerialNumber)
+	return svc.certStorage.Insert(ctx, &cert)
+}
+
+type SignCertificatesBatchInput struct {
+	CAID string `validate:"required"`
+	// Requests are signed as independent SignCertificate calls. Their CAID is ignored.
+	Requests []SignCertificateInput `validate:"required"`
+}
+
+// SignCertificatesBatch signs the certificate requests concurrently, bounded by the batch signing workers. The
+// failure of a request does not abort the batch: the result of each request holds either its certificate or its
+// error, in the order of the requests.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The batch is empty or exceeds the maximum batch size.
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCAStatus
+//     CA is not active
+func (svc *CAServiceBackend) SignCertificatesBatch(ctx context.Context, input SignCertificatesBatchInput) ([]models.CertificateSigningResult, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("SignCertificatesBatchInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if len(input.Requests) == 0 || len(input.Requests) > svc.csrLimits.MaxBatchSize {
+		lFunc.Errorf("batch of %d certificate requests is empty or exceeds the maximum batch size %d", len(input.Requests), svc.csrLimits.MaxBatchSize)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Status != models.StatusActive {
+		lFunc.Errorf("%s CA is not active", ca.ID)
+		return nil, errs.ErrCAStatus
+	}
+
+	results := make([]models.CertificateSigningResult, len(input.Requests))
+	requests := make(chan int)
+	workers := min(svc.batchSigningWorkers, len(input.Requests))
+
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for idx := range requests {
+				results[idx].Index = idx
+				if err := ctx.Err(); err != nil {
+					results[idx].Error = err.Error()
+					continue
+				}
+
+				req := input.Requests[idx]
+				req.CAID = input.CAID
+				crt, err := svc.service.SignCertificate(ctx, req)
+				if err != nil {
+					results[idx].Error = err.Error()
+					continue
+				}
+				results[idx].Certificate = crt
+			}
+		}()
+	}
+
+	for idx := range input.Requests {
+		requests <- idx
+	}
+	close(requests)
+	wg.Wait()
+
+	failed := 0
+	for _, result := range results {
+		if result.Error != "" {
+			failed++
+		}
+	}
+	lFunc.Infof("signed batch of %d certificate requests with CA %s: %d failed", len(results), input.CAID, failed)
+
+	return results, nil
+}
+
+type CreateCertificateInput struct {
+	KeyMetadata models.KeyMetadata `validate:"required"`
+	Subject     models.Subject     `validate:"required"`
+}
+
+func (svc *CAServiceBackend) CreateCertificate(ctx context.Context, input CreateCertificateInput) (*models.Certificate, error) {
+	return nil, fmt.Errorf("TODO")
+}
+
+type ImportCertificateInput struct {
+	ImportMode  models.CertificateType
+	Certificate *models.X509Certificate
+	Metadata    map[string]any
+}
+
+func (svc *CAServiceBackend) ImportCertificate(ctx context.Context, input ImportCertificateInput) (*models.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	status := models.StatusActive
+	if input.Certificate.NotAfter.Before(time.Now()) {
+		status = models.StatusExpired
+	}
+
+	newCert := models.Certificate{
+		Metadata:            input.Metadata,
+		Type:                models.CertificateTypeExternal,
+		Certificate:         (*models.X509Certificate)(input.Certificate),
+		Status:              status,
+		KeyMetadata:         helpers.KeyStrengthMetadataFromCertificate((*x509.Certificate)(input.Certificate)),
+		Subject:             helpers.PkixNameToSubject(input.Certificate.Subject),
+		SerialNumber:        helpers.SerialNumberToString(input.Certificate.SerialNumber),
+		ValidFrom:           input.Certificate.NotBefore,
+		ValidTo:             input.Certificate.NotAfter,
+		RevocationTimestamp: time.Time{},
+	}
+
+	var parentCA *models.CACertificate
+	svc.caStorage.SelectByCommonName(ctx, input.Certificate.Issuer.CommonName, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			err := helpers.ValidateCertificate((*x509.Certificate)(ca.Certificate.Certificate), (*x509.Certificate)(input.Certificate), false)
+			if err == nil {
+				parentCA = &ca
+			}
+		},
+	})
+
+	if parentCA != nil {
+		newCert.IssuerCAMetadata = models.IssuerCAMetadata{
+			SerialNumber: parentCA.SerialNumber,
+			ID:           parentCA.ID,
+			Level:        parentCA.Level,
+		}
+	} else {
+		newCert.IssuerCAMetadata = models.IssuerCAMetadata{
+			SerialNumber: "-",
+			ID:           "-",
+			Level:        -1,
+		}
+	}
+
+	cert, err := svc.certStorage.Insert(ctx, &newCert)
+	if err != nil {
+		lFunc.Errorf("could not insert certificate: %s", err)
+		return nil, err
+	}
+
+	return cert, nil
+}
+
+type MigrateCAKeyInput struct {
+	CAID           string `validate:"required"`
+	TargetEngineID string `validate:"required"`
+	// RemoveSourceKey removes the key from the source engine once the CA uses the target engine.
+	RemoveSourceKey bool
+}
+
+// MigrateCAKey copies the private key of a CA into another crypto engine and, once a test signature
+// made with the migrated key has been verified against the CA certificate, updates the CA record to use
+// the target engine. The key is only removed from the source engine if RemoveSourceKey is set. With the approval
+// of destructive operations enabled, the removal is registered as a DELETE_CA_KEY pending action and the key is kept
+// until a second administrator approves it; the migration itself is not delayed.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCryptoEngineNotFound
+//     The target engine is not configured
+//   - ErrCAType
+//     The CA has no private key managed by Lamassu
+//   - ErrCAKeyNotExportable
+//     The source engine does not allow exporting the private key (i.e. KMS or PKCS11)
+//   - ErrCAKeyMigrationVerification
+//     The test signature made with the migrated key could not be verified
+//   - ErrCAKeyMigrationConflict
+//     The engine of the CA changed while its key was being migrated
+//   - ErrCASigningApprovalRequired
+//     The CA enforces dual control. Like their export, the keys of dual control CAs can not be copied to other engines.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) MigrateCAKey(ctx context.Context, input MigrateCAKeyInput) (*models.CACertificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("MigrateCAKey struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Type == models.CertificateTypeExternal {
+		lFunc.Errorf("CA %s is of type %s and has no private key to migrate", ca.ID, ca.Type)
+		return nil, errs.ErrCAType
+	}
+
+	if ca.Certificate.EngineID == input.TargetEngineID {
+		lFunc.Infof("CA %s key is already stored in engine %s", ca.ID, input.TargetEngineID)
+		return ca, nil
+	}
+
+	err = svc.copyCAKey(ctx, ca, input.TargetEngineID)
+	if err != nil {
+		return nil, err
+	}
+
+	prevEngineID := ca.Certificate.EngineID
+	ca.Certificate.EngineID = input.TargetEngineID
+
+	lFunc.Debugf("updating CA %s engine from %s to %s", ca.ID, prevEngineID, input.TargetEngineID)
+	ca, err = svc.caStorage.UpdateIf(ctx, ca, func(current *models.CACertificate) bool {
+		return current.Certificate.EngineID == prevEngineID
+	})
+	if errors.Is(err, storage.ErrUpdateConflict) {
+		lFunc.Errorf("CA %s engine changed while its key was being migrated to %s", input.CAID, input.TargetEngineID)
+		return nil, errs.ErrCAKeyMigrationConflict
+	} else if err != nil {
+		lFunc.Errorf("could not update CA %s engine in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("CA %s key migrated from engine %s to %s", ca.ID, prevEngineID, input.TargetEngineID)
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventKeyMigrated, map[string]any{
+		"previous_engine_id": prevEngineID,
+		"engine_id":          input.TargetEngineID,
+	})
+
+	if input.RemoveSourceKey {
+		err = svc.removeCAKey(ctx, ca, prevEngineID)
+		if err == errs.ErrCAActionPendingApproval {
+			lFunc.Infof("removal of CA %s key from engine %s is pending approval", ca.ID, prevEngineID)
+		} else if err != nil {
+			return nil, err
+		}
+	}
+
+	return ca, nil
+}
+
+// removeCAKey removes the CA key from an engine the CA no longer uses, neither as its engine nor as its fallback
+// engine. The removal is a DELETE_CA_KEY destructive operation.
+func (svc *CAServiceBackend) removeCAKey(ctx context.Context, ca *models.CACertificate, engineID string) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	fallbackID, _ := ca.Metadata[models.CAMetadataFallbackEngineKey].(string)
+	if engineID == ca.Certificate.EngineID || engineID == fallbackID {
+		lFunc.Errorf("CA %s key can not be removed from engine %s. the CA still uses it", ca.ID, engineID)
+		return errs.ErrCAKeyInUse
+	}
+
+	engine, ok := svc.cryptoEngines[engineID]
+	if !ok {
+		lFunc.Errorf("crypto engine %s is not configured", engineID)
+		return errs.ErrCryptoEngineNotFound
+	}
+
+	err := svc.requireApproval(ctx, ca, models.CAPendingAction{
+		Type:     models.CAPendingActionDeleteKey,
+		EngineID: engineID,
+	})
+	if err != nil {
+		return err
+	}
+
+	deleter, ok := (*engine).(keyDeleter)
+	if !ok {
+		lFunc.Warnf("crypto engine %s does not support key removal. CA %s key must be removed manually", engineID, ca.ID)
+		return nil
+	}
+
+	lFunc.Infof("removing CA %s key from %s crypto engine", ca.ID, engineID)
+	keyID := x509engines.CryptoAssetLRI(x509engines.CertificateAuthority, ca.Certificate.SerialNumber)
+	err = deleter.DeleteKey(keyID)
+	if err != nil {
+		lFunc.Errorf("could not remove CA %s key from engine %s: %s", ca.ID, engineID, err)
+		return err
+	}
+
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventKeyRemoved, map[string]any{
+		"engine_id": engineID,
+	})
+
+	return nil
+}
+
+// copyCAKey imports the private key of a CA into the target engine and verifies a test signature made with the
+// imported key against the CA certificate. Copying the key exports it from the source engine, so it is guarded as
+// an EXPORT_KEY operation.
+func (svc *CAServiceBackend) copyCAKey(ctx context.Context, ca *models.CACertificate, targetEngineID string) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := svc.requireSigningApproval(ctx, ca, models.CASigningOperationKeyExport)
+	if err != nil {
+		return err
+	}
+
+	sourceEngine, ok := svc.cryptoEngines[ca.Certificate.EngineID]
+	if !ok {
+		lFunc.Errorf("source engine %s for CA %s is not configured", ca.Certificate.EngineID, ca.ID)
+		return errs.ErrCryptoEngineNotFound
+	}
+
+	targetEngine, ok := svc.cryptoEngines[targetEngineID]
+	if !ok {
+		lFunc.Errorf("target engine %s is not configured", targetEngineID)
+		return errs.ErrCryptoEngineNotFound
+	}
+
+	caCert := (*x509.Certificate)(ca.Certificate.Certificate)
+	keyID := x509engines.CryptoAssetLRI(x509engines.CertificateAuthority, helpers.SerialNumberToString(caCert.SerialNumber))
+
+	lFunc.Debugf("reading CA %s key from engine %s", ca.ID, ca.Certificate.EngineID)
+	signer, err := (*sourceEngine).GetPrivateKeyByID(keyID)
+	if err != nil {
+		lFunc.Errorf("could not get CA %s key from engine %s: %s", ca.ID, ca.Certificate.EngineID, err)
+		return err
+	}
+
+	var copiedSigner crypto.Signer
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		copiedSigner, err = (*targetEngine).ImportRSAPrivateKey(key, keyID)
+	case *ecdsa.PrivateKey:
+		copiedSigner, err = (*targetEngine).ImportECDSAPrivateKey(key, keyID)
+	default:
+		lFunc.Errorf("CA %s key stored in engine %s is not exportable", ca.ID, ca.Certificate.EngineID)
+		return errs.ErrCAKeyNotExportable
+	}
+	if err != nil {
+		lFunc.Errorf("could not import CA %s key into engine %s: %s", ca.ID, targetEngineID, err)
+		return err
+	}
+
+	lFunc.Debugf("verifying test signature made with the key copied into engine %s", targetEngineID)
+	digest := sha256.Sum256([]byte(fmt.Sprintf("lamassu key migration check for CA %s", ca.ID)))
+	signature, err := copiedSigner.Sign(rand.Reader, digest[:], crypto.SHA256)
+	if err != nil {
+		lFunc.Errorf("could not sign with the copied key: %s", err)
+		return errs.ErrCAKeyMigrationVerification
+	}
+
+	valid := false
+	switch pub := caCert.PublicKey.(type) {
+	case *rsa.PublicKey:
+		valid = rsa.VerifyPKCS1v15(pub, crypto.SHA256, digest[:], signature) == nil
+	case *ecdsa.PublicKey:
+		valid = ecdsa.VerifyASN1(pub, digest[:], signature)
+	}
+	if !valid {
+		lFunc.Errorf("test signature made with the key copied into engine %s does not match CA %s certificate", targetEngineID, ca.ID)
+		return errs.ErrCAKeyMigrationVerification
+	}
+
+	return nil
+}
+
+type SetCAFallbackEngineInput struct {
+	CAID     string `validate:"required"`
+	EngineID string
+}
+
+// SetCAFallbackEngine copies the private key of a CA into a second crypto engine, used to sign while the engine
+// of the CA is unavailable. An empty EngineID removes the fallback engine of the CA. The key is not removed from
+// the previous fallback engine.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCryptoEngineNotFound
+//     The fallback engine is not configured
+//   - ErrCAType
+//     The CA has no private key managed by Lamassu
+//   - ErrCAKeyNotExportable
+//     The engine of the CA does not allow exporting the private key (i.e. KMS or PKCS11)
+//   - ErrCAKeyMigrationVerification
+//     The test signature made with the copied key could not be verified
+//   - ErrCASigningApprovalRequired
+//     The CA enforces dual control. The keys of dual control CAs can not be copied to other engines.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, or the fallback engine is the engine of the CA.
+func (svc *CAServiceBackend) SetCAFallbackEngine(ctx context.Context, input SetCAFallbackEngineInput) (*models.CACertificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("SetCAFallbackEngine struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Type == models.CertificateTypeExternal {
+		lFunc.Errorf("CA %s is of type %s and has no private key", ca.ID, ca.Type)
+		return nil, errs.ErrCAType
+	}
+
+	if input.EngineID == ca.Certificate.EngineID {
+		lFunc.Errorf("fallback engine of CA %s must be different from its engine %s", ca.ID, ca.Certificate.EngineID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if ca.Metadata == nil {
+		ca.Metadata = map[string]any{}
+	}
+
+	if input.EngineID == "" {
+		lFunc.Debugf("removing fallback engine of CA %s", ca.ID)
+		delete(ca.Metadata, models.CAMetadataFallbackEngineKey)
+	} else {
+		err = svc.copyCAKey(ctx, ca, input.EngineID)
+		if err != nil {
+			return nil, err
+		}
+
+		ca.Metadata[models.CAMetadataFallbackEngineKey] = input.EngineID
+	}
+
+	ca, err = svc.caStorage.Update(ctx, ca)
+	if err != nil {
+		lFunc.Errorf("could not update CA %s fallback engine in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("CA %s fallback engine set to '%s'", ca.ID, input.EngineID)
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventFallbackEngineUpdated, map[string]any{
+		"engine_id": input.EngineID,
+	})
+
+	return ca, nil
+}
+
+type SetCAIssuanceWebhookInput struct {
+	CAID    string `validate:"required"`
+	Webhook *models.CAIssuanceWebhook
+}
+
+// SetCAIssuanceWebhook replaces the webhook reviewing the certificates signed by the CA. A nil Webhook removes it.
+// The webhook can not be changed through the CA metadata, as it can mutate the issued certificates.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, or the webhook URL is not an absolute HTTP(S) URL.
+func (svc *CAServiceBackend) SetCAIssuanceWebhook(ctx context.Context, input SetCAIssuanceWebhookInput) (*models.CACertificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("SetCAIssuanceWebhook struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if input.Webhook != nil {
+		webhookURL, err := url.Parse(input.Webhook.URL)
+		if err != nil || (webhookURL.Scheme != "http" && webhookURL.Scheme != "https") || webhookURL.Host == "" {
+			lFunc.Errorf("invalid issuance webhook URL '%s': must be an absolute HTTP(S) URL", input.Webhook.URL)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Metadata == nil {
+		ca.Metadata = map[string]any{}
+	}
+
+	details := map[string]any{}
+	if input.Webhook == nil {
+		lFunc.Debugf("removing issuance webhook of CA %s", ca.ID)
+		delete(ca.Metadata, models.CAMetadataIssuanceWebhookKey)
+	} else {
+		ca.Metadata[models.CAMetadataIssuanceWebhookKey] = *input.Webhook
+		details["url"] = input.Webhook.URL
+		details["fail_open"] = input.Webhook.FailOpen
+	}
+
+	ca, err = svc.caStorage.Update(ctx, ca)
+	if err != nil {
+		lFunc.Errorf("could not update CA %s issuance webhook in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("CA %s issuance webhook updated: %v", ca.ID, details)
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventIssuanceWebhookUpdated, details)
+
+	return ca, nil
+}
+
+// signingEngine returns the engine holding the key used to sign with the CA. If the engine of the CA reports it
+// is unavailable and the CA has a fallback engine, the fallback engine is used instead.
+func (svc *CAServiceBackend) signingEngine(ctx context.Context, ca *models.CACertificate) *cryptoengines.CryptoEngine {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	engine := svc.cryptoEngines[ca.Certificate.EngineID]
+	if engine == nil {
+		return engine
+	}
+
+	reporter, ok := (*engine).(cryptoengines.HealthReporter)
+	if !ok || reporter.Available() {
+		return engine
+	}
+
+	fallbackID, _ := ca.Metadata[models.CAMetadataFallbackEngineKey].(string)
+	fallback, ok := svc.cryptoEngines[fallbackID]
+	if !ok {
+		lFunc.Warnf("engine %s of CA %s is unavailable and the CA has no fallback engine", ca.Certificate.EngineID, ca.ID)
+		return engine
+	}
+
+	lFunc.Warnf("engine %s of CA %s is unavailable. Signing with fallback engine %s", ca.Certificate.EngineID, ca.ID, fallbackID)
+	return fallback
+}
+
+type ExportCAKeyInput struct {
+	CAID string `validate:"required"`
+}
+
+// ExportCAKey returns the private key of a CA encrypted by its crypto engine, so it can be backed up outside the
+// engine. The key never leaves the service in plain form.
+//
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCAType
+//     The CA is of type EXTERNAL and has no private key.
+//   - ErrCryptoEngineNotFound
+//     The crypto engine of the CA is not configured.
+//   - ErrCAKeyNotExportable
+//     The crypto engine of the CA does not support encrypted key backups.
+//   - ErrCASigningApprovalRequired
+//     The CA enforces dual control. The keys of dual control CAs can not be exported.
+func (svc *CAServiceBackend) ExportCAKey(ctx context.Context, input ExportCAKeyInput) (*models.CAKeyBackup, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("ExportCAKey struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	ca, keyID, backupEngine, err := svc.getCAKeyBackupEngine(ctx, input.CAID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.requireSigningApproval(ctx, ca, models.CASigningOperationKeyExport)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Debugf("exporting CA %s key from engine %s", ca.ID, ca.Certificate.EngineID)
+	backup, err := backupEngine.ExportKey(keyID)
+	if err != nil {
+		if errors.Is(err, cryptoengines.ErrKeyBackupNotSupported) {
+			lFunc.Errorf("engine %s of CA %s has no key encryption configured", ca.Certificate.EngineID, ca.ID)
+			return nil, errs.ErrCAKeyNotExportable
+		}
+
+		lFunc.Errorf("could not export CA %s key from engine %s: %s", ca.ID, ca.Certificate.EngineID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("CA %s key exported from engine %s", ca.ID, ca.Certificate.EngineID)
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventKeyExported, map[string]any{
+		"engine_id": ca.Certificate.EngineID,
+	})
+
+	return &models.CAKeyBackup{
+		CAID:       ca.ID,
+		EngineID:   ca.Certificate.EngineID,
+		KeyID:      keyID,
+		Backup:     backup,
+		ExportedAt: time.Now(),
+	}, nil
 }
 
-type SignCertificateInput struct {
-	CAID         string                         `validate:"required"`
-	CertRequest  *models.X509CertificateRequest `validate:"required"`
-	Subject      *models.Subject
-	SignVerbatim bool
+type RestoreCAKeyInput struct {
+	CAID   string `validate:"required"`
+	Backup []byte `validate:"required"`
 }
 
+// RestoreCAKey decrypts a backup created by ExportCAKey and stores the key back into the crypto engine of the CA.
+// The backup is only restored if it matches the CA certificate.
+//
 // Returned Error Codes:
 //   - ErrCANotFound
 //     The specified CA can not be found in the Database
 //   - ErrValidateBadRequest
 //     The required variables of the data structure are not valid.
-//   - ErrCAStatus
-//     CA is not active
-func (svc *CAServiceBackend) SignCertificate(ctx context.Context, input SignCertificateInput) (*models.Certificate, error) {
+//   - ErrCAType
+//     The CA is of type EXTERNAL and has no private key.
+//   - ErrCryptoEngineNotFound
+//     The crypto engine of the CA is not configured.
+//   - ErrCAKeyNotExportable
+//     The crypto engine of the CA does not support encrypted key backups.
+//   - ErrCAKeyBackupInvalid
+//     The backup can not be decrypted by the engine or does not match the CA certificate.
+func (svc *CAServiceBackend) RestoreCAKey(ctx context.Context, input RestoreCAKeyInput) (*models.CACertificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
 	err := validate.Struct(input)
 	if err != nil {
-		lFunc.Errorf("SignCertificateInput struct validation error: %s", err)
-		return nil, errs.ErrCANotFound
+		lFunc.Errorf("RestoreCAKey struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
 	}
 
-	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
-	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	ca, keyID, backupEngine, err := svc.getCAKeyBackupEngine(ctx, input.CAID)
 	if err != nil {
-		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
 		return nil, err
 	}
 
-	if !exists {
-		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
-		return nil, errs.ErrCANotFound
-	}
+	lFunc.Debugf("opening CA %s key backup with engine %s", ca.ID, ca.Certificate.EngineID)
+	signer, err := backupEngine.OpenKeyBackup(keyID, input.Backup)
+	if err != nil {
+		if errors.Is(err, cryptoengines.ErrKeyBackupNotSupported) {
+			lFunc.Errorf("engine %s of CA %s has no key encryption configured", ca.Certificate.EngineID, ca.ID)
+			return nil, errs.ErrCAKeyNotExportable
+		}
 
-	if ca.Status != models.StatusActive {
-		lFunc.Errorf("%s CA is not active", ca.ID)
-		return nil, errs.ErrCAStatus
+		lFunc.Errorf("could not open CA %s key backup: %s", ca.ID, err)
+		return nil, errs.ErrCAKeyBackupInvalid
 	}
 
-	engine := svc.cryptoEngines[ca.Certificate.EngineID]
-
-	x509Engine := x509engines.NewX509Engine(engine, svc.vaServerDomain)
-
 	caCert := (*x509.Certificate)(ca.Certificate.Certificate)
-	csr := (*x509.CertificateRequest)(input.CertRequest)
-
-	if !input.SignVerbatim {
-		csr.Subject = pkix.Name{
-			CommonName:         input.Subject.CommonName,
-			Country:            []string{input.Subject.Country},
-			Province:           []string{input.Subject.State},
-			Locality:           []string{input.Subject.Locality},
-			Organization:       []string{input.Subject.Organization},
-			OrganizationalUnit: []string{input.Subject.OrganizationUnit},
+	engine := *svc.cryptoEngines[ca.Certificate.EngineID]
+
+	var valid bool
+	switch key := signer.(type) {
+	case *rsa.PrivateKey:
+		valid, err = helpers.ValidateCertAndPrivKey(caCert, key, nil)
+		if err == nil && valid {
+			_, err = engine.ImportRSAPrivateKey(key, keyID)
+		}
+	case *ecdsa.PrivateKey:
+		valid, err = helpers.ValidateCertAndPrivKey(caCert, nil, key)
+		if err == nil && valid {
+			_, err = engine.ImportECDSAPrivateKey(key, keyID)
 		}
 	}
-
-	expiration := time.Now()
-	if ca.IssuanceExpirationRef.Type == models.Duration {
-		expiration = expiration.Add(time.Duration(*ca.IssuanceExpirationRef.Duration))
-	} else {
-		expiration = *ca.IssuanceExpirationRef.Time
+	if !valid {
+		lFunc.Errorf("CA %s key backup does not match the CA certificate", ca.ID)
+		return nil, errs.ErrCAKeyBackupInvalid
 	}
-	lFunc.Debugf("sign certificate request with %s CA and %s crypto engine", input.CAID, x509Engine.GetEngineConfig().Provider)
-	x509Cert, err := x509Engine.SignCertificateRequest(caCert, csr, expiration)
 	if err != nil {
-		lFunc.Errorf("could not sign certificate request with %s CA", caCert.Subject.CommonName)
+		lFunc.Errorf("could not restore CA %s key into engine %s: %s", ca.ID, ca.Certificate.EngineID, err)
 		return nil, err
 	}
 
-	cert := models.Certificate{
-		Metadata:    map[string]interface{}{},
-		Type:        models.CertificateTypeExternal,
-		Certificate: (*models.X509Certificate)(x509Cert),
-		IssuerCAMetadata: models.IssuerCAMetadata{
-			SerialNumber: helpers.SerialNumberToString(caCert.SerialNumber),
-			ID:           ca.ID,
-		},
-		Status:              models.StatusActive,
-		KeyMetadata:         helpers.KeyStrengthMetadataFromCertificate(x509Cert),
-		Subject:             helpers.PkixNameToSubject(x509Cert.Subject),
-		SerialNumber:        helpers.SerialNumberToString(x509Cert.SerialNumber),
-		ValidFrom:           x509Cert.NotBefore,
-		ValidTo:             x509Cert.NotAfter,
-		RevocationTimestamp: time.Time{},
-	}
-	lFunc.Debugf("insert Certificate %s in storage engine", cert.SerialNumber)
-	return svc.certStorage.Insert(ctx, &cert)
-}
-
-type CreateCertificateInput struct {
-	KeyMetadata models.KeyMetadata `validate:"required"`
-	Subject     models.Subject     `validate:"required"`
-}
-
-func (svc *CAServiceBackend) CreateCertificate(ctx context.Context, input CreateCertificateInput) (*models.Certificate, error) {
-	return nil, fmt.Errorf("TODO")
-}
+	lFunc.Infof("CA %s key restored into engine %s", ca.ID, ca.Certificate.EngineID)
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventKeyRestored, map[string]any{
+		"engine_id": ca.Certificate.EngineID,
+	})
 
-type ImportCertificateInput struct {
-	ImportMode  models.CertificateType
-	Certificate *models.X509Certificate
-	Metadata    map[string]any
+	return ca, nil
 }
 
-func (svc *CAServiceBackend) ImportCertificate(ctx context.Context, input ImportCertificateInput) (*models.Certificate, error) {
+// getCAKeyBackupEngine returns the CA, the ID of its key and its crypto engine if it supports encrypted key backups.
+func (svc *CAServiceBackend) getCAKeyBackupEngine(ctx context.Context, caID string) (*models.CACertificate, string, cryptoengines.KeyBackupEngine, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
-	status := models.StatusActive
-	if input.Certificate.NotAfter.Before(time.Now()) {
-		status = models.StatusExpired
+	lFunc.Debugf("checking if CA '%s' exists", caID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, caID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", caID, err)
+		return nil, "", nil, err
 	}
 
-	newCert := models.Certificate{
-		Metadata:            input.Metadata,
-		Type:                models.CertificateTypeExternal,
-		Certificate:         (*models.X509Certificate)(input.Certificate),
-		Status:              status,
-		KeyMetadata:         helpers.KeyStrengthMetadataFromCertificate((*x509.Certificate)(input.Certificate)),
-		Subject:             helpers.PkixNameToSubject(input.Certificate.Subject),
-		SerialNumber:        helpers.SerialNumberToString(input.Certificate.SerialNumber),
-		ValidFrom:           input.Certificate.NotBefore,
-		ValidTo:             input.Certificate.NotAfter,
-		RevocationTimestamp: time.Time{},
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", caID)
+		return nil, "", nil, errs.ErrCANotFound
 	}
 
-	var parentCA *models.CACertificate
-	svc.caStorage.SelectByCommonName(ctx, input.Certificate.Issuer.CommonName, storage.StorageListRequest[models.CACertificate]{
-		ExhaustiveRun: true,
-		ApplyFunc: func(ca models.CACertificate) {
-			err := helpers.ValidateCertificate((*x509.Certificate)(ca.Certificate.Certificate), (*x509.Certificate)(input.Certificate), false)
-			if err == nil {
-				parentCA = &ca
-			}
-		},
-	})
+	if ca.Type == models.CertificateTypeExternal {
+		lFunc.Errorf("CA %s is of type %s and has no private key", ca.ID, ca.Type)
+		return nil, "", nil, errs.ErrCAType
+	}
 
-	if parentCA != nil {
-		newCert.IssuerCAMetadata = models.IssuerCAMetadata{
-			SerialNumber: parentCA.SerialNumber,
-			ID:           parentCA.ID,
-			Level:        parentCA.Level,
-		}
-	} else {
-		newCert.IssuerCAMetadata = models.IssuerCAMetadata{
-			SerialNumber: "-",
-			ID:           "-",
-			Level:        -1,
-		}
+	engine, ok := svc.cryptoEngines[ca.Certificate.EngineID]
+	if !ok {
+		lFunc.Errorf("engine %s for CA %s is not configured", ca.Certificate.EngineID, ca.ID)
+		return nil, "", nil, errs.ErrCryptoEngineNotFound
 	}
 
-	cert, err := svc.certStorage.Insert(ctx, &newCert)
-	if err != nil {
-		lFunc.Errorf("could not insert certificate: %s", err)
-		return nil, err
+	backupEngine, ok := (*engine).(cryptoengines.KeyBackupEngine)
+	if !ok {
+		lFunc.Errorf("engine %s of CA %s does not support encrypted key backups", ca.Certificate.EngineID, ca.ID)
+		return nil, "", nil, errs.ErrCAKeyNotExportable
 	}
 
-	return cert, nil
+	caCert := (*x509.Certificate)(ca.Certificate.Certificate)
+	keyID := x509engines.CryptoAssetLRI(x509engines.CertificateAuthority, helpers.SerialNumberToString(caCert.SerialNumber))
+	return ca, keyID, backupEngine, nil
 }
 
 type SignatureSignInput struct {
@@ -1076,6 +3060,13 @@ type SignatureSignInput struct {
 	SigningAlgorithm string                 `validate:"required"`
 }
 
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCASigningApprovalRequired
+//     The CA enforces dual control over message signing. See CreateCASigningRequest
 func (svc *CAServiceBackend) SignatureSign(ctx context.Context, input SignatureSignInput) ([]byte, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
@@ -1096,7 +3087,12 @@ func (svc *CAServiceBackend) SignatureSign(ctx context.Context, input SignatureS
 		return nil, errs.ErrCANotFound
 	}
 
-	engine := svc.cryptoEngines[ca.Certificate.EngineID]
+	err = svc.requireSigningApproval(ctx, ca, models.CASigningOperationMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	engine := svc.signingEngine(ctx, ca)
 	x509Engine := x509engines.NewX509Engine(engine, svc.vaServerDomain)
 	lFunc.Debugf("sign signature with %s CA and %s crypto engine", input.CAID, x509Engine.GetEngineConfig().Provider)
 	signature, err := x509Engine.Sign(x509engines.CertificateAuthority, (*x509.Certificate)(ca.Certificate.Certificate), input.Message, input.MessageType, input.SigningAlgorithm)
@@ -1140,6 +3136,185 @@ func (svc *CAServiceBackend) SignatureVerify(ctx context.Context, input Signatur
 	return x509Engine.Verify((*x509.Certificate)(ca.Certificate.Certificate), input.Signature, input.Message, input.MessageType, input.SigningAlgorithm)
 }
 
+type SignTokenInput struct {
+	CAID      string         `validate:"required"`
+	Claims    map[string]any `validate:"required"`
+	Algorithm string
+}
+
+// SignToken issues a JWS (compact serialization) over the claims, signed by the CA key held in its crypto engine.
+// The CA ID is used as key ID, so the token can be verified with the keys published by GetTokenSigningKeys.
+// If no algorithm is requested, ES256/ES384/ES512 are used for ECDSA keys (depending on the curve) and RS256 for RSA keys.
+//
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCAStatus
+//     The CA is not active.
+//   - ErrCATokenSigningNotEnabled
+//     The CA has not been enabled to sign tokens.
+//   - ErrCASigningApprovalRequired
+//     The CA enforces dual control over message signing.
+func (svc *CAServiceBackend) SignToken(ctx context.Context, input SignTokenInput) (*models.SignedToken, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("SignTokenInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Status != models.StatusActive {
+		lFunc.Errorf("CA %s is not active", ca.ID)
+		return nil, errs.ErrCAStatus
+	}
+
+	if !tokenSigningEnabled(*ca) {
+		lFunc.Errorf("CA %s is not enabled to sign tokens. Set the %s metadata key", ca.ID, models.CAMetadataTokenSigningKey)
+		return nil, errs.ErrCATokenSigningNotEnabled
+	}
+
+	err = svc.requireSigningApproval(ctx, ca, models.CASigningOperationMessage)
+	if err != nil {
+		return nil, err
+	}
+
+	pub := ca.Certificate.Certificate.PublicKey
+	alg := input.Algorithm
+	if alg == "" {
+		alg, err = helpers.DefaultJWSAlgorithm(pub)
+		if err != nil {
+			lFunc.Errorf("could not select a JWS algorithm for CA %s: %s", ca.ID, err)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
+	signingAlg, err := helpers.JWSAlgorithmToSigningAlgorithm(pub, alg)
+	if err != nil {
+		lFunc.Errorf("invalid JWS algorithm for CA %s: %s", ca.ID, err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	signingInput, err := helpers.JWSSigningInput(alg, ca.ID, input.Claims)
+	if err != nil {
+		lFunc.Errorf("could not encode token: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	engine := svc.signingEngine(ctx, ca)
+	x509Engine := x509engines.NewX509Engine(engine, svc.vaServerDomain)
+	lFunc.Debugf("sign %s token with %s CA and %s crypto engine", alg, ca.ID, x509Engine.GetEngineConfig().Provider)
+	signature, err := x509Engine.Sign(x509engines.CertificateAuthority, (*x509.Certificate)(ca.Certificate.Certificate), []byte(signingInput), models.Raw, signingAlg)
+	if err != nil {
+		lFunc.Errorf("could not sign token with CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	jwsSignature, err := helpers.JWSSignature(pub, signature)
+	if err != nil {
+		lFunc.Errorf("could not encode token signature: %s", err)
+		return nil, err
+	}
+
+	return &models.SignedToken{
+		Token:     signingInput + "." + jwsSignature,
+		KeyID:     ca.ID,
+		Algorithm: alg,
+	}, nil
+}
+
+// GetTokenSigningKeys returns the JWKS with the public keys of the active CAs enabled to sign tokens.
+func (svc *CAServiceBackend) GetTokenSigningKeys(ctx context.Context) (*models.JWKS, error) {
+	return svc.buildJWKS(ctx, func(ca models.CACertificate) []models.JWKUsage {
+		if !tokenSigningEnabled(ca) {
+			return nil
+		}
+		return []models.JWKUsage{models.JWKUsageTokenSigning}
+	})
+}
+
+// GetJWKS returns the JWKS with the public keys of the active CAs managed by the service, which sign the OCSP
+// responses served by the VA, the detached JWS of signed CRL and CA certificate responses and, if enabled, tokens.
+// Since keys are read from the CAs on each request, new CAs are published right away and revoked or expired CAs
+// are withdrawn, with no manual key distribution.
+func (svc *CAServiceBackend) GetJWKS(ctx context.Context) (*models.JWKS, error) {
+	return svc.buildJWKS(ctx, func(ca models.CACertificate) []models.JWKUsage {
+		if ca.Type == models.CertificateTypeExternal {
+			return nil
+		}
+
+		usages := []models.JWKUsage{models.JWKUsageOCSP, models.JWKUsageResponseSigning}
+		if tokenSigningEnabled(ca) {
+			usages = append(usages, models.JWKUsageTokenSigning)
+		}
+		return usages
+	})
+}
+
+// buildJWKS publishes the keys of the active CAs for which usagesOf returns at least one usage. The CA ID is used as key ID.
+func (svc *CAServiceBackend) buildJWKS(ctx context.Context, usagesOf func(ca models.CACertificate) []models.JWKUsage) (*models.JWKS, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	jwks := &models.JWKS{Keys: []models.JWK{}}
+	_, err := svc.caStorage.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			if ca.Status != models.StatusActive {
+				return
+			}
+
+			usages := usagesOf(ca)
+			if len(usages) == 0 {
+				return
+			}
+
+			cert := (*x509.Certificate)(ca.Certificate.Certificate)
+			alg, err := helpers.DefaultJWSAlgorithm(cert.PublicKey)
+			if err != nil {
+				lFunc.Warnf("skipping CA %s from JWKS: %s", ca.ID, err)
+				return
+			}
+
+			jwk, err := helpers.CertificateToJWK(cert, ca.ID, alg)
+			if err != nil {
+				lFunc.Warnf("skipping CA %s from JWKS: %s", ca.ID, err)
+				return
+			}
+
+			jwk.Usages = usages
+			jwks.Keys = append(jwks.Keys, *jwk)
+		},
+		QueryParams: nil,
+		ExtraOpts:   nil,
+	})
+	if err != nil {
+		lFunc.Errorf("something went wrong while reading all CAs from storage engine: %s", err)
+		return nil, err
+	}
+
+	return jwks, nil
+}
+
+func tokenSigningEnabled(ca models.CACertificate) bool {
+	enabled := false
+	_, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataTokenSigningKey, &enabled)
+	return err == nil && enabled
+}
+
 type GetCertificatesBySerialNumberInput struct {
 	SerialNumber string `validate:"required"`
 }
@@ -1186,6 +3361,62 @@ func (svc *CAServiceBackend) GetCertificates(ctx context.Context, input GetCerti
 	})
 }
 
+type ExportCertificatesInput struct {
+	// QueryParameters filter the exported certificates. Its "issued_after" filter is the export cursor. Pagination
+	// and sorting are ignored.
+	QueryParameters *resources.QueryParameters
+	ApplyFunc       func(models.Certificate) `validate:"required"`
+}
+
+// ExportCertificates calls ApplyFunc for every certificate matching the filters, in ascending valid_from order, so
+// SIEM and CMDB systems can ingest the certificate inventory incrementally. It returns the cursor of the next export:
+// the valid_from of the last exported certificate, to be used as its "issued_after" filter. The zero time is returned
+// if no certificate is exported.
+//
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) ExportCertificates(ctx context.Context, input ExportCertificatesInput) (time.Time, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return time.Time{}, errs.ErrValidateBadRequest
+	}
+
+	queryParams := &resources.QueryParameters{}
+	if input.QueryParameters != nil {
+		queryParams.Filters = input.QueryParameters.Filters
+	}
+	queryParams.Sort = resources.SortOptions{
+		SortMode:  resources.SortModeAsc,
+		SortField: "valid_from",
+	}
+
+	var cursor time.Time
+	exported := 0
+	lFunc.Debugf("exporting certificates with %d filters", len(queryParams.Filters))
+	_, err = svc.certStorage.SelectAll(ctx, storage.StorageListRequest[models.Certificate]{
+		ExhaustiveRun: true,
+		QueryParams:   queryParams,
+		ApplyFunc: func(cert models.Certificate) {
+			if cert.ValidFrom.After(cursor) {
+				cursor = cert.ValidFrom
+			}
+			exported++
+			input.ApplyFunc(cert)
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not export certificates: %s", err)
+		return time.Time{}, err
+	}
+
+	lFunc.Debugf("exported %d certificates", exported)
+	return cursor, nil
+}
+
 type GetCertificatesByCAInput struct {
 	CAID string `validate:"required"`
 	resources.ListInput[models.Certificate]
@@ -1338,11 +3569,13 @@ type UpdateCertificateMetadataInput struct {
 	Metadata     map[string]interface{} `validate:"required"`
 }
 
+// UpdateCertificateMetadata replaces the metadata of the certificate. The reserved keys written while signing the
+// certificate (i.e. its issuance context) are kept, and can only be sent back unchanged.
 // Returned Error Codes:
 //   - ErrCertificateNotFound
 //     The specified Certificate can not be found in the Database
 //   - ErrValidateBadRequest
-//     The required variables of the data structure are not valid.
+//     The required variables of the data structure are not valid, or the metadata changes a reserved key.
 func (svc *CAServiceBackend) UpdateCertificateMetadata(ctx context.Context, input UpdateCertificateMetadataInput) (*models.Certificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
@@ -1364,11 +3597,32 @@ func (svc *CAServiceBackend) UpdateCertificateMetadata(ctx context.Context, inpu
 		return nil, errs.ErrCertificateNotFound
 	}
 
+	for _, key := range reservedCertificateMetadataKeys {
+		value, ok := input.Metadata[key]
+		if ok && !sameMetadataValue(cert.Metadata[key], value) {
+			lFunc.Errorf("invalid metadata for certificate %s: metadata key %s is reserved", input.SerialNumber, key)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		if stored, ok := cert.Metadata[key]; ok {
+			input.Metadata[key] = stored
+		} else {
+			delete(input.Metadata, key)
+		}
+	}
+
 	cert.Metadata = input.Metadata
 	lFunc.Debugf("updating %s certificate metadata", input.SerialNumber)
 	return svc.certStorage.Update(ctx, cert)
 }
 
+// reservedCertificateMetadataKeys are written by the CA service while signing the certificate. Metadata updates can
+// not change them, as they are trusted to authorize operations over the certificate (i.e. its revocation by the DMS
+// that enrolled it).
+var reservedCertificateMetadataKeys = []string{
+	models.CertificateMetadataIssuanceContextKey,
+}
+
 func createCAValidation(sl validator.StructLevel) {
 	ca := sl.Current().Interface().(CreateCAInput)
 	if !helpers.ValidateExpirationTimeRef(ca.CAExpiration) {
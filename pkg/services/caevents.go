@@ -0,0 +1,137 @@
+package services
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// recordCAEvent appends the event to the timeline of the CA. The timeline is best effort: failures are logged and
+// never fail the operation that triggered the event.
+func (svc *CAServiceBackend) recordCAEvent(ctx context.Context, caID string, eventType models.CAEventType, details map[string]any) {
+	if svc.caEventsStorage == nil {
+		return
+	}
+
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if details == nil {
+		details = map[string]any{}
+	}
+
+	_, err := svc.caEventsStorage.Insert(ctx, &models.CAEvent{
+		ID:        uuid.NewString(),
+		CAID:      caID,
+		Type:      eventType,
+		Timestamp: time.Now(),
+		Actor:     callerID(ctx),
+		Details:   details,
+	})
+	if err != nil {
+		lFunc.Errorf("could not record %s event of CA %s: %s", eventType, caID, err)
+	}
+}
+
+type GetCAEventsInput struct {
+	CAID string `validate:"required"`
+}
+
+// GetCAEvents returns the lifecycle timeline of the CA sorted from the oldest to the newest event.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) GetCAEvents(ctx context.Context, input GetCAEventsInput) ([]models.CAEvent, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetCAEventsInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, _, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	events := []models.CAEvent{}
+	if svc.caEventsStorage == nil {
+		return events, nil
+	}
+
+	_, err = svc.caEventsStorage.SelectByCA(ctx, input.CAID, storage.StorageListRequest[models.CAEvent]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(event models.CAEvent) {
+			events = append(events, event)
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not read the events of CA %s: %s", input.CAID, err)
+		return nil, err
+	}
+
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Timestamp.Before(events[j].Timestamp)
+	})
+
+	return events, nil
+}
+
+type ReportCRLGenerationInput struct {
+	CAID                string `validate:"required"`
+	Number              string `validate:"required"`
+	ThisUpdate          time.Time
+	NextUpdate          time.Time
+	RevokedCertificates int
+}
+
+// ReportCRLGeneration records a CRL generated by the VA in the timeline of the CA.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) ReportCRLGeneration(ctx context.Context, input ReportCRLGenerationInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("ReportCRLGenerationInput struct validation error: %s", err)
+		return errs.ErrValidateBadRequest
+	}
+
+	exists, _, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return errs.ErrCANotFound
+	}
+
+	svc.recordCAEvent(ctx, input.CAID, models.CAEventCRLGenerated, map[string]any{
+		"number":               input.Number,
+		"this_update":          input.ThisUpdate,
+		"next_update":          input.NextUpdate,
+		"revoked_certificates": input.RevokedCertificates,
+	})
+
+	return nil
+}
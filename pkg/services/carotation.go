@@ -0,0 +1,332 @@
+package services
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+)
+
+const defaultCARotationBatchSize = 50
+
+type StartCARotationInput struct {
+	CAID string `validate:"required"`
+	// BatchSize is the number of certificates processed in each batch. Defaults to 50.
+	BatchSize int `validate:"gte=0"`
+}
+
+// StartCARotation starts the rotation campaign of a CA. The successor of the CA is created (unless the CA has
+// already been replaced), the DMSs enrolling with the CA are switched to the successor (keeping the rotated CA as a
+// reenrollment validation CA) and the CA is marked for retirement. The devices holding certificates issued by the
+// rotated CA are then forced to reenroll in batches, see ProcessCARotationBatch.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCAType
+//     The CA has no private key managed by Lamassu
+//   - ErrCAStatus
+//     Only active CAs can be replaced
+//   - ErrCARotationAlreadyStarted
+//     The CA rotation is already running
+//   - ErrCARotationCompleted
+//     The CA has already been rotated
+func (svc DMSManagerServiceBackend) StartCARotation(ctx context.Context, input StartCARotationInput) (*models.CARotation, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	ca, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{CAID: input.CAID})
+	if err != nil {
+		lFunc.Errorf("could not get CA %s: %s", input.CAID, err)
+		return nil, err
+	}
+
+	var rotation models.CARotation
+	hasRotation, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataRotationKey, &rotation)
+	if err != nil {
+		lFunc.Errorf("could not decode rotation of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	if hasRotation && rotation.Status == models.CARotationCompleted {
+		lFunc.Errorf("CA %s has already been rotated", ca.ID)
+		return nil, errs.ErrCARotationCompleted
+	} else if hasRotation {
+		lFunc.Errorf("CA %s rotation already started", ca.ID)
+		return nil, errs.ErrCARotationAlreadyStarted
+	}
+
+	var succession models.CASuccession
+	hasSuccessor, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataSuccessorKey, &succession)
+	if err != nil {
+		lFunc.Errorf("could not decode successor of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	if !hasSuccessor {
+		created, err := svc.caClient.CreateSuccessorCA(ctx, CreateSuccessorCAInput{CAID: ca.ID})
+		if err != nil {
+			lFunc.Errorf("could not create successor of CA %s: %s", ca.ID, err)
+			return nil, err
+		}
+		succession = *created
+
+		// the successor is stored in the metadata of the CA, which is replaced below
+		ca, err = svc.caClient.GetCAByID(ctx, GetCAByIDInput{CAID: ca.ID})
+		if err != nil {
+			lFunc.Errorf("could not get CA %s: %s", input.CAID, err)
+			return nil, err
+		}
+	}
+
+	dmss := []models.DMS{}
+	_, err = svc.service.GetAll(ctx, GetAllInput{
+		ListInput: resources.ListInput[models.DMS]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(dms models.DMS) {
+				if dms.Settings.EnrollmentSettings.EnrollmentCA == ca.ID {
+					dmss = append(dmss, dms)
+				}
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate DMSs: %s", err)
+		return nil, err
+	}
+
+	updatedDMSs := []string{}
+	for _, dms := range dmss {
+		dms.Settings.EnrollmentSettings.EnrollmentCA = succession.SuccessorCAID
+		if !slices.Contains(dms.Settings.ReEnrollmentSettings.AdditionalValidationCAs, ca.ID) {
+			dms.Settings.ReEnrollmentSettings.AdditionalValidationCAs = append(dms.Settings.ReEnrollmentSettings.AdditionalValidationCAs, ca.ID)
+		}
+
+		_, err = svc.service.UpdateDMS(ctx, UpdateDMSInput{DMS: dms})
+		if err != nil {
+			lFunc.Errorf("could not switch DMS %s to the successor CA %s: %s", dms.ID, succession.SuccessorCAID, err)
+			return nil, err
+		}
+
+		lFunc.Infof("DMS %s switched to enroll with the successor CA %s", dms.ID, succession.SuccessorCAID)
+		updatedDMSs = append(updatedDMSs, dms.ID)
+	}
+
+	batchSize := input.BatchSize
+	if batchSize == 0 {
+		batchSize = defaultCARotationBatchSize
+	}
+
+	now := time.Now()
+	rotation = models.CARotation{
+		CAID:              ca.ID,
+		SuccessorCAID:     succession.SuccessorCAID,
+		Status:            models.CARotationRunning,
+		BatchSize:         batchSize,
+		UpdatedDMSs:       updatedDMSs,
+		ReenrolledDevices: []string{},
+		FailedDevices:     []string{},
+		StartedAt:         now,
+		UpdatedAt:         now,
+	}
+
+	err = svc.storeCARotation(ctx, ca, rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Infof("rotation of CA %s to successor CA %s started", ca.ID, succession.SuccessorCAID)
+	return &rotation, nil
+}
+
+type ProcessCARotationBatchInput struct {
+	CAID string `validate:"required"`
+}
+
+// ProcessCARotationBatch forces the reenrollment of the devices holding the next batch of active certificates issued
+// by the rotated CA. Once every certificate has been processed, the campaign is completed and the issuance of the
+// rotated CA is paused.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCARotationNotFound
+//     The CA rotation has not been started
+//   - ErrCARotationCompleted
+//     The CA has already been rotated
+func (svc DMSManagerServiceBackend) ProcessCARotationBatch(ctx context.Context, input ProcessCARotationBatchInput) (*models.CARotation, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	ca, rotation, err := svc.getCARotation(ctx, input.CAID)
+	if err != nil {
+		return nil, err
+	}
+
+	if rotation.Status == models.CARotationCompleted {
+		lFunc.Errorf("CA %s has already been rotated", ca.ID)
+		return nil, errs.ErrCARotationCompleted
+	}
+
+	certs := []models.Certificate{}
+	bookmark, err := svc.caClient.GetCertificatesByCA(ctx, GetCertificatesByCAInput{
+		CAID: ca.ID,
+		ListInput: resources.ListInput[models.Certificate]{
+			QueryParameters: &resources.QueryParameters{
+				PageSize:     rotation.BatchSize,
+				NextBookmark: rotation.Bookmark,
+			},
+			ExhaustiveRun: false,
+			ApplyFunc: func(cert models.Certificate) {
+				certs = append(certs, cert)
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate certificates of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	reason := fmt.Sprintf("CA %s rotated to successor CA %s", rotation.CAID, rotation.SuccessorCAID)
+	for _, cert := range certs {
+		rotation.ProcessedCertificates++
+		if cert.Status != models.StatusActive {
+			continue
+		}
+
+		binding, err := svc.deviceManagerCli.GetDeviceByCertificate(ctx, GetDeviceByCertificateInput{
+			SerialNumber: cert.SerialNumber,
+		})
+		if err == errs.ErrDeviceCertificateNotBound {
+			continue
+		} else if err != nil {
+			lFunc.Errorf("could not get device of certificate %s: %s", cert.SerialNumber, err)
+			return nil, err
+		}
+
+		deviceID := binding.Device.ID
+		if !binding.Active || slices.Contains(rotation.ReenrolledDevices, deviceID) {
+			continue
+		}
+
+		_, err = svc.deviceManagerCli.ForceDeviceReenroll(ctx, ForceDeviceReenrollInput{
+			ID:     deviceID,
+			Reason: reason,
+		})
+		if err != nil {
+			lFunc.Warnf("could not force reenrollment of device %s: %s", deviceID, err)
+			rotation.FailedDevices = append(rotation.FailedDevices, deviceID)
+			continue
+		}
+
+		rotation.ReenrolledDevices = append(rotation.ReenrolledDevices, deviceID)
+	}
+
+	now := time.Now()
+	rotation.Bookmark = bookmark
+	rotation.UpdatedAt = now
+	if len(certs) == 0 || bookmark == "" {
+		rotation.Status = models.CARotationCompleted
+		rotation.CompletedAt = &now
+		if ca.Metadata == nil {
+			ca.Metadata = map[string]any{}
+		}
+		ca.Metadata[models.CAMetadataIssuancePausedKey] = true
+		lFunc.Infof("rotation of CA %s completed. Issuance of CA %s paused", ca.ID, ca.ID)
+	}
+
+	err = svc.storeCARotation(ctx, ca, *rotation)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Infof("CA %s rotation batch processed: %d certificates, %d devices reenrolled", ca.ID, len(certs), len(rotation.ReenrolledDevices))
+	return rotation, nil
+}
+
+type GetCARotationInput struct {
+	CAID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCARotationNotFound
+//     The CA rotation has not been started
+func (svc DMSManagerServiceBackend) GetCARotation(ctx context.Context, input GetCARotationInput) (*models.CARotation, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, rotation, err := svc.getCARotation(ctx, input.CAID)
+	return rotation, err
+}
+
+func (svc DMSManagerServiceBackend) getCARotation(ctx context.Context, caID string) (*models.CACertificate, *models.CARotation, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	ca, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{CAID: caID})
+	if err != nil {
+		lFunc.Errorf("could not get CA %s: %s", caID, err)
+		return nil, nil, err
+	}
+
+	var rotation models.CARotation
+	hasRotation, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataRotationKey, &rotation)
+	if err != nil {
+		lFunc.Errorf("could not decode rotation of CA %s: %s", ca.ID, err)
+		return nil, nil, err
+	}
+
+	if !hasRotation {
+		lFunc.Errorf("CA %s rotation not found", ca.ID)
+		return nil, nil, errs.ErrCARotationNotFound
+	}
+
+	return ca, &rotation, nil
+}
+
+// storeCARotation stores the rotation in the metadata of the rotated CA.
+func (svc DMSManagerServiceBackend) storeCARotation(ctx context.Context, ca *models.CACertificate, rotation models.CARotation) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	metadata := map[string]any{}
+	for key, value := range ca.Metadata {
+		metadata[key] = value
+	}
+	metadata[models.CAMetadataRotationKey] = rotation
+
+	_, err := svc.caClient.UpdateCAMetadata(ctx, UpdateCAMetadataInput{
+		CAID:     ca.ID,
+		Metadata: metadata,
+	})
+	if err != nil {
+		lFunc.Errorf("could not update rotation of CA %s: %s", ca.ID, err)
+		return err
+	}
+
+	return nil
+}
@@ -6,6 +6,7 @@ import (
 	"crypto/x509"
 	"io"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/sirupsen/logrus"
 )
@@ -48,3 +49,44 @@ func (s *caSignerImpl) Sign(rand io.Reader, digest []byte, opts crypto.SignerOpt
 		SigningAlgorithm: signAlg,
 	})
 }
+
+// SignDetachedContent signs the content with the CA key, returning it along with its detached JWS. The CA ID is
+// used as key ID, so consumers can verify the signature with the keys published in the CA service JWKS.
+func SignDetachedContent(ctx context.Context, caSDK CAService, ca *models.CACertificate, content []byte) (*models.SignedContent, error) {
+	pub := ca.Certificate.Certificate.PublicKey
+	alg, err := helpers.DefaultJWSAlgorithm(pub)
+	if err != nil {
+		return nil, err
+	}
+
+	signingAlg, err := helpers.JWSAlgorithmToSigningAlgorithm(pub, alg)
+	if err != nil {
+		return nil, err
+	}
+
+	signingInput, header, err := helpers.JWSDetachedSigningInput(alg, ca.ID, content)
+	if err != nil {
+		return nil, err
+	}
+
+	signature, err := caSDK.SignatureSign(ctx, SignatureSignInput{
+		CAID:             ca.ID,
+		Message:          []byte(signingInput),
+		MessageType:      models.Raw,
+		SigningAlgorithm: signingAlg,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	jwsSignature, err := helpers.JWSSignature(pub, signature)
+	if err != nil {
+		return nil, err
+	}
+
+	return &models.SignedContent{
+		Content:   content,
+		Signature: header + ".." + jwsSignature,
+		KeyID:     ca.ID,
+	}, nil
+}
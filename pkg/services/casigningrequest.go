@@ -0,0 +1,591 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// signingRequestUpdateAttempts bounds the retries of a signing request update conflicting with concurrent updates.
+const signingRequestUpdateAttempts = 5
+
+// approvedSigningCtxKey marks the context of a sign operation approved through a signing request. Its value is the
+// ID of the CA the request was approved for.
+type approvedSigningCtxKey struct{}
+
+func guardsSigningOperation(dualControl models.CADualControl, op models.CASigningOperation) bool {
+	return op == models.CASigningOperationDualControl || op == models.CASigningOperationKeyExport || len(dualControl.Operations) == 0 || slices.Contains(dualControl.Operations, op)
+}
+
+// requireSigningApproval rejects the sign operation if the CA enforces dual control over it, unless the operation
+// is executed by an approved signing request of the CA.
+func (svc *CAServiceBackend) requireSigningApproval(ctx context.Context, ca *models.CACertificate, op models.CASigningOperation) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	var dualControl models.CADualControl
+	hasDualControl, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataDualControlKey, &dualControl)
+	if err != nil {
+		// an unreadable flag must not disable the dual control of the CA
+		lFunc.Errorf("could not decode dual control of CA %s: %s", ca.ID, err)
+		return errs.ErrCASigningApprovalRequired
+	}
+
+	if !hasDualControl || !guardsSigningOperation(dualControl, op) {
+		return nil
+	}
+
+	if approvedCAID, ok := ctx.Value(approvedSigningCtxKey{}).(string); ok && approvedCAID == ca.ID {
+		return nil
+	}
+
+	lFunc.Errorf("CA %s enforces dual control over %s operations. The operation requires an approved signing request", ca.ID, op)
+	return errs.ErrCASigningApprovalRequired
+}
+
+// auditSigningRequest appends the entry to the audit log of the signing request and mirrors it in the CA timeline.
+func (svc *CAServiceBackend) auditSigningRequest(ctx context.Context, request *models.CASigningRequest, actor string, action models.CASigningAuditAction, detail string) {
+	request.AuditLog = append(request.AuditLog, models.CASigningAuditEntry{
+		Timestamp: time.Now(),
+		Actor:     actor,
+		Action:    action,
+		Detail:    detail,
+	})
+
+	svc.recordCAEvent(ctx, request.CAID, models.CAEventSigningRequest, map[string]any{
+		"request_id": request.ID,
+		"operation":  request.Operation,
+		"action":     action,
+		"detail":     detail,
+	})
+}
+
+type CreateCASigningRequestInput struct {
+	CAID      string                    `validate:"required"`
+	Operation models.CASigningOperation `validate:"required"`
+
+	Message          []byte
+	MessageType      models.SignMessageType
+	SigningAlgorithm string
+
+	CertRequest          *models.X509CertificateRequest
+	Subject              *models.Subject
+	SignVerbatim         bool
+	SigningProfile       *models.SigningProfile
+	CertificateProfileID string
+
+	// DualControl is the new policy of UPDATE_DUAL_CONTROL requests. The dual control of the CA is removed if nil.
+	DualControl *models.CADualControl
+}
+
+// CreateCASigningRequest registers a sign operation against a dual control CA. The operation is executed once the
+// request collects the required approvals. See ApproveCASigningRequest. Changes to the dual control policy itself
+// are registered as UPDATE_DUAL_CONTROL requests, approved under the current policy. The requester must be verified
+// by the authentication of the API, so it can not approve its own request under a different identity.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCAApprovalUnverifiedIdentity
+//     The identity of the requester was not verified by the authentication of the API.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the operation is missing its message or
+//     certificate request.
+//   - ErrCADualControlNotEnabled
+//     The CA does not enforce dual control over the operation.
+func (svc *CAServiceBackend) CreateCASigningRequest(ctx context.Context, input CreateCASigningRequestInput) (*models.CASigningRequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("CreateCASigningRequestInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	switch input.Operation {
+	case models.CASigningOperationMessage:
+		if len(input.Message) == 0 || input.MessageType == "" || input.SigningAlgorithm == "" {
+			lFunc.Errorf("%s signing requests require the message, its type and the signing algorithm", input.Operation)
+			return nil, errs.ErrValidateBadRequest
+		}
+	case models.CASigningOperationCertificate:
+		if input.CertRequest == nil || (!input.SignVerbatim && input.Subject == nil) {
+			lFunc.Errorf("%s signing requests require the certificate request and its subject unless signed verbatim", input.Operation)
+			return nil, errs.ErrValidateBadRequest
+		}
+	case models.CASigningOperationDualControl:
+		if input.DualControl != nil && !validDualControl(*input.DualControl) {
+			lFunc.Errorf("%s signing requests require a positive number of approvals and known operations", input.Operation)
+			return nil, errs.ErrValidateBadRequest
+		}
+	default:
+		lFunc.Errorf("unknown signing request operation %s", input.Operation)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	requester := verifiedCallerID(ctx)
+	if requester == "" {
+		lFunc.Errorf("signing requests over CA %s can only be requested by an administrator verified by the API authentication", input.CAID)
+		return nil, errs.ErrCAApprovalUnverifiedIdentity
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	var dualControl models.CADualControl
+	hasDualControl, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataDualControlKey, &dualControl)
+	if err != nil || !hasDualControl || !guardsSigningOperation(dualControl, input.Operation) {
+		lFunc.Errorf("CA %s does not enforce dual control over %s operations: %v", input.CAID, input.Operation, err)
+		return nil, errs.ErrCADualControlNotEnabled
+	}
+
+	if svc.signingRequestStorage == nil {
+		lFunc.Errorf("CA signing requests storage is not configured")
+		return nil, errs.ErrCADualControlNotEnabled
+	}
+
+	approvalWindow := svc.approvalWindow
+	if dualControl.ApprovalWindow != nil && *dualControl.ApprovalWindow > 0 {
+		approvalWindow = time.Duration(*dualControl.ApprovalWindow)
+	}
+
+	now := time.Now()
+	request := &models.CASigningRequest{
+		ID:                   goid.NewV4UUID().String(),
+		CAID:                 ca.ID,
+		Operation:            input.Operation,
+		Message:              input.Message,
+		MessageType:          input.MessageType,
+		SigningAlgorithm:     input.SigningAlgorithm,
+		CertRequest:          input.CertRequest,
+		Subject:              input.Subject,
+		SignVerbatim:         input.SignVerbatim,
+		SigningProfile:       input.SigningProfile,
+		CertificateProfileID: input.CertificateProfileID,
+		DualControl:          input.DualControl,
+		Status:               models.CASigningRequestPending,
+		RequiredApprovals:    max(dualControl.RequiredApprovals, 1),
+		Approvers:            dualControl.Approvers,
+		Approvals:            []models.CASigningApproval{},
+		RequestedBy:          requester,
+		RequestedAt:          now,
+		ExpiresAt:            now.Add(approvalWindow),
+		AuditLog:             []models.CASigningAuditEntry{},
+	}
+
+	svc.auditSigningRequest(ctx, request, request.RequestedBy, models.CASigningAuditRequested, fmt.Sprintf("%d approvals required", request.RequiredApprovals))
+
+	lFunc.Infof("%s signing request %s over CA %s requested by '%s'. %d approvals required", request.Operation, request.ID, ca.ID, request.RequestedBy, request.RequiredApprovals)
+	return svc.signingRequestStorage.Insert(ctx, request)
+}
+
+type GetCASigningRequestsInput struct {
+	CAID string `validate:"required"`
+	resources.ListInput[models.CASigningRequest]
+}
+
+// GetCASigningRequests iterates the signing requests of the CA.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) GetCASigningRequests(ctx context.Context, input GetCASigningRequestsInput) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetCASigningRequestsInput struct validation error: %s", err)
+		return "", errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, _, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return "", err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return "", errs.ErrCANotFound
+	}
+
+	if svc.signingRequestStorage == nil {
+		return "", nil
+	}
+
+	return svc.signingRequestStorage.SelectByCA(ctx, input.CAID, storage.StorageListRequest[models.CASigningRequest]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   input.QueryParameters,
+	})
+}
+
+type GetCASigningRequestByIDInput struct {
+	CAID      string `validate:"required"`
+	RequestID string `validate:"required"`
+}
+
+// GetCASigningRequestByID returns the signing request of the CA. Pending requests past their approval window are
+// marked as expired.
+// Returned Error Codes:
+//   - ErrCASigningRequestNotFound
+//     The CA has no signing request with the specified ID
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) GetCASigningRequestByID(ctx context.Context, input GetCASigningRequestByIDInput) (*models.CASigningRequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetCASigningRequestByIDInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	request, err := retrySigningRequestUpdate(func() (*models.CASigningRequest, error) {
+		return svc.getPendingSigningRequest(ctx, input.CAID, input.RequestID)
+	})
+	if err != nil && err != errs.ErrCASigningRequestNotPending && err != errs.ErrCASigningRequestExpired {
+		return nil, err
+	}
+
+	return request, nil
+}
+
+// unchangedSigningRequest is the precondition of the signing request updates. The stored request must still be in the
+// status and hold the approvals it was read with, so concurrent approvals (even on different replicas) can neither
+// overwrite each other nor execute the request twice.
+func unchangedSigningRequest(read *models.CASigningRequest) func(current *models.CASigningRequest) bool {
+	status, approvals := read.Status, len(read.Approvals)
+	return func(current *models.CASigningRequest) bool {
+		return current.Status == status && len(current.Approvals) == approvals
+	}
+}
+
+// retrySigningRequestUpdate runs update again, over the stored request, while it conflicts with a concurrent update.
+func retrySigningRequestUpdate(update func() (*models.CASigningRequest, error)) (*models.CASigningRequest, error) {
+	for attempt := 1; ; attempt++ {
+		request, err := update()
+		if !errors.Is(err, storage.ErrUpdateConflict) || attempt == signingRequestUpdateAttempts {
+			return request, err
+		}
+	}
+}
+
+// getPendingSigningRequest reads the signing request of the CA and checks that it can still be approved. Requests
+// past their approval window are marked as expired. The request is returned along with ErrCASigningRequestNotPending
+// and ErrCASigningRequestExpired. storage.ErrUpdateConflict is returned if the request changed while being expired.
+func (svc *CAServiceBackend) getPendingSigningRequest(ctx context.Context, caID, requestID string) (*models.CASigningRequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if svc.signingRequestStorage == nil {
+		return nil, errs.ErrCASigningRequestNotFound
+	}
+
+	exists, request, err := svc.signingRequestStorage.SelectExists(ctx, requestID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if signing request '%s' exists in storage engine: %s", requestID, err)
+		return nil, err
+	}
+
+	if !exists || request.CAID != caID {
+		lFunc.Errorf("CA %s has no signing request %s", caID, requestID)
+		return nil, errs.ErrCASigningRequestNotFound
+	}
+
+	if request.Status != models.CASigningRequestPending {
+		return request, errs.ErrCASigningRequestNotPending
+	}
+
+	if time.Now().After(request.ExpiresAt) {
+		lFunc.Infof("signing request %s over CA %s expired at %s", request.ID, caID, request.ExpiresAt)
+		unchanged := unchangedSigningRequest(request)
+		request.Status = models.CASigningRequestExpired
+		svc.auditSigningRequest(ctx, request, "", models.CASigningAuditExpired, fmt.Sprintf("%d of %d approvals collected", len(request.Approvals), request.RequiredApprovals))
+
+		request, err = svc.signingRequestStorage.UpdateIf(ctx, request, unchanged)
+		if err != nil {
+			lFunc.Errorf("could not expire signing request %s: %s", requestID, err)
+			return nil, err
+		}
+
+		return request, errs.ErrCASigningRequestExpired
+	}
+
+	return request, nil
+}
+
+type ApproveCASigningRequestInput struct {
+	CAID      string `validate:"required"`
+	RequestID string `validate:"required"`
+}
+
+// ApproveCASigningRequest registers the approval of the caller, which must be verified by the authentication of the
+// API. The sign operation is executed as soon as the request collects the required approvals, and its result (or the
+// failure) is stored in the request.
+// Returned Error Codes:
+//   - ErrCASigningRequestNotFound
+//     The CA has no signing request with the specified ID
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCASigningRequestNotPending
+//     The request was already signed, rejected or expired
+//   - ErrCASigningRequestExpired
+//     The approval window of the request expired
+//   - ErrCASigningRequestSelfApproval
+//     The approver is the administrator who requested the operation
+//   - ErrCASigningRequestApproverDenied
+//     The approver is not listed as an approver of the CA
+//   - ErrCASigningRequestAlreadyApproved
+//     The approver already approved the request
+//   - ErrCAApprovalUnverifiedIdentity
+//     The identity of the approver was not verified by the authentication of the API.
+func (svc *CAServiceBackend) ApproveCASigningRequest(ctx context.Context, input ApproveCASigningRequestInput) (*models.CASigningRequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("ApproveCASigningRequestInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	approver := verifiedCallerID(ctx)
+	if approver == "" {
+		lFunc.Errorf("signing request %s over CA %s can only be approved by an administrator verified by the API authentication", input.RequestID, input.CAID)
+		return nil, errs.ErrCAApprovalUnverifiedIdentity
+	}
+
+	request, err := retrySigningRequestUpdate(func() (*models.CASigningRequest, error) {
+		return svc.approveSigningRequest(ctx, input.CAID, input.RequestID, approver)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if request.Status != models.CASigningRequestApproved {
+		return request, nil
+	}
+
+	signErr := svc.executeSigningRequest(ctx, request)
+	if signErr != nil {
+		lFunc.Errorf("could not execute approved signing request %s over CA %s: %s", request.ID, input.CAID, signErr)
+		request.Status = models.CASigningRequestFailed
+		svc.auditSigningRequest(ctx, request, "", models.CASigningAuditFailed, signErr.Error())
+	} else {
+		lFunc.Infof("approved signing request %s over CA %s executed", request.ID, input.CAID)
+		request.Status = models.CASigningRequestSigned
+		svc.auditSigningRequest(ctx, request, "", models.CASigningAuditSigned, request.CertificateSerialNumber)
+	}
+
+	// the request is APPROVED, so no other approval nor rejection can update it in between
+	request, err = svc.signingRequestStorage.Update(ctx, request)
+	if err != nil {
+		lFunc.Errorf("could not update signing request %s: %s", input.RequestID, err)
+		return nil, err
+	}
+
+	if signErr != nil {
+		return nil, signErr
+	}
+
+	return request, nil
+}
+
+// approveSigningRequest stores the approval of the approver. The request is moved to APPROVED once it collects the
+// required approvals. The update is conditioned on the request being unchanged since it was read, so only the
+// caller storing the last approval executes the request.
+func (svc *CAServiceBackend) approveSigningRequest(ctx context.Context, caID, requestID, approver string) (*models.CASigningRequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	request, err := svc.getPendingSigningRequest(ctx, caID, requestID)
+	if err != nil {
+		return nil, err
+	}
+
+	if approver == request.RequestedBy {
+		lFunc.Errorf("signing request %s over CA %s must be approved by a different administrator than '%s'", request.ID, caID, request.RequestedBy)
+		return nil, errs.ErrCASigningRequestSelfApproval
+	}
+
+	if len(request.Approvers) > 0 && !slices.Contains(request.Approvers, approver) {
+		lFunc.Errorf("'%s' is not an approver of the signing requests of CA %s", approver, caID)
+		return nil, errs.ErrCASigningRequestApproverDenied
+	}
+
+	if slices.ContainsFunc(request.Approvals, func(approval models.CASigningApproval) bool { return approval.Approver == approver }) {
+		lFunc.Errorf("signing request %s over CA %s already approved by '%s'", request.ID, caID, approver)
+		return nil, errs.ErrCASigningRequestAlreadyApproved
+	}
+
+	unchanged := unchangedSigningRequest(request)
+	request.Approvals = append(request.Approvals, models.CASigningApproval{
+		Approver:   approver,
+		ApprovedAt: time.Now(),
+	})
+	svc.auditSigningRequest(ctx, request, approver, models.CASigningAuditApproved, fmt.Sprintf("%d of %d approvals collected", len(request.Approvals), request.RequiredApprovals))
+
+	if len(request.Approvals) >= request.RequiredApprovals {
+		request.Status = models.CASigningRequestApproved
+	}
+
+	request, err = svc.signingRequestStorage.UpdateIf(ctx, request, unchanged)
+	if err != nil {
+		if errors.Is(err, storage.ErrUpdateConflict) {
+			lFunc.Warnf("signing request %s over CA %s was updated concurrently. Retrying approval of '%s'", requestID, caID, approver)
+		} else {
+			lFunc.Errorf("could not update signing request %s: %s", requestID, err)
+		}
+		return nil, err
+	}
+
+	lFunc.Infof("signing request %s over CA %s approved by '%s'. %d of %d approvals collected", request.ID, caID, approver, len(request.Approvals), request.RequiredApprovals)
+	return request, nil
+}
+
+func (svc *CAServiceBackend) executeSigningRequest(ctx context.Context, request *models.CASigningRequest) error {
+	approvedCtx := context.WithValue(ctx, approvedSigningCtxKey{}, request.CAID)
+
+	switch request.Operation {
+	case models.CASigningOperationMessage:
+		signature, err := svc.service.SignatureSign(approvedCtx, SignatureSignInput{
+			CAID:             request.CAID,
+			Message:          request.Message,
+			MessageType:      request.MessageType,
+			SigningAlgorithm: request.SigningAlgorithm,
+		})
+		if err != nil {
+			return err
+		}
+
+		request.Signature = signature
+	case models.CASigningOperationCertificate:
+		crt, err := svc.service.SignCertificate(approvedCtx, SignCertificateInput{
+			CAID:                 request.CAID,
+			CertRequest:          request.CertRequest,
+			Subject:              request.Subject,
+			SignVerbatim:         request.SignVerbatim,
+			SigningProfile:       request.SigningProfile,
+			CertificateProfileID: request.CertificateProfileID,
+		})
+		if err != nil {
+			return err
+		}
+
+		request.CertificateSerialNumber = crt.SerialNumber
+	case models.CASigningOperationDualControl:
+		return svc.updateDualControl(ctx, request)
+	default:
+		return fmt.Errorf("unknown signing request operation %s", request.Operation)
+	}
+
+	return nil
+}
+
+func validDualControl(dualControl models.CADualControl) bool {
+	if dualControl.RequiredApprovals < 1 {
+		return false
+	}
+
+	for _, op := range dualControl.Operations {
+		if op != models.CASigningOperationCertificate && op != models.CASigningOperationMessage {
+			return false
+		}
+	}
+
+	return true
+}
+
+// updateDualControl replaces the dual control policy of the CA with the one of the approved request.
+func (svc *CAServiceBackend) updateDualControl(ctx context.Context, request *models.CASigningRequest) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, request.CAID)
+	if err != nil {
+		return err
+	}
+
+	if !exists {
+		return errs.ErrCANotFound
+	}
+
+	if ca.Metadata == nil {
+		ca.Metadata = map[string]any{}
+	}
+
+	if request.DualControl == nil {
+		delete(ca.Metadata, models.CAMetadataDualControlKey)
+		lFunc.Infof("removing dual control of CA %s", ca.ID)
+	} else {
+		ca.Metadata[models.CAMetadataDualControlKey] = *request.DualControl
+		lFunc.Infof("replacing dual control policy of CA %s. %d approvals required", ca.ID, request.DualControl.RequiredApprovals)
+	}
+
+	_, err = svc.caStorage.Update(ctx, ca)
+	return err
+}
+
+type RejectCASigningRequestInput struct {
+	CAID      string `validate:"required"`
+	RequestID string `validate:"required"`
+	Reason    string
+}
+
+// RejectCASigningRequest discards the pending signing request. The crypto engine is never invoked for rejected
+// requests. The rejecter must be verified by the authentication of the API.
+// Returned Error Codes:
+//   - ErrCASigningRequestNotFound
+//     The CA has no signing request with the specified ID
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCASigningRequestNotPending
+//     The request was already signed, rejected or expired
+//   - ErrCASigningRequestExpired
+//     The approval window of the request expired
+//   - ErrCAApprovalUnverifiedIdentity
+//     The identity of the rejecter was not verified by the authentication of the API.
+func (svc *CAServiceBackend) RejectCASigningRequest(ctx context.Context, input RejectCASigningRequestInput) (*models.CASigningRequest, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("RejectCASigningRequestInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	rejecter := verifiedCallerID(ctx)
+	if rejecter == "" {
+		lFunc.Errorf("signing request %s over CA %s can only be rejected by an administrator verified by the API authentication", input.RequestID, input.CAID)
+		return nil, errs.ErrCAApprovalUnverifiedIdentity
+	}
+
+	return retrySigningRequestUpdate(func() (*models.CASigningRequest, error) {
+		request, err := svc.getPendingSigningRequest(ctx, input.CAID, input.RequestID)
+		if err != nil {
+			return nil, err
+		}
+
+		unchanged := unchangedSigningRequest(request)
+		request.Status = models.CASigningRequestRejected
+		svc.auditSigningRequest(ctx, request, rejecter, models.CASigningAuditRejected, input.Reason)
+
+		lFunc.Infof("signing request %s over CA %s rejected by '%s'", request.ID, input.CAID, rejecter)
+		return svc.signingRequestStorage.UpdateIf(ctx, request, unchanged)
+	})
+}
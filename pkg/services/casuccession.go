@@ -0,0 +1,204 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/x509engines"
+)
+
+type CreateSuccessorCAInput struct {
+	CAID string `validate:"required"`
+}
+
+// CreateSuccessorCA creates a CA replacing the given one before it expires. The successor is created with the same
+// key type, subject, parent, crypto engine, issuance expiration, validity period and metadata (hence, the same
+// profiles) as the replaced CA, and it is cross signed by the replaced CA so the devices trusting the replaced CA
+// also trust the certificates issued by the successor. The succession is stored in the metadata of both CAs.
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCAType
+//     The CA has no private key managed by Lamassu
+//   - ErrCAStatus
+//     Only active CAs can be replaced
+//   - ErrCASuccessorAlreadyExists
+//     The CA has already been replaced
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) CreateSuccessorCA(ctx context.Context, input CreateSuccessorCAInput) (*models.CASuccession, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("CreateSuccessorCAInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if CA '%s' exists", input.CAID)
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	if ca.Type != models.CertificateTypeManaged {
+		lFunc.Errorf("CA %s is of type %s. Only managed CAs can be replaced", ca.ID, ca.Type)
+		return nil, errs.ErrCAType
+	}
+
+	if ca.Status != models.StatusActive {
+		lFunc.Errorf("CA %s is in %s status. Only active CAs can be replaced", ca.ID, ca.Status)
+		return nil, errs.ErrCAStatus
+	}
+
+	if _, ok := ca.Metadata[models.CAMetadataSuccessorKey]; ok {
+		lFunc.Errorf("CA %s already has a successor", ca.ID)
+		return nil, errs.ErrCASuccessorAlreadyExists
+	}
+
+	// the successor is cross signed with the CA key
+	err = svc.requireSigningApproval(ctx, ca, models.CASigningOperationCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	validity := ca.Certificate.ValidTo.Sub(ca.Certificate.ValidFrom)
+	caExpiration := time.Now().Add(validity)
+
+	if ca.Certificate.IssuerCAMetadata.ID != ca.ID {
+		_, parentCA, err := svc.caStorage.SelectExistsByID(ctx, ca.Certificate.IssuerCAMetadata.ID)
+		if err == nil && parentCA != nil && parentCA.Certificate.ValidTo.Before(caExpiration) {
+			lFunc.Warnf("successor of CA %s would expire after parent CA %s. Capping to the parent CA expiration", ca.ID, parentCA.ID)
+			caExpiration = parentCA.Certificate.ValidTo
+		}
+	}
+
+	// Absolute issuance expirations are moved forward as much as the CA expiration.
+	issuanceExpiration := ca.IssuanceExpirationRef
+	if issuanceExpiration.Type == models.Time && issuanceExpiration.Time != nil {
+		shifted := issuanceExpiration.Time.Add(caExpiration.Sub(ca.Certificate.ValidTo))
+		issuanceExpiration.Time = &shifted
+	}
+
+	metadata := map[string]any{}
+	for key, value := range ca.Metadata {
+		switch key {
+		case models.CAMetadataSuccessorKey, models.CAMetadataPredecessorKey, models.CAMetadataIssuancePausedKey:
+		default:
+			metadata[key] = value
+		}
+	}
+	metadata[models.CAMetadataPredecessorKey] = ca.ID
+
+	parentID := ""
+	if ca.Level > 0 {
+		parentID = ca.Certificate.IssuerCAMetadata.ID
+	}
+
+	lFunc.Infof("creating successor of CA %s", ca.ID)
+	successor, err := svc.service.CreateCA(ctx, CreateCAInput{
+		ParentID: parentID,
+		KeyMetadata: models.KeyMetadata{
+			Type: ca.Certificate.KeyMetadata.Type,
+			Bits: ca.Certificate.KeyMetadata.Bits,
+		},
+		Subject:            ca.Certificate.Subject,
+		IssuanceExpiration: issuanceExpiration,
+		CAExpiration: models.Expiration{
+			Type: models.Time,
+			Time: &caExpiration,
+		},
+		EngineID:          ca.Certificate.EngineID,
+		Metadata:          metadata,
+		HybridKeyMetadata: ca.HybridKeyMetadata,
+	})
+	if err != nil {
+		lFunc.Errorf("could not create successor of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	caCert := (*x509.Certificate)(ca.Certificate.Certificate)
+	successorCert := (*x509.Certificate)(successor.Certificate.Certificate)
+
+	lFunc.Debugf("cross signing successor CA %s with CA %s", successor.ID, ca.ID)
+	x509Engine := x509engines.NewX509Engine(svc.cryptoEngines[ca.Certificate.EngineID], svc.vaServerDomain)
+	crossCert, err := x509Engine.CrossSignCA(caCert, successorCert, caCert.NotAfter)
+	if err != nil {
+		lFunc.Errorf("could not cross sign successor CA %s with CA %s: %s", successor.ID, ca.ID, err)
+		return nil, err
+	}
+
+	if svc.issuanceLogStorage != nil {
+		err = svc.appendIssuanceLogEntry(ctx, ca.ID, crossCert)
+		if err != nil {
+			lFunc.Errorf("could not append cross certificate to the issuance log of CA %s: %s", ca.ID, err)
+			return nil, err
+		}
+	}
+
+	cert := models.Certificate{
+		Metadata: map[string]interface{}{
+			models.CertificateMetadataCrossSignedCAKey: successor.ID,
+		},
+		Type:        models.CertificateTypeManaged,
+		Certificate: (*models.X509Certificate)(crossCert),
+		IssuerCAMetadata: models.IssuerCAMetadata{
+			SerialNumber: ca.SerialNumber,
+			ID:           ca.ID,
+			Level:        ca.Level,
+		},
+		Status:              models.StatusActive,
+		KeyMetadata:         helpers.KeyStrengthMetadataFromCertificate(crossCert),
+		Subject:             helpers.PkixNameToSubject(crossCert.Subject),
+		SerialNumber:        helpers.SerialNumberToString(crossCert.SerialNumber),
+		ValidFrom:           crossCert.NotBefore,
+		ValidTo:             crossCert.NotAfter,
+		RevocationTimestamp: time.Time{},
+		EngineID:            successor.Certificate.EngineID,
+	}
+
+	lFunc.Debugf("insert cross certificate %s in storage engine", cert.SerialNumber)
+	_, err = svc.certStorage.Insert(ctx, &cert)
+	if err != nil {
+		lFunc.Errorf("could not insert cross certificate %s: %s", cert.SerialNumber, err)
+		return nil, err
+	}
+
+	succession := models.CASuccession{
+		CAID:                         ca.ID,
+		SuccessorCAID:                successor.ID,
+		CrossCertificateSerialNumber: cert.SerialNumber,
+		ExpiresAt:                    ca.Certificate.ValidTo,
+		CreatedAt:                    time.Now(),
+	}
+
+	if ca.Metadata == nil {
+		ca.Metadata = map[string]any{}
+	}
+	ca.Metadata[models.CAMetadataSuccessorKey] = succession
+
+	lFunc.Debugf("updating CA %s successor", ca.ID)
+	_, err = svc.caStorage.Update(ctx, ca)
+	if err != nil {
+		lFunc.Errorf("could not update CA %s successor in storage engine: %s", ca.ID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("CA %s replaced by successor CA %s", ca.ID, successor.ID)
+	svc.recordCAEvent(ctx, ca.ID, models.CAEventSuccessorCreated, map[string]any{
+		"successor_id":                    successor.ID,
+		"cross_certificate_serial_number": cert.SerialNumber,
+	})
+
+	return &succession, nil
+}
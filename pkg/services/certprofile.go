@@ -0,0 +1,291 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type CreateCertificateProfileInput struct {
+	ID                 string
+	Name               string `validate:"required"`
+	Description        string
+	CAIDs              []string
+	KeyUsages          []models.KeyUsage
+	ExtendedKeyUsages  []models.ExtendedKeyUsage
+	SANPolicy          models.SANPolicy
+	SubjectConstraints models.SubjectConstraints
+	MaxValidity        *models.TimeDuration
+	NotBeforeBackdate  *models.TimeDuration
+}
+
+// Returned Error Codes:
+//   - ErrCertificateProfileAlreadyExists
+//     A certificate profile with the same ID already exists.
+//   - ErrCertificateProfileCABound
+//     One of the CAs is already bound to another certificate profile.
+//   - ErrCANotFound
+//     One of the CAs can not be found in the Database.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the profile references unknown usages,
+//     subject attributes or an invalid common name pattern.
+func (svc *CAServiceBackend) CreateCertificateProfile(ctx context.Context, input CreateCertificateProfileInput) (*models.CertificateProfile, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("CreateCertificateProfileInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	profile := models.CertificateProfile{
+		ID:                 input.ID,
+		Name:               input.Name,
+		Description:        input.Description,
+		CAIDs:              input.CAIDs,
+		KeyUsages:          input.KeyUsages,
+		ExtendedKeyUsages:  input.ExtendedKeyUsages,
+		SANPolicy:          input.SANPolicy,
+		SubjectConstraints: input.SubjectConstraints,
+		MaxValidity:        input.MaxValidity,
+		NotBeforeBackdate:  input.NotBeforeBackdate,
+		CreationTS:         time.Now(),
+	}
+
+	if profile.ID == "" {
+		profile.ID = goid.NewV4UUID().String()
+	}
+
+	exists, _, err := svc.certProfileStorage.SelectExists(ctx, profile.ID)
+	if err != nil {
+		lFunc.Errorf("could not check if certificate profile %s exists: %s", profile.ID, err)
+		return nil, err
+	}
+
+	if exists {
+		lFunc.Errorf("certificate profile %s already exists", profile.ID)
+		return nil, errs.ErrCertificateProfileAlreadyExists
+	}
+
+	err = svc.checkCertificateProfile(ctx, lFunc, profile)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Debugf("insert certificate profile %s in storage engine", profile.ID)
+	return svc.certProfileStorage.Insert(ctx, &profile)
+}
+
+type GetCertificateProfileByIDInput struct {
+	ID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrCertificateProfileNotFound
+//     The specified certificate profile can not be found in the Database.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) GetCertificateProfileByID(ctx context.Context, input GetCertificateProfileByIDInput) (*models.CertificateProfile, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetCertificateProfileByIDInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, profile, err := svc.certProfileStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("could not check if certificate profile %s exists: %s", input.ID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("certificate profile %s can not be found in storage engine", input.ID)
+		return nil, errs.ErrCertificateProfileNotFound
+	}
+
+	return profile, nil
+}
+
+type GetCertificateProfilesInput struct {
+	resources.ListInput[models.CertificateProfile]
+}
+
+func (svc *CAServiceBackend) GetCertificateProfiles(ctx context.Context, input GetCertificateProfilesInput) (string, error) {
+	return svc.certProfileStorage.SelectAll(ctx, storage.StorageListRequest[models.CertificateProfile]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   input.QueryParameters,
+		ExtraOpts:     nil,
+	})
+}
+
+type UpdateCertificateProfileInput struct {
+	Profile models.CertificateProfile `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrCertificateProfileNotFound
+//     The specified certificate profile can not be found in the Database.
+//   - ErrCertificateProfileCABound
+//     One of the CAs is already bound to another certificate profile.
+//   - ErrCANotFound
+//     One of the CAs can not be found in the Database.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the profile references unknown usages,
+//     subject attributes or an invalid common name pattern.
+func (svc *CAServiceBackend) UpdateCertificateProfile(ctx context.Context, input UpdateCertificateProfileInput) (*models.CertificateProfile, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil || input.Profile.ID == "" || input.Profile.Name == "" {
+		lFunc.Errorf("UpdateCertificateProfileInput struct validation error: %v", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, profile, err := svc.certProfileStorage.SelectExists(ctx, input.Profile.ID)
+	if err != nil {
+		lFunc.Errorf("could not check if certificate profile %s exists: %s", input.Profile.ID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("certificate profile %s can not be found in storage engine", input.Profile.ID)
+		return nil, errs.ErrCertificateProfileNotFound
+	}
+
+	updated := input.Profile
+	updated.CreationTS = profile.CreationTS
+
+	err = svc.checkCertificateProfile(ctx, lFunc, updated)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Debugf("updating certificate profile %s", updated.ID)
+	return svc.certProfileStorage.Update(ctx, &updated)
+}
+
+type DeleteCertificateProfileInput struct {
+	ID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrCertificateProfileNotFound
+//     The specified certificate profile can not be found in the Database.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) DeleteCertificateProfile(ctx context.Context, input DeleteCertificateProfileInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("DeleteCertificateProfileInput struct validation error: %s", err)
+		return errs.ErrValidateBadRequest
+	}
+
+	exists, _, err := svc.certProfileStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("could not check if certificate profile %s exists: %s", input.ID, err)
+		return err
+	}
+
+	if !exists {
+		lFunc.Errorf("certificate profile %s can not be found in storage engine", input.ID)
+		return errs.ErrCertificateProfileNotFound
+	}
+
+	lFunc.Debugf("deleting certificate profile %s", input.ID)
+	return svc.certProfileStorage.Delete(ctx, input.ID)
+}
+
+// checkCertificateProfile validates the profile definition and checks that its CAs exist and are not bound
+// to another profile.
+func (svc *CAServiceBackend) checkCertificateProfile(ctx context.Context, lFunc *logrus.Entry, profile models.CertificateProfile) error {
+	err := helpers.ValidateCertificateProfile(profile)
+	if err != nil {
+		lFunc.Errorf("invalid certificate profile %s: %s", profile.ID, err)
+		return errs.ErrValidateBadRequest
+	}
+
+	for _, caID := range profile.CAIDs {
+		exists, _, err := svc.caStorage.SelectExistsByID(ctx, caID)
+		if err != nil {
+			lFunc.Errorf("could not check if CA %s exists: %s", caID, err)
+			return err
+		}
+
+		if !exists {
+			lFunc.Errorf("CA %s of certificate profile %s can not be found in storage engine", caID, profile.ID)
+			return errs.ErrCANotFound
+		}
+	}
+
+	boundProfileID := ""
+	_, err = svc.certProfileStorage.SelectAll(ctx, storage.StorageListRequest[models.CertificateProfile]{
+		ExhaustiveRun: true,
+		QueryParams:   &resources.QueryParameters{},
+		ExtraOpts:     map[string]interface{}{},
+		ApplyFunc: func(other models.CertificateProfile) {
+			if other.ID == profile.ID {
+				return
+			}
+
+			if slices.ContainsFunc(other.CAIDs, func(caID string) bool { return slices.Contains(profile.CAIDs, caID) }) {
+				boundProfileID = other.ID
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list certificate profiles: %s", err)
+		return err
+	}
+
+	if boundProfileID != "" {
+		lFunc.Errorf("certificate profile %s shares CAs with certificate profile %s", profile.ID, boundProfileID)
+		return errs.ErrCertificateProfileCABound
+	}
+
+	return nil
+}
+
+// resolveCertificateProfile returns the profile enforced when signing with the CA: the profile bound to the CA,
+// or nil if none is bound. profileID can only pin the bound profile: a profile that is not bound to the CA is
+// rejected, so callers can't escape the profile of the CA by referencing another one.
+func (svc *CAServiceBackend) resolveCertificateProfile(ctx context.Context, lFunc *logrus.Entry, ca *models.CACertificate, profileID string) (*models.CertificateProfile, error) {
+	if svc.certProfileStorage == nil {
+		return nil, nil
+	}
+
+	var boundProfile *models.CertificateProfile
+	_, err := svc.certProfileStorage.SelectByCA(ctx, ca.ID, storage.StorageListRequest[models.CertificateProfile]{
+		ExhaustiveRun: true,
+		QueryParams:   &resources.QueryParameters{},
+		ExtraOpts:     map[string]interface{}{},
+		ApplyFunc: func(profile models.CertificateProfile) {
+			if boundProfile == nil {
+				boundProfile = &profile
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not get the certificate profile bound to CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	if profileID != "" && (boundProfile == nil || boundProfile.ID != profileID) {
+		lFunc.Errorf("certificate profile %s is not bound to CA %s and can not be used to sign with it", profileID, ca.ID)
+		return nil, errs.ErrCertificateProfileViolation
+	}
+
+	return boundProfile, nil
+}
@@ -20,23 +20,35 @@ var crlValidate *validator.Validate
 
 type CRLService interface {
 	GetCRL(ctx context.Context, input GetCRLInput) ([]byte, error)
+	// GetSignedCRL returns the CRL along with a detached JWS signed by its CA.
+	GetSignedCRL(ctx context.Context, input GetCRLInput) (*models.SignedContent, error)
 }
 
 type crlServiceImpl struct {
-	caSDK  CAService
-	logger *logrus.Entry
+	caSDK    CAService
+	validity time.Duration
+	logger   *logrus.Entry
 }
 
 type CRLServiceBuilder struct {
 	Logger   *logrus.Entry
 	CAClient CAService
+	// Validity is the NextUpdate window of the generated CRLs. Defaults to 48h.
+	Validity time.Duration
 }
 
 func NewCRLService(builder CRLServiceBuilder) CRLService {
 	crlValidate = validator.New()
+
+	validity := builder.Validity
+	if validity == 0 {
+		validity = time.Hour * 48
+	}
+
 	return &crlServiceImpl{
-		caSDK:  builder.CAClient,
-		logger: builder.Logger,
+		caSDK:    builder.CAClient,
+		validity: validity,
+		logger:   builder.Logger,
 	}
 }
 
@@ -45,12 +57,35 @@ type GetCRLInput struct {
 }
 
 func (svc crlServiceImpl) GetCRL(ctx context.Context, input GetCRLInput) ([]byte, error) {
+	crl, _, err := svc.createCRL(ctx, input)
+	return crl, err
+}
+
+func (svc crlServiceImpl) GetSignedCRL(ctx context.Context, input GetCRLInput) (*models.SignedContent, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	crl, ca, err := svc.createCRL(ctx, input)
+	if err != nil {
+		return nil, err
+	}
+
+	lFunc.Debugf("signing CRL of CA %s", ca.ID)
+	signed, err := SignDetachedContent(ctx, svc.caSDK, ca, crl)
+	if err != nil {
+		lFunc.Errorf("something went wrong while signing CRL of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	return signed, nil
+}
+
+func (svc crlServiceImpl) createCRL(ctx context.Context, input GetCRLInput) ([]byte, *models.CACertificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
 	err := crlValidate.Struct(input)
 	if err != nil {
 		lFunc.Errorf("struct validation error: %s", err)
-		return nil, errs.ErrValidateBadRequest
+		return nil, nil, errs.ErrValidateBadRequest
 	}
 
 	certList := []x509.RevocationListEntry{}
@@ -75,12 +110,12 @@ func (svc crlServiceImpl) GetCRL(ctx context.Context, input GetCRLInput) ([]byte
 	})
 	if err != nil {
 		lFunc.Errorf("something went wrong while reading CA %s certificates: %s", input.CAID, err)
-		return nil, err
+		return nil, nil, err
 	}
 
 	ca, err := svc.caSDK.GetCAByID(ctx, GetCAByIDInput(input))
 	if err != nil {
-		return nil, err
+		return nil, nil, err
 	}
 
 	caSigner := NewCASigner(ctx, ca, svc.caSDK)
@@ -88,16 +123,29 @@ func (svc crlServiceImpl) GetCRL(ctx context.Context, input GetCRLInput) ([]byte
 
 	lFunc.Debugf("creating revocation list. CA %s", input.CAID)
 	now := time.Now()
+	number := big.NewInt(time.Now().UnixMilli())
 	crl, err := x509.CreateRevocationList(rand.Reader, &x509.RevocationList{
 		RevokedCertificateEntries: certList,
-		Number:                    big.NewInt(time.Now().UnixMilli()),
+		Number:                    number,
 		ThisUpdate:                now,
-		NextUpdate:                now.Add(time.Hour * 48),
+		NextUpdate:                now.Add(svc.validity),
 	}, caCert, caSigner)
 	if err != nil {
 		lFunc.Errorf("something went wrong while creating revocation list: %s", err)
-		return nil, err
+		return nil, nil, err
+	}
+
+	// the CA timeline is best effort, the CRL is served even if it can not be reported
+	err = svc.caSDK.ReportCRLGeneration(ctx, ReportCRLGenerationInput{
+		CAID:                ca.ID,
+		Number:              number.String(),
+		ThisUpdate:          now,
+		NextUpdate:          now.Add(svc.validity),
+		RevokedCertificates: len(certList),
+	})
+	if err != nil {
+		lFunc.Warnf("could not report CRL %s of CA %s: %s", number, ca.ID, err)
 	}
 
-	return crl, nil
+	return crl, ca, nil
 }
@@ -0,0 +1,713 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type CreateDeviceGroupInput struct {
+	Name        string `validate:"required"`
+	Description string
+	DeviceIDs   []string
+	Tags        []string
+	// Filters and CertificateStatus define a dynamic group. They can not be combined with DeviceIDs nor Tags.
+	Filters           []string
+	CertificateStatus models.DeviceCertificateStatus
+}
+
+// CreateDeviceGroup creates a group with the listed devices and, dynamically, the devices with any of the tags.
+// Groups created with filters hold the devices matching them instead.
+//
+// Returned Error Codes:
+//   - ErrDeviceNotFound
+//     One of the listed devices does not exist.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, or the filters are not valid or combined with
+//     static members or tags.
+func (svc DeviceManagerServiceBackend) CreateDeviceGroup(ctx context.Context, input CreateDeviceGroupInput) (*models.DeviceGroup, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dynamic := len(input.Filters) > 0 || input.CertificateStatus != ""
+	if dynamic && (len(input.DeviceIDs) > 0 || len(input.Tags) > 0) {
+		lFunc.Errorf("dynamic device groups can not have static members nor tags")
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, err = parseDeviceGroupFilters(input.Filters, input.CertificateStatus)
+	if err != nil {
+		lFunc.Errorf("invalid device group filters: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = svc.checkDevicesExist(ctx, input.DeviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	group := &models.DeviceGroup{
+		ID:                goid.NewV4UUID().String(),
+		Name:              input.Name,
+		Description:       input.Description,
+		DeviceIDs:         uniqueStrings(input.DeviceIDs),
+		Tags:              uniqueStrings(input.Tags),
+		Filters:           uniqueStrings(input.Filters),
+		CertificateStatus: input.CertificateStatus,
+		CreationTimestamp: time.Now(),
+	}
+
+	lFunc.Debugf("creating device group '%s' (%s)", group.Name, group.ID)
+	return svc.groupsStorage.Insert(ctx, group)
+}
+
+type GetDeviceGroupsInput struct {
+	resources.ListInput[models.DeviceGroup]
+}
+
+func (svc DeviceManagerServiceBackend) GetDeviceGroups(ctx context.Context, input GetDeviceGroupsInput) (string, error) {
+	return svc.groupsStorage.SelectAll(ctx, storage.StorageListRequest[models.DeviceGroup]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   input.QueryParameters,
+	})
+}
+
+type GetDeviceGroupByIDInput struct {
+	ID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) GetDeviceGroupByID(ctx context.Context, input GetDeviceGroupByIDInput) (*models.DeviceGroup, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, group, err := svc.groupsStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device group '%s' exists in storage engine: %s", input.ID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("device group '%s' does not exist", input.ID)
+		return nil, errs.ErrDeviceGroupNotFound
+	}
+
+	return group, nil
+}
+
+type UpdateDeviceGroupDevicesInput struct {
+	ID              string `validate:"required"`
+	AddDeviceIDs    []string
+	RemoveDeviceIDs []string
+}
+
+// UpdateDeviceGroupDevices adds and removes devices from the static members of the group. Devices joining the group
+// through its tags can only leave it by removing the tag from the device.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrDeviceNotFound
+//     One of the added devices does not exist.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the group is dynamic.
+func (svc DeviceManagerServiceBackend) UpdateDeviceGroupDevices(ctx context.Context, input UpdateDeviceGroupDevicesInput) (*models.DeviceGroup, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	group, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if isDynamicDeviceGroup(group) && len(input.AddDeviceIDs) > 0 {
+		lFunc.Errorf("device group '%s' is dynamic and can not have static members", group.ID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = svc.checkDevicesExist(ctx, input.AddDeviceIDs)
+	if err != nil {
+		return nil, err
+	}
+
+	deviceIDs := slices.DeleteFunc(append(group.DeviceIDs, input.AddDeviceIDs...), func(id string) bool {
+		return slices.Contains(input.RemoveDeviceIDs, id)
+	})
+	group.DeviceIDs = uniqueStrings(deviceIDs)
+
+	lFunc.Debugf("updating devices of device group '%s'. %d static members", group.ID, len(group.DeviceIDs))
+	return svc.groupsStorage.Update(ctx, group)
+}
+
+type UpdateDeviceGroupTagsInput struct {
+	ID   string `validate:"required"`
+	Tags []string
+}
+
+// UpdateDeviceGroupTags replaces the tags selecting the dynamic members of the group.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the group is defined by filters.
+func (svc DeviceManagerServiceBackend) UpdateDeviceGroupTags(ctx context.Context, input UpdateDeviceGroupTagsInput) (*models.DeviceGroup, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	group, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if isDynamicDeviceGroup(group) && len(input.Tags) > 0 {
+		lFunc.Errorf("device group '%s' is defined by filters and can not have tags", group.ID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	group.Tags = uniqueStrings(input.Tags)
+
+	lFunc.Debugf("updating tags of device group '%s': %v", group.ID, group.Tags)
+	return svc.groupsStorage.Update(ctx, group)
+}
+
+type UpdateDeviceGroupFiltersInput struct {
+	ID                string `validate:"required"`
+	Filters           []string
+	CertificateStatus models.DeviceCertificateStatus
+}
+
+// UpdateDeviceGroupFilters replaces the filters selecting the members of a dynamic group. Removing every filter turns
+// the group into an empty static group.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, the filters are not valid or the group has static
+//     members or tags.
+func (svc DeviceManagerServiceBackend) UpdateDeviceGroupFilters(ctx context.Context, input UpdateDeviceGroupFiltersInput) (*models.DeviceGroup, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	group, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(group.DeviceIDs) > 0 || len(group.Tags) > 0 {
+		lFunc.Errorf("device group '%s' has static members or tags and can not be defined by filters", group.ID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, err = parseDeviceGroupFilters(input.Filters, input.CertificateStatus)
+	if err != nil {
+		lFunc.Errorf("invalid device group filters: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	group.Filters = uniqueStrings(input.Filters)
+	group.CertificateStatus = input.CertificateStatus
+
+	lFunc.Debugf("updating filters of device group '%s': %v", group.ID, group.Filters)
+	return svc.groupsStorage.Update(ctx, group)
+}
+
+type DeleteDeviceGroupInput struct {
+	ID string `validate:"required"`
+}
+
+// DeleteDeviceGroup deletes the group. The devices and the bulk actions already run on the group are kept.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) DeleteDeviceGroup(ctx context.Context, input DeleteDeviceGroupInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	_, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.ID})
+	if err != nil {
+		return err
+	}
+
+	lFunc.Debugf("deleting device group '%s'", input.ID)
+	return svc.groupsStorage.Delete(ctx, input.ID)
+}
+
+type GetDeviceGroupDevicesInput struct {
+	ID string `validate:"required"`
+	resources.ListInput[models.Device]
+}
+
+// GetDeviceGroupDevices iterates the devices of the group. Confidential slot payloads are always redacted. As devices
+// outside the group are skipped, a page may hold fewer entries than the requested page size.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) GetDeviceGroupDevices(ctx context.Context, input GetDeviceGroupDevicesInput) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	group, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.ID})
+	if err != nil {
+		return "", err
+	}
+
+	lFunc.Debugf("getting all devices of device group '%s'", group.ID)
+	return svc.selectDeviceGroupDevices(ctx, group, input.ExhaustiveRun, redactDeviceSecretsApplyFunc(input.ApplyFunc), input.QueryParameters)
+}
+
+type StartDeviceGroupBulkActionInput struct {
+	GroupID string                           `validate:"required"`
+	Type    models.DeviceGroupBulkActionType `validate:"required"`
+	// Metadata is merged into the metadata of the devices by UPDATE_METADATA actions.
+	Metadata map[string]any
+}
+
+// StartDeviceGroupBulkAction resolves the devices of the group and runs the action on each of them in the background.
+// The returned action is RUNNING, its progress is reported by GetDeviceGroupBulkActionByID.
+//   - FORCE_REENROLL flags the devices so they can reenroll before the reenrollment window of their DMS opens.
+//   - REVOKE revokes the identity slot of the devices (and its certificate). If the service requires the approval of
+//     bulk revocations, the returned action is PENDING_APPROVAL and only runs once approved with
+//     ApproveDeviceGroupBulkAction.
+//   - UPDATE_METADATA merges the action metadata into the metadata of the devices.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupNotFound
+//     The specified group can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the action type is unknown.
+func (svc DeviceManagerServiceBackend) StartDeviceGroupBulkAction(ctx context.Context, input StartDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	switch input.Type {
+	case models.DeviceGroupBulkActionForceReenroll, models.DeviceGroupBulkActionRevoke:
+	case models.DeviceGroupBulkActionUpdateMetadata:
+		if len(input.Metadata) == 0 {
+			lFunc.Errorf("%s bulk action requires metadata", input.Type)
+			return nil, errs.ErrValidateBadRequest
+		}
+	default:
+		lFunc.Errorf("unknown bulk action type '%s'", input.Type)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	group, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.GroupID})
+	if err != nil {
+		return nil, err
+	}
+
+	if input.Type == models.DeviceGroupBulkActionRevoke && svc.bulkRevocationApprovalWindow > 0 {
+		now := time.Now()
+		expiresAt := now.Add(svc.bulkRevocationApprovalWindow)
+		action, err := svc.bulkActionsStorage.Insert(ctx, &models.DeviceGroupBulkAction{
+			ID:                goid.NewV4UUID().String(),
+			GroupID:           group.ID,
+			Type:              input.Type,
+			Status:            models.DeviceGroupBulkActionPendingApproval,
+			Errors:            []models.DeviceGroupBulkActionError{},
+			CreatedBy:         verifiedCallerID(ctx),
+			CreationTimestamp: now,
+			ApprovalExpiresAt: &expiresAt,
+		})
+		if err != nil {
+			lFunc.Errorf("could not store %s bulk action of device group '%s': %s", input.Type, group.ID, err)
+			return nil, err
+		}
+
+		lFunc.Infof("%s bulk action %s of device group '%s' waiting for approval until %s", action.Type, action.ID, group.ID, expiresAt)
+		return action, nil
+	}
+
+	deviceIDs, err := svc.resolveDeviceGroupDeviceIDs(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	action, err := svc.bulkActionsStorage.Insert(ctx, &models.DeviceGroupBulkAction{
+		ID:                goid.NewV4UUID().String(),
+		GroupID:           group.ID,
+		Type:              input.Type,
+		Metadata:          input.Metadata,
+		Status:            models.DeviceGroupBulkActionRunning,
+		Total:             len(deviceIDs),
+		Errors:            []models.DeviceGroupBulkActionError{},
+		CreatedBy:         callerID(ctx),
+		CreationTimestamp: time.Now(),
+	})
+	if err != nil {
+		lFunc.Errorf("could not store %s bulk action of device group '%s': %s", input.Type, group.ID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("starting %s bulk action %s on %d devices of device group '%s'", action.Type, action.ID, action.Total, group.ID)
+
+	// the action outlives the request that started it
+	progress := *action
+	go svc.runDeviceGroupBulkAction(context.WithoutCancel(ctx), &progress, deviceIDs)
+
+	return action, nil
+}
+
+type ApproveDeviceGroupBulkActionInput struct {
+	GroupID  string `validate:"required"`
+	ActionID string `validate:"required"`
+}
+
+// ApproveDeviceGroupBulkAction approves a PENDING_APPROVAL bulk action, resolving the devices of the group and running
+// it in the background. The approver must be verified by the authentication of the API and be a different
+// administrator than the one who started the action.
+//
+// Returned Error Codes:
+//   - ErrDeviceGroupBulkActionNotFound
+//     The group has not started a bulk action with the specified ID.
+//   - ErrDeviceGroupBulkActionNotPending
+//     The action is not waiting for approval (i.e. it was already approved).
+//   - ErrDeviceGroupBulkActionApprovalExpired
+//     The approval window of the action expired.
+//   - ErrDeviceGroupBulkActionUnverifiedApprover
+//     The identity of the approver was not verified by the authentication of the API.
+//   - ErrDeviceGroupBulkActionSelfApproval
+//     The approver is the administrator who started the action.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) ApproveDeviceGroupBulkAction(ctx context.Context, input ApproveDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	action, err := svc.GetDeviceGroupBulkActionByID(ctx, GetDeviceGroupBulkActionByIDInput{GroupID: input.GroupID, ActionID: input.ActionID})
+	if err != nil {
+		return nil, err
+	}
+
+	if action.Status != models.DeviceGroupBulkActionPendingApproval {
+		lFunc.Errorf("bulk action %s of device group '%s' is %s and can not be approved", action.ID, input.GroupID, action.Status)
+		return nil, errs.ErrDeviceGroupBulkActionNotPending
+	}
+
+	now := time.Now()
+	if action.ApprovalExpiresAt != nil && now.After(*action.ApprovalExpiresAt) {
+		lFunc.Errorf("approval window of bulk action %s of device group '%s' expired at %s", action.ID, input.GroupID, action.ApprovalExpiresAt)
+		return nil, errs.ErrDeviceGroupBulkActionApprovalExpired
+	}
+
+	approver := verifiedCallerID(ctx)
+	if approver == "" {
+		lFunc.Errorf("bulk action %s of device group '%s' can only be approved by an administrator verified by the API authentication", action.ID, input.GroupID)
+		return nil, errs.ErrDeviceGroupBulkActionUnverifiedApprover
+	}
+
+	if approver == action.CreatedBy {
+		lFunc.Errorf("bulk action %s of device group '%s' must be approved by a different administrator than '%s'", action.ID, input.GroupID, action.CreatedBy)
+		return nil, errs.ErrDeviceGroupBulkActionSelfApproval
+	}
+
+	group, err := svc.GetDeviceGroupByID(ctx, GetDeviceGroupByIDInput{ID: input.GroupID})
+	if err != nil {
+		return nil, err
+	}
+
+	deviceIDs, err := svc.resolveDeviceGroupDeviceIDs(ctx, group)
+	if err != nil {
+		return nil, err
+	}
+
+	action.Status = models.DeviceGroupBulkActionRunning
+	action.Total = len(deviceIDs)
+	action.ApprovedBy = approver
+	action.ApprovedAt = &now
+	action, err = svc.bulkActionsStorage.UpdateIf(ctx, action, func(current *models.DeviceGroupBulkAction) bool {
+		return current.Status == models.DeviceGroupBulkActionPendingApproval
+	})
+	if errors.Is(err, storage.ErrUpdateConflict) {
+		lFunc.Errorf("bulk action %s of device group '%s' has already been approved", input.ActionID, input.GroupID)
+		return nil, errs.ErrDeviceGroupBulkActionNotPending
+	} else if err != nil {
+		lFunc.Errorf("could not approve bulk action %s of device group '%s': %s", input.ActionID, input.GroupID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("%s bulk action %s started by '%s' approved by '%s'. Running on %d devices of device group '%s'", action.Type, action.ID, action.CreatedBy, approver, action.Total, group.ID)
+
+	progress := *action
+	go svc.runDeviceGroupBulkAction(context.WithoutCancel(ctx), &progress, deviceIDs)
+
+	return action, nil
+}
+
+func (svc DeviceManagerServiceBackend) resolveDeviceGroupDeviceIDs(ctx context.Context, group *models.DeviceGroup) ([]string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	deviceIDs := []string{}
+	_, err := svc.selectDeviceGroupDevices(ctx, group, true, func(device models.Device) {
+		deviceIDs = append(deviceIDs, device.ID)
+	}, nil)
+	if err != nil {
+		lFunc.Errorf("could not resolve devices of device group '%s': %s", group.ID, err)
+		return nil, err
+	}
+
+	return deviceIDs, nil
+}
+
+// runDeviceGroupBulkAction runs the action on each device, storing the progress after each one.
+func (svc DeviceManagerServiceBackend) runDeviceGroupBulkAction(ctx context.Context, action *models.DeviceGroupBulkAction, deviceIDs []string) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	for _, deviceID := range deviceIDs {
+		err := svc.applyDeviceGroupBulkAction(ctx, action, deviceID)
+		if err != nil {
+			lFunc.Warnf("%s bulk action %s failed on device '%s': %s", action.Type, action.ID, deviceID, err)
+			action.Failed++
+			action.Errors = append(action.Errors, models.DeviceGroupBulkActionError{
+				DeviceID: deviceID,
+				Error:    err.Error(),
+			})
+		} else {
+			action.Succeeded++
+		}
+
+		_, err = svc.bulkActionsStorage.Update(ctx, action)
+		if err != nil {
+			lFunc.Errorf("could not update progress of bulk action %s: %s", action.ID, err)
+		}
+	}
+
+	now := time.Now()
+	action.Status = models.DeviceGroupBulkActionCompleted
+	action.CompletionTimestamp = &now
+	_, err := svc.bulkActionsStorage.Update(ctx, action)
+	if err != nil {
+		lFunc.Errorf("could not complete bulk action %s: %s", action.ID, err)
+	}
+
+	lFunc.Infof("%s bulk action %s completed. %d succeeded, %d failed", action.Type, action.ID, action.Succeeded, action.Failed)
+}
+
+func (svc DeviceManagerServiceBackend) applyDeviceGroupBulkAction(ctx context.Context, action *models.DeviceGroupBulkAction, deviceID string) error {
+	device, err := svc.service.GetDeviceByID(ctx, GetDeviceByIDInput{ID: deviceID})
+	if err != nil {
+		return err
+	}
+
+	metadata := map[string]any{}
+	for key, value := range device.Metadata {
+		metadata[key] = value
+	}
+
+	switch action.Type {
+	case models.DeviceGroupBulkActionRevoke:
+		if device.IdentitySlot == nil {
+			return errs.ErrDeviceNoIdentity
+		} else if device.IdentitySlot.Status == models.SlotRevoke {
+			return nil
+		}
+
+		slot := *device.IdentitySlot
+		slot.Status = models.SlotRevoke
+		_, err = svc.service.UpdateDeviceIdentitySlot(ctx, UpdateDeviceIdentitySlotInput{
+			ID:   device.ID,
+			Slot: slot,
+		})
+		return err
+	case models.DeviceGroupBulkActionForceReenroll:
+		_, err = svc.service.ForceDeviceReenroll(ctx, ForceDeviceReenrollInput{
+			ID:     device.ID,
+			Reason: fmt.Sprintf("device group bulk action %s", action.ID),
+		})
+		return err
+	case models.DeviceGroupBulkActionUpdateMetadata:
+		for key, value := range action.Metadata {
+			metadata[key] = value
+		}
+	}
+
+	_, err = svc.service.UpdateDeviceMetadata(ctx, UpdateDeviceMetadataInput{
+		ID:       device.ID,
+		Metadata: metadata,
+	})
+	return err
+}
+
+type GetDeviceGroupBulkActionsInput struct {
+	GroupID string `validate:"required"`
+	resources.ListInput[models.DeviceGroupBulkAction]
+}
+
+// GetDeviceGroupBulkActions iterates the bulk actions run on the group, including the running ones.
+//
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) GetDeviceGroupBulkActions(ctx context.Context, input GetDeviceGroupBulkActionsInput) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return "", errs.ErrValidateBadRequest
+	}
+
+	return svc.bulkActionsStorage.SelectByGroup(ctx, input.GroupID, storage.StorageListRequest[models.DeviceGroupBulkAction]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   input.QueryParameters,
+	})
+}
+
+type GetDeviceGroupBulkActionByIDInput struct {
+	GroupID  string `validate:"required"`
+	ActionID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrDeviceGroupBulkActionNotFound
+//     The group has not run a bulk action with the specified ID.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) GetDeviceGroupBulkActionByID(ctx context.Context, input GetDeviceGroupBulkActionByIDInput) (*models.DeviceGroupBulkAction, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, action, err := svc.bulkActionsStorage.SelectExists(ctx, input.ActionID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if bulk action '%s' exists in storage engine: %s", input.ActionID, err)
+		return nil, err
+	} else if !exists || action.GroupID != input.GroupID {
+		lFunc.Errorf("device group '%s' has no bulk action '%s'", input.GroupID, input.ActionID)
+		return nil, errs.ErrDeviceGroupBulkActionNotFound
+	}
+
+	return action, nil
+}
+
+func (svc DeviceManagerServiceBackend) checkDevicesExist(ctx context.Context, deviceIDs []string) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	for _, deviceID := range deviceIDs {
+		exists, _, err := svc.devicesStorage.SelectExists(ctx, deviceID)
+		if err != nil {
+			lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", deviceID, err)
+			return err
+		} else if !exists {
+			lFunc.Errorf("device %s can not be found in storage engine", deviceID)
+			return errs.ErrDeviceNotFound
+		}
+	}
+
+	return nil
+}
+
+// selectDeviceGroupDevices iterates the members of the group. The filters of dynamic groups are added to the query
+// parameters, so they are evaluated by the storage engine (and its indexes). Static and tagged groups are resolved
+// by checking each device.
+func (svc DeviceManagerServiceBackend) selectDeviceGroupDevices(ctx context.Context, group *models.DeviceGroup, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters) (string, error) {
+	if !isDynamicDeviceGroup(group) {
+		return svc.devicesStorage.SelectAll(ctx, exhaustiveRun, func(device models.Device) {
+			if deviceInGroup(group, device) {
+				applyFunc(device)
+			}
+		}, queryParams, nil)
+	}
+
+	filters, err := parseDeviceGroupFilters(group.Filters, group.CertificateStatus)
+	if err != nil {
+		return "", fmt.Errorf("invalid filters in device group '%s': %w", group.ID, err)
+	}
+
+	for _, filter := range filters {
+		queryParams = withQueryFilter(queryParams, filter)
+	}
+
+	if group.CertificateStatus != "" {
+		return svc.devicesStorage.SelectByIdentitySlotStatus(ctx, models.DeviceCertificateStatusSlots[group.CertificateStatus], exhaustiveRun, applyFunc, queryParams, nil)
+	}
+
+	return svc.devicesStorage.SelectAll(ctx, exhaustiveRun, applyFunc, queryParams, nil)
+}
+
+func isDynamicDeviceGroup(group *models.DeviceGroup) bool {
+	return len(group.Filters) > 0 || group.CertificateStatus != ""
+}
+
+// parseDeviceGroupFilters parses the filter expressions of a dynamic group over the device filtrable fields.
+func parseDeviceGroupFilters(expressions []string, certStatus models.DeviceCertificateStatus) ([]resources.FilterOption, error) {
+	if _, ok := models.DeviceCertificateStatusSlots[certStatus]; certStatus != "" && !ok {
+		return nil, fmt.Errorf("unknown certificate status %s", certStatus)
+	}
+
+	filters := []resources.FilterOption{}
+	for _, expr := range expressions {
+		filter, err := resources.ParseFilterExpression(expr, resources.DeviceFiltrableFields)
+		if err != nil {
+			return nil, err
+		}
+		filters = append(filters, filter)
+	}
+
+	return filters, nil
+}
+
+// deviceInGroup checks if the device is a static member of the group or has any of the group tags.
+func deviceInGroup(group *models.DeviceGroup, device models.Device) bool {
+	if slices.Contains(group.DeviceIDs, device.ID) {
+		return true
+	}
+
+	for _, tag := range group.Tags {
+		if slices.Contains(device.Tags, tag) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func uniqueStrings(values []string) []string {
+	unique := []string{}
+	for _, value := range values {
+		if !slices.Contains(unique, value) {
+			unique = append(unique, value)
+		}
+	}
+	return unique
+}
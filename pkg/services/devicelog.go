@@ -0,0 +1,174 @@
+package services
+
+import (
+	"context"
+	"slices"
+	"time"
+
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type CreateDeviceLogInput struct {
+	DeviceID string                   `validate:"required"`
+	Severity models.DeviceLogSeverity `validate:"required,oneof=DEBUG INFO WARN ERROR"`
+	Message  string                   `validate:"required"`
+	Source   string
+	// Timestamp defaults to the current time.
+	Timestamp time.Time
+}
+
+// CreateDeviceLog appends an entry to the log of the device.
+// Returned Error Codes:
+//   - ErrDeviceNotFound
+//     The specified Device can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) CreateDeviceLog(ctx context.Context, input CreateDeviceLogInput) (*models.DeviceLog, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, _, err := svc.devicesStorage.SelectExists(ctx, input.DeviceID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.DeviceID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.DeviceID)
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	timestamp := input.Timestamp
+	if timestamp.IsZero() {
+		timestamp = time.Now()
+	}
+
+	lFunc.Debugf("inserting %s log entry of device %s", input.Severity, input.DeviceID)
+	return svc.logsStorage.Insert(ctx, &models.DeviceLog{
+		ID:        goid.NewV4UUID().String(),
+		DeviceID:  input.DeviceID,
+		Timestamp: timestamp.UTC(),
+		Severity:  input.Severity,
+		Message:   input.Message,
+		Source:    input.Source,
+	})
+}
+
+type GetDeviceLogsInput struct {
+	DeviceID string `validate:"required"`
+	// Severity selects the entries with the given severity. All entries are selected if empty.
+	Severity models.DeviceLogSeverity `validate:"omitempty,oneof=DEBUG INFO WARN ERROR"`
+	resources.ListInput[models.DeviceLog]
+}
+
+// GetDeviceLogs iterates the log entries of the device.
+// Returned Error Codes:
+//   - ErrDeviceNotFound
+//     The specified Device can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) GetDeviceLogs(ctx context.Context, input GetDeviceLogsInput) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return "", errs.ErrValidateBadRequest
+	}
+
+	exists, _, err := svc.devicesStorage.SelectExists(ctx, input.DeviceID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.DeviceID, err)
+		return "", err
+	}
+
+	if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.DeviceID)
+		return "", errs.ErrDeviceNotFound
+	}
+
+	queryParams := input.QueryParameters
+	if input.Severity != "" {
+		queryParams = withQueryFilter(queryParams, resources.FilterOption{
+			Field:           "severity",
+			FilterOperation: resources.EnumEqual,
+			Value:           string(input.Severity),
+		})
+	}
+
+	return svc.logsStorage.SelectByDevice(ctx, input.DeviceID, storage.StorageListRequest[models.DeviceLog]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   queryParams,
+	})
+}
+
+type PruneDeviceLogsInput struct {
+	// Before is the timestamp of the oldest log entry kept.
+	Before time.Time `validate:"required"`
+}
+
+// PruneDeviceLogs deletes the log entries of all devices older than the given timestamp. It returns the number of
+// deleted entries.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) PruneDeviceLogs(ctx context.Context, input PruneDeviceLogsInput) (int, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return 0, errs.ErrValidateBadRequest
+	}
+
+	// entries are deleted once the iteration ends, so the storage is not modified while being listed
+	expired := []string{}
+	_, err = svc.logsStorage.SelectAll(ctx, storage.StorageListRequest[models.DeviceLog]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(log models.DeviceLog) {
+			expired = append(expired, log.ID)
+		},
+		QueryParams: withQueryFilter(nil, resources.FilterOption{
+			Field:           "timestamp",
+			FilterOperation: resources.DateBefore,
+			Value:           input.Before.UTC().Format(time.RFC3339),
+		}),
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate device logs older than %s: %s", input.Before, err)
+		return 0, err
+	}
+
+	for idx, id := range expired {
+		err = svc.logsStorage.Delete(ctx, id)
+		if err != nil {
+			lFunc.Errorf("could not delete device log entry %s: %s", id, err)
+			return idx, err
+		}
+	}
+
+	lFunc.Infof("pruned %d device log entries older than %s", len(expired), input.Before)
+	return len(expired), nil
+}
+
+// withQueryFilter returns a copy of the query parameters with the filter appended.
+func withQueryFilter(queryParams *resources.QueryParameters, filter resources.FilterOption) *resources.QueryParameters {
+	filtered := resources.QueryParameters{PageSize: resources.DefaultPageSize}
+	if queryParams != nil {
+		filtered = *queryParams
+	}
+
+	filtered.Filters = append(slices.Clone(filtered.Filters), filter)
+	return &filtered
+}
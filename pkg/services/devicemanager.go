@@ -2,7 +2,10 @@ package services
 
 import (
 	"context"
+	"crypto/x509"
+	"encoding/json"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/go-playground/validator/v10"
@@ -21,34 +24,75 @@ type DeviceMiddleware func(DeviceManagerService) DeviceManagerService
 
 type DeviceManagerService interface {
 	GetDevicesStats(ctx context.Context, input GetDevicesStatsInput) (*models.DevicesStats, error)
+	GetIssuanceReport(ctx context.Context, input GetIssuanceReportInput) (*models.IssuanceReport, error)
 	CreateDevice(ctx context.Context, input CreateDeviceInput) (*models.Device, error)
 	GetDeviceByID(ctx context.Context, input GetDeviceByIDInput) (*models.Device, error)
+	GetDeviceByCertificate(ctx context.Context, input GetDeviceByCertificateInput) (*models.CertificateDeviceBinding, error)
 	GetDevices(ctx context.Context, input GetDevicesInput) (string, error)
 	GetDeviceByDMS(ctx context.Context, input GetDevicesByDMSInput) (string, error)
+	GetDevicesOutOfCloudSync(ctx context.Context, input GetDevicesOutOfCloudSyncInput) (string, error)
 	UpdateDeviceStatus(ctx context.Context, input UpdateDeviceStatusInput) (*models.Device, error)
 	UpdateDeviceIdentitySlot(ctx context.Context, input UpdateDeviceIdentitySlotInput) (*models.Device, error)
 	UpdateDeviceMetadata(ctx context.Context, input UpdateDeviceMetadataInput) (*models.Device, error)
+	UpdateDeviceConnectionMetadata(ctx context.Context, input UpdateDeviceConnectionMetadataInput) (*models.Device, error)
+	ReportDeviceHeartbeat(ctx context.Context, input ReportDeviceHeartbeatInput) (*models.Device, error)
+	ForceDeviceReenroll(ctx context.Context, input ForceDeviceReenrollInput) (*models.Device, error)
+	ProvisionDeviceSecretSlot(ctx context.Context, input ProvisionDeviceSecretSlotInput) (*models.Device, error)
+	RotateDeviceSecretSlot(ctx context.Context, input RotateDeviceSecretSlotInput) (*models.Device, error)
+	GetDeviceSecretSlot(ctx context.Context, input GetDeviceSecretSlotInput) (*models.EncryptedSlotSecret, error)
+	CreateDeviceLog(ctx context.Context, input CreateDeviceLogInput) (*models.DeviceLog, error)
+	GetDeviceLogs(ctx context.Context, input GetDeviceLogsInput) (string, error)
+	PruneDeviceLogs(ctx context.Context, input PruneDeviceLogsInput) (int, error)
+
+	CreateDeviceGroup(ctx context.Context, input CreateDeviceGroupInput) (*models.DeviceGroup, error)
+	GetDeviceGroups(ctx context.Context, input GetDeviceGroupsInput) (string, error)
+	GetDeviceGroupByID(ctx context.Context, input GetDeviceGroupByIDInput) (*models.DeviceGroup, error)
+	UpdateDeviceGroupDevices(ctx context.Context, input UpdateDeviceGroupDevicesInput) (*models.DeviceGroup, error)
+	UpdateDeviceGroupTags(ctx context.Context, input UpdateDeviceGroupTagsInput) (*models.DeviceGroup, error)
+	UpdateDeviceGroupFilters(ctx context.Context, input UpdateDeviceGroupFiltersInput) (*models.DeviceGroup, error)
+	DeleteDeviceGroup(ctx context.Context, input DeleteDeviceGroupInput) error
+	GetDeviceGroupDevices(ctx context.Context, input GetDeviceGroupDevicesInput) (string, error)
+	StartDeviceGroupBulkAction(ctx context.Context, input StartDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error)
+	ApproveDeviceGroupBulkAction(ctx context.Context, input ApproveDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error)
+	GetDeviceGroupBulkActions(ctx context.Context, input GetDeviceGroupBulkActionsInput) (string, error)
+	GetDeviceGroupBulkActionByID(ctx context.Context, input GetDeviceGroupBulkActionByIDInput) (*models.DeviceGroupBulkAction, error)
 }
 
 type DeviceManagerServiceBackend struct {
-	devicesStorage storage.DeviceManagerRepo
-	caClient       CAService
-	service        DeviceManagerService
-	logger         *logrus.Entry
+	devicesStorage     storage.DeviceManagerRepo
+	groupsStorage      storage.DeviceGroupsRepo
+	bulkActionsStorage storage.DeviceGroupBulkActionsRepo
+	logsStorage        storage.DeviceLogsRepo
+	caClient           CAService
+	service            DeviceManagerService
+	logger             *logrus.Entry
+
+	bulkRevocationApprovalWindow time.Duration
 }
 
 type DeviceManagerBuilder struct {
-	Logger         *logrus.Entry
-	CAClient       CAService
-	DevicesStorage storage.DeviceManagerRepo
+	Logger                        *logrus.Entry
+	CAClient                      CAService
+	DevicesStorage                storage.DeviceManagerRepo
+	DeviceGroupsStorage           storage.DeviceGroupsRepo
+	DeviceGroupBulkActionsStorage storage.DeviceGroupBulkActionsRepo
+	DeviceLogsStorage             storage.DeviceLogsRepo
+	// BulkRevocationApprovalWindow, if set, requires a second administrator to approve the REVOKE bulk actions
+	// within the window since they were requested.
+	BulkRevocationApprovalWindow time.Duration
 }
 
 func NewDeviceManagerService(builder DeviceManagerBuilder) DeviceManagerService {
 	deviceValidate = validator.New()
 	svc := &DeviceManagerServiceBackend{
-		caClient:       builder.CAClient,
-		devicesStorage: builder.DevicesStorage,
-		logger:         builder.Logger,
+		caClient:           builder.CAClient,
+		devicesStorage:     builder.DevicesStorage,
+		groupsStorage:      builder.DeviceGroupsStorage,
+		bulkActionsStorage: builder.DeviceGroupBulkActionsStorage,
+		logsStorage:        builder.DeviceLogsStorage,
+		logger:             builder.Logger,
+
+		bulkRevocationApprovalWindow: builder.BulkRevocationApprovalWindow,
 	}
 
 	svc.service = svc
@@ -101,6 +145,136 @@ func (svc *DeviceManagerServiceBackend) GetDevicesStats(ctx context.Context, inp
 	return &stats, nil
 }
 
+// maxIssuanceReportPeriod bounds the period of the reports, which are generated synchronously.
+const maxIssuanceReportPeriod = 366 * 24 * time.Hour
+
+type GetIssuanceReportInput struct {
+	From time.Time `validate:"required"`
+	To   time.Time `validate:"required"`
+}
+
+// GetIssuanceReport summarizes the certificates issued, renewed and revoked within [From, To) per CA and per DMS.
+// CA activity covers every certificate issued by the CA. DMS activity covers the identity certificates of the
+// devices owned by the DMS: the first identity version counts as issued, later versions as renewals.
+// The report is generated synchronously, iterating the certificates once, hence its period is limited to a year.
+// Periodic reports are generated in the background and delivered through the event bus (see
+// config.IssuanceReports).
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, To is not after From or the period exceeds
+//     a year.
+func (svc *DeviceManagerServiceBackend) GetIssuanceReport(ctx context.Context, input GetIssuanceReportInput) (*models.IssuanceReport, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil || !input.To.After(input.From) || input.To.Sub(input.From) > maxIssuanceReportPeriod {
+		lFunc.Errorf("struct validation error: %v", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	inPeriod := func(t time.Time) bool {
+		return !t.Before(input.From) && t.Before(input.To)
+	}
+
+	cas := map[string]*models.IssuanceActivity{}
+	dmss := map[string]*models.IssuanceActivity{}
+	activityFor := func(activities map[string]*models.IssuanceActivity, id string) *models.IssuanceActivity {
+		if _, ok := activities[id]; !ok {
+			activities[id] = &models.IssuanceActivity{ID: id}
+		}
+		return activities[id]
+	}
+
+	// The certificates with activity within the period are indexed by serial number while iterating them, so the
+	// device identities are resolved without fetching their certificates one by one.
+	type certActivity struct {
+		issuerCA string
+		issued   bool
+		revoked  bool
+	}
+	certs := map[string]certActivity{}
+
+	lFunc.Debugf("collecting CA issuance activity between %s and %s", input.From, input.To)
+	_, err = svc.caClient.GetCertificates(ctx, GetCertificatesInput{
+		ListInput: resources.ListInput[models.Certificate]{
+			QueryParameters: nil,
+			ExhaustiveRun:   true,
+			ApplyFunc: func(cert models.Certificate) {
+				activity := certActivity{
+					issuerCA: cert.IssuerCAMetadata.ID,
+					issued:   inPeriod(cert.ValidFrom),
+					revoked:  cert.Status == models.StatusRevoked && inPeriod(cert.RevocationTimestamp),
+				}
+				if activity.issued {
+					activityFor(cas, activity.issuerCA).Issued++
+				}
+				if activity.revoked {
+					activityFor(cas, activity.issuerCA).Revoked++
+				}
+				if activity.issued || activity.revoked {
+					certs[cert.SerialNumber] = activity
+				}
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not iterate certificates: %s", err)
+		return nil, err
+	}
+
+	lFunc.Debugf("collecting DMS issuance activity between %s and %s", input.From, input.To)
+	_, err = svc.devicesStorage.SelectAll(ctx, true, func(device models.Device) {
+		if device.IdentitySlot == nil {
+			return
+		}
+
+		for version, sn := range device.IdentitySlot.Secrets {
+			cert, ok := certs[sn]
+			if !ok {
+				continue
+			}
+
+			dmsActivity := activityFor(dmss, device.DMSOwner)
+			if cert.issued {
+				if version == 0 {
+					dmsActivity.Issued++
+				} else {
+					dmsActivity.Renewed++
+					activityFor(cas, cert.issuerCA).Renewed++
+				}
+			}
+			if cert.revoked {
+				dmsActivity.Revoked++
+			}
+		}
+	}, nil, map[string]interface{}{})
+	if err != nil {
+		lFunc.Errorf("could not iterate devices: %s", err)
+		return nil, err
+	}
+
+	return &models.IssuanceReport{
+		From:        input.From,
+		To:          input.To,
+		GeneratedAt: time.Now(),
+		CAs:         sortedIssuanceActivities(cas),
+		DMSs:        sortedIssuanceActivities(dmss),
+	}, nil
+}
+
+func sortedIssuanceActivities(activities map[string]*models.IssuanceActivity) []models.IssuanceActivity {
+	sorted := []models.IssuanceActivity{}
+	for _, activity := range activities {
+		sorted = append(sorted, *activity)
+	}
+
+	sort.Slice(sorted, func(i, j int) bool {
+		return sorted[i].ID < sorted[j].ID
+	})
+
+	return sorted
+}
+
 type CreateDeviceInput struct {
 	ID        string `validate:"required"`
 	Alias     string
@@ -109,8 +283,16 @@ type CreateDeviceInput struct {
 	DMSID     string `validate:"required"`
 	Icon      string `validate:"required"`
 	IconColor string `validate:"required"`
+	// DeviceIDRules of the owner DMS. If set, the ID is normalized (and validated) before registering the device.
+	DeviceIDRules *models.DeviceIDRules
 }
 
+// CreateDevice registers a device without identity.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrDeviceInvalidID
+//     The device ID does not satisfy the DMS device ID rules.
 func (svc DeviceManagerServiceBackend) CreateDevice(ctx context.Context, input CreateDeviceInput) (*models.Device, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
@@ -128,6 +310,19 @@ func (svc DeviceManagerServiceBackend) CreateDevice(ctx context.Context, input C
 		input.Tags = []string{}
 	}
 
+	if input.DeviceIDRules != nil {
+		id, err := helpers.NormalizeDeviceID(input.ID, *input.DeviceIDRules)
+		if err != nil {
+			lFunc.Errorf("invalid device ID: %s", err)
+			return nil, errs.ErrDeviceInvalidID
+		}
+
+		if id != input.ID {
+			lFunc.Debugf("device ID '%s' normalized to '%s'", input.ID, id)
+		}
+		input.ID = id
+	}
+
 	lFunc.Debugf("creating %s device", input.ID)
 	now := time.Now()
 
@@ -164,14 +359,52 @@ type ProvisionDeviceSlotInput struct {
 }
 
 type GetDevicesInput struct {
+	// CertificateStatus only iterates the devices whose identity slot certificate is in the given state. All devices
+	// are iterated if empty.
+	CertificateStatus models.DeviceCertificateStatus
+	// NotSeenFor only iterates the devices whose connection has not been reported within the given duration,
+	// including the devices never seen. All devices are iterated if zero.
+	NotSeenFor time.Duration
 	resources.ListInput[models.Device]
 }
 
+// GetDevices iterates the devices. Confidential slot payloads are always redacted.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The certificate status is not valid.
 func (svc DeviceManagerServiceBackend) GetDevices(ctx context.Context, input GetDevicesInput) (string, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
+	if input.NotSeenFor < 0 {
+		lFunc.Errorf("negative not seen duration %s", input.NotSeenFor)
+		return "", errs.ErrValidateBadRequest
+	} else if input.NotSeenFor > 0 {
+		lFunc.Debugf("getting devices not seen for %s", input.NotSeenFor)
+		input.QueryParameters = notSeenQueryParameters(input.QueryParameters, time.Now().Add(-input.NotSeenFor))
+	}
+
+	if input.CertificateStatus != "" {
+		slotStatus, ok := models.DeviceCertificateStatusSlots[input.CertificateStatus]
+		if !ok {
+			lFunc.Errorf("unknown certificate status %s", input.CertificateStatus)
+			return "", errs.ErrValidateBadRequest
+		}
+
+		lFunc.Debugf("getting all devices with identity slot in %s status", slotStatus)
+		return svc.devicesStorage.SelectByIdentitySlotStatus(ctx, slotStatus, input.ExhaustiveRun, redactDeviceSecretsApplyFunc(input.ApplyFunc), input.QueryParameters, nil)
+	}
+
 	lFunc.Debugf("getting all devices")
-	return svc.devicesStorage.SelectAll(ctx, input.ExhaustiveRun, input.ApplyFunc, input.QueryParameters, nil)
+	return svc.devicesStorage.SelectAll(ctx, input.ExhaustiveRun, redactDeviceSecretsApplyFunc(input.ApplyFunc), input.QueryParameters, nil)
+}
+
+// notSeenQueryParameters returns a copy of the query parameters filtering out the devices seen after the given date.
+func notSeenQueryParameters(queryParams *resources.QueryParameters, seenBefore time.Time) *resources.QueryParameters {
+	return withQueryFilter(queryParams, resources.FilterOption{
+		Field:           "connection_metadata.last_seen",
+		FilterOperation: resources.DateBefore,
+		Value:           seenBefore.UTC().Format(time.RFC3339),
+	})
 }
 
 type GetDevicesByDMSInput struct {
@@ -179,17 +412,93 @@ type GetDevicesByDMSInput struct {
 	resources.ListInput[models.Device]
 }
 
+// GetDeviceByDMS iterates the devices owned by the DMS. Confidential slot payloads are always redacted.
 func (svc DeviceManagerServiceBackend) GetDeviceByDMS(ctx context.Context, input GetDevicesByDMSInput) (string, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
 	lFunc.Debugf("getting all devices owned by DMS with ID=%s", input.DMSID)
-	return svc.devicesStorage.SelectByDMS(ctx, input.DMSID, input.ExhaustiveRun, input.ApplyFunc, input.QueryParameters, nil)
+	return svc.devicesStorage.SelectByDMS(ctx, input.DMSID, input.ExhaustiveRun, redactDeviceSecretsApplyFunc(input.ApplyFunc), input.QueryParameters, nil)
+}
+
+type GetDevicesOutOfCloudSyncInput struct {
+	ConnectorID string `validate:"required"`
+	resources.ListInput[models.DeviceCloudSyncStatus]
+}
+
+// GetDevicesOutOfCloudSync iterates the devices bound to the cloud connector whose active identity certificate is
+// not reflected in the cloud provider. Devices are bound to a connector by its metadata key, devices without the key
+// are ignored. As devices in sync are skipped, a page may hold fewer entries than the requested page size.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) GetDevicesOutOfCloudSync(ctx context.Context, input GetDevicesOutOfCloudSyncInput) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return "", errs.ErrValidateBadRequest
+	}
+
+	metaKey := models.CloudConnectorMetadataKey(input.ConnectorID)
+
+	lFunc.Debugf("getting all devices out of sync with connector %s", input.ConnectorID)
+	return svc.devicesStorage.SelectAll(ctx, input.ExhaustiveRun, func(device models.Device) {
+		if device.IdentitySlot == nil {
+			return
+		}
+
+		var deviceMeta models.DeviceAWSMetadata
+		hasKey, err := helpers.GetMetadataToStruct(device.Metadata, metaKey, &deviceMeta)
+		if err != nil {
+			lFunc.Warnf("could not decode device '%s' metadata key %s: %s", device.ID, metaKey, err)
+			return
+		} else if !hasKey {
+			return
+		}
+
+		status := models.DeviceCloudSyncStatus{
+			DeviceID:     device.ID,
+			DMSID:        device.DMSOwner,
+			ConnectorID:  input.ConnectorID,
+			SerialNumber: device.IdentitySlot.Secrets[device.IdentitySlot.ActiveVersion],
+		}
+
+		if !deviceMeta.Registered {
+			status.Reason = models.DeviceCloudSyncNotRegistered
+			input.ApplyFunc(status)
+			return
+		}
+
+		crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+			SerialNumber: status.SerialNumber,
+		})
+		if err != nil {
+			lFunc.Warnf("could not get certificate %s of device '%s': %s", status.SerialNumber, device.ID, err)
+			return
+		}
+
+		if _, ok := crt.Metadata[metaKey]; !ok {
+			status.Reason = models.DeviceCloudSyncCertificateNotSynced
+			input.ApplyFunc(status)
+		}
+	}, input.QueryParameters, nil)
 }
 
 type GetDeviceByIDInput struct {
 	ID string `validate:"required"`
+	// IncludeSecrets returns the confidential payloads of the device slots. Each access is logged and audited.
+	IncludeSecrets bool
 }
 
+// GetDeviceByID returns the device. The confidential payloads of the extra slots (tokens, SSH keys...) are redacted
+// unless IncludeSecrets is set. The identity slot and the x509 slots only hold certificate serial numbers, so they are
+// never redacted.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrDeviceNotFound
+//     The device does not exist.
 func (svc DeviceManagerServiceBackend) GetDeviceByID(ctx context.Context, input GetDeviceByIDInput) (*models.Device, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
@@ -208,7 +517,157 @@ func (svc DeviceManagerServiceBackend) GetDeviceByID(ctx context.Context, input
 		return nil, errs.ErrDeviceNotFound
 	}
 
-	return device, nil
+	if input.IncludeSecrets {
+		lFunc.Infof("confidential slot payloads of device '%s' retrieved by '%s'", input.ID, callerID(ctx))
+		return device, nil
+	}
+
+	return redactDeviceSecrets(device), nil
+}
+
+// redactDeviceSecrets returns a copy of the device without the confidential payloads of the extra slots. The secret
+// versions are kept so the slot history can still be displayed. The PSK and SAS token versions keep their creation
+// and expiration dates.
+func redactDeviceSecrets(device *models.Device) *models.Device {
+	redacted := *device
+	redacted.ExtraSlots = make(map[string]*models.Slot[any], len(device.ExtraSlots))
+	for slotID, slot := range device.ExtraSlots {
+		if slot == nil || slot.SecretType == models.X509SlotProfileType {
+			redacted.ExtraSlots[slotID] = slot
+			continue
+		}
+
+		redactedSlot := *slot
+		redactedSlot.Secrets = make(map[int]any, len(slot.Secrets))
+		for version, secret := range slot.Secrets {
+			redactedSlot.Secrets[version] = nil
+			if models.IsSymmetricSlotType(slot.SecretType) {
+				if s, err := helpers.DecodeSymmetricSecret(secret); err == nil {
+					redactedSlot.Secrets[version] = models.SymmetricSecret{CreatedAt: s.CreatedAt, ExpiresAt: s.ExpiresAt}
+				}
+			}
+		}
+		redactedSlot.Redacted = true
+		redacted.ExtraSlots[slotID] = &redactedSlot
+	}
+
+	return &redacted
+}
+
+// DeviceETag returns the ETag of the device. It is computed over the redacted device, so it does not depend on
+// whether the confidential slot payloads were requested.
+func DeviceETag(device *models.Device) string {
+	return helpers.ETag(redactDeviceSecrets(device))
+}
+
+func redactDeviceSecretsApplyFunc(applyFunc func(models.Device)) func(models.Device) {
+	if applyFunc == nil {
+		return nil
+	}
+
+	return func(device models.Device) {
+		applyFunc(*redactDeviceSecrets(&device))
+	}
+}
+
+type GetDeviceByCertificateInput struct {
+	SerialNumber string `validate:"required"`
+}
+
+// GetDeviceByCertificate resolves the device (and slot version) owning the certificate. The device is looked up
+// by the device the certificate is attached to and, for superseded certificates, by the certificate CommonName.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCertificateNotFound
+//     The certificate does not exist.
+//   - ErrDeviceCertificateNotBound
+//     The certificate is not stored in the slots of any device.
+func (svc DeviceManagerServiceBackend) GetDeviceByCertificate(ctx context.Context, input GetDeviceByCertificateInput) (*models.CertificateDeviceBinding, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+		SerialNumber: input.SerialNumber,
+	})
+	if err != nil {
+		lFunc.Errorf("could not get certificate %s: %s", input.SerialNumber, err)
+		return nil, err
+	}
+
+	candidates := []string{}
+	var attachedTo models.CAAttachedToDevice
+	hasKey, err := helpers.GetMetadataToStruct(crt.Metadata, models.CAAttachedToDeviceKey, &attachedTo)
+	if err != nil {
+		lFunc.Warnf("could not decode certificate %s metadata key %s: %s", input.SerialNumber, models.CAAttachedToDeviceKey, err)
+	} else if hasKey && attachedTo.DeviceID != "" {
+		candidates = append(candidates, attachedTo.DeviceID)
+	}
+
+	if cn := crt.Subject.CommonName; cn != "" && (len(candidates) == 0 || candidates[0] != cn) {
+		candidates = append(candidates, cn)
+	}
+
+	for _, deviceID := range candidates {
+		exists, device, err := svc.devicesStorage.SelectExists(ctx, deviceID)
+		if err != nil {
+			lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", deviceID, err)
+			return nil, err
+		} else if !exists {
+			lFunc.Debugf("candidate device '%s' for certificate %s does not exist", deviceID, input.SerialNumber)
+			continue
+		}
+
+		if binding := findCertificateInDeviceSlots(device, input.SerialNumber); binding != nil {
+			binding.Device = redactDeviceSecrets(binding.Device)
+			return binding, nil
+		}
+
+		lFunc.Debugf("certificate %s is not stored in any slot of device '%s'", input.SerialNumber, deviceID)
+	}
+
+	return nil, errs.ErrDeviceCertificateNotBound
+}
+
+func findCertificateInDeviceSlots(device *models.Device, serialNumber string) *models.CertificateDeviceBinding {
+	if device.IdentitySlot != nil {
+		for version, sn := range device.IdentitySlot.Secrets {
+			if sn == serialNumber {
+				return &models.CertificateDeviceBinding{
+					SerialNumber: serialNumber,
+					Device:       device,
+					SlotID:       models.DeviceIdentitySlotID,
+					Version:      version,
+					Active:       version == device.IdentitySlot.ActiveVersion,
+				}
+			}
+		}
+	}
+
+	for slotID, slot := range device.ExtraSlots {
+		if slot == nil {
+			continue
+		}
+
+		for version, secret := range slot.Secrets {
+			if sn, ok := secret.(string); ok && sn == serialNumber {
+				return &models.CertificateDeviceBinding{
+					SerialNumber: serialNumber,
+					Device:       device,
+					SlotID:       slotID,
+					Version:      version,
+					Active:       version == slot.ActiveVersion,
+				}
+			}
+		}
+	}
+
+	return nil
 }
 
 type UpdateDeviceStatusInput struct {
@@ -236,10 +695,10 @@ func (svc DeviceManagerServiceBackend) UpdateDeviceStatus(ctx context.Context, i
 
 	if device.Status == input.NewStatus {
 		lFunc.Warnf("skipping update. Device already in %s status", input.NewStatus)
-		return device, nil
+		return redactDeviceSecrets(device), nil
 	} else if device.Status == models.DeviceDecommissioned {
 		lFunc.Warnf("skipping update. Device decommissioned")
-		return device, nil
+		return redactDeviceSecrets(device), nil
 	}
 
 	if input.NewStatus == models.DeviceDecommissioned {
@@ -281,12 +740,96 @@ func (svc DeviceManagerServiceBackend) UpdateDeviceStatus(ctx context.Context, i
 	}
 
 	lFunc.Debugf("device %s status updated. new status: %s", input.ID, input.NewStatus)
-	return device, nil
+	return redactDeviceSecrets(device), nil
+}
+
+type ForceDeviceReenrollInput struct {
+	ID     string `validate:"required"`
+	Reason string
+}
+
+// ForceDeviceReenroll flags the identity slot of the device to be reenrolled. The reenrollment window of the DMS
+// is opened right away, so the device can reenroll on its next attempt without waiting for the certificate to
+// approach its expiration.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the device is decommissioned.
+//   - ErrDeviceNotFound
+//     The device does not exist.
+//   - ErrDeviceNoIdentity
+//     The device has no identity slot to reenroll.
+func (svc DeviceManagerServiceBackend) ForceDeviceReenroll(ctx context.Context, input ForceDeviceReenrollInput) (*models.Device, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if device '%s' exists", input.ID)
+	exists, device, err := svc.devicesStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.ID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.ID)
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	if device.Status == models.DeviceDecommissioned {
+		lFunc.Errorf("device '%s' is decommissioned and can not be reenrolled", input.ID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if device.IdentitySlot == nil {
+		lFunc.Errorf("device '%s' has no identity slot", input.ID)
+		return nil, errs.ErrDeviceNoIdentity
+	}
+
+	description := fmt.Sprintf("Reenrollment forced by '%s'", callerID(ctx))
+	if input.Reason != "" {
+		description = fmt.Sprintf("%s: %s", description, input.Reason)
+	}
+
+	now := time.Now()
+	if device.Metadata == nil {
+		device.Metadata = map[string]any{}
+	}
+	if device.Events == nil {
+		device.Events = map[time.Time]models.DeviceEvent{}
+	}
+	if device.IdentitySlot.Events == nil {
+		device.IdentitySlot.Events = map[time.Time]models.DeviceEvent{}
+	}
+
+	device.Metadata[models.DeviceMetadataForceReenrollKey] = true
+	device.IdentitySlot.Status = models.SlotRenewalWindow
+	device.IdentitySlot.Events[now] = models.DeviceEvent{
+		EvenType:          models.DeviceEventTypeForceReenroll,
+		EventDescriptions: description,
+	}
+	device.Status = models.DeviceRenewalWindow
+	device.Events[now] = models.DeviceEvent{
+		EvenType:          models.DeviceEventTypeForceReenroll,
+		EventDescriptions: description,
+	}
+
+	lFunc.Infof("forcing reenrollment of device '%s'", input.ID)
+	device, err = svc.devicesStorage.Update(ctx, device)
+	if err != nil {
+		lFunc.Errorf("could not update device '%s' in storage engine: %s", input.ID, err)
+		return nil, err
+	}
+
+	return redactDeviceSecrets(device), nil
 }
 
 type UpdateDeviceMetadataInput struct {
 	ID       string         `validate:"required"`
 	Metadata map[string]any `validate:"required"`
+	// IfMatch is the If-Match precondition of the update, evaluated against the DeviceETag of the stored device.
+	IfMatch string
 }
 
 func (svc DeviceManagerServiceBackend) UpdateDeviceMetadata(ctx context.Context, input UpdateDeviceMetadataInput) (*models.Device, error) {
@@ -307,19 +850,126 @@ func (svc DeviceManagerServiceBackend) UpdateDeviceMetadata(ctx context.Context,
 
 	if !exists {
 		lFunc.Errorf("device %s can not be found in storage engine", input.ID)
-		return nil, err
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	if !helpers.ETagMatches(input.IfMatch, DeviceETag(device)) {
+		lFunc.Errorf("device %s has been modified. If-Match precondition failed", input.ID)
+		return nil, errs.ErrPreconditionFailed
 	}
 
 	device.Metadata = input.Metadata
 
 	lFunc.Debugf("updating %s device metadata", input.ID)
-	return svc.devicesStorage.Update(ctx, device)
+	device, err = updateIfMatch(ctx, svc.devicesStorage.UpdateIf, device, input.IfMatch, DeviceETag)
+	if err != nil {
+		return nil, err
+	}
+
+	return redactDeviceSecrets(device), nil
+}
+
+type UpdateDeviceConnectionMetadataInput struct {
+	ID                 string `validate:"required"`
+	ConnectionMetadata models.DeviceConnectionMetadata
+}
+
+// UpdateDeviceConnectionMetadata stores the connection state reported by a cloud connector.
+// Reports older than the stored one are ignored, so out of order deliveries don't override newer states.
+// Returned Error Codes:
+//   - ErrDeviceNotFound
+//     The specified Device can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) UpdateDeviceConnectionMetadata(ctx context.Context, input UpdateDeviceConnectionMetadataInput) (*models.Device, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("UpdateDeviceConnectionMetadata struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	switch input.ConnectionMetadata.Status {
+	case models.DeviceConnectionOnline, models.DeviceConnectionOffline:
+	default:
+		lFunc.Errorf("unknown connection status '%s'", input.ConnectionMetadata.Status)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if device '%s' exists", input.ID)
+	exists, device, err := svc.devicesStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.ID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.ID)
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	if input.ConnectionMetadata.LastSeen.IsZero() {
+		input.ConnectionMetadata.LastSeen = time.Now()
+	}
+
+	if input.ConnectionMetadata.LastSeen.Before(device.ConnectionMetadata.LastSeen) {
+		lFunc.Warnf("skipping connection report for device %s: report is older than the stored one", input.ID)
+		return redactDeviceSecrets(device), nil
+	}
+
+	device.ConnectionMetadata = input.ConnectionMetadata
+
+	lFunc.Debugf("updating %s device connection metadata", input.ID)
+	device, err = svc.devicesStorage.Update(ctx, device)
+	if err != nil {
+		return nil, err
+	}
 
+	return redactDeviceSecrets(device), nil
+}
+
+type ReportDeviceHeartbeatInput struct {
+	ID        string `validate:"required"`
+	Source    string `validate:"required"`
+	IPAddress string
+	Broker    string
+}
+
+// ReportDeviceHeartbeat records the device as online and seen now through the given source. Heartbeats are stored
+// as any other connection report, so the last seen timestamp of the device can drive the fleet hygiene listings.
+// Returned Error Codes:
+//   - ErrDeviceNotFound
+//     The specified Device can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DeviceManagerServiceBackend) ReportDeviceHeartbeat(ctx context.Context, input ReportDeviceHeartbeatInput) (*models.Device, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("ReportDeviceHeartbeat struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("heartbeat of device %s reported by %s", input.ID, input.Source)
+	return svc.service.UpdateDeviceConnectionMetadata(ctx, UpdateDeviceConnectionMetadataInput{
+		ID: input.ID,
+		ConnectionMetadata: models.DeviceConnectionMetadata{
+			Status:    models.DeviceConnectionOnline,
+			LastSeen:  time.Now(),
+			IPAddress: input.IPAddress,
+			Broker:    input.Broker,
+			Source:    input.Source,
+		},
+	})
 }
 
 type UpdateDeviceIdentitySlotInput struct {
 	ID   string              `validate:"required"`
 	Slot models.Slot[string] `validate:"required"`
+	// IfMatch is the If-Match precondition of the update, evaluated against the DeviceETag of the stored device.
+	IfMatch string
 }
 
 func (svc DeviceManagerServiceBackend) UpdateDeviceIdentitySlot(ctx context.Context, input UpdateDeviceIdentitySlotInput) (*models.Device, error) {
@@ -340,9 +990,14 @@ func (svc DeviceManagerServiceBackend) UpdateDeviceIdentitySlot(ctx context.Cont
 		return nil, errs.ErrDeviceNotFound
 	}
 
+	if !helpers.ETagMatches(input.IfMatch, DeviceETag(device)) {
+		lFunc.Errorf("device %s has been modified. If-Match precondition failed", input.ID)
+		return nil, errs.ErrPreconditionFailed
+	}
+
 	if device.Status == models.DeviceDecommissioned {
 		lFunc.Warnf("device %s is decommissioned", input.ID)
-		return device, nil
+		return redactDeviceSecrets(device), nil
 	}
 
 	newSlot := input.Slot
@@ -390,7 +1045,7 @@ func (svc DeviceManagerServiceBackend) UpdateDeviceIdentitySlot(ctx context.Cont
 	device.IdentitySlot = &newSlot
 
 	lFunc.Debugf("updating %s device identity slot. New device status %s. ID slot status %s", input.ID, device.Status, device.IdentitySlot.Status)
-	device, err = svc.devicesStorage.Update(ctx, device)
+	device, err = updateIfMatch(ctx, svc.devicesStorage.UpdateIf, device, input.IfMatch, DeviceETag)
 	if err != nil {
 		return nil, err
 	}
@@ -406,5 +1061,261 @@ func (svc DeviceManagerServiceBackend) UpdateDeviceIdentitySlot(ctx context.Cont
 		}
 	}
 
-	return device, nil
+	return redactDeviceSecrets(device), nil
+}
+
+type ProvisionDeviceSecretSlotInput struct {
+	DeviceID   string                  `validate:"required"`
+	SlotID     string                  `validate:"required"`
+	SecretType models.CryptoSecretType `validate:"required"`
+	Profile    models.SymmetricSlotProfile
+}
+
+// ProvisionDeviceSecretSlot generates the secret of a PSK or SAS token slot. The slot is created if it does not
+// exist, otherwise its profile is replaced and a new secret version is activated.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, the slot type is not a PSK or SAS token type
+//     or the profile is not valid for the slot type.
+//   - ErrDeviceNotFound
+//     The device does not exist.
+func (svc DeviceManagerServiceBackend) ProvisionDeviceSecretSlot(ctx context.Context, input ProvisionDeviceSecretSlotInput) (*models.Device, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if input.SlotID == models.DeviceIdentitySlotID {
+		lFunc.Errorf("slot '%s' is reserved for the device identity", input.SlotID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSymmetricSlotProfile(input.SecretType, input.Profile)
+	if err != nil {
+		lFunc.Errorf("invalid %s slot profile: %s", input.SecretType, err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if device '%s' exists", input.DeviceID)
+	exists, device, err := svc.devicesStorage.SelectExists(ctx, input.DeviceID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.DeviceID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.DeviceID)
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	if device.ExtraSlots == nil {
+		device.ExtraSlots = map[string]*models.Slot[any]{}
+	}
+
+	slot, exists := device.ExtraSlots[input.SlotID]
+	if exists && slot != nil && slot.SecretType != input.SecretType {
+		lFunc.Errorf("slot '%s' of device '%s' is a %s slot", input.SlotID, input.DeviceID, slot.SecretType)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if !exists || slot == nil {
+		slot = &models.Slot[any]{
+			Status:     models.SlotActive,
+			SecretType: input.SecretType,
+			Secrets:    map[int]any{},
+			Events:     map[time.Time]models.DeviceEvent{},
+		}
+		device.ExtraSlots[input.SlotID] = slot
+	}
+
+	profile := input.Profile
+	slot.Profile = &profile
+
+	lFunc.Infof("provisioning %s slot '%s' of device '%s'", input.SecretType, input.SlotID, input.DeviceID)
+	return svc.rotateSecretSlot(ctx, device, input.SlotID, models.DeviceEventTypeProvisioned)
+}
+
+type RotateDeviceSecretSlotInput struct {
+	DeviceID string `validate:"required"`
+	SlotID   string `validate:"required"`
+}
+
+// RotateDeviceSecretSlot generates and activates a new secret version of a PSK or SAS token slot.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the slot is not a PSK or SAS token slot.
+//   - ErrDeviceNotFound
+//     The device does not exist.
+//   - ErrDeviceSlotNotFound
+//     The device has no slot with the given ID.
+func (svc DeviceManagerServiceBackend) RotateDeviceSecretSlot(ctx context.Context, input RotateDeviceSecretSlotInput) (*models.Device, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if device '%s' exists", input.DeviceID)
+	exists, device, err := svc.devicesStorage.SelectExists(ctx, input.DeviceID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.DeviceID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.DeviceID)
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	slot, ok := device.ExtraSlots[input.SlotID]
+	if !ok || slot == nil {
+		lFunc.Errorf("device '%s' has no slot '%s'", input.DeviceID, input.SlotID)
+		return nil, errs.ErrDeviceSlotNotFound
+	}
+
+	if !models.IsSymmetricSlotType(slot.SecretType) || slot.Profile == nil {
+		lFunc.Errorf("slot '%s' of device '%s' is a %s slot and can not be rotated", input.SlotID, input.DeviceID, slot.SecretType)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Infof("rotating %s slot '%s' of device '%s'", slot.SecretType, input.SlotID, input.DeviceID)
+	return svc.rotateSecretSlot(ctx, device, input.SlotID, models.DeviceEventTypeRenewed)
+}
+
+// rotateSecretSlot generates a new secret version of the symmetric slot, activates it and stores the device.
+// The returned device is redacted.
+func (svc DeviceManagerServiceBackend) rotateSecretSlot(ctx context.Context, device *models.Device, slotID string, eventType models.DeviceEventType) (*models.Device, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	now := time.Now()
+	slot := device.ExtraSlots[slotID]
+	secret, err := helpers.GenerateSymmetricSlotSecret(slot.SecretType, *slot.Profile, now)
+	if err != nil {
+		lFunc.Errorf("could not generate %s secret for slot '%s' of device '%s': %s", slot.SecretType, slotID, device.ID, err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	version := 0
+	if len(slot.Secrets) > 0 {
+		version = slot.ActiveVersion + 1
+	}
+
+	if slot.Secrets == nil {
+		slot.Secrets = map[int]any{}
+	}
+	if slot.Events == nil {
+		slot.Events = map[time.Time]models.DeviceEvent{}
+	}
+
+	slot.Secrets[version] = *secret
+	slot.ActiveVersion = version
+	slot.Status = models.SlotActive
+	slot.Events[now] = models.DeviceEvent{
+		EvenType:          eventType,
+		EventDescriptions: fmt.Sprintf("New %s secret version %d", slot.SecretType, version),
+	}
+
+	device, err = svc.devicesStorage.Update(ctx, device)
+	if err != nil {
+		lFunc.Errorf("could not update device '%s' in storage engine: %s", device.ID, err)
+		return nil, err
+	}
+
+	return redactDeviceSecrets(device), nil
+}
+
+type GetDeviceSecretSlotInput struct {
+	DeviceID string `validate:"required"`
+	SlotID   string `validate:"required"`
+}
+
+// GetDeviceSecretSlot returns the active secret of a PSK or SAS token slot, encrypted to the public key of the
+// active identity certificate of the device. Only the device holding the identity key can read the secret.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the slot is not a PSK or SAS token slot.
+//   - ErrDeviceNotFound
+//     The device does not exist.
+//   - ErrDeviceSlotNotFound
+//     The device has no slot with the given ID or the slot has no secret.
+//   - ErrDeviceNoIdentity
+//     The device has no identity certificate to encrypt the secret to.
+func (svc DeviceManagerServiceBackend) GetDeviceSecretSlot(ctx context.Context, input GetDeviceSecretSlotInput) (*models.EncryptedSlotSecret, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := deviceValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Debugf("checking if device '%s' exists", input.DeviceID)
+	exists, device, err := svc.devicesStorage.SelectExists(ctx, input.DeviceID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if device '%s' exists in storage engine: %s", input.DeviceID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("device %s can not be found in storage engine", input.DeviceID)
+		return nil, errs.ErrDeviceNotFound
+	}
+
+	slot, ok := device.ExtraSlots[input.SlotID]
+	if !ok || slot == nil {
+		lFunc.Errorf("device '%s' has no slot '%s'", input.DeviceID, input.SlotID)
+		return nil, errs.ErrDeviceSlotNotFound
+	}
+
+	if !models.IsSymmetricSlotType(slot.SecretType) {
+		lFunc.Errorf("slot '%s' of device '%s' is a %s slot", input.SlotID, input.DeviceID, slot.SecretType)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	active, ok := slot.Secrets[slot.ActiveVersion]
+	if !ok {
+		lFunc.Errorf("slot '%s' of device '%s' has no active secret", input.SlotID, input.DeviceID)
+		return nil, errs.ErrDeviceSlotNotFound
+	}
+
+	secret, err := helpers.DecodeSymmetricSecret(active)
+	if err != nil {
+		lFunc.Errorf("could not decode secret of slot '%s' of device '%s': %s", input.SlotID, input.DeviceID, err)
+		return nil, err
+	}
+
+	if device.IdentitySlot == nil {
+		lFunc.Errorf("device '%s' has no identity slot", input.DeviceID)
+		return nil, errs.ErrDeviceNoIdentity
+	}
+
+	sn := device.IdentitySlot.Secrets[device.IdentitySlot.ActiveVersion]
+	crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+		SerialNumber: sn,
+	})
+	if err != nil {
+		lFunc.Errorf("could not get identity certificate %s of device '%s': %s", sn, input.DeviceID, err)
+		return nil, err
+	}
+
+	payload, err := json.Marshal(secret)
+	if err != nil {
+		return nil, err
+	}
+
+	jwe, err := helpers.EncryptToPublicKey(payload, (*x509.Certificate)(crt.Certificate).PublicKey)
+	if err != nil {
+		lFunc.Errorf("could not encrypt secret of slot '%s' to the identity of device '%s': %s", input.SlotID, input.DeviceID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("secret of slot '%s' of device '%s' retrieved by '%s'", input.SlotID, input.DeviceID, callerID(ctx))
+	return &models.EncryptedSlotSecret{
+		DeviceID:     input.DeviceID,
+		SlotID:       input.SlotID,
+		SecretType:   slot.SecretType,
+		Version:      slot.ActiveVersion,
+		ExpiresAt:    secret.ExpiresAt,
+		SerialNumber: sn,
+		JWE:          jwe,
+	}, nil
 }
@@ -0,0 +1,215 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	identityextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/identity-extractors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const defaultBootstrapTokenTTL = 24 * time.Hour
+
+type CreateBootstrapTokenInput struct {
+	DMSID string `validate:"required"`
+	// DeviceID restricts the token to the enrollment of a single device (if set).
+	DeviceID string
+	// TTL defaults to 24 hours if empty.
+	TTL models.TimeDuration `validate:"gte=0"`
+}
+
+// CreateBootstrapToken mints a single-use token enrolling a device with the DMS. The token is only returned once.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) CreateBootstrapToken(ctx context.Context, input CreateBootstrapTokenInput) (*models.DMSBootstrapTokenCredentials, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	deviceID := input.DeviceID
+	if deviceID != "" {
+		deviceID, err = helpers.NormalizeDeviceID(deviceID, dms.Settings.EnrollmentSettings.DeviceIDRules)
+		if err != nil {
+			lFunc.Errorf("invalid device ID '%s': %s", input.DeviceID, err)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
+	ttl := time.Duration(input.TTL)
+	if ttl == 0 {
+		ttl = defaultBootstrapTokenTTL
+	}
+
+	secret, id, err := helpers.GenerateBootstrapToken()
+	if err != nil {
+		lFunc.Errorf("could not generate bootstrap token: %s", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	token, err := svc.tokenStorage.Insert(ctx, &models.DMSBootstrapToken{
+		ID:        id,
+		DMSID:     dms.ID,
+		DeviceID:  deviceID,
+		CreatedBy: callerID(ctx),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		lFunc.Errorf("could not store bootstrap token for DMS %s: %s", dms.ID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("bootstrap token %s issued for DMS %s. expires at %s", token.ID, dms.ID, token.ExpiresAt)
+	return &models.DMSBootstrapTokenCredentials{
+		DMSBootstrapToken: *token,
+		Token:             secret,
+	}, nil
+}
+
+type GetBootstrapTokensInput struct {
+	DMSID string `validate:"required"`
+}
+
+// GetBootstrapTokens returns the bootstrap tokens issued by the DMS, including the used and expired ones.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) GetBootstrapTokens(ctx context.Context, input GetBootstrapTokensInput) ([]models.DMSBootstrapToken, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, err = svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	tokens := []models.DMSBootstrapToken{}
+	_, err = svc.tokenStorage.SelectAll(ctx, storage.StorageListRequest[models.DMSBootstrapToken]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(token models.DMSBootstrapToken) {
+			if token.DMSID == input.DMSID {
+				tokens = append(tokens, token)
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list bootstrap tokens of DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	return tokens, nil
+}
+
+type RevokeBootstrapTokenInput struct {
+	DMSID   string `validate:"required"`
+	TokenID string `validate:"required"`
+}
+
+// RevokeBootstrapToken deletes the bootstrap token, so it can no longer enroll devices.
+//
+// Returned Error Codes:
+//   - ErrDMSBootstrapTokenNotFound
+//     The DMS has not issued a bootstrap token with the specified ID.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) RevokeBootstrapToken(ctx context.Context, input RevokeBootstrapTokenInput) (*models.DMSBootstrapToken, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, token, err := svc.tokenStorage.SelectExists(ctx, input.TokenID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if bootstrap token '%s' exists in storage engine: %s", input.TokenID, err)
+		return nil, err
+	} else if !exists || token.DMSID != input.DMSID {
+		lFunc.Errorf("DMS '%s' has no bootstrap token '%s'", input.DMSID, input.TokenID)
+		return nil, errs.ErrDMSBootstrapTokenNotFound
+	}
+
+	lFunc.Infof("revoking bootstrap token %s of DMS %s", token.ID, token.DMSID)
+	err = svc.tokenStorage.Delete(ctx, token.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return token, nil
+}
+
+// consumeBootstrapToken validates the bearer token presented while enrolling the device and marks it as used. The
+// token is consumed as soon as it is validated, so a device failing the rest of the enrollment needs a new token.
+func (svc DMSManagerServiceBackend) consumeBootstrapToken(ctx context.Context, dms *models.DMS, deviceID string) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	secret, hasValue := ctx.Value(string(identityextractors.IdentityExtractorBootstrapToken)).(string)
+	if !hasValue {
+		lFunc.Errorf("aborting enrollment process for device '%s'. DMS '%s' is configured with '%s'. No bearer token was presented", deviceID, dms.ID, identityextractors.IdentityExtractorBootstrapToken)
+		return errs.ErrDMSAuthModeNotSupported
+	}
+
+	if svc.tokenStorage == nil {
+		lFunc.Errorf("aborting enrollment process for device '%s'. bootstrap token storage is not configured", deviceID)
+		return errs.ErrDMSEnrollInvalidBootstrapToken
+	}
+
+	tokenID := helpers.BootstrapTokenID(secret)
+	exists, token, err := svc.tokenStorage.SelectExists(ctx, tokenID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if bootstrap token '%s' exists in storage engine: %s", tokenID, err)
+		return err
+	} else if !exists {
+		lFunc.Errorf("aborting enrollment process for device '%s'. unknown bootstrap token", deviceID)
+		return errs.ErrDMSEnrollInvalidBootstrapToken
+	}
+
+	now := time.Now()
+	err = helpers.CheckBootstrapToken(*token, dms.ID, deviceID, now)
+	if err != nil {
+		lFunc.Errorf("aborting enrollment process for device '%s'. invalid bootstrap token %s: %s", deviceID, token.ID, err)
+		return errs.ErrDMSEnrollInvalidBootstrapToken
+	}
+
+	// the token is only consumed if it is still unused when written, so concurrent enrollments presenting the same
+	// token can not both pass the check above
+	consumed, err := svc.tokenStorage.MarkUsed(ctx, token.ID, deviceID, now)
+	if err != nil {
+		lFunc.Errorf("could not mark bootstrap token %s as used: %s", token.ID, err)
+		return err
+	} else if !consumed {
+		lFunc.Errorf("aborting enrollment process for device '%s'. bootstrap token %s was already used", deviceID, token.ID)
+		return errs.ErrDMSEnrollInvalidBootstrapToken
+	}
+
+	lFunc.Infof("bootstrap token %s of DMS %s used by device '%s'", token.ID, dms.ID, deviceID)
+	return nil
+}
@@ -0,0 +1,300 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const defaultClaimCodeTTL = 7 * 24 * time.Hour
+
+// claimedEnrollmentCtxKey holds the ID of the claim code authorizing the enrollment. The enrollment skips the EST
+// auth mode of the DMS, as the claim code has already been validated.
+type claimedEnrollmentCtxKey struct{}
+
+type CreateClaimCodeInput struct {
+	DMSID string `validate:"required"`
+	// DeviceID restricts the code to the enrollment of a single device (if set).
+	DeviceID string
+	// TTL defaults to 7 days if empty.
+	TTL models.TimeDuration `validate:"gte=0"`
+}
+
+// CreateClaimCode mints a one-time code enrolling a device with the DMS through the public claim endpoint. The code
+// is only returned once.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) CreateClaimCode(ctx context.Context, input CreateClaimCodeInput) (*models.DMSClaimCodeCredentials, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	deviceID := input.DeviceID
+	if deviceID != "" {
+		deviceID, err = helpers.NormalizeDeviceID(deviceID, dms.Settings.EnrollmentSettings.DeviceIDRules)
+		if err != nil {
+			lFunc.Errorf("invalid device ID '%s': %s", input.DeviceID, err)
+			return nil, errs.ErrValidateBadRequest
+		}
+	}
+
+	ttl := time.Duration(input.TTL)
+	if ttl == 0 {
+		ttl = defaultClaimCodeTTL
+	}
+
+	secret, id, err := helpers.GenerateClaimCode()
+	if err != nil {
+		lFunc.Errorf("could not generate claim code: %s", err)
+		return nil, err
+	}
+
+	now := time.Now()
+	code, err := svc.claimCodeStorage.Insert(ctx, &models.DMSClaimCode{
+		ID:        id,
+		DMSID:     dms.ID,
+		DeviceID:  deviceID,
+		CreatedBy: callerID(ctx),
+		CreatedAt: now,
+		ExpiresAt: now.Add(ttl),
+	})
+	if err != nil {
+		lFunc.Errorf("could not store claim code for DMS %s: %s", dms.ID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("claim code %s issued for DMS %s. expires at %s", code.ID, dms.ID, code.ExpiresAt)
+	return &models.DMSClaimCodeCredentials{
+		DMSClaimCode: *code,
+		Code:         secret,
+	}, nil
+}
+
+type GetClaimCodesInput struct {
+	DMSID string `validate:"required"`
+}
+
+// GetClaimCodes returns the claim codes issued by the DMS, including the claimed and expired ones.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) GetClaimCodes(ctx context.Context, input GetClaimCodesInput) ([]models.DMSClaimCode, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	_, err = svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	codes := []models.DMSClaimCode{}
+	_, err = svc.claimCodeStorage.SelectAll(ctx, storage.StorageListRequest[models.DMSClaimCode]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(code models.DMSClaimCode) {
+			if code.DMSID == input.DMSID {
+				codes = append(codes, code)
+			}
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list claim codes of DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	return codes, nil
+}
+
+type RevokeClaimCodeInput struct {
+	DMSID  string `validate:"required"`
+	CodeID string `validate:"required"`
+}
+
+// RevokeClaimCode deletes the claim code, so it can no longer enroll devices.
+//
+// Returned Error Codes:
+//   - ErrDMSClaimCodeNotFound
+//     The DMS has not issued a claim code with the specified ID.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) RevokeClaimCode(ctx context.Context, input RevokeClaimCodeInput) (*models.DMSClaimCode, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, code, err := svc.claimCodeStorage.SelectExists(ctx, input.CodeID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if claim code '%s' exists in storage engine: %s", input.CodeID, err)
+		return nil, err
+	} else if !exists || code.DMSID != input.DMSID {
+		lFunc.Errorf("DMS '%s' has no claim code '%s'", input.DMSID, input.CodeID)
+		return nil, errs.ErrDMSClaimCodeNotFound
+	}
+
+	lFunc.Infof("revoking claim code %s of DMS %s", code.ID, code.DMSID)
+	err = svc.claimCodeStorage.Delete(ctx, code.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	return code, nil
+}
+
+type ClaimDeviceInput struct {
+	Code string                   `validate:"required"`
+	CSR  *x509.CertificateRequest `validate:"required"`
+	// InstallerMetadata is stored in the claim code and in the DeviceMetadataClaimKey metadata of the device.
+	InstallerMetadata map[string]any
+}
+
+// ClaimDevice enrolls the device of the CSR with the DMS that issued the claim code. The claim code replaces the
+// EST authentication of the DMS, while the rest of the enrollment rules of the DMS still apply. The code is consumed
+// before the device is enrolled, so concurrent claims can not enroll two devices with it, and released if the
+// enrollment fails, so a failed claim can be retried with the same code.
+//
+// Returned Error Codes:
+//   - ErrDMSInvalidClaimCode
+//     The code is unknown, expired, already claimed or scoped to another device.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrDeviceInvalidID
+//     The CommonName of the CSR does not satisfy the device ID rules of the DMS.
+func (svc DMSManagerServiceBackend) ClaimDevice(ctx context.Context, input ClaimDeviceInput) (*models.DeviceClaim, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	codeID := helpers.ClaimCodeID(input.Code)
+	exists, code, err := svc.claimCodeStorage.SelectExists(ctx, codeID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if claim code '%s' exists in storage engine: %s", codeID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("aborting claim of device '%s'. unknown claim code", input.CSR.Subject.CommonName)
+		return nil, errs.ErrDMSInvalidClaimCode
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: code.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s of claim code %s: %s", code.DMSID, code.ID, err)
+		return nil, err
+	}
+
+	deviceID, err := helpers.NormalizeDeviceID(input.CSR.Subject.CommonName, dms.Settings.EnrollmentSettings.DeviceIDRules)
+	if err != nil {
+		lFunc.Errorf("aborting claim of device '%s'. Invalid device ID: %s", input.CSR.Subject.CommonName, err)
+		return nil, errs.ErrDeviceInvalidID
+	}
+
+	now := time.Now()
+	err = helpers.CheckClaimCode(*code, deviceID, now)
+	if err != nil {
+		lFunc.Errorf("aborting claim of device '%s'. invalid claim code %s: %s", deviceID, code.ID, err)
+		return nil, errs.ErrDMSInvalidClaimCode
+	}
+
+	unclaimed := *code
+	code.ClaimedAt = &now
+	code.ClaimedBy = deviceID
+	code.InstallerMetadata = input.InstallerMetadata
+	_, err = svc.claimCodeStorage.UpdateIf(ctx, code, func(current *models.DMSClaimCode) bool {
+		return current.ClaimedAt == nil
+	})
+	if err == storage.ErrUpdateConflict {
+		lFunc.Errorf("aborting claim of device '%s'. claim code %s was claimed concurrently", deviceID, code.ID)
+		return nil, errs.ErrDMSInvalidClaimCode
+	} else if err != nil {
+		lFunc.Errorf("could not mark claim code %s as claimed: %s", code.ID, err)
+		return nil, err
+	}
+
+	crt, err := svc.service.Enroll(context.WithValue(ctx, claimedEnrollmentCtxKey{}, code.ID), input.CSR, dms.ID)
+	if err != nil {
+		lFunc.Errorf("could not enroll device '%s' claimed with code %s: %s", deviceID, code.ID, err)
+		svc.releaseClaimCode(ctx, &unclaimed)
+		return nil, err
+	}
+
+	device, err := svc.deviceManagerCli.GetDeviceByID(ctx, GetDeviceByIDInput{ID: deviceID})
+	if err != nil {
+		lFunc.Warnf("could not get device '%s' to store its claim metadata: %s", deviceID, err)
+	} else {
+		if device.Metadata == nil {
+			device.Metadata = map[string]any{}
+		}
+		device.Metadata[models.DeviceMetadataClaimKey] = models.DeviceClaimMetadata{
+			ClaimCodeID: code.ID,
+			ClaimedAt:   now,
+			Installer:   input.InstallerMetadata,
+		}
+		_, err = svc.deviceManagerCli.UpdateDeviceMetadata(ctx, UpdateDeviceMetadataInput{
+			ID:       device.ID,
+			Metadata: device.Metadata,
+		})
+		if err != nil {
+			lFunc.Warnf("could not store claim metadata of device '%s': %s", deviceID, err)
+		}
+	}
+
+	lFunc.Infof("device '%s' claimed with code %s of DMS %s", deviceID, code.ID, dms.ID)
+	return &models.DeviceClaim{
+		DeviceID:    deviceID,
+		DMSID:       dms.ID,
+		Certificate: (*models.X509Certificate)(crt),
+	}, nil
+}
+
+// releaseClaimCode restores the claim code as it was before a failed claim consumed it. The code is written back
+// instead of updated, as the SQL storage engines skip the zero values (the cleared ClaimedAt) on updates.
+func (svc DMSManagerServiceBackend) releaseClaimCode(ctx context.Context, code *models.DMSClaimCode) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := svc.claimCodeStorage.Delete(ctx, code.ID)
+	if err == nil {
+		_, err = svc.claimCodeStorage.Insert(ctx, code)
+	}
+	if err != nil {
+		lFunc.Errorf("could not release claim code %s after a failed claim: %s", code.ID, err)
+	}
+}
+
+// isClaimedEnrollment reports whether the enrollment is authorized by a claim code validated by ClaimDevice.
+func isClaimedEnrollment(ctx context.Context) (string, bool) {
+	codeID, ok := ctx.Value(claimedEnrollmentCtxKey{}).(string)
+	return codeID, ok && codeID != ""
+}
@@ -0,0 +1,101 @@
+package services
+
+import (
+	"context"
+	"time"
+
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	headerextractors "github.com/lamassuiot/lamassuiot/v2/pkg/routes/middlewares/basic-header-extractors"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// evaluateEnrollmentContext applies the enrollment context policy of the DMS to the request and records the
+// evaluation. Audit storage errors are only logged, so they never make the enrollment fail.
+func (svc DMSManagerServiceBackend) evaluateEnrollmentContext(ctx context.Context, dms *models.DMS, deviceID string) error {
+	policy := dms.Settings.EnrollmentSettings.ContextPolicy
+	if !policy.Enabled {
+		return nil
+	}
+
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	enrollCtx := models.EnrollmentContext{Time: time.Now()}
+	enrollCtx.SourceIP, _ = ctx.Value(headerextractors.CtxClientIP).(string)
+	enrollCtx.GatewayID, _ = ctx.Value(headerextractors.CtxGatewayID).(string)
+
+	action, rule := helpers.EvaluateEnrollmentContextPolicy(policy, enrollCtx)
+	ruleName := ""
+	if rule != nil {
+		ruleName = rule.Name
+	}
+
+	if svc.auditStorage != nil {
+		_, err := svc.auditStorage.Insert(ctx, &models.DMSEnrollmentAudit{
+			ID:        goid.NewV4UUID().String(),
+			DMSID:     dms.ID,
+			DeviceID:  deviceID,
+			SourceIP:  enrollCtx.SourceIP,
+			GatewayID: enrollCtx.GatewayID,
+			Rule:      ruleName,
+			Action:    action,
+			Timestamp: enrollCtx.Time,
+		})
+		if err != nil {
+			lFunc.Errorf("could not store enrollment audit record of DMS '%s': %s", dms.ID, err)
+		}
+	}
+
+	switch action {
+	case models.EnrollmentContextDeny:
+		lFunc.Errorf("aborting enrollment process for device '%s'. request from '%s' (gateway '%s') denied by the context policy of DMS '%s' (rule '%s')", deviceID, enrollCtx.SourceIP, enrollCtx.GatewayID, dms.ID, ruleName)
+		return errs.ErrDMSEnrollContextDenied
+	case models.EnrollmentContextFlag:
+		lFunc.Warnf("enrollment of device '%s' from '%s' (gateway '%s') flagged by the context policy of DMS '%s' (rule '%s')", deviceID, enrollCtx.SourceIP, enrollCtx.GatewayID, dms.ID, ruleName)
+	default:
+		lFunc.Debugf("enrollment of device '%s' allowed by the context policy of DMS '%s' (rule '%s')", deviceID, dms.ID, ruleName)
+	}
+
+	return nil
+}
+
+type GetEnrollmentAuditsInput struct {
+	DMSID string `validate:"required"`
+	resources.ListInput[models.DMSEnrollmentAudit]
+}
+
+// GetEnrollmentAudits iterates the evaluations of the enrollment context policy of the DMS.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) GetEnrollmentAudits(ctx context.Context, input GetEnrollmentAuditsInput) (string, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return "", errs.ErrValidateBadRequest
+	}
+
+	_, err = svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return "", err
+	}
+
+	if svc.auditStorage == nil {
+		return "", nil
+	}
+
+	return svc.auditStorage.SelectByDMS(ctx, input.DMSID, storage.StorageListRequest[models.DMSEnrollmentAudit]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   input.QueryParameters,
+	})
+}
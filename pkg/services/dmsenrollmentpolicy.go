@@ -0,0 +1,256 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"slices"
+	"time"
+
+	"github.com/jakehl/goid"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// matchEnrollmentPolicy returns the first enrollment policy, by creation date, matching the DMS registration or nil
+// if none matches.
+func (svc DMSManagerServiceBackend) matchEnrollmentPolicy(ctx context.Context, csr *x509.CertificateRequest, settings models.DMSSettings) (*models.DMSEnrollmentPolicy, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if svc.policyStorage == nil {
+		return nil, nil
+	}
+
+	policies := []models.DMSEnrollmentPolicy{}
+	_, err := svc.policyStorage.SelectAll(ctx, storage.StorageListRequest[models.DMSEnrollmentPolicy]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(policy models.DMSEnrollmentPolicy) {
+			policies = append(policies, policy)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	slices.SortFunc(policies, func(a, b models.DMSEnrollmentPolicy) int {
+		return a.CreationDate.Compare(b.CreationDate)
+	})
+
+	for _, policy := range policies {
+		matches, reason := helpers.MatchDMSEnrollmentPolicy(policy, csr, settings)
+		if matches {
+			return &policy, nil
+		}
+
+		lFunc.Debugf("enrollment policy '%s' does not match: %s", policy.ID, reason)
+	}
+
+	return nil, nil
+}
+
+type UpdateDMSStatusInput struct {
+	ID     string           `validate:"required"`
+	Status models.DMSStatus `validate:"required"`
+}
+
+// UpdateDMSStatus approves (ACTIVE) or holds (PENDING_APPROVAL) the registration of a DMS.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found.
+//   - ErrDMSInvalidStatus
+//     The status is neither ACTIVE nor PENDING_APPROVAL.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) UpdateDMSStatus(ctx context.Context, input UpdateDMSStatusInput) (*models.DMS, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if input.Status != models.ActiveDMSStatus && input.Status != models.PendingApprovalDMSStatus {
+		lFunc.Errorf("DMS status %s can not be set", input.Status)
+		return nil, errs.ErrDMSInvalidStatus
+	}
+
+	exists, dms, err := svc.dmsStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if DMS '%s' exists in storage engine: %s", input.ID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("DMS '%s' does not exist in storage engine", input.ID)
+		return nil, errs.ErrDMSNotFound
+	}
+
+	lFunc.Infof("updating DMS '%s' status from '%s' to '%s'", dms.ID, dms.Status, input.Status)
+	dms.Status = input.Status
+	return svc.dmsStorage.Update(ctx, dms)
+}
+
+type CreateEnrollmentPolicyInput struct {
+	// ID is generated if empty.
+	ID           string
+	Name         string `validate:"required"`
+	Description  string
+	SubjectRegex string
+	KeyTypes     []models.KeyType
+	MinKeyBits   int
+	AllowedCAIDs []string
+}
+
+// Returned Error Codes:
+//   - ErrDMSEnrollmentPolicyAlreadyExists
+//     A policy with the same ID already exists.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the subject regex does not compile.
+func (svc DMSManagerServiceBackend) CreateEnrollmentPolicy(ctx context.Context, input CreateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	policy := &models.DMSEnrollmentPolicy{
+		ID:           input.ID,
+		Name:         input.Name,
+		Description:  input.Description,
+		SubjectRegex: input.SubjectRegex,
+		KeyTypes:     input.KeyTypes,
+		MinKeyBits:   input.MinKeyBits,
+		AllowedCAIDs: input.AllowedCAIDs,
+		CreationDate: time.Now(),
+	}
+	if policy.ID == "" {
+		policy.ID = goid.NewV4UUID().String()
+	}
+
+	err = helpers.ValidateDMSEnrollmentPolicy(*policy)
+	if err != nil {
+		lFunc.Errorf("invalid enrollment policy: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, _, err := svc.policyStorage.SelectExists(ctx, policy.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if enrollment policy '%s' exists in storage engine: %s", policy.ID, err)
+		return nil, err
+	} else if exists {
+		lFunc.Errorf("enrollment policy '%s' already exists", policy.ID)
+		return nil, errs.ErrDMSEnrollmentPolicyAlreadyExists
+	}
+
+	lFunc.Debugf("creating enrollment policy '%s'", policy.ID)
+	return svc.policyStorage.Insert(ctx, policy)
+}
+
+type GetEnrollmentPoliciesInput struct {
+	resources.ListInput[models.DMSEnrollmentPolicy]
+}
+
+func (svc DMSManagerServiceBackend) GetEnrollmentPolicies(ctx context.Context, input GetEnrollmentPoliciesInput) (string, error) {
+	return svc.policyStorage.SelectAll(ctx, storage.StorageListRequest[models.DMSEnrollmentPolicy]{
+		ExhaustiveRun: input.ExhaustiveRun,
+		ApplyFunc:     input.ApplyFunc,
+		QueryParams:   input.QueryParameters,
+	})
+}
+
+type GetEnrollmentPolicyByIDInput struct {
+	ID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrDMSEnrollmentPolicyNotFound
+//     The specified policy can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) GetEnrollmentPolicyByID(ctx context.Context, input GetEnrollmentPolicyByIDInput) (*models.DMSEnrollmentPolicy, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, policy, err := svc.policyStorage.SelectExists(ctx, input.ID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if enrollment policy '%s' exists in storage engine: %s", input.ID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("enrollment policy '%s' does not exist", input.ID)
+		return nil, errs.ErrDMSEnrollmentPolicyNotFound
+	}
+
+	return policy, nil
+}
+
+type UpdateEnrollmentPolicyInput struct {
+	Policy models.DMSEnrollmentPolicy `validate:"required"`
+}
+
+// UpdateEnrollmentPolicy replaces the rules of the policy. The DMSs already approved by the policy are not reevaluated.
+//
+// Returned Error Codes:
+//   - ErrDMSEnrollmentPolicyNotFound
+//     The specified policy can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or the subject regex does not compile.
+func (svc DMSManagerServiceBackend) UpdateEnrollmentPolicy(ctx context.Context, input UpdateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateDMSEnrollmentPolicy(input.Policy)
+	if err != nil {
+		lFunc.Errorf("invalid enrollment policy: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	policy, err := svc.GetEnrollmentPolicyByID(ctx, GetEnrollmentPolicyByIDInput{ID: input.Policy.ID})
+	if err != nil {
+		return nil, err
+	}
+
+	policy.Name = input.Policy.Name
+	policy.Description = input.Policy.Description
+	policy.SubjectRegex = input.Policy.SubjectRegex
+	policy.KeyTypes = input.Policy.KeyTypes
+	policy.MinKeyBits = input.Policy.MinKeyBits
+	policy.AllowedCAIDs = input.Policy.AllowedCAIDs
+
+	lFunc.Debugf("updating enrollment policy '%s'", policy.ID)
+	return svc.policyStorage.Update(ctx, policy)
+}
+
+type DeleteEnrollmentPolicyInput struct {
+	ID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrDMSEnrollmentPolicyNotFound
+//     The specified policy can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) DeleteEnrollmentPolicy(ctx context.Context, input DeleteEnrollmentPolicyInput) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	_, err := svc.GetEnrollmentPolicyByID(ctx, GetEnrollmentPolicyByIDInput{ID: input.ID})
+	if err != nil {
+		return err
+	}
+
+	lFunc.Debugf("deleting enrollment policy '%s'", input.ID)
+	return svc.policyStorage.Delete(ctx, input.ID)
+}
@@ -0,0 +1,157 @@
+package services
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+var enrollmentAuthErrors = []error{
+	errs.ErrDMSAuthModeNotSupported,
+	errs.ErrDMSEnrollInvalidCert,
+	errs.ErrDMSEnrollExpiredCert,
+	errs.ErrDMSEnrollRevokedCert,
+}
+
+var enrollmentPolicyErrors = []error{
+	errs.ErrDMSPendingApproval,
+	errs.ErrDMSOnlyEST,
+	errs.ErrDeviceInvalidID,
+	errs.ErrDMSSecureElementMissing,
+	errs.ErrDMSSecureElementNotAllowed,
+	errs.ErrDMSTPMAttestationMissing,
+	errs.ErrDMSTPMAttestationInvalid,
+	errs.ErrDMSEnrollForbidden,
+	errs.ErrDMSEnrollDeviceNotRegistered,
+	errs.ErrDMSReenrollSubjectMismatch,
+	errs.ErrDMSReenrollWindowNotOpen,
+	errs.ErrDMSEnrollContextDenied,
+}
+
+// enrollmentFailureReason classifies the error returned by an enrollment or reenrollment.
+func enrollmentFailureReason(err error) models.EnrollmentFailureReason {
+	var caErr *errs.DMSEnrollCAError
+	if errors.As(err, &caErr) {
+		return models.EnrollmentFailureCA
+	}
+
+	for _, authErr := range enrollmentAuthErrors {
+		if errors.Is(err, authErr) {
+			return models.EnrollmentFailureAuth
+		}
+	}
+
+	for _, policyErr := range enrollmentPolicyErrors {
+		if errors.Is(err, policyErr) {
+			return models.EnrollmentFailurePolicy
+		}
+	}
+
+	return models.EnrollmentFailureOther
+}
+
+func newDMSEnrollmentStats(dmsID string) *models.DMSEnrollmentStats {
+	return &models.DMSEnrollmentStats{
+		DMSID:    dmsID,
+		Enroll:   models.EnrollmentCounters{Failed: map[models.EnrollmentFailureReason]int{}},
+		Reenroll: models.EnrollmentCounters{Failed: map[models.EnrollmentFailureReason]int{}},
+	}
+}
+
+// recordEnrollment counts the outcome of the enrollment in the stats of the DMS. Requests for unknown DMSs are not
+// counted. Storage errors are only logged, so they never make the enrollment fail.
+func (svc DMSManagerServiceBackend) recordEnrollment(ctx context.Context, dmsID string, operation models.EnrollmentOperation, enrollErr error) {
+	if svc.statsStorage == nil || errors.Is(enrollErr, errs.ErrDMSNotFound) {
+		return
+	}
+
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	svc.statsLock.Lock()
+	defer svc.statsLock.Unlock()
+
+	exists, stats, err := svc.statsStorage.SelectExists(ctx, dmsID)
+	if err != nil {
+		lFunc.Errorf("could not read enrollment stats of DMS '%s': %s", dmsID, err)
+		return
+	}
+
+	if !exists {
+		stats = newDMSEnrollmentStats(dmsID)
+	}
+
+	counters := &stats.Enroll
+	if operation == models.EnrollmentOperationReenroll {
+		counters = &stats.Reenroll
+	}
+
+	if counters.Failed == nil {
+		counters.Failed = map[models.EnrollmentFailureReason]int{}
+	}
+
+	now := time.Now()
+	if enrollErr == nil {
+		counters.Succeeded++
+		stats.LastSuccessAt = &now
+	} else {
+		counters.Failed[enrollmentFailureReason(enrollErr)]++
+		stats.LastFailureAt = &now
+	}
+
+	if exists {
+		_, err = svc.statsStorage.Update(ctx, stats)
+	} else {
+		_, err = svc.statsStorage.Insert(ctx, stats)
+	}
+	if err != nil {
+		lFunc.Errorf("could not store enrollment stats of DMS '%s': %s", dmsID, err)
+	}
+}
+
+type GetDMSEnrollmentStatsInput struct {
+	DMSID string `validate:"required"`
+}
+
+// GetDMSEnrollmentStats returns the enrollment and reenrollment counters of the DMS. Counters are zero until the DMS
+// serves its first enrollment.
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) GetDMSEnrollmentStats(ctx context.Context, input GetDMSEnrollmentStatsInput) (*models.DMSEnrollmentStats, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, _, err := svc.dmsStorage.SelectExists(ctx, input.DMSID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if DMS '%s' exists in storage engine: %s", input.DMSID, err)
+		return nil, err
+	} else if !exists {
+		lFunc.Errorf("DMS '%s' does not exist in storage engine", input.DMSID)
+		return nil, errs.ErrDMSNotFound
+	}
+
+	if svc.statsStorage == nil {
+		return newDMSEnrollmentStats(input.DMSID), nil
+	}
+
+	exists, stats, err := svc.statsStorage.SelectExists(ctx, input.DMSID)
+	if err != nil {
+		lFunc.Errorf("could not read enrollment stats of DMS '%s': %s", input.DMSID, err)
+		return nil, err
+	} else if !exists {
+		return newDMSEnrollmentStats(input.DMSID), nil
+	}
+
+	return stats, nil
+}
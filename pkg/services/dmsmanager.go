@@ -2,18 +2,23 @@ package services
 
 import (
 	"context"
+	"crypto"
 	"crypto/ecdsa"
 	"crypto/elliptic"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/x509"
+	"encoding/base64"
 	"fmt"
 	"slices"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/go-playground/validator/v10"
+	"github.com/jakehl/goid"
 	external_clients "github.com/lamassuiot/lamassuiot/v2/pkg/clients/external"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/cryptoengines"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
@@ -31,20 +36,59 @@ type DMSManagerMiddleware func(DMSManagerService) DMSManagerService
 type DMSManagerService interface {
 	ESTService
 	GetDMSStats(ctx context.Context, input GetDMSStatsInput) (*models.DMSStats, error)
+	GetDMSEnrollmentStats(ctx context.Context, input GetDMSEnrollmentStatsInput) (*models.DMSEnrollmentStats, error)
 	CreateDMS(ctx context.Context, input CreateDMSInput) (*models.DMS, error)
 	UpdateDMS(ctx context.Context, input UpdateDMSInput) (*models.DMS, error)
 	GetDMSByID(ctx context.Context, input GetDMSByIDInput) (*models.DMS, error)
 	GetAll(ctx context.Context, input GetAllInput) (string, error)
+	UpdateDMSStatus(ctx context.Context, input UpdateDMSStatusInput) (*models.DMS, error)
 
+	CreateEnrollmentPolicy(ctx context.Context, input CreateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error)
+	GetEnrollmentPolicies(ctx context.Context, input GetEnrollmentPoliciesInput) (string, error)
+	GetEnrollmentPolicyByID(ctx context.Context, input GetEnrollmentPolicyByIDInput) (*models.DMSEnrollmentPolicy, error)
+	UpdateEnrollmentPolicy(ctx context.Context, input UpdateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error)
+	DeleteEnrollmentPolicy(ctx context.Context, input DeleteEnrollmentPolicyInput) error
+
+	CreateBootstrapToken(ctx context.Context, input CreateBootstrapTokenInput) (*models.DMSBootstrapTokenCredentials, error)
+	GetBootstrapTokens(ctx context.Context, input GetBootstrapTokensInput) ([]models.DMSBootstrapToken, error)
+	RevokeBootstrapToken(ctx context.Context, input RevokeBootstrapTokenInput) (*models.DMSBootstrapToken, error)
+
+	CreateClaimCode(ctx context.Context, input CreateClaimCodeInput) (*models.DMSClaimCodeCredentials, error)
+	GetClaimCodes(ctx context.Context, input GetClaimCodesInput) ([]models.DMSClaimCode, error)
+	RevokeClaimCode(ctx context.Context, input RevokeClaimCodeInput) (*models.DMSClaimCode, error)
+	ClaimDevice(ctx context.Context, input ClaimDeviceInput) (*models.DeviceClaim, error)
+
+	GetEnrollmentAudits(ctx context.Context, input GetEnrollmentAuditsInput) (string, error)
+
+	PreregisterDevices(ctx context.Context, input PreregisterDevicesInput) (*models.DevicePreregistration, error)
 	BindIdentityToDevice(ctx context.Context, input BindIdentityToDeviceInput) (*models.BindIdentityToDeviceOutput, error)
+	RevokeSupersededCertificate(ctx context.Context, input RevokeSupersededCertificateInput) (*models.Certificate, error)
+	RevokeDeviceCertificate(ctx context.Context, input RevokeDeviceCertificateInput) (*models.Certificate, error)
+
+	StartCARotation(ctx context.Context, input StartCARotationInput) (*models.CARotation, error)
+	ProcessCARotationBatch(ctx context.Context, input ProcessCARotationBatchInput) (*models.CARotation, error)
+	GetCARotation(ctx context.Context, input GetCARotationInput) (*models.CARotation, error)
+
+	CreateACMEEABKey(ctx context.Context, input CreateACMEEABKeyInput) (*models.DMSACMEEABCredentials, error)
+	GetACMEEABKeys(ctx context.Context, input GetACMEEABKeysInput) ([]models.DMSACMEEABKey, error)
+	RevokeACMEEABKey(ctx context.Context, input RevokeACMEEABKeyInput) (*models.DMSACMEEABKey, error)
 }
 
 type DMSManagerServiceBackend struct {
 	service          DMSManagerService
 	downstreamCert   *x509.Certificate
 	dmsStorage       storage.DMSRepo
+	statsStorage     storage.DMSEnrollmentStatsRepo
+	statsLock        *sync.Mutex
+	policyStorage    storage.DMSEnrollmentPoliciesRepo
+	approvalEnabled  bool
+	tokenStorage     storage.DMSBootstrapTokensRepo
+	claimCodeStorage storage.DMSClaimCodesRepo
+	auditStorage     storage.DMSEnrollmentAuditsRepo
 	deviceManagerCli DeviceManagerService
 	caClient         CAService
+	acmeEABSecret    []byte
+	keyGenEngine     cryptoengines.CryptoEngine
 	logger           *logrus.Entry
 }
 
@@ -54,14 +98,40 @@ type DMSManagerBuilder struct {
 	CAClient              CAService
 	DMSStorage            storage.DMSRepo
 	DownstreamCertificate *x509.Certificate
+	ACMEEABSecret         []byte
+	// KeyGenEngine stores the keys generated with EST server-side key generation. Keys are only kept in memory if nil.
+	KeyGenEngine cryptoengines.CryptoEngine
+	// EnrollmentStatsStorage stores the enrollment counters of the DMSs. Enrollments are not counted if nil.
+	EnrollmentStatsStorage storage.DMSEnrollmentStatsRepo
+	// EnrollmentPolicyStorage stores the policies auto-approving the DMS registrations.
+	EnrollmentPolicyStorage storage.DMSEnrollmentPoliciesRepo
+	// RegistrationApproval holds the DMSs registered with a CSR in PENDING_APPROVAL unless an enrollment policy
+	// matches the registration.
+	RegistrationApproval bool
+	// BootstrapTokenStorage stores the single-use tokens of the DMSs using the BOOTSTRAP_TOKEN EST auth mode.
+	BootstrapTokenStorage storage.DMSBootstrapTokensRepo
+	// ClaimCodeStorage stores the one-time codes of the public device claim endpoint.
+	ClaimCodeStorage storage.DMSClaimCodesRepo
+	// EnrollmentAuditStorage stores the evaluations of the enrollment context policies. Evaluations are not recorded
+	// if nil.
+	EnrollmentAuditStorage storage.DMSEnrollmentAuditsRepo
 }
 
 func NewDMSManagerService(builder DMSManagerBuilder) DMSManagerService {
 	svc := &DMSManagerServiceBackend{
 		dmsStorage:       builder.DMSStorage,
+		statsStorage:     builder.EnrollmentStatsStorage,
+		statsLock:        &sync.Mutex{},
+		policyStorage:    builder.EnrollmentPolicyStorage,
+		approvalEnabled:  builder.RegistrationApproval,
+		tokenStorage:     builder.BootstrapTokenStorage,
+		claimCodeStorage: builder.ClaimCodeStorage,
+		auditStorage:     builder.EnrollmentAuditStorage,
 		caClient:         builder.CAClient,
 		deviceManagerCli: builder.DevManagerCli,
 		downstreamCert:   builder.DownstreamCertificate,
+		acmeEABSecret:    builder.ACMEEABSecret,
+		keyGenEngine:     builder.KeyGenEngine,
 		logger:           builder.Logger,
 	}
 
@@ -95,6 +165,7 @@ type CreateDMSInput struct {
 	Name     string `validate:"required"`
 	Metadata map[string]any
 	Settings models.DMSSettings `validate:"required"`
+	CSR      *models.X509CertificateRequest
 }
 
 func (svc DMSManagerServiceBackend) CreateDMS(ctx context.Context, input CreateDMSInput) (*models.DMS, error) {
@@ -105,6 +176,61 @@ func (svc DMSManagerServiceBackend) CreateDMS(ctx context.Context, input CreateD
 		lFunc.Errorf("struct validation error: %s", err)
 		return nil, errs.ErrValidateBadRequest
 	}
+
+	err = helpers.ValidateDeviceProvisionProfile(input.Settings.EnrollmentSettings.DeviceProvisionProfile)
+	if err != nil {
+		lFunc.Errorf("invalid device provisioning profile template: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateDeviceIDRules(input.Settings.EnrollmentSettings.DeviceIDRules)
+	if err != nil {
+		lFunc.Errorf("invalid device ID rules: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSecureElementVerification(input.Settings.EnrollmentSettings.SecureElementVerification)
+	if err != nil {
+		lFunc.Errorf("invalid secure element verification settings: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateTPMAttestation(input.Settings.EnrollmentSettings.TPMAttestation)
+	if err != nil {
+		lFunc.Errorf("invalid TPM attestation settings: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSubjectOrder(input.Settings.EnrollmentSettings.SigningProfile.SubjectOrder)
+	if err != nil {
+		lFunc.Errorf("invalid signing profile subject order: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSigningProfileURLs(input.Settings.EnrollmentSettings.SigningProfile)
+	if err != nil {
+		lFunc.Errorf("invalid signing profile validation URLs: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateClockSkewSettings(input.Settings)
+	if err != nil {
+		lFunc.Errorf("invalid clock skew settings: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateDeviceClassProfiles(input.Settings.EnrollmentSettings)
+	if err != nil {
+		lFunc.Errorf("invalid device class profiles: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateEnrollmentContextPolicy(input.Settings.EnrollmentSettings.ContextPolicy)
+	if err != nil {
+		lFunc.Errorf("invalid enrollment context policy: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
 	lFunc.Debugf("checking if DMS '%s' exists", input.ID)
 	if exists, _, err := svc.dmsStorage.SelectExists(ctx, input.ID); err != nil {
 		lFunc.Errorf("something went wrong while checking if DMS '%s' exists in storage engine: %s", input.ID, err)
@@ -114,14 +240,52 @@ func (svc DMSManagerServiceBackend) CreateDMS(ctx context.Context, input CreateD
 		return nil, errs.ErrDMSAlreadyExists
 	}
 
+	fingerprint := ""
+	if input.CSR != nil {
+		csr := (*x509.CertificateRequest)(input.CSR)
+		if err := csr.CheckSignature(); err != nil {
+			lFunc.Errorf("invalid DMS '%s' CSR signature: %s", input.ID, err)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		fingerprint = helpers.PublicKeyFingerprint(csr.RawSubjectPublicKeyInfo)
+		lFunc.Debugf("checking if DMS public key %s is already registered", fingerprint)
+		existingDMS, err := svc.getDMSByPublicKeyFingerprint(ctx, fingerprint)
+		if err != nil {
+			lFunc.Errorf("something went wrong while looking for DMSs with public key %s: %s", fingerprint, err)
+			return nil, err
+		} else if existingDMS != nil {
+			lFunc.Errorf("DMS '%s' CSR public key %s is already registered by DMS '%s'", input.ID, fingerprint, existingDMS.ID)
+			return nil, &errs.DMSPublicKeyConflictError{DMSID: existingDMS.ID}
+		}
+	}
+
 	now := time.Now()
 
 	dms := &models.DMS{
-		ID:           input.ID,
-		Name:         input.Name,
-		Metadata:     input.Metadata,
-		CreationDate: now,
-		Settings:     input.Settings,
+		ID:                   input.ID,
+		Name:                 input.Name,
+		Metadata:             input.Metadata,
+		CreationDate:         now,
+		Settings:             input.Settings,
+		PublicKeyFingerprint: fingerprint,
+	}
+
+	if svc.approvalEnabled && input.CSR != nil {
+		policy, err := svc.matchEnrollmentPolicy(ctx, (*x509.CertificateRequest)(input.CSR), input.Settings)
+		if err != nil {
+			lFunc.Errorf("could not evaluate enrollment policies for DMS '%s': %s", input.ID, err)
+			return nil, err
+		}
+
+		if policy != nil {
+			lFunc.Infof("DMS '%s' registration auto-approved by enrollment policy '%s'", input.ID, policy.ID)
+			dms.Status = models.ActiveDMSStatus
+			dms.EnrollmentPolicyID = policy.ID
+		} else {
+			lFunc.Infof("DMS '%s' registration does not match any enrollment policy. pending approval", input.ID)
+			dms.Status = models.PendingApprovalDMSStatus
+		}
 	}
 
 	dms, err = svc.dmsStorage.Insert(ctx, dms)
@@ -134,8 +298,30 @@ func (svc DMSManagerServiceBackend) CreateDMS(ctx context.Context, input CreateD
 	return dms, nil
 }
 
+func (svc DMSManagerServiceBackend) getDMSByPublicKeyFingerprint(ctx context.Context, fingerprint string) (*models.DMS, error) {
+	var found *models.DMS
+	_, err := svc.dmsStorage.SelectAll(ctx, false, func(dms models.DMS) {
+		if found == nil && dms.PublicKeyFingerprint == fingerprint {
+			found = &dms
+		}
+	}, &resources.QueryParameters{
+		PageSize: 1,
+		Filters: []resources.FilterOption{
+			{
+				Field:           "public_key_fingerprint",
+				FilterOperation: resources.StringEqual,
+				Value:           fingerprint,
+			},
+		},
+	}, map[string]interface{}{})
+
+	return found, err
+}
+
 type UpdateDMSInput struct {
 	DMS models.DMS `validate:"required"`
+	// IfMatch is the If-Match precondition of the update, evaluated against the ETag of the stored DMS.
+	IfMatch string
 }
 
 func (svc DMSManagerServiceBackend) UpdateDMS(ctx context.Context, input UpdateDMSInput) (*models.DMS, error) {
@@ -146,6 +332,61 @@ func (svc DMSManagerServiceBackend) UpdateDMS(ctx context.Context, input UpdateD
 		lFunc.Errorf("struct validation error: %s", err)
 		return nil, errs.ErrValidateBadRequest
 	}
+
+	err = helpers.ValidateDeviceProvisionProfile(input.DMS.Settings.EnrollmentSettings.DeviceProvisionProfile)
+	if err != nil {
+		lFunc.Errorf("invalid device provisioning profile template: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateDeviceIDRules(input.DMS.Settings.EnrollmentSettings.DeviceIDRules)
+	if err != nil {
+		lFunc.Errorf("invalid device ID rules: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSecureElementVerification(input.DMS.Settings.EnrollmentSettings.SecureElementVerification)
+	if err != nil {
+		lFunc.Errorf("invalid secure element verification settings: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateTPMAttestation(input.DMS.Settings.EnrollmentSettings.TPMAttestation)
+	if err != nil {
+		lFunc.Errorf("invalid TPM attestation settings: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSubjectOrder(input.DMS.Settings.EnrollmentSettings.SigningProfile.SubjectOrder)
+	if err != nil {
+		lFunc.Errorf("invalid signing profile subject order: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateSigningProfileURLs(input.DMS.Settings.EnrollmentSettings.SigningProfile)
+	if err != nil {
+		lFunc.Errorf("invalid signing profile validation URLs: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateClockSkewSettings(input.DMS.Settings)
+	if err != nil {
+		lFunc.Errorf("invalid clock skew settings: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateDeviceClassProfiles(input.DMS.Settings.EnrollmentSettings)
+	if err != nil {
+		lFunc.Errorf("invalid device class profiles: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	err = helpers.ValidateEnrollmentContextPolicy(input.DMS.Settings.EnrollmentSettings.ContextPolicy)
+	if err != nil {
+		lFunc.Errorf("invalid enrollment context policy: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
 	lFunc.Debugf("checking if DMS '%s' exists", input.DMS.ID)
 	exists, dms, err := svc.dmsStorage.SelectExists(ctx, input.DMS.ID)
 	if err != nil {
@@ -156,12 +397,17 @@ func (svc DMSManagerServiceBackend) UpdateDMS(ctx context.Context, input UpdateD
 		return nil, errs.ErrDMSNotFound
 	}
 
+	if !helpers.ETagMatches(input.IfMatch, helpers.ETag(dms)) {
+		lFunc.Errorf("DMS '%s' has been modified. If-Match precondition failed", input.DMS.ID)
+		return nil, errs.ErrPreconditionFailed
+	}
+
 	dms.Metadata = input.DMS.Metadata
 	dms.Name = input.DMS.Name
 	dms.Settings = input.DMS.Settings
 
 	lFunc.Debugf("updating DMS %s", input.DMS.ID)
-	return svc.dmsStorage.Update(ctx, dms)
+	return updateIfMatch(ctx, svc.dmsStorage.UpdateIf, dms, input.IfMatch, dmsETag)
 }
 
 type GetDMSByIDInput struct {
@@ -206,10 +452,21 @@ func (svc DMSManagerServiceBackend) GetAll(ctx context.Context, input GetAllInpu
 	return bookmark, nil
 }
 
+// CACerts returns the CAs distributed by the DMS (RFC7030 4.1) so devices can bootstrap trust. Subordinate CAs are
+// returned with their parent CAs, so devices can build the full chain. Each certificate is only returned once.
 func (svc DMSManagerServiceBackend) CACerts(ctx context.Context, aps string) ([]*x509.Certificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
 	cas := []*x509.Certificate{}
+	added := map[string]bool{}
+	addCA := func(crt *x509.Certificate) {
+		if crt == nil || added[string(crt.Raw)] {
+			return
+		}
+
+		added[string(crt.Raw)] = true
+		cas = append(cas, crt)
+	}
 	lFunc.Debugf("checking if DMS '%s' exists", aps)
 	exists, dms, err := svc.dmsStorage.SelectExists(ctx, aps)
 	if err != nil {
@@ -226,7 +483,7 @@ func (svc DMSManagerServiceBackend) CACerts(ctx context.Context, aps string) ([]
 		if svc.downstreamCert == nil {
 			lFunc.Warnf("downstream certificate is nil. skipping")
 		} else {
-			cas = append(cas, svc.downstreamCert)
+			addCA(svc.downstreamCert)
 		}
 	}
 
@@ -249,16 +506,71 @@ func (svc DMSManagerServiceBackend) CACerts(ctx context.Context, aps string) ([]
 
 		lFunc.Debugf("got CA %s\n%s", caResponse.ID, helpers.CertificateToPEM((*x509.Certificate)(caResponse.Certificate.Certificate)))
 
-		cas = append(cas, (*x509.Certificate)(caResponse.Certificate.Certificate))
+		for _, parent := range caResponse.CAChain {
+			addCA((*x509.Certificate)(parent))
+		}
+
+		addCA((*x509.Certificate)(caResponse.Certificate.Certificate))
 	}
 
 	return cas, nil
 }
 
+// SignedCACerts signs the CA certificates with the enrollment CA, so devices fetching them from mirrors over plain
+// HTTP can verify them against the CA they enroll with.
+func (svc DMSManagerServiceBackend) SignedCACerts(ctx context.Context, aps string, pemEncoded bool) (*models.SignedContent, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	cas, err := svc.CACerts(ctx, aps)
+	if err != nil {
+		return nil, err
+	}
+
+	var content []byte
+	if pemEncoded {
+		content = helpers.CertificatesToPEMBundle(cas)
+	} else {
+		content, err = helpers.CertificatesToPKCS7(cas)
+		if err != nil {
+			lFunc.Errorf("could not encode CA certificates: %s", err)
+			return nil, err
+		}
+	}
+
+	_, dms, err := svc.dmsStorage.SelectExists(ctx, aps)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if DMS '%s' exists in storage engine: %s", aps, err)
+		return nil, err
+	}
+
+	enrollCA, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{
+		CAID: dms.Settings.EnrollmentSettings.EnrollmentCA,
+	})
+	if err != nil {
+		lFunc.Errorf("something went wrong while reading enrollment CA '%s': %s", dms.Settings.EnrollmentSettings.EnrollmentCA, err)
+		return nil, err
+	}
+
+	lFunc.Debugf("signing CA certificates of DMS %s with enrollment CA %s", aps, enrollCA.ID)
+	signed, err := SignDetachedContent(ctx, svc.caClient, enrollCA, content)
+	if err != nil {
+		lFunc.Errorf("something went wrong while signing CA certificates with enrollment CA '%s': %s", enrollCA.ID, err)
+		return nil, err
+	}
+
+	return signed, nil
+}
+
 // Validation:
 //   - Cert:
 //     Only Bootstrap cert (CA issued By Lamassu)
 func (svc DMSManagerServiceBackend) Enroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, error) {
+	crt, err := svc.enroll(ctx, csr, aps)
+	svc.recordEnrollment(ctx, aps, models.EnrollmentOperationEnroll, err)
+	return crt, err
+}
+
+func (svc DMSManagerServiceBackend) enroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
 	lFunc.Debugf("checking if DMS '%s' exists", aps)
@@ -270,86 +582,66 @@ func (svc DMSManagerServiceBackend) Enroll(ctx context.Context, csr *x509.Certif
 		return nil, errs.ErrDMSNotFound
 	}
 
+	if dms.Status == models.PendingApprovalDMSStatus {
+		lFunc.Errorf("aborting enrollment process for device '%s'. DMS '%s' registration is pending approval", csr.Subject.CommonName, aps)
+		return nil, errs.ErrDMSPendingApproval
+	}
+
 	if dms.Settings.EnrollmentSettings.EnrollmentProtocol != models.EST {
 		lFunc.Errorf("aborting enrollment process for device '%s'. DMS '%s' doesn't support EST Protocol", csr.Subject.CommonName, aps)
 		return nil, errs.ErrDMSOnlyEST
 	}
 
-	estAuthOptions := dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030
-	if estAuthOptions.AuthMode == models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate) {
-		clientCert, hasValue := ctx.Value(string(identityextractors.IdentityExtractorClientCertificate)).(*x509.Certificate)
-		if !hasValue {
-			lFunc.Errorf("aborting enrollment process for device '%s'. DMS '%s' is configured with '%s'. No client certificate was presented", csr.Subject.CommonName, dms.ID, estAuthOptions.AuthMode)
-			return nil, errs.ErrDMSAuthModeNotSupported
-		}
-
-		lFunc.Debugf("presented client certificate has CommonName '%s' and SerialNumber '%s' issued by CA with CommonName '%s'", clientCert.Subject.CommonName, helpers.SerialNumberToString(clientCert.SerialNumber), clientCert.Issuer.CommonName)
-
-		//check if certificate is a certificate issued by bootstrap CA
-		validCertificate := false
-		var validationCA *models.CACertificate
-		estEnrollOpts := dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030
-		for _, caID := range estEnrollOpts.AuthOptionsMTLS.ValidationCAs {
-			ca, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{CAID: caID})
-			if err != nil {
-				lFunc.Warnf("could not obtain lamassu CA '%s'. Skipping to next validation CA: %s", caID, err)
-				continue
-			}
-
-			err = helpers.ValidateCertificate((*x509.Certificate)(ca.Certificate.Certificate), clientCert, true)
-			if err != nil {
-				lFunc.Debugf("invalid validation using CA [%s] with CommonName '%s', SerialNumber '%s'", ca.ID, ca.Subject.CommonName, ca.SerialNumber)
-			} else {
-				lFunc.Debugf("OK validation using CA [%s] with CommonName '%s', SerialNumber '%s'", ca.ID, ca.Subject.CommonName, ca.SerialNumber)
-				validCertificate = true
-				validationCA = ca
-				break
-			}
-		}
-
-		clientSN := helpers.SerialNumberToString(clientCert.SerialNumber)
+	deviceID, err := helpers.NormalizeDeviceID(csr.Subject.CommonName, dms.Settings.EnrollmentSettings.DeviceIDRules)
+	if err != nil {
+		lFunc.Errorf("aborting enrollment process for device '%s'. Invalid device ID: %s", csr.Subject.CommonName, err)
+		return nil, errs.ErrDeviceInvalidID
+	}
 
-		if !validCertificate {
-			lFunc.Errorf("invalid enrollment. used certificate not authorized for this DMS. certificate has SerialNumber %s issued by CA %s", clientSN, clientCert.Issuer.CommonName)
-			return nil, errs.ErrDMSEnrollInvalidCert
-		}
+	err = svc.evaluateEnrollmentContext(ctx, dms, deviceID)
+	if err != nil {
+		return nil, err
+	}
 
-		//checks against Lamassu, external OCSP or CRL
-		couldCheckRevocation, isRevoked, err := svc.checkCertificateRevocation(ctx, clientCert, (*x509.Certificate)(validationCA.Certificate.Certificate))
+	var clientCert *x509.Certificate
+	if auth, preauthenticated := ctx.Value(authenticatedEnrollmentCtxKey{}).(*enrollmentAuthentication); preauthenticated {
+		lFunc.Debugf("device '%s' was already authenticated by DMS '%s'", deviceID, dms.ID)
+		clientCert = auth.clientCert
+	} else if codeID, claimed := isClaimedEnrollment(ctx); claimed {
+		lFunc.Infof("device '%s' is claimed with code %s. skipping DMS '%s' %s auth", deviceID, codeID, dms.ID, dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthMode)
+	} else {
+		clientCert, err = svc.authenticateEnrollment(ctx, dms, csr, deviceID)
 		if err != nil {
-			lFunc.Errorf("error while checking certificate revocation status: %s", err)
 			return nil, err
 		}
+	}
 
-		if couldCheckRevocation {
-			if isRevoked {
-				return nil, fmt.Errorf("certificate is revoked")
-			}
-			lFunc.Infof("certificate is not revoked")
-		} else {
-			lFunc.Infof("could not verify certificate expiration. Assuming certificate as not-revoked")
-		}
+	err = svc.verifySecureElement(ctx, dms, csr)
+	if err != nil {
+		return nil, err
+	}
 
-	} else if estAuthOptions.AuthMode == models.ESTAuthMode(identityextractors.IdentityExtractorNoAuth) {
-		lFunc.Warnf("DMS %s is configured with NoAuth. Allowing enrollment", dms.ID)
+	err = svc.verifyTPMAttestation(ctx, dms, csr)
+	if err != nil {
+		return nil, err
 	}
 
 	var device *models.Device
 	device, err = svc.deviceManagerCli.GetDeviceByID(ctx, GetDeviceByIDInput{
-		ID: csr.Subject.CommonName,
+		ID: deviceID,
 	})
 	if err != nil {
 		switch err {
 		case errs.ErrDeviceNotFound:
-			lFunc.Debugf("device '%s' doesn't exist", csr.Subject.CommonName)
+			lFunc.Debugf("device '%s' doesn't exist", deviceID)
 		default:
-			lFunc.Errorf("could not get device '%s': %s", csr.Subject.CommonName, err)
+			lFunc.Errorf("could not get device '%s': %s", deviceID, err)
 			return nil, err
 		}
 	} else {
-		lFunc.Debugf("device '%s' does exist", csr.Subject.CommonName)
+		lFunc.Debugf("device '%s' does exist", deviceID)
 		if dms.Settings.EnrollmentSettings.EnableReplaceableEnrollment {
-			lFunc.Debugf("DMS '%s' allows new enrollments. continuing enrollment process for device '%s'", dms.ID, csr.Subject.CommonName)
+			lFunc.Debugf("DMS '%s' allows new enrollments. continuing enrollment process for device '%s'", dms.ID, deviceID)
 			//revoke active certificate
 			defer func() {
 				if device.IdentitySlot == nil {
@@ -363,47 +655,70 @@ func (svc DMSManagerServiceBackend) Enroll(ctx context.Context, csr *x509.Certif
 				lFunc.Errorf("could not revoke certificate %s: %s", device.IdentitySlot.Secrets[device.IdentitySlot.ActiveVersion], err)
 			}()
 		} else {
-			lFunc.Debugf("DMS '%s' forbids new enrollments. aborting enrollment process for device '%s'. consider switching NewEnrollment option ON in the DMS", dms.ID, csr.Subject.CommonName)
-			return nil, fmt.Errorf("forbiddenNewEnrollment")
+			lFunc.Debugf("DMS '%s' forbids new enrollments. aborting enrollment process for device '%s'. consider switching NewEnrollment option ON in the DMS", dms.ID, deviceID)
+			return nil, errs.ErrDMSEnrollForbidden
 		}
 	}
 
 	if dms.Settings.EnrollmentSettings.RegistrationMode == models.JITP {
 		if device == nil {
-			lFunc.Debugf("DMS '%s' is configured with JustInTime registration. will create device with ID %s", dms.ID, csr.Subject.CommonName)
+			lFunc.Debugf("DMS '%s' is configured with JustInTime registration. will create device with ID %s", dms.ID, deviceID)
+			profile, err := helpers.RenderDeviceProvisionProfile(dms.Settings.EnrollmentSettings.DeviceProvisionProfile, helpers.NewDeviceProvisionTemplateContext(dms, csr, clientCert))
+			if err != nil {
+				lFunc.Errorf("could not render DMS '%s' device provisioning profile for device '%s': %s", dms.ID, deviceID, err)
+				return nil, err
+			}
+
 			//contact device manager and register device first
 			device, err = svc.deviceManagerCli.CreateDevice(ctx, CreateDeviceInput{
-				ID:        csr.Subject.CommonName,
+				ID:        deviceID,
 				Alias:     csr.Subject.CommonName,
-				Tags:      dms.Settings.EnrollmentSettings.DeviceProvisionProfile.Tags,
-				Metadata:  dms.Settings.EnrollmentSettings.DeviceProvisionProfile.Metadata,
-				Icon:      dms.Settings.EnrollmentSettings.DeviceProvisionProfile.Icon,
-				IconColor: dms.Settings.EnrollmentSettings.DeviceProvisionProfile.IconColor,
+				Tags:      profile.Tags,
+				Metadata:  profile.Metadata,
+				Icon:      profile.Icon,
+				IconColor: profile.IconColor,
 				DMSID:     dms.ID,
+
+				DeviceIDRules: &dms.Settings.EnrollmentSettings.DeviceIDRules,
 			})
 			if err != nil {
-				lFunc.Errorf("could not register device '%s': %s", csr.Subject.CommonName, err)
+				lFunc.Errorf("could not register device '%s': %s", deviceID, err)
 				return nil, err
 			}
 		} else {
-			lFunc.Debugf("skipping '%s' device registration since already exists", csr.Subject.CommonName)
+			lFunc.Debugf("skipping '%s' device registration since already exists", deviceID)
 		}
 	} else if device == nil {
-		lFunc.Errorf("DMS '%s' is doesn't allow JustInTime registration. register the '%s' device or switch DMS JIT option ON", dms.ID, csr.Subject.CommonName)
-		return nil, fmt.Errorf("device not preregistered")
+		lFunc.Errorf("DMS '%s' is doesn't allow JustInTime registration. register the '%s' device or switch DMS JIT option ON", dms.ID, deviceID)
+		return nil, errs.ErrDMSEnrollDeviceNotRegistered
+	} else if device.DMSOwner != dms.ID {
+		lFunc.Errorf("device '%s' is preregistered by DMS '%s', not by DMS '%s'. aborting enrollment process", deviceID, device.DMSOwner, dms.ID)
+		return nil, errs.ErrDMSEnrollDeviceNotRegistered
 	} else {
 		lFunc.Debugf("device '%s' is preregistered. continuing enrollment process", device.ID)
 	}
 
+	signingProfile, certificateProfileID, deviceClass := helpers.GetDeviceIssuanceProfile(dms.Settings.EnrollmentSettings, *device)
+	if deviceClass != nil {
+		lFunc.Debugf("device '%s' belongs to device class '%s' of DMS '%s'", deviceID, deviceClass.Name, dms.ID)
+	}
+
 	crt, err := svc.caClient.SignCertificate(ctx, SignCertificateInput{
-		CAID:         dms.Settings.EnrollmentSettings.EnrollmentCA,
-		CertRequest:  (*models.X509CertificateRequest)(csr),
-		Subject:      nil,
-		SignVerbatim: true,
+		CAID:                 dms.Settings.EnrollmentSettings.EnrollmentCA,
+		CertRequest:          (*models.X509CertificateRequest)(csr),
+		Subject:              nil,
+		SignVerbatim:         true,
+		SigningProfile:       &signingProfile,
+		CertificateProfileID: certificateProfileID,
+		IssuanceContext: &models.CertificateIssuanceContext{
+			DMSID:     dms.ID,
+			DeviceID:  deviceID,
+			RequestID: helpers.GetRequestID(ctx),
+		},
 	})
 	if err != nil {
-		lFunc.Errorf("could issue certificate for device '%s': %s", csr.Subject.CommonName, err)
-		return nil, err
+		lFunc.Errorf("could issue certificate for device '%s': %s", deviceID, err)
+		return nil, &errs.DMSEnrollCAError{Err: err}
 	}
 
 	bindMode := models.DeviceEventTypeProvisioned
@@ -425,48 +740,149 @@ func (svc DMSManagerServiceBackend) Enroll(ctx context.Context, csr *x509.Certif
 	return (*x509.Certificate)(crt.Certificate), nil
 }
 
-func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, error) {
-	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
-
-	lFunc.Debugf("checking if DMS '%s' exists", aps)
-	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{
-		ID: aps,
-	})
-	if err != nil {
-		lFunc.Errorf("aborting reenrollment process for device '%s'. Could not get DMS '%s': %s", csr.Subject.CommonName, aps, err)
-		return nil, errs.ErrDMSNotFound
-	}
+// authenticatedEnrollmentCtxKey holds the enrollmentAuthentication of a device already authenticated with the EST auth
+// mode of the DMS, so the enrollment does not authenticate it again (e.g. consuming the bootstrap token twice).
+type authenticatedEnrollmentCtxKey struct{}
 
-	if dms.Settings.EnrollmentSettings.EnrollmentProtocol != models.EST {
-		lFunc.Errorf("aborting reenrollment process for device '%s'. DMS '%s' doesn't support EST Protocol", csr.Subject.CommonName, aps)
-		return nil, errs.ErrDMSOnlyEST
-	}
+type enrollmentAuthentication struct {
+	clientCert *x509.Certificate
+}
 
-	enrollCAID := dms.Settings.EnrollmentSettings.EnrollmentCA
-	enrollCA, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{
-		CAID: enrollCAID,
-	})
-	if err != nil {
-		lFunc.Errorf("could not get enroll CA with ID=%s: %s", enrollCAID, err)
-		return nil, err
-	}
+// authenticateEnrollment checks the device against the EST auth mode of the DMS. The client certificate is returned
+// if the DMS authenticates the devices with one.
+func (svc DMSManagerServiceBackend) authenticateEnrollment(ctx context.Context, dms *models.DMS, csr *x509.CertificateRequest, deviceID string) (*x509.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
-	if dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthMode == models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate) {
-		clientCert, hasValue := ctx.Value(string(identityextractors.IdentityExtractorClientCertificate)).(*x509.Certificate)
+	estAuthOptions := dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030
+	var clientCert *x509.Certificate
+	if estAuthOptions.AuthMode == models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate) {
+		var hasValue bool
+		clientCert, hasValue = ctx.Value(string(identityextractors.IdentityExtractorClientCertificate)).(*x509.Certificate)
 		if !hasValue {
-			lFunc.Errorf("aborting reenrollment process for device '%s'. No client certificate was presented", csr.Subject.CommonName)
+			lFunc.Errorf("aborting enrollment process for device '%s'. DMS '%s' is configured with '%s'. No client certificate was presented", csr.Subject.CommonName, dms.ID, estAuthOptions.AuthMode)
 			return nil, errs.ErrDMSAuthModeNotSupported
 		}
 
-		lFunc.Debugf("presented client certificate has CN=%s and SN=%s issued by CA with CommonName '%s'", clientCert.Subject.CommonName, helpers.SerialNumberToString(clientCert.SerialNumber), clientCert.Issuer.CommonName)
+		lFunc.Debugf("presented client certificate has CommonName '%s' and SerialNumber '%s' issued by CA with CommonName '%s'", clientCert.Subject.CommonName, helpers.SerialNumberToString(clientCert.SerialNumber), clientCert.Issuer.CommonName)
 
+		//check if certificate is a certificate issued by bootstrap CA
 		validCertificate := false
-		var validationCA *x509.Certificate
+		var validationCA *models.CACertificate
+		estEnrollOpts := dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030
+		for _, caID := range estEnrollOpts.AuthOptionsMTLS.ValidationCAs {
+			ca, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{CAID: caID})
+			if err != nil {
+				lFunc.Warnf("could not obtain lamassu CA '%s'. Skipping to next validation CA: %s", caID, err)
+				continue
+			}
 
-		//check if certificate is a certificate issued by Enroll CA
-		lFunc.Debugf("validating client certificate using EST Enrollment CA witch has ID=%s CN=%s SN=%s", enrollCAID, enrollCA.Certificate.Subject.CommonName, enrollCA.SerialNumber)
-		err = helpers.ValidateCertificate((*x509.Certificate)(enrollCA.Certificate.Certificate), clientCert, false)
-		if err != nil {
+			err = helpers.ValidateCertificateWithClockSkew((*x509.Certificate)(ca.Certificate.Certificate), clientCert, time.Duration(estEnrollOpts.AuthOptionsMTLS.ClockSkewTolerance))
+			if err != nil {
+				lFunc.Debugf("invalid validation using CA [%s] with CommonName '%s', SerialNumber '%s'", ca.ID, ca.Subject.CommonName, ca.SerialNumber)
+			} else {
+				lFunc.Debugf("OK validation using CA [%s] with CommonName '%s', SerialNumber '%s'", ca.ID, ca.Subject.CommonName, ca.SerialNumber)
+				validCertificate = true
+				validationCA = ca
+				break
+			}
+		}
+
+		clientSN := helpers.SerialNumberToString(clientCert.SerialNumber)
+
+		if !validCertificate {
+			lFunc.Errorf("invalid enrollment. used certificate not authorized for this DMS. certificate has SerialNumber %s issued by CA %s", clientSN, clientCert.Issuer.CommonName)
+			return nil, errs.ErrDMSEnrollInvalidCert
+		}
+
+		//checks against Lamassu, external OCSP or CRL
+		couldCheckRevocation, isRevoked, err := svc.checkCertificateRevocation(ctx, clientCert, (*x509.Certificate)(validationCA.Certificate.Certificate))
+		if err != nil {
+			lFunc.Errorf("error while checking certificate revocation status: %s", err)
+			return nil, err
+		}
+
+		if couldCheckRevocation {
+			if isRevoked {
+				return nil, errs.ErrDMSEnrollRevokedCert
+			}
+			lFunc.Infof("certificate is not revoked")
+		} else {
+			lFunc.Infof("could not verify certificate expiration. Assuming certificate as not-revoked")
+		}
+
+	} else if estAuthOptions.AuthMode == models.ESTAuthMode(identityextractors.IdentityExtractorBootstrapToken) {
+		err := svc.consumeBootstrapToken(ctx, dms, deviceID)
+		if err != nil {
+			return nil, err
+		}
+	} else if estAuthOptions.AuthMode == models.ESTAuthMode(identityextractors.IdentityExtractorNoAuth) {
+		lFunc.Warnf("DMS %s is configured with NoAuth. Allowing enrollment", dms.ID)
+	}
+
+	return clientCert, nil
+}
+
+func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, error) {
+	crt, err := svc.reenroll(ctx, csr, aps)
+	svc.recordEnrollment(ctx, aps, models.EnrollmentOperationReenroll, err)
+	return crt, err
+}
+
+func (svc DMSManagerServiceBackend) reenroll(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	lFunc.Debugf("checking if DMS '%s' exists", aps)
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{
+		ID: aps,
+	})
+	if err != nil {
+		lFunc.Errorf("aborting reenrollment process for device '%s'. Could not get DMS '%s': %s", csr.Subject.CommonName, aps, err)
+		return nil, errs.ErrDMSNotFound
+	}
+
+	if dms.Status == models.PendingApprovalDMSStatus {
+		lFunc.Errorf("aborting reenrollment process for device '%s'. DMS '%s' registration is pending approval", csr.Subject.CommonName, aps)
+		return nil, errs.ErrDMSPendingApproval
+	}
+
+	if dms.Settings.EnrollmentSettings.EnrollmentProtocol != models.EST {
+		lFunc.Errorf("aborting reenrollment process for device '%s'. DMS '%s' doesn't support EST Protocol", csr.Subject.CommonName, aps)
+		return nil, errs.ErrDMSOnlyEST
+	}
+
+	deviceID, err := helpers.NormalizeDeviceID(csr.Subject.CommonName, dms.Settings.EnrollmentSettings.DeviceIDRules)
+	if err != nil {
+		lFunc.Errorf("aborting reenrollment process for device '%s'. Invalid device ID: %s", csr.Subject.CommonName, err)
+		return nil, errs.ErrDeviceInvalidID
+	}
+
+	enrollCAID := dms.Settings.EnrollmentSettings.EnrollmentCA
+	enrollCA, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{
+		CAID: enrollCAID,
+	})
+	if err != nil {
+		lFunc.Errorf("could not get enroll CA with ID=%s: %s", enrollCAID, err)
+		return nil, &errs.DMSEnrollCAError{Err: err}
+	}
+
+	// Devices enrolled with a bootstrap token reenroll presenting the certificate issued by the DMS
+	authMode := dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthMode
+	if authMode == models.ESTAuthMode(identityextractors.IdentityExtractorClientCertificate) || authMode == models.ESTAuthMode(identityextractors.IdentityExtractorBootstrapToken) {
+		clientCert, hasValue := ctx.Value(string(identityextractors.IdentityExtractorClientCertificate)).(*x509.Certificate)
+		if !hasValue {
+			lFunc.Errorf("aborting reenrollment process for device '%s'. No client certificate was presented", csr.Subject.CommonName)
+			return nil, errs.ErrDMSAuthModeNotSupported
+		}
+
+		lFunc.Debugf("presented client certificate has CN=%s and SN=%s issued by CA with CommonName '%s'", clientCert.Subject.CommonName, helpers.SerialNumberToString(clientCert.SerialNumber), clientCert.Issuer.CommonName)
+
+		validCertificate := false
+		var validationCA *x509.Certificate
+
+		//check if certificate is a certificate issued by Enroll CA
+		lFunc.Debugf("validating client certificate using EST Enrollment CA witch has ID=%s CN=%s SN=%s", enrollCAID, enrollCA.Certificate.Subject.CommonName, enrollCA.SerialNumber)
+		err = helpers.ValidateCertificate((*x509.Certificate)(enrollCA.Certificate.Certificate), clientCert, false)
+		if err != nil {
 			lFunc.Warnf("invalid validation using enroll CA: %s", err)
 		} else {
 			lFunc.Debugf("OK validation using enroll")
@@ -511,14 +927,15 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 			return nil, errs.ErrDMSEnrollInvalidCert
 		}
 
-		//Check if EXPIRED
+		//Check if EXPIRED. The clock skew tolerance applies to devices presenting a just expired certificate
 		now := time.Now()
-		if now.After(clientCert.NotAfter) {
+		clockSkew := time.Duration(dms.Settings.EnrollmentSettings.EnrollmentOptionsESTRFC7030.AuthOptionsMTLS.ClockSkewTolerance)
+		if now.Add(-clockSkew).After(clientCert.NotAfter) {
 			if dms.Settings.ReEnrollmentSettings.EnableExpiredRenewal {
 				lFunc.Infof("presented an expired certificate by %s, but DMS allows expired renewals. Continuing", now.Sub(clientCert.NotBefore))
 			} else {
 				lFunc.Errorf("aborting reenrollment. Device has a valid but expired certificate")
-				return nil, fmt.Errorf("expired certificate")
+				return nil, errs.ErrDMSEnrollExpiredCert
 			}
 		}
 
@@ -532,7 +949,7 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 		if couldCheckRevocation {
 			if isRevoked {
 				lFunc.Errorf("certificate is revoked")
-				return nil, fmt.Errorf("certificate is revoked")
+				return nil, errs.ErrDMSEnrollRevokedCert
 			}
 			lFunc.Infof("certificate is not revoked")
 		} else {
@@ -543,20 +960,30 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 		lFunc.Warnf("allowing reenroll: using NO AUTH mode")
 	}
 
+	err = svc.verifySecureElement(ctx, dms, csr)
+	if err != nil {
+		return nil, err
+	}
+
+	err = svc.verifyTPMAttestation(ctx, dms, csr)
+	if err != nil {
+		return nil, err
+	}
+
 	var device *models.Device
 	device, err = svc.deviceManagerCli.GetDeviceByID(ctx, GetDeviceByIDInput{
-		ID: csr.Subject.CommonName,
+		ID: deviceID,
 	})
 	if err != nil {
 		switch err {
 		case errs.ErrDeviceNotFound:
-			lFunc.Debugf("device '%s' doesn't exist", csr.Subject.CommonName)
+			lFunc.Debugf("device '%s' doesn't exist", deviceID)
 		default:
-			lFunc.Errorf("could not get device '%s': %s", csr.Subject.CommonName, err)
+			lFunc.Errorf("could not get device '%s': %s", deviceID, err)
 			return nil, err
 		}
 	} else {
-		lFunc.Debugf("device '%s' does exist", csr.Subject.CommonName)
+		lFunc.Debugf("device '%s' does exist", deviceID)
 	}
 
 	currentDeviceCertSN := device.IdentitySlot.Secrets[device.IdentitySlot.ActiveVersion]
@@ -596,7 +1023,7 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 		for _, pair2Comp := range pairsToCompare {
 			if pair2Comp.crtClaim != pair2Comp.csrClaim {
 				lFunc.Errorf("current device certificate and csr differ in claim %s. crt got '%s' while csr got '%s'", pair2Comp.claim, pair2Comp.crtClaim, pair2Comp.csrClaim)
-				return nil, fmt.Errorf("invalid RawSubject bytes")
+				return nil, errs.ErrDMSReenrollSubjectMismatch
 			}
 		}
 
@@ -618,29 +1045,59 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 	//Check if current cert is REVOKED
 	if currentDeviceCert.Status == models.StatusRevoked {
 		lFunc.Warnf("aborting reenrollment as certificate %s is revoked with status code %s", currentDeviceCertSN, currentDeviceCert.RevocationReason)
-		return nil, fmt.Errorf("revoked certificate")
+		return nil, errs.ErrDMSEnrollRevokedCert
 	}
 
-	//Check if Not in DMS ReEnroll Window
+	//Check if Not in DMS ReEnroll Window. Devices flagged by a FORCE_REENROLL bulk action skip the window
+	forceReenroll, _ := device.Metadata[models.DeviceMetadataForceReenrollKey].(bool)
 	if comparisonTimeThreshold.After(now) {
-		lFunc.Errorf("aborting reenrollment. Device has a valid certificate but DMS reenrollment window does not allow reenrolling with %s delta. Update DMS or wait until the reenrollment window is open", models.TimeDuration(now.Sub(comparisonTimeThreshold)).String())
-		return nil, fmt.Errorf("invalid reenroll window")
+		if !forceReenroll {
+			lFunc.Errorf("aborting reenrollment. Device has a valid certificate but DMS reenrollment window does not allow reenrolling with %s delta. Update DMS or wait until the reenrollment window is open", models.TimeDuration(now.Sub(comparisonTimeThreshold)).String())
+			return nil, errs.ErrDMSReenrollWindowNotOpen
+		}
+
+		lFunc.Infof("device %s is flagged to force its reenrollment. DMS reenrollment window is ignored", device.ID)
+	}
+
+	signingProfile, certificateProfileID, deviceClass := helpers.GetDeviceIssuanceProfile(dms.Settings.EnrollmentSettings, *device)
+	if deviceClass != nil {
+		lFunc.Debugf("device '%s' belongs to device class '%s' of DMS '%s'", deviceID, deviceClass.Name, dms.ID)
 	}
 
 	crt, err := svc.caClient.SignCertificate(ctx, SignCertificateInput{
-		CAID:         dms.Settings.EnrollmentSettings.EnrollmentCA,
-		CertRequest:  (*models.X509CertificateRequest)(csr),
-		Subject:      nil,
-		SignVerbatim: true,
+		CAID:                 dms.Settings.EnrollmentSettings.EnrollmentCA,
+		CertRequest:          (*models.X509CertificateRequest)(csr),
+		Subject:              nil,
+		SignVerbatim:         true,
+		SigningProfile:       &signingProfile,
+		CertificateProfileID: certificateProfileID,
+		IssuanceContext: &models.CertificateIssuanceContext{
+			DMSID:     dms.ID,
+			DeviceID:  deviceID,
+			RequestID: helpers.GetRequestID(ctx),
+		},
 	})
 	if err != nil {
-		lFunc.Errorf("could not issue certificate for device '%s': %s", csr.Subject.CommonName, err)
-		return nil, err
+		lFunc.Errorf("could not issue certificate for device '%s': %s", deviceID, err)
+		return nil, &errs.DMSEnrollCAError{Err: err}
 	}
 
 	//detach certificate from meta
 	delete(currentDeviceCert.Metadata, models.CAAttachedToDeviceKey)
 	delete(currentDeviceCert.Metadata, models.CAMetadataMonitoringExpirationDeltasKey)
+
+	supersededRevocation := dms.Settings.ReEnrollmentSettings.SupersededRevocation
+	deferRevocation := supersededRevocation.Enabled && supersededRevocation.GracePeriod > 0 && currentDeviceCert.Status == models.StatusActive
+	if deferRevocation {
+		revokeAfter := now.Add(time.Duration(supersededRevocation.GracePeriod))
+		lFunc.Infof("superseded certificate %s will be revoked after %s grace period (%s)", currentDeviceCertSN, supersededRevocation.GracePeriod.String(), revokeAfter.UTC().Format("2006-01-02T15:04:05Z07:00"))
+		currentDeviceCert.Metadata[models.DMSMetadataSupersededRevocationKey] = models.DMSMetadataSupersededRevocation{
+			DMSID:        dms.ID,
+			SupersededBy: crt.SerialNumber,
+			RevokeAfter:  revokeAfter,
+		}
+	}
+
 	_, err = svc.caClient.UpdateCertificateMetadata(ctx, UpdateCertificateMetadataInput{
 		SerialNumber: currentDeviceCertSN,
 		Metadata:     currentDeviceCert.Metadata,
@@ -651,7 +1108,7 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 	}
 
 	//revoke superseded cert if active. Don't try revoking expired or already revoked since is not a valid transition for the CA service.
-	if currentDeviceCert.Status == models.StatusActive {
+	if currentDeviceCert.Status == models.StatusActive && !deferRevocation {
 		_, err = svc.caClient.UpdateCertificateStatus(ctx, UpdateCertificateStatusInput{
 			SerialNumber:     currentDeviceCertSN,
 			NewStatus:        models.StatusRevoked,
@@ -672,15 +1129,36 @@ func (svc DMSManagerServiceBackend) Reenroll(ctx context.Context, csr *x509.Cert
 		return nil, err
 	}
 
+	if forceReenroll {
+		delete(device.Metadata, models.DeviceMetadataForceReenrollKey)
+		_, err = svc.deviceManagerCli.UpdateDeviceMetadata(ctx, UpdateDeviceMetadataInput{
+			ID:       device.ID,
+			Metadata: device.Metadata,
+		})
+		if err != nil {
+			lFunc.Warnf("could not clear force reenrollment flag of device %s: %s", device.ID, err)
+		}
+	}
+
 	return (*x509.Certificate)(crt.Certificate), nil
 }
 
+// ServerKeyGen generates the device key (RFC 7030 4.4) and enrolls it with the subject and extensions requested in
+// the CSR. The key type and size are read from the ESTServerKeyGenKeyType and ESTServerKeyGenBitSize context values
+// (RSA 4096 by default). The returned key is either a *rsa.PrivateKey or a *ecdsa.PrivateKey. The device is
+// authenticated with the DMS before the key is generated.
+//
+// Returned Error Codes:
+//   - ErrDMSServerKeyGenInvalidKeySize
+//     The requested key size is not supported for the key type.
 func (svc DMSManagerServiceBackend) ServerKeyGen(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, interface{}, error) {
 	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
 
-	var privKey any
+	var signer crypto.Signer
 	var err error
 
+	keyID := fmt.Sprintf("serverkeygen-%s", goid.NewV4UUID())
+
 	keyType, hasValue := ctx.Value(string(models.ESTServerKeyGenKeyType)).(x509.PublicKeyAlgorithm)
 	if !hasValue {
 		lFunc.Debugf("no valid key type found. Defaulting to RSA")
@@ -689,47 +1167,246 @@ func (svc DMSManagerServiceBackend) ServerKeyGen(ctx context.Context, csr *x509.
 
 	keySize, hasKeySizeValue := ctx.Value(string(models.ESTServerKeyGenBitSize)).(int)
 
+	var curve elliptic.Curve
 	switch keyType {
 	case x509.RSA:
-		if hasKeySizeValue {
-			if keySize%1024 != 0 {
-				lFunc.Warnf("invalid key size of %d for RSA. Defaulting to 4096", keySize)
-				keySize = 4096
-			}
-		} else {
+		if !hasKeySizeValue {
 			lFunc.Debugf("no key size specified. Defaulting to RSA 4096")
 			keySize = 4096
 		}
 
-		privKey, err = rsa.GenerateKey(rand.Reader, keySize)
+		switch keySize {
+		case 2048, 3072, 4096:
+		default:
+			lFunc.Errorf("invalid key size of %d for RSA", keySize)
+			return nil, nil, errs.ErrDMSServerKeyGenInvalidKeySize
+		}
 	case x509.ECDSA:
-		var curve elliptic.Curve
-		if hasKeySizeValue {
-			switch keySize {
-			case 224:
-				curve = elliptic.P224()
-			case 256:
-				curve = elliptic.P256()
-			case 384:
-				curve = elliptic.P384()
-			case 521:
-				curve = elliptic.P521()
-			default:
-				lFunc.Warnf("invalid key size of %d for ECDSA. Defaulting to 256 curve", keySize)
-				curve = elliptic.P256()
-			}
-		} else {
+		if !hasKeySizeValue {
 			lFunc.Debugf("no key size specified. Defaulting to ECDSA 256 curve")
-			curve = elliptic.P256()
+			keySize = 256
 		}
 
-		privKey, err = ecdsa.GenerateKey(curve, rand.Reader)
+		switch keySize {
+		case 224:
+			curve = elliptic.P224()
+		case 256:
+			curve = elliptic.P256()
+		case 384:
+			curve = elliptic.P384()
+		case 521:
+			curve = elliptic.P521()
+		default:
+			lFunc.Errorf("invalid key size of %d for ECDSA", keySize)
+			return nil, nil, errs.ErrDMSServerKeyGenInvalidKeySize
+		}
 	default:
 		return nil, nil, fmt.Errorf("unsupported key type %s", keyType)
 	}
 
-	fmt.Println(privKey, err)
-	return nil, nil, fmt.Errorf("TODO")
+	// authenticate the device before generating its key, so unauthenticated clients can not make the server generate keys
+	auth, err := svc.authenticateServerKeyGen(ctx, csr, aps)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	switch keyType {
+	case x509.RSA:
+		if svc.keyGenEngine != nil {
+			signer, err = svc.keyGenEngine.CreateRSAPrivateKey(keySize, keyID)
+		} else {
+			signer, err = rsa.GenerateKey(rand.Reader, keySize)
+		}
+	case x509.ECDSA:
+		if svc.keyGenEngine != nil {
+			signer, err = svc.keyGenEngine.CreateECDSAPrivateKey(curve, keyID)
+		} else {
+			signer, err = ecdsa.GenerateKey(curve, rand.Reader)
+		}
+	}
+	if err != nil {
+		lFunc.Errorf("could not generate %s key for device '%s': %s", keyType, csr.Subject.CommonName, err)
+		return nil, nil, err
+	}
+
+	// the key is returned to the device, so it must be exportable
+	switch signer.(type) {
+	case *rsa.PrivateKey, *ecdsa.PrivateKey:
+	default:
+		lFunc.Errorf("the key generation crypto engine does not export the private keys")
+		svc.deleteServerGeneratedKey(ctx, keyID)
+		return nil, nil, fmt.Errorf("server generated keys can not be exported")
+	}
+
+	// the CSR sent by the device is signed with a key other than the generated one, so a new one is built
+	keyCsrDer, err := x509.CreateCertificateRequest(rand.Reader, &x509.CertificateRequest{
+		Subject:         csr.Subject,
+		ExtraExtensions: csr.Extensions,
+	}, signer)
+	if err != nil {
+		lFunc.Errorf("could not create CSR with the generated key for device '%s': %s", csr.Subject.CommonName, err)
+		svc.deleteServerGeneratedKey(ctx, keyID)
+		return nil, nil, err
+	}
+
+	keyCsr, err := x509.ParseCertificateRequest(keyCsrDer)
+	if err != nil {
+		svc.deleteServerGeneratedKey(ctx, keyID)
+		return nil, nil, err
+	}
+
+	crt, err := svc.service.Enroll(context.WithValue(ctx, authenticatedEnrollmentCtxKey{}, auth), keyCsr, aps)
+	if err != nil {
+		svc.deleteServerGeneratedKey(ctx, keyID)
+		return nil, nil, err
+	}
+
+	if svc.keyGenEngine != nil {
+		lFunc.Infof("generated key of certificate %s stored in crypto engine with ID %s", helpers.SerialNumberToString(crt.SerialNumber), keyID)
+	}
+
+	return crt, signer, nil
+}
+
+// authenticateServerKeyGen authenticates the device of the CSR with the EST auth mode of the DMS.
+func (svc DMSManagerServiceBackend) authenticateServerKeyGen(ctx context.Context, csr *x509.CertificateRequest, aps string) (*enrollmentAuthentication, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{
+		ID: aps,
+	})
+	if err != nil {
+		lFunc.Errorf("aborting server key generation for device '%s'. Could not get DMS '%s': %s", csr.Subject.CommonName, aps, err)
+		return nil, errs.ErrDMSNotFound
+	}
+
+	if dms.Status == models.PendingApprovalDMSStatus {
+		lFunc.Errorf("aborting server key generation for device '%s'. DMS '%s' registration is pending approval", csr.Subject.CommonName, aps)
+		return nil, errs.ErrDMSPendingApproval
+	}
+
+	if dms.Settings.EnrollmentSettings.EnrollmentProtocol != models.EST {
+		lFunc.Errorf("aborting server key generation for device '%s'. DMS '%s' doesn't support EST Protocol", csr.Subject.CommonName, aps)
+		return nil, errs.ErrDMSOnlyEST
+	}
+
+	deviceID, err := helpers.NormalizeDeviceID(csr.Subject.CommonName, dms.Settings.EnrollmentSettings.DeviceIDRules)
+	if err != nil {
+		lFunc.Errorf("aborting server key generation for device '%s'. Invalid device ID: %s", csr.Subject.CommonName, err)
+		return nil, errs.ErrDeviceInvalidID
+	}
+
+	clientCert, err := svc.authenticateEnrollment(ctx, dms, csr, deviceID)
+	if err != nil {
+		return nil, err
+	}
+
+	return &enrollmentAuthentication{clientCert: clientCert}, nil
+}
+
+// deleteServerGeneratedKey removes the key generated for a failed enrollment from the key generation crypto engine.
+func (svc DMSManagerServiceBackend) deleteServerGeneratedKey(ctx context.Context, keyID string) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if svc.keyGenEngine == nil {
+		return
+	}
+
+	deleter, ok := svc.keyGenEngine.(keyDeleter)
+	if !ok {
+		lFunc.Warnf("key generation crypto engine does not support key removal. key %s must be removed manually", keyID)
+		return
+	}
+
+	err := deleter.DeleteKey(keyID)
+	if err != nil {
+		lFunc.Errorf("could not remove generated key %s after a failed enrollment: %s", keyID, err)
+	}
+}
+
+// verifySecureElement checks the secure element presented in the CSR against the DMS manufacturer allow-list.
+// The enrollment is rejected if the allow-list service can not be reached.
+func (svc DMSManagerServiceBackend) verifySecureElement(ctx context.Context, dms *models.DMS, csr *x509.CertificateRequest) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	settings := dms.Settings.EnrollmentSettings.SecureElementVerification
+	if !settings.Enabled {
+		return nil
+	}
+
+	identity, err := helpers.GetSecureElementIdentity(csr, settings.ExtensionOID)
+	if err != nil {
+		lFunc.Errorf("invalid secure element extension in CSR '%s': %s", csr.Subject.CommonName, err)
+		return errs.ErrDMSSecureElementNotAllowed
+	}
+
+	if identity == nil {
+		lFunc.Errorf("CSR '%s' does not include the secure element extension %s required by DMS '%s'", csr.Subject.CommonName, settings.ExtensionOID, dms.ID)
+		return errs.ErrDMSSecureElementMissing
+	}
+
+	identity.DMSID = dms.ID
+	lFunc.Debugf("checking secure element %s against allow-list %s", identity.SerialNumber, settings.AllowListURL)
+	response, err := external_clients.QuerySecureElementAllowList(ctx, settings.AllowListURL, *identity)
+	if err != nil {
+		lFunc.Errorf("could not query secure element allow-list %s: %s", settings.AllowListURL, err)
+		return err
+	}
+
+	if !response.Allowed {
+		lFunc.Errorf("secure element %s is not allowed by the manufacturer allow-list", identity.SerialNumber)
+		return errs.ErrDMSSecureElementNotAllowed
+	}
+
+	if response.PublicKeySHA256 != "" && !strings.EqualFold(response.PublicKeySHA256, identity.PublicKeySHA256) {
+		lFunc.Errorf("CSR public key does not match the key provisioned into secure element %s. Possible cloned key", identity.SerialNumber)
+		return errs.ErrDMSSecureElementNotAllowed
+	}
+
+	lFunc.Infof("secure element %s verified", identity.SerialNumber)
+	return nil
+}
+
+// verifyTPMAttestation checks the TPM attestation presented in the CSR against the DMS manufacturer CAs. Manufacturer
+// CAs that can not be obtained are skipped.
+func (svc DMSManagerServiceBackend) verifyTPMAttestation(ctx context.Context, dms *models.DMS, csr *x509.CertificateRequest) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	settings := dms.Settings.EnrollmentSettings.TPMAttestation
+	if !settings.Enabled {
+		return nil
+	}
+
+	evidence, err := helpers.GetTPMAttestationEvidence(csr, settings.ExtensionOID)
+	if err != nil {
+		lFunc.Errorf("invalid TPM attestation extension in CSR '%s': %s", csr.Subject.CommonName, err)
+		return errs.ErrDMSTPMAttestationInvalid
+	}
+
+	if evidence == nil {
+		lFunc.Errorf("CSR '%s' does not include the TPM attestation extension %s required by DMS '%s'", csr.Subject.CommonName, settings.ExtensionOID, dms.ID)
+		return errs.ErrDMSTPMAttestationMissing
+	}
+
+	manufacturerCAs := []*x509.Certificate{}
+	for _, caID := range settings.ManufacturerCAs {
+		ca, err := svc.caClient.GetCAByID(ctx, GetCAByIDInput{CAID: caID})
+		if err != nil {
+			lFunc.Warnf("could not obtain manufacturer CA '%s'. Skipping: %s", caID, err)
+			continue
+		}
+
+		manufacturerCAs = append(manufacturerCAs, (*x509.Certificate)(ca.Certificate.Certificate))
+	}
+
+	ek, err := helpers.VerifyTPMAttestation(csr, evidence, manufacturerCAs, settings.RequireAKCertificate)
+	if err != nil {
+		lFunc.Errorf("TPM attestation of CSR '%s' rejected: %s", csr.Subject.CommonName, err)
+		return errs.ErrDMSTPMAttestationInvalid
+	}
+
+	lFunc.Infof("TPM attestation verified with EK certificate %s issued by '%s'", helpers.SerialNumberToString(ek.SerialNumber), ek.Issuer.CommonName)
+	return nil
 }
 
 // returns if the given certificate COULD BE checked for revocation (true means that it could be checked), and if it is revoked (true) or not (false)
@@ -910,3 +1587,345 @@ func (svc DMSManagerServiceBackend) BindIdentityToDevice(ctx context.Context, in
 		Device:      device,
 	}, nil
 }
+
+type RevokeSupersededCertificateInput struct {
+	SerialNumber string `validate:"required"`
+}
+
+// RevokeSupersededCertificate revokes, with reason 'superseded', a certificate replaced by a reenrollment whose
+// grace period has already elapsed. Certificates without a pending superseded revocation are left untouched.
+func (svc DMSManagerServiceBackend) RevokeSupersededCertificate(ctx context.Context, input RevokeSupersededCertificateInput) (*models.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+		SerialNumber: input.SerialNumber,
+	})
+	if err != nil {
+		lFunc.Errorf("could not get certificate %s: %s", input.SerialNumber, err)
+		return nil, err
+	}
+
+	var pendingRevocation models.DMSMetadataSupersededRevocation
+	hasKey, err := helpers.GetMetadataToStruct(crt.Metadata, models.DMSMetadataSupersededRevocationKey, &pendingRevocation)
+	if err != nil {
+		lFunc.Errorf("could not decode metadata with key %s: %s", models.DMSMetadataSupersededRevocationKey, err)
+		return nil, err
+	}
+
+	if !hasKey {
+		lFunc.Errorf("certificate %s has no pending superseded revocation", input.SerialNumber)
+		return nil, errs.ErrDMSNoPendingSupersededRevocation
+	}
+
+	if time.Now().Before(pendingRevocation.RevokeAfter) {
+		lFunc.Errorf("certificate %s grace period ends at %s", input.SerialNumber, pendingRevocation.RevokeAfter.UTC().Format("2006-01-02T15:04:05Z07:00"))
+		return nil, errs.ErrDMSSupersededGracePeriod
+	}
+
+	// The pending revocation is only removed once the certificate is revoked, so failed revocations are retried.
+	if crt.Status == models.StatusActive {
+		lFunc.Infof("revoking superseded certificate %s. superseded by %s", input.SerialNumber, pendingRevocation.SupersededBy)
+		crt, err = svc.caClient.UpdateCertificateStatus(ctx, UpdateCertificateStatusInput{
+			SerialNumber:     input.SerialNumber,
+			NewStatus:        models.StatusRevoked,
+			RevocationReason: ocsp.Superseded,
+		})
+		if err != nil {
+			lFunc.Errorf("could not update superseded certificate status to revoked %s: %s", input.SerialNumber, err)
+			return nil, err
+		}
+	} else {
+		lFunc.Warnf("superseded certificate %s is already in %s status. skipping revocation", input.SerialNumber, crt.Status)
+	}
+
+	delete(crt.Metadata, models.DMSMetadataSupersededRevocationKey)
+	crt, err = svc.caClient.UpdateCertificateMetadata(ctx, UpdateCertificateMetadataInput{
+		SerialNumber: crt.SerialNumber,
+		Metadata:     crt.Metadata,
+	})
+	if err != nil {
+		lFunc.Errorf("could not update superseded certificate metadata %s: %s", input.SerialNumber, err)
+		return nil, err
+	}
+
+	return crt, nil
+}
+
+type RevokeDeviceCertificateInput struct {
+	DMSID            string `validate:"required"`
+	SerialNumber     string `validate:"required"`
+	RevocationReason models.RevocationReason
+}
+
+// RevokeDeviceCertificate lets the owners of a DMS revoke the certificates of its devices without administrator
+// rights over the PKI. The caller must have a verified identity listed in the DMS self-service owners, and the
+// certificate must belong to a device of the DMS: either the device it is bound to, or the device recorded in its
+// issuance context, must be owned by the DMS.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrDMSSelfServiceForbidden
+//     The caller is not a verified owner of the DMS.
+//   - ErrCertificateNotFound
+//     The certificate does not exist.
+//   - ErrDMSCertificateNotOwned
+//     The certificate was not issued for a device of the DMS.
+func (svc DMSManagerServiceBackend) RevokeDeviceCertificate(ctx context.Context, input RevokeDeviceCertificateInput) (*models.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	caller := verifiedCallerID(ctx)
+	if caller == "" || !slices.Contains(dms.Settings.SelfServiceSettings.Owners, caller) {
+		lFunc.Errorf("'%s' is not a verified owner of DMS %s", callerID(ctx), dms.ID)
+		return nil, errs.ErrDMSSelfServiceForbidden
+	}
+
+	crt, err := svc.caClient.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
+		SerialNumber: input.SerialNumber,
+	})
+	if err != nil {
+		lFunc.Errorf("could not get certificate %s: %s", input.SerialNumber, err)
+		return nil, err
+	}
+
+	owned, err := svc.isCertificateIssuedForDMS(ctx, crt, dms.ID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !owned {
+		lFunc.Errorf("certificate %s was not issued for a device of DMS %s", input.SerialNumber, dms.ID)
+		return nil, errs.ErrDMSCertificateNotOwned
+	}
+
+	lFunc.Infof("certificate %s of DMS %s revoked by owner '%s'", input.SerialNumber, dms.ID, caller)
+	crt, err = svc.caClient.UpdateCertificateStatus(ctx, UpdateCertificateStatusInput{
+		SerialNumber:     input.SerialNumber,
+		NewStatus:        models.StatusRevoked,
+		RevocationReason: input.RevocationReason,
+	})
+	if err != nil {
+		lFunc.Errorf("could not update certificate status to revoked %s: %s", input.SerialNumber, err)
+		return nil, err
+	}
+
+	return crt, nil
+}
+
+// isCertificateIssuedForDMS checks the DMS owning the device the certificate is bound to. Certificates not bound
+// to any device fall back to the device recorded in their issuance context, which must still be owned by the DMS:
+// the context alone only tells on behalf of which DMS the certificate was requested.
+func (svc DMSManagerServiceBackend) isCertificateIssuedForDMS(ctx context.Context, crt *models.Certificate, dmsID string) (bool, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	binding, err := svc.deviceManagerCli.GetDeviceByCertificate(ctx, GetDeviceByCertificateInput{
+		SerialNumber: crt.SerialNumber,
+	})
+	if err == nil {
+		return binding.Device.DMSOwner == dmsID, nil
+	} else if err != errs.ErrDeviceCertificateNotBound {
+		lFunc.Errorf("could not get device of certificate %s: %s", crt.SerialNumber, err)
+		return false, err
+	}
+
+	var issuanceCtx models.CertificateIssuanceContext
+	hasKey, err := helpers.GetMetadataToStruct(crt.Metadata, models.CertificateMetadataIssuanceContextKey, &issuanceCtx)
+	if err != nil {
+		lFunc.Errorf("could not decode metadata with key %s: %s", models.CertificateMetadataIssuanceContextKey, err)
+		return false, err
+	}
+
+	if !hasKey || issuanceCtx.DMSID != dmsID || issuanceCtx.DeviceID == "" {
+		return false, nil
+	}
+
+	device, err := svc.deviceManagerCli.GetDeviceByID(ctx, GetDeviceByIDInput{ID: issuanceCtx.DeviceID})
+	if err == errs.ErrDeviceNotFound {
+		return false, nil
+	} else if err != nil {
+		lFunc.Errorf("could not get device %s: %s", issuanceCtx.DeviceID, err)
+		return false, err
+	}
+
+	return device.DMSOwner == dmsID, nil
+}
+
+type CreateACMEEABKeyInput struct {
+	DMSID string `validate:"required"`
+}
+
+// CreateACMEEABKey issues a new ACME External Account Binding key for the DMS. The HMAC key is only
+// returned by this method, so it must be handed over to the ACME client operator right away.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrDMSACMEEABNotConfigured
+//     The DMS Manager has no EAB secret configured.
+func (svc DMSManagerServiceBackend) CreateACMEEABKey(ctx context.Context, input CreateACMEEABKeyInput) (*models.DMSACMEEABCredentials, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	if len(svc.acmeEABSecret) == 0 {
+		lFunc.Errorf("ACME external account binding secret is not configured")
+		return nil, errs.ErrDMSACMEEABNotConfigured
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	keys, err := helpers.GetACMEEABKeys(*dms)
+	if err != nil {
+		lFunc.Errorf("could not decode metadata with key %s: %s", models.DMSMetadataACMEEABKeysKey, err)
+		return nil, err
+	}
+
+	key := models.DMSACMEEABKey{
+		KeyID:     goid.NewV4UUID().String(),
+		DMSID:     dms.ID,
+		CreatedBy: callerID(ctx),
+		CreatedAt: time.Now(),
+	}
+
+	if dms.Metadata == nil {
+		dms.Metadata = map[string]any{}
+	}
+	dms.Metadata[models.DMSMetadataACMEEABKeysKey] = append(keys, key)
+
+	_, err = svc.dmsStorage.Update(ctx, dms)
+	if err != nil {
+		lFunc.Errorf("could not store EAB key %s for DMS %s: %s", key.KeyID, dms.ID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("ACME EAB key %s issued for DMS %s", key.KeyID, dms.ID)
+	return &models.DMSACMEEABCredentials{
+		DMSACMEEABKey: key,
+		HMACKey:       base64.RawURLEncoding.EncodeToString(helpers.DeriveACMEEABHMACKey(svc.acmeEABSecret, dms.ID, key.KeyID)),
+	}, nil
+}
+
+type GetACMEEABKeysInput struct {
+	DMSID string `validate:"required"`
+}
+
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) GetACMEEABKeys(ctx context.Context, input GetACMEEABKeysInput) ([]models.DMSACMEEABKey, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	keys, err := helpers.GetACMEEABKeys(*dms)
+	if err != nil {
+		lFunc.Errorf("could not decode metadata with key %s: %s", models.DMSMetadataACMEEABKeysKey, err)
+		return nil, err
+	}
+
+	return keys, nil
+}
+
+type RevokeACMEEABKeyInput struct {
+	DMSID string `validate:"required"`
+	KeyID string `validate:"required"`
+}
+
+// RevokeACMEEABKey revokes an EAB key of the DMS. The ACME front-end rejects new accounts and orders
+// from the accounts bound to a revoked key.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrDMSACMEEABKeyNotFound
+//     The DMS has not issued an EAB key with the specified ID.
+//   - ErrDMSACMEEABKeyRevoked
+//     The EAB key is already revoked.
+func (svc DMSManagerServiceBackend) RevokeACMEEABKey(ctx context.Context, input RevokeACMEEABKeyInput) (*models.DMSACMEEABKey, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{ID: input.DMSID})
+	if err != nil {
+		lFunc.Errorf("could not get DMS %s: %s", input.DMSID, err)
+		return nil, err
+	}
+
+	keys, err := helpers.GetACMEEABKeys(*dms)
+	if err != nil {
+		lFunc.Errorf("could not decode metadata with key %s: %s", models.DMSMetadataACMEEABKeysKey, err)
+		return nil, err
+	}
+
+	idx := slices.IndexFunc(keys, func(key models.DMSACMEEABKey) bool { return key.KeyID == input.KeyID })
+	if idx < 0 {
+		lFunc.Errorf("DMS %s has no EAB key %s", dms.ID, input.KeyID)
+		return nil, errs.ErrDMSACMEEABKeyNotFound
+	}
+
+	if keys[idx].RevokedAt != nil {
+		lFunc.Errorf("EAB key %s already revoked", input.KeyID)
+		return nil, errs.ErrDMSACMEEABKeyRevoked
+	}
+
+	now := time.Now()
+	keys[idx].RevokedAt = &now
+	dms.Metadata[models.DMSMetadataACMEEABKeysKey] = keys
+
+	_, err = svc.dmsStorage.Update(ctx, dms)
+	if err != nil {
+		lFunc.Errorf("could not revoke EAB key %s for DMS %s: %s", input.KeyID, dms.ID, err)
+		return nil, err
+	}
+
+	lFunc.Infof("ACME EAB key %s of DMS %s revoked", input.KeyID, dms.ID)
+	return &keys[idx], nil
+}
@@ -0,0 +1,112 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"fmt"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+type PreregisterDevicesInput struct {
+	DMSID string `validate:"required"`
+	// DeviceIDs are the serial numbers or CommonNames of the devices. They are normalized with the DMS device ID rules.
+	DeviceIDs []string `validate:"required,min=1"`
+}
+
+// PreregisterDevices claims the devices for the DMS by registering them in the Device Manager with the DMS device
+// provisioning profile. DMSs configured with PRE_REGISTRATION only enroll the devices they have claimed. Invalid or
+// already claimed devices are reported as failed without aborting the rest of the devices.
+//
+// Returned Error Codes:
+//   - ErrDMSNotFound
+//     The specified DMS can not be found.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc DMSManagerServiceBackend) PreregisterDevices(ctx context.Context, input PreregisterDevicesInput) (*models.DevicePreregistration, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := dmsValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	dms, err := svc.service.GetDMSByID(ctx, GetDMSByIDInput{
+		ID: input.DMSID,
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	output := &models.DevicePreregistration{
+		Registered:        []string{},
+		AlreadyRegistered: []string{},
+		Failed:            map[string]string{},
+	}
+
+	rules := dms.Settings.EnrollmentSettings.DeviceIDRules
+	seen := map[string]bool{}
+	for _, rawID := range input.DeviceIDs {
+		deviceID, err := helpers.NormalizeDeviceID(rawID, rules)
+		if err != nil {
+			output.Failed[rawID] = err.Error()
+			continue
+		}
+
+		if seen[deviceID] {
+			continue
+		}
+		seen[deviceID] = true
+
+		device, err := svc.deviceManagerCli.GetDeviceByID(ctx, GetDeviceByIDInput{
+			ID: deviceID,
+		})
+		if err == nil {
+			if device.DMSOwner != dms.ID {
+				output.Failed[deviceID] = fmt.Sprintf("device is registered by DMS '%s'", device.DMSOwner)
+			} else {
+				output.AlreadyRegistered = append(output.AlreadyRegistered, deviceID)
+			}
+			continue
+		} else if err != errs.ErrDeviceNotFound {
+			lFunc.Errorf("could not get device '%s': %s", deviceID, err)
+			output.Failed[deviceID] = err.Error()
+			continue
+		}
+
+		// There is no CSR yet. The templates of the profile only see the device ID as the CSR CommonName
+		csr := &x509.CertificateRequest{Subject: pkix.Name{CommonName: deviceID}}
+		profile, err := helpers.RenderDeviceProvisionProfile(dms.Settings.EnrollmentSettings.DeviceProvisionProfile, helpers.NewDeviceProvisionTemplateContext(dms, csr, nil))
+		if err != nil {
+			lFunc.Errorf("could not render DMS '%s' device provisioning profile for device '%s': %s", dms.ID, deviceID, err)
+			output.Failed[deviceID] = err.Error()
+			continue
+		}
+
+		_, err = svc.deviceManagerCli.CreateDevice(ctx, CreateDeviceInput{
+			ID:        deviceID,
+			Alias:     rawID,
+			Tags:      profile.Tags,
+			Metadata:  profile.Metadata,
+			Icon:      profile.Icon,
+			IconColor: profile.IconColor,
+			DMSID:     dms.ID,
+
+			DeviceIDRules: &rules,
+		})
+		if err != nil {
+			lFunc.Errorf("could not preregister device '%s': %s", deviceID, err)
+			output.Failed[deviceID] = err.Error()
+			continue
+		}
+
+		output.Registered = append(output.Registered, deviceID)
+	}
+
+	lFunc.Infof("DMS '%s' preregistered %d devices. %d already registered, %d failed", dms.ID, len(output.Registered), len(output.AlreadyRegistered), len(output.Failed))
+	return output, nil
+}
@@ -3,12 +3,18 @@ package services
 import (
 	"context"
 	"crypto/x509"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 )
 
 type ESTService interface {
 	// CACerts requests a copy of the current CA certificates. See RFC7030 4.1.
 	CACerts(ctx context.Context, aps string) ([]*x509.Certificate, error)
 
+	// SignedCACerts returns the CA certificates, PEM encoded or as the certs-only PKCS#7 of CACerts, along with a
+	// detached JWS signed by the enrollment CA of the APS.
+	SignedCACerts(ctx context.Context, aps string, pemEncoded bool) (*models.SignedContent, error)
+
 	// CSRAttrs requests a list of CA-desired CSR attributes. The returned list
 	// may be empty. See RFC7030 4.5.
 	//CSRAttrs( aps string, r *http.Request) (CSRAttrs, error)
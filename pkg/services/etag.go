@@ -0,0 +1,33 @@
+package services
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// updateIfMatch writes elem evaluating the If-Match precondition against the stored element atomically with the
+// write, so an update committed after the precondition was first checked is not overwritten. A failed precondition
+// returns ErrPreconditionFailed.
+func updateIfMatch[E any](ctx context.Context, updateIf func(context.Context, *E, func(*E) bool) (*E, error), elem *E, ifMatch string, etag func(*E) string) (*E, error) {
+	updated, err := updateIf(ctx, elem, func(current *E) bool {
+		return helpers.ETagMatches(ifMatch, etag(current))
+	})
+	if errors.Is(err, storage.ErrUpdateConflict) {
+		return nil, errs.ErrPreconditionFailed
+	}
+
+	return updated, err
+}
+
+func caETag(ca *models.CACertificate) string {
+	return helpers.ETag(ca)
+}
+
+func dmsETag(dms *models.DMS) string {
+	return helpers.ETag(dms)
+}
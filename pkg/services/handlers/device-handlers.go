@@ -18,10 +18,38 @@ func NewDeviceEventHandler(l *logrus.Entry, svc services.DeviceManagerService) *
 		dispatchMap: map[string]func(*event.Event) error{
 			string(models.EventUpdateCertificateMetadataKey): func(m *event.Event) error { return updateCertMetaHandler(m, svc, l) },
 			string(models.EventUpdateCertificateStatusKey):   func(m *event.Event) error { return updateCertStatusHandler(m, svc, l) },
+			string(models.EventReportDeviceConnectionKey):    func(m *event.Event) error { return reportDeviceConnectionHandler(m, svc, l) },
 		},
 	}
 }
 
+func reportDeviceConnectionHandler(event *event.Event, svc services.DeviceManagerService, lMessaging *logrus.Entry) error {
+	ctx := context.Background()
+
+	report, err := helpers.GetEventBody[models.DeviceConnectionReport](event)
+	if err != nil {
+		err = fmt.Errorf("could not decode cloud event: %s", err)
+		lMessaging.Error(err)
+		return err
+	}
+
+	if report.Connection.Source == "" {
+		report.Connection.Source = event.Source()
+	}
+
+	_, err = svc.UpdateDeviceConnectionMetadata(ctx, services.UpdateDeviceConnectionMetadataInput{
+		ID:                 report.DeviceID,
+		ConnectionMetadata: report.Connection,
+	})
+	if err != nil {
+		err = fmt.Errorf("could not update connection metadata for device %s: %s", report.DeviceID, err)
+		lMessaging.Error(err)
+		return err
+	}
+
+	return nil
+}
+
 func updateCertStatusHandler(event *event.Event, svc services.DeviceManagerService, lMessaging *logrus.Entry) error {
 	ctx := context.Background()
 
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"fmt"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/cloudevents/sdk-go/v2/event"
@@ -14,9 +15,37 @@ type IEventHandler interface {
 	HandleEvent(event *message.Message) error
 }
 
+const (
+	defaultProcessingPollInterval = 500 * time.Millisecond
+	defaultProcessingMaxWait      = 5 * time.Second
+)
+
 type EventHandler struct {
-	lMessaging  *logrus.Entry
-	dispatchMap map[string]func(*event.Event) error
+	lMessaging      *logrus.Entry
+	dispatchMap     map[string]func(*event.Event) error
+	processedEvents ProcessedEventStore
+	// Events being handled by another consumer are polled at pollInterval for up to maxWait before the redelivery
+	// is negatively acknowledged.
+	pollInterval time.Duration
+	maxWait      time.Duration
+}
+
+// NewEventHandler builds an event handler dispatching each event type to its handler. Handlers registered
+// with the EventAnyKey type receive the events without a specific handler.
+func NewEventHandler(l *logrus.Entry, dispatchMap map[string]func(*event.Event) error) *EventHandler {
+	return &EventHandler{
+		lMessaging:   l,
+		dispatchMap:  dispatchMap,
+		pollInterval: defaultProcessingPollInterval,
+		maxWait:      defaultProcessingMaxWait,
+	}
+}
+
+// WithProcessedEventStore returns a copy of the handler that skips the events already handled, identified by
+// their source and ID.
+func (h EventHandler) WithProcessedEventStore(store ProcessedEventStore) EventHandler {
+	h.processedEvents = store
+	return h
 }
 
 func (h EventHandler) HandleEvent(m *message.Message) error {
@@ -39,6 +68,23 @@ func (h EventHandler) HandleEvent(m *message.Message) error {
 		}
 	}
 
+	if h.processedEvents != nil && event.ID() != "" {
+		key := fmt.Sprintf("%s/%s", event.Source(), event.ID())
+		switch h.awaitProcessing(key) {
+		case EventProcessed:
+			h.lMessaging.Infof("skipping event %s from %s: already handled", event.ID(), event.Source())
+			return nil
+		case EventProcessing:
+			// The event is negatively acknowledged after the wait, so it is redelivered if the ongoing handling
+			// fails without redelivering it in a tight loop.
+			return fmt.Errorf("event %s from %s is being handled", event.ID(), event.Source())
+		}
+
+		defer func() {
+			h.processedEvents.Release(key, err == nil)
+		}()
+	}
+
 	err = handler(event)
 
 	if err != nil {
@@ -47,3 +93,15 @@ func (h EventHandler) HandleEvent(m *message.Message) error {
 
 	return err
 }
+
+// awaitProcessing acquires the event. If another consumer is handling it, the event is polled until the handling
+// ends or maxWait elapses. Returns EventNotProcessed once the event is acquired.
+func (h EventHandler) awaitProcessing(key string) ProcessedEventStatus {
+	status := h.processedEvents.Acquire(key)
+	for waited := time.Duration(0); status == EventProcessing && waited < h.maxWait; waited += h.pollInterval {
+		time.Sleep(h.pollInterval)
+		status = h.processedEvents.Acquire(key)
+	}
+
+	return status
+}
@@ -1,12 +1,15 @@
 package handlers
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	"github.com/ThreeDotsLabs/watermill/message"
 	"github.com/cloudevents/sdk-go/v2/event"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/memory"
 	"github.com/sirupsen/logrus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -124,3 +127,150 @@ func TestHandleAnyEvent(t *testing.T) {
 	svc.AssertCalled(t, "Event1", mock.Anything)
 
 }
+
+func TestHandleProcessedEvents(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+
+	calls := 0
+	fail := true
+	handler := NewEventHandler(entry, map[string]func(*event.Event) error{
+		"event_type_1": func(event *event.Event) error {
+			calls++
+			if fail {
+				return errors.New("error handling event")
+			}
+			return nil
+		},
+	}).WithProcessedEventStore(NewMemoryProcessedEventStore(time.Hour))
+
+	newMessage := func(id string) *message.Message {
+		return &message.Message{
+			Payload: []byte(`{"type": "event_type_1", "specversion": "1.0", "source": "test", "id": "` + id + `"}`),
+		}
+	}
+
+	// Failed events are handled again when redelivered.
+	err := handler.HandleEvent(newMessage("1"))
+	assert.Error(t, err)
+
+	fail = false
+	err = handler.HandleEvent(newMessage("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	// Redeliveries of handled events are acknowledged without handling them.
+	err = handler.HandleEvent(newMessage("1"))
+	assert.NoError(t, err)
+	assert.Equal(t, 2, calls)
+
+	err = handler.HandleEvent(newMessage("2"))
+	assert.NoError(t, err)
+	assert.Equal(t, 3, calls)
+}
+
+func TestMemoryProcessedEventStore(t *testing.T) {
+	now := time.Now()
+	store := NewMemoryProcessedEventStore(time.Minute)
+	store.now = func() time.Time { return now }
+
+	assert.Equal(t, EventNotProcessed, store.Acquire("event"))
+	assert.Equal(t, EventProcessing, store.Acquire("event"))
+
+	store.Release("event", true)
+	assert.Equal(t, EventProcessed, store.Acquire("event"))
+
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, EventNotProcessed, store.Acquire("event"))
+
+	store.Release("event", false)
+	assert.Equal(t, EventNotProcessed, store.Acquire("event"))
+
+	// Expired events are removed from the store.
+	now = now.Add(2 * time.Minute)
+	store.Acquire("other")
+	assert.Len(t, store.events, 1)
+}
+
+func TestHandleEventBeingProcessed(t *testing.T) {
+	entry := logrus.NewEntry(logrus.New())
+
+	calls := 0
+	store := NewMemoryProcessedEventStore(time.Hour)
+	handler := NewEventHandler(entry, map[string]func(*event.Event) error{
+		"event_type_1": func(event *event.Event) error {
+			calls++
+			return nil
+		},
+	}).WithProcessedEventStore(store)
+	handler.pollInterval = 10 * time.Millisecond
+	handler.maxWait = 50 * time.Millisecond
+
+	msg := &message.Message{
+		Payload: []byte(`{"type": "event_type_1", "specversion": "1.0", "source": "test", "id": "1"}`),
+	}
+
+	// Another consumer is handling the event: the redelivery is negatively acknowledged after the wait.
+	store.Acquire("test/1")
+	start := time.Now()
+	err := handler.HandleEvent(msg)
+	assert.Error(t, err)
+	assert.GreaterOrEqual(t, time.Since(start), handler.maxWait)
+	assert.Equal(t, 0, calls)
+
+	// The ongoing handling fails during the wait: the redelivery is handled.
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		store.Release("test/1", false)
+	}()
+	err = handler.HandleEvent(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+
+	// The ongoing handling succeeds during the wait: the redelivery is acknowledged without handling it.
+	store.Release("test/1", false)
+	store.Acquire("test/1")
+	go func() {
+		time.Sleep(20 * time.Millisecond)
+		store.Release("test/1", true)
+	}()
+	err = handler.HandleEvent(msg)
+	assert.NoError(t, err)
+	assert.Equal(t, 1, calls)
+}
+
+func TestStorageProcessedEventStore(t *testing.T) {
+	now := time.Now()
+	repo := memory.NewProcessedEventsRepository()
+	entry := logrus.NewEntry(logrus.New())
+
+	store := NewStorageProcessedEventStore(repo, time.Minute, "service-a", entry)
+	store.now = func() time.Time { return now }
+	replica := NewStorageProcessedEventStore(repo, time.Minute, "service-a", entry)
+	replica.now = func() time.Time { return now }
+	other := NewStorageProcessedEventStore(repo, time.Minute, "service-b", entry)
+	other.now = func() time.Time { return now }
+
+	assert.Equal(t, EventNotProcessed, store.Acquire("event"))
+	assert.Equal(t, EventProcessing, replica.Acquire("event"))
+
+	// Services sharing the storage keep separate records.
+	assert.Equal(t, EventNotProcessed, other.Acquire("event"))
+
+	store.Release("event", true)
+	assert.Equal(t, EventProcessed, replica.Acquire("event"))
+
+	// Expired records are taken over.
+	now = now.Add(2 * time.Minute)
+	assert.Equal(t, EventNotProcessed, replica.Acquire("event"))
+	assert.Equal(t, EventProcessing, store.Acquire("event"))
+
+	replica.Release("event", false)
+	assert.Equal(t, EventNotProcessed, store.Acquire("event"))
+
+	// Expired records are removed from the storage.
+	now = now.Add(2 * time.Minute)
+	store.Acquire("other")
+	exists, _, err := repo.SelectExists(context.Background(), "service-a/event")
+	assert.NoError(t, err)
+	assert.False(t, exists)
+}
@@ -0,0 +1,232 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type ProcessedEventStatus int
+
+const (
+	EventNotProcessed ProcessedEventStatus = iota
+	EventProcessing
+	EventProcessed
+)
+
+// ProcessedEventStore keeps track of the handled events, so the events redelivered by the event bus (i.e. AMQP
+// redeliveries after a connection loss or a missing acknowledgement) are not handled twice.
+type ProcessedEventStore interface {
+	// Acquire returns the status of the event before the call. If the event was not processed, it is marked as
+	// being processed.
+	Acquire(key string) ProcessedEventStatus
+	// Release records the event as processed if it was handled successfully. Otherwise the event is forgotten, so
+	// its redelivery is handled again.
+	Release(key string, handled bool)
+}
+
+type processedEvent struct {
+	status    ProcessedEventStatus
+	expiresAt time.Time
+}
+
+// MemoryProcessedEventStore keeps the processed events of the service instance for the TTL. Redeliveries to other
+// instances of the service are not detected.
+type MemoryProcessedEventStore struct {
+	lock      sync.Mutex
+	ttl       time.Duration
+	events    map[string]processedEvent
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+func NewMemoryProcessedEventStore(ttl time.Duration) *MemoryProcessedEventStore {
+	return &MemoryProcessedEventStore{
+		ttl:    ttl,
+		events: map[string]processedEvent{},
+		now:    time.Now,
+	}
+}
+
+func (s *MemoryProcessedEventStore) Acquire(key string) ProcessedEventStatus {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	now := s.now()
+	s.sweep(now)
+
+	event, ok := s.events[key]
+	if ok && event.expiresAt.After(now) {
+		return event.status
+	}
+
+	// Events being processed also expire, so an event is not blocked forever if its handler never returns.
+	s.events[key] = processedEvent{status: EventProcessing, expiresAt: now.Add(s.ttl)}
+	return EventNotProcessed
+}
+
+func (s *MemoryProcessedEventStore) Release(key string, handled bool) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if !handled {
+		delete(s.events, key)
+		return
+	}
+
+	s.events[key] = processedEvent{status: EventProcessed, expiresAt: s.now().Add(s.ttl)}
+}
+
+// sweep removes the expired events, at most twice per TTL.
+func (s *MemoryProcessedEventStore) sweep(now time.Time) {
+	if now.Before(s.nextSweep) {
+		return
+	}
+
+	for key, event := range s.events {
+		if !event.expiresAt.After(now) {
+			delete(s.events, key)
+		}
+	}
+
+	s.nextSweep = now.Add(s.ttl / 2)
+}
+
+// StorageProcessedEventStore keeps the processed events in the storage engine for the TTL, so redeliveries to any
+// instance of the service are detected. Keys are prefixed with the namespace, so the services sharing the storage
+// keep separate records of the events.
+type StorageProcessedEventStore struct {
+	repo      storage.ProcessedEventsRepo
+	ttl       time.Duration
+	namespace string
+	logger    *logrus.Entry
+
+	lock      sync.Mutex
+	nextSweep time.Time
+	now       func() time.Time
+}
+
+func NewStorageProcessedEventStore(repo storage.ProcessedEventsRepo, ttl time.Duration, namespace string, logger *logrus.Entry) *StorageProcessedEventStore {
+	return &StorageProcessedEventStore{
+		repo:      repo,
+		ttl:       ttl,
+		namespace: namespace,
+		logger:    logger,
+		now:       time.Now,
+	}
+}
+
+// Acquire behaves as MemoryProcessedEventStore.Acquire. Storage errors are logged and the event is reported as not
+// processed, so a storage outage does not block the consumers.
+func (s *StorageProcessedEventStore) Acquire(key string) ProcessedEventStatus {
+	ctx := context.Background()
+	key = s.key(key)
+	now := s.now()
+	s.sweep(ctx, now)
+
+	processing := &models.ProcessedEvent{Key: key, Processed: false, ExpiresAt: now.Add(s.ttl)}
+
+	exists, event, err := s.repo.SelectExists(ctx, key)
+	if err != nil {
+		s.logger.Errorf("could not read processed event %s: %s", key, err)
+		return EventNotProcessed
+	}
+
+	if !exists {
+		_, err = s.repo.Insert(ctx, processing)
+		if err == nil {
+			return EventNotProcessed
+		}
+
+		// Another consumer may have inserted the record in between.
+		exists, event, err = s.repo.SelectExists(ctx, key)
+		if err != nil || !exists {
+			s.logger.Errorf("could not record processed event %s: %s", key, err)
+			return EventNotProcessed
+		}
+	}
+
+	if event.ExpiresAt.After(now) {
+		return eventStatus(event)
+	}
+
+	// Events being processed also expire, so an event is not blocked forever if its handler never returns. The
+	// expired record is taken over only if no other consumer did it first.
+	_, err = s.repo.UpdateIf(ctx, processing, func(current *models.ProcessedEvent) bool {
+		return !current.ExpiresAt.After(now)
+	})
+	if errors.Is(err, storage.ErrUpdateConflict) {
+		return EventProcessing
+	} else if err != nil {
+		s.logger.Errorf("could not record processed event %s: %s", key, err)
+	}
+
+	return EventNotProcessed
+}
+
+func (s *StorageProcessedEventStore) Release(key string, handled bool) {
+	ctx := context.Background()
+	key = s.key(key)
+
+	if !handled {
+		if err := s.repo.Delete(ctx, key); err != nil {
+			s.logger.Errorf("could not remove processed event %s: %s", key, err)
+		}
+		return
+	}
+
+	event := &models.ProcessedEvent{Key: key, Processed: true, ExpiresAt: s.now().Add(s.ttl)}
+	_, err := s.repo.Update(ctx, event)
+	if err != nil {
+		// The record may have been swept while the event was handled.
+		_, err = s.repo.Insert(ctx, event)
+	}
+	if err != nil {
+		s.logger.Errorf("could not record processed event %s: %s", key, err)
+	}
+}
+
+func (s *StorageProcessedEventStore) key(key string) string {
+	return s.namespace + "/" + key
+}
+
+// sweep removes the expired events of every namespace, at most twice per TTL of this instance.
+func (s *StorageProcessedEventStore) sweep(ctx context.Context, now time.Time) {
+	s.lock.Lock()
+	if now.Before(s.nextSweep) {
+		s.lock.Unlock()
+		return
+	}
+	s.nextSweep = now.Add(s.ttl / 2)
+	s.lock.Unlock()
+
+	expired := []string{}
+	_, err := s.repo.SelectExpired(ctx, now, storage.StorageListRequest[models.ProcessedEvent]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(event models.ProcessedEvent) {
+			expired = append(expired, event.Key)
+		},
+	})
+	if err != nil {
+		s.logger.Warnf("could not list expired processed events: %s", err)
+		return
+	}
+
+	for _, key := range expired {
+		if err := s.repo.Delete(ctx, key); err != nil {
+			s.logger.Warnf("could not remove expired processed event %s: %s", key, err)
+		}
+	}
+}
+
+func eventStatus(event *models.ProcessedEvent) ProcessedEventStatus {
+	if event.Processed {
+		return EventProcessed
+	}
+	return EventProcessing
+}
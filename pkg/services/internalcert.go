@@ -0,0 +1,92 @@
+package services
+
+import (
+	"context"
+	"crypto/subtle"
+	"crypto/x509"
+
+	"github.com/go-playground/validator/v10"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+var internalCertValidate *validator.Validate
+
+// InternalCertificateService issues the short-lived client certificates of the service-to-service calls. The
+// services only send the CSR of their own key, so the internal CA key never leaves the CA service.
+type InternalCertificateService interface {
+	IssueInternalCertificate(ctx context.Context, input IssueInternalCertificateInput) (*models.InternalCertificate, error)
+}
+
+type internalCertificateServiceImpl struct {
+	issuer   *helpers.InternalCertificateIssuer
+	services []config.InternalService
+	logger   *logrus.Entry
+}
+
+type InternalCertificateServiceBuilder struct {
+	Logger *logrus.Entry
+	Issuer *helpers.InternalCertificateIssuer
+	// Services binds each service identity to the token it authenticates with.
+	Services []config.InternalService
+}
+
+func NewInternalCertificateService(builder InternalCertificateServiceBuilder) InternalCertificateService {
+	internalCertValidate = validator.New()
+
+	return &internalCertificateServiceImpl{
+		issuer:   builder.Issuer,
+		services: builder.Services,
+		logger:   builder.Logger,
+	}
+}
+
+type IssueInternalCertificateInput struct {
+	Token       string                         `validate:"required"`
+	CertRequest *models.X509CertificateRequest `validate:"required"`
+}
+
+// IssueInternalCertificate signs a client certificate for the service identity bound to the token. The identity
+// requested in the CSR is ignored.
+// Returned Error Codes:
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid, or the CSR signature is not valid.
+//   - ErrInternalCertificateInvalidToken
+//     The token is not bound to any service identity.
+func (svc internalCertificateServiceImpl) IssueInternalCertificate(ctx context.Context, input IssueInternalCertificateInput) (*models.InternalCertificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := internalCertValidate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("IssueInternalCertificateInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	identity := ""
+	for _, service := range svc.services {
+		if subtle.ConstantTimeCompare([]byte(service.Token), []byte(input.Token)) == 1 {
+			identity = service.Identity
+			break
+		}
+	}
+
+	if identity == "" {
+		lFunc.Errorf("rejecting internal certificate request with unknown token")
+		return nil, errs.ErrInternalCertificateInvalidToken
+	}
+
+	crt, err := svc.issuer.Issue((*x509.CertificateRequest)(input.CertRequest), identity)
+	if err != nil {
+		lFunc.Errorf("could not issue internal certificate for '%s': %s", identity, err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	lFunc.Infof("internal certificate %s issued for '%s'. Expires at %s", helpers.SerialNumberToString(crt.SerialNumber), identity, crt.NotAfter)
+	return &models.InternalCertificate{
+		Certificate:   (*models.X509Certificate)(crt),
+		CACertificate: (*models.X509Certificate)(svc.issuer.CACertificate()),
+	}, nil
+}
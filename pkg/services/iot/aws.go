@@ -3,8 +3,10 @@ package iot
 import (
 	"bytes"
 	"context"
+	"crypto/sha256"
 	"crypto/x509"
 	"encoding/base64"
+	"encoding/hex"
 	"encoding/json"
 	"errors"
 	"fmt"
@@ -23,6 +25,7 @@ import (
 	"github.com/aws/aws-sdk-go-v2/service/sts"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
 	"github.com/sirupsen/logrus"
 )
@@ -165,28 +168,32 @@ func (svc *AWSCloudConnectorService) RegisterAndAttachThing(ctx context.Context,
 		return err
 	}
 
+	certID := awsCertificateID((*x509.Certificate)(input.BindedIdentity.Certificate.Certificate))
 	_, err = svc.iotSDK.DescribeThing(context.Background(), &iot.DescribeThingInput{
 		ThingName: &input.DeviceID,
 	})
 	if err == nil {
-		//thing was registered, revoke all other attached certs
+		//thing was registered, revoke and detach all other attached certs
 		paginator := iot.NewListThingPrincipalsPaginator(&svc.iotSDK, &iot.ListThingPrincipalsInput{
 			ThingName: &input.DeviceID,
 		}, func(ltppo *iot.ListThingPrincipalsPaginatorOptions) {
 			ltppo.Limit = 15
 		})
-		pageNum := 0
 		for paginator.HasMorePages() {
 			output, err := paginator.NextPage(context.TODO())
 			if err != nil {
 				logrus.Warnf("error while iterating principals for thing %s: %s", input.DeviceID, err)
+				break
 			}
 
 			for _, value := range output.Principals {
 				//value is an ARN like arn:aws:iot:eu-west-1:XXXXXXX:cert/ea8d99d4fdc37f9a6109614e46183015887cf7366db1bc2b3d62786e5ea0232c
 				//get cerID only
-
 				certIDSplit := strings.Split(value, "/")
+				if len(certIDSplit) != 2 || certIDSplit[1] == certID {
+					continue
+				}
+
 				_, err = svc.iotSDK.UpdateCertificate(context.Background(), &iot.UpdateCertificateInput{
 					CertificateId: aws.String(certIDSplit[1]),
 					NewStatus:     types.CertificateStatusRevoked,
@@ -194,76 +201,35 @@ func (svc *AWSCloudConnectorService) RegisterAndAttachThing(ctx context.Context,
 				if err != nil {
 					logrus.Warnf("error while revoking AWS certificate-principal %s for thing %s: %s", value, input.DeviceID, err)
 				}
+
+				_, err = svc.iotSDK.DetachThingPrincipal(context.Background(), &iot.DetachThingPrincipalInput{
+					ThingName: &input.DeviceID,
+					Principal: aws.String(value),
+				})
+				if err != nil {
+					logrus.Warnf("error while detaching AWS certificate-principal %s from thing %s: %s", value, input.DeviceID, err)
+				}
 			}
-			pageNum++
 		}
-
 	}
 
-	template := map[string]any{
-		"Parameters": map[string]any{
-			"ThingName": map[string]any{
-				"Type": "String",
-			},
-			"SerialNumber": map[string]any{
-				"Type": "String",
-			},
-			"DMS": map[string]any{
-				"Type": "String",
-			},
-			"LamassuCertificate": map[string]any{
-				"Type": "String",
-			},
-			"LamassuCACertificatePem": map[string]any{
-				"Type": "String",
-			},
-		},
-		"Resources": map[string]any{
-			"thing": map[string]any{
-				"Type": "AWS::IoT::Thing",
-				"Properties": map[string]any{
-					"ThingName": map[string]any{
-						"Ref": "ThingName",
-					},
-					"AttributePayload": map[string]any{},
-					"ThingGroups":      input.DMSIoTAutomationConfig.GroupNames,
-				},
-				"OverrideSettings": map[string]any{
-					"AttributePayload": "REPLACE",
-					"ThingTypeName":    "REPLACE",
-					"ThingGroups":      "REPLACE",
-				},
-			},
-			"certificate": map[string]any{
-				"Type": "AWS::IoT::Certificate",
-				"Properties": map[string]any{
-					"CACertificatePem": map[string]any{
-						"Ref": "LamassuCACertificatePem",
-					},
-					"CertificatePem": map[string]any{
-						"Ref": "LamassuCertificate",
-					},
-				},
-			},
-		},
+	policies := []string{}
+	for _, policy := range input.DMSIoTAutomationConfig.Policies {
+		policies = append(policies, policy.PolicyName)
 	}
 
-	resources := template["Resources"].(map[string]any)
-	for _, policy := range input.DMSIoTAutomationConfig.Policies {
-		resources[policy.PolicyName] = map[string]any{
-			"Type": "AWS::IoT::Policy",
-			"Properties": map[string]any{
-				"PolicyName": policy.PolicyName,
-			},
-		}
+	templateBody, err := thingRegistrationTemplateBuilder(input.DMSIoTAutomationConfig.GroupNames, policies)
+	if err != nil {
+		logrus.Errorf("could not serialize template %s", err)
+		return err
 	}
 
-	aki := input.BindedIdentity.Certificate.Certificate.AuthorityKeyId
+	caID := input.BindedIdentity.Certificate.IssuerCAMetadata.ID
 	ca, err := svc.CaSDK.GetCAByID(context.Background(), services.GetCAByIDInput{
-		CAID: string(aki),
+		CAID: caID,
 	})
 	if err != nil {
-		logrus.Errorf("could not get CA using AKI %s for device %s: Skipping: %s", string(aki), input.DeviceID, err)
+		logrus.Errorf("could not get issuer CA %s for device %s: Skipping: %s", caID, input.DeviceID, err)
 		return err
 	}
 
@@ -275,14 +241,8 @@ func (svc *AWSCloudConnectorService) RegisterAndAttachThing(ctx context.Context,
 		"LamassuCACertificatePem": helpers.CertificateToPEM((*x509.Certificate)(ca.Certificate.Certificate)),
 	}
 
-	templateB, err := json.Marshal(template)
-	if err != nil {
-		logrus.Errorf("could not serialize template %s", err)
-		return err
-	}
-
 	registrationOutput, err := svc.iotSDK.RegisterThing(context.Background(), &iot.RegisterThingInput{
-		TemplateBody: aws.String(string(templateB)),
+		TemplateBody: aws.String(templateBody),
 		Parameters:   params,
 	})
 	if err != nil {
@@ -321,6 +281,7 @@ func (svc *AWSCloudConnectorService) RegisterAndAttachThing(ctx context.Context,
 
 	device.Metadata[models.AWSIoTMetadataKey(svc.ConnectorID)] = models.DeviceAWSMetadata{
 		Registered: true,
+		ThingARN:   registrationOutput.ResourceArns["thing"],
 		Actions:    []models.RemediationActionType{},
 	}
 
@@ -348,33 +309,9 @@ func (svc *AWSCloudConnectorService) UpdateDeviceShadow(ctx context.Context, inp
 		return nil
 	}
 
-	getShadowReq := &iotdataplane.GetThingShadowInput{
-		ThingName: aws.String(input.DeviceID),
-	}
-	if input.DMSIoTAutomationConfig.ShadowConfig.ShadowName != "" {
-		logrus.Debugf("using a named shadow with name '%s'", input.DMSIoTAutomationConfig.ShadowConfig.ShadowName)
-		getShadowReq.ShadowName = &input.DMSIoTAutomationConfig.ShadowConfig.ShadowName
-	}
-
-	deviceShadow := shadowMsg{
-		State: shadowState{
-			Reported: map[string]any{},
-			Desired:  map[string]any{},
-		},
-		Version: 0,
-	}
-
-	var rnf *iotdataplaneTypes.ResourceNotFoundException
-	getShadowOutput, err := svc.iotdataplaneSDK.GetThingShadow(context.Background(), getShadowReq)
+	deviceShadow, err := svc.getDeviceShadow(input.DeviceID, input.DMSIoTAutomationConfig.ShadowConfig.ShadowName)
 	if err != nil {
-		if !errors.As(err, &rnf) {
-			return fmt.Errorf("could not get device %s shadow: %s", input.DeviceID, err)
-		}
-	} else {
-		err = json.Unmarshal(getShadowOutput.Payload, &deviceShadow)
-		if err != nil {
-			return fmt.Errorf("could not unmarshal device %s shadow: %s", input.DeviceID, err)
-		}
+		return err
 	}
 
 	idShadow := map[string]int{}
@@ -401,24 +338,8 @@ func (svc *AWSCloudConnectorService) UpdateDeviceShadow(ctx context.Context, inp
 
 	deviceShadow.State.Desired["identity_actions"] = idShadow
 
-	deviceShadowBytes, err := json.Marshal(deviceShadow)
+	err = svc.putDeviceShadow(input.DeviceID, input.DMSIoTAutomationConfig.ShadowConfig.ShadowName, deviceShadow)
 	if err != nil {
-		return fmt.Errorf("failed encoding new shadow payload: %s", err)
-	}
-
-	shadowUpdateMsg := &iotdataplane.UpdateThingShadowInput{
-		ThingName: &input.DeviceID,
-		Payload:   deviceShadowBytes,
-	}
-
-	if input.DMSIoTAutomationConfig.ShadowConfig.ShadowName != "" {
-		logrus.Debugf("using a named shadow with name '%s'", input.DMSIoTAutomationConfig.ShadowConfig.ShadowName)
-		shadowUpdateMsg.ShadowName = &input.DMSIoTAutomationConfig.ShadowConfig.ShadowName
-	}
-
-	_, err = svc.iotdataplaneSDK.UpdateThingShadow(context.Background(), shadowUpdateMsg)
-	if err != nil {
-		logrus.Errorf("could not create Update Shadow for thing %s: %s", input.DeviceID, err)
 		return err
 	}
 
@@ -483,6 +404,128 @@ func (svc *AWSCloudConnectorService) UpdateDeviceShadow(ctx context.Context, inp
 	return nil
 }
 
+func (svc *AWSCloudConnectorService) getDeviceShadow(deviceID string, shadowName string) (*shadowMsg, error) {
+	getShadowReq := &iotdataplane.GetThingShadowInput{
+		ThingName: aws.String(deviceID),
+	}
+	if shadowName != "" {
+		logrus.Debugf("using a named shadow with name '%s'", shadowName)
+		getShadowReq.ShadowName = &shadowName
+	}
+
+	deviceShadow := shadowMsg{
+		State: shadowState{
+			Reported: map[string]any{},
+			Desired:  map[string]any{},
+		},
+		Version: 0,
+	}
+
+	var rnf *iotdataplaneTypes.ResourceNotFoundException
+	getShadowOutput, err := svc.iotdataplaneSDK.GetThingShadow(context.Background(), getShadowReq)
+	if err != nil {
+		if !errors.As(err, &rnf) {
+			return nil, fmt.Errorf("could not get device %s shadow: %s", deviceID, err)
+		}
+	} else {
+		err = json.Unmarshal(getShadowOutput.Payload, &deviceShadow)
+		if err != nil {
+			return nil, fmt.Errorf("could not unmarshal device %s shadow: %s", deviceID, err)
+		}
+	}
+
+	if deviceShadow.State.Desired == nil {
+		deviceShadow.State.Desired = map[string]any{}
+	}
+
+	return &deviceShadow, nil
+}
+
+func (svc *AWSCloudConnectorService) putDeviceShadow(deviceID string, shadowName string, deviceShadow *shadowMsg) error {
+	deviceShadowBytes, err := json.Marshal(deviceShadow)
+	if err != nil {
+		return fmt.Errorf("failed encoding new shadow payload: %s", err)
+	}
+
+	shadowUpdateMsg := &iotdataplane.UpdateThingShadowInput{
+		ThingName: &deviceID,
+		Payload:   deviceShadowBytes,
+	}
+
+	if shadowName != "" {
+		logrus.Debugf("using a named shadow with name '%s'", shadowName)
+		shadowUpdateMsg.ShadowName = &shadowName
+	}
+
+	_, err = svc.iotdataplaneSDK.UpdateThingShadow(context.Background(), shadowUpdateMsg)
+	if err != nil {
+		logrus.Errorf("could not create Update Shadow for thing %s: %s", deviceID, err)
+		return err
+	}
+
+	return nil
+}
+
+type identityExpiryShadow struct {
+	SerialNumber  string `json:"serial_number"`
+	NotAfter      int64  `json:"not_after"`
+	ExpiresInDays int    `json:"expires_in_days"`
+}
+
+// NotifyCertificateExpiry writes the "identity_expiry" flag into the desired state of the device shadow
+// so that device-side agents subscribed to the shadow can trigger a re-enrollment proactively.
+// The shadow is only updated when the serial number or the remaining days change.
+func (svc *AWSCloudConnectorService) NotifyCertificateExpiry(ctx context.Context, device models.Device, cert models.Certificate, daysLeft int) error {
+	dms, err := svc.DmsSDK.GetDMSByID(ctx, services.GetDMSByIDInput{
+		ID: device.DMSOwner,
+	})
+	if err != nil {
+		logrus.Errorf("could not get DMS %s: %s", device.DMSOwner, err)
+		return err
+	}
+
+	var dmsAWSConf models.IotAWSDMSMetadata
+	hasKey, err := helpers.GetMetadataToStruct(dms.Metadata, models.AWSIoTMetadataKey(svc.ConnectorID), &dmsAWSConf)
+	if err != nil {
+		logrus.Errorf("could not decode metadata with key %s: %s", models.AWSIoTMetadataKey(svc.ConnectorID), err)
+		return err
+	}
+
+	if !hasKey || !dmsAWSConf.ShadowConfig.Enable {
+		logrus.Debugf("shadow usage is not enabled for DMS %s associated to device %s. Skipping", dms.ID, device.ID)
+		return nil
+	}
+
+	deviceShadow, err := svc.getDeviceShadow(device.ID, dmsAWSConf.ShadowConfig.ShadowName)
+	if err != nil {
+		return err
+	}
+
+	expiry := identityExpiryShadow{
+		SerialNumber:  cert.SerialNumber,
+		NotAfter:      cert.ValidTo.Unix(),
+		ExpiresInDays: daysLeft,
+	}
+
+	if current, ok := deviceShadow.State.Desired["identity_expiry"]; ok {
+		var currentExpiry identityExpiryShadow
+		currentBytes, err := json.Marshal(current)
+		if err == nil && json.Unmarshal(currentBytes, &currentExpiry) == nil && currentExpiry == expiry {
+			return nil
+		}
+	}
+
+	deviceShadow.State.Desired["identity_expiry"] = expiry
+
+	err = svc.putDeviceShadow(device.ID, dmsAWSConf.ShadowConfig.ShadowName, deviceShadow)
+	if err != nil {
+		return err
+	}
+
+	logrus.Infof("updated shadow for device %s: certificate %s expires in %d days", device.ID, cert.SerialNumber, daysLeft)
+	return nil
+}
+
 func (svc *AWSCloudConnectorService) GetRegisteredCAs(ctx context.Context) ([]*models.CACertificate, error) {
 	lFunc := svc.logger
 	cas := []*models.CACertificate{}
@@ -547,6 +590,73 @@ type RegisterCAInput struct {
 	RegisterConfiguration models.IoTAWSCAMetadata
 }
 
+// ListCertificateStatuses iterates the certificates registered in AWS IoT. Only the ACTIVE certificates are
+// reported as active, any other AWS status is reported as revoked.
+func (svc *AWSCloudConnectorService) ListCertificateStatuses(ctx context.Context, apply func(models.ProviderCertificateStatus)) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	lFunc.Debugf("listing certificates in AWS IoT")
+	paginator := iot.NewListCertificatesPaginator(&svc.iotSDK, &iot.ListCertificatesInput{}, func(lcpo *iot.ListCertificatesPaginatorOptions) {
+		lcpo.Limit = 50
+	})
+	for paginator.HasMorePages() {
+		output, err := paginator.NextPage(ctx)
+		if err != nil {
+			lFunc.Errorf("something went wrong while listing certificates from AWS IoT: %s", err)
+			return err
+		}
+
+		for _, certMeta := range output.Certificates {
+			descRes, err := svc.iotSDK.DescribeCertificate(ctx, &iot.DescribeCertificateInput{CertificateId: certMeta.CertificateId})
+			if err != nil {
+				lFunc.Errorf("something went wrong while describing '%s' certificate from AWS IoT: %s", *certMeta.CertificateId, err)
+				return err
+			}
+
+			descCrt, err := helpers.ParseCertificate(*descRes.CertificateDescription.CertificatePem)
+			if err != nil {
+				lFunc.Warnf("skipping certificate '%s'. Could not parse PEM: %s", *certMeta.CertificateId, err)
+				continue
+			}
+
+			status := models.StatusRevoked
+			if certMeta.Status == types.CertificateStatusActive {
+				status = models.StatusActive
+			}
+
+			apply(models.ProviderCertificateStatus{
+				SerialNumber: helpers.SerialNumberToString(descCrt.SerialNumber),
+				ProviderID:   *certMeta.CertificateId,
+				Status:       status,
+			})
+		}
+	}
+
+	return nil
+}
+
+// SetCertificateStatus activates or revokes the certificate in AWS IoT.
+func (svc *AWSCloudConnectorService) SetCertificateStatus(ctx context.Context, cert models.ProviderCertificateStatus, status models.CertificateStatus) error {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	newStatus := types.CertificateStatusRevoked
+	if status == models.StatusActive {
+		newStatus = types.CertificateStatusActive
+	}
+
+	lFunc.Infof("updating AWS IoT certificate '%s' with SN '%s' status to %s", cert.ProviderID, cert.SerialNumber, newStatus)
+	_, err := svc.iotSDK.UpdateCertificate(ctx, &iot.UpdateCertificateInput{
+		CertificateId: aws.String(cert.ProviderID),
+		NewStatus:     newStatus,
+	})
+	if err != nil {
+		lFunc.Errorf("could not update AWS IoT certificate '%s' status: %s", cert.ProviderID, err)
+		return err
+	}
+
+	return nil
+}
+
 func (svc *AWSCloudConnectorService) RegisterCA(ctx context.Context, input RegisterCAInput) (*models.CACertificate, error) {
 	lFunc := svc.logger
 
@@ -574,6 +684,13 @@ func (svc *AWSCloudConnectorService) RegisterCA(ctx context.Context, input Regis
 		logrus.Infof("registering CA with SN '%s'", input.SerialNumber)
 	} else {
 		logrus.Warnf("CA with SN '%s' is already registered in AWS IoT. Skipping registration process", input.SerialNumber)
+		if input.RegisterConfiguration.CertificateID != "" {
+			err = svc.RegisterCAJITPProvisioners(ctx, &input.CACertificate)
+			if err != nil {
+				return nil, err
+			}
+		}
+
 		return &input.CACertificate, nil
 	}
 
@@ -663,6 +780,12 @@ func (svc *AWSCloudConnectorService) RegisterCA(ctx context.Context, input Regis
 	})
 	if err != nil {
 		lFunc.Errorf("could not update CA metadata: %s", err)
+		return nil, err
+	}
+
+	err = svc.RegisterCAJITPProvisioners(ctx, ca)
+	if err != nil {
+		return nil, err
 	}
 
 	return ca, nil
@@ -808,27 +931,23 @@ func (svc *AWSCloudConnectorService) RegisterUpdateJITPProvisioner(ctx context.C
 		provRoleARN = fmt.Sprintf("arn:aws:iam::%s:role/JITPRole", svc.AccountID)
 		lFunc.Warnf("using default provisioning role. Make sure %s IAM Role exists in the %s account", provRoleARN, svc.AccountID)
 	}
-	cpTemplate, err := svc.iotSDK.CreateProvisioningTemplate(context.Background(), &iot.CreateProvisioningTemplateInput{
-		ProvisioningRoleArn: aws.String(provRoleARN),
-		TemplateBody:        &templateBody,
-		TemplateName:        &input.DMS.ID,
-		Description:         &input.DMS.Name,
-		Enabled:             input.AwsJITPConfig.JITPProvisioningTemplate.EnableTemplate,
-		PreProvisioningHook: nil,
-		Tags:                []types.Tag{{Key: aws.String("created-by"), Value: aws.String("LAMASSU")}},
-		Type:                types.TemplateTypeJitp,
-	})
+
+	templateARN, err := svc.upsertJITPTemplate(ctx, input.DMS, templateBody, provRoleARN, input.AwsJITPConfig.JITPProvisioningTemplate.EnableTemplate)
 	if err != nil {
-		lFunc.Errorf("something went wrong while creating JITP template in AWS: %s", err)
+		lFunc.Errorf("something went wrong while registering JITP template in AWS: %s", err)
 		return err
 	}
 
-	lFunc.Infof("created JITP '%s' template", templateBody)
+	awsCAID, err := svc.jitpAWSCACertificateID(ctx, input.DMS, input.AwsJITPConfig)
+	if err != nil {
+		lFunc.Errorf("could not get the AWS CA Certificate of DMS %s enrollment CA: %s", input.DMS.ID, err)
+		return err
+	}
 
-	if input.AwsJITPConfig.JITPProvisioningTemplate.AWSCACertificateId != "" {
-		lFunc.Infof("updating AWS CA Certificate %s assigning JITP '%s' template", input.AwsJITPConfig.JITPProvisioningTemplate.AWSCACertificateId, input.DMS.ID)
+	if awsCAID != "" {
+		lFunc.Infof("updating AWS CA Certificate %s assigning JITP '%s' template", awsCAID, input.DMS.ID)
 		_, err = svc.iotSDK.UpdateCACertificate(context.Background(), &iot.UpdateCACertificateInput{
-			CertificateId:             &input.AwsJITPConfig.JITPProvisioningTemplate.AWSCACertificateId,
+			CertificateId:             &awsCAID,
 			NewAutoRegistrationStatus: types.AutoRegistrationStatusEnable,
 			NewStatus:                 types.CACertificateStatusActive,
 			RegistrationConfig: &types.RegistrationConfig{
@@ -841,12 +960,17 @@ func (svc *AWSCloudConnectorService) RegisterUpdateJITPProvisioner(ctx context.C
 			return err
 		}
 	} else {
-		lFunc.Warnf("not updating any AWS CA Certificate for JITP '%s' template", input.DMS.ID)
+		lFunc.Warnf("not updating any AWS CA Certificate for JITP '%s' template. The enrollment CA %s is not registered in AWS IoT yet", input.DMS.ID, input.DMS.Settings.EnrollmentSettings.EnrollmentCA)
+	}
+
+	if input.AwsJITPConfig.JITPProvisioningTemplate.ARN == templateARN {
+		return nil
 	}
 
-	dms := input.DMS
 	updatedJitpConf := input.AwsJITPConfig
-	updatedJitpConf.JITPProvisioningTemplate.ARN = *cpTemplate.TemplateArn
+	updatedJitpConf.JITPProvisioningTemplate.ARN = templateARN
+
+	dms := input.DMS
 	dms.Metadata[models.AWSIoTMetadataKey(svc.ConnectorID)] = updatedJitpConf
 
 	_, err = svc.DmsSDK.UpdateDMS(ctx, services.UpdateDMSInput{
@@ -860,6 +984,245 @@ func (svc *AWSCloudConnectorService) RegisterUpdateJITPProvisioner(ctx context.C
 	return nil
 }
 
+// upsertJITPTemplate creates the JITP provisioning template of the DMS or, if it already exists, updates its settings
+// and creates a new default version when the template body changed. It returns the ARN of the template.
+func (svc *AWSCloudConnectorService) upsertJITPTemplate(ctx context.Context, dms *models.DMS, templateBody, provRoleARN string, enabled bool) (string, error) {
+	lFunc := svc.logger
+
+	current, err := svc.iotSDK.DescribeProvisioningTemplate(ctx, &iot.DescribeProvisioningTemplateInput{
+		TemplateName: &dms.ID,
+	})
+	if err != nil {
+		var rne *types.ResourceNotFoundException
+		if !errors.As(err, &rne) {
+			return "", err
+		}
+
+		cpTemplate, err := svc.iotSDK.CreateProvisioningTemplate(ctx, &iot.CreateProvisioningTemplateInput{
+			ProvisioningRoleArn: aws.String(provRoleARN),
+			TemplateBody:        &templateBody,
+			TemplateName:        &dms.ID,
+			Description:         &dms.Name,
+			Enabled:             enabled,
+			PreProvisioningHook: nil,
+			Tags:                []types.Tag{{Key: aws.String("created-by"), Value: aws.String("LAMASSU")}},
+			Type:                types.TemplateTypeJitp,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		lFunc.Infof("created JITP '%s' template", dms.ID)
+		return *cpTemplate.TemplateArn, nil
+	}
+
+	if current.TemplateBody == nil || *current.TemplateBody != templateBody {
+		// AWS keeps up to 5 versions of a template. Drop the previous non default versions before adding a new one
+		versions, err := svc.iotSDK.ListProvisioningTemplateVersions(ctx, &iot.ListProvisioningTemplateVersionsInput{
+			TemplateName: &dms.ID,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		for _, version := range versions.Versions {
+			if version.IsDefaultVersion {
+				continue
+			}
+
+			lFunc.Infof("deleting version '%d' from JITP '%s' template", *version.VersionId, dms.ID)
+			_, err = svc.iotSDK.DeleteProvisioningTemplateVersion(ctx, &iot.DeleteProvisioningTemplateVersionInput{
+				TemplateName: &dms.ID,
+				VersionId:    version.VersionId,
+			})
+			if err != nil {
+				return "", err
+			}
+		}
+
+		_, err = svc.iotSDK.CreateProvisioningTemplateVersion(ctx, &iot.CreateProvisioningTemplateVersionInput{
+			TemplateName: &dms.ID,
+			TemplateBody: &templateBody,
+			SetAsDefault: true,
+		})
+		if err != nil {
+			return "", err
+		}
+
+		lFunc.Infof("created new version of JITP '%s' template", dms.ID)
+	}
+
+	_, err = svc.iotSDK.UpdateProvisioningTemplate(ctx, &iot.UpdateProvisioningTemplateInput{
+		TemplateName:        &dms.ID,
+		Description:         &dms.Name,
+		Enabled:             enabled,
+		ProvisioningRoleArn: aws.String(provRoleARN),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	return *current.TemplateArn, nil
+}
+
+// jitpAWSCACertificateID returns the AWS IoT CA certificate the JITP template of the DMS is assigned to. It defaults
+// to the AWS IoT CA certificate of the DMS enrollment CA, if the CA is registered by this connector.
+func (svc *AWSCloudConnectorService) jitpAWSCACertificateID(ctx context.Context, dms *models.DMS, conf models.IotAWSDMSMetadata) (string, error) {
+	if conf.JITPProvisioningTemplate.AWSCACertificateId != "" {
+		return conf.JITPProvisioningTemplate.AWSCACertificateId, nil
+	}
+
+	if dms.Settings.EnrollmentSettings.EnrollmentCA == "" {
+		return "", nil
+	}
+
+	ca, err := svc.CaSDK.GetCAByID(ctx, services.GetCAByIDInput{
+		CAID: dms.Settings.EnrollmentSettings.EnrollmentCA,
+	})
+	if err != nil {
+		return "", err
+	}
+
+	var caAWSConf models.IoTAWSCAMetadata
+	_, err = helpers.GetMetadataToStruct(ca.Metadata, models.AWSIoTMetadataKey(svc.ConnectorID), &caAWSConf)
+	if err != nil {
+		return "", err
+	}
+
+	return caAWSConf.CertificateID, nil
+}
+
+// RegisterCAJITPProvisioners provisions the JITP templates of the DMSs using the CA as enrollment CA in JITP
+// registration mode. Unless the DMS sets its own AWS CA certificate, the templates are assigned to the AWS IoT CA
+// certificate of the CA, so the CA must be registered beforehand.
+func (svc *AWSCloudConnectorService) RegisterCAJITPProvisioners(ctx context.Context, ca *models.CACertificate) error {
+	lFunc := svc.logger
+
+	dmss := []models.DMS{}
+	_, err := svc.DmsSDK.GetAll(ctx, services.GetAllInput{
+		ListInput: resources.ListInput[models.DMS]{
+			ExhaustiveRun: true,
+			ApplyFunc: func(dms models.DMS) {
+				if dms.Settings.EnrollmentSettings.EnrollmentCA == ca.ID {
+					dmss = append(dmss, dms)
+				}
+			},
+		},
+	})
+	if err != nil {
+		lFunc.Errorf("could not list DMSs: %s", err)
+		return err
+	}
+
+	for _, dms := range dmss {
+		var dmsAWSConf models.IotAWSDMSMetadata
+		hasKey, err := helpers.GetMetadataToStruct(dms.Metadata, models.AWSIoTMetadataKey(svc.ConnectorID), &dmsAWSConf)
+		if err != nil {
+			lFunc.Warnf("skipping DMS %s. Could not decode metadata with key %s: %s", dms.ID, models.AWSIoTMetadataKey(svc.ConnectorID), err)
+			continue
+		}
+
+		if !hasKey || dmsAWSConf.RegistrationMode != models.JitpAWSIoTRegistrationMode {
+			continue
+		}
+
+		lFunc.Infof("provisioning JITP '%s' template for CA %s", dms.ID, ca.ID)
+		err = svc.RegisterUpdateJITPProvisioner(ctx, RegisterUpdateJITPProvisionerInput{
+			DMS:           &dms,
+			AwsJITPConfig: dmsAWSConf,
+		})
+		if err != nil {
+			lFunc.Errorf("could not provision JITP '%s' template for CA %s: %s", dms.ID, ca.ID, err)
+			return err
+		}
+	}
+
+	return nil
+}
+
+// awsCertificateID returns the ID AWS IoT assigns to the certificate, the hex encoded SHA-256 of its DER encoding.
+func awsCertificateID(cert *x509.Certificate) string {
+	fingerprint := sha256.Sum256(cert.Raw)
+	return hex.EncodeToString(fingerprint[:])
+}
+
+// thingRegistrationTemplateBuilder builds the template used to register a thing with its certificate. The thing is
+// attached to the groups and the certificate to the policies.
+func thingRegistrationTemplateBuilder(thingGroups []string, policyNames []string) (string, error) {
+	template := map[string]any{
+		"Parameters": map[string]any{
+			"ThingName": map[string]any{
+				"Type": "String",
+			},
+			"SerialNumber": map[string]any{
+				"Type": "String",
+			},
+			"DMS": map[string]any{
+				"Type": "String",
+			},
+			"LamassuCertificate": map[string]any{
+				"Type": "String",
+			},
+			"LamassuCACertificatePem": map[string]any{
+				"Type": "String",
+			},
+		},
+		"Resources": map[string]any{
+			"thing": map[string]any{
+				"Type": "AWS::IoT::Thing",
+				"Properties": map[string]any{
+					"ThingName": map[string]any{
+						"Ref": "ThingName",
+					},
+					"AttributePayload": map[string]any{
+						"lamassu_dms": map[string]any{
+							"Ref": "DMS",
+						},
+						"lamassu_serial_number": map[string]any{
+							"Ref": "SerialNumber",
+						},
+					},
+					"ThingGroups": thingGroups,
+				},
+				"OverrideSettings": map[string]any{
+					"AttributePayload": "REPLACE",
+					"ThingTypeName":    "REPLACE",
+					"ThingGroups":      "REPLACE",
+				},
+			},
+			"certificate": map[string]any{
+				"Type": "AWS::IoT::Certificate",
+				"Properties": map[string]any{
+					"CACertificatePem": map[string]any{
+						"Ref": "LamassuCACertificatePem",
+					},
+					"CertificatePem": map[string]any{
+						"Ref": "LamassuCertificate",
+					},
+					"Status": "ACTIVE",
+				},
+			},
+		},
+	}
+
+	resources := template["Resources"].(map[string]any)
+	for _, policyName := range policyNames {
+		resources[policyName] = map[string]any{
+			"Type": "AWS::IoT::Policy",
+			"Properties": map[string]any{
+				"PolicyName": policyName,
+			},
+		}
+	}
+
+	b, err := json.Marshal(template)
+	if err != nil {
+		return "", err
+	}
+
+	return string(b), nil
+}
+
 func jitpTemplateBuilder(thingGroups []string, policyNames []string) (string, error) {
 
 	jitpTemplate := map[string]any{
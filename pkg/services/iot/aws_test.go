@@ -0,0 +1,66 @@
+package iot
+
+import (
+	"crypto/elliptic"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/stretchr/testify/assert"
+)
+
+type templateDocument struct {
+	Parameters map[string]any `json:"Parameters"`
+	Resources  map[string]struct {
+		Type       string         `json:"Type"`
+		Properties map[string]any `json:"Properties"`
+	} `json:"Resources"`
+}
+
+func TestThingRegistrationTemplateBuilder(t *testing.T) {
+	body, err := thingRegistrationTemplateBuilder([]string{"sensors"}, []string{"telemetry", "shadow"})
+	assert.NoError(t, err)
+
+	var template templateDocument
+	assert.NoError(t, json.Unmarshal([]byte(body), &template))
+
+	thing := template.Resources["thing"]
+	assert.Equal(t, "AWS::IoT::Thing", thing.Type)
+	assert.Equal(t, []any{"sensors"}, thing.Properties["ThingGroups"])
+	assert.Contains(t, thing.Properties["AttributePayload"], "lamassu_dms")
+
+	assert.Equal(t, "AWS::IoT::Certificate", template.Resources["certificate"].Type)
+	for _, policy := range []string{"telemetry", "shadow"} {
+		assert.Equal(t, "AWS::IoT::Policy", template.Resources[policy].Type)
+		assert.Equal(t, policy, template.Resources[policy].Properties["PolicyName"])
+	}
+
+	for _, param := range []string{"ThingName", "SerialNumber", "DMS", "LamassuCertificate", "LamassuCACertificatePem"} {
+		assert.Contains(t, template.Parameters, param)
+	}
+}
+
+func TestJITPTemplateBuilder(t *testing.T) {
+	body, err := jitpTemplateBuilder([]string{"gateways"}, []string{"telemetry"})
+	assert.NoError(t, err)
+
+	var template templateDocument
+	assert.NoError(t, json.Unmarshal([]byte(body), &template))
+
+	assert.Equal(t, []any{"gateways"}, template.Resources["thing"].Properties["ThingGroups"])
+	assert.Equal(t, "AWS::IoT::Policy", template.Resources["telemetry"].Type)
+	assert.Equal(t, "ACTIVE", template.Resources["certificate"].Properties["Status"])
+}
+
+func TestAWSCertificateID(t *testing.T) {
+	key, err := helpers.GenerateECDSAKey(elliptic.P256())
+	assert.NoError(t, err)
+
+	cert, err := helpers.GenerateSelfSignedCertificate(key, "aws-id")
+	assert.NoError(t, err)
+
+	fingerprint := sha256.Sum256(cert.Raw)
+	assert.Equal(t, hex.EncodeToString(fingerprint[:]), awsCertificateID(cert))
+}
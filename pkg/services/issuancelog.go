@@ -0,0 +1,286 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/x509engines"
+)
+
+// appendIssuanceLogEntry appends the certificate to the issuance log of the CA, chained to the last entry of the log.
+func (svc *CAServiceBackend) appendIssuanceLogEntry(ctx context.Context, caID string, cert *x509.Certificate) error {
+	svc.issuanceLogLock.Lock()
+	defer svc.issuanceLogLock.Unlock()
+
+	index, err := svc.issuanceLogStorage.CountByCA(ctx, caID)
+	if err != nil {
+		return err
+	}
+
+	prevChainHash := []byte{}
+	if index > 0 {
+		exists, prev, err := svc.issuanceLogStorage.SelectExists(ctx, helpers.IssuanceLogEntryID(caID, index-1))
+		if err != nil {
+			return err
+		}
+
+		if !exists {
+			return fmt.Errorf("issuance log entry %d of CA %s not found", index-1, caID)
+		}
+
+		prevChainHash, err = hex.DecodeString(prev.ChainHash)
+		if err != nil {
+			return fmt.Errorf("invalid chain hash of issuance log entry %d of CA %s: %s", index-1, caID, err)
+		}
+	}
+
+	leafHash := helpers.MerkleLeafHash(cert.Raw)
+	_, err = svc.issuanceLogStorage.Insert(ctx, &models.IssuanceLogEntry{
+		ID:           helpers.IssuanceLogEntryID(caID, index),
+		CAID:         caID,
+		Index:        index,
+		SerialNumber: helpers.SerialNumberToString(cert.SerialNumber),
+		LeafHash:     hex.EncodeToString(leafHash),
+		ChainHash:    hex.EncodeToString(helpers.IssuanceLogChainHash(prevChainHash, leafHash)),
+		LoggedAt:     time.Now(),
+	})
+
+	return err
+}
+
+// issuanceLogEntries returns the entries of the issuance log of the CA sorted by index. The hash chain of the
+// entries is verified, so a tampered log is reported instead of being signed.
+func (svc *CAServiceBackend) issuanceLogEntries(ctx context.Context, caID string) ([]models.IssuanceLogEntry, error) {
+	entries := []models.IssuanceLogEntry{}
+	_, err := svc.issuanceLogStorage.SelectByCA(ctx, caID, storage.StorageListRequest[models.IssuanceLogEntry]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(entry models.IssuanceLogEntry) {
+			entries = append(entries, entry)
+		},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.Slice(entries, func(i, j int) bool {
+		return entries[i].Index < entries[j].Index
+	})
+
+	prevChainHash := []byte{}
+	for i, entry := range entries {
+		leafHash, err := hex.DecodeString(entry.LeafHash)
+		if err != nil || entry.Index != i {
+			return nil, fmt.Errorf("issuance log of CA %s is corrupted at index %d", caID, i)
+		}
+
+		chainHash := helpers.IssuanceLogChainHash(prevChainHash, leafHash)
+		if hex.EncodeToString(chainHash) != entry.ChainHash {
+			return nil, fmt.Errorf("issuance log of CA %s is corrupted at index %d", caID, i)
+		}
+		prevChainHash = chainHash
+	}
+
+	return entries, nil
+}
+
+// signIssuanceLogTreeHead computes the tree head of the first treeSize entries and signs it with the CA key.
+func (svc *CAServiceBackend) signIssuanceLogTreeHead(ctx context.Context, ca *models.CACertificate, entries []models.IssuanceLogEntry, treeSize int) (*models.IssuanceLogTreeHead, [][]byte, error) {
+	leaves := make([][]byte, treeSize)
+	for i := 0; i < treeSize; i++ {
+		leaves[i], _ = hex.DecodeString(entries[i].LeafHash)
+	}
+
+	chainHash := ""
+	if treeSize > 0 {
+		chainHash = entries[treeSize-1].ChainHash
+	}
+
+	head := models.IssuanceLogTreeHead{
+		CAID:      ca.ID,
+		TreeSize:  treeSize,
+		RootHash:  hex.EncodeToString(helpers.MerkleRootHash(leaves)),
+		ChainHash: chainHash,
+		Timestamp: time.Now(),
+	}
+
+	pub := ca.Certificate.Certificate.PublicKey
+	jwsAlg, err := helpers.DefaultJWSAlgorithm(pub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	signingAlg, err := helpers.JWSAlgorithmToSigningAlgorithm(pub, jwsAlg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	engine := svc.signingEngine(ctx, ca)
+	x509Engine := x509engines.NewX509Engine(engine, svc.vaServerDomain)
+	msg := helpers.IssuanceLogTreeHeadMessage(head.CAID, head.TreeSize, head.RootHash, head.ChainHash, head.Timestamp)
+	signature, err := x509Engine.Sign(x509engines.CertificateAuthority, (*x509.Certificate)(ca.Certificate.Certificate), msg, models.Raw, signingAlg)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	head.SigningAlgorithm = signingAlg
+	head.Signature = base64.StdEncoding.EncodeToString(signature)
+
+	return &head, leaves, nil
+}
+
+func (svc *CAServiceBackend) issuanceLogCA(ctx context.Context, caID string) (*models.CACertificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	if svc.issuanceLogStorage == nil {
+		lFunc.Errorf("issuance log is not enabled")
+		return nil, errs.ErrCAIssuanceLogNotEnabled
+	}
+
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, caID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", caID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", caID)
+		return nil, errs.ErrCANotFound
+	}
+
+	return ca, nil
+}
+
+type GetIssuanceLogTreeHeadInput struct {
+	CAID string `validate:"required"`
+}
+
+// GetIssuanceLogTreeHead returns the current tree head of the issuance log of the CA, signed with the CA key.
+// Returned Error Codes:
+//   - ErrCAIssuanceLogNotEnabled
+//     The issuance log is not enabled.
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+func (svc *CAServiceBackend) GetIssuanceLogTreeHead(ctx context.Context, input GetIssuanceLogTreeHeadInput) (*models.IssuanceLogTreeHead, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetIssuanceLogTreeHeadInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	ca, err := svc.issuanceLogCA(ctx, input.CAID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := svc.issuanceLogEntries(ctx, ca.ID)
+	if err != nil {
+		lFunc.Errorf("could not read issuance log of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	head, _, err := svc.signIssuanceLogTreeHead(ctx, ca, entries, len(entries))
+	if err != nil {
+		lFunc.Errorf("could not sign issuance log tree head of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	return head, nil
+}
+
+type GetIssuanceLogInclusionProofInput struct {
+	CAID         string `validate:"required"`
+	SerialNumber string `validate:"required"`
+	// TreeSize is the size of the tree the proof is computed for. The current size is used if 0.
+	TreeSize int `validate:"gte=0"`
+}
+
+// GetIssuanceLogInclusionProof returns the Merkle audit path of the certificate in the issuance log of the CA,
+// together with the signed tree head it leads to.
+// Returned Error Codes:
+//   - ErrCAIssuanceLogNotEnabled
+//     The issuance log is not enabled.
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrCAIssuanceLogEntryNotFound
+//     The certificate is not in the first TreeSize entries of the issuance log.
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid or TreeSize exceeds the size of the log.
+func (svc *CAServiceBackend) GetIssuanceLogInclusionProof(ctx context.Context, input GetIssuanceLogInclusionProofInput) (*models.IssuanceLogInclusionProof, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("GetIssuanceLogInclusionProofInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	ca, err := svc.issuanceLogCA(ctx, input.CAID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := svc.issuanceLogEntries(ctx, ca.ID)
+	if err != nil {
+		lFunc.Errorf("could not read issuance log of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	treeSize := input.TreeSize
+	if treeSize == 0 {
+		treeSize = len(entries)
+	} else if treeSize > len(entries) {
+		lFunc.Errorf("tree size %d exceeds the size %d of the issuance log of CA %s", treeSize, len(entries), ca.ID)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	var entry *models.IssuanceLogEntry
+	for i := 0; i < treeSize; i++ {
+		if entries[i].SerialNumber == input.SerialNumber {
+			entry = &entries[i]
+			break
+		}
+	}
+
+	if entry == nil {
+		lFunc.Errorf("certificate %s not found in the first %d entries of the issuance log of CA %s", input.SerialNumber, treeSize, ca.ID)
+		return nil, errs.ErrCAIssuanceLogEntryNotFound
+	}
+
+	head, leaves, err := svc.signIssuanceLogTreeHead(ctx, ca, entries, treeSize)
+	if err != nil {
+		lFunc.Errorf("could not sign issuance log tree head of CA %s: %s", ca.ID, err)
+		return nil, err
+	}
+
+	auditPath, err := helpers.MerkleInclusionProof(leaves, entry.Index)
+	if err != nil {
+		lFunc.Errorf("could not compute inclusion proof of certificate %s: %s", entry.SerialNumber, err)
+		return nil, err
+	}
+
+	proof := models.IssuanceLogInclusionProof{
+		SerialNumber: entry.SerialNumber,
+		LeafIndex:    entry.Index,
+		LeafHash:     entry.LeafHash,
+		AuditPath:    []string{},
+		TreeHead:     *head,
+	}
+	for _, node := range auditPath {
+		proof.AuditPath = append(proof.AuditPath, hex.EncodeToString(node))
+	}
+
+	return &proof, nil
+}
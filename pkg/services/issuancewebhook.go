@@ -0,0 +1,201 @@
+package services
+
+import (
+	"bytes"
+	"context"
+	"crypto/x509"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"slices"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/sirupsen/logrus"
+)
+
+// Usages of the certificates signed without explicit usages. They match the defaults of the x509 engine.
+var (
+	defaultIssuanceKeyUsages         = []models.KeyUsage{models.KeyUsageDigitalSignature}
+	defaultIssuanceExtendedKeyUsages = []models.ExtendedKeyUsage{models.ExtendedKeyUsageClientAuth, models.ExtendedKeyUsageServerAuth}
+)
+
+// reviewCertificateIssuance sends the certificate to be issued to the issuance webhook of the CA. If the webhook
+// replaces the template, the CSR subject is updated and the returned expiration and signing profile must be used.
+//
+// Returned Error Codes:
+//   - ErrCertificateIssuanceVetoed
+//     The webhook denied the issuance.
+//   - ErrCertificateIssuanceWebhook
+//     The webhook could not be reached, replied with an invalid response or its template widens the issuance.
+//     Only returned if the webhook does not fail open.
+func (svc *CAServiceBackend) reviewCertificateIssuance(ctx context.Context, lFunc *logrus.Entry, webhook models.CAIssuanceWebhook, caID string, csr *x509.CertificateRequest, expiration time.Time, profile *models.SigningProfile, certProfile *models.CertificateProfile) (time.Time, *models.SigningProfile, error) {
+	template := models.CertificateIssuanceTemplate{
+		Subject:           helpers.PkixNameToSubject(csr.Subject),
+		NotAfter:          expiration,
+		KeyUsages:         defaultIssuanceKeyUsages,
+		ExtendedKeyUsages: defaultIssuanceExtendedKeyUsages,
+	}
+	if profile != nil && len(profile.KeyUsages) > 0 {
+		template.KeyUsages = profile.KeyUsages
+	}
+	if profile != nil && len(profile.ExtendedKeyUsages) > 0 {
+		template.ExtendedKeyUsages = profile.ExtendedKeyUsages
+	}
+
+	lFunc.Debugf("requesting the review of the certificate issuance to the webhook of CA %s", caID)
+	response, err := svc.callIssuanceWebhook(ctx, webhook.URL, models.CertificateIssuanceReview{
+		CAID:               caID,
+		RequestID:          helpers.GetRequestID(ctx),
+		CertificateRequest: (*models.X509CertificateRequest)(csr),
+		Template:           template,
+	})
+	if err == nil && response.Template != nil {
+		err = validateIssuanceTemplate(csr, template, *response.Template, certProfile)
+	}
+
+	if err != nil {
+		if webhook.FailOpen {
+			lFunc.Warnf("issuance webhook of CA %s failed. Signing without review as the webhook fails open: %s", caID, err)
+			return expiration, profile, nil
+		}
+
+		lFunc.Errorf("issuance webhook of CA %s failed: %s", caID, err)
+		return expiration, profile, errs.ErrCertificateIssuanceWebhook
+	}
+
+	if !response.Allowed {
+		lFunc.Errorf("issuance webhook of CA %s vetoed the certificate: %s", caID, response.Reason)
+		return expiration, profile, errs.ErrCertificateIssuanceVetoed
+	}
+
+	if response.Template == nil {
+		return expiration, profile, nil
+	}
+
+	lFunc.Infof("issuance webhook of CA %s updated the certificate template", caID)
+	mutated := *response.Template
+	csr.Subject = helpers.SubjectToPkixName(mutated.Subject)
+
+	mutatedProfile := models.SigningProfile{}
+	if profile != nil {
+		mutatedProfile = *profile
+	}
+	mutatedProfile.KeyUsages = mutated.KeyUsages
+	mutatedProfile.ExtendedKeyUsages = mutated.ExtendedKeyUsages
+
+	return mutated.NotAfter, &mutatedProfile, nil
+}
+
+func (svc *CAServiceBackend) callIssuanceWebhook(ctx context.Context, url string, review models.CertificateIssuanceReview) (*models.CertificateIssuanceReviewResponse, error) {
+	body, err := json.Marshal(review)
+	if err != nil {
+		return nil, err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	resp, err := svc.issuanceWebhookClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("unexpected status code %d", resp.StatusCode)
+	}
+
+	respBody, err := io.ReadAll(io.LimitReader(resp.Body, 1024*1024))
+	if err != nil {
+		return nil, err
+	}
+
+	var response models.CertificateIssuanceReviewResponse
+	err = json.Unmarshal(respBody, &response)
+	if err != nil {
+		return nil, fmt.Errorf("could not decode response: %s", err)
+	}
+
+	return &response, nil
+}
+
+// validateIssuanceSubject checks that the subject returned by the webhook only removes attributes of the reviewed
+// one (or reorders them), so the certificate can not be issued for a different identity than the reviewed CSR.
+func validateIssuanceSubject(reviewed, mutated models.Subject) error {
+	attributes := []struct {
+		name              string
+		reviewed, mutated string
+	}{
+		{"common name", reviewed.CommonName, mutated.CommonName},
+		{"organization", reviewed.Organization, mutated.Organization},
+		{"organization unit", reviewed.OrganizationUnit, mutated.OrganizationUnit},
+		{"country", reviewed.Country, mutated.Country},
+		{"state", reviewed.State, mutated.State},
+		{"locality", reviewed.Locality, mutated.Locality},
+		{"serial number", reviewed.SerialNumber, mutated.SerialNumber},
+		{"email address", reviewed.EmailAddress, mutated.EmailAddress},
+	}
+
+	for _, attribute := range attributes {
+		if attribute.mutated != "" && attribute.mutated != attribute.reviewed {
+			return fmt.Errorf("subject %s '%s' was not in the reviewed template", attribute.name, attribute.mutated)
+		}
+	}
+
+	for _, dc := range mutated.DomainComponents {
+		if !slices.Contains(reviewed.DomainComponents, dc) {
+			return fmt.Errorf("subject domain component '%s' was not in the reviewed template", dc)
+		}
+	}
+
+	return nil
+}
+
+// validateIssuanceTemplate checks that the template returned by the webhook only narrows the reviewed one.
+func validateIssuanceTemplate(csr *x509.CertificateRequest, reviewed models.CertificateIssuanceTemplate, mutated models.CertificateIssuanceTemplate, certProfile *models.CertificateProfile) error {
+	if mutated.NotAfter.After(reviewed.NotAfter) || !mutated.NotAfter.After(time.Now()) {
+		return fmt.Errorf("not after %s must be between now and %s", mutated.NotAfter, reviewed.NotAfter)
+	}
+
+	if len(mutated.KeyUsages) == 0 {
+		return fmt.Errorf("key usages can not be empty")
+	}
+
+	for _, usage := range mutated.KeyUsages {
+		if !slices.Contains(reviewed.KeyUsages, usage) {
+			return fmt.Errorf("key usage %s was not in the reviewed template", usage)
+		}
+	}
+
+	for _, usage := range mutated.ExtendedKeyUsages {
+		if !slices.Contains(reviewed.ExtendedKeyUsages, usage) {
+			return fmt.Errorf("extended key usage %s was not in the reviewed template", usage)
+		}
+	}
+
+	err := validateIssuanceSubject(reviewed.Subject, mutated.Subject)
+	if err != nil {
+		return err
+	}
+
+	err = helpers.ValidateSubjectOrder(mutated.Subject.Order)
+	if err != nil {
+		return err
+	}
+
+	if certProfile != nil {
+		err = helpers.ValidateCertificateRequestWithProfile(csr, mutated.Subject, *certProfile)
+		if err != nil {
+			return fmt.Errorf("subject does not satisfy certificate profile %s: %s", certProfile.ID, err)
+		}
+	}
+
+	return nil
+}
@@ -2,6 +2,7 @@ package mock
 
 import (
 	"context"
+	"time"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
@@ -31,6 +32,56 @@ func (m *MockCAService) GetStatsByCAID(ctx context.Context, input services.GetSt
 	return args.Get(0).(map[models.CertificateStatus]int), args.Error(1)
 }
 
+func (m *MockCAService) GetPendingCAAction(ctx context.Context, input services.GetPendingCAActionInput) (*models.CAPendingAction, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CAPendingAction), args.Error(1)
+}
+
+func (m *MockCAService) ApprovePendingCAAction(ctx context.Context, input services.ApprovePendingCAActionInput) (*models.CAPendingAction, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CAPendingAction), args.Error(1)
+}
+
+func (m *MockCAService) MigrateCAKey(ctx context.Context, input services.MigrateCAKeyInput) (*models.CACertificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CACertificate), args.Error(1)
+}
+
+func (m *MockCAService) SetCAFallbackEngine(ctx context.Context, input services.SetCAFallbackEngineInput) (*models.CACertificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CACertificate), args.Error(1)
+}
+
+func (m *MockCAService) SetCAIssuanceWebhook(ctx context.Context, input services.SetCAIssuanceWebhookInput) (*models.CACertificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CACertificate), args.Error(1)
+}
+
+func (m *MockCAService) ExportCAKey(ctx context.Context, input services.ExportCAKeyInput) (*models.CAKeyBackup, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CAKeyBackup), args.Error(1)
+}
+
+func (m *MockCAService) RestoreCAKey(ctx context.Context, input services.RestoreCAKeyInput) (*models.CACertificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CACertificate), args.Error(1)
+}
+
+func (m *MockCAService) GetSoftwareKeyCustodyReport(ctx context.Context) (*models.SoftwareKeyCustodyReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.SoftwareKeyCustodyReport), args.Error(1)
+}
+
+func (m *MockCAService) ExportCAInventory(ctx context.Context) (*models.CAInventory, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.CAInventory), args.Error(1)
+}
+
+func (m *MockCAService) GetKeyStrengthReport(ctx context.Context) (*models.KeyStrengthReport, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.KeyStrengthReport), args.Error(1)
+}
+
 func (m *MockCAService) GetCryptoEngineProvider(ctx context.Context) ([]*models.CryptoEngineProvider, error) {
 	args := m.Called(ctx)
 	return args.Get(0).([]*models.CryptoEngineProvider), args.Error(1)
@@ -66,6 +117,10 @@ func (m *MockCAService) UpdateCAMetadata(ctx context.Context, input services.Upd
 	return args.Get(0).(*models.CACertificate), args.Error(1)
 
 }
+func (m *MockCAService) UpdateCASettings(ctx context.Context, input services.UpdateCASettingsInput) (*models.CACertificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CACertificate), args.Error(1)
+}
 func (m *MockCAService) DeleteCA(ctx context.Context, input services.DeleteCAInput) error {
 	args := m.Called(ctx, input)
 	return args.Error(0)
@@ -89,6 +144,11 @@ func (m *MockCAService) SignCertificate(ctx context.Context, input services.Sign
 	return args.Get(0).(*models.Certificate), args.Error(1)
 }
 
+func (m *MockCAService) SignCertificatesBatch(ctx context.Context, input services.SignCertificatesBatchInput) ([]models.CertificateSigningResult, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).([]models.CertificateSigningResult), args.Error(1)
+}
+
 func (m *MockCAService) CreateCertificate(ctx context.Context, input services.CreateCertificateInput) (*models.Certificate, error) {
 	args := m.Called(ctx, input)
 	return args.Get(0).(*models.Certificate), args.Error(1)
@@ -106,6 +166,10 @@ func (m *MockCAService) GetCertificates(ctx context.Context, input services.GetC
 	args := m.Called(ctx, input)
 	return args.String(0), args.Error(1)
 }
+func (m *MockCAService) ExportCertificates(ctx context.Context, input services.ExportCertificatesInput) (time.Time, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(time.Time), args.Error(1)
+}
 func (m *MockCAService) GetCertificatesByCA(ctx context.Context, input services.GetCertificatesByCAInput) (string, error) {
 	args := m.Called(ctx, input)
 	return args.String(0), args.Error(1)
@@ -123,3 +187,98 @@ func (m *MockCAService) GetCertificatesByStatus(ctx context.Context, input servi
 	args := m.Called(ctx, input)
 	return args.String(0), args.Error(1)
 }
+
+func (m *MockCAService) SignSVID(ctx context.Context, input services.SignSVIDInput) (*models.Certificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.Certificate), args.Error(1)
+}
+
+func (m *MockCAService) SignToken(ctx context.Context, input services.SignTokenInput) (*models.SignedToken, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.SignedToken), args.Error(1)
+}
+
+func (m *MockCAService) GetTokenSigningKeys(ctx context.Context) (*models.JWKS, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.JWKS), args.Error(1)
+}
+
+func (m *MockCAService) GetJWKS(ctx context.Context) (*models.JWKS, error) {
+	args := m.Called(ctx)
+	return args.Get(0).(*models.JWKS), args.Error(1)
+}
+
+func (m *MockCAService) CreateCertificateProfile(ctx context.Context, input services.CreateCertificateProfileInput) (*models.CertificateProfile, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CertificateProfile), args.Error(1)
+}
+
+func (m *MockCAService) GetCertificateProfileByID(ctx context.Context, input services.GetCertificateProfileByIDInput) (*models.CertificateProfile, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CertificateProfile), args.Error(1)
+}
+
+func (m *MockCAService) GetCertificateProfiles(ctx context.Context, input services.GetCertificateProfilesInput) (string, error) {
+	args := m.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCAService) UpdateCertificateProfile(ctx context.Context, input services.UpdateCertificateProfileInput) (*models.CertificateProfile, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CertificateProfile), args.Error(1)
+}
+
+func (m *MockCAService) DeleteCertificateProfile(ctx context.Context, input services.DeleteCertificateProfileInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockCAService) GetIssuanceLogTreeHead(ctx context.Context, input services.GetIssuanceLogTreeHeadInput) (*models.IssuanceLogTreeHead, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.IssuanceLogTreeHead), args.Error(1)
+}
+
+func (m *MockCAService) GetIssuanceLogInclusionProof(ctx context.Context, input services.GetIssuanceLogInclusionProofInput) (*models.IssuanceLogInclusionProof, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.IssuanceLogInclusionProof), args.Error(1)
+}
+
+func (m *MockCAService) GetCAEvents(ctx context.Context, input services.GetCAEventsInput) ([]models.CAEvent, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).([]models.CAEvent), args.Error(1)
+}
+
+func (m *MockCAService) ReportCRLGeneration(ctx context.Context, input services.ReportCRLGenerationInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (m *MockCAService) CreateSuccessorCA(ctx context.Context, input services.CreateSuccessorCAInput) (*models.CASuccession, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CASuccession), args.Error(1)
+}
+
+func (m *MockCAService) CreateCASigningRequest(ctx context.Context, input services.CreateCASigningRequestInput) (*models.CASigningRequest, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CASigningRequest), args.Error(1)
+}
+
+func (m *MockCAService) GetCASigningRequests(ctx context.Context, input services.GetCASigningRequestsInput) (string, error) {
+	args := m.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockCAService) GetCASigningRequestByID(ctx context.Context, input services.GetCASigningRequestByIDInput) (*models.CASigningRequest, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CASigningRequest), args.Error(1)
+}
+
+func (m *MockCAService) ApproveCASigningRequest(ctx context.Context, input services.ApproveCASigningRequestInput) (*models.CASigningRequest, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CASigningRequest), args.Error(1)
+}
+
+func (m *MockCAService) RejectCASigningRequest(ctx context.Context, input services.RejectCASigningRequestInput) (*models.CASigningRequest, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CASigningRequest), args.Error(1)
+}
@@ -12,11 +12,26 @@ type MockDeviceManagerService struct {
 	mock.Mock
 }
 
+func (dm *MockDeviceManagerService) GetIssuanceReport(ctx context.Context, input services.GetIssuanceReportInput) (*models.IssuanceReport, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.IssuanceReport), args.Error(1)
+}
+
 func (dm *MockDeviceManagerService) GetDevicesStats(ctx context.Context, input services.GetDevicesStatsInput) (*models.DevicesStats, error) {
 	args := dm.Called(ctx, input)
 	return args.Get(0).(*models.DevicesStats), args.Error(1)
 }
 
+func (dm *MockDeviceManagerService) GetDeviceByCertificate(ctx context.Context, input services.GetDeviceByCertificateInput) (*models.CertificateDeviceBinding, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.CertificateDeviceBinding), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDevicesOutOfCloudSync(ctx context.Context, input services.GetDevicesOutOfCloudSyncInput) (string, error) {
+	args := dm.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
 func (dm *MockDeviceManagerService) CreateDevice(ctx context.Context, input services.CreateDeviceInput) (*models.Device, error) {
 	args := dm.Called(ctx, input)
 	return args.Get(0).(*models.Device), args.Error(1)
@@ -47,7 +62,112 @@ func (dm *MockDeviceManagerService) UpdateDeviceIdentitySlot(ctx context.Context
 	return args.Get(0).(*models.Device), args.Error(1)
 }
 
+func (dm *MockDeviceManagerService) UpdateDeviceConnectionMetadata(ctx context.Context, input services.UpdateDeviceConnectionMetadataInput) (*models.Device, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.Device), args.Error(1)
+}
+
 func (dm *MockDeviceManagerService) UpdateDeviceMetadata(ctx context.Context, input services.UpdateDeviceMetadataInput) (*models.Device, error) {
 	args := dm.Called(ctx, input)
 	return args.Get(0).(*models.Device), args.Error(1)
 }
+
+func (dm *MockDeviceManagerService) CreateDeviceLog(ctx context.Context, input services.CreateDeviceLogInput) (*models.DeviceLog, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceLog), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceLogs(ctx context.Context, input services.GetDeviceLogsInput) (string, error) {
+	args := dm.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) PruneDeviceLogs(ctx context.Context, input services.PruneDeviceLogsInput) (int, error) {
+	args := dm.Called(ctx, input)
+	return args.Int(0), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) ReportDeviceHeartbeat(ctx context.Context, input services.ReportDeviceHeartbeatInput) (*models.Device, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.Device), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) ForceDeviceReenroll(ctx context.Context, input services.ForceDeviceReenrollInput) (*models.Device, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.Device), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) ProvisionDeviceSecretSlot(ctx context.Context, input services.ProvisionDeviceSecretSlotInput) (*models.Device, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.Device), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) RotateDeviceSecretSlot(ctx context.Context, input services.RotateDeviceSecretSlotInput) (*models.Device, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.Device), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceSecretSlot(ctx context.Context, input services.GetDeviceSecretSlotInput) (*models.EncryptedSlotSecret, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.EncryptedSlotSecret), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) CreateDeviceGroup(ctx context.Context, input services.CreateDeviceGroupInput) (*models.DeviceGroup, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroup), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceGroups(ctx context.Context, input services.GetDeviceGroupsInput) (string, error) {
+	args := dm.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceGroupByID(ctx context.Context, input services.GetDeviceGroupByIDInput) (*models.DeviceGroup, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroup), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) UpdateDeviceGroupDevices(ctx context.Context, input services.UpdateDeviceGroupDevicesInput) (*models.DeviceGroup, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroup), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) UpdateDeviceGroupTags(ctx context.Context, input services.UpdateDeviceGroupTagsInput) (*models.DeviceGroup, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroup), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) UpdateDeviceGroupFilters(ctx context.Context, input services.UpdateDeviceGroupFiltersInput) (*models.DeviceGroup, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroup), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) DeleteDeviceGroup(ctx context.Context, input services.DeleteDeviceGroupInput) error {
+	args := dm.Called(ctx, input)
+	return args.Error(0)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceGroupDevices(ctx context.Context, input services.GetDeviceGroupDevicesInput) (string, error) {
+	args := dm.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) StartDeviceGroupBulkAction(ctx context.Context, input services.StartDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroupBulkAction), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) ApproveDeviceGroupBulkAction(ctx context.Context, input services.ApproveDeviceGroupBulkActionInput) (*models.DeviceGroupBulkAction, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroupBulkAction), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceGroupBulkActions(ctx context.Context, input services.GetDeviceGroupBulkActionsInput) (string, error) {
+	args := dm.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (dm *MockDeviceManagerService) GetDeviceGroupBulkActionByID(ctx context.Context, input services.GetDeviceGroupBulkActionByIDInput) (*models.DeviceGroupBulkAction, error) {
+	args := dm.Called(ctx, input)
+	return args.Get(0).(*models.DeviceGroupBulkAction), args.Error(1)
+}
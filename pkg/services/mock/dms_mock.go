@@ -18,6 +18,11 @@ func (m *MockDMSManagerService) CACerts(ctx context.Context, aps string) ([]*x50
 	return args.Get(0).([]*x509.Certificate), args.Error(1)
 }
 
+func (m *MockDMSManagerService) SignedCACerts(ctx context.Context, aps string, pemEncoded bool) (*models.SignedContent, error) {
+	args := m.Called(ctx, aps, pemEncoded)
+	return args.Get(0).(*models.SignedContent), args.Error(1)
+}
+
 func (m *MockDMSManagerService) ServerKeyGen(ctx context.Context, csr *x509.CertificateRequest, aps string) (*x509.Certificate, interface{}, error) {
 	args := m.Called(ctx, csr, aps)
 	return args.Get(0).(*x509.Certificate), args.Get(1), args.Error(2)
@@ -38,6 +43,11 @@ func (m *MockDMSManagerService) GetDMSStats(ctx context.Context, input services.
 	return args.Get(0).(*models.DMSStats), args.Error(1)
 }
 
+func (m *MockDMSManagerService) GetDMSEnrollmentStats(ctx context.Context, input services.GetDMSEnrollmentStatsInput) (*models.DMSEnrollmentStats, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSEnrollmentStats), args.Error(1)
+}
+
 func (m *MockDMSManagerService) CreateDMS(ctx context.Context, input services.CreateDMSInput) (*models.DMS, error) {
 	args := m.Called(ctx, input)
 	return args.Get(0).(*models.DMS), args.Error(1)
@@ -62,3 +72,118 @@ func (m *MockDMSManagerService) BindIdentityToDevice(ctx context.Context, input
 	args := m.Called(ctx, input)
 	return args.Get(0).(*models.BindIdentityToDeviceOutput), args.Error(1)
 }
+
+func (m *MockDMSManagerService) RevokeSupersededCertificate(ctx context.Context, input services.RevokeSupersededCertificateInput) (*models.Certificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.Certificate), args.Error(1)
+}
+
+func (m *MockDMSManagerService) RevokeDeviceCertificate(ctx context.Context, input services.RevokeDeviceCertificateInput) (*models.Certificate, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.Certificate), args.Error(1)
+}
+
+func (m *MockDMSManagerService) StartCARotation(ctx context.Context, input services.StartCARotationInput) (*models.CARotation, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CARotation), args.Error(1)
+}
+
+func (m *MockDMSManagerService) ProcessCARotationBatch(ctx context.Context, input services.ProcessCARotationBatchInput) (*models.CARotation, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CARotation), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetCARotation(ctx context.Context, input services.GetCARotationInput) (*models.CARotation, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.CARotation), args.Error(1)
+}
+
+func (m *MockDMSManagerService) CreateACMEEABKey(ctx context.Context, input services.CreateACMEEABKeyInput) (*models.DMSACMEEABCredentials, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSACMEEABCredentials), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetACMEEABKeys(ctx context.Context, input services.GetACMEEABKeysInput) ([]models.DMSACMEEABKey, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).([]models.DMSACMEEABKey), args.Error(1)
+}
+
+func (m *MockDMSManagerService) RevokeACMEEABKey(ctx context.Context, input services.RevokeACMEEABKeyInput) (*models.DMSACMEEABKey, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSACMEEABKey), args.Error(1)
+}
+
+func (m *MockDMSManagerService) UpdateDMSStatus(ctx context.Context, input services.UpdateDMSStatusInput) (*models.DMS, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMS), args.Error(1)
+}
+
+func (m *MockDMSManagerService) CreateEnrollmentPolicy(ctx context.Context, input services.CreateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSEnrollmentPolicy), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetEnrollmentPolicies(ctx context.Context, input services.GetEnrollmentPoliciesInput) (string, error) {
+	args := m.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDMSManagerService) CreateBootstrapToken(ctx context.Context, input services.CreateBootstrapTokenInput) (*models.DMSBootstrapTokenCredentials, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSBootstrapTokenCredentials), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetBootstrapTokens(ctx context.Context, input services.GetBootstrapTokensInput) ([]models.DMSBootstrapToken, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).([]models.DMSBootstrapToken), args.Error(1)
+}
+
+func (m *MockDMSManagerService) RevokeBootstrapToken(ctx context.Context, input services.RevokeBootstrapTokenInput) (*models.DMSBootstrapToken, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSBootstrapToken), args.Error(1)
+}
+
+func (m *MockDMSManagerService) CreateClaimCode(ctx context.Context, input services.CreateClaimCodeInput) (*models.DMSClaimCodeCredentials, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSClaimCodeCredentials), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetClaimCodes(ctx context.Context, input services.GetClaimCodesInput) ([]models.DMSClaimCode, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).([]models.DMSClaimCode), args.Error(1)
+}
+
+func (m *MockDMSManagerService) RevokeClaimCode(ctx context.Context, input services.RevokeClaimCodeInput) (*models.DMSClaimCode, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSClaimCode), args.Error(1)
+}
+
+func (m *MockDMSManagerService) ClaimDevice(ctx context.Context, input services.ClaimDeviceInput) (*models.DeviceClaim, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DeviceClaim), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetEnrollmentAudits(ctx context.Context, input services.GetEnrollmentAuditsInput) (string, error) {
+	args := m.Called(ctx, input)
+	return args.String(0), args.Error(1)
+}
+
+func (m *MockDMSManagerService) PreregisterDevices(ctx context.Context, input services.PreregisterDevicesInput) (*models.DevicePreregistration, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DevicePreregistration), args.Error(1)
+}
+
+func (m *MockDMSManagerService) GetEnrollmentPolicyByID(ctx context.Context, input services.GetEnrollmentPolicyByIDInput) (*models.DMSEnrollmentPolicy, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSEnrollmentPolicy), args.Error(1)
+}
+
+func (m *MockDMSManagerService) UpdateEnrollmentPolicy(ctx context.Context, input services.UpdateEnrollmentPolicyInput) (*models.DMSEnrollmentPolicy, error) {
+	args := m.Called(ctx, input)
+	return args.Get(0).(*models.DMSEnrollmentPolicy), args.Error(1)
+}
+
+func (m *MockDMSManagerService) DeleteEnrollmentPolicy(ctx context.Context, input services.DeleteEnrollmentPolicyInput) error {
+	args := m.Called(ctx, input)
+	return args.Error(0)
+}
@@ -1,10 +1,14 @@
 package services
 
 import (
+	"bytes"
 	"context"
 	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
 	"time"
 
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/sirupsen/logrus"
@@ -32,6 +36,13 @@ func NewOCSPService(builder OCSPServiceBuilder) OCSPService {
 	}
 }
 
+// Verify signs the OCSP response of the requested certificate. Requests whose issuer hashes do not identify the CA
+// that issued the certificate are answered as unauthorized.
+// Returned Error Codes:
+//   - ErrCertificateNotFound
+//     The requested certificate has not been issued by Lamassu.
+//   - ErrOCSPIssuerMismatch
+//     The issuer name or key hashes of the request do not match the issuer of the certificate.
 func (svc ocspResponder) Verify(ctx context.Context, req *ocsp.Request) ([]byte, error) {
 	ocspCrtSN := helpers.SerialNumberToString(req.SerialNumber)
 	crt, err := svc.caSDK.GetCertificateBySerialNumber(ctx, GetCertificatesBySerialNumberInput{
@@ -48,6 +59,11 @@ func (svc ocspResponder) Verify(ctx context.Context, req *ocsp.Request) ([]byte,
 		return nil, err
 	}
 
+	if !ocspIssuerMatches(req, (*x509.Certificate)(ca.Certificate.Certificate)) {
+		svc.logger.Errorf("OCSP request issuer hashes do not match CA %s, the issuer of certificate %s", ca.ID, ocspCrtSN)
+		return nil, errs.ErrOCSPIssuerMismatch
+	}
+
 	status := ocsp.Unknown
 	var revokedAt time.Time
 	if crt.Status == models.StatusRevoked {
@@ -83,3 +99,27 @@ func (svc ocspResponder) Verify(ctx context.Context, req *ocsp.Request) ([]byte,
 
 	return rawResp, nil
 }
+
+// ocspIssuerMatches checks the CertID of the request identifies the issuer: the issuer name hash is computed over
+// the DER encoded subject of the issuer and the issuer key hash over its public key bit string (RFC 6960 section 4.1.1).
+func ocspIssuerMatches(req *ocsp.Request, issuer *x509.Certificate) bool {
+	if !req.HashAlgorithm.Available() {
+		return false
+	}
+
+	var spki struct {
+		Algorithm pkix.AlgorithmIdentifier
+		PublicKey asn1.BitString
+	}
+	if _, err := asn1.Unmarshal(issuer.RawSubjectPublicKeyInfo, &spki); err != nil {
+		return false
+	}
+
+	nameHash := req.HashAlgorithm.New()
+	nameHash.Write(issuer.RawSubject)
+
+	keyHash := req.HashAlgorithm.New()
+	keyHash.Write(spki.PublicKey.RightAlign())
+
+	return bytes.Equal(req.IssuerNameHash, nameHash.Sum(nil)) && bytes.Equal(req.IssuerKeyHash, keyHash.Sum(nil))
+}
@@ -0,0 +1,118 @@
+package services
+
+import (
+	"context"
+	"crypto/x509"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/errs"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+const defaultSVIDValidity = time.Hour
+
+type SignSVIDInput struct {
+	CAID        string                         `validate:"required"`
+	CertRequest *models.X509CertificateRequest `validate:"required"`
+	// SPIFFEID defaults to the URI SAN of the CSR.
+	SPIFFEID string
+	// Validity is capped to the validity of the CA SPIFFE profile.
+	Validity *models.TimeDuration
+}
+
+// SignSVID signs a SPIFFE X509-SVID with a CA enabled with the CAMetadataSPIFFEKey metadata. The SVID has the SPIFFE
+// ID as its only SAN, the subject of the CSR (which may be empty) and the digital signature, key encipherment and key
+// agreement usages. The SPIFFE ID must belong to the trust domain of the CA profile.
+//
+// Returned Error Codes:
+//   - ErrCANotFound
+//     The specified CA can not be found in the Database
+//   - ErrValidateBadRequest
+//     The required variables of the data structure are not valid.
+//   - ErrCASPIFFENotEnabled
+//     The CA has not been enabled to issue SVIDs.
+//   - ErrSVIDInvalidSPIFFEID
+//     The SPIFFE ID is not valid, is not in the CA trust domain or does not match the URI SAN of the CSR.
+//
+// The errors returned by SignCertificate also apply.
+func (svc *CAServiceBackend) SignSVID(ctx context.Context, input SignSVIDInput) (*models.Certificate, error) {
+	lFunc := helpers.ConfigureLogger(ctx, svc.logger)
+
+	err := validate.Struct(input)
+	if err != nil {
+		lFunc.Errorf("SignSVIDInput struct validation error: %s", err)
+		return nil, errs.ErrValidateBadRequest
+	}
+
+	exists, ca, err := svc.caStorage.SelectExistsByID(ctx, input.CAID)
+	if err != nil {
+		lFunc.Errorf("something went wrong while checking if CA '%s' exists in storage engine: %s", input.CAID, err)
+		return nil, err
+	}
+
+	if !exists {
+		lFunc.Errorf("CA %s can not be found in storage engine", input.CAID)
+		return nil, errs.ErrCANotFound
+	}
+
+	var profile models.CASPIFFEProfile
+	hasProfile, err := helpers.GetMetadataToStruct(ca.Metadata, models.CAMetadataSPIFFEKey, &profile)
+	if err != nil || !hasProfile || profile.TrustDomain == "" {
+		lFunc.Errorf("CA %s is not enabled to issue SVIDs. Set the %s metadata key", ca.ID, models.CAMetadataSPIFFEKey)
+		return nil, errs.ErrCASPIFFENotEnabled
+	}
+
+	csr := (*x509.CertificateRequest)(input.CertRequest)
+	rawID := input.SPIFFEID
+	if rawID == "" && len(csr.URIs) == 1 {
+		rawID = csr.URIs[0].String()
+	}
+
+	spiffeID, err := helpers.ParseSPIFFEID(rawID)
+	if err != nil {
+		lFunc.Errorf("invalid SPIFFE ID '%s': %s", rawID, err)
+		return nil, errs.ErrSVIDInvalidSPIFFEID
+	}
+
+	if spiffeID.Host != profile.TrustDomain || spiffeID.Path == "" {
+		lFunc.Errorf("SPIFFE ID %s does not identify a workload of trust domain %s", spiffeID, profile.TrustDomain)
+		return nil, errs.ErrSVIDInvalidSPIFFEID
+	}
+
+	svidCSR, err := helpers.SVIDCertificateRequest(csr, spiffeID)
+	if err != nil {
+		lFunc.Errorf("invalid SVID certificate request: %s", err)
+		return nil, errs.ErrSVIDInvalidSPIFFEID
+	}
+
+	validity := models.TimeDuration(defaultSVIDValidity)
+	if profile.Validity != nil {
+		validity = *profile.Validity
+	}
+
+	if input.Validity != nil {
+		if *input.Validity <= 0 {
+			lFunc.Errorf("invalid SVID validity: %s", input.Validity)
+			return nil, errs.ErrValidateBadRequest
+		}
+
+		if *input.Validity < validity {
+			validity = *input.Validity
+		} else {
+			lFunc.Warnf("requested SVID validity %s exceeds the validity %s of CA %s. Capping", input.Validity, validity, ca.ID)
+		}
+	}
+
+	lFunc.Infof("signing X509-SVID for %s with CA %s", spiffeID, ca.ID)
+	return svc.service.SignCertificate(ctx, SignCertificateInput{
+		CAID:         ca.ID,
+		CertRequest:  (*models.X509CertificateRequest)(svidCSR),
+		SignVerbatim: true,
+		SigningProfile: &models.SigningProfile{
+			KeyUsages:         []models.KeyUsage{models.KeyUsageDigitalSignature, models.KeyUsageKeyEncipherment, models.KeyUsageKeyAgreement},
+			ExtendedKeyUsages: []models.ExtendedKeyUsage{models.ExtendedKeyUsageServerAuth, models.ExtendedKeyUsageClientAuth},
+			Validity:          &validity,
+		},
+	})
+}
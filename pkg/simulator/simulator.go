@@ -0,0 +1,193 @@
+package simulator
+
+import (
+	"context"
+	"crypto"
+	"crypto/elliptic"
+	"crypto/x509"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/services"
+	"github.com/sirupsen/logrus"
+	"golang.org/x/crypto/ocsp"
+)
+
+// ESTClient is implemented by the EST client (github.com/globalsign/est) used by the devices.
+type ESTClient interface {
+	Enroll(ctx context.Context, csr *x509.CertificateRequest) (*x509.Certificate, error)
+	Reenroll(ctx context.Context, csr *x509.CertificateRequest) (*x509.Certificate, error)
+}
+
+// ESTClientBuilder returns an EST client authenticated with the given certificate and key. It is called
+// with the bootstrap credentials to enroll and with the device credentials to re-enroll.
+type ESTClientBuilder func(cert *x509.Certificate, key crypto.Signer) ESTClient
+
+type Config struct {
+	Devices        int
+	DeviceIDPrefix string
+	// KeyType of the device keys: RSA (2048 bits) or ECDSA (P-256)
+	KeyType x509.PublicKeyAlgorithm
+	// Reenrollments performed by each device, waiting ReenrollInterval between them
+	Reenrollments    int
+	ReenrollInterval time.Duration
+	// Revoke the last certificate of each device once the device finishes its re-enrollments
+	Revoke bool
+	// RampUp spreads the start of the devices along the given period
+	RampUp time.Duration
+}
+
+// Simulator spawns virtual devices that enroll, periodically re-enroll and revoke their certificates against a
+// Lamassu deployment, to measure how it behaves under load.
+type Simulator struct {
+	conf          Config
+	bootstrapCert *x509.Certificate
+	bootstrapKey  crypto.Signer
+	estClient     ESTClientBuilder
+	caClient      services.CAService
+	recorder      *Recorder
+	logger        *logrus.Entry
+}
+
+func NewSimulator(conf Config, bootstrapCert *x509.Certificate, bootstrapKey crypto.Signer, estClient ESTClientBuilder, caClient services.CAService, logger *logrus.Entry) *Simulator {
+	return &Simulator{
+		conf:          conf,
+		bootstrapCert: bootstrapCert,
+		bootstrapKey:  bootstrapKey,
+		estClient:     estClient,
+		caClient:      caClient,
+		recorder:      NewRecorder(),
+		logger:        logger,
+	}
+}
+
+// Run blocks until all the devices finish or the context is cancelled, and returns the report of each operation.
+func (sim *Simulator) Run(ctx context.Context) []OperationReport {
+	var wg sync.WaitGroup
+	for i := 0; i < sim.conf.Devices; i++ {
+		deviceID := fmt.Sprintf("%s%d", sim.conf.DeviceIDPrefix, i)
+
+		var delay time.Duration
+		if sim.conf.Devices > 1 {
+			delay = sim.conf.RampUp * time.Duration(i) / time.Duration(sim.conf.Devices)
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if !sleep(ctx, delay) {
+				return
+			}
+
+			err := sim.runDevice(ctx, deviceID)
+			if err != nil {
+				sim.logger.Warnf("device %s stopped: %s", deviceID, err)
+			}
+		}()
+	}
+
+	wg.Wait()
+	return sim.recorder.Report()
+}
+
+func (sim *Simulator) runDevice(ctx context.Context, deviceID string) error {
+	var key crypto.Signer
+	err := sim.measure(OperationKeyGen, func() error {
+		var err error
+		key, err = generateKey(sim.conf.KeyType)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not generate key: %w", err)
+	}
+
+	csr, err := helpers.GenerateCertificateRequest(models.Subject{CommonName: deviceID}, key)
+	if err != nil {
+		return fmt.Errorf("could not generate CSR: %w", err)
+	}
+
+	var cert *x509.Certificate
+	err = sim.measure(OperationEnroll, func() error {
+		var err error
+		cert, err = sim.estClient(sim.bootstrapCert, sim.bootstrapKey).Enroll(ctx, csr)
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not enroll: %w", err)
+	}
+
+	for i := 0; i < sim.conf.Reenrollments; i++ {
+		if !sleep(ctx, sim.conf.ReenrollInterval) {
+			return ctx.Err()
+		}
+
+		err = sim.measure(OperationReenroll, func() error {
+			newCert, err := sim.estClient(cert, key).Reenroll(ctx, csr)
+			if err != nil {
+				return err
+			}
+
+			cert = newCert
+			return nil
+		})
+		if err != nil {
+			return fmt.Errorf("could not reenroll: %w", err)
+		}
+	}
+
+	if !sim.conf.Revoke {
+		return nil
+	}
+
+	err = sim.measure(OperationRevoke, func() error {
+		_, err := sim.caClient.UpdateCertificateStatus(ctx, services.UpdateCertificateStatusInput{
+			SerialNumber:     helpers.SerialNumberToString(cert.SerialNumber),
+			NewStatus:        models.StatusRevoked,
+			RevocationReason: ocsp.CessationOfOperation,
+		})
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("could not revoke certificate: %w", err)
+	}
+
+	return nil
+}
+
+func (sim *Simulator) measure(op Operation, fn func() error) error {
+	start := time.Now()
+	err := fn()
+	sim.recorder.Record(op, time.Since(start), err)
+	return err
+}
+
+func generateKey(keyType x509.PublicKeyAlgorithm) (crypto.Signer, error) {
+	switch keyType {
+	case x509.RSA:
+		return helpers.GenerateRSAKey(2048)
+	case x509.ECDSA:
+		return helpers.GenerateECDSAKey(elliptic.P256())
+	default:
+		return nil, fmt.Errorf("unsupported key type %s", keyType)
+	}
+}
+
+// sleep returns false if the context is cancelled before the given duration elapses
+func sleep(ctx context.Context, d time.Duration) bool {
+	if d <= 0 {
+		return ctx.Err() == nil
+	}
+
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return false
+	case <-timer.C:
+		return true
+	}
+}
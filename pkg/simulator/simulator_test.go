@@ -0,0 +1,150 @@
+package simulator
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/x509"
+	"errors"
+	"math/big"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	svcmock "github.com/lamassuiot/lamassuiot/v2/pkg/services/mock"
+	"github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/mock"
+)
+
+type fakeESTClient struct {
+	caCert     *x509.Certificate
+	caKey      crypto.Signer
+	serial     *int64
+	failEnroll bool
+}
+
+func (c *fakeESTClient) sign(csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(atomic.AddInt64(c.serial, 1)),
+		Subject:      csr.Subject,
+		NotBefore:    time.Now(),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, c.caCert, csr.PublicKey, c.caKey)
+	if err != nil {
+		return nil, err
+	}
+
+	return x509.ParseCertificate(der)
+}
+
+func (c *fakeESTClient) Enroll(ctx context.Context, csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	if c.failEnroll {
+		return nil, errors.New("enroll failed")
+	}
+
+	return c.sign(csr)
+}
+
+func (c *fakeESTClient) Reenroll(ctx context.Context, csr *x509.CertificateRequest) (*x509.Certificate, error) {
+	return c.sign(csr)
+}
+
+func newTestSimulator(t *testing.T, conf Config, failEnroll bool, caClient *svcmock.MockCAService) *Simulator {
+	caCert, caKey, err := helpers.GenerateSelfSignedCA(x509.ECDSA, time.Hour, "sim-ca")
+	if err != nil {
+		t.Fatalf("could not generate CA: %s", err)
+	}
+
+	var serial int64
+	builder := func(cert *x509.Certificate, key crypto.Signer) ESTClient {
+		return &fakeESTClient{caCert: caCert, caKey: caKey.(crypto.Signer), serial: &serial, failEnroll: failEnroll}
+	}
+
+	return NewSimulator(conf, caCert, caKey.(crypto.Signer), builder, caClient, logrus.NewEntry(logrus.StandardLogger()))
+}
+
+func findReport(reports []OperationReport, op Operation) OperationReport {
+	for _, r := range reports {
+		if r.Operation == op {
+			return r
+		}
+	}
+
+	return OperationReport{}
+}
+
+func TestSimulatorRun(t *testing.T) {
+	caClient := new(svcmock.MockCAService)
+	caClient.On("UpdateCertificateStatus", mock.Anything, mock.Anything).Return(&models.Certificate{}, nil)
+
+	sim := newTestSimulator(t, Config{
+		Devices:        5,
+		DeviceIDPrefix: "dev-",
+		KeyType:        x509.ECDSA,
+		Reenrollments:  2,
+		Revoke:         true,
+	}, false, caClient)
+
+	reports := sim.Run(context.Background())
+
+	expected := map[Operation]int{OperationKeyGen: 5, OperationEnroll: 5, OperationReenroll: 10, OperationRevoke: 5}
+	for op, total := range expected {
+		r := findReport(reports, op)
+		if r.Total != total || r.Errors != 0 {
+			t.Errorf("%s: expected %d calls without errors, got total=%d errors=%d", op, total, r.Total, r.Errors)
+		}
+	}
+
+	caClient.AssertNumberOfCalls(t, "UpdateCertificateStatus", 5)
+}
+
+func TestSimulatorEnrollErrors(t *testing.T) {
+	caClient := new(svcmock.MockCAService)
+
+	sim := newTestSimulator(t, Config{
+		Devices:       3,
+		KeyType:       x509.ECDSA,
+		Reenrollments: 1,
+		Revoke:        true,
+	}, true, caClient)
+
+	reports := sim.Run(context.Background())
+
+	enroll := findReport(reports, OperationEnroll)
+	if enroll.Errors != 3 || enroll.ErrorRate != 1 {
+		t.Errorf("expected all enrollments to fail, got errors=%d rate=%f", enroll.Errors, enroll.ErrorRate)
+	}
+
+	if findReport(reports, OperationReenroll).Total != 0 {
+		t.Errorf("devices failing to enroll should not re-enroll")
+	}
+
+	caClient.AssertNotCalled(t, "UpdateCertificateStatus", mock.Anything, mock.Anything)
+}
+
+func TestPercentile(t *testing.T) {
+	latencies := []time.Duration{}
+	for i := 1; i <= 100; i++ {
+		latencies = append(latencies, time.Duration(i)*time.Millisecond)
+	}
+
+	if p := percentile(latencies, 50); p != 50*time.Millisecond {
+		t.Errorf("expected p50 50ms, got %s", p)
+	}
+
+	if p := percentile(latencies, 99); p != 99*time.Millisecond {
+		t.Errorf("expected p99 99ms, got %s", p)
+	}
+
+	if p := percentile(latencies, 100); p != 100*time.Millisecond {
+		t.Errorf("expected max 100ms, got %s", p)
+	}
+
+	if p := percentile(nil, 50); p != 0 {
+		t.Errorf("expected 0 for empty latencies, got %s", p)
+	}
+}
@@ -0,0 +1,99 @@
+package simulator
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+type Operation string
+
+const (
+	OperationKeyGen   Operation = "keygen"
+	OperationEnroll   Operation = "enroll"
+	OperationReenroll Operation = "reenroll"
+	OperationRevoke   Operation = "revoke"
+)
+
+var Operations = []Operation{OperationKeyGen, OperationEnroll, OperationReenroll, OperationRevoke}
+
+// OperationReport summarizes the latencies (of the successful calls) and the errors of an operation.
+type OperationReport struct {
+	Operation Operation
+	Total     int
+	Errors    int
+	ErrorRate float64
+	P50       time.Duration
+	P90       time.Duration
+	P99       time.Duration
+	Max       time.Duration
+}
+
+// Recorder collects the outcome of the operations performed by the virtual devices. It is safe for concurrent use.
+type Recorder struct {
+	lock      sync.Mutex
+	latencies map[Operation][]time.Duration
+	errors    map[Operation]int
+}
+
+func NewRecorder() *Recorder {
+	return &Recorder{
+		latencies: map[Operation][]time.Duration{},
+		errors:    map[Operation]int{},
+	}
+}
+
+func (r *Recorder) Record(op Operation, latency time.Duration, err error) {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	if err != nil {
+		r.errors[op]++
+		return
+	}
+
+	r.latencies[op] = append(r.latencies[op], latency)
+}
+
+func (r *Recorder) Report() []OperationReport {
+	r.lock.Lock()
+	defer r.lock.Unlock()
+
+	reports := []OperationReport{}
+	for _, op := range Operations {
+		latencies := append([]time.Duration{}, r.latencies[op]...)
+		sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+
+		report := OperationReport{
+			Operation: op,
+			Total:     len(latencies) + r.errors[op],
+			Errors:    r.errors[op],
+			P50:       percentile(latencies, 50),
+			P90:       percentile(latencies, 90),
+			P99:       percentile(latencies, 99),
+			Max:       percentile(latencies, 100),
+		}
+
+		if report.Total > 0 {
+			report.ErrorRate = float64(report.Errors) / float64(report.Total)
+		}
+
+		reports = append(reports, report)
+	}
+
+	return reports
+}
+
+// percentile uses the nearest-rank method over the sorted latencies
+func percentile(sorted []time.Duration, p int) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+
+	rank := (p*len(sorted) + 99) / 100
+	if rank < 1 {
+		rank = 1
+	}
+
+	return sorted[rank-1]
+}
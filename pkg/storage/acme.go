@@ -0,0 +1,20 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+type ACMEAccountsRepo interface {
+	SelectExists(ctx context.Context, id string) (bool, *models.ACMEAccount, error)
+	Insert(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error)
+	Update(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error)
+}
+
+type ACMEOrdersRepo interface {
+	SelectByAccount(ctx context.Context, accountID string, req StorageListRequest[models.ACMEOrder]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.ACMEOrder, error)
+	Insert(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error)
+	Update(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error)
+}
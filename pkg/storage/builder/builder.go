@@ -1,10 +1,12 @@
 package builder
 
 import (
+	"context"
 	"fmt"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/dualwrite"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -16,3 +18,27 @@ func BuildStorageEngine(logger *log.Entry, conf config.PluggableStorageEngine) (
 	}
 	return builder(logger, conf)
 }
+
+// BuildAndMigrateStorageEngine builds the storage engine and upgrades its schema to the latest version. The schemas
+// of both the source and target engines of dual_write storage engines are upgraded.
+func BuildAndMigrateStorageEngine(logger *log.Entry, conf config.PluggableStorageEngine) (storage.StorageEngine, error) {
+	engine, err := BuildStorageEngine(logger, conf)
+	if err != nil {
+		return nil, err
+	}
+
+	engines := []storage.StorageEngine{engine}
+	if dualWriteEngine, ok := engine.(*dualwrite.DualWriteStorageEngine); ok {
+		source, target := dualWriteEngine.Engines()
+		engines = []storage.StorageEngine{source, target}
+	}
+
+	for _, schemaEngine := range engines {
+		_, err = storage.MigrateSchema(context.Background(), logger, schemaEngine)
+		if err != nil {
+			return nil, fmt.Errorf("could not migrate storage schema: %w", err)
+		}
+	}
+
+	return engine, nil
+}
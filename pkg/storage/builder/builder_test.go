@@ -4,6 +4,7 @@ import (
 	"testing"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/memory"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/postgres"
 	log "github.com/sirupsen/logrus"
 )
@@ -49,3 +50,20 @@ func TestBuildStorageEngineInvalidProvider(t *testing.T) {
 		t.Errorf("unexpected error: %s", err)
 	}
 }
+
+func TestBuildStorageEngineMemory(t *testing.T) {
+	logger := log.WithField("test", "BuildStorageEngine_Memory")
+	conf := config.PluggableStorageEngine{
+		Provider: config.Memory,
+	}
+
+	storageEngine, err := BuildStorageEngine(logger, conf)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, ok := storageEngine.(*memory.MemoryStorageEngine)
+	if !ok {
+		t.Error("expected storage engine of type *memory.MemoryStorageEngine")
+	}
+}
@@ -0,0 +1,9 @@
+package builder
+
+import (
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/dualwrite"
+)
+
+func init() {
+	dualwrite.Register()
+}
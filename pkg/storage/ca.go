@@ -36,5 +36,56 @@ type CACertificatesRepo interface {
 
 	Insert(ctx context.Context, caCertificate *models.CACertificate) (*models.CACertificate, error)
 	Update(ctx context.Context, caCertificate *models.CACertificate) (*models.CACertificate, error)
+	// UpdateIf updates the CA only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error)
 	Delete(ctx context.Context, caID string) error
 }
+
+type CertificateProfilesRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.CertificateProfile]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.CertificateProfile, error)
+	// SelectByCA lists the profiles bound to the CA (those including caID in their CAIDs).
+	SelectByCA(ctx context.Context, caID string, req StorageListRequest[models.CertificateProfile]) (string, error)
+
+	Insert(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error)
+	Update(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// CAEventsRepo stores the lifecycle timeline of the CAs. Events are never updated nor deleted.
+type CAEventsRepo interface {
+	SelectByCA(ctx context.Context, caID string, req StorageListRequest[models.CAEvent]) (string, error)
+	Insert(ctx context.Context, event *models.CAEvent) (*models.CAEvent, error)
+}
+
+// CASigningRequestsRepo stores the sign operations against dual control CAs and their approvals.
+type CASigningRequestsRepo interface {
+	SelectByCA(ctx context.Context, caID string, req StorageListRequest[models.CASigningRequest]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.CASigningRequest, error)
+	Update(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error)
+	// UpdateIf updates the request only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, request *models.CASigningRequest, precondition func(current *models.CASigningRequest) bool) (*models.CASigningRequest, error)
+	Insert(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error)
+}
+
+// CAPendingActionsRepo stores the destructive operations over the CAs waiting for the approval of a second
+// administrator.
+type CAPendingActionsRepo interface {
+	SelectByCA(ctx context.Context, caID string, req StorageListRequest[models.CAPendingAction]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.CAPendingAction, error)
+	// UpdateIf updates the action only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, action *models.CAPendingAction, precondition func(current *models.CAPendingAction) bool) (*models.CAPendingAction, error)
+	Insert(ctx context.Context, action *models.CAPendingAction) (*models.CAPendingAction, error)
+}
+
+// IssuanceLogRepo stores the append-only issuance log of the CAs. Entries are never updated nor deleted.
+type IssuanceLogRepo interface {
+	CountByCA(ctx context.Context, caID string) (int, error)
+	SelectByCA(ctx context.Context, caID string, req StorageListRequest[models.IssuanceLogEntry]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.IssuanceLogEntry, error)
+
+	Insert(ctx context.Context, entry *models.IssuanceLogEntry) (*models.IssuanceLogEntry, error)
+}
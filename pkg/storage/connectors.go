@@ -0,0 +1,16 @@
+package storage
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ConnectorPendingEventsRepo stores the retry queues of the cloud connectors.
+type ConnectorPendingEventsRepo interface {
+	SelectByConnector(ctx context.Context, connectorID string, req StorageListRequest[models.ConnectorPendingEvent]) (string, error)
+	SelectExistsByID(ctx context.Context, id string) (bool, *models.ConnectorPendingEvent, error)
+	Insert(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error)
+	Update(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error)
+	Delete(ctx context.Context, id string) error
+}
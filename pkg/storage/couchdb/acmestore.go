@@ -0,0 +1,94 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const (
+	acmeAccountDBName = "acme-accounts"
+	acmeOrderDBName   = "acme-orders"
+)
+
+type CouchDBACMEAccountStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.ACMEAccount]
+}
+
+func NewCouchACMEAccountRepository(client *kivik.Client) (storage.ACMEAccountsRepo, error) {
+	err := CheckAndCreateDB(client, acmeAccountDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.ACMEAccount](client.DB(acmeAccountDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBACMEAccountStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBACMEAccountStorage) SelectExists(ctx context.Context, id string) (bool, *models.ACMEAccount, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBACMEAccountStorage) Insert(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Insert(*account, account.ID)
+}
+
+func (db *CouchDBACMEAccountStorage) Update(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Update(*account, account.ID)
+}
+
+type CouchDBACMEOrderStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.ACMEOrder]
+}
+
+func NewCouchACMEOrderRepository(client *kivik.Client) (storage.ACMEOrdersRepo, error) {
+	err := CheckAndCreateDB(client, acmeOrderDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.ACMEOrder](client.DB(acmeOrderDBName))
+	querier.CreateBasicCounterView()
+	querier.EnsureIndexExists("account_id")
+
+	return &CouchDBACMEOrderStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBACMEOrderStorage) SelectByAccount(ctx context.Context, accountID string, req storage.StorageListRequest[models.ACMEOrder]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"account_id": map[string]interface{}{
+				"$eq": accountID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBACMEOrderStorage) SelectExists(ctx context.Context, id string) (bool, *models.ACMEOrder, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBACMEOrderStorage) Insert(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Insert(*order, order.ID)
+}
+
+func (db *CouchDBACMEOrderStorage) Update(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Update(*order, order.ID)
+}
@@ -0,0 +1,50 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const caEventsDBName = "ca-events"
+
+type CouchDBCAEventsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.CAEvent]
+}
+
+func NewCouchCAEventsRepository(client *kivik.Client) (storage.CAEventsRepo, error) {
+	err := CheckAndCreateDB(client, caEventsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.CAEvent](client.DB(caEventsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBCAEventsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBCAEventsStorage) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAEvent]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"ca_id": map[string]interface{}{
+				"$eq": caID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBCAEventsStorage) Insert(ctx context.Context, event *models.CAEvent) (*models.CAEvent, error) {
+	return db.querier.Insert(*event, event.ID)
+}
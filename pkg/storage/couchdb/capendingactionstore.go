@@ -0,0 +1,58 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const caPendingActionsDBName = "ca-pending-actions"
+
+type CouchDBCAPendingActionsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.CAPendingAction]
+}
+
+func NewCouchCAPendingActionsRepository(client *kivik.Client) (storage.CAPendingActionsRepo, error) {
+	err := CheckAndCreateDB(client, caPendingActionsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.CAPendingAction](client.DB(caPendingActionsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBCAPendingActionsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBCAPendingActionsStorage) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAPendingAction]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"ca_id": map[string]string{
+				"$eq": caID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBCAPendingActionsStorage) SelectExists(ctx context.Context, id string) (bool, *models.CAPendingAction, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBCAPendingActionsStorage) UpdateIf(ctx context.Context, action *models.CAPendingAction, precondition func(current *models.CAPendingAction) bool) (*models.CAPendingAction, error) {
+	return db.querier.UpdateIf(*action, action.ID, precondition)
+}
+
+func (db *CouchDBCAPendingActionsStorage) Insert(ctx context.Context, action *models.CAPendingAction) (*models.CAPendingAction, error) {
+	return db.querier.Insert(*action, action.ID)
+}
@@ -0,0 +1,62 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const caSigningRequestsDBName = "ca-signing-requests"
+
+type CouchDBCASigningRequestsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.CASigningRequest]
+}
+
+func NewCouchCASigningRequestsRepository(client *kivik.Client) (storage.CASigningRequestsRepo, error) {
+	err := CheckAndCreateDB(client, caSigningRequestsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.CASigningRequest](client.DB(caSigningRequestsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBCASigningRequestsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBCASigningRequestsStorage) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CASigningRequest]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"ca_id": map[string]string{
+				"$eq": caID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBCASigningRequestsStorage) SelectExists(ctx context.Context, id string) (bool, *models.CASigningRequest, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBCASigningRequestsStorage) Update(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Update(*request, request.ID)
+}
+
+func (db *CouchDBCASigningRequestsStorage) UpdateIf(ctx context.Context, request *models.CASigningRequest, precondition func(current *models.CASigningRequest) bool) (*models.CASigningRequest, error) {
+	return db.querier.UpdateIf(*request, request.ID, precondition)
+}
+
+func (db *CouchDBCASigningRequestsStorage) Insert(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Insert(*request, request.ID)
+}
@@ -119,6 +119,10 @@ func (db *CouchDBCAStorage) Update(ctx context.Context, caCertificate *models.CA
 	return db.querier.Update(*caCertificate, caCertificate.ID)
 }
 
+func (db *CouchDBCAStorage) UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error) {
+	return db.querier.UpdateIf(*caCertificate, caCertificate.ID, precondition)
+}
+
 func (db *CouchDBCAStorage) Delete(ctx context.Context, id string) error {
 	return db.querier.Delete(id)
 }
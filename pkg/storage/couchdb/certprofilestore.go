@@ -0,0 +1,68 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const certProfileDBName = "certificate-profiles"
+
+type CouchDBCertificateProfileStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.CertificateProfile]
+}
+
+func NewCouchCertificateProfileRepository(client *kivik.Client) (storage.CertificateProfilesRepo, error) {
+	err := CheckAndCreateDB(client, certProfileDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.CertificateProfile](client.DB(certProfileDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBCertificateProfileStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBCertificateProfileStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBCertificateProfileStorage) SelectExists(ctx context.Context, id string) (bool, *models.CertificateProfile, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBCertificateProfileStorage) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"ca_ids": map[string]interface{}{
+				"$elemMatch": map[string]interface{}{
+					"$eq": caID,
+				},
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBCertificateProfileStorage) Insert(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Insert(*profile, profile.ID)
+}
+
+func (db *CouchDBCertificateProfileStorage) Update(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Update(*profile, profile.ID)
+}
+
+func (db *CouchDBCertificateProfileStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
@@ -0,0 +1,62 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const connectorEventsDBName = "connector-pending-events"
+
+type CouchDBConnectorPendingEventsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.ConnectorPendingEvent]
+}
+
+func NewCouchConnectorPendingEventsRepository(client *kivik.Client) (storage.ConnectorPendingEventsRepo, error) {
+	err := CheckAndCreateDB(client, connectorEventsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.ConnectorPendingEvent](client.DB(connectorEventsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBConnectorPendingEventsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBConnectorPendingEventsStorage) SelectByConnector(ctx context.Context, connectorID string, req storage.StorageListRequest[models.ConnectorPendingEvent]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"connector_id": map[string]interface{}{
+				"$eq": connectorID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBConnectorPendingEventsStorage) SelectExistsByID(ctx context.Context, id string) (bool, *models.ConnectorPendingEvent, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBConnectorPendingEventsStorage) Insert(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Insert(*event, event.ID)
+}
+
+func (db *CouchDBConnectorPendingEventsStorage) Update(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Update(*event, event.ID)
+}
+
+func (db *CouchDBConnectorPendingEventsStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
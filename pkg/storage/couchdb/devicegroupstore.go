@@ -0,0 +1,105 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const (
+	deviceGroupsDBName           = "device-groups"
+	deviceGroupBulkActionsDBName = "device-group-bulk-actions"
+)
+
+type CouchDBDeviceGroupsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DeviceGroup]
+}
+
+func NewCouchDeviceGroupsRepository(client *kivik.Client) (storage.DeviceGroupsRepo, error) {
+	err := CheckAndCreateDB(client, deviceGroupsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DeviceGroup](client.DB(deviceGroupsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDeviceGroupsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDeviceGroupsStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceGroup]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDeviceGroupsStorage) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroup, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBDeviceGroupsStorage) Update(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Update(*group, group.ID)
+}
+
+func (db *CouchDBDeviceGroupsStorage) Insert(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Insert(*group, group.ID)
+}
+
+func (db *CouchDBDeviceGroupsStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
+
+type CouchDBDeviceGroupBulkActionsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DeviceGroupBulkAction]
+}
+
+func NewCouchDeviceGroupBulkActionsRepository(client *kivik.Client) (storage.DeviceGroupBulkActionsRepo, error) {
+	err := CheckAndCreateDB(client, deviceGroupBulkActionsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DeviceGroupBulkAction](client.DB(deviceGroupBulkActionsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDeviceGroupBulkActionsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDeviceGroupBulkActionsStorage) SelectByGroup(ctx context.Context, groupID string, req storage.StorageListRequest[models.DeviceGroupBulkAction]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"group_id": map[string]string{
+				"$eq": groupID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDeviceGroupBulkActionsStorage) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroupBulkAction, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBDeviceGroupBulkActionsStorage) Update(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Update(*action, action.ID)
+}
+
+func (db *CouchDBDeviceGroupBulkActionsStorage) UpdateIf(ctx context.Context, action *models.DeviceGroupBulkAction, precondition func(current *models.DeviceGroupBulkAction) bool) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.UpdateIf(*action, action.ID, precondition)
+}
+
+func (db *CouchDBDeviceGroupBulkActionsStorage) Insert(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Insert(*action, action.ID)
+}
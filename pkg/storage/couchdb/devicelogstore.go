@@ -0,0 +1,58 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const deviceLogsDBName = "device-logs"
+
+type CouchDBDeviceLogsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DeviceLog]
+}
+
+func NewCouchDeviceLogsRepository(client *kivik.Client) (storage.DeviceLogsRepo, error) {
+	err := CheckAndCreateDB(client, deviceLogsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DeviceLog](client.DB(deviceLogsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDeviceLogsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDeviceLogsStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDeviceLogsStorage) SelectByDevice(ctx context.Context, deviceID string, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"device_id": map[string]string{
+				"$eq": deviceID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDeviceLogsStorage) Insert(ctx context.Context, log *models.DeviceLog) (*models.DeviceLog, error) {
+	return db.querier.Insert(*log, log.ID)
+}
+
+func (db *CouchDBDeviceLogsStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
@@ -33,6 +33,7 @@ func NewCouchDeviceRepository(client *kivik.Client) (storage.DeviceManagerRepo,
 	for field := range resources.DeviceFiltrableFields {
 		querier.EnsureIndexExists(field)
 	}
+	querier.EnsureIndexExists("identity.status")
 
 	return &CouchDBDeviceStorage{
 		client:  client,
@@ -76,10 +77,25 @@ func (db *CouchDBDeviceStorage) SelectByDMS(ctx context.Context, dmsID string, e
 	return db.querier.SelectAll(queryParams, &opts, exhaustiveRun, applyFunc)
 }
 
+func (db *CouchDBDeviceStorage) SelectByIdentitySlotStatus(ctx context.Context, status models.SlotStatus, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"identity.status": map[string]interface{}{
+				"$eq": status,
+			},
+		},
+	}
+	return db.querier.SelectAll(queryParams, &opts, exhaustiveRun, applyFunc)
+}
+
 func (db *CouchDBDeviceStorage) Update(ctx context.Context, device *models.Device) (*models.Device, error) {
 	return db.querier.Update(*device, device.ID)
 }
 
+func (db *CouchDBDeviceStorage) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error) {
+	return db.querier.UpdateIf(*device, device.ID, precondition)
+}
+
 func (db *CouchDBDeviceStorage) Insert(ctx context.Context, device *models.Device) (*models.Device, error) {
 	return db.querier.Insert(*device, device.ID)
 }
@@ -0,0 +1,87 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const dmsBootstrapTokensDBName = "dms-bootstrap-tokens"
+
+type CouchDBDMSBootstrapTokensStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DMSBootstrapToken]
+}
+
+func NewCouchDMSBootstrapTokensRepository(client *kivik.Client) (storage.DMSBootstrapTokensRepo, error) {
+	err := CheckAndCreateDB(client, dmsBootstrapTokensDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DMSBootstrapToken](client.DB(dmsBootstrapTokensDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDMSBootstrapTokensStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDMSBootstrapTokensStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSBootstrapToken]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDMSBootstrapTokensStorage) SelectExists(ctx context.Context, id string) (bool, *models.DMSBootstrapToken, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBDMSBootstrapTokensStorage) Update(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error) {
+	return db.querier.Update(*token, token.ID)
+}
+
+func (db *CouchDBDMSBootstrapTokensStorage) Insert(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error) {
+	return db.querier.Insert(*token, token.ID)
+}
+
+func (db *CouchDBDMSBootstrapTokensStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
+
+// MarkUsed writes the used token with the revision it was read with, so CouchDB rejects the update with a conflict
+// if another enrollment used the token in between.
+func (db *CouchDBDMSBootstrapTokensStorage) MarkUsed(ctx context.Context, id string, deviceID string, usedAt time.Time) (bool, error) {
+	rs := db.querier.Get(ctx, id)
+	if rs.Err() != nil {
+		return false, rs.Err()
+	}
+
+	rs.Next()
+	var doc map[string]interface{}
+	if err := rs.ScanDoc(&doc); err != nil {
+		return false, err
+	}
+
+	if used, ok := doc["used_at"]; ok && used != nil {
+		return false, nil
+	}
+
+	doc["used_at"] = usedAt
+	doc["used_by"] = deviceID
+	_, err := db.querier.Put(ctx, id, doc)
+	if kivik.StatusCode(err) == http.StatusConflict {
+		return false, nil
+	} else if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
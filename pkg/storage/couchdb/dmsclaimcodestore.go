@@ -0,0 +1,59 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const dmsClaimCodesDBName = "dms-claim-codes"
+
+type CouchDBDMSClaimCodesStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DMSClaimCode]
+}
+
+func NewCouchDMSClaimCodesRepository(client *kivik.Client) (storage.DMSClaimCodesRepo, error) {
+	err := CheckAndCreateDB(client, dmsClaimCodesDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DMSClaimCode](client.DB(dmsClaimCodesDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDMSClaimCodesStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDMSClaimCodesStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSClaimCode]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDMSClaimCodesStorage) SelectExists(ctx context.Context, id string) (bool, *models.DMSClaimCode, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBDMSClaimCodesStorage) Update(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Update(*code, code.ID)
+}
+
+func (db *CouchDBDMSClaimCodesStorage) UpdateIf(ctx context.Context, code *models.DMSClaimCode, precondition func(current *models.DMSClaimCode) bool) (*models.DMSClaimCode, error) {
+	return db.querier.UpdateIf(*code, code.ID, precondition)
+}
+
+func (db *CouchDBDMSClaimCodesStorage) Insert(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Insert(*code, code.ID)
+}
+
+func (db *CouchDBDMSClaimCodesStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
@@ -0,0 +1,54 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const dmsEnrollmentAuditsDBName = "dms-enrollment-audits"
+
+type CouchDBDMSEnrollmentAuditsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DMSEnrollmentAudit]
+}
+
+func NewCouchDMSEnrollmentAuditsRepository(client *kivik.Client) (storage.DMSEnrollmentAuditsRepo, error) {
+	err := CheckAndCreateDB(client, dmsEnrollmentAuditsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DMSEnrollmentAudit](client.DB(dmsEnrollmentAuditsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDMSEnrollmentAuditsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDMSEnrollmentAuditsStorage) SelectByDMS(ctx context.Context, dmsID string, req storage.StorageListRequest[models.DMSEnrollmentAudit]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"dms_id": map[string]string{
+				"$eq": dmsID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDMSEnrollmentAuditsStorage) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentAudit, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBDMSEnrollmentAuditsStorage) Insert(ctx context.Context, audit *models.DMSEnrollmentAudit) (*models.DMSEnrollmentAudit, error) {
+	return db.querier.Insert(*audit, audit.ID)
+}
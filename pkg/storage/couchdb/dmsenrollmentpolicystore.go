@@ -0,0 +1,55 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const dmsEnrollmentPoliciesDBName = "dms-enrollment-policies"
+
+type CouchDBDMSEnrollmentPoliciesStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DMSEnrollmentPolicy]
+}
+
+func NewCouchDMSEnrollmentPoliciesRepository(client *kivik.Client) (storage.DMSEnrollmentPoliciesRepo, error) {
+	err := CheckAndCreateDB(client, dmsEnrollmentPoliciesDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DMSEnrollmentPolicy](client.DB(dmsEnrollmentPoliciesDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDMSEnrollmentPoliciesStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDMSEnrollmentPoliciesStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentPolicy]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDMSEnrollmentPoliciesStorage) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentPolicy, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBDMSEnrollmentPoliciesStorage) Update(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Update(*policy, policy.ID)
+}
+
+func (db *CouchDBDMSEnrollmentPoliciesStorage) Insert(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Insert(*policy, policy.ID)
+}
+
+func (db *CouchDBDMSEnrollmentPoliciesStorage) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(id)
+}
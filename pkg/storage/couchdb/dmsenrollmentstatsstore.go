@@ -0,0 +1,51 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const dmsEnrollmentStatsDBName = "dms-enrollment-stats"
+
+type CouchDBDMSEnrollmentStatsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.DMSEnrollmentStats]
+}
+
+func NewCouchDMSEnrollmentStatsRepository(client *kivik.Client) (storage.DMSEnrollmentStatsRepo, error) {
+	err := CheckAndCreateDB(client, dmsEnrollmentStatsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.DMSEnrollmentStats](client.DB(dmsEnrollmentStatsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBDMSEnrollmentStatsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBDMSEnrollmentStatsStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentStats]) (string, error) {
+	return db.querier.SelectAll(req.QueryParams, &req.ExtraOpts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBDMSEnrollmentStatsStorage) SelectExists(ctx context.Context, dmsID string) (bool, *models.DMSEnrollmentStats, error) {
+	return db.querier.SelectExists(dmsID)
+}
+
+func (db *CouchDBDMSEnrollmentStatsStorage) Update(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Update(*stats, stats.DMSID)
+}
+
+func (db *CouchDBDMSEnrollmentStatsStorage) Insert(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Insert(*stats, stats.DMSID)
+}
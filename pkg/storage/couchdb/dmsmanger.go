@@ -56,6 +56,10 @@ func (db *CouchDBDMSStorage) Update(ctx context.Context, dms *models.DMS) (*mode
 	return db.querier.Update(*dms, dms.ID)
 }
 
+func (db *CouchDBDMSStorage) UpdateIf(ctx context.Context, dms *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error) {
+	return db.querier.UpdateIf(*dms, dms.ID, precondition)
+}
+
 func (db *CouchDBDMSStorage) Insert(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
 	return db.querier.Insert(*dms, dms.ID)
 }
@@ -61,6 +61,83 @@ func (s *CouchDBStorageEngine) GetCertstorage() (storage.CertificatesRepo, error
 	return s.Cert, nil
 }
 
+func (s *CouchDBStorageEngine) GetCertificateProfileStorage() (storage.CertificateProfilesRepo, error) {
+	if s.CertificateProfiles == nil {
+		profileStore, err := NewCouchCertificateProfileRepository(s.couchdbClient)
+		s.CertificateProfiles = profileStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Certificate Profile client: %s", err)
+		}
+	}
+	return s.CertificateProfiles, nil
+}
+
+func (s *CouchDBStorageEngine) GetIssuanceLogStorage() (storage.IssuanceLogRepo, error) {
+	if s.IssuanceLog == nil {
+		issuanceLogStore, err := NewCouchIssuanceLogRepository(s.couchdbClient)
+		s.IssuanceLog = issuanceLogStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Issuance Log client: %s", err)
+		}
+	}
+	return s.IssuanceLog, nil
+}
+
+func (s *CouchDBStorageEngine) GetCAEventsStorage() (storage.CAEventsRepo, error) {
+	if s.CAEvents == nil {
+		caEventsStore, err := NewCouchCAEventsRepository(s.couchdbClient)
+		s.CAEvents = caEventsStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb CA Events client: %s", err)
+		}
+	}
+	return s.CAEvents, nil
+}
+
+func (s *CouchDBStorageEngine) GetCASigningRequestsStorage() (storage.CASigningRequestsRepo, error) {
+	if s.CASigningRequests == nil {
+		requestStore, err := NewCouchCASigningRequestsRepository(s.couchdbClient)
+		s.CASigningRequests = requestStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb CA Signing Requests client: %s", err)
+		}
+	}
+	return s.CASigningRequests, nil
+}
+
+func (s *CouchDBStorageEngine) GetCAPendingActionsStorage() (storage.CAPendingActionsRepo, error) {
+	if s.CAPendingActions == nil {
+		actionStore, err := NewCouchCAPendingActionsRepository(s.couchdbClient)
+		s.CAPendingActions = actionStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb CA Pending Actions client: %s", err)
+		}
+	}
+	return s.CAPendingActions, nil
+}
+
+func (s *CouchDBStorageEngine) GetConnectorPendingEventsStorage() (storage.ConnectorPendingEventsRepo, error) {
+	if s.ConnectorEvents == nil {
+		eventsStore, err := NewCouchConnectorPendingEventsRepository(s.couchdbClient)
+		s.ConnectorEvents = eventsStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Connector Pending Events client: %s", err)
+		}
+	}
+	return s.ConnectorEvents, nil
+}
+
+func (s *CouchDBStorageEngine) GetProcessedEventsStorage() (storage.ProcessedEventsRepo, error) {
+	if s.ProcessedEvents == nil {
+		eventsStore, err := NewCouchProcessedEventsRepository(s.couchdbClient)
+		s.ProcessedEvents = eventsStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Processed Events client: %s", err)
+		}
+	}
+	return s.ProcessedEvents, nil
+}
+
 func (s *CouchDBStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, error) {
 	if s.Device == nil {
 		deviceStore, err := NewCouchDeviceRepository(s.couchdbClient)
@@ -72,6 +149,39 @@ func (s *CouchDBStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, er
 	return s.Device, nil
 }
 
+func (s *CouchDBStorageEngine) GetDeviceGroupsStorage() (storage.DeviceGroupsRepo, error) {
+	if s.DeviceGroups == nil {
+		groupStore, err := NewCouchDeviceGroupsRepository(s.couchdbClient)
+		s.DeviceGroups = groupStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Device Groups client: %s", err)
+		}
+	}
+	return s.DeviceGroups, nil
+}
+
+func (s *CouchDBStorageEngine) GetDeviceGroupBulkActionsStorage() (storage.DeviceGroupBulkActionsRepo, error) {
+	if s.DeviceBulkActions == nil {
+		actionStore, err := NewCouchDeviceGroupBulkActionsRepository(s.couchdbClient)
+		s.DeviceBulkActions = actionStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Device Group Bulk Actions client: %s", err)
+		}
+	}
+	return s.DeviceBulkActions, nil
+}
+
+func (s *CouchDBStorageEngine) GetDeviceLogsStorage() (storage.DeviceLogsRepo, error) {
+	if s.DeviceLogs == nil {
+		logStore, err := NewCouchDeviceLogsRepository(s.couchdbClient)
+		s.DeviceLogs = logStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb Device Logs client: %s", err)
+		}
+	}
+	return s.DeviceLogs, nil
+}
+
 func (s *CouchDBStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
 	if s.DMS == nil {
 		dmsStore, err := NewCouchDMSRepository(s.couchdbClient)
@@ -83,6 +193,83 @@ func (s *CouchDBStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
 	return s.DMS, nil
 }
 
+func (s *CouchDBStorageEngine) GetDMSEnrollmentStatsStorage() (storage.DMSEnrollmentStatsRepo, error) {
+	if s.DMSEnrollmentStats == nil {
+		statsStore, err := NewCouchDMSEnrollmentStatsRepository(s.couchdbClient)
+		s.DMSEnrollmentStats = statsStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb DMS Enrollment Stats client: %s", err)
+		}
+	}
+	return s.DMSEnrollmentStats, nil
+}
+
+func (s *CouchDBStorageEngine) GetDMSEnrollmentPoliciesStorage() (storage.DMSEnrollmentPoliciesRepo, error) {
+	if s.DMSEnrollmentPolicy == nil {
+		policyStore, err := NewCouchDMSEnrollmentPoliciesRepository(s.couchdbClient)
+		s.DMSEnrollmentPolicy = policyStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb DMS Enrollment Policies client: %s", err)
+		}
+	}
+	return s.DMSEnrollmentPolicy, nil
+}
+
+func (s *CouchDBStorageEngine) GetDMSBootstrapTokensStorage() (storage.DMSBootstrapTokensRepo, error) {
+	if s.DMSBootstrapTokens == nil {
+		tokenStore, err := NewCouchDMSBootstrapTokensRepository(s.couchdbClient)
+		s.DMSBootstrapTokens = tokenStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb DMS Bootstrap Tokens client: %s", err)
+		}
+	}
+	return s.DMSBootstrapTokens, nil
+}
+
+func (s *CouchDBStorageEngine) GetDMSClaimCodesStorage() (storage.DMSClaimCodesRepo, error) {
+	if s.DMSClaimCodes == nil {
+		codeStore, err := NewCouchDMSClaimCodesRepository(s.couchdbClient)
+		s.DMSClaimCodes = codeStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb DMS Claim Codes client: %s", err)
+		}
+	}
+	return s.DMSClaimCodes, nil
+}
+
+func (s *CouchDBStorageEngine) GetDMSEnrollmentAuditsStorage() (storage.DMSEnrollmentAuditsRepo, error) {
+	if s.DMSEnrollmentAudits == nil {
+		auditStore, err := NewCouchDMSEnrollmentAuditsRepository(s.couchdbClient)
+		s.DMSEnrollmentAudits = auditStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb DMS Enrollment Audits client: %s", err)
+		}
+	}
+	return s.DMSEnrollmentAudits, nil
+}
+
+func (s *CouchDBStorageEngine) GetACMEAccountStorage() (storage.ACMEAccountsRepo, error) {
+	if s.ACMEAccounts == nil {
+		accountStore, err := NewCouchACMEAccountRepository(s.couchdbClient)
+		s.ACMEAccounts = accountStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb ACME Account client: %s", err)
+		}
+	}
+	return s.ACMEAccounts, nil
+}
+
+func (s *CouchDBStorageEngine) GetACMEOrderStorage() (storage.ACMEOrdersRepo, error) {
+	if s.ACMEOrders == nil {
+		orderStore, err := NewCouchACMEOrderRepository(s.couchdbClient)
+		s.ACMEOrders = orderStore
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize couchdb ACME Order client: %s", err)
+		}
+	}
+	return s.ACMEOrders, nil
+}
+
 func (s *CouchDBStorageEngine) GetEnventsStorage() (storage.EventRepository, error) {
 	return nil, fmt.Errorf("not implemented")
 }
@@ -0,0 +1,70 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const issuanceLogDBName = "issuance-log"
+
+type CouchDBIssuanceLogStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.IssuanceLogEntry]
+}
+
+func NewCouchIssuanceLogRepository(client *kivik.Client) (storage.IssuanceLogRepo, error) {
+	err := CheckAndCreateDB(client, issuanceLogDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.IssuanceLogEntry](client.DB(issuanceLogDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBIssuanceLogStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+// CountByCA iterates the entries of the CA, as CouchDB does not support counting with a selector.
+func (db *CouchDBIssuanceLogStorage) CountByCA(ctx context.Context, caID string) (int, error) {
+	count := 0
+	_, err := db.SelectByCA(ctx, caID, storage.StorageListRequest[models.IssuanceLogEntry]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(models.IssuanceLogEntry) {
+			count++
+		},
+	})
+	if err != nil {
+		return -1, err
+	}
+
+	return count, nil
+}
+
+func (db *CouchDBIssuanceLogStorage) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.IssuanceLogEntry]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"ca_id": map[string]interface{}{
+				"$eq": caID,
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBIssuanceLogStorage) SelectExists(ctx context.Context, id string) (bool, *models.IssuanceLogEntry, error) {
+	return db.querier.SelectExists(id)
+}
+
+func (db *CouchDBIssuanceLogStorage) Insert(ctx context.Context, entry *models.IssuanceLogEntry) (*models.IssuanceLogEntry, error) {
+	return db.querier.Insert(*entry, entry.ID)
+}
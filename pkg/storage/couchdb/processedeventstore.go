@@ -0,0 +1,67 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+	"time"
+
+	_ "github.com/go-kivik/couchdb/v4" // The CouchDB driver
+	kivik "github.com/go-kivik/kivik/v4"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const processedEventsDBName = "processed-events"
+
+type CouchDBProcessedEventsStorage struct {
+	client  *kivik.Client
+	querier *couchDBQuerier[models.ProcessedEvent]
+}
+
+func NewCouchProcessedEventsRepository(client *kivik.Client) (storage.ProcessedEventsRepo, error) {
+	err := CheckAndCreateDB(client, processedEventsDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[models.ProcessedEvent](client.DB(processedEventsDBName))
+	querier.CreateBasicCounterView()
+
+	return &CouchDBProcessedEventsStorage{
+		client:  client,
+		querier: &querier,
+	}, nil
+}
+
+func (db *CouchDBProcessedEventsStorage) SelectExists(ctx context.Context, key string) (bool, *models.ProcessedEvent, error) {
+	return db.querier.SelectExists(key)
+}
+
+func (db *CouchDBProcessedEventsStorage) SelectExpired(ctx context.Context, before time.Time, req storage.StorageListRequest[models.ProcessedEvent]) (string, error) {
+	opts := map[string]interface{}{
+		"selector": map[string]interface{}{
+			"expires_at": map[string]interface{}{
+				"$lt": before.Format(time.RFC3339),
+			},
+		},
+	}
+	return db.querier.SelectAll(req.QueryParams, &opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *CouchDBProcessedEventsStorage) Insert(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Insert(*event, event.Key)
+}
+
+func (db *CouchDBProcessedEventsStorage) Update(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Update(*event, event.Key)
+}
+
+func (db *CouchDBProcessedEventsStorage) UpdateIf(ctx context.Context, event *models.ProcessedEvent, precondition func(current *models.ProcessedEvent) bool) (*models.ProcessedEvent, error) {
+	return db.querier.UpdateIf(*event, event.Key, precondition)
+}
+
+func (db *CouchDBProcessedEventsStorage) Delete(ctx context.Context, key string) error {
+	return db.querier.Delete(key)
+}
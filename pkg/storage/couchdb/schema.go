@@ -0,0 +1,101 @@
+//go:build experimental
+// +build experimental
+
+package couchdb
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+const (
+	schemaDBName       = "lamassu-schema"
+	schemaVersionDocID = "version"
+)
+
+type schemaVersion struct {
+	Version   int       `json:"version"`
+	UpdatedAt time.Time `json:"updated_at"`
+}
+
+func (s *CouchDBStorageEngine) schemaQuerier() (*couchDBQuerier[schemaVersion], error) {
+	err := CheckAndCreateDB(s.couchdbClient, schemaDBName)
+	if err != nil {
+		return nil, err
+	}
+
+	querier := newCouchDBQuerier[schemaVersion](s.couchdbClient.DB(schemaDBName))
+	return &querier, nil
+}
+
+func (s *CouchDBStorageEngine) GetSchemaVersion(ctx context.Context) (int, error) {
+	querier, err := s.schemaQuerier()
+	if err != nil {
+		return -1, err
+	}
+
+	exists, version, err := querier.SelectExists(schemaVersionDocID)
+	if err != nil {
+		return -1, err
+	}
+
+	if !exists {
+		return 0, nil
+	}
+
+	return version.Version, nil
+}
+
+func (s *CouchDBStorageEngine) SetSchemaVersion(ctx context.Context, version int) error {
+	querier, err := s.schemaQuerier()
+	if err != nil {
+		return err
+	}
+
+	exists, _, err := querier.SelectExists(schemaVersionDocID)
+	if err != nil {
+		return err
+	}
+
+	doc := schemaVersion{Version: version, UpdatedAt: time.Now()}
+	if exists {
+		_, err = querier.Update(doc, schemaVersionDocID)
+	} else {
+		_, err = querier.Insert(doc, schemaVersionDocID)
+	}
+
+	return err
+}
+
+// SchemaMigrations returns the migrations of the CouchDB databases. New migrations must be appended with the next
+// version, i.e. to update the design documents and views of the databases after adding filtrable fields to a model.
+func (s *CouchDBStorageEngine) SchemaMigrations() []storage.SchemaMigration {
+	return []storage.SchemaMigration{
+		{
+			Version:     1,
+			Description: "create the databases, count views and field indexes of all the repositories",
+			Migrate: func(ctx context.Context) error {
+				// The repositories create their databases, views and indexes when they are initialized.
+				initializers := []func() error{
+					func() error { _, err := s.GetCAStorage(); return err },
+					func() error { _, err := s.GetCertstorage(); return err },
+					func() error { _, err := s.GetCertificateProfileStorage(); return err },
+					func() error { _, err := s.GetDeviceStorage(); return err },
+					func() error { _, err := s.GetDMSStorage(); return err },
+					func() error { _, err := s.GetACMEAccountStorage(); return err },
+					func() error { _, err := s.GetACMEOrderStorage(); return err },
+				}
+
+				for _, initialize := range initializers {
+					if err := initialize(); err != nil {
+						return err
+					}
+				}
+
+				return nil
+			},
+		},
+	}
+}
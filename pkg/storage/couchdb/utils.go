@@ -16,6 +16,7 @@ import (
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
 	"github.com/sirupsen/logrus"
 )
 
@@ -288,6 +289,59 @@ func (db *couchDBQuerier[E]) Update(elem E, elemID string) (*E, error) {
 	return newUpdatedElem, err
 }
 
+// UpdateIf updates the element only if precondition holds for the stored one. The document is written with the
+// revision the precondition was evaluated on, so CouchDB rejects the update with a conflict if another writer
+// modified it in between.
+func (db *couchDBQuerier[E]) UpdateIf(elem E, elemID string, precondition func(current *E) bool) (*E, error) {
+	rs := db.Get(context.Background(), elemID)
+	if rs.Err() != nil {
+		return nil, rs.Err()
+	}
+
+	rs.Next()
+	var prevElem map[string]interface{}
+	if err := rs.ScanDoc(&prevElem); err != nil {
+		return nil, err
+	}
+
+	marshalPrevElem, err := json.Marshal(prevElem)
+	if err != nil {
+		return nil, err
+	}
+
+	var current E
+	err = json.Unmarshal(marshalPrevElem, &current)
+	if err != nil {
+		return nil, err
+	}
+
+	if !precondition(&current) {
+		return nil, storage.ErrUpdateConflict
+	}
+
+	marshalElem, err := json.Marshal(elem)
+	if err != nil {
+		return nil, err
+	}
+
+	var newElem map[string]interface{}
+	err = json.Unmarshal(marshalElem, &newElem)
+	if err != nil {
+		return nil, err
+	}
+
+	newElem["_rev"] = prevElem["_rev"]
+	_, err = db.Put(context.Background(), elemID, newElem)
+	if kivik.StatusCode(err) == http.StatusConflict {
+		return nil, storage.ErrUpdateConflict
+	} else if err != nil {
+		return nil, err
+	}
+
+	_, newUpdatedElem, err := db.SelectExists(elemID)
+	return newUpdatedElem, err
+}
+
 func (db *couchDBQuerier[E]) Delete(elemID string) error {
 	rs := db.Get(context.Background(), elemID)
 	if rs.Err() != nil {
@@ -12,7 +12,39 @@ type DeviceManagerRepo interface {
 	CountByStatus(ctx context.Context, status models.DeviceStatus) (int, error)
 	SelectAll(ctx context.Context, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error)
 	SelectByDMS(ctx context.Context, dmsID string, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error)
+	SelectByIdentitySlotStatus(ctx context.Context, status models.SlotStatus, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error)
 	SelectExists(ctx context.Context, ID string) (bool, *models.Device, error)
 	Update(ctx context.Context, device *models.Device) (*models.Device, error)
+	// UpdateIf updates the device only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error)
 	Insert(ctx context.Context, device *models.Device) (*models.Device, error)
 }
+
+// DeviceLogsRepo stores the log entries of the devices, apart from the device documents.
+type DeviceLogsRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.DeviceLog]) (string, error)
+	SelectByDevice(ctx context.Context, deviceID string, req StorageListRequest[models.DeviceLog]) (string, error)
+	Insert(ctx context.Context, log *models.DeviceLog) (*models.DeviceLog, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// DeviceGroupsRepo stores the device groups. Group membership is resolved against the devices storage.
+type DeviceGroupsRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.DeviceGroup]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroup, error)
+	Update(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error)
+	Insert(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// DeviceGroupBulkActionsRepo stores the bulk actions run on the device groups and their progress.
+type DeviceGroupBulkActionsRepo interface {
+	SelectByGroup(ctx context.Context, groupID string, req StorageListRequest[models.DeviceGroupBulkAction]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroupBulkAction, error)
+	Update(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error)
+	// UpdateIf updates the action only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, action *models.DeviceGroupBulkAction, precondition func(current *models.DeviceGroupBulkAction) bool) (*models.DeviceGroupBulkAction, error)
+	Insert(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error)
+}
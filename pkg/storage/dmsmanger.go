@@ -2,6 +2,7 @@ package storage
 
 import (
 	"context"
+	"time"
 
 	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
@@ -12,5 +13,57 @@ type DMSRepo interface {
 	SelectAll(ctx context.Context, exhaustiveRun bool, applyFunc func(models.DMS), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error)
 	SelectExists(ctx context.Context, ID string) (bool, *models.DMS, error)
 	Update(ctx context.Context, dms *models.DMS) (*models.DMS, error)
+	// UpdateIf updates the DMS only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, dms *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error)
 	Insert(ctx context.Context, dms *models.DMS) (*models.DMS, error)
 }
+
+// DMSEnrollmentStatsRepo stores the enrollment counters of the DMSs, identified by the DMS ID.
+type DMSEnrollmentStatsRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.DMSEnrollmentStats]) (string, error)
+	SelectExists(ctx context.Context, dmsID string) (bool, *models.DMSEnrollmentStats, error)
+	Update(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error)
+	Insert(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error)
+}
+
+// DMSEnrollmentPoliciesRepo stores the policies that auto-approve the DMS registrations.
+type DMSEnrollmentPoliciesRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.DMSEnrollmentPolicy]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentPolicy, error)
+	Update(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error)
+	Insert(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// DMSBootstrapTokensRepo stores the single-use tokens enrolling devices with the DMSs using bootstrap token auth.
+type DMSBootstrapTokensRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.DMSBootstrapToken]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.DMSBootstrapToken, error)
+	Update(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error)
+	Insert(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error)
+	Delete(ctx context.Context, id string) error
+	// MarkUsed atomically marks the token as used by the device, only if it was not used yet. Returns false if the
+	// token was already used, so concurrent enrollments can never consume the same token twice.
+	MarkUsed(ctx context.Context, id string, deviceID string, usedAt time.Time) (bool, error)
+}
+
+// DMSClaimCodesRepo stores the one-time claim codes enrolling devices through the public claim endpoint.
+type DMSClaimCodesRepo interface {
+	SelectAll(ctx context.Context, req StorageListRequest[models.DMSClaimCode]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.DMSClaimCode, error)
+	Update(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error)
+	// UpdateIf updates the code only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, code *models.DMSClaimCode, precondition func(current *models.DMSClaimCode) bool) (*models.DMSClaimCode, error)
+	Insert(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error)
+	Delete(ctx context.Context, id string) error
+}
+
+// DMSEnrollmentAuditsRepo stores the evaluations of the enrollment context policies of the DMSs. Records are never
+// updated nor deleted.
+type DMSEnrollmentAuditsRepo interface {
+	SelectByDMS(ctx context.Context, dmsID string, req StorageListRequest[models.DMSEnrollmentAudit]) (string, error)
+	SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentAudit, error)
+	Insert(ctx context.Context, audit *models.DMSEnrollmentAudit) (*models.DMSEnrollmentAudit, error)
+}
@@ -0,0 +1,154 @@
+package dualwrite
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+func Register() {
+	storage.RegisterStorageEngine(config.DualWrite, func(logger *log.Entry, conf config.PluggableStorageEngine) (storage.StorageEngine, error) {
+		return NewStorageEngine(logger, conf)
+	})
+}
+
+// DualWriteStorageEngine serves the reads from the source engine and writes the CAs, certificates, DMSs and devices
+// into both the source and the target engines. The source engine remains authoritative: a write is only failed if
+// the source engine fails, writes failing on the target engine are logged and left to the backfill of the
+// storage-migrate tool. The remaining repositories are only read from and written into the source engine.
+type DualWriteStorageEngine struct {
+	storage.StorageEngine
+	target storage.StorageEngine
+	logger *log.Entry
+}
+
+func NewStorageEngine(logger *log.Entry, conf config.PluggableStorageEngine) (storage.StorageEngine, error) {
+	if conf.DualWrite == nil {
+		return nil, fmt.Errorf("dual_write storage engine requires the source and target storage engines")
+	}
+
+	source, err := buildEngine(logger, conf.DualWrite.Source)
+	if err != nil {
+		return nil, fmt.Errorf("could not create source storage engine: %w", err)
+	}
+
+	target, err := buildEngine(logger, conf.DualWrite.Target)
+	if err != nil {
+		return nil, fmt.Errorf("could not create target storage engine: %w", err)
+	}
+
+	logger.Infof("dual writing into %s (source) and %s (target) storage engines", conf.DualWrite.Source.Provider, conf.DualWrite.Target.Provider)
+	return NewDualWriteStorageEngine(source, target, logger), nil
+}
+
+func NewDualWriteStorageEngine(source, target storage.StorageEngine, logger *log.Entry) *DualWriteStorageEngine {
+	return &DualWriteStorageEngine{
+		StorageEngine: source,
+		target:        target,
+		logger:        logger,
+	}
+}
+
+func buildEngine(logger *log.Entry, conf config.PluggableStorageEngine) (storage.StorageEngine, error) {
+	if conf.Provider == config.DualWrite {
+		return nil, fmt.Errorf("dual_write storage engines can not be nested")
+	}
+
+	builder := storage.GetEngineBuilder(conf.Provider)
+	if builder == nil {
+		return nil, fmt.Errorf("no storage engine of type %s", conf.Provider)
+	}
+
+	return builder(logger, conf)
+}
+
+// Engines returns the source and target storage engines.
+func (s *DualWriteStorageEngine) Engines() (source storage.StorageEngine, target storage.StorageEngine) {
+	return s.StorageEngine, s.target
+}
+
+func (s *DualWriteStorageEngine) GetCAStorage() (storage.CACertificatesRepo, error) {
+	source, err := s.StorageEngine.GetCAStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.target.GetCAStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &caRepo{CACertificatesRepo: source, target: target, logger: s.logger}, nil
+}
+
+func (s *DualWriteStorageEngine) GetCertstorage() (storage.CertificatesRepo, error) {
+	source, err := s.StorageEngine.GetCertstorage()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.target.GetCertstorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &certRepo{CertificatesRepo: source, target: target, logger: s.logger}, nil
+}
+
+func (s *DualWriteStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, error) {
+	source, err := s.StorageEngine.GetDeviceStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.target.GetDeviceStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &deviceRepo{DeviceManagerRepo: source, target: target, logger: s.logger}, nil
+}
+
+func (s *DualWriteStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
+	source, err := s.StorageEngine.GetDMSStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	target, err := s.target.GetDMSStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	return &dmsRepo{DMSRepo: source, target: target, logger: s.logger}, nil
+}
+
+// mirror writes a record already stored in the source engine into the target engine.
+func mirror(logger *log.Entry, asset string, id string, write func() error) {
+	err := write()
+	if err != nil {
+		logger.Warnf("could not write %s %s into the target storage engine. It will be copied by the backfill: %s", asset, id, err)
+		return
+	}
+
+	logger.Tracef("%s %s written into the target storage engine", asset, id)
+}
+
+// upsert inserts the record in the target engine, or updates it if it was already copied.
+func upsert[T any](ctx context.Context, record *T, exists func() (bool, *T, error), insert, update func(context.Context, *T) (*T, error)) error {
+	found, _, err := exists()
+	if err != nil {
+		return err
+	}
+
+	if found {
+		_, err = update(ctx, record)
+	} else {
+		_, err = insert(ctx, record)
+	}
+
+	return err
+}
@@ -0,0 +1,119 @@
+package dualwrite
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/memory"
+	log "github.com/sirupsen/logrus"
+)
+
+func newTestEngines(t *testing.T) (*DualWriteStorageEngine, storage.StorageEngine, storage.StorageEngine) {
+	logger := log.WithField("test", t.Name())
+
+	source, err := memory.NewStorageEngine(logger)
+	if err != nil {
+		t.Fatalf("could not create source storage engine: %s", err)
+	}
+
+	target, err := memory.NewStorageEngine(logger)
+	if err != nil {
+		t.Fatalf("could not create target storage engine: %s", err)
+	}
+
+	return NewDualWriteStorageEngine(source, target, logger), source, target
+}
+
+func TestDualWriteDevices(t *testing.T) {
+	ctx := context.Background()
+	engine, source, target := newTestEngines(t)
+
+	repo, err := engine.GetDeviceStorage()
+	if err != nil {
+		t.Fatalf("could not get device storage: %s", err)
+	}
+
+	sourceRepo, _ := source.GetDeviceStorage()
+	targetRepo, _ := target.GetDeviceStorage()
+
+	_, err = repo.Insert(ctx, &models.Device{ID: "device-1", Status: models.DeviceNoIdentity})
+	if err != nil {
+		t.Fatalf("could not insert device: %s", err)
+	}
+
+	for name, r := range map[string]storage.DeviceManagerRepo{"source": sourceRepo, "target": targetRepo} {
+		exists, _, err := r.SelectExists(ctx, "device-1")
+		if err != nil || !exists {
+			t.Fatalf("device should be stored in the %s engine: %v", name, err)
+		}
+	}
+
+	// Records written before enabling the dual write are only in the source engine until the backfill.
+	_, err = sourceRepo.Insert(ctx, &models.Device{ID: "device-2", Status: models.DeviceNoIdentity})
+	if err != nil {
+		t.Fatalf("could not insert device: %s", err)
+	}
+
+	_, err = repo.Update(ctx, &models.Device{ID: "device-2", Status: models.DeviceActive})
+	if err != nil {
+		t.Fatalf("could not update device: %s", err)
+	}
+
+	exists, device, err := targetRepo.SelectExists(ctx, "device-2")
+	if err != nil || !exists {
+		t.Fatalf("updated device should be written into the target engine: %v", err)
+	}
+	if device.Status != models.DeviceActive {
+		t.Fatalf("expected status %s in the target engine, got %s", models.DeviceActive, device.Status)
+	}
+}
+
+func TestDualWriteDeleteCA(t *testing.T) {
+	ctx := context.Background()
+	engine, _, target := newTestEngines(t)
+
+	repo, err := engine.GetCAStorage()
+	if err != nil {
+		t.Fatalf("could not get CA storage: %s", err)
+	}
+	targetRepo, _ := target.GetCAStorage()
+
+	_, err = repo.Insert(ctx, &models.CACertificate{ID: "ca-1", Certificate: models.Certificate{KeyMetadata: models.KeyStrengthMetadata{Type: models.KeyType(x509.ECDSA)}}})
+	if err != nil {
+		t.Fatalf("could not insert CA: %s", err)
+	}
+
+	err = repo.Delete(ctx, "ca-1")
+	if err != nil {
+		t.Fatalf("could not delete CA: %s", err)
+	}
+
+	exists, _, err := targetRepo.SelectExistsByID(ctx, "ca-1")
+	if err != nil || exists {
+		t.Fatalf("CA should be deleted from the target engine: %v", err)
+	}
+}
+
+func TestNewStorageEngineRequiresEngines(t *testing.T) {
+	logger := log.WithField("test", t.Name())
+
+	_, err := NewStorageEngine(logger, config.PluggableStorageEngine{Provider: config.DualWrite})
+	if err == nil {
+		t.Fatalf("expected an error without source and target engines")
+	}
+
+	_, err = NewStorageEngine(logger, config.PluggableStorageEngine{
+		Provider: config.DualWrite,
+		DualWrite: &config.DualWritePSEConfig{
+			Source: config.PluggableStorageEngine{Provider: config.DualWrite},
+			Target: config.PluggableStorageEngine{Provider: config.Memory},
+		},
+	})
+	if err == nil {
+		t.Fatalf("expected an error with nested dual_write engines")
+	}
+}
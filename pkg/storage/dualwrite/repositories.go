@@ -0,0 +1,192 @@
+package dualwrite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+type caRepo struct {
+	storage.CACertificatesRepo
+	target storage.CACertificatesRepo
+	logger *log.Entry
+}
+
+func (r *caRepo) Insert(ctx context.Context, ca *models.CACertificate) (*models.CACertificate, error) {
+	inserted, err := r.CACertificatesRepo.Insert(ctx, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, inserted)
+	return inserted, nil
+}
+
+func (r *caRepo) Update(ctx context.Context, ca *models.CACertificate) (*models.CACertificate, error) {
+	updated, err := r.CACertificatesRepo.Update(ctx, ca)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *caRepo) UpdateIf(ctx context.Context, ca *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error) {
+	updated, err := r.CACertificatesRepo.UpdateIf(ctx, ca, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *caRepo) Delete(ctx context.Context, caID string) error {
+	err := r.CACertificatesRepo.Delete(ctx, caID)
+	if err != nil {
+		return err
+	}
+
+	mirror(r.logger, "CA", caID, func() error {
+		exists, _, err := r.target.SelectExistsByID(ctx, caID)
+		if err != nil || !exists {
+			return err
+		}
+
+		return r.target.Delete(ctx, caID)
+	})
+	return nil
+}
+
+func (r *caRepo) mirror(ctx context.Context, ca *models.CACertificate) {
+	mirror(r.logger, "CA", ca.ID, func() error {
+		return upsert(ctx, ca, func() (bool, *models.CACertificate, error) {
+			return r.target.SelectExistsByID(ctx, ca.ID)
+		}, r.target.Insert, r.target.Update)
+	})
+}
+
+type certRepo struct {
+	storage.CertificatesRepo
+	target storage.CertificatesRepo
+	logger *log.Entry
+}
+
+func (r *certRepo) Insert(ctx context.Context, cert *models.Certificate) (*models.Certificate, error) {
+	inserted, err := r.CertificatesRepo.Insert(ctx, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, inserted)
+	return inserted, nil
+}
+
+func (r *certRepo) Update(ctx context.Context, cert *models.Certificate) (*models.Certificate, error) {
+	updated, err := r.CertificatesRepo.Update(ctx, cert)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *certRepo) mirror(ctx context.Context, cert *models.Certificate) {
+	mirror(r.logger, "certificate", cert.SerialNumber, func() error {
+		return upsert(ctx, cert, func() (bool, *models.Certificate, error) {
+			return r.target.SelectExistsBySerialNumber(ctx, cert.SerialNumber)
+		}, r.target.Insert, r.target.Update)
+	})
+}
+
+type deviceRepo struct {
+	storage.DeviceManagerRepo
+	target storage.DeviceManagerRepo
+	logger *log.Entry
+}
+
+func (r *deviceRepo) Insert(ctx context.Context, device *models.Device) (*models.Device, error) {
+	inserted, err := r.DeviceManagerRepo.Insert(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, inserted)
+	return inserted, nil
+}
+
+func (r *deviceRepo) Update(ctx context.Context, device *models.Device) (*models.Device, error) {
+	updated, err := r.DeviceManagerRepo.Update(ctx, device)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *deviceRepo) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error) {
+	updated, err := r.DeviceManagerRepo.UpdateIf(ctx, device, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *deviceRepo) mirror(ctx context.Context, device *models.Device) {
+	mirror(r.logger, "device", device.ID, func() error {
+		return upsert(ctx, device, func() (bool, *models.Device, error) {
+			return r.target.SelectExists(ctx, device.ID)
+		}, r.target.Insert, r.target.Update)
+	})
+}
+
+type dmsRepo struct {
+	storage.DMSRepo
+	target storage.DMSRepo
+	logger *log.Entry
+}
+
+func (r *dmsRepo) Insert(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
+	inserted, err := r.DMSRepo.Insert(ctx, dms)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, inserted)
+	return inserted, nil
+}
+
+func (r *dmsRepo) Update(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
+	updated, err := r.DMSRepo.Update(ctx, dms)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *dmsRepo) UpdateIf(ctx context.Context, dms *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error) {
+	updated, err := r.DMSRepo.UpdateIf(ctx, dms, precondition)
+	if err != nil {
+		return nil, err
+	}
+
+	r.mirror(ctx, updated)
+	return updated, nil
+}
+
+func (r *dmsRepo) mirror(ctx context.Context, dms *models.DMS) {
+	mirror(r.logger, "DMS", dms.ID, func() error {
+		return upsert(ctx, dms, func() (bool, *models.DMS, error) {
+			return r.target.SelectExists(ctx, dms.ID)
+		}, r.target.Insert, r.target.Update)
+	})
+}
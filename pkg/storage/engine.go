@@ -6,19 +6,53 @@ import (
 )
 
 type CommonStorageEngine struct {
-	CA            CACertificatesRepo
-	Cert          CertificatesRepo
-	Device        DeviceManagerRepo
-	DMS           DMSRepo
-	Events        EventRepository
-	Subscriptions SubscriptionsRepository
+	CA                  CACertificatesRepo
+	Cert                CertificatesRepo
+	CertificateProfiles CertificateProfilesRepo
+	IssuanceLog         IssuanceLogRepo
+	CAEvents            CAEventsRepo
+	CASigningRequests   CASigningRequestsRepo
+	CAPendingActions    CAPendingActionsRepo
+	ConnectorEvents     ConnectorPendingEventsRepo
+	ProcessedEvents     ProcessedEventsRepo
+	Device              DeviceManagerRepo
+	DeviceGroups        DeviceGroupsRepo
+	DeviceBulkActions   DeviceGroupBulkActionsRepo
+	DeviceLogs          DeviceLogsRepo
+	DMS                 DMSRepo
+	DMSEnrollmentStats  DMSEnrollmentStatsRepo
+	DMSEnrollmentPolicy DMSEnrollmentPoliciesRepo
+	DMSBootstrapTokens  DMSBootstrapTokensRepo
+	DMSClaimCodes       DMSClaimCodesRepo
+	DMSEnrollmentAudits DMSEnrollmentAuditsRepo
+	ACMEAccounts        ACMEAccountsRepo
+	ACMEOrders          ACMEOrdersRepo
+	Events              EventRepository
+	Subscriptions       SubscriptionsRepository
 }
 
 type StorageEngine interface {
 	GetCAStorage() (CACertificatesRepo, error)
 	GetCertstorage() (CertificatesRepo, error)
+	GetCertificateProfileStorage() (CertificateProfilesRepo, error)
+	GetIssuanceLogStorage() (IssuanceLogRepo, error)
+	GetCAEventsStorage() (CAEventsRepo, error)
+	GetCASigningRequestsStorage() (CASigningRequestsRepo, error)
+	GetCAPendingActionsStorage() (CAPendingActionsRepo, error)
+	GetConnectorPendingEventsStorage() (ConnectorPendingEventsRepo, error)
+	GetProcessedEventsStorage() (ProcessedEventsRepo, error)
 	GetDeviceStorage() (DeviceManagerRepo, error)
+	GetDeviceGroupsStorage() (DeviceGroupsRepo, error)
+	GetDeviceGroupBulkActionsStorage() (DeviceGroupBulkActionsRepo, error)
+	GetDeviceLogsStorage() (DeviceLogsRepo, error)
 	GetDMSStorage() (DMSRepo, error)
+	GetDMSEnrollmentStatsStorage() (DMSEnrollmentStatsRepo, error)
+	GetDMSEnrollmentPoliciesStorage() (DMSEnrollmentPoliciesRepo, error)
+	GetDMSBootstrapTokensStorage() (DMSBootstrapTokensRepo, error)
+	GetDMSClaimCodesStorage() (DMSClaimCodesRepo, error)
+	GetDMSEnrollmentAuditsStorage() (DMSEnrollmentAuditsRepo, error)
+	GetACMEAccountStorage() (ACMEAccountsRepo, error)
+	GetACMEOrderStorage() (ACMEOrdersRepo, error)
 	GetEnventsStorage() (EventRepository, error)
 	GetSubscriptionsStorage() (SubscriptionsRepository, error)
 }
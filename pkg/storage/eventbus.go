@@ -0,0 +1,22 @@
+package storage
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// ProcessedEventsRepo stores the events handled by the event bus consumers, so the replicas of a consumer detect the
+// redeliveries of the events handled by the others.
+type ProcessedEventsRepo interface {
+	SelectExists(ctx context.Context, key string) (bool, *models.ProcessedEvent, error)
+	// SelectExpired iterates the records expired before the given time.
+	SelectExpired(ctx context.Context, before time.Time, req StorageListRequest[models.ProcessedEvent]) (string, error)
+	Insert(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error)
+	Update(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error)
+	// UpdateIf updates the record only if precondition holds for the stored one, checked atomically with the write.
+	// Returns ErrUpdateConflict otherwise.
+	UpdateIf(ctx context.Context, event *models.ProcessedEvent, precondition func(current *models.ProcessedEvent) bool) (*models.ProcessedEvent, error)
+	Delete(ctx context.Context, key string) error
+}
@@ -0,0 +1,58 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryACMEAccountStore struct {
+	querier *memoryQuerier[models.ACMEAccount]
+}
+
+func NewACMEAccountRepository() storage.ACMEAccountsRepo {
+	return &MemoryACMEAccountStore{
+		querier: newMemoryQuerier[models.ACMEAccount](),
+	}
+}
+
+func (db *MemoryACMEAccountStore) SelectExists(ctx context.Context, id string) (bool, *models.ACMEAccount, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryACMEAccountStore) Insert(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Insert(ctx, account, account.ID)
+}
+
+func (db *MemoryACMEAccountStore) Update(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Update(ctx, account, account.ID)
+}
+
+type MemoryACMEOrderStore struct {
+	querier *memoryQuerier[models.ACMEOrder]
+}
+
+func NewACMEOrderRepository() storage.ACMEOrdersRepo {
+	return &MemoryACMEOrderStore{
+		querier: newMemoryQuerier[models.ACMEOrder](),
+	}
+}
+
+func (db *MemoryACMEOrderStore) SelectByAccount(ctx context.Context, accountID string, req storage.StorageListRequest[models.ACMEOrder]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(order models.ACMEOrder) bool {
+		return order.AccountID == accountID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryACMEOrderStore) SelectExists(ctx context.Context, id string) (bool, *models.ACMEOrder, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryACMEOrderStore) Insert(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Insert(ctx, order, order.ID)
+}
+
+func (db *MemoryACMEOrderStore) Update(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Update(ctx, order, order.ID)
+}
@@ -0,0 +1,28 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryCAEventsStore struct {
+	querier *memoryQuerier[models.CAEvent]
+}
+
+func NewCAEventsRepository() storage.CAEventsRepo {
+	return &MemoryCAEventsStore{
+		querier: newMemoryQuerier[models.CAEvent](),
+	}
+}
+
+func (db *MemoryCAEventsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAEvent]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(event models.CAEvent) bool {
+		return event.CAID == caID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCAEventsStore) Insert(ctx context.Context, event *models.CAEvent) (*models.CAEvent, error) {
+	return db.querier.Insert(ctx, event, event.ID)
+}
@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryCAPendingActionsStore struct {
+	querier *memoryQuerier[models.CAPendingAction]
+}
+
+func NewCAPendingActionsRepository() storage.CAPendingActionsRepo {
+	return &MemoryCAPendingActionsStore{
+		querier: newMemoryQuerier[models.CAPendingAction](),
+	}
+}
+
+func (db *MemoryCAPendingActionsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAPendingAction]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(action models.CAPendingAction) bool {
+		return action.CAID == caID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCAPendingActionsStore) SelectExists(ctx context.Context, id string) (bool, *models.CAPendingAction, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryCAPendingActionsStore) UpdateIf(ctx context.Context, action *models.CAPendingAction, precondition func(current *models.CAPendingAction) bool) (*models.CAPendingAction, error) {
+	return db.querier.UpdateIf(ctx, action, action.ID, precondition)
+}
+
+func (db *MemoryCAPendingActionsStore) Insert(ctx context.Context, action *models.CAPendingAction) (*models.CAPendingAction, error) {
+	return db.querier.Insert(ctx, action, action.ID)
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryCASigningRequestsStore struct {
+	querier *memoryQuerier[models.CASigningRequest]
+}
+
+func NewCASigningRequestsRepository() storage.CASigningRequestsRepo {
+	return &MemoryCASigningRequestsStore{
+		querier: newMemoryQuerier[models.CASigningRequest](),
+	}
+}
+
+func (db *MemoryCASigningRequestsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CASigningRequest]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(request models.CASigningRequest) bool {
+		return request.CAID == caID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCASigningRequestsStore) SelectExists(ctx context.Context, id string) (bool, *models.CASigningRequest, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryCASigningRequestsStore) Update(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Update(ctx, request, request.ID)
+}
+
+func (db *MemoryCASigningRequestsStore) UpdateIf(ctx context.Context, request *models.CASigningRequest, precondition func(current *models.CASigningRequest) bool) (*models.CASigningRequest, error) {
+	return db.querier.UpdateIf(ctx, request, request.ID, precondition)
+}
+
+func (db *MemoryCASigningRequestsStore) Insert(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Insert(ctx, request, request.ID)
+}
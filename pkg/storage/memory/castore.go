@@ -0,0 +1,82 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryCAStore struct {
+	querier *memoryQuerier[models.CACertificate]
+}
+
+func NewCAMemoryRepository() storage.CACertificatesRepo {
+	return &MemoryCAStore{
+		querier: newMemoryQuerier[models.CACertificate](),
+	}
+}
+
+func (db *MemoryCAStore) Count(ctx context.Context) (int, error) {
+	return db.querier.Count(ctx, nil)
+}
+
+func (db *MemoryCAStore) CountByEngine(ctx context.Context, engineID string) (int, error) {
+	return db.querier.Count(ctx, func(ca models.CACertificate) bool {
+		return ca.Certificate.EngineID == engineID
+	})
+}
+
+func (db *MemoryCAStore) CountByStatus(ctx context.Context, status models.CertificateStatus) (int, error) {
+	return db.querier.Count(ctx, func(ca models.CACertificate) bool {
+		return ca.Certificate.Status == status
+	})
+}
+
+func (db *MemoryCAStore) SelectByType(ctx context.Context, CAType models.CertificateType, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(ca models.CACertificate) bool {
+		return ca.Type == CAType
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCAStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCAStore) SelectByCommonName(ctx context.Context, commonName string, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(ca models.CACertificate) bool {
+		return ca.Certificate.Subject.CommonName == commonName
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCAStore) SelectExistsBySerialNumber(ctx context.Context, serialNumber string) (bool, *models.CACertificate, error) {
+	return db.querier.SelectFirst(ctx, func(ca models.CACertificate) bool {
+		return ca.Certificate.SerialNumber == serialNumber
+	})
+}
+
+func (db *MemoryCAStore) SelectByParentCA(ctx context.Context, parentCAID string, req storage.StorageListRequest[models.CACertificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(ca models.CACertificate) bool {
+		return ca.Certificate.IssuerCAMetadata.ID == parentCAID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCAStore) SelectExistsByID(ctx context.Context, id string) (bool, *models.CACertificate, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryCAStore) Insert(ctx context.Context, caCertificate *models.CACertificate) (*models.CACertificate, error) {
+	return db.querier.Insert(ctx, caCertificate, caCertificate.ID)
+}
+
+func (db *MemoryCAStore) Update(ctx context.Context, caCertificate *models.CACertificate) (*models.CACertificate, error) {
+	return db.querier.Update(ctx, caCertificate, caCertificate.ID)
+}
+
+func (db *MemoryCAStore) UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error) {
+	return db.querier.UpdateIf(ctx, caCertificate, caCertificate.ID, precondition)
+}
+
+func (db *MemoryCAStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
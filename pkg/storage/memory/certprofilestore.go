@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"slices"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryCertificateProfileStore struct {
+	querier *memoryQuerier[models.CertificateProfile]
+}
+
+func NewCertificateProfileRepository() storage.CertificateProfilesRepo {
+	return &MemoryCertificateProfileStore{
+		querier: newMemoryQuerier[models.CertificateProfile](),
+	}
+}
+
+func (db *MemoryCertificateProfileStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCertificateProfileStore) SelectExists(ctx context.Context, id string) (bool, *models.CertificateProfile, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryCertificateProfileStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(profile models.CertificateProfile) bool {
+		return slices.Contains(profile.CAIDs, caID)
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCertificateProfileStore) Insert(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Insert(ctx, profile, profile.ID)
+}
+
+func (db *MemoryCertificateProfileStore) Update(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Update(ctx, profile, profile.ID)
+}
+
+func (db *MemoryCertificateProfileStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
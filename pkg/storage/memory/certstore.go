@@ -0,0 +1,76 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryCertificateStorage struct {
+	querier *memoryQuerier[models.Certificate]
+}
+
+func NewCertificateRepository() storage.CertificatesRepo {
+	return &MemoryCertificateStorage{
+		querier: newMemoryQuerier[models.Certificate](),
+	}
+}
+
+func (db *MemoryCertificateStorage) Count(ctx context.Context) (int, error) {
+	return db.querier.Count(ctx, nil)
+}
+
+func (db *MemoryCertificateStorage) CountByCA(ctx context.Context, CAID string) (int, error) {
+	return db.querier.Count(ctx, func(crt models.Certificate) bool {
+		return crt.IssuerCAMetadata.ID == CAID
+	})
+}
+
+func (db *MemoryCertificateStorage) CountByCAIDAndStatus(ctx context.Context, caID string, status models.CertificateStatus) (int, error) {
+	return db.querier.Count(ctx, func(crt models.Certificate) bool {
+		return crt.IssuerCAMetadata.ID == caID && crt.Status == status
+	})
+}
+
+func (db *MemoryCertificateStorage) SelectAll(ctx context.Context, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCertificateStorage) SelectExistsBySerialNumber(ctx context.Context, serialNumber string) (bool, *models.Certificate, error) {
+	return db.querier.SelectExists(ctx, serialNumber)
+}
+
+func (db *MemoryCertificateStorage) Insert(ctx context.Context, certificate *models.Certificate) (*models.Certificate, error) {
+	return db.querier.Insert(ctx, certificate, certificate.SerialNumber)
+}
+
+func (db *MemoryCertificateStorage) Update(ctx context.Context, certificate *models.Certificate) (*models.Certificate, error) {
+	return db.querier.Update(ctx, certificate, certificate.SerialNumber)
+}
+
+func (db *MemoryCertificateStorage) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(crt models.Certificate) bool {
+		return crt.IssuerCAMetadata.ID == caID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCertificateStorage) SelectByExpirationDate(ctx context.Context, beforeExpirationDate time.Time, afterExpirationDate time.Time, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(crt models.Certificate) bool {
+		return crt.ValidTo.After(afterExpirationDate) && crt.ValidTo.Before(beforeExpirationDate) &&
+			crt.Status != models.StatusExpired && crt.Status != models.StatusRevoked
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCertificateStorage) SelectByCAIDAndStatus(ctx context.Context, CAID string, status models.CertificateStatus, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(crt models.Certificate) bool {
+		return crt.IssuerCAMetadata.ID == CAID && crt.Status == status
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryCertificateStorage) SelectByStatus(ctx context.Context, status models.CertificateStatus, req storage.StorageListRequest[models.Certificate]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(crt models.Certificate) bool {
+		return crt.Status == status
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
@@ -0,0 +1,40 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryConnectorPendingEventsStore struct {
+	querier *memoryQuerier[models.ConnectorPendingEvent]
+}
+
+func NewConnectorPendingEventsRepository() storage.ConnectorPendingEventsRepo {
+	return &MemoryConnectorPendingEventsStore{
+		querier: newMemoryQuerier[models.ConnectorPendingEvent](),
+	}
+}
+
+func (db *MemoryConnectorPendingEventsStore) SelectByConnector(ctx context.Context, connectorID string, req storage.StorageListRequest[models.ConnectorPendingEvent]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(event models.ConnectorPendingEvent) bool {
+		return event.ConnectorID == connectorID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryConnectorPendingEventsStore) SelectExistsByID(ctx context.Context, id string) (bool, *models.ConnectorPendingEvent, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryConnectorPendingEventsStore) Insert(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Insert(ctx, event, event.ID)
+}
+
+func (db *MemoryConnectorPendingEventsStore) Update(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Update(ctx, event, event.ID)
+}
+
+func (db *MemoryConnectorPendingEventsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
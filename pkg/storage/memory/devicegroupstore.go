@@ -0,0 +1,70 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDeviceGroupsStore struct {
+	querier *memoryQuerier[models.DeviceGroup]
+}
+
+func NewDeviceGroupsRepository() storage.DeviceGroupsRepo {
+	return &MemoryDeviceGroupsStore{
+		querier: newMemoryQuerier[models.DeviceGroup](),
+	}
+}
+
+func (db *MemoryDeviceGroupsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceGroup]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDeviceGroupsStore) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroup, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryDeviceGroupsStore) Update(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Update(ctx, group, group.ID)
+}
+
+func (db *MemoryDeviceGroupsStore) Insert(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Insert(ctx, group, group.ID)
+}
+
+func (db *MemoryDeviceGroupsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
+
+type MemoryDeviceGroupBulkActionsStore struct {
+	querier *memoryQuerier[models.DeviceGroupBulkAction]
+}
+
+func NewDeviceGroupBulkActionsRepository() storage.DeviceGroupBulkActionsRepo {
+	return &MemoryDeviceGroupBulkActionsStore{
+		querier: newMemoryQuerier[models.DeviceGroupBulkAction](),
+	}
+}
+
+func (db *MemoryDeviceGroupBulkActionsStore) SelectByGroup(ctx context.Context, groupID string, req storage.StorageListRequest[models.DeviceGroupBulkAction]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(action models.DeviceGroupBulkAction) bool {
+		return action.GroupID == groupID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDeviceGroupBulkActionsStore) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroupBulkAction, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryDeviceGroupBulkActionsStore) Update(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Update(ctx, action, action.ID)
+}
+
+func (db *MemoryDeviceGroupBulkActionsStore) UpdateIf(ctx context.Context, action *models.DeviceGroupBulkAction, precondition func(current *models.DeviceGroupBulkAction) bool) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.UpdateIf(ctx, action, action.ID, precondition)
+}
+
+func (db *MemoryDeviceGroupBulkActionsStore) Insert(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Insert(ctx, action, action.ID)
+}
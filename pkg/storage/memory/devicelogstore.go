@@ -0,0 +1,36 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDeviceLogsStore struct {
+	querier *memoryQuerier[models.DeviceLog]
+}
+
+func NewDeviceLogsRepository() storage.DeviceLogsRepo {
+	return &MemoryDeviceLogsStore{
+		querier: newMemoryQuerier[models.DeviceLog](),
+	}
+}
+
+func (db *MemoryDeviceLogsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDeviceLogsStore) SelectByDevice(ctx context.Context, deviceID string, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(log models.DeviceLog) bool {
+		return log.DeviceID == deviceID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDeviceLogsStore) Insert(ctx context.Context, log *models.DeviceLog) (*models.DeviceLog, error) {
+	return db.querier.Insert(ctx, log, log.ID)
+}
+
+func (db *MemoryDeviceLogsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
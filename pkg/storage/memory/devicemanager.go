@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDeviceManagerStore struct {
+	querier *memoryQuerier[models.Device]
+}
+
+func NewDeviceManagerRepository() storage.DeviceManagerRepo {
+	return &MemoryDeviceManagerStore{
+		querier: newMemoryQuerier[models.Device](),
+	}
+}
+
+func (db *MemoryDeviceManagerStore) Count(ctx context.Context) (int, error) {
+	return db.querier.Count(ctx, nil)
+}
+
+func (db *MemoryDeviceManagerStore) CountByStatus(ctx context.Context, status models.DeviceStatus) (int, error) {
+	return db.querier.Count(ctx, func(dev models.Device) bool {
+		return dev.Status == status
+	})
+}
+
+func (db *MemoryDeviceManagerStore) SelectAll(ctx context.Context, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return db.querier.SelectAll(ctx, queryParams, nil, exhaustiveRun, applyFunc)
+}
+
+func (db *MemoryDeviceManagerStore) SelectByDMS(ctx context.Context, dmsID string, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return db.querier.SelectAll(ctx, queryParams, func(dev models.Device) bool {
+		return dev.DMSOwner == dmsID
+	}, exhaustiveRun, applyFunc)
+}
+
+func (db *MemoryDeviceManagerStore) SelectByIdentitySlotStatus(ctx context.Context, status models.SlotStatus, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return db.querier.SelectAll(ctx, queryParams, func(dev models.Device) bool {
+		return dev.IdentitySlot != nil && dev.IdentitySlot.Status == status
+	}, exhaustiveRun, applyFunc)
+}
+
+func (db *MemoryDeviceManagerStore) SelectExists(ctx context.Context, ID string) (bool, *models.Device, error) {
+	return db.querier.SelectExists(ctx, ID)
+}
+
+func (db *MemoryDeviceManagerStore) Update(ctx context.Context, device *models.Device) (*models.Device, error) {
+	return db.querier.Update(ctx, device, device.ID)
+}
+
+func (db *MemoryDeviceManagerStore) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error) {
+	return db.querier.UpdateIf(ctx, device, device.ID, precondition)
+}
+
+func (db *MemoryDeviceManagerStore) Insert(ctx context.Context, device *models.Device) (*models.Device, error) {
+	return db.querier.Insert(ctx, device, device.ID)
+}
@@ -0,0 +1,61 @@
+package memory
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDMSBootstrapTokensStore struct {
+	querier *memoryQuerier[models.DMSBootstrapToken]
+	// useLock serializes MarkUsed, as the querier has no conditional updates.
+	useLock sync.Mutex
+}
+
+func NewDMSBootstrapTokensRepository() storage.DMSBootstrapTokensRepo {
+	return &MemoryDMSBootstrapTokensStore{
+		querier: newMemoryQuerier[models.DMSBootstrapToken](),
+	}
+}
+
+func (db *MemoryDMSBootstrapTokensStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSBootstrapToken]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDMSBootstrapTokensStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSBootstrapToken, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryDMSBootstrapTokensStore) Update(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error) {
+	return db.querier.Update(ctx, token, token.ID)
+}
+
+func (db *MemoryDMSBootstrapTokensStore) Insert(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error) {
+	return db.querier.Insert(ctx, token, token.ID)
+}
+
+func (db *MemoryDMSBootstrapTokensStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
+
+func (db *MemoryDMSBootstrapTokensStore) MarkUsed(ctx context.Context, id string, deviceID string, usedAt time.Time) (bool, error) {
+	db.useLock.Lock()
+	defer db.useLock.Unlock()
+
+	exists, token, err := db.querier.SelectExists(ctx, id)
+	if err != nil || !exists || token.UsedAt != nil {
+		return false, err
+	}
+
+	token.UsedAt = &usedAt
+	token.UsedBy = deviceID
+	_, err = db.querier.Update(ctx, token, token.ID)
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
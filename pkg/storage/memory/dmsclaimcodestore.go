@@ -0,0 +1,42 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDMSClaimCodesStore struct {
+	querier *memoryQuerier[models.DMSClaimCode]
+}
+
+func NewDMSClaimCodesRepository() storage.DMSClaimCodesRepo {
+	return &MemoryDMSClaimCodesStore{
+		querier: newMemoryQuerier[models.DMSClaimCode](),
+	}
+}
+
+func (db *MemoryDMSClaimCodesStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSClaimCode]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDMSClaimCodesStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSClaimCode, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryDMSClaimCodesStore) Update(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Update(ctx, code, code.ID)
+}
+
+func (db *MemoryDMSClaimCodesStore) UpdateIf(ctx context.Context, code *models.DMSClaimCode, precondition func(current *models.DMSClaimCode) bool) (*models.DMSClaimCode, error) {
+	return db.querier.UpdateIf(ctx, code, code.ID, precondition)
+}
+
+func (db *MemoryDMSClaimCodesStore) Insert(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Insert(ctx, code, code.ID)
+}
+
+func (db *MemoryDMSClaimCodesStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
@@ -0,0 +1,32 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDMSEnrollmentAuditsStore struct {
+	querier *memoryQuerier[models.DMSEnrollmentAudit]
+}
+
+func NewDMSEnrollmentAuditsRepository() storage.DMSEnrollmentAuditsRepo {
+	return &MemoryDMSEnrollmentAuditsStore{
+		querier: newMemoryQuerier[models.DMSEnrollmentAudit](),
+	}
+}
+
+func (db *MemoryDMSEnrollmentAuditsStore) SelectByDMS(ctx context.Context, dmsID string, req storage.StorageListRequest[models.DMSEnrollmentAudit]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(audit models.DMSEnrollmentAudit) bool {
+		return audit.DMSID == dmsID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDMSEnrollmentAuditsStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentAudit, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryDMSEnrollmentAuditsStore) Insert(ctx context.Context, audit *models.DMSEnrollmentAudit) (*models.DMSEnrollmentAudit, error) {
+	return db.querier.Insert(ctx, audit, audit.ID)
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDMSEnrollmentPoliciesStore struct {
+	querier *memoryQuerier[models.DMSEnrollmentPolicy]
+}
+
+func NewDMSEnrollmentPoliciesRepository() storage.DMSEnrollmentPoliciesRepo {
+	return &MemoryDMSEnrollmentPoliciesStore{
+		querier: newMemoryQuerier[models.DMSEnrollmentPolicy](),
+	}
+}
+
+func (db *MemoryDMSEnrollmentPoliciesStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentPolicy]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDMSEnrollmentPoliciesStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentPolicy, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryDMSEnrollmentPoliciesStore) Update(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Update(ctx, policy, policy.ID)
+}
+
+func (db *MemoryDMSEnrollmentPoliciesStore) Insert(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Insert(ctx, policy, policy.ID)
+}
+
+func (db *MemoryDMSEnrollmentPoliciesStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
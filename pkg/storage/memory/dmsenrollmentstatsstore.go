@@ -0,0 +1,34 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDMSEnrollmentStatsStore struct {
+	querier *memoryQuerier[models.DMSEnrollmentStats]
+}
+
+func NewDMSEnrollmentStatsRepository() storage.DMSEnrollmentStatsRepo {
+	return &MemoryDMSEnrollmentStatsStore{
+		querier: newMemoryQuerier[models.DMSEnrollmentStats](),
+	}
+}
+
+func (db *MemoryDMSEnrollmentStatsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentStats]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, nil, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryDMSEnrollmentStatsStore) SelectExists(ctx context.Context, dmsID string) (bool, *models.DMSEnrollmentStats, error) {
+	return db.querier.SelectExists(ctx, dmsID)
+}
+
+func (db *MemoryDMSEnrollmentStatsStore) Update(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Update(ctx, stats, stats.DMSID)
+}
+
+func (db *MemoryDMSEnrollmentStatsStore) Insert(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Insert(ctx, stats, stats.DMSID)
+}
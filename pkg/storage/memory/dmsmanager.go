@@ -0,0 +1,43 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryDMSManagerStore struct {
+	querier *memoryQuerier[models.DMS]
+}
+
+func NewDMSManagerRepository() storage.DMSRepo {
+	return &MemoryDMSManagerStore{
+		querier: newMemoryQuerier[models.DMS](),
+	}
+}
+
+func (db *MemoryDMSManagerStore) Count(ctx context.Context) (int, error) {
+	return db.querier.Count(ctx, nil)
+}
+
+func (db *MemoryDMSManagerStore) SelectAll(ctx context.Context, exhaustiveRun bool, applyFunc func(models.DMS), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return db.querier.SelectAll(ctx, queryParams, nil, exhaustiveRun, applyFunc)
+}
+
+func (db *MemoryDMSManagerStore) SelectExists(ctx context.Context, ID string) (bool, *models.DMS, error) {
+	return db.querier.SelectExists(ctx, ID)
+}
+
+func (db *MemoryDMSManagerStore) Update(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
+	return db.querier.Update(ctx, dms, dms.ID)
+}
+
+func (db *MemoryDMSManagerStore) UpdateIf(ctx context.Context, dms *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error) {
+	return db.querier.UpdateIf(ctx, dms, dms.ID, precondition)
+}
+
+func (db *MemoryDMSManagerStore) Insert(ctx context.Context, dms *models.DMS) (*models.DMS, error) {
+	return db.querier.Insert(ctx, dms, dms.ID)
+}
@@ -0,0 +1,260 @@
+package memory
+
+import (
+	"sync"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	log "github.com/sirupsen/logrus"
+)
+
+func Register() {
+	storage.RegisterStorageEngine(config.Memory, func(logger *log.Entry, conf config.PluggableStorageEngine) (storage.StorageEngine, error) {
+		return NewStorageEngine(logger)
+	})
+}
+
+// MemoryStorageEngine keeps all the repositories in memory. Data is lost once the process exits, so it is only meant
+// for development and tests.
+type MemoryStorageEngine struct {
+	storage.CommonStorageEngine
+	lock   sync.Mutex
+	logger *log.Entry
+}
+
+func NewStorageEngine(logger *log.Entry) (storage.StorageEngine, error) {
+	logger.Warn("using in-memory storage engine. Data will be lost once the service stops")
+	return &MemoryStorageEngine{
+		logger: logger,
+	}, nil
+}
+
+func (s *MemoryStorageEngine) GetCAStorage() (storage.CACertificatesRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.CA == nil {
+		s.CA = NewCAMemoryRepository()
+	}
+	return s.CA, nil
+}
+
+func (s *MemoryStorageEngine) GetCertstorage() (storage.CertificatesRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.Cert == nil {
+		s.Cert = NewCertificateRepository()
+	}
+	return s.Cert, nil
+}
+
+func (s *MemoryStorageEngine) GetCertificateProfileStorage() (storage.CertificateProfilesRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.CertificateProfiles == nil {
+		s.CertificateProfiles = NewCertificateProfileRepository()
+	}
+	return s.CertificateProfiles, nil
+}
+
+func (s *MemoryStorageEngine) GetIssuanceLogStorage() (storage.IssuanceLogRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.IssuanceLog == nil {
+		s.IssuanceLog = NewIssuanceLogRepository()
+	}
+	return s.IssuanceLog, nil
+}
+
+func (s *MemoryStorageEngine) GetCAEventsStorage() (storage.CAEventsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.CAEvents == nil {
+		s.CAEvents = NewCAEventsRepository()
+	}
+	return s.CAEvents, nil
+}
+
+func (s *MemoryStorageEngine) GetCASigningRequestsStorage() (storage.CASigningRequestsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.CASigningRequests == nil {
+		s.CASigningRequests = NewCASigningRequestsRepository()
+	}
+	return s.CASigningRequests, nil
+}
+
+func (s *MemoryStorageEngine) GetCAPendingActionsStorage() (storage.CAPendingActionsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.CAPendingActions == nil {
+		s.CAPendingActions = NewCAPendingActionsRepository()
+	}
+	return s.CAPendingActions, nil
+}
+
+func (s *MemoryStorageEngine) GetConnectorPendingEventsStorage() (storage.ConnectorPendingEventsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ConnectorEvents == nil {
+		s.ConnectorEvents = NewConnectorPendingEventsRepository()
+	}
+	return s.ConnectorEvents, nil
+}
+
+func (s *MemoryStorageEngine) GetProcessedEventsStorage() (storage.ProcessedEventsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ProcessedEvents == nil {
+		s.ProcessedEvents = NewProcessedEventsRepository()
+	}
+	return s.ProcessedEvents, nil
+}
+
+func (s *MemoryStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.Device == nil {
+		s.Device = NewDeviceManagerRepository()
+	}
+	return s.Device, nil
+}
+
+func (s *MemoryStorageEngine) GetDeviceGroupsStorage() (storage.DeviceGroupsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DeviceGroups == nil {
+		s.DeviceGroups = NewDeviceGroupsRepository()
+	}
+	return s.DeviceGroups, nil
+}
+
+func (s *MemoryStorageEngine) GetDeviceGroupBulkActionsStorage() (storage.DeviceGroupBulkActionsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DeviceBulkActions == nil {
+		s.DeviceBulkActions = NewDeviceGroupBulkActionsRepository()
+	}
+	return s.DeviceBulkActions, nil
+}
+
+func (s *MemoryStorageEngine) GetDeviceLogsStorage() (storage.DeviceLogsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DeviceLogs == nil {
+		s.DeviceLogs = NewDeviceLogsRepository()
+	}
+	return s.DeviceLogs, nil
+}
+
+func (s *MemoryStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DMS == nil {
+		s.DMS = NewDMSManagerRepository()
+	}
+	return s.DMS, nil
+}
+
+func (s *MemoryStorageEngine) GetDMSEnrollmentStatsStorage() (storage.DMSEnrollmentStatsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DMSEnrollmentStats == nil {
+		s.DMSEnrollmentStats = NewDMSEnrollmentStatsRepository()
+	}
+	return s.DMSEnrollmentStats, nil
+}
+
+func (s *MemoryStorageEngine) GetDMSEnrollmentPoliciesStorage() (storage.DMSEnrollmentPoliciesRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DMSEnrollmentPolicy == nil {
+		s.DMSEnrollmentPolicy = NewDMSEnrollmentPoliciesRepository()
+	}
+	return s.DMSEnrollmentPolicy, nil
+}
+
+func (s *MemoryStorageEngine) GetDMSBootstrapTokensStorage() (storage.DMSBootstrapTokensRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DMSBootstrapTokens == nil {
+		s.DMSBootstrapTokens = NewDMSBootstrapTokensRepository()
+	}
+	return s.DMSBootstrapTokens, nil
+}
+
+func (s *MemoryStorageEngine) GetDMSClaimCodesStorage() (storage.DMSClaimCodesRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DMSClaimCodes == nil {
+		s.DMSClaimCodes = NewDMSClaimCodesRepository()
+	}
+	return s.DMSClaimCodes, nil
+}
+
+func (s *MemoryStorageEngine) GetDMSEnrollmentAuditsStorage() (storage.DMSEnrollmentAuditsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.DMSEnrollmentAudits == nil {
+		s.DMSEnrollmentAudits = NewDMSEnrollmentAuditsRepository()
+	}
+	return s.DMSEnrollmentAudits, nil
+}
+
+func (s *MemoryStorageEngine) GetACMEAccountStorage() (storage.ACMEAccountsRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ACMEAccounts == nil {
+		s.ACMEAccounts = NewACMEAccountRepository()
+	}
+	return s.ACMEAccounts, nil
+}
+
+func (s *MemoryStorageEngine) GetACMEOrderStorage() (storage.ACMEOrdersRepo, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.ACMEOrders == nil {
+		s.ACMEOrders = NewACMEOrderRepository()
+	}
+	return s.ACMEOrders, nil
+}
+
+func (s *MemoryStorageEngine) GetEnventsStorage() (storage.EventRepository, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.Events == nil {
+		s.Events = NewEventsMemoryRepository()
+	}
+	return s.Events, nil
+}
+
+func (s *MemoryStorageEngine) GetSubscriptionsStorage() (storage.SubscriptionsRepository, error) {
+	s.lock.Lock()
+	defer s.lock.Unlock()
+
+	if s.Subscriptions == nil {
+		s.Subscriptions = NewSubscriptionsMemoryRepository()
+	}
+	return s.Subscriptions, nil
+}
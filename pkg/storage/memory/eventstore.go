@@ -0,0 +1,48 @@
+package memory
+
+import (
+	"context"
+	"errors"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryEventsStore struct {
+	querier *memoryQuerier[models.AlertLatestEvent]
+}
+
+func NewEventsMemoryRepository() storage.EventRepository {
+	return &MemoryEventsStore{
+		querier: newMemoryQuerier[models.AlertLatestEvent](),
+	}
+}
+
+func (db *MemoryEventsStore) InsertUpdateEvent(ctx context.Context, ev *models.AlertLatestEvent) (*models.AlertLatestEvent, error) {
+	event, err := db.querier.Update(ctx, ev, string(ev.EventType))
+	if err == nil {
+		return event, nil
+	}
+	if errors.Is(err, ErrElemNotFound) {
+		return db.querier.Insert(ctx, ev, string(ev.EventType))
+	}
+	return nil, err
+}
+
+func (db *MemoryEventsStore) GetLatestEventByEventType(ctx context.Context, eventType models.EventType) (bool, *models.AlertLatestEvent, error) {
+	return db.querier.SelectExists(ctx, string(eventType))
+}
+
+func (db *MemoryEventsStore) GetLatestEvents(ctx context.Context) ([]*models.AlertLatestEvent, error) {
+	evs := []*models.AlertLatestEvent{}
+	_, err := db.querier.SelectAll(ctx, nil, nil, true, func(elem models.AlertLatestEvent) {
+		derefElem := elem
+		evs = append(evs, &derefElem)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return evs, nil
+}
@@ -0,0 +1,38 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryIssuanceLogStore struct {
+	querier *memoryQuerier[models.IssuanceLogEntry]
+}
+
+func NewIssuanceLogRepository() storage.IssuanceLogRepo {
+	return &MemoryIssuanceLogStore{
+		querier: newMemoryQuerier[models.IssuanceLogEntry](),
+	}
+}
+
+func (db *MemoryIssuanceLogStore) CountByCA(ctx context.Context, caID string) (int, error) {
+	return db.querier.Count(ctx, func(entry models.IssuanceLogEntry) bool {
+		return entry.CAID == caID
+	})
+}
+
+func (db *MemoryIssuanceLogStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.IssuanceLogEntry]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(entry models.IssuanceLogEntry) bool {
+		return entry.CAID == caID
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryIssuanceLogStore) SelectExists(ctx context.Context, id string) (bool, *models.IssuanceLogEntry, error) {
+	return db.querier.SelectExists(ctx, id)
+}
+
+func (db *MemoryIssuanceLogStore) Insert(ctx context.Context, entry *models.IssuanceLogEntry) (*models.IssuanceLogEntry, error) {
+	return db.querier.Insert(ctx, entry, entry.ID)
+}
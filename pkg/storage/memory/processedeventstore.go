@@ -0,0 +1,45 @@
+package memory
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemoryProcessedEventsStore struct {
+	querier *memoryQuerier[models.ProcessedEvent]
+}
+
+func NewProcessedEventsRepository() storage.ProcessedEventsRepo {
+	return &MemoryProcessedEventsStore{
+		querier: newMemoryQuerier[models.ProcessedEvent](),
+	}
+}
+
+func (db *MemoryProcessedEventsStore) SelectExists(ctx context.Context, key string) (bool, *models.ProcessedEvent, error) {
+	return db.querier.SelectExists(ctx, key)
+}
+
+func (db *MemoryProcessedEventsStore) SelectExpired(ctx context.Context, before time.Time, req storage.StorageListRequest[models.ProcessedEvent]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, func(event models.ProcessedEvent) bool {
+		return event.ExpiresAt.Before(before)
+	}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *MemoryProcessedEventsStore) Insert(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Insert(ctx, event, event.Key)
+}
+
+func (db *MemoryProcessedEventsStore) Update(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Update(ctx, event, event.Key)
+}
+
+func (db *MemoryProcessedEventsStore) UpdateIf(ctx context.Context, event *models.ProcessedEvent, precondition func(current *models.ProcessedEvent) bool) (*models.ProcessedEvent, error) {
+	return db.querier.UpdateIf(ctx, event, event.Key, precondition)
+}
+
+func (db *MemoryProcessedEventsStore) Delete(ctx context.Context, key string) error {
+	return db.querier.Delete(ctx, key)
+}
@@ -0,0 +1,39 @@
+package memory
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+type MemorySubscriptionsStore struct {
+	querier *memoryQuerier[models.Subscription]
+}
+
+func NewSubscriptionsMemoryRepository() storage.SubscriptionsRepository {
+	return &MemorySubscriptionsStore{
+		querier: newMemoryQuerier[models.Subscription](),
+	}
+}
+
+func (db *MemorySubscriptionsStore) GetSubscriptions(ctx context.Context, userID string, exhaustiveRun bool, applyFunc func(models.Subscription), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return db.querier.SelectAll(ctx, queryParams, func(sub models.Subscription) bool {
+		return sub.UserID == userID
+	}, exhaustiveRun, applyFunc)
+}
+
+func (db *MemorySubscriptionsStore) Subscribe(ctx context.Context, sub *models.Subscription) (*models.Subscription, error) {
+	return db.querier.Insert(ctx, sub, sub.ID)
+}
+
+func (db *MemorySubscriptionsStore) Unsubscribe(ctx context.Context, subscriptionID string) error {
+	return db.querier.Delete(ctx, subscriptionID)
+}
+
+func (db *MemorySubscriptionsStore) GetSubscriptionsByEventType(ctx context.Context, eventType string, exhaustiveRun bool, applyFunc func(models.Subscription), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	return db.querier.SelectAll(ctx, queryParams, func(sub models.Subscription) bool {
+		return string(sub.EventType) == eventType
+	}, exhaustiveRun, applyFunc)
+}
@@ -0,0 +1,450 @@
+package memory
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// defaultPageSize matches the default limit of the CouchDB find queries.
+const defaultPageSize = 25
+
+var (
+	ErrElemNotFound      = fmt.Errorf("element not found")
+	ErrElemAlreadyExists = fmt.Errorf("element already exists")
+)
+
+// document keeps both the JSON encoding of an element, used to return independent copies of it, and its decoded
+// form, used to evaluate filters and sorting over the JSON field names as CouchDB selectors do.
+type document struct {
+	id   string
+	raw  []byte
+	tree map[string]any
+}
+
+type memoryQuerier[E any] struct {
+	lock sync.RWMutex
+	docs map[string]document
+}
+
+func newMemoryQuerier[E any]() *memoryQuerier[E] {
+	return &memoryQuerier[E]{
+		docs: map[string]document{},
+	}
+}
+
+func newDocument[E any](elemID string, elem E) (document, error) {
+	raw, err := json.Marshal(elem)
+	if err != nil {
+		return document{}, err
+	}
+
+	tree := map[string]any{}
+	err = json.Unmarshal(raw, &tree)
+	if err != nil {
+		return document{}, err
+	}
+
+	return document{id: elemID, raw: raw, tree: tree}, nil
+}
+
+func decodeDocument[E any](doc document) (*E, error) {
+	var elem E
+	err := json.Unmarshal(doc.raw, &elem)
+	if err != nil {
+		return nil, err
+	}
+
+	return &elem, nil
+}
+
+func (db *memoryQuerier[E]) Count(ctx context.Context, matchFunc func(E) bool) (int, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	count := 0
+	for _, doc := range db.docs {
+		if matchFunc != nil {
+			elem, err := decodeDocument[E](doc)
+			if err != nil {
+				return -1, err
+			}
+
+			if !matchFunc(*elem) {
+				continue
+			}
+		}
+
+		count++
+	}
+
+	return count, nil
+}
+
+// bookmark holds the state of a paginated query. As with the SQL engines, the page size, sorting and filters of the
+// first request are kept for the following pages.
+type bookmark struct {
+	Offset   int                      `json:"offset"`
+	PageSize int                      `json:"page_size"`
+	Sort     resources.SortOptions    `json:"sort"`
+	Filters  []resources.FilterOption `json:"filters"`
+}
+
+// SelectAll iterates the elements matching both the filters of the query parameters and matchFunc (if not nil).
+// Elements are sorted by ID unless a sort field is requested. As with CouchDB, a bookmark is returned for every
+// non-empty page, so the end is reached once a page without elements is returned.
+func (db *memoryQuerier[E]) SelectAll(ctx context.Context, queryParams *resources.QueryParameters, matchFunc func(E) bool, exhaustiveRun bool, applyFunc func(elem E)) (string, error) {
+	page := bookmark{PageSize: defaultPageSize}
+	if queryParams != nil {
+		if queryParams.NextBookmark != "" {
+			var err error
+			page, err = decodeBookmark(queryParams.NextBookmark)
+			if err != nil {
+				return "", err
+			}
+		} else {
+			if queryParams.PageSize > 0 {
+				page.PageSize = queryParams.PageSize
+			}
+			page.Sort = queryParams.Sort
+			page.Filters = queryParams.Filters
+		}
+	}
+
+	filters := page.Filters
+	sortOpts := page.Sort
+	offset := page.Offset
+	limit := page.PageSize
+
+	db.lock.RLock()
+	matches := []document{}
+	elems := map[string]*E{}
+	for _, doc := range db.docs {
+		if !matchFilters(doc.tree, filters) {
+			continue
+		}
+
+		elem, err := decodeDocument[E](doc)
+		if err != nil {
+			db.lock.RUnlock()
+			return "", err
+		}
+
+		if matchFunc != nil && !matchFunc(*elem) {
+			continue
+		}
+
+		matches = append(matches, doc)
+		elems[doc.id] = elem
+	}
+	db.lock.RUnlock()
+
+	sort.SliceStable(matches, func(i, j int) bool {
+		if sortOpts.SortField != "" {
+			cmp := compareValues(lookupField(matches[i].tree, sortOpts.SortField), lookupField(matches[j].tree, sortOpts.SortField))
+			if cmp != 0 {
+				if sortOpts.SortMode == resources.SortModeDesc {
+					return cmp > 0
+				}
+				return cmp < 0
+			}
+		}
+
+		return matches[i].id < matches[j].id
+	})
+
+	if offset > len(matches) {
+		offset = len(matches)
+	}
+	matches = matches[offset:]
+
+	if !exhaustiveRun {
+		if len(matches) == 0 {
+			return "", nil
+		}
+
+		if len(matches) > limit {
+			matches = matches[:limit]
+		}
+	}
+
+	for _, doc := range matches {
+		applyFunc(*elems[doc.id])
+	}
+
+	if exhaustiveRun {
+		return "", nil
+	}
+
+	page.Offset = offset + len(matches)
+	return encodeBookmark(page)
+}
+
+func (db *memoryQuerier[E]) SelectExists(ctx context.Context, elemID string) (bool, *E, error) {
+	db.lock.RLock()
+	defer db.lock.RUnlock()
+
+	doc, ok := db.docs[elemID]
+	if !ok {
+		return false, nil, nil
+	}
+
+	elem, err := decodeDocument[E](doc)
+	if err != nil {
+		return false, nil, err
+	}
+
+	return true, elem, nil
+}
+
+// SelectFirst returns the first element, sorted by ID, matching matchFunc.
+func (db *memoryQuerier[E]) SelectFirst(ctx context.Context, matchFunc func(E) bool) (bool, *E, error) {
+	var first *E
+	_, err := db.SelectAll(ctx, &resources.QueryParameters{PageSize: 1}, matchFunc, false, func(elem E) {
+		first = &elem
+	})
+	if err != nil {
+		return false, nil, err
+	}
+
+	return first != nil, first, nil
+}
+
+func (db *memoryQuerier[E]) Insert(ctx context.Context, elem *E, elemID string) (*E, error) {
+	doc, err := newDocument(elemID, *elem)
+	if err != nil {
+		return nil, err
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if _, ok := db.docs[elemID]; ok {
+		return nil, fmt.Errorf("%w: %s", ErrElemAlreadyExists, elemID)
+	}
+
+	db.docs[elemID] = doc
+	return decodeDocument[E](doc)
+}
+
+func (db *memoryQuerier[E]) Update(ctx context.Context, elem *E, elemID string) (*E, error) {
+	doc, err := newDocument(elemID, *elem)
+	if err != nil {
+		return nil, err
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if _, ok := db.docs[elemID]; !ok {
+		return nil, fmt.Errorf("%w: %s", ErrElemNotFound, elemID)
+	}
+
+	db.docs[elemID] = doc
+	return decodeDocument[E](doc)
+}
+
+// UpdateIf updates the element only if precondition holds for the stored one, evaluated while holding the write lock.
+func (db *memoryQuerier[E]) UpdateIf(ctx context.Context, elem *E, elemID string, precondition func(current *E) bool) (*E, error) {
+	doc, err := newDocument(elemID, *elem)
+	if err != nil {
+		return nil, err
+	}
+
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	prevDoc, ok := db.docs[elemID]
+	if !ok {
+		return nil, fmt.Errorf("%w: %s", ErrElemNotFound, elemID)
+	}
+
+	current, err := decodeDocument[E](prevDoc)
+	if err != nil {
+		return nil, err
+	}
+
+	if !precondition(current) {
+		return nil, storage.ErrUpdateConflict
+	}
+
+	db.docs[elemID] = doc
+	return decodeDocument[E](doc)
+}
+
+func (db *memoryQuerier[E]) Delete(ctx context.Context, elemID string) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+
+	if _, ok := db.docs[elemID]; !ok {
+		return fmt.Errorf("%w: %s", ErrElemNotFound, elemID)
+	}
+
+	delete(db.docs, elemID)
+	return nil
+}
+
+func encodeBookmark(page bookmark) (string, error) {
+	encodedBookmark, err := json.Marshal(page)
+	if err != nil {
+		return "", err
+	}
+
+	return base64.StdEncoding.EncodeToString(encodedBookmark), nil
+}
+
+func decodeBookmark(encodedBookmark string) (bookmark, error) {
+	page := bookmark{}
+	decodedBookmark, err := base64.StdEncoding.DecodeString(encodedBookmark)
+	if err != nil {
+		return page, fmt.Errorf("not a valid bookmark")
+	}
+
+	err = json.Unmarshal(decodedBookmark, &page)
+	if err != nil || page.Offset < 0 || page.PageSize <= 0 {
+		return page, fmt.Errorf("not a valid bookmark")
+	}
+
+	return page, nil
+}
+
+// lookupField resolves a dot separated field path (i.e. "subject.common_name") within the JSON document.
+func lookupField(tree map[string]any, field string) any {
+	var value any = tree
+	for _, key := range strings.Split(field, ".") {
+		obj, ok := value.(map[string]any)
+		if !ok {
+			return nil
+		}
+
+		value, ok = obj[key]
+		if !ok {
+			return nil
+		}
+	}
+
+	return value
+}
+
+// matchFilters evaluates the filters as the CouchDB selectors built by FilterOperandToCouchDBSelector: all the
+// filters must match and documents without the filtered field never match.
+func matchFilters(tree map[string]any, filters []resources.FilterOption) bool {
+	for _, filter := range filters {
+		value := lookupField(tree, filter.Field)
+		if value == nil {
+			return false
+		}
+
+		if !matchFilter(value, filter) {
+			return false
+		}
+	}
+
+	return true
+}
+
+func matchFilter(value any, filter resources.FilterOption) bool {
+	switch filter.FilterOperation {
+	case resources.StringEqual, resources.DateEqual, resources.NumberEqual, resources.EnumEqual:
+		return compareValues(value, filter.Value) == 0
+	case resources.StringNotEqual, resources.NumberNotEqual, resources.EnumNotEqual:
+		return compareValues(value, filter.Value) != 0
+	case resources.StringContains:
+		return matchRegex(value, filter.Value)
+	case resources.StringNotContains:
+		return !matchRegex(value, filter.Value)
+	case resources.StringArrayContains:
+		items, ok := value.([]any)
+		if !ok {
+			return false
+		}
+		for _, item := range items {
+			if compareValues(item, filter.Value) == 0 {
+				return true
+			}
+		}
+		return false
+	case resources.DateBefore, resources.NumberLessThan:
+		return compareValues(value, filter.Value) < 0
+	case resources.DateAfter, resources.NumberGreaterThan:
+		return compareValues(value, filter.Value) > 0
+	case resources.NumberLessOrEqualThan:
+		return compareValues(value, filter.Value) <= 0
+	case resources.NumberGreaterOrEqualThan:
+		return compareValues(value, filter.Value) >= 0
+	default:
+		return true
+	}
+}
+
+func matchRegex(value any, filterValue string) bool {
+	str, ok := value.(string)
+	if !ok {
+		return false
+	}
+
+	matched, err := regexp.MatchString(fmt.Sprintf(".*%s.*", filterValue), str)
+	return err == nil && matched
+}
+
+// compareValues compares a document value with another document value or a filter value. Filter values are always
+// strings, so they are compared numerically only if the document value is a number. RFC3339 timestamps are compared
+// chronologically.
+func compareValues(a, b any) int {
+	if a == nil || b == nil {
+		switch {
+		case a == nil && b == nil:
+			return 0
+		case a == nil:
+			return -1
+		default:
+			return 1
+		}
+	}
+
+	if aNumber, ok := a.(float64); ok {
+		if bNumber, ok := toNumber(b); ok {
+			switch {
+			case aNumber < bNumber:
+				return -1
+			case aNumber > bNumber:
+				return 1
+			default:
+				return 0
+			}
+		}
+	}
+
+	aStr := fmt.Sprint(a)
+	bStr := fmt.Sprint(b)
+
+	aTime, aErr := time.Parse(time.RFC3339Nano, aStr)
+	bTime, bErr := time.Parse(time.RFC3339Nano, bStr)
+	if aErr == nil && bErr == nil {
+		return aTime.Compare(bTime)
+	}
+
+	return strings.Compare(aStr, bStr)
+}
+
+func toNumber(value any) (float64, bool) {
+	switch v := value.(type) {
+	case float64:
+		return v, true
+	case string:
+		n, err := strconv.ParseFloat(v, 64)
+		return n, err == nil
+	default:
+		return 0, false
+	}
+}
@@ -0,0 +1,239 @@
+package memory
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+func prepareDevices(t *testing.T) *memoryQuerier[models.Device] {
+	querier := newMemoryQuerier[models.Device]()
+	now := time.Now()
+	devices := []models.Device{
+		{ID: "dev-1", Status: models.DeviceActive, Tags: []string{"edge"}, DMSOwner: "dms-a", CreationTimestamp: now.Add(-3 * time.Hour), ConnectionMetadata: models.DeviceConnectionMetadata{Status: models.DeviceConnectionOnline}},
+		{ID: "dev-2", Status: models.DeviceNoIdentity, Tags: []string{"edge", "gateway"}, DMSOwner: "dms-a", CreationTimestamp: now.Add(-2 * time.Hour)},
+		{ID: "dev-3", Status: models.DeviceActive, Tags: []string{"gateway"}, DMSOwner: "dms-b", CreationTimestamp: now.Add(-1 * time.Hour), ConnectionMetadata: models.DeviceConnectionMetadata{Status: models.DeviceConnectionOffline}},
+	}
+
+	for _, dev := range devices {
+		_, err := querier.Insert(context.Background(), &dev, dev.ID)
+		if err != nil {
+			t.Fatalf("could not insert device %s: %s", dev.ID, err)
+		}
+	}
+
+	return querier
+}
+
+func selectIDs(t *testing.T, querier *memoryQuerier[models.Device], queryParams *resources.QueryParameters, matchFunc func(models.Device) bool) ([]string, string) {
+	ids := []string{}
+	bookmark, err := querier.SelectAll(context.Background(), queryParams, matchFunc, false, func(dev models.Device) {
+		ids = append(ids, dev.ID)
+	})
+	if err != nil {
+		t.Fatalf("could not select devices: %s", err)
+	}
+
+	return ids, bookmark
+}
+
+func TestMemoryQuerierFilters(t *testing.T) {
+	querier := prepareDevices(t)
+
+	testcases := []struct {
+		name     string
+		filters  []resources.FilterOption
+		expected []string
+	}{
+		{
+			name:     "EnumEqual",
+			filters:  []resources.FilterOption{{Field: "status", FilterOperation: resources.EnumEqual, Value: string(models.DeviceActive)}},
+			expected: []string{"dev-1", "dev-3"},
+		},
+		{
+			name:     "NestedField",
+			filters:  []resources.FilterOption{{Field: "connection_metadata.status", FilterOperation: resources.EnumEqual, Value: string(models.DeviceConnectionOnline)}},
+			expected: []string{"dev-1"},
+		},
+		{
+			name:     "StringContains",
+			filters:  []resources.FilterOption{{Field: "dms_owner", FilterOperation: resources.StringContains, Value: "-b"}},
+			expected: []string{"dev-3"},
+		},
+		{
+			name:     "StringArrayContains",
+			filters:  []resources.FilterOption{{Field: "tags", FilterOperation: resources.StringArrayContains, Value: "gateway"}},
+			expected: []string{"dev-2", "dev-3"},
+		},
+		{
+			name:     "DateAfter",
+			filters:  []resources.FilterOption{{Field: "creation_timestamp", FilterOperation: resources.DateAfter, Value: time.Now().Add(-150 * time.Minute).Format(time.RFC3339)}},
+			expected: []string{"dev-2", "dev-3"},
+		},
+		{
+			name: "MultipleFilters",
+			filters: []resources.FilterOption{
+				{Field: "status", FilterOperation: resources.EnumEqual, Value: string(models.DeviceActive)},
+				{Field: "dms_owner", FilterOperation: resources.StringNotEqual, Value: "dms-a"},
+			},
+			expected: []string{"dev-3"},
+		},
+		{
+			name:     "UnknownField",
+			filters:  []resources.FilterOption{{Field: "unknown", FilterOperation: resources.StringNotEqual, Value: "value"}},
+			expected: []string{},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			ids, _ := selectIDs(t, querier, &resources.QueryParameters{Filters: tc.filters}, nil)
+			if !slices.Equal(ids, tc.expected) {
+				t.Errorf("unexpected devices: expected %v, got %v", tc.expected, ids)
+			}
+		})
+	}
+
+	t.Run("MatchFunc", func(t *testing.T) {
+		ids, _ := selectIDs(t, querier, nil, func(dev models.Device) bool { return dev.DMSOwner == "dms-a" })
+		if !slices.Equal(ids, []string{"dev-1", "dev-2"}) {
+			t.Errorf("unexpected devices: %v", ids)
+		}
+	})
+}
+
+func TestMemoryQuerierPagination(t *testing.T) {
+	querier := prepareDevices(t)
+
+	// The sorting and the page size of the first request are kept by the bookmark.
+	ids, bookmark := selectIDs(t, querier, &resources.QueryParameters{
+		PageSize: 2,
+		Sort:     resources.SortOptions{SortField: "creation_timestamp", SortMode: resources.SortModeDesc},
+	}, nil)
+	if !slices.Equal(ids, []string{"dev-3", "dev-2"}) {
+		t.Fatalf("unexpected first page: %v", ids)
+	}
+	if bookmark == "" {
+		t.Fatalf("expected a bookmark for the next page")
+	}
+
+	ids, bookmark = selectIDs(t, querier, &resources.QueryParameters{NextBookmark: bookmark}, nil)
+	if !slices.Equal(ids, []string{"dev-1"}) {
+		t.Fatalf("unexpected second page: %v", ids)
+	}
+
+	ids, bookmark = selectIDs(t, querier, &resources.QueryParameters{NextBookmark: bookmark}, nil)
+	if len(ids) != 0 || bookmark != "" {
+		t.Fatalf("expected an empty last page without bookmark, got %v and bookmark %q", ids, bookmark)
+	}
+
+	_, err := querier.SelectAll(context.Background(), &resources.QueryParameters{NextBookmark: "invalid"}, nil, false, func(models.Device) {})
+	if err == nil {
+		t.Errorf("expected error with an invalid bookmark")
+	}
+
+	all := 0
+	bookmark, err = querier.SelectAll(context.Background(), &resources.QueryParameters{PageSize: 1}, nil, true, func(models.Device) { all++ })
+	if err != nil {
+		t.Fatalf("could not select devices: %s", err)
+	}
+	if all != 3 || bookmark != "" {
+		t.Errorf("exhaustive run should iterate all devices without bookmark, got %d and bookmark %q", all, bookmark)
+	}
+}
+
+func TestMemoryQuerierWrites(t *testing.T) {
+	ctx := context.Background()
+	querier := prepareDevices(t)
+
+	_, err := querier.Insert(ctx, &models.Device{ID: "dev-1"}, "dev-1")
+	if !errors.Is(err, ErrElemAlreadyExists) {
+		t.Errorf("expected error %s, got %v", ErrElemAlreadyExists, err)
+	}
+
+	_, err = querier.Update(ctx, &models.Device{ID: "unknown"}, "unknown")
+	if !errors.Is(err, ErrElemNotFound) {
+		t.Errorf("expected error %s, got %v", ErrElemNotFound, err)
+	}
+
+	// Stored elements are copies: modifying a returned element does not modify the stored one.
+	_, dev, err := querier.SelectExists(ctx, "dev-1")
+	if err != nil {
+		t.Fatalf("could not get device: %s", err)
+	}
+	dev.Tags[0] = "modified"
+
+	_, dev, _ = querier.SelectExists(ctx, "dev-1")
+	if dev.Tags[0] != "edge" {
+		t.Errorf("stored device was modified")
+	}
+
+	dev.Status = models.DeviceDecommissioned
+	_, err = querier.Update(ctx, dev, dev.ID)
+	if err != nil {
+		t.Fatalf("could not update device: %s", err)
+	}
+
+	count, _ := querier.Count(ctx, func(dev models.Device) bool { return dev.Status == models.DeviceDecommissioned })
+	if count != 1 {
+		t.Errorf("expected 1 decommissioned device, got %d", count)
+	}
+
+	err = querier.Delete(ctx, "dev-1")
+	if err != nil {
+		t.Fatalf("could not delete device: %s", err)
+	}
+
+	exists, _, _ := querier.SelectExists(ctx, "dev-1")
+	if exists {
+		t.Errorf("device should have been deleted")
+	}
+
+	err = querier.Delete(ctx, "dev-1")
+	if !errors.Is(err, ErrElemNotFound) {
+		t.Errorf("expected error %s, got %v", ErrElemNotFound, err)
+	}
+}
+
+func TestMemoryQuerierUpdateIf(t *testing.T) {
+	querier := prepareDevices(t)
+	ctx := context.Background()
+
+	_, read, err := querier.SelectExists(ctx, "dev-2")
+	if err != nil {
+		t.Fatalf("could not select device: %s", err)
+	}
+
+	concurrent := *read
+	concurrent.Status = models.DeviceActive
+	_, err = querier.Update(ctx, &concurrent, concurrent.ID)
+	if err != nil {
+		t.Fatalf("could not update device: %s", err)
+	}
+
+	read.Status = models.DeviceDecommissioned
+	_, err = querier.UpdateIf(ctx, read, read.ID, func(current *models.Device) bool {
+		return current.Status == models.DeviceNoIdentity
+	})
+	if !errors.Is(err, storage.ErrUpdateConflict) {
+		t.Fatalf("expected error %s, got %v", storage.ErrUpdateConflict, err)
+	}
+
+	_, stored, _ := querier.SelectExists(ctx, "dev-2")
+	if stored.Status != models.DeviceActive {
+		t.Errorf("concurrent update was overwritten. Got status %s", stored.Status)
+	}
+
+	_, err = querier.UpdateIf(ctx, read, read.ID, func(current *models.Device) bool {
+		return current.Status == models.DeviceActive
+	})
+	if err != nil {
+		t.Fatalf("could not update device: %s", err)
+	}
+}
@@ -0,0 +1,109 @@
+package migration
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"sync"
+)
+
+type AssetType string
+
+const (
+	AssetCA          AssetType = "ca"
+	AssetDMS         AssetType = "dms"
+	AssetDevice      AssetType = "device"
+	AssetCertificate AssetType = "certificate"
+)
+
+// Checkpoint keeps track of the migrated records, so an interrupted migration can be resumed
+// without writing the same records again.
+type Checkpoint struct {
+	path string
+	mu   sync.Mutex
+
+	Migrated map[AssetType]map[string]bool `json:"migrated"`
+}
+
+// LoadCheckpoint reads the checkpoint stored in path. A new checkpoint is returned if the file does not exist.
+func LoadCheckpoint(path string) (*Checkpoint, error) {
+	checkpoint := &Checkpoint{
+		path:     path,
+		Migrated: map[AssetType]map[string]bool{},
+	}
+
+	if path == "" {
+		return checkpoint, nil
+	}
+
+	content, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, fs.ErrNotExist) {
+			return checkpoint, nil
+		}
+		return nil, err
+	}
+
+	err = json.Unmarshal(content, checkpoint)
+	if err != nil {
+		return nil, err
+	}
+
+	if checkpoint.Migrated == nil {
+		checkpoint.Migrated = map[AssetType]map[string]bool{}
+	}
+
+	return checkpoint, nil
+}
+
+func (c *Checkpoint) IsMigrated(asset AssetType, id string) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	return c.Migrated[asset][id]
+}
+
+// MarkMigrated records the migrated record and persists the checkpoint.
+func (c *Checkpoint) MarkMigrated(asset AssetType, id string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if _, ok := c.Migrated[asset]; !ok {
+		c.Migrated[asset] = map[string]bool{}
+	}
+	c.Migrated[asset][id] = true
+
+	return c.save()
+}
+
+// Forget removes the records from the checkpoint, so they are copied again by the next run.
+func (c *Checkpoint) Forget(asset AssetType, ids []string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for _, id := range ids {
+		delete(c.Migrated[asset], id)
+	}
+
+	return c.save()
+}
+
+func (c *Checkpoint) save() error {
+	if c.path == "" {
+		return nil
+	}
+
+	content, err := json.Marshal(c)
+	if err != nil {
+		return err
+	}
+
+	tmpPath := c.path + ".tmp"
+	err = os.WriteFile(tmpPath, content, 0600)
+	if err != nil {
+		return err
+	}
+
+	return os.Rename(tmpPath, c.path)
+}
@@ -0,0 +1,304 @@
+package migration
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"github.com/sirupsen/logrus"
+)
+
+type AssetSummary struct {
+	Migrated int `json:"migrated"`
+	Skipped  int `json:"skipped"`
+	Failed   int `json:"failed"`
+}
+
+type Summary map[AssetType]*AssetSummary
+
+// Migrator copies the CAs, DMSs, devices (including their identity and extra slots) and the certificate history
+// from the source storage engine into the target one, filling the fields missing in records written by older versions.
+// It also backfills the target engine of a dual_write storage engine while the services keep writing into both.
+type Migrator struct {
+	source     storage.StorageEngine
+	target     storage.StorageEngine
+	checkpoint *Checkpoint
+	dryRun     bool
+	logger     *logrus.Entry
+}
+
+func NewMigrator(source, target storage.StorageEngine, checkpoint *Checkpoint, dryRun bool, logger *logrus.Entry) *Migrator {
+	return &Migrator{
+		source:     source,
+		target:     target,
+		checkpoint: checkpoint,
+		dryRun:     dryRun,
+		logger:     logger,
+	}
+}
+
+func (m *Migrator) Run(ctx context.Context) (Summary, error) {
+	summary := Summary{
+		AssetCA:          &AssetSummary{},
+		AssetDMS:         &AssetSummary{},
+		AssetDevice:      &AssetSummary{},
+		AssetCertificate: &AssetSummary{},
+	}
+
+	err := m.migrateCAs(ctx, summary[AssetCA])
+	if err != nil {
+		return summary, fmt.Errorf("could not migrate CAs: %w", err)
+	}
+
+	err = m.migrateDMSs(ctx, summary[AssetDMS])
+	if err != nil {
+		return summary, fmt.Errorf("could not migrate DMSs: %w", err)
+	}
+
+	err = m.migrateDevices(ctx, summary[AssetDevice])
+	if err != nil {
+		return summary, fmt.Errorf("could not migrate devices: %w", err)
+	}
+
+	err = m.migrateCertificates(ctx, summary[AssetCertificate])
+	if err != nil {
+		return summary, fmt.Errorf("could not migrate certificates: %w", err)
+	}
+
+	return summary, nil
+}
+
+// migrateRecord writes the record (unless running in dry-run mode) and records it in the checkpoint.
+func (m *Migrator) migrateRecord(asset AssetType, id string, summary *AssetSummary, write func() error) {
+	lFunc := m.logger.WithField("asset", asset)
+
+	if m.checkpoint.IsMigrated(asset, id) {
+		lFunc.Debugf("skipping %s. Already migrated", id)
+		summary.Skipped++
+		return
+	}
+
+	if m.dryRun {
+		lFunc.Infof("[dry-run] %s would be migrated", id)
+		summary.Migrated++
+		return
+	}
+
+	err := write()
+	if err != nil {
+		lFunc.Errorf("could not migrate %s: %s", id, err)
+		summary.Failed++
+		return
+	}
+
+	err = m.checkpoint.MarkMigrated(asset, id)
+	if err != nil {
+		lFunc.Warnf("%s migrated but could not be saved in the checkpoint: %s", id, err)
+	}
+
+	lFunc.Debugf("%s migrated", id)
+	summary.Migrated++
+}
+
+func (m *Migrator) migrateCAs(ctx context.Context, summary *AssetSummary) error {
+	sourceRepo, err := m.source.GetCAStorage()
+	if err != nil {
+		return err
+	}
+
+	targetRepo, err := m.target.GetCAStorage()
+	if err != nil {
+		return err
+	}
+
+	_, err = sourceRepo.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			NormalizeCA(&ca)
+			m.migrateRecord(AssetCA, ca.ID, summary, func() error {
+				exists, _, err := targetRepo.SelectExistsByID(ctx, ca.ID)
+				if err != nil {
+					return err
+				}
+
+				if exists {
+					_, err = targetRepo.Update(ctx, &ca)
+				} else {
+					_, err = targetRepo.Insert(ctx, &ca)
+				}
+				return err
+			})
+		},
+		QueryParams: nil,
+		ExtraOpts:   map[string]interface{}{},
+	})
+
+	return err
+}
+
+func (m *Migrator) migrateDMSs(ctx context.Context, summary *AssetSummary) error {
+	sourceRepo, err := m.source.GetDMSStorage()
+	if err != nil {
+		return err
+	}
+
+	targetRepo, err := m.target.GetDMSStorage()
+	if err != nil {
+		return err
+	}
+
+	_, err = sourceRepo.SelectAll(ctx, true, func(dms models.DMS) {
+		NormalizeDMS(&dms)
+		m.migrateRecord(AssetDMS, dms.ID, summary, func() error {
+			exists, _, err := targetRepo.SelectExists(ctx, dms.ID)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				_, err = targetRepo.Update(ctx, &dms)
+			} else {
+				_, err = targetRepo.Insert(ctx, &dms)
+			}
+			return err
+		})
+	}, nil, map[string]interface{}{})
+
+	return err
+}
+
+func (m *Migrator) migrateDevices(ctx context.Context, summary *AssetSummary) error {
+	sourceRepo, err := m.source.GetDeviceStorage()
+	if err != nil {
+		return err
+	}
+
+	targetRepo, err := m.target.GetDeviceStorage()
+	if err != nil {
+		return err
+	}
+
+	_, err = sourceRepo.SelectAll(ctx, true, func(device models.Device) {
+		NormalizeDevice(&device)
+		m.migrateRecord(AssetDevice, device.ID, summary, func() error {
+			exists, _, err := targetRepo.SelectExists(ctx, device.ID)
+			if err != nil {
+				return err
+			}
+
+			if exists {
+				_, err = targetRepo.Update(ctx, &device)
+			} else {
+				_, err = targetRepo.Insert(ctx, &device)
+			}
+			return err
+		})
+	}, nil, map[string]interface{}{})
+
+	return err
+}
+
+func (m *Migrator) migrateCertificates(ctx context.Context, summary *AssetSummary) error {
+	sourceRepo, err := m.source.GetCertstorage()
+	if err != nil {
+		return err
+	}
+
+	targetRepo, err := m.target.GetCertstorage()
+	if err != nil {
+		return err
+	}
+
+	_, err = sourceRepo.SelectAll(ctx, storage.StorageListRequest[models.Certificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(cert models.Certificate) {
+			NormalizeCertificate(&cert)
+			m.migrateRecord(AssetCertificate, cert.SerialNumber, summary, func() error {
+				exists, _, err := targetRepo.SelectExistsBySerialNumber(ctx, cert.SerialNumber)
+				if err != nil {
+					return err
+				}
+
+				if exists {
+					_, err = targetRepo.Update(ctx, &cert)
+				} else {
+					_, err = targetRepo.Insert(ctx, &cert)
+				}
+				return err
+			})
+		},
+		QueryParams: nil,
+		ExtraOpts:   map[string]interface{}{},
+	})
+
+	return err
+}
+
+// NormalizeCA fills the fields that CAs stored by older versions lack.
+func NormalizeCA(ca *models.CACertificate) {
+	if ca.Metadata == nil {
+		ca.Metadata = map[string]any{}
+	}
+}
+
+// NormalizeDMS fills the fields that DMSs stored by older versions lack.
+func NormalizeDMS(dms *models.DMS) {
+	if dms.Metadata == nil {
+		dms.Metadata = map[string]any{}
+	}
+
+	profile := &dms.Settings.EnrollmentSettings.DeviceProvisionProfile
+	if profile.Metadata == nil {
+		profile.Metadata = map[string]any{}
+	}
+	if profile.Tags == nil {
+		profile.Tags = []string{}
+	}
+}
+
+// NormalizeDevice fills the fields that devices stored by older versions lack.
+func NormalizeDevice(device *models.Device) {
+	if device.Tags == nil {
+		device.Tags = []string{}
+	}
+	if device.Metadata == nil {
+		device.Metadata = map[string]any{}
+	}
+	if device.ExtraSlots == nil {
+		device.ExtraSlots = map[string]*models.Slot[any]{}
+	}
+	if device.Events == nil {
+		device.Events = map[time.Time]models.DeviceEvent{}
+	}
+
+	if device.IdentitySlot != nil {
+		if device.IdentitySlot.Secrets == nil {
+			device.IdentitySlot.Secrets = map[int]string{}
+		}
+		if device.IdentitySlot.Events == nil {
+			device.IdentitySlot.Events = map[time.Time]models.DeviceEvent{}
+		}
+	}
+
+	if device.Status == "" {
+		if device.IdentitySlot == nil {
+			device.Status = models.DeviceNoIdentity
+		} else {
+			device.Status = models.DeviceActive
+		}
+	}
+}
+
+// NormalizeCertificate fills the fields that certificates stored by older versions lack.
+func NormalizeCertificate(cert *models.Certificate) {
+	if cert.Metadata == nil {
+		cert.Metadata = map[string]any{}
+	}
+
+	if cert.SerialNumber == "" && cert.Certificate != nil {
+		cert.SerialNumber = helpers.SerialNumberToString(cert.Certificate.SerialNumber)
+	}
+}
@@ -0,0 +1,73 @@
+package migration
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+func TestCheckpointResume(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "checkpoint.json")
+
+	checkpoint, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading empty checkpoint: %s", err)
+	}
+
+	if checkpoint.IsMigrated(AssetDevice, "device-1") {
+		t.Fatalf("empty checkpoint should not contain records")
+	}
+
+	err = checkpoint.MarkMigrated(AssetDevice, "device-1")
+	if err != nil {
+		t.Fatalf("unexpected error saving checkpoint: %s", err)
+	}
+
+	resumed, err := LoadCheckpoint(path)
+	if err != nil {
+		t.Fatalf("unexpected error loading checkpoint: %s", err)
+	}
+
+	if !resumed.IsMigrated(AssetDevice, "device-1") {
+		t.Errorf("resumed checkpoint should contain device-1")
+	}
+
+	if resumed.IsMigrated(AssetDMS, "device-1") {
+		t.Errorf("records must be tracked per asset type")
+	}
+}
+
+func TestNormalizeDevice(t *testing.T) {
+	device := models.Device{ID: "device-1"}
+	NormalizeDevice(&device)
+
+	if device.Status != models.DeviceNoIdentity {
+		t.Errorf("expected status %s, got %s", models.DeviceNoIdentity, device.Status)
+	}
+
+	if device.Tags == nil || device.Metadata == nil || device.ExtraSlots == nil || device.Events == nil {
+		t.Errorf("expected empty collections to be initialized")
+	}
+
+	withIdentity := models.Device{ID: "device-2", IdentitySlot: &models.Slot[string]{}}
+	NormalizeDevice(&withIdentity)
+
+	if withIdentity.Status != models.DeviceActive {
+		t.Errorf("expected status %s, got %s", models.DeviceActive, withIdentity.Status)
+	}
+
+	if withIdentity.IdentitySlot.Secrets == nil || withIdentity.IdentitySlot.Events == nil {
+		t.Errorf("expected identity slot collections to be initialized")
+	}
+}
+
+func TestNormalizeDMS(t *testing.T) {
+	dms := models.DMS{ID: "dms-1"}
+	NormalizeDMS(&dms)
+
+	profile := dms.Settings.EnrollmentSettings.DeviceProvisionProfile
+	if dms.Metadata == nil || profile.Metadata == nil || profile.Tags == nil {
+		t.Errorf("expected empty collections to be initialized")
+	}
+}
@@ -0,0 +1,234 @@
+package migration
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+)
+
+// AssetVerification compares the records of an asset type stored in the source and target engines. Each record
+// is hashed after being normalized, and the hash of the asset is computed over the hashes of its records sorted by ID.
+type AssetVerification struct {
+	SourceCount int    `json:"source_count"`
+	TargetCount int    `json:"target_count"`
+	SourceHash  string `json:"source_hash"`
+	TargetHash  string `json:"target_hash"`
+	// Missing lists the records stored in the source engine but not in the target engine.
+	Missing []string `json:"missing,omitempty"`
+	// Unexpected lists the records stored in the target engine but not in the source engine.
+	Unexpected []string `json:"unexpected,omitempty"`
+	// Mismatched lists the records stored in both engines with different contents.
+	Mismatched []string `json:"mismatched,omitempty"`
+}
+
+func (v *AssetVerification) Consistent() bool {
+	return v.SourceHash == v.TargetHash
+}
+
+type VerificationReport struct {
+	GeneratedAt time.Time                        `json:"generated_at"`
+	Consistent  bool                             `json:"consistent"`
+	Assets      map[AssetType]*AssetVerification `json:"assets"`
+}
+
+// Verify compares the CAs, DMSs, devices and certificates stored in the source and target engines. The backend
+// can be switched once the report is consistent.
+func Verify(ctx context.Context, source, target storage.StorageEngine) (*VerificationReport, error) {
+	report := &VerificationReport{
+		GeneratedAt: time.Now(),
+		Consistent:  true,
+		Assets:      map[AssetType]*AssetVerification{},
+	}
+
+	collectors := map[AssetType]func(context.Context, storage.StorageEngine) (map[string]string, error){
+		AssetCA:          collectCAs,
+		AssetDMS:         collectDMSs,
+		AssetDevice:      collectDevices,
+		AssetCertificate: collectCertificates,
+	}
+
+	for asset, collect := range collectors {
+		sourceRecords, err := collect(ctx, source)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s records from source storage engine: %w", asset, err)
+		}
+
+		targetRecords, err := collect(ctx, target)
+		if err != nil {
+			return nil, fmt.Errorf("could not read %s records from target storage engine: %w", asset, err)
+		}
+
+		verification := compareRecords(sourceRecords, targetRecords)
+		report.Assets[asset] = verification
+		if !verification.Consistent() {
+			report.Consistent = false
+		}
+	}
+
+	return report, nil
+}
+
+func compareRecords(source, target map[string]string) *AssetVerification {
+	verification := &AssetVerification{
+		SourceCount: len(source),
+		TargetCount: len(target),
+		SourceHash:  assetHash(source),
+		TargetHash:  assetHash(target),
+		Missing:     []string{},
+		Unexpected:  []string{},
+		Mismatched:  []string{},
+	}
+
+	for id, sourceHash := range source {
+		targetHash, ok := target[id]
+		if !ok {
+			verification.Missing = append(verification.Missing, id)
+		} else if targetHash != sourceHash {
+			verification.Mismatched = append(verification.Mismatched, id)
+		}
+	}
+
+	for id := range target {
+		if _, ok := source[id]; !ok {
+			verification.Unexpected = append(verification.Unexpected, id)
+		}
+	}
+
+	sort.Strings(verification.Missing)
+	sort.Strings(verification.Unexpected)
+	sort.Strings(verification.Mismatched)
+
+	return verification
+}
+
+func assetHash(records map[string]string) string {
+	ids := make([]string, 0, len(records))
+	for id := range records {
+		ids = append(ids, id)
+	}
+	sort.Strings(ids)
+
+	hash := sha256.New()
+	for _, id := range ids {
+		fmt.Fprintf(hash, "%s:%s\n", id, records[id])
+	}
+
+	return hex.EncodeToString(hash.Sum(nil))
+}
+
+func recordHash(record any) (string, error) {
+	content, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+
+	hash := sha256.Sum256(content)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+// addRecord hashes the record into records. Hashing errors are returned through hashErr, as the storage engines
+// iterate the records with callbacks.
+func addRecord(records map[string]string, id string, record any, hashErr *error) {
+	if *hashErr != nil {
+		return
+	}
+
+	hash, err := recordHash(record)
+	if err != nil {
+		*hashErr = fmt.Errorf("could not hash %s: %w", id, err)
+		return
+	}
+
+	records[id] = hash
+}
+
+func collectCAs(ctx context.Context, engine storage.StorageEngine) (map[string]string, error) {
+	repo, err := engine.GetCAStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[string]string{}
+	var hashErr error
+	_, err = repo.SelectAll(ctx, storage.StorageListRequest[models.CACertificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(ca models.CACertificate) {
+			NormalizeCA(&ca)
+			addRecord(records, ca.ID, ca, &hashErr)
+		},
+		ExtraOpts: map[string]interface{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, hashErr
+}
+
+func collectDMSs(ctx context.Context, engine storage.StorageEngine) (map[string]string, error) {
+	repo, err := engine.GetDMSStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[string]string{}
+	var hashErr error
+	_, err = repo.SelectAll(ctx, true, func(dms models.DMS) {
+		NormalizeDMS(&dms)
+		addRecord(records, dms.ID, dms, &hashErr)
+	}, nil, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, hashErr
+}
+
+func collectDevices(ctx context.Context, engine storage.StorageEngine) (map[string]string, error) {
+	repo, err := engine.GetDeviceStorage()
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[string]string{}
+	var hashErr error
+	_, err = repo.SelectAll(ctx, true, func(device models.Device) {
+		NormalizeDevice(&device)
+		addRecord(records, device.ID, device, &hashErr)
+	}, nil, map[string]interface{}{})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, hashErr
+}
+
+func collectCertificates(ctx context.Context, engine storage.StorageEngine) (map[string]string, error) {
+	repo, err := engine.GetCertstorage()
+	if err != nil {
+		return nil, err
+	}
+
+	records := map[string]string{}
+	var hashErr error
+	_, err = repo.SelectAll(ctx, storage.StorageListRequest[models.Certificate]{
+		ExhaustiveRun: true,
+		ApplyFunc: func(cert models.Certificate) {
+			NormalizeCertificate(&cert)
+			addRecord(records, cert.SerialNumber, cert, &hashErr)
+		},
+		ExtraOpts: map[string]interface{}{},
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return records, hashErr
+}
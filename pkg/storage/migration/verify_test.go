@@ -0,0 +1,90 @@
+package migration
+
+import (
+	"context"
+	"crypto/x509"
+	"testing"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage/memory"
+	"github.com/sirupsen/logrus"
+)
+
+func TestVerifyAndResync(t *testing.T) {
+	ctx := context.Background()
+	logger := logrus.WithField("test", t.Name())
+
+	source, _ := memory.NewStorageEngine(logger)
+	target, _ := memory.NewStorageEngine(logger)
+
+	sourceDevices, _ := source.GetDeviceStorage()
+	targetDevices, _ := target.GetDeviceStorage()
+	sourceCAs, _ := source.GetCAStorage()
+
+	for _, id := range []string{"device-1", "device-2"} {
+		device := models.Device{ID: id}
+		NormalizeDevice(&device)
+		_, err := sourceDevices.Insert(ctx, &device)
+		if err != nil {
+			t.Fatalf("could not insert device: %s", err)
+		}
+	}
+
+	_, err := sourceCAs.Insert(ctx, &models.CACertificate{ID: "ca-1", Certificate: models.Certificate{KeyMetadata: models.KeyStrengthMetadata{Type: models.KeyType(x509.ECDSA)}}})
+	if err != nil {
+		t.Fatalf("could not insert CA: %s", err)
+	}
+
+	report, err := Verify(ctx, source, target)
+	if err != nil {
+		t.Fatalf("could not verify storage engines: %s", err)
+	}
+	if report.Consistent {
+		t.Fatalf("empty target engine should not be consistent")
+	}
+	if devices := report.Assets[AssetDevice]; devices.SourceCount != 2 || devices.TargetCount != 0 || len(devices.Missing) != 2 {
+		t.Fatalf("unexpected device verification: %+v", devices)
+	}
+
+	checkpoint, _ := LoadCheckpoint("")
+	migrator := NewMigrator(source, target, checkpoint, false, logger)
+	_, err = migrator.Run(ctx)
+	if err != nil {
+		t.Fatalf("could not backfill target engine: %s", err)
+	}
+
+	report, err = Verify(ctx, source, target)
+	if err != nil {
+		t.Fatalf("could not verify storage engines: %s", err)
+	}
+	if !report.Consistent {
+		t.Fatalf("target engine should be consistent after the backfill: %+v", report.Assets)
+	}
+
+	// A stale copy in the target engine is reported as mismatched and copied again once forgotten.
+	_, err = targetDevices.Update(ctx, &models.Device{ID: "device-1", Status: models.DeviceRevoked})
+	if err != nil {
+		t.Fatalf("could not update device: %s", err)
+	}
+
+	report, _ = Verify(ctx, source, target)
+	devices := report.Assets[AssetDevice]
+	if report.Consistent || len(devices.Mismatched) != 1 || devices.Mismatched[0] != "device-1" {
+		t.Fatalf("expected device-1 to be mismatched, got %+v", devices)
+	}
+
+	err = checkpoint.Forget(AssetDevice, devices.Mismatched)
+	if err != nil {
+		t.Fatalf("could not update checkpoint: %s", err)
+	}
+
+	_, err = migrator.Run(ctx)
+	if err != nil {
+		t.Fatalf("could not backfill target engine: %s", err)
+	}
+
+	report, _ = Verify(ctx, source, target)
+	if !report.Consistent {
+		t.Fatalf("target engine should be consistent after the resync: %+v", report.Assets)
+	}
+}
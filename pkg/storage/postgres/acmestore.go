@@ -0,0 +1,79 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	acmeAccountDBName = "acme_accounts"
+	acmeOrderDBName   = "acme_orders"
+)
+
+type PostgresACMEAccountStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.ACMEAccount]
+}
+
+func NewACMEAccountPostgresRepository(db *gorm.DB) (storage.ACMEAccountsRepo, error) {
+	querier, err := CheckAndCreateTable(db, acmeAccountDBName, "id", models.ACMEAccount{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresACMEAccountStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresACMEAccountStore) SelectExists(ctx context.Context, id string) (bool, *models.ACMEAccount, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresACMEAccountStore) Insert(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Insert(ctx, account, account.ID)
+}
+
+func (db *PostgresACMEAccountStore) Update(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Update(ctx, account, account.ID)
+}
+
+type PostgresACMEOrderStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.ACMEOrder]
+}
+
+func NewACMEOrderPostgresRepository(db *gorm.DB) (storage.ACMEOrdersRepo, error) {
+	querier, err := CheckAndCreateTable(db, acmeOrderDBName, "id", models.ACMEOrder{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresACMEOrderStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresACMEOrderStore) SelectByAccount(ctx context.Context, accountID string, req storage.StorageListRequest[models.ACMEOrder]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "account_id = ?", extraArgs: []any{accountID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresACMEOrderStore) SelectExists(ctx context.Context, id string) (bool, *models.ACMEOrder, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresACMEOrderStore) Insert(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Insert(ctx, order, order.ID)
+}
+
+func (db *PostgresACMEOrderStore) Update(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Update(ctx, order, order.ID)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const caEventsDBName = "ca_events"
+
+type PostgresCAEventsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.CAEvent]
+}
+
+func NewCAEventsPostgresRepository(db *gorm.DB) (storage.CAEventsRepo, error) {
+	querier, err := CheckAndCreateTable(db, caEventsDBName, "id", models.CAEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexes(db, caEventsDBName, []string{"ca_id"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresCAEventsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresCAEventsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAEvent]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresCAEventsStore) Insert(ctx context.Context, event *models.CAEvent) (*models.CAEvent, error) {
+	return db.querier.Insert(ctx, event, event.ID)
+}
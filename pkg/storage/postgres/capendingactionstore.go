@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const caPendingActionsDBName = "ca_pending_actions"
+
+type PostgresCAPendingActionsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.CAPendingAction]
+}
+
+func NewCAPendingActionsPostgresRepository(db *gorm.DB) (storage.CAPendingActionsRepo, error) {
+	querier, err := CheckAndCreateTable(db, caPendingActionsDBName, "id", models.CAPendingAction{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresCAPendingActionsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresCAPendingActionsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAPendingAction]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresCAPendingActionsStore) SelectExists(ctx context.Context, id string) (bool, *models.CAPendingAction, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresCAPendingActionsStore) UpdateIf(ctx context.Context, action *models.CAPendingAction, precondition func(current *models.CAPendingAction) bool) (*models.CAPendingAction, error) {
+	return db.querier.UpdateIf(ctx, action, action.ID, precondition)
+}
+
+func (db *PostgresCAPendingActionsStore) Insert(ctx context.Context, action *models.CAPendingAction) (*models.CAPendingAction, error) {
+	return db.querier.Insert(ctx, action, action.ID)
+}
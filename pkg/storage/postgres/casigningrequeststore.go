@@ -0,0 +1,51 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const caSigningRequestsDBName = "ca_signing_requests"
+
+type PostgresCASigningRequestsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.CASigningRequest]
+}
+
+func NewCASigningRequestsPostgresRepository(db *gorm.DB) (storage.CASigningRequestsRepo, error) {
+	querier, err := CheckAndCreateTable(db, caSigningRequestsDBName, "id", models.CASigningRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresCASigningRequestsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresCASigningRequestsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CASigningRequest]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresCASigningRequestsStore) SelectExists(ctx context.Context, id string) (bool, *models.CASigningRequest, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresCASigningRequestsStore) Update(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Update(ctx, request, request.ID)
+}
+
+func (db *PostgresCASigningRequestsStore) UpdateIf(ctx context.Context, request *models.CASigningRequest, precondition func(current *models.CASigningRequest) bool) (*models.CASigningRequest, error) {
+	return db.querier.UpdateIf(ctx, request, request.ID, precondition)
+}
+
+func (db *PostgresCASigningRequestsStore) Insert(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Insert(ctx, request, request.ID)
+}
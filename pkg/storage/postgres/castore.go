@@ -21,6 +21,17 @@ func NewCAPostgresRepository(db *gorm.DB) (storage.CACertificatesRepo, error) {
 		return nil, err
 	}
 
+	err = CreateIndexes(db, caDBName,
+		[]string{"engine_id"},
+		[]string{"status"},
+		[]string{"type"},
+		[]string{"subject_common_name"},
+		[]string{"issuer_meta_id"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PostgresCAStore{
 		db:      db,
 		querier: querier,
@@ -83,6 +94,10 @@ func (db *PostgresCAStore) Update(ctx context.Context, caCertificate *models.CAC
 	return db.querier.Update(ctx, caCertificate, caCertificate.ID)
 }
 
+func (db *PostgresCAStore) UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error) {
+	return db.querier.UpdateIf(ctx, caCertificate, caCertificate.ID, precondition)
+}
+
 func (db *PostgresCAStore) Delete(ctx context.Context, id string) error {
 	return db.querier.Delete(ctx, id)
 }
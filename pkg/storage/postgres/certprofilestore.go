@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"encoding/json"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const certProfileDBName = "certificate_profiles"
+
+type PostgresCertificateProfileStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.CertificateProfile]
+}
+
+func NewCertificateProfilePostgresRepository(db *gorm.DB) (storage.CertificateProfilesRepo, error) {
+	querier, err := CheckAndCreateTable(db, certProfileDBName, "id", models.CertificateProfile{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresCertificateProfileStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresCertificateProfileStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresCertificateProfileStore) SelectExists(ctx context.Context, id string) (bool, *models.CertificateProfile, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresCertificateProfileStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	caIDs, err := json.Marshal([]string{caID})
+	if err != nil {
+		return "", err
+	}
+
+	opts := []gormWhereParams{
+		{query: "ca_ids::jsonb @> ?::jsonb", extraArgs: []any{string(caIDs)}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresCertificateProfileStore) Insert(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Insert(ctx, profile, profile.ID)
+}
+
+func (db *PostgresCertificateProfileStore) Update(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Update(ctx, profile, profile.ID)
+}
+
+func (db *PostgresCertificateProfileStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
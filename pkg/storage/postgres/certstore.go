@@ -22,6 +22,16 @@ func NewCertificateRepository(db *gorm.DB) (storage.CertificatesRepo, error) {
 		return nil, err
 	}
 
+	err = CreateIndexes(db, certDBName,
+		[]string{"issuer_meta_id", "status"},
+		[]string{"status"},
+		[]string{"type"},
+		[]string{"valid_to"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PostgresCertificateStorage{
 		db:      db,
 		querier: querier,
@@ -0,0 +1,56 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const connectorEventsDBName = "connector_pending_events"
+
+type PostgresConnectorPendingEventsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.ConnectorPendingEvent]
+}
+
+func NewConnectorPendingEventsPostgresRepository(db *gorm.DB) (storage.ConnectorPendingEventsRepo, error) {
+	querier, err := CheckAndCreateTable(db, connectorEventsDBName, "id", models.ConnectorPendingEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexes(db, connectorEventsDBName, []string{"connector_id"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresConnectorPendingEventsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresConnectorPendingEventsStore) SelectByConnector(ctx context.Context, connectorID string, req storage.StorageListRequest[models.ConnectorPendingEvent]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "connector_id = ?", extraArgs: []any{connectorID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresConnectorPendingEventsStore) SelectExistsByID(ctx context.Context, id string) (bool, *models.ConnectorPendingEvent, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresConnectorPendingEventsStore) Insert(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Insert(ctx, event, event.ID)
+}
+
+func (db *PostgresConnectorPendingEventsStore) Update(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Update(ctx, event, event.ID)
+}
+
+func (db *PostgresConnectorPendingEventsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
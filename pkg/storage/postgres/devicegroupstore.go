@@ -0,0 +1,91 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	deviceGroupsDBName           = "device_groups"
+	deviceGroupBulkActionsDBName = "device_group_bulk_actions"
+)
+
+type PostgresDeviceGroupsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DeviceGroup]
+}
+
+func NewDeviceGroupsPostgresRepository(db *gorm.DB) (storage.DeviceGroupsRepo, error) {
+	querier, err := CheckAndCreateTable(db, deviceGroupsDBName, "id", models.DeviceGroup{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDeviceGroupsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDeviceGroupsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceGroup]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDeviceGroupsStore) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroup, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresDeviceGroupsStore) Update(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Update(ctx, group, group.ID)
+}
+
+func (db *PostgresDeviceGroupsStore) Insert(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Insert(ctx, group, group.ID)
+}
+
+func (db *PostgresDeviceGroupsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
+
+type PostgresDeviceGroupBulkActionsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DeviceGroupBulkAction]
+}
+
+func NewDeviceGroupBulkActionsPostgresRepository(db *gorm.DB) (storage.DeviceGroupBulkActionsRepo, error) {
+	querier, err := CheckAndCreateTable(db, deviceGroupBulkActionsDBName, "id", models.DeviceGroupBulkAction{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDeviceGroupBulkActionsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDeviceGroupBulkActionsStore) SelectByGroup(ctx context.Context, groupID string, req storage.StorageListRequest[models.DeviceGroupBulkAction]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "group_id = ?", extraArgs: []any{groupID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDeviceGroupBulkActionsStore) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroupBulkAction, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresDeviceGroupBulkActionsStore) Update(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Update(ctx, action, action.ID)
+}
+
+func (db *PostgresDeviceGroupBulkActionsStore) UpdateIf(ctx context.Context, action *models.DeviceGroupBulkAction, precondition func(current *models.DeviceGroupBulkAction) bool) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.UpdateIf(ctx, action, action.ID, precondition)
+}
+
+func (db *PostgresDeviceGroupBulkActionsStore) Insert(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Insert(ctx, action, action.ID)
+}
@@ -0,0 +1,47 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const deviceLogsDBName = "device_logs"
+
+type PostgresDeviceLogsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DeviceLog]
+}
+
+func NewDeviceLogsPostgresRepository(db *gorm.DB) (storage.DeviceLogsRepo, error) {
+	querier, err := CheckAndCreateTable(db, deviceLogsDBName, "id", models.DeviceLog{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDeviceLogsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDeviceLogsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDeviceLogsStore) SelectByDevice(ctx context.Context, deviceID string, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "device_id = ?", extraArgs: []any{deviceID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDeviceLogsStore) Insert(ctx context.Context, log *models.DeviceLog) (*models.DeviceLog, error) {
+	return db.querier.Insert(ctx, log, log.ID)
+}
+
+func (db *PostgresDeviceLogsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
@@ -9,6 +9,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// identitySlotStatusExpression extracts the status of the identity slot, stored as a JSON document.
+const identitySlotStatusExpression = "(identity_slot::jsonb->>'status')"
+
 type PostgresDeviceManagerStore struct {
 	db      *gorm.DB
 	querier *postgresDBQuerier[models.Device]
@@ -20,6 +23,19 @@ func NewDeviceManagerRepository(db *gorm.DB) (storage.DeviceManagerRepo, error)
 		return nil, err
 	}
 
+	err = CreateIndexes(db, "devices",
+		[]string{"status"},
+		[]string{"dms_owner"},
+	)
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateExpressionIndex(db, "devices", "identity_slot_status", identitySlotStatusExpression)
+	if err != nil {
+		return nil, err
+	}
+
 	return &PostgresDeviceManagerStore{
 		db:      db,
 		querier: querier,
@@ -49,6 +65,13 @@ func (db *PostgresDeviceManagerStore) SelectByDMS(ctx context.Context, dmsID str
 	return db.querier.SelectAll(ctx, queryParams, opts, exhaustiveRun, applyFunc)
 }
 
+func (db *PostgresDeviceManagerStore) SelectByIdentitySlotStatus(ctx context.Context, status models.SlotStatus, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	opts := []gormWhereParams{
+		{query: identitySlotStatusExpression + " = ?", extraArgs: []any{status}},
+	}
+	return db.querier.SelectAll(ctx, queryParams, opts, exhaustiveRun, applyFunc)
+}
+
 func (db *PostgresDeviceManagerStore) SelectExists(ctx context.Context, ID string) (bool, *models.Device, error) {
 	return db.querier.SelectExists(ctx, ID, nil)
 }
@@ -57,6 +80,10 @@ func (db *PostgresDeviceManagerStore) Update(ctx context.Context, device *models
 	return db.querier.Update(ctx, device, device.ID)
 }
 
+func (db *PostgresDeviceManagerStore) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error) {
+	return db.querier.UpdateIf(ctx, device, device.ID, precondition)
+}
+
 func (db *PostgresDeviceManagerStore) Insert(ctx context.Context, device *models.Device) (*models.Device, error) {
 	return db.querier.Insert(ctx, device, device.ID)
 }
@@ -0,0 +1,63 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsBootstrapTokensDBName = "dms_bootstrap_tokens"
+
+type PostgresDMSBootstrapTokensStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DMSBootstrapToken]
+}
+
+func NewDMSBootstrapTokensPostgresRepository(db *gorm.DB) (storage.DMSBootstrapTokensRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsBootstrapTokensDBName, "id", models.DMSBootstrapToken{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDMSBootstrapTokensStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDMSBootstrapTokensStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSBootstrapToken]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDMSBootstrapTokensStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSBootstrapToken, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresDMSBootstrapTokensStore) Update(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error) {
+	return db.querier.Update(ctx, token, token.ID)
+}
+
+func (db *PostgresDMSBootstrapTokensStore) Insert(ctx context.Context, token *models.DMSBootstrapToken) (*models.DMSBootstrapToken, error) {
+	return db.querier.Insert(ctx, token, token.ID)
+}
+
+func (db *PostgresDMSBootstrapTokensStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
+
+// MarkUsed only updates the token if its used_at column is still null, so the database serializes the enrollments
+// racing for the same token.
+func (db *PostgresDMSBootstrapTokensStore) MarkUsed(ctx context.Context, id string, deviceID string, usedAt time.Time) (bool, error) {
+	tx := db.db.Table(dmsBootstrapTokensDBName).WithContext(ctx).Where("id = ? AND used_at IS NULL", id).Updates(map[string]any{
+		"used_at": usedAt,
+		"used_by": deviceID,
+	})
+	if err := tx.Error; err != nil {
+		return false, err
+	}
+
+	return tx.RowsAffected == 1, nil
+}
@@ -0,0 +1,52 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsClaimCodesDBName = "dms_claim_codes"
+
+type PostgresDMSClaimCodesStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DMSClaimCode]
+}
+
+func NewDMSClaimCodesPostgresRepository(db *gorm.DB) (storage.DMSClaimCodesRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsClaimCodesDBName, "id", models.DMSClaimCode{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDMSClaimCodesStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDMSClaimCodesStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSClaimCode]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDMSClaimCodesStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSClaimCode, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresDMSClaimCodesStore) Update(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Update(ctx, code, code.ID)
+}
+
+func (db *PostgresDMSClaimCodesStore) UpdateIf(ctx context.Context, code *models.DMSClaimCode, precondition func(current *models.DMSClaimCode) bool) (*models.DMSClaimCode, error) {
+	return db.querier.UpdateIf(ctx, code, code.ID, precondition)
+}
+
+func (db *PostgresDMSClaimCodesStore) Insert(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Insert(ctx, code, code.ID)
+}
+
+func (db *PostgresDMSClaimCodesStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
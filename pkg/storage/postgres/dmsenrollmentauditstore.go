@@ -0,0 +1,43 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsEnrollmentAuditsDBName = "dms_enrollment_audits"
+
+type PostgresDMSEnrollmentAuditsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DMSEnrollmentAudit]
+}
+
+func NewDMSEnrollmentAuditsPostgresRepository(db *gorm.DB) (storage.DMSEnrollmentAuditsRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsEnrollmentAuditsDBName, "id", models.DMSEnrollmentAudit{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDMSEnrollmentAuditsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDMSEnrollmentAuditsStore) SelectByDMS(ctx context.Context, dmsID string, req storage.StorageListRequest[models.DMSEnrollmentAudit]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "dms_id = ?", extraArgs: []any{dmsID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDMSEnrollmentAuditsStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentAudit, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresDMSEnrollmentAuditsStore) Insert(ctx context.Context, audit *models.DMSEnrollmentAudit) (*models.DMSEnrollmentAudit, error) {
+	return db.querier.Insert(ctx, audit, audit.ID)
+}
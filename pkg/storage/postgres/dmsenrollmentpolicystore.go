@@ -0,0 +1,48 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsEnrollmentPoliciesDBName = "dms_enrollment_policies"
+
+type PostgresDMSEnrollmentPoliciesStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DMSEnrollmentPolicy]
+}
+
+func NewDMSEnrollmentPoliciesPostgresRepository(db *gorm.DB) (storage.DMSEnrollmentPoliciesRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsEnrollmentPoliciesDBName, "id", models.DMSEnrollmentPolicy{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDMSEnrollmentPoliciesStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDMSEnrollmentPoliciesStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentPolicy]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDMSEnrollmentPoliciesStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentPolicy, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresDMSEnrollmentPoliciesStore) Update(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Update(ctx, policy, policy.ID)
+}
+
+func (db *PostgresDMSEnrollmentPoliciesStore) Insert(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Insert(ctx, policy, policy.ID)
+}
+
+func (db *PostgresDMSEnrollmentPoliciesStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
@@ -0,0 +1,44 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsEnrollmentStatsDBName = "dms_enrollment_stats"
+
+type PostgresDMSEnrollmentStatsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.DMSEnrollmentStats]
+}
+
+func NewDMSEnrollmentStatsPostgresRepository(db *gorm.DB) (storage.DMSEnrollmentStatsRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsEnrollmentStatsDBName, "dms_id", models.DMSEnrollmentStats{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresDMSEnrollmentStatsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresDMSEnrollmentStatsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentStats]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresDMSEnrollmentStatsStore) SelectExists(ctx context.Context, dmsID string) (bool, *models.DMSEnrollmentStats, error) {
+	return db.querier.SelectExists(ctx, dmsID, nil)
+}
+
+func (db *PostgresDMSEnrollmentStatsStore) Update(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Update(ctx, stats, stats.DMSID)
+}
+
+func (db *PostgresDMSEnrollmentStatsStore) Insert(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Insert(ctx, stats, stats.DMSID)
+}
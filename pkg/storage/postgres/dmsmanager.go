@@ -42,6 +42,10 @@ func (db *PostgresDMSManagerStore) Update(ctx context.Context, DMS *models.DMS)
 	return db.querier.Update(ctx, DMS, DMS.ID)
 }
 
+func (db *PostgresDMSManagerStore) UpdateIf(ctx context.Context, DMS *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error) {
+	return db.querier.UpdateIf(ctx, DMS, DMS.ID, precondition)
+}
+
 func (db *PostgresDMSManagerStore) Insert(ctx context.Context, DMS *models.DMS) (*models.DMS, error) {
 	return db.querier.Insert(ctx, DMS, DMS.ID)
 }
@@ -19,6 +19,10 @@ const (
 	DEVICE_DB_NAME = "devicemanager"
 	DMS_DB_NAME    = "dmsmanager"
 	ALERTS_DB_NAME = "alerts"
+	// CLOUD_PROXY_DB_NAME holds the state of the cloud connectors.
+	CLOUD_PROXY_DB_NAME = "cloudproxy"
+	// EVENTBUS_DB_NAME holds the events handled by the event bus consumers.
+	EVENTBUS_DB_NAME = "eventbus"
 )
 
 type PostgresStorageEngine struct {
@@ -64,6 +68,41 @@ func (s *PostgresStorageEngine) initialiceCACertStorage() error {
 			return err
 		}
 	}
+
+	if s.IssuanceLog == nil {
+		s.IssuanceLog, err = NewIssuanceLogPostgresRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CAEvents == nil {
+		s.CAEvents, err = NewCAEventsPostgresRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CASigningRequests == nil {
+		s.CASigningRequests, err = NewCASigningRequestsPostgresRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CAPendingActions == nil {
+		s.CAPendingActions, err = NewCAPendingActionsPostgresRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CertificateProfiles == nil {
+		s.CertificateProfiles, err = NewCertificateProfilePostgresRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -78,6 +117,93 @@ func (s *PostgresStorageEngine) GetCertstorage() (storage.CertificatesRepo, erro
 	return s.Cert, nil
 }
 
+func (s *PostgresStorageEngine) GetCertificateProfileStorage() (storage.CertificateProfilesRepo, error) {
+	if s.CertificateProfiles == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CertificateProfiles, nil
+}
+
+func (s *PostgresStorageEngine) GetIssuanceLogStorage() (storage.IssuanceLogRepo, error) {
+	if s.IssuanceLog == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.IssuanceLog, nil
+}
+
+func (s *PostgresStorageEngine) GetCAEventsStorage() (storage.CAEventsRepo, error) {
+	if s.CAEvents == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CAEvents, nil
+}
+
+func (s *PostgresStorageEngine) GetCASigningRequestsStorage() (storage.CASigningRequestsRepo, error) {
+	if s.CASigningRequests == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CASigningRequests, nil
+}
+
+func (s *PostgresStorageEngine) GetCAPendingActionsStorage() (storage.CAPendingActionsRepo, error) {
+	if s.CAPendingActions == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CAPendingActions, nil
+}
+
+func (s *PostgresStorageEngine) GetConnectorPendingEventsStorage() (storage.ConnectorPendingEventsRepo, error) {
+	if s.ConnectorEvents == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, CLOUD_PROXY_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		eventsStore, err := NewConnectorPendingEventsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres Connector Pending Events client: %s", err)
+		}
+		s.ConnectorEvents = eventsStore
+	}
+	return s.ConnectorEvents, nil
+}
+
+func (s *PostgresStorageEngine) GetProcessedEventsStorage() (storage.ProcessedEventsRepo, error) {
+	if s.ProcessedEvents == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, EVENTBUS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		eventsStore, err := NewProcessedEventsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres Processed Events client: %s", err)
+		}
+		s.ProcessedEvents = eventsStore
+	}
+	return s.ProcessedEvents, nil
+}
+
 func (s *PostgresStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, error) {
 
 	if s.Device == nil {
@@ -95,6 +221,54 @@ func (s *PostgresStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, e
 	return s.Device, nil
 }
 
+func (s *PostgresStorageEngine) GetDeviceGroupsStorage() (storage.DeviceGroupsRepo, error) {
+	if s.DeviceGroups == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DEVICE_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		groupStore, err := NewDeviceGroupsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres Device Groups client: %s", err)
+		}
+		s.DeviceGroups = groupStore
+	}
+	return s.DeviceGroups, nil
+}
+
+func (s *PostgresStorageEngine) GetDeviceGroupBulkActionsStorage() (storage.DeviceGroupBulkActionsRepo, error) {
+	if s.DeviceBulkActions == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DEVICE_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		actionStore, err := NewDeviceGroupBulkActionsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres Device Group Bulk Actions client: %s", err)
+		}
+		s.DeviceBulkActions = actionStore
+	}
+	return s.DeviceBulkActions, nil
+}
+
+func (s *PostgresStorageEngine) GetDeviceLogsStorage() (storage.DeviceLogsRepo, error) {
+	if s.DeviceLogs == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DEVICE_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		logStore, err := NewDeviceLogsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres Device Logs client: %s", err)
+		}
+		s.DeviceLogs = logStore
+	}
+	return s.DeviceLogs, nil
+}
+
 func (s *PostgresStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
 	if s.DMS == nil {
 		psqlCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
@@ -111,6 +285,131 @@ func (s *PostgresStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
 	return s.DMS, nil
 }
 
+func (s *PostgresStorageEngine) GetDMSEnrollmentStatsStorage() (storage.DMSEnrollmentStatsRepo, error) {
+	if s.DMSEnrollmentStats == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		statsStore, err := NewDMSEnrollmentStatsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres DMS Enrollment Stats client: %s", err)
+		}
+		s.DMSEnrollmentStats = statsStore
+	}
+	return s.DMSEnrollmentStats, nil
+}
+
+func (s *PostgresStorageEngine) GetDMSEnrollmentPoliciesStorage() (storage.DMSEnrollmentPoliciesRepo, error) {
+	if s.DMSEnrollmentPolicy == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		policyStore, err := NewDMSEnrollmentPoliciesPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres DMS Enrollment Policies client: %s", err)
+		}
+		s.DMSEnrollmentPolicy = policyStore
+	}
+	return s.DMSEnrollmentPolicy, nil
+}
+
+func (s *PostgresStorageEngine) GetDMSBootstrapTokensStorage() (storage.DMSBootstrapTokensRepo, error) {
+	if s.DMSBootstrapTokens == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		tokenStore, err := NewDMSBootstrapTokensPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres DMS Bootstrap Tokens client: %s", err)
+		}
+		s.DMSBootstrapTokens = tokenStore
+	}
+	return s.DMSBootstrapTokens, nil
+}
+
+func (s *PostgresStorageEngine) GetDMSClaimCodesStorage() (storage.DMSClaimCodesRepo, error) {
+	if s.DMSClaimCodes == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		codeStore, err := NewDMSClaimCodesPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres DMS Claim Codes client: %s", err)
+		}
+		s.DMSClaimCodes = codeStore
+	}
+	return s.DMSClaimCodes, nil
+}
+
+func (s *PostgresStorageEngine) GetDMSEnrollmentAuditsStorage() (storage.DMSEnrollmentAuditsRepo, error) {
+	if s.DMSEnrollmentAudits == nil {
+		dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create postgres client: %s", err)
+		}
+
+		auditStore, err := NewDMSEnrollmentAuditsPostgresRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres DMS Enrollment Audits client: %s", err)
+		}
+		s.DMSEnrollmentAudits = auditStore
+	}
+	return s.DMSEnrollmentAudits, nil
+}
+
+func (s *PostgresStorageEngine) GetACMEAccountStorage() (storage.ACMEAccountsRepo, error) {
+	if s.ACMEAccounts == nil {
+		err := s.initialiceACMEStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres ACME clients: %s", err)
+		}
+	}
+
+	return s.ACMEAccounts, nil
+}
+
+func (s *PostgresStorageEngine) GetACMEOrderStorage() (storage.ACMEOrdersRepo, error) {
+	if s.ACMEOrders == nil {
+		err := s.initialiceACMEStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize postgres ACME clients: %s", err)
+		}
+	}
+
+	return s.ACMEOrders, nil
+}
+
+func (s *PostgresStorageEngine) initialiceACMEStorage() error {
+	dbCli, err := CreatePostgresDBConnection(s.logger, s.Config, DMS_DB_NAME)
+	if err != nil {
+		return err
+	}
+
+	if s.ACMEAccounts == nil {
+		s.ACMEAccounts, err = NewACMEAccountPostgresRepository(dbCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.ACMEOrders == nil {
+		s.ACMEOrders, err = NewACMEOrderPostgresRepository(dbCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *PostgresStorageEngine) GetEnventsStorage() (storage.EventRepository, error) {
 	if s.Events == nil {
 		s.initialiceSubscriptionsStorage()
@@ -0,0 +1,54 @@
+package postgres
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const issuanceLogDBName = "issuance_log"
+
+type PostgresIssuanceLogStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.IssuanceLogEntry]
+}
+
+func NewIssuanceLogPostgresRepository(db *gorm.DB) (storage.IssuanceLogRepo, error) {
+	querier, err := CheckAndCreateTable(db, issuanceLogDBName, "id", models.IssuanceLogEntry{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexes(db, issuanceLogDBName, []string{"ca_id", "log_index"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresIssuanceLogStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresIssuanceLogStore) CountByCA(ctx context.Context, caID string) (int, error) {
+	return db.querier.Count(ctx, []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	})
+}
+
+func (db *PostgresIssuanceLogStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.IssuanceLogEntry]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresIssuanceLogStore) SelectExists(ctx context.Context, id string) (bool, *models.IssuanceLogEntry, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *PostgresIssuanceLogStore) Insert(ctx context.Context, entry *models.IssuanceLogEntry) (*models.IssuanceLogEntry, error) {
+	return db.querier.Insert(ctx, entry, entry.ID)
+}
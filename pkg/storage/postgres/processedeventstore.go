@@ -0,0 +1,61 @@
+package postgres
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const processedEventsDBName = "processed_events"
+
+type PostgresProcessedEventsStore struct {
+	db      *gorm.DB
+	querier *postgresDBQuerier[models.ProcessedEvent]
+}
+
+func NewProcessedEventsPostgresRepository(db *gorm.DB) (storage.ProcessedEventsRepo, error) {
+	querier, err := CheckAndCreateTable(db, processedEventsDBName, "key", models.ProcessedEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	err = CreateIndexes(db, processedEventsDBName, []string{"expires_at"})
+	if err != nil {
+		return nil, err
+	}
+
+	return &PostgresProcessedEventsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *PostgresProcessedEventsStore) SelectExists(ctx context.Context, key string) (bool, *models.ProcessedEvent, error) {
+	return db.querier.SelectExists(ctx, key, nil)
+}
+
+func (db *PostgresProcessedEventsStore) SelectExpired(ctx context.Context, before time.Time, req storage.StorageListRequest[models.ProcessedEvent]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "expires_at < ?", extraArgs: []any{before}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *PostgresProcessedEventsStore) Insert(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Insert(ctx, event, event.Key)
+}
+
+func (db *PostgresProcessedEventsStore) Update(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Update(ctx, event, event.Key)
+}
+
+func (db *PostgresProcessedEventsStore) UpdateIf(ctx context.Context, event *models.ProcessedEvent, precondition func(current *models.ProcessedEvent) bool) (*models.ProcessedEvent, error) {
+	return db.querier.UpdateIf(ctx, event, event.Key, precondition)
+}
+
+func (db *PostgresProcessedEventsStore) Delete(ctx context.Context, key string) error {
+	return db.querier.Delete(ctx, key)
+}
@@ -14,9 +14,11 @@ import (
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
 	gormlogger "gorm.io/gorm/logger"
 	"gorm.io/gorm/schema"
 )
@@ -91,6 +93,31 @@ func CheckAndCreateTable[E any](db *gorm.DB, tableName string, primaryKeyColumn
 	return &querier, nil
 }
 
+// CreateIndexes creates a secondary index for each group of columns, named after the table and its columns.
+// Existing indexes are kept as they are.
+func CreateIndexes(db *gorm.DB, tableName string, indexes ...[]string) error {
+	for _, columns := range indexes {
+		err := CreateExpressionIndex(db, tableName, strings.Join(columns, "_"), strings.Join(columns, ", "))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// CreateExpressionIndex creates a secondary index over an expression, such as a field of a JSON column. The queries
+// must use the very same expression for the index to be used.
+func CreateExpressionIndex(db *gorm.DB, tableName string, name string, expression string) error {
+	indexName := fmt.Sprintf("idx_%s_%s", tableName, name)
+	err := db.Exec(fmt.Sprintf("CREATE INDEX IF NOT EXISTS %s ON %s (%s)", indexName, tableName, expression)).Error
+	if err != nil {
+		return fmt.Errorf("could not create index %s: %w", indexName, err)
+	}
+
+	return nil
+}
+
 type postgresDBQuerier[E any] struct {
 	*gorm.DB
 	tableName        string
@@ -318,6 +345,38 @@ func (db *postgresDBQuerier[E]) Update(ctx context.Context, elem *E, elemID stri
 	return elem, nil
 }
 
+// UpdateIf updates the element only if precondition holds for the stored one. The row is locked while the precondition
+// is evaluated, so the database serializes the conditional updates racing for the same element.
+func (db *postgresDBQuerier[E]) UpdateIf(ctx context.Context, elem *E, elemID string, precondition func(current *E) bool) (*E, error) {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current E
+		res := tx.Table(db.tableName).Clauses(clause.Locking{Strength: "UPDATE"}).First(&current, fmt.Sprintf("%s = ?", db.primaryKeyColumn), elemID)
+		if err := res.Error; err != nil {
+			return err
+		}
+
+		if !precondition(&current) {
+			return storage.ErrUpdateConflict
+		}
+
+		res = tx.Table(db.tableName).Where(fmt.Sprintf("%s = ?", db.primaryKeyColumn), elemID).Updates(elem)
+		if err := res.Error; err != nil {
+			return err
+		}
+
+		if res.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return elem, nil
+}
+
 func (db *postgresDBQuerier[E]) Delete(ctx context.Context, elemID string) error {
 	tx := db.Table(db.tableName).WithContext(ctx).Delete(nil, db.Where(fmt.Sprintf("%s = ?", db.primaryKeyColumn), elemID))
 	if err := tx.Error; err != nil {
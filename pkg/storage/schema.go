@@ -0,0 +1,79 @@
+package storage
+
+import (
+	"context"
+	"fmt"
+	"sort"
+
+	"github.com/sirupsen/logrus"
+)
+
+// SchemaMigration is a versioned change of the schema of a storage engine (i.e. CouchDB design documents and views
+// or SQL tables). Migrations must be idempotent, as replicas of the same service may run them concurrently.
+type SchemaMigration struct {
+	Version     int
+	Description string
+	Migrate     func(ctx context.Context) error
+}
+
+// SchemaMigrator is implemented by the storage engines with a versioned schema. Engines not implementing it are
+// considered to be always up to date.
+type SchemaMigrator interface {
+	// GetSchemaVersion returns the version of the last applied migration, or 0 if none has been applied.
+	GetSchemaVersion(ctx context.Context) (int, error)
+	SetSchemaVersion(ctx context.Context, version int) error
+	SchemaMigrations() []SchemaMigration
+}
+
+// MigrateSchema applies, in version order, the migrations of the engine newer than its current schema version and
+// returns the resulting schema version. The version is recorded after each migration, so a failed run is resumed
+// from the failing migration.
+func MigrateSchema(ctx context.Context, logger *logrus.Entry, engine StorageEngine) (int, error) {
+	migrator, ok := engine.(SchemaMigrator)
+	if !ok {
+		logger.Debugf("storage engine has no versioned schema. Skipping schema migrations")
+		return 0, nil
+	}
+
+	current, err := migrator.GetSchemaVersion(ctx)
+	if err != nil {
+		return -1, fmt.Errorf("could not get schema version: %w", err)
+	}
+
+	migrations := migrator.SchemaMigrations()
+	sort.SliceStable(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	latest := 0
+	if len(migrations) > 0 {
+		latest = migrations[len(migrations)-1].Version
+	}
+
+	if current > latest {
+		logger.Warnf("schema version %d is newer than the latest known migration. The storage may have been upgraded by a newer release", current)
+		return current, nil
+	}
+
+	for _, migration := range migrations {
+		if migration.Version <= current {
+			continue
+		}
+
+		logger.Infof("applying schema migration %d: %s", migration.Version, migration.Description)
+		err = migration.Migrate(ctx)
+		if err != nil {
+			return current, fmt.Errorf("schema migration %d failed: %w", migration.Version, err)
+		}
+
+		err = migrator.SetSchemaVersion(ctx, migration.Version)
+		if err != nil {
+			return current, fmt.Errorf("could not record schema version %d: %w", migration.Version, err)
+		}
+
+		current = migration.Version
+	}
+
+	logger.Infof("storage schema is up to date at version %d", current)
+	return current, nil
+}
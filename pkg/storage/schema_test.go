@@ -0,0 +1,111 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"testing"
+
+	"github.com/sirupsen/logrus"
+)
+
+type fakeSchemaEngine struct {
+	StorageEngine
+	version    int
+	applied    []int
+	failAt     int
+	migrations []int
+}
+
+func (e *fakeSchemaEngine) GetSchemaVersion(ctx context.Context) (int, error) {
+	return e.version, nil
+}
+
+func (e *fakeSchemaEngine) SetSchemaVersion(ctx context.Context, version int) error {
+	e.version = version
+	return nil
+}
+
+func (e *fakeSchemaEngine) SchemaMigrations() []SchemaMigration {
+	migrations := []SchemaMigration{}
+	for _, version := range e.migrations {
+		migrations = append(migrations, SchemaMigration{
+			Version: version,
+			Migrate: func(ctx context.Context) error {
+				if version == e.failAt {
+					return errors.New("migration failed")
+				}
+				e.applied = append(e.applied, version)
+				return nil
+			},
+		})
+	}
+
+	return migrations
+}
+
+func TestMigrateSchema(t *testing.T) {
+	logger := logrus.NewEntry(logrus.New())
+
+	testcases := []struct {
+		name            string
+		engine          *fakeSchemaEngine
+		expectErr       bool
+		expectedVersion int
+		expectedApplied []int
+	}{
+		{
+			name:            "OK/FromScratchUnordered",
+			engine:          &fakeSchemaEngine{migrations: []int{2, 1, 3}},
+			expectedVersion: 3,
+			expectedApplied: []int{1, 2, 3},
+		},
+		{
+			name:            "OK/PendingMigrations",
+			engine:          &fakeSchemaEngine{version: 2, migrations: []int{1, 2, 3}},
+			expectedVersion: 3,
+			expectedApplied: []int{3},
+		},
+		{
+			name:            "OK/UpToDate",
+			engine:          &fakeSchemaEngine{version: 3, migrations: []int{1, 2, 3}},
+			expectedVersion: 3,
+		},
+		{
+			name:            "OK/NewerSchema",
+			engine:          &fakeSchemaEngine{version: 5, migrations: []int{1, 2, 3}},
+			expectedVersion: 5,
+		},
+		{
+			name:            "Err/FailedMigration",
+			engine:          &fakeSchemaEngine{migrations: []int{1, 2, 3}, failAt: 2},
+			expectErr:       true,
+			expectedVersion: 1,
+			expectedApplied: []int{1},
+		},
+	}
+
+	for _, tc := range testcases {
+		t.Run(tc.name, func(t *testing.T) {
+			version, err := MigrateSchema(context.Background(), logger, tc.engine)
+			if tc.expectErr != (err != nil) {
+				t.Fatalf("unexpected error result: %v", err)
+			}
+
+			if version != tc.expectedVersion || tc.engine.version != tc.expectedVersion {
+				t.Errorf("expected schema version %d, got %d (recorded %d)", tc.expectedVersion, version, tc.engine.version)
+			}
+
+			if !slices.Equal(tc.engine.applied, tc.expectedApplied) {
+				t.Errorf("expected applied migrations %v, got %v", tc.expectedApplied, tc.engine.applied)
+			}
+		})
+	}
+}
+
+func TestMigrateSchemaUnversionedEngine(t *testing.T) {
+	version, err := MigrateSchema(context.Background(), logrus.NewEntry(logrus.New()), struct{ StorageEngine }{})
+	if err != nil || version != 0 {
+		t.Errorf("expected unversioned engines to be skipped, got version %d and error %v", version, err)
+	}
+}
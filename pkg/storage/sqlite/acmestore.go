@@ -0,0 +1,82 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	acmeAccountDBName = "acme_accounts"
+	acmeOrderDBName   = "acme_orders"
+)
+
+type SQLiteACMEAccountStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.ACMEAccount]
+}
+
+func NewACMEAccountRepository(db *gorm.DB) (storage.ACMEAccountsRepo, error) {
+	querier, err := CheckAndCreateTable(db, acmeAccountDBName, "id", models.ACMEAccount{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteACMEAccountStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteACMEAccountStore) SelectExists(ctx context.Context, id string) (bool, *models.ACMEAccount, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteACMEAccountStore) Insert(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Insert(ctx, account, account.ID)
+}
+
+func (db *SQLiteACMEAccountStore) Update(ctx context.Context, account *models.ACMEAccount) (*models.ACMEAccount, error) {
+	return db.querier.Update(ctx, account, account.ID)
+}
+
+type SQLiteACMEOrderStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.ACMEOrder]
+}
+
+func NewACMEOrderRepository(db *gorm.DB) (storage.ACMEOrdersRepo, error) {
+	querier, err := CheckAndCreateTable(db, acmeOrderDBName, "id", models.ACMEOrder{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteACMEOrderStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteACMEOrderStore) SelectByAccount(ctx context.Context, accountID string, req storage.StorageListRequest[models.ACMEOrder]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "account_id = ?", extraArgs: []any{accountID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteACMEOrderStore) SelectExists(ctx context.Context, id string) (bool, *models.ACMEOrder, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteACMEOrderStore) Insert(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Insert(ctx, order, order.ID)
+}
+
+func (db *SQLiteACMEOrderStore) Update(ctx context.Context, order *models.ACMEOrder) (*models.ACMEOrder, error) {
+	return db.querier.Update(ctx, order, order.ID)
+}
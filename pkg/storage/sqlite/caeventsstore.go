@@ -0,0 +1,42 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const caEventsDBName = "ca_events"
+
+type SQLiteCAEventsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.CAEvent]
+}
+
+func NewCAEventsRepository(db *gorm.DB) (storage.CAEventsRepo, error) {
+	querier, err := CheckAndCreateTable(db, caEventsDBName, "id", models.CAEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteCAEventsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteCAEventsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAEvent]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteCAEventsStore) Insert(ctx context.Context, event *models.CAEvent) (*models.CAEvent, error) {
+	return db.querier.Insert(ctx, event, event.ID)
+}
@@ -0,0 +1,50 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const caPendingActionsDBName = "ca_pending_actions"
+
+type SQLiteCAPendingActionsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.CAPendingAction]
+}
+
+func NewCAPendingActionsRepository(db *gorm.DB) (storage.CAPendingActionsRepo, error) {
+	querier, err := CheckAndCreateTable(db, caPendingActionsDBName, "id", models.CAPendingAction{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteCAPendingActionsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteCAPendingActionsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CAPendingAction]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteCAPendingActionsStore) SelectExists(ctx context.Context, id string) (bool, *models.CAPendingAction, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteCAPendingActionsStore) UpdateIf(ctx context.Context, action *models.CAPendingAction, precondition func(current *models.CAPendingAction) bool) (*models.CAPendingAction, error) {
+	return db.querier.UpdateIf(ctx, action, action.ID, precondition)
+}
+
+func (db *SQLiteCAPendingActionsStore) Insert(ctx context.Context, action *models.CAPendingAction) (*models.CAPendingAction, error) {
+	return db.querier.Insert(ctx, action, action.ID)
+}
@@ -0,0 +1,54 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const caSigningRequestsDBName = "ca_signing_requests"
+
+type SQLiteCASigningRequestsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.CASigningRequest]
+}
+
+func NewCASigningRequestsRepository(db *gorm.DB) (storage.CASigningRequestsRepo, error) {
+	querier, err := CheckAndCreateTable(db, caSigningRequestsDBName, "id", models.CASigningRequest{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteCASigningRequestsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteCASigningRequestsStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CASigningRequest]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteCASigningRequestsStore) SelectExists(ctx context.Context, id string) (bool, *models.CASigningRequest, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteCASigningRequestsStore) Update(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Update(ctx, request, request.ID)
+}
+
+func (db *SQLiteCASigningRequestsStore) UpdateIf(ctx context.Context, request *models.CASigningRequest, precondition func(current *models.CASigningRequest) bool) (*models.CASigningRequest, error) {
+	return db.querier.UpdateIf(ctx, request, request.ID, precondition)
+}
+
+func (db *SQLiteCASigningRequestsStore) Insert(ctx context.Context, request *models.CASigningRequest) (*models.CASigningRequest, error) {
+	return db.querier.Insert(ctx, request, request.ID)
+}
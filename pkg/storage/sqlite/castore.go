@@ -86,6 +86,10 @@ func (db *SQLiteCAStore) Update(ctx context.Context, caCertificate *models.CACer
 	return db.querier.Update(ctx, caCertificate, caCertificate.ID)
 }
 
+func (db *SQLiteCAStore) UpdateIf(ctx context.Context, caCertificate *models.CACertificate, precondition func(current *models.CACertificate) bool) (*models.CACertificate, error) {
+	return db.querier.UpdateIf(ctx, caCertificate, caCertificate.ID, precondition)
+}
+
 func (db *SQLiteCAStore) Delete(ctx context.Context, id string) error {
 	return db.querier.Delete(ctx, id)
 }
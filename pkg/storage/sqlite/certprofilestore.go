@@ -0,0 +1,58 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const certProfileDBName = "certificate_profiles"
+
+type SQLiteCertificateProfileStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.CertificateProfile]
+}
+
+func NewCertificateProfileRepository(db *gorm.DB) (storage.CertificateProfilesRepo, error) {
+	querier, err := CheckAndCreateTable(db, certProfileDBName, "id", models.CertificateProfile{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteCertificateProfileStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteCertificateProfileStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteCertificateProfileStore) SelectExists(ctx context.Context, id string) (bool, *models.CertificateProfile, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteCertificateProfileStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.CertificateProfile]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "EXISTS (SELECT 1 FROM json_each(ca_ids) WHERE json_each.value = ?)", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteCertificateProfileStore) Insert(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Insert(ctx, profile, profile.ID)
+}
+
+func (db *SQLiteCertificateProfileStore) Update(ctx context.Context, profile *models.CertificateProfile) (*models.CertificateProfile, error) {
+	return db.querier.Update(ctx, profile, profile.ID)
+}
+
+func (db *SQLiteCertificateProfileStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
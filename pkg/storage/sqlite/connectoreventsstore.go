@@ -0,0 +1,54 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const connectorEventsDBName = "connector_pending_events"
+
+type SQLiteConnectorPendingEventsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.ConnectorPendingEvent]
+}
+
+func NewConnectorPendingEventsRepository(db *gorm.DB) (storage.ConnectorPendingEventsRepo, error) {
+	querier, err := CheckAndCreateTable(db, connectorEventsDBName, "id", models.ConnectorPendingEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteConnectorPendingEventsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteConnectorPendingEventsStore) SelectByConnector(ctx context.Context, connectorID string, req storage.StorageListRequest[models.ConnectorPendingEvent]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "connector_id = ?", extraArgs: []any{connectorID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteConnectorPendingEventsStore) SelectExistsByID(ctx context.Context, id string) (bool, *models.ConnectorPendingEvent, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteConnectorPendingEventsStore) Insert(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Insert(ctx, event, event.ID)
+}
+
+func (db *SQLiteConnectorPendingEventsStore) Update(ctx context.Context, event *models.ConnectorPendingEvent) (*models.ConnectorPendingEvent, error) {
+	return db.querier.Update(ctx, event, event.ID)
+}
+
+func (db *SQLiteConnectorPendingEventsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
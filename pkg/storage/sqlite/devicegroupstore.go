@@ -0,0 +1,94 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const (
+	deviceGroupsDBName           = "device_groups"
+	deviceGroupBulkActionsDBName = "device_group_bulk_actions"
+)
+
+type SQLiteDeviceGroupsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DeviceGroup]
+}
+
+func NewDeviceGroupsRepository(db *gorm.DB) (storage.DeviceGroupsRepo, error) {
+	querier, err := CheckAndCreateTable(db, deviceGroupsDBName, "id", models.DeviceGroup{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDeviceGroupsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDeviceGroupsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceGroup]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDeviceGroupsStore) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroup, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteDeviceGroupsStore) Update(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Update(ctx, group, group.ID)
+}
+
+func (db *SQLiteDeviceGroupsStore) Insert(ctx context.Context, group *models.DeviceGroup) (*models.DeviceGroup, error) {
+	return db.querier.Insert(ctx, group, group.ID)
+}
+
+func (db *SQLiteDeviceGroupsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
+
+type SQLiteDeviceGroupBulkActionsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DeviceGroupBulkAction]
+}
+
+func NewDeviceGroupBulkActionsRepository(db *gorm.DB) (storage.DeviceGroupBulkActionsRepo, error) {
+	querier, err := CheckAndCreateTable(db, deviceGroupBulkActionsDBName, "id", models.DeviceGroupBulkAction{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDeviceGroupBulkActionsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDeviceGroupBulkActionsStore) SelectByGroup(ctx context.Context, groupID string, req storage.StorageListRequest[models.DeviceGroupBulkAction]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "group_id = ?", extraArgs: []any{groupID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDeviceGroupBulkActionsStore) SelectExists(ctx context.Context, id string) (bool, *models.DeviceGroupBulkAction, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteDeviceGroupBulkActionsStore) Update(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Update(ctx, action, action.ID)
+}
+
+func (db *SQLiteDeviceGroupBulkActionsStore) UpdateIf(ctx context.Context, action *models.DeviceGroupBulkAction, precondition func(current *models.DeviceGroupBulkAction) bool) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.UpdateIf(ctx, action, action.ID, precondition)
+}
+
+func (db *SQLiteDeviceGroupBulkActionsStore) Insert(ctx context.Context, action *models.DeviceGroupBulkAction) (*models.DeviceGroupBulkAction, error) {
+	return db.querier.Insert(ctx, action, action.ID)
+}
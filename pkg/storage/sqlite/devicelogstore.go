@@ -0,0 +1,50 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const deviceLogsDBName = "device_logs"
+
+type SQLiteDeviceLogsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DeviceLog]
+}
+
+func NewDeviceLogsRepository(db *gorm.DB) (storage.DeviceLogsRepo, error) {
+	querier, err := CheckAndCreateTable(db, deviceLogsDBName, "id", models.DeviceLog{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDeviceLogsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDeviceLogsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDeviceLogsStore) SelectByDevice(ctx context.Context, deviceID string, req storage.StorageListRequest[models.DeviceLog]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "device_id = ?", extraArgs: []any{deviceID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDeviceLogsStore) Insert(ctx context.Context, log *models.DeviceLog) (*models.DeviceLog, error) {
+	return db.querier.Insert(ctx, log, log.ID)
+}
+
+func (db *SQLiteDeviceLogsStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
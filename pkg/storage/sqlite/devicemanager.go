@@ -12,6 +12,9 @@ import (
 	"gorm.io/gorm"
 )
 
+// identitySlotStatusExpression extracts the status of the identity slot, stored as a JSON document.
+const identitySlotStatusExpression = "json_extract(identity_slot, '$.status')"
+
 type SQLiteDeviceManagerStore struct {
 	db      *gorm.DB
 	querier *sqliteDBQuerier[models.Device]
@@ -23,6 +26,11 @@ func NewDeviceManagerRepository(db *gorm.DB) (storage.DeviceManagerRepo, error)
 		return nil, err
 	}
 
+	err = db.Exec("CREATE INDEX IF NOT EXISTS idx_devices_identity_slot_status ON devices (" + identitySlotStatusExpression + ")").Error
+	if err != nil {
+		return nil, err
+	}
+
 	return &SQLiteDeviceManagerStore{
 		db:      db,
 		querier: querier,
@@ -52,6 +60,13 @@ func (db *SQLiteDeviceManagerStore) SelectByDMS(ctx context.Context, dmsID strin
 	return db.querier.SelectAll(ctx, queryParams, opts, exhaustiveRun, applyFunc)
 }
 
+func (db *SQLiteDeviceManagerStore) SelectByIdentitySlotStatus(ctx context.Context, status models.SlotStatus, exhaustiveRun bool, applyFunc func(models.Device), queryParams *resources.QueryParameters, extraOpts map[string]interface{}) (string, error) {
+	opts := []gormWhereParams{
+		{query: identitySlotStatusExpression + " = ?", extraArgs: []any{status}},
+	}
+	return db.querier.SelectAll(ctx, queryParams, opts, exhaustiveRun, applyFunc)
+}
+
 func (db *SQLiteDeviceManagerStore) SelectExists(ctx context.Context, ID string) (bool, *models.Device, error) {
 	return db.querier.SelectExists(ctx, ID, nil)
 }
@@ -60,6 +75,10 @@ func (db *SQLiteDeviceManagerStore) Update(ctx context.Context, device *models.D
 	return db.querier.Update(ctx, device, device.ID)
 }
 
+func (db *SQLiteDeviceManagerStore) UpdateIf(ctx context.Context, device *models.Device, precondition func(current *models.Device) bool) (*models.Device, error) {
+	return db.querier.UpdateIf(ctx, device, device.ID, precondition)
+}
+
 func (db *SQLiteDeviceManagerStore) Insert(ctx context.Context, device *models.Device) (*models.Device, error) {
 	return db.querier.Insert(ctx, device, device.ID)
 }
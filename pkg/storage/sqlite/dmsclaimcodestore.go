@@ -0,0 +1,55 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsClaimCodesDBName = "dms_claim_codes"
+
+type SQLiteDMSClaimCodesStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DMSClaimCode]
+}
+
+func NewDMSClaimCodesRepository(db *gorm.DB) (storage.DMSClaimCodesRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsClaimCodesDBName, "id", models.DMSClaimCode{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDMSClaimCodesStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDMSClaimCodesStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSClaimCode]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDMSClaimCodesStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSClaimCode, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteDMSClaimCodesStore) Update(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Update(ctx, code, code.ID)
+}
+
+func (db *SQLiteDMSClaimCodesStore) UpdateIf(ctx context.Context, code *models.DMSClaimCode, precondition func(current *models.DMSClaimCode) bool) (*models.DMSClaimCode, error) {
+	return db.querier.UpdateIf(ctx, code, code.ID, precondition)
+}
+
+func (db *SQLiteDMSClaimCodesStore) Insert(ctx context.Context, code *models.DMSClaimCode) (*models.DMSClaimCode, error) {
+	return db.querier.Insert(ctx, code, code.ID)
+}
+
+func (db *SQLiteDMSClaimCodesStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
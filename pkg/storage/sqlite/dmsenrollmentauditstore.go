@@ -0,0 +1,46 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsEnrollmentAuditsDBName = "dms_enrollment_audits"
+
+type SQLiteDMSEnrollmentAuditsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DMSEnrollmentAudit]
+}
+
+func NewDMSEnrollmentAuditsRepository(db *gorm.DB) (storage.DMSEnrollmentAuditsRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsEnrollmentAuditsDBName, "id", models.DMSEnrollmentAudit{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDMSEnrollmentAuditsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDMSEnrollmentAuditsStore) SelectByDMS(ctx context.Context, dmsID string, req storage.StorageListRequest[models.DMSEnrollmentAudit]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "dms_id = ?", extraArgs: []any{dmsID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDMSEnrollmentAuditsStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentAudit, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteDMSEnrollmentAuditsStore) Insert(ctx context.Context, audit *models.DMSEnrollmentAudit) (*models.DMSEnrollmentAudit, error) {
+	return db.querier.Insert(ctx, audit, audit.ID)
+}
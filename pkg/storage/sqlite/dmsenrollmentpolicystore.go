@@ -0,0 +1,51 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsEnrollmentPoliciesDBName = "dms_enrollment_policies"
+
+type SQLiteDMSEnrollmentPoliciesStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DMSEnrollmentPolicy]
+}
+
+func NewDMSEnrollmentPoliciesRepository(db *gorm.DB) (storage.DMSEnrollmentPoliciesRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsEnrollmentPoliciesDBName, "id", models.DMSEnrollmentPolicy{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDMSEnrollmentPoliciesStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDMSEnrollmentPoliciesStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentPolicy]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDMSEnrollmentPoliciesStore) SelectExists(ctx context.Context, id string) (bool, *models.DMSEnrollmentPolicy, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteDMSEnrollmentPoliciesStore) Update(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Update(ctx, policy, policy.ID)
+}
+
+func (db *SQLiteDMSEnrollmentPoliciesStore) Insert(ctx context.Context, policy *models.DMSEnrollmentPolicy) (*models.DMSEnrollmentPolicy, error) {
+	return db.querier.Insert(ctx, policy, policy.ID)
+}
+
+func (db *SQLiteDMSEnrollmentPoliciesStore) Delete(ctx context.Context, id string) error {
+	return db.querier.Delete(ctx, id)
+}
@@ -0,0 +1,47 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const dmsEnrollmentStatsDBName = "dms_enrollment_stats"
+
+type SQLiteDMSEnrollmentStatsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.DMSEnrollmentStats]
+}
+
+func NewDMSEnrollmentStatsRepository(db *gorm.DB) (storage.DMSEnrollmentStatsRepo, error) {
+	querier, err := CheckAndCreateTable(db, dmsEnrollmentStatsDBName, "dms_id", models.DMSEnrollmentStats{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteDMSEnrollmentStatsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteDMSEnrollmentStatsStore) SelectAll(ctx context.Context, req storage.StorageListRequest[models.DMSEnrollmentStats]) (string, error) {
+	return db.querier.SelectAll(ctx, req.QueryParams, []gormWhereParams{}, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteDMSEnrollmentStatsStore) SelectExists(ctx context.Context, dmsID string) (bool, *models.DMSEnrollmentStats, error) {
+	return db.querier.SelectExists(ctx, dmsID, nil)
+}
+
+func (db *SQLiteDMSEnrollmentStatsStore) Update(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Update(ctx, stats, stats.DMSID)
+}
+
+func (db *SQLiteDMSEnrollmentStatsStore) Insert(ctx context.Context, stats *models.DMSEnrollmentStats) (*models.DMSEnrollmentStats, error) {
+	return db.querier.Insert(ctx, stats, stats.DMSID)
+}
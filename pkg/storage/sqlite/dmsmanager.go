@@ -45,6 +45,10 @@ func (db *SQLiteDMSManagerStore) Update(ctx context.Context, DMS *models.DMS) (*
 	return db.querier.Update(ctx, DMS, DMS.ID)
 }
 
+func (db *SQLiteDMSManagerStore) UpdateIf(ctx context.Context, DMS *models.DMS, precondition func(current *models.DMS) bool) (*models.DMS, error) {
+	return db.querier.UpdateIf(ctx, DMS, DMS.ID, precondition)
+}
+
 func (db *SQLiteDMSManagerStore) Insert(ctx context.Context, DMS *models.DMS) (*models.DMS, error) {
 	return db.querier.Insert(ctx, DMS, DMS.ID)
 }
@@ -22,6 +22,10 @@ const (
 	DEVICE_DB_NAME = "devicemanager"
 	DMS_DB_NAME    = "dmsmanager"
 	ALERTS_DB_NAME = "alerts"
+	// CLOUD_PROXY_DB_NAME holds the state of the cloud connectors.
+	CLOUD_PROXY_DB_NAME = "cloudproxy"
+	// EVENTBUS_DB_NAME holds the events handled by the event bus consumers.
+	EVENTBUS_DB_NAME = "eventbus"
 )
 
 type SQLiteStorageEngine struct {
@@ -67,6 +71,41 @@ func (s *SQLiteStorageEngine) initialiceCACertStorage() error {
 			return err
 		}
 	}
+
+	if s.IssuanceLog == nil {
+		s.IssuanceLog, err = NewIssuanceLogRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CAEvents == nil {
+		s.CAEvents, err = NewCAEventsRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CASigningRequests == nil {
+		s.CASigningRequests, err = NewCASigningRequestsRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CAPendingActions == nil {
+		s.CAPendingActions, err = NewCAPendingActionsRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.CertificateProfiles == nil {
+		s.CertificateProfiles, err = NewCertificateProfileRepository(psqlCli)
+		if err != nil {
+			return err
+		}
+	}
 	return nil
 }
 
@@ -81,6 +120,61 @@ func (s *SQLiteStorageEngine) GetCertstorage() (storage.CertificatesRepo, error)
 	return s.Cert, nil
 }
 
+func (s *SQLiteStorageEngine) GetCertificateProfileStorage() (storage.CertificateProfilesRepo, error) {
+	if s.CertificateProfiles == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CertificateProfiles, nil
+}
+
+func (s *SQLiteStorageEngine) GetIssuanceLogStorage() (storage.IssuanceLogRepo, error) {
+	if s.IssuanceLog == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.IssuanceLog, nil
+}
+
+func (s *SQLiteStorageEngine) GetCAEventsStorage() (storage.CAEventsRepo, error) {
+	if s.CAEvents == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CAEvents, nil
+}
+
+func (s *SQLiteStorageEngine) GetCASigningRequestsStorage() (storage.CASigningRequestsRepo, error) {
+	if s.CASigningRequests == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CASigningRequests, nil
+}
+
+func (s *SQLiteStorageEngine) GetCAPendingActionsStorage() (storage.CAPendingActionsRepo, error) {
+	if s.CAPendingActions == nil {
+		err := s.initialiceCACertStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite CA and Cert clients: %s", err)
+		}
+	}
+
+	return s.CAPendingActions, nil
+}
+
 func (s *SQLiteStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, error) {
 
 	if s.Device == nil {
@@ -98,6 +192,54 @@ func (s *SQLiteStorageEngine) GetDeviceStorage() (storage.DeviceManagerRepo, err
 	return s.Device, nil
 }
 
+func (s *SQLiteStorageEngine) GetDeviceGroupsStorage() (storage.DeviceGroupsRepo, error) {
+	if s.DeviceGroups == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DEVICE_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		groupStore, err := NewDeviceGroupsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite Device Groups client: %s", err)
+		}
+		s.DeviceGroups = groupStore
+	}
+	return s.DeviceGroups, nil
+}
+
+func (s *SQLiteStorageEngine) GetDeviceGroupBulkActionsStorage() (storage.DeviceGroupBulkActionsRepo, error) {
+	if s.DeviceBulkActions == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DEVICE_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		actionStore, err := NewDeviceGroupBulkActionsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite Device Group Bulk Actions client: %s", err)
+		}
+		s.DeviceBulkActions = actionStore
+	}
+	return s.DeviceBulkActions, nil
+}
+
+func (s *SQLiteStorageEngine) GetDeviceLogsStorage() (storage.DeviceLogsRepo, error) {
+	if s.DeviceLogs == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DEVICE_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		logStore, err := NewDeviceLogsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite Device Logs client: %s", err)
+		}
+		s.DeviceLogs = logStore
+	}
+	return s.DeviceLogs, nil
+}
+
 func (s *SQLiteStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
 	if s.DMS == nil {
 		psqlCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
@@ -114,6 +256,163 @@ func (s *SQLiteStorageEngine) GetDMSStorage() (storage.DMSRepo, error) {
 	return s.DMS, nil
 }
 
+func (s *SQLiteStorageEngine) GetDMSEnrollmentStatsStorage() (storage.DMSEnrollmentStatsRepo, error) {
+	if s.DMSEnrollmentStats == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		statsStore, err := NewDMSEnrollmentStatsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite DMS Enrollment Stats client: %s", err)
+		}
+		s.DMSEnrollmentStats = statsStore
+	}
+	return s.DMSEnrollmentStats, nil
+}
+
+func (s *SQLiteStorageEngine) GetConnectorPendingEventsStorage() (storage.ConnectorPendingEventsRepo, error) {
+	if s.ConnectorEvents == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, CLOUD_PROXY_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		eventsStore, err := NewConnectorPendingEventsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite Connector Pending Events client: %s", err)
+		}
+		s.ConnectorEvents = eventsStore
+	}
+	return s.ConnectorEvents, nil
+}
+
+func (s *SQLiteStorageEngine) GetProcessedEventsStorage() (storage.ProcessedEventsRepo, error) {
+	if s.ProcessedEvents == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, EVENTBUS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		eventsStore, err := NewProcessedEventsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite Processed Events client: %s", err)
+		}
+		s.ProcessedEvents = eventsStore
+	}
+	return s.ProcessedEvents, nil
+}
+
+func (s *SQLiteStorageEngine) GetDMSEnrollmentPoliciesStorage() (storage.DMSEnrollmentPoliciesRepo, error) {
+	if s.DMSEnrollmentPolicy == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		policyStore, err := NewDMSEnrollmentPoliciesRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite DMS Enrollment Policies client: %s", err)
+		}
+		s.DMSEnrollmentPolicy = policyStore
+	}
+	return s.DMSEnrollmentPolicy, nil
+}
+
+func (s *SQLiteStorageEngine) GetDMSBootstrapTokensStorage() (storage.DMSBootstrapTokensRepo, error) {
+	if s.DMSBootstrapTokens == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		tokenStore, err := NewDMSBootstrapTokensRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite DMS Bootstrap Tokens client: %s", err)
+		}
+		s.DMSBootstrapTokens = tokenStore
+	}
+	return s.DMSBootstrapTokens, nil
+}
+
+func (s *SQLiteStorageEngine) GetDMSClaimCodesStorage() (storage.DMSClaimCodesRepo, error) {
+	if s.DMSClaimCodes == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		codeStore, err := NewDMSClaimCodesRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite DMS Claim Codes client: %s", err)
+		}
+		s.DMSClaimCodes = codeStore
+	}
+	return s.DMSClaimCodes, nil
+}
+
+func (s *SQLiteStorageEngine) GetDMSEnrollmentAuditsStorage() (storage.DMSEnrollmentAuditsRepo, error) {
+	if s.DMSEnrollmentAudits == nil {
+		dbCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
+		if err != nil {
+			return nil, fmt.Errorf("could not create sqlite client: %s", err)
+		}
+
+		auditStore, err := NewDMSEnrollmentAuditsRepository(dbCli)
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite DMS Enrollment Audits client: %s", err)
+		}
+		s.DMSEnrollmentAudits = auditStore
+	}
+	return s.DMSEnrollmentAudits, nil
+}
+
+func (s *SQLiteStorageEngine) GetACMEAccountStorage() (storage.ACMEAccountsRepo, error) {
+	if s.ACMEAccounts == nil {
+		err := s.initialiceACMEStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite ACME clients: %s", err)
+		}
+	}
+
+	return s.ACMEAccounts, nil
+}
+
+func (s *SQLiteStorageEngine) GetACMEOrderStorage() (storage.ACMEOrdersRepo, error) {
+	if s.ACMEOrders == nil {
+		err := s.initialiceACMEStorage()
+		if err != nil {
+			return nil, fmt.Errorf("could not initialize sqlite ACME clients: %s", err)
+		}
+	}
+
+	return s.ACMEOrders, nil
+}
+
+func (s *SQLiteStorageEngine) initialiceACMEStorage() error {
+	dbCli, err := CreateDBConnection(s.logger, s.Config, DMS_DB_NAME)
+	if err != nil {
+		return err
+	}
+
+	if s.ACMEAccounts == nil {
+		s.ACMEAccounts, err = NewACMEAccountRepository(dbCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	if s.ACMEOrders == nil {
+		s.ACMEOrders, err = NewACMEOrderRepository(dbCli)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
 func (s *SQLiteStorageEngine) GetEnventsStorage() (storage.EventRepository, error) {
 	if s.Events == nil {
 		s.initialiceSubscriptionsStorage()
@@ -0,0 +1,52 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const issuanceLogDBName = "issuance_log"
+
+type SQLiteIssuanceLogStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.IssuanceLogEntry]
+}
+
+func NewIssuanceLogRepository(db *gorm.DB) (storage.IssuanceLogRepo, error) {
+	querier, err := CheckAndCreateTable(db, issuanceLogDBName, "id", models.IssuanceLogEntry{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteIssuanceLogStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteIssuanceLogStore) CountByCA(ctx context.Context, caID string) (int, error) {
+	return db.querier.Count(ctx, []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	})
+}
+
+func (db *SQLiteIssuanceLogStore) SelectByCA(ctx context.Context, caID string, req storage.StorageListRequest[models.IssuanceLogEntry]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "ca_id = ?", extraArgs: []any{caID}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteIssuanceLogStore) SelectExists(ctx context.Context, id string) (bool, *models.IssuanceLogEntry, error) {
+	return db.querier.SelectExists(ctx, id, nil)
+}
+
+func (db *SQLiteIssuanceLogStore) Insert(ctx context.Context, entry *models.IssuanceLogEntry) (*models.IssuanceLogEntry, error) {
+	return db.querier.Insert(ctx, entry, entry.ID)
+}
@@ -0,0 +1,59 @@
+//go:build experimental
+// +build experimental
+
+package sqlite
+
+import (
+	"context"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
+	"gorm.io/gorm"
+)
+
+const processedEventsDBName = "processed_events"
+
+type SQLiteProcessedEventsStore struct {
+	db      *gorm.DB
+	querier *sqliteDBQuerier[models.ProcessedEvent]
+}
+
+func NewProcessedEventsRepository(db *gorm.DB) (storage.ProcessedEventsRepo, error) {
+	querier, err := CheckAndCreateTable(db, processedEventsDBName, "key", models.ProcessedEvent{})
+	if err != nil {
+		return nil, err
+	}
+
+	return &SQLiteProcessedEventsStore{
+		db:      db,
+		querier: querier,
+	}, nil
+}
+
+func (db *SQLiteProcessedEventsStore) SelectExists(ctx context.Context, key string) (bool, *models.ProcessedEvent, error) {
+	return db.querier.SelectExists(ctx, key, nil)
+}
+
+func (db *SQLiteProcessedEventsStore) SelectExpired(ctx context.Context, before time.Time, req storage.StorageListRequest[models.ProcessedEvent]) (string, error) {
+	opts := []gormWhereParams{
+		{query: "expires_at < ?", extraArgs: []any{before}},
+	}
+	return db.querier.SelectAll(ctx, req.QueryParams, opts, req.ExhaustiveRun, req.ApplyFunc)
+}
+
+func (db *SQLiteProcessedEventsStore) Insert(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Insert(ctx, event, event.Key)
+}
+
+func (db *SQLiteProcessedEventsStore) Update(ctx context.Context, event *models.ProcessedEvent) (*models.ProcessedEvent, error) {
+	return db.querier.Update(ctx, event, event.Key)
+}
+
+func (db *SQLiteProcessedEventsStore) UpdateIf(ctx context.Context, event *models.ProcessedEvent, precondition func(current *models.ProcessedEvent) bool) (*models.ProcessedEvent, error) {
+	return db.querier.UpdateIf(ctx, event, event.Key, precondition)
+}
+
+func (db *SQLiteProcessedEventsStore) Delete(ctx context.Context, key string) error {
+	return db.querier.Delete(ctx, key)
+}
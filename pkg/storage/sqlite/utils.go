@@ -17,6 +17,7 @@ import (
 	"github.com/lamassuiot/lamassuiot/v2/pkg/config"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/storage"
 	"github.com/sirupsen/logrus"
 	"gorm.io/driver/sqlite"
 	"gorm.io/gorm"
@@ -325,6 +326,38 @@ func (db *sqliteDBQuerier[E]) Update(ctx context.Context, elem *E, elemID string
 	return elem, nil
 }
 
+// UpdateIf updates the element only if precondition holds for the stored one. The precondition is evaluated in the
+// same transaction as the update, and SQLite serializes the write transactions.
+func (db *sqliteDBQuerier[E]) UpdateIf(ctx context.Context, elem *E, elemID string, precondition func(current *E) bool) (*E, error) {
+	err := db.WithContext(ctx).Transaction(func(tx *gorm.DB) error {
+		var current E
+		res := tx.Table(db.tableName).First(&current, fmt.Sprintf("%s = ?", db.primaryKeyColumn), elemID)
+		if err := res.Error; err != nil {
+			return err
+		}
+
+		if !precondition(&current) {
+			return storage.ErrUpdateConflict
+		}
+
+		res = tx.Table(db.tableName).Where(fmt.Sprintf("%s = ?", db.primaryKeyColumn), elemID).Updates(elem)
+		if err := res.Error; err != nil {
+			return err
+		}
+
+		if res.RowsAffected != 1 {
+			return gorm.ErrRecordNotFound
+		}
+
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return elem, nil
+}
+
 func (db *sqliteDBQuerier[E]) Delete(ctx context.Context, elemID string) error {
 	tx := db.Table(db.tableName).WithContext(ctx).Delete(nil, db.Where(fmt.Sprintf("%s = ?", db.primaryKeyColumn), elemID))
 	if err := tx.Error; err != nil {
@@ -1,9 +1,15 @@
 package storage
 
 import (
+	"errors"
+
 	"github.com/lamassuiot/lamassuiot/v2/pkg/resources"
 )
 
+// ErrUpdateConflict is returned by the conditional updates when the stored element does not satisfy the precondition
+// of the update, i.e. because it was modified by another writer since it was read.
+var ErrUpdateConflict = errors.New("stored element does not satisfy the update precondition")
+
 type StorageListRequest[E any] struct {
 	ExhaustiveRun bool
 	ApplyFunc     func(E)
@@ -49,7 +49,7 @@ func TestUseCase1(t *testing.T) {
 		}
 	}
 
-	pCleanup, storageConfig, err := postgres_test.RunPostgresDocker([]string{"ca", "alerts", "dmsmanager", "devicemanager", "cloudproxy"})
+	pCleanup, storageConfig, err := postgres_test.RunPostgresDocker([]string{"ca", "alerts", "dmsmanager", "devicemanager", "cloudproxy", "eventbus"})
 	if err != nil {
 		log.Fatalf("could not launch Postgres: %s", err)
 	}
@@ -22,257 +22,392 @@ import (
 	log "github.com/sirupsen/logrus"
 )
 
+// gatewayUpstream is a service HTTP server exposed by the gateway under a path prefix.
+type gatewayUpstream struct {
+	name string
+	path string
+	port int
+}
+
 func RunMonolithicLamassuPKI(conf config.MonolithicConfig) (int, error) {
-	log.SetLevel(log.PanicLevel)
-	if conf.AssemblyMode == config.Http {
-		apiInfo := models.APIServiceInfo{
-			Version:   "-",
-			BuildSHA:  "-",
-			BuildTime: "-",
-		}
+	apiInfo := models.APIServiceInfo{
+		Version:   "-",
+		BuildSHA:  "-",
+		BuildTime: "-",
+	}
 
-		key, _ := helpers.GenerateRSAKey(2048)
-		keyPem, _ := helpers.PrivateKeyToPEM(key)
-		os.WriteFile("proxy.key", []byte(keyPem), 0600)
+	return RunMonolithicLamassuPKIWithInfo(conf, apiInfo)
+}
 
-		crt, err := helpers.GenerateSelfSignedCertificate(key, "proxy-lms-test")
-		if err != nil {
-			panic(fmt.Sprintf("could not create self signed cert: %s", err))
-		}
+// RunMonolithicLamassuPKIWithInfo runs the CA, VA, Device Manager, DMS Manager and Alerts services in the current
+// process behind a single TLS gateway listening on conf.GatewayPort. It returns the port used by the gateway.
+func RunMonolithicLamassuPKIWithInfo(conf config.MonolithicConfig, apiInfo models.APIServiceInfo) (int, error) {
+	key, _ := helpers.GenerateRSAKey(2048)
+	keyPem, _ := helpers.PrivateKeyToPEM(key)
+	os.WriteFile("proxy.key", []byte(keyPem), 0600)
 
-		crtPem := helpers.CertificateToPEM(crt)
-		os.WriteFile("proxy.crt", []byte(crtPem), 0600)
+	crt, err := helpers.GenerateSelfSignedCertificate(key, "proxy-lms-test")
+	if err != nil {
+		panic(fmt.Sprintf("could not create self signed cert: %s", err))
+	}
 
-		_, _, caPort, err := lamassu.AssembleCAServiceWithHTTPServer(config.CAConfig{
-			Logs: config.BaseConfigLogging{
-				Level: conf.Logs.Level,
-			},
-			Server: config.HttpServer{
-				LogLevel:           conf.Logs.Level,
-				HealthCheckLogging: true,
-				ListenAddress:      "0.0.0.0",
-				Port:               0,
-				Protocol:           config.HTTP,
-			},
-			PublisherEventBus: conf.PublisherEventBus,
-			Storage:           conf.Storage,
-			CryptoEngines:     conf.CryptoEngines,
-			CryptoMonitoring:  conf.CryptoMonitoring,
-			VAServerDomain:    fmt.Sprintf("%s/api/va", conf.Domain),
-		}, apiInfo)
-		if err != nil {
-			return -1, fmt.Errorf("could not assemble CA Service: %s", err)
-		}
+	crtPem := helpers.CertificateToPEM(crt)
+	os.WriteFile("proxy.crt", []byte(crtPem), 0600)
+
+	var upstreams []gatewayUpstream
+	switch conf.AssemblyMode {
+	case config.Http:
+		log.SetLevel(log.PanicLevel)
+		upstreams, err = assembleHttpServices(conf, apiInfo)
+	case config.InMemory:
+		upstreams, err = assembleInMemoryServices(conf, apiInfo)
+	default:
+		return -1, fmt.Errorf("unsupported mode")
+	}
+	if err != nil {
+		return -1, err
+	}
 
-		caConnection := config.HTTPConnection{BasicConnection: config.BasicConnection{Hostname: "127.0.0.1", Port: caPort}, Protocol: config.HTTP, BasePath: ""}
-		caSDKBuilder := func(serviceID, src string) services.CAService {
-			lCAClient := helpers.SetupLogger(config.Info, serviceID, "LMS SDK - CA Client")
-			caHttpCli, err := clients.BuildHTTPClient(config.HTTPClient{
-				LogLevel:       config.Info,
-				AuthMode:       config.NoAuth,
-				HTTPConnection: caConnection,
-			}, lCAClient)
-			if err != nil {
-				log.Fatalf("could not build HTTP CA Client: %s", err)
-			}
+	return runGateway(conf, upstreams)
+}
 
-			return clients.NewHttpCAClient(
-				clients.HttpClientWithSourceHeaderInjector(caHttpCli, src),
-				fmt.Sprintf("%s://%s%s:%d", caConnection.Protocol, caConnection.Hostname, caConnection.BasePath, caConnection.Port),
-			)
-		}
+// monolithicServer is the HTTP server of a service running in the monolith. Services listen on a random port and are
+// reached through the gateway.
+func monolithicServer(conf config.MonolithicConfig, listenAddress string) config.HttpServer {
+	return config.HttpServer{
+		LogLevel:           conf.Logs.Level,
+		HealthCheckLogging: true,
+		ListenAddress:      listenAddress,
+		Port:               0,
+		Protocol:           config.HTTP,
+	}
+}
 
-		_, _, vaPort, err := lamassu.AssembleVAServiceWithHTTPServer(config.VAconfig{
-			Logs: config.BaseConfigLogging{
-				Level: conf.Logs.Level,
-			},
-			Server: config.HttpServer{
-				LogLevel:           conf.Logs.Level,
-				HealthCheckLogging: true,
-				ListenAddress:      "0.0.0.0",
-				Port:               0,
-				Protocol:           config.HTTP,
-			},
-		}, caSDKBuilder("VA", models.VASource), apiInfo)
-		if err != nil {
-			return -1, fmt.Errorf("could not assemble VA Service: %s", err)
-		}
+// assembleInMemoryServices wires the services with each other's business logic, so requests between services do not
+// go through HTTP.
+func assembleInMemoryServices(conf config.MonolithicConfig, apiInfo models.APIServiceInfo) ([]gatewayUpstream, error) {
+	caSvc, _, caPort, err := lamassu.AssembleCAServiceWithHTTPServer(config.CAConfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server:            monolithicServer(conf, "127.0.0.1"),
+		PublisherEventBus: conf.PublisherEventBus,
+		Storage:           conf.Storage,
+		CryptoEngines:     conf.CryptoEngines,
+		CryptoMonitoring:  conf.CryptoMonitoring,
+		VAServerDomain:    fmt.Sprintf("%s/api/va", conf.Domain),
+	}, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble CA Service: %s", err)
+	}
 
-		_, devPort, err := lamassu.AssembleDeviceManagerServiceWithHTTPServer(config.DeviceManagerConfig{
+	_, _, vaPort, err := lamassu.AssembleVAServiceWithHTTPServer(config.VAconfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server: monolithicServer(conf, "127.0.0.1"),
+	}, *caSvc, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble VA Service: %s", err)
+	}
+
+	devSvc, devPort, err := lamassu.AssembleDeviceManagerServiceWithHTTPServer(config.DeviceManagerConfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server:             monolithicServer(conf, "127.0.0.1"),
+		PublisherEventBus:  conf.PublisherEventBus,
+		SubscriberEventBus: conf.SubscriberEventBus,
+		Storage:            conf.Storage,
+	}, *caSvc, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble Device Manager Service: %s", err)
+	}
+
+	dmsSvc, dmsPort, err := lamassu.AssembleDMSManagerServiceWithHTTPServer(config.DMSconfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server:                    monolithicServer(conf, "127.0.0.1"),
+		PublisherEventBus:         conf.PublisherEventBus,
+		DownstreamCertificateFile: "proxy.crt",
+		Storage:                   conf.Storage,
+	}, *caSvc, *devSvc, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble DMS Manager Service: %s", err)
+	}
+
+	_, alertsPort, err := lamassu.AssembleAlertsServiceWithHTTPServer(config.AlertsConfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server:             monolithicServer(conf, "127.0.0.1"),
+		SubscriberEventBus: conf.SubscriberEventBus,
+		Storage:            conf.Storage,
+	}, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble Alerts Service: %s", err)
+	}
+
+	if conf.AWSIoTManager.Enabled {
+		_, err = lamassu.AssembleAWSIoTManagerService(config.IotAWS{
 			Logs: config.BaseConfigLogging{
 				Level: conf.Logs.Level,
 			},
-			Server: config.HttpServer{
-				LogLevel:           conf.Logs.Level,
-				HealthCheckLogging: true,
-				ListenAddress:      "0.0.0.0",
-				Port:               0,
-				Protocol:           config.HTTP,
-			},
-			PublisherEventBus:  conf.PublisherEventBus,
 			SubscriberEventBus: conf.SubscriberEventBus,
-			Storage:            conf.Storage,
-		}, caSDKBuilder("Device Manager", models.DeviceManagerSource), apiInfo)
+			ConnectorID:        conf.AWSIoTManager.ConnectorID,
+			AWSSDKConfig:       conf.AWSIoTManager.AWSSDKConfig,
+		}, *caSvc, *dmsSvc, *devSvc)
 		if err != nil {
-			return -1, fmt.Errorf("could not assemble Device Manager Service: %s", err)
+			return nil, fmt.Errorf("could not assemble AWS IoT Manager: %s", err)
 		}
+	}
 
-		devMngrConnection := config.HTTPConnection{BasicConnection: config.BasicConnection{Hostname: "127.0.0.1", Port: devPort}, Protocol: config.HTTP, BasePath: ""}
+	return []gatewayUpstream{
+		{name: "CA", path: "/api/ca/", port: caPort},
+		{name: "Dev Manager", path: "/api/devmanager/", port: devPort},
+		{name: "DMS Manager", path: "/api/dmsmanager/", port: dmsPort},
+		{name: "VA", path: "/api/va/", port: vaPort},
+		{name: "Alerts", path: "/api/alerts/", port: alertsPort},
+	}, nil
+}
 
-		deviceMngrSDKBuilder := func(serviceID, src string) services.DeviceManagerService {
-			lDevMngrClient := helpers.SetupLogger(config.Info, serviceID, "LMS SDK - DevManager Client")
-			devMngrHttpCli, err := clients.BuildHTTPClient(config.HTTPClient{
-				LogLevel:       config.Info,
-				AuthMode:       config.NoAuth,
-				HTTPConnection: devMngrConnection,
-			}, lDevMngrClient)
-			if err != nil {
-				log.Fatalf("could not build HTTP DevManager Client: %s", err)
-			}
+// assembleHttpServices runs each service as a separate HTTP server. Services reach each other through HTTP clients.
+func assembleHttpServices(conf config.MonolithicConfig, apiInfo models.APIServiceInfo) ([]gatewayUpstream, error) {
+	_, _, caPort, err := lamassu.AssembleCAServiceWithHTTPServer(config.CAConfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server: config.HttpServer{
+			LogLevel:           conf.Logs.Level,
+			HealthCheckLogging: true,
+			ListenAddress:      "0.0.0.0",
+			Port:               0,
+			Protocol:           config.HTTP,
+		},
+		PublisherEventBus: conf.PublisherEventBus,
+		Storage:           conf.Storage,
+		CryptoEngines:     conf.CryptoEngines,
+		CryptoMonitoring:  conf.CryptoMonitoring,
+		VAServerDomain:    fmt.Sprintf("%s/api/va", conf.Domain),
+	}, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble CA Service: %s", err)
+	}
 
-			return clients.NewHttpDeviceManagerClient(
-				clients.HttpClientWithSourceHeaderInjector(devMngrHttpCli, src),
-				fmt.Sprintf("%s://%s%s:%d", devMngrConnection.Protocol, devMngrConnection.Hostname, devMngrConnection.BasePath, devMngrConnection.Port),
-			)
+	caConnection := config.HTTPConnection{BasicConnection: config.BasicConnection{Hostname: "127.0.0.1", Port: caPort}, Protocol: config.HTTP, BasePath: ""}
+	caSDKBuilder := func(serviceID, src string) services.CAService {
+		lCAClient := helpers.SetupLogger(config.Info, serviceID, "LMS SDK - CA Client")
+		caHttpCli, err := clients.BuildHTTPClient(config.HTTPClient{
+			LogLevel:       config.Info,
+			AuthMode:       config.NoAuth,
+			HTTPConnection: caConnection,
+		}, lCAClient)
+		if err != nil {
+			log.Fatalf("could not build HTTP CA Client: %s", err)
 		}
-		_, dmsPort, err := lamassu.AssembleDMSManagerServiceWithHTTPServer(config.DMSconfig{
-			Logs: config.BaseConfigLogging{
-				Level: conf.Logs.Level,
-			},
-			Server: config.HttpServer{
-				LogLevel:           conf.Logs.Level,
-				HealthCheckLogging: true,
-				ListenAddress:      "0.0.0.0",
-				Port:               0,
-				Protocol:           config.HTTP,
-			},
-			PublisherEventBus:         conf.PublisherEventBus,
-			DownstreamCertificateFile: "proxy.crt",
-			Storage:                   conf.Storage,
-		}, caSDKBuilder("DMS Manager", models.DMSManagerSource), deviceMngrSDKBuilder("DMS Manager", models.DMSManagerSource), apiInfo)
+
+		return clients.NewHttpCAClient(
+			clients.HttpClientWithSourceHeaderInjector(caHttpCli, src),
+			fmt.Sprintf("%s://%s%s:%d", caConnection.Protocol, caConnection.Hostname, caConnection.BasePath, caConnection.Port),
+		)
+	}
+
+	_, _, vaPort, err := lamassu.AssembleVAServiceWithHTTPServer(config.VAconfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server: config.HttpServer{
+			LogLevel:           conf.Logs.Level,
+			HealthCheckLogging: true,
+			ListenAddress:      "0.0.0.0",
+			Port:               0,
+			Protocol:           config.HTTP,
+		},
+	}, caSDKBuilder("VA", models.VASource), apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble VA Service: %s", err)
+	}
+
+	_, devPort, err := lamassu.AssembleDeviceManagerServiceWithHTTPServer(config.DeviceManagerConfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server: config.HttpServer{
+			LogLevel:           conf.Logs.Level,
+			HealthCheckLogging: true,
+			ListenAddress:      "0.0.0.0",
+			Port:               0,
+			Protocol:           config.HTTP,
+		},
+		PublisherEventBus:  conf.PublisherEventBus,
+		SubscriberEventBus: conf.SubscriberEventBus,
+		Storage:            conf.Storage,
+	}, caSDKBuilder("Device Manager", models.DeviceManagerSource), apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble Device Manager Service: %s", err)
+	}
+
+	devMngrConnection := config.HTTPConnection{BasicConnection: config.BasicConnection{Hostname: "127.0.0.1", Port: devPort}, Protocol: config.HTTP, BasePath: ""}
+
+	deviceMngrSDKBuilder := func(serviceID, src string) services.DeviceManagerService {
+		lDevMngrClient := helpers.SetupLogger(config.Info, serviceID, "LMS SDK - DevManager Client")
+		devMngrHttpCli, err := clients.BuildHTTPClient(config.HTTPClient{
+			LogLevel:       config.Info,
+			AuthMode:       config.NoAuth,
+			HTTPConnection: devMngrConnection,
+		}, lDevMngrClient)
 		if err != nil {
-			return -1, fmt.Errorf("could not assemble DMS Manager Service: %s", err)
+			log.Fatalf("could not build HTTP DevManager Client: %s", err)
 		}
 
-		dmsMngrConnection := config.HTTPConnection{BasicConnection: config.BasicConnection{Hostname: "127.0.0.1", Port: dmsPort}, Protocol: config.HTTP, BasePath: ""}
+		return clients.NewHttpDeviceManagerClient(
+			clients.HttpClientWithSourceHeaderInjector(devMngrHttpCli, src),
+			fmt.Sprintf("%s://%s%s:%d", devMngrConnection.Protocol, devMngrConnection.Hostname, devMngrConnection.BasePath, devMngrConnection.Port),
+		)
+	}
+	_, dmsPort, err := lamassu.AssembleDMSManagerServiceWithHTTPServer(config.DMSconfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server: config.HttpServer{
+			LogLevel:           conf.Logs.Level,
+			HealthCheckLogging: true,
+			ListenAddress:      "0.0.0.0",
+			Port:               0,
+			Protocol:           config.HTTP,
+		},
+		PublisherEventBus:         conf.PublisherEventBus,
+		DownstreamCertificateFile: "proxy.crt",
+		Storage:                   conf.Storage,
+	}, caSDKBuilder("DMS Manager", models.DMSManagerSource), deviceMngrSDKBuilder("DMS Manager", models.DMSManagerSource), apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble DMS Manager Service: %s", err)
+	}
 
-		dmsMngrSDKBuilder := func(serviceID, src string) services.DMSManagerService {
-			lDMSMngrClient := helpers.SetupLogger(config.Info, serviceID, "LMS SDK - DMSManager Client")
-			dmsMngrHttpCli, err := clients.BuildHTTPClient(config.HTTPClient{
-				LogLevel:       config.Info,
-				AuthMode:       config.NoAuth,
-				HTTPConnection: dmsMngrConnection,
-			}, lDMSMngrClient)
-			if err != nil {
-				log.Fatalf("could not build HTTP DMSManager Client: %s", err)
-			}
+	dmsMngrConnection := config.HTTPConnection{BasicConnection: config.BasicConnection{Hostname: "127.0.0.1", Port: dmsPort}, Protocol: config.HTTP, BasePath: ""}
 
-			return clients.NewHttpDMSManagerClient(
-				clients.HttpClientWithSourceHeaderInjector(dmsMngrHttpCli, src),
-				fmt.Sprintf("%s://%s%s:%d", dmsMngrConnection.Protocol, dmsMngrConnection.Hostname, dmsMngrConnection.BasePath, dmsMngrConnection.Port),
-			)
+	dmsMngrSDKBuilder := func(serviceID, src string) services.DMSManagerService {
+		lDMSMngrClient := helpers.SetupLogger(config.Info, serviceID, "LMS SDK - DMSManager Client")
+		dmsMngrHttpCli, err := clients.BuildHTTPClient(config.HTTPClient{
+			LogLevel:       config.Info,
+			AuthMode:       config.NoAuth,
+			HTTPConnection: dmsMngrConnection,
+		}, lDMSMngrClient)
+		if err != nil {
+			log.Fatalf("could not build HTTP DMSManager Client: %s", err)
 		}
-		_, alertsPort, err := lamassu.AssembleAlertsServiceWithHTTPServer(config.AlertsConfig{
+
+		return clients.NewHttpDMSManagerClient(
+			clients.HttpClientWithSourceHeaderInjector(dmsMngrHttpCli, src),
+			fmt.Sprintf("%s://%s%s:%d", dmsMngrConnection.Protocol, dmsMngrConnection.Hostname, dmsMngrConnection.BasePath, dmsMngrConnection.Port),
+		)
+	}
+	_, alertsPort, err := lamassu.AssembleAlertsServiceWithHTTPServer(config.AlertsConfig{
+		Logs: config.BaseConfigLogging{
+			Level: conf.Logs.Level,
+		},
+		Server: config.HttpServer{
+			LogLevel:           conf.Logs.Level,
+			HealthCheckLogging: true,
+			ListenAddress:      "0.0.0.0",
+			Port:               0,
+			Protocol:           config.HTTP,
+		},
+		SubscriberEventBus: conf.SubscriberEventBus,
+		Storage:            conf.Storage,
+	}, apiInfo)
+	if err != nil {
+		return nil, fmt.Errorf("could not assemble Alerts Service: %s", err)
+	}
+
+	if conf.AWSIoTManager.Enabled {
+		_, err = lamassu.AssembleAWSIoTManagerService(config.IotAWS{
 			Logs: config.BaseConfigLogging{
 				Level: conf.Logs.Level,
 			},
-			Server: config.HttpServer{
-				LogLevel:           conf.Logs.Level,
-				HealthCheckLogging: true,
-				ListenAddress:      "0.0.0.0",
-				Port:               0,
-				Protocol:           config.HTTP,
-			},
 			SubscriberEventBus: conf.SubscriberEventBus,
-			Storage:            conf.Storage,
-		}, apiInfo)
+			ConnectorID:        conf.AWSIoTManager.ConnectorID,
+			AWSSDKConfig:       conf.AWSIoTManager.AWSSDKConfig,
+		}, caSDKBuilder("AWS IoT Connector", models.AWSIoTSource(conf.AWSIoTManager.ConnectorID)), dmsMngrSDKBuilder("AWS IoT Connector", models.AWSIoTSource(conf.AWSIoTManager.ConnectorID)), deviceMngrSDKBuilder("AWS IoT Connector", models.AWSIoTSource(conf.AWSIoTManager.ConnectorID)))
 		if err != nil {
-			return -1, fmt.Errorf("could not assemble Alerts Service: %s", err)
+			return nil, fmt.Errorf("could not assemble AWS IoT Manager: %s", err)
 		}
+	}
+
+	return []gatewayUpstream{
+		{name: "CA", path: "/api/ca/", port: caPort},
+		{name: "Dev Manager", path: "/api/devmanager/", port: devPort},
+		{name: "DMS Manager", path: "/api/dmsmanager/", port: dmsPort},
+		{name: "VA", path: "/api/va/", port: vaPort},
+		{name: "Alerts", path: "/api/alerts/", port: alertsPort},
+	}, nil
+}
 
-		if conf.AWSIoTManager.Enabled {
-			_, err = lamassu.AssembleAWSIoTManagerService(config.IotAWS{
-				Logs: config.BaseConfigLogging{
-					Level: conf.Logs.Level,
-				},
-				SubscriberEventBus: conf.SubscriberEventBus,
-				ConnectorID:        conf.AWSIoTManager.ConnectorID,
-				AWSSDKConfig:       conf.AWSIoTManager.AWSSDKConfig,
-			}, caSDKBuilder("AWS IoT Connector", models.AWSIoTSource(conf.AWSIoTManager.ConnectorID)), dmsMngrSDKBuilder("AWS IoT Connector", models.AWSIoTSource(conf.AWSIoTManager.ConnectorID)), deviceMngrSDKBuilder("AWS IoT Connector", models.AWSIoTSource(conf.AWSIoTManager.ConnectorID)))
+// runGateway exposes the upstreams behind a single TLS listener, emulating the envoy proxy of a regular deployment.
+func runGateway(conf config.MonolithicConfig, upstreams []gatewayUpstream) (int, error) {
+	engine := gin.New()
+	engine.Use(gin.Recovery(), clientCertsToHeaderUsingEnvoyStyle())
+	buildReverseProxyHandler := func(engine *gin.Engine, serviceName, servicePath string, servicePort int) {
+		subpath := servicePath
+		subpath = strings.TrimSuffix(subpath, "/")
+
+		color.Set(color.BgCyan)
+		color.Set(color.FgWhite)
+		fmt.Printf("  0.0.0.0:%d%s*  --> %s 127.0.0.1:%d  ", conf.GatewayPort, servicePath, serviceName, servicePort)
+		color.Unset()
+		fmt.Printf("\n")
+
+		proxy := func(c *gin.Context) {
+			remote, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", servicePort))
 			if err != nil {
-				return -1, fmt.Errorf("could not assemble AWS IoT Manager: %s", err)
+				panic(err)
 			}
-		}
-
-		engine := gin.New()
-		engine.Use(gin.Recovery(), clientCertsToHeaderUsingEnvoyStyle())
-		buildReverseProxyHandler := func(engine *gin.Engine, serviceName, servicePath string, servicePort int) {
-			subpath := servicePath
-			subpath = strings.TrimSuffix(subpath, "/")
-
-			color.Set(color.BgCyan)
-			color.Set(color.FgWhite)
-			fmt.Printf("  0.0.0.0:%d%s*  --> %s 127.0.0.1:%d  ", conf.GatewayPort, servicePath, serviceName, servicePort)
-			color.Unset()
-			fmt.Printf("\n")
-
-			proxy := func(c *gin.Context) {
-				remote, err := url.Parse(fmt.Sprintf("http://127.0.0.1:%d", servicePort))
-				if err != nil {
-					panic(err)
-				}
-
-				//emulate envoy config by generating rand request id as HTTP header to the upstream service
-				c.Request.Header.Add("x-request-id", uuid.NewString())
-
-				proxy := httputil.NewSingleHostReverseProxy(remote)
-				//Define the director func
-				//This is a good place to log, for example
-				proxy.Director = func(req *http.Request) {
-					req.Header = c.Request.Header
-					req.Host = remote.Host
-					req.URL.Scheme = remote.Scheme
-					req.URL.Host = remote.Host
-					req.URL.Path = c.Param("proxyPath")
-				}
 
-				proxy.ServeHTTP(c.Writer, c.Request)
+			//emulate envoy config by generating rand request id as HTTP header to the upstream service
+			c.Request.Header.Add("x-request-id", uuid.NewString())
+
+			proxy := httputil.NewSingleHostReverseProxy(remote)
+			//Define the director func
+			//This is a good place to log, for example
+			proxy.Director = func(req *http.Request) {
+				req.Header = c.Request.Header
+				req.Host = remote.Host
+				req.URL.Scheme = remote.Scheme
+				req.URL.Host = remote.Host
+				req.URL.Path = c.Param("proxyPath")
 			}
 
-			engine.Any(fmt.Sprintf("%s/*proxyPath", subpath), proxy)
+			proxy.ServeHTTP(c.Writer, c.Request)
 		}
 
-		buildReverseProxyHandler(engine, "CA", "/api/ca/", caPort)
-		buildReverseProxyHandler(engine, "Dev Manager", "/api/devmanager/", devPort)
-		buildReverseProxyHandler(engine, "DMS Manager", "/api/dmsmanager/", dmsPort)
-		buildReverseProxyHandler(engine, "VA", "/api/va/", vaPort)
-		buildReverseProxyHandler(engine, "Alerts", "/api/alerts/", alertsPort)
-
-		listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", conf.GatewayPort))
-		if err != nil {
-			log.Fatalf("could not get Gateway net Listener: %s", err)
-		}
+		engine.Any(fmt.Sprintf("%s/*proxyPath", subpath), proxy)
+	}
 
-		usedPort := listener.Addr().(*net.TCPAddr).Port
+	for _, upstream := range upstreams {
+		buildReverseProxyHandler(engine, upstream.name, upstream.path, upstream.port)
+	}
 
-		go func() {
-			// log.Fatal(http.ListenAndServe(fmt.Sprintf(":%d", conf.GatewayPort), engine))
+	listener, err := net.Listen("tcp", fmt.Sprintf("0.0.0.0:%d", conf.GatewayPort))
+	if err != nil {
+		return -1, fmt.Errorf("could not get Gateway net Listener: %s", err)
+	}
 
-			server := http.Server{
-				Handler: engine,
-				Addr:    fmt.Sprintf(":%d", conf.GatewayPort),
-				TLSConfig: &tls.Config{
-					ClientAuth: tls.RequestClientCert,
-				},
-			}
+	usedPort := listener.Addr().(*net.TCPAddr).Port
 
-			log.Fatal(server.ServeTLS(listener, "proxy.crt", "proxy.key"))
-		}()
+	go func() {
+		server := http.Server{
+			Handler: engine,
+			Addr:    fmt.Sprintf(":%d", conf.GatewayPort),
+			TLSConfig: &tls.Config{
+				ClientAuth: tls.RequestClientCert,
+			},
+		}
 
-		return usedPort, nil
-	}
+		log.Fatal(server.ServeTLS(listener, "proxy.crt", "proxy.key"))
+	}()
 
-	return -1, fmt.Errorf("unsupported mode")
+	return usedPort, nil
 }
 
 func clientCertsToHeaderUsingEnvoyStyle() gin.HandlerFunc {
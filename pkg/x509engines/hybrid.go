@@ -0,0 +1,280 @@
+package x509engines
+
+import (
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"errors"
+	"fmt"
+	"slices"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// HybridSigner holds the alternative (post-quantum) keys of hybrid CAs. Signers are external to the crypto engines,
+// as crypto.Signer and crypto/x509 do not support post-quantum algorithms.
+type HybridSigner interface {
+	// CreateKey generates a key of the given type and returns its public key as a DER encoded SubjectPublicKeyInfo.
+	CreateKey(keyType models.KeyType, keyID string) ([]byte, error)
+	// Sign returns the signature of the message. The message is not hashed beforehand.
+	Sign(keyID string, message []byte) ([]byte, error)
+}
+
+// Extensions defined by ITU-T X.509 (10/2019) section 9.8 for certificates with alternative signatures.
+var (
+	OIDExtensionSubjectAltPublicKeyInfo = asn1.ObjectIdentifier{2, 5, 29, 72}
+	OIDExtensionAltSignatureAlgorithm   = asn1.ObjectIdentifier{2, 5, 29, 73}
+	OIDExtensionAltSignatureValue       = asn1.ObjectIdentifier{2, 5, 29, 74}
+)
+
+var hybridSignatureAlgorithms = map[models.KeyType]asn1.ObjectIdentifier{
+	models.KeyTypeMLDSA44: {2, 16, 840, 1, 101, 3, 4, 3, 17},
+	models.KeyTypeMLDSA65: {2, 16, 840, 1, 101, 3, 4, 3, 18},
+	models.KeyTypeMLDSA87: {2, 16, 840, 1, 101, 3, 4, 3, 19},
+}
+
+// HybridSignature is the alternative signature of a hybrid certificate.
+type HybridSignature struct {
+	Algorithm asn1.ObjectIdentifier
+	Value     []byte
+	// PreTBSCertificate is the signed message: the TBSCertificate without the signature algorithm field and the
+	// AltSignatureValue extension.
+	PreTBSCertificate []byte
+}
+
+// WithHybridSigners returns a copy of the engine able to create and sign with the alternative keys of hybrid CAs.
+func (engine X509Engine) WithHybridSigners(signers map[string]HybridSigner) X509Engine {
+	engine.hybridSigners = signers
+	return engine
+}
+
+func (engine X509Engine) getHybridSigner(hybridKey models.HybridKeyMetadata) (HybridSigner, error) {
+	if !hybridKey.Type.IsPostQuantum() {
+		return nil, fmt.Errorf("unsupported hybrid key type %s", hybridKey.Type)
+	}
+
+	signer, ok := engine.hybridSigners[hybridKey.SignerID]
+	if !ok {
+		return nil, fmt.Errorf("hybrid signer %s not configured", hybridKey.SignerID)
+	}
+
+	return signer, nil
+}
+
+// addHybridPublicKey generates the alternative key of a hybrid CA and adds its public key to the template.
+func (engine X509Engine) addHybridPublicKey(template *x509.Certificate, hybridKey models.HybridKeyMetadata) error {
+	signer, err := engine.getHybridSigner(hybridKey)
+	if err != nil {
+		return err
+	}
+
+	lri := CryptoAssetLRI(CertificateAuthority, helpers.SerialNumberToString(template.SerialNumber))
+	lCEngine.Debugf("requesting hybrid signer %s for %s key generation: %s", hybridKey.SignerID, hybridKey.Type, lri)
+	spki, err := signer.CreateKey(hybridKey.Type, lri)
+	if err != nil {
+		lCEngine.Errorf("hybrid signer %s failed while generating %s key: %s", hybridKey.SignerID, hybridKey.Type, err)
+		return err
+	}
+
+	template.ExtraExtensions = append(template.ExtraExtensions, pkix.Extension{
+		Id:    OIDExtensionSubjectAltPublicKeyInfo,
+		Value: spki,
+	})
+
+	return nil
+}
+
+// createHybridCertificate creates the certificate as x509.CreateCertificate does. If the issuer is a hybrid CA, the
+// certificate also carries the alternative signature of the issuer.
+func (engine X509Engine) createHybridCertificate(template, parent *x509.Certificate, issuerHybridKey *models.HybridKeyMetadata, pub, priv any) ([]byte, error) {
+	if issuerHybridKey == nil {
+		return x509.CreateCertificate(rand.Reader, template, parent, pub, priv)
+	}
+
+	signer, err := engine.getHybridSigner(*issuerHybridKey)
+	if err != nil {
+		return nil, err
+	}
+
+	algorithm, err := asn1.Marshal(pkix.AlgorithmIdentifier{Algorithm: hybridSignatureAlgorithms[issuerHybridKey.Type]})
+	if err != nil {
+		return nil, err
+	}
+
+	hybridTemplate := *template
+	hybridTemplate.ExtraExtensions = append(slices.Clone(template.ExtraExtensions), pkix.Extension{
+		Id:    OIDExtensionAltSignatureAlgorithm,
+		Value: algorithm,
+	})
+
+	// The certificate is created twice: the first one only provides the TBSCertificate to be signed with the
+	// alternative key. Both share the same TBSCertificate except for the AltSignatureValue extension.
+	preCertificate, err := x509.CreateCertificate(rand.Reader, &hybridTemplate, parent, pub, priv)
+	if err != nil {
+		return nil, err
+	}
+
+	preTBS, err := hybridPreTBSCertificate(preCertificate)
+	if err != nil {
+		return nil, err
+	}
+
+	lri := CryptoAssetLRI(CertificateAuthority, helpers.SerialNumberToString(parent.SerialNumber))
+	lCEngine.Debugf("requesting hybrid signer %s for alternative signature with key %s", issuerHybridKey.SignerID, lri)
+	altSignature, err := signer.Sign(lri, preTBS)
+	if err != nil {
+		lCEngine.Errorf("hybrid signer %s failed while signing: %s", issuerHybridKey.SignerID, err)
+		return nil, err
+	}
+
+	altSignatureValue, err := asn1.Marshal(asn1.BitString{Bytes: altSignature, BitLength: len(altSignature) * 8})
+	if err != nil {
+		return nil, err
+	}
+
+	hybridTemplate.ExtraExtensions = append(hybridTemplate.ExtraExtensions, pkix.Extension{
+		Id:    OIDExtensionAltSignatureValue,
+		Value: altSignatureValue,
+	})
+
+	return x509.CreateCertificate(rand.Reader, &hybridTemplate, parent, pub, priv)
+}
+
+// ParseHybridPublicKey returns the DER encoded SubjectPublicKeyInfo of the alternative key of a hybrid CA certificate.
+func ParseHybridPublicKey(certificate *x509.Certificate) ([]byte, bool) {
+	for _, ext := range certificate.Extensions {
+		if ext.Id.Equal(OIDExtensionSubjectAltPublicKeyInfo) {
+			return ext.Value, true
+		}
+	}
+
+	return nil, false
+}
+
+// ParseHybridSignature returns the alternative signature of a certificate signed by a hybrid CA.
+func ParseHybridSignature(certificate *x509.Certificate) (*HybridSignature, error) {
+	signature := HybridSignature{}
+	var hasAlgorithm, hasValue bool
+	for _, ext := range certificate.Extensions {
+		switch {
+		case ext.Id.Equal(OIDExtensionAltSignatureAlgorithm):
+			var algorithm pkix.AlgorithmIdentifier
+			_, err := asn1.Unmarshal(ext.Value, &algorithm)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alternative signature algorithm: %s", err)
+			}
+			signature.Algorithm = algorithm.Algorithm
+			hasAlgorithm = true
+		case ext.Id.Equal(OIDExtensionAltSignatureValue):
+			var value asn1.BitString
+			_, err := asn1.Unmarshal(ext.Value, &value)
+			if err != nil {
+				return nil, fmt.Errorf("invalid alternative signature value: %s", err)
+			}
+			signature.Value = value.Bytes
+			hasValue = true
+		}
+	}
+
+	if !hasAlgorithm || !hasValue {
+		return nil, errors.New("certificate has no alternative signature")
+	}
+
+	preTBS, err := hybridPreTBSCertificate(certificate.Raw)
+	if err != nil {
+		return nil, err
+	}
+	signature.PreTBSCertificate = preTBS
+
+	return &signature, nil
+}
+
+// hybridPreTBSCertificate returns the TBSCertificate of the DER encoded certificate without the signature algorithm
+// field and the AltSignatureValue extension.
+func hybridPreTBSCertificate(der []byte) ([]byte, error) {
+	var cert, tbs asn1.RawValue
+	_, err := asn1.Unmarshal(der, &cert)
+	if err != nil {
+		return nil, err
+	}
+
+	_, err = asn1.Unmarshal(cert.Bytes, &tbs)
+	if err != nil {
+		return nil, err
+	}
+
+	fields, err := unmarshalSequence(tbs.Bytes)
+	if err != nil {
+		return nil, err
+	}
+
+	// version [0] (optional), serialNumber and signature.
+	signatureIdx := 1
+	if len(fields) > 0 && fields[0].Class == asn1.ClassContextSpecific && fields[0].Tag == 0 {
+		signatureIdx = 2
+	}
+	if len(fields) <= signatureIdx {
+		return nil, errors.New("malformed TBSCertificate")
+	}
+
+	preTBS := []byte{}
+	for i, field := range fields {
+		if i == signatureIdx {
+			continue
+		}
+
+		// extensions [3]
+		if field.Class == asn1.ClassContextSpecific && field.Tag == 3 {
+			field, err = removeAltSignatureValue(field)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		preTBS = append(preTBS, field.FullBytes...)
+	}
+
+	return asn1.Marshal(asn1.RawValue{Class: asn1.ClassUniversal, Tag: asn1.TagSequence, IsCompound: true, Bytes: preTBS})
+}
+
+func removeAltSignatureValue(extensionsField asn1.RawValue) (asn1.RawValue, error) {
+	var extensions []pkix.Extension
+	_, err := asn1.Unmarshal(extensionsField.Bytes, &extensions)
+	if err != nil {
+		return extensionsField, err
+	}
+
+	extensions = slices.DeleteFunc(extensions, func(ext pkix.Extension) bool {
+		return ext.Id.Equal(OIDExtensionAltSignatureValue)
+	})
+
+	extensionsBytes, err := asn1.Marshal(extensions)
+	if err != nil {
+		return extensionsField, err
+	}
+
+	fullBytes, err := asn1.Marshal(asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 3, IsCompound: true, Bytes: extensionsBytes})
+	if err != nil {
+		return extensionsField, err
+	}
+
+	return asn1.RawValue{Class: asn1.ClassContextSpecific, Tag: 3, IsCompound: true, Bytes: extensionsBytes, FullBytes: fullBytes}, nil
+}
+
+func unmarshalSequence(data []byte) ([]asn1.RawValue, error) {
+	values := []asn1.RawValue{}
+	for len(data) > 0 {
+		var value asn1.RawValue
+		rest, err := asn1.Unmarshal(data, &value)
+		if err != nil {
+			return nil, err
+		}
+
+		values = append(values, value)
+		data = rest
+	}
+
+	return values, nil
+}
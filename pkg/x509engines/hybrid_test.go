@@ -0,0 +1,140 @@
+package x509engines
+
+import (
+	"crypto/ed25519"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/lamassuiot/lamassuiot/v2/pkg/helpers"
+	"github.com/lamassuiot/lamassuiot/v2/pkg/models"
+)
+
+// fakeHybridSigner uses Ed25519 keys as a stand-in for the post-quantum keys, as the alternative signatures are opaque
+// to the x509 engine.
+type fakeHybridSigner struct {
+	keys map[string]ed25519.PrivateKey
+}
+
+func (s *fakeHybridSigner) CreateKey(keyType models.KeyType, keyID string) ([]byte, error) {
+	pub, key, err := ed25519.GenerateKey(rand.Reader)
+	if err != nil {
+		return nil, err
+	}
+
+	s.keys[keyID] = key
+	return x509.MarshalPKIXPublicKey(pub)
+}
+
+func (s *fakeHybridSigner) Sign(keyID string, message []byte) ([]byte, error) {
+	key, ok := s.keys[keyID]
+	if !ok {
+		return nil, fmt.Errorf("key %s not found", keyID)
+	}
+
+	return ed25519.Sign(key, message), nil
+}
+
+func verifyHybridSignature(t *testing.T, cert, issuer *x509.Certificate) {
+	spki, ok := ParseHybridPublicKey(issuer)
+	if !ok {
+		t.Fatalf("issuer %s has no alternative public key", issuer.Subject.CommonName)
+	}
+
+	pub, err := x509.ParsePKIXPublicKey(spki)
+	if err != nil {
+		t.Fatalf("could not parse alternative public key: %s", err)
+	}
+
+	signature, err := ParseHybridSignature(cert)
+	if err != nil {
+		t.Fatalf("could not parse alternative signature: %s", err)
+	}
+
+	if !signature.Algorithm.Equal(hybridSignatureAlgorithms[models.KeyTypeMLDSA65]) {
+		t.Errorf("unexpected alternative signature algorithm %s", signature.Algorithm)
+	}
+
+	if !ed25519.Verify(pub.(ed25519.PublicKey), signature.PreTBSCertificate, signature.Value) {
+		t.Errorf("alternative signature of %s is not valid", cert.Subject.CommonName)
+	}
+}
+
+func TestHybridCertificates(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	x509Engine = x509Engine.WithHybridSigners(map[string]HybridSigner{
+		"pqc": &fakeHybridSigner{keys: map[string]ed25519.PrivateKey{}},
+	})
+	hybridKey := &models.HybridKeyMetadata{SignerID: "pqc", Type: models.KeyTypeMLDSA65}
+	keyMetadata := models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256}
+	expiration := time.Now().Add(time.Hour)
+
+	root, err := x509Engine.CreateHybridRootCA("root", keyMetadata, hybridKey, models.Subject{CommonName: "Hybrid Root"}, expiration)
+	if err != nil {
+		t.Fatalf("could not create hybrid root CA: %s", err)
+	}
+	verifyHybridSignature(t, root, root)
+
+	sub, err := x509Engine.CreateHybridSubordinateCA("root", "sub", root, hybridKey, keyMetadata, hybridKey, models.Subject{CommonName: "Hybrid Sub"}, expiration, x509Engine)
+	if err != nil {
+		t.Fatalf("could not create hybrid subordinate CA: %s", err)
+	}
+	verifyHybridSignature(t, sub, root)
+
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "device"}, key)
+	leaf, err := x509Engine.SignHybridCertificateRequest(sub, hybridKey, csr, expiration, nil)
+	if err != nil {
+		t.Fatalf("could not sign hybrid certificate: %s", err)
+	}
+	verifyHybridSignature(t, leaf, sub)
+
+	if _, ok := ParseHybridPublicKey(leaf); ok {
+		t.Errorf("leaf certificate must not have an alternative public key")
+	}
+
+	// The classical chain is not affected by the alternative signatures.
+	roots := x509.NewCertPool()
+	roots.AddCert(root)
+	intermediates := x509.NewCertPool()
+	intermediates.AddCert(sub)
+	_, err = leaf.Verify(x509.VerifyOptions{Roots: roots, Intermediates: intermediates, KeyUsages: []x509.ExtKeyUsage{x509.ExtKeyUsageAny}})
+	if err != nil {
+		t.Errorf("could not verify classical chain: %s", err)
+	}
+}
+
+func TestHybridCertificatesErrors(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	keyMetadata := models.KeyMetadata{Type: models.KeyType(x509.ECDSA), Bits: 256}
+	expiration := time.Now().Add(time.Hour)
+
+	_, err := x509Engine.CreateHybridRootCA("root", keyMetadata, &models.HybridKeyMetadata{SignerID: "pqc", Type: models.KeyTypeMLDSA44}, models.Subject{CommonName: "Hybrid Root"}, expiration)
+	if err == nil {
+		t.Errorf("expected error with an unknown hybrid signer")
+	}
+
+	x509Engine = x509Engine.WithHybridSigners(map[string]HybridSigner{
+		"pqc": &fakeHybridSigner{keys: map[string]ed25519.PrivateKey{}},
+	})
+	_, err = x509Engine.CreateHybridRootCA("root", keyMetadata, &models.HybridKeyMetadata{SignerID: "pqc", Type: models.KeyType(x509.ECDSA)}, models.Subject{CommonName: "Hybrid Root"}, expiration)
+	if err == nil {
+		t.Errorf("expected error with a classical hybrid key type")
+	}
+
+	root, err := x509Engine.CreateRootCA("root", keyMetadata, models.Subject{CommonName: "Classical Root"}, expiration)
+	if err != nil {
+		t.Fatalf("could not create root CA: %s", err)
+	}
+
+	if _, err := ParseHybridSignature(root); err == nil {
+		t.Errorf("expected error parsing the alternative signature of a classical certificate")
+	}
+}
@@ -35,6 +35,7 @@ func SetCryptoEngineLogger(lgr *logrus.Entry) {
 type X509Engine struct {
 	cryptoEngine              cryptoengines.CryptoEngine
 	validationAuthorityDomain string
+	hybridSigners             map[string]HybridSigner
 }
 
 func NewX509Engine(cryptoEngine *cryptoengines.CryptoEngine, validationAuthorityDomain string) X509Engine {
@@ -54,6 +55,12 @@ func (engine X509Engine) GetCACryptoSigner(caCertificate *x509.Certificate) (cry
 }
 
 func (engine X509Engine) CreateRootCA(caID string, keyMetadata models.KeyMetadata, subject models.Subject, expirationTine time.Time) (*x509.Certificate, error) {
+	return engine.CreateHybridRootCA(caID, keyMetadata, nil, subject, expirationTine)
+}
+
+// CreateHybridRootCA creates a root CA as CreateRootCA does. If hybridKey is not nil, an alternative key is generated
+// with the hybrid signer and the certificate carries both its public key and the self-signed alternative signature.
+func (engine X509Engine) CreateHybridRootCA(caID string, keyMetadata models.KeyMetadata, hybridKey *models.HybridKeyMetadata, subject models.Subject, expirationTine time.Time) (*x509.Certificate, error) {
 	lCEngine.Debugf("starting root CA generation with key metadata [%v], subject [%v] and expiration time [%s]", keyMetadata, subject, expirationTine)
 	templateCA, signer, err := engine.genCertTemplateAndPrivateKey(keyMetadata, subject, expirationTine, caID, caID)
 	if err != nil {
@@ -65,21 +72,24 @@ func (engine X509Engine) CreateRootCA(caID string, keyMetadata models.KeyMetadat
 
 	templateCA.IsCA = true
 
-	var derBytes []byte
-	if models.KeyType(keyMetadata.Type) == models.KeyType(x509.RSA) {
-		rsaPub := signer.Public().(*rsa.PublicKey)
-		derBytes, err = x509.CreateCertificate(rand.Reader, templateCA, templateCA, rsaPub, signer)
+	if hybridKey != nil {
+		err = engine.addHybridPublicKey(templateCA, *hybridKey)
 		if err != nil {
-			lCEngine.Errorf("could not sign root CA: %s", err)
 			return nil, err
 		}
+	}
+
+	var pubKey interface{}
+	if models.KeyType(keyMetadata.Type) == models.KeyType(x509.RSA) {
+		pubKey = signer.Public().(*rsa.PublicKey)
 	} else {
-		ecdsaPub := signer.Public().(*ecdsa.PublicKey)
-		derBytes, err = x509.CreateCertificate(rand.Reader, templateCA, templateCA, ecdsaPub, signer)
-		if err != nil {
-			lCEngine.Errorf("could not sign root CA: %s", err)
-			return nil, err
-		}
+		pubKey = signer.Public().(*ecdsa.PublicKey)
+	}
+
+	derBytes, err := engine.createHybridCertificate(templateCA, templateCA, hybridKey, pubKey, signer)
+	if err != nil {
+		lCEngine.Errorf("could not sign root CA: %s", err)
+		return nil, err
 	}
 
 	cert, err := x509.ParseCertificate(derBytes)
@@ -93,12 +103,27 @@ func (engine X509Engine) CreateRootCA(caID string, keyMetadata models.KeyMetadat
 }
 
 func (engine X509Engine) CreateSubordinateCA(aki string, caID string, parentCACertificate *x509.Certificate, keyMetadata models.KeyMetadata, subject models.Subject, expirationTine time.Time, parentEngine X509Engine) (*x509.Certificate, error) {
+	return engine.CreateHybridSubordinateCA(aki, caID, parentCACertificate, nil, keyMetadata, nil, subject, expirationTine, parentEngine)
+}
+
+// CreateHybridSubordinateCA creates a subordinate CA as CreateSubordinateCA does. If hybridKey is not nil, an
+// alternative key is generated with the hybrid signer of the engine and its public key is added to the certificate.
+// If parentHybridKey is not nil, the certificate also carries the alternative signature of the parent CA, produced by
+// the hybrid signer of the parent engine.
+func (engine X509Engine) CreateHybridSubordinateCA(aki string, caID string, parentCACertificate *x509.Certificate, parentHybridKey *models.HybridKeyMetadata, keyMetadata models.KeyMetadata, hybridKey *models.HybridKeyMetadata, subject models.Subject, expirationTine time.Time, parentEngine X509Engine) (*x509.Certificate, error) {
 	templateCA, signer, err := engine.genCertTemplateAndPrivateKey(keyMetadata, subject, expirationTine, aki, caID)
 	if err != nil {
 		lCEngine.Errorf("could not generate subordinate CA Template and Key: %s", err)
 		return nil, err
 	}
 
+	if hybridKey != nil {
+		err = engine.addHybridPublicKey(templateCA, *hybridKey)
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	var pubKey interface{}
 	if models.KeyType(keyMetadata.Type) == models.KeyType(x509.RSA) {
 		pubKey = signer.Public().(*rsa.PublicKey)
@@ -114,7 +139,7 @@ func (engine X509Engine) CreateSubordinateCA(aki string, caID string, parentCACe
 	}
 
 	templateCA.IsCA = true
-	certificateBytes, err := x509.CreateCertificate(rand.Reader, templateCA, parentCACertificate, pubKey, parentCASigner)
+	certificateBytes, err := parentEngine.createHybridCertificate(templateCA, parentCACertificate, parentHybridKey, pubKey, parentCASigner)
 	if err != nil {
 		lCEngine.Errorf("could not sign subordinate CA: %s", err)
 		return nil, err
@@ -129,7 +154,66 @@ func (engine X509Engine) CreateSubordinateCA(aki string, caID string, parentCACe
 	return certificate, nil
 }
 
+// CrossSignCA issues a cross certificate for subjectCACertificate signed with the key of signerCACertificate, held by
+// the crypto engine. The cross certificate keeps the subject, the public key and the subject key ID of the subject CA,
+// so the chains built on the signer CA also validate the certificates issued by the subject CA.
+func (engine X509Engine) CrossSignCA(signerCACertificate *x509.Certificate, subjectCACertificate *x509.Certificate, expirationTine time.Time) (*x509.Certificate, error) {
+	signerSN := helpers.SerialNumberToString(signerCACertificate.SerialNumber)
+	signer, err := engine.cryptoEngine.GetPrivateKeyByID(CryptoAssetLRI(CertificateAuthority, signerSN))
+	if err != nil {
+		lCEngine.Errorf("could not get signer key '%s': %s", signerSN, err)
+		return nil, err
+	}
+
+	serialNumberLimit := new(big.Int).Lsh(big.NewInt(1), 128)
+	sn, _ := rand.Int(rand.Reader, serialNumberLimit)
+
+	template := &x509.Certificate{
+		SerialNumber:          sn,
+		Subject:               subjectCACertificate.Subject,
+		AuthorityKeyId:        signerCACertificate.SubjectKeyId,
+		SubjectKeyId:          subjectCACertificate.SubjectKeyId,
+		OCSPServer:            subjectCACertificate.OCSPServer,
+		CRLDistributionPoints: subjectCACertificate.CRLDistributionPoints,
+		NotBefore:             time.Now(),
+		NotAfter:              expirationTine,
+		KeyUsage:              subjectCACertificate.KeyUsage,
+		ExtKeyUsage:           subjectCACertificate.ExtKeyUsage,
+		BasicConstraintsValid: true,
+		IsCA:                  true,
+	}
+
+	certificateBytes, err := x509.CreateCertificate(rand.Reader, template, signerCACertificate, subjectCACertificate.PublicKey, signer)
+	if err != nil {
+		lCEngine.Errorf("could not cross sign CA: %s", err)
+		return nil, err
+	}
+
+	certificate, err := x509.ParseCertificate(certificateBytes)
+	if err != nil {
+		lCEngine.Errorf("could not parse cross certificate: %s", err)
+		return nil, err
+	}
+
+	return certificate, nil
+}
+
 func (engine X509Engine) SignCertificateRequest(caCertificate *x509.Certificate, csr *x509.CertificateRequest, expirationDate time.Time) (*x509.Certificate, error) {
+	return engine.SignCertificateRequestWithProfile(caCertificate, csr, expirationDate, nil)
+}
+
+// SignCertificateRequestWithProfile signs the CSR as SignCertificateRequest does, but the validation URLs defined
+// in the signing profile (if any) replace the default ones derived from the VA domain. If the profile sets a subject
+// order, the subject is re-encoded with the attributes supported by models.Subject in that order. The profile key
+// usages and extended key usages, if any, replace the default ones. The profile backdate, if any, moves the NotBefore
+// of the certificate to the past.
+func (engine X509Engine) SignCertificateRequestWithProfile(caCertificate *x509.Certificate, csr *x509.CertificateRequest, expirationDate time.Time, profile *models.SigningProfile) (*x509.Certificate, error) {
+	return engine.SignHybridCertificateRequest(caCertificate, nil, csr, expirationDate, profile)
+}
+
+// SignHybridCertificateRequest signs the CSR as SignCertificateRequestWithProfile does. If caHybridKey is not nil,
+// the certificate also carries the alternative signature of the hybrid CA.
+func (engine X509Engine) SignHybridCertificateRequest(caCertificate *x509.Certificate, caHybridKey *models.HybridKeyMetadata, csr *x509.CertificateRequest, expirationDate time.Time, profile *models.SigningProfile) (*x509.Certificate, error) {
 	lCEngine.Debugf("starting csr signing with CA [%s]", caCertificate.Subject.CommonName)
 	lCEngine.Debugf("csr cn is [%s]", csr.Subject.CommonName)
 	caSn := helpers.SerialNumberToString(caCertificate.SerialNumber)
@@ -185,7 +269,51 @@ func (engine X509Engine) SignCertificateRequest(caCertificate *x509.Certificate,
 		ExtKeyUsage: []x509.ExtKeyUsage{x509.ExtKeyUsageClientAuth, x509.ExtKeyUsageServerAuth},
 	}
 
-	certificateBytes, err := x509.CreateCertificate(rand.Reader, &certificateTemplate, caCertificate, csr.PublicKey, privkey)
+	if profile != nil {
+		if len(profile.OCSPServers) > 0 {
+			lCEngine.Debugf("overriding default OCSP servers with signing profile: %v", profile.OCSPServers)
+			certificateTemplate.OCSPServer = profile.OCSPServers
+		}
+
+		if len(profile.CRLDistributionPoints) > 0 {
+			lCEngine.Debugf("overriding default CRL distribution points with signing profile: %v", profile.CRLDistributionPoints)
+			certificateTemplate.CRLDistributionPoints = profile.CRLDistributionPoints
+		}
+
+		if len(profile.SubjectOrder) > 0 {
+			lCEngine.Debugf("reordering subject RDNs with signing profile: %v", profile.SubjectOrder)
+			subject := helpers.PkixNameToSubject(csr.Subject)
+			subject.Order = profile.SubjectOrder
+			certificateTemplate.Subject = helpers.SubjectToPkixName(subject)
+		}
+
+		if len(profile.KeyUsages) > 0 {
+			keyUsage, err := helpers.KeyUsagesToX509(profile.KeyUsages)
+			if err != nil {
+				return nil, err
+			}
+
+			lCEngine.Debugf("overriding default key usages with signing profile: %v", profile.KeyUsages)
+			certificateTemplate.KeyUsage = keyUsage
+		}
+
+		if len(profile.ExtendedKeyUsages) > 0 {
+			extKeyUsages, err := helpers.ExtendedKeyUsagesToX509(profile.ExtendedKeyUsages)
+			if err != nil {
+				return nil, err
+			}
+
+			lCEngine.Debugf("overriding default extended key usages with signing profile: %v", profile.ExtendedKeyUsages)
+			certificateTemplate.ExtKeyUsage = extKeyUsages
+		}
+
+		if profile.NotBeforeBackdate != nil && *profile.NotBeforeBackdate > 0 {
+			lCEngine.Debugf("backdating not before with signing profile: %s", profile.NotBeforeBackdate)
+			certificateTemplate.NotBefore = now.Add(-time.Duration(*profile.NotBeforeBackdate))
+		}
+	}
+
+	certificateBytes, err := engine.createHybridCertificate(&certificateTemplate, caCertificate, caHybridKey, csr.PublicKey, privkey)
 	if err != nil {
 		lCEngine.Errorf("could not sign certificate: %s", err)
 		return nil, err
@@ -26,7 +26,10 @@ func setup(t *testing.T) (string, cryptoengines.CryptoEngine, X509Engine) {
 
 	// Create a new instance of GoCryptoEngine
 	log := helpers.SetupLogger(config.Info, "Test Case", "Golang Engine")
-	engine := cryptoengines.NewGolangPEMEngine(log, config.GolangEngineConfig{StorageDirectory: tempDir})
+	engine, err := cryptoengines.NewGolangPEMEngine(log, config.GolangEngineConfig{StorageDirectory: tempDir})
+	if err != nil {
+		t.Fatalf("could not create Golang engine: %s", err)
+	}
 
 	x509Engine := NewX509Engine(&engine, "ocsp.lamassu.io")
 
@@ -708,6 +711,129 @@ func TestSignCertificateRequest(t *testing.T) {
 	}
 }
 
+func TestSignCertificateRequestWithProfile(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	expirationTime := time.Now().AddDate(1, 0, 0)
+	caCertificate, err := x509Engine.CreateRootCA("rootCA", models.KeyMetadata{
+		Type: models.KeyType(x509.ECDSA),
+		Bits: 256,
+	}, models.Subject{CommonName: "Root CA"}, expirationTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, err := helpers.GenerateCertificateRequest(models.Subject{CommonName: "device"}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	profile := &models.SigningProfile{
+		OCSPServers:           []string{"https://eu.ocsp.lamassu.io/ocsp"},
+		CRLDistributionPoints: []string{"https://eu.crl.lamassu.io/crl/root.crl"},
+	}
+
+	cert, err := x509Engine.SignCertificateRequestWithProfile(caCertificate, csr, expirationTime, profile)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !reflect.DeepEqual(cert.OCSPServer, profile.OCSPServers) {
+		t.Errorf("unexpected OCSP servers, got: %v, want: %v", cert.OCSPServer, profile.OCSPServers)
+	}
+
+	if !reflect.DeepEqual(cert.CRLDistributionPoints, profile.CRLDistributionPoints) {
+		t.Errorf("unexpected CRL distribution points, got: %v, want: %v", cert.CRLDistributionPoints, profile.CRLDistributionPoints)
+	}
+
+	cert, err = x509Engine.SignCertificateRequestWithProfile(caCertificate, csr, expirationTime, &models.SigningProfile{})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if len(cert.OCSPServer) != 1 || len(cert.CRLDistributionPoints) != 1 {
+		t.Errorf("expected default validation URLs to be kept with an empty profile")
+	}
+}
+
+func TestSignCertificateRequestWithProfileBackdate(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	expirationTime := time.Now().AddDate(1, 0, 0)
+	caCertificate, err := x509Engine.CreateRootCA("rootCA", models.KeyMetadata{
+		Type: models.KeyType(x509.ECDSA),
+		Bits: 256,
+	}, models.Subject{CommonName: "Root CA"}, expirationTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "device"}, key)
+
+	backdate := models.TimeDuration(10 * time.Minute)
+	before := time.Now()
+	cert, err := x509Engine.SignCertificateRequestWithProfile(caCertificate, csr, expirationTime, &models.SigningProfile{NotBeforeBackdate: &backdate})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	// NotBefore is encoded with second precision.
+	if notBefore := before.Add(-10 * time.Minute).Truncate(time.Second); cert.NotBefore.After(notBefore.Add(time.Second)) || cert.NotBefore.Before(notBefore.Add(-time.Second)) {
+		t.Errorf("unexpected not before, got: %s, want: %s", cert.NotBefore, notBefore)
+	}
+}
+
+func TestSignCertificateRequestWithProfileSubjectOrder(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	expirationTime := time.Now().AddDate(1, 0, 0)
+	caCertificate, err := x509Engine.CreateRootCA("rootCA", models.KeyMetadata{
+		Type: models.KeyType(x509.ECDSA),
+		Bits: 256,
+	}, models.Subject{CommonName: "Root CA"}, expirationTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, err := helpers.GenerateCertificateRequest(models.Subject{
+		CommonName:   "device",
+		Organization: "Acme Corp",
+		Country:      "ES",
+		SerialNumber: "SN-0001",
+	}, key)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	cert, err := x509Engine.SignCertificateRequestWithProfile(caCertificate, csr, expirationTime, &models.SigningProfile{
+		SubjectOrder: []models.SubjectAttribute{
+			models.SubjectAttributeSerialNumber,
+			models.SubjectAttributeCommonName,
+			models.SubjectAttributeOrganization,
+			models.SubjectAttributeCountry,
+		},
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	expected := []any{"SN-0001", "device", "Acme Corp", "ES"}
+	got := []any{}
+	for _, atv := range cert.Subject.Names {
+		got = append(got, atv.Value)
+	}
+
+	if !reflect.DeepEqual(got, expected) {
+		t.Errorf("unexpected subject RDN order, got: %v, want: %v", got, expected)
+	}
+}
+
 func TestGetEngineConfig(t *testing.T) {
 	tempDir, engine, x509Engine := setup(t)
 	defer teardown(tempDir)
@@ -721,3 +847,80 @@ func TestGetEngineConfig(t *testing.T) {
 		t.Errorf("unexpected result, got: %v, want: %v", config, expected)
 	}
 }
+
+func TestCrossSignCA(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	keyMetadata := models.KeyMetadata{
+		Type: models.KeyType(x509.ECDSA),
+		Bits: 256,
+	}
+	expirationTime := time.Now().AddDate(1, 0, 0)
+
+	oldCA, err := x509Engine.CreateRootCA("oldCA", keyMetadata, models.Subject{CommonName: "Root CA"}, expirationTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	successorCA, err := x509Engine.CreateRootCA("successorCA", keyMetadata, models.Subject{CommonName: "Root CA"}, expirationTime.AddDate(1, 0, 0))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	crossCert, err := x509Engine.CrossSignCA(oldCA, successorCA, expirationTime)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if !crossCert.IsCA {
+		t.Errorf("cross certificate is not a CA")
+	}
+
+	if crossCert.Subject.String() != successorCA.Subject.String() {
+		t.Errorf("unexpected subject: %s", crossCert.Subject.String())
+	}
+
+	if !slices.Equal(crossCert.SubjectKeyId, successorCA.SubjectKeyId) {
+		t.Errorf("unexpected subject key ID: %s", crossCert.SubjectKeyId)
+	}
+
+	if !slices.Equal(crossCert.AuthorityKeyId, oldCA.SubjectKeyId) {
+		t.Errorf("unexpected authority key ID: %s", crossCert.AuthorityKeyId)
+	}
+
+	if crossCert.NotAfter.Unix() != expirationTime.Unix() {
+		t.Errorf("unexpected expiration: %s", crossCert.NotAfter)
+	}
+
+	if err := crossCert.CheckSignatureFrom(oldCA); err != nil {
+		t.Errorf("cross certificate not signed by the old CA: %s", err)
+	}
+
+	// The certificates issued by the successor CA must chain to the old CA through the cross certificate.
+	key, _ := helpers.GenerateECDSAKey(elliptic.P256())
+	csr, _ := helpers.GenerateCertificateRequest(models.Subject{CommonName: "device"}, key)
+	leaf, err := x509Engine.SignCertificateRequest(successorCA, csr, time.Now().Add(time.Hour))
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	if err := leaf.CheckSignatureFrom(crossCert); err != nil {
+		t.Errorf("leaf certificate does not chain to the cross certificate: %s", err)
+	}
+}
+
+func TestCrossSignCANonExistentKey(t *testing.T) {
+	tempDir, _, x509Engine := setup(t)
+	defer teardown(tempDir)
+
+	externalCA, _, err := helpers.GenerateSelfSignedCA(x509.ECDSA, time.Hour, "External CA")
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	_, err = x509Engine.CrossSignCA(externalCA, externalCA, time.Now().Add(time.Hour))
+	if err == nil {
+		t.Errorf("expected error, got nil")
+	}
+}